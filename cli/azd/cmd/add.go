@@ -0,0 +1,189 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type addFlags struct {
+	name   string
+	global *internal.GlobalCommandOptions
+}
+
+func (f *addFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.name,
+		"name",
+		"",
+		"The name of the module in the infrastructure, used as the prefix of its outputs. Defaults to the resource.",
+	)
+	f.global = global
+}
+
+func newAddFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *addFlags {
+	flags := &addFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "add [<resource>]",
+		Short:     "Add an Azure resource to the infrastructure of your application.",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: addResourceKinds(),
+	}
+}
+
+type addAction struct {
+	flags         *addFlags
+	args          []string
+	projectConfig *project.ProjectConfig
+	console       input.Console
+}
+
+func newAddAction(
+	flags *addFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	console input.Console,
+) actions.Action {
+	return &addAction{
+		flags:         flags,
+		args:          args,
+		projectConfig: projectConfig,
+		console:       console,
+	}
+}
+
+func (a *addAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.projectConfig.Infra.Provider != provisioning.Bicep {
+		return nil, fmt.Errorf(
+			"azd add only supports Bicep infrastructure, the project uses the %s provider", a.projectConfig.Infra.Provider)
+	}
+
+	module, err := a.selectModule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: fmt.Sprintf("Adding %s to the infrastructure (azd add)", module.Description),
+	})
+
+	infraPath := a.projectConfig.Infra.Path
+	if !filepath.IsAbs(infraPath) {
+		infraPath = filepath.Join(a.projectConfig.Path, infraPath)
+	}
+
+	mainModule := a.projectConfig.Infra.Module
+	if mainModule == "" {
+		mainModule = bicep.DefaultModule
+	}
+
+	result, err := module.Compose(infraPath, mainModule, a.flags.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Added %s to %s.", module.Kind, filepath.Join(infraPath, mainModule+".bicep")),
+			FollowUp: fmt.Sprintf(
+				"Run %s to create the resource. Its outputs will be stored in the environment as %s.",
+				output.WithHighLightFormat("azd provision"),
+				strings.Join(result.EnvVarNames, ", "),
+			),
+		},
+	}, nil
+}
+
+// selectModule returns the module of the resource argument, prompting for one when no resource is specified.
+func (a *addAction) selectModule(ctx context.Context) (*infra.ComposableModule, error) {
+	if len(a.args) > 0 {
+		module := infra.FindComposableModule(a.args[0])
+		if module == nil {
+			return nil, fmt.Errorf(
+				"unknown resource '%s', supported resources are: %s", a.args[0], strings.Join(addResourceKinds(), ", "))
+		}
+
+		return module, nil
+	}
+
+	if a.flags.global.NoPrompt {
+		return nil, fmt.Errorf(
+			"a resource is required when prompting is disabled, supported resources are: %s",
+			strings.Join(addResourceKinds(), ", "))
+	}
+
+	options := make([]string, 0, len(infra.ComposableModules))
+	for _, module := range infra.ComposableModules {
+		options = append(options, fmt.Sprintf("%s - %s", module.Kind, module.Description))
+	}
+
+	selected, err := a.console.Select(ctx, input.ConsoleOptions{
+		Message: "Select the resource to add",
+		Options: options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("selecting resource: %w", err)
+	}
+
+	return &infra.ComposableModules[selected], nil
+}
+
+func addResourceKinds() []string {
+	kinds := make([]string, 0, len(infra.ComposableModules))
+	for _, module := range infra.ComposableModules {
+		kinds = append(kinds, module.Kind)
+	}
+
+	return kinds
+}
+
+func getCmdAddHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Add an Azure resource, from a curated set of Bicep modules, to the infrastructure of your application."+
+			" The module is wired to the environment name, location and tags of your infrastructure and its outputs"+
+			" are stored in the environment when provisioned.",
+		[]string{
+			formatHelpNote(fmt.Sprintf("Supported resources: %s.", strings.Join(addResourceKinds(), ", "))),
+			formatHelpNote(fmt.Sprintf(
+				"Use %s to add more than one resource of the same kind.",
+				output.WithHighLightFormat("--name"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Declare the model deployments of openai, and whether services authenticate with keys, under %s in %s.",
+				output.WithHighLightFormat("infra.openai"),
+				output.WithHighLightFormat("azure.yaml"),
+			)),
+		})
+}
+
+func getCmdAddHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Add an Azure Cosmos DB account.": output.WithHighLightFormat("azd add cosmos"),
+		"Add a second Azure Cache for Redis named sessions.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd add redis --name"),
+			output.WithWarningFormat("sessions"),
+		),
+	})
+}
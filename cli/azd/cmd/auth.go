@@ -33,10 +33,21 @@ func authActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		DefaultFormat:  output.NoneFormat,
 	})
 
+	group.Add("show", &actions.ActionDescriptorOptions{
+		Command:        newAuthShowCmd(),
+		FlagsResolver:  newAuthShowFlags,
+		ActionResolver: newAuthShowAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
 	group.Add("logout", &actions.ActionDescriptorOptions{
 		Command:        newLogoutCmd("auth"),
+		FlagsResolver:  newLogoutFlags,
 		ActionResolver: newLogoutAction,
 	})
 
+	authSpActions(group)
+
 	return group
 }
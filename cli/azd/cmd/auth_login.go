@@ -52,6 +52,8 @@ type loginFlags struct {
 	federatedTokenProvider string
 	scopes                 []string
 	redirectPort           int
+	noBrowser              bool
+	profile                string
 	global                 *internal.GlobalCommandOptions
 }
 
@@ -149,6 +151,17 @@ func (lf *loginFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		"redirect-port",
 		0,
 		"Choose the port to be used as part of the redirect URI during interactive login.")
+	local.BoolVar(
+		&lf.noBrowser,
+		"no-browser",
+		false,
+		"Never launch a browser. Log in with a device code, printed with a QR code of the login page, "+
+			"instead of interactively. Can also be set with the AZD_NO_BROWSER environment variable.")
+	local.StringVar(
+		&lf.profile,
+		"profile",
+		"",
+		"The auth profile to log in with. Environments bound to the profile with `azd env set-profile` use its account.")
 
 	lf.global = global
 }
@@ -168,7 +181,8 @@ func newLoginCmd(parent string) *cobra.Command {
 		Log in to Azure.
 
 		When run without any arguments, log in interactively using a browser. To log in using a device code, pass
-		--use-device-code.
+		--use-device-code. On machines without a browser, ex) over SSH or in a container, pass --no-browser to log in
+		using a device code, printed along with a QR code of the login page to scan from another device.
 		
 		To log in as a service principal, pass --client-id and --tenant-id as well as one of: --client-secret, 
 		--client-certificate, or --federated-credential-provider.
@@ -257,6 +271,16 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			"Next time use `azd auth login`.")
 	}
 
+	if la.flags.profile != "" {
+		if err := la.authManager.UseProfile(la.flags.profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if la.flags.noBrowser {
+		la.authManager.DisableBrowser()
+	}
+
 	if la.flags.onlyCheckStatus {
 		// In check status mode, we always print the final status to stdout.
 		// We print any non-setup related errors to stderr.
@@ -334,6 +358,11 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		}
 	}
 
+	if profile := la.authManager.Profile(); profile != auth.DefaultProfile {
+		la.console.Message(ctx, fmt.Sprintf("Logged in to Azure with profile %s.", output.WithHighLightFormat(profile)))
+		return nil, nil
+	}
+
 	la.console.Message(ctx, cLoginSuccessMessage)
 	return nil, nil
 }
@@ -461,6 +490,15 @@ func (la *loginAction) login(ctx context.Context) error {
 		return err
 	}
 
+	if la.authManager.BrowserDisabled() {
+		if la.flags.useDeviceCode.ptr != nil && !useDevCode {
+			return errors.New("--use-device-code=false can't be used without a browser. Remove --no-browser or unset " +
+				auth.NoBrowserEnvVarName)
+		}
+
+		useDevCode = true
+	}
+
 	if useDevCode {
 		_, err := la.authManager.LoginWithDeviceCode(ctx, la.flags.tenantID, la.flags.scopes)
 		if err != nil {
@@ -9,13 +9,32 @@ import (
 	"io"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+type logoutFlags struct {
+	profile string
+	global  *internal.GlobalCommandOptions
+}
+
+func (lf *logoutFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&lf.profile, "profile", "", "The auth profile to log out of.")
+	lf.global = global
+}
+
+func newLogoutFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *logoutFlags {
+	flags := &logoutFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
 func newLogoutCmd(parent string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
@@ -28,6 +47,7 @@ func newLogoutCmd(parent string) *cobra.Command {
 }
 
 type logoutAction struct {
+	flags             *logoutFlags
 	authManager       *auth.Manager
 	accountSubManager *account.SubscriptionsManager
 	formatter         output.Formatter
@@ -37,6 +57,7 @@ type logoutAction struct {
 }
 
 func newLogoutAction(
+	flags *logoutFlags,
 	authManager *auth.Manager,
 	accountSubManager *account.SubscriptionsManager,
 	formatter output.Formatter,
@@ -44,6 +65,7 @@ func newLogoutAction(
 	console input.Console,
 	annotations CmdAnnotations) actions.Action {
 	return &logoutAction{
+		flags:             flags,
 		authManager:       authManager,
 		accountSubManager: accountSubManager,
 		formatter:         formatter,
@@ -64,6 +86,12 @@ func (la *logoutAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			"Next time use `azd auth logout`.")
 	}
 
+	if la.flags.profile != "" {
+		if err := la.authManager.UseProfile(la.flags.profile); err != nil {
+			return nil, err
+		}
+	}
+
 	err := la.authManager.Logout(ctx)
 	if err != nil {
 		return nil, err
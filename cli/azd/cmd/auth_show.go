@@ -0,0 +1,247 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/permissions"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type authShowFlags struct {
+	permissions bool
+	envFlag
+}
+
+func (f *authShowFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.permissions,
+		"permissions",
+		false,
+		"Evaluate whether the signed-in principal can perform the actions the project needs in the subscription "+
+			"of the environment.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newAuthShowFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *authShowFlags {
+	flags := &authShowFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newAuthShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the signed-in principal, its tenant and subscriptions.",
+		Long: "Show the signed-in principal, its tenant and subscriptions. With --permissions, also evaluate whether " +
+			"the signed-in principal can perform the actions the project needs, such as creating resource groups and " +
+			"role assignments, pushing images to Azure Container Registry and getting AKS cluster credentials, and " +
+			"report the missing permissions.",
+	}
+}
+
+// authShowPrincipal is the signed-in principal
+type authShowPrincipal struct {
+	Id string `json:"id"`
+	// The user principal name of a user, empty for service principals
+	Name string `json:"name,omitempty"`
+	// User or ServicePrincipal
+	Type string `json:"type"`
+}
+
+// authShowResult is the JSON output of azd auth show
+type authShowResult struct {
+	Profile       string                 `json:"profile"`
+	Principal     authShowPrincipal      `json:"principal"`
+	TenantId      string                 `json:"tenantId"`
+	Subscriptions []account.Subscription `json:"subscriptions"`
+	Permissions   *permissions.Result    `json:"permissions,omitempty"`
+}
+
+type authShowAction struct {
+	flags              *authShowFlags
+	authManager        *auth.Manager
+	userProfileService *azcli.UserProfileService
+	accountManager     account.Manager
+	permissionsManager *permissions.Manager
+	lazyEnv            *lazy.Lazy[*environment.Environment]
+	lazyProjectConfig  *lazy.Lazy[*project.ProjectConfig]
+	console            input.Console
+	formatter          output.Formatter
+	writer             io.Writer
+}
+
+func newAuthShowAction(
+	flags *authShowFlags,
+	_ auth.LoggedInGuard,
+	authManager *auth.Manager,
+	userProfileService *azcli.UserProfileService,
+	accountManager account.Manager,
+	permissionsManager *permissions.Manager,
+	lazyEnv *lazy.Lazy[*environment.Environment],
+	lazyProjectConfig *lazy.Lazy[*project.ProjectConfig],
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &authShowAction{
+		flags:              flags,
+		authManager:        authManager,
+		userProfileService: userProfileService,
+		accountManager:     accountManager,
+		permissionsManager: permissionsManager,
+		lazyEnv:            lazyEnv,
+		lazyProjectConfig:  lazyProjectConfig,
+		console:            console,
+		formatter:          formatter,
+		writer:             writer,
+	}
+}
+
+func (a *authShowAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	// the home tenant of the signed-in principal
+	token, err := a.userProfileService.GetAccessToken(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tenantId, err := auth.GetTenantIdFromToken(token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("getting tenant from token: %w", err)
+	}
+
+	principalId, err := auth.GetOidFromAccessToken(token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("getting oid from token: %w", err)
+	}
+
+	result := authShowResult{
+		Profile: a.authManager.Profile(),
+		Principal: authShowPrincipal{
+			Id:   principalId,
+			Type: "ServicePrincipal",
+		},
+		TenantId: tenantId,
+	}
+
+	// only users have a profile
+	if user, err := a.userProfileService.GetSignedInUser(ctx, tenantId); err == nil {
+		result.Principal.Name = user.UserPrincipalName
+		result.Principal.Type = "User"
+	} else {
+		log.Printf("signed-in principal isn't a user: %v", err)
+	}
+
+	result.Subscriptions, err = a.accountManager.GetSubscriptionsWithDefaultSet(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing subscriptions: %w", err)
+	}
+
+	var env *environment.Environment
+	if a.flags.permissions {
+		env, err = a.lazyEnv.GetValue()
+		if err != nil {
+			return nil, fmt.Errorf("--permissions evaluates the permissions of an environment: %w", err)
+		}
+
+		projectConfig, err := a.lazyProjectConfig.GetValue()
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptionId := env.GetSubscriptionId()
+		if subscriptionId == "" {
+			return nil, fmt.Errorf(
+				"%s is not set in environment %s. Run `azd provision` to select a subscription",
+				environment.SubscriptionIdEnvVarName, env.GetEnvName())
+		}
+
+		displayMsg := "Evaluating permissions"
+		a.console.ShowSpinner(ctx, displayMsg, input.Step)
+		result.Permissions, err = a.permissionsManager.Check(
+			ctx, projectConfig, subscriptionId, env.Getenv(environment.ResourceGroupEnvVarName))
+		a.console.StopSpinner(ctx, "", input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		if err := a.formatter.Format(result, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("signed-in principal could not be displayed: %w", err)
+		}
+	} else {
+		a.showResult(ctx, result, env)
+	}
+
+	if result.Permissions != nil {
+		if missing := result.Permissions.Missing(); len(missing) > 0 {
+			return nil, fmt.Errorf(
+				"the signed-in principal is missing %d permission(s) the project needs. "+
+					"Ask an owner of the subscription to assign it a role which grants them",
+				len(missing))
+		}
+	}
+
+	return nil, nil
+}
+
+func (a *authShowAction) showResult(ctx context.Context, result authShowResult, env *environment.Environment) {
+	principal := result.Principal.Id
+	if result.Principal.Name != "" {
+		principal = fmt.Sprintf("%s (%s)", result.Principal.Name, result.Principal.Id)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Signed in as %s %s", output.WithHighLightFormat(principal),
+		output.WithGrayFormat("(%s)", result.Principal.Type)))
+	a.console.Message(ctx, fmt.Sprintf("Profile: %s", result.Profile))
+	a.console.Message(ctx, fmt.Sprintf("Tenant: %s", result.TenantId))
+
+	a.console.Message(ctx, "\nSubscriptions:")
+	if len(result.Subscriptions) == 0 {
+		a.console.Message(ctx, output.WithGrayFormat("  (none)"))
+	}
+	for _, subscription := range result.Subscriptions {
+		line := fmt.Sprintf("  %s (%s)", subscription.Name, subscription.Id)
+		if subscription.IsDefault {
+			line += output.WithGrayFormat(" default")
+		}
+		a.console.Message(ctx, line)
+	}
+
+	if result.Permissions == nil {
+		a.console.Message(ctx, "")
+		return
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("\nPermissions in subscription %s (environment %s):",
+		env.GetSubscriptionId(), env.GetEnvName()))
+	for _, check := range result.Permissions.Checks {
+		if check.Allowed {
+			a.console.Message(ctx, fmt.Sprintf("  %s %s", output.WithSuccessFormat("(✓)"), check.Description))
+			continue
+		}
+
+		a.console.Message(ctx, fmt.Sprintf("  %s %s\n      %s", output.WithErrorFormat("(x)"), check.Description,
+			output.WithGrayFormat("missing %s at %s", check.Action, check.Scope)))
+	}
+	a.console.Message(ctx, "")
+}
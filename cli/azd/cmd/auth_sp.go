@@ -0,0 +1,571 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// credentialExpiryWarning is how long before a secret or certificate expires azd auth sp show warns about it
+const credentialExpiryWarning = 30 * 24 * time.Hour
+
+func authSpActions(group *actions.ActionDescriptor) {
+	spGroup := group.Add("sp", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "sp",
+			Short: "Manage the service principal used by the deployment pipeline.",
+		},
+	})
+
+	spGroup.Add("show", &actions.ActionDescriptorOptions{
+		Command:        newAuthSpShowCmd(),
+		FlagsResolver:  newAuthSpShowFlags,
+		ActionResolver: newAuthSpShowAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
+	spGroup.Add("create", &actions.ActionDescriptorOptions{
+		Command:        newAuthSpCreateCmd(),
+		FlagsResolver:  newAuthSpCreateFlags,
+		ActionResolver: newAuthSpCreateAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
+	spGroup.Add("rotate", &actions.ActionDescriptorOptions{
+		Command:        newAuthSpRotateCmd(),
+		FlagsResolver:  newAuthSpRotateFlags,
+		ActionResolver: newAuthSpRotateAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+}
+
+// authSpPipelineFlags are the flags of the commands which update the credentials of the pipeline of the repository
+type authSpPipelineFlags struct {
+	noPipelineUpdate bool
+	provider         string
+	remoteName       string
+}
+
+func (f *authSpPipelineFlags) Bind(local *pflag.FlagSet) {
+	local.BoolVar(
+		&f.noPipelineUpdate,
+		"no-pipeline-update",
+		false,
+		"Don't update the credentials of the pipeline. The new credentials are written to the output instead.",
+	)
+	local.StringVar(&f.provider, "provider", "",
+		"The pipeline provider to update (github for Github Actions and azdo for Azure Pipelines).")
+	local.StringVar(&f.remoteName, "remote-name", "origin", "The name of the git remote the pipeline runs on.")
+}
+
+// authSpPipeline updates the credentials of the pipeline of the repository of the project
+type authSpPipeline struct {
+	azCli          azcli.AzCli
+	gitCli         git.GitCli
+	azdCtx         *azdcontext.AzdContext
+	env            *environment.Environment
+	console        input.Console
+	serviceLocator ioc.ServiceLocator
+}
+
+func (p *authSpPipeline) update(
+	ctx context.Context,
+	flags *authSpPipelineFlags,
+	credentials json.RawMessage,
+) error {
+	manager, err := pipeline.NewPipelineManager(ctx, p.azCli, p.gitCli, p.azdCtx, p.env, p.console,
+		&pipeline.PipelineManagerArgs{
+			PipelineRemoteName: flags.remoteName,
+			PipelineProvider:   flags.provider,
+		}, p.serviceLocator)
+	if err != nil {
+		return err
+	}
+
+	if err := manager.UpdateCredentials(ctx, credentials); err != nil {
+		return fmt.Errorf("updating the credentials of the pipeline: %w", err)
+	}
+
+	return nil
+}
+
+// ensureSubscription returns the subscription of the environment, which the service principal is assigned roles in
+func ensureSubscription(env *environment.Environment) (string, error) {
+	subscriptionId := env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return "", fmt.Errorf(
+			"%s is not set in environment %s. Run `azd provision` to select a subscription",
+			environment.SubscriptionIdEnvVarName, env.GetEnvName())
+	}
+
+	return subscriptionId, nil
+}
+
+// resolveServicePrincipal gets the service principal with the object id or name, or the service principal of the
+// pipeline of the environment when name is empty
+func resolveServicePrincipal(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	name string,
+) (*azcli.AzCliServicePrincipal, error) {
+	subscriptionId, err := ensureSubscription(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = env.Getenv(environment.PipelinePrincipalIdEnvVarName)
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf(
+			"environment %s has no pipeline service principal. Specify one with --name, or run `azd auth sp create`",
+			env.GetEnvName())
+	}
+
+	return azCli.GetServicePrincipal(ctx, subscriptionId, name)
+}
+
+type authSpShowFlags struct {
+	name string
+	envFlag
+}
+
+func (f *authSpShowFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&f.name, "name", "",
+		"The object id or name of the service principal. Defaults to the pipeline service principal of the environment.")
+	f.envFlag.Bind(local, global)
+}
+
+func newAuthSpShowFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *authSpShowFlags {
+	flags := &authSpShowFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newAuthSpShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the service principal of the pipeline, its credentials and when they expire.",
+	}
+}
+
+type authSpShowAction struct {
+	flags     *authSpShowFlags
+	azCli     azcli.AzCli
+	env       *environment.Environment
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newAuthSpShowAction(
+	flags *authSpShowFlags,
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &authSpShowAction{
+		flags:     flags,
+		azCli:     azCli,
+		env:       env,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+func (a *authSpShowAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	servicePrincipal, err := resolveServicePrincipal(ctx, a.azCli, a.env, a.flags.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		if err := a.formatter.Format(servicePrincipal, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("service principal could not be displayed: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Service principal %s", output.WithHighLightFormat(servicePrincipal.DisplayName)))
+	a.console.Message(ctx, fmt.Sprintf("  Object id:      %s", servicePrincipal.ObjectId))
+	a.console.Message(ctx, fmt.Sprintf("  Application id: %s", servicePrincipal.AppId))
+	a.console.Message(ctx, fmt.Sprintf("  Tenant id:      %s", servicePrincipal.TenantId))
+
+	a.console.Message(ctx, "\nSecrets:")
+	showCredentials(ctx, a.console, servicePrincipal.Secrets)
+
+	a.console.Message(ctx, "\nCertificates:")
+	showCredentials(ctx, a.console, servicePrincipal.Certificates)
+
+	a.console.Message(ctx, "\nFederated credentials:")
+	if len(servicePrincipal.FederatedSubjects) == 0 {
+		a.console.Message(ctx, output.WithGrayFormat("  (none)"))
+	}
+	for _, subject := range servicePrincipal.FederatedSubjects {
+		a.console.Message(ctx, fmt.Sprintf("  %s", subject))
+	}
+	a.console.Message(ctx, "")
+
+	return nil, nil
+}
+
+// showCredentials writes a line per credential, highlighting the credentials which expired or expire soon
+func showCredentials(ctx context.Context, console input.Console, credentials []azcli.AzCliServicePrincipalCredential) {
+	if len(credentials) == 0 {
+		console.Message(ctx, output.WithGrayFormat("  (none)"))
+	}
+
+	for _, credential := range credentials {
+		line := fmt.Sprintf("  %s", credential.KeyId)
+		if credential.DisplayName != "" {
+			line += fmt.Sprintf(" (%s)", credential.DisplayName)
+		}
+
+		if credential.EndDateTime != nil {
+			expires := credential.EndDateTime.Format(time.DateOnly)
+			switch {
+			case time.Now().After(*credential.EndDateTime):
+				line += " " + output.WithErrorFormat("expired %s", expires)
+			case time.Until(*credential.EndDateTime) < credentialExpiryWarning:
+				line += " " + output.WithWarningFormat("expires %s", expires)
+			default:
+				line += fmt.Sprintf(" expires %s", expires)
+			}
+		}
+
+		console.Message(ctx, line)
+	}
+}
+
+type authSpCreateFlags struct {
+	name  string
+	roles []string
+	authSpPipelineFlags
+	envFlag
+}
+
+func (f *authSpCreateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&f.name, "name", "", "The name of the service principal to create or update.")
+	local.StringArrayVar(&f.roles, "role", pipeline.DefaultRoleNames,
+		"The roles to assign to the service principal in the subscription of the environment.")
+	f.authSpPipelineFlags.Bind(local)
+	f.envFlag.Bind(local, global)
+}
+
+func newAuthSpCreateFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *authSpCreateFlags {
+	flags := &authSpCreateFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newAuthSpCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create",
+		Short: "Create or update the service principal of the pipeline and update the credentials of the pipeline.",
+	}
+}
+
+type authSpCreateAction struct {
+	flags     *authSpCreateFlags
+	azCli     azcli.AzCli
+	env       *environment.Environment
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+	pipeline  *authSpPipeline
+}
+
+func newAuthSpCreateAction(
+	flags *authSpCreateFlags,
+	azCli azcli.AzCli,
+	gitCli git.GitCli,
+	azdCtx *azdcontext.AzdContext,
+	env *environment.Environment,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+	serviceLocator ioc.ServiceLocator,
+) actions.Action {
+	return &authSpCreateAction{
+		flags:     flags,
+		azCli:     azCli,
+		env:       env,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+		pipeline: &authSpPipeline{
+			azCli:          azCli,
+			gitCli:         gitCli,
+			azdCtx:         azdCtx,
+			env:            env,
+			console:        console,
+			serviceLocator: serviceLocator,
+		},
+	}
+}
+
+func (a *authSpCreateAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	subscriptionId, err := ensureSubscription(a.env)
+	if err != nil {
+		return nil, err
+	}
+
+	name := a.flags.name
+	if name == "" {
+		// matches the name azd pipeline config uses when a name is not provided
+		name = fmt.Sprintf("az-dev-%s", time.Now().UTC().Format("01-02-2006-15-04-05"))
+	}
+
+	displayMsg := fmt.Sprintf("Creating or updating service principal %s", name)
+	a.console.ShowSpinner(ctx, displayMsg, input.Step)
+	credentials, err := a.azCli.CreateOrUpdateServicePrincipal(ctx, subscriptionId, name, a.flags.roles)
+	a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update service principal: %w", err)
+	}
+
+	var azureCredentials azcli.AzureCredentials
+	if err := json.Unmarshal(credentials, &azureCredentials); err != nil {
+		return nil, err
+	}
+
+	principalId, err := a.azCli.GetServicePrincipalId(ctx, subscriptionId, azureCredentials.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	a.env.DotenvSet(environment.PipelinePrincipalIdEnvVarName, principalId)
+	if err := a.env.Save(); err != nil {
+		return nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	if err := publishCredentials(
+		ctx, a.pipeline, &a.flags.authSpPipelineFlags, a.formatter, a.writer, principalId, credentials); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf(
+				"Service principal %s is the pipeline service principal of environment %s.", name, a.env.GetEnvName()),
+		},
+	}, nil
+}
+
+type authSpRotateFlags struct {
+	name        string
+	roles       []string
+	certificate string
+	authSpPipelineFlags
+	envFlag
+}
+
+func (f *authSpRotateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&f.name, "name", "",
+		"The object id or name of the service principal. Defaults to the pipeline service principal of the environment.")
+	local.StringArrayVar(&f.roles, "role", nil,
+		"The roles to assign again to the service principal in the subscription of the environment.")
+	local.StringVar(&f.certificate, "certificate", "",
+		"The PEM or DER encoded certificate file replacing the certificates of the service principal, "+
+			"instead of resetting its secret.")
+	f.authSpPipelineFlags.Bind(local)
+	f.envFlag.Bind(local, global)
+}
+
+func newAuthSpRotateFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *authSpRotateFlags {
+	flags := &authSpRotateFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newAuthSpRotateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "rotate",
+		Short: "Rotate the secret or certificate of the service principal of the pipeline and update the credentials " +
+			"of the pipeline.",
+	}
+}
+
+type authSpRotateAction struct {
+	flags     *authSpRotateFlags
+	azCli     azcli.AzCli
+	env       *environment.Environment
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+	pipeline  *authSpPipeline
+}
+
+func newAuthSpRotateAction(
+	flags *authSpRotateFlags,
+	azCli azcli.AzCli,
+	gitCli git.GitCli,
+	azdCtx *azdcontext.AzdContext,
+	env *environment.Environment,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+	serviceLocator ioc.ServiceLocator,
+) actions.Action {
+	return &authSpRotateAction{
+		flags:     flags,
+		azCli:     azCli,
+		env:       env,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+		pipeline: &authSpPipeline{
+			azCli:          azCli,
+			gitCli:         gitCli,
+			azdCtx:         azdCtx,
+			env:            env,
+			console:        console,
+			serviceLocator: serviceLocator,
+		},
+	}
+}
+
+func (a *authSpRotateAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	servicePrincipal, err := resolveServicePrincipal(ctx, a.azCli, a.env, a.flags.name)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionId := a.env.GetSubscriptionId()
+
+	if len(a.flags.roles) > 0 {
+		displayMsg := fmt.Sprintf("Assigning roles to service principal %s", servicePrincipal.DisplayName)
+		a.console.ShowSpinner(ctx, displayMsg, input.Step)
+		err := a.azCli.EnsureServicePrincipalRoles(ctx, subscriptionId, servicePrincipal, a.flags.roles)
+		a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.flags.certificate != "" {
+		certificate, err := os.ReadFile(a.flags.certificate)
+		if err != nil {
+			return nil, fmt.Errorf("reading certificate: %w", err)
+		}
+
+		displayMsg := fmt.Sprintf("Replacing the certificate of service principal %s", servicePrincipal.DisplayName)
+		a.console.ShowSpinner(ctx, displayMsg, input.Step)
+		err = a.azCli.SetServicePrincipalCertificate(ctx, subscriptionId, servicePrincipal, certificate)
+		a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+
+		// azd never has the private key of the certificate, so the pipeline can't be updated
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("The certificate of service principal %s was replaced.", servicePrincipal.DisplayName),
+				FollowUp: "Update the certificate your pipeline signs in with to the new certificate and its private " +
+					"key.",
+			},
+		}, nil
+	}
+
+	displayMsg := fmt.Sprintf("Resetting the secret of service principal %s", servicePrincipal.DisplayName)
+	a.console.ShowSpinner(ctx, displayMsg, input.Step)
+	credentials, err := a.azCli.ResetServicePrincipalSecret(ctx, subscriptionId, servicePrincipal)
+	a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	// pipelines which sign in with federated credentials don't use the secret, and are left unchanged
+	if len(servicePrincipal.FederatedSubjects) > 0 && !a.flags.noPipelineUpdate {
+		a.console.Message(ctx, output.WithGrayFormat(
+			"The pipeline signs in with federated credentials, skipping updating the credentials of the pipeline."))
+		a.flags.noPipelineUpdate = true
+	}
+
+	if err := publishCredentials(
+		ctx, a.pipeline, &a.flags.authSpPipelineFlags, a.formatter, a.writer, servicePrincipal.ObjectId, credentials,
+	); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("The secret of service principal %s was rotated.", servicePrincipal.DisplayName),
+		},
+	}, nil
+}
+
+// authSpCredentialsResult is the JSON output of azd auth sp create and rotate
+type authSpCredentialsResult struct {
+	ObjectId string `json:"objectId"`
+	// The credentials in the `AZURE_CREDENTIALS` format, only set when the pipeline isn't updated
+	Credentials json.RawMessage `json:"credentials,omitempty"`
+}
+
+// publishCredentials updates the credentials of the pipeline, or, when the pipeline isn't updated, writes the
+// credentials to the output since the new secret can't be retrieved again.
+func publishCredentials(
+	ctx context.Context,
+	spPipeline *authSpPipeline,
+	flags *authSpPipelineFlags,
+	formatter output.Formatter,
+	writer io.Writer,
+	principalId string,
+	credentials json.RawMessage,
+) error {
+	result := authSpCredentialsResult{
+		ObjectId: principalId,
+	}
+
+	if flags.noPipelineUpdate {
+		result.Credentials = credentials
+	} else if err := spPipeline.update(ctx, flags, credentials); err != nil {
+		return err
+	}
+
+	if formatter.Kind().IsStructured() {
+		if err := formatter.Format(result, writer, nil); err != nil {
+			return fmt.Errorf("credentials could not be displayed: %w", err)
+		}
+
+		return nil
+	}
+
+	if result.Credentials != nil {
+		if _, err := fmt.Fprintf(writer, "%s\n", string(result.Credentials)); err != nil {
+			return fmt.Errorf("credentials could not be displayed: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -20,9 +20,10 @@ import (
 
 type buildFlags struct {
 	*envFlag
-	all    bool
-	global *internal.GlobalCommandOptions
-	only   bool
+	all     bool
+	global  *internal.GlobalCommandOptions
+	only    bool
+	noCache bool
 }
 
 func newBuildFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *buildFlags {
@@ -45,6 +46,12 @@ func (bf *buildFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		false,
 		"Deploys all services that are listed in "+azdcontext.ProjectFileName,
 	)
+	local.BoolVar(
+		&bf.noCache,
+		"no-cache",
+		false,
+		"Restores and builds the services even when their dependencies and source didn't change since their last build.",
+	)
 }
 
 func newBuildCmd() *cobra.Command {
@@ -54,6 +61,7 @@ func newBuildCmd() *cobra.Command {
 		Hidden: true,
 	}
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
 	return cmd
 }
 
@@ -63,6 +71,7 @@ type buildAction struct {
 	projectConfig            *project.ProjectConfig
 	projectManager           project.ProjectManager
 	serviceManager           project.ServiceManager
+	buildCache               *project.BuildCache
 	console                  input.Console
 	formatter                output.Formatter
 	writer                   io.Writer
@@ -76,6 +85,7 @@ func newBuildAction(
 	projectConfig *project.ProjectConfig,
 	projectManager project.ProjectManager,
 	serviceManager project.ServiceManager,
+	buildCache *project.BuildCache,
 	console input.Console,
 	formatter output.Formatter,
 	writer io.Writer,
@@ -89,6 +99,7 @@ func newBuildAction(
 		projectConfig:            projectConfig,
 		projectManager:           projectManager,
 		serviceManager:           serviceManager,
+		buildCache:               buildCache,
 		console:                  console,
 		formatter:                formatter,
 		writer:                   writer,
@@ -103,6 +114,10 @@ type BuildResult struct {
 }
 
 func (ba *buildAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if ba.flags.noCache {
+		ba.buildCache.Disable()
+	}
+
 	if !ba.flags.only {
 		restoreAction, err := ba.restoreActionInitializer()
 		restoreAction.flags.all = ba.flags.all
@@ -187,7 +202,7 @@ func (ba *buildAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		ba.console.MessageUxItem(ctx, buildResult)
 	}
 
-	if ba.formatter.Kind() == output.JsonFormat {
+	if ba.formatter.Kind().IsStructured() {
 		buildResult := BuildResult{
 			Timestamp: time.Now(),
 			Services:  buildResults,
@@ -58,8 +58,9 @@ func (cb *CobraBuilder) BuildCommand(descriptor *actions.ActionDescriptor) (*cob
 		}
 	}
 
-	// Configure action resolver for leaf commands
-	if !cmd.HasSubCommands() {
+	// Configure action resolver for leaf commands, and for the commands with sub commands which run an action of their
+	// own, ex) azd show and azd show graph
+	if !cmd.HasSubCommands() || descriptor.Options.ActionResolver != nil {
 		if err := cb.configureActionResolver(cmd, descriptor); err != nil {
 			return nil, err
 		}
@@ -241,6 +242,13 @@ func (cb *CobraBuilder) bindCommand(cmd *cobra.Command, descriptor *actions.Acti
 		}
 	}
 
+	// All commands accepting an environment complete the names of the environments of the current project
+	if _, has := descriptor.FlagCompletions()[environmentNameFlag]; !has && cmd.Flags().Lookup(environmentNameFlag) != nil {
+		if err := cmd.RegisterFlagCompletionFunc(environmentNameFlag, environmentNameCompletion); err != nil {
+			return fmt.Errorf("failed registering flag completion function for '%s', %w", environmentNameFlag, err)
+		}
+	}
+
 	// Bind the child commands for the current descriptor
 	for _, childDescriptor := range descriptor.Children() {
 		childCmd := childDescriptor.Options.Command
@@ -176,6 +176,41 @@ func Test_BuildAndRunActionWithNestedAndConditionalMiddleware(t *testing.T) {
 	require.False(t, middlewareBRan)
 }
 
+func Test_BuildAndRunActionWithSubCommands(t *testing.T) {
+	container := ioc.NewNestedContainer(nil)
+	setup(container)
+
+	childRan := false
+	root := actions.NewActionDescriptor("root", &actions.ActionDescriptorOptions{
+		ActionResolver: newTestAction,
+		FlagsResolver:  newTestFlags,
+	})
+	root.Add("child", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			RunE: func(cmd *cobra.Command, args []string) error {
+				childRan = true
+				return nil
+			},
+		},
+	})
+
+	builder := NewCobraBuilder(container)
+	cmd, err := builder.BuildCommand(root)
+	require.NoError(t, err)
+
+	// The command with sub commands runs its own action
+	actionRan := false
+	ctx := context.WithValue(context.Background(), actionName, &actionRan)
+	cmd.SetArgs([]string{"-r"})
+	require.NoError(t, cmd.ExecuteContext(ctx))
+	require.True(t, actionRan)
+	require.False(t, childRan)
+
+	cmd.SetArgs([]string{"child"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.True(t, childRan)
+}
+
 func Test_BuildCommandsWithAutomaticHelpAndOutputFlags(t *testing.T) {
 	container := ioc.NewNestedContainer(nil)
 
@@ -206,7 +241,11 @@ func Test_BuildCommandsWithAutomaticHelpAndOutputFlags(t *testing.T) {
 	require.NotNil(t, outputFlag)
 	require.Equal(t, "output", outputFlag.Name)
 	require.Equal(t, "o", outputFlag.Shorthand)
-	require.Equal(t, "The output format (the supported formats are json, table).", outputFlag.Usage)
+	require.Equal(
+		t,
+		"The output format (the supported formats are json, yaml, go-template=<template>, go-template-file=<path>, table).",
+		outputFlag.Usage,
+	)
 }
 
 func setup(container *ioc.NestedContainer) {
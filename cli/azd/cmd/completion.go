@@ -0,0 +1,238 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/pkg/tunnel"
+	"github.com/azure/azure-dev/cli/azd/pkg/workspace"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The default amount of time lists fetched for completion are cached for
+const completionCacheTTL = 1 * time.Hour
+
+// completionCache caches lists used for shell completion on disk so remote lists don't need to be fetched every time
+// the user requests completions.
+type completionCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// The on-disk representation of a cached list of completion values
+type completionCacheEntry struct {
+	ExpiresOn time.Time `json:"expiresOn"`
+	Values    []string  `json:"values"`
+}
+
+var completionCacheKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9\-_]+$`)
+
+// newCompletionCache creates a completion cache stored within the azd user configuration directory
+func newCompletionCache() (*completionCache, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &completionCache{
+		dir: filepath.Join(configDir, "cache", "completion"),
+		ttl: completionCacheTTL,
+	}, nil
+}
+
+// Get returns the cached values for the key, calling load and caching the result when the values are missing or expired.
+// Failing to read or write the cache is not fatal, values are loaded directly instead.
+func (c *completionCache) Get(key string, load func() ([]string, error)) ([]string, error) {
+	if !completionCacheKeyRegex.MatchString(key) {
+		return nil, fmt.Errorf("invalid completion cache key '%s'", key)
+	}
+
+	cachePath := filepath.Join(c.dir, fmt.Sprintf("%s.json", key))
+
+	if contents, err := os.ReadFile(cachePath); err == nil {
+		var entry completionCacheEntry
+		if err := json.Unmarshal(contents, &entry); err == nil && time.Now().Before(entry.ExpiresOn) {
+			return entry.Values, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Printf("failed reading completion cache '%s': %v", cachePath, err)
+	}
+
+	values, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := json.Marshal(completionCacheEntry{
+		ExpiresOn: time.Now().Add(c.ttl),
+		Values:    values,
+	})
+	if err == nil {
+		if err := os.MkdirAll(c.dir, osutil.PermissionDirectory); err == nil {
+			err = os.WriteFile(cachePath, contents, osutil.PermissionFile)
+		}
+	}
+
+	if err != nil {
+		log.Printf("failed writing completion cache '%s': %v", cachePath, err)
+	}
+
+	return values, nil
+}
+
+// completionAzdContext finds the azd project for the command being completed, honoring the --cwd and --workspace flags.
+func completionAzdContext(cmd *cobra.Command) (*azdcontext.AzdContext, error) {
+	if cwd, err := cmd.Flags().GetString("cwd"); err == nil && cwd != "" {
+		if err := os.Chdir(cwd); err != nil {
+			return nil, fmt.Errorf("changing current working directory: %w", err)
+		}
+	}
+
+	if name, err := cmd.Flags().GetString("workspace"); err == nil && name != "" {
+		ws, err := workspace.NewManager(config.NewUserConfigManager()).Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return azdcontext.NewAzdContextWithDirectory(ws.Path), nil
+	}
+
+	return azdcontext.NewAzdContext()
+}
+
+// environmentNameCompletion completes the names of the environments of the current project
+func environmentNameCompletion(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	azdCtx, err := completionAzdContext(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	envs, err := azdCtx.ListEnvironments()
+	if err != nil {
+		cobra.CompError(fmt.Sprintf("Error listing environments: %s", err))
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	envNames := make([]string, len(envs))
+	for i, env := range envs {
+		envNames[i] = env.Name
+	}
+
+	return envNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+// environmentNameArgCompletion completes the environment name for commands accepting it as the only positional argument
+func environmentNameArgCompletion(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return environmentNameCompletion(cmd, args, toComplete)
+}
+
+// serviceNameCompletion completes the names of the services defined in azure.yaml for commands accepting a service name
+// as the only positional argument
+func serviceNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	azdCtx, err := completionAzdContext(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// The context is not set when the completion function is invoked outside of command execution
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	projectConfig, err := project.LoadFs(ctx, azdCtx.Fs(), azdCtx.ProjectPath())
+	if err != nil {
+		cobra.CompError(fmt.Sprintf("Error loading project: %s", err))
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	serviceNames := maps.Keys(projectConfig.Services)
+	slices.Sort(serviceNames)
+
+	return serviceNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+// templateNameCompletion completes the repository paths of the curated templates, including the friendly name of the
+// template as the description
+func templateNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	loadTemplates := func() ([]string, error) {
+		templateManager := templates.NewTemplateManager()
+		templates, err := templateManager.ListTemplates()
+		if err != nil {
+			return nil, err
+		}
+
+		templateNames := make([]string, len(templates))
+		for i, v := range templates {
+			templateNames[i] = fmt.Sprintf("%s\t%s", v.RepositoryPath, v.Name)
+		}
+
+		return templateNames, nil
+	}
+
+	var templateNames []string
+	cache, err := newCompletionCache()
+	if err == nil {
+		templateNames, err = cache.Get("templates", loadTemplates)
+	} else {
+		templateNames, err = loadTemplates()
+	}
+
+	if err != nil {
+		cobra.CompError(fmt.Sprintf("Error listing templates: %s", err))
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return templateNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+// templateNameArgCompletion completes the template for commands accepting it as the only positional argument
+func templateNameArgCompletion(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return templateNameCompletion(cmd, args, toComplete)
+}
+
+// tunnelTargetCompletion completes the data services azd tunnel opens tunnels to
+func tunnelTargetCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return tunnel.TargetNames(), cobra.ShellCompDirectiveNoFileComp
+}
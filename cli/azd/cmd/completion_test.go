@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCache(t *testing.T) {
+	cache := &completionCache{
+		dir: t.TempDir(),
+		ttl: time.Hour,
+	}
+
+	loads := 0
+	load := func() ([]string, error) {
+		loads++
+		return []string{"a", "b"}, nil
+	}
+
+	values, err := cache.Get("values", load)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, values)
+
+	// Cached values are returned without loading again
+	values, err = cache.Get("values", load)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, values)
+	require.Equal(t, 1, loads)
+
+	// Expired values are loaded again
+	cache.ttl = -time.Minute
+	_, err = cache.Get("expired", load)
+	require.NoError(t, err)
+	_, err = cache.Get("expired", load)
+	require.NoError(t, err)
+	require.Equal(t, 3, loads)
+
+	_, err = cache.Get("values", func() ([]string, error) {
+		return nil, errors.New("should not be called")
+	})
+	require.NoError(t, err)
+
+	_, err = cache.Get("../outside", load)
+	require.Error(t, err)
+}
+
+func TestProjectCompletions(t *testing.T) {
+	dir := t.TempDir()
+	ostest.Chdir(t, dir)
+
+	azureYaml := `
+name: test-proj
+services:
+  web:
+    project: src/web
+    language: js
+    host: appservice
+  api:
+    project: src/api
+    language: js
+    host: appservice
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte(azureYaml), osutil.PermissionFile))
+	for _, envName := range []string{"dev", "prod"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".azure", envName), osutil.PermissionDirectory))
+	}
+
+	cmd := &cobra.Command{}
+
+	services, directive := serviceNameCompletion(cmd, []string{}, "")
+	require.Equal(t, []string{"api", "web"}, services)
+	require.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	// Only a single service can be specified
+	services, _ = serviceNameCompletion(cmd, []string{"api"}, "")
+	require.Empty(t, services)
+
+	envs, directive := environmentNameCompletion(cmd, []string{}, "")
+	require.Equal(t, []string{"dev", "prod"}, envs)
+	require.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
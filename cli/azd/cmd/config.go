@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -13,9 +14,12 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var userConfigPath string
@@ -152,6 +156,22 @@ $ azd config set defaults.location eastus`,
 		ActionResolver: newConfigListAlphaAction,
 	})
 
+	group.Add("resolve", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "resolve [<service>]",
+			Short: "Display the configuration azd resolves for the project and its services, with the source of each value.",
+			Long: "Display the configuration azd resolves for the project and its services in the environment, merged from " +
+				"azure.yaml, the environment, the user configuration and the defaults of azd. The source of each value " +
+				"tells why azd picks a cluster, a registry or a namespace.",
+			Args:              cobra.MaximumNArgs(1),
+			ValidArgsFunction: serviceNameCompletion,
+		},
+		ActionResolver: newConfigResolveAction,
+		FlagsResolver:  newConfigResolveFlags,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat},
+		DefaultFormat:  output.TableFormat,
+	})
+
 	return group
 }
 
@@ -182,7 +202,7 @@ func (a *configListAction) Run(ctx context.Context) (*actions.ActionResult, erro
 
 	values := azdConfig.Raw()
 
-	if a.formatter.Kind() == output.JsonFormat {
+	if a.formatter.Kind().IsStructured() {
 		err := a.formatter.Format(values, a.writer, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failing formatting config values: %w", err)
@@ -192,6 +212,112 @@ func (a *configListAction) Run(ctx context.Context) (*actions.ActionResult, erro
 	return nil, nil
 }
 
+// azd config resolve [<service>]
+
+type configResolveFlags struct {
+	envFlag
+}
+
+func (f *configResolveFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+}
+
+func newConfigResolveFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *configResolveFlags {
+	flags := &configResolveFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+type configResolveAction struct {
+	flags         *configResolveFlags
+	args          []string
+	projectConfig *project.ProjectConfig
+	env           *environment.Environment
+	configManager config.UserConfigManager
+	formatter     output.Formatter
+	writer        io.Writer
+}
+
+func newConfigResolveAction(
+	flags *configResolveFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	env *environment.Environment,
+	configManager config.UserConfigManager,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &configResolveAction{
+		flags:         flags,
+		args:          args,
+		projectConfig: projectConfig,
+		env:           env,
+		configManager: configManager,
+		formatter:     formatter,
+		writer:        writer,
+	}
+}
+
+// Executes the `azd config resolve [<service>]` action
+func (a *configResolveAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if len(a.args) == 1 && !a.projectConfig.HasService(a.args[0]) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", a.args[0])
+	}
+
+	azdConfig, err := a.configManager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := project.NewConfigResolver(a.projectConfig, a.env, azdConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []project.ResolvedValue{a.resolveEnvironmentName()}
+	values = append(values, resolver.ResolveProject()...)
+	for _, serviceConfig := range a.projectConfig.GetServicesStable() {
+		if len(a.args) == 0 || serviceConfig.Name == a.args[0] {
+			values = append(values, resolver.ResolveService(serviceConfig)...)
+		}
+	}
+
+	if a.formatter.Kind() == output.TableFormat {
+		return nil, a.formatter.Format(values, a.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{Heading: "SCOPE", ValueTemplate: "{{.Scope}}"},
+				{Heading: "NAME", ValueTemplate: "{{.Name}}"},
+				{Heading: "VALUE", ValueTemplate: "{{.Value}}"},
+				{Heading: "SOURCE", ValueTemplate: "{{.Source}}"},
+			},
+		})
+	}
+
+	return nil, a.formatter.Format(values, a.writer, nil)
+}
+
+// resolveEnvironmentName resolves the environment the way the commands of azd do: the --environment flag, which
+// defaults to AZURE_ENV_NAME, or the default environment of the project
+func (a *configResolveAction) resolveEnvironmentName() project.ResolvedValue {
+	value := project.ResolvedValue{
+		Scope: "project",
+		Name:  "environment",
+		Value: a.env.GetEnvName(),
+	}
+
+	switch {
+	case a.flags.environmentName == "":
+		value.Source = "default environment of the project"
+	case a.flags.environmentName == os.Getenv(environment.EnvNameEnvVarName):
+		value.Source = fmt.Sprintf("environment variable %s", environment.EnvNameEnvVarName)
+	default:
+		value.Source = fmt.Sprintf("flag --%s", environmentNameFlag)
+	}
+
+	return value
+}
+
 // azd config get <path>
 
 type configGetAction struct {
@@ -229,7 +355,7 @@ func (a *configGetAction) Run(ctx context.Context) (*actions.ActionResult, error
 		return nil, fmt.Errorf("no value stored at path '%s'", key)
 	}
 
-	if a.formatter.Kind() == output.JsonFormat {
+	if a.formatter.Kind().IsStructured() {
 		err := a.formatter.Format(value, a.writer, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failing formatting config values: %w", err)
@@ -346,6 +472,9 @@ func getCmdConfigHelpFooter(c *cobra.Command) string {
 		"Set the default Azure deployment location.": fmt.Sprintf("%s %s",
 			output.WithHighLightFormat("azd config set defaults.location"),
 			output.WithWarningFormat("<location>")),
+		"Set how long deploy waits for the rollout of AKS services.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd config set deploy.waits.aks.timeout"),
+			output.WithWarningFormat("<duration, ex) 20m>")),
 	})
 }
 
@@ -10,10 +10,15 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/repository"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/alerts"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/appimport"
+	"github.com/azure/azure-dev/cli/azd/pkg/appinsights"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/chaos"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
+	"github.com/azure/azure-dev/cli/azd/pkg/diagnostics"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
@@ -24,10 +29,15 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/loadtest"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/permissions"
 	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/policy"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/pkg/rbac"
+	"github.com/azure/azure-dev/cli/azd/pkg/remote"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
@@ -37,11 +47,18 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubelogin"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/opa"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/python"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/swa"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/terraform"
+	"github.com/azure/azure-dev/cli/azd/pkg/tunnel"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/azure/azure-dev/cli/azd/pkg/workspace"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -95,7 +112,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		cmd *cobra.Command) input.Console {
 		writer := cmd.OutOrStdout()
 		// When using JSON formatting, we want to ensure we always write messages from the console to stderr.
-		if formatter != nil && formatter.Kind() == output.JsonFormat {
+		if formatter != nil && formatter.Kind().IsStructured() {
 			writer = cmd.ErrOrStderr()
 		}
 
@@ -169,6 +186,15 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		})
 	})
 
+	// The file system of the project, or of the OS outside of a project
+	container.RegisterSingleton(func(lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext]) vfs.Fs {
+		if azdCtx, err := lazyAzdContext.GetValue(); err == nil {
+			return azdCtx.Fs()
+		}
+
+		return vfs.OsFs()
+	})
+
 	// Register an initialized environment based on the specified environment flag, or the default environment.
 	// Note that referencing an *environment.Environment in a command automatically triggers a UI prompt if the
 	// environment is uninitialized or a default environment doesn't yet exist.
@@ -232,16 +258,40 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 
 	// Project Config
 	container.RegisterSingleton(
-		func(ctx context.Context, azdContext *azdcontext.AzdContext) (*project.ProjectConfig, error) {
+		func(
+			ctx context.Context,
+			serviceLocator ioc.ServiceLocator,
+			azdContext *azdcontext.AzdContext,
+			appHostImporter *project.AppHostImporter,
+		) (*project.ProjectConfig, error) {
 			if azdContext == nil {
 				return nil, azdcontext.ErrNoProject
 			}
 
-			projectConfig, err := project.Load(ctx, azdContext.ProjectPath())
+			// The overrides of azure.yaml are resolved against the environment of the command, or the default environment
+			environmentName := ""
+			var cmd *cobra.Command
+			if err := serviceLocator.Resolve(&cmd); err == nil && cmd.Flags().Lookup(environmentNameFlag) != nil {
+				environmentName, _ = cmd.Flags().GetString(environmentNameFlag)
+			}
+
+			if environmentName == "" {
+				var err error
+				if environmentName, err = azdContext.GetDefaultEnvironmentName(); err != nil {
+					return nil, err
+				}
+			}
+
+			projectConfig, err := project.LoadFsForEnvironment(ctx, azdContext.Fs(), azdContext.ProjectPath(), environmentName)
 			if err != nil {
 				return nil, err
 			}
 
+			// Services which are .NET Aspire app hosts are replaced by the services of their resources
+			if err := appHostImporter.Import(ctx, projectConfig); err != nil {
+				return nil, err
+			}
+
 			return projectConfig, nil
 		},
 	)
@@ -262,20 +312,72 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	})
 
 	container.RegisterSingleton(project.NewResourceManager)
+	container.RegisterSingleton(project.NewAppHostImporter)
+	container.RegisterSingleton(tunnel.NewManager)
+	container.RegisterSingleton(workspace.NewManager)
+	container.RegisterSingleton(remote.NewRunner)
+	container.RegisterSingleton(remote.NewWorker)
+	container.RegisterSingleton(rbac.NewManager)
+	container.RegisterSingleton(diagnostics.NewManager)
+	container.RegisterSingleton(appinsights.NewAnnotator)
+	container.RegisterSingleton(alerts.NewManager)
+	container.RegisterSingleton(loadtest.NewManager)
+	container.RegisterSingleton(chaos.NewManager)
+	container.RegisterSingleton(permissions.NewManager)
 	container.RegisterSingleton(project.NewProjectManager)
 	container.RegisterSingleton(project.NewServiceManager)
+	container.RegisterSingleton(project.NewBuildCache)
+	container.RegisterSingleton(project.NewArtifactStore)
 	container.RegisterSingleton(repository.NewInitializer)
+	container.RegisterSingleton(appimport.NewInspector)
 	container.RegisterSingleton(config.NewUserConfigManager)
 	container.RegisterSingleton(alpha.NewFeaturesManager)
 	container.RegisterSingleton(config.NewManager)
+	container.RegisterSingleton(update.NewManager)
 	container.RegisterSingleton(templates.NewTemplateManager)
 	container.RegisterSingleton(auth.NewManager)
+	// Credentials are resolved through the auth profile bound to the environment, when there is one. Commands which
+	// don't take an environment flag resolve the profile of the default environment.
+	container.RegisterSingleton(func(
+		serviceLocator ioc.ServiceLocator,
+		lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext],
+	) auth.ProfileResolver {
+		return func() string {
+			azdCtx, err := lazyAzdContext.GetValue()
+			if err != nil {
+				return ""
+			}
+
+			environmentName := ""
+			var cmd *cobra.Command
+			if err := serviceLocator.Resolve(&cmd); err == nil {
+				environmentName, _ = cmd.Flags().GetString(environmentNameFlag)
+			}
+
+			if environmentName == "" {
+				if environmentName, err = azdCtx.GetDefaultEnvironmentName(); err != nil || environmentName == "" {
+					return ""
+				}
+			}
+
+			env, err := environment.GetEnvironment(azdCtx, environmentName)
+			if err != nil {
+				return ""
+			}
+
+			profile, _ := env.Config.Get(auth.ProfileConfigPath)
+			name, _ := profile.(string)
+			return name
+		}
+	})
 	container.RegisterSingleton(azcli.NewUserProfileService)
 	container.RegisterSingleton(account.NewSubscriptionsService)
 	container.RegisterSingleton(account.NewManager)
 	container.RegisterSingleton(account.NewSubscriptionsManager)
 	container.RegisterSingleton(account.NewSubscriptionCredentialProvider)
 	container.RegisterSingleton(azcli.NewManagedClustersService)
+	container.RegisterSingleton(azcli.NewManagedIdentityService)
+	container.RegisterSingleton(azcli.NewLoadTestingService)
 	container.RegisterSingleton(azcli.NewContainerRegistryService)
 	container.RegisterSingleton(containerapps.NewContainerAppService)
 	container.RegisterSingleton(project.NewContainerHelper)
@@ -306,20 +408,26 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.RegisterSingleton(github.NewGitHubCli)
 	container.RegisterSingleton(javac.NewCli)
 	container.RegisterSingleton(kubectl.NewKubectl)
+	container.RegisterSingleton(kubelogin.NewKubelogin)
 	container.RegisterSingleton(maven.NewMavenCli)
 	container.RegisterSingleton(npm.NewNpmCli)
+	container.RegisterSingleton(opa.NewOpaCli)
 	container.RegisterSingleton(python.NewPythonCli)
+	container.RegisterSingleton(ssh.NewSshCli)
 	container.RegisterSingleton(swa.NewSwaCli)
 	container.RegisterSingleton(terraform.NewTerraformCli)
 
 	// Provisioning
 	container.RegisterTransient(provisioning.NewManager)
 	container.RegisterSingleton(provisioning.NewPrincipalIdProvider)
+	container.RegisterSingleton(provisioning.NewEnvironmentHydrator)
 	container.RegisterSingleton(prompt.NewDefaultPrompter)
+	container.RegisterSingleton(policy.NewEngine)
 
 	// Provisioning Providers
 	provisionProviderMap := map[provisioning.ProviderKind]any{
 		provisioning.Bicep:     infraBicep.NewBicepProvider,
+		provisioning.Arm:       infraBicep.NewArmProvider,
 		provisioning.Terraform: infraTerraform.NewTerraformProvider,
 	}
 
@@ -331,6 +439,14 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 
 	// Other
 	container.RegisterSingleton(createClock)
+	container.RegisterSingleton(func(userConfigManager config.UserConfigManager) (*project.ServiceTargetWaits, error) {
+		userConfig, err := userConfigManager.Load()
+		if err != nil {
+			return nil, fmt.Errorf("loading user config: %w", err)
+		}
+
+		return project.NewServiceTargetWaits(userConfig)
+	})
 
 	// Service Targets
 	serviceTargetMap := map[project.ServiceTargetKind]any{
@@ -359,6 +475,8 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		project.ServiceLanguageJavaScript: project.NewNpmProject,
 		project.ServiceLanguageTypeScript: project.NewNpmProject,
 		project.ServiceLanguageJava:       project.NewMavenProject,
+		project.ServiceLanguageGo:         project.NewGoProject,
+		project.ServiceLanguageCustom:     project.NewCustomProject,
 		project.ServiceLanguageDocker:     project.NewDockerProject,
 	}
 
@@ -368,6 +486,32 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		}
 	}
 
+	if err := container.RegisterNamedSingleton(project.AppHostFramework, project.NewAppHostProject); err != nil {
+		panic(fmt.Errorf("registering framework service %s: %w", project.AppHostFramework, err))
+	}
+
+	// Package managers
+	container.RegisterSingleton(project.NewDependencyRestorers)
+	dependencyRestorerMap := map[project.PackageManagerKind]any{
+		project.PackageManagerNpm:    project.NewNpmRestorer,
+		project.PackageManagerPnpm:   project.NewPnpmRestorer,
+		project.PackageManagerYarn:   project.NewYarnRestorer,
+		project.PackageManagerBun:    project.NewBunRestorer,
+		project.PackageManagerPip:    project.NewPipRestorer,
+		project.PackageManagerPoetry: project.NewPoetryRestorer,
+		project.PackageManagerUv:     project.NewUvRestorer,
+		project.PackageManagerDotNet: project.NewDotNetRestorer,
+		project.PackageManagerMaven:  project.NewMavenRestorer,
+		project.PackageManagerGradle: project.NewGradleRestorer,
+		project.PackageManagerGo:     project.NewGoRestorer,
+	}
+
+	for packageManager, constructor := range dependencyRestorerMap {
+		if err := container.RegisterNamedSingleton(string(packageManager), constructor); err != nil {
+			panic(fmt.Errorf("registering package manager %s: %w", packageManager, err))
+		}
+	}
+
 	// Pipelines
 	container.RegisterSingleton(pipeline.NewPipelineManager)
 	container.RegisterSingleton(func(flags *pipelineConfigFlags) *pipeline.PipelineManagerArgs {
@@ -8,30 +8,54 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/tracing/resource"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/appinsights"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/chaos"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/github"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/loadtest"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
 )
 
 type deployFlags struct {
-	serviceName string
-	all         bool
-	fromPackage string
-	global      *internal.GlobalCommandOptions
+	serviceName     string
+	all             bool
+	fromPackage     string
+	fromArtifact    string
+	uploadArtifacts string
+	breakLock       bool
+	tag             string
+	only            []string
+	skip            []string
+	noLoadTest      bool
+	noChaos         bool
+	noCache         bool
+	matrix          environmentMatrixFlags
+	global          *internal.GlobalCommandOptions
 	*envFlag
 }
 
@@ -52,6 +76,51 @@ func (d *deployFlags) bindNonCommon(
 	)
 	//deprecate:flag hide --service
 	_ = local.MarkHidden("service")
+	local.StringVar(
+		&d.tag,
+		"tag",
+		"",
+		"Tags the container images of the services with the tag, instead of the tag configured in "+
+			azdcontext.ProjectFileName+".",
+	)
+	local.StringSliceVar(
+		&d.only,
+		"only",
+		nil,
+		"Deploys only the given services, ex) --only api,web.",
+	)
+	local.StringSliceVar(
+		&d.skip,
+		"skip",
+		nil,
+		"Deploys all services except the given services, ex) --skip worker.",
+	)
+	local.BoolVar(
+		&d.noLoadTest,
+		"no-load-test",
+		false,
+		"Skips the load test configured in "+azdcontext.ProjectFileName+" after the services are deployed.",
+	)
+	local.BoolVar(
+		&d.noChaos,
+		"no-chaos",
+		false,
+		"Skips the chaos experiments configured in "+azdcontext.ProjectFileName+" after the services are deployed.",
+	)
+	local.BoolVar(
+		&d.noCache,
+		"no-cache",
+		false,
+		"Restores and builds the services even when their dependencies and source didn't change since their last build.",
+	)
+	local.StringVar(
+		&d.uploadArtifacts,
+		"upload-artifacts",
+		"",
+		"Uploads the package of each deployed service to the blob container of the url, "+
+			"ex) https://<account>.blob.core.windows.net/<container>.",
+	)
+	d.matrix.Bind(local)
 	d.global = global
 }
 
@@ -71,6 +140,19 @@ func (d *deployFlags) bindCommon(local *pflag.FlagSet, global *internal.GlobalCo
 		"",
 		"Deploys the application from an existing package.",
 	)
+	local.StringVar(
+		&d.fromArtifact,
+		"from-artifact",
+		"",
+		"Deploys the service from the artifact of the url, uploaded by the deploy of another environment "+
+			"with --upload-artifacts.",
+	)
+	local.BoolVar(
+		&d.breakLock,
+		"break-lock",
+		false,
+		"Removes the lock of the environment held by another azd process before deploying.",
+	)
 }
 
 func (d *deployFlags) setCommon(envFlag *envFlag) {
@@ -90,6 +172,7 @@ func newDeployCmd() *cobra.Command {
 		Short: "Deploy the application's code to Azure.",
 	}
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
 
 	return cmd
 }
@@ -103,6 +186,9 @@ type deployAction struct {
 	projectManager           project.ProjectManager
 	serviceManager           project.ServiceManager
 	resourceManager          project.ResourceManager
+	containerHelper          *project.ContainerHelper
+	buildCache               *project.BuildCache
+	artifactStore            *project.ArtifactStore
 	accountManager           account.Manager
 	azCli                    azcli.AzCli
 	formatter                output.Formatter
@@ -112,6 +198,13 @@ type deployAction struct {
 	middlewareRunner         middleware.MiddlewareContext
 	packageActionInitializer actions.ActionInitializer[*packageAction]
 	alphaFeatureManager      *alpha.FeatureManager
+	annotator                *appinsights.Annotator
+	loadTestManager          *loadtest.Manager
+	chaosManager             *chaos.Manager
+	// The services deployed by the last run, summarized by azd up
+	deployedServices []ux.DeployedService
+	// The comment of the pull request reporting the progress of azd up, when enabled with --progress-comment
+	progressComment *pipeline.ProgressComment
 }
 
 func newDeployAction(
@@ -121,6 +214,9 @@ func newDeployAction(
 	projectManager project.ProjectManager,
 	serviceManager project.ServiceManager,
 	resourceManager project.ResourceManager,
+	containerHelper *project.ContainerHelper,
+	buildCache *project.BuildCache,
+	artifactStore *project.ArtifactStore,
 	azdCtx *azdcontext.AzdContext,
 	environment *environment.Environment,
 	accountManager account.Manager,
@@ -132,6 +228,9 @@ func newDeployAction(
 	middlewareRunner middleware.MiddlewareContext,
 	packageActionInitializer actions.ActionInitializer[*packageAction],
 	alphaFeatureManager *alpha.FeatureManager,
+	annotator *appinsights.Annotator,
+	loadTestManager *loadtest.Manager,
+	chaosManager *chaos.Manager,
 ) actions.Action {
 	return &deployAction{
 		flags:                    flags,
@@ -142,6 +241,9 @@ func newDeployAction(
 		projectManager:           projectManager,
 		serviceManager:           serviceManager,
 		resourceManager:          resourceManager,
+		containerHelper:          containerHelper,
+		buildCache:               buildCache,
+		artifactStore:            artifactStore,
 		accountManager:           accountManager,
 		azCli:                    azCli,
 		formatter:                formatter,
@@ -151,15 +253,25 @@ func newDeployAction(
 		middlewareRunner:         middlewareRunner,
 		packageActionInitializer: packageActionInitializer,
 		alphaFeatureManager:      alphaFeatureManager,
+		annotator:                annotator,
+		loadTestManager:          loadTestManager,
+		chaosManager:             chaosManager,
 	}
 }
 
 type DeploymentResult struct {
 	Timestamp time.Time                               `json:"timestamp"`
 	Services  map[string]*project.ServiceDeployResult `json:"services"`
+	// The result of the load test run after the services are deployed, when configured
+	LoadTest *loadtest.Result `json:"loadTest,omitempty"`
+	// The outcomes of the chaos experiments run after the services are deployed, when configured
+	Chaos *chaos.Result `json:"chaos,omitempty"`
 }
 
-func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+// deployCleanupTimeout is how long the cleanup of an interrupted deploy of a service can take
+const deployCleanupTimeout = 5 * time.Minute
+
+func (da *deployAction) Run(ctx context.Context) (result *actions.ActionResult, err error) {
 	targetServiceName := da.flags.serviceName
 	if len(da.args) == 1 {
 		targetServiceName = da.args[0]
@@ -167,42 +279,112 @@ func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	serviceNameWarningCheck(da.console, da.flags.serviceName, "deploy")
 
+	if da.flags.matrix.enabled() {
+		matrix := &environmentMatrix{
+			flags:         &da.flags.matrix,
+			azdCtx:        da.azdCtx,
+			commandRunner: da.commandRunner,
+			console:       da.console,
+			formatter:     da.formatter,
+			writer:        da.writer,
+		}
+
+		return matrix.run(ctx, "deploy", da.args)
+	}
+
 	if da.env.GetSubscriptionId() == "" {
 		return nil, errors.New(
 			"infrastructure has not been provisioned. Run `azd provision`",
 		)
 	}
 
-	targetServiceName, err := getTargetServiceName(
+	filtered := len(da.flags.only) > 0 || len(da.flags.skip) > 0
+	if err := da.validateServiceFilters(targetServiceName); err != nil {
+		return nil, err
+	}
+
+	targetServiceName, err = getTargetServiceName(
 		ctx,
 		da.projectManager,
 		da.projectConfig,
 		string(project.ServiceEventDeploy),
 		targetServiceName,
-		da.flags.all,
+		da.flags.all || filtered,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if da.flags.all && da.flags.fromPackage != "" {
-		return nil, errors.New(
-			"'--from-package' cannot be specified when '--all' is set. Specify a specific service by passing a <service>")
+	// The flag deploying the service from a package built before, if any
+	fromFlag := ""
+	if da.flags.fromPackage != "" {
+		fromFlag = "--from-package"
 	}
 
-	if targetServiceName == "" && da.flags.fromPackage != "" {
-		return nil, errors.New(
-			//nolint:lll
-			"'--from-package' cannot be specified when deploying all services. Specify a specific service by passing a <service>",
+	if da.flags.fromArtifact != "" {
+		if fromFlag != "" {
+			return nil, errors.New("'--from-package' and '--from-artifact' cannot be specified together")
+		}
+
+		fromFlag = "--from-artifact"
+	}
+
+	if da.flags.all && fromFlag != "" {
+		return nil, fmt.Errorf(
+			"'%s' cannot be specified when '--all' is set. Specify a specific service by passing a <service>", fromFlag)
+	}
+
+	if targetServiceName == "" && fromFlag != "" {
+		return nil, fmt.Errorf(
+			"'%s' cannot be specified when deploying all services. Specify a specific service by passing a <service>",
+			fromFlag,
 		)
 	}
 
+	for _, artifactsUrl := range []string{da.flags.fromArtifact, da.flags.uploadArtifacts} {
+		if artifactsUrl != "" {
+			if err := project.ValidateArtifactsUrl(artifactsUrl); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if da.flags.tag != "" {
+		if fromFlag != "" {
+			return nil, fmt.Errorf("'--tag' cannot be specified when '%s' is set, the package is deployed as is", fromFlag)
+		}
+
+		if err := da.containerHelper.SetImageTag(da.flags.tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if da.flags.noCache {
+		da.buildCache.Disable()
+	}
+
+	lock, err := da.env.Lock("deploy", da.flags.breakLock)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// Cancelling the deploy, ex) with Ctrl+C, stops the deploy of the current service. The changes it left half applied
+	// are cleaned up and the interrupted deploy is recorded in the deployment history of the environment.
+	ctx, stop := notifyInterrupt(ctx)
+	defer stop()
+
+	record := &environment.DeploymentRecord{StartedAt: time.Now()}
+	defer func() {
+		da.recordDeployment(ctx, record, err)
+	}()
+
 	if err := da.projectManager.Initialize(ctx, da.projectConfig); err != nil {
 		return nil, err
 	}
 
 	if err := da.projectManager.EnsureServiceTargetTools(ctx, da.projectConfig, func(svc *project.ServiceConfig) bool {
-		return targetServiceName == "" || svc.Name == targetServiceName
+		return svc.IsDeployed() && da.isTargetService(targetServiceName, svc)
 	}); err != nil {
 		return nil, err
 	}
@@ -216,13 +398,40 @@ func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	deployResults := map[string]*project.ServiceDeployResult{}
 
-	for _, svc := range da.projectConfig.GetServicesStable() {
+	// When running in GitHub Actions, the deployments are reported to the environments of the repository
+	ghDeployments, err := github.NewDeploymentsClientFromEnv(nil)
+	if err != nil {
+		log.Printf("skipping GitHub deployments: %v", err)
+	}
+
+	// Services are deployed after the services they use, for the outputs of those to be available to them
+	services, err := da.projectConfig.GetServicesDeployOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	if da.progressComment != nil {
+		names := []string{}
+		for _, svc := range services {
+			if svc.IsDeployed() && da.isTargetService(targetServiceName, svc) {
+				names = append(names, svc.Name)
+			}
+		}
+		da.progressComment.SetServices(ctx, names)
+	}
+
+	for _, svc := range services {
 		stepMessage := fmt.Sprintf("Deploying service %s", svc.Name)
 
-		// Skip this service if both cases are true:
-		// 1. The user specified a service name
-		// 2. This service is not the one the user specified
-		if targetServiceName != "" && targetServiceName != svc.Name {
+		// Skip this service when the user specified a service name, or --only/--skip, which excludes it
+		if !da.isTargetService(targetServiceName, svc) {
+			continue
+		}
+
+		// Services with deploy: false only define infrastructure
+		if !svc.IsDeployed() {
+			da.console.ShowSpinner(ctx, stepMessage, input.Step)
+			da.console.StopSpinner(ctx, stepMessage, input.StepSkipped)
 			continue
 		}
 
@@ -232,55 +441,76 @@ func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			da.console.WarnForFeature(ctx, alphaFeatureId)
 		}
 
-		da.console.ShowSpinner(ctx, stepMessage, input.Step)
-		var packageResult *project.ServicePackageResult
-		if da.flags.fromPackage != "" {
-			// --from-package set, skip packaging
-			packageResult = &project.ServicePackageResult{
-				PackagePath: da.flags.fromPackage,
-			}
-		} else {
-			//  --from-package not set, package the application
-			packageTask := da.serviceManager.Package(ctx, svc, nil)
-			go func() {
-				for packageProgress := range packageTask.Progress() {
-					progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, packageProgress.Message)
-					da.console.ShowSpinner(ctx, progressMessage, input.Step)
-				}
-			}()
-
-			packageResult, err = packageTask.Await()
-			if err != nil {
-				da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
-				return nil, err
-			}
-		}
-
-		deployTask := da.serviceManager.Deploy(ctx, svc, packageResult)
-		go func() {
-			for deployProgress := range deployTask.Progress() {
-				progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, deployProgress.Message)
-				da.console.ShowSpinner(ctx, progressMessage, input.Step)
-			}
-		}()
+		record.Services = append(record.Services, environment.ServiceDeploymentRecord{Name: svc.Name})
+		serviceRecord := &record.Services[len(record.Services)-1]
 
-		deployResult, err := deployTask.Await()
+		da.console.ShowSpinner(ctx, stepMessage, input.Step)
+		serviceStartTime := time.Now()
+		ghDeployment := startGitHubDeployment(ctx, ghDeployments, da.env.GetEnvName(), svc.Name)
+		da.progressComment.SetService(ctx, svc.Name, pipeline.ProgressInProgress, "")
+		deployResult, packageResult, err := da.deployService(ctx, svc, stepMessage, ghDeployment, serviceRecord)
 		if err != nil {
-			da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
 			return nil, err
 		}
 
+		serviceRecord.Status = environment.DeploymentStatusSucceeded
+
+		ghDeployment.setState(ctx, github.DeploymentStateSuccess, environmentUrl(deployResult.Endpoints))
+		da.progressComment.SetService(ctx, svc.Name, pipeline.ProgressSucceeded, environmentUrl(deployResult.Endpoints))
+
 		da.console.StopSpinner(ctx, stepMessage, input.StepDone)
+		version := da.serviceVersion(svc, packageResult)
 		deployResults[svc.Name] = deployResult
+		da.deployedServices = append(da.deployedServices, ux.DeployedService{
+			Name:      svc.Name,
+			Host:      string(svc.Host),
+			Version:   version,
+			Endpoints: deployResult.Endpoints,
+			Duration:  since(serviceStartTime),
+		})
+
+		if svc.ReleaseAnnotation.Annotates(resource.IsRunningOnCI()) {
+			da.annotateRelease(ctx, svc, version)
+		}
 
 		// report deploy outputs
 		da.console.MessageUxItem(ctx, deployResult)
 	}
 
-	if da.formatter.Kind() == output.JsonFormat {
+	da.secureRegistryCredentials(ctx)
+
+	if err := pipeline.PublishOutputs(
+		da.env, da.projectConfig.Pipeline.Outputs, da.console.Handles().Stdout); err != nil {
+		return nil, fmt.Errorf("publishing pipeline outputs: %w", err)
+	}
+
+	var loadTestResult *loadtest.Result
+	if da.projectConfig.LoadTest != nil && !da.flags.noLoadTest && len(deployResults) > 0 {
+		loadTestResult, err = da.runLoadTest(ctx, deployResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var chaosResult *chaos.Result
+	if da.projectConfig.Chaos != nil && !da.flags.noChaos && len(deployResults) > 0 &&
+		(loadTestResult == nil || loadTestResult.Passed()) {
+		if da.projectConfig.Chaos.RunsIn(da.env.GetEnvName()) {
+			chaosResult, err = da.runChaosExperiments(ctx)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			log.Printf("skipping chaos experiments, they don't run in environment '%s'", da.env.GetEnvName())
+		}
+	}
+
+	if da.formatter.Kind().IsStructured() {
 		deployResult := DeploymentResult{
 			Timestamp: time.Now(),
 			Services:  deployResults,
+			LoadTest:  loadTestResult,
+			Chaos:     chaosResult,
 		}
 
 		if fmtErr := da.formatter.Format(deployResult, da.writer, nil); fmtErr != nil {
@@ -288,6 +518,17 @@ func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		}
 	}
 
+	// A failed load test fails the deployment, so that it gates the promotion of the deployment in CI
+	if loadTestResult != nil && !loadTestResult.Passed() {
+		return nil, fmt.Errorf(
+			"load test %s failed (status: %s, result: %s), see %s",
+			loadTestResult.TestRunId, loadTestResult.Status, loadTestResult.TestResult, loadTestResult.PortalUrl)
+	}
+
+	if chaosResult != nil && !chaosResult.Succeeded() {
+		return nil, errors.New("chaos experiments failed, see the executions of the experiments in Chaos Studio")
+	}
+
 	return &actions.ActionResult{
 		Message: &actions.ResultMessage{
 			Header:   fmt.Sprintf("Your application was deployed to Azure in %s.", ux.DurationAsText(since(startTime))),
@@ -296,6 +537,457 @@ func (da *deployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	}, nil
 }
 
+// deployService packages the service, or downloads its package from --from-artifact, deploys it and uploads its artifact
+// with --upload-artifacts. Once it fails, the step of the service is failed and the error is recorded, then the changes
+// of a deploy interrupted half way are cleaned up. The temporary files of the service are removed once it returns.
+func (da *deployAction) deployService(
+	ctx context.Context,
+	svc *project.ServiceConfig,
+	stepMessage string,
+	ghDeployment *gitHubDeployment,
+	serviceRecord *environment.ServiceDeploymentRecord,
+) (*project.ServiceDeployResult, *project.ServicePackageResult, error) {
+	fail := func(err error) error {
+		da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+		ghDeployment.setState(ctx, github.DeploymentStateFailure, "")
+		da.progressComment.SetService(ctx, svc.Name, pipeline.ProgressFailed, "")
+		setServiceDeploymentError(ctx, serviceRecord, err)
+		return err
+	}
+
+	var packageResult *project.ServicePackageResult
+	if da.flags.fromPackage != "" {
+		// --from-package set, skip packaging
+		packageResult = &project.ServicePackageResult{
+			PackagePath: da.flags.fromPackage,
+		}
+	} else if da.flags.fromArtifact != "" {
+		// --from-artifact set, deploy the package of the artifact
+		serviceRecord.Artifact = da.flags.fromArtifact
+		da.console.ShowSpinner(ctx, fmt.Sprintf("Deploying service %s (Downloading artifact)", svc.Name), input.Step)
+
+		artifactDir, err := os.MkdirTemp("", "azdartifact")
+		if err != nil {
+			return nil, nil, fail(err)
+		}
+		defer os.RemoveAll(artifactDir)
+
+		packageResult, err = da.artifactStore.Download(ctx, da.flags.fromArtifact, svc, artifactDir)
+		if err != nil {
+			return nil, nil, fail(err)
+		}
+	} else {
+		//  --from-package not set, package the application
+		packageTask := da.serviceManager.Package(ctx, svc, nil)
+		go func() {
+			for packageProgress := range packageTask.Progress() {
+				progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, packageProgress.Message)
+				da.console.ShowSpinner(ctx, progressMessage, input.Step)
+			}
+		}()
+
+		var err error
+		packageResult, err = packageTask.Await()
+		if err != nil {
+			return nil, nil, fail(err)
+		}
+	}
+
+	// Some hosts delete the package once deployed, the artifact keeps a copy to upload once the service is deployed
+	var stagedArtifact *project.StagedArtifact
+	if da.flags.uploadArtifacts != "" {
+		var err error
+		stagedArtifact, err = da.artifactStore.Stage(svc, packageResult)
+		if err != nil {
+			return nil, nil, fail(err)
+		}
+		defer stagedArtifact.Close()
+	}
+
+	deployTask := da.serviceManager.Deploy(ctx, svc, packageResult)
+	go func() {
+		for deployProgress := range deployTask.Progress() {
+			progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, deployProgress.Message)
+			da.console.ShowSpinner(ctx, progressMessage, input.Step)
+		}
+	}()
+
+	deployResult, err := deployTask.Await()
+	if err != nil {
+		err = fail(err)
+		if ctx.Err() != nil {
+			serviceRecord.CleanedUp = da.cleanupDeploy(ctx, svc)
+		}
+
+		return nil, nil, err
+	}
+
+	if stagedArtifact != nil {
+		da.console.ShowSpinner(ctx, fmt.Sprintf("Deploying service %s (Uploading artifact)", svc.Name), input.Step)
+		artifactUrl, err := da.artifactStore.Upload(ctx, da.flags.uploadArtifacts, stagedArtifact)
+		if err != nil {
+			return nil, nil, fail(fmt.Errorf("uploading artifact of service '%s': %w", svc.Name, err))
+		}
+
+		deployResult.Artifact = artifactUrl
+		serviceRecord.Artifact = artifactUrl
+	}
+
+	return deployResult, packageResult, nil
+}
+
+// secureRegistryCredentials warns about the container registries azd used the credentials of their admin user for, with
+// how to use tokens of the signed-in principal instead, and rotates the passwords of the admin users after deploying in
+// CI when enabled with AZD_ROTATE_REGISTRY_ADMIN_PASSWORD. The deploy succeeded, failures to rotate are warnings.
+func (da *deployAction) secureRegistryCredentials(ctx context.Context) {
+	uses := da.containerHelper.AdminCredentialsUses()
+	if len(uses) == 0 {
+		return
+	}
+
+	for _, use := range uses {
+		registryName, _, _ := strings.Cut(use.LoginServer, ".")
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The credentials of the admin user of container registry %s were used, as the signed-in principal "+
+				"couldn't get a token of the registry. Admin credentials are shared by all the clients of the registry "+
+				"and don't expire. Grant the AcrPush role on the registry to the principal deploying, and the AcrPull "+
+				"role to the services, then disable the admin user with 'az acr update --name %s --admin-enabled false'.",
+			use.LoginServer, registryName))
+	}
+
+	rotate, _ := strconv.ParseBool(da.env.Getenv(project.RotateRegistryAdminPasswordEnvVarName))
+	if !rotate || !resource.IsRunningOnCI() {
+		return
+	}
+
+	for _, use := range uses {
+		// The image pull secrets of the services keep using the password, they'd fail pulling the images once rotated
+		if use.Pull {
+			da.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: The admin password of container registry %s isn't rotated, the image pull secrets of the "+
+					"services use it.", use.LoginServer))
+			continue
+		}
+
+		stepMessage := fmt.Sprintf("Rotating the admin password of container registry %s", use.LoginServer)
+		da.console.ShowSpinner(ctx, stepMessage, input.Step)
+		err := da.containerHelper.RotateAdminPassword(ctx, use)
+		da.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			da.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: The admin password of container registry %s could not be rotated: %v", use.LoginServer, err))
+		}
+	}
+}
+
+// notifyInterrupt returns a context done on the first interrupt, ex) Ctrl+C. The default handler of interrupts is restored
+// as soon as the context is done, so another interrupt exits azd without waiting, ex) for the deploy of the current
+// service to stop or for its cleanup.
+func notifyInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return ctx, stop
+}
+
+// cleanupDeploy runs the cleanup of the service target of the service once the deploy of the service was interrupted,
+// and returns whether the changes the deploy left half applied were cleaned up
+func (da *deployAction) cleanupDeploy(ctx context.Context, serviceConfig *project.ServiceConfig) bool {
+	ctx, cancel := context.WithTimeout(uncancelledContext{ctx}, deployCleanupTimeout)
+	defer cancel()
+
+	serviceTarget, err := da.serviceManager.GetServiceTarget(ctx, serviceConfig)
+	if err != nil {
+		log.Printf("skipping cleanup of service '%s': %v", serviceConfig.Name, err)
+		return false
+	}
+
+	cleanupTarget, ok := serviceTarget.(project.ServiceCleanupTarget)
+	if !ok {
+		return false
+	}
+
+	stepMessage := fmt.Sprintf("Cleaning up the interrupted deploy of service %s", serviceConfig.Name)
+	da.console.ShowSpinner(ctx, stepMessage, input.Step)
+
+	targetResource, err := da.resourceManager.GetTargetResource(ctx, da.env.GetSubscriptionId(), serviceConfig)
+	if err == nil {
+		err = cleanupTarget.CleanupDeploy(ctx, serviceConfig, targetResource)
+	}
+
+	da.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The interrupted deploy of service %s could not be cleaned up: %v", serviceConfig.Name, err))
+		return false
+	}
+
+	return true
+}
+
+// recordDeployment records the deploy in the deployment history of the environment. Failing to record the deploy is
+// logged but doesn't fail the deploy.
+func (da *deployAction) recordDeployment(ctx context.Context, record *environment.DeploymentRecord, err error) {
+	record.EndedAt = time.Now()
+
+	switch {
+	case err == nil:
+		record.Status = environment.DeploymentStatusSucceeded
+	case ctx.Err() != nil:
+		record.Status = environment.DeploymentStatusInterrupted
+	default:
+		record.Status = environment.DeploymentStatusFailed
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if err := da.env.RecordDeployment(*record); err != nil {
+		log.Printf("failed recording deployment: %v", err)
+	}
+}
+
+// setServiceDeploymentError sets the status of the deploy of the service, interrupted when the deploy was cancelled
+func setServiceDeploymentError(ctx context.Context, serviceRecord *environment.ServiceDeploymentRecord, err error) {
+	serviceRecord.Status = environment.DeploymentStatusFailed
+	if ctx.Err() != nil {
+		serviceRecord.Status = environment.DeploymentStatusInterrupted
+	}
+
+	serviceRecord.Error = err.Error()
+}
+
+// uncancelledContext has the values of its parent context but is never cancelled, like context.WithoutCancel
+type uncancelledContext struct {
+	context.Context
+}
+
+func (uncancelledContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (uncancelledContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (uncancelledContext) Err() error {
+	return nil
+}
+
+// validateServiceFilters validates the services of --only and --skip exist, and that they aren't combined with each
+// other or with a <service>
+func (da *deployAction) validateServiceFilters(targetServiceName string) error {
+	if len(da.flags.only) > 0 && len(da.flags.skip) > 0 {
+		return errors.New("'--only' and '--skip' cannot be specified together")
+	}
+
+	if (len(da.flags.only) > 0 || len(da.flags.skip) > 0) && (targetServiceName != "" || da.flags.all) {
+		return errors.New("'--only' and '--skip' cannot be specified with <service> or '--all'")
+	}
+
+	for _, name := range append(da.flags.only, da.flags.skip...) {
+		if !da.projectConfig.HasService(name) {
+			return fmt.Errorf("service name '%s' doesn't exist", name)
+		}
+	}
+
+	return nil
+}
+
+// isTargetService reports whether the service is deployed, given the <service> and the --only and --skip flags
+func (da *deployAction) isTargetService(targetServiceName string, svc *project.ServiceConfig) bool {
+	switch {
+	case targetServiceName != "":
+		return svc.Name == targetServiceName
+	case len(da.flags.only) > 0:
+		return slices.Contains(da.flags.only, svc.Name)
+	case len(da.flags.skip) > 0:
+		return !slices.Contains(da.flags.skip, svc.Name)
+	default:
+		return true
+	}
+}
+
+// gitHubDeployment is the GitHub deployment of a service, reported when azd runs in GitHub Actions
+type gitHubDeployment struct {
+	client      *github.DeploymentsClient
+	id          int64
+	environment string
+}
+
+// startGitHubDeployment creates an in progress GitHub deployment of the service to the <env>-<service> environment of
+// the repository. Returns nil without a client or when the deployment couldn't be created, failing to report a
+// deployment doesn't fail the deployment of the service.
+func startGitHubDeployment(
+	ctx context.Context,
+	client *github.DeploymentsClient,
+	envName string,
+	serviceName string,
+) *gitHubDeployment {
+	if client == nil {
+		return nil
+	}
+
+	environment := fmt.Sprintf("%s-%s", envName, serviceName)
+	id, err := client.CreateDeployment(ctx, environment, fmt.Sprintf("Deploying service %s with azd", serviceName))
+	if err != nil {
+		log.Printf("failed creating GitHub deployment of service '%s': %v", serviceName, err)
+		return nil
+	}
+
+	deployment := &gitHubDeployment{client: client, id: id, environment: environment}
+	deployment.setState(ctx, github.DeploymentStateInProgress, "")
+
+	return deployment
+}
+
+func (d *gitHubDeployment) setState(ctx context.Context, state github.DeploymentState, environmentUrl string) {
+	if d == nil {
+		return
+	}
+
+	if err := d.client.CreateDeploymentStatus(ctx, d.id, d.environment, state, environmentUrl); err != nil {
+		log.Printf("failed setting state of GitHub deployment to '%s': %v", state, err)
+	}
+}
+
+// environmentUrl returns the url of the first endpoint of the service, endpoints can be followed by a description, ex)
+// "http://10.0.0.1, (Service, Type: LoadBalancer)"
+func environmentUrl(endpoints []string) string {
+	if len(endpoints) == 0 {
+		return ""
+	}
+
+	fields := strings.Fields(endpoints[0])
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.TrimSuffix(fields[0], ",")
+}
+
+// annotateRelease creates the release annotation of the deployment of the service on the Application Insights component
+// of the environment. Failing to annotate the release doesn't fail the deployment of the service.
+func (da *deployAction) annotateRelease(ctx context.Context, svc *project.ServiceConfig, version string) {
+	annotated, err := da.annotator.Annotate(ctx, svc, version)
+	if err != nil {
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The release of service %s was not annotated on Application Insights: %v", svc.Name, err))
+		return
+	}
+
+	if annotated {
+		log.Printf("annotated the release of service '%s' on Application Insights", svc.Name)
+	}
+}
+
+// runLoadTest runs the load test of the project against the endpoints of the deployed services, and summarizes its
+// results in the console
+func (da *deployAction) runLoadTest(
+	ctx context.Context,
+	deployResults map[string]*project.ServiceDeployResult,
+) (*loadtest.Result, error) {
+	endpoints := map[string]string{}
+	for name, deployResult := range deployResults {
+		if endpoint := environmentUrl(deployResult.Endpoints); endpoint != "" {
+			endpoints[name] = endpoint
+		}
+	}
+
+	stepMessage := fmt.Sprintf("Running load test %s", da.projectConfig.LoadTest.TestPlan)
+	da.console.ShowSpinner(ctx, stepMessage, input.Step)
+	result, err := da.loadTestManager.Run(ctx, da.projectConfig, endpoints)
+	if err != nil {
+		da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+		return nil, fmt.Errorf("running load test: %w", err)
+	}
+
+	if result.Passed() {
+		da.console.StopSpinner(ctx, stepMessage, input.StepDone)
+	} else {
+		da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+	}
+
+	if stats := result.Statistics; stats != nil {
+		da.console.Message(ctx, fmt.Sprintf(
+			"  Requests: %.0f, errors: %.2f%%, response time: %.0f ms on average, %.0f ms at the 90th percentile, "+
+				"throughput: %.2f/s",
+			stats.SampleCount, stats.ErrorPct, stats.MeanResTime, stats.Pct90ResTime, stats.Throughput))
+	}
+
+	for _, criterion := range result.Criteria {
+		actual := "n/a"
+		if criterion.ActualValue != nil {
+			actual = fmt.Sprintf("%g", *criterion.ActualValue)
+		}
+
+		message := fmt.Sprintf("  %s: %s (actual: %s)", criterion.Criterion, criterion.Result, actual)
+		if criterion.Result == "failed" {
+			message = output.WithErrorFormat(message)
+		}
+		da.console.Message(ctx, message)
+	}
+
+	for _, message := range result.Errors {
+		da.console.Message(ctx, output.WithErrorFormat("  %s", message))
+	}
+
+	if result.PortalUrl != "" {
+		da.console.Message(ctx, fmt.Sprintf("  Results: %s", output.WithLinkFormat(result.PortalUrl)))
+	}
+
+	return result, nil
+}
+
+// runChaosExperiments runs the chaos experiments of the project against the environment, and reports their outcomes in
+// the console
+func (da *deployAction) runChaosExperiments(ctx context.Context) (*chaos.Result, error) {
+	stepMessage := "Running chaos experiments"
+	da.console.ShowSpinner(ctx, stepMessage, input.Step)
+	result, err := da.chaosManager.Run(ctx, da.projectConfig, func(experiment string) {
+		da.console.ShowSpinner(ctx, fmt.Sprintf("%s (%s)", stepMessage, experiment), input.Step)
+	})
+	if err != nil {
+		da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+		return nil, fmt.Errorf("running chaos experiments: %w", err)
+	}
+
+	if result.Succeeded() {
+		da.console.StopSpinner(ctx, stepMessage, input.StepDone)
+	} else {
+		da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+	}
+
+	for _, experiment := range result.Experiments {
+		message := fmt.Sprintf(
+			"  %s: %s (%s)", experiment.Name, experiment.Status, ux.DurationAsText(experiment.Duration()))
+		if !experiment.Succeeded() {
+			message = output.WithErrorFormat(message)
+		}
+		da.console.Message(ctx, message)
+	}
+
+	return result, nil
+}
+
+// serviceVersion returns the deployed version of the service, the container image for services deployed as containers
+// and the package otherwise
+func (da *deployAction) serviceVersion(svc *project.ServiceConfig, packageResult *project.ServicePackageResult) string {
+	if imageName := da.env.GetServiceProperty(svc.Name, "IMAGE_NAME"); imageName != "" {
+		return imageName
+	}
+
+	if packageResult != nil && packageResult.PackagePath != "" {
+		return filepath.Base(packageResult.PackagePath)
+	}
+
+	return ""
+}
+
 func getCmdDeployHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription("Deploy application to Azure.", []string{
 		formatHelpNote(
@@ -303,8 +995,62 @@ func getCmdDeployHelpDescription(*cobra.Command) string {
 				" or the service described in the project that matches the current directory."),
 		formatHelpNote(
 			fmt.Sprintf("When %s is set, only the specific service is deployed.", output.WithHighLightFormat("<service>"))),
+		formatHelpNote(fmt.Sprintf(
+			"Services with %s in 'azure.yaml' only define infrastructure, and are not deployed.",
+			output.WithHighLightFormat("deploy: false"))),
+		formatHelpNote(fmt.Sprintf(
+			"Services are deployed after the services they list in %s, whose endpoints are stored in the environment"+
+				" as %s for them to reference.",
+			output.WithHighLightFormat("uses"),
+			output.WithHighLightFormat("SERVICE_<NAME>_ENDPOINT_URL"),
+		)),
 		formatHelpNote("After the deployment is complete, the endpoint is printed. To start the service, select" +
 			" the endpoint or paste it in a browser."),
+		formatHelpNote(fmt.Sprintf(
+			"In GitHub Actions, with %s set, each service is reported as a deployment to the %s environment"+
+				" of the repository.",
+			output.WithHighLightFormat("GITHUB_TOKEN"),
+			output.WithHighLightFormat("<environment>-<service>"),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"Each deployed service is annotated as a release on the Application Insights component of the environment,"+
+				" unless disabled with %s in 'azure.yaml'.",
+			output.WithHighLightFormat("releaseAnnotation"),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"With %s in 'azure.yaml', the load test runs on Azure Load Testing once the services are deployed,"+
+				" and fails the deployment when it fails its criteria. Skip it with %s.",
+			output.WithHighLightFormat("loadTest"),
+			output.WithHighLightFormat("--no-load-test"),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"With %s in 'azure.yaml', the Chaos Studio experiments run against the environments they're enabled in"+
+				" once the services are deployed. Skip them with %s.",
+			output.WithHighLightFormat("chaos"),
+			output.WithHighLightFormat("--no-chaos"),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"With %s or %s, the services are deployed to several environments concurrently, each by its own azd "+
+				"process, and a summary of the outcome of each environment is displayed once done. Limit the "+
+				"environments deployed at a time with %s.",
+			output.WithHighLightFormat("--environments"),
+			output.WithHighLightFormat("--all-environments"),
+			output.WithHighLightFormat("--max-parallel"),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"The requests to ARM are limited per subscription, by default to %g reads and %g writes per second, "+
+				"split among the environments deployed at a time. Set the limits with %s and %s.",
+			azsdk.DefaultRateLimits.ReadsPerSecond,
+			azsdk.DefaultRateLimits.WritesPerSecond,
+			output.WithHighLightFormat(azsdk.ArmReadsPerSecondEnvVarName),
+			output.WithHighLightFormat(azsdk.ArmWritesPerSecondEnvVarName),
+		)),
+		formatHelpNote(fmt.Sprintf(
+			"With %s, the package of each deployed service is uploaded to the blob container with the environment,"+
+				" build and commit. Deploy exactly that package to another environment with %s.",
+			output.WithHighLightFormat("--upload-artifacts"),
+			output.WithHighLightFormat("--from-artifact"),
+		)),
 	})
 }
 
@@ -322,5 +1068,13 @@ func getCmdDeployHelpFooter(*cobra.Command) string {
 		"Deploy the service named 'api' to Azure from a previously generated package.": output.WithHighLightFormat(
 			"azd deploy api --from-package <package-path>",
 		),
+		"Deploy all services except the service named 'worker' to Azure.": output.WithHighLightFormat(
+			"azd deploy --skip worker",
+		),
+		"Deploy the service named 'api' to Azure from the artifact uploaded by the deploy of another environment.": output.
+			WithHighLightFormat("azd deploy api --from-artifact <artifact-url>"),
+		"Deploy all services to the dev and staging environments concurrently.": output.WithHighLightFormat(
+			"azd deploy --environments dev,staging",
+		),
 	})
 }
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EnvironmentUrl(t *testing.T) {
+	tests := map[string]struct {
+		endpoints []string
+		expected  string
+	}{
+		"NoEndpoints": {endpoints: nil, expected: ""},
+		"Url":         {endpoints: []string{"https://api.contoso.com/"}, expected: "https://api.contoso.com/"},
+		"Described": {
+			endpoints: []string{"http://10.0.0.1, (Service, Type: LoadBalancer)", "http://10.0.0.2"},
+			expected:  "http://10.0.0.1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, environmentUrl(test.endpoints))
+		})
+	}
+}
+
+func Test_DeployServiceFilters(t *testing.T) {
+	projectConfig := &project.ProjectConfig{
+		Services: map[string]*project.ServiceConfig{
+			"api":    {Name: "api"},
+			"web":    {Name: "web"},
+			"worker": {Name: "worker"},
+		},
+	}
+
+	deployed := func(action *deployAction, targetServiceName string) []string {
+		names := []string{}
+		for _, svc := range projectConfig.GetServicesStable() {
+			if action.isTargetService(targetServiceName, svc) {
+				names = append(names, svc.Name)
+			}
+		}
+		return names
+	}
+
+	t.Run("Only", func(t *testing.T) {
+		action := &deployAction{flags: &deployFlags{only: []string{"api", "web"}}, projectConfig: projectConfig}
+		require.NoError(t, action.validateServiceFilters(""))
+		require.Equal(t, []string{"api", "web"}, deployed(action, ""))
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		action := &deployAction{flags: &deployFlags{skip: []string{"worker"}}, projectConfig: projectConfig}
+		require.NoError(t, action.validateServiceFilters(""))
+		require.Equal(t, []string{"api", "web"}, deployed(action, ""))
+	})
+
+	t.Run("Service", func(t *testing.T) {
+		action := &deployAction{flags: &deployFlags{}, projectConfig: projectConfig}
+		require.NoError(t, action.validateServiceFilters("web"))
+		require.Equal(t, []string{"web"}, deployed(action, "web"))
+		require.Equal(t, []string{"api", "web", "worker"}, deployed(action, ""))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		action := &deployAction{
+			flags: &deployFlags{only: []string{"api"}, skip: []string{"web"}}, projectConfig: projectConfig}
+		require.ErrorContains(t, action.validateServiceFilters(""), "cannot be specified together")
+
+		action = &deployAction{flags: &deployFlags{only: []string{"api"}}, projectConfig: projectConfig}
+		require.ErrorContains(t, action.validateServiceFilters("web"), "cannot be specified with <service>")
+
+		action = &deployAction{flags: &deployFlags{skip: []string{"missing"}}, projectConfig: projectConfig}
+		require.ErrorContains(t, action.validateServiceFilters(""), "service name 'missing' doesn't exist")
+	})
+}
+
+func Test_RecordDeployment(t *testing.T) {
+	env := environment.EmptyWithRoot(t.TempDir())
+	action := &deployAction{env: env}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	record := &environment.DeploymentRecord{
+		Services: []environment.ServiceDeploymentRecord{{Name: "api"}},
+	}
+
+	action.recordDeployment(ctx, record, errors.New("deploy failed"))
+
+	cancel()
+	setServiceDeploymentError(ctx, &record.Services[0], ctx.Err())
+	action.recordDeployment(ctx, record, ctx.Err())
+
+	history, err := env.DeploymentHistory()
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, environment.DeploymentStatusFailed, history[0].Status)
+	require.Equal(t, "deploy failed", history[0].Error)
+	require.Equal(t, environment.DeploymentStatusInterrupted, history[1].Status)
+	require.Equal(t, environment.DeploymentStatusInterrupted, history[1].Services[0].Status)
+	require.Equal(t, "context canceled", history[1].Services[0].Error)
+}
+
+func Test_UncancelledContext(t *testing.T) {
+	type key struct{}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	cancel()
+
+	uncancelled := uncancelledContext{ctx}
+	require.NoError(t, uncancelled.Err())
+	require.Nil(t, uncancelled.Done())
+	require.Equal(t, "value", uncancelled.Value(key{}))
+}
+
+func Test_NotifyInterrupt(t *testing.T) {
+	// The process interrupted by the test
+	if os.Getenv("AZD_TEST_NOTIFY_INTERRUPT") == "1" {
+		ctx, stop := notifyInterrupt(context.Background())
+		defer stop()
+
+		fmt.Println("ready")
+		<-ctx.Done()
+		fmt.Println("interrupted")
+
+		// A long cleanup of the interrupted deploy, which another interrupt doesn't wait for
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupts can't be sent to processes on Windows")
+	}
+
+	cmd := osexec.Command(os.Args[0], "-test.run=^Test_NotifyInterrupt$")
+	cmd.Env = append(os.Environ(), "AZD_TEST_NOTIFY_INTERRUPT=1")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	lines := bufio.NewScanner(stdout)
+	require.True(t, lines.Scan())
+	require.Equal(t, "ready", lines.Text())
+
+	require.NoError(t, cmd.Process.Signal(os.Interrupt))
+	require.True(t, lines.Scan())
+	require.Equal(t, "interrupted", lines.Text())
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	// The default handler is restored right after the first interrupt, the interrupts are sent until it is
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case err := <-exited:
+			var exitErr *osexec.ExitError
+			require.ErrorAs(t, err, &exitErr)
+			require.True(t, exitErr.Sys().(syscall.WaitStatus).Signaled())
+			return
+		case <-time.After(100 * time.Millisecond):
+			_ = cmd.Process.Signal(os.Interrupt)
+		case <-timeout:
+			_ = cmd.Process.Kill()
+			require.Fail(t, "the second interrupt didn't exit the process")
+		}
+	}
+}
+
+func Test_DeployService_ArtifactDirError(t *testing.T) {
+	// The temporary directory of the artifact can't be created
+	missingDir := filepath.Join(t.TempDir(), "missing")
+	for _, name := range []string{"TMPDIR", "TMP", "TEMP"} {
+		t.Setenv(name, missingDir)
+	}
+
+	console := mockinput.NewMockConsole()
+	action := &deployAction{flags: &deployFlags{fromArtifact: "v1"}, console: console}
+	serviceRecord := &environment.ServiceDeploymentRecord{Name: "api"}
+
+	_, _, err := action.deployService(
+		context.Background(), &project.ServiceConfig{Name: "api"}, "Deploying service api", nil, serviceRecord)
+	require.Error(t, err)
+
+	require.Equal(t, environment.DeploymentStatusFailed, serviceRecord.Status)
+	require.Equal(t, err.Error(), serviceRecord.Error)
+
+	spinnerOps := console.SpinnerOps()
+	require.Equal(t, mockinput.SpinnerOp{
+		Op:      mockinput.SpinnerOpStop,
+		Message: "Deploying service api",
+		Format:  input.StepFailed,
+	}, spinnerOps[len(spinnerOps)-1])
+}
@@ -0,0 +1,202 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/forwarding"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var devFeature = alpha.MustFeatureKey("dev")
+
+type devFlags struct {
+	forwardEvents bool
+	target        string
+	tunnelUrl     string
+	relayPort     int
+	envFlag
+}
+
+func (f *devFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.forwardEvents,
+		"forward-events",
+		false,
+		"Forward the events of the Service Bus and Event Grid topics of the environment to the local service.",
+	)
+	local.StringVar(
+		&f.target,
+		"target",
+		"http://localhost:8080/",
+		"The URL of the locally running service events are posted to.",
+	)
+	local.StringVar(
+		&f.tunnelUrl,
+		"tunnel-url",
+		"",
+		"The public URL of a tunnel to the relay port, required to forward Event Grid events.",
+	)
+	local.IntVar(
+		&f.relayPort,
+		"relay-port",
+		7071,
+		"The local port receiving the Event Grid events from the tunnel.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newDevFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *devFlags {
+	flags := &devFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newDevCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dev",
+		Short: fmt.Sprintf("Run the dev loop of your application against Azure. %s", output.WithWarningFormat("(Alpha)")),
+	}
+}
+
+type devAction struct {
+	flags               *devFlags
+	azCli               azcli.AzCli
+	env                 *environment.Environment
+	console             input.Console
+	httpClient          httputil.HttpClient
+	alphaFeatureManager *alpha.FeatureManager
+}
+
+func newDevAction(
+	flags *devFlags,
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	console input.Console,
+	httpClient httputil.HttpClient,
+	alphaFeatureManager *alpha.FeatureManager,
+) actions.Action {
+	return &devAction{
+		flags:               flags,
+		azCli:               azCli,
+		env:                 env,
+		console:             console,
+		httpClient:          httpClient,
+		alphaFeatureManager: alphaFeatureManager,
+	}
+}
+
+func (a *devAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if !a.alphaFeatureManager.IsEnabled(devFeature) {
+		return nil, fmt.Errorf(
+			"azd dev is currently in alpha and needs to be enabled explicitly. Run `%s` to enable the feature.",
+			alpha.GetEnableCommand(devFeature),
+		)
+	}
+
+	if !a.flags.forwardEvents {
+		return nil, errors.New("nothing to do, use --forward-events to forward events to the local service")
+	}
+
+	a.console.WarnForFeature(ctx, devFeature)
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title:     "Forwarding events to the local service (azd dev)",
+		TitleNote: "Temporary subscriptions are created for the topics of the environment and deleted on exit.",
+	})
+
+	subscriptionId := a.env.GetSubscriptionId()
+	resourceManager := infra.NewAzureResourceManager(a.azCli)
+	resourceGroups, err := resourceManager.GetResourceGroupsForEnvironment(ctx, subscriptionId, a.env.GetEnvName())
+	if err != nil {
+		return nil, fmt.Errorf("getting resource groups for environment: %w", err)
+	}
+
+	resources := []azcli.AzCliResource{}
+	for _, resourceGroup := range resourceGroups {
+		groupResources, err := a.azCli.ListResourceGroupResources(ctx, subscriptionId, resourceGroup.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing resources of resource group '%s': %w", resourceGroup.Name, err)
+		}
+
+		resources = append(resources, groupResources...)
+	}
+
+	forwarder := forwarding.NewForwarder(a.azCli, a.console, a.httpClient, subscriptionId, forwarding.Options{
+		TargetUrl: a.flags.target,
+		TunnelUrl: a.flags.tunnelUrl,
+		RelayPort: a.flags.relayPort,
+	})
+
+	sources, err := forwarder.Sources(ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf(
+			"no Service Bus or Event Grid topics found for environment '%s'", a.env.GetEnvName())
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	a.console.Message(ctx, output.WithGrayFormat("Press Ctrl+C to stop forwarding.\n"))
+	if err := forwarder.Run(ctx, sources); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: "Stopped forwarding events, the temporary subscriptions were deleted.",
+		},
+	}, nil
+}
+
+func getCmdDevHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Run the dev loop of your application against the Azure resources of the environment.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"%s creates temporary subscriptions to the Service Bus and Event Grid topics of the environment"+
+					" and posts their events to the service running locally at %s.",
+				output.WithHighLightFormat("--forward-events"),
+				output.WithHighLightFormat("--target"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Event Grid delivers events to webhooks, use %s to forward them through a tunnel to %s.",
+				output.WithHighLightFormat("--tunnel-url"),
+				output.WithHighLightFormat("--relay-port"),
+			)),
+		})
+}
+
+func getCmdDevHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Forward the events of the Service Bus topics to a service listening on port 3000.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd dev --forward-events --target"),
+			output.WithWarningFormat("http://localhost:3000/events"),
+		),
+		"Forward the Event Grid events received by a dev tunnel.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd dev --forward-events --tunnel-url"),
+			output.WithWarningFormat("https://<tunnel>.devtunnels.ms"),
+		),
+	})
+}
@@ -21,6 +21,7 @@ import (
 type downFlags struct {
 	forceDelete bool
 	purgeDelete bool
+	unlockCode  string
 	global      *internal.GlobalCommandOptions
 	envFlag
 }
@@ -34,6 +35,12 @@ func (i *downFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOpt
 		//nolint:lll
 		"Does not require confirmation before it permanently deletes resources that are soft-deleted by default (for example, key vaults).",
 	)
+	local.StringVar(
+		&i.unlockCode,
+		"unlock-code",
+		"",
+		"The unlock code configured for a protected environment, required to delete its resources.",
+	)
 	i.envFlag.Bind(local, global)
 	i.global = global
 }
@@ -90,7 +97,17 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		return nil, fmt.Errorf("initializing provisioning manager: %w", err)
 	}
 
-	destroyOptions := provisioning.NewDestroyOptions(a.flags.forceDelete, a.flags.purgeDelete)
+	protection, err := a.env.Protection()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.confirmProtectedDestroy(ctx, protection); err != nil {
+		return nil, err
+	}
+
+	destroyOptions := provisioning.NewDestroyOptions(a.flags.forceDelete, a.flags.purgeDelete).
+		WithExcludedResources(protection.ExcludedResources)
 	if _, err := a.provisionManager.Destroy(ctx, destroyOptions); err != nil {
 		return nil, fmt.Errorf("deleting infrastructure: %w", err)
 	}
@@ -102,6 +119,44 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// confirmProtectedDestroy requires the unlock code, when configured, and typing the name of a protected environment
+// before its resources are deleted. Typing the name is not required with --force and a configured unlock code, which
+// allows automation to delete protected environments.
+func (a *downAction) confirmProtectedDestroy(ctx context.Context, protection *environment.Protection) error {
+	if !protection.Protected {
+		return nil
+	}
+
+	envName := a.env.GetEnvName()
+	if protection.UnlockCode != "" {
+		if a.flags.unlockCode != protection.UnlockCode {
+			return fmt.Errorf(
+				"environment '%s' is protected, run again with the --unlock-code configured for the environment", envName)
+		}
+
+		if a.flags.forceDelete {
+			return nil
+		}
+	}
+
+	a.console.MessageUxItem(ctx, &ux.WarningMessage{
+		Description: fmt.Sprintf("Environment %s is protected.", envName),
+	})
+
+	typedName, err := a.console.Prompt(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf("Enter the name of the environment (%s) to confirm deleting its resources:", envName),
+	})
+	if err != nil {
+		return fmt.Errorf("prompting for the environment name: %w", err)
+	}
+
+	if typedName != envName {
+		return fmt.Errorf("the entered name does not match environment '%s', no resources were deleted", envName)
+	}
+
+	return nil
+}
+
 func getCmdDownHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Delete Azure resources for an application. Running %s will not delete application"+
@@ -115,5 +170,7 @@ func getCmdDownHelpFooter(*cobra.Command) string {
 		"Forcibly delete all applications resources without confirmation.": output.WithHighLightFormat("azd down --force"),
 		"Permanently delete resources that are soft-deleted by default," +
 			" without confirmation.": output.WithHighLightFormat("azd down --purge"),
+		"Delete all resources of a protected environment configured with an unlock code," +
+			" without confirmation.": output.WithHighLightFormat("azd down --force --unlock-code <code>"),
 	})
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
@@ -19,6 +20,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
@@ -41,6 +44,12 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		ActionResolver: newEnvSetAction,
 	})
 
+	group.Add("set-profile", &actions.ActionDescriptorOptions{
+		Command:        newEnvSetProfileCmd(),
+		FlagsResolver:  newEnvSetProfileFlags,
+		ActionResolver: newEnvSetProfileAction,
+	})
+
 	group.Add("select", &actions.ActionDescriptorOptions{
 		Command:        newEnvSelectCmd(),
 		ActionResolver: newEnvSelectAction,
@@ -75,6 +84,12 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		DefaultFormat:  output.EnvVarsFormat,
 	})
 
+	group.Add("encrypt", &actions.ActionDescriptorOptions{
+		Command:        newEnvEncryptCmd(),
+		FlagsResolver:  newEnvEncryptFlags,
+		ActionResolver: newEnvEncryptAction,
+	})
+
 	return group
 }
 
@@ -137,11 +152,94 @@ func (e *envSetAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	return nil, nil
 }
 
+func newEnvSetProfileFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envSetProfileFlags {
+	flags := &envSetProfileFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newEnvSetProfileCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-profile <profile>",
+		Short: "Bind the environment to an auth profile.",
+		Long: "Bind the environment to an auth profile, so azd uses the account the profile is logged in to " +
+			"(with `azd auth login --profile <profile>`) for the environment. Use the profile `default` to remove the binding.",
+		Args: cobra.ExactArgs(1),
+	}
+}
+
+type envSetProfileFlags struct {
+	envFlag
+	global *internal.GlobalCommandOptions
+}
+
+func (f *envSetProfileFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+	f.global = global
+}
+
+type envSetProfileAction struct {
+	authManager *auth.Manager
+	env         *environment.Environment
+	console     input.Console
+	args        []string
+}
+
+func newEnvSetProfileAction(
+	authManager *auth.Manager,
+	env *environment.Environment,
+	console input.Console,
+	args []string,
+) actions.Action {
+	return &envSetProfileAction{
+		authManager: authManager,
+		env:         env,
+		console:     console,
+		args:        args,
+	}
+}
+
+func (e *envSetProfileAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	profile := e.args[0]
+	if err := auth.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+
+	if profile == auth.DefaultProfile {
+		if err := e.env.Config.Unset(auth.ProfileConfigPath); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := e.env.Config.Set(auth.ProfileConfigPath, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.env.Save(); err != nil {
+		return nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	profiles, err := e.authManager.Profiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(profiles, profile) {
+		e.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: profile %s isn't logged in. Run `azd auth login --profile %s` to log in.", profile, profile))
+	}
+
+	return nil, nil
+}
+
 func newEnvSelectCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "select <environment>",
 		Short: "Set the default environment.",
 		Args:  cobra.ExactArgs(1),
+
+		ValidArgsFunction: environmentNameArgCompletion,
 	}
 }
 
@@ -222,6 +320,7 @@ func (e *envListAction) Run(ctx context.Context) (*actions.ActionResult, error)
 type envNewFlags struct {
 	subscription string
 	location     string
+	shared       string
 	global       *internal.GlobalCommandOptions
 }
 
@@ -233,6 +332,12 @@ func (f *envNewFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		"Name or ID of an Azure subscription to use for the new environment",
 	)
 	local.StringVarP(&f.location, "location", "l", "", "Azure location for the new environment")
+	local.StringVar(
+		&f.shared,
+		"shared",
+		"",
+		"Name of a shared environment whose values are imported into the new environment before provisioning",
+	)
 
 	f.global = global
 }
@@ -292,6 +397,17 @@ func (en *envNewAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, fmt.Errorf("creating new environment: %w", err)
 	}
 
+	if en.flags.shared != "" {
+		shared := environment.Shared{Environment: en.flags.shared}
+		if err := env.Config.Set(environment.SharedConfigKey, shared); err != nil {
+			return nil, fmt.Errorf("setting shared environment: %w", err)
+		}
+
+		if err := env.Save(); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+	}
+
 	if err := en.azdCtx.SetDefaultEnvironmentName(env.GetEnvName()); err != nil {
 		return nil, fmt.Errorf("saving default environment: %w", err)
 	}
@@ -342,7 +458,8 @@ func newEnvRefreshCmd() *cobra.Command {
 
 			return cmd.Flags().Set(environmentNameFlag, args[0])
 		},
-		Annotations: map[string]string{},
+		ValidArgsFunction: environmentNameArgCompletion,
+		Annotations:       map[string]string{},
 	}
 
 	// This is like the Use property above, but does not include the hint to show an environment name is supported. This
@@ -354,6 +471,7 @@ func newEnvRefreshCmd() *cobra.Command {
 
 type envRefreshAction struct {
 	provisionManager *provisioning.Manager
+	envHydrator      *provisioning.EnvironmentHydrator
 	projectConfig    *project.ProjectConfig
 	projectManager   project.ProjectManager
 	env              *environment.Environment
@@ -365,6 +483,7 @@ type envRefreshAction struct {
 
 func newEnvRefreshAction(
 	provisionManager *provisioning.Manager,
+	envHydrator *provisioning.EnvironmentHydrator,
 	projectConfig *project.ProjectConfig,
 	projectManager project.ProjectManager,
 	env *environment.Environment,
@@ -375,6 +494,7 @@ func newEnvRefreshAction(
 ) actions.Action {
 	return &envRefreshAction{
 		provisionManager: provisionManager,
+		envHydrator:      envHydrator,
 		projectManager:   projectManager,
 		env:              env,
 		console:          console,
@@ -403,9 +523,22 @@ func (ef *envRefreshAction) Run(ctx context.Context) (*actions.ActionResult, err
 		return nil, err
 	}
 
+	// Outputs don't always include the values required for deployment, i.e. the AKS cluster name or container registry
+	// endpoint. Derive those from the provisioned resources so the environment can be deployed without provisioning.
+	hydrated, err := ef.envHydrator.Hydrate(ctx, ef.env, getStateResult.State)
+	if err != nil {
+		return nil, fmt.Errorf("hydrating environment from provisioned resources: %w", err)
+	}
+
+	hydratedKeys := maps.Keys(hydrated)
+	slices.Sort(hydratedKeys)
+	for _, key := range hydratedKeys {
+		ef.console.Message(ctx, fmt.Sprintf("Set %s from provisioned resources", output.WithHighLightFormat(key)))
+	}
+
 	ef.console.Message(ctx, "Environments setting refresh completed")
 
-	if ef.formatter.Kind() == output.JsonFormat {
+	if ef.formatter.Kind().IsStructured() {
 		err = ef.formatter.Format(provisioning.NewEnvRefreshResultFromState(getStateResult.State), ef.writer, nil)
 		if err != nil {
 			return nil, fmt.Errorf("writing deployment result in JSON format: %w", err)
@@ -489,6 +622,113 @@ func (eg *envGetValuesAction) Run(ctx context.Context) (*actions.ActionResult, e
 	return nil, nil
 }
 
+func newEnvEncryptFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envEncryptFlags {
+	flags := &envEncryptFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newEnvEncryptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the sensitive values of the environment with a key stored in the OS keychain.",
+		Long: "Encrypt the sensitive values of the .env file and config of the environment, such as passwords, keys " +
+			"and connection strings, with a key stored in the OS keychain. The values are decrypted when azd loads the " +
+			"environment, and values set later are encrypted too.",
+		Args: cobra.NoArgs,
+	}
+}
+
+type envEncryptFlags struct {
+	envFlag
+	decrypt bool
+	values  []string
+	global  *internal.GlobalCommandOptions
+}
+
+func (f *envEncryptFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+	local.BoolVar(&f.decrypt, "decrypt", false, "Decrypt the values of the environment and store them in plain text.")
+	local.StringArrayVar(
+		&f.values,
+		"value",
+		nil,
+		"Name of a value to encrypt besides the values whose names look sensitive. Names can contain * wildcards.",
+	)
+	f.global = global
+}
+
+type envEncryptAction struct {
+	console input.Console
+	env     *environment.Environment
+	flags   *envEncryptFlags
+}
+
+func newEnvEncryptAction(
+	env *environment.Environment,
+	console input.Console,
+	flags *envEncryptFlags,
+) actions.Action {
+	return &envEncryptAction{
+		console: console,
+		env:     env,
+		flags:   flags,
+	}
+}
+
+func (e *envEncryptAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if e.flags.decrypt && len(e.flags.values) > 0 {
+		return nil, errors.New("--value can't be set with --decrypt")
+	}
+
+	encryption, err := e.env.Encryption()
+	if err != nil {
+		return nil, err
+	}
+
+	encryption.Enabled = !e.flags.decrypt
+	for _, value := range e.flags.values {
+		if !slices.Contains(encryption.Values, value) {
+			encryption.Values = append(encryption.Values, value)
+		}
+	}
+
+	if err := e.env.Config.Set(environment.EncryptionConfigKey, encryption); err != nil {
+		return nil, fmt.Errorf("setting encryption: %w", err)
+	}
+
+	// Saving the environment encrypts, or decrypts, its values
+	if err := e.env.Save(); err != nil {
+		return nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	if e.flags.decrypt {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("The values of environment %s are stored in plain text.", e.env.GetEnvName()),
+			},
+		}, nil
+	}
+
+	encrypted, err := e.env.EncryptedValues()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range encrypted {
+		e.console.Message(ctx, fmt.Sprintf("  %s", output.WithHighLightFormat(name)))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Encrypted %d values of environment %s.", len(encrypted), e.env.GetEnvName()),
+			FollowUp: "Values whose names look sensitive, ex) *_PASSWORD, *_CONNECTION_STRING or *_KEY, are encrypted. " +
+				"Encrypt other values with --value.",
+		},
+	}, nil
+}
+
 func getCmdEnvHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		"Manage your application environments. With this command group, you can create a new environment or get, set,"+
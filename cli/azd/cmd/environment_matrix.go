@@ -0,0 +1,323 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
+)
+
+// matrixSkippedFlags are the flags of the command which aren't passed on to the command run against each environment
+var matrixSkippedFlags = []string{
+	"environments",
+	"all-environments",
+	"max-parallel",
+	environmentNameFlag,
+	// azd already runs in the directory of --cwd or --workspace
+	"cwd",
+	"workspace",
+	"output",
+	"no-prompt",
+	"help",
+}
+
+// environmentMatrixFlags run a command against several environments concurrently, ex) the environments of a
+// deployment stamped across regions
+type environmentMatrixFlags struct {
+	environments    []string
+	allEnvironments bool
+	maxParallel     int
+	// The flags of the command, passed on to the command run against each environment
+	commandFlags *pflag.FlagSet
+}
+
+func (f *environmentMatrixFlags) Bind(local *pflag.FlagSet) {
+	local.StringSliceVar(
+		&f.environments,
+		"environments",
+		nil,
+		"Runs against the given environments concurrently, ex) --environments dev,staging.",
+	)
+	local.BoolVar(
+		&f.allEnvironments,
+		"all-environments",
+		false,
+		"Runs against all the environments of the project concurrently.",
+	)
+	local.IntVar(
+		&f.maxParallel,
+		"max-parallel",
+		0,
+		"The maximum number of environments run against concurrently with '--environments' or '--all-environments', "+
+			"all of them by default.",
+	)
+	f.commandFlags = local
+}
+
+// enabled reports whether the command runs against several environments
+func (f *environmentMatrixFlags) enabled() bool {
+	return len(f.environments) > 0 || f.allEnvironments
+}
+
+// environmentResult is the outcome of the command run against an environment of the matrix
+type environmentResult struct {
+	Environment string `json:"environment"`
+	Succeeded   bool   `json:"succeeded"`
+	Duration    string `json:"duration"`
+	Error       string `json:"error,omitempty"`
+}
+
+// environmentMatrix runs a command against several environments concurrently. The command runs in its own azd process
+// for each environment, so the environments don't share state, ex) their credentials, locks or loaded configuration.
+// The rate limits of the requests to ARM are split among the processes running concurrently, as the environments
+// typically share their subscription.
+type environmentMatrix struct {
+	flags         *environmentMatrixFlags
+	azdCtx        *azdcontext.AzdContext
+	commandRunner exec.CommandRunner
+	console       input.Console
+	formatter     output.Formatter
+	writer        io.Writer
+}
+
+// run runs `azd <command> <args>` with the flags of the command against each environment, writing the output of each
+// environment prefixed with the environment, then a summary of the outcomes
+func (m *environmentMatrix) run(ctx context.Context, command string, args []string) (*actions.ActionResult, error) {
+	if m.flags.commandFlags.Changed(environmentNameFlag) {
+		return nil, errors.New("'--environment' cannot be specified with '--environments' or '--all-environments'")
+	}
+
+	if m.flags.maxParallel < 0 {
+		return nil, errors.New("'--max-parallel' must be at least 1")
+	}
+
+	if m.azdCtx == nil {
+		return nil, azdcontext.ErrNoProject
+	}
+
+	envNames, err := m.environmentNames()
+	if err != nil {
+		return nil, err
+	}
+
+	azdPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("getting the path of azd: %w", err)
+	}
+
+	// Ctrl+C reaches the azd processes of the environments, which stop and clean up. azd waits for them to exit.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	// The output of the environments is moved to stderr, for stdout to only have the json summary
+	out := m.writer
+	if m.formatter.Kind().IsStructured() {
+		out = m.console.Handles().Stderr
+	}
+
+	parallel := len(envNames)
+	if m.flags.maxParallel > 0 && m.flags.maxParallel < parallel {
+		parallel = m.flags.maxParallel
+	}
+
+	m.console.Message(ctx, output.WithGrayFormat(
+		"Running 'azd %s' against environments %s, %d at a time", command, strings.Join(envNames, ", "), parallel))
+
+	// Each process gets its share of the rate limits of ARM, for the processes to stay under the limits together
+	rateLimitEnv := azsdk.RateLimitsFromEnv().Divide(parallel).Environ()
+
+	startTime := time.Now()
+	results := make([]environmentResult, len(envNames))
+	slots := make(chan struct{}, parallel)
+
+	// Ctrl+C also stops the environments waiting for their turn
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupts:
+			close(stopped)
+		case <-done:
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, envName := range envNames {
+		// The output and error output of the process are written concurrently, each has its own pending line
+		color := logsServiceColors[i%len(logsServiceColors)]
+		stdout := &serviceLogWriter{service: envName, color: color, mu: &mu, writer: out}
+		stderr := &serviceLogWriter{service: envName, color: color, mu: &mu, writer: out}
+		runArgs := exec.NewRunArgs(azdPath, m.commandArgs(command, args, envName)...).
+			WithEnv(append([]string{fmt.Sprintf("%s=%s", environment.EnvNameEnvVarName, envName)}, rateLimitEnv...)).
+			WithStdOut(stdout).
+			WithStdErr(stderr)
+
+		wg.Add(1)
+		go func(i int, envName string) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-stopped:
+			}
+
+			select {
+			case <-stopped:
+				results[i] = environmentResult{Environment: envName, Error: "interrupted before running"}
+				return
+			default:
+			}
+
+			envStartTime := time.Now()
+
+			_, err := m.commandRunner.Run(ctx, runArgs)
+			for _, writer := range []*serviceLogWriter{stdout, stderr} {
+				if flushErr := writer.Flush(); err == nil {
+					err = flushErr
+				}
+			}
+
+			results[i] = environmentResult{
+				Environment: envName,
+				Succeeded:   err == nil,
+				Duration:    ux.DurationAsText(since(envStartTime)),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, envName)
+	}
+
+	wg.Wait()
+
+	if err := m.writeSummary(results); err != nil {
+		return nil, err
+	}
+
+	failed := []string{}
+	for _, result := range results {
+		if !result.Succeeded {
+			failed = append(failed, result.Environment)
+		}
+	}
+
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("'azd %s' failed against environments: %s", command, strings.Join(failed, ", "))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("'azd %s' completed against %d environments in %s.",
+				command, len(envNames), ux.DurationAsText(since(startTime))),
+		},
+	}, nil
+}
+
+// environmentNames returns the environments of --environments, or all the environments of the project
+func (m *environmentMatrix) environmentNames() ([]string, error) {
+	envs, err := m.azdCtx.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("listing environments: %w", err)
+	}
+
+	existing := []string{}
+	for _, env := range envs {
+		existing = append(existing, env.Name)
+	}
+
+	if m.flags.allEnvironments {
+		if len(m.flags.environments) > 0 {
+			return nil, errors.New("'--environments' and '--all-environments' cannot be specified together")
+		}
+
+		if len(existing) == 0 {
+			return nil, errors.New("the project has no environment, create one with `azd env new`")
+		}
+
+		return existing, nil
+	}
+
+	envNames := []string{}
+	for _, envName := range m.flags.environments {
+		if !slices.Contains(existing, envName) {
+			return nil, fmt.Errorf("environment '%s' doesn't exist", envName)
+		}
+
+		if !slices.Contains(envNames, envName) {
+			envNames = append(envNames, envName)
+		}
+	}
+
+	return envNames, nil
+}
+
+// commandArgs returns the arguments of azd running the command against the environment, without prompting
+func (m *environmentMatrix) commandArgs(command string, args []string, envName string) []string {
+	commandArgs := append([]string{command}, args...)
+
+	m.flags.commandFlags.Visit(func(flag *pflag.Flag) {
+		if slices.Contains(matrixSkippedFlags, flag.Name) {
+			return
+		}
+
+		if values, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range values.GetSlice() {
+				commandArgs = append(commandArgs, fmt.Sprintf("--%s=%s", flag.Name, value))
+			}
+			return
+		}
+
+		commandArgs = append(commandArgs, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+	})
+
+	return append(commandArgs, "--environment", envName, "--no-prompt")
+}
+
+// writeSummary writes the outcome of each environment, as a table or as json
+func (m *environmentMatrix) writeSummary(results []environmentResult) error {
+	if m.formatter.Kind().IsStructured() {
+		return m.formatter.Format(results, m.writer, nil)
+	}
+
+	fmt.Fprintln(m.writer)
+	tableFormatter := &output.TableFormatter{}
+	return tableFormatter.Format(results, m.writer, output.TableFormatterOptions{
+		Columns: []output.Column{
+			{
+				Heading:       "ENVIRONMENT",
+				ValueTemplate: "{{.Environment}}",
+			},
+			{
+				Heading:       "RESULT",
+				ValueTemplate: `{{if .Succeeded}}Succeeded{{else}}Failed{{end}}`,
+			},
+			{
+				Heading:       "DURATION",
+				ValueTemplate: "{{.Duration}}",
+			},
+		},
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEnvironmentMatrix(t *testing.T, envNames ...string) (*environmentMatrix, *pflag.FlagSet) {
+	dir := t.TempDir()
+	azdCtx := azdcontext.NewAzdContextWithDirectory(dir)
+	for _, envName := range envNames {
+		require.NoError(t, os.MkdirAll(filepath.Join(azdCtx.EnvironmentDirectory(), envName), 0755))
+	}
+
+	flags := &environmentMatrixFlags{}
+	local := pflag.NewFlagSet("deploy", pflag.ContinueOnError)
+	flags.Bind(local)
+	local.StringSlice("only", nil, "")
+	local.Bool("all", false, "")
+	local.String(environmentNameFlag, "", "")
+	local.Bool("no-prompt", false, "")
+
+	return &environmentMatrix{
+		flags:     flags,
+		azdCtx:    azdCtx,
+		formatter: &output.NoneFormatter{},
+	}, local
+}
+
+func Test_EnvironmentMatrix_CommandArgs(t *testing.T) {
+	matrix, local := newTestEnvironmentMatrix(t)
+	require.NoError(t, local.Parse([]string{"--environments", "dev,staging", "--only", "api,web", "--all", "--no-prompt"}))
+
+	require.Equal(
+		t,
+		[]string{"deploy", "api", "--all=true", "--only=api", "--only=web", "--environment", "dev", "--no-prompt"},
+		matrix.commandArgs("deploy", []string{"api"}, "dev"),
+	)
+}
+
+func Test_EnvironmentMatrix_EnvironmentNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "Environments",
+			args: []string{"--environments", "staging,dev,staging"},
+			want: []string{"staging", "dev"},
+		},
+		{
+			name: "AllEnvironments",
+			args: []string{"--all-environments"},
+			want: []string{"dev", "staging"},
+		},
+		{
+			name:    "UnknownEnvironment",
+			args:    []string{"--environments", "dev,prod"},
+			wantErr: "environment 'prod' doesn't exist",
+		},
+		{
+			name:    "BothFlags",
+			args:    []string{"--environments", "dev", "--all-environments"},
+			wantErr: "cannot be specified together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matrix, local := newTestEnvironmentMatrix(t, "dev", "staging")
+			require.NoError(t, local.Parse(tt.args))
+
+			envNames, err := matrix.environmentNames()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, envNames)
+		})
+	}
+}
+
+func Test_EnvironmentMatrix_Run(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "deploy")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		envName := args.Args[len(args.Args)-2]
+		fmt.Fprintf(args.Stdout, "deploying %s\n", envName)
+		if envName == "staging" {
+			return exec.RunResult{ExitCode: 1}, errors.New("exit code: 1")
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	matrix, local := newTestEnvironmentMatrix(t, "dev", "staging")
+	require.NoError(t, local.Parse([]string{"--all-environments"}))
+
+	buf := &bytes.Buffer{}
+	matrix.commandRunner = mockContext.CommandRunner
+	matrix.console = mockContext.Console
+	matrix.writer = buf
+
+	_, err := matrix.run(*mockContext.Context, "deploy", nil)
+	require.EqualError(t, err, "'azd deploy' failed against environments: staging")
+
+	require.Contains(t, buf.String(), "deploying dev")
+	require.Contains(t, buf.String(), "deploying staging")
+	require.Regexp(t, `dev\s+Succeeded`, buf.String())
+	require.Regexp(t, `staging\s+Failed`, buf.String())
+}
+
+func Test_EnvironmentMatrix_Run_MaxParallel(t *testing.T) {
+	t.Setenv(azsdk.ArmReadsPerSecondEnvVarName, "20")
+	t.Setenv(azsdk.ArmWritesPerSecondEnvVarName, "10")
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "deploy")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Contains(t, args.Env, "AZD_ARM_READS_PER_SECOND=10")
+		require.Contains(t, args.Env, "AZD_ARM_WRITES_PER_SECOND=5")
+
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	matrix, local := newTestEnvironmentMatrix(t, "dev", "staging", "prod")
+	require.NoError(t, local.Parse([]string{"--all-environments", "--max-parallel", "2"}))
+
+	matrix.commandRunner = mockContext.CommandRunner
+	matrix.console = mockContext.Console
+	matrix.writer = &bytes.Buffer{}
+
+	_, err := matrix.run(*mockContext.Context, "deploy", nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, maxRunning)
+}
+
+func Test_EnvironmentMatrix_Run_EnvironmentFlag(t *testing.T) {
+	matrix, local := newTestEnvironmentMatrix(t, "dev")
+	require.NoError(t, local.Parse([]string{"--all-environments", "--environment", "dev"}))
+
+	_, err := matrix.run(context.Background(), "deploy", nil)
+	require.ErrorContains(t, err, "'--environment' cannot be specified")
+}
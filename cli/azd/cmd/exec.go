@@ -0,0 +1,202 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/maps"
+)
+
+// The command run when no command is specified, an interactive shell
+const defaultExecCommand = "/bin/sh"
+
+type execFlags struct {
+	serviceName string
+	noTty       bool
+	envFlag
+}
+
+func (f *execFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.serviceName,
+		"service",
+		"",
+		"The service to run the command in. Defaults to the service of the current directory.",
+	)
+	local.BoolVar(
+		&f.noTty,
+		"no-tty",
+		false,
+		"Runs the command without a terminal, even when azd runs in a terminal.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newExecFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *execFlags {
+	flags := &execFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newExecCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exec [flags] [-- <command> [<args>...]]",
+		Short: "Run a command inside the deployed compute of a service.",
+	}
+}
+
+type execAction struct {
+	flags           *execFlags
+	args            []string
+	projectConfig   *project.ProjectConfig
+	projectManager  project.ProjectManager
+	serviceManager  project.ServiceManager
+	resourceManager project.ResourceManager
+	env             *environment.Environment
+	console         input.Console
+}
+
+func newExecAction(
+	flags *execFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	projectManager project.ProjectManager,
+	serviceManager project.ServiceManager,
+	resourceManager project.ResourceManager,
+	env *environment.Environment,
+	console input.Console,
+) actions.Action {
+	return &execAction{
+		flags:           flags,
+		args:            args,
+		projectConfig:   projectConfig,
+		projectManager:  projectManager,
+		serviceManager:  serviceManager,
+		resourceManager: resourceManager,
+		env:             env,
+		console:         console,
+	}
+}
+
+func (a *execAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.env.GetSubscriptionId() == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	serviceConfig, err := a.targetService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceTarget, err := a.serviceManager.GetServiceTarget(ctx, serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting service target: %w", err)
+	}
+
+	execTarget, ok := serviceTarget.(project.ServiceExecTarget)
+	if !ok {
+		return nil, fmt.Errorf(
+			"azd exec is not supported for service '%s' hosted on %s, supported hosts are aks, containerapp and appservice",
+			serviceConfig.Name,
+			serviceConfig.Host,
+		)
+	}
+
+	targetResource, err := a.resourceManager.GetTargetResource(ctx, a.env.GetSubscriptionId(), serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting target resource: %w", err)
+	}
+
+	command := a.args
+	if len(command) == 0 {
+		command = []string{defaultExecCommand}
+	}
+
+	handles := a.console.Handles()
+	a.console.Message(ctx, output.WithGrayFormat(
+		"Running '%s' in service %s (%s)", command[0], serviceConfig.Name, serviceConfig.Host))
+
+	if err := execTarget.Exec(ctx, serviceConfig, targetResource, project.ExecOptions{
+		Command: command,
+		Tty:     !a.flags.noTty && handles.IsTerminal(),
+		Handles: handles,
+	}); err != nil {
+		return nil, fmt.Errorf("running command in service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return nil, nil
+}
+
+// targetService returns the service of the --service flag, the service of the current directory or the only service of
+// the project
+func (a *execAction) targetService(ctx context.Context) (*project.ServiceConfig, error) {
+	if a.flags.serviceName != "" {
+		if !a.projectConfig.HasService(a.flags.serviceName) {
+			return nil, fmt.Errorf("service name '%s' doesn't exist", a.flags.serviceName)
+		}
+
+		return a.projectConfig.Services[a.flags.serviceName], nil
+	}
+
+	serviceConfig, err := a.projectManager.DefaultServiceFromWd(ctx, a.projectConfig)
+	if err != nil && !errors.Is(err, project.ErrNoDefaultService) {
+		return nil, err
+	}
+
+	if serviceConfig != nil {
+		return serviceConfig, nil
+	}
+
+	if len(a.projectConfig.Services) != 1 {
+		return nil, errors.New("the project has more than one service, specify the service with --service")
+	}
+
+	return a.projectConfig.Services[maps.Keys(a.projectConfig.Services)[0]], nil
+}
+
+func getCmdExecHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Run a command inside the deployed compute of a service, ex) a shell to troubleshoot the service.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"The command follows %s and defaults to %s. A terminal is allocated when azd runs in a terminal.",
+				output.WithHighLightFormat("--"),
+				output.WithHighLightFormat(defaultExecCommand),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"AKS services run the command with %s in the deployment of the service.",
+				output.WithHighLightFormat("kubectl exec"),
+			)),
+			formatHelpNote(
+				"Container app services run the command in a replica of the latest ready revision."),
+			formatHelpNote(
+				"App Service services run the command on the Kudu site of the app, without a terminal."),
+		})
+}
+
+func getCmdExecHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Open a shell in the container of the api service.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd exec --service"),
+			output.WithWarningFormat("api"),
+		),
+		"List the files of the web service.": fmt.Sprintf("%s %s %s",
+			output.WithHighLightFormat("azd exec --service"),
+			output.WithWarningFormat("web"),
+			output.WithHighLightFormat("-- ls -la"),
+		),
+	})
+}
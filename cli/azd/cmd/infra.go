@@ -39,5 +39,37 @@ func infraActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		}).
 		UseMiddleware("hooks", middleware.NewHooksMiddleware)
 
+	group.
+		Add("export", &actions.ActionDescriptorOptions{
+			Command:        newInfraExportCmd(),
+			FlagsResolver:  newInfraExportFlags,
+			ActionResolver: newInfraExportAction,
+		})
+
+	group.
+		Add("drift", &actions.ActionDescriptorOptions{
+			Command:        newInfraDriftCmd(),
+			FlagsResolver:  newInfraDriftFlags,
+			ActionResolver: newInfraDriftAction,
+			OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+			DefaultFormat:  output.NoneFormat,
+		})
+
+	group.
+		Add("lint", &actions.ActionDescriptorOptions{
+			Command:        newInfraLintCmd(),
+			FlagsResolver:  newInfraLintFlags,
+			ActionResolver: newInfraLintAction,
+			OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+			DefaultFormat:  output.NoneFormat,
+		})
+
+	group.
+		Add("prune-images", &actions.ActionDescriptorOptions{
+			Command:        newInfraPruneImagesCmd(),
+			FlagsResolver:  newInfraPruneImagesFlags,
+			ActionResolver: newInfraPruneImagesAction,
+		})
+
 	return group
 }
@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/rbac"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// driftExitCode is the exit code of azd infra drift when drift is detected, like terraform plan -detailed-exitcode
+const driftExitCode = 2
+
+type infraDriftFlags struct {
+	envFlag
+}
+
+func (f *infraDriftFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+}
+
+func newInfraDriftFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *infraDriftFlags {
+	flags := &infraDriftFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newInfraDriftCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drift",
+		Short: "Detect provisioned Azure resources which drifted from the infrastructure of the project.",
+		Long: "Detect provisioned Azure resources which drifted from the infrastructure of the project, " +
+			"with an ARM what-if of the template for Bicep, or a plan for Terraform, and role assignments of azure.yaml " +
+			"which are missing. No resources are changed. " +
+			fmt.Sprintf("The command exits with code %d when drift is detected, ", driftExitCode) +
+			"for example to fail scheduled checks in CI.",
+	}
+}
+
+type infraDriftAction struct {
+	flags            *infraDriftFlags
+	provisionManager *provisioning.Manager
+	projectManager   project.ProjectManager
+	projectConfig    *project.ProjectConfig
+	env              *environment.Environment
+	formatter        output.Formatter
+	writer           io.Writer
+	console          input.Console
+	rbacManager      *rbac.Manager
+}
+
+func newInfraDriftAction(
+	flags *infraDriftFlags,
+	provisionManager *provisioning.Manager,
+	projectManager project.ProjectManager,
+	projectConfig *project.ProjectConfig,
+	env *environment.Environment,
+	formatter output.Formatter,
+	writer io.Writer,
+	console input.Console,
+	rbacManager *rbac.Manager,
+) actions.Action {
+	return &infraDriftAction{
+		flags:            flags,
+		provisionManager: provisionManager,
+		projectManager:   projectManager,
+		projectConfig:    projectConfig,
+		env:              env,
+		formatter:        formatter,
+		writer:           writer,
+		console:          console,
+		rbacManager:      rbacManager,
+	}
+}
+
+func (a *infraDriftAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Detecting infrastructure drift (azd infra drift)",
+	})
+
+	if err := a.projectManager.Initialize(ctx, a.projectConfig); err != nil {
+		return nil, err
+	}
+
+	if err := a.provisionManager.Initialize(ctx, a.projectConfig.Path, a.projectConfig.Infra); err != nil {
+		return nil, fmt.Errorf("initializing provisioning manager: %w", err)
+	}
+
+	driftResult, err := a.provisionManager.Drift(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Role assignments declared in azure.yaml are missing when they were deleted since provisioning
+	roleAssignments, err := a.rbacManager.Resolve(ctx, a.projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("resolving role assignments: %w", err)
+	}
+
+	for _, roleAssignment := range roleAssignments {
+		if !roleAssignment.Assigned {
+			driftResult.Resources = append(driftResult.Resources, &provisioning.DriftedResource{
+				Id:         roleAssignment.Id(),
+				ChangeType: "Create",
+				Properties: []string{fmt.Sprintf("role assignment: %s", roleAssignment)},
+			})
+		}
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		if err := a.formatter.Format(driftResult, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("drift result could not be displayed: %w", err)
+		}
+	}
+
+	if len(driftResult.Resources) == 0 {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf(
+					"The resources of environment %s match the infrastructure of the project.", a.env.GetEnvName()),
+			},
+		}, nil
+	}
+
+	a.console.Message(ctx, "")
+	for _, resource := range driftResult.Resources {
+		line := fmt.Sprintf("  %s %s", output.WithWarningFormat("(%s)", resource.ChangeType), resource.Id)
+		if len(resource.Properties) > 0 {
+			line += fmt.Sprintf("\n      %s", output.WithGrayFormat(strings.Join(resource.Properties, ", ")))
+		}
+		a.console.Message(ctx, line)
+	}
+	a.console.Message(ctx, "")
+
+	return nil, &internal.ErrorWithExitCode{
+		Err: fmt.Errorf(
+			"%d resource(s) of environment %s drifted from the infrastructure of the project. "+
+				"Run `azd provision` to restore them",
+			len(driftResult.Resources), a.env.GetEnvName()),
+		ExitCode: driftExitCode,
+	}
+}
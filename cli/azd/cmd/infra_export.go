@@ -0,0 +1,215 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	exportFormatBicep = "bicep"
+	exportFormatArm   = "arm"
+)
+
+type infraExportFlags struct {
+	format string
+	envFlag
+}
+
+func (f *infraExportFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.format,
+		"format",
+		exportFormatBicep,
+		"The format of the exported templates, either bicep or arm.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newInfraExportFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *infraExportFlags {
+	flags := &infraExportFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newInfraExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "Export the resource groups of the environment to infrastructure templates.",
+		Long: "Export the resource groups of the environment to infrastructure templates under infra/exported, " +
+			"for example to adopt azd for environments created manually. " +
+			"Resource names are exported as parameters.",
+	}
+}
+
+type infraExportAction struct {
+	flags         *infraExportFlags
+	azCli         azcli.AzCli
+	bicepCli      bicep.BicepCli
+	env           *environment.Environment
+	projectConfig *project.ProjectConfig
+	console       input.Console
+}
+
+func newInfraExportAction(
+	flags *infraExportFlags,
+	azCli azcli.AzCli,
+	bicepCli bicep.BicepCli,
+	env *environment.Environment,
+	projectConfig *project.ProjectConfig,
+	console input.Console,
+) actions.Action {
+	return &infraExportAction{
+		flags:         flags,
+		azCli:         azCli,
+		bicepCli:      bicepCli,
+		env:           env,
+		projectConfig: projectConfig,
+		console:       console,
+	}
+}
+
+func (a *infraExportAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.flags.format != exportFormatBicep && a.flags.format != exportFormatArm {
+		return nil, fmt.Errorf("invalid format '%s', supported formats are bicep and arm", a.flags.format)
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Exporting environment infrastructure (azd infra export)",
+	})
+
+	subscriptionId := a.env.GetSubscriptionId()
+	resourceGroups, err := a.resourceGroups(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resourceGroups) == 0 {
+		return nil, fmt.Errorf("no resource groups found for environment '%s'", a.env.GetEnvName())
+	}
+
+	exportPath := filepath.Join(a.projectConfig.Path, "infra", "exported")
+	if err := os.MkdirAll(exportPath, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating directory %s: %w", exportPath, err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		spinnerMessage := fmt.Sprintf("Exporting resource group %s", output.WithHighLightFormat(resourceGroup.Name))
+		a.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+
+		err := a.exportResourceGroup(ctx, subscriptionId, resourceGroup, exportPath)
+		a.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, fmt.Errorf("exporting resource group '%s': %w", resourceGroup.Name, err)
+		}
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Exported %d resource group(s) to %s.", len(resourceGroups), exportPath),
+			FollowUp: "Review the exported templates before using them, resources which can't be exported " +
+				"are not included.",
+		},
+	}, nil
+}
+
+// resourceGroups returns the resource groups tagged with the name of the environment and the resource group of the
+// environment, when set.
+func (a *infraExportAction) resourceGroups(
+	ctx context.Context,
+	subscriptionId string,
+) ([]azcli.AzCliResource, error) {
+	groups, err := a.azCli.ListResourceGroup(ctx, subscriptionId, &azcli.ListResourceGroupOptions{
+		TagFilter: &azcli.Filter{Key: azure.TagKeyAzdEnvName, Value: a.env.GetEnvName()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing resource groups: %w", err)
+	}
+
+	envResourceGroup := a.env.Getenv(environment.ResourceGroupEnvVarName)
+	if envResourceGroup == "" {
+		return groups, nil
+	}
+
+	for _, group := range groups {
+		if group.Name == envResourceGroup {
+			return groups, nil
+		}
+	}
+
+	allGroups, err := a.azCli.ListResourceGroup(ctx, subscriptionId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing resource groups: %w", err)
+	}
+
+	for _, group := range allGroups {
+		if group.Name == envResourceGroup {
+			return append(groups, group), nil
+		}
+	}
+
+	return nil, fmt.Errorf("resource group '%s' not found", envResourceGroup)
+}
+
+func (a *infraExportAction) exportResourceGroup(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup azcli.AzCliResource,
+	exportPath string,
+) error {
+	template, err := a.azCli.ExportResourceGroupTemplate(ctx, subscriptionId, resourceGroup.Name)
+	if err != nil {
+		return err
+	}
+
+	template, err = infra.NormalizeExportedTemplate(template, a.env.GetEnvName(), resourceGroup.Location)
+	if err != nil {
+		return err
+	}
+
+	armPath := filepath.Join(exportPath, fmt.Sprintf("%s.json", resourceGroup.Name))
+	if err := os.WriteFile(armPath, template, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing %s: %w", armPath, err)
+	}
+
+	if a.flags.format == exportFormatArm {
+		return nil
+	}
+
+	bicepSource, err := a.bicepCli.Decompile(ctx, armPath)
+	if err != nil {
+		return err
+	}
+
+	bicepPath := filepath.Join(exportPath, fmt.Sprintf("%s.bicep", resourceGroup.Name))
+	if err := os.WriteFile(bicepPath, []byte(bicepSource), osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing %s: %w", bicepPath, err)
+	}
+
+	if err := os.Remove(armPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing %s: %w", armPath, err)
+	}
+
+	return nil
+}
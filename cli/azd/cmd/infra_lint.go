@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type infraLintFlags struct {
+	envFlag
+}
+
+func (f *infraLintFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+}
+
+func newInfraLintFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *infraLintFlags {
+	flags := &infraLintFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newInfraLintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint",
+		Short: "Lint the infrastructure of the project, like azd provision does before provisioning.",
+		Long: "Lint the Bicep infrastructure of the project with the linter of Bicep and the rules of azd: " +
+			"no-hardcoded-location, secure-secrets-in-params and use-recent-api-versions. " +
+			"The severity of each rule is configured in infra.lint.rules of azure.yaml, " +
+			"diagnostics of severity error fail provisioning.",
+	}
+}
+
+type infraLintAction struct {
+	flags            *infraLintFlags
+	provisionManager *provisioning.Manager
+	projectManager   project.ProjectManager
+	projectConfig    *project.ProjectConfig
+	formatter        output.Formatter
+	writer           io.Writer
+	console          input.Console
+}
+
+func newInfraLintAction(
+	flags *infraLintFlags,
+	provisionManager *provisioning.Manager,
+	projectManager project.ProjectManager,
+	projectConfig *project.ProjectConfig,
+	formatter output.Formatter,
+	writer io.Writer,
+	console input.Console,
+) actions.Action {
+	return &infraLintAction{
+		flags:            flags,
+		provisionManager: provisionManager,
+		projectManager:   projectManager,
+		projectConfig:    projectConfig,
+		formatter:        formatter,
+		writer:           writer,
+		console:          console,
+	}
+}
+
+func (a *infraLintAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Linting infrastructure (azd infra lint)",
+	})
+
+	if err := a.projectManager.Initialize(ctx, a.projectConfig); err != nil {
+		return nil, err
+	}
+
+	if err := a.provisionManager.Initialize(ctx, a.projectConfig.Path, a.projectConfig.Infra); err != nil {
+		return nil, fmt.Errorf("initializing provisioning manager: %w", err)
+	}
+
+	result, err := a.provisionManager.Lint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, errors.New("the infrastructure of the project can't be linted, only Bicep is supported")
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		if err := a.formatter.Format(result, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("lint result could not be displayed: %w", err)
+		}
+	} else {
+		printLintResult(ctx, a.console, result)
+	}
+
+	if result.Count(provisioning.LintSeverityError) > 0 {
+		return nil, &provisioning.LintError{Result: result}
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("The linter found %d warning(s) in the infrastructure.",
+				result.Count(provisioning.LintSeverityWarning)),
+		},
+	}, nil
+}
+
+// printLintResult prints the diagnostics of the linter, grouped by file
+func printLintResult(ctx context.Context, console input.Console, result *provisioning.LintResult) {
+	for _, file := range result.Files {
+		console.Message(ctx, fmt.Sprintf("  %s", output.WithHighLightFormat(file.Path)))
+		for _, diagnostic := range file.Diagnostics {
+			location := output.WithGrayFormat("(%d,%d)", diagnostic.Line, diagnostic.Column)
+			message := fmt.Sprintf("%s: %s", diagnostic.Rule, diagnostic.Message)
+			switch diagnostic.Severity {
+			case provisioning.LintSeverityError:
+				message = output.WithErrorFormat("error %s", message)
+			case provisioning.LintSeverityWarning:
+				message = output.WithWarningFormat("warning %s", message)
+			default:
+				message = fmt.Sprintf("%s %s", diagnostic.Severity, message)
+			}
+
+			console.Message(ctx, fmt.Sprintf("    %s %s", location, message))
+		}
+	}
+
+	if len(result.Files) > 0 {
+		console.Message(ctx, "")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type infraPruneImagesFlags struct {
+	keep      int
+	olderThan int
+	dryRun    bool
+	force     bool
+	envFlag
+}
+
+func (f *infraPruneImagesFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.IntVar(&f.keep, "keep", 10, "The number of most recently pushed images kept for each service.")
+	local.IntVar(
+		&f.olderThan,
+		"older-than",
+		0,
+		"Only deletes the images pushed more than the given number of days ago.",
+	)
+	local.BoolVar(&f.dryRun, "dry-run", false, "Lists the images which would be deleted, without deleting them.")
+	local.BoolVar(&f.force, "force", false, "Does not require confirmation before it deletes images.")
+	f.envFlag.Bind(local, global)
+}
+
+func newInfraPruneImagesFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *infraPruneImagesFlags {
+	flags := &infraPruneImagesFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newInfraPruneImagesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune-images <service>",
+		Short: "Delete stale container images pushed by azd from the container registry of the environment.",
+		Long: "Delete stale container images pushed by azd from the container registry of the environment. " +
+			"The most recently pushed images and the deployed image of each service are kept. " +
+			"When <service> is set, only the images of the specific service are deleted.",
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
+
+	return cmd
+}
+
+type infraPruneImagesAction struct {
+	flags           *infraPruneImagesFlags
+	args            []string
+	env             *environment.Environment
+	projectConfig   *project.ProjectConfig
+	containerHelper *project.ContainerHelper
+	console         input.Console
+}
+
+func newInfraPruneImagesAction(
+	flags *infraPruneImagesFlags,
+	args []string,
+	env *environment.Environment,
+	projectConfig *project.ProjectConfig,
+	containerHelper *project.ContainerHelper,
+	console input.Console,
+) actions.Action {
+	return &infraPruneImagesAction{
+		flags:           flags,
+		args:            args,
+		env:             env,
+		projectConfig:   projectConfig,
+		containerHelper: containerHelper,
+		console:         console,
+	}
+}
+
+func (a *infraPruneImagesAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.flags.keep < 0 || a.flags.olderThan < 0 {
+		return nil, errors.New("'--keep' and '--older-than' cannot be negative")
+	}
+
+	targetServiceName := ""
+	if len(a.args) == 1 {
+		targetServiceName = a.args[0]
+		if !a.projectConfig.HasService(targetServiceName) {
+			return nil, fmt.Errorf("service name '%s' doesn't exist", targetServiceName)
+		}
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Deleting stale container images (azd infra prune-images)",
+	})
+
+	retention := project.ImagesRetention{
+		Keep: a.flags.keep,
+		Days: a.flags.olderThan,
+	}
+
+	staleImages := []*project.StaleImage{}
+	for _, svc := range a.projectConfig.GetServicesStable() {
+		if targetServiceName != "" && targetServiceName != svc.Name {
+			continue
+		}
+
+		images, err := a.containerHelper.StaleImages(ctx, svc, retention)
+		if err != nil {
+			return nil, fmt.Errorf("finding stale images of service '%s': %w", svc.Name, err)
+		}
+		staleImages = append(staleImages, images...)
+	}
+
+	if len(staleImages) == 0 {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: "No stale container images were found.",
+			},
+		}, nil
+	}
+
+	for _, image := range staleImages {
+		a.console.Message(ctx, fmt.Sprintf("  %s (%s, pushed %s)",
+			output.WithHighLightFormat("%s@%s", image.Repository, image.Digest),
+			strings.Join(image.Tags, ", "),
+			image.Pushed.Format("2006-01-02")))
+	}
+	a.console.Message(ctx, "")
+
+	if a.flags.dryRun {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("%d stale container image(s) would be deleted.", len(staleImages)),
+			},
+		}, nil
+	}
+
+	if !a.flags.force {
+		confirm, err := a.console.Confirm(ctx, input.ConsoleOptions{
+			Message: fmt.Sprintf(
+				"Delete %d container image(s) from %s?", len(staleImages), staleImages[0].LoginServer),
+			DefaultValue: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !confirm {
+			return nil, errors.New("deleting container images was cancelled")
+		}
+	}
+
+	for _, image := range staleImages {
+		spinnerMessage := fmt.Sprintf("Deleting image %s", output.WithHighLightFormat(image.Repository+"@"+image.Digest))
+		a.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+
+		err := a.containerHelper.DeleteImage(ctx, image)
+		a.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, fmt.Errorf("deleting image %s@%s: %w", image.Repository, image.Digest, err)
+		}
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Deleted %d stale container image(s).", len(staleImages)),
+		},
+	}, nil
+}
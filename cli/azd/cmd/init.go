@@ -14,6 +14,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/repository"
+	"github.com/azure/azure-dev/cli/azd/pkg/appimport"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
@@ -46,6 +47,7 @@ type initFlags struct {
 	templateBranch string
 	subscription   string
 	location       string
+	fromApp        string
 	global         *internal.GlobalCommandOptions
 	envFlag
 }
@@ -67,6 +69,13 @@ func (i *initFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOpt
 		"Name or ID of an Azure subscription to use for the new environment",
 	)
 	local.StringVarP(&i.location, "location", "l", "", "Azure location for the new environment")
+	local.StringVar(
+		&i.fromApp,
+		"from-app",
+		"",
+		"The resource ID of an App Service app, a Container App or an AKS cluster "+
+			"(optionally ending with /namespaces/<namespace>) to import into the project.",
+	)
 	i.envFlag.Bind(local, global)
 
 	i.global = global
@@ -78,6 +87,7 @@ type initAction struct {
 	gitCli          git.GitCli
 	flags           *initFlags
 	repoInitializer *repository.Initializer
+	appInspector    *appimport.Inspector
 }
 
 func newInitAction(
@@ -85,13 +95,15 @@ func newInitAction(
 	console input.Console,
 	gitCli git.GitCli,
 	flags *initFlags,
-	repoInitializer *repository.Initializer) actions.Action {
+	repoInitializer *repository.Initializer,
+	appInspector *appimport.Inspector) actions.Action {
 	return &initAction{
 		console:         console,
 		cmdRun:          cmdRun,
 		gitCli:          gitCli,
 		flags:           flags,
 		repoInitializer: repoInitializer,
+		appInspector:    appInspector,
 	}
 }
 
@@ -107,6 +119,10 @@ func (i *initAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		return nil, errors.New("template required when specifying a branch name")
 	}
 
+	if i.flags.fromApp != "" && i.flags.templatePath != "" {
+		return nil, errors.New("only one of --template and --from-app can be specified")
+	}
+
 	// ensure that git is available
 	if err := tools.EnsureInstalled(ctx, []tools.ExternalTool{i.gitCli}...); err != nil {
 		return nil, err
@@ -130,36 +146,55 @@ func (i *initAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		return nil, fmt.Errorf("checking if project exists: %w", err)
 	}
 
-	if !existingProject {
+	var importedApp *appimport.App
+	var scaffoldResult *appimport.ScaffoldResult
+	if i.flags.fromApp != "" {
+		if existingProject {
+			return nil, fmt.Errorf(
+				"%s already exists, --from-app initializes new projects", azdcontext.ProjectFileName)
+		}
+
 		err = i.repoInitializer.PromptIfNonEmpty(ctx, azdCtx)
 		if err != nil {
 			return nil, err
 		}
 
-		if i.flags.templatePath == "" {
-			template, err := templates.PromptTemplate(ctx, "Select a project template:", i.console)
-			i.flags.templatePath = template.RepositoryPath
-
+		importedApp, scaffoldResult, err = i.initFromApp(ctx, azdCtx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !existingProject {
+			err = i.repoInitializer.PromptIfNonEmpty(ctx, azdCtx)
 			if err != nil {
 				return nil, err
 			}
-		}
-	}
 
-	if i.flags.templatePath != "" {
-		gitUri, err := templates.Absolute(i.flags.templatePath)
-		if err != nil {
-			return nil, err
-		}
+			if i.flags.templatePath == "" {
+				template, err := templates.PromptTemplate(ctx, "Select a project template:", i.console)
+				i.flags.templatePath = template.RepositoryPath
 
-		err = i.repoInitializer.Initialize(ctx, azdCtx, gitUri, i.flags.templateBranch)
-		if err != nil {
-			return nil, fmt.Errorf("init from template repository: %w", err)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
-	} else if !existingProject { // do not initialize for empty if azure.yaml is present
-		err = i.repoInitializer.InitializeMinimal(ctx, azdCtx)
-		if err != nil {
-			return nil, fmt.Errorf("init empty repository: %w", err)
+
+		if i.flags.templatePath != "" {
+			gitUri, err := templates.Absolute(i.flags.templatePath)
+			if err != nil {
+				return nil, err
+			}
+
+			err = i.repoInitializer.Initialize(ctx, azdCtx, gitUri, i.flags.templateBranch)
+			if err != nil {
+				return nil, fmt.Errorf("init from template repository: %w", err)
+			}
+		} else if !existingProject { // do not initialize for empty if azure.yaml is present
+			err = i.repoInitializer.InitializeMinimal(ctx, azdCtx)
+			if err != nil {
+				return nil, fmt.Errorf("init empty repository: %w", err)
+			}
 		}
 	}
 
@@ -184,15 +219,53 @@ func (i *initAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		suggest:         suggest,
 	}
 
+	// The new environment defaults to the subscription and the location of the imported app
+	if importedApp != nil {
+		if envSpec.subscription == "" {
+			envSpec.subscription = importedApp.SubscriptionId
+		}
+
+		if envSpec.location == "" {
+			envSpec.location = importedApp.Location
+		}
+	}
+
 	env, err := createEnvironment(ctx, envSpec, azdCtx, i.console)
 	if err != nil {
 		return nil, fmt.Errorf("loading environment: %w", err)
 	}
 
+	if scaffoldResult != nil && len(scaffoldResult.EnvValues) > 0 {
+		for key, value := range scaffoldResult.EnvValues {
+			env.DotenvSet(key, value)
+		}
+
+		if err := env.Save(); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+	}
+
 	if err := azdCtx.SetDefaultEnvironmentName(env.GetEnvName()); err != nil {
 		return nil, fmt.Errorf("saving default environment: %w", err)
 	}
 
+	if importedApp != nil {
+		for _, note := range importedApp.Notes {
+			i.console.MessageUxItem(ctx, &ux.WarningMessage{Description: note})
+		}
+
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("New project initialized from %s!", importedApp.Name),
+				FollowUp: heredoc.Docf(`
+				Add the code of the services to their directories, ex) %s, with a Dockerfile for the services running images.
+				Then run %s to provision a copy of the app in a new environment and deploy the services.`,
+					output.WithLinkFormat("%s", filepath.Join(wd, "src", importedApp.Services[0].Name)),
+					output.WithHighLightFormat("azd up")),
+			},
+		}, nil
+	}
+
 	return &actions.ActionResult{
 		Message: &actions.ResultMessage{
 			Header: "New project initialized!",
@@ -205,6 +278,26 @@ func (i *initAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// initFromApp inspects the app of --from-app, and initializes the project reproducing it
+func (i *initAction) initFromApp(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext) (*appimport.App, *appimport.ScaffoldResult, error) {
+	spinnerMessage := fmt.Sprintf("Inspecting %s", i.flags.fromApp)
+	i.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+	app, err := i.appInspector.Inspect(ctx, i.flags.fromApp)
+	i.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, nil, fmt.Errorf("inspecting app: %w", err)
+	}
+
+	result, err := i.repoInitializer.InitializeFromApp(ctx, azdCtx, app)
+	if err != nil {
+		return nil, nil, fmt.Errorf("init from app: %w", err)
+	}
+
+	return app, result, nil
+}
+
 func getCmdInitHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription("Initialize a new application in your current directory.",
 		[]string{
@@ -231,5 +324,9 @@ func getCmdInitHelpFooter(*cobra.Command) string {
 			output.WithHighLightFormat("--branch"),
 			output.WithWarningFormat("[Branch name]"),
 		),
+		"Initialize a project reproducing an app already deployed to Azure.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd init --from-app"),
+			output.WithWarningFormat("[Resource ID]"),
+		),
 	})
 }
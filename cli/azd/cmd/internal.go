@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lsp"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/remote"
+	"github.com/azure/azure-dev/schemas"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func internalActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
+	group := root.Add("internal", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short:  "Commands used by tooling integrating with azd.",
+			Hidden: true,
+		},
+	})
+
+	group.Add("export-schema", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short: "Export a description of all commands, flags and the azure.yaml schema as JSON.",
+		},
+		ActionResolver:   newExportSchemaAction,
+		OutputFormats:    []output.Format{output.JsonFormat},
+		DefaultFormat:    output.JsonFormat,
+		DisableTelemetry: true,
+	})
+
+	group.Add("lsp", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short: "Run a language server for azure.yaml over the standard input and output.",
+		},
+		FlagsResolver:    newLspFlags,
+		ActionResolver:   newLspAction,
+		DisableTelemetry: true,
+	})
+
+	group.Add("remote-run", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short: "Run azd up in the Container Apps job of an environment, for azd up --remote.",
+		},
+		ActionResolver: newRemoteRunAction,
+	})
+
+	group.Add("api-versions", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short: "Report the ARM api-versions azd uses which have newer stable versions, or aren't available in a cloud.",
+		},
+		FlagsResolver:  newApiVersionsFlags,
+		ActionResolver: newApiVersionsAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat},
+		DefaultFormat:  output.TableFormat,
+	})
+
+	return group
+}
+
+type lspFlags struct {
+	stdio  bool
+	global *internal.GlobalCommandOptions
+}
+
+func (f *lspFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	// Language clients pass --stdio to servers communicating over the standard input and output, the only transport
+	local.BoolVar(&f.stdio, "stdio", true, "Communicate over the standard input and output.")
+	f.global = global
+}
+
+func newLspFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *lspFlags {
+	flags := &lspFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+type lspAction struct {
+	console input.Console
+}
+
+func newLspAction(console input.Console) actions.Action {
+	return &lspAction{
+		console: console,
+	}
+}
+
+func (a *lspAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	server, err := lsp.NewServer(schemas.AzureYamlAlpha)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, server.Serve(ctx, a.console.Handles().Stdin, a.console.Handles().Stdout)
+}
+
+type remoteRunAction struct {
+	worker  *remote.Worker
+	console input.Console
+}
+
+func newRemoteRunAction(worker *remote.Worker, console input.Console) actions.Action {
+	return &remoteRunAction{
+		worker:  worker,
+		console: console,
+	}
+}
+
+func (a *remoteRunAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	return nil, a.worker.Run(ctx, a.console.Handles().Stdout)
+}
+
+type exportSchemaAction struct {
+	cmd       *cobra.Command
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newExportSchemaAction(cmd *cobra.Command, formatter output.Formatter, writer io.Writer) actions.Action {
+	return &exportSchemaAction{
+		cmd:       cmd,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+func (a *exportSchemaAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	result := contracts.CliSchema{
+		Version: internal.VersionInfo().Version.String(),
+		Command: exportCommandSchema(a.cmd.Root()),
+		AzureYamlSchemas: map[string]json.RawMessage{
+			"v1.0":  schemas.AzureYamlV1,
+			"alpha": schemas.AzureYamlAlpha,
+		},
+	}
+
+	return nil, a.formatter.Format(result, a.writer, nil)
+}
+
+// exportCommandSchema describes the command and all the available (not hidden or deprecated) sub commands and flags
+func exportCommandSchema(cmd *cobra.Command) contracts.CliSchemaCommand {
+	command := contracts.CliSchemaCommand{
+		Name:        cmd.Name(),
+		Path:        cmd.CommandPath(),
+		Usage:       cmd.UseLine(),
+		Description: cmd.Short,
+		Aliases:     cmd.Aliases,
+		Flags:       []contracts.CliSchemaFlag{},
+	}
+
+	addFlags := func(flags *pflag.FlagSet, persistent bool) {
+		flags.VisitAll(func(flag *pflag.Flag) {
+			if flag.Hidden || flag.Deprecated != "" {
+				return
+			}
+
+			command.Flags = append(command.Flags, contracts.CliSchemaFlag{
+				Name:        flag.Name,
+				Shorthand:   flag.Shorthand,
+				Type:        flag.Value.Type(),
+				Default:     flag.DefValue,
+				Description: flag.Usage,
+				Persistent:  persistent,
+			})
+		})
+	}
+
+	addFlags(cmd.LocalNonPersistentFlags(), false)
+	addFlags(cmd.PersistentFlags(), true)
+
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+
+		command.Commands = append(command.Commands, exportCommandSchema(child))
+	}
+
+	return command
+}
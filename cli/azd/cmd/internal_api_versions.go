@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type apiVersionsFlags struct {
+	subscription string
+	all          bool
+}
+
+func (f *apiVersionsFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.subscription,
+		"subscription",
+		"",
+		"The subscription of the cloud to compare the api-versions with, the default subscription when not set.",
+	)
+	local.BoolVar(
+		&f.all,
+		"all",
+		false,
+		"Reports all the resource types, instead of only the ones with newer or unavailable api-versions.",
+	)
+}
+
+func newApiVersionsFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *apiVersionsFlags {
+	flags := &apiVersionsFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+type apiVersionsAction struct {
+	flags          *apiVersionsFlags
+	azCli          azcli.AzCli
+	accountManager account.Manager
+	console        input.Console
+	formatter      output.Formatter
+	writer         io.Writer
+}
+
+func newApiVersionsAction(
+	flags *apiVersionsFlags,
+	azCli azcli.AzCli,
+	accountManager account.Manager,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &apiVersionsAction{
+		flags:          flags,
+		azCli:          azCli,
+		accountManager: accountManager,
+		console:        console,
+		formatter:      formatter,
+		writer:         writer,
+	}
+}
+
+func (a *apiVersionsAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	subscriptionId := a.flags.subscription
+	if subscriptionId == "" {
+		subscriptionId = a.accountManager.GetDefaultSubscriptionID(ctx)
+	}
+	if subscriptionId == "" {
+		return nil, errors.New("no default subscription, set one with --subscription")
+	}
+
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := azsdk.LoadApiVersions(filepath.Join(configDir, azsdk.ApiVersionsFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recorded) == 0 {
+		return nil, errors.New("no api-versions are recorded yet, they're recorded when azd calls Azure Resource Manager")
+	}
+
+	resourceTypes := make([]string, 0, len(recorded))
+	for resourceType := range recorded {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	spinnerMessage := "Comparing api-versions with the resource providers"
+	a.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+
+	reports := []*azsdk.ApiVersionReport{}
+	for _, resourceType := range resourceTypes {
+		available, err := a.availableApiVersions(ctx, subscriptionId, resourceType)
+		if err != nil {
+			a.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+			return nil, err
+		}
+
+		report, err := azsdk.NewApiVersionReport(resourceType, recorded[resourceType], available)
+		if err != nil {
+			a.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+			return nil, err
+		}
+
+		if a.flags.all || report.HasUpgrade() {
+			reports = append(reports, report)
+		}
+	}
+
+	a.console.StopSpinner(ctx, spinnerMessage, input.StepDone)
+
+	if a.formatter.Kind() == output.TableFormat {
+		if len(reports) == 0 {
+			a.console.Message(ctx, "The api-versions azd uses are the latest stable api-versions of the cloud.")
+			return nil, nil
+		}
+
+		return nil, a.formatter.Format(reports, a.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{
+					Heading:       "RESOURCE TYPE",
+					ValueTemplate: "{{.ResourceType}}",
+				},
+				{
+					Heading:       "USED",
+					ValueTemplate: `{{range $i, $v := .Used}}{{if $i}}, {{end}}{{$v}}{{end}}`,
+				},
+				{
+					Heading:       "LATEST STABLE",
+					ValueTemplate: "{{.LatestStable}}",
+				},
+				{
+					Heading:       "UNAVAILABLE",
+					ValueTemplate: `{{range $i, $v := .Unavailable}}{{if $i}}, {{end}}{{$v}}{{end}}`,
+				},
+				{
+					Heading:       "NOTES",
+					ValueTemplate: `{{range $i, $v := .Notes}}{{if $i}}; {{end}}{{$v}}{{end}}`,
+				},
+			},
+		})
+	}
+
+	return nil, a.formatter.Format(reports, a.writer, nil)
+}
+
+// availableApiVersions returns the api-versions of the resource type available in the cloud of the subscription. The
+// recorded types of calls to actions, ex) Microsoft.Web/sites/config/list, end with the action, which isn't a resource
+// type; the api-versions of their parent type are returned. Returns nil for resource types the cloud doesn't know.
+func (a *apiVersionsAction) availableApiVersions(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	for candidate := resourceType; strings.Count(candidate, "/") > 0; {
+		available, err := a.azCli.GetResourceTypeApiVersions(ctx, subscriptionId, candidate)
+		if err == nil {
+			return available, nil
+		}
+
+		if !errors.Is(err, azcli.ErrResourceTypeNotFound) {
+			return nil, fmt.Errorf("getting api-versions of %s: %w", resourceType, err)
+		}
+
+		candidate = candidate[:strings.LastIndex(candidate, "/")]
+	}
+
+	log.Printf("resource type %s isn't known to the cloud of subscription %s", resourceType, subscriptionId)
+	return nil, nil
+}
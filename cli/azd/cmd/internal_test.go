@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommandSchema(t *testing.T) {
+	root := NewRootCmd(false, nil)
+	schema := exportCommandSchema(root)
+
+	require.Equal(t, "azd", schema.Name)
+
+	findCommand := func(commands []contracts.CliSchemaCommand, name string) *contracts.CliSchemaCommand {
+		for i := range commands {
+			if commands[i].Name == name {
+				return &commands[i]
+			}
+		}
+
+		return nil
+	}
+
+	// Hidden commands are not exported
+	require.Nil(t, findCommand(schema.Commands, "internal"))
+	require.Nil(t, findCommand(schema.Commands, "telemetry"))
+
+	env := findCommand(schema.Commands, "env")
+	require.NotNil(t, env)
+
+	refresh := findCommand(env.Commands, "refresh")
+	require.NotNil(t, refresh)
+	require.Equal(t, "azd env refresh", refresh.Path)
+
+	var environmentFlag *contracts.CliSchemaFlag
+	for i := range refresh.Flags {
+		if refresh.Flags[i].Name == environmentNameFlag {
+			environmentFlag = &refresh.Flags[i]
+		}
+	}
+
+	require.NotNil(t, environmentFlag)
+	require.Equal(t, "e", environmentFlag.Shorthand)
+	require.Equal(t, "string", environmentFlag.Type)
+	require.False(t, environmentFlag.Persistent)
+
+	var cwdFlag *contracts.CliSchemaFlag
+	for i := range schema.Flags {
+		if schema.Flags[i].Name == "cwd" {
+			cwdFlag = &schema.Flags[i]
+		}
+	}
+
+	require.NotNil(t, cwdFlag)
+	require.True(t, cwdFlag.Persistent)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
+)
+
+// The colors of the prefixes of the services, assigned in the order of the services
+var logsServiceColors = []func(string, ...interface{}) string{
+	output.WithHighLightFormat,
+	output.WithSuccessFormat,
+	output.WithWarningFormat,
+	output.WithLinkFormat,
+	output.WithBold,
+}
+
+type logsFlags struct {
+	serviceName string
+	since       time.Duration
+	follow      bool
+	envFlag
+}
+
+func (f *logsFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.serviceName,
+		"service",
+		"",
+		"The service to write the logs of. Defaults to all the services of the project.",
+	)
+	local.DurationVar(
+		&f.since,
+		"since",
+		15*time.Minute,
+		"Only writes the logs newer than the duration, ex) 5m or 1h.",
+	)
+	local.BoolVarP(&f.follow, "follow", "f", false, "Streams new logs until azd is interrupted.")
+	f.envFlag.Bind(local, global)
+}
+
+func newLogsFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *logsFlags {
+	flags := &logsFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newLogsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logs",
+		Short: "Write the logs of the deployed services.",
+		Args:  cobra.NoArgs,
+	}
+}
+
+type logsAction struct {
+	flags           *logsFlags
+	projectConfig   *project.ProjectConfig
+	serviceManager  project.ServiceManager
+	resourceManager project.ResourceManager
+	env             *environment.Environment
+	console         input.Console
+	formatter       output.Formatter
+	writer          io.Writer
+}
+
+func newLogsAction(
+	flags *logsFlags,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	resourceManager project.ResourceManager,
+	env *environment.Environment,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &logsAction{
+		flags:           flags,
+		projectConfig:   projectConfig,
+		serviceManager:  serviceManager,
+		resourceManager: resourceManager,
+		env:             env,
+		console:         console,
+		formatter:       formatter,
+		writer:          writer,
+	}
+}
+
+// serviceLogs is a service whose logs are written by azd logs
+type serviceLogs struct {
+	serviceConfig  *project.ServiceConfig
+	target         project.ServiceLogsTarget
+	targetResource *environment.TargetResource
+}
+
+func (a *logsAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	// The lines of the logs are streamed, they can only be written as json messages
+	if kind := a.formatter.Kind(); kind.IsStructured() && kind != output.JsonFormat {
+		return nil, fmt.Errorf("azd logs doesn't support the %s output format, use --output json", kind)
+	}
+
+	if a.env.GetSubscriptionId() == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	if a.flags.serviceName != "" && !a.projectConfig.HasService(a.flags.serviceName) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", a.flags.serviceName)
+	}
+
+	services := []serviceLogs{}
+	for _, serviceConfig := range a.projectConfig.GetServicesStable() {
+		if a.flags.serviceName != "" && serviceConfig.Name != a.flags.serviceName {
+			continue
+		}
+
+		serviceTarget, err := a.serviceManager.GetServiceTarget(ctx, serviceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("getting service target: %w", err)
+		}
+
+		logsTarget, ok := serviceTarget.(project.ServiceLogsTarget)
+		if !ok {
+			if a.flags.serviceName != "" {
+				return nil, fmt.Errorf(
+					"azd logs is not supported for service '%s' hosted on %s",
+					serviceConfig.Name,
+					serviceConfig.Host,
+				)
+			}
+
+			a.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: Skipping service %s, azd logs is not supported for %s", serviceConfig.Name, serviceConfig.Host))
+			continue
+		}
+
+		targetResource, err := a.resourceManager.GetTargetResource(ctx, a.env.GetSubscriptionId(), serviceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("getting target resource: %w", err)
+		}
+
+		services = append(services, serviceLogs{
+			serviceConfig:  serviceConfig,
+			target:         logsTarget,
+			targetResource: targetResource,
+		})
+	}
+
+	if len(services) == 0 {
+		return nil, errors.New("the project has no service supporting azd logs")
+	}
+
+	// Following the logs ends when azd is interrupted
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var logsErr error
+
+	for i, service := range services {
+		writer := &serviceLogWriter{
+			service: service.serviceConfig.Name,
+			color:   logsServiceColors[i%len(logsServiceColors)],
+			json:    a.formatter.Kind().IsStructured(),
+			mu:      &mu,
+			writer:  a.writer,
+		}
+
+		wg.Add(1)
+		go func(service serviceLogs) {
+			defer wg.Done()
+
+			err := service.target.Logs(ctx, service.serviceConfig, service.targetResource, project.LogsOptions{
+				Since:  a.flags.since,
+				Follow: a.flags.follow,
+			}, writer)
+			if flushErr := writer.Flush(); err == nil {
+				err = flushErr
+			}
+
+			if err != nil {
+				mu.Lock()
+				logsErr = multierr.Append(
+					logsErr, fmt.Errorf("writing logs of service '%s': %w", service.serviceConfig.Name, err))
+				mu.Unlock()
+			}
+		}(service)
+	}
+
+	wg.Wait()
+
+	return nil, logsErr
+}
+
+// serviceLogWriter writes each line of the logs of a service prefixed with the service, or as a json message. The
+// lines of the services are written one at a time to the shared writer.
+type serviceLogWriter struct {
+	service string
+	color   func(string, ...interface{}) string
+	json    bool
+	mu      *sync.Mutex
+	writer  io.Writer
+	// The partial line not yet ended by a line ending
+	pending []byte
+}
+
+func (w *serviceLogWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := bytes.TrimSuffix(w.pending[:i], []byte("\r"))
+		if err := w.writeLine(string(line)); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the partial line left once the logs end
+func (w *serviceLogWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	line := string(w.pending)
+	w.pending = nil
+
+	return w.writeLine(line)
+}
+
+func (w *serviceLogWriter) writeLine(line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.json {
+		return json.NewEncoder(w.writer).Encode(contracts.LogMessage{
+			Service:   w.service,
+			Timestamp: time.Now(),
+			Message:   line,
+		})
+	}
+
+	_, err := fmt.Fprintf(w.writer, "%s %s\n", w.color("%s |", w.service), line)
+	return err
+}
+
+func getCmdLogsHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Write the logs of the deployed services, each line prefixed with its service.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"AKS services write the logs of the containers of their deployment with %s.",
+				output.WithHighLightFormat("kubectl logs"),
+			)),
+			formatHelpNote(
+				"Container app services write the console logs of the replicas of the latest ready revision."),
+			formatHelpNote(fmt.Sprintf(
+				"App Service services and function apps stream their live logs and require %s.",
+				output.WithHighLightFormat("--follow"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"With %s, each line is written as a json message.",
+				output.WithHighLightFormat("--output json"),
+			)),
+		})
+}
+
+func getCmdLogsHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Write the logs of the last hour of all the services.": output.WithHighLightFormat("azd logs --since 1h"),
+		"Stream the logs of the api service.": fmt.Sprintf("%s %s %s",
+			output.WithHighLightFormat("azd logs --service"),
+			output.WithWarningFormat("api"),
+			output.WithHighLightFormat("--follow"),
+		),
+	})
+}
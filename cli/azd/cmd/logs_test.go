@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceLogWriter(t *testing.T) {
+	noColor := fmt.Sprintf
+
+	t.Run("Prefix", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		writer := &serviceLogWriter{service: "api", color: noColor, mu: &sync.Mutex{}, writer: output}
+
+		_, err := writer.Write([]byte("first\r\nsec"))
+		require.NoError(t, err)
+		_, err = writer.Write([]byte("ond\nthird"))
+		require.NoError(t, err)
+		require.Equal(t, "api | first\napi | second\n", output.String())
+
+		require.NoError(t, writer.Flush())
+		require.Equal(t, "api | first\napi | second\napi | third\n", output.String())
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		writer := &serviceLogWriter{service: "web", color: noColor, json: true, mu: &sync.Mutex{}, writer: output}
+
+		_, err := writer.Write([]byte("started\nlistening\n"))
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var message contracts.LogMessage
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &message))
+		require.Equal(t, "web", message.Service)
+		require.Equal(t, "listening", message.Message)
+		require.False(t, message.Timestamp.IsZero())
+	})
+}
+
+func Test_LogsAction_UnsupportedFormat(t *testing.T) {
+	action := &logsAction{formatter: &output.YamlFormatter{}}
+
+	_, err := action.Run(context.Background())
+	require.ErrorContains(t, err, "use --output json")
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/runs"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Runs middleware records the events of the run to the directory of the environment, for `azd runs`
+type RunsMiddleware struct {
+	options *Options
+	lazyEnv *lazy.Lazy[*environment.Environment]
+}
+
+// Creates a new Runs middleware instance
+func NewRunsMiddleware(options *Options, lazyEnv *lazy.Lazy[*environment.Environment]) Middleware {
+	return &RunsMiddleware{
+		options: options,
+		lazyEnv: lazyEnv,
+	}
+}
+
+// Invokes the middleware and records the command and its result. Child actions are recorded in the run of their parent.
+func (m *RunsMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	// reading the runs isn't recorded as a run
+	if strings.HasPrefix(m.options.CommandPath, "azd runs") {
+		return next(ctx)
+	}
+
+	if m.options.IsChildAction() {
+		if runs.RecorderFromContext(ctx) == nil {
+			return next(ctx)
+		}
+
+		return m.record(ctx, next)
+	}
+
+	recorder := runs.NewRecorder()
+	ctx = runs.WithRecorder(ctx, recorder)
+	defer func() {
+		if err := recorder.Close(); err != nil {
+			log.Printf("failed closing run %s: %v", recorder.Id(), err)
+		}
+	}()
+
+	// The environment is created while some commands run, ex) azd up, the events are kept until it's available
+	m.open(recorder)
+	result, err := m.record(ctx, next)
+	m.open(recorder)
+
+	return result, err
+}
+
+// records the command and its result in the run of ctx
+func (m *RunsMiddleware) record(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	event := &runs.Event{
+		Type:    runs.CommandEvent,
+		Name:    m.options.CommandPath,
+		Version: internal.VersionInfo().Version.String(),
+	}
+	if env, err := m.lazyEnv.GetValue(); err == nil && env != nil {
+		event.Environment = env.GetEnvName()
+	}
+	if m.options.Flags != nil {
+		m.options.Flags.Visit(func(f *pflag.Flag) {
+			event.Flags = append(event.Flags, f.Name)
+		})
+	}
+	runs.Record(ctx, event)
+
+	started := time.Now()
+	result, err := next(ctx)
+
+	resultEvent := &runs.Event{
+		Type:       runs.ResultEvent,
+		Name:       m.options.CommandPath,
+		Status:     runs.StatusOf(err),
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		resultEvent.Error = err.Error()
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		resultEvent.TraceId = spanContext.TraceID().String()
+	}
+	runs.Record(ctx, resultEvent)
+
+	return result, err
+}
+
+// opens the recorder in the directory of the environment, when the environment is available
+func (m *RunsMiddleware) open(recorder *runs.Recorder) {
+	if recorder.IsOpen() {
+		return
+	}
+
+	env, err := m.lazyEnv.GetValue()
+	if err != nil || env == nil || env.Root == "" {
+		return
+	}
+
+	if err := recorder.Open(env.Root); err != nil {
+		log.Printf("failed recording run %s: %v", recorder.Id(), err)
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/runs"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runs_Run(t *testing.T) {
+	t.Run("RecordsCommandAndChildActions", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", nil)
+		env.Root = t.TempDir()
+		available := false
+		lazyEnv := lazy.NewLazy(func() (*environment.Environment, error) {
+			if !available {
+				return nil, errors.New("environment not found")
+			}
+			return env, nil
+		})
+
+		rootMiddleware := NewRunsMiddleware(&Options{CommandPath: "azd up"}, lazyEnv)
+		childMiddleware := NewRunsMiddleware(&Options{CommandPath: "azd provision", isChildAction: true}, lazyEnv)
+
+		var recorder *runs.Recorder
+		_, err := rootMiddleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			recorder = runs.RecorderFromContext(ctx)
+			require.NotNil(t, recorder)
+
+			// the environment is created while the command runs
+			available = true
+
+			return childMiddleware.Run(ctx, func(ctx context.Context) (*actions.ActionResult, error) {
+				runs.Record(ctx, &runs.Event{Type: runs.StepEvent, Name: "Creating resources", Status: runs.StatusFailed})
+				return nil, errors.New("deployment failed")
+			})
+		})
+		require.Error(t, err)
+
+		events, err := runs.Read(env.Root, recorder.Id())
+		require.NoError(t, err)
+
+		summary := []string{}
+		for _, event := range events {
+			summary = append(summary, string(event.Type)+" "+event.Name+" "+string(event.Status))
+		}
+		require.Equal(t, []string{
+			"command azd up ",
+			"command azd provision ",
+			"step Creating resources failed",
+			"result azd provision failed",
+			"result azd up failed",
+		}, summary)
+		require.Equal(t, "deployment failed", events[4].Error)
+		require.Equal(t, "dev", events[1].Environment)
+	})
+
+	t.Run("NoEnvironment", func(t *testing.T) {
+		lazyEnv := lazy.NewLazy(func() (*environment.Environment, error) {
+			return nil, errors.New("environment not found")
+		})
+
+		middleware := NewRunsMiddleware(&Options{CommandPath: "azd deploy"}, lazyEnv)
+		ran := false
+		_, err := middleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			ran = true
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("RunsCommands", func(t *testing.T) {
+		lazyEnv := lazy.NewLazy(func() (*environment.Environment, error) {
+			return nil, errors.New("environment not found")
+		})
+
+		middleware := NewRunsMiddleware(&Options{CommandPath: "azd runs list"}, lazyEnv)
+		_, err := middleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			require.Nil(t, runs.RecorderFromContext(ctx))
+			return nil, nil
+		})
+		require.NoError(t, err)
+	})
+}
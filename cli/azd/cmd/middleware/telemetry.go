@@ -8,9 +8,11 @@ import (
 	"log"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/events"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/fields"
@@ -71,7 +73,19 @@ func (m *TelemetryMiddleware) Run(ctx context.Context, next NextFn) (*actions.Ac
 		span.End()
 	}()
 
+	start := time.Now()
 	result, err := next(spanCtx)
+	if !m.options.IsChildAction() {
+		commandResult := "success"
+		if err != nil {
+			commandResult = "failure"
+		}
+
+		tags := map[string]string{"command": cmdPath, "result": commandResult}
+		metrics.Add(metrics.Commands, 1, tags)
+		metrics.Add(metrics.CommandDuration, time.Since(start).Milliseconds(), tags)
+	}
+
 	if result == nil {
 		result = &actions.ActionResult{}
 	}
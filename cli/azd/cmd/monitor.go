@@ -7,18 +7,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/alerts"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/cli/browser"
 	"github.com/spf13/cobra"
@@ -29,6 +34,9 @@ type monitorFlags struct {
 	monitorLive     bool
 	monitorLogs     bool
 	monitorOverview bool
+	initAlerts      bool
+	alertEmails     []string
+	alertWebhooks   []string
 	global          *internal.GlobalCommandOptions
 	envFlag
 }
@@ -42,6 +50,24 @@ func (m *monitorFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommand
 	)
 	local.BoolVar(&m.monitorLogs, "logs", false, "Open a browser to Application Insights Logs.")
 	local.BoolVar(&m.monitorOverview, "overview", false, "Open a browser to Application Insights Overview Dashboard.")
+	local.BoolVar(
+		&m.initAlerts,
+		"init-alerts",
+		false,
+		"Creates metric alerts, ex) on server errors and CPU usage, on the resources hosting the services.",
+	)
+	local.StringSliceVar(
+		&m.alertEmails,
+		"alert-email",
+		nil,
+		"Email addresses notified when the alerts created by --init-alerts fire.",
+	)
+	local.StringSliceVar(
+		&m.alertWebhooks,
+		"alert-webhook",
+		nil,
+		"Webhooks notified when the alerts created by --init-alerts fire.",
+	)
 	m.envFlag.Bind(local, global)
 	m.global = global
 }
@@ -61,12 +87,16 @@ func newMonitorCmd() *cobra.Command {
 }
 
 type monitorAction struct {
-	azdCtx      *azdcontext.AzdContext
-	env         *environment.Environment
-	subResolver account.SubscriptionTenantResolver
-	azCli       azcli.AzCli
-	console     input.Console
-	flags       *monitorFlags
+	azdCtx        *azdcontext.AzdContext
+	env           *environment.Environment
+	subResolver   account.SubscriptionTenantResolver
+	azCli         azcli.AzCli
+	console       input.Console
+	flags         *monitorFlags
+	projectConfig *lazy.Lazy[*project.ProjectConfig]
+	alertsManager *alerts.Manager
+	formatter     output.Formatter
+	writer        io.Writer
 }
 
 func newMonitorAction(
@@ -76,18 +106,38 @@ func newMonitorAction(
 	azCli azcli.AzCli,
 	console input.Console,
 	flags *monitorFlags,
+	projectConfig *lazy.Lazy[*project.ProjectConfig],
+	alertsManager *alerts.Manager,
+	formatter output.Formatter,
+	writer io.Writer,
 ) actions.Action {
 	return &monitorAction{
-		azdCtx:      azdCtx,
-		env:         env,
-		azCli:       azCli,
-		console:     console,
-		flags:       flags,
-		subResolver: subResolver,
+		azdCtx:        azdCtx,
+		env:           env,
+		azCli:         azCli,
+		console:       console,
+		flags:         flags,
+		subResolver:   subResolver,
+		projectConfig: projectConfig,
+		alertsManager: alertsManager,
+		formatter:     formatter,
+		writer:        writer,
 	}
 }
 
 func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if (len(m.flags.alertEmails) > 0 || len(m.flags.alertWebhooks) > 0) && !m.flags.initAlerts {
+		return nil, errors.New("'--alert-email' and '--alert-webhook' can only be specified with '--init-alerts'")
+	}
+
+	if m.flags.initAlerts {
+		if m.flags.monitorLive || m.flags.monitorLogs || m.flags.monitorOverview {
+			return nil, errors.New("'--init-alerts' cannot be combined with '--live', '--logs' or '--overview'")
+		}
+
+		return m.initAlerts(ctx)
+	}
+
 	if !m.flags.monitorLive && !m.flags.monitorLogs && !m.flags.monitorOverview {
 		m.flags.monitorOverview = true
 	}
@@ -187,6 +237,61 @@ func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	return nil, nil
 }
 
+// initAlerts creates the default alerts of the services of the project, and lists them
+func (m *monitorAction) initAlerts(ctx context.Context) (*actions.ActionResult, error) {
+	projectConfig, err := m.projectConfig.GetValue()
+	if err != nil {
+		return nil, err
+	}
+
+	spinnerMessage := "Creating alerts"
+	m.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+	result, err := m.alertsManager.Init(ctx, projectConfig, alerts.Receivers{
+		Emails:   m.flags.alertEmails,
+		Webhooks: m.flags.alertWebhooks,
+	})
+	m.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	if m.formatter.Kind().IsStructured() {
+		if err := m.formatter.Format(result, m.writer, nil); err != nil {
+			return nil, fmt.Errorf("alerts could not be displayed: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	m.console.Message(ctx, "")
+	for _, alert := range result.Alerts {
+		m.console.Message(ctx, fmt.Sprintf(
+			"  %s %s",
+			output.WithHighLightFormat(alert.Name),
+			output.WithGrayFormat("(%s: %s)", strings.Join(alert.Services, ", "), alert.Description)))
+	}
+	if len(result.Skipped) > 0 {
+		m.console.Message(ctx, output.WithGrayFormat(
+			"  No default alerts for the hosts of %s", strings.Join(result.Skipped, ", ")))
+	}
+	m.console.Message(ctx, "")
+
+	followUp := ""
+	if result.ActionGroupId == "" {
+		followUp = fmt.Sprintf(
+			"The alerts notify no one, set %s or %s to be notified when they fire.",
+			output.WithHighLightFormat("--alert-email"),
+			output.WithHighLightFormat("--alert-webhook"))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header:   fmt.Sprintf("Created %d alert(s) for the services of environment %s.", len(result.Alerts), m.env.GetEnvName()),
+			FollowUp: followUp,
+		},
+	}, nil
+}
+
 func getCmdMonitorHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		fmt.Sprintf("Monitor a deployed application %s. For more information, go to: %s.",
@@ -199,5 +304,7 @@ func getCmdMonitorHelpFooter(c *cobra.Command) string {
 		"Open Application Insights Overview Dashboard.": output.WithHighLightFormat("azd monitor --overview"),
 		"Open Application Insights Live Metrics.":       output.WithHighLightFormat("azd monitor --live"),
 		"Open Application Insights Logs.":               output.WithHighLightFormat("azd monitor --logs"),
+		"Create alerts on the services, notifying an email address.": output.WithHighLightFormat(
+			"azd monitor --init-alerts --alert-email ops@contoso.com"),
 	})
 }
@@ -18,8 +18,10 @@ import (
 )
 
 type packageFlags struct {
-	all    bool
-	global *internal.GlobalCommandOptions
+	all     bool
+	tag     string
+	noCache bool
+	global  *internal.GlobalCommandOptions
 	*envFlag
 }
 
@@ -43,6 +45,19 @@ func (pf *packageFlags) Bind(local *pflag.FlagSet, global *internal.GlobalComman
 		false,
 		"Deploys all services that are listed in "+azdcontext.ProjectFileName,
 	)
+	local.StringVar(
+		&pf.tag,
+		"tag",
+		"",
+		"Tags the container images of the services with the tag, instead of the tag configured in "+
+			azdcontext.ProjectFileName+".",
+	)
+	local.BoolVar(
+		&pf.noCache,
+		"no-cache",
+		false,
+		"Restores and builds the services even when their dependencies and source didn't change since their last build.",
+	)
 }
 
 func newPackageCmd() *cobra.Command {
@@ -54,18 +69,21 @@ func newPackageCmd() *cobra.Command {
 		),
 	}
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
 	return cmd
 }
 
 type packageAction struct {
-	flags          *packageFlags
-	args           []string
-	projectConfig  *project.ProjectConfig
-	projectManager project.ProjectManager
-	serviceManager project.ServiceManager
-	console        input.Console
-	formatter      output.Formatter
-	writer         io.Writer
+	flags           *packageFlags
+	args            []string
+	projectConfig   *project.ProjectConfig
+	projectManager  project.ProjectManager
+	serviceManager  project.ServiceManager
+	containerHelper *project.ContainerHelper
+	buildCache      *project.BuildCache
+	console         input.Console
+	formatter       output.Formatter
+	writer          io.Writer
 }
 
 func newPackageAction(
@@ -74,19 +92,23 @@ func newPackageAction(
 	projectConfig *project.ProjectConfig,
 	projectManager project.ProjectManager,
 	serviceManager project.ServiceManager,
+	containerHelper *project.ContainerHelper,
+	buildCache *project.BuildCache,
 	console input.Console,
 	formatter output.Formatter,
 	writer io.Writer,
 ) actions.Action {
 	return &packageAction{
-		flags:          flags,
-		args:           args,
-		projectConfig:  projectConfig,
-		projectManager: projectManager,
-		serviceManager: serviceManager,
-		console:        console,
-		formatter:      formatter,
-		writer:         writer,
+		flags:           flags,
+		args:            args,
+		projectConfig:   projectConfig,
+		projectManager:  projectManager,
+		serviceManager:  serviceManager,
+		containerHelper: containerHelper,
+		buildCache:      buildCache,
+		console:         console,
+		formatter:       formatter,
+		writer:          writer,
 	}
 }
 
@@ -103,6 +125,10 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	startTime := time.Now()
 
+	if pa.flags.noCache {
+		pa.buildCache.Disable()
+	}
+
 	targetServiceName := ""
 	if len(pa.args) == 1 {
 		targetServiceName = pa.args[0]
@@ -120,12 +146,18 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, err
 	}
 
+	if pa.flags.tag != "" {
+		if err := pa.containerHelper.SetImageTag(pa.flags.tag); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := pa.projectManager.Initialize(ctx, pa.projectConfig); err != nil {
 		return nil, err
 	}
 
 	if err := pa.projectManager.EnsureAllTools(ctx, pa.projectConfig, func(svc *project.ServiceConfig) bool {
-		return targetServiceName == "" || svc.Name == targetServiceName
+		return svc.IsDeployed() && (targetServiceName == "" || svc.Name == targetServiceName)
 	}); err != nil {
 		return nil, err
 	}
@@ -144,6 +176,12 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			continue
 		}
 
+		// Services with deploy: false only define infrastructure
+		if !svc.IsDeployed() {
+			pa.console.StopSpinner(ctx, stepMessage, input.StepSkipped)
+			continue
+		}
+
 		packageTask := pa.serviceManager.Package(ctx, svc, nil)
 		go func() {
 			for packageProgress := range packageTask.Progress() {
@@ -165,7 +203,7 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		pa.console.MessageUxItem(ctx, packageResult)
 	}
 
-	if pa.formatter.Kind() == output.JsonFormat {
+	if pa.formatter.Kind().IsStructured() {
 		packageResult := PackageResult{
 			Timestamp: time.Now(),
 			Services:  packageResults,
@@ -2,25 +2,40 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/diagnostics"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	infraBicep "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/policy"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/rbac"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.uber.org/multierr"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 type provisionFlags struct {
 	noProgress bool
+	attach     bool
+	breakLock  bool
+	locations  []string
+	network    string
 	global     *internal.GlobalCommandOptions
 	*envFlag
 }
@@ -28,12 +43,38 @@ type provisionFlags struct {
 func (i *provisionFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	i.bindNonCommon(local, global)
 	i.bindCommon(local, global)
+	local.BoolVar(
+		&i.attach,
+		"attach",
+		false,
+		"Attaches to the in-flight deployment started by a previous azd process instead of starting a new one.",
+	)
+	local.StringSliceVar(
+		&i.locations,
+		"locations",
+		nil,
+		"Provisions a regional stamp of the infrastructure in each of the comma separated locations, "+
+			"ex) eastus,westeurope. The first location is the primary location of the environment.",
+	)
 }
 
 func (i *provisionFlags) bindNonCommon(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	local.BoolVar(&i.noProgress, "no-progress", false, "Suppresses progress information.")
 	//deprecate:Flag hide --no-progress
 	_ = local.MarkHidden("no-progress")
+	local.BoolVar(
+		&i.breakLock,
+		"break-lock",
+		false,
+		"Removes the lock of the environment held by another azd process before provisioning.",
+	)
+	local.StringVar(
+		&i.network,
+		"network",
+		"",
+		"Provisions the resources with public network access (public), or with private endpoints, VNet integration "+
+			"and public network access disabled (private). Overrides infra.network of azure.yaml.",
+	)
 	i.global = global
 }
 
@@ -70,6 +111,10 @@ type provisionAction struct {
 	projectConfig    *project.ProjectConfig
 	writer           io.Writer
 	console          input.Console
+	policyEngine     *policy.Engine
+	rbacManager      *rbac.Manager
+	azdCtx           *azdcontext.AzdContext
+	diagnostics      *diagnostics.Manager
 }
 
 func newProvisionAction(
@@ -82,6 +127,10 @@ func newProvisionAction(
 	console input.Console,
 	formatter output.Formatter,
 	writer io.Writer,
+	policyEngine *policy.Engine,
+	rbacManager *rbac.Manager,
+	azdCtx *azdcontext.AzdContext,
+	diagnosticsManager *diagnostics.Manager,
 ) actions.Action {
 	return &provisionAction{
 		flags:            flags,
@@ -93,6 +142,10 @@ func newProvisionAction(
 		projectConfig:    projectConfig,
 		writer:           writer,
 		console:          console,
+		policyEngine:     policyEngine,
+		rbacManager:      rbacManager,
+		azdCtx:           azdCtx,
+		diagnostics:      diagnosticsManager,
 	}
 }
 
@@ -113,8 +166,40 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		TitleNote: "Provisioning Azure resources can take some time"},
 	)
 
+	if p.flags.attach && len(p.flags.locations) > 0 {
+		return nil, errors.New("'--attach' and '--locations' cannot be used together")
+	}
+
+	if p.flags.network != "" {
+		networkMode, err := provisioning.ParseNetworkMode(p.flags.network)
+		if err != nil {
+			return nil, err
+		}
+
+		p.projectConfig.Infra.Network = networkMode
+	}
+
+	lock, err := p.env.Lock("provision", p.flags.breakLock)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
 	startTime := time.Now()
 
+	// The values of the shared environment, ex) the platform resources of a hub, are imported before the parameters of
+	// the deployment are resolved.
+	imported, err := p.env.ImportShared(p.azdCtx)
+	if err != nil {
+		return nil, fmt.Errorf("importing shared environment: %w", err)
+	}
+
+	importedKeys := maps.Keys(imported)
+	slices.Sort(importedKeys)
+	for _, key := range importedKeys {
+		p.console.Message(ctx, fmt.Sprintf("Imported %s from the shared environment", output.WithHighLightFormat(key)))
+	}
+
 	if err := p.projectManager.Initialize(ctx, p.projectConfig); err != nil {
 		return nil, err
 	}
@@ -129,19 +214,40 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		Project: p.projectConfig,
 	}
 
-	err := p.projectConfig.Invoke(ctx, project.ProjectEventProvision, projectEventArgs, func() error {
+	err = p.projectConfig.Invoke(ctx, project.ProjectEventProvision, projectEventArgs, func() error {
+		// The infrastructure of an in-flight deployment was linted when it started
+		if !p.flags.attach {
+			if err := p.lintInfra(ctx); err != nil {
+				return err
+			}
+		}
+
+		if len(p.flags.locations) > 0 {
+			deployResult, err = p.provisionManager.DeployToLocations(ctx, p.flags.locations, p.checkPolicies)
+			return err
+		}
+
 		deploymentPlan, err := p.provisionManager.Plan(ctx)
 		if err != nil {
 			return fmt.Errorf("planning deployment: %w", err)
 		}
 
+		if p.flags.attach {
+			deployResult, err = p.provisionManager.Attach(ctx, deploymentPlan)
+			return err
+		}
+
+		if err := p.checkPolicies(ctx, deploymentPlan); err != nil {
+			return err
+		}
+
 		deployResult, err = p.provisionManager.Deploy(ctx, deploymentPlan)
 
 		return err
 	})
 
 	if err != nil {
-		if p.formatter.Kind() == output.JsonFormat {
+		if p.formatter.Kind().IsStructured() {
 			stateResult, err := p.provisionManager.State(ctx)
 			if err != nil {
 				return nil, fmt.Errorf(
@@ -176,7 +282,20 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		}
 	}
 
-	if p.formatter.Kind() == output.JsonFormat {
+	if err := p.applyRoleAssignments(ctx); err != nil {
+		return nil, fmt.Errorf("applying role assignments: %w", err)
+	}
+
+	if err := p.applyDiagnosticSettings(ctx); err != nil {
+		return nil, fmt.Errorf("applying diagnostic settings: %w", err)
+	}
+
+	if err := pipeline.PublishOutputs(
+		p.env, p.projectConfig.Pipeline.Outputs, p.console.Handles().Stdout); err != nil {
+		return nil, fmt.Errorf("publishing pipeline outputs: %w", err)
+	}
+
+	if p.formatter.Kind().IsStructured() {
 		stateResult, err := p.provisionManager.State(ctx)
 		if err != nil {
 			return nil, fmt.Errorf(
@@ -204,6 +323,167 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 	}, nil
 }
 
+// lintInfra reports the diagnostics of the linter of the infrastructure, grouped by file, and fails when some are of
+// severity error
+func (p *provisionAction) lintInfra(ctx context.Context) error {
+	infraOptions := p.projectConfig.Infra
+	if infraOptions.Lint.IsSkipped() || (infraOptions.Provider != "" && infraOptions.Provider != provisioning.Bicep) {
+		return nil
+	}
+
+	spinnerMessage := "Linting infrastructure"
+	p.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+	result, err := p.provisionManager.Lint(ctx)
+	if err != nil {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+		return err
+	}
+
+	switch {
+	case result == nil:
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepSkipped)
+		return nil
+	case result.Count(provisioning.LintSeverityError) > 0:
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+	case len(result.Files) > 0:
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepWarning)
+	default:
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepDone)
+		return nil
+	}
+
+	printLintResult(ctx, p.console, result)
+
+	if result.Count(provisioning.LintSeverityError) > 0 {
+		return &provisioning.LintError{Result: result}
+	}
+
+	return nil
+}
+
+// checkPolicies warns about the planned resources the Azure Policy assignments of the subscription would deny, and
+// evaluates the rendered deployment template against the configured policies.
+// Only ARM based deployment plans (Bicep) are currently evaluated.
+func (p *provisionAction) checkPolicies(ctx context.Context, deploymentPlan *provisioning.DeploymentPlan) error {
+	if p.projectConfig.Policy != nil && p.projectConfig.Policy.SimulateAssignments {
+		if err := p.simulatePolicyAssignments(ctx, deploymentPlan); err != nil {
+			return err
+		}
+	}
+
+	if p.projectConfig.Policy == nil || !p.projectConfig.Policy.Enabled {
+		return nil
+	}
+
+	details, ok := deploymentPlan.Details.(infraBicep.BicepDeploymentDetails)
+	if !ok {
+		log.Printf("skipping policy evaluation, provider '%s' is not supported", p.projectConfig.Infra.Provider)
+		return nil
+	}
+
+	p.console.ShowSpinner(ctx, "Evaluating policies", input.Step)
+	err := p.policyEngine.Check(ctx, p.projectConfig.Path, p.projectConfig.Policy, policy.KindArm, map[string]any{
+		"template":   details.Template,
+		"parameters": details.Parameters,
+	})
+	if err != nil {
+		p.console.StopSpinner(ctx, "Evaluating policies", input.StepFailed)
+		return fmt.Errorf("policy check failed: %w", err)
+	}
+
+	p.console.StopSpinner(ctx, "Evaluating policies", input.StepDone)
+	return nil
+}
+
+// simulatePolicyAssignments warns about the planned resources the Azure Policy assignments of the subscription would
+// deny, ex) public IP addresses or locations the subscription doesn't allow, before provisioning fails on them.
+func (p *provisionAction) simulatePolicyAssignments(
+	ctx context.Context,
+	deploymentPlan *provisioning.DeploymentPlan,
+) error {
+	p.console.ShowSpinner(ctx, "Simulating Azure Policy assignments", input.Step)
+	result, err := p.provisionManager.SimulatePolicies(ctx, deploymentPlan)
+	p.console.StopSpinner(ctx, "Simulating Azure Policy assignments", input.GetStepResultFormat(err))
+	if err != nil {
+		return err
+	}
+
+	if result == nil || len(result.Denials) == 0 {
+		return nil
+	}
+
+	p.console.Message(ctx, output.WithWarningFormat(
+		"WARNING: Azure Policy assignments of the subscription would deny some of the %d planned resources:",
+		result.Evaluated))
+	for _, denial := range result.Denials {
+		message := fmt.Sprintf("  - %s: %s (%s)", denial.ResourceId, denial.PolicyDefinition, denial.PolicyAssignment)
+		if denial.Reason != "" {
+			message += fmt.Sprintf(", %s", denial.Reason)
+		}
+		p.console.Message(ctx, output.WithWarningFormat("%s", message))
+	}
+
+	return nil
+}
+
+// applyRoleAssignments assigns the roles declared in azure.yaml which aren't assigned already.
+func (p *provisionAction) applyRoleAssignments(ctx context.Context) error {
+	roleAssignments, err := p.rbacManager.Resolve(ctx, p.projectConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, roleAssignment := range roleAssignments {
+		if roleAssignment.Assigned {
+			log.Printf("role assignment '%s' is already assigned", roleAssignment)
+			continue
+		}
+
+		spinnerMessage := fmt.Sprintf("Assigning role %s", output.WithHighLightFormat(roleAssignment.String()))
+		p.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+		err := p.rbacManager.Apply(ctx, roleAssignment)
+		p.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyDiagnosticSettings sends the logs and metrics of the provisioned resources to the Log Analytics workspace, when
+// diagnostics are configured in azure.yaml, and reports the resources which don't support diagnostic settings.
+func (p *provisionAction) applyDiagnosticSettings(ctx context.Context) error {
+	settings, err := p.diagnostics.Resolve(ctx, p.projectConfig)
+	if err != nil {
+		return err
+	}
+
+	unsupported := []string{}
+	for _, setting := range settings {
+		if !setting.Supported() {
+			log.Printf("resource '%s' doesn't support the configured diagnostics", setting.ResourceId)
+			unsupported = append(unsupported, setting.ResourceName)
+			continue
+		}
+
+		spinnerMessage := fmt.Sprintf("Sending diagnostics of %s", output.WithHighLightFormat(setting.String()))
+		p.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+		err := p.diagnostics.Apply(ctx, setting)
+		p.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(unsupported) > 0 {
+		p.console.Message(ctx, output.WithGrayFormat(
+			"Diagnostics were not configured for %s, which don't support them", strings.Join(unsupported, ", ")))
+	}
+
+	return nil
+}
+
 func getCmdProvisionHelpDescription(c *cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Provision the Azure resources for an application."+
@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type restartFlags struct {
+	serviceName string
+	envFlag
+}
+
+func (f *restartFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&f.serviceName, "service", "", "The service to restart.")
+	f.envFlag.Bind(local, global)
+}
+
+func newRestartFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *restartFlags {
+	flags := &restartFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newRestartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restart",
+		Short: "Restart the deployed compute of a service.",
+		Args:  cobra.NoArgs,
+	}
+}
+
+type restartAction struct {
+	flags           *restartFlags
+	projectConfig   *project.ProjectConfig
+	serviceManager  project.ServiceManager
+	resourceManager project.ResourceManager
+	env             *environment.Environment
+	console         input.Console
+}
+
+func newRestartAction(
+	flags *restartFlags,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	resourceManager project.ResourceManager,
+	env *environment.Environment,
+	console input.Console,
+) actions.Action {
+	return &restartAction{
+		flags:           flags,
+		projectConfig:   projectConfig,
+		serviceManager:  serviceManager,
+		resourceManager: resourceManager,
+		env:             env,
+		console:         console,
+	}
+}
+
+func (a *restartAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.env.GetSubscriptionId() == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	if a.flags.serviceName == "" {
+		return nil, errors.New("specify the service to restart with --service")
+	}
+
+	if !a.projectConfig.HasService(a.flags.serviceName) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", a.flags.serviceName)
+	}
+
+	serviceConfig := a.projectConfig.Services[a.flags.serviceName]
+	serviceTarget, err := a.serviceManager.GetServiceTarget(ctx, serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting service target: %w", err)
+	}
+
+	restarter, ok := serviceTarget.(project.Restarter)
+	if !ok {
+		return nil, fmt.Errorf(
+			"azd restart is not supported for service '%s' hosted on %s, supported hosts are aks, containerapp, "+
+				"appservice and function",
+			serviceConfig.Name,
+			serviceConfig.Host,
+		)
+	}
+
+	targetResource, err := a.resourceManager.GetTargetResource(ctx, a.env.GetSubscriptionId(), serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting target resource: %w", err)
+	}
+
+	displayMsg := fmt.Sprintf("Restarting service %s (%s)", serviceConfig.Name, serviceConfig.Host)
+	a.console.ShowSpinner(ctx, displayMsg, input.Step)
+	err = restarter.Restart(ctx, serviceConfig, targetResource)
+	a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, fmt.Errorf("restarting service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Service %s was restarted.", serviceConfig.Name),
+		},
+	}, nil
+}
+
+func getCmdRestartHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Restart the deployed compute of a service, without deploying the service again.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"AKS services restart the pods of their deployment with %s.",
+				output.WithHighLightFormat("kubectl rollout restart"),
+			)),
+			formatHelpNote("Container app services restart their latest revision."),
+			formatHelpNote("App Service services and function apps restart their app."),
+		})
+}
+
+func getCmdRestartHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Restart the api service.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd restart --service"),
+			output.WithWarningFormat("api"),
+		),
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
@@ -20,10 +21,12 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 )
 
 type restoreFlags struct {
 	all         bool
+	noCache     bool
 	global      *internal.GlobalCommandOptions
 	serviceName string
 	envFlag
@@ -45,6 +48,12 @@ func (r *restoreFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommand
 	)
 	//deprecate:flag hide --service
 	_ = local.MarkHidden("service")
+	local.BoolVar(
+		&r.noCache,
+		"no-cache",
+		false,
+		"Restores the services even when their dependencies didn't change since their last restore.",
+	)
 }
 
 func newRestoreFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *restoreFlags {
@@ -62,6 +71,7 @@ func newRestoreCmd() *cobra.Command {
 		Short: fmt.Sprintf("Restores the application's dependencies. %s", output.WithWarningFormat("(Beta)")),
 	}
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
 	return cmd
 }
 
@@ -76,6 +86,7 @@ type restoreAction struct {
 	projectConfig  *project.ProjectConfig
 	projectManager project.ProjectManager
 	serviceManager project.ServiceManager
+	buildCache     *project.BuildCache
 	commandRunner  exec.CommandRunner
 }
 
@@ -90,6 +101,7 @@ func newRestoreAction(
 	projectConfig *project.ProjectConfig,
 	projectManager project.ProjectManager,
 	serviceManager project.ServiceManager,
+	buildCache *project.BuildCache,
 	commandRunner exec.CommandRunner,
 ) actions.Action {
 	return &restoreAction{
@@ -102,6 +114,7 @@ func newRestoreAction(
 		projectConfig:  projectConfig,
 		projectManager: projectManager,
 		serviceManager: serviceManager,
+		buildCache:     buildCache,
 		env:            env,
 		commandRunner:  commandRunner,
 	}
@@ -122,6 +135,10 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	serviceNameWarningCheck(ra.console, ra.flags.serviceName, "restore")
 
+	if ra.flags.noCache {
+		ra.buildCache.Disable()
+	}
+
 	targetServiceName := ra.flags.serviceName
 	if len(ra.args) == 1 {
 		targetServiceName = ra.args[0]
@@ -149,8 +166,46 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, err
 	}
 
+	// Services are restored in parallel, the result of each service is displayed once all services are restored
 	restoreResults := map[string]*project.ServiceRestoreResult{}
+	restoreErrors := map[string]error{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
+	ra.console.ShowSpinner(ctx, "Restoring services", input.Step)
+	for _, svc := range ra.projectConfig.GetServicesStable() {
+		if targetServiceName != "" && targetServiceName != svc.Name {
+			continue
+		}
+
+		wg.Add(1)
+		go func(svc *project.ServiceConfig) {
+			defer wg.Done()
+
+			restoreTask := ra.serviceManager.Restore(ctx, svc)
+			go func() {
+				for restoreProgress := range restoreTask.Progress() {
+					progressMessage := fmt.Sprintf("Restoring service %s (%s)", svc.Name, restoreProgress.Message)
+					mu.Lock()
+					ra.console.ShowSpinner(ctx, progressMessage, input.Step)
+					mu.Unlock()
+				}
+			}()
+
+			restoreResult, err := restoreTask.Await()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				restoreErrors[svc.Name] = err
+				return
+			}
+			restoreResults[svc.Name] = restoreResult
+		}(svc)
+	}
+	wg.Wait()
+
+	var restoreErr error
 	for _, svc := range ra.projectConfig.GetServicesStable() {
 		stepMessage := fmt.Sprintf("Restoring service %s", svc.Name)
 		ra.console.ShowSpinner(ctx, stepMessage, input.Step)
@@ -163,25 +218,20 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			continue
 		}
 
-		restoreTask := ra.serviceManager.Restore(ctx, svc)
-		go func() {
-			for restoreProgress := range restoreTask.Progress() {
-				progressMessage := fmt.Sprintf("Restoring service %s (%s)", svc.Name, restoreProgress.Message)
-				ra.console.ShowSpinner(ctx, progressMessage, input.Step)
-			}
-		}()
-
-		restoreResult, err := restoreTask.Await()
-		if err != nil {
+		if err, has := restoreErrors[svc.Name]; has {
 			ra.console.StopSpinner(ctx, stepMessage, input.StepFailed)
-			return nil, err
+			restoreErr = multierr.Append(restoreErr, err)
+			continue
 		}
 
 		ra.console.StopSpinner(ctx, stepMessage, input.StepDone)
-		restoreResults[svc.Name] = restoreResult
 	}
 
-	if ra.formatter.Kind() == output.JsonFormat {
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+
+	if ra.formatter.Kind().IsStructured() {
 		restoreResult := RestoreResult{
 			Timestamp: time.Now(),
 			Services:  restoreResults,
@@ -38,7 +38,17 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		Use:   "azd",
 		Short: fmt.Sprintf("%s is an open-source tool that helps onboard and manage your application on Azure", productName),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if opts.Cwd != "" {
+			workspaceDir, err := workspaceDirectory(cmd, opts)
+			if err != nil {
+				return err
+			}
+
+			dir := opts.Cwd
+			if workspaceDir != "" {
+				dir = workspaceDir
+			}
+
+			if dir != "" {
 				current, err := os.Getwd()
 
 				if err != nil {
@@ -47,8 +57,8 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 
 				prevDir = current
 
-				if err := os.Chdir(opts.Cwd); err != nil {
-					return fmt.Errorf("failed to change directory to %s: %w", opts.Cwd, err)
+				if err := os.Chdir(dir); err != nil {
+					return fmt.Errorf("failed to change directory to %s: %w", dir, err)
 				}
 			}
 
@@ -74,6 +84,12 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		Command: rootCmd,
 		FlagsResolver: func(cmd *cobra.Command) *internal.GlobalCommandOptions {
 			rootCmd.PersistentFlags().StringVarP(&opts.Cwd, "cwd", "C", "", "Sets the current working directory.")
+			rootCmd.PersistentFlags().StringVarP(
+				&opts.Workspace,
+				"workspace",
+				"w",
+				"",
+				"Runs the command in the project of the registered workspace, instead of the current working directory.")
 			rootCmd.PersistentFlags().
 				BoolVar(&opts.EnableDebugLogging, "debug", false, "Enables debugging and diagnostics logging.")
 			rootCmd.PersistentFlags().
@@ -98,15 +114,18 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 
 			return opts
 		},
-	})
+	}).AddFlagCompletion("workspace", workspaceNameCompletion)
 
 	configActions(root, opts)
 	envActions(root)
+	workspaceActions(root)
 	infraActions(root)
 	pipelineActions(root)
+	runsActions(root)
 	telemetryActions(root)
 	templatesActions(root)
 	authActions(root)
+	internalActions(root)
 
 	root.Add("version", &actions.ActionDescriptorOptions{
 		Command: &cobra.Command{
@@ -122,7 +141,19 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		},
 	})
 
-	root.Add("show", &actions.ActionDescriptorOptions{
+	root.Add("upgrade", &actions.ActionDescriptorOptions{
+		Command:        newUpgradeCmd(),
+		FlagsResolver:  newUpgradeFlags,
+		ActionResolver: newUpgradeAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdUpgradeHelpDescription,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupAbout,
+		},
+	})
+
+	show := root.Add("show", &actions.ActionDescriptorOptions{
 		Command:        newShowCmd(),
 		FlagsResolver:  newShowFlags,
 		ActionResolver: newShowAction,
@@ -130,6 +161,22 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		DefaultFormat:  output.NoneFormat,
 	})
 
+	show.Add("endpoints", &actions.ActionDescriptorOptions{
+		Command:        newShowEndpointsCmd(),
+		FlagsResolver:  newShowEndpointsFlags,
+		ActionResolver: newShowEndpointsAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
+	show.Add("graph", &actions.ActionDescriptorOptions{
+		Command:        newShowGraphCmd(),
+		FlagsResolver:  newShowGraphFlags,
+		ActionResolver: newShowGraphAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
 	//deprecate:cmd hide login
 	login := newLoginCmd("")
 	login.Hidden = true
@@ -146,6 +193,7 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 	logout.Hidden = true
 	root.Add("logout", &actions.ActionDescriptorOptions{
 		Command:        logout,
+		FlagsResolver:  newLogoutFlags,
 		ActionResolver: newLogoutAction,
 	})
 
@@ -162,6 +210,19 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		},
 	}).AddFlagCompletion("template", templateNameCompletion)
 
+	root.Add("add", &actions.ActionDescriptorOptions{
+		Command:        newAddCmd(),
+		FlagsResolver:  newAddFlags,
+		ActionResolver: newAddAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdAddHelpDescription,
+			Footer:      getCmdAddHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupConfig,
+		},
+	})
+
 	root.
 		Add("restore", &actions.ActionDescriptorOptions{
 			Command:        newRestoreCmd(),
@@ -256,10 +317,83 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		}).
 		UseMiddleware("hooks", middleware.NewHooksMiddleware)
 
+	root.Add("exec", &actions.ActionDescriptorOptions{
+		Command:        newExecCmd(),
+		FlagsResolver:  newExecFlags,
+		ActionResolver: newExecAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdExecHelpDescription,
+			Footer:      getCmdExecHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupMonitor,
+		},
+	}).AddFlagCompletion("service", serviceNameCompletion)
+
+	root.Add("tunnel", &actions.ActionDescriptorOptions{
+		Command:        newTunnelCmd(),
+		FlagsResolver:  newTunnelFlags,
+		ActionResolver: newTunnelAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdTunnelHelpDescription,
+			Footer:      getCmdTunnelHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupMonitor,
+		},
+	}).AddFlagCompletion("target", tunnelTargetCompletion)
+
+	root.Add("logs", &actions.ActionDescriptorOptions{
+		Command:        newLogsCmd(),
+		FlagsResolver:  newLogsFlags,
+		ActionResolver: newLogsAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdLogsHelpDescription,
+			Footer:      getCmdLogsHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupMonitor,
+		},
+	}).AddFlagCompletion("service", serviceNameCompletion)
+
+	root.Add("restart", &actions.ActionDescriptorOptions{
+		Command:        newRestartCmd(),
+		FlagsResolver:  newRestartFlags,
+		ActionResolver: newRestartAction,
+		OutputFormats:  []output.Format{output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdRestartHelpDescription,
+			Footer:      getCmdRestartHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupManage,
+		},
+	}).AddFlagCompletion("service", serviceNameCompletion)
+
+	root.Add("scale", &actions.ActionDescriptorOptions{
+		Command:        newScaleCmd(),
+		FlagsResolver:  newScaleFlags,
+		ActionResolver: newScaleAction,
+		OutputFormats:  []output.Format{output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdScaleHelpDescription,
+			Footer:      getCmdScaleHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupManage,
+		},
+	}).AddFlagCompletion("service", serviceNameCompletion)
+
 	root.Add("monitor", &actions.ActionDescriptorOptions{
 		Command:        newMonitorCmd(),
 		FlagsResolver:  newMonitorFlags,
 		ActionResolver: newMonitorAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
 		HelpOptions: actions.ActionHelpOptions{
 			Description: getCmdMonitorHelpDescription,
 			Footer:      getCmdMonitorHelpFooter,
@@ -269,6 +403,19 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		},
 	})
 
+	root.Add("dev", &actions.ActionDescriptorOptions{
+		Command:        newDevCmd(),
+		FlagsResolver:  newDevFlags,
+		ActionResolver: newDevAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdDevHelpDescription,
+			Footer:      getCmdDevHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupMonitor,
+		},
+	})
+
 	root.
 		Add("down", &actions.ActionDescriptorOptions{
 			Command:        newDownCmd(),
@@ -298,6 +445,10 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		UseMiddleware("debug", middleware.NewDebugMiddleware).
 		UseMiddlewareWhen("telemetry", middleware.NewTelemetryMiddleware, func(descriptor *actions.ActionDescriptor) bool {
 			return !descriptor.Options.DisableTelemetry
+		}).
+		UseMiddlewareWhen("runs", middleware.NewRunsMiddleware, func(descriptor *actions.ActionDescriptor) bool {
+			// runs are recorded in the directory of the environment of the command
+			return descriptor.Options.Command.Flags().Lookup(environmentNameFlag) != nil
 		})
 
 	registerCommonDependencies(ioc.Global)
@@ -0,0 +1,278 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/runs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func runsActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
+	group := root.Add("runs", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "runs",
+			Short: "Inspect the recorded runs of azd commands.",
+			Long: "Inspect the recorded runs of azd commands. The command, steps, tool invocations and result of each " +
+				"run are recorded as newline delimited JSON to .azure/<environment>/runs/<id>.ndjson.",
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupMonitor,
+		},
+	})
+
+	group.Add("list", &actions.ActionDescriptorOptions{
+		Command:        newRunsListCmd(),
+		FlagsResolver:  newRunsFlags,
+		ActionResolver: newRunsListAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat},
+		DefaultFormat:  output.TableFormat,
+	})
+
+	group.Add("show", &actions.ActionDescriptorOptions{
+		Command:        newRunsShowCmd(),
+		FlagsResolver:  newRunsFlags,
+		ActionResolver: newRunsShowAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
+	return group
+}
+
+type runsFlags struct {
+	envFlag
+}
+
+func (f *runsFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.envFlag.Bind(local, global)
+}
+
+func newRunsFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *runsFlags {
+	flags := &runsFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+// runsEnvRoot returns the directory of the environment the runs are recorded in. The environment isn't created when it
+// doesn't exist.
+func runsEnvRoot(azdCtx *azdcontext.AzdContext, flags *runsFlags) (string, error) {
+	environmentName := flags.environmentName
+	if environmentName == "" {
+		name, err := azdCtx.GetDefaultEnvironmentName()
+		if err != nil {
+			return "", err
+		}
+		environmentName = name
+	}
+
+	if environmentName == "" {
+		return "", errors.New("no environment selected, use `-e` to select an environment")
+	}
+
+	return azdCtx.EnvironmentRoot(environmentName), nil
+}
+
+func newRunsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List the recorded runs of the environment, most recent first.",
+		Aliases: []string{"ls"},
+	}
+}
+
+type runsListAction struct {
+	flags     *runsFlags
+	azdCtx    *azdcontext.AzdContext
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newRunsListAction(
+	flags *runsFlags,
+	azdCtx *azdcontext.AzdContext,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &runsListAction{
+		flags:     flags,
+		azdCtx:    azdCtx,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+// runRow is a run, as displayed by the table format
+type runRow struct {
+	Id       string
+	Command  string
+	Started  string
+	Status   string
+	Duration string
+}
+
+func (a *runsListAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	envRoot, err := runsEnvRoot(a.azdCtx, a.flags)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := runs.List(envRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.formatter.Kind() != output.TableFormat {
+		return nil, a.formatter.Format(list, a.writer, nil)
+	}
+
+	rows := []runRow{}
+	for _, run := range list {
+		status := string(run.Status)
+		if status == "" {
+			status = "incomplete"
+		}
+
+		rows = append(rows, runRow{
+			Id:       run.Id,
+			Command:  run.Command,
+			Started:  run.Started.Local().Format(time.DateTime),
+			Status:   status,
+			Duration: formatDurationMs(run.DurationMs),
+		})
+	}
+
+	return nil, a.formatter.Format(rows, a.writer, output.TableFormatterOptions{
+		Columns: []output.Column{
+			{Heading: "ID", ValueTemplate: "{{.Id}}"},
+			{Heading: "COMMAND", ValueTemplate: "{{.Command}}"},
+			{Heading: "STARTED", ValueTemplate: "{{.Started}}"},
+			{Heading: "STATUS", ValueTemplate: "{{.Status}}"},
+			{Heading: "DURATION", ValueTemplate: "{{.Duration}}"},
+		},
+	})
+}
+
+func newRunsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show the events of a recorded run, or of the most recent run when <id> isn't set.",
+		Args:  cobra.MaximumNArgs(1),
+	}
+}
+
+type runsShowAction struct {
+	flags     *runsFlags
+	args      []string
+	azdCtx    *azdcontext.AzdContext
+	formatter output.Formatter
+	writer    io.Writer
+	console   input.Console
+}
+
+func newRunsShowAction(
+	flags *runsFlags,
+	args []string,
+	azdCtx *azdcontext.AzdContext,
+	formatter output.Formatter,
+	writer io.Writer,
+	console input.Console,
+) actions.Action {
+	return &runsShowAction{
+		flags:     flags,
+		args:      args,
+		azdCtx:    azdCtx,
+		formatter: formatter,
+		writer:    writer,
+		console:   console,
+	}
+}
+
+func (a *runsShowAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	envRoot, err := runsEnvRoot(a.azdCtx, a.flags)
+	if err != nil {
+		return nil, err
+	}
+
+	id := ""
+	if len(a.args) == 1 {
+		id = a.args[0]
+	} else {
+		list, err := runs.List(envRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(list) == 0 {
+			return nil, errors.New("no runs were recorded for the environment")
+		}
+		id = list[0].Id
+	}
+
+	events, err := runs.Read(envRoot, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		return nil, a.formatter.Format(events, a.writer, nil)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Run %s\n", output.WithHighLightFormat(id)))
+	for _, event := range events {
+		a.console.Message(ctx, formatRunEvent(event))
+	}
+
+	return nil, nil
+}
+
+// formatRunEvent formats the event as a line of `azd runs show`
+func formatRunEvent(event *runs.Event) string {
+	name := event.Name
+	if len(event.Args) > 0 {
+		name += " " + strings.Join(event.Args, " ")
+	}
+
+	details := []string{}
+	if event.Status != "" {
+		details = append(details, string(event.Status))
+	}
+	if event.ExitCode != nil {
+		details = append(details, fmt.Sprintf("exit code %d", *event.ExitCode))
+	}
+	if event.DurationMs > 0 {
+		details = append(details, formatDurationMs(event.DurationMs))
+	}
+
+	line := fmt.Sprintf("%s  %-7s  %s", event.Time.Local().Format("15:04:05.000"), event.Type, name)
+	if len(details) > 0 {
+		line += " " + output.WithGrayFormat("(%s)", strings.Join(details, ", "))
+	}
+	if event.Error != "" {
+		line += "\n    " + output.WithErrorFormat("%s", event.Error)
+	}
+
+	return line
+}
+
+func formatDurationMs(durationMs int64) string {
+	if durationMs <= 0 {
+		return ""
+	}
+
+	return (time.Duration(durationMs) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type scaleFlags struct {
+	serviceName string
+	replicas    int
+	envFlag
+}
+
+func (f *scaleFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(&f.serviceName, "service", "", "The service to scale.")
+	local.IntVar(&f.replicas, "replicas", -1, "The number of replicas, or instances, of the service.")
+	f.envFlag.Bind(local, global)
+}
+
+func newScaleFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *scaleFlags {
+	flags := &scaleFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newScaleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scale",
+		Short: "Set the number of replicas of the deployed compute of a service.",
+		Args:  cobra.NoArgs,
+	}
+}
+
+type scaleAction struct {
+	flags           *scaleFlags
+	projectConfig   *project.ProjectConfig
+	serviceManager  project.ServiceManager
+	resourceManager project.ResourceManager
+	env             *environment.Environment
+	console         input.Console
+}
+
+func newScaleAction(
+	flags *scaleFlags,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	resourceManager project.ResourceManager,
+	env *environment.Environment,
+	console input.Console,
+) actions.Action {
+	return &scaleAction{
+		flags:           flags,
+		projectConfig:   projectConfig,
+		serviceManager:  serviceManager,
+		resourceManager: resourceManager,
+		env:             env,
+		console:         console,
+	}
+}
+
+func (a *scaleAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.env.GetSubscriptionId() == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	if a.flags.serviceName == "" {
+		return nil, errors.New("specify the service to scale with --service")
+	}
+
+	if a.flags.replicas < 0 {
+		return nil, errors.New("specify the number of replicas with --replicas")
+	}
+
+	if !a.projectConfig.HasService(a.flags.serviceName) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", a.flags.serviceName)
+	}
+
+	serviceConfig := a.projectConfig.Services[a.flags.serviceName]
+	serviceTarget, err := a.serviceManager.GetServiceTarget(ctx, serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting service target: %w", err)
+	}
+
+	scaler, ok := serviceTarget.(project.Scaler)
+	if !ok {
+		return nil, fmt.Errorf(
+			"azd scale is not supported for service '%s' hosted on %s, supported hosts are aks and appservice",
+			serviceConfig.Name,
+			serviceConfig.Host,
+		)
+	}
+
+	targetResource, err := a.resourceManager.GetTargetResource(ctx, a.env.GetSubscriptionId(), serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting target resource: %w", err)
+	}
+
+	displayMsg := fmt.Sprintf(
+		"Scaling service %s (%s) to %d replicas", serviceConfig.Name, serviceConfig.Host, a.flags.replicas)
+	a.console.ShowSpinner(ctx, displayMsg, input.Step)
+	err = scaler.Scale(ctx, serviceConfig, targetResource, a.flags.replicas)
+	a.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, fmt.Errorf("scaling service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Service %s was scaled to %d replicas.", serviceConfig.Name, a.flags.replicas),
+		},
+	}, nil
+}
+
+func getCmdScaleHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Set the number of replicas of the deployed compute of a service, until the service is provisioned again.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"AKS services set the replicas of their deployment with %s.",
+				output.WithHighLightFormat("kubectl scale"),
+			)),
+			formatHelpNote(
+				"App Service services set the instance count of their App Service plan, which scales the other " +
+					"apps of the plan too."),
+		})
+}
+
+func getCmdScaleHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Scale the api service to 3 replicas.": fmt.Sprintf("%s %s %s",
+			output.WithHighLightFormat("azd scale --service"),
+			output.WithWarningFormat("api"),
+			output.WithHighLightFormat("--replicas 3"),
+		),
+	})
+}
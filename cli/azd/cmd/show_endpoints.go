@@ -0,0 +1,250 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
+)
+
+type showEndpointsFlags struct {
+	connect bool
+	envFlag
+}
+
+func (f *showEndpointsFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.connect,
+		"connect",
+		false,
+		"Forwards local ports to the services which support it, until azd is interrupted, "+
+			"ex) to reach services only accessible inside a k8s cluster.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newShowEndpointsFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *showEndpointsFlags {
+	flags := &showEndpointsFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newShowEndpointsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "endpoints <service>",
+		Short: "Display the endpoints of the deployed services.",
+		Long: "Display the endpoints of the deployed services. With --connect, local ports are forwarded to the " +
+			"services which support it, ex) AKS services of type ClusterIP, so that they can be reached on localhost.",
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = serviceNameCompletion
+
+	return cmd
+}
+
+type showEndpointsAction struct {
+	flags           *showEndpointsFlags
+	args            []string
+	projectConfig   *project.ProjectConfig
+	serviceManager  project.ServiceManager
+	resourceManager project.ResourceManager
+	env             *environment.Environment
+	console         input.Console
+	formatter       output.Formatter
+	writer          io.Writer
+}
+
+func newShowEndpointsAction(
+	flags *showEndpointsFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	resourceManager project.ResourceManager,
+	env *environment.Environment,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &showEndpointsAction{
+		flags:           flags,
+		args:            args,
+		projectConfig:   projectConfig,
+		serviceManager:  serviceManager,
+		resourceManager: resourceManager,
+		env:             env,
+		console:         console,
+		formatter:       formatter,
+		writer:          writer,
+	}
+}
+
+// serviceConnection is a service whose local port is forwarded by azd show endpoints --connect
+type serviceConnection struct {
+	serviceConfig  *project.ServiceConfig
+	target         project.ServiceConnectTarget
+	targetResource *environment.TargetResource
+}
+
+func (a *showEndpointsAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.env.GetSubscriptionId() == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	if a.flags.connect && a.formatter.Kind().IsStructured() {
+		return nil, fmt.Errorf("'--connect' can't be used with '--output %s'", a.formatter.Kind())
+	}
+
+	targetServiceName := ""
+	if len(a.args) == 1 {
+		targetServiceName = a.args[0]
+		if !a.projectConfig.HasService(targetServiceName) {
+			return nil, fmt.Errorf("service name '%s' doesn't exist", targetServiceName)
+		}
+	}
+
+	if !a.formatter.Kind().IsStructured() {
+		a.console.MessageUxItem(ctx, &ux.MessageTitle{
+			Title: "Showing the endpoints of the services (azd show endpoints)",
+		})
+	}
+
+	result := contracts.ShowEndpointsResult{
+		Services: map[string][]string{},
+	}
+	connections := []serviceConnection{}
+
+	for _, serviceConfig := range a.projectConfig.GetServicesStable() {
+		if targetServiceName != "" && serviceConfig.Name != targetServiceName {
+			continue
+		}
+
+		serviceTarget, err := a.serviceManager.GetServiceTarget(ctx, serviceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("getting service target: %w", err)
+		}
+
+		targetResource, err := a.resourceManager.GetTargetResource(ctx, a.env.GetSubscriptionId(), serviceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("getting target resource: %w", err)
+		}
+
+		endpoints, err := serviceTarget.Endpoints(ctx, serviceConfig, targetResource)
+		if err != nil {
+			return nil, fmt.Errorf("getting endpoints of service '%s': %w", serviceConfig.Name, err)
+		}
+		result.Services[serviceConfig.Name] = endpoints
+
+		if connectTarget, ok := serviceTarget.(project.ServiceConnectTarget); ok {
+			connections = append(connections, serviceConnection{
+				serviceConfig:  serviceConfig,
+				target:         connectTarget,
+				targetResource: targetResource,
+			})
+		}
+	}
+
+	if a.formatter.Kind().IsStructured() {
+		return nil, a.formatter.Format(result, a.writer, nil)
+	}
+
+	for _, serviceConfig := range a.projectConfig.GetServicesStable() {
+		endpoints, has := result.Services[serviceConfig.Name]
+		if !has {
+			continue
+		}
+
+		a.console.Message(ctx, fmt.Sprintf("  - %s (%s)", output.WithHighLightFormat(serviceConfig.Name), serviceConfig.Host))
+		if len(endpoints) == 0 {
+			a.console.Message(ctx, output.WithGrayFormat("    No endpoints"))
+		}
+		for _, endpoint := range endpoints {
+			a.console.Message(ctx, fmt.Sprintf("    Endpoint: %s", output.WithLinkFormat(endpoint)))
+		}
+	}
+	a.console.Message(ctx, "")
+
+	if !a.flags.connect {
+		if len(connections) > 0 {
+			a.console.Message(ctx, output.WithGrayFormat(
+				"Run `azd show endpoints --connect` to reach the services on localhost, through forwarded ports."))
+		}
+
+		return nil, nil
+	}
+
+	if len(connections) == 0 {
+		return nil, errors.New("none of the services supports forwarding local ports")
+	}
+
+	return a.connect(ctx, connections)
+}
+
+// connect forwards local ports to the services until azd is interrupted
+func (a *showEndpointsAction) connect(
+	ctx context.Context,
+	connections []serviceConnection,
+) (*actions.ActionResult, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var connectErr error
+
+	for _, connection := range connections {
+		wg.Add(1)
+		go func(connection serviceConnection) {
+			defer wg.Done()
+
+			err := connection.target.Connect(
+				ctx, connection.serviceConfig, connection.targetResource, func(endpoint string) {
+					a.console.Message(ctx, fmt.Sprintf("  - %s: forwarding %s",
+						output.WithHighLightFormat(connection.serviceConfig.Name), output.WithLinkFormat(endpoint)))
+				})
+			if err != nil {
+				mu.Lock()
+				connectErr = multierr.Append(connectErr, fmt.Errorf(
+					"forwarding a local port to service '%s': %w", connection.serviceConfig.Name, err))
+				mu.Unlock()
+			}
+		}(connection)
+	}
+
+	a.console.Message(ctx, output.WithGrayFormat("Press Ctrl+C to stop forwarding."))
+	wg.Wait()
+
+	if connectErr != nil {
+		return nil, connectErr
+	}
+
+	names := []string{}
+	for _, connection := range connections {
+		names = append(names, connection.serviceConfig.Name)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Stopped forwarding local ports to %s.", strings.Join(names, ", ")),
+		},
+	}, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	graphFormatMermaid = "mermaid"
+	graphFormatDot     = "dot"
+)
+
+type showGraphFlags struct {
+	format string
+	envFlag
+}
+
+func (f *showGraphFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.format,
+		"format",
+		graphFormatMermaid,
+		"The format of the graph, either mermaid or dot.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newShowGraphFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *showGraphFlags {
+	flags := &showGraphFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newShowGraphCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "graph",
+		Short: "Display a graph of the services, their dependencies, Azure resources and infrastructure modules.",
+		Long: "Display a graph of the services of the project, the services they use, the Azure resources they're " +
+			"deployed to in the environment and the modules of the infrastructure, as a Mermaid flowchart or in the " +
+			"DOT language of Graphviz, ex) azd show graph --format dot | dot -Tsvg > graph.svg. " +
+			"The Azure resources are only included once the environment is provisioned.",
+		Args: cobra.NoArgs,
+	}
+}
+
+type showGraphAction struct {
+	flags         *showGraphFlags
+	projectConfig *project.ProjectConfig
+	azCli         azcli.AzCli
+	azdCtx        *azdcontext.AzdContext
+	formatter     output.Formatter
+	writer        io.Writer
+}
+
+func newShowGraphAction(
+	flags *showGraphFlags,
+	projectConfig *project.ProjectConfig,
+	azCli azcli.AzCli,
+	azdCtx *azdcontext.AzdContext,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &showGraphAction{
+		flags:         flags,
+		projectConfig: projectConfig,
+		azCli:         azCli,
+		azdCtx:        azdCtx,
+		formatter:     formatter,
+		writer:        writer,
+	}
+}
+
+func (a *showGraphAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.flags.format != graphFormatMermaid && a.flags.format != graphFormatDot {
+		return nil, fmt.Errorf("invalid format '%s', supported formats are mermaid and dot", a.flags.format)
+	}
+
+	graph, err := project.NewGraph(a.projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating graph: %w", err)
+	}
+
+	a.addTargetResources(ctx, graph)
+
+	if a.formatter.Kind().IsStructured() {
+		return nil, a.formatter.Format(graph, a.writer, nil)
+	}
+
+	contents := graph.Mermaid()
+	if a.flags.format == graphFormatDot {
+		contents = graph.Dot()
+	}
+
+	if _, err := io.WriteString(a.writer, contents); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// addTargetResources adds the Azure resources of the services when the environment is provisioned. Like `azd show`, the
+// environment is loaded here instead of injected, for the graph of projects without environments not to prompt for one.
+func (a *showGraphAction) addTargetResources(ctx context.Context, graph *project.Graph) {
+	environmentName := a.flags.environmentName
+	if environmentName == "" {
+		var err error
+		environmentName, err = a.azdCtx.GetDefaultEnvironmentName()
+		if err != nil {
+			log.Printf("could not determine current environment: %s, resources will not be available", err)
+			return
+		}
+	}
+
+	env, err := environment.GetEnvironment(a.azdCtx, environmentName)
+	if err != nil {
+		log.Printf("could not load environment: %s, resources will not be available", err)
+		return
+	}
+
+	subscriptionId := env.GetSubscriptionId()
+	if subscriptionId == "" {
+		log.Printf("provision has not been run, resources will not be available")
+		return
+	}
+
+	resourceManager := project.NewResourceManager(env, a.azCli)
+	for _, serviceConfig := range a.projectConfig.GetServicesStable() {
+		if !serviceConfig.IsDeployed() {
+			continue
+		}
+
+		targetResource, err := resourceManager.GetTargetResource(ctx, subscriptionId, serviceConfig)
+		if err != nil {
+			log.Printf("ignoring error determining resource of service %s: %v", serviceConfig.Name, err)
+			continue
+		}
+
+		graph.AddTargetResource(serviceConfig.Name, targetResource)
+	}
+}
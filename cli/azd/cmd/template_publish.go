@@ -0,0 +1,329 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// The user config of the gallery templates are published to, when --gallery is not specified
+	templateGalleryConfigPath = "template.gallery"
+	// The user config of the container registry repository templates are pushed to, when --registry is not specified
+	templateRegistryConfigPath = "template.registry"
+)
+
+type templatePublishFlags struct {
+	gallery        string
+	registry       string
+	smokeTest      bool
+	subscriptionId string
+	location       string
+	global         *internal.GlobalCommandOptions
+}
+
+func (f *templatePublishFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.gallery,
+		"gallery",
+		"",
+		fmt.Sprintf("The gallery file, a JSON list of templates, to add the template to. Defaults to the '%s' config.",
+			templateGalleryConfigPath),
+	)
+	local.StringVar(
+		&f.registry,
+		"registry",
+		"",
+		fmt.Sprintf("The container registry repository to push the template to, ex) contoso.azurecr.io/templates/todo. "+
+			"Defaults to the '%s' config.", templateRegistryConfigPath),
+	)
+	local.BoolVar(
+		&f.smokeTest,
+		"smoke-test",
+		false,
+		"Provisions the template in a new environment, then deletes its resources, before publishing it.",
+	)
+	local.StringVar(
+		&f.subscriptionId,
+		"subscription",
+		"",
+		"The subscription of the smoke test and of the container registry. Defaults to the default subscription.",
+	)
+	local.StringVarP(&f.location, "location", "l", "", "The location of the smoke test. Defaults to the default location.")
+	f.global = global
+}
+
+func newTemplatePublishFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *templatePublishFlags {
+	flags := &templatePublishFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newTemplatePublishCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "publish [<path>]",
+		Short: fmt.Sprintf(
+			"Validate a template and publish it to a gallery or a container registry. %s",
+			output.WithWarningFormat("(Beta)")),
+		Args: cobra.MaximumNArgs(1),
+	}
+}
+
+type templatePublishAction struct {
+	flags                    *templatePublishFlags
+	args                     []string
+	console                  input.Console
+	commandRunner            exec.CommandRunner
+	accountManager           account.Manager
+	userConfigManager        config.UserConfigManager
+	containerRegistryService azcli.ContainerRegistryService
+}
+
+func newTemplatePublishAction(
+	flags *templatePublishFlags,
+	args []string,
+	console input.Console,
+	commandRunner exec.CommandRunner,
+	accountManager account.Manager,
+	userConfigManager config.UserConfigManager,
+	containerRegistryService azcli.ContainerRegistryService,
+) actions.Action {
+	return &templatePublishAction{
+		flags:                    flags,
+		args:                     args,
+		console:                  console,
+		commandRunner:            commandRunner,
+		accountManager:           accountManager,
+		userConfigManager:        userConfigManager,
+		containerRegistryService: containerRegistryService,
+	}
+}
+
+func (a *templatePublishAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	path := "."
+	if len(a.args) > 0 {
+		path = a.args[0]
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	userConfig, err := a.userConfigManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	gallery := a.flags.gallery
+	if value, has := userConfig.Get(templateGalleryConfigPath); has && gallery == "" {
+		gallery = fmt.Sprint(value)
+	}
+
+	registry := a.flags.registry
+	if value, has := userConfig.Get(templateRegistryConfigPath); has && registry == "" {
+		registry = fmt.Sprint(value)
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{Title: "Publishing template (azd template publish)"})
+
+	stepMessage := "Validating template"
+	a.console.ShowSpinner(ctx, stepMessage, input.Step)
+	metadata, err := templates.ValidateTemplate(ctx, path)
+	a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	stepMessage = "Packaging template"
+	a.console.ShowSpinner(ctx, stepMessage, input.Step)
+	content, err := templates.PackageTemplate(path)
+	a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionId := a.flags.subscriptionId
+	if subscriptionId == "" {
+		subscriptionId = a.accountManager.GetDefaultSubscriptionID(ctx)
+	}
+
+	if a.flags.smokeTest {
+		if err := a.smokeTest(ctx, content, subscriptionId); err != nil {
+			return nil, err
+		}
+	}
+
+	published := []string{}
+
+	if gallery != "" {
+		stepMessage = fmt.Sprintf("Adding template to gallery %s", output.WithHighLightFormat(gallery))
+		a.console.ShowSpinner(ctx, stepMessage, input.Step)
+		err := templates.PublishToGallery(gallery, metadata.Template())
+		a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+
+		published = append(published, gallery)
+	}
+
+	if registry != "" {
+		reference, err := a.pushTemplate(ctx, registry, subscriptionId, metadata, content)
+		if err != nil {
+			return nil, err
+		}
+
+		published = append(published, reference)
+	}
+
+	if len(published) == 0 {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("Template '%s' is valid.", metadata.Name),
+				FollowUp: fmt.Sprintf(
+					"Publish it with --gallery or --registry, or set the %s or %s config.",
+					output.WithHighLightFormat(templateGalleryConfigPath),
+					output.WithHighLightFormat(templateRegistryConfigPath)),
+			},
+		}, nil
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Template '%s' published to %s.", metadata.Name, strings.Join(published, ", ")),
+		},
+	}, nil
+}
+
+// pushTemplate pushes the packaged template to the container registry repository, tagged with the version of the
+// template, and returns the reference of the pushed artifact
+func (a *templatePublishAction) pushTemplate(
+	ctx context.Context,
+	registry string,
+	subscriptionId string,
+	metadata *templates.TemplateMetadata,
+	content []byte,
+) (string, error) {
+	loginServer, repository, tag, err := parseTemplateReference(registry)
+	if err != nil {
+		return "", err
+	}
+
+	if tag == "" {
+		tag = metadata.Version
+	}
+
+	if tag == "" {
+		tag = "latest"
+	}
+
+	if subscriptionId == "" {
+		return "", errors.New(
+			"the subscription of the container registry is unknown, specify it with --subscription or " +
+				"'azd config set defaults.subscription'")
+	}
+
+	reference := fmt.Sprintf("%s/%s:%s", loginServer, repository, tag)
+	stepMessage := fmt.Sprintf("Pushing template to %s", output.WithHighLightFormat(reference))
+	a.console.ShowSpinner(ctx, stepMessage, input.Step)
+	digest, err := a.containerRegistryService.PushArtifact(
+		ctx, subscriptionId, loginServer, repository, tag, azcli.ContainerRegistryArtifact{
+			ArtifactType: templates.ArtifactType,
+			MediaType:    templates.ArtifactMediaType,
+			Content:      content,
+			Annotations: map[string]string{
+				"org.opencontainers.image.title":       metadata.Name,
+				"org.opencontainers.image.description": metadata.Description,
+				"org.opencontainers.image.source":      metadata.RepositoryPath,
+				"org.opencontainers.image.version":     metadata.Version,
+			},
+		})
+	a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return "", fmt.Errorf("pushing template to '%s': %w", reference, err)
+	}
+
+	log.Printf("pushed template '%s' as %s@%s", metadata.Name, reference, digest)
+	return reference, nil
+}
+
+// smokeTest provisions the packaged template in a new environment of the subscription, then deletes its resources
+func (a *templatePublishAction) smokeTest(ctx context.Context, content []byte, subscriptionId string) error {
+	if subscriptionId == "" {
+		return errors.New(
+			"the subscription of the smoke test is unknown, specify it with --subscription or " +
+				"'azd config set defaults.subscription'")
+	}
+
+	location := a.flags.location
+	if location == "" {
+		location = a.accountManager.GetDefaultLocationName(ctx)
+	}
+
+	sandbox, err := newTemplateSandbox(a.commandRunner, content, a.console.Handles().Stdout)
+	if err != nil {
+		return err
+	}
+	defer sandbox.Close()
+
+	a.console.Message(ctx, output.WithGrayFormat(
+		"Smoke testing template in environment '%s' of subscription %s (%s)", sandbox.envName, subscriptionId, location))
+
+	if err := sandbox.createEnvironment(ctx, subscriptionId, location); err != nil {
+		return fmt.Errorf("smoke test: creating environment: %w", err)
+	}
+
+	_, provisionErr := sandbox.run(ctx, "provision")
+
+	// The resources are deleted even when provisioning fails
+	downErr := sandbox.down(ctx)
+
+	if provisionErr != nil {
+		return fmt.Errorf("smoke test: provisioning failed: %w", provisionErr)
+	}
+
+	if downErr != nil {
+		return fmt.Errorf("smoke test: deleting resources failed, delete the resources of environment '%s': %w",
+			sandbox.envName, downErr)
+	}
+
+	return nil
+}
+
+// parseTemplateReference parses the container registry repository of templates, ex) contoso.azurecr.io/templates/todo,
+// with an optional tag, ex) contoso.azurecr.io/templates/todo:1.0.0
+func parseTemplateReference(reference string) (loginServer string, repository string, tag string, err error) {
+	loginServer, repository, has := strings.Cut(reference, "/")
+	if !has || loginServer == "" || repository == "" {
+		return "", "", "", fmt.Errorf(
+			"invalid registry '%s', expected <login server>/<repository>, ex) contoso.azurecr.io/templates/todo",
+			reference)
+	}
+
+	if index := strings.LastIndex(repository, ":"); index > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:index], repository[index+1:]
+	}
+
+	return loginServer, repository, tag, nil
+}
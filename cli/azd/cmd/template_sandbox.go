@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+)
+
+// templateSandbox runs azd commands against a copy of a packaged template, in a disposable environment. The commands
+// run in their own azd process, so that the template is tested as users get it.
+type templateSandbox struct {
+	commandRunner exec.CommandRunner
+	azdPath       string
+	directory     string
+	envName       string
+	// When set, the output of the commands is written to it, otherwise it is only part of the errors of the commands
+	output io.Writer
+}
+
+// newTemplateSandbox extracts the packaged template to a temporary directory, removed by Close
+func newTemplateSandbox(
+	commandRunner exec.CommandRunner,
+	content []byte,
+	output io.Writer,
+) (*templateSandbox, error) {
+	azdPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("getting the path of azd: %w", err)
+	}
+
+	directory, err := os.MkdirTemp("", "azd-template-sandbox")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := templates.ExtractTemplate(content, directory); err != nil {
+		os.RemoveAll(directory)
+		return nil, err
+	}
+
+	return &templateSandbox{
+		commandRunner: commandRunner,
+		azdPath:       azdPath,
+		directory:     directory,
+		envName:       fmt.Sprintf("sandbox-%d", time.Now().Unix()),
+		output:        output,
+	}, nil
+}
+
+// createEnvironment creates the disposable environment of the sandbox
+func (s *templateSandbox) createEnvironment(ctx context.Context, subscriptionId string, location string) error {
+	_, err := s.run(ctx, "env", "new", s.envName, "--subscription", subscriptionId, "--location", location)
+	return err
+}
+
+// run runs the azd command in the environment of the sandbox and returns its standard output, unless the output of the
+// commands is written to the output of the sandbox
+func (s *templateSandbox) run(ctx context.Context, args ...string) (string, error) {
+	if args[0] != "env" {
+		args = append(args, "--environment", s.envName)
+	}
+
+	runArgs := exec.NewRunArgs(s.azdPath, append(args, "--no-prompt")...).WithCwd(s.directory)
+	if s.output != nil {
+		runArgs = runArgs.WithStdOut(s.output).WithStdErr(s.output)
+	}
+
+	result, err := s.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Stdout, nil
+}
+
+// down deletes the resources of the environment of the sandbox
+func (s *templateSandbox) down(ctx context.Context) error {
+	_, err := s.run(ctx, "down", "--force", "--purge")
+	return err
+}
+
+// Close removes the copy of the template
+func (s *templateSandbox) Close() error {
+	return os.RemoveAll(s.directory)
+}
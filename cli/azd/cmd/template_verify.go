@@ -0,0 +1,386 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The interval between the requests probing an endpoint which is not ready
+const templateVerifyProbeInterval = 10 * time.Second
+
+type templateVerifyFlags struct {
+	subscriptionId string
+	location       string
+	timeout        time.Duration
+	probeTimeout   time.Duration
+	maxResources   int
+	report         string
+	global         *internal.GlobalCommandOptions
+}
+
+func (f *templateVerifyFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.subscriptionId,
+		"subscription",
+		"",
+		"The subscription of the disposable environment. Defaults to the default subscription.",
+	)
+	local.StringVarP(
+		&f.location,
+		"location",
+		"l",
+		"",
+		"The location of the disposable environment. Defaults to the default location.",
+	)
+	local.DurationVar(
+		&f.timeout,
+		"timeout",
+		time.Hour,
+		"The time provisioning, deploying and probing the template may take. The resources are deleted when it's exceeded.",
+	)
+	local.DurationVar(
+		&f.probeTimeout,
+		"probe-timeout",
+		5*time.Minute,
+		"The time each endpoint of the services has to respond without a server error.",
+	)
+	local.IntVar(
+		&f.maxResources,
+		"max-resources",
+		0,
+		"Fails the verification, and deletes the resources before deploying, when the template provisions more "+
+			"resources than this, to cap the cost of the verification. 0 for no cap.",
+	)
+	local.StringVar(&f.report, "report", "", "The file to write the JUnit XML report of the verification to.")
+	f.global = global
+}
+
+func newTemplateVerifyFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *templateVerifyFlags {
+	flags := &templateVerifyFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newTemplateVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "verify [<path>]",
+		Short: fmt.Sprintf(
+			"Verify a template end-to-end in a disposable environment. %s", output.WithWarningFormat("(Beta)")),
+		Long: "Verify a template end-to-end in a disposable environment: provision it, deploy its services, " +
+			"probe their endpoints and delete its resources. With --report, the steps are written as a JUnit XML " +
+			"report, ex) for the CI of the template.",
+		Args: cobra.MaximumNArgs(1),
+	}
+}
+
+type templateVerifyAction struct {
+	flags          *templateVerifyFlags
+	args           []string
+	console        input.Console
+	commandRunner  exec.CommandRunner
+	accountManager account.Manager
+	azCli          azcli.AzCli
+	httpClient     httputil.HttpClient
+	clock          clock.Clock
+}
+
+func newTemplateVerifyAction(
+	flags *templateVerifyFlags,
+	args []string,
+	console input.Console,
+	commandRunner exec.CommandRunner,
+	accountManager account.Manager,
+	azCli azcli.AzCli,
+	httpClient httputil.HttpClient,
+	clock clock.Clock,
+) actions.Action {
+	return &templateVerifyAction{
+		flags:          flags,
+		args:           args,
+		console:        console,
+		commandRunner:  commandRunner,
+		accountManager: accountManager,
+		azCli:          azCli,
+		httpClient:     httpClient,
+		clock:          clock,
+	}
+}
+
+func (a *templateVerifyAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	path := "."
+	if len(a.args) > 0 {
+		path = a.args[0]
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	projectConfig, err := project.Load(ctx, filepath.Join(path, azdcontext.ProjectFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionId := a.flags.subscriptionId
+	if subscriptionId == "" {
+		subscriptionId = a.accountManager.GetDefaultSubscriptionID(ctx)
+	}
+
+	if subscriptionId == "" {
+		return nil, errors.New(
+			"the subscription of the verification is unknown, specify it with --subscription or " +
+				"'azd config set defaults.subscription'")
+	}
+
+	location := a.flags.location
+	if location == "" {
+		location = a.accountManager.GetDefaultLocationName(ctx)
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: fmt.Sprintf("Verifying template %s (azd template verify)", projectConfig.Name),
+	})
+
+	content, err := templates.PackageTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sandbox, err := newTemplateSandbox(a.commandRunner, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer sandbox.Close()
+
+	a.console.Message(ctx, output.WithGrayFormat(
+		"Environment '%s' of subscription %s (%s)\n", sandbox.envName, subscriptionId, location))
+
+	verification := &templateVerification{
+		action: a,
+		report: &templates.VerificationReport{Template: projectConfig.Name},
+	}
+
+	// The resources are deleted when the timeout is exceeded, with the context of the command
+	verifyCtx, cancel := context.WithTimeout(ctx, a.flags.timeout)
+	defer cancel()
+
+	created := verification.step(verifyCtx, "environment", func(ctx context.Context) error {
+		return sandbox.createEnvironment(ctx, subscriptionId, location)
+	})
+
+	provisioned := created && verification.step(verifyCtx, "provision", func(ctx context.Context) error {
+		_, err := sandbox.run(ctx, "provision")
+		return err
+	})
+
+	if provisioned && a.flags.maxResources > 0 {
+		provisioned = verification.step(verifyCtx, "resources", func(ctx context.Context) error {
+			return a.verifyResourceCount(ctx, subscriptionId, sandbox.envName)
+		})
+	}
+
+	deployed := provisioned && verification.step(verifyCtx, "deploy", func(ctx context.Context) error {
+		_, err := sandbox.run(ctx, "deploy", "--all")
+		return err
+	})
+
+	if deployed {
+		verification.probeEndpoints(verifyCtx, sandbox)
+	}
+
+	if created {
+		verification.step(ctx, "down", sandbox.down)
+	} else {
+		verification.skip(ctx, "down", "the environment was not created")
+	}
+
+	if a.flags.report != "" {
+		if err := a.writeReport(verification.report); err != nil {
+			return nil, err
+		}
+	}
+
+	a.console.Message(ctx, "")
+	if verification.report.Failed() {
+		failed := []string{}
+		for _, step := range verification.report.Steps {
+			if step.Err != nil {
+				failed = append(failed, step.Name)
+			}
+		}
+
+		return nil, fmt.Errorf("verification of template '%s' failed: %s", projectConfig.Name, strings.Join(failed, ", "))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Template '%s' verified.", projectConfig.Name),
+		},
+	}, nil
+}
+
+// verifyResourceCount fails when the resource groups of the environment have more resources than allowed
+func (a *templateVerifyAction) verifyResourceCount(ctx context.Context, subscriptionId string, envName string) error {
+	groups, err := a.azCli.ListResourceGroup(ctx, subscriptionId, &azcli.ListResourceGroupOptions{
+		TagFilter: &azcli.Filter{Key: azure.TagKeyAzdEnvName, Value: envName},
+	})
+	if err != nil {
+		return fmt.Errorf("listing resource groups: %w", err)
+	}
+
+	count := 0
+	for _, group := range groups {
+		resources, err := a.azCli.ListResourceGroupResources(ctx, subscriptionId, group.Name, nil)
+		if err != nil {
+			return fmt.Errorf("listing resources of resource group '%s': %w", group.Name, err)
+		}
+
+		count += len(resources)
+	}
+
+	if count > a.flags.maxResources {
+		return fmt.Errorf("the template provisioned %d resources, more than --max-resources %d", count, a.flags.maxResources)
+	}
+
+	return nil
+}
+
+func (a *templateVerifyAction) writeReport(report *templates.VerificationReport) error {
+	if err := os.MkdirAll(filepath.Dir(a.flags.report), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating report folder: %w", err)
+	}
+
+	file, err := os.Create(a.flags.report)
+	if err != nil {
+		return fmt.Errorf("creating report: %w", err)
+	}
+	defer file.Close()
+
+	if err := report.WriteJUnit(file); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	return nil
+}
+
+// templateVerification records the steps of the verification of a template in its report
+type templateVerification struct {
+	action *templateVerifyAction
+	report *templates.VerificationReport
+}
+
+// step runs the step and returns true when it passed. Steps are skipped once the context is done.
+func (v *templateVerification) step(ctx context.Context, name string, run func(ctx context.Context) error) bool {
+	if ctx.Err() != nil {
+		v.skip(ctx, name, "the verification timed out")
+		return false
+	}
+
+	console := v.action.console
+	console.ShowSpinner(ctx, name, input.Step)
+
+	start := v.action.clock.Now()
+	err := run(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", v.action.flags.timeout, err)
+	}
+
+	v.report.Steps = append(v.report.Steps, &templates.VerificationStep{
+		Name:     name,
+		Duration: v.action.clock.Since(start),
+		Err:      err,
+	})
+
+	console.StopSpinner(ctx, name, input.GetStepResultFormat(err))
+	return err == nil
+}
+
+func (v *templateVerification) skip(ctx context.Context, name string, reason string) {
+	v.report.Steps = append(v.report.Steps, &templates.VerificationStep{
+		Name:          name,
+		SkippedReason: reason,
+	})
+
+	v.action.console.StopSpinner(ctx, name, input.StepSkipped)
+}
+
+// probeEndpoints probes the endpoints of the deployed services, each as a step of the verification
+func (v *templateVerification) probeEndpoints(ctx context.Context, sandbox *templateSandbox) {
+	var endpoints contracts.ShowEndpointsResult
+	found := v.step(ctx, "endpoints", func(ctx context.Context) error {
+		output, err := sandbox.run(ctx, "show", "endpoints", "--output", "json")
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal([]byte(output), &endpoints); err != nil {
+			return fmt.Errorf("parsing endpoints: %w", err)
+		}
+
+		return nil
+	})
+
+	if !found {
+		return
+	}
+
+	services := maps.Keys(endpoints.Services)
+	slices.Sort(services)
+
+	for _, service := range services {
+		for _, endpoint := range endpoints.Services[service] {
+			// Endpoints are optionally followed by their description, ex) http://10.0.0.1, (Service, Type: ClusterIP)
+			url, description, _ := strings.Cut(endpoint, ",")
+			name := fmt.Sprintf("probe %s %s", service, url)
+
+			if strings.Contains(description, "ClusterIP") {
+				v.skip(ctx, name, "the endpoint is only reachable inside the cluster")
+				continue
+			}
+
+			v.step(ctx, name, func(ctx context.Context) error {
+				probeWait := wait.Config{Timeout: v.action.flags.probeTimeout, Interval: templateVerifyProbeInterval}
+
+				// The wait checks its timeout between the requests, the context stops a request which doesn't respond,
+				// ex) while the endpoint starts. Like the verification, it doesn't outlast the timeout of its parent.
+				probeCtx, cancel := context.WithTimeout(ctx, probeWait.Timeout)
+				defer cancel()
+
+				return templates.ProbeEndpoint(probeCtx, v.action.clock, probeWait, v.action.httpClient, url)
+			})
+		}
+	}
+}
@@ -14,22 +14,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func templateNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	templateManager := templates.NewTemplateManager()
-	templates, err := templateManager.ListTemplates()
-
-	if err != nil {
-		cobra.CompError(fmt.Sprintf("Error listing templates: %s", err))
-		return []string{}, cobra.ShellCompDirectiveError
-	}
-
-	templateNames := make([]string, len(templates))
-	for i, v := range templates {
-		templateNames[i] = v.Name
-	}
-	return templateNames, cobra.ShellCompDirectiveDefault
-}
-
 func templatesActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 	group := root.Add("template", &actions.ActionDescriptorOptions{
 		Command: &cobra.Command{
@@ -57,6 +41,22 @@ func templatesActions(root *actions.ActionDescriptor) *actions.ActionDescriptor
 		DefaultFormat:  output.NoneFormat,
 	})
 
+	group.Add("publish", &actions.ActionDescriptorOptions{
+		Command:        newTemplatePublishCmd(),
+		ActionResolver: newTemplatePublishAction,
+		FlagsResolver:  newTemplatePublishFlags,
+		OutputFormats:  []output.Format{output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
+	group.Add("verify", &actions.ActionDescriptorOptions{
+		Command:        newTemplateVerifyCmd(),
+		ActionResolver: newTemplateVerifyAction,
+		FlagsResolver:  newTemplateVerifyFlags,
+		OutputFormats:  []output.Format{output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	})
+
 	return group
 }
 
@@ -156,6 +156,8 @@ func newTemplateShowCmd() *cobra.Command {
 		Use:   "show <template>",
 		Short: fmt.Sprintf("Show details for a given template. %s", output.WithWarningFormat("(Beta)")),
 		Args:  cobra.ExactArgs(1),
+
+		ValidArgsFunction: templateNameArgCompletion,
 	}
 }
 
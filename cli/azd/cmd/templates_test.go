@@ -4,10 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,3 +47,122 @@ func TestTemplateList(t *testing.T) {
 		assert.Equal(t, template.Name, storedTemplates[i].Name)
 	}
 }
+
+func TestParseTemplateReference(t *testing.T) {
+	tests := map[string]struct {
+		reference   string
+		loginServer string
+		repository  string
+		tag         string
+	}{
+		"NoTag":   {"contoso.azurecr.io/templates/todo", "contoso.azurecr.io", "templates/todo", ""},
+		"Tag":     {"contoso.azurecr.io/templates/todo:1.0.0", "contoso.azurecr.io", "templates/todo", "1.0.0"},
+		"Port":    {"localhost:5000/todo", "localhost:5000", "todo", ""},
+		"PortTag": {"localhost:5000/todo:latest", "localhost:5000", "todo", "latest"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			loginServer, repository, tag, err := parseTemplateReference(test.reference)
+			require.NoError(t, err)
+			require.Equal(t, test.loginServer, loginServer)
+			require.Equal(t, test.repository, repository)
+			require.Equal(t, test.tag, tag)
+		})
+	}
+
+	_, _, _, err := parseTemplateReference("contoso.azurecr.io")
+	require.Error(t, err)
+}
+
+func TestTemplateVerify(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "azure.yaml"), []byte("name: todo\n"), 0600))
+
+	// endpointHangs makes the endpoint accept the requests without responding, until their context is done
+	runVerify := func(t *testing.T, provisionErr error, endpointHangs bool) ([]string, string, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		commands := []string{}
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return len(args.Args) > 0
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			require.FileExists(t, filepath.Join(args.Cwd, "azure.yaml"))
+			commands = append(commands, args.Args[0])
+
+			switch args.Args[0] {
+			case "provision":
+				return exec.RunResult{}, provisionErr
+			case "show":
+				return exec.NewRunResult(0, `{"services": {
+					"api": ["http://10.0.0.1:80, (Service, Type: ClusterIP)"],
+					"web": ["https://todo.contoso.com/"]
+				}}`, ""), nil
+			}
+
+			return exec.RunResult{}, nil
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.URL.Host == "todo.contoso.com"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			if endpointHangs {
+				<-request.Context().Done()
+				return nil, request.Context().Err()
+			}
+
+			return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		})
+
+		probeTimeout := time.Minute
+		if endpointHangs {
+			probeTimeout = 100 * time.Millisecond
+		}
+
+		reportPath := filepath.Join(t.TempDir(), "report.xml")
+		action := newTemplateVerifyAction(
+			&templateVerifyFlags{timeout: time.Minute, probeTimeout: probeTimeout, report: reportPath},
+			[]string{path},
+			mockContext.Console,
+			mockContext.CommandRunner,
+			&mockaccount.MockAccountManager{DefaultSubscription: "SUBSCRIPTION_ID", DefaultLocation: "eastus2"},
+			nil,
+			mockContext.HttpClient,
+			clock.NewMock(),
+		)
+
+		_, err := action.Run(*mockContext.Context)
+		report, readErr := os.ReadFile(reportPath)
+		require.NoError(t, readErr)
+
+		return commands, string(report), err
+	}
+
+	t.Run("Passed", func(t *testing.T) {
+		commands, report, err := runVerify(t, nil, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"env", "provision", "deploy", "show", "down"}, commands)
+		require.Contains(t, report, `<testsuite name="todo" tests="7" failures="0" skipped="1"`)
+		require.Contains(t, report, `<testcase name="probe web https://todo.contoso.com/" classname="todo"`)
+	})
+
+	t.Run("ProvisionFailed", func(t *testing.T) {
+		commands, report, err := runVerify(t, errors.New("deployment failed"), false)
+		require.ErrorContains(t, err, "verification of template 'todo' failed: provision")
+
+		// The resources are deleted even when provisioning fails
+		require.Equal(t, []string{"env", "provision", "down"}, commands)
+		require.Contains(t, report, `<failure message="deployment failed">`)
+	})
+
+	t.Run("ProbeTimeout", func(t *testing.T) {
+		start := time.Now()
+		commands, report, err := runVerify(t, nil, true)
+		require.ErrorContains(t, err, "probe web https://todo.contoso.com/")
+
+		// The request which doesn't respond is stopped by the probe timeout, not by the timeout of the verification
+		require.Less(t, time.Since(start), 30*time.Second)
+		require.Equal(t, []string{"env", "provision", "deploy", "show", "down"}, commands)
+		require.Contains(t, report, `<failure message="endpoint &#39;https://todo.contoso.com/&#39; is not ready: `)
+		require.Contains(t, report, "context deadline exceeded")
+	})
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type tunnelFlags struct {
+	target    string
+	name      string
+	via       string
+	localPort int
+	envFlag
+}
+
+func (f *tunnelFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.target,
+		"target",
+		"",
+		fmt.Sprintf("The data service to open the tunnel to (%s).", strings.Join(tunnel.TargetNames(), ", ")),
+	)
+	local.StringVar(
+		&f.name,
+		"name",
+		"",
+		"The name of the server, when the resource group of the environment has more than one server of the target.",
+	)
+	local.StringVar(
+		&f.via,
+		"via",
+		"",
+		fmt.Sprintf(
+			"How the tunnel reaches the jump box (%s, %s). Defaults to %s when %s is set, otherwise %s.",
+			tunnel.ViaSsh, tunnel.ViaBastion, tunnel.ViaSsh, tunnel.JumpBoxHostEnvVarName, tunnel.ViaBastion),
+	)
+	local.IntVar(
+		&f.localPort,
+		"local-port",
+		0,
+		"The local port of the tunnel. Defaults to the port of the data service.",
+	)
+	f.envFlag.Bind(local, global)
+}
+
+func newTunnelFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *tunnelFlags {
+	flags := &tunnelFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newTunnelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tunnel",
+		Short: "Open a tunnel from a local port to a private data service of the environment.",
+	}
+	cmd.Args = cobra.NoArgs
+
+	return cmd
+}
+
+type tunnelAction struct {
+	flags         *tunnelFlags
+	projectConfig *project.ProjectConfig
+	tunnelManager *tunnel.Manager
+	console       input.Console
+}
+
+func newTunnelAction(
+	flags *tunnelFlags,
+	projectConfig *project.ProjectConfig,
+	tunnelManager *tunnel.Manager,
+	console input.Console,
+) actions.Action {
+	return &tunnelAction{
+		flags:         flags,
+		projectConfig: projectConfig,
+		tunnelManager: tunnelManager,
+		console:       console,
+	}
+}
+
+func (a *tunnelAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.flags.target == "" {
+		return nil, fmt.Errorf(
+			"specify the data service to open the tunnel to with --target (%s)", strings.Join(tunnel.TargetNames(), ", "))
+	}
+
+	if a.flags.localPort < 0 || a.flags.localPort > 65535 {
+		return nil, fmt.Errorf("'--local-port' %d is not a valid port", a.flags.localPort)
+	}
+
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Opening tunnel (azd tunnel)",
+	})
+
+	tunnelToTarget, err := a.tunnelManager.Resolve(
+		ctx, a.projectConfig, a.flags.target, a.flags.name, tunnel.Via(a.flags.via))
+	if err != nil {
+		return nil, err
+	}
+
+	localPort := a.flags.localPort
+	if localPort == 0 {
+		localPort = tunnelToTarget.Target.Port
+	}
+
+	through := tunnelToTarget.JumpBoxHost
+	if tunnelToTarget.Via == tunnel.ViaBastion {
+		through = fmt.Sprintf("Bastion %s", tunnelToTarget.BastionName)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Forwarding localhost:%d to %s:%d through %s.",
+		localPort, tunnelToTarget.RemoteHost, tunnelToTarget.Target.Port, through))
+	a.console.Message(ctx, fmt.Sprintf("Connection string: %s",
+		output.WithHighLightFormat(tunnelToTarget.ConnectionString(localPort))))
+	a.console.Message(ctx, output.WithGrayFormat("Press Ctrl+C to close the tunnel."))
+	a.console.Message(ctx, "")
+
+	// The tunnel is open until azd is interrupted
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	err = a.tunnelManager.Open(ctx, tunnelToTarget, localPort)
+	if err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Closed the tunnel to %s.", tunnelToTarget.ServerName),
+		},
+	}, nil
+}
+
+func getCmdTunnelHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Open a tunnel from a local port to a data service, only reachable from the private network of the "+
+			"environment, ex) to run migrations or queries locally.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"The tunnel runs %s through a jump box, a virtual machine in the network of the data service, "+
+					"and prints the connection string to the local port.",
+				output.WithHighLightFormat("ssh -L"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Via %s, the tunnel signs in to %s, as %s (defaults to azureuser).",
+				output.WithHighLightFormat(string(tunnel.ViaSsh)),
+				output.WithHighLightFormat(tunnel.JumpBoxHostEnvVarName),
+				output.WithHighLightFormat(tunnel.JumpBoxUserEnvVarName),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Via %s, the jump box is reached with %s of the Azure CLI, through the Bastion of the resource group.",
+				output.WithHighLightFormat(string(tunnel.ViaBastion)),
+				output.WithHighLightFormat("az network bastion tunnel"),
+			)),
+		})
+}
+
+func getCmdTunnelHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Open a tunnel to the PostgreSQL server of the environment.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd tunnel --target"),
+			output.WithWarningFormat("postgres"),
+		),
+		"Open a tunnel to the Azure SQL server on local port 11433, through Bastion.": fmt.Sprintf("%s %s %s %s",
+			output.WithHighLightFormat("azd tunnel --target"),
+			output.WithWarningFormat("sql"),
+			output.WithHighLightFormat("--local-port 11433 --via"),
+			output.WithWarningFormat("bastion"),
+		),
+	})
+}
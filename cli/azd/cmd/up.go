@@ -2,27 +2,47 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/pkg/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
 )
 
 type upFlags struct {
 	provisionFlags
 	deployFlags
-	global *internal.GlobalCommandOptions
+	summaryFile     string
+	progressComment bool
+	remote          bool
+	global          *internal.GlobalCommandOptions
 	envFlag
 }
 
@@ -30,6 +50,29 @@ func (u *upFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptio
 	u.envFlag.Bind(local, global)
 	u.global = global
 
+	local.StringVar(
+		&u.summaryFile,
+		"summary-file",
+		"",
+		"Writes the deployment summary as Markdown to the file, or as the payload of a pull request comment when the "+
+			"file has the .json extension.",
+	)
+	local.BoolVar(
+		&u.progressComment,
+		"progress-comment",
+		false,
+		"Reports the progress of the provisioning and deployment in a comment of the pull request of the CI run, "+
+			"updated as it progresses.",
+	)
+
+	local.BoolVar(
+		&u.remote,
+		"remote",
+		false,
+		fmt.Sprintf("Runs the provisioning and deployment in the Container Apps job of %s instead of locally, "+
+			"streaming its logs.", remote.JobIdEnvVarName),
+	)
+
 	u.provisionFlags.bindNonCommon(local, global)
 	u.provisionFlags.setCommon(&u.envFlag)
 	u.deployFlags.bindNonCommon(local, global)
@@ -52,38 +95,59 @@ func newUpCmd() *cobra.Command {
 
 type upAction struct {
 	flags                      *upFlags
+	azdCtx                     *azdcontext.AzdContext
 	env                        *environment.Environment
 	projectConfig              *project.ProjectConfig
+	containerHelper            *project.ContainerHelper
+	buildCache                 *project.BuildCache
 	packageActionInitializer   actions.ActionInitializer[*packageAction]
 	provisionActionInitializer actions.ActionInitializer[*provisionAction]
 	deployActionInitializer    actions.ActionInitializer[*deployAction]
 	console                    input.Console
+	commandRunner              exec.CommandRunner
+	formatter                  output.Formatter
+	writer                     io.Writer
 	runner                     middleware.MiddlewareContext
 	prompters                  prompt.Prompter
+	remoteRunner               *remote.Runner
 }
 
 func newUpAction(
 	flags *upFlags,
+	azdCtx *azdcontext.AzdContext,
 	env *environment.Environment,
 	_ auth.LoggedInGuard,
 	projectConfig *project.ProjectConfig,
+	containerHelper *project.ContainerHelper,
+	buildCache *project.BuildCache,
 	packageActionInitializer actions.ActionInitializer[*packageAction],
 	provisionActionInitializer actions.ActionInitializer[*provisionAction],
 	deployActionInitializer actions.ActionInitializer[*deployAction],
 	console input.Console,
+	commandRunner exec.CommandRunner,
+	formatter output.Formatter,
+	writer io.Writer,
 	runner middleware.MiddlewareContext,
 	prompters prompt.Prompter,
+	remoteRunner *remote.Runner,
 ) actions.Action {
 	return &upAction{
 		flags:                      flags,
+		azdCtx:                     azdCtx,
 		env:                        env,
 		projectConfig:              projectConfig,
+		containerHelper:            containerHelper,
+		buildCache:                 buildCache,
 		packageActionInitializer:   packageActionInitializer,
 		provisionActionInitializer: provisionActionInitializer,
 		deployActionInitializer:    deployActionInitializer,
 		console:                    console,
+		commandRunner:              commandRunner,
+		formatter:                  formatter,
+		writer:                     writer,
 		runner:                     runner,
 		prompters:                  prompters,
+		remoteRunner:               remoteRunner,
 	}
 }
 
@@ -107,13 +171,52 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			output.WithWarningFormat("WARNING: The '--service' flag is deprecated and will be removed in a future release."))
 	}
 
+	if u.flags.deployFlags.matrix.enabled() {
+		if u.flags.remote {
+			return nil, errors.New("--remote can't be combined with --environments or --all-environments")
+		}
+
+		matrix := &environmentMatrix{
+			flags:         &u.flags.deployFlags.matrix,
+			azdCtx:        u.azdCtx,
+			commandRunner: u.commandRunner,
+			console:       u.console,
+			formatter:     u.formatter,
+			writer:        u.writer,
+		}
+
+		return matrix.run(ctx, "up", nil)
+	}
+
 	err := u.prompters.EnsureEnv(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if u.flags.remote {
+		return u.runRemote(ctx)
+	}
+
 	startTime := time.Now()
 
+	// The services are packaged before provisioning, --tag must apply to the images packaged then
+	if err := u.containerHelper.SetImageTag(u.flags.deployFlags.tag); err != nil {
+		return nil, err
+	}
+
+	if u.flags.deployFlags.noCache {
+		u.buildCache.Disable()
+	}
+
+	var progressComment *pipeline.ProgressComment
+	if u.flags.progressComment {
+		progressComment, err = pipeline.NewProgressCommentFromEnv(u.env.GetEnvName())
+		if err != nil {
+			u.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: The progress isn't reported on the pull request: %v", err))
+		}
+	}
+
 	packageAction, err := u.packageActionInitializer()
 	if err != nil {
 		return nil, err
@@ -131,10 +234,19 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 
 	provision.flags = &u.flags.provisionFlags
 	provisionOptions := &middleware.Options{CommandPath: "provision"}
-	_, err = u.runner.RunChildAction(ctx, provisionOptions, provision)
+	provisionStartTime := time.Now()
+	provisionCtx := ctx
+	if progressComment != nil {
+		provisionCtx = provisioning.WithProgressObserver(ctx, progressComment.ObserveProvisioning)
+	}
+	progressComment.SetProvisioning(ctx, pipeline.ProgressInProgress)
+	_, err = u.runner.RunChildAction(provisionCtx, provisionOptions, provision)
 	if err != nil {
+		progressComment.SetProvisioning(ctx, pipeline.ProgressFailed)
 		return nil, err
 	}
+	progressComment.SetProvisioning(ctx, pipeline.ProgressSucceeded)
+	provisionDuration := since(provisionStartTime)
 
 	// Print an additional newline to separate provision from deploy
 	u.console.Message(ctx, "")
@@ -145,17 +257,37 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}
 
 	deploy.flags = &u.flags.deployFlags
+	deploy.progressComment = progressComment
 	// move flag to args to avoid extra deprecation flag warning
 	if deploy.flags.serviceName != "" {
 		deploy.args = []string{deploy.flags.serviceName}
 		deploy.flags.serviceName = ""
 	}
 	deployOptions := &middleware.Options{CommandPath: "deploy"}
+	deployStartTime := time.Now()
 	_, err = u.runner.RunChildAction(ctx, deployOptions, deploy)
 	if err != nil {
 		return nil, err
 	}
 
+	summary := &ux.DeploymentSummary{
+		Environment:       u.env.GetEnvName(),
+		ProvisionDuration: provisionDuration,
+		DeployDuration:    since(deployStartTime),
+		Services:          deploy.deployedServices,
+		Resources:         provisionedResources(ctx, provision.provisionManager),
+		ArmRequests:       armRequestStats(),
+	}
+
+	u.console.MessageUxItem(ctx, summary)
+	progressComment.Complete(ctx, summary.Markdown())
+
+	if u.flags.summaryFile != "" {
+		if err := writeDeploymentSummary(summary, u.flags.summaryFile); err != nil {
+			return nil, fmt.Errorf("writing deployment summary: %w", err)
+		}
+	}
+
 	return &actions.ActionResult{
 		Message: &actions.ResultMessage{
 			Header: fmt.Sprintf("Your application was provisioned and deployed to Azure in %s.",
@@ -164,9 +296,141 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// runRemote runs `azd up` for the environment in its Container Apps job, writing the logs of the run
+func (u *upAction) runRemote(ctx context.Context) (*actions.ActionResult, error) {
+	startTime := time.Now()
+
+	stepMessage := "Starting remote run"
+	u.console.ShowSpinner(ctx, stepMessage, input.Step)
+	execution, err := u.remoteRunner.Start(ctx, u.env, u.azdCtx.ProjectDirectory(), func(progress string) {
+		u.console.ShowSpinner(ctx, fmt.Sprintf("%s (%s)", stepMessage, progress), input.Step)
+	})
+	if err != nil {
+		u.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+		return nil, err
+	}
+	u.console.StopSpinner(ctx, stepMessage, input.StepDone)
+
+	u.console.Message(ctx, fmt.Sprintf(
+		"Running in execution %s of job %s, logs are uploaded to %s\n",
+		output.WithHighLightFormat(execution.Name),
+		output.WithHighLightFormat(execution.JobName),
+		output.WithLinkFormat(execution.LogUrl)))
+
+	if err := u.remoteRunner.Follow(ctx, execution, u.console.Handles().Stdout); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Your application was provisioned and deployed to Azure remotely in %s.",
+				ux.DurationAsText(since(startTime))),
+		},
+	}, nil
+}
+
+// provisionedResources returns the resources of the provisioning state, sorted by type and name
+func provisionedResources(ctx context.Context, provisionManager *provisioning.Manager) []ux.DeployedResource {
+	stateResult, err := provisionManager.State(ctx)
+	if err != nil {
+		log.Printf("failed getting provisioning state for the deployment summary: %v", err)
+		return nil
+	}
+
+	resources := []ux.DeployedResource{}
+	for _, resource := range stateResult.State.Resources {
+		resourceId, err := arm.ParseResourceID(resource.Id)
+		if err != nil {
+			log.Printf("skipping resource '%s' of the deployment summary: %v", resource.Id, err)
+			continue
+		}
+
+		resourceType := infra.GetResourceTypeDisplayName(infra.AzureResourceType(resourceId.ResourceType.String()))
+		if resourceType == "" {
+			resourceType = resourceId.ResourceType.String()
+		}
+
+		resources = append(resources, ux.DeployedResource{Type: resourceType, Name: resourceId.Name})
+	}
+
+	slices.SortFunc(resources, func(a, b ux.DeployedResource) bool {
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		return a.Name < b.Name
+	})
+
+	return resources
+}
+
+// armRequestStats returns the counters of the requests of azd to ARM, shared by its clients
+func armRequestStats() ux.ArmRequestStats {
+	stats := azsdk.ArmRateLimiter().Stats()
+	return ux.ArmRequestStats{
+		Requests:  stats.Requests,
+		Delayed:   stats.Delayed,
+		Waited:    stats.Waited,
+		Throttled: stats.Throttled,
+	}
+}
+
+// writeDeploymentSummary writes the summary as Markdown, or as the json payload of a GitHub pull request comment when
+// the file has the .json extension
+func writeDeploymentSummary(summary *ux.DeploymentSummary, path string) error {
+	contents := []byte(summary.Markdown())
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		payload, err := json.MarshalIndent(map[string]string{"body": summary.Markdown()}, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		contents = payload
+	}
+
+	return os.WriteFile(path, contents, osutil.PermissionFile)
+}
+
 func getCmdUpHelpDescription(c *cobra.Command) string {
 	return generateCmdHelpDescription(
 		fmt.Sprintf("Executes the %s and %s commands in a single step.",
 			output.WithHighLightFormat("azd provision"),
-			output.WithHighLightFormat("azd deploy")), nil)
+			output.WithHighLightFormat("azd deploy")),
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"A summary of the deployed services and provisioned resources is displayed once done. With %s, the "+
+					"summary is also written as Markdown, ex) for CI to comment on a pull request.",
+				output.WithHighLightFormat("--summary-file"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"With %s, the progress is reported in a comment of the pull request when running in GitHub Actions "+
+					"with GITHUB_TOKEN set, or in Azure Pipelines with SYSTEM_ACCESSTOKEN set.",
+				output.WithHighLightFormat("--progress-comment"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"With %s or %s, the environments are provisioned and deployed concurrently, each by its own azd "+
+					"process, ex) the environments of a deployment stamped across regions. Limit the environments "+
+					"run at a time with %s.",
+				output.WithHighLightFormat("--environments"),
+				output.WithHighLightFormat("--all-environments"),
+				output.WithHighLightFormat("--max-parallel"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"The requests to ARM are limited per subscription, by default to %g reads and %g writes per second, "+
+					"split among the environments run at a time. Set the limits with %s and %s.",
+				azsdk.DefaultRateLimits.ReadsPerSecond,
+				azsdk.DefaultRateLimits.WritesPerSecond,
+				output.WithHighLightFormat(azsdk.ArmReadsPerSecondEnvVarName),
+				output.WithHighLightFormat(azsdk.ArmWritesPerSecondEnvVarName),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"With %s, the project is uploaded to the blob container of %s, and azd runs in the Container Apps "+
+					"job of %s with the credentials of the pipeline identity set on the job, ex) when the local "+
+					"network can't reach private resources. The image of the job must have azd installed.",
+				output.WithHighLightFormat("--remote"),
+				output.WithHighLightFormat(remote.StorageUrlEnvVarName),
+				output.WithHighLightFormat(remote.JobIdEnvVarName),
+			)),
+		})
 }
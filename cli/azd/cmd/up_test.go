@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteDeploymentSummary(t *testing.T) {
+	summary := &ux.DeploymentSummary{
+		Environment:    "dev",
+		DeployDuration: 30 * time.Second,
+		Services:       []ux.DeployedService{{Name: "api", Host: "containerapp"}},
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "summary.md")
+		require.NoError(t, writeDeploymentSummary(summary, path))
+
+		contents, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, summary.Markdown(), string(contents))
+	})
+
+	t.Run("PullRequestComment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "comment.json")
+		require.NoError(t, writeDeploymentSummary(summary, path))
+
+		contents, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var payload map[string]string
+		require.NoError(t, json.Unmarshal(contents, &payload))
+		require.Equal(t, summary.Markdown(), payload["body"])
+	})
+}
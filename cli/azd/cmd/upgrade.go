@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type upgradeFlags struct {
+	channel string
+	global  *internal.GlobalCommandOptions
+}
+
+func (f *upgradeFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.channel,
+		"channel",
+		"",
+		fmt.Sprintf(
+			"The release channel to upgrade from: '%s' or '%s'. Defaults to the '%s' config value, or '%s' when not set.",
+			update.ChannelStable,
+			update.ChannelBeta,
+			update.ChannelConfigKey,
+			update.ChannelStable,
+		),
+	)
+	f.global = global
+}
+
+func newUpgradeFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *upgradeFlags {
+	flags := &upgradeFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newUpgradeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade Azure Developer CLI to the latest version.",
+	}
+}
+
+type upgradeAction struct {
+	flags             *upgradeFlags
+	updateManager     *update.Manager
+	userConfigManager config.UserConfigManager
+	console           input.Console
+}
+
+func newUpgradeAction(
+	flags *upgradeFlags,
+	updateManager *update.Manager,
+	userConfigManager config.UserConfigManager,
+	console input.Console,
+) actions.Action {
+	return &upgradeAction{
+		flags:             flags,
+		updateManager:     updateManager,
+		userConfigManager: userConfigManager,
+		console:           console,
+	}
+}
+
+func (a *upgradeAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	channel, err := a.channel()
+	if err != nil {
+		return nil, err
+	}
+
+	currentVersion := internal.VersionInfo().Version
+
+	a.console.ShowSpinner(ctx, "Checking for a new version", input.Step)
+	latestVersion, err := a.updateManager.LatestVersion(ctx, channel)
+	a.console.StopSpinner(ctx, "", input.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	if !internal.IsDevVersion() && !latestVersion.GT(currentVersion) {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf(
+					"azd is up to date. You have %s, the latest version in the %s channel.", currentVersion, channel),
+			},
+		}, nil
+	}
+
+	a.console.Message(ctx, fmt.Sprintf(
+		"A new version of azd is available in the %s channel: %s (you have %s)\n",
+		channel,
+		output.WithHighLightFormat(latestVersion.String()),
+		currentVersion,
+	))
+
+	// Release notes are informational, failing to fetch them doesn't prevent the upgrade
+	if notes, err := a.updateManager.ReleaseNotes(ctx, latestVersion); err != nil {
+		log.Printf("failed to fetch release notes: %v", err)
+	} else if notes != "" {
+		a.console.Message(ctx, output.WithBold("Release notes"))
+		a.console.Message(ctx, notes+"\n")
+	}
+
+	if !update.SupportsSelfUpdate() {
+		return nil, fmt.Errorf(
+			"azd was not installed by the install script and can't upgrade itself. To upgrade, %s",
+			update.UpgradeInstructions(),
+		)
+	}
+
+	confirm, err := a.console.Confirm(ctx, input.ConsoleOptions{
+		Message:      fmt.Sprintf("Upgrade azd to %s?", latestVersion),
+		DefaultValue: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !confirm {
+		return nil, nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding current executable: %w", err)
+	}
+
+	if exePath, err = filepath.EvalSymlinks(exePath); err != nil {
+		return nil, fmt.Errorf("finding current executable: %w", err)
+	}
+
+	stepMessage := fmt.Sprintf("Upgrading azd to %s", latestVersion)
+	a.console.ShowSpinner(ctx, stepMessage, input.Step)
+	err = a.updateManager.Update(ctx, latestVersion, exePath)
+	a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, fmt.Errorf("upgrading azd: %w", err)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("azd was upgraded to %s.", latestVersion),
+		},
+	}, nil
+}
+
+// channel returns the release channel selected by the --channel flag or the user configuration
+func (a *upgradeAction) channel() (update.Channel, error) {
+	if a.flags.channel != "" {
+		return update.ParseChannel(a.flags.channel)
+	}
+
+	userConfig, err := a.userConfigManager.Load()
+	if err != nil {
+		return "", fmt.Errorf("loading user config: %w", err)
+	}
+
+	return update.ChannelFromConfig(userConfig)
+}
+
+func getCmdUpgradeHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Upgrade Azure Developer CLI to the latest version available in the selected release channel."+
+			" The downloaded release is verified against the checksums published with it before azd is replaced.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"Select the channel with %s, or persist it with %s.",
+				output.WithHighLightFormat("--channel"),
+				output.WithHighLightFormat("azd config set %s <channel>", update.ChannelConfigKey),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Disable the daily new version notice with %s.",
+				output.WithHighLightFormat("azd config set %s false", update.CheckConfigKey),
+			)),
+		})
+}
@@ -99,6 +99,10 @@ func createEnvironment(
 		return nil, fmt.Errorf("environment '%s' already exists", envSpec.environmentName)
 	}
 
+	if err := applyEnvironmentTemplate(ctx, azdCtx, env, envSpec.environmentName); err != nil {
+		return nil, err
+	}
+
 	env.SetEnvName(envSpec.environmentName)
 
 	if envSpec.subscription != "" {
@@ -116,6 +120,32 @@ func createEnvironment(
 	return env, nil
 }
 
+// applyEnvironmentTemplate sets the values of the environment template of the project on a new environment. Projects
+// without an azure.yaml, ex) while running azd init, have no template. The name of the environment isn't templated.
+func applyEnvironmentTemplate(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	env *environment.Environment,
+	environmentName string,
+) error {
+	projectConfig, err := project.LoadFs(ctx, azdCtx.Fs(), azdCtx.ProjectPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("loading environment template: %w", err)
+	}
+
+	for key, value := range projectConfig.Environment.Values(environmentName) {
+		if key == environment.EnvNameEnvVarName {
+			continue
+		}
+
+		env.DotenvSet(key, value)
+	}
+
+	return nil
+}
+
 func loadEnvironmentIfAvailable() (*environment.Environment, error) {
 	azdCtx, err := azdcontext.NewAzdContext()
 	if err != nil {
@@ -196,6 +226,10 @@ func loadOrCreateEnvironment(
 	}
 
 	if isNew {
+		if err := applyEnvironmentTemplate(ctx, azdCtx, env, environmentName); err != nil {
+			return nil, err
+		}
+
 		if env.GetEnvName() == "" {
 			env.SetEnvName(environmentName)
 		}
@@ -235,7 +269,7 @@ func getResourceGroupFollowUp(
 	resourceManager project.ResourceManager,
 	env *environment.Environment,
 ) (followUp string) {
-	if formatter.Kind() != output.JsonFormat {
+	if !formatter.Kind().IsStructured() {
 		subscriptionId := env.GetSubscriptionId()
 
 		if resourceGroupName, err := resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig); err == nil {
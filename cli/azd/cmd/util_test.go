@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -86,4 +87,40 @@ func Test_createAndInitEnvironment(t *testing.T) {
 			fmt.Sprintf("environment '%s' already exists",
 				validName))
 	})
+	t.Run("environment template", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		tempDir := t.TempDir()
+		azdContext := azdcontext.NewAzdContextWithDirectory(tempDir)
+		err := os.WriteFile(azdContext.ProjectPath(), []byte(`
+name: test-proj
+environment:
+  defaults:
+    AZURE_LOCATION: eastus2
+    APP_SERVICE_SKU: B1
+  overrides:
+    prod:
+      APP_SERVICE_SKU: P1v3
+`), 0600)
+		require.NoError(t, err)
+
+		env, err := createEnvironment(
+			*mockContext.Context,
+			environmentSpec{
+				environmentName: "prod",
+				location:        "westus3",
+			},
+			azdContext,
+			mockContext.Console,
+		)
+		require.NoError(t, err)
+
+		// values of the command win over the template
+		require.Equal(t, "westus3", env.GetLocation())
+		require.Equal(t, "P1v3", env.Getenv("APP_SERVICE_SKU"))
+		require.Equal(t, "prod", env.GetEnvName())
+
+		saved, err := environment.GetEnvironment(azdContext, "prod")
+		require.NoError(t, err)
+		require.Equal(t, "P1v3", saved.Getenv("APP_SERVICE_SKU"))
+	})
 }
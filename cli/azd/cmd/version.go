@@ -57,7 +57,8 @@ func (v *versionAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	switch v.formatter.Kind() {
 	case output.NoneFormat:
 		fmt.Fprintf(v.console.Handles().Stdout, "azd version %s\n", internal.Version)
-	case output.JsonFormat:
+	default:
+		// The structured formats, ex) json
 		var result contracts.VersionResult
 		versionSpec := internal.VersionInfo()
 
@@ -0,0 +1,344 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/workspace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
+)
+
+// The commands which don't run in the project of the current workspace, since they create projects or take the paths of
+// projects as arguments. They run in the project of --workspace only.
+var noCurrentWorkspaceCommands = []string{"init", "workspace", "template"}
+
+func workspaceActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
+	group := root.Add("workspace", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "workspace",
+			Short: "Register and switch between azd projects.",
+		},
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdWorkspaceHelpDescription,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupConfig,
+		},
+	})
+
+	group.Add("add", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "add <name> [<path>]",
+			Short: "Register the project of a directory as a workspace.",
+			Args:  cobra.RangeArgs(1, 2),
+		},
+		ActionResolver: newWorkspaceAddAction,
+	})
+
+	group.Add("remove", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:               "remove <name>",
+			Short:             "Unregister a workspace.",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: workspaceNameArgCompletion,
+		},
+		ActionResolver: newWorkspaceRemoveAction,
+	})
+
+	group.Add("list", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:     "list",
+			Short:   "List the registered workspaces.",
+			Aliases: []string{"ls"},
+		},
+		ActionResolver: newWorkspaceListAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat},
+		DefaultFormat:  output.TableFormat,
+	})
+
+	group.Add("switch", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:               "switch [<name>]",
+			Short:             "Set the workspace commands run against outside of a project.",
+			Args:              cobra.MaximumNArgs(1),
+			ValidArgsFunction: workspaceNameArgCompletion,
+		},
+		FlagsResolver:  newWorkspaceSwitchFlags,
+		ActionResolver: newWorkspaceSwitchAction,
+	})
+
+	return group
+}
+
+// workspaceDirectory returns the project directory of the workspace the command runs in, the workspace of --workspace,
+// or the current workspace when the command runs outside of a project. Returns an empty string when the command runs in
+// the current working directory.
+func workspaceDirectory(cmd *cobra.Command, opts *internal.GlobalCommandOptions) (string, error) {
+	manager := workspace.NewManager(config.NewUserConfigManager())
+
+	if opts.Workspace != "" {
+		if opts.Cwd != "" {
+			return "", errors.New("--workspace and --cwd can't be combined")
+		}
+
+		ws, err := manager.Get(opts.Workspace)
+		if err != nil {
+			return "", err
+		}
+
+		return ws.Path, nil
+	}
+
+	topLevel := cmd
+	for topLevel.HasParent() && topLevel.Parent().HasParent() {
+		topLevel = topLevel.Parent()
+	}
+
+	if opts.Cwd != "" || slices.Contains(noCurrentWorkspaceCommands, topLevel.Name()) {
+		return "", nil
+	}
+
+	if _, err := azdcontext.NewAzdContext(); !errors.Is(err, azdcontext.ErrNoProject) {
+		return "", nil
+	}
+
+	current, err := manager.Current()
+	if err != nil {
+		// Commands outside of a project run in the current working directory when the workspaces can't be read
+		log.Printf("failed reading current workspace: %v", err)
+		return "", nil
+	}
+
+	if current == nil {
+		return "", nil
+	}
+
+	log.Printf("running in the project of the current workspace '%s': %s", current.Name, current.Path)
+	return current.Path, nil
+}
+
+type workspaceAddAction struct {
+	args             []string
+	workspaceManager *workspace.Manager
+}
+
+func newWorkspaceAddAction(args []string, workspaceManager *workspace.Manager) actions.Action {
+	return &workspaceAddAction{
+		args:             args,
+		workspaceManager: workspaceManager,
+	}
+}
+
+func (a *workspaceAddAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	path := "."
+	if len(a.args) > 1 {
+		path = a.args[1]
+	}
+
+	ws, err := a.workspaceManager.Add(a.args[0], path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Registered workspace %s for %s.", ws.Name, ws.Path),
+			FollowUp: fmt.Sprintf("Run commands against the project from any directory with %s.",
+				output.WithHighLightFormat("-w %s", ws.Name)),
+		},
+	}, nil
+}
+
+type workspaceRemoveAction struct {
+	args             []string
+	workspaceManager *workspace.Manager
+}
+
+func newWorkspaceRemoveAction(args []string, workspaceManager *workspace.Manager) actions.Action {
+	return &workspaceRemoveAction{
+		args:             args,
+		workspaceManager: workspaceManager,
+	}
+}
+
+func (a *workspaceRemoveAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if err := a.workspaceManager.Remove(a.args[0]); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+type workspaceListAction struct {
+	workspaceManager *workspace.Manager
+	formatter        output.Formatter
+	writer           io.Writer
+}
+
+func newWorkspaceListAction(
+	workspaceManager *workspace.Manager,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &workspaceListAction{
+		workspaceManager: workspaceManager,
+		formatter:        formatter,
+		writer:           writer,
+	}
+}
+
+func (a *workspaceListAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	workspaces, err := a.workspaceManager.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing workspaces: %w", err)
+	}
+
+	if a.formatter.Kind() == output.TableFormat {
+		err = a.formatter.Format(workspaces, a.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{
+					Heading:       "NAME",
+					ValueTemplate: "{{.Name}}",
+				},
+				{
+					Heading:       "PATH",
+					ValueTemplate: "{{.Path}}",
+				},
+				{
+					Heading:       "DEFAULT ENVIRONMENT",
+					ValueTemplate: "{{.DefaultEnvironment}}",
+				},
+				{
+					Heading:       "CURRENT",
+					ValueTemplate: "{{.Current}}",
+				},
+			},
+		})
+	} else {
+		err = a.formatter.Format(workspaces, a.writer, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+type workspaceSwitchFlags struct {
+	none bool
+}
+
+func (f *workspaceSwitchFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.none,
+		"none",
+		false,
+		"Clears the current workspace, commands outside of a project no longer run against a workspace.")
+}
+
+func newWorkspaceSwitchFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *workspaceSwitchFlags {
+	flags := &workspaceSwitchFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+type workspaceSwitchAction struct {
+	flags            *workspaceSwitchFlags
+	args             []string
+	workspaceManager *workspace.Manager
+}
+
+func newWorkspaceSwitchAction(
+	flags *workspaceSwitchFlags,
+	args []string,
+	workspaceManager *workspace.Manager,
+) actions.Action {
+	return &workspaceSwitchAction{
+		flags:            flags,
+		args:             args,
+		workspaceManager: workspaceManager,
+	}
+}
+
+func (a *workspaceSwitchAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if a.flags.none == (len(a.args) == 1) {
+		return nil, errors.New("specify either the name of the workspace to switch to, or --none")
+	}
+
+	name := ""
+	if len(a.args) == 1 {
+		name = a.args[0]
+	}
+
+	if err := a.workspaceManager.Switch(name); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Switched to workspace %s.", name),
+		},
+	}, nil
+}
+
+// workspaceNameCompletion completes the names of the registered workspaces
+func workspaceNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	workspaces, err := workspace.NewManager(config.NewUserConfigManager()).List()
+	if err != nil {
+		cobra.CompError(fmt.Sprintf("Error listing workspaces: %s", err))
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, len(workspaces))
+	for i, ws := range workspaces {
+		names[i] = ws.Name
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// workspaceNameArgCompletion completes the name of the workspace of the first argument
+func workspaceNameArgCompletion(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return workspaceNameCompletion(cmd, args, toComplete)
+}
+
+func getCmdWorkspaceHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Register the directories of your azd projects as workspaces, to run commands against a project without "+
+			"changing to its directory.",
+		[]string{
+			formatHelpNote(fmt.Sprintf("Any command runs in the project of a workspace with %s, ex) %s.",
+				output.WithHighLightFormat("--workspace"),
+				output.WithHighLightFormat("azd deploy -w shop-api"))),
+			formatHelpNote(fmt.Sprintf(
+				"After %s, commands run outside of a project run in the project of the current workspace.",
+				output.WithHighLightFormat("azd workspace switch"))),
+			formatHelpNote("The workspaces are stored in the azd user configuration."),
+		})
+}
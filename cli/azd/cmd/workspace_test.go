@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/workspace"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceDirectory(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, azdcontext.ProjectFileName), []byte("name: shop"), 0600))
+
+	manager := workspace.NewManager(config.NewUserConfigManager())
+	ws, err := manager.Add("shop-api", projectDir)
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "azd"}
+	deploy := &cobra.Command{Use: "deploy"}
+	initCmd := &cobra.Command{Use: "init"}
+	root.AddCommand(deploy, initCmd)
+
+	ostest.Chdir(t, t.TempDir())
+
+	t.Run("Workspace", func(t *testing.T) {
+		dir, err := workspaceDirectory(deploy, &internal.GlobalCommandOptions{Workspace: "shop-api"})
+		require.NoError(t, err)
+		require.Equal(t, ws.Path, dir)
+
+		_, err = workspaceDirectory(deploy, &internal.GlobalCommandOptions{Workspace: "shop-api", Cwd: "."})
+		require.EqualError(t, err, "--workspace and --cwd can't be combined")
+
+		_, err = workspaceDirectory(deploy, &internal.GlobalCommandOptions{Workspace: "orders"})
+		require.ErrorContains(t, err, "workspace 'orders' isn't registered")
+	})
+
+	t.Run("CurrentWorkspace", func(t *testing.T) {
+		dir, err := workspaceDirectory(deploy, &internal.GlobalCommandOptions{})
+		require.NoError(t, err)
+		require.Empty(t, dir)
+
+		require.NoError(t, manager.Switch("shop-api"))
+		t.Cleanup(func() { _ = manager.Switch("") })
+
+		dir, err = workspaceDirectory(deploy, &internal.GlobalCommandOptions{})
+		require.NoError(t, err)
+		require.Equal(t, ws.Path, dir)
+
+		// Projects are created in the current working directory
+		dir, err = workspaceDirectory(initCmd, &internal.GlobalCommandOptions{})
+		require.NoError(t, err)
+		require.Empty(t, dir)
+
+		// Commands run in their project, not in the current workspace
+		otherDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(otherDir, azdcontext.ProjectFileName), []byte("name: other"), 0600))
+		ostest.Chdir(t, otherDir)
+
+		dir, err = workspaceDirectory(deploy, &internal.GlobalCommandOptions{})
+		require.NoError(t, err)
+		require.Empty(t, dir)
+	})
+}
@@ -0,0 +1,19 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+// ErrorWithExitCode is returned by commands exiting with a specific exit code instead of 1, ex) to let scripts tell
+// apart failures from the outcome of a check.
+type ErrorWithExitCode struct {
+	Err      error
+	ExitCode int
+}
+
+func (e *ErrorWithExitCode) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ErrorWithExitCode) Unwrap() error {
+	return e.Err
+}
@@ -7,6 +7,10 @@ type GlobalCommandOptions struct {
 	// easier)
 	Cwd string
 
+	// Workspace is the name of the registered workspace whose project directory the command runs in, instead of the
+	// current working directory. It's set with `--workspace`, for any command.
+	Workspace string
+
 	// EnableDebugLogging indicates you should turn on verbose/debug logging in your command any
 	// launched tools. It's enabled with `--debug`, for any command.
 	EnableDebugLogging bool
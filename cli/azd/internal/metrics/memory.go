@@ -0,0 +1,38 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package metrics
+
+import "context"
+
+// MemorySink keeps the counters in memory, ex) for tests to assert the counters of the code they exercise
+type MemorySink struct {
+	counters *counters
+}
+
+// NewMemorySink creates a sink keeping the counters in memory
+func NewMemorySink() *MemorySink {
+	return &MemorySink{counters: newCounters()}
+}
+
+func (s *MemorySink) Add(name string, value int64, tags map[string]string) {
+	s.counters.add(name, value, tags)
+}
+
+func (s *MemorySink) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Value returns the value of the counter with the name and tags, 0 when nothing was added to it
+func (s *MemorySink) Value(name string, tags map[string]string) int64 {
+	key := counterKey(name, tags)
+
+	s.counters.mu.Lock()
+	defer s.counters.mu.Unlock()
+
+	if counter, has := s.counters.values[key]; has {
+		return counter.value
+	}
+
+	return 0
+}
@@ -0,0 +1,227 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package metrics counts the internal operations of azd, ex) the calls to Azure APIs and their retries, and exports the
+// counters to a sink configured by the automation wrapping azd, to track the performance and failure rates of azd
+// across its runs.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// Names of the counters of azd
+const (
+	// The calls to Azure APIs, tagged with the host and status of the call, ex) 2xx, or error when the call failed
+	// without a response
+	ApiCalls = "azd.api.calls"
+	// The retries of calls to Azure APIs, tagged with the host of the call
+	ApiRetries = "azd.api.retries"
+	// The calls to ARM throttled with 429 responses, tagged with the host of the call
+	ApiThrottled = "azd.api.throttled"
+	// The milliseconds the calls to ARM were delayed to stay under the rate limits of their subscription, tagged with the
+	// host of the call
+	ApiRateLimitWait = "azd.api.rate_limit.wait_ms"
+	// The lookups hitting a cache of azd, tagged with the cache, ex) subscriptions or build
+	CacheHits = "azd.cache.hits"
+	// The lookups missing a cache of azd, tagged with the cache
+	CacheMisses = "azd.cache.misses"
+	// The bytes uploaded to Azure, ex) zip deployments and artifacts pushed to registries, tagged with the kind of upload
+	BytesPushed = "azd.bytes.pushed"
+	// The commands run, tagged with the command and its result, success or failure
+	Commands = "azd.commands"
+	// The milliseconds the commands took, tagged like Commands
+	CommandDuration = "azd.commands.duration_ms"
+)
+
+const (
+	// The address of the statsd agent to send the counters to over UDP, ex) localhost:8125
+	StatsdAddressEnvVarName = "AZD_METRICS_STATSD_ADDR"
+	// The endpoint of the OpenTelemetry collector to export the counters to with OTLP/HTTP, ex) http://localhost:4318
+	OtlpEndpointEnvVarName = "AZD_METRICS_OTLP_ENDPOINT"
+)
+
+// Sink receives the counters of azd. Sinks are safe for concurrent use.
+type Sink interface {
+	// Add adds the value to the counter with the name and tags
+	Add(name string, value int64, tags map[string]string)
+	// Flush exports the counters added since the last flush
+	Flush(ctx context.Context) error
+}
+
+var (
+	sinkMu sync.RWMutex
+	sink   Sink = noopSink{}
+)
+
+// SetSink sets the sink of the counters of azd, which discards them by default
+func SetSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	if s == nil {
+		s = noopSink{}
+	}
+	sink = s
+}
+
+// Add adds the value to the counter with the name and tags
+func Add(name string, value int64, tags map[string]string) {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+
+	sink.Add(name, value, tags)
+}
+
+// Flush exports the counters of azd to its sink
+func Flush(ctx context.Context) error {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+
+	return sink.Flush(ctx)
+}
+
+// NewSinkFromEnv creates the sinks configured with AZD_METRICS_STATSD_ADDR and AZD_METRICS_OTLP_ENDPOINT. It returns nil
+// when neither is set.
+func NewSinkFromEnv(httpClient httputil.HttpClient) Sink {
+	sinks := multiSink{}
+	if address := os.Getenv(StatsdAddressEnvVarName); address != "" {
+		sinks = append(sinks, NewStatsdSink(address))
+	}
+
+	if endpoint := os.Getenv(OtlpEndpointEnvVarName); endpoint != "" {
+		sinks = append(sinks, NewOtlpSink(endpoint, httpClient))
+	}
+
+	switch len(sinks) {
+	case 0:
+		return nil
+	case 1:
+		return sinks[0]
+	default:
+		return sinks
+	}
+}
+
+type noopSink struct{}
+
+func (noopSink) Add(name string, value int64, tags map[string]string) {}
+
+func (noopSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+// multiSink adds the counters to each of its sinks
+type multiSink []Sink
+
+func (m multiSink) Add(name string, value int64, tags map[string]string) {
+	for _, s := range m {
+		s.Add(name, value, tags)
+	}
+}
+
+func (m multiSink) Flush(ctx context.Context) error {
+	var errs []string
+	for _, s := range m {
+		if err := s.Flush(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("flushing metrics: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// counter is the sum of the values added to a counter with its name and tags
+type counter struct {
+	name  string
+	tags  map[string]string
+	value int64
+}
+
+// counters aggregates the values added to the counters of a sink until they're exported
+type counters struct {
+	mu     sync.Mutex
+	values map[string]*counter
+}
+
+func newCounters() *counters {
+	return &counters{values: map[string]*counter{}}
+}
+
+func (c *counters) add(name string, value int64, tags map[string]string) {
+	key := counterKey(name, tags)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, has := c.values[key]
+	if !has {
+		copied := make(map[string]string, len(tags))
+		for k, v := range tags {
+			copied[k] = v
+		}
+
+		existing = &counter{name: name, tags: copied}
+		c.values[key] = existing
+	}
+
+	existing.value += value
+}
+
+// snapshot returns the counters sorted by their name and tags. With reset, the counters start over from zero.
+func (c *counters) snapshot(reset bool) []counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	snapshot := make([]counter, 0, len(keys))
+	for _, key := range keys {
+		snapshot = append(snapshot, *c.values[key])
+	}
+
+	if reset {
+		c.values = map[string]*counter{}
+	}
+
+	return snapshot
+}
+
+// sortedTagKeys returns the keys of the tags in order, for the counters to be exported deterministically
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func counterKey(name string, tags map[string]string) string {
+	var key strings.Builder
+	key.WriteString(name)
+	for _, tag := range sortedTagKeys(tags) {
+		key.WriteString("\x00")
+		key.WriteString(tag)
+		key.WriteString("=")
+		key.WriteString(tags[tag])
+	}
+
+	return key.String()
+}
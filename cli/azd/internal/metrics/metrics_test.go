@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_Add(t *testing.T) {
+	sink := NewMemorySink()
+	SetSink(sink)
+	t.Cleanup(func() { SetSink(nil) })
+
+	Add(ApiCalls, 1, map[string]string{"host": "management.azure.com", "status": "2xx"})
+	Add(ApiCalls, 2, map[string]string{"status": "2xx", "host": "management.azure.com"})
+	Add(ApiCalls, 1, map[string]string{"host": "management.azure.com", "status": "4xx"})
+
+	require.Equal(t, int64(3), sink.Value(ApiCalls, map[string]string{"host": "management.azure.com", "status": "2xx"}))
+	require.Equal(t, int64(1), sink.Value(ApiCalls, map[string]string{"host": "management.azure.com", "status": "4xx"}))
+	require.Equal(t, int64(0), sink.Value(ApiRetries, nil))
+}
+
+func Test_NewSinkFromEnv(t *testing.T) {
+	t.Setenv(StatsdAddressEnvVarName, "")
+	t.Setenv(OtlpEndpointEnvVarName, "")
+	require.Nil(t, NewSinkFromEnv(http.DefaultClient))
+
+	t.Setenv(StatsdAddressEnvVarName, "localhost:8125")
+	require.IsType(t, &StatsdSink{}, NewSinkFromEnv(http.DefaultClient))
+
+	t.Setenv(OtlpEndpointEnvVarName, "http://localhost:4318")
+	require.IsType(t, multiSink{}, NewSinkFromEnv(http.DefaultClient))
+}
+
+func Test_StatsdSink(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sink := NewStatsdSink(listener.LocalAddr().String())
+	sink.Add(ApiCalls, 2, map[string]string{"status": "2xx", "host": "management.azure.com"})
+	sink.Add(Commands, 1, map[string]string{"command": "cmd.up", "result": "success"})
+	sink.Add(CacheHits, 1, nil)
+	require.NoError(t, sink.Flush(context.Background()))
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	packet := make([]byte, statsdMaxPacketSize)
+	n, _, err := listener.ReadFrom(packet)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"azd.api.calls:2|c|#host:management.azure.com,status:2xx",
+		"azd.cache.hits:1|c",
+		"azd.commands:1|c|#command:cmd.up,result:success",
+	}, "\n"), string(packet[:n]))
+
+	// Flushes send the values added since the last flush
+	require.NoError(t, sink.Flush(context.Background()))
+	sink.Add(CacheHits, 1, nil)
+	require.NoError(t, sink.Flush(context.Background()))
+
+	n, _, err = listener.ReadFrom(packet)
+	require.NoError(t, err)
+	require.Equal(t, "azd.cache.hits:1|c", string(packet[:n]))
+}
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_OtlpSink(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	status := http.StatusOK
+	httpClient := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, req)
+		bodies = append(bodies, body)
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewReader([]byte("unavailable"))),
+			Request:    req,
+		}, nil
+	})
+
+	sink := NewOtlpSink("http://localhost:4318/", httpClient)
+
+	// Nothing is exported without counters
+	require.NoError(t, sink.Flush(context.Background()))
+	require.Empty(t, requests)
+
+	sink.Add(ApiCalls, 2, map[string]string{"host": "management.azure.com", "status": "2xx"})
+	sink.Add(ApiCalls, 1, map[string]string{"host": "management.azure.com", "status": "5xx"})
+	sink.Add(BytesPushed, 1024, map[string]string{"upload": "zipdeploy"})
+	require.NoError(t, sink.Flush(context.Background()))
+
+	require.Len(t, requests, 1)
+	require.Equal(t, "http://localhost:4318/v1/metrics", requests[0].URL.String())
+	require.Equal(t, "application/x-protobuf", requests[0].Header.Get("Content-Type"))
+
+	var data metricspb.MetricsData
+	require.NoError(t, proto.Unmarshal(bodies[0], &data))
+
+	metrics := data.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+	require.Equal(t, ApiCalls, metrics[0].Name)
+	require.Equal(t, BytesPushed, metrics[1].Name)
+
+	sum := metrics[0].GetSum()
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 2)
+	require.Equal(t, int64(2), sum.DataPoints[0].GetAsInt())
+	require.Equal(t, "host", sum.DataPoints[0].Attributes[0].Key)
+	require.Equal(t, "5xx", sum.DataPoints[1].Attributes[1].Value.GetStringValue())
+
+	// Sums are cumulative across flushes
+	sink.Add(BytesPushed, 1024, map[string]string{"upload": "zipdeploy"})
+	require.NoError(t, sink.Flush(context.Background()))
+	require.NoError(t, proto.Unmarshal(bodies[1], &data))
+	require.Equal(t, int64(2048), data.ResourceMetrics[0].ScopeMetrics[0].Metrics[1].GetSum().DataPoints[0].GetAsInt())
+
+	status = http.StatusServiceUnavailable
+	err := sink.Flush(context.Background())
+	require.ErrorContains(t, err, "collector responded with status 503: unavailable")
+}
+
+type failingSink struct {
+	MemorySink
+}
+
+func (s *failingSink) Flush(ctx context.Context) error {
+	return errors.New("agent unreachable")
+}
+
+func Test_MultiSink(t *testing.T) {
+	memory := NewMemorySink()
+	failing := &failingSink{MemorySink: *NewMemorySink()}
+	sinks := multiSink{memory, failing}
+
+	sinks.Add(CacheMisses, 1, map[string]string{"cache": "build"})
+	require.Equal(t, int64(1), memory.Value(CacheMisses, map[string]string{"cache": "build"}))
+	require.Equal(t, int64(1), failing.Value(CacheMisses, map[string]string{"cache": "build"}))
+
+	require.EqualError(t, sinks.Flush(context.Background()), "flushing metrics: agent unreachable")
+}
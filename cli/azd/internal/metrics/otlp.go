@@ -0,0 +1,143 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// The path collectors receive metrics at with OTLP/HTTP
+const otlpMetricsPath = "/v1/metrics"
+
+// OtlpSink exports the counters to an OpenTelemetry collector with OTLP/HTTP, as monotonic cumulative sums since the
+// sink was created
+type OtlpSink struct {
+	url        string
+	httpClient httputil.HttpClient
+	start      time.Time
+	counters   *counters
+}
+
+// NewOtlpSink creates a sink exporting the counters to the collector at the endpoint, ex) http://localhost:4318
+func NewOtlpSink(endpoint string, httpClient httputil.HttpClient) *OtlpSink {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, otlpMetricsPath) {
+		url += otlpMetricsPath
+	}
+
+	return &OtlpSink{
+		url:        url,
+		httpClient: httpClient,
+		start:      time.Now(),
+		counters:   newCounters(),
+	}
+}
+
+func (s *OtlpSink) Add(name string, value int64, tags map[string]string) {
+	s.counters.add(name, value, tags)
+}
+
+func (s *OtlpSink) Flush(ctx context.Context) error {
+	counters := s.counters.snapshot(false)
+	if len(counters) == 0 {
+		return nil
+	}
+
+	// MetricsData is encoded like the ExportMetricsServiceRequest of the collector, without the dependencies of its
+	// gRPC service
+	body, err := proto.Marshal(&metricspb.MetricsData{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: otlpAttributes(map[string]string{
+						"service.name":    "azd",
+						"service.version": internal.VersionInfo().Version.String(),
+					}),
+				},
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope:   &commonpb.InstrumentationScope{Name: "github.com/azure/azure-dev/cli/azd/internal/metrics"},
+						Metrics: otlpMetrics(counters, s.start, time.Now()),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating metrics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting metrics: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		details, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("exporting metrics: collector responded with status %d: %s", res.StatusCode, details)
+	}
+
+	return nil
+}
+
+// otlpMetrics groups the counters by their name, as the data points of a sum each
+func otlpMetrics(counters []counter, start time.Time, now time.Time) []*metricspb.Metric {
+	metrics := []*metricspb.Metric{}
+	sums := map[string]*metricspb.Sum{}
+	for _, counter := range counters {
+		sum, has := sums[counter.name]
+		if !has {
+			sum = &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}
+			sums[counter.name] = sum
+			metrics = append(metrics, &metricspb.Metric{
+				Name: counter.name,
+				Data: &metricspb.Metric_Sum{Sum: sum},
+			})
+		}
+
+		sum.DataPoints = append(sum.DataPoints, &metricspb.NumberDataPoint{
+			Attributes:        otlpAttributes(counter.tags),
+			StartTimeUnixNano: uint64(start.UnixNano()),
+			TimeUnixNano:      uint64(now.UnixNano()),
+			Value:             &metricspb.NumberDataPoint_AsInt{AsInt: counter.value},
+		})
+	}
+
+	return metrics
+}
+
+func otlpAttributes(tags map[string]string) []*commonpb.KeyValue {
+	attributes := make([]*commonpb.KeyValue, 0, len(tags))
+	for _, key := range sortedTagKeys(tags) {
+		attributes = append(attributes, &commonpb.KeyValue{
+			Key:   key,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: tags[key]}},
+		})
+	}
+
+	return attributes
+}
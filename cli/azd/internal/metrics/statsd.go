@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The largest UDP packet sent to the statsd agent, which fits the MTU of common networks without fragmentation
+const statsdMaxPacketSize = 1432
+
+// Characters with a meaning in the statsd format, replaced in the names and tags of the counters
+var statsdReplacer = strings.NewReplacer(":", "_", "|", "_", ",", "_", "#", "_", "\n", "_")
+
+// StatsdSink sends the counters to a statsd agent over UDP, in the DogStatsD format supporting tags, ex)
+// azd.api.calls:3|c|#host:management.azure.com,status:2xx. Each flush sends the values added since the last one.
+type StatsdSink struct {
+	address  string
+	counters *counters
+}
+
+// NewStatsdSink creates a sink sending the counters to the statsd agent at the address, ex) localhost:8125
+func NewStatsdSink(address string) *StatsdSink {
+	return &StatsdSink{
+		address:  address,
+		counters: newCounters(),
+	}
+}
+
+func (s *StatsdSink) Add(name string, value int64, tags map[string]string) {
+	s.counters.add(name, value, tags)
+}
+
+func (s *StatsdSink) Flush(ctx context.Context) error {
+	counters := s.counters.snapshot(true)
+	if len(counters) == 0 {
+		return nil
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", s.address)
+	if err != nil {
+		return fmt.Errorf("connecting to statsd agent: %w", err)
+	}
+	defer conn.Close()
+
+	var packet bytes.Buffer
+	send := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+
+		defer packet.Reset()
+		if _, err := conn.Write(packet.Bytes()); err != nil {
+			return fmt.Errorf("sending metrics to statsd agent: %w", err)
+		}
+
+		return nil
+	}
+
+	for _, counter := range counters {
+		line := statsdLine(counter)
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacketSize {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+
+	return send()
+}
+
+func statsdLine(counter counter) string {
+	line := fmt.Sprintf("%s:%d|c", statsdReplacer.Replace(counter.name), counter.value)
+	if len(counter.tags) == 0 {
+		return line
+	}
+
+	tags := make([]string, 0, len(counter.tags))
+	for _, key := range sortedTagKeys(counter.tags) {
+		tags = append(tags, statsdReplacer.Replace(key)+":"+statsdReplacer.Replace(counter.tags[key]))
+	}
+
+	return line + "|#" + strings.Join(tags, ",")
+}
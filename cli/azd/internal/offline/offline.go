@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package offline supports running azd on isolated networks. When offline mode is enabled, content azd would otherwise
+// download from the internet (templates, tools and version information) is served from a local mirror, and other outbound
+// calls, such as telemetry uploads, are disabled. Calls to the target cloud endpoints are unaffected.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// EnabledEnvVarName is the name of the environment variable which, when set to true, enables offline mode.
+const EnabledEnvVarName = "AZD_OFFLINE"
+
+// MirrorEnvVarName is the name of the environment variable holding the location of the mirror content is served from in
+// offline mode. The mirror is either a directory or the base URL of an internal HTTP server. Content is located in the
+// mirror by the host and path of its public URL, e.g. https://github.com/cli/cli/releases/... is served from
+// <mirror>/github.com/cli/cli/releases/...
+const MirrorEnvVarName = "AZD_OFFLINE_MIRROR"
+
+// ErrNoMirror is returned when content is requested in offline mode, but no mirror is configured.
+var ErrNoMirror = fmt.Errorf("azd is running in offline mode and %s is not set", MirrorEnvVarName)
+
+// IsEnabled returns true when offline mode is enabled.
+func IsEnabled() bool {
+	value, has := os.LookupEnv(EnabledEnvVarName)
+	if !has {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("could not parse value for %s as a boolean (it was: %s), offline mode is disabled", EnabledEnvVarName, value)
+		return false
+	}
+
+	return enabled
+}
+
+// Mirror returns the configured mirror, or an empty string when no mirror is configured.
+func Mirror() string {
+	return os.Getenv(MirrorEnvVarName)
+}
+
+// MirrorLocation returns the location of rawUrl in the mirror when offline mode is enabled, and rawUrl unchanged otherwise.
+// The returned location is a file path when the mirror is a directory.
+func MirrorLocation(rawUrl string) (string, error) {
+	if !IsEnabled() {
+		return rawUrl, nil
+	}
+
+	mirror := Mirror()
+	if mirror == "" {
+		return "", ErrNoMirror
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("parsing url '%s': %w", rawUrl, err)
+	}
+
+	if u.Host == "" {
+		// Not a remote location (e.g. a local path), nothing to mirror.
+		return rawUrl, nil
+	}
+
+	if isHttpMirror(mirror) {
+		mirrorUrl, err := url.Parse(mirror)
+		if err != nil {
+			return "", fmt.Errorf("parsing %s: %w", MirrorEnvVarName, err)
+		}
+
+		mirrorUrl.Path = path.Join(mirrorUrl.Path, u.Host, u.Path)
+		mirrorUrl.RawQuery = u.RawQuery
+		return mirrorUrl.String(), nil
+	}
+
+	return filepath.Join(mirror, u.Host, filepath.FromSlash(u.Path)), nil
+}
+
+func isHttpMirror(mirror string) bool {
+	return strings.HasPrefix(mirror, "https://") || strings.HasPrefix(mirror, "http://")
+}
+
+// NewTransporter wraps transporter so requests are served from the mirror when offline mode is enabled.
+func NewTransporter(transporter policy.Transporter) policy.Transporter {
+	return &mirrorTransporter{
+		inner: transporter,
+	}
+}
+
+type mirrorTransporter struct {
+	inner policy.Transporter
+}
+
+func (t *mirrorTransporter) Do(req *http.Request) (*http.Response, error) {
+	if !IsEnabled() {
+		return t.inner.Do(req)
+	}
+
+	location, err := MirrorLocation(req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("offline mode: serving %s from %s", req.URL, location)
+
+	if isHttpMirror(location) {
+		mirrorUrl, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+
+		mirrored := req.Clone(req.Context())
+		mirrored.URL = mirrorUrl
+		mirrored.Host = mirrorUrl.Host
+		return t.inner.Do(mirrored)
+	}
+
+	return serveFile(req, location)
+}
+
+// serveFile creates a response for a request with the contents of the file at location.
+func serveFile(req *http.Request, location string) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("offline mode: %s requests can not be served from mirror %s", req.Method, Mirror())
+	}
+
+	response := &http.Response{
+		Request:    req,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+	}
+
+	f, err := os.Open(location)
+	if errors.Is(err, os.ErrNotExist) {
+		response.StatusCode = http.StatusNotFound
+		response.Status = http.StatusText(http.StatusNotFound)
+		response.Body = http.NoBody
+		return response, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(http.StatusOK)
+	response.Body = f
+
+	if stat, err := f.Stat(); err == nil {
+		response.ContentLength = stat.Size()
+	}
+
+	return response, nil
+}
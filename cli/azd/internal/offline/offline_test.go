@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package offline
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransporter struct {
+	requests []*http.Request
+}
+
+func (f *fakeTransporter) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestMirrorLocation(t *testing.T) {
+	location, err := MirrorLocation("https://github.com/Azure-Samples/todo-nodejs-mongo")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/Azure-Samples/todo-nodejs-mongo", location)
+
+	t.Setenv(EnabledEnvVarName, "true")
+
+	_, err = MirrorLocation("https://github.com/Azure-Samples/todo-nodejs-mongo")
+	require.ErrorIs(t, err, ErrNoMirror)
+
+	t.Setenv(MirrorEnvVarName, "https://mirror.contoso.com/azd")
+	location, err = MirrorLocation("https://github.com/Azure-Samples/todo-nodejs-mongo")
+	require.NoError(t, err)
+	require.Equal(t, "https://mirror.contoso.com/azd/github.com/Azure-Samples/todo-nodejs-mongo", location)
+
+	mirrorDir := t.TempDir()
+	t.Setenv(MirrorEnvVarName, mirrorDir)
+	location, err = MirrorLocation("https://github.com/Azure-Samples/todo-nodejs-mongo")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(mirrorDir, "github.com", "Azure-Samples", "todo-nodejs-mongo"), location)
+}
+
+func TestTransporter(t *testing.T) {
+	inner := &fakeTransporter{}
+	transporter := NewTransporter(inner)
+
+	req, err := http.NewRequest(http.MethodGet, "https://downloads.bicep.azure.com/v0.18.4/bicep-linux-x64", nil)
+	require.NoError(t, err)
+
+	// Requests are sent as-is when offline mode is disabled
+	_, err = transporter.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "downloads.bicep.azure.com", inner.requests[0].URL.Host)
+
+	t.Setenv(EnabledEnvVarName, "true")
+
+	t.Run("HttpMirror", func(t *testing.T) {
+		t.Setenv(MirrorEnvVarName, "https://mirror.contoso.com")
+
+		_, err = transporter.Do(req)
+		require.NoError(t, err)
+		require.Equal(t,
+			"https://mirror.contoso.com/downloads.bicep.azure.com/v0.18.4/bicep-linux-x64",
+			inner.requests[1].URL.String())
+	})
+
+	t.Run("DirectoryMirror", func(t *testing.T) {
+		mirrorDir := t.TempDir()
+		t.Setenv(MirrorEnvVarName, mirrorDir)
+
+		res, err := transporter.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		filePath := filepath.Join(mirrorDir, "downloads.bicep.azure.com", "v0.18.4", "bicep-linux-x64")
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte("this is bicep"), 0600))
+
+		res, err = transporter.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		contents, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "this is bicep", string(contents))
+		require.Len(t, inner.requests, 2)
+	})
+}
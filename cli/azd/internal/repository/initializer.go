@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/internal/offline"
+	"github.com/azure/azure-dev/cli/azd/pkg/appimport"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
@@ -116,6 +118,12 @@ func (i *Initializer) fetchCode(
 	templateUrl string,
 	templateBranch string,
 	destination string) (executableFilePaths []string, err error) {
+	// In offline mode, the template repository is cloned from the mirror
+	templateUrl, err = offline.MirrorLocation(templateUrl)
+	if err != nil {
+		return nil, fmt.Errorf("fetching template: %w", err)
+	}
+
 	err = i.gitCli.ShallowClone(ctx, templateUrl, templateBranch, destination)
 	if err != nil {
 		return nil, fmt.Errorf("fetching template: %w", err)
@@ -333,6 +341,45 @@ func (i *Initializer) InitializeMinimal(ctx context.Context, azdCtx *azdcontext.
 	return nil
 }
 
+// Initializes an azd project reproducing an app already deployed to Azure, see [appimport.App.Scaffold].
+func (i *Initializer) InitializeFromApp(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	app *appimport.App) (*appimport.ScaffoldResult, error) {
+	projectDir := azdCtx.ProjectDirectory()
+	var err error
+
+	projectFormatted := output.WithLinkFormat("%s", projectDir)
+	i.console.ShowSpinner(ctx,
+		fmt.Sprintf("Creating project files of %s at: %s", app.Name, projectFormatted),
+		input.Step)
+	defer i.console.StopSpinner(ctx,
+		fmt.Sprintf("Created project files of %s at: %s", app.Name, projectFormatted)+"\n",
+		input.GetStepResultFormat(err))
+
+	isEmpty, err := isEmptyDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := app.Scaffold(ctx, projectDir, azdCtx.GetDefaultProjectName())
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.writeCoreAssets(ctx, azdCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.gitInitialize(ctx, projectDir, []string{}, isEmpty)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // writeFileSafe writes a file to path but only if it doesn't already exist.
 // If it does exist, an extra attempt is performed to write the file with the retryInfix appended to the filename,
 // before the file extension.
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -115,8 +116,11 @@ func SetAttributeAsPropertyOrMeasurement(
 	measurements map[string]float64) {
 
 	switch kv.Value.Type() {
-	case attribute.BOOL, attribute.STRING:
+	case attribute.BOOL:
 		properties[string(kv.Key)] = kv.Value.Emit()
+	case attribute.STRING:
+		// values flagged secure, ex) the values of secure Bicep parameters, are never sent
+		properties[string(kv.Key)] = redact.String(kv.Value.AsString())
 	case attribute.INT64:
 		measurements[string(kv.Key)] = float64(kv.Value.AsInt64())
 	case attribute.FLOAT64:
@@ -127,7 +131,7 @@ func SetAttributeAsPropertyOrMeasurement(
 			diagLog.Printf("Could not serialize slice of type '%s' as JSON array: %s", kv.Value.Type(), err.Error())
 			return
 		}
-		properties[string(kv.Key)] = string(arrayJson)
+		properties[string(kv.Key)] = redact.String(string(arrayJson))
 	default:
 		diagLog.Printf("Telemetry data warning, unknown type: %s", kv.Value.Type())
 		return
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
@@ -205,3 +206,19 @@ func getDefaultSpanStub() tracetest.SpanStub {
 		),
 	}
 }
+
+func TestSetAttributeAsPropertyRedactsSecrets(t *testing.T) {
+	redact.AddSecret("S3cr3tTelemetryV@lue")
+
+	properties := map[string]string{}
+	measurements := map[string]float64{}
+	SetAttributeAsPropertyOrMeasurement(
+		attribute.String("string", "value: S3cr3tTelemetryV@lue"), properties, measurements)
+	SetAttributeAsPropertyOrMeasurement(
+		attribute.StringSlice("slice", []string{"value", "S3cr3tTelemetryV@lue"}), properties, measurements)
+
+	assert.Equal(t, map[string]string{
+		"string": "value: <redacted>",
+		"slice":  `["value","<redacted>"]`,
+	}, properties)
+}
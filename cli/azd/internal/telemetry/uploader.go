@@ -7,8 +7,8 @@ import (
 	"time"
 
 	appinsightsexporter "github.com/azure/azure-dev/cli/azd/internal/telemetry/appinsights-exporter"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
 	"github.com/benbjohnson/clock"
-	"github.com/sethvargo/go-retry"
 )
 
 const (
@@ -118,14 +118,15 @@ func (u *TelemetryUploader) reliablePeekWithRemoveFallback(ctx context.Context)
 // reliablePeekOnly calls Peek() only.
 func (u *TelemetryUploader) reliablePeekOnly(ctx context.Context) (*StoredItem, error) {
 	var item *StoredItem
-	err := retry.Do(
+	err := wait.Retry(
 		ctx,
-		retry.WithMaxRetries(maxStorageFailCount, retry.NewConstant(storageQueueRetryDelay)),
+		u.clock,
+		wait.ConstantRetry(maxStorageFailCount, storageQueueRetryDelay),
 		func(ctx context.Context) error {
 			peekItem, err := u.telemetryQueue.Peek()
 
 			if err != nil {
-				return retry.RetryableError(err)
+				return wait.RetryableError(err)
 			}
 
 			item = peekItem
@@ -137,11 +138,12 @@ func (u *TelemetryUploader) reliablePeekOnly(ctx context.Context) (*StoredItem,
 }
 
 func (u *TelemetryUploader) reliableRemove(ctx context.Context, item *StoredItem) error {
-	return retry.Do(
+	return wait.Retry(
 		ctx,
-		retry.WithMaxRetries(maxStorageFailCount, retry.NewConstant(storageQueueRetryDelay)),
+		u.clock,
+		wait.ConstantRetry(maxStorageFailCount, storageQueueRetryDelay),
 		func(ctx context.Context) error {
-			return retry.RetryableError(u.telemetryQueue.Remove(item))
+			return wait.RetryableError(u.telemetryQueue.Remove(item))
 		},
 	)
 }
@@ -156,11 +158,12 @@ func (u *TelemetryUploader) enqueueRetry(
 	delayDuration time.Duration,
 	attempts int,
 ) {
-	err := retry.Do(
+	err := wait.Retry(
 		ctx,
-		retry.WithMaxRetries(maxStorageFailCount, retry.NewConstant(storageQueueRetryDelay)),
+		u.clock,
+		wait.ConstantRetry(maxStorageFailCount, storageQueueRetryDelay),
 		func(ctx context.Context) error {
-			return retry.RetryableError(u.telemetryQueue.EnqueueWithDelay(payload, delayDuration, attempts))
+			return wait.RetryableError(u.telemetryQueue.EnqueueWithDelay(payload, delayDuration, attempts))
 		},
 	)
 
@@ -17,7 +17,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -25,10 +24,15 @@ import (
 	azcorelog "github.com/Azure/azure-sdk-for-go/sdk/azcore/log"
 	"github.com/azure/azure-dev/cli/azd/cmd"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+	"github.com/azure/azure-dev/cli/azd/internal/offline"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
-	"github.com/azure/azure-dev/cli/azd/pkg/installer"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
 	"github.com/blang/semver/v4"
 	"github.com/mattn/go-colorable"
 	"github.com/spf13/pflag"
@@ -43,6 +47,8 @@ func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
 	if isDebugEnabled() {
+		// values flagged secure, ex) the values of secure Bicep parameters, are never written to the debug log
+		log.SetOutput(redact.NewWriter(log.Writer()))
 		azcorelog.SetListener(func(event azcorelog.Event, msg string) {
 			log.Printf("%s: %s\n", event, msg)
 		})
@@ -54,6 +60,11 @@ func main() {
 
 	ts := telemetry.GetTelemetrySystem()
 
+	// Platform teams wrapping azd in automation export its counters to their statsd agent or OpenTelemetry collector
+	if sink := metrics.NewSinkFromEnv(http.DefaultClient); sink != nil {
+		metrics.SetSink(sink)
+	}
+
 	latest := make(chan semver.Version)
 	go fetchLatestVersion(latest)
 
@@ -67,54 +78,15 @@ func main() {
 	//
 	// Don't write this message when JSON output is enabled, since in that case we use stderr to return structured
 	// information about command progress.
-	if !isJsonOutput() && ok {
+	//
+	// `azd upgrade` reports on the version itself, so the message isn't written in that case either.
+	if !isJsonOutput() && !isUpgradeCommand() && ok {
 		if internal.IsDevVersion() {
 			// This is a dev build (i.e. built using `go install without setting a version`) - don't print a warning in this
 			// case
 			log.Printf("eliding update message for dev build")
 		} else if latestVersion.GT(internal.VersionInfo().Version) {
-			var upgradeText string
-
-			installedBy := installer.InstalledBy()
-			if runtime.GOOS == "windows" {
-				switch installedBy {
-				case installer.InstallTypePs:
-					//nolint:lll
-					upgradeText = "run:\npowershell -ex AllSigned -c \"Invoke-RestMethod 'https://aka.ms/install-azd.ps1' | Invoke-Expression\"\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/windows"
-				case installer.InstallTypeWinget:
-					upgradeText = "run:\nwinget upgrade Microsoft.Azd"
-				case installer.InstallTypeChoco:
-					upgradeText = "run:\nchoco upgrade azd"
-				default:
-					// Also covers "msi" case where the user installed directly
-					// via MSI
-					upgradeText = "visit https://aka.ms/azd/upgrade/windows"
-				}
-			} else if runtime.GOOS == "linux" {
-				switch installedBy {
-				case installer.InstallTypeSh:
-					//nolint:lll
-					upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/linux"
-				default:
-					// Also covers "deb" and "rpm" cases which are currently
-					// documented. When package manager distribution support is
-					// added, this will need to be updated.
-					upgradeText = "visit https://aka.ms/azd/upgrade/linux"
-				}
-			} else if runtime.GOOS == "darwin" {
-				switch installedBy {
-				case installer.InstallTypeBrew:
-					upgradeText = "run:\nbrew update && brew upgrade azd"
-				case installer.InstallTypeSh:
-					//nolint:lll
-					upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/mac"
-				default:
-					upgradeText = "visit https://aka.ms/azd/upgrade/mac"
-				}
-			} else {
-				// Platform is not recognized, use the generic install link
-				upgradeText = "visit https://aka.ms/azd/upgrade"
-			}
+			upgradeText := update.UpgradeInstructions()
 
 			fmt.Fprintln(
 				os.Stderr,
@@ -129,6 +101,14 @@ func main() {
 		}
 	}
 
+	flushCtx, cancel := context.WithTimeout(ctx, metricsFlushTimeout)
+	if err := metrics.Flush(flushCtx); err != nil {
+		log.Printf("failed to flush metrics: %v\n", err)
+	}
+	cancel()
+
+	saveUsedApiVersions()
+
 	if ts != nil {
 		err := ts.Shutdown(ctx)
 		if err != nil {
@@ -144,10 +124,18 @@ func main() {
 	}
 
 	if cmdErr != nil {
+		var exitCodeErr *internal.ErrorWithExitCode
+		if errors.As(cmdErr, &exitCodeErr) {
+			os.Exit(exitCodeErr.ExitCode)
+		}
+
 		os.Exit(1)
 	}
 }
 
+// metricsFlushTimeout bounds the time azd waits on the sinks of its metrics before exiting
+const metricsFlushTimeout = 5 * time.Second
+
 // azdConfigDir is the name of the folder where `azd` writes user wide configuration data.
 const azdConfigDir = ".azd"
 
@@ -173,6 +161,23 @@ func fetchLatestVersion(version chan<- semver.Version) {
 		}
 	}
 
+	// Allow the user to disable the daily new version notice and select the release channel through config
+	channel := update.ChannelStable
+	if userConfig, err := config.NewUserConfigManager().Load(); err == nil {
+		if !update.CheckEnabled(userConfig) {
+			log.Printf("skipping update check since %s is false", update.CheckConfigKey)
+			return
+		}
+
+		if configChannel, err := update.ChannelFromConfig(userConfig); err == nil {
+			channel = configChannel
+		} else {
+			log.Printf("%v, using the %s channel for update check", err, channel)
+		}
+	} else {
+		log.Printf("could not load user config: %v, proceeding with update check", err)
+	}
+
 	// To avoid fetching the latest version of the CLI on every invocation, we cache the result for a period
 	// of time, in the user's home directory.
 	homeDir, err := os.UserHomeDir()
@@ -198,7 +203,9 @@ func fetchLatestVersion(version chan<- semver.Version) {
 			parsedVersion, parseVersionErr := semver.Parse(cache.Version)
 			parsedExpiresOn, parseExpiresOnErr := time.Parse(time.RFC3339, cache.ExpiresOn)
 
-			if parseVersionErr == nil && parseExpiresOnErr == nil {
+			if cache.Channel != "" && cache.Channel != string(channel) {
+				log.Printf("ignoring cached latest version, it is for the %s channel", cache.Channel)
+			} else if parseVersionErr == nil && parseExpiresOnErr == nil {
 				if time.Now().UTC().Before(parsedExpiresOn) {
 					log.Printf("using cached latest version: %s (expires on: %s)", cache.Version, cache.ExpiresOn)
 					cachedLatestVersion = &parsedVersion
@@ -226,14 +233,15 @@ func fetchLatestVersion(version chan<- semver.Version) {
 	// If we don't have a cached version we can use, fetch one (and cache it)
 	if cachedLatestVersion == nil {
 		log.Print("fetching latest version information for update check")
-		req, err := http.NewRequest(http.MethodGet, "https://aka.ms/azure-dev/versions/cli/latest", nil)
+		req, err := http.NewRequest(http.MethodGet, update.VersionUrl(channel), nil)
 		if err != nil {
 			log.Printf("failed to create request object: %v, skipping update check", err)
 		}
 
 		req.Header.Set("User-Agent", internal.UserAgent())
 
-		res, err := http.DefaultClient.Do(req)
+		// In offline mode, the latest version is served from the mirror
+		res, err := offline.NewTransporter(http.DefaultClient).Do(req)
 		if err != nil {
 			log.Printf("failed to fetch latest version: %v, skipping update check", err)
 			return
@@ -273,6 +281,7 @@ func fetchLatestVersion(version chan<- semver.Version) {
 		} else {
 			cacheObject := updateCacheFile{
 				Version:   fetchedVersionText,
+				Channel:   string(channel),
 				ExpiresOn: time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339),
 			}
 
@@ -294,6 +303,8 @@ func fetchLatestVersion(version chan<- semver.Version) {
 type updateCacheFile struct {
 	// The semver of the  latest version the CLI
 	Version string `json:"version"`
+	// The release channel the version was fetched for
+	Channel string `json:"channel,omitempty"`
 	// A time at which this cached value expires, stored as an RFC3339 timestamp
 	ExpiresOn string `json:"expiresOn"`
 }
@@ -342,6 +353,21 @@ func isJsonOutput() bool {
 	return output == "json"
 }
 
+// isUpgradeCommand checks to see if the command being run is `azd upgrade`
+func isUpgradeCommand() bool {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.Usage = func() {}
+
+	// Unknown flags consume the following argument as their value, declare the global boolean flags so they don't.
+	flags.Bool("debug", false, "")
+	flags.Bool("no-prompt", false, "")
+
+	_ = flags.Parse(os.Args[1:])
+
+	return flags.NArg() > 0 && flags.Arg(0) == "upgrade"
+}
+
 func readToEndAndClose(r io.ReadCloser) (string, error) {
 	defer r.Close()
 	var buf strings.Builder
@@ -360,3 +386,17 @@ func startBackgroundUploadProcess() error {
 	err = cmd.Start()
 	return err
 }
+
+// saveUsedApiVersions records the ARM api-versions azd called with in the user config directory, for
+// `azd internal api-versions` to report the resource types with newer api-versions
+func saveUsedApiVersions() {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		log.Printf("failed to get user config directory: %v\n", err)
+		return
+	}
+
+	if err := azsdk.SaveUsedApiVersions(filepath.Join(configDir, azsdk.ApiVersionsFileName)); err != nil {
+		log.Printf("failed to save used api-versions: %v\n", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 )
@@ -97,3 +98,58 @@ func (s *SubscriptionsCache) Clear() error {
 	s.inMemoryCopy = []Subscription{}
 	return nil
 }
+
+// ProfileSubscriptionsCache caches the subscriptions of the account of each auth profile in its own file, since the
+// profiles are logged in to different accounts. The profile is resolved on each call, since the profile of a command may
+// be selected after the cache is created, ex) azd auth login --profile.
+type ProfileSubscriptionsCache struct {
+	configDir string
+	profile   func() string
+
+	caches     map[string]*SubscriptionsCache
+	cachesLock sync.Mutex
+}
+
+func NewProfileSubscriptionsCache(configDir string, profile func() string) *ProfileSubscriptionsCache {
+	return &ProfileSubscriptionsCache{
+		configDir: configDir,
+		profile:   profile,
+		caches:    map[string]*SubscriptionsCache{},
+	}
+}
+
+func (p *ProfileSubscriptionsCache) Load() ([]Subscription, error) {
+	return p.cache().Load()
+}
+
+func (p *ProfileSubscriptionsCache) Save(subscriptions []Subscription) error {
+	return p.cache().Save(subscriptions)
+}
+
+func (p *ProfileSubscriptionsCache) Clear() error {
+	return p.cache().Clear()
+}
+
+// cache returns the cache of the active profile. The subscriptions of the default profile are cached in
+// subscriptions.cache, like before azd supported profiles, and those of other profiles in subscriptions.<profile>.cache.
+func (p *ProfileSubscriptionsCache) cache() *SubscriptionsCache {
+	profile := p.profile()
+
+	p.cachesLock.Lock()
+	defer p.cachesLock.Unlock()
+
+	if cache, has := p.caches[profile]; has {
+		return cache
+	}
+
+	fileName := cSubscriptionsCacheFile
+	if profile != auth.DefaultProfile {
+		fileName = fmt.Sprintf("subscriptions.%s.cache", profile)
+	}
+
+	cache := &SubscriptionsCache{
+		cachePath: filepath.Join(p.configDir, fileName),
+	}
+	p.caches[profile] = cache
+	return cache
+}
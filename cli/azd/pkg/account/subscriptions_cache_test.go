@@ -0,0 +1,37 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package account
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileSubscriptionsCache(t *testing.T) {
+	dir := t.TempDir()
+	profile := auth.DefaultProfile
+	cache := NewProfileSubscriptionsCache(dir, func() string { return profile })
+
+	require.NoError(t, cache.Save([]Subscription{{Id: "default-subscription"}}))
+	require.FileExists(t, filepath.Join(dir, cSubscriptionsCacheFile))
+
+	profile = "work"
+	_, err := cache.Load()
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, cache.Save([]Subscription{{Id: "work-subscription"}}))
+	require.FileExists(t, filepath.Join(dir, "subscriptions.work.cache"))
+
+	require.NoError(t, cache.Clear())
+	require.NoFileExists(t, filepath.Join(dir, "subscriptions.work.cache"))
+
+	profile = auth.DefaultProfile
+	subscriptions, err := cache.Load()
+	require.NoError(t, err)
+	require.Equal(t, "default-subscription", subscriptions[0].Id)
+}
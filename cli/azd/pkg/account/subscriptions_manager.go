@@ -11,10 +11,12 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/events"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"go.uber.org/multierr"
@@ -53,14 +55,14 @@ func NewSubscriptionsManager(
 	service *SubscriptionsService,
 	auth *auth.Manager,
 	msg input.Messaging) (*SubscriptionsManager, error) {
-	cache, err := NewSubscriptionsCache()
+	configDir, err := config.GetUserConfigDir()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("loading stored user subscriptions: %w", err)
 	}
 
 	return &SubscriptionsManager{
 		service:       service,
-		cache:         cache,
+		cache:         NewProfileSubscriptionsCache(configDir, auth.Profile),
 		principalInfo: auth,
 		msg:           msg,
 	}, nil
@@ -134,7 +136,11 @@ func (m *SubscriptionsManager) LookupTenant(ctx context.Context, subscriptionId
 // On cache miss, subscriptions are fetched, the cached is updated, before the result is returned.
 func (m *SubscriptionsManager) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
 	subscriptions, err := m.cache.Load()
-	if err != nil {
+	if err == nil {
+		metrics.Add(metrics.CacheHits, 1, map[string]string{"cache": "subscriptions"})
+	} else {
+		metrics.Add(metrics.CacheMisses, 1, map[string]string{"cache": "subscriptions"})
+
 		subscriptions, err = m.ListSubscriptions(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("listing subscriptions: %w", err)
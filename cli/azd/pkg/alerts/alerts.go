@@ -0,0 +1,337 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package alerts creates default metric alerts on the resources hosting the services of a project, notifying an action
+// group of email addresses and webhooks.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// actionGroupShortNameMaxLength is the maximum length of the short name of action groups
+const actionGroupShortNameMaxLength = 12
+
+// Rule is a default metric alert of a type of resource.
+type Rule struct {
+	// Suffixes the name of the resource in the name of the alert, ex) http5xx
+	Name        string
+	Description string
+	MetricName  string
+	Aggregation string
+	Operator    string
+	Threshold   float64
+	Dimensions  map[string]string
+	Severity    int
+}
+
+// Rules are the default metric alerts of the types of resources services are hosted on.
+var Rules = map[infra.AzureResourceType][]Rule{
+	infra.AzureResourceTypeWebSite: {
+		{
+			Name:        "http5xx",
+			Description: "More than 10 server errors in 5 minutes",
+			MetricName:  "Http5xx",
+			Aggregation: "Total",
+			Operator:    "GreaterThan",
+			Threshold:   10,
+			Severity:    2,
+		},
+		{
+			Name:        "availability",
+			Description: "Health check status below 100%",
+			MetricName:  "HealthCheckStatus",
+			Aggregation: "Average",
+			Operator:    "LessThan",
+			Threshold:   100,
+			Severity:    1,
+		},
+		{
+			Name:        "cpu",
+			Description: "More than 2 minutes of CPU time in 5 minutes",
+			MetricName:  "CpuTime",
+			Aggregation: "Total",
+			Operator:    "GreaterThan",
+			Threshold:   120,
+			Severity:    3,
+		},
+		{
+			Name:        "memory",
+			Description: "Average memory working set above 1.5 GB",
+			MetricName:  "AverageMemoryWorkingSet",
+			Aggregation: "Average",
+			Operator:    "GreaterThan",
+			Threshold:   1.5 * 1024 * 1024 * 1024,
+			Severity:    3,
+		},
+	},
+	infra.AzureResourceTypeContainerApp: {
+		{
+			Name:        "http5xx",
+			Description: "More than 10 server errors in 5 minutes",
+			MetricName:  "Requests",
+			Aggregation: "Total",
+			Operator:    "GreaterThan",
+			Threshold:   10,
+			Dimensions:  map[string]string{"statusCodeCategory": "5xx"},
+			Severity:    2,
+		},
+		{
+			Name:        "availability",
+			Description: "More than 3 restarts of the replicas in 5 minutes",
+			MetricName:  "RestartCount",
+			Aggregation: "Total",
+			Operator:    "GreaterThan",
+			Threshold:   3,
+			Severity:    1,
+		},
+		{
+			Name:        "cpu",
+			Description: "Average CPU usage above 80%",
+			MetricName:  "CpuPercentage",
+			Aggregation: "Average",
+			Operator:    "GreaterThan",
+			Threshold:   80,
+			Severity:    3,
+		},
+		{
+			Name:        "memory",
+			Description: "Average memory usage above 80%",
+			MetricName:  "MemoryPercentage",
+			Aggregation: "Average",
+			Operator:    "GreaterThan",
+			Threshold:   80,
+			Severity:    3,
+		},
+	},
+	infra.AzureResourceTypeManagedCluster: {
+		{
+			Name:        "availability",
+			Description: "Pods not ready",
+			MetricName:  "kube_pod_status_ready",
+			Aggregation: "Average",
+			Operator:    "LessThan",
+			Threshold:   1,
+			Dimensions:  map[string]string{"condition": "false"},
+			Severity:    1,
+		},
+		{
+			Name:        "cpu",
+			Description: "Average CPU usage of the nodes above 80%",
+			MetricName:  "node_cpu_usage_percentage",
+			Aggregation: "Average",
+			Operator:    "GreaterThan",
+			Threshold:   80,
+			Severity:    3,
+		},
+		{
+			Name:        "memory",
+			Description: "Average memory working set of the nodes above 80%",
+			MetricName:  "node_memory_working_set_percentage",
+			Aggregation: "Average",
+			Operator:    "GreaterThan",
+			Threshold:   80,
+			Severity:    3,
+		},
+	},
+}
+
+// Receivers are the email addresses and webhooks notified when alerts fire.
+type Receivers struct {
+	Emails   []string
+	Webhooks []string
+}
+
+// Alert is a metric alert created on the resource hosting a service.
+type Alert struct {
+	Name        string   `json:"name"`
+	Id          string   `json:"id"`
+	Services    []string `json:"services"`
+	ResourceId  string   `json:"resourceId"`
+	Description string   `json:"description"`
+	Metric      string   `json:"metric"`
+	Severity    int      `json:"severity"`
+}
+
+// Result lists the alerts created for the services of the project.
+type Result struct {
+	// The action group notified by the alerts, empty without receivers
+	ActionGroupId string   `json:"actionGroupId,omitempty"`
+	Alerts        []*Alert `json:"alerts"`
+	// The services whose host has no default alerts
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Manager creates the default alerts of the services of a project.
+type Manager struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager project.ResourceManager
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+) *Manager {
+	return &Manager{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+	}
+}
+
+// Init creates, or updates, the default alerts of the resources hosting the services of the project, and the action
+// group notifying the receivers. Alerts and action groups are named after the resources and the environment, so that
+// running Init again updates them instead of creating duplicates.
+func (m *Manager) Init(ctx context.Context, projectConfig *project.ProjectConfig, receivers Receivers) (*Result, error) {
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	resourceGroupName, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	result := &Result{
+		Alerts: []*Alert{},
+	}
+
+	if len(receivers.Emails) > 0 || len(receivers.Webhooks) > 0 {
+		name := fmt.Sprintf("ag-%s", m.env.GetEnvName())
+		shortName := name
+		if len(shortName) > actionGroupShortNameMaxLength {
+			shortName = shortName[:actionGroupShortNameMaxLength]
+		}
+
+		err := m.azCli.CreateOrUpdateActionGroup(ctx, subscriptionId, resourceGroupName, name, azcli.AzCliActionGroup{
+			ShortName: shortName,
+			Emails:    receivers.Emails,
+			Webhooks:  receivers.Webhooks,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result.ActionGroupId = fmt.Sprintf(
+			"%s/providers/Microsoft.Insights/actionGroups/%s",
+			azure.ResourceGroupRID(subscriptionId, resourceGroupName), name)
+	}
+
+	// services sharing a host, ex) an AKS cluster, share its alerts
+	alertsByName := map[string]*Alert{}
+	for _, serviceConfig := range projectConfig.GetServicesStable() {
+		if !serviceConfig.IsDeployed() {
+			continue
+		}
+
+		resourceId, resourceName, resourceType, err := m.hostResource(ctx, subscriptionId, serviceConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		rules, has := rulesOf(resourceType)
+		if !has {
+			log.Printf("skipping alerts of service '%s', %s resources have no default alerts", serviceConfig.Name, resourceType)
+			result.Skipped = append(result.Skipped, serviceConfig.Name)
+			continue
+		}
+
+		for _, rule := range rules {
+			name := fmt.Sprintf("%s-%s", resourceName, rule.Name)
+			if alert, has := alertsByName[name]; has {
+				alert.Services = append(alert.Services, serviceConfig.Name)
+				continue
+			}
+
+			err := m.azCli.CreateOrUpdateMetricAlert(ctx, subscriptionId, resourceGroupName, name, azcli.AzCliMetricAlert{
+				Description:     rule.Description,
+				Severity:        rule.Severity,
+				ResourceId:      resourceId,
+				MetricNamespace: resourceType,
+				MetricName:      rule.MetricName,
+				Aggregation:     rule.Aggregation,
+				Operator:        rule.Operator,
+				Threshold:       rule.Threshold,
+				Dimensions:      rule.Dimensions,
+				ActionGroupId:   result.ActionGroupId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("creating alerts of service '%s': %w", serviceConfig.Name, err)
+			}
+
+			alert := &Alert{
+				Name: name,
+				Id: fmt.Sprintf(
+					"%s/providers/Microsoft.Insights/metricAlerts/%s",
+					azure.ResourceGroupRID(subscriptionId, resourceGroupName), name),
+				Services:    []string{serviceConfig.Name},
+				ResourceId:  resourceId,
+				Description: rule.Description,
+				Metric:      rule.MetricName,
+				Severity:    rule.Severity,
+			}
+			alertsByName[name] = alert
+			result.Alerts = append(result.Alerts, alert)
+		}
+	}
+
+	return result, nil
+}
+
+// hostResource returns the id, name and type of the resource the service is hosted on. Services hosted on AKS are
+// hosted on the cluster of the environment.
+func (m *Manager) hostResource(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *project.ServiceConfig,
+) (string, string, string, error) {
+	targetResource, err := m.resourceManager.GetTargetResource(ctx, subscriptionId, serviceConfig)
+	if err != nil {
+		return "", "", "", fmt.Errorf("getting target resource of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	resourceGroupName := targetResource.ResourceGroupName()
+	resourceName := targetResource.ResourceName()
+	resourceType := targetResource.ResourceType()
+	if serviceConfig.Host == project.AksTarget {
+		resourceName = m.env.Getenv(environment.AksClusterEnvVarName)
+		resourceType = string(infra.AzureResourceTypeManagedCluster)
+		if resourceName == "" {
+			return "", "", "", fmt.Errorf(
+				"%s is not set, the cluster of service '%s' is unknown", environment.AksClusterEnvVarName, serviceConfig.Name)
+		}
+	}
+
+	resourceId := fmt.Sprintf(
+		"%s/providers/%s/%s",
+		azure.ResourceGroupRID(targetResource.SubscriptionId(), resourceGroupName),
+		resourceType,
+		resourceName,
+	)
+
+	return resourceId, resourceName, resourceType, nil
+}
+
+// rulesOf returns the default alerts of the type of resource, matched case-insensitively
+func rulesOf(resourceType string) ([]Rule, bool) {
+	for ruleType, rules := range Rules {
+		if strings.EqualFold(string(ruleType), resourceType) {
+			return rules, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const rgId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev"
+
+var tagValueRegex = regexp.MustCompile(`tagValue eq '([^']+)'`)
+
+func Test_Init(t *testing.T) {
+	// the resources tagged with the names of the services
+	resources := map[string]*armresources.GenericResourceExpanded{
+		"api": {
+			ID:   convert.RefOf(rgId + "/providers/Microsoft.App/containerApps/ca-api"),
+			Name: convert.RefOf("ca-api"),
+			Type: convert.RefOf("Microsoft.App/containerApps"),
+		},
+		"web": {
+			ID:   convert.RefOf(rgId + "/providers/Microsoft.Web/staticSites/stapp-web"),
+			Name: convert.RefOf("stapp-web"),
+			Type: convert.RefOf("Microsoft.Web/staticSites"),
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-dev/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		result := armresources.ResourceListResult{Value: []*armresources.GenericResourceExpanded{}}
+		match := tagValueRegex.FindStringSubmatch(request.URL.Query().Get("$filter"))
+		if resource, has := resources[match[1]]; has {
+			resource.Location = convert.RefOf("eastus2")
+			result.Value = append(result.Value, resource)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, result)
+	})
+
+	created := map[string]map[string]any{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/providers/Microsoft.Insights/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(contents, &body))
+		created[request.URL.Path] = body["properties"].(map[string]any)
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, body)
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+		environment.AksClusterEnvVarName:     "aks-dev",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	manager := NewManager(env, azCli, project.NewResourceManager(env, azCli))
+
+	projectConfig := &project.ProjectConfig{Name: "app", Services: map[string]*project.ServiceConfig{}}
+	for name, host := range map[string]project.ServiceTargetKind{
+		"api":    project.ContainerAppTarget,
+		"web":    project.StaticWebAppTarget,
+		"jobs":   project.AksTarget,
+		"worker": project.AksTarget,
+	} {
+		projectConfig.Services[name] = &project.ServiceConfig{
+			Name:            name,
+			Host:            host,
+			Project:         projectConfig,
+			EventDispatcher: ext.NewEventDispatcher[project.ServiceLifecycleEventArgs](),
+		}
+	}
+
+	result, err := manager.Init(*mockContext.Context, projectConfig, Receivers{
+		Emails: []string{"ops@contoso.com"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, rgId+"/providers/Microsoft.Insights/actionGroups/ag-dev", result.ActionGroupId)
+	require.Equal(t, []string{"web"}, result.Skipped)
+
+	names := []string{}
+	for _, alert := range result.Alerts {
+		names = append(names, alert.Name)
+	}
+	require.Equal(t, []string{
+		"ca-api-http5xx", "ca-api-availability", "ca-api-cpu", "ca-api-memory",
+		"aks-dev-availability", "aks-dev-cpu", "aks-dev-memory",
+	}, names)
+
+	// the services hosted on the cluster share its alerts
+	require.Equal(t, []string{"jobs", "worker"}, result.Alerts[4].Services)
+
+	actionGroup := created[rgId+"/providers/Microsoft.Insights/actionGroups/ag-dev"]
+	require.Equal(t, "ag-dev", actionGroup["groupShortName"])
+	require.Equal(t, "ops@contoso.com", actionGroup["emailReceivers"].([]any)[0].(map[string]any)["emailAddress"])
+
+	alert := created[rgId+"/providers/Microsoft.Insights/metricAlerts/ca-api-http5xx"]
+	require.Equal(t, []any{rgId + "/providers/Microsoft.App/containerApps/ca-api"}, alert["scopes"])
+	require.Equal(t, []any{map[string]any{"actionGroupId": result.ActionGroupId}}, alert["actions"])
+
+	criterion := alert["criteria"].(map[string]any)["allOf"].([]any)[0].(map[string]any)
+	require.Equal(t, "Requests", criterion["metricName"])
+	require.Equal(t, "Microsoft.App/containerApps", criterion["metricNamespace"])
+	require.Equal(t, []any{
+		map[string]any{"name": "statusCodeCategory", "operator": "Include", "values": []any{"5xx"}},
+	}, criterion["dimensions"])
+
+	// 7 alerts and the action group
+	require.Len(t, created, 8)
+}
+
+func Test_InitNoReceivers(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	manager := NewManager(env, azCli, project.NewResourceManager(env, azCli))
+
+	result, err := manager.Init(*mockContext.Context, &project.ProjectConfig{Name: "app"}, Receivers{})
+	require.NoError(t, err)
+	require.Empty(t, result.ActionGroupId)
+	require.Empty(t, result.Alerts)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package apphost
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// HostResolver returns the host name the container of a deployable resource is reached at by the other containers,
+// ex) the name of the container app of the resource
+type HostResolver func(resourceName string) (string, error)
+
+// expressionRegex matches the references to other resources in the values of the manifest, ex) {cache.connectionString}
+var expressionRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Environment returns the environment variables of the resource, with the references to the other resources replaced
+// by their values, ex) the URL of the endpoint of a project or the connection string of a container. The resources are
+// reached at the hosts returned by resolveHost.
+func (m *Manifest) Environment(resourceName string, resolveHost HostResolver) (map[string]string, error) {
+	resource, has := m.Resources[resourceName]
+	if !has {
+		return nil, fmt.Errorf("resource '%s' not found in manifest", resourceName)
+	}
+
+	env := make(map[string]string, len(resource.Env))
+	for name, value := range resource.Env {
+		evaluated, err := m.evaluate(value, resolveHost, nil)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating environment variable '%s' of resource '%s': %w", name, resourceName, err)
+		}
+
+		env[name] = evaluated
+	}
+
+	return env, nil
+}
+
+// evaluate replaces the references to resources in value. visiting are the resources whose connection strings are
+// being evaluated, to detect connection strings referencing themselves.
+func (m *Manifest) evaluate(value string, resolveHost HostResolver, visiting []string) (string, error) {
+	var evalErr error
+	result := expressionRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if evalErr != nil {
+			return match
+		}
+
+		evaluated, err := m.evaluateExpression(strings.Trim(match, "{}"), resolveHost, visiting)
+		if err != nil {
+			evalErr = err
+			return match
+		}
+
+		return evaluated
+	})
+
+	return result, evalErr
+}
+
+// evaluateExpression evaluates a reference to a resource, either <resource>.connectionString or
+// <resource>.bindings.<binding>.<url|host|port|scheme>
+func (m *Manifest) evaluateExpression(expression string, resolveHost HostResolver, visiting []string) (string, error) {
+	parts := strings.Split(expression, ".")
+	resource, has := m.Resources[parts[0]]
+	if !has {
+		return "", fmt.Errorf("'%s' references resource '%s', which isn't in the manifest", expression, parts[0])
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "connectionString":
+		if resource.ConnectionString == nil {
+			return "", fmt.Errorf("resource '%s' has no connection string", parts[0])
+		}
+
+		if slices.Contains(visiting, parts[0]) {
+			return "", fmt.Errorf("connection string of resource '%s' references itself", parts[0])
+		}
+
+		return m.evaluate(*resource.ConnectionString, resolveHost, append(visiting, parts[0]))
+	case len(parts) == 4 && parts[1] == "bindings":
+		binding, has := resource.Bindings[parts[2]]
+		if !has {
+			return "", fmt.Errorf("resource '%s' has no binding '%s'", parts[0], parts[2])
+		}
+
+		if !resource.IsDeployable() {
+			return "", fmt.Errorf("'%s' references binding of resource '%s' of type '%s', which isn't supported",
+				expression, parts[0], resource.Type)
+		}
+
+		return bindingProperty(parts[0], parts[2], binding, parts[3], resolveHost)
+	default:
+		return "", fmt.Errorf("unsupported expression '%s'", expression)
+	}
+}
+
+// bindingProperty returns the property of the binding of a resource. HTTP endpoints are reached through the ingress of
+// the host, at the default port of their scheme, and the other endpoints at the port of the container.
+func bindingProperty(
+	resourceName string,
+	bindingName string,
+	binding *Binding,
+	property string,
+	resolveHost HostResolver,
+) (string, error) {
+	host, err := resolveHost(resourceName)
+	if err != nil {
+		return "", fmt.Errorf("resolving host of resource '%s': %w", resourceName, err)
+	}
+
+	var port string
+	switch {
+	case binding.Scheme == "http":
+		port = "80"
+	case binding.Scheme == "https":
+		port = "443"
+	case binding.ContainerPort != nil:
+		port = strconv.Itoa(*binding.ContainerPort)
+	default:
+		return "", fmt.Errorf("binding '%s' of resource '%s' has no container port", bindingName, resourceName)
+	}
+
+	switch property {
+	case "url":
+		if binding.Scheme == "http" || binding.Scheme == "https" {
+			return fmt.Sprintf("%s://%s", binding.Scheme, host), nil
+		}
+		return fmt.Sprintf("%s://%s:%s", binding.Scheme, host, port), nil
+	case "host":
+		return host, nil
+	case "port":
+		return port, nil
+	case "scheme":
+		return binding.Scheme, nil
+	default:
+		return "", fmt.Errorf("unsupported property '%s' of binding '%s' of resource '%s'", property, bindingName, resourceName)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package apphost reads the application model of .NET Aspire app hosts, from the manifest published by the app host.
+package apphost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+)
+
+// The types of the resources of the manifest supported by azd
+const (
+	// A .NET project, built as a container image with the container support of the .NET SDK
+	ResourceTypeProject = "project.v0"
+	// A container built from a Dockerfile
+	ResourceTypeDockerfile = "dockerfile.v0"
+	// A container running an existing image, ex) redis:latest
+	ResourceTypeContainer = "container.v0"
+	// A value referenced by the other resources, ex) the connection string of an existing database
+	ResourceTypeValue = "value.v0"
+)
+
+// Manifest is the application model of an app host, published with dotnet run --publisher manifest
+type Manifest struct {
+	Schema    string               `json:"$schema"`
+	Resources map[string]*Resource `json:"resources"`
+}
+
+// Resource is a resource of the application model of an app host
+type Resource struct {
+	// The type of the resource, ex) project.v0
+	Type string `json:"type"`
+	// The path of the project of project.v0 resources, or the Dockerfile of dockerfile.v0 resources. Absolute once the
+	// manifest is read.
+	Path string `json:"path,omitempty"`
+	// The build context of dockerfile.v0 resources. Absolute once the manifest is read.
+	Context string `json:"context,omitempty"`
+	// The image of container.v0 resources
+	Image string `json:"image,omitempty"`
+	// The environment variables of the resource, which may reference the other resources, ex) {cache.connectionString}
+	Env map[string]string `json:"env,omitempty"`
+	// The endpoints the resource listens on, by name
+	Bindings map[string]*Binding `json:"bindings,omitempty"`
+	// The connection string other resources connect to the resource with, which may reference the resource or other
+	// resources, ex) {cache.bindings.tcp.host}:{cache.bindings.tcp.port}
+	ConnectionString *string `json:"connectionString,omitempty"`
+}
+
+// IsDeployable reports whether the resource is deployed as a container, as opposed to only being referenced by the other
+// resources
+func (r *Resource) IsDeployable() bool {
+	switch r.Type {
+	case ResourceTypeProject, ResourceTypeDockerfile, ResourceTypeContainer:
+		return true
+	default:
+		return false
+	}
+}
+
+// Binding is an endpoint a resource listens on
+type Binding struct {
+	// The scheme of the URL of the endpoint, ex) http, https or tcp
+	Scheme string `json:"scheme"`
+	// The protocol of the endpoint, ex) tcp or udp
+	Protocol string `json:"protocol"`
+	// The transport of the endpoint, ex) http or http2
+	Transport string `json:"transport"`
+	// The port the container of the resource listens on, if any
+	ContainerPort *int `json:"containerPort,omitempty"`
+	// Whether the endpoint is reachable from outside the application
+	External bool `json:"external,omitempty"`
+}
+
+// appHostPropertyRegex matches the IsAspireHost property of the project of an app host
+var appHostPropertyRegex = regexp.MustCompile(`(?i)<IsAspireHost>\s*true\s*</IsAspireHost>`)
+
+// FindAppHostProject returns the path of the project of the app host at path, which is either a project file or a
+// directory with a single project file. Returns false when path isn't an app host.
+func FindAppHostProject(path string) (string, bool) {
+	projectPath := path
+	if info, err := os.Stat(path); err != nil {
+		return "", false
+	} else if info.IsDir() {
+		projects, err := filepath.Glob(filepath.Join(path, "*.csproj"))
+		if err != nil || len(projects) != 1 {
+			return "", false
+		}
+		projectPath = projects[0]
+	}
+
+	if !strings.EqualFold(filepath.Ext(projectPath), ".csproj") {
+		return "", false
+	}
+
+	contents, err := os.ReadFile(projectPath)
+	if err != nil {
+		log.Printf("reading project '%s': %v", projectPath, err)
+		return "", false
+	}
+
+	return projectPath, appHostPropertyRegex.Match(contents)
+}
+
+// ManifestFromAppHost runs the app host to publish its manifest, and reads the manifest
+func ManifestFromAppHost(ctx context.Context, appHostProject string, dotnetCli dotnet.DotNetCli) (*Manifest, error) {
+	tempDir, err := os.MkdirTemp("", "azd-apphost")
+	if err != nil {
+		return nil, fmt.Errorf("creating directory of manifest: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	manifestPath := filepath.Join(tempDir, "manifest.json")
+	if err := dotnetCli.PublishAppHostManifest(ctx, appHostProject, manifestPath); err != nil {
+		return nil, err
+	}
+
+	return ReadManifest(manifestPath)
+}
+
+// ReadManifest reads the manifest at manifestPath. The paths of the resources, relative to the directory of the
+// manifest, are made absolute.
+func ReadManifest(manifestPath string) (*Manifest, error) {
+	contents, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest '%s': %w", manifestPath, err)
+	}
+
+	manifestDir := filepath.Dir(manifestPath)
+	for name, resource := range manifest.Resources {
+		if resource == nil {
+			return nil, fmt.Errorf("resource '%s' of manifest '%s' is empty", name, manifestPath)
+		}
+
+		if resource.Path != "" && !filepath.IsAbs(resource.Path) {
+			resource.Path = filepath.Join(manifestDir, filepath.FromSlash(resource.Path))
+		}
+
+		if resource.Context != "" && !filepath.IsAbs(resource.Context) {
+			resource.Context = filepath.Join(manifestDir, filepath.FromSlash(resource.Context))
+		}
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package apphost
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `{
+  "resources": {
+    "cache": {
+      "type": "container.v0",
+      "image": "redis:latest",
+      "bindings": {
+        "tcp": {"scheme": "tcp", "protocol": "tcp", "transport": "tcp", "containerPort": 6379}
+      },
+      "connectionString": "{cache.bindings.tcp.host}:{cache.bindings.tcp.port}"
+    },
+    "catalogdb": {
+      "type": "value.v0",
+      "connectionString": "Host=contoso.postgres.database.azure.com;Database=catalog"
+    },
+    "apiservice": {
+      "type": "project.v0",
+      "path": "../src/ApiService/ApiService.csproj",
+      "env": {
+        "ConnectionStrings__catalogdb": "{catalogdb.connectionString}"
+      },
+      "bindings": {
+        "http": {"scheme": "http", "protocol": "tcp", "transport": "http"}
+      }
+    },
+    "webfrontend": {
+      "type": "project.v0",
+      "path": "../src/Web/Web.csproj",
+      "env": {
+        "ConnectionStrings__cache": "{cache.connectionString}",
+        "services__apiservice__0": "{apiservice.bindings.http.url}"
+      },
+      "bindings": {
+        "https": {"scheme": "https", "protocol": "tcp", "transport": "http", "external": true}
+      }
+    },
+    "worker": {
+      "type": "dockerfile.v0",
+      "path": "../worker/Dockerfile",
+      "context": "../worker"
+    }
+  }
+}`
+
+func writeTestManifest(t *testing.T) string {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "apphost", "manifest.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), 0755))
+	require.NoError(t, os.WriteFile(manifestPath, []byte(testManifest), 0600))
+	return manifestPath
+}
+
+func Test_ReadManifest(t *testing.T) {
+	manifestPath := writeTestManifest(t)
+	root := filepath.Dir(filepath.Dir(manifestPath))
+
+	manifest, err := ReadManifest(manifestPath)
+	require.NoError(t, err)
+	require.Len(t, manifest.Resources, 5)
+
+	require.Equal(t, filepath.Join(root, "src", "ApiService", "ApiService.csproj"), manifest.Resources["apiservice"].Path)
+	require.Equal(t, filepath.Join(root, "worker", "Dockerfile"), manifest.Resources["worker"].Path)
+	require.Equal(t, filepath.Join(root, "worker"), manifest.Resources["worker"].Context)
+	require.Equal(t, "redis:latest", manifest.Resources["cache"].Image)
+
+	require.True(t, manifest.Resources["cache"].IsDeployable())
+	require.True(t, manifest.Resources["worker"].IsDeployable())
+	require.False(t, manifest.Resources["catalogdb"].IsDeployable())
+}
+
+func Test_Manifest_Environment(t *testing.T) {
+	manifest, err := ReadManifest(writeTestManifest(t))
+	require.NoError(t, err)
+
+	resolveHost := func(resourceName string) (string, error) {
+		return fmt.Sprintf("ca-%s", resourceName), nil
+	}
+
+	t.Run("ProjectReferences", func(t *testing.T) {
+		env, err := manifest.Environment("webfrontend", resolveHost)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"ConnectionStrings__cache": "ca-cache:6379",
+			"services__apiservice__0":  "http://ca-apiservice",
+		}, env)
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		env, err := manifest.Environment("apiservice", resolveHost)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"ConnectionStrings__catalogdb": "Host=contoso.postgres.database.azure.com;Database=catalog",
+		}, env)
+	})
+
+	t.Run("NoEnv", func(t *testing.T) {
+		env, err := manifest.Environment("worker", resolveHost)
+		require.NoError(t, err)
+		require.Empty(t, env)
+	})
+
+	t.Run("UnknownResource", func(t *testing.T) {
+		_, err := manifest.Environment("missing", resolveHost)
+		require.ErrorContains(t, err, "resource 'missing' not found")
+	})
+
+	t.Run("HostNotResolved", func(t *testing.T) {
+		_, err := manifest.Environment("webfrontend", func(resourceName string) (string, error) {
+			return "", fmt.Errorf("container app not found")
+		})
+		require.ErrorContains(t, err, "container app not found")
+	})
+}
+
+func Test_Manifest_Environment_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expectedErr string
+	}{
+		{name: "MissingResource", value: "{db.connectionString}", expectedErr: "references resource 'db'"},
+		{name: "NoConnectionString", value: "{api.connectionString}", expectedErr: "has no connection string"},
+		{name: "MissingBinding", value: "{api.bindings.grpc.url}", expectedErr: "has no binding 'grpc'"},
+		{name: "UnsupportedProperty", value: "{api.bindings.http.path}", expectedErr: "unsupported property 'path'"},
+		{name: "UnsupportedExpression", value: "{api.inputs.password}", expectedErr: "unsupported expression"},
+		{name: "Cycle", value: "{loop.connectionString}", expectedErr: "references itself"},
+	}
+
+	connectionString := "{loop.connectionString}"
+	manifest := &Manifest{
+		Resources: map[string]*Resource{
+			"api": {
+				Type:     ResourceTypeProject,
+				Bindings: map[string]*Binding{"http": {Scheme: "http"}},
+			},
+			"loop": {Type: ResourceTypeValue, ConnectionString: &connectionString},
+			"app":  {Type: ResourceTypeProject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest.Resources["app"].Env = map[string]string{"VALUE": tt.value}
+			_, err := manifest.Environment("app", func(resourceName string) (string, error) {
+				return resourceName, nil
+			})
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func Test_FindAppHostProject(t *testing.T) {
+	dir := t.TempDir()
+
+	appHostDir := filepath.Join(dir, "AppHost")
+	require.NoError(t, os.MkdirAll(appHostDir, 0755))
+	appHostProject := filepath.Join(appHostDir, "AppHost.csproj")
+	require.NoError(t, os.WriteFile(appHostProject, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <IsAspireHost>true</IsAspireHost>
+  </PropertyGroup>
+</Project>`), 0600))
+
+	webDir := filepath.Join(dir, "Web")
+	require.NoError(t, os.MkdirAll(webDir, 0755))
+	webProject := []byte(`<Project Sdk="Microsoft.NET.Sdk.Web" />`)
+	require.NoError(t, os.WriteFile(filepath.Join(webDir, "Web.csproj"), webProject, 0600))
+
+	projectPath, isAppHost := FindAppHostProject(appHostDir)
+	require.True(t, isAppHost)
+	require.Equal(t, appHostProject, projectPath)
+
+	projectPath, isAppHost = FindAppHostProject(appHostProject)
+	require.True(t, isAppHost)
+	require.Equal(t, appHostProject, projectPath)
+
+	_, isAppHost = FindAppHostProject(webDir)
+	require.False(t, isAppHost)
+
+	_, isAppHost = FindAppHostProject(filepath.Join(dir, "missing"))
+	require.False(t, isAppHost)
+}
@@ -0,0 +1,264 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package appimport imports apps already deployed to Azure into azd projects, with azd init --from-app. The app is
+// inspected, and an azure.yaml and the infrastructure reproducing the app are scaffolded from what was found: its
+// settings, its image and the size of its hosting resources.
+package appimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/resources"
+)
+
+// Kind is a kind of app which can be imported
+type Kind string
+
+const (
+	// KindAppService is a web app or a function app of Azure App Service
+	KindAppService Kind = "appservice"
+	// KindContainerApp is an Azure Container App
+	KindContainerApp Kind = "containerapp"
+	// KindAks is the deployments of a namespace of an Azure Kubernetes Service cluster
+	KindAks Kind = "aks"
+)
+
+// App is an app deployed to Azure, inspected to be imported into an azd project
+type App struct {
+	Kind           Kind
+	SubscriptionId string
+	// The name of the app, ex) the name of the web app or the name of the cluster
+	Name     string
+	Location string
+	// The parameters of the infrastructure reproducing the hosting resources of the app, ex) the SKU of the app service
+	// plan or the VM size of the nodes of the cluster
+	Parameters map[string]any
+	// The settings of the app, ex) the app settings of a web app or the environment variables of a container app
+	Settings map[string]string
+	// The settings of the app holding secrets. Their values are kept in the environment, not in the infrastructure.
+	SecretSettings map[string]string
+	// The namespace of the deployments of AKS apps
+	Namespace string
+	// The services of the app, one per deployment for AKS
+	Services []*Service
+	// What couldn't be imported, ex) the Key Vault references of the secrets of a container app
+	Notes []string
+}
+
+// Service is a service of an imported app
+type Service struct {
+	Name     string
+	Host     project.ServiceTargetKind
+	Language project.ServiceLanguageKind
+	// The image the service runs, empty for services deployed from code
+	Image string
+	// The k8s manifests of the service, by file name
+	Manifests map[string][]byte
+}
+
+// ScaffoldResult is the result of scaffolding the project of an imported app
+type ScaffoldResult struct {
+	// The values of the environment the infrastructure references, ex) the values of the secret settings
+	EnvValues map[string]string
+}
+
+// The directory of the project the services of the imported apps are in, ex) src/web
+const servicesDirectory = "src"
+
+// The language of the services running images, which can't be inferred from their images. The services are built from
+// their code and their Dockerfile; their language is set in azure.yaml once their code is added.
+const imageServiceLanguage = project.ServiceLanguageDotNet
+
+// secretSettingRegex matches the names of the settings which are likely to hold secrets
+var secretSettingRegex = regexp.MustCompile(`(?i)(key|secret|password|pwd|token|connectionstring|connection_string|sas)`)
+
+// invalidEnvNameCharsRegex matches the characters of the names of settings which aren't valid in environment variables
+var invalidEnvNameCharsRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// IsSecretSetting reports whether the name of the setting suggests it holds a secret, ex) DB_PASSWORD or STORAGE_KEY
+func IsSecretSetting(name string) bool {
+	return secretSettingRegex.MatchString(name)
+}
+
+// Scaffold writes the azure.yaml and the infrastructure of the imported app to the project directory, and creates the
+// directories of its services. The existing files of the project directory are kept; their names must not collide with
+// the scaffolded files.
+func (a *App) Scaffold(ctx context.Context, projectDir string, projectName string) (*ScaffoldResult, error) {
+	infraPath := filepath.Join(projectDir, "infra")
+	for _, file := range []string{filepath.Join(projectDir, "azure.yaml"), filepath.Join(infraPath, "main.bicep")} {
+		if _, err := os.Stat(file); err == nil {
+			return nil, fmt.Errorf("%s already exists", file)
+		}
+	}
+
+	if err := writeTemplates(string(a.Kind), infraPath); err != nil {
+		return nil, err
+	}
+
+	parameters, envValues := a.infraParameters()
+	contents, err := json.MarshalIndent(map[string]any{
+		"$schema":        "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters":     parameters,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	parametersPath := filepath.Join(infraPath, "main.parameters.json")
+	if err := os.WriteFile(parametersPath, contents, osutil.PermissionFile); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", parametersPath, err)
+	}
+
+	projectConfig := &project.ProjectConfig{
+		Name:     projectName,
+		Services: map[string]*project.ServiceConfig{},
+	}
+
+	for _, service := range a.Services {
+		servicePath := filepath.Join(projectDir, servicesDirectory, service.Name)
+		if err := os.MkdirAll(servicePath, osutil.PermissionDirectory); err != nil {
+			return nil, fmt.Errorf("creating directory %s: %w", servicePath, err)
+		}
+
+		serviceConfig := &project.ServiceConfig{
+			Name:         service.Name,
+			RelativePath: path.Join(servicesDirectory, service.Name),
+			Host:         service.Host,
+			Language:     service.Language,
+		}
+
+		if len(service.Manifests) > 0 {
+			serviceConfig.K8s.DeploymentPath = "manifests"
+			serviceConfig.K8s.Namespace = a.Namespace
+			if err := writeManifests(filepath.Join(servicePath, "manifests"), service.Manifests); err != nil {
+				return nil, err
+			}
+		}
+
+		projectConfig.Services[service.Name] = serviceConfig
+	}
+
+	if err := project.Save(ctx, projectConfig, filepath.Join(projectDir, "azure.yaml")); err != nil {
+		return nil, err
+	}
+
+	return &ScaffoldResult{EnvValues: envValues}, nil
+}
+
+// infraParameters returns the parameters of main.parameters.json reproducing the app, and the values of the environment
+// they reference
+func (a *App) infraParameters() (map[string]any, map[string]string) {
+	envValues := map[string]string{}
+	parameters := map[string]any{
+		"environmentName": map[string]any{"value": "${AZURE_ENV_NAME}"},
+		"location":        map[string]any{"value": "${AZURE_LOCATION}"},
+	}
+
+	for name, value := range a.Parameters {
+		parameters[name] = map[string]any{"value": value}
+	}
+
+	// The deployments of AKS apps are in their manifests
+	if a.Kind == KindAks {
+		return parameters, envValues
+	}
+
+	service := a.Services[0]
+	parameters["serviceName"] = map[string]any{"value": service.Name}
+
+	settingsParameter, secretSettingsParameter := "appSettings", "secretSettings"
+	if a.Kind == KindContainerApp {
+		settingsParameter, secretSettingsParameter = "env", "secretEnv"
+
+		// Images of registries the identity of the container app can't pull from are replaced on azd deploy, by the
+		// image pushed to the registry of the environment
+		if service.Image != "" && !isAzureContainerRegistryImage(service.Image) {
+			parameters["image"] = map[string]any{"value": service.Image}
+		}
+	}
+
+	// The parameters file is substituted with the values of the environment, $ are escaped as $$
+	settings := map[string]string{}
+	for name, value := range a.Settings {
+		settings[name] = strings.ReplaceAll(value, "$", "$$")
+	}
+	parameters[settingsParameter] = map[string]any{"value": settings}
+
+	// The secrets are referenced from the environment, ex) ${DB_PASSWORD}
+	secretSettings := map[string]string{}
+	for name, value := range a.SecretSettings {
+		envName := invalidEnvNameCharsRegex.ReplaceAllString(name, "_")
+		secretSettings[name] = fmt.Sprintf("${%s}", envName)
+		envValues[envName] = value
+	}
+	parameters[secretSettingsParameter] = map[string]any{"value": secretSettings}
+
+	return parameters, envValues
+}
+
+// writeTemplates copies the infrastructure templates of the kind of app to the infra directory
+func writeTemplates(kind string, infraPath string) error {
+	root := path.Join("import", kind)
+	return fs.WalkDir(resources.ImportTemplates, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(infraPath, filepath.FromSlash(strings.TrimPrefix(name, root)))
+		if d.IsDir() {
+			return os.MkdirAll(target, osutil.PermissionDirectory)
+		}
+
+		contents, err := resources.ImportTemplates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(target, contents, osutil.PermissionFile); err != nil {
+			return fmt.Errorf("writing %s: %w", target, err)
+		}
+
+		return nil
+	})
+}
+
+func writeManifests(manifestsPath string, manifests map[string][]byte) error {
+	if err := os.MkdirAll(manifestsPath, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating directory %s: %w", manifestsPath, err)
+	}
+
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		manifestPath := filepath.Join(manifestsPath, name)
+		if err := os.WriteFile(manifestPath, manifests[name], osutil.PermissionFile); err != nil {
+			return fmt.Errorf("writing %s: %w", manifestPath, err)
+		}
+	}
+
+	return nil
+}
+
+// isAzureContainerRegistryImage reports whether the image is in an Azure Container Registry, ex)
+// contoso.azurecr.io/web:1.0, which the identities of the scaffolded infrastructure can't pull from
+func isAzureContainerRegistryImage(image string) bool {
+	registry, _, has := strings.Cut(image, "/")
+	return has && strings.Contains(strings.ToLower(registry), ".azurecr.")
+}
@@ -0,0 +1,118 @@
+package appimport
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsSecretSetting(t *testing.T) {
+	require.True(t, IsSecretSetting("DB_PASSWORD"))
+	require.True(t, IsSecretSetting("StorageConnectionString"))
+	require.True(t, IsSecretSetting("api_key"))
+	require.False(t, IsSecretSetting("LOG_LEVEL"))
+}
+
+func Test_App_Scaffold(t *testing.T) {
+	t.Run("ContainerApp", func(t *testing.T) {
+		projectDir := t.TempDir()
+		app := &App{
+			Kind:           KindContainerApp,
+			Name:           "api",
+			Parameters:     map[string]any{"cpu": "0.5"},
+			Settings:       map[string]string{"GREETING": "costs $5"},
+			SecretSettings: map[string]string{"DB-PASSWORD": "P@ssw0rd"},
+			Services: []*Service{{
+				Name:     "api",
+				Host:     project.ContainerAppTarget,
+				Language: imageServiceLanguage,
+				Image:    "docker.io/contoso/api:1.0",
+			}},
+		}
+
+		result, err := app.Scaffold(context.Background(), projectDir, "my-project")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"DB_PASSWORD": "P@ssw0rd"}, result.EnvValues)
+
+		require.FileExists(t, filepath.Join(projectDir, "infra", "main.bicep"))
+		require.FileExists(t, filepath.Join(projectDir, "infra", "app.bicep"))
+		require.DirExists(t, filepath.Join(projectDir, "src", "api"))
+
+		parameters := readParameters(t, projectDir)
+		require.Equal(t, "${AZURE_ENV_NAME}", parameters["environmentName"])
+		require.Equal(t, "0.5", parameters["cpu"])
+		require.Equal(t, "api", parameters["serviceName"])
+		require.Equal(t, "docker.io/contoso/api:1.0", parameters["image"])
+		require.Equal(t, map[string]any{"GREETING": "costs $$5"}, parameters["env"])
+		require.Equal(t, map[string]any{"DB-PASSWORD": "${DB_PASSWORD}"}, parameters["secretEnv"])
+
+		projectConfig, err := project.Load(context.Background(), filepath.Join(projectDir, "azure.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, "my-project", projectConfig.Name)
+		require.Equal(t, project.ContainerAppTarget, projectConfig.Services["api"].Host)
+		require.Equal(t, "src/api", projectConfig.Services["api"].RelativePath)
+	})
+
+	t.Run("Aks", func(t *testing.T) {
+		projectDir := t.TempDir()
+		app := &App{
+			Kind:       KindAks,
+			Name:       "cluster",
+			Namespace:  "todo",
+			Parameters: map[string]any{"nodeCount": 2},
+			Services: []*Service{{
+				Name:      "todo-api",
+				Host:      project.AksTarget,
+				Language:  imageServiceLanguage,
+				Manifests: map[string][]byte{"deployment.yaml": []byte("kind: Deployment\n")},
+			}},
+		}
+
+		_, err := app.Scaffold(context.Background(), projectDir, "my-project")
+		require.NoError(t, err)
+
+		parameters := readParameters(t, projectDir)
+		require.Equal(t, float64(2), parameters["nodeCount"])
+		require.NotContains(t, parameters, "serviceName")
+		require.FileExists(t, filepath.Join(projectDir, "src", "todo-api", "manifests", "deployment.yaml"))
+
+		projectConfig, err := project.Load(context.Background(), filepath.Join(projectDir, "azure.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, "manifests", projectConfig.Services["todo-api"].K8s.DeploymentPath)
+		require.Equal(t, "todo", projectConfig.Services["todo-api"].K8s.Namespace)
+	})
+
+	t.Run("ExistingProject", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "azure.yaml"), []byte("name: existing\n"), 0600))
+
+		app := &App{Kind: KindAks, Name: "cluster"}
+		_, err := app.Scaffold(context.Background(), projectDir, "my-project")
+		require.ErrorContains(t, err, "already exists")
+	})
+}
+
+// readParameters returns the values of the parameters of main.parameters.json by name
+func readParameters(t *testing.T, projectDir string) map[string]any {
+	contents, err := os.ReadFile(filepath.Join(projectDir, "infra", "main.parameters.json"))
+	require.NoError(t, err)
+
+	var file struct {
+		Parameters map[string]struct {
+			Value any `json:"value"`
+		} `json:"parameters"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &file))
+
+	values := map[string]any{}
+	for name, parameter := range file.Parameters {
+		values[name] = parameter.Value
+	}
+
+	return values
+}
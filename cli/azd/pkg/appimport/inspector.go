@@ -0,0 +1,481 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package appimport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+)
+
+// The app settings set by App Service or by deployments, which aren't part of the configuration of the app
+var platformAppSettings = []string{
+	"WEBSITE_RUN_FROM_PACKAGE",
+	"WEBSITE_ENABLE_SYNC_UPDATE_SITE",
+	"WEBSITES_ENABLE_APP_SERVICE_STORAGE",
+	"AzureWebJobsStorage",
+}
+
+// The languages of the runtime stacks of App Service, ex) NODE of NODE|18-lts
+var appServiceRuntimeLanguages = map[string]project.ServiceLanguageKind{
+	"NODE":       project.ServiceLanguageJavaScript,
+	"PYTHON":     project.ServiceLanguagePython,
+	"DOTNETCORE": project.ServiceLanguageDotNet,
+	"DOTNET":     project.ServiceLanguageDotNet,
+	"JAVA":       project.ServiceLanguageJava,
+	"TOMCAT":     project.ServiceLanguageJava,
+	"JBOSSEAP":   project.ServiceLanguageJava,
+}
+
+// invalidServiceNameCharsRegex matches the characters of the names of apps which aren't valid in the names of services
+var invalidServiceNameCharsRegex = regexp.MustCompile(`[^a-z0-9-]`)
+
+// Inspector inspects apps deployed to Azure, for them to be imported into azd projects
+type Inspector struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	kubectl            kubectl.KubectlCli
+	userAgent          string
+}
+
+// NewInspector creates a new Inspector
+func NewInspector(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+	kubectl kubectl.KubectlCli,
+) *Inspector {
+	return &Inspector{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		kubectl:            kubectl,
+		userAgent:          azdinternal.UserAgent(),
+	}
+}
+
+// Inspect inspects the app with the resource id: a web app or a function app, ex)
+// /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.Web/sites/<name>, a container app, or the deployments of
+// a namespace of an AKS cluster, with the namespace appended to the id of the cluster, ex)
+// /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.ContainerService/managedClusters/<name>/namespaces/<ns>.
+// The deployments of the default namespace are imported when the namespace is omitted.
+func (i *Inspector) Inspect(ctx context.Context, resourceId string) (*App, error) {
+	namespace := ""
+	if clusterId, ns, has := strings.Cut(resourceId, "/namespaces/"); has {
+		resourceId, namespace = clusterId, ns
+	}
+
+	id, err := arm.ParseResourceID(resourceId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource id '%s': %w", resourceId, err)
+	}
+
+	var app *App
+	switch {
+	case strings.EqualFold(id.ResourceType.String(), "Microsoft.Web/sites"):
+		return i.inspectAppService(ctx, id)
+	case strings.EqualFold(id.ResourceType.String(), "Microsoft.App/containerApps"):
+		app, err = i.inspectContainerApp(ctx, id)
+	case strings.EqualFold(id.ResourceType.String(), "Microsoft.ContainerService/managedClusters"):
+		if namespace == "" {
+			namespace = "default"
+		}
+		app, err = i.inspectAks(ctx, id, namespace)
+	default:
+		return nil, fmt.Errorf(
+			"resource type '%s' can't be imported, supported resource types are Microsoft.Web/sites, "+
+				"Microsoft.App/containerApps and Microsoft.ContainerService/managedClusters", id.ResourceType.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The language of the code of the images can't be inferred
+	for _, service := range app.Services {
+		app.Notes = append(app.Notes, fmt.Sprintf(
+			"Service '%s' runs the image %s, add its code and its Dockerfile to %s and set its language in azure.yaml.",
+			service.Name, service.Image, path.Join(servicesDirectory, service.Name)))
+	}
+
+	return app, nil
+}
+
+func (i *Inspector) inspectAppService(ctx context.Context, id *arm.ResourceID) (*App, error) {
+	credential, err := i.credentialProvider.CredentialForSubscription(ctx, id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, i.httpClient, i.userAgent).BuildArmClientOptions()
+	client, err := armappservice.NewWebAppsClient(id.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating web apps client: %w", err)
+	}
+
+	site, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting app service '%s': %w", id.Name, err)
+	}
+
+	config, err := client.GetConfiguration(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting configuration of app service '%s': %w", id.Name, err)
+	}
+
+	appSettings, err := client.ListApplicationSettings(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing app settings of app service '%s': %w", id.Name, err)
+	}
+
+	kind := convert.ToValueWithDefault(site.Kind, "app,linux")
+	// The scaffolded infrastructure runs the apps on Linux, ex) app,linux for app
+	windows := !strings.Contains(kind, "linux")
+	if windows {
+		kind, _, _ = strings.Cut(kind, ",")
+		kind += ",linux"
+	}
+
+	linuxFxVersion := ""
+	appCommandLine := ""
+	if config.Properties != nil {
+		linuxFxVersion = convert.ToValueWithDefault(config.Properties.LinuxFxVersion, "")
+		appCommandLine = convert.ToValueWithDefault(config.Properties.AppCommandLine, "")
+	}
+
+	app := &App{
+		Kind:           KindAppService,
+		SubscriptionId: id.SubscriptionID,
+		Name:           id.Name,
+		Location:       convert.ToValueWithDefault(site.Location, ""),
+		Parameters: map[string]any{
+			"kind":           kind,
+			"linuxFxVersion": linuxFxVersion,
+			"appCommandLine": appCommandLine,
+		},
+		Settings:       map[string]string{},
+		SecretSettings: map[string]string{},
+	}
+
+	if windows {
+		app.Notes = append(app.Notes,
+			"The app runs on Windows, the scaffolded infrastructure runs it on Linux.")
+	}
+
+	if site.Properties != nil && site.Properties.ServerFarmID != nil {
+		sku, err := i.appServicePlanSku(ctx, *site.Properties.ServerFarmID)
+		if err != nil {
+			return nil, err
+		}
+		app.Parameters["sku"] = sku
+	}
+
+	for name, value := range appSettings.Properties {
+		if isPlatformAppSetting(name) {
+			continue
+		}
+
+		if IsSecretSetting(name) {
+			app.SecretSettings[name] = convert.ToValueWithDefault(value, "")
+		} else {
+			app.Settings[name] = convert.ToValueWithDefault(value, "")
+		}
+	}
+
+	service := &Service{
+		Name: serviceName(id.Name),
+		Host: project.AppServiceTarget,
+	}
+
+	if strings.Contains(kind, "functionapp") {
+		service.Host = project.AzureFunctionTarget
+	}
+
+	runtime, version, _ := strings.Cut(linuxFxVersion, "|")
+	switch {
+	case strings.EqualFold(runtime, "DOCKER"):
+		service.Language = imageServiceLanguage
+		service.Image = version
+		app.Notes = append(app.Notes, fmt.Sprintf(
+			"The app runs the image %s, azd deploys the code of service '%s' to the app instead. Set the language "+
+				"of the service in azure.yaml.", version, service.Name))
+	case appServiceRuntimeLanguages[strings.ToUpper(runtime)] != "":
+		service.Language = appServiceRuntimeLanguages[strings.ToUpper(runtime)]
+	default:
+		// Windows apps, ex) .NET Framework apps, have no linuxFxVersion
+		service.Language = project.ServiceLanguageDotNet
+		app.Notes = append(app.Notes, fmt.Sprintf(
+			"The runtime stack '%s' of the app is unknown, set the language of service '%s' in azure.yaml.",
+			linuxFxVersion, service.Name))
+	}
+
+	app.Services = []*Service{service}
+	return app, nil
+}
+
+// appServicePlanSku returns the name of the SKU of the app service plan, ex) P1v3
+func (i *Inspector) appServicePlanSku(ctx context.Context, planId string) (string, error) {
+	id, err := arm.ParseResourceID(planId)
+	if err != nil {
+		return "", fmt.Errorf("invalid app service plan id '%s': %w", planId, err)
+	}
+
+	credential, err := i.credentialProvider.CredentialForSubscription(ctx, id.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, i.httpClient, i.userAgent).BuildArmClientOptions()
+	client, err := armappservice.NewPlansClient(id.SubscriptionID, credential, options)
+	if err != nil {
+		return "", fmt.Errorf("creating app service plans client: %w", err)
+	}
+
+	plan, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting app service plan '%s': %w", id.Name, err)
+	}
+
+	if plan.SKU == nil || plan.SKU.Name == nil {
+		return "B1", nil
+	}
+
+	return *plan.SKU.Name, nil
+}
+
+func (i *Inspector) inspectContainerApp(ctx context.Context, id *arm.ResourceID) (*App, error) {
+	credential, err := i.credentialProvider.CredentialForSubscription(ctx, id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, i.httpClient, i.userAgent).BuildArmClientOptions()
+	client, err := armappcontainers.NewContainerAppsClient(id.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating container apps client: %w", err)
+	}
+
+	containerApp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting container app '%s': %w", id.Name, err)
+	}
+
+	properties := containerApp.Properties
+	if properties == nil || properties.Template == nil || len(properties.Template.Containers) == 0 {
+		return nil, fmt.Errorf("container app '%s' has no containers", id.Name)
+	}
+
+	secrets, err := client.ListSecrets(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing secrets of container app '%s': %w", id.Name, err)
+	}
+
+	secretValues := map[string]*armappcontainers.ContainerAppSecret{}
+	for _, secret := range secrets.Value {
+		if secret.Name != nil {
+			secretValues[*secret.Name] = secret
+		}
+	}
+
+	app := &App{
+		Kind:           KindContainerApp,
+		SubscriptionId: id.SubscriptionID,
+		Name:           id.Name,
+		Location:       convert.ToValueWithDefault(containerApp.Location, ""),
+		Parameters:     map[string]any{},
+		Settings:       map[string]string{},
+		SecretSettings: map[string]string{},
+	}
+
+	container := properties.Template.Containers[0]
+	if len(properties.Template.Containers) > 1 {
+		app.Notes = append(app.Notes, fmt.Sprintf(
+			"Only the container '%s' of the container app is imported.", convert.ToValueWithDefault(container.Name, "")))
+	}
+
+	for _, envVar := range container.Env {
+		name := convert.ToValueWithDefault(envVar.Name, "")
+		if envVar.SecretRef == nil {
+			app.Settings[name] = convert.ToValueWithDefault(envVar.Value, "")
+			continue
+		}
+
+		secret, has := secretValues[*envVar.SecretRef]
+		switch {
+		case !has:
+			app.Notes = append(app.Notes, fmt.Sprintf("The secret '%s' of %s was not found.", *envVar.SecretRef, name))
+		case secret.KeyVaultURL != nil:
+			app.Notes = append(app.Notes, fmt.Sprintf(
+				"The secret %s references Key Vault secret %s, set its value with azd env set %s.",
+				name, *secret.KeyVaultURL, invalidEnvNameCharsRegex.ReplaceAllString(name, "_")))
+			app.SecretSettings[name] = ""
+		default:
+			app.SecretSettings[name] = convert.ToValueWithDefault(secret.Value, "")
+		}
+	}
+
+	if container.Resources != nil {
+		if container.Resources.CPU != nil {
+			app.Parameters["cpu"] = fmt.Sprintf("%g", *container.Resources.CPU)
+		}
+		if container.Resources.Memory != nil {
+			app.Parameters["memory"] = *container.Resources.Memory
+		}
+	}
+
+	if scale := properties.Template.Scale; scale != nil {
+		if scale.MinReplicas != nil {
+			app.Parameters["minReplicas"] = *scale.MinReplicas
+		}
+		if scale.MaxReplicas != nil {
+			app.Parameters["maxReplicas"] = *scale.MaxReplicas
+		}
+	}
+
+	if properties.Configuration != nil && properties.Configuration.Ingress != nil {
+		ingress := properties.Configuration.Ingress
+		app.Parameters["targetPort"] = convert.ToValueWithDefault(ingress.TargetPort, 0)
+		app.Parameters["external"] = convert.ToValueWithDefault(ingress.External, false)
+	}
+
+	app.Services = []*Service{
+		{
+			Name:     serviceName(id.Name),
+			Host:     project.ContainerAppTarget,
+			Language: imageServiceLanguage,
+			Image:    convert.ToValueWithDefault(container.Image, ""),
+		},
+	}
+
+	return app, nil
+}
+
+func (i *Inspector) inspectAks(ctx context.Context, id *arm.ResourceID, namespace string) (*App, error) {
+	credential, err := i.credentialProvider.CredentialForSubscription(ctx, id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, i.httpClient, i.userAgent).BuildArmClientOptions()
+	client, err := armcontainerservice.NewManagedClustersClient(id.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating managed clusters client: %w", err)
+	}
+
+	cluster, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting AKS cluster '%s': %w", id.Name, err)
+	}
+
+	app := &App{
+		Kind:           KindAks,
+		SubscriptionId: id.SubscriptionID,
+		Name:           id.Name,
+		Location:       convert.ToValueWithDefault(cluster.Location, ""),
+		Parameters:     map[string]any{},
+		Namespace:      namespace,
+	}
+
+	if cluster.Properties != nil {
+		if cluster.Properties.KubernetesVersion != nil {
+			app.Parameters["kubernetesVersion"] = *cluster.Properties.KubernetesVersion
+		}
+
+		for _, pool := range cluster.Properties.AgentPoolProfiles {
+			if pool.Mode == nil || *pool.Mode != armcontainerservice.AgentPoolModeSystem {
+				continue
+			}
+
+			if pool.VMSize != nil {
+				app.Parameters["nodeVmSize"] = *pool.VMSize
+			}
+			if pool.Count != nil {
+				app.Parameters["nodeCount"] = *pool.Count
+			}
+			break
+		}
+	}
+
+	credentials, err := client.ListClusterUserCredentials(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting credentials of AKS cluster '%s': %w", id.Name, err)
+	}
+
+	if len(credentials.Kubeconfigs) == 0 {
+		return nil, fmt.Errorf("AKS cluster '%s' has no credentials", id.Name)
+	}
+
+	resources, err := i.namespaceResources(ctx, credentials.Kubeconfigs[0].Value, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := aksServices(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("namespace '%s' of AKS cluster '%s' has no deployments", namespace, id.Name)
+	}
+
+	app.Services = services
+	return app, nil
+}
+
+// namespaceResources gets the deployments and services of the namespace, with the kube config of the cluster
+func (i *Inspector) namespaceResources(ctx context.Context, kubeConfig []byte, namespace string) ([]byte, error) {
+	directory, err := os.MkdirTemp("", "azd-import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(directory)
+
+	kubeConfigPath := filepath.Join(directory, "config")
+	if err := os.WriteFile(kubeConfigPath, kubeConfig, osutil.PermissionFileOwnerOnly); err != nil {
+		return nil, err
+	}
+
+	res, err := i.kubectl.Exec(
+		ctx,
+		&kubectl.KubeCliFlags{Namespace: namespace, Output: kubectl.OutputTypeJson},
+		"get", "deployments,services", "--kubeconfig", kubeConfigPath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting deployments of namespace '%s': %w", namespace, err)
+	}
+
+	return []byte(res.Stdout), nil
+}
+
+// serviceName returns the name of the service of azure.yaml of an app, ex) web for Web_App
+func serviceName(appName string) string {
+	name := invalidServiceNameCharsRegex.ReplaceAllString(strings.ToLower(appName), "-")
+	return strings.Trim(name, "-")
+}
+
+func isPlatformAppSetting(name string) bool {
+	for _, setting := range platformAppSettings {
+		if strings.EqualFold(setting, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package appimport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sitesId         = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/sites/My_Web"
+	containerAppsId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.App/containerApps/api"
+)
+
+func newInspectorFromMockContext(mockContext *mocks.MockContext) *Inspector {
+	return NewInspector(
+		mockaccount.SubscriptionCredentialProviderFunc(func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		}),
+		mockContext.HttpClient,
+		nil,
+	)
+}
+
+func mockArmResponse(mockContext *mocks.MockContext, method string, path string, body any) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == method && strings.EqualFold(request.URL.Path, path)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, body)
+	})
+}
+
+func Test_Inspector_AppService(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	planId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/serverfarms/plan"
+	mockArmResponse(mockContext, http.MethodGet, sitesId, map[string]any{
+		"location":   "westus2",
+		"kind":       "app",
+		"properties": map[string]any{"serverFarmId": planId},
+	})
+	mockArmResponse(mockContext, http.MethodGet, sitesId+"/config/web", map[string]any{
+		"properties": map[string]any{"linuxFxVersion": "NODE|18-lts", "appCommandLine": "npm start"},
+	})
+	mockArmResponse(mockContext, http.MethodPost, sitesId+"/config/appsettings/list", map[string]any{
+		"properties": map[string]any{
+			"API_URL":                  "https://api.contoso.com",
+			"DB_PASSWORD":              "P@ssw0rd",
+			"WEBSITE_RUN_FROM_PACKAGE": "1",
+		},
+	})
+	mockArmResponse(mockContext, http.MethodGet, planId, map[string]any{
+		"sku": map[string]any{"name": "P1v3"},
+	})
+
+	app, err := newInspectorFromMockContext(mockContext).Inspect(*mockContext.Context, sitesId)
+	require.NoError(t, err)
+
+	require.Equal(t, KindAppService, app.Kind)
+	require.Equal(t, "SUBSCRIPTION_ID", app.SubscriptionId)
+	require.Equal(t, "westus2", app.Location)
+	require.Equal(t, "app,linux", app.Parameters["kind"])
+	require.Equal(t, "P1v3", app.Parameters["sku"])
+	require.Equal(t, "NODE|18-lts", app.Parameters["linuxFxVersion"])
+	require.Equal(t, map[string]string{"API_URL": "https://api.contoso.com"}, app.Settings)
+	require.Equal(t, map[string]string{"DB_PASSWORD": "P@ssw0rd"}, app.SecretSettings)
+	// Apps without linux in their kind run on Windows
+	require.Len(t, app.Notes, 1)
+
+	require.Len(t, app.Services, 1)
+	require.Equal(t, "my-web", app.Services[0].Name)
+	require.Equal(t, project.AppServiceTarget, app.Services[0].Host)
+	require.Equal(t, project.ServiceLanguageJavaScript, app.Services[0].Language)
+}
+
+func Test_Inspector_ContainerApp(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockArmResponse(mockContext, http.MethodGet, containerAppsId, map[string]any{
+		"location": "eastus",
+		"properties": map[string]any{
+			"configuration": map[string]any{
+				"ingress": map[string]any{"targetPort": 8080, "external": true},
+			},
+			"template": map[string]any{
+				"containers": []map[string]any{{
+					"name":  "api",
+					"image": "docker.io/contoso/api:1.0",
+					"env": []map[string]any{
+						{"name": "LOG_LEVEL", "value": "debug"},
+						{"name": "DB_PASSWORD", "secretRef": "db-password"},
+						{"name": "API_KEY", "secretRef": "api-key"},
+					},
+					"resources": map[string]any{"cpu": 0.5, "memory": "1Gi"},
+				}},
+				"scale": map[string]any{"minReplicas": 1, "maxReplicas": 3},
+			},
+		},
+	})
+	mockArmResponse(mockContext, http.MethodPost, containerAppsId+"/listSecrets", map[string]any{
+		"value": []map[string]any{
+			{"name": "db-password", "value": "P@ssw0rd"},
+			{"name": "api-key", "keyVaultUrl": "https://contoso.vault.azure.net/secrets/api-key"},
+		},
+	})
+
+	app, err := newInspectorFromMockContext(mockContext).Inspect(*mockContext.Context, containerAppsId)
+	require.NoError(t, err)
+
+	require.Equal(t, KindContainerApp, app.Kind)
+	require.Equal(t, "eastus", app.Location)
+	require.Equal(t, "0.5", app.Parameters["cpu"])
+	require.Equal(t, "1Gi", app.Parameters["memory"])
+	require.Equal(t, map[string]string{"LOG_LEVEL": "debug"}, app.Settings)
+	require.Equal(t, map[string]string{"DB_PASSWORD": "P@ssw0rd", "API_KEY": ""}, app.SecretSettings)
+	// The Key Vault reference isn't imported, and the language of the image is unknown
+	require.Len(t, app.Notes, 2)
+	require.Contains(t, app.Notes[0], "azd env set API_KEY")
+
+	require.Len(t, app.Services, 1)
+	require.Equal(t, project.ContainerAppTarget, app.Services[0].Host)
+	require.Equal(t, "docker.io/contoso/api:1.0", app.Services[0].Image)
+}
+
+func Test_Inspector_UnsupportedResourceType(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	_, err := newInspectorFromMockContext(mockContext).Inspect(*mockContext.Context,
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Storage/storageAccounts/st")
+	require.ErrorContains(t, err, "can't be imported")
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package appimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"gopkg.in/yaml.v3"
+)
+
+// The metadata of k8s resources set by the cluster, removed from the imported manifests
+var clusterMetadata = []string{
+	"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink", "namespace",
+}
+
+// The annotations of k8s resources set by kubectl or by the cluster, removed from the imported manifests
+var clusterAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+// aksServices returns a service per deployment of the output of kubectl get deployments,services -o json. The manifests
+// of a service are its deployment, and the k8s services selecting the pods of the deployment. The image of the first
+// container of the deployment is replaced by the image azd deploys, ex) ${SERVICE_API_IMAGE_NAME}.
+func aksServices(resources []byte) ([]*Service, error) {
+	var list struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(resources, &list); err != nil {
+		return nil, fmt.Errorf("parsing k8s resources: %w", err)
+	}
+
+	k8sServices := []map[string]any{}
+	for _, item := range list.Items {
+		if item["kind"] == "Service" {
+			k8sServices = append(k8sServices, item)
+		}
+	}
+
+	services := []*Service{}
+	for _, deployment := range list.Items {
+		if deployment["kind"] != "Deployment" {
+			continue
+		}
+
+		name, _ := nested(deployment, "metadata", "name").(string)
+		service := &Service{
+			Name:      name,
+			Host:      project.AksTarget,
+			Language:  imageServiceLanguage,
+			Manifests: map[string][]byte{},
+		}
+
+		cleanManifest(deployment)
+		containers, _ := nested(deployment, "spec", "template", "spec", "containers").([]any)
+		if len(containers) > 0 {
+			if container, ok := containers[0].(map[string]any); ok {
+				service.Image, _ = container["image"].(string)
+				container["image"] = fmt.Sprintf(
+					"${SERVICE_%s_IMAGE_NAME}", strings.ReplaceAll(strings.ToUpper(name), "-", "_"))
+			}
+		}
+
+		contents, err := yaml.Marshal(deployment)
+		if err != nil {
+			return nil, err
+		}
+		service.Manifests["deployment.yaml"] = contents
+
+		labels, _ := nested(deployment, "spec", "template", "metadata", "labels").(map[string]any)
+		for _, k8sService := range k8sServices {
+			selector, _ := nested(k8sService, "spec", "selector").(map[string]any)
+			if !selects(selector, labels) {
+				continue
+			}
+
+			k8sServiceName, _ := nested(k8sService, "metadata", "name").(string)
+			// The k8s service is copied, a k8s service selecting the pods of several deployments is in each service
+			var manifest map[string]any
+			if err := remarshal(k8sService, &manifest); err != nil {
+				return nil, err
+			}
+
+			cleanManifest(manifest)
+			if spec, ok := manifest["spec"].(map[string]any); ok {
+				// The IPs of the k8s service are allocated by the cluster
+				delete(spec, "clusterIP")
+				delete(spec, "clusterIPs")
+			}
+
+			contents, err := yaml.Marshal(manifest)
+			if err != nil {
+				return nil, err
+			}
+			service.Manifests[fmt.Sprintf("service-%s.yaml", k8sServiceName)] = contents
+		}
+
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// cleanManifest removes the status, and the metadata and annotations set by the cluster, from the k8s resource
+func cleanManifest(resource map[string]any) {
+	delete(resource, "status")
+
+	metadata, ok := resource["metadata"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, name := range clusterMetadata {
+		delete(metadata, name)
+	}
+
+	annotations, ok := metadata["annotations"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, name := range clusterAnnotations {
+		delete(annotations, name)
+	}
+
+	if len(annotations) == 0 {
+		delete(metadata, "annotations")
+	}
+}
+
+// selects reports whether the selector of a k8s service selects the pods with the labels
+func selects(selector map[string]any, labels map[string]any) bool {
+	if len(selector) == 0 {
+		return false
+	}
+
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nested returns the value at the path of the properties of the object, nil when not found
+func nested(object map[string]any, path ...string) any {
+	var value any = object
+	for _, property := range path {
+		properties, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = properties[property]
+	}
+
+	return value
+}
+
+func remarshal(value any, target any) error {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contents, target)
+}
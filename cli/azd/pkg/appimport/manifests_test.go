@@ -0,0 +1,70 @@
+package appimport
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const kubectlResources = `{
+  "items": [
+    {
+      "kind": "Deployment",
+      "metadata": {
+        "name": "todo-api",
+        "namespace": "todo",
+        "uid": "1234",
+        "annotations": {"deployment.kubernetes.io/revision": "3"}
+      },
+      "spec": {
+        "template": {
+          "metadata": {"labels": {"app": "todo-api"}},
+          "spec": {"containers": [{"name": "api", "image": "contoso.azurecr.io/todo-api:1.0"}]}
+        }
+      },
+      "status": {"replicas": 1}
+    },
+    {
+      "kind": "Service",
+      "metadata": {"name": "todo-api", "namespace": "todo"},
+      "spec": {"selector": {"app": "todo-api"}, "clusterIP": "10.0.0.1", "ports": [{"port": 80}]}
+    },
+    {
+      "kind": "Service",
+      "metadata": {"name": "kubernetes"},
+      "spec": {"clusterIP": "10.0.0.2"}
+    }
+  ]
+}`
+
+func Test_AksServices(t *testing.T) {
+	services, err := aksServices([]byte(kubectlResources))
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+
+	service := services[0]
+	require.Equal(t, "todo-api", service.Name)
+	require.Equal(t, project.AksTarget, service.Host)
+	require.Equal(t, "contoso.azurecr.io/todo-api:1.0", service.Image)
+	require.Len(t, service.Manifests, 2)
+
+	var deployment map[string]any
+	require.NoError(t, yaml.Unmarshal(service.Manifests["deployment.yaml"], &deployment))
+	require.Nil(t, deployment["status"])
+	require.Equal(t, map[string]any{"name": "todo-api"}, deployment["metadata"])
+
+	containers := nested(deployment, "spec", "template", "spec", "containers").([]any)
+	require.Equal(t, "${SERVICE_TODO_API_IMAGE_NAME}", containers[0].(map[string]any)["image"])
+
+	var k8sService map[string]any
+	require.NoError(t, yaml.Unmarshal(service.Manifests["service-todo-api.yaml"], &k8sService))
+	require.Nil(t, nested(k8sService, "spec", "clusterIP"))
+	require.Nil(t, nested(k8sService, "metadata", "namespace"))
+}
+
+func Test_AksServices_InvalidResources(t *testing.T) {
+	_, err := aksServices([]byte("not json"))
+	require.Error(t, err)
+}
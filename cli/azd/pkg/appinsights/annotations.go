@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package appinsights creates the release annotations of the deployments of services on the Application Insights
+// component of the environment.
+package appinsights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+)
+
+// ComponentNameEnvVarName is the name of the key used to store the name of the Application Insights component annotated,
+// when the resource group of the environment has more than one. It's usually an output of the infrastructure.
+const ComponentNameEnvVarName = "APPLICATIONINSIGHTS_NAME"
+
+// releaseAnnotationCategory is the category of the annotations Application Insights shows as releases
+const releaseAnnotationCategory = "Deployment"
+
+// Release is the deployment of a service annotated on the metric charts of the Application Insights component.
+type Release struct {
+	Service string
+	// The deployed version of the service, ex) its container image
+	Version string
+	// The abbreviated hash of the commit the service was deployed from, empty outside of git repositories
+	Commit string
+	Time   time.Time
+}
+
+// Name is the name of the annotation of the release.
+func (r *Release) Name() string {
+	if r.Version == "" {
+		return fmt.Sprintf("Deployed %s", r.Service)
+	}
+
+	return fmt.Sprintf("Deployed %s %s", r.Service, r.Version)
+}
+
+// Annotator creates the release annotations of the deployments of services.
+type Annotator struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager project.ResourceManager
+	gitCli          git.GitCli
+
+	// the resource id of the component, resolved on the first annotation
+	componentId *string
+}
+
+func NewAnnotator(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+	gitCli git.GitCli,
+) *Annotator {
+	return &Annotator{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		gitCli:          gitCli,
+	}
+}
+
+// Annotate creates the release annotation of the deployment of the service with the version. Returns false when the
+// resource group of the environment has no Application Insights component, and the deployment isn't annotated.
+func (a *Annotator) Annotate(ctx context.Context, serviceConfig *project.ServiceConfig, version string) (bool, error) {
+	componentId, err := a.component(ctx, serviceConfig.Project)
+	if err != nil || componentId == "" {
+		return false, err
+	}
+
+	release := &Release{
+		Service: serviceConfig.Name,
+		Version: version,
+		Time:    time.Now(),
+	}
+
+	commit, err := a.gitCli.GetHeadCommit(ctx, serviceConfig.Path())
+	if err == nil {
+		release.Commit = commit
+	} else if !errors.Is(err, git.ErrNotRepository) {
+		log.Printf("failed getting the commit of service '%s': %v", serviceConfig.Name, err)
+	}
+
+	properties := map[string]string{
+		"ReleaseName":    release.Name(),
+		"Service":        release.Service,
+		"Environment":    a.env.GetEnvName(),
+		"ReleaseVersion": release.Version,
+		"CommitId":       release.Commit,
+	}
+
+	for key, value := range properties {
+		if value == "" {
+			delete(properties, key)
+		}
+	}
+
+	err = a.azCli.CreateAppInsightsAnnotation(
+		ctx,
+		a.env.GetSubscriptionId(),
+		componentId,
+		azcli.AzCliAppInsightsAnnotation{
+			Name:       release.Name(),
+			Category:   releaseAnnotationCategory,
+			EventTime:  release.Time,
+			Properties: properties,
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("annotating the release of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return true, nil
+}
+
+// component returns the resource id of the Application Insights component of the resource group of the environment, the
+// component named by APPLICATIONINSIGHTS_NAME or the only component, and an empty id when there is none
+func (a *Annotator) component(ctx context.Context, projectConfig *project.ProjectConfig) (string, error) {
+	if a.componentId != nil {
+		return *a.componentId, nil
+	}
+
+	subscriptionId := a.env.GetSubscriptionId()
+	resourceGroupName, err := a.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return "", fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	filter := fmt.Sprintf("resourceType eq '%s'", infra.AzureResourceTypeAppInsightComponent)
+	components, err := a.azCli.ListResourceGroupResources(
+		ctx,
+		subscriptionId,
+		resourceGroupName,
+		&azcli.ListResourceGroupResourcesOptions{
+			Filter: &filter,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("listing Application Insights components: %w", err)
+	}
+
+	componentId := ""
+	name := a.env.Getenv(ComponentNameEnvVarName)
+	for _, component := range components {
+		if strings.EqualFold(component.Name, name) || (name == "" && len(components) == 1) {
+			componentId = component.Id
+		}
+	}
+
+	if componentId == "" && len(components) > 0 {
+		log.Printf(
+			"skipping release annotations, none of the %d Application Insights components is named by %s",
+			len(components), ComponentNameEnvVarName)
+	}
+
+	a.componentId = &componentId
+	return componentId, nil
+}
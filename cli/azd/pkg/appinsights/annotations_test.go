@@ -0,0 +1,145 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package appinsights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const rgId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev"
+
+func componentId(name string) string {
+	return fmt.Sprintf("%s/providers/Microsoft.Insights/components/%s", rgId, name)
+}
+
+// newTestAnnotator mocks the components of the resource group, and records the annotations created on them
+func newTestAnnotator(
+	t *testing.T,
+	values map[string]string,
+	components ...string,
+) (*Annotator, *mocks.MockContext, map[string]map[string]any) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-dev/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "resourceType eq 'Microsoft.Insights/components'", request.URL.Query().Get("$filter"))
+
+		result := armresources.ResourceListResult{}
+		for _, name := range components {
+			result.Value = append(result.Value, &armresources.GenericResourceExpanded{
+				ID:       convert.RefOf(componentId(name)),
+				Name:     convert.RefOf(name),
+				Type:     convert.RefOf("Microsoft.Insights/components"),
+				Location: convert.RefOf("eastus2"),
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, result)
+	})
+
+	annotations := map[string]map[string]any{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/Annotations")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var body []map[string]any
+		require.NoError(t, json.Unmarshal(contents, &body))
+		require.Len(t, body, 1)
+		annotations[strings.TrimSuffix(request.URL.Path, "/Annotations")] = body[0]
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, body)
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse")
+	}).Respond(exec.NewRunResult(0, "1a2b3c4\n", ""))
+
+	values[environment.SubscriptionIdEnvVarName] = "SUBSCRIPTION_ID"
+	values[environment.ResourceGroupEnvVarName] = "rg-dev"
+	env := environment.EphemeralWithValues("dev", values)
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	annotator := NewAnnotator(
+		env, azCli, project.NewResourceManager(env, azCli), git.NewGitCli(mockContext.CommandRunner))
+
+	return annotator, mockContext, annotations
+}
+
+func testServiceConfig() *project.ServiceConfig {
+	return &project.ServiceConfig{
+		Name:         "api",
+		RelativePath: "src/api",
+		Project:      &project.ProjectConfig{Name: "app", Path: "."},
+	}
+}
+
+func Test_Annotate(t *testing.T) {
+	annotator, mockContext, annotations := newTestAnnotator(t, map[string]string{}, "appi-dev")
+
+	annotated, err := annotator.Annotate(*mockContext.Context, testServiceConfig(), "crdev.azurecr.io/app/api-dev:v2")
+	require.NoError(t, err)
+	require.True(t, annotated)
+
+	annotation := annotations[componentId("appi-dev")]
+	require.Equal(t, "Deployed api crdev.azurecr.io/app/api-dev:v2", annotation["AnnotationName"])
+	require.Equal(t, "Deployment", annotation["Category"])
+
+	var properties map[string]string
+	require.NoError(t, json.Unmarshal([]byte(annotation["Properties"].(string)), &properties))
+	require.Equal(t, map[string]string{
+		"ReleaseName":    "Deployed api crdev.azurecr.io/app/api-dev:v2",
+		"Service":        "api",
+		"Environment":    "dev",
+		"ReleaseVersion": "crdev.azurecr.io/app/api-dev:v2",
+		"CommitId":       "1a2b3c4",
+	}, properties)
+}
+
+func Test_AnnotateComponent(t *testing.T) {
+	t.Run("NoComponent", func(t *testing.T) {
+		annotator, mockContext, annotations := newTestAnnotator(t, map[string]string{})
+
+		annotated, err := annotator.Annotate(*mockContext.Context, testServiceConfig(), "")
+		require.NoError(t, err)
+		require.False(t, annotated)
+		require.Empty(t, annotations)
+	})
+
+	t.Run("NamedComponent", func(t *testing.T) {
+		annotator, mockContext, annotations := newTestAnnotator(
+			t, map[string]string{ComponentNameEnvVarName: "appi-web"}, "appi-api", "appi-web")
+
+		annotated, err := annotator.Annotate(*mockContext.Context, testServiceConfig(), "")
+		require.NoError(t, err)
+		require.True(t, annotated)
+		require.Contains(t, annotations, componentId("appi-web"))
+		require.Equal(t, "Deployed api", annotations[componentId("appi-web")]["AnnotationName"])
+	})
+
+	t.Run("SeveralComponents", func(t *testing.T) {
+		annotator, mockContext, annotations := newTestAnnotator(t, map[string]string{}, "appi-api", "appi-web")
+
+		annotated, err := annotator.Annotate(*mockContext.Context, testServiceConfig(), "")
+		require.NoError(t, err)
+		require.False(t, annotated)
+		require.Empty(t, annotations)
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/qrcode"
 	"github.com/cli/browser"
 )
 
@@ -83,6 +84,11 @@ type HttpClient interface {
 //
 // Logging out removes this cached authentication data.
 //
+// Users signed in to several accounts, ex) in the tenants of different customers, log in to each account with a named
+// profile. The identity information of the account of a named profile is stored under auth.profiles.<profile>.currentUser,
+// and the credentials of all the accounts share the caches above. Credentials are resolved through the active profile,
+// which an environment selects by binding to it. See [Manager.Profile].
+//
 // You can configure azd to ignore its native credential system and instead delegate to AZ CLI (useful for cases where azd
 // does not yet support your preferred method of authentication by setting [cUseLegacyAzCliAuthKey] in config to true.
 type Manager struct {
@@ -94,7 +100,12 @@ type Manager struct {
 	ghClient            *github.FederatedTokenClient
 	httpClient          HttpClient
 	launchBrowserFn     func(url string) error
-	console             input.Console
+	// When set, azd never launches a browser to log in. See [Manager.DisableBrowser].
+	noBrowser bool
+	console   input.Console
+	// The active profile, resolved on first use. See [Manager.Profile].
+	profile         *string
+	profileResolver ProfileResolver
 }
 
 func NewManager(
@@ -102,6 +113,7 @@ func NewManager(
 	userConfigManager config.UserConfigManager,
 	httpClient HttpClient,
 	console input.Console,
+	profileResolver ProfileResolver,
 ) (*Manager, error) {
 	cfgRoot, err := config.GetUserConfigDir()
 	if err != nil {
@@ -141,6 +153,7 @@ func NewManager(
 		httpClient:          httpClient,
 		launchBrowserFn:     browser.OpenURL,
 		console:             console,
+		profileResolver:     profileResolver,
 	}, nil
 }
 
@@ -190,7 +203,7 @@ func (m *Manager) CredentialForCurrentUser(
 		return nil, fmt.Errorf("reading auth config: %w", err)
 	}
 
-	currentUser, err := readUserProperties(authConfig)
+	currentUser, err := readUserProperties(authConfig, m.currentUserKey())
 	if errors.Is(err, ErrNoCurrentUser) {
 		// User is not logged in, not using az credentials, try CloudShell if possible
 		if ShouldUseCloudShellAuth() {
@@ -302,7 +315,7 @@ func (m *Manager) GetLoggedInServicePrincipalTenantID(ctx context.Context) (*str
 		return nil, fmt.Errorf("fetching auth config: %w", err)
 	}
 
-	currentUser, err := readUserProperties(authCfg)
+	currentUser, err := readUserProperties(authCfg, m.currentUserKey())
 	if err != nil {
 		// No user is logged in, if running in CloudShell use tenant id from
 		// CloudShell session (single tenant)
@@ -424,8 +437,41 @@ func (m *Manager) newCredentialFromCloudShell() (azcore.TokenCredential, error)
 	return NewCloudShellCredential(m.httpClient), nil
 }
 
+// NoBrowserEnvVarName is the environment variable which, when set to true, prevents azd from launching a browser to log
+// in, ex) on remote SSH sessions and in containers without a browser.
+const NoBrowserEnvVarName = "AZD_NO_BROWSER"
+
+// deviceCodeShortUrl is the short URL of the device code login page, rendered as a QR code since a shorter URL makes a
+// smaller QR code.
+const deviceCodeShortUrl = "https://aka.ms/devicelogin"
+
+// ErrBrowserDisabled is returned when logging in requires launching a browser, but the browser is disabled.
+var ErrBrowserDisabled = errors.New(
+	"logging in interactively requires a browser, which is disabled. Log in with a device code instead")
+
+// DisableBrowser prevents azd from launching a browser to log in. Device code login prints the code, the URL and a QR
+// code of the URL instead, and interactive login fails with [ErrBrowserDisabled].
+func (m *Manager) DisableBrowser() {
+	m.noBrowser = true
+}
+
+// BrowserDisabled reports whether azd must not launch a browser to log in, either because of [Manager.DisableBrowser]
+// or because AZD_NO_BROWSER is true.
+func (m *Manager) BrowserDisabled() bool {
+	if m.noBrowser {
+		return true
+	}
+
+	noBrowser, err := strconv.ParseBool(os.Getenv(NoBrowserEnvVarName))
+	return err == nil && noBrowser
+}
+
 func (m *Manager) LoginInteractive(
 	ctx context.Context, redirectPort int, tenantID string, scopes []string) (azcore.TokenCredential, error) {
+	if m.BrowserDisabled() {
+		return nil, ErrBrowserDisabled
+	}
+
 	if scopes == nil {
 		scopes = LoginScopes
 	}
@@ -476,10 +522,23 @@ func (m *Manager) LoginWithDeviceCode(
 				fmt.Sprintf("To sign in, use a web browser to open the page %s and enter the code %s to authenticate.", output.WithUnderline(url), output.WithBold(code.UserCode())),
 			},
 		})
+	} else if m.BrowserDisabled() {
+		m.showDeviceCodeQR(ctx)
+		m.console.MessageUxItem(ctx, &ux.MultilineMessage{
+			Lines: []string{
+				fmt.Sprintf("To sign in, scan the QR code or open %s (%s) in a browser on any device,",
+					output.WithUnderline(deviceCodeShortUrl), url),
+				fmt.Sprintf("then enter the code %s to authenticate.", output.WithBold(code.UserCode())),
+			},
+		})
+		m.console.Message(ctx, "Waiting for you to complete authentication...")
 	} else {
+		m.showDeviceCodeQR(ctx)
 		m.console.MessageUxItem(ctx, &ux.MultilineMessage{
 			Lines: []string{
 				fmt.Sprintf("Start by copying the next code: %s", output.WithBold(code.UserCode())),
+				fmt.Sprintf("To sign in on another device, scan the QR code or open %s.",
+					output.WithUnderline(deviceCodeShortUrl)),
 				"Then press enter and continue to log in from your browser...",
 			},
 		})
@@ -506,6 +565,18 @@ func (m *Manager) LoginWithDeviceCode(
 
 }
 
+// showDeviceCodeQR prints a QR code of the short URL of the device code login page, so users can log in from a phone
+// when the terminal is on a machine without a browser.
+func (m *Manager) showDeviceCodeQR(ctx context.Context) {
+	qr, err := qrcode.Encode(deviceCodeShortUrl)
+	if err != nil {
+		log.Printf("encoding device code QR code: %v", err)
+		return
+	}
+
+	m.console.Message(ctx, qr.String())
+}
+
 func (m *Manager) LoginWithServicePrincipalSecret(
 	ctx context.Context, tenantId, clientId, clientSecret string,
 ) (azcore.TokenCredential, error) {
@@ -585,22 +656,25 @@ func (m *Manager) Logout(ctx context.Context) error {
 		return fmt.Errorf("fetching current user: %w", err)
 	}
 
-	if act != nil {
-		if err := m.publicClient.RemoveAccount(ctx, *act); err != nil {
-			return fmt.Errorf("removing account from msal cache: %w", err)
-		}
-	}
-
 	cfg, err := m.readAuthConfig()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
 	// we are fine to ignore the error here, it just means there's nothing to clean up.
-	currentUser, _ := readUserProperties(cfg)
+	currentUser, _ := readUserProperties(cfg, m.currentUserKey())
+
+	// The cached credentials of an account other profiles are logged in to are kept
+	sharedAccount := currentUser != nil && m.usedByOtherProfiles(cfg, currentUser)
+
+	if act != nil && !sharedAccount {
+		if err := m.publicClient.RemoveAccount(ctx, *act); err != nil {
+			return fmt.Errorf("removing account from msal cache: %w", err)
+		}
+	}
 
 	// When logged in as a service principal, remove the stored credential
-	if currentUser != nil && currentUser.TenantID != nil && currentUser.ClientID != nil {
+	if currentUser != nil && currentUser.TenantID != nil && currentUser.ClientID != nil && !sharedAccount {
 		if err := m.saveLoginForServicePrincipal(
 			*currentUser.TenantID, *currentUser.ClientID, &persistedSecret{},
 		); err != nil {
@@ -608,7 +682,7 @@ func (m *Manager) Logout(ctx context.Context) error {
 		}
 	}
 
-	if err := cfg.Unset(cCurrentUserKey); err != nil {
+	if err := cfg.Unset(m.currentUserKey()); err != nil {
 		return fmt.Errorf("un-setting current user: %w", err)
 	}
 
@@ -647,7 +721,7 @@ func (m *Manager) getSignedInAccount(ctx context.Context) (*public.Account, erro
 		return nil, fmt.Errorf("fetching current user: %w", err)
 	}
 
-	currentUser, err := readUserProperties(cfg)
+	currentUser, err := readUserProperties(cfg, m.currentUserKey())
 	if err != nil {
 		return nil, ErrNoCurrentUser
 	}
@@ -667,14 +741,15 @@ func (m *Manager) getSignedInAccount(ctx context.Context) (*public.Account, erro
 	return nil, nil
 }
 
-// saveUserProperties writes the properties under [cCurrentUserKey], overwriting any existing value.
+// saveUserProperties writes the properties under the key of the active profile, [cCurrentUserKey] for the default
+// profile, overwriting any existing value.
 func (m *Manager) saveUserProperties(user *userProperties) error {
 	cfg, err := m.readAuthConfig()
 	if err != nil {
 		return fmt.Errorf("fetching current user: %w", err)
 	}
 
-	if err := cfg.Set(cCurrentUserKey, *user); err != nil {
+	if err := cfg.Set(m.currentUserKey(), *user); err != nil {
 		return fmt.Errorf("setting account id in config: %w", err)
 	}
 
@@ -815,8 +890,9 @@ type userProperties struct {
 	TenantID      *string `json:"tenantId,omitempty"`
 }
 
-func readUserProperties(cfg config.Config) (*userProperties, error) {
-	currentUser, has := cfg.Get(cCurrentUserKey)
+// readUserProperties reads the properties stored under the key, ex) the key of a profile
+func readUserProperties(cfg config.Config, key string) (*userProperties, error) {
+	currentUser, has := cfg.Get(key)
 	if !has {
 		return nil, ErrNoCurrentUser
 	}
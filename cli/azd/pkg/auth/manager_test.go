@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	_ "embed"
@@ -30,7 +31,7 @@ func TestReadUserProperties(t *testing.T) {
 		cfg := config.NewEmptyConfig()
 		require.NoError(t, cfg.Set("auth.account.currentUser.homeAccountId", "testAccountId"))
 
-		props, err := readUserProperties(cfg)
+		props, err := readUserProperties(cfg, cCurrentUserKey)
 
 		require.NoError(t, err)
 		require.Nil(t, props.ClientID)
@@ -43,7 +44,7 @@ func TestReadUserProperties(t *testing.T) {
 		require.NoError(t, cfg.Set("auth.account.currentUser.clientId", "testClientId"))
 		require.NoError(t, cfg.Set("auth.account.currentUser.tenantId", "testTenantId"))
 
-		props, err := readUserProperties(cfg)
+		props, err := readUserProperties(cfg, cCurrentUserKey)
 
 		require.NoError(t, err)
 		require.Nil(t, props.HomeAccountID)
@@ -256,6 +257,43 @@ func TestLoginDeviceCode(t *testing.T) {
 	require.True(t, errors.Is(err, ErrNoCurrentUser))
 }
 
+func TestLoginDeviceCodeNoBrowser(t *testing.T) {
+	console := mockinput.NewMockConsole()
+	m := &Manager{
+		configManager:     newMemoryConfigManager(),
+		userConfigManager: newMemoryUserConfigManager(),
+		publicClient:      &mockPublicClient{},
+		launchBrowserFn: func(url string) error {
+			require.Fail(t, "the browser must not be launched")
+			return nil
+		},
+		console: console,
+	}
+	m.DisableBrowser()
+
+	cred, err := m.LoginWithDeviceCode(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.IsType(t, new(azdCredential), cred)
+
+	output := strings.Join(console.Output(), "\n")
+	require.Contains(t, output, "https://aka.ms/devicelogin")
+	require.Contains(t, output, "123-456")
+	require.Contains(t, output, "█")
+
+	_, err = m.LoginInteractive(context.Background(), 0, "", nil)
+	require.ErrorIs(t, err, ErrBrowserDisabled)
+}
+
+func TestBrowserDisabledEnvVar(t *testing.T) {
+	m := &Manager{}
+
+	t.Setenv(NoBrowserEnvVarName, "")
+	require.False(t, m.BrowserDisabled())
+
+	t.Setenv(NoBrowserEnvVarName, "true")
+	require.True(t, m.BrowserDisabled())
+}
+
 func TestAuthFileConfigUpgrade(t *testing.T) {
 	cfgMgr := newMemoryConfigManager()
 	userCfg := config.NewEmptyConfig()
@@ -278,7 +316,7 @@ func TestAuthFileConfigUpgrade(t *testing.T) {
 	cfg, err := m.readAuthConfig()
 	require.NoError(t, err)
 
-	properties, err := readUserProperties(cfg)
+	properties, err := readUserProperties(cfg, cCurrentUserKey)
 	require.NoError(t, err)
 	require.NotNil(t, properties.HomeAccountID)
 	require.Equal(t, "homeAccountID", *properties.HomeAccountID)
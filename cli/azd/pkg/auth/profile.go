@@ -0,0 +1,155 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultProfile is the name of the auth profile used when no profile is selected. The account of the default profile is
+// stored under [cCurrentUserKey], like before azd supported profiles.
+const DefaultProfile = "default"
+
+// cProfilesKey is the key we use in config for storing the identity information of the accounts of named profiles, each
+// under <cProfilesKey>.<profile>.currentUser.
+const cProfilesKey = "auth.profiles"
+
+// ProfileEnvVarName is the environment variable selecting the auth profile, overriding the profile of the environment.
+const ProfileEnvVarName = "AZD_AUTH_PROFILE"
+
+// ProfileConfigPath is the path of the auth profile in the config of an environment, binding the environment to it.
+const ProfileConfigPath = "auth.profile"
+
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// ProfileResolver returns the auth profile bound to the active environment, or an empty string when there is no active
+// environment or it isn't bound to a profile.
+type ProfileResolver func() string
+
+// ValidateProfileName returns an error when the name can't be used as the name of a profile.
+func ValidateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf(
+			"profile name '%s' is invalid. Profile names contain only letters, digits, '-' and '_'", name)
+	}
+
+	return nil
+}
+
+// UseProfile selects the profile the credentials of the current user are resolved through, overriding the profile of the
+// environment. An empty name selects the default profile.
+func (m *Manager) UseProfile(name string) error {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	m.profile = &name
+	return nil
+}
+
+// Profile returns the name of the active profile. The profile selected with [Manager.UseProfile] takes precedence over the
+// profile of the AZD_AUTH_PROFILE environment variable, which takes precedence over the profile bound to the active
+// environment.
+func (m *Manager) Profile() string {
+	if m.profile != nil {
+		return *m.profile
+	}
+
+	name := os.Getenv(ProfileEnvVarName)
+	if name == "" && m.profileResolver != nil {
+		name = m.profileResolver()
+	}
+
+	if name == "" {
+		name = DefaultProfile
+	} else if err := ValidateProfileName(name); err != nil {
+		log.Printf("using the default profile: %v", err)
+		name = DefaultProfile
+	}
+
+	// the active environment doesn't change during a command
+	m.profile = &name
+	return name
+}
+
+// Profiles lists the names of the profiles which are logged in.
+func (m *Manager) Profiles() ([]string, error) {
+	cfg, err := m.readAuthConfig()
+	if err != nil {
+		return nil, fmt.Errorf("reading auth config: %w", err)
+	}
+
+	return profileNames(cfg), nil
+}
+
+func profileNames(cfg config.Config) []string {
+	names := []string{}
+	if _, has := cfg.Get(cCurrentUserKey); has {
+		names = append(names, DefaultProfile)
+	}
+
+	if profiles, has := cfg.Get(cProfilesKey); has {
+		if profiles, ok := profiles.(map[string]any); ok {
+			for name := range profiles {
+				if _, has := cfg.Get(profileUserKey(name)); has {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// profileUserKey returns the key the identity information of the account of the profile is stored under
+func profileUserKey(profile string) string {
+	if profile == DefaultProfile {
+		return cCurrentUserKey
+	}
+
+	return fmt.Sprintf("%s.%s.currentUser", cProfilesKey, profile)
+}
+
+// currentUserKey returns the key the identity information of the account of the active profile is stored under
+func (m *Manager) currentUserKey() string {
+	return profileUserKey(m.Profile())
+}
+
+// usedByOtherProfiles reports whether a profile other than the active profile is logged in to the same account, in
+// which case the cached credentials of the account are kept when the active profile logs out.
+func (m *Manager) usedByOtherProfiles(cfg config.Config, user *userProperties) bool {
+	userJson, err := json.Marshal(user)
+	if err != nil {
+		return false
+	}
+
+	for _, name := range profileNames(cfg) {
+		if name == m.Profile() {
+			continue
+		}
+
+		other, err := readUserProperties(cfg, profileUserKey(name))
+		if err != nil {
+			continue
+		}
+
+		if otherJson, err := json.Marshal(other); err == nil && string(otherJson) == string(userJson) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	t.Setenv(ProfileEnvVarName, "")
+
+	t.Run("Default", func(t *testing.T) {
+		m := Manager{}
+		require.Equal(t, DefaultProfile, m.Profile())
+	})
+
+	t.Run("Environment", func(t *testing.T) {
+		m := Manager{
+			profileResolver: func() string { return "work" },
+		}
+		require.Equal(t, "work", m.Profile())
+	})
+
+	t.Run("EnvVarOverridesEnvironment", func(t *testing.T) {
+		t.Setenv(ProfileEnvVarName, "ci")
+		m := Manager{
+			profileResolver: func() string { return "work" },
+		}
+		require.Equal(t, "ci", m.Profile())
+	})
+
+	t.Run("UseProfileOverridesAll", func(t *testing.T) {
+		t.Setenv(ProfileEnvVarName, "ci")
+		m := Manager{
+			profileResolver: func() string { return "work" },
+		}
+		require.NoError(t, m.UseProfile("customer"))
+		require.Equal(t, "customer", m.Profile())
+
+		require.NoError(t, m.UseProfile(""))
+		require.Equal(t, DefaultProfile, m.Profile())
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		m := Manager{
+			profileResolver: func() string { return "../work" },
+		}
+		require.Equal(t, DefaultProfile, m.Profile())
+		require.Error(t, m.UseProfile("work.customer"))
+	})
+}
+
+func TestProfileUserKey(t *testing.T) {
+	require.Equal(t, cCurrentUserKey, profileUserKey(DefaultProfile))
+	require.Equal(t, "auth.profiles.work.currentUser", profileUserKey("work"))
+}
+
+func TestProfilesLoginLogout(t *testing.T) {
+	t.Setenv(ProfileEnvVarName, "")
+
+	credentialCache := &memoryCache{
+		cache: make(map[string][]byte),
+	}
+	configManager := newMemoryConfigManager()
+	userConfigManager := newMemoryUserConfigManager()
+
+	newManager := func(profile string) *Manager {
+		m := &Manager{
+			configManager:     configManager,
+			userConfigManager: userConfigManager,
+			credentialCache:   credentialCache,
+		}
+		require.NoError(t, m.UseProfile(profile))
+		return m
+	}
+
+	defaultManager := newManager(DefaultProfile)
+	workManager := newManager("work")
+	customerManager := newManager("customer")
+
+	_, err := defaultManager.LoginWithServicePrincipalSecret(
+		context.Background(), "defaultClientId", "testTenantId", "testClientSecret")
+	require.NoError(t, err)
+
+	_, err = workManager.LoginWithServicePrincipalSecret(
+		context.Background(), "workClientId", "testTenantId", "testClientSecret")
+	require.NoError(t, err)
+
+	_, err = customerManager.LoginWithServicePrincipalSecret(
+		context.Background(), "workClientId", "testTenantId", "testClientSecret")
+	require.NoError(t, err)
+
+	profiles, err := defaultManager.Profiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{"customer", DefaultProfile, "work"}, profiles)
+
+	// logging out of a profile keeps the credentials of the account another profile is logged in to
+	require.NoError(t, customerManager.Logout(context.Background()))
+
+	_, err = customerManager.CredentialForCurrentUser(context.Background(), nil)
+	require.True(t, errors.Is(err, ErrNoCurrentUser))
+
+	cred, err := workManager.CredentialForCurrentUser(context.Background(), nil)
+	require.NoError(t, err)
+	require.IsType(t, new(azidentity.ClientSecretCredential), cred)
+
+	require.NoError(t, workManager.Logout(context.Background()))
+
+	_, err = workManager.CredentialForCurrentUser(context.Background(), nil)
+	require.True(t, errors.Is(err, ErrNoCurrentUser))
+
+	cred, err = defaultManager.CredentialForCurrentUser(context.Background(), nil)
+	require.NoError(t, err)
+	require.IsType(t, new(azidentity.ClientSecretCredential), cred)
+
+	profiles, err = defaultManager.Profiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{DefaultProfile}, profiles)
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/pipelinepermissions"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
+)
+
+// api version of the environments and pipeline permissions REST APIs, which have no client in the AzDo go sdk
+const environmentsApiVersion = "7.0-preview.1"
+
+// the Azure DevOps environment of an azd environment. Pipelines targeting the environment from a deployment job record
+// their deployments to it, and wait on the approvals and checks configured on it.
+type Environment struct {
+	Id   int
+	Name string
+	// web url of the environment, where its deployment history, approvals and checks are displayed
+	WebUrl string
+}
+
+// creates, or updates, the Azure DevOps environment named after the azd environment, with a description linking to its
+// Azure resources. The environment is authorized to be used in all the pipelines of the project.
+func CreateOrUpdateEnvironment(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	projectId string,
+	azdEnvironment *environment.Environment,
+	console input.Console) (*Environment, error) {
+	client := connection.GetClientByUrl(connection.BaseUrl)
+	name := azdEnvironment.GetEnvName()
+	description := environmentDescription(azdEnvironment)
+
+	found, err := findEnvironment(ctx, client, connection.BaseUrl, projectId, name)
+	if err != nil {
+		return nil, fmt.Errorf("looking for existing environment: %w", err)
+	}
+
+	var result taskagent.EnvironmentInstance
+	if found != nil {
+		updateUrl := fmt.Sprintf("%s/%s/_apis/distributedtask/environments/%d", connection.BaseUrl, projectId, *found.Id)
+		err = sendEnvironmentsRequest(ctx, client, http.MethodPatch, updateUrl, taskagent.EnvironmentUpdateParameter{
+			Name:        &name,
+			Description: &description,
+		}, &result)
+		if err != nil {
+			return nil, fmt.Errorf("updating environment: %w", err)
+		}
+		console.MessageUxItem(ctx, &ux.DisplayedResource{
+			Type: "Azure DevOps",
+			Name: "Updated environment",
+		})
+	} else {
+		createUrl := fmt.Sprintf("%s/%s/_apis/distributedtask/environments", connection.BaseUrl, projectId)
+		err = sendEnvironmentsRequest(ctx, client, http.MethodPost, createUrl, taskagent.EnvironmentCreateParameter{
+			Name:        &name,
+			Description: &description,
+		}, &result)
+		if err != nil {
+			return nil, fmt.Errorf("creating environment: %w", err)
+		}
+		console.MessageUxItem(ctx, &ux.DisplayedResource{
+			Type: "Azure DevOps",
+			Name: "Environment",
+		})
+	}
+
+	if err := authorizeEnvironmentToAllPipelines(ctx, client, connection.BaseUrl, projectId, *result.Id); err != nil {
+		return nil, fmt.Errorf("authorizing environment: %w", err)
+	}
+
+	return &Environment{
+		Id:     *result.Id,
+		Name:   name,
+		WebUrl: fmt.Sprintf("%s/%s/_environments/%d", connection.BaseUrl, projectId, *result.Id),
+	}, nil
+}
+
+// find environment by name.
+func findEnvironment(
+	ctx context.Context,
+	client *azuredevops.Client,
+	baseUrl string,
+	projectId string,
+	name string) (*taskagent.EnvironmentInstance, error) {
+	listUrl := fmt.Sprintf(
+		"%s/%s/_apis/distributedtask/environments?name=%s", baseUrl, projectId, url.QueryEscape(name))
+
+	var environments struct {
+		Value []taskagent.EnvironmentInstance `json:"value"`
+	}
+	if err := sendEnvironmentsRequest(ctx, client, http.MethodGet, listUrl, nil, &environments); err != nil {
+		return nil, err
+	}
+
+	for _, env := range environments.Value {
+		if env.Name != nil && *env.Name == name {
+			return &env, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// authorize an environment to be used in all pipelines, so that the first run of the pipeline isn't blocked
+// waiting for permission
+func authorizeEnvironmentToAllPipelines(
+	ctx context.Context,
+	client *azuredevops.Client,
+	baseUrl string,
+	projectId string,
+	environmentId int) error {
+	permissionsUrl := fmt.Sprintf(
+		"%s/%s/_apis/pipelines/pipelinePermissions/environment/%d", baseUrl, projectId, environmentId)
+	authorized := true
+
+	return sendEnvironmentsRequest(ctx, client, http.MethodPatch, permissionsUrl,
+		pipelinepermissions.ResourcePipelinePermissions{
+			AllPipelines: &pipelinepermissions.Permission{
+				Authorized: &authorized,
+			},
+		}, nil)
+}
+
+// describes the azd environment and links to its resource group, or subscription, in the Azure portal
+func environmentDescription(azdEnvironment *environment.Environment) string {
+	description := fmt.Sprintf("Deployments of the azd environment '%s'.", azdEnvironment.GetEnvName())
+
+	subscriptionId := azdEnvironment.GetSubscriptionId()
+	if subscriptionId == "" {
+		return description
+	}
+
+	resourcesUrl := fmt.Sprintf("https://portal.azure.com/#@/resource/subscriptions/%s/overview", subscriptionId)
+	if resourceGroup := azdEnvironment.Getenv(environment.ResourceGroupEnvVarName); resourceGroup != "" {
+		resourcesUrl = fmt.Sprintf(
+			"https://portal.azure.com/#@/resource/subscriptions/%s/resourceGroups/%s/overview", subscriptionId, resourceGroup)
+	}
+
+	return fmt.Sprintf("%s Azure resources: %s", description, resourcesUrl)
+}
+
+// sends a request to the environments, or pipeline permissions, REST API and unmarshals the response into result,
+// when not nil
+func sendEnvironmentsRequest(
+	ctx context.Context,
+	client *azuredevops.Client,
+	method string,
+	requestUrl string,
+	body any,
+	result any) error {
+	bodyReader := bytes.NewReader(nil)
+	mediaType := ""
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(content)
+		mediaType = "application/json"
+	}
+
+	req, err := client.CreateRequestMessage(
+		ctx, method, requestUrl, environmentsApiVersion, bodyReader, mediaType, "application/json", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.SendRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return res.Body.Close()
+	}
+
+	return client.UnmarshalBody(res, result)
+}
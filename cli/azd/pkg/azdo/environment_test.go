@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/pipelinepermissions"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateOrUpdateEnvironment(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+	})
+
+	run := func(t *testing.T, existing []taskagent.EnvironmentInstance) (*Environment, map[string]string, []string) {
+		bodies := map[string]string{}
+		requests := []string{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+
+			var body map[string]any
+			if r.Method != http.MethodGet {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				content, _ := json.Marshal(body)
+				bodies[r.Method+" "+r.URL.Path] = string(content)
+			}
+
+			switch {
+			case r.Method == http.MethodGet:
+				require.Equal(t, "dev", r.URL.Query().Get("name"))
+				_ = json.NewEncoder(w).Encode(map[string]any{"count": len(existing), "value": existing})
+			case r.URL.Path == "/PROJECT_ID/_apis/pipelines/pipelinePermissions/environment/7":
+				_ = json.NewEncoder(w).Encode(pipelinepermissions.ResourcePipelinePermissions{})
+			default:
+				id := 7
+				name := body["name"].(string)
+				_ = json.NewEncoder(w).Encode(taskagent.EnvironmentInstance{Id: &id, Name: &name})
+			}
+		}))
+		defer server.Close()
+
+		connection := azuredevops.NewPatConnection(server.URL, "PAT")
+		azdoEnvironment, err := CreateOrUpdateEnvironment(
+			context.Background(), connection, "PROJECT_ID", env, mockinput.NewMockConsole())
+		require.NoError(t, err)
+
+		require.Equal(t, 7, azdoEnvironment.Id)
+		require.Equal(t, "dev", azdoEnvironment.Name)
+		require.Equal(t, server.URL+"/PROJECT_ID/_environments/7", azdoEnvironment.WebUrl)
+
+		return azdoEnvironment, bodies, requests
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		_, bodies, requests := run(t, nil)
+
+		require.Equal(t, []string{
+			"GET /PROJECT_ID/_apis/distributedtask/environments",
+			"POST /PROJECT_ID/_apis/distributedtask/environments",
+			"PATCH /PROJECT_ID/_apis/pipelines/pipelinePermissions/environment/7",
+		}, requests)
+		var created taskagent.EnvironmentCreateParameter
+		require.NoError(t, json.Unmarshal([]byte(bodies["POST /PROJECT_ID/_apis/distributedtask/environments"]), &created))
+		require.Equal(t, "dev", *created.Name)
+		require.Equal(t,
+			"Deployments of the azd environment 'dev'. "+
+				"Azure resources: https://portal.azure.com/#@/resource/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/overview",
+			*created.Description)
+		require.JSONEq(t, `{"allPipelines": {"authorized": true}}`,
+			bodies["PATCH /PROJECT_ID/_apis/pipelines/pipelinePermissions/environment/7"])
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		id := 7
+		name := "dev"
+		_, _, requests := run(t, []taskagent.EnvironmentInstance{{Id: &id, Name: &name}})
+
+		require.Equal(t, []string{
+			"GET /PROJECT_ID/_apis/distributedtask/environments",
+			"PATCH /PROJECT_ID/_apis/distributedtask/environments/7",
+			"PATCH /PROJECT_ID/_apis/pipelines/pipelinePermissions/environment/7",
+		}, requests)
+	})
+}
+
+func Test_environmentDescription(t *testing.T) {
+	t.Run("NoSubscription", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", nil)
+		require.Equal(t, "Deployments of the azd environment 'dev'.", environmentDescription(env))
+	})
+
+	t.Run("Subscription", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+		require.Equal(t,
+			"Deployments of the azd environment 'dev'. "+
+				"Azure resources: https://portal.azure.com/#@/resource/subscriptions/SUBSCRIPTION_ID/overview",
+			environmentDescription(env))
+	})
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/operations"
@@ -73,26 +75,21 @@ func createProject(
 		OperationId: res.Id,
 	}
 
-	projectCreated := false
-	maxCheck := 10
-	count := 0
-
-	for !projectCreated {
+	errProjectNotCreated := fmt.Errorf("error creating azure devops project %s", name)
+	err = wait.Retry(ctx, clock.New(), wait.ConstantRetry(10, 800*time.Millisecond), func(ctx context.Context) error {
 		operation, err := operationsClient.GetOperation(ctx, getOperationsArgs)
 		if err != nil {
-			return nil, err
-		}
-
-		if *operation.Status == "succeeded" {
-			projectCreated = true
+			return err
 		}
 
-		if count >= maxCheck {
-			return nil, fmt.Errorf("error creating azure devops project %s", name)
+		if *operation.Status != "succeeded" {
+			return wait.RetryableError(errProjectNotCreated)
 		}
 
-		count++
-		time.Sleep(800 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	project, err := GetProjectByName(ctx, connection, name)
@@ -0,0 +1,209 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// ErrPullRequestUnavailable is returned when azd doesn't run in an Azure Pipelines run of a pull request with a token to
+// comment on it
+var ErrPullRequestUnavailable = errors.New(
+	"not running in Azure Pipelines for a pull request with SYSTEM_ACCESSTOKEN set")
+
+// pullRequestsApiVersion is the version of the pull request threads API of Azure DevOps
+const pullRequestsApiVersion = "7.0"
+
+// PullRequestCommentsClient creates and updates a comment of the pull request of the Azure Pipelines run azd runs in.
+// The build service of the project needs the Contribute to pull requests permission on the repository.
+type PullRequestCommentsClient struct {
+	pipeline runtime.Pipeline
+	// The url of the pull request, ex)
+	// https://dev.azure.com/contoso/<project id>/_apis/git/repositories/<repository id>/pullRequests/7
+	pullRequestUrl string
+	// The ids of the thread and comment, once created or found
+	threadId  int
+	commentId int
+}
+
+// NewPullRequestCommentsClientFromEnv creates a client for the pull request of the Azure Pipelines run azd runs in,
+// using the predefined variables of the run. The System.AccessToken variable of the pipeline must be mapped to the
+// SYSTEM_ACCESSTOKEN environment variable of the step. ErrPullRequestUnavailable is returned outside of Azure Pipelines,
+// for runs which aren't triggered by a pull request, or without a token.
+func NewPullRequestCommentsClientFromEnv(options *policy.ClientOptions) (*PullRequestCommentsClient, error) {
+	if strings.ToLower(os.Getenv("TF_BUILD")) != "true" {
+		return nil, ErrPullRequestUnavailable
+	}
+
+	token := os.Getenv("SYSTEM_ACCESSTOKEN")
+	collectionUri := os.Getenv("SYSTEM_COLLECTIONURI")
+	projectId := os.Getenv("SYSTEM_TEAMPROJECTID")
+	repositoryId := os.Getenv("BUILD_REPOSITORY_ID")
+	pullRequestId := os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID")
+	if token == "" || collectionUri == "" || projectId == "" || repositoryId == "" || pullRequestId == "" {
+		return nil, ErrPullRequestUnavailable
+	}
+
+	pipeline := runtime.NewPipeline("azdo", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			&bearerTokenPolicy{token: token},
+		},
+	}, options)
+
+	return &PullRequestCommentsClient{
+		pipeline: pipeline,
+		pullRequestUrl: fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullRequests/%s",
+			strings.TrimSuffix(collectionUri, "/"), projectId, repositoryId, pullRequestId),
+	}, nil
+}
+
+type pullRequestComment struct {
+	Id              int    `json:"id,omitempty"`
+	ParentCommentId int    `json:"parentCommentId,omitempty"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType,omitempty"`
+}
+
+type pullRequestThread struct {
+	Id       int                  `json:"id,omitempty"`
+	Comments []pullRequestComment `json:"comments"`
+	Status   string               `json:"status,omitempty"`
+}
+
+type pullRequestThreads struct {
+	Value []pullRequestThread `json:"value"`
+}
+
+// UpsertComment updates the comment of the pull request containing the marker, ex) an HTML comment, with the content,
+// or creates it in a new thread when the pull request has no such comment. The content should contain the marker for
+// the comment to be updated the next time.
+func (c *PullRequestCommentsClient) UpsertComment(ctx context.Context, marker string, content string) error {
+	if c.threadId == 0 {
+		if err := c.findComment(ctx, marker); err != nil {
+			return err
+		}
+	}
+
+	if c.threadId == 0 {
+		return c.createThread(ctx, content)
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodPatch,
+		fmt.Sprintf("%s/threads/%d/comments/%d", c.pullRequestUrl, c.threadId, c.commentId))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = "api-version=" + pullRequestsApiVersion
+	if err := runtime.MarshalAsJSON(req, pullRequestComment{Content: content}); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return fmt.Errorf("updating pull request comment: %w", runtime.NewResponseError(res))
+	}
+
+	return nil
+}
+
+// createThread creates an active thread of the pull request with the content as its first comment
+func (c *PullRequestCommentsClient) createThread(ctx context.Context, content string) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/threads", c.pullRequestUrl))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = "api-version=" + pullRequestsApiVersion
+	if err := runtime.MarshalAsJSON(req, pullRequestThread{
+		Comments: []pullRequestComment{{Content: content, CommentType: "text"}},
+		Status:   "active",
+	}); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK, http.StatusCreated) {
+		return fmt.Errorf("creating pull request thread: %w", runtime.NewResponseError(res))
+	}
+
+	thread, err := httputil.ReadRawResponse[pullRequestThread](res)
+	if err != nil {
+		return fmt.Errorf("reading body: %w", err)
+	}
+
+	if len(thread.Comments) == 0 {
+		return errors.New("the pull request thread was created without comment")
+	}
+
+	c.threadId = thread.Id
+	c.commentId = thread.Comments[0].Id
+	return nil
+}
+
+// findComment finds the thread and comment of the pull request containing the marker
+func (c *PullRequestCommentsClient) findComment(ctx context.Context, marker string) error {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s/threads", c.pullRequestUrl))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = "api-version=" + pullRequestsApiVersion
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return fmt.Errorf("listing pull request threads: %w", runtime.NewResponseError(res))
+	}
+
+	threads, err := httputil.ReadRawResponse[pullRequestThreads](res)
+	if err != nil {
+		return fmt.Errorf("reading body: %w", err)
+	}
+
+	for _, thread := range threads.Value {
+		for _, comment := range thread.Comments {
+			if strings.Contains(comment.Content, marker) {
+				c.threadId = thread.Id
+				c.commentId = comment.Id
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+type bearerTokenPolicy struct {
+	token string
+}
+
+// Do authorizes a request to the Azure DevOps API with the token
+func (p *bearerTokenPolicy) Do(req *policy.Request) (*http.Response, error) {
+	req.Raw().Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.token))
+	return req.Next()
+}
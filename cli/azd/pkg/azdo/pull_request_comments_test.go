@@ -0,0 +1,85 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func setPullRequestRunEnv(t *testing.T) {
+	t.Setenv("TF_BUILD", "True")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "fake-token")
+	t.Setenv("SYSTEM_COLLECTIONURI", "https://dev.azure.com/contoso/")
+	t.Setenv("SYSTEM_TEAMPROJECTID", "project-id")
+	t.Setenv("BUILD_REPOSITORY_ID", "repository-id")
+	t.Setenv("SYSTEM_PULLREQUEST_PULLREQUESTID", "7")
+}
+
+func TestNewPullRequestCommentsClientFromEnv(t *testing.T) {
+	setPullRequestRunEnv(t)
+	t.Setenv("SYSTEM_PULLREQUEST_PULLREQUESTID", "")
+
+	_, err := NewPullRequestCommentsClientFromEnv(nil)
+	require.ErrorIs(t, err, ErrPullRequestUnavailable)
+}
+
+func TestPullRequestUpsertComment(t *testing.T) {
+	setPullRequestRunEnv(t)
+
+	const threadsPath = "/contoso/project-id/_apis/git/repositories/repository-id/pullRequests/7/threads"
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == threadsPath
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, pullRequestThreads{
+			Value: []pullRequestThread{{Id: 1, Comments: []pullRequestComment{{Id: 1, Content: "LGTM"}}}},
+		})
+	})
+
+	var created pullRequestThread
+	var authorization string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == threadsPath
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		authorization = request.Header.Get("Authorization")
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &created))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, pullRequestThread{
+			Id: 5, Comments: []pullRequestComment{{Id: 1, Content: created.Comments[0].Content}},
+		})
+	})
+
+	var updated pullRequestComment
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch && request.URL.Path == threadsPath+"/5/comments/1"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &updated))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updated)
+	})
+
+	client, err := NewPullRequestCommentsClientFromEnv(&policy.ClientOptions{Transport: mockContext.HttpClient})
+	require.NoError(t, err)
+
+	require.NoError(t, client.UpsertComment(*mockContext.Context, "<!-- marker -->", "<!-- marker -->\nstarted"))
+	require.Equal(t, "Bearer fake-token", authorization)
+	require.Equal(t, "active", created.Status)
+	require.Equal(t, "<!-- marker -->\nstarted", created.Comments[0].Content)
+
+	require.NoError(t, client.UpsertComment(*mockContext.Context, "<!-- marker -->", "<!-- marker -->\ndone"))
+	require.Equal(t, "<!-- marker -->\ndone", updated.Content)
+}
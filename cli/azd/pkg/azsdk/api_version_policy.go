@@ -0,0 +1,150 @@
+package azsdk
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// ApiVersionsEnvVarName pins the ARM api-versions of resource types, for clouds which lag the api-versions of the
+// public cloud, ex) Microsoft.App/containerApps=2023-05-01,Microsoft.Web/sites=2022-03-01
+const ApiVersionsEnvVarName = "AZD_ARM_API_VERSIONS"
+
+const apiVersionQueryParameter = "api-version"
+
+// usedApiVersions are the api-versions of the calls to ARM of the process, by resource type
+var usedApiVersions = struct {
+	mu       sync.Mutex
+	versions map[string]map[string]struct{}
+}{versions: map[string]map[string]struct{}{}}
+
+// apiVersionPolicy records the api-versions of the calls to ARM by resource type, and replaces the api-versions of the
+// resource types pinned with AZD_ARM_API_VERSIONS
+type apiVersionPolicy struct {
+	// The pinned api-versions by lowercase resource type
+	pins map[string]string
+}
+
+// NewApiVersionPolicy creates a per-call policy recording the api-versions azd calls ARM with, see [UsedApiVersions],
+// and pinning the api-versions of AZD_ARM_API_VERSIONS
+func NewApiVersionPolicy() policy.Policy {
+	return &apiVersionPolicy{
+		pins: parseApiVersionPins(os.Getenv(ApiVersionsEnvVarName)),
+	}
+}
+
+func (p *apiVersionPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	query := raw.URL.Query()
+	apiVersion := query.Get(apiVersionQueryParameter)
+	resourceType := ArmResourceType(raw.URL.Path)
+	if apiVersion == "" || resourceType == "" {
+		return req.Next()
+	}
+
+	if pinned, has := p.pins[strings.ToLower(resourceType)]; has && pinned != apiVersion {
+		log.Printf("pinning api-version of %s to %s instead of %s", resourceType, pinned, apiVersion)
+
+		apiVersion = pinned
+		query.Set(apiVersionQueryParameter, pinned)
+		raw.URL.RawQuery = query.Encode()
+	}
+
+	recordApiVersion(resourceType, apiVersion)
+
+	return req.Next()
+}
+
+// UsedApiVersions returns the api-versions of the calls to ARM of the process by resource type, sorted
+func UsedApiVersions() map[string][]string {
+	usedApiVersions.mu.Lock()
+	defer usedApiVersions.mu.Unlock()
+
+	result := make(map[string][]string, len(usedApiVersions.versions))
+	for resourceType, versions := range usedApiVersions.versions {
+		sorted := make([]string, 0, len(versions))
+		for version := range versions {
+			sorted = append(sorted, version)
+		}
+		sort.Strings(sorted)
+
+		result[resourceType] = sorted
+	}
+
+	return result
+}
+
+func recordApiVersion(resourceType string, apiVersion string) {
+	usedApiVersions.mu.Lock()
+	defer usedApiVersions.mu.Unlock()
+
+	versions, has := usedApiVersions.versions[resourceType]
+	if !has {
+		versions = map[string]struct{}{}
+		usedApiVersions.versions[resourceType] = versions
+	}
+
+	versions[apiVersion] = struct{}{}
+}
+
+// ArmResourceType returns the resource type of the path of an ARM request, ex) Microsoft.Web/sites/slots for
+// /subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Web/sites/app/slots/staging. Returns an empty string for the
+// paths which aren't paths of ARM.
+func ArmResourceType(path string) string {
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 ||
+		(!strings.EqualFold(segments[0], "subscriptions") && !strings.EqualFold(segments[0], "providers")) {
+		return ""
+	}
+
+	// The resources of extension resources are of the last provider, ex) role assignments of a resource. Resource groups
+	// are of Microsoft.Resources, not a child type of subscriptions.
+	namespace := "Microsoft.Resources"
+	types := segments
+	if len(types) > 2 && strings.EqualFold(types[0], "subscriptions") {
+		types = types[2:]
+	}
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			namespace = segments[i+1]
+			types = segments[i+2:]
+			break
+		}
+	}
+
+	// The segments alternate between types and names, ex) sites/app/slots/staging
+	typeNames := []string{}
+	for i := 0; i < len(types); i += 2 {
+		typeNames = append(typeNames, types[i])
+	}
+
+	if len(typeNames) == 0 {
+		return namespace
+	}
+
+	return namespace + "/" + strings.Join(typeNames, "/")
+}
+
+// parseApiVersionPins parses the pinned api-versions by resource type, ex) Microsoft.Web/sites=2022-03-01, separated by
+// commas
+func parseApiVersionPins(value string) map[string]string {
+	pins := map[string]string{}
+	for _, pin := range strings.Split(value, ",") {
+		resourceType, apiVersion, has := strings.Cut(strings.TrimSpace(pin), "=")
+		if !has || resourceType == "" || apiVersion == "" {
+			if strings.TrimSpace(pin) != "" {
+				log.Printf("ignoring invalid api-version pin '%s' of %s", pin, ApiVersionsEnvVarName)
+			}
+			continue
+		}
+
+		pins[strings.ToLower(strings.TrimSpace(resourceType))] = strings.TrimSpace(apiVersion)
+	}
+
+	return pins
+}
@@ -0,0 +1,129 @@
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ArmResourceType(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		expect string
+	}{
+		{
+			name:   "Resource",
+			path:   "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Web/sites/app",
+			expect: "Microsoft.Web/sites",
+		},
+		{
+			name:   "ChildResource",
+			path:   "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Web/sites/app/slots/staging",
+			expect: "Microsoft.Web/sites/slots",
+		},
+		{
+			name:   "Collection",
+			path:   "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Web/sites",
+			expect: "Microsoft.Web/sites",
+		},
+		{
+			name: "ExtensionResource",
+			path: "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.KeyVault/vaults/kv" +
+				"/providers/Microsoft.Authorization/roleAssignments/ID",
+			expect: "Microsoft.Authorization/roleAssignments",
+		},
+		{
+			name:   "ResourceGroup",
+			path:   "/subscriptions/SUB/resourcegroups/RG",
+			expect: "Microsoft.Resources/resourcegroups",
+		},
+		{
+			name:   "Subscription",
+			path:   "/subscriptions/SUB",
+			expect: "Microsoft.Resources/subscriptions",
+		},
+		{
+			name:   "Provider",
+			path:   "/providers/Microsoft.Web",
+			expect: "Microsoft.Web",
+		},
+		{
+			name:   "NotArm",
+			path:   "/api/zipdeploy",
+			expect: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expect, ArmResourceType(tt.path))
+		})
+	}
+}
+
+func Test_parseApiVersionPins(t *testing.T) {
+	pins := parseApiVersionPins(" Microsoft.App/containerApps = 2023-05-01,invalid,,Microsoft.Web/sites=2022-03-01")
+
+	require.Equal(t, map[string]string{
+		"microsoft.app/containerapps": "2023-05-01",
+		"microsoft.web/sites":         "2022-03-01",
+	}, pins)
+}
+
+func Test_apiVersionPolicy_Do(t *testing.T) {
+	tests := []struct {
+		name   string
+		pins   string
+		expect string
+	}{
+		{
+			name:   "NotPinned",
+			pins:   "",
+			expect: "2021-04-01",
+		},
+		{
+			name:   "Pinned",
+			pins:   "Microsoft.Resources/resourceGroups=2020-06-01",
+			expect: "2020-06-01",
+		},
+		{
+			name:   "OtherTypePinned",
+			pins:   "Microsoft.Web/sites=2022-03-01",
+			expect: "2021-04-01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ApiVersionsEnvVarName, tt.pins)
+
+			httpClient := mockhttp.NewMockHttpUtil()
+			httpClient.When(func(request *http.Request) bool {
+				return true
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+			})
+
+			clientOptions := NewClientOptionsBuilder().
+				WithTransport(httpClient).
+				WithPerCallPolicy(NewApiVersionPolicy()).
+				BuildArmClientOptions()
+
+			client, err := armresources.NewResourceGroupsClient("SUB", &mocks.MockCredentials{}, clientOptions)
+			require.NoError(t, err)
+
+			var response *http.Response
+			ctx := runtime.WithCaptureResponse(context.Background(), &response)
+
+			_, _ = client.Get(ctx, "RG", nil)
+
+			require.Equal(t, tt.expect, response.Request.URL.Query().Get(apiVersionQueryParameter))
+			require.Contains(t, UsedApiVersions()["Microsoft.Resources/resourcegroups"], tt.expect)
+		})
+	}
+}
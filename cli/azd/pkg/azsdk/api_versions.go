@@ -0,0 +1,193 @@
+package azsdk
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"golang.org/x/exp/slices"
+)
+
+// ApiVersionsFileName is the name of the file of the user config directory the api-versions azd called ARM with are
+// recorded in, across runs, for `azd internal api-versions` to report the resource types with newer api-versions
+const ApiVersionsFileName = "api-versions.json"
+
+// The notes of the breaking changes of api-versions, by resource type and api-version, ex)
+// {"Microsoft.App/containerApps": {"2024-03-01": "..."}}, reported when azd uses an older api-version of the type
+//
+//go:embed api_version_notes.json
+var apiVersionNotesJson []byte
+
+// SaveUsedApiVersions adds the api-versions of the calls to ARM of the process to the api-versions recorded in the file.
+// The file is only written when the process used api-versions which aren't recorded.
+func SaveUsedApiVersions(path string) error {
+	used := UsedApiVersions()
+	if len(used) == 0 {
+		return nil
+	}
+
+	recorded, err := LoadApiVersions(path)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for resourceType, versions := range used {
+		for _, version := range versions {
+			if !slices.Contains(recorded[resourceType], version) {
+				recorded[resourceType] = append(recorded[resourceType], version)
+				changed = true
+			}
+		}
+		sort.Strings(recorded[resourceType])
+	}
+
+	if !changed {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, contents, osutil.PermissionFile)
+}
+
+// LoadApiVersions loads the api-versions recorded in the file by resource type, empty when the file doesn't exist
+func LoadApiVersions(path string) (map[string][]string, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	recorded := map[string][]string{}
+	if err := json.Unmarshal(contents, &recorded); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return recorded, nil
+}
+
+// ApiVersionReport compares the api-versions azd uses for a resource type with the api-versions available in a cloud
+type ApiVersionReport struct {
+	ResourceType string   `json:"resourceType"`
+	Used         []string `json:"used"`
+	// The latest stable api-version of the resource type, empty when the resource type isn't known to the cloud
+	LatestStable string `json:"latestStable,omitempty"`
+	// The stable api-versions newer than the api-versions used
+	Newer []string `json:"newer,omitempty"`
+	// The api-versions used which the cloud doesn't support, ex) in clouds which lag the api-versions of the public cloud
+	Unavailable []string `json:"unavailable,omitempty"`
+	// The breaking changes of the newer api-versions
+	Notes []string `json:"notes,omitempty"`
+}
+
+// HasUpgrade reports whether newer stable api-versions are available, or some used api-versions aren't
+func (r *ApiVersionReport) HasUpgrade() bool {
+	return len(r.Newer) > 0 || len(r.Unavailable) > 0
+}
+
+// NewApiVersionReport compares the api-versions used for the resource type with the api-versions available for it
+func NewApiVersionReport(resourceType string, used []string, available []string) (*ApiVersionReport, error) {
+	notes := map[string]map[string]string{}
+	if err := json.Unmarshal(apiVersionNotesJson, &notes); err != nil {
+		return nil, fmt.Errorf("parsing api-version notes: %w", err)
+	}
+
+	return newApiVersionReport(resourceType, used, available, notes), nil
+}
+
+func newApiVersionReport(
+	resourceType string,
+	used []string,
+	available []string,
+	notes map[string]map[string]string,
+) *ApiVersionReport {
+	report := &ApiVersionReport{
+		ResourceType: resourceType,
+		Used:         used,
+	}
+
+	if len(available) == 0 {
+		return report
+	}
+
+	latestUsed := ""
+	for _, version := range used {
+		if !containsFold(available, version) {
+			report.Unavailable = append(report.Unavailable, version)
+		}
+
+		if version > latestUsed {
+			latestUsed = version
+		}
+	}
+
+	// Compared by their dates, the prefixes of api-versions
+	for _, version := range available {
+		if !isStableApiVersion(version) {
+			continue
+		}
+
+		if version > report.LatestStable {
+			report.LatestStable = version
+		}
+
+		if apiVersionDate(version) > apiVersionDate(latestUsed) {
+			report.Newer = append(report.Newer, version)
+		}
+	}
+	sort.Strings(report.Newer)
+
+	for typeName, typeNotes := range notes {
+		if !strings.EqualFold(typeName, resourceType) {
+			continue
+		}
+
+		for _, version := range report.Newer {
+			if note, has := typeNotes[version]; has {
+				report.Notes = append(report.Notes, fmt.Sprintf("%s: %s", version, note))
+			}
+		}
+	}
+
+	return report
+}
+
+// isStableApiVersion reports whether the api-version isn't a preview, ex) 2023-05-01 but not 2023-11-02-preview
+func isStableApiVersion(version string) bool {
+	return !strings.Contains(version, "-preview") && !strings.Contains(version, "-beta") &&
+		!strings.Contains(version, "-alpha")
+}
+
+// apiVersionDate returns the date of the api-version, ex) 2023-11-02 for 2023-11-02-preview
+func apiVersionDate(version string) string {
+	if len(version) > len("2006-01-02") {
+		return version[:len("2006-01-02")]
+	}
+
+	return version
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package azsdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newApiVersionReport(t *testing.T) {
+	notes := map[string]map[string]string{
+		"Microsoft.App/containerApps": {
+			"2024-03-01": "ingress is required",
+		},
+	}
+
+	t.Run("NewerStable", func(t *testing.T) {
+		report := newApiVersionReport(
+			"Microsoft.App/containerApps",
+			[]string{"2023-05-01"},
+			[]string{"2022-03-01", "2023-05-01", "2024-03-01", "2024-08-02-preview"},
+			notes,
+		)
+
+		require.True(t, report.HasUpgrade())
+		require.Equal(t, "2024-03-01", report.LatestStable)
+		require.Equal(t, []string{"2024-03-01"}, report.Newer)
+		require.Empty(t, report.Unavailable)
+		require.Equal(t, []string{"2024-03-01: ingress is required"}, report.Notes)
+	})
+
+	t.Run("Latest", func(t *testing.T) {
+		report := newApiVersionReport(
+			"Microsoft.Web/sites",
+			[]string{"2022-03-01"},
+			[]string{"2021-02-01", "2022-03-01", "2022-09-01-preview"},
+			notes,
+		)
+
+		require.False(t, report.HasUpgrade())
+		require.Equal(t, "2022-03-01", report.LatestStable)
+		require.Empty(t, report.Notes)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		report := newApiVersionReport(
+			"Microsoft.Web/sites",
+			[]string{"2023-01-01"},
+			[]string{"2021-02-01", "2022-03-01"},
+			notes,
+		)
+
+		require.True(t, report.HasUpgrade())
+		require.Equal(t, []string{"2023-01-01"}, report.Unavailable)
+		require.Empty(t, report.Newer)
+	})
+
+	t.Run("UnknownType", func(t *testing.T) {
+		report := newApiVersionReport("Microsoft.Web/sites", []string{"2022-03-01"}, nil, notes)
+
+		require.False(t, report.HasUpgrade())
+		require.Empty(t, report.LatestStable)
+	})
+}
+
+func Test_NewApiVersionReport(t *testing.T) {
+	// The embedded notes are valid
+	_, err := NewApiVersionReport("Microsoft.Web/sites", []string{"2022-03-01"}, []string{"2022-03-01"})
+	require.NoError(t, err)
+}
+
+func Test_SaveUsedApiVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ApiVersionsFileName)
+
+	recorded, err := LoadApiVersions(path)
+	require.NoError(t, err)
+	require.Empty(t, recorded)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"Microsoft.Web/sites": ["2022-03-01"]}`), 0600))
+
+	recordApiVersion("Microsoft.Web/sites", "2021-02-01")
+	recordApiVersion("Microsoft.KeyVault/vaults", "2023-07-01")
+	require.NoError(t, SaveUsedApiVersions(path))
+
+	recorded, err = LoadApiVersions(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2021-02-01", "2022-03-01"}, recorded["Microsoft.Web/sites"])
+	require.Equal(t, []string{"2023-07-01"}, recorded["Microsoft.KeyVault/vaults"])
+}
@@ -13,5 +13,9 @@ func DefaultClientOptionsBuilder(
 	return NewClientOptionsBuilder().
 		WithTransport(httpClient).
 		WithPerCallPolicy(NewUserAgentPolicy(userAgent)).
-		WithPerCallPolicy(NewMsCorrelationPolicy(ctx))
+		WithPerCallPolicy(NewMsCorrelationPolicy(ctx)).
+		WithPerCallPolicy(NewApiCallMetricsPolicy()).
+		WithPerCallPolicy(NewApiVersionPolicy()).
+		WithPerRetryPolicy(NewApiRetryMetricsPolicy()).
+		WithPerRetryPolicy(NewArmRateLimitPolicy(ArmRateLimiter()))
 }
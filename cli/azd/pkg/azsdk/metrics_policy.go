@@ -0,0 +1,64 @@
+package azsdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+)
+
+// apiCallAttempts counts the attempts of a call, as an operation value shared by its retries
+type apiCallAttempts struct {
+	count int
+}
+
+// apiCallMetricsPolicy counts the calls to Azure APIs, by their host and the class of their status
+type apiCallMetricsPolicy struct {
+}
+
+// NewApiCallMetricsPolicy creates a per-call policy counting the calls to Azure APIs in [metrics.ApiCalls]. Pair it with
+// the per-retry policy of [NewApiRetryMetricsPolicy] to count their retries.
+func NewApiCallMetricsPolicy() policy.Policy {
+	return &apiCallMetricsPolicy{}
+}
+
+func (p *apiCallMetricsPolicy) Do(req *policy.Request) (*http.Response, error) {
+	req.SetOperationValue(&apiCallAttempts{})
+
+	res, err := req.Next()
+
+	status := "error"
+	if err == nil {
+		status = fmt.Sprintf("%dxx", res.StatusCode/100)
+	}
+
+	metrics.Add(metrics.ApiCalls, 1, map[string]string{
+		"host":   req.Raw().URL.Host,
+		"status": status,
+	})
+
+	return res, err
+}
+
+// apiRetryMetricsPolicy counts the retries of the calls to Azure APIs
+type apiRetryMetricsPolicy struct {
+}
+
+// NewApiRetryMetricsPolicy creates a per-retry policy counting the retries of calls to Azure APIs in
+// [metrics.ApiRetries]
+func NewApiRetryMetricsPolicy() policy.Policy {
+	return &apiRetryMetricsPolicy{}
+}
+
+func (p *apiRetryMetricsPolicy) Do(req *policy.Request) (*http.Response, error) {
+	var attempts *apiCallAttempts
+	if req.OperationValue(&attempts) {
+		attempts.count++
+		if attempts.count > 1 {
+			metrics.Add(metrics.ApiRetries, 1, map[string]string{"host": req.Raw().URL.Host})
+		}
+	}
+
+	return req.Next()
+}
@@ -0,0 +1,58 @@
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApiMetricsPolicies(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	t.Cleanup(func() { metrics.SetSink(nil) })
+
+	attempts := 0
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+		}
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	clientOptions := NewClientOptionsBuilder().
+		WithTransport(httpClient).
+		WithPerCallPolicy(NewApiCallMetricsPolicy()).
+		WithPerRetryPolicy(NewApiRetryMetricsPolicy()).
+		BuildArmClientOptions()
+	clientOptions.Retry.RetryDelay = time.Millisecond
+
+	client, err := armresources.NewClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, clientOptions)
+	require.NoError(t, err)
+
+	_, err = client.GetByID(context.Background(), "RESOURCE_ID", "", nil)
+	require.Error(t, err)
+	require.Equal(t, 3, attempts)
+
+	host := map[string]string{"host": "management.azure.com"}
+	require.Equal(t, int64(2), sink.Value(metrics.ApiRetries, host))
+	require.Equal(t, int64(1), sink.Value(metrics.ApiCalls, map[string]string{
+		"host":   "management.azure.com",
+		"status": "4xx",
+	}))
+	require.Equal(t, int64(0), sink.Value(metrics.ApiCalls, map[string]string{
+		"host":   "management.azure.com",
+		"status": "5xx",
+	}))
+}
@@ -0,0 +1,276 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+)
+
+const (
+	// ArmReadsPerSecondEnvVarName sets the reads per second, per subscription, azd sends to ARM. 0 disables the limit.
+	ArmReadsPerSecondEnvVarName = "AZD_ARM_READS_PER_SECOND"
+	// ArmWritesPerSecondEnvVarName sets the writes and deletes per second, per subscription, azd sends to ARM. 0 disables
+	// the limit.
+	ArmWritesPerSecondEnvVarName = "AZD_ARM_WRITES_PER_SECOND"
+)
+
+// The seconds of requests a bucket holds, the requests sent in a burst before the requests are delayed
+const rateLimitBurstSeconds = 10
+
+// RateLimits are the requests per second azd sends to ARM per subscription. ARM throttles the requests of a
+// subscription with token buckets refilled by the second; azd keeps its requests under the same refill rates, for large
+// parallel deployments to be delayed instead of failing with 429 responses.
+type RateLimits struct {
+	ReadsPerSecond  float64
+	WritesPerSecond float64
+}
+
+// DefaultRateLimits are the refill rates of the buckets ARM throttles the requests of a subscription with
+var DefaultRateLimits = RateLimits{
+	ReadsPerSecond:  25,
+	WritesPerSecond: 10,
+}
+
+// RateLimitsFromEnv returns the [DefaultRateLimits] overridden by AZD_ARM_READS_PER_SECOND and
+// AZD_ARM_WRITES_PER_SECOND
+func RateLimitsFromEnv() RateLimits {
+	limits := DefaultRateLimits
+	for name, limit := range map[string]*float64{
+		ArmReadsPerSecondEnvVarName:  &limits.ReadsPerSecond,
+		ArmWritesPerSecondEnvVarName: &limits.WritesPerSecond,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		perSecond, err := strconv.ParseFloat(value, 64)
+		if err != nil || perSecond < 0 {
+			log.Printf("ignoring invalid rate limit '%s' of %s", value, name)
+			continue
+		}
+
+		*limit = perSecond
+	}
+
+	return limits
+}
+
+// Environ returns the environment variables setting the limits, ex) for the azd processes sharing the limits of the
+// subscription
+func (l RateLimits) Environ() []string {
+	return []string{
+		fmt.Sprintf("%s=%s", ArmReadsPerSecondEnvVarName, strconv.FormatFloat(l.ReadsPerSecond, 'f', -1, 64)),
+		fmt.Sprintf("%s=%s", ArmWritesPerSecondEnvVarName, strconv.FormatFloat(l.WritesPerSecond, 'f', -1, 64)),
+	}
+}
+
+// Divide returns the limits split evenly among the given number of clients, ex) concurrent azd processes
+func (l RateLimits) Divide(clients int) RateLimits {
+	if clients < 2 {
+		return l
+	}
+
+	return RateLimits{
+		ReadsPerSecond:  l.ReadsPerSecond / float64(clients),
+		WritesPerSecond: l.WritesPerSecond / float64(clients),
+	}
+}
+
+// RateLimitStats are the counters of a [RateLimiter]
+type RateLimitStats struct {
+	// The requests sent to ARM
+	Requests int64
+	// The requests delayed to stay under the limits
+	Delayed int64
+	// The time the delayed requests waited
+	Waited time.Duration
+	// The requests throttled by ARM, with 429 responses
+	Throttled int64
+}
+
+// tokenBucket holds the tokens of requests, refilled at a constant rate up to the burst
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimiter delays the requests to ARM exceeding the limits of their subscription. It's safe for concurrent use.
+type RateLimiter struct {
+	limits RateLimits
+	// now and sleep are replaced by tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	stats   RateLimitStats
+}
+
+// NewRateLimiter creates a limiter of the requests to ARM, with a bucket per subscription for the reads, and another for
+// the writes
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		now:     time.Now,
+		sleep:   sleepContext,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+var (
+	armRateLimiterOnce sync.Once
+	armRateLimiter     *RateLimiter
+)
+
+// ArmRateLimiter returns the limiter shared by the clients of ARM of the process, with the [RateLimitsFromEnv]
+func ArmRateLimiter() *RateLimiter {
+	armRateLimiterOnce.Do(func() {
+		armRateLimiter = NewRateLimiter(RateLimitsFromEnv())
+	})
+
+	return armRateLimiter
+}
+
+// Limits returns the limits of the limiter
+func (r *RateLimiter) Limits() RateLimits {
+	return r.limits
+}
+
+// Stats returns the counters of the requests of the limiter
+func (r *RateLimiter) Stats() RateLimitStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// Wait takes a token of the bucket of the subscription, waiting for the bucket to be refilled when it's empty. It
+// returns the time waited, or the error of the context when it's done before.
+func (r *RateLimiter) Wait(ctx context.Context, subscriptionId string, write bool) (time.Duration, error) {
+	perSecond := r.limits.ReadsPerSecond
+	key := "read/" + strings.ToLower(subscriptionId)
+	if write {
+		perSecond = r.limits.WritesPerSecond
+		key = "write/" + strings.ToLower(subscriptionId)
+	}
+
+	r.mu.Lock()
+	r.stats.Requests++
+	if perSecond <= 0 {
+		r.mu.Unlock()
+		return 0, nil
+	}
+
+	// The tokens are taken ahead, the requests waiting for the same bucket are sent in order of their arrival
+	now := r.now()
+	burst := perSecond * rateLimitBurstSeconds
+	bucket, has := r.buckets[key]
+	if !has {
+		bucket = &tokenBucket{tokens: burst, updated: now}
+		r.buckets[key] = bucket
+	}
+
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * perSecond
+		if bucket.tokens > burst {
+			bucket.tokens = burst
+		}
+		bucket.updated = now
+	}
+
+	bucket.tokens--
+	wait := time.Duration(0)
+	if bucket.tokens < 0 {
+		wait = time.Duration(-bucket.tokens / perSecond * float64(time.Second))
+		r.stats.Delayed++
+		r.stats.Waited += wait
+	}
+	r.mu.Unlock()
+
+	if wait == 0 {
+		return 0, nil
+	}
+
+	return wait, r.sleep(ctx, wait)
+}
+
+// throttled counts a request throttled by ARM
+func (r *RateLimiter) throttled() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.Throttled++
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// armRateLimitPolicy delays the requests to ARM exceeding the limits of their subscription
+type armRateLimitPolicy struct {
+	limiter *RateLimiter
+}
+
+// NewArmRateLimitPolicy creates a per-retry policy delaying the requests to ARM with the limiter, and counting the time
+// waited in [metrics.ApiRateLimitWait] and the throttled requests in [metrics.ApiThrottled]. The retries of a request
+// take their own tokens.
+func NewArmRateLimitPolicy(limiter *RateLimiter) policy.Policy {
+	return &armRateLimitPolicy{limiter: limiter}
+}
+
+func (p *armRateLimitPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	subscriptionId := armSubscriptionId(raw.URL.Path)
+	if subscriptionId == "" {
+		return req.Next()
+	}
+
+	write := raw.Method != http.MethodGet && raw.Method != http.MethodHead
+	waited, err := p.limiter.Wait(raw.Context(), subscriptionId, write)
+	if err != nil {
+		return nil, err
+	}
+
+	if waited > 0 {
+		metrics.Add(metrics.ApiRateLimitWait, waited.Milliseconds(), map[string]string{"host": raw.URL.Host})
+	}
+
+	res, err := req.Next()
+	if err == nil && res.StatusCode == http.StatusTooManyRequests {
+		log.Printf("request to %s throttled by ARM, retry after '%s'", raw.URL.Path, res.Header.Get("Retry-After"))
+
+		p.limiter.throttled()
+		metrics.Add(metrics.ApiThrottled, 1, map[string]string{"host": raw.URL.Host})
+	}
+
+	return res, err
+}
+
+// armSubscriptionId returns the subscription of the path of an ARM request, ex) SUB for
+// /subscriptions/SUB/resourceGroups/RG. Returns an empty string for the paths which aren't scoped to a subscription.
+func armSubscriptionId(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 || !strings.EqualFold(segments[0], "subscriptions") {
+		return ""
+	}
+
+	return segments[1]
+}
@@ -0,0 +1,149 @@
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRateLimiter creates a limiter with a clock advanced by the time the requests wait
+func newTestRateLimiter(limits RateLimits) (*RateLimiter, *time.Time) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(limits)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+
+	return limiter, &now
+}
+
+func Test_RateLimiter_Wait(t *testing.T) {
+	t.Run("Burst", func(t *testing.T) {
+		limiter, _ := newTestRateLimiter(RateLimits{ReadsPerSecond: 2, WritesPerSecond: 1})
+
+		// The bucket of the reads holds 10 seconds of requests
+		for i := 0; i < 20; i++ {
+			waited, err := limiter.Wait(context.Background(), "SUB", false)
+			require.NoError(t, err)
+			require.Zero(t, waited)
+		}
+
+		waited, err := limiter.Wait(context.Background(), "SUB", false)
+		require.NoError(t, err)
+		require.Equal(t, 500*time.Millisecond, waited)
+
+		require.Equal(t, RateLimitStats{Requests: 21, Delayed: 1, Waited: 500 * time.Millisecond}, limiter.Stats())
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		limiter, now := newTestRateLimiter(RateLimits{ReadsPerSecond: 1, WritesPerSecond: 1})
+		for i := 0; i < 10; i++ {
+			_, err := limiter.Wait(context.Background(), "SUB", true)
+			require.NoError(t, err)
+		}
+
+		*now = now.Add(3 * time.Second)
+		for i := 0; i < 3; i++ {
+			waited, err := limiter.Wait(context.Background(), "SUB", true)
+			require.NoError(t, err)
+			require.Zero(t, waited)
+		}
+
+		waited, err := limiter.Wait(context.Background(), "SUB", true)
+		require.NoError(t, err)
+		require.Equal(t, time.Second, waited)
+	})
+
+	t.Run("BucketPerSubscriptionAndKind", func(t *testing.T) {
+		limiter, _ := newTestRateLimiter(RateLimits{ReadsPerSecond: 0.1, WritesPerSecond: 0.1})
+
+		for _, subscriptionId := range []string{"SUB1", "SUB2"} {
+			for _, write := range []bool{false, true} {
+				waited, err := limiter.Wait(context.Background(), subscriptionId, write)
+				require.NoError(t, err)
+				require.Zero(t, waited)
+			}
+		}
+
+		waited, err := limiter.Wait(context.Background(), "sub1", false)
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, waited)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		limiter, _ := newTestRateLimiter(RateLimits{ReadsPerSecond: 0, WritesPerSecond: 1})
+		for i := 0; i < 100; i++ {
+			waited, err := limiter.Wait(context.Background(), "SUB", false)
+			require.NoError(t, err)
+			require.Zero(t, waited)
+		}
+
+		require.Equal(t, int64(100), limiter.Stats().Requests)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimits{ReadsPerSecond: 0.1, WritesPerSecond: 0.1})
+		_, err := limiter.Wait(context.Background(), "SUB", false)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = limiter.Wait(ctx, "SUB", false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test_RateLimitsFromEnv(t *testing.T) {
+	t.Setenv(ArmReadsPerSecondEnvVarName, "12.5")
+	t.Setenv(ArmWritesPerSecondEnvVarName, "invalid")
+
+	limits := RateLimitsFromEnv()
+	require.Equal(t, RateLimits{ReadsPerSecond: 12.5, WritesPerSecond: DefaultRateLimits.WritesPerSecond}, limits)
+
+	require.Equal(t, []string{
+		"AZD_ARM_READS_PER_SECOND=6.25",
+		"AZD_ARM_WRITES_PER_SECOND=5",
+	}, limits.Divide(2).Environ())
+	require.Equal(t, limits, limits.Divide(1))
+}
+
+func Test_armSubscriptionId(t *testing.T) {
+	require.Equal(t, "SUB", armSubscriptionId("/subscriptions/SUB/resourceGroups/RG"))
+	require.Equal(t, "SUB", armSubscriptionId("/subscriptions/SUB"))
+	require.Equal(t, "", armSubscriptionId("/subscriptions"))
+	require.Equal(t, "", armSubscriptionId("/providers/Microsoft.Web"))
+	require.Equal(t, "", armSubscriptionId("/api/zipdeploy"))
+}
+
+func Test_armRateLimitPolicy_Do(t *testing.T) {
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusTooManyRequests)
+	})
+
+	limiter, _ := newTestRateLimiter(DefaultRateLimits)
+	clientOptions := NewClientOptionsBuilder().
+		WithTransport(httpClient).
+		WithPerRetryPolicy(NewArmRateLimitPolicy(limiter)).
+		BuildArmClientOptions()
+	clientOptions.Retry.MaxRetries = -1
+
+	client, err := armresources.NewResourceGroupsClient("SUB", &mocks.MockCredentials{}, clientOptions)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "RG", nil)
+	require.Error(t, err)
+
+	require.Equal(t, RateLimitStats{Requests: 1, Throttled: 1}, limiter.Stats())
+}
@@ -0,0 +1,137 @@
+package azsdk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// The size of the chunks deployment packages are read and uploaded in, and the progress of their upload is reported for
+const zipDeployChunkSize = 4 * 1024 * 1024
+
+var errZipDeployPackageChanged = errors.New("the deployment package changed while uploading it")
+
+// zipDeployBody is the body of a zip deploy request, streaming the deployment package in chunks.
+//
+// The checksum of the package is computed before uploading it, and the upload fails when the chunks read don't match it,
+// ex) when the package changes while uploading it. This only validates the package read locally, the checksum is sent
+// with the request for the server to validate the package it received, see [zipDeployBody.ContentDigest].
+//
+// The body is rewound by the retry policy of the pipeline when an upload fails on a flaky connection, for the whole
+// package to be uploaded again: zip deploy takes the package in a single request, which can't be resumed from the chunk
+// that failed.
+type zipDeployBody struct {
+	reader   io.ReadSeeker
+	size     int64
+	checksum []byte
+	progress func(percent int)
+
+	// The state of the current upload, reset when the body is rewound
+	offset   int64
+	hash     hash.Hash
+	reported int
+}
+
+// newZipDeployBody computes the checksum of the package, and creates a body reporting the progress of its upload
+func newZipDeployBody(reader io.ReadSeeker, progress func(percent int)) (*zipDeployBody, error) {
+	checksum := sha256.New()
+	size, err := io.Copy(checksum, reader)
+	if err != nil {
+		return nil, fmt.Errorf("computing checksum of deployment package: %w", err)
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewinding deployment package: %w", err)
+	}
+
+	return &zipDeployBody{
+		reader:   reader,
+		size:     size,
+		checksum: checksum.Sum(nil),
+		progress: progress,
+		hash:     sha256.New(),
+		reported: -1,
+	}, nil
+}
+
+func (b *zipDeployBody) Read(p []byte) (int, error) {
+	if b.offset == b.size {
+		return 0, io.EOF
+	}
+
+	// Reads stop at the end of the chunk, for the progress to be reported as each chunk is uploaded
+	if remaining := zipDeployChunkSize - b.offset%zipDeployChunkSize; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := b.reader.Read(p)
+	b.hash.Write(p[:n])
+	b.offset += int64(n)
+
+	if b.offset > b.size || (errors.Is(err, io.EOF) && b.offset < b.size) {
+		return n, errZipDeployPackageChanged
+	}
+
+	if b.offset%zipDeployChunkSize == 0 || b.offset == b.size {
+		b.reportProgress()
+	}
+
+	// The request may not read past the content length, so the checksum is verified with the last chunk
+	if b.offset == b.size {
+		if !bytes.Equal(b.hash.Sum(nil), b.checksum) {
+			return n, errZipDeployPackageChanged
+		}
+
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// ContentDigest returns the checksum of the package as the value of the Content-Digest header of RFC 9530, ex)
+// sha-256=:<base64 checksum>:
+func (b *zipDeployBody) ContentDigest() string {
+	return fmt.Sprintf("sha-256=:%s:", base64.StdEncoding.EncodeToString(b.checksum))
+}
+
+// Seek supports rewinding the body, and seeking its end for the size of the request
+func (b *zipDeployBody) Seek(offset int64, whence int) (int64, error) {
+	switch {
+	case offset == 0 && whence == io.SeekStart:
+		if _, err := b.reader.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		b.offset = 0
+		b.hash.Reset()
+		b.reported = -1
+		return 0, nil
+	case offset == 0 && whence == io.SeekEnd:
+		return b.size, nil
+	case offset == 0 && whence == io.SeekCurrent:
+		return b.offset, nil
+	default:
+		return 0, fmt.Errorf("seeking offset %d from %d of deployment package isn't supported", offset, whence)
+	}
+}
+
+// Close doesn't close the package, which is owned by the caller and read again when the upload is retried
+func (b *zipDeployBody) Close() error {
+	return nil
+}
+
+func (b *zipDeployBody) reportProgress() {
+	if b.progress == nil || b.size == 0 {
+		return
+	}
+
+	percent := int(b.offset * 100 / b.size)
+	if percent != b.reported {
+		b.reported = percent
+		b.progress(percent)
+	}
+}
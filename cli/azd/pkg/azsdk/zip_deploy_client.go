@@ -13,6 +13,7 @@ import (
 	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 )
 
@@ -82,13 +83,20 @@ func NewZipDeployClient(
 	}, nil
 }
 
-// Begins a zip deployment and returns a poller to check for status
+// Begins a zip deployment and returns a poller to check for status. The progress, when set, is called with the percent of
+// the zip file uploaded, as each of its chunks is uploaded.
+//
+// Zip deploy takes the zip file in a single request, which can't be resumed: when the upload fails, ex) on a flaky
+// connection, the retry policy of the client uploads the whole zip file again. The SHA-256 checksum of the zip file is
+// sent in the Content-Digest header, for the server to validate the zip file it received. Servers ignoring the header
+// don't validate it; azd itself only validates the zip file didn't change while it was uploaded.
 func (c *ZipDeployClient) BeginDeploy(
 	ctx context.Context,
 	appName string,
-	zipFile io.Reader,
+	zipFile io.ReadSeeker,
+	progress func(percent int),
 ) (*runtime.Poller[*DeployResponse], error) {
-	request, err := c.createDeployRequest(ctx, appName, zipFile)
+	request, body, err := c.createDeployRequest(ctx, appName, zipFile, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +112,9 @@ func (c *ZipDeployClient) BeginDeploy(
 		return nil, runtime.NewResponseError(response)
 	}
 
+	// The bytes are counted once uploaded, not for each retry of the upload
+	metrics.Add(metrics.BytesPushed, body.size, map[string]string{"upload": "zipdeploy"})
+
 	var finalResponse *DeployResponse
 
 	pollerOptions := &runtime.NewPollerOptions[*DeployResponse]{
@@ -115,8 +126,13 @@ func (c *ZipDeployClient) BeginDeploy(
 }
 
 // Deploys the specified application zip to the azure app service and waits for completion
-func (c *ZipDeployClient) Deploy(ctx context.Context, appName string, zipFile io.Reader) (*DeployResponse, error) {
-	poller, err := c.BeginDeploy(ctx, appName, zipFile)
+func (c *ZipDeployClient) Deploy(
+	ctx context.Context,
+	appName string,
+	zipFile io.ReadSeeker,
+	progress func(percent int),
+) (*DeployResponse, error) {
+	poller, err := c.BeginDeploy(ctx, appName, zipFile, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -135,23 +151,33 @@ func (c *ZipDeployClient) Deploy(ctx context.Context, appName string, zipFile io
 func (c *ZipDeployClient) createDeployRequest(
 	ctx context.Context,
 	appName string,
-	zipFile io.Reader,
-) (*policy.Request, error) {
+	zipFile io.ReadSeeker,
+	progress func(percent int),
+) (*policy.Request, *zipDeployBody, error) {
 	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/zipdeploy", appName)
 	req, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("creating deploy request: %w", err)
+		return nil, nil, fmt.Errorf("creating deploy request: %w", err)
+	}
+
+	body, err := newZipDeployBody(zipFile, progress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The body is rewound by the retry policy, for failed uploads to be retried
+	if err := req.SetBody(body, "application/octet-stream"); err != nil {
+		return nil, nil, fmt.Errorf("setting deploy request body: %w", err)
 	}
 
 	rawRequest := req.Raw()
-	rawRequest.Body = io.NopCloser(zipFile)
 	query := rawRequest.URL.Query()
 	query.Set("isAsync", "true")
-	rawRequest.Header.Set("Content-Type", "application/octet-stream")
 	rawRequest.Header.Set("Accept", "application/json")
+	rawRequest.Header.Set("Content-Digest", body.ContentDigest())
 	rawRequest.URL.RawQuery = query.Encode()
 
-	return req, nil
+	return req, body, nil
 }
 
 // Implementation of a Go SDK polling handler for async zip deploy operations
@@ -3,12 +3,16 @@ package azsdk
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
@@ -27,8 +31,8 @@ func TestZipDeploy(t *testing.T) {
 		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
 		require.NoError(t, err)
 
-		zipFile := bytes.NewBuffer([]byte{})
-		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile)
+		zipFile := bytes.NewReader([]byte{})
+		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile, nil)
 		require.NotNil(t, poller)
 		require.NoError(t, err)
 
@@ -52,8 +56,8 @@ func TestZipDeploy(t *testing.T) {
 		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
 		require.NoError(t, err)
 
-		zipFile := bytes.NewBuffer([]byte{})
-		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile)
+		zipFile := bytes.NewReader([]byte{})
+		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile, nil)
 		require.NotNil(t, poller)
 		require.NoError(t, err)
 
@@ -76,11 +80,102 @@ func TestZipDeploy(t *testing.T) {
 		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
 		require.NoError(t, err)
 
-		zipFile := bytes.NewBuffer([]byte{})
-		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile)
+		zipFile := bytes.NewReader([]byte{})
+		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", zipFile, nil)
 		require.Nil(t, poller)
 		require.Error(t, err)
 	})
+
+	t.Run("WithRetriedUpload", func(t *testing.T) {
+		sink := metrics.NewMemorySink()
+		metrics.SetSink(sink)
+		t.Cleanup(func() { metrics.SetSink(nil) })
+
+		mockContext := mocks.NewMockContext(context.Background())
+		registerPollingMocks(mockContext)
+
+		content := bytes.Repeat([]byte("z"), zipDeployChunkSize+1024)
+		checksum := sha256.Sum256(content)
+		uploads := [][]byte{}
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "/api/zipdeploy")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			upload, err := io.ReadAll(request.Body)
+			if err != nil {
+				return nil, err
+			}
+			uploads = append(uploads, upload)
+
+			// The checksum is sent for the server to validate the upload
+			require.Equal(t,
+				"sha-256=:"+base64.StdEncoding.EncodeToString(checksum[:])+":", request.Header.Get("Content-Digest"))
+
+			// The first upload fails like on a flaky connection
+			if len(uploads) == 1 {
+				return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+			}
+
+			response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+			response.Header.Set("Location", "http://myapp.scm.azurewebsites.net/deployments/latest")
+			return response, nil
+		})
+
+		options := NewClientOptionsBuilder().
+			WithTransport(mockContext.HttpClient).
+			BuildArmClientOptions()
+
+		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
+		require.NoError(t, err)
+
+		progress := []int{}
+		poller, err := client.BeginDeploy(*mockContext.Context, "APP_NAME", bytes.NewReader(content), func(percent int) {
+			progress = append(progress, percent)
+		})
+		require.NoError(t, err)
+		require.NotNil(t, poller)
+
+		require.Len(t, uploads, 2)
+		require.Equal(t, content, uploads[0])
+		require.Equal(t, content, uploads[1])
+		require.Equal(t, []int{99, 100, 99, 100}, progress)
+
+		// The bytes of the package are counted once, not for the failed upload
+		require.Equal(t, int64(len(content)), sink.Value(metrics.BytesPushed, map[string]string{"upload": "zipdeploy"}))
+	})
+}
+
+func Test_zipDeployBody(t *testing.T) {
+	t.Run("ChangedWhileUploading", func(t *testing.T) {
+		content := []byte("package")
+		body, err := newZipDeployBody(bytes.NewReader(content), nil)
+		require.NoError(t, err)
+
+		content[0] = 'P'
+		_, err = io.ReadAll(body)
+		require.ErrorIs(t, err, errZipDeployPackageChanged)
+	})
+
+	t.Run("Rewind", func(t *testing.T) {
+		body, err := newZipDeployBody(bytes.NewReader([]byte("package")), nil)
+		require.NoError(t, err)
+
+		size, err := body.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, int64(7), size)
+
+		_, err = io.ReadAll(body)
+		require.NoError(t, err)
+
+		_, err = body.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+
+		upload, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "package", string(upload))
+
+		_, err = body.Seek(3, io.SeekStart)
+		require.Error(t, err)
+	})
 }
 
 func registerConflictMocks(mockContext *mocks.MockContext) {
@@ -71,7 +71,7 @@ type ArmTemplateParameterDefinition struct {
 }
 
 func (d *ArmTemplateParameterDefinition) Secure() bool {
-	return d.Type == "secureObject" || d.Type == "secureString"
+	return strings.EqualFold(d.Type, "secureObject") || strings.EqualFold(d.Type, "secureString")
 }
 
 type AzdMetadata struct {
@@ -0,0 +1,180 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package chaos runs the Chaos Studio experiments of a project against its environment, and reports their outcomes.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// defaultPollInterval is how often the status of experiment executions is polled
+const defaultPollInterval = 15 * time.Second
+
+// Experiment is the outcome of the execution of a Chaos Studio experiment.
+type Experiment struct {
+	Name          string `json:"name"`
+	ResourceGroup string `json:"resourceGroup"`
+	ExecutionId   string `json:"executionId"`
+	// Success, Failed or Cancelled
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"startedAt"`
+	StoppedAt time.Time `json:"stoppedAt"`
+}
+
+// Succeeded reports whether the experiment completed.
+func (e *Experiment) Succeeded() bool {
+	return e.Status == "Success"
+}
+
+// Duration is how long the experiment ran.
+func (e *Experiment) Duration() time.Duration {
+	return e.StoppedAt.Sub(e.StartedAt)
+}
+
+// Result lists the outcomes of the experiments of the project.
+type Result struct {
+	Experiments []*Experiment `json:"experiments"`
+}
+
+// Succeeded reports whether all the experiments completed.
+func (r *Result) Succeeded() bool {
+	for _, experiment := range r.Experiments {
+		if !experiment.Succeeded() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Manager runs the Chaos Studio experiments of a project.
+type Manager struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager project.ResourceManager
+	// How often the status of experiment executions is polled
+	pollInterval time.Duration
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+) *Manager {
+	return &Manager{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		pollInterval:    defaultPollInterval,
+	}
+}
+
+// Run runs the experiments of the project one after the other, waiting for each execution to end before starting the
+// next. progress is called with the name of each experiment as it starts. Returns an error when the experiments
+// don't run in the environment.
+func (m *Manager) Run(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	progress func(experiment string),
+) (*Result, error) {
+	if !projectConfig.Chaos.RunsIn(m.env.GetEnvName()) {
+		return nil, fmt.Errorf("chaos experiments don't run in environment '%s'", m.env.GetEnvName())
+	}
+
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	defaultResourceGroupName, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	result := &Result{
+		Experiments: []*Experiment{},
+	}
+
+	for i, config := range projectConfig.Chaos.Experiments {
+		name, err := config.Name.Envsubst(m.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding name of chaos experiment %d: %w", i+1, err)
+		}
+
+		resourceGroupName, err := config.ResourceGroup.Envsubst(m.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding resource group of chaos experiment '%s': %w", name, err)
+		}
+
+		if resourceGroupName == "" {
+			resourceGroupName = defaultResourceGroupName
+		}
+
+		if progress != nil {
+			progress(name)
+		}
+
+		execution, err := m.runExperiment(ctx, subscriptionId, resourceGroupName, name)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Experiments = append(result.Experiments, &Experiment{
+			Name:          name,
+			ResourceGroup: resourceGroupName,
+			ExecutionId:   execution.Id,
+			Status:        execution.Status,
+			StartedAt:     execution.StartedAt,
+			StoppedAt:     execution.StoppedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// runExperiment starts the experiment, and waits for its execution to end
+func (m *Manager) runExperiment(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	name string,
+) (*azcli.AzCliChaosExperimentExecution, error) {
+	// executions started before this one are ignored, the clock of the service may be slightly off
+	startTime := time.Now().Add(-time.Minute)
+	if err := m.azCli.StartChaosExperiment(ctx, subscriptionId, resourceGroupName, name); err != nil {
+		return nil, err
+	}
+
+	for {
+		executions, err := m.azCli.ListChaosExperimentExecutions(ctx, subscriptionId, resourceGroupName, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var latest *azcli.AzCliChaosExperimentExecution
+		for i, execution := range executions {
+			if execution.StartedAt.After(startTime) && (latest == nil || execution.StartedAt.After(latest.StartedAt)) {
+				latest = &executions[i]
+			}
+		}
+
+		// the execution may not be listed right after the experiment is started
+		if latest != nil && latest.IsTerminal() {
+			return latest, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package chaos
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+// mockExperiments mocks the experiments of the resource groups, each execution ending with the status of the
+// experiment after two polls
+func mockExperiments(mockContext *mocks.MockContext, statuses map[string]string) map[string]int {
+	started := map[string]int{}
+	polls := map[string]int{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/start")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		experiment := strings.TrimSuffix(request.URL.Path, "/start")
+		started[experiment]++
+		return mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/executions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		experiment := strings.TrimSuffix(request.URL.Path, "/executions")
+		polls[experiment]++
+
+		name := experiment[strings.LastIndex(experiment, "/")+1:]
+		properties := map[string]any{
+			"status":    "Running",
+			"startedAt": time.Now().Format(time.RFC3339),
+		}
+		if polls[experiment] > 1 {
+			properties["status"] = statuses[name]
+			properties["stoppedAt"] = time.Now().Format(time.RFC3339)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []map[string]any{
+				{
+					"id":   experiment + "/executions/old",
+					"name": "old",
+					"properties": map[string]any{
+						"status":    "Failed",
+						"startedAt": time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+						"stoppedAt": time.Now().Add(-23 * time.Hour).Format(time.RFC3339),
+					},
+				},
+				{
+					"id":         experiment + "/executions/new",
+					"name":       "new",
+					"properties": properties,
+				},
+			},
+		})
+	})
+
+	return started
+}
+
+func newTestManager(mockContext *mocks.MockContext, envName string) *Manager {
+	env := environment.EphemeralWithValues(envName, map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-" + envName,
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	manager := NewManager(env, azCli, project.NewResourceManager(env, azCli))
+	manager.pollInterval = 0
+
+	return manager
+}
+
+func testProjectConfig() *project.ProjectConfig {
+	return &project.ProjectConfig{
+		Name: "app",
+		Chaos: &project.ChaosConfig{
+			Environments: []string{"dev"},
+			Experiments: []project.ChaosExperimentConfig{
+				{Name: project.NewExpandableString("exp-${AZURE_ENV_NAME}-zone-down")},
+				{
+					Name:          project.NewExpandableString("exp-shared-cpu"),
+					ResourceGroup: project.NewExpandableString("rg-chaos"),
+				},
+			},
+		},
+	}
+}
+
+func Test_Run(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	started := mockExperiments(mockContext, map[string]string{
+		"exp-dev-zone-down": "Success",
+		"exp-shared-cpu":    "Failed",
+	})
+
+	progress := []string{}
+	result, err := newTestManager(mockContext, "dev").Run(
+		*mockContext.Context, testProjectConfig(), func(experiment string) {
+			progress = append(progress, experiment)
+		})
+	require.NoError(t, err)
+	require.False(t, result.Succeeded())
+
+	require.Equal(t, []string{"exp-dev-zone-down", "exp-shared-cpu"}, progress)
+	require.Equal(t, map[string]int{
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Chaos/experiments/exp-dev-zone-down": 1,
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-chaos/providers/Microsoft.Chaos/experiments/exp-shared-cpu":  1,
+	}, started)
+
+	require.Len(t, result.Experiments, 2)
+	require.Equal(t, "rg-dev", result.Experiments[0].ResourceGroup)
+	require.True(t, strings.HasSuffix(result.Experiments[0].ExecutionId, "/executions/new"))
+	require.True(t, result.Experiments[0].Succeeded())
+	require.Equal(t, "Failed", result.Experiments[1].Status)
+}
+
+func Test_RunOtherEnvironment(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	started := mockExperiments(mockContext, map[string]string{})
+
+	_, err := newTestManager(mockContext, "prod").Run(*mockContext.Context, testProjectConfig(), nil)
+	require.ErrorContains(t, err, "don't run in environment 'prod'")
+	require.Empty(t, started)
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/password"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
@@ -39,6 +40,7 @@ func (e *SecretOrRandomPasswordCommandExecutor) Run(
 
 	generatePassword := func() (bool, string, error) {
 		substitute, err := password.Generate(password.PasswordComposition{NumLowercase: 5, NumUppercase: 5, NumDigits: 5})
+		redact.AddSecret(substitute)
 		return err == nil, substitute, err
 	}
 
@@ -80,5 +82,6 @@ func (e *SecretOrRandomPasswordCommandExecutor) Run(
 		return generatePassword() // Do not use empty password secret even if the secret exists
 	}
 
+	redact.AddSecret(secret.Value)
 	return true, secret.Value, nil
 }
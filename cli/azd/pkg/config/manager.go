@@ -4,19 +4,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 )
 
 const cConfigDir = ".azd"
 
 // Config Manager provides the ability to load, parse and save azd configuration files
 type manager struct {
+	fs vfs.Fs
 }
 
 type Manager interface {
@@ -26,7 +27,14 @@ type Manager interface {
 
 // Creates a new Configuration Manager
 func NewManager() Manager {
-	return &manager{}
+	return NewManagerWithFs(vfs.OsFs())
+}
+
+// Creates a new Configuration Manager which loads and saves files of the file system
+func NewManagerWithFs(fs vfs.Fs) Manager {
+	return &manager{
+		fs: fs,
+	}
 }
 
 // Saves the azd configuration to the specified file path
@@ -37,11 +45,11 @@ func (c *manager) Save(config Config, filePath string) error {
 	}
 
 	folderPath := filepath.Dir(filePath)
-	if err := os.MkdirAll(folderPath, osutil.PermissionDirectory); err != nil {
+	if err := c.fs.MkdirAll(folderPath, osutil.PermissionDirectory); err != nil {
 		return fmt.Errorf("failed creating config directory: %w", err)
 	}
 
-	err = os.WriteFile(filePath, configJson, osutil.PermissionFile)
+	err = c.fs.WriteFile(filePath, configJson, osutil.PermissionFile)
 	if err != nil {
 		return fmt.Errorf("failed writing configuration data: %w", err)
 	}
@@ -51,18 +59,11 @@ func (c *manager) Save(config Config, filePath string) error {
 
 // Loads azd configuration from the specified file path
 func (c *manager) Load(filePath string) (Config, error) {
-	file, err := os.Open(filePath)
+	jsonBytes, err := c.fs.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed opening azd configuration file: %w", err)
 	}
 
-	defer file.Close()
-
-	jsonBytes, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed reading azd configuration file")
-	}
-
 	return Parse(jsonBytes)
 }
 
@@ -3,6 +3,9 @@ package containerapps
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
 	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
@@ -11,6 +14,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 // ContainerAppService exposes operations for managing Azure Container Apps
@@ -22,14 +27,70 @@ type ContainerAppService interface {
 		resourceGroup,
 		appName string,
 	) (*ContainerAppIngressConfiguration, error)
-	// Adds and activates a new revision to the specified container app
+	// Adds and activates a new revision to the specified container app. The environment variables in env are set on the
+	// container of the revision, the other environment variables of the container are kept. The secrets are added to the
+	// container app or updated when they drifted, and referenced from their environment variables. The ingress and scale
+	// settings of config, when not nil, are applied to the container app.
 	AddRevision(
 		ctx context.Context,
 		subscriptionId string,
 		resourceGroupName string,
 		appName string,
 		imageName string,
+		env map[string]string,
+		secrets []*Secret,
+		config *AppConfig,
 	) error
+	// Runs a command in a container of the specified container app
+	Exec(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		options ExecOptions,
+	) error
+	// Writes the console logs of the containers of the specified container app
+	Logs(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		options LogsOptions,
+		writer io.Writer,
+	) error
+	// Restarts the latest revision of the specified container app
+	RestartRevision(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+	) error
+	// Deactivates the latest revision of the specified container app when it was added by azd and doesn't receive
+	// traffic, ex) when a deploy was interrupted before the traffic was moved to the revision
+	DeactivateUnservedRevision(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+	) error
+	// Starts an execution of the specified Container Apps job running the command, with the environment variables in env
+	// set on its containers. Returns the name of the execution.
+	StartJob(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		jobName string,
+		command []string,
+		env map[string]string,
+	) (string, error)
+	// Gets the running state of the specified execution of a Container Apps job
+	GetJobExecutionStatus(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		jobName string,
+		executionName string,
+	) (armappcontainers.JobExecutionRunningState, error)
 }
 
 // NewContainerAppService creates a new ContainerAppService
@@ -91,6 +152,9 @@ func (cas *containerAppService) AddRevision(
 	resourceGroupName string,
 	appName string,
 	imageName string,
+	env map[string]string,
+	secrets []*Secret,
+	config *AppConfig,
 ) error {
 	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
 	if err != nil {
@@ -113,10 +177,13 @@ func (cas *containerAppService) AddRevision(
 	revision := revisionResponse.Revision
 	revision.Properties.Template.RevisionSuffix = convert.RefOf(fmt.Sprintf("azd-%d", cas.clock.Now().Unix()))
 	revision.Properties.Template.Containers[0].Image = convert.RefOf(imageName)
+	setContainerEnv(revision.Properties.Template.Containers[0], env)
+	setContainerSecretEnv(revision.Properties.Template.Containers[0], secrets)
 
 	// Update the container app with the new revision
 	containerApp.Properties.Template = revision.Properties.Template
-	containerApp, err = cas.syncSecrets(ctx, subscriptionId, resourceGroupName, appName, containerApp)
+	config.apply(containerApp)
+	containerApp, err = cas.syncSecrets(ctx, subscriptionId, resourceGroupName, appName, containerApp, secrets)
 	if err != nil {
 		return fmt.Errorf("syncing secrets: %w", err)
 	}
@@ -139,40 +206,145 @@ func (cas *containerAppService) AddRevision(
 	return nil
 }
 
-func (cas *containerAppService) syncSecrets(
+// Restarts the latest revision of the specified container app
+func (cas *containerAppService) RestartRevision(
 	ctx context.Context,
 	subscriptionId string,
 	resourceGroupName string,
 	appName string,
-	containerApp *armappcontainers.ContainerApp,
-) (*armappcontainers.ContainerApp, error) {
-	// If the container app doesn't have any secrets, we don't need to do anything
-	if len(containerApp.Properties.Configuration.Secrets) == 0 {
-		return containerApp, nil
+) error {
+	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return fmt.Errorf("getting container app: %w", err)
 	}
 
-	appClient, err := cas.createContainerAppsClient(ctx, subscriptionId)
+	if containerApp.Properties == nil || containerApp.Properties.LatestRevisionName == nil {
+		return fmt.Errorf("container app '%s' has no revision", appName)
+	}
+
+	revisionName := *containerApp.Properties.LatestRevisionName
+	revisionsClient, err := cas.createRevisionsClient(ctx, subscriptionId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Copy the secret configuration from the current version
-	// Secret values are not returned by the API, so we need to get them separately
-	// to ensure the update call succeeds
-	secretsResponse, err := appClient.ListSecrets(ctx, resourceGroupName, appName, nil)
+	_, err = revisionsClient.RestartRevision(ctx, resourceGroupName, appName, revisionName, nil)
 	if err != nil {
-		return nil, fmt.Errorf("listing secrets: %w", err)
+		return fmt.Errorf("restarting revision '%s': %w", revisionName, err)
 	}
 
-	secrets := []*armappcontainers.Secret{}
-	for _, secret := range secretsResponse.SecretsCollection.Value {
-		secrets = append(secrets, &armappcontainers.Secret{
-			Name:  secret.Name,
-			Value: secret.Value,
+	return nil
+}
+
+// Deactivates the latest revision of the specified container app when it was added by azd and doesn't receive traffic
+func (cas *containerAppService) DeactivateUnservedRevision(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+) error {
+	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return fmt.Errorf("getting container app: %w", err)
+	}
+
+	if containerApp.Properties == nil || containerApp.Properties.LatestRevisionName == nil ||
+		containerApp.Properties.Configuration == nil || containerApp.Properties.Configuration.Ingress == nil {
+		return nil
+	}
+
+	revisionName := *containerApp.Properties.LatestRevisionName
+	if !strings.HasPrefix(revisionName, fmt.Sprintf("%s--azd-", appName)) ||
+		servesTraffic(containerApp.Properties.Configuration.Ingress, revisionName) {
+		return nil
+	}
+
+	revisionsClient, err := cas.createRevisionsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("deactivating revision '%s' of container app '%s', it doesn't receive traffic", revisionName, appName)
+	_, err = revisionsClient.DeactivateRevision(ctx, resourceGroupName, appName, revisionName, nil)
+	if err != nil {
+		return fmt.Errorf("deactivating revision '%s': %w", revisionName, err)
+	}
+
+	return nil
+}
+
+// servesTraffic reports whether the ingress routes traffic to the revision, which is the latest revision
+func servesTraffic(ingress *armappcontainers.Ingress, revisionName string) bool {
+	for _, weight := range ingress.Traffic {
+		if convert.ToValueWithDefault(weight.Weight, 0) == 0 {
+			continue
+		}
+
+		if convert.ToValueWithDefault(weight.LatestRevision, false) ||
+			convert.ToValueWithDefault(weight.RevisionName, "") == revisionName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setContainerEnv sets the environment variables of the container, replacing the values of the variables it already has
+func setContainerEnv(container *armappcontainers.Container, env map[string]string) {
+	names := maps.Keys(env)
+	slices.Sort(names)
+
+	for _, name := range names {
+		idx := slices.IndexFunc(container.Env, func(v *armappcontainers.EnvironmentVar) bool {
+			return v.Name != nil && *v.Name == name
 		})
+
+		envVar := &armappcontainers.EnvironmentVar{Name: convert.RefOf(name), Value: convert.RefOf(env[name])}
+		if idx >= 0 {
+			container.Env[idx] = envVar
+		} else {
+			container.Env = append(container.Env, envVar)
+		}
+	}
+}
+
+func (cas *containerAppService) syncSecrets(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	containerApp *armappcontainers.ContainerApp,
+	secrets []*Secret,
+) (*armappcontainers.ContainerApp, error) {
+	// If the container app doesn't have any secrets and none are declared, we don't need to do anything
+	if len(containerApp.Properties.Configuration.Secrets) == 0 && len(secrets) == 0 {
+		return containerApp, nil
+	}
+
+	current := []*armappcontainers.ContainerAppSecret{}
+	if len(containerApp.Properties.Configuration.Secrets) > 0 {
+		appClient, err := cas.createContainerAppsClient(ctx, subscriptionId)
+		if err != nil {
+			return nil, err
+		}
+
+		// Copy the secret configuration from the current version
+		// Secret values are not returned by the API, so we need to get them separately
+		// to ensure the update call succeeds
+		secretsResponse, err := appClient.ListSecrets(ctx, resourceGroupName, appName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing secrets: %w", err)
+		}
+
+		current = secretsResponse.SecretsCollection.Value
+	}
+
+	merged, drifted := mergeSecrets(current, secrets)
+	if len(drifted) > 0 {
+		log.Printf("updating drifted secrets of container app '%s': %s", appName, strings.Join(drifted, ", "))
 	}
 
-	containerApp.Properties.Configuration.Secrets = secrets
+	containerApp.Properties.Configuration.Secrets = merged
 
 	return containerApp, nil
 }
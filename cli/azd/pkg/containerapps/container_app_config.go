@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"strconv"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// AppConfig is the ingress and scale configuration applied to a container app on each deploy. The settings which
+// aren't set are left as configured by the infrastructure.
+type AppConfig struct {
+	Ingress *Ingress
+	Scale   *Scale
+}
+
+// Ingress is the ingress configuration of a container app. Setting it enables the ingress of the container app.
+type Ingress struct {
+	// Whether the container app is reachable from outside of the container apps environment
+	External *bool
+	// The port of the container the ingress forwards traffic to, zero to keep the current port
+	TargetPort int
+	// The transport protocol, ex) http2, empty to keep the current protocol
+	Transport string
+	// The CORS policy, nil to keep the current policy
+	Cors *Cors
+}
+
+// Cors is the CORS policy of the ingress of a container app
+type Cors struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// The number of seconds browsers cache the result of preflight requests, zero for the default
+	MaxAge int
+}
+
+// Scale is the scale configuration of the revisions of a container app
+type Scale struct {
+	MinReplicas *int
+	MaxReplicas *int
+	// The scale rules replacing the rules of the container app, none to keep the current rules
+	Rules []*ScaleRule
+}
+
+// ScaleRule is either an HTTP scale rule, when HttpConcurrency is set, or a custom KEDA scale rule of Type
+type ScaleRule struct {
+	Name string
+	// The number of concurrent requests per replica the HTTP scale rule scales out at
+	HttpConcurrency int
+	// The type of the KEDA scaler, ex) azure-servicebus
+	Type string
+	// The metadata of the KEDA scaler, ex) queueName
+	Metadata map[string]string
+	// The secrets the trigger parameters of the KEDA scaler are read from, keyed by trigger parameter
+	Auth map[string]string
+}
+
+// apply sets the configuration on the container app
+func (c *AppConfig) apply(containerApp *armappcontainers.ContainerApp) {
+	if c == nil {
+		return
+	}
+
+	if c.Ingress != nil {
+		if containerApp.Properties.Configuration.Ingress == nil {
+			containerApp.Properties.Configuration.Ingress = &armappcontainers.Ingress{}
+		}
+		c.Ingress.apply(containerApp.Properties.Configuration.Ingress)
+	}
+
+	if c.Scale != nil {
+		if containerApp.Properties.Template.Scale == nil {
+			containerApp.Properties.Template.Scale = &armappcontainers.Scale{}
+		}
+		c.Scale.apply(containerApp.Properties.Template.Scale)
+	}
+}
+
+func (i *Ingress) apply(ingress *armappcontainers.Ingress) {
+	if i.External != nil {
+		ingress.External = convert.RefOf(*i.External)
+	}
+
+	if i.TargetPort != 0 {
+		ingress.TargetPort = convert.RefOf(int32(i.TargetPort))
+	}
+
+	if i.Transport != "" {
+		ingress.Transport = convert.RefOf(armappcontainers.IngressTransportMethod(i.Transport))
+	}
+
+	if i.Cors != nil {
+		ingress.CorsPolicy = &armappcontainers.CorsPolicy{
+			AllowedOrigins:   stringRefs(i.Cors.AllowedOrigins),
+			AllowedMethods:   stringRefs(i.Cors.AllowedMethods),
+			AllowedHeaders:   stringRefs(i.Cors.AllowedHeaders),
+			ExposeHeaders:    stringRefs(i.Cors.ExposeHeaders),
+			AllowCredentials: convert.RefOf(i.Cors.AllowCredentials),
+		}
+
+		if i.Cors.MaxAge != 0 {
+			ingress.CorsPolicy.MaxAge = convert.RefOf(int32(i.Cors.MaxAge))
+		}
+	}
+}
+
+func (s *Scale) apply(scale *armappcontainers.Scale) {
+	if s.MinReplicas != nil {
+		scale.MinReplicas = convert.RefOf(int32(*s.MinReplicas))
+	}
+
+	if s.MaxReplicas != nil {
+		scale.MaxReplicas = convert.RefOf(int32(*s.MaxReplicas))
+	}
+
+	if len(s.Rules) == 0 {
+		return
+	}
+
+	scale.Rules = []*armappcontainers.ScaleRule{}
+	for _, rule := range s.Rules {
+		scale.Rules = append(scale.Rules, rule.toArm())
+	}
+}
+
+func (r *ScaleRule) toArm() *armappcontainers.ScaleRule {
+	if r.HttpConcurrency > 0 {
+		return &armappcontainers.ScaleRule{
+			Name: convert.RefOf(r.Name),
+			HTTP: &armappcontainers.HTTPScaleRule{
+				Metadata: map[string]*string{
+					"concurrentRequests": convert.RefOf(strconv.Itoa(r.HttpConcurrency)),
+				},
+				Auth: r.auth(),
+			},
+		}
+	}
+
+	metadata := map[string]*string{}
+	for key, value := range r.Metadata {
+		metadata[key] = convert.RefOf(value)
+	}
+
+	return &armappcontainers.ScaleRule{
+		Name: convert.RefOf(r.Name),
+		Custom: &armappcontainers.CustomScaleRule{
+			Type:     convert.RefOf(r.Type),
+			Metadata: metadata,
+			Auth:     r.auth(),
+		},
+	}
+}
+
+func (r *ScaleRule) auth() []*armappcontainers.ScaleRuleAuth {
+	parameters := maps.Keys(r.Auth)
+	slices.Sort(parameters)
+
+	auth := []*armappcontainers.ScaleRuleAuth{}
+	for _, parameter := range parameters {
+		auth = append(auth, &armappcontainers.ScaleRuleAuth{
+			TriggerParameter: convert.RefOf(parameter),
+			SecretRef:        convert.RefOf(r.Auth[parameter]),
+		})
+	}
+
+	return auth
+}
+
+func stringRefs(values []string) []*string {
+	refs := []*string{}
+	for _, value := range values {
+		refs = append(refs, convert.RefOf(value))
+	}
+
+	return refs
+}
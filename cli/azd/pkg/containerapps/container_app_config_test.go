@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestContainerApp() *armappcontainers.ContainerApp {
+	return &armappcontainers.ContainerApp{
+		Properties: &armappcontainers.ContainerAppProperties{
+			Configuration: &armappcontainers.Configuration{},
+			Template: &armappcontainers.Template{
+				Scale: &armappcontainers.Scale{
+					MinReplicas: convert.RefOf[int32](0),
+					MaxReplicas: convert.RefOf[int32](10),
+					Rules: []*armappcontainers.ScaleRule{
+						{Name: convert.RefOf("infra-rule")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_AppConfig_apply(t *testing.T) {
+	t.Run("Nil", func(t *testing.T) {
+		containerApp := newTestContainerApp()
+		var config *AppConfig
+		config.apply(containerApp)
+
+		require.Equal(t, newTestContainerApp(), containerApp)
+	})
+
+	t.Run("Ingress", func(t *testing.T) {
+		containerApp := newTestContainerApp()
+		config := &AppConfig{
+			Ingress: &Ingress{
+				External:   convert.RefOf(false),
+				TargetPort: 8080,
+				Transport:  "http2",
+				Cors: &Cors{
+					AllowedOrigins: []string{"https://contoso.com"},
+					AllowedMethods: []string{"GET", "POST"},
+					MaxAge:         300,
+				},
+			},
+		}
+		config.apply(containerApp)
+
+		ingress := containerApp.Properties.Configuration.Ingress
+		require.NotNil(t, ingress)
+		require.False(t, *ingress.External)
+		require.Equal(t, int32(8080), *ingress.TargetPort)
+		require.Equal(t, armappcontainers.IngressTransportMethodHTTP2, *ingress.Transport)
+		require.Equal(t, "https://contoso.com", *ingress.CorsPolicy.AllowedOrigins[0])
+		require.Len(t, ingress.CorsPolicy.AllowedMethods, 2)
+		require.Equal(t, int32(300), *ingress.CorsPolicy.MaxAge)
+		// the scale settings aren't declared, so they are kept
+		require.Equal(t, newTestContainerApp().Properties.Template.Scale, containerApp.Properties.Template.Scale)
+	})
+
+	t.Run("Scale", func(t *testing.T) {
+		containerApp := newTestContainerApp()
+		config := &AppConfig{
+			Scale: &Scale{
+				MinReplicas: convert.RefOf(1),
+				Rules: []*ScaleRule{
+					{Name: "http", HttpConcurrency: 50},
+					{
+						Name:     "queue",
+						Type:     "azure-servicebus",
+						Metadata: map[string]string{"queueName": "orders"},
+						Auth:     map[string]string{"connection": "sb-connection"},
+					},
+				},
+			},
+		}
+		config.apply(containerApp)
+
+		scale := containerApp.Properties.Template.Scale
+		require.Equal(t, int32(1), *scale.MinReplicas)
+		require.Equal(t, int32(10), *scale.MaxReplicas)
+		require.Len(t, scale.Rules, 2)
+		require.Equal(t, "50", *scale.Rules[0].HTTP.Metadata["concurrentRequests"])
+		require.Equal(t, "azure-servicebus", *scale.Rules[1].Custom.Type)
+		require.Equal(t, "orders", *scale.Rules[1].Custom.Metadata["queueName"])
+		require.Equal(t, "connection", *scale.Rules[1].Custom.Auth[0].TriggerParameter)
+		require.Equal(t, "sb-connection", *scale.Rules[1].Custom.Auth[0].SecretRef)
+		require.Nil(t, containerApp.Properties.Configuration.Ingress)
+	})
+}
@@ -0,0 +1,297 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"golang.org/x/net/websocket"
+)
+
+// The channels multiplexed over the exec websocket of a replica. Frames sent to the replica are prefixed with a zero
+// byte followed by the channel, frames received from the replica are prefixed with the channel.
+const (
+	execStdinChannel  byte = 0
+	execStdoutChannel byte = 1
+	execStderrChannel byte = 2
+	execStatusChannel byte = 3
+	execResizeChannel byte = 4
+)
+
+// ExecOptions configures a command run in a container of a container app
+type ExecOptions struct {
+	// The name of the container, defaults to the first container of the replica
+	Container string
+	// The command, ex) /bin/sh
+	Command string
+	// The size of the terminal of the command, when the command runs in a terminal
+	Width  int
+	Height int
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec runs a command in a container of a replica of the latest ready revision of the container app, streaming its
+// input and output until the command exits or the context is cancelled.
+func (cas *containerAppService) Exec(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	options ExecOptions,
+) error {
+	endpoint, token, err := cas.execEndpoint(ctx, subscriptionId, resourceGroupName, appName, options)
+	if err != nil {
+		return err
+	}
+
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("parsing exec endpoint: %w", err)
+	}
+
+	config, err := websocket.NewConfig(endpoint, fmt.Sprintf("https://%s", endpointUrl.Host))
+	if err != nil {
+		return fmt.Errorf("creating exec connection: %w", err)
+	}
+	config.Header = http.Header{}
+	config.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return fmt.Errorf("connecting to container app '%s': %w", appName, err)
+	}
+	defer conn.Close()
+
+	return streamExec(ctx, conn, options)
+}
+
+// execEndpoint returns the exec endpoint of the container of a replica, including the command, and the token
+// authorizing the connection to the endpoint
+func (cas *containerAppService) execEndpoint(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	options ExecOptions,
+) (string, string, error) {
+	revisionName, containers, err := cas.replicaContainers(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return "", "", err
+	}
+
+	var container *armappcontainers.ReplicaContainer
+	for _, replicaContainer := range containers {
+		if replicaContainer.ExecEndpoint != nil &&
+			(options.Container == "" || replicaContainer.Name != nil && *replicaContainer.Name == options.Container) {
+			container = replicaContainer
+			break
+		}
+	}
+
+	if container == nil {
+		if options.Container != "" {
+			return "", "", fmt.Errorf("no running replica of revision '%s' has a container '%s'",
+				revisionName, options.Container)
+		}
+
+		return "", "", fmt.Errorf("revision '%s' has no running replica", revisionName)
+	}
+
+	token, err := cas.getAuthToken(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return "", "", err
+	}
+
+	endpointUrl, err := url.Parse(*container.ExecEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing exec endpoint: %w", err)
+	}
+
+	query := endpointUrl.Query()
+	query.Set("command", options.Command)
+	endpointUrl.RawQuery = query.Encode()
+
+	return endpointUrl.String(), token, nil
+}
+
+// replicaContainers returns the name of the latest ready revision of the container app and the containers of its
+// replicas
+func (cas *containerAppService) replicaContainers(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+) (string, []*armappcontainers.ReplicaContainer, error) {
+	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if containerApp.Properties == nil || containerApp.Properties.LatestReadyRevisionName == nil {
+		return "", nil, fmt.Errorf("container app '%s' has no ready revision", appName)
+	}
+	revisionName := *containerApp.Properties.LatestReadyRevisionName
+
+	replicasClient, err := cas.createReplicasClient(ctx, subscriptionId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	replicas, err := replicasClient.ListReplicas(ctx, resourceGroupName, appName, revisionName, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("listing replicas of revision '%s': %w", revisionName, err)
+	}
+
+	containers := []*armappcontainers.ReplicaContainer{}
+	for _, replica := range replicas.Value {
+		if replica.Properties == nil {
+			continue
+		}
+
+		log.Printf("found replica '%s' of revision '%s'", convert.ToValueWithDefault(replica.Name, ""), revisionName)
+		containers = append(containers, replica.Properties.Containers...)
+	}
+
+	return revisionName, containers, nil
+}
+
+// getAuthToken returns the token authorizing the connections to the exec and log stream endpoints of the replicas
+func (cas *containerAppService) getAuthToken(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+) (string, error) {
+	appClient, err := cas.createContainerAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	tokenResponse, err := appClient.GetAuthToken(ctx, resourceGroupName, appName, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting auth token of container app: %w", err)
+	}
+
+	if tokenResponse.Properties == nil || tokenResponse.Properties.Token == nil {
+		return "", errors.New("getting auth token of container app: empty token")
+	}
+
+	return *tokenResponse.Properties.Token, nil
+}
+
+// streamExec sends the input to the command and writes its output until the replica closes the connection
+func streamExec(ctx context.Context, conn *websocket.Conn, options ExecOptions) error {
+	if options.Width > 0 && options.Height > 0 {
+		size, err := json.Marshal(map[string]int{"Width": options.Width, "Height": options.Height})
+		if err != nil {
+			return err
+		}
+
+		if err := sendExecFrame(conn, execResizeChannel, size); err != nil {
+			return fmt.Errorf("sending terminal size: %w", err)
+		}
+	}
+
+	// Closing the connection unblocks the receive loop when the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	// The input is read until it is closed or the connection fails. A read blocked on the console input outlives the
+	// command, which is fine since azd exits once the command exits.
+	if options.Stdin != nil {
+		go func() {
+			buffer := make([]byte, 4096)
+			for {
+				n, err := options.Stdin.Read(buffer)
+				if n > 0 {
+					if sendErr := sendExecFrame(conn, execStdinChannel, buffer[:n]); sendErr != nil {
+						return
+					}
+				}
+
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(conn, &frame); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("receiving command output: %w", err)
+		}
+
+		if len(frame) == 0 {
+			continue
+		}
+
+		var writer io.Writer
+		switch frame[0] {
+		case execStdoutChannel:
+			writer = options.Stdout
+		case execStderrChannel, execStatusChannel:
+			writer = options.Stderr
+		}
+
+		if writer != nil {
+			if _, err := writer.Write(frame[1:]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sendExecFrame(conn *websocket.Conn, channel byte, data []byte) error {
+	frame := make([]byte, 0, len(data)+2)
+	frame = append(frame, 0, channel)
+	frame = append(frame, data...)
+
+	return websocket.Message.Send(conn, frame)
+}
+
+func (cas *containerAppService) createReplicasClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armappcontainers.ContainerAppsRevisionReplicasClient, error) {
+	credential, err := cas.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, cas.httpClient, cas.userAgent).BuildArmClientOptions()
+	client, err := armappcontainers.NewContainerAppsRevisionReplicasClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating ContainerApps client: %w", err)
+	}
+
+	return client, nil
+}
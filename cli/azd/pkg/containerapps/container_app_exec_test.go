@@ -0,0 +1,114 @@
+package containerapps
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func Test_ContainerApp_ExecEndpoint(t *testing.T) {
+	subscriptionId := "SUBSCRIPTION_ID"
+	resourceGroup := "RESOURCE_GROUP"
+	appName := "APP_NAME"
+	revisionName := "REVISION_NAME"
+
+	containerApp := &armappcontainers.ContainerApp{
+		Name: &appName,
+		Properties: &armappcontainers.ContainerAppProperties{
+			LatestReadyRevisionName: &revisionName,
+		},
+	}
+
+	replicas := &armappcontainers.ReplicaCollection{
+		Value: []*armappcontainers.Replica{
+			{
+				Name: convert.RefOf("REPLICA_NAME"),
+				Properties: &armappcontainers.ReplicaProperties{
+					Containers: []*armappcontainers.ReplicaContainer{
+						{
+							Name:         convert.RefOf("sidecar"),
+							ExecEndpoint: convert.RefOf("wss://exec.azurecontainerapps.dev/sidecar/exec"),
+						},
+						{
+							Name:         convert.RefOf("main"),
+							ExecEndpoint: convert.RefOf("wss://exec.azurecontainerapps.dev/main/exec"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockazsdk.MockContainerAppGet(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+	mockazsdk.MockContainerAppReplicasList(mockContext, subscriptionId, resourceGroup, appName, revisionName, replicas)
+	mockazsdk.MockContainerAppAuthToken(mockContext, subscriptionId, resourceGroup, appName, "TOKEN")
+
+	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
+
+	t.Run("FirstContainer", func(t *testing.T) {
+		endpoint, token, err := cas.(*containerAppService).execEndpoint(
+			*mockContext.Context, subscriptionId, resourceGroup, appName, ExecOptions{Command: "/bin/sh"})
+		require.NoError(t, err)
+		require.Equal(t, "TOKEN", token)
+
+		endpointUrl, err := url.Parse(endpoint)
+		require.NoError(t, err)
+		require.Equal(t, "/sidecar/exec", endpointUrl.Path)
+		require.Equal(t, "/bin/sh", endpointUrl.Query().Get("command"))
+	})
+
+	t.Run("NamedContainer", func(t *testing.T) {
+		endpoint, _, err := cas.(*containerAppService).execEndpoint(
+			*mockContext.Context, subscriptionId, resourceGroup, appName, ExecOptions{Container: "main", Command: "ls"})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(endpoint, "wss://exec.azurecontainerapps.dev/main/exec?"))
+	})
+
+	t.Run("MissingContainer", func(t *testing.T) {
+		_, _, err := cas.(*containerAppService).execEndpoint(
+			*mockContext.Context, subscriptionId, resourceGroup, appName, ExecOptions{Container: "other", Command: "ls"})
+		require.ErrorContains(t, err, "has a container 'other'")
+	})
+}
+
+func Test_ContainerApp_StreamExec(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		var frame []byte
+		if err := websocket.Message.Receive(conn, &frame); err != nil {
+			return
+		}
+
+		// Echo the input back on the output channel, then report on the error channel
+		_ = websocket.Message.Send(conn, append([]byte{execStdoutChannel}, frame[2:]...))
+		_ = websocket.Message.Send(conn, append([]byte{execStderrChannel}, []byte("warning")...))
+		conn.Close()
+	}))
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1), "", "http://localhost/")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err = streamExec(context.Background(), conn, ExecOptions{
+		Stdin:  strings.NewReader("hello"),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello", stdout.String())
+	require.Equal(t, "warning", stderr.String())
+}
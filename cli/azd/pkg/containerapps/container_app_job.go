@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Starts an execution of the specified Container Apps job, running the command in the containers of the job instead of
+// their own. The environment variables in env are set on the containers, the other environment variables of the
+// containers are kept. Returns the name of the execution.
+func (cas *containerAppService) StartJob(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	jobName string,
+	command []string,
+	env map[string]string,
+) (string, error) {
+	jobsClient, err := cas.createJobsClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	job, err := jobsClient.Get(ctx, resourceGroupName, jobName, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting job '%s': %w", jobName, err)
+	}
+
+	if job.Properties == nil || job.Properties.Template == nil || len(job.Properties.Template.Containers) == 0 {
+		return "", fmt.Errorf("job '%s' has no container", jobName)
+	}
+
+	envNames := maps.Keys(env)
+	slices.Sort(envNames)
+
+	// The template of an execution replaces the containers of the job, the image and resources of the containers are
+	// copied for the execution to run them
+	template := armappcontainers.JobExecutionTemplate{}
+	for _, container := range job.Properties.Template.Containers {
+		executionContainer := &armappcontainers.JobExecutionContainer{
+			Name:      container.Name,
+			Image:     container.Image,
+			Resources: container.Resources,
+			Command:   to.SliceOfPtrs(command...),
+		}
+
+		for _, envVar := range container.Env {
+			if envVar.Name == nil {
+				continue
+			}
+
+			if _, has := env[*envVar.Name]; !has {
+				executionContainer.Env = append(executionContainer.Env, envVar)
+			}
+		}
+
+		for _, name := range envNames {
+			executionContainer.Env = append(executionContainer.Env, &armappcontainers.EnvironmentVar{
+				Name:  convert.RefOf(name),
+				Value: convert.RefOf(env[name]),
+			})
+		}
+
+		template.Containers = append(template.Containers, executionContainer)
+	}
+
+	poller, err := jobsClient.BeginStart(ctx, resourceGroupName, jobName, template, nil)
+	if err != nil {
+		return "", fmt.Errorf("starting job '%s': %w", jobName, err)
+	}
+
+	execution, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("polling for job start completion: %w", err)
+	}
+
+	if execution.Name == nil {
+		return "", fmt.Errorf("the execution of job '%s' has no name", jobName)
+	}
+
+	return *execution.Name, nil
+}
+
+// Gets the running state of the specified execution of a Container Apps job, ex) Running or Succeeded
+func (cas *containerAppService) GetJobExecutionStatus(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	jobName string,
+	executionName string,
+) (armappcontainers.JobExecutionRunningState, error) {
+	executionsClient, err := cas.createJobsExecutionsClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	pager := executionsClient.NewListPager(resourceGroupName, jobName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("listing executions of job '%s': %w", jobName, err)
+		}
+
+		for _, execution := range page.Value {
+			if execution.Name != nil && *execution.Name == executionName {
+				return convert.ToValueWithDefault(execution.Status, armappcontainers.JobExecutionRunningStateUnknown), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("job '%s' has no execution '%s'", jobName, executionName)
+}
+
+func (cas *containerAppService) createJobsClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armappcontainers.JobsClient, error) {
+	credential, err := cas.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, cas.httpClient, cas.userAgent).BuildArmClientOptions()
+	client, err := armappcontainers.NewJobsClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating Jobs client: %w", err)
+	}
+
+	return client, nil
+}
+
+func (cas *containerAppService) createJobsExecutionsClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armappcontainers.JobsExecutionsClient, error) {
+	credential, err := cas.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := azsdk.DefaultClientOptionsBuilder(ctx, cas.httpClient, cas.userAgent).BuildArmClientOptions()
+	client, err := armappcontainers.NewJobsExecutionsClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating JobsExecutions client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,112 @@
+package containerapps
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContainerApp_StartJob(t *testing.T) {
+	job := armappcontainers.Job{
+		Properties: &armappcontainers.JobProperties{
+			Template: &armappcontainers.JobTemplate{
+				Containers: []*armappcontainers.Container{
+					{
+						Name:  convert.RefOf("runner"),
+						Image: convert.RefOf("mcr.microsoft.com/azure-dev-cli-apps:latest"),
+						Env: []*armappcontainers.EnvironmentVar{
+							{Name: convert.RefOf("AZURE_CLIENT_ID"), Value: convert.RefOf("CLIENT_ID")},
+							{Name: convert.RefOf("AZURE_ENV_NAME"), Value: convert.RefOf("dev")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/jobs/JOB_NAME")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, job)
+	})
+
+	var template armappcontainers.JobExecutionTemplate
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/jobs/JOB_NAME/start")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(body, &template); err != nil {
+			return nil, err
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappcontainers.JobExecutionBase{
+			Name: convert.RefOf("JOB_NAME-1a2b3c"),
+		})
+	})
+
+	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
+	executionName, err := cas.StartJob(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"JOB_NAME",
+		[]string{"azd", "version"},
+		map[string]string{"AZURE_ENV_NAME": "prod", "AZD_DEBUG": "true"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "JOB_NAME-1a2b3c", executionName)
+
+	require.Len(t, template.Containers, 1)
+	container := template.Containers[0]
+	require.Equal(t, "runner", *container.Name)
+	require.Equal(t, "mcr.microsoft.com/azure-dev-cli-apps:latest", *container.Image)
+	require.Equal(t, []*string{convert.RefOf("azd"), convert.RefOf("version")}, container.Command)
+
+	env := map[string]string{}
+	for _, envVar := range container.Env {
+		env[*envVar.Name] = *envVar.Value
+	}
+	require.Equal(t, map[string]string{
+		"AZURE_CLIENT_ID": "CLIENT_ID",
+		"AZURE_ENV_NAME":  "prod",
+		"AZD_DEBUG":       "true",
+	}, env)
+}
+
+func Test_ContainerApp_GetJobExecutionStatus(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/jobs/JOB_NAME/executions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappcontainers.ContainerAppJobExecutions{
+			Value: []*armappcontainers.JobExecution{
+				{Name: convert.RefOf("JOB_NAME-000000"), Status: convert.RefOf(armappcontainers.JobExecutionRunningStateFailed)},
+				{Name: convert.RefOf("JOB_NAME-1a2b3c"), Status: convert.RefOf(armappcontainers.JobExecutionRunningStateRunning)},
+			},
+		})
+	})
+
+	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
+	status, err := cas.GetJobExecutionStatus(
+		*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "JOB_NAME", "JOB_NAME-1a2b3c")
+	require.NoError(t, err)
+	require.Equal(t, armappcontainers.JobExecutionRunningStateRunning, status)
+
+	_, err = cas.GetJobExecutionStatus(
+		*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "JOB_NAME", "JOB_NAME-ffffff")
+	require.ErrorContains(t, err, "job 'JOB_NAME' has no execution 'JOB_NAME-ffffff'")
+}
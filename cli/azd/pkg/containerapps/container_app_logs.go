@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+)
+
+// The maximum number of past lines returned by the log stream of a container
+const logStreamTailLines = 300
+
+// LogsOptions configures the console logs written for a container app
+type LogsOptions struct {
+	// Only the logs newer than the duration are written, the most recent logs when zero
+	Since time.Duration
+	// Whether the logs are streamed until the context is cancelled
+	Follow bool
+}
+
+// logStreamEntry is a line of the log stream of a container when requesting the json output
+type logStreamEntry struct {
+	TimeStamp string `json:"TimeStamp"`
+	Log       string `json:"Log"`
+}
+
+// Logs writes the console logs of the containers of the replicas of the latest ready revision of the container app,
+// prefixed with the container. Each line is written with a single call to the writer.
+func (cas *containerAppService) Logs(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	revisionName, containers, err := cas.replicaContainers(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return err
+	}
+
+	if len(containers) == 0 {
+		return fmt.Errorf("revision '%s' has no running replica", revisionName)
+	}
+
+	token, err := cas.getAuthToken(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var streamErrs []error
+	containerWriter := &syncWriter{mu: &mu, writer: writer}
+
+	for _, container := range containers {
+		if container.LogStreamEndpoint == nil {
+			continue
+		}
+
+		name := convert.ToValueWithDefault(container.Name, "")
+		endpoint := *container.LogStreamEndpoint
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := cas.streamContainerLogs(ctx, endpoint, token, name, options, containerWriter); err != nil {
+				mu.Lock()
+				streamErrs = append(streamErrs, fmt.Errorf("streaming logs of container '%s': %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(streamErrs) > 0 {
+		return streamErrs[0]
+	}
+
+	return nil
+}
+
+// streamContainerLogs writes the lines of the log stream of a container until the stream ends or the context is
+// cancelled
+func (cas *containerAppService) streamContainerLogs(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	containerName string,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("parsing log stream endpoint: %w", err)
+	}
+
+	query := endpointUrl.Query()
+	query.Set("tailLines", fmt.Sprint(logStreamTailLines))
+	query.Set("follow", fmt.Sprint(options.Follow))
+	query.Set("output", "json")
+	endpointUrl.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	response, err := cas.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return err
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return runtime.NewResponseError(response)
+	}
+
+	var since time.Time
+	if options.Since > 0 {
+		since = cas.clock.Now().Add(-options.Since)
+	}
+
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var entry logStreamEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			// Lines which aren't log entries, ex) connection messages, are written as is
+			entry = logStreamEntry{Log: line}
+		}
+
+		if !since.IsZero() {
+			if timestamp, err := time.Parse(time.RFC3339Nano, entry.TimeStamp); err == nil && timestamp.Before(since) {
+				continue
+			}
+		}
+
+		if _, err := fmt.Fprintf(writer, "[%s] %s\n", containerName, entry.Log); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// syncWriter serializes the writes of the log streams of several containers
+type syncWriter struct {
+	mu     *sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.writer.Write(p)
+}
@@ -0,0 +1,94 @@
+package containerapps
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContainerApp_Logs(t *testing.T) {
+	subscriptionId := "SUBSCRIPTION_ID"
+	resourceGroup := "RESOURCE_GROUP"
+	appName := "APP_NAME"
+	revisionName := "REVISION_NAME"
+
+	containerApp := &armappcontainers.ContainerApp{
+		Name: &appName,
+		Properties: &armappcontainers.ContainerAppProperties{
+			LatestReadyRevisionName: &revisionName,
+		},
+	}
+
+	replicas := &armappcontainers.ReplicaCollection{
+		Value: []*armappcontainers.Replica{
+			{
+				Name: convert.RefOf("REPLICA_NAME"),
+				Properties: &armappcontainers.ReplicaProperties{
+					Containers: []*armappcontainers.ReplicaContainer{
+						{
+							Name:              convert.RefOf("main"),
+							LogStreamEndpoint: convert.RefOf("https://eastus2.azurecontainerapps.dev/main/logstream"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockazsdk.MockContainerAppGet(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+	mockazsdk.MockContainerAppReplicasList(mockContext, subscriptionId, resourceGroup, appName, revisionName, replicas)
+	mockazsdk.MockContainerAppAuthToken(mockContext, subscriptionId, resourceGroup, appName, "TOKEN")
+
+	var logStreamRequest *http.Request
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "eastus2.azurecontainerapps.dev"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		logStreamRequest = request
+
+		body := strings.Join([]string{
+			`{"TimeStamp":"2023-05-01T09:00:00.000Z","Log":"old"}`,
+			`{"TimeStamp":"2023-05-01T09:55:00.000Z","Log":"recent"}`,
+			`Connected to the log stream`,
+		}, "\n")
+
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC))
+
+	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, mockClock)
+
+	output := &bytes.Buffer{}
+	err := cas.Logs(
+		*mockContext.Context,
+		subscriptionId,
+		resourceGroup,
+		appName,
+		LogsOptions{Since: 15 * time.Minute},
+		output,
+	)
+	require.NoError(t, err)
+	require.Equal(t, "[main] recent\n[main] Connected to the log stream\n", output.String())
+
+	require.Equal(t, "Bearer TOKEN", logStreamRequest.Header.Get("Authorization"))
+	require.Equal(t, "false", logStreamRequest.URL.Query().Get("follow"))
+	require.Equal(t, "json", logStreamRequest.URL.Query().Get("output"))
+}
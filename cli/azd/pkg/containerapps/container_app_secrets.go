@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"golang.org/x/exp/slices"
+)
+
+// SystemIdentity is the identity of a Key Vault reference resolved with the system assigned identity of the container app
+const SystemIdentity = "System"
+
+// Secret is a secret of a container app, with either a value or a reference to a Key Vault secret.
+type Secret struct {
+	// The name of the secret, ex) db-password
+	Name string
+	// The value of the secret, empty for Key Vault references
+	Value string
+	// The URL of the Key Vault secret, ex) https://<vault>.vault.azure.net/secrets/<name>
+	KeyVaultUrl string
+	// The identity the container app reads the Key Vault secret with, [SystemIdentity] or the resource id of a user
+	// assigned identity
+	Identity string
+	// The environment variable of the container referencing the secret, if any
+	EnvVar string
+}
+
+// differs reports whether the secret of the container app differs from the secret
+func (s *Secret) differs(current *armappcontainers.ContainerAppSecret) bool {
+	if s.KeyVaultUrl != "" {
+		return s.KeyVaultUrl != convert.ToValueWithDefault(current.KeyVaultURL, "") ||
+			!strings.EqualFold(s.Identity, convert.ToValueWithDefault(current.Identity, ""))
+	}
+
+	return convert.ToValueWithDefault(current.KeyVaultURL, "") != "" ||
+		s.Value != convert.ToValueWithDefault(current.Value, "")
+}
+
+func (s *Secret) toArm() *armappcontainers.Secret {
+	if s.KeyVaultUrl != "" {
+		return &armappcontainers.Secret{
+			Name:        convert.RefOf(s.Name),
+			KeyVaultURL: convert.RefOf(s.KeyVaultUrl),
+			Identity:    convert.RefOf(s.Identity),
+		}
+	}
+
+	return &armappcontainers.Secret{
+		Name:  convert.RefOf(s.Name),
+		Value: convert.RefOf(s.Value),
+	}
+}
+
+// mergeSecrets returns the secrets of the container app with the secrets added or updated, and the names of the secrets
+// which drifted, ie) which the container app doesn't have or has with another value or reference. The other secrets of the
+// container app, ex) those declared in the infrastructure, are kept.
+func mergeSecrets(
+	current []*armappcontainers.ContainerAppSecret,
+	secrets []*Secret,
+) ([]*armappcontainers.Secret, []string) {
+	merged := []*armappcontainers.Secret{}
+	for _, secret := range current {
+		if secret.KeyVaultURL != nil {
+			merged = append(merged, &armappcontainers.Secret{
+				Name:        secret.Name,
+				KeyVaultURL: secret.KeyVaultURL,
+				Identity:    secret.Identity,
+			})
+			continue
+		}
+
+		merged = append(merged, &armappcontainers.Secret{
+			Name:  secret.Name,
+			Value: secret.Value,
+		})
+	}
+
+	drifted := []string{}
+	for _, secret := range secrets {
+		idx := slices.IndexFunc(current, func(s *armappcontainers.ContainerAppSecret) bool {
+			return s.Name != nil && *s.Name == secret.Name
+		})
+
+		if idx < 0 {
+			merged = append(merged, secret.toArm())
+			drifted = append(drifted, secret.Name)
+		} else if secret.differs(current[idx]) {
+			merged[idx] = secret.toArm()
+			drifted = append(drifted, secret.Name)
+		}
+	}
+
+	return merged, drifted
+}
+
+// setContainerSecretEnv sets the environment variables of the container referencing the secrets, replacing the variables
+// it already has
+func setContainerSecretEnv(container *armappcontainers.Container, secrets []*Secret) {
+	for _, secret := range secrets {
+		if secret.EnvVar == "" {
+			continue
+		}
+
+		envVar := &armappcontainers.EnvironmentVar{
+			Name:      convert.RefOf(secret.EnvVar),
+			SecretRef: convert.RefOf(secret.Name),
+		}
+
+		idx := slices.IndexFunc(container.Env, func(v *armappcontainers.EnvironmentVar) bool {
+			return v.Name != nil && *v.Name == secret.EnvVar
+		})
+		if idx >= 0 {
+			container.Env[idx] = envVar
+		} else {
+			container.Env = append(container.Env, envVar)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerapps
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeSecrets(t *testing.T) {
+	vaultUrl := "https://vault.vault.azure.net/secrets/api-key"
+	current := []*armappcontainers.ContainerAppSecret{
+		{Name: convert.RefOf("infra"), Value: convert.RefOf("from-bicep")},
+		{Name: convert.RefOf("unchanged"), Value: convert.RefOf("same")},
+		{Name: convert.RefOf("changed"), Value: convert.RefOf("old")},
+		{Name: convert.RefOf("api-key"), KeyVaultURL: &vaultUrl, Identity: convert.RefOf("system")},
+	}
+
+	secrets := []*Secret{
+		{Name: "unchanged", Value: "same"},
+		{Name: "changed", Value: "new"},
+		{Name: "api-key", KeyVaultUrl: vaultUrl, Identity: SystemIdentity},
+		{Name: "added", KeyVaultUrl: vaultUrl, Identity: "IDENTITY_ID"},
+	}
+
+	merged, drifted := mergeSecrets(current, secrets)
+	require.Equal(t, []string{"changed", "added"}, drifted)
+
+	actual := map[string]armappcontainers.Secret{}
+	for _, secret := range merged {
+		actual[*secret.Name] = *secret
+	}
+
+	require.Len(t, actual, 5)
+	// secrets which aren't declared are kept
+	require.Equal(t, "from-bicep", *actual["infra"].Value)
+	require.Equal(t, "same", *actual["unchanged"].Value)
+	require.Equal(t, "new", *actual["changed"].Value)
+	require.Equal(t, vaultUrl, *actual["api-key"].KeyVaultURL)
+	require.Nil(t, actual["api-key"].Value)
+	require.Equal(t, vaultUrl, *actual["added"].KeyVaultURL)
+	require.Equal(t, "IDENTITY_ID", *actual["added"].Identity)
+}
+
+func Test_mergeSecrets_ValueReplacesReference(t *testing.T) {
+	current := []*armappcontainers.ContainerAppSecret{
+		{Name: convert.RefOf("secret"), KeyVaultURL: convert.RefOf("https://vault.vault.azure.net/secrets/secret")},
+	}
+
+	merged, drifted := mergeSecrets(current, []*Secret{{Name: "secret", Value: "value"}})
+	require.Equal(t, []string{"secret"}, drifted)
+	require.Len(t, merged, 1)
+	require.Equal(t, "value", *merged[0].Value)
+	require.Nil(t, merged[0].KeyVaultURL)
+}
+
+func Test_setContainerSecretEnv(t *testing.T) {
+	container := &armappcontainers.Container{
+		Env: []*armappcontainers.EnvironmentVar{
+			{Name: convert.RefOf("KEEP"), Value: convert.RefOf("kept")},
+			{Name: convert.RefOf("DB_PASSWORD"), Value: convert.RefOf("plain")},
+		},
+	}
+
+	setContainerSecretEnv(container, []*Secret{
+		{Name: "db-password", Value: "value", EnvVar: "DB_PASSWORD"},
+		{Name: "api-key", Value: "value", EnvVar: "API_KEY"},
+		{Name: "no-env", Value: "value"},
+	})
+
+	require.Len(t, container.Env, 3)
+	require.Equal(t, "kept", *container.Env[0].Value)
+	require.Equal(t, "DB_PASSWORD", *container.Env[1].Name)
+	require.Equal(t, "db-password", *container.Env[1].SecretRef)
+	require.Nil(t, container.Env[1].Value)
+	require.Equal(t, "API_KEY", *container.Env[2].Name)
+	require.Equal(t, "api-key", *container.Env[2].SecretRef)
+}
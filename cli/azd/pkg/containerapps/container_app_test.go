@@ -128,7 +128,7 @@ func Test_ContainerApp_AddRevision(t *testing.T) {
 	)
 
 	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
-	err := cas.AddRevision(*mockContext.Context, subscriptionId, resourceGroup, appName, updatedImageName)
+	err := cas.AddRevision(*mockContext.Context, subscriptionId, resourceGroup, appName, updatedImageName, nil, nil, nil)
 	require.NoError(t, err)
 
 	// Verify lastest revision is read
@@ -150,3 +150,135 @@ func Test_ContainerApp_AddRevision(t *testing.T) {
 	require.Equal(t, updatedImageName, *updatedContainerApp.Properties.Template.Containers[0].Image)
 	require.Equal(t, "azd-0", *updatedContainerApp.Properties.Template.RevisionSuffix)
 }
+
+func Test_ContainerApp_RestartRevision(t *testing.T) {
+	subscriptionId := "SUBSCRIPTION_ID"
+	resourceGroup := "RESOURCE_GROUP"
+	appName := "APP_NAME"
+	revisionName := "REVISION_NAME"
+
+	containerApp := &armappcontainers.ContainerApp{
+		Name: &appName,
+		Properties: &armappcontainers.ContainerAppProperties{
+			LatestRevisionName: &revisionName,
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	_ = mockazsdk.MockContainerAppGet(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+	restartRequest := mockazsdk.MockContainerAppRevisionRestart(
+		mockContext,
+		subscriptionId,
+		resourceGroup,
+		appName,
+		revisionName,
+	)
+
+	cas := NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
+	err := cas.RestartRevision(*mockContext.Context, subscriptionId, resourceGroup, appName)
+	require.NoError(t, err)
+
+	expectedPath := fmt.Sprintf(
+		"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s/revisions/%s/restart",
+		subscriptionId,
+		resourceGroup,
+		appName,
+		revisionName,
+	)
+	require.Equal(t, expectedPath, restartRequest.URL.Path)
+}
+
+func Test_ContainerApp_DeactivateUnservedRevision(t *testing.T) {
+	subscriptionId := "SUBSCRIPTION_ID"
+	resourceGroup := "RESOURCE_GROUP"
+	appName := "APP_NAME"
+
+	tests := []struct {
+		name         string
+		revisionName string
+		traffic      []*armappcontainers.TrafficWeight
+		deactivated  bool
+	}{
+		{
+			name:         "Unserved",
+			revisionName: "APP_NAME--azd-2",
+			traffic: []*armappcontainers.TrafficWeight{
+				{RevisionName: convert.RefOf("APP_NAME--azd-1"), Weight: convert.RefOf[int32](100)},
+			},
+			deactivated: true,
+		},
+		{
+			name:         "Served",
+			revisionName: "APP_NAME--azd-2",
+			traffic: []*armappcontainers.TrafficWeight{
+				{RevisionName: convert.RefOf("APP_NAME--azd-2"), Weight: convert.RefOf[int32](100)},
+			},
+		},
+		{
+			name:         "ServedAsLatest",
+			revisionName: "APP_NAME--azd-2",
+			traffic: []*armappcontainers.TrafficWeight{
+				{LatestRevision: convert.RefOf(true), Weight: convert.RefOf[int32](100)},
+			},
+		},
+		{
+			name:         "NotAddedByAzd",
+			revisionName: "APP_NAME--v2",
+			traffic: []*armappcontainers.TrafficWeight{
+				{RevisionName: convert.RefOf("APP_NAME--azd-1"), Weight: convert.RefOf[int32](100)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerApp := &armappcontainers.ContainerApp{
+				Name: &appName,
+				Properties: &armappcontainers.ContainerAppProperties{
+					LatestRevisionName: convert.RefOf(tt.revisionName),
+					Configuration: &armappcontainers.Configuration{
+						ActiveRevisionsMode: convert.RefOf(armappcontainers.ActiveRevisionsModeMultiple),
+						Ingress: &armappcontainers.Ingress{
+							Traffic: tt.traffic,
+						},
+					},
+				},
+			}
+
+			mockContext := mocks.NewMockContext(context.Background())
+			_ = mockazsdk.MockContainerAppGet(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+			deactivateRequest := mockazsdk.MockContainerAppRevisionDeactivate(
+				mockContext,
+				subscriptionId,
+				resourceGroup,
+				appName,
+				tt.revisionName,
+			)
+
+			cas := NewContainerAppService(
+				mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, clock.NewMock())
+			err := cas.DeactivateUnservedRevision(*mockContext.Context, subscriptionId, resourceGroup, appName)
+			require.NoError(t, err)
+			require.Equal(t, tt.deactivated, deactivateRequest.URL != nil)
+		})
+	}
+}
+
+func Test_setContainerEnv(t *testing.T) {
+	container := &armappcontainers.Container{
+		Env: []*armappcontainers.EnvironmentVar{
+			{Name: convert.RefOf("KEEP"), Value: convert.RefOf("kept")},
+			{Name: convert.RefOf("REPLACE"), SecretRef: convert.RefOf("secret")},
+		},
+	}
+
+	setContainerEnv(container, map[string]string{"REPLACE": "replaced", "ADD": "added"})
+
+	env := map[string]string{}
+	for _, envVar := range container.Env {
+		env[*envVar.Name] = convert.ToValueWithDefault(envVar.Value, "")
+	}
+
+	require.Equal(t, map[string]string{"KEEP": "kept", "REPLACE": "replaced", "ADD": "added"}, env)
+	require.Nil(t, container.Env[1].SecretRef)
+}
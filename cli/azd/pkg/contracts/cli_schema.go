@@ -0,0 +1,38 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package contracts
+
+import "encoding/json"
+
+// CliSchema is the contract for the output of `azd internal export-schema`
+type CliSchema struct {
+	// The version of azd the schema was exported from
+	Version string `json:"version"`
+	// The root `azd` command
+	Command CliSchemaCommand `json:"command"`
+	// The azure.yaml JSON schemas, keyed by schema version
+	AzureYamlSchemas map[string]json.RawMessage `json:"azureYamlSchemas"`
+}
+
+// CliSchemaCommand describes a single command and its sub commands
+type CliSchemaCommand struct {
+	Name        string             `json:"name"`
+	Path        string             `json:"path"`
+	Usage       string             `json:"usage"`
+	Description string             `json:"description"`
+	Aliases     []string           `json:"aliases,omitempty"`
+	Flags       []CliSchemaFlag    `json:"flags"`
+	Commands    []CliSchemaCommand `json:"commands,omitempty"`
+}
+
+// CliSchemaFlag describes a single command flag
+type CliSchemaFlag struct {
+	Name        string `json:"name"`
+	Shorthand   string `json:"shorthand,omitempty"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+	// Whether the flag is inherited by all sub commands
+	Persistent bool `json:"persistent,omitempty"`
+}
@@ -0,0 +1,17 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package contracts
+
+import "time"
+
+// LogMessage is the contract for a line of the logs written by `azd logs --output json`. A message is written on
+// each line of the output, as the logs are received.
+type LogMessage struct {
+	// The name of the service which wrote the line
+	Service string `json:"service"`
+	// The time the line was received by azd
+	Timestamp time.Time `json:"timestamp"`
+	// The line, without the line ending
+	Message string `json:"message"`
+}
@@ -41,3 +41,9 @@ type ShowServiceProject struct {
 type ShowTargetArm struct {
 	ResourceIds []string `json:"resourceIds"`
 }
+
+// ShowEndpointsResult is the contract for the output of `azd show endpoints`
+type ShowEndpointsResult struct {
+	// The endpoints of each service, by service name
+	Services map[string][]string `json:"services"`
+}
@@ -0,0 +1,198 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package diagnostics creates the diagnostic settings sending the logs and metrics of the provisioned resources to the
+// Log Analytics workspace of the environment, in place of diagnostic settings declared in every module or assigned by
+// policy.
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/drone/envsubst"
+	"golang.org/x/exp/slices"
+)
+
+// SettingName is the name of the diagnostic settings created by azd, so that provisioning again updates them
+const SettingName = "azd-diagnostics"
+
+// Setting is the diagnostic setting of a provisioned resource, resolved against the categories the resource supports.
+type Setting struct {
+	ResourceId   string
+	ResourceName string
+	ResourceType string
+	WorkspaceId  string
+	// The configured category groups supported by the resource
+	CategoryGroups []string
+	// Whether the resource has metrics, and metrics are configured
+	Metrics bool
+}
+
+// Supported returns whether the resource supports any of the configured logs or metrics.
+func (s *Setting) Supported() bool {
+	return len(s.CategoryGroups) > 0 || s.Metrics
+}
+
+// String describes what the setting sends to the workspace.
+func (s *Setting) String() string {
+	sent := slices.Clone(s.CategoryGroups)
+	if s.Metrics {
+		sent = append(sent, "metrics")
+	}
+
+	return fmt.Sprintf("%s (%s)", s.ResourceName, strings.Join(sent, ", "))
+}
+
+// Manager resolves and applies the diagnostic settings of the resources of the environment.
+type Manager struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager project.ResourceManager
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+) *Manager {
+	return &Manager{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+	}
+}
+
+// Resolve resolves the workspace, and the diagnostic settings of the resources of the resource group of the environment,
+// when diagnostics are configured in azure.yaml.
+func (m *Manager) Resolve(ctx context.Context, projectConfig *project.ProjectConfig) ([]*Setting, error) {
+	options := projectConfig.Infra.Diagnostics
+	if options == nil {
+		return nil, nil
+	}
+
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	resourceGroupName, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	resources, err := m.azCli.ListResourceGroupResources(ctx, subscriptionId, resourceGroupName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing resources of resource group '%s': %w", resourceGroupName, err)
+	}
+
+	workspaceId, err := m.workspaceId(options.Workspace, resourceGroupName, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := []*Setting{}
+	for _, resource := range resources {
+		categories, err := m.azCli.ListDiagnosticSettingsCategories(ctx, subscriptionId, resource.Id)
+		if err != nil {
+			return nil, fmt.Errorf("resolving diagnostics of resource '%s': %w", resource.Name, err)
+		}
+
+		setting := &Setting{
+			ResourceId:     resource.Id,
+			ResourceName:   resource.Name,
+			ResourceType:   resource.Type,
+			WorkspaceId:    workspaceId,
+			CategoryGroups: []string{},
+		}
+
+		supportedGroups := map[string]struct{}{}
+		for _, category := range categories {
+			switch category.CategoryType {
+			case "Logs":
+				for _, group := range category.CategoryGroups {
+					supportedGroups[strings.ToLower(group)] = struct{}{}
+				}
+			case "Metrics":
+				setting.Metrics = options.SendMetrics()
+			}
+		}
+
+		for _, group := range options.ResolvedCategoryGroups() {
+			if _, has := supportedGroups[strings.ToLower(group)]; has {
+				setting.CategoryGroups = append(setting.CategoryGroups, group)
+			}
+		}
+
+		settings = append(settings, setting)
+	}
+
+	return settings, nil
+}
+
+// Apply creates, or updates, the diagnostic setting of the resource, when the resource supports it.
+func (m *Manager) Apply(ctx context.Context, setting *Setting) error {
+	if !setting.Supported() {
+		return nil
+	}
+
+	err := m.azCli.CreateOrUpdateDiagnosticSetting(
+		ctx,
+		m.env.GetSubscriptionId(),
+		setting.ResourceId,
+		SettingName,
+		azcli.AzCliDiagnosticSetting{
+			WorkspaceId:    setting.WorkspaceId,
+			CategoryGroups: setting.CategoryGroups,
+			Metrics:        setting.Metrics,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("configuring diagnostics of %s: %w", setting.ResourceName, err)
+	}
+
+	return nil
+}
+
+// workspaceId returns the resource id of the configured workspace, or of the only Log Analytics workspace of the
+// resource group
+func (m *Manager) workspaceId(workspace string, resourceGroupName string, resources []azcli.AzCliResource) (string, error) {
+	if workspace != "" {
+		workspaceId, err := envsubst.Eval(workspace, m.env.Getenv)
+		if err != nil {
+			return "", fmt.Errorf("substituting environment variables for workspace: %w", err)
+		}
+
+		if !strings.HasPrefix(workspaceId, "/") {
+			return "", fmt.Errorf("workspace '%s' is not the resource id of a Log Analytics workspace", workspaceId)
+		}
+
+		return strings.TrimSuffix(workspaceId, "/"), nil
+	}
+
+	workspaceIds := []string{}
+	for _, resource := range resources {
+		if strings.EqualFold(resource.Type, string(infra.AzureResourceTypeLogAnalyticsWorkspace)) {
+			workspaceIds = append(workspaceIds, resource.Id)
+		}
+	}
+
+	switch len(workspaceIds) {
+	case 0:
+		return "", fmt.Errorf(
+			"resource group '%s' has no Log Analytics workspace, set infra.diagnostics.workspace in azure.yaml",
+			resourceGroupName)
+	case 1:
+		return workspaceIds[0], nil
+	}
+
+	return "", fmt.Errorf(
+		"resource group '%s' has more than one Log Analytics workspace, set infra.diagnostics.workspace in azure.yaml",
+		resourceGroupName)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rgId        = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev"
+	workspaceId = rgId + "/providers/Microsoft.OperationalInsights/workspaces/log-dev"
+	keyVaultId  = rgId + "/providers/Microsoft.KeyVault/vaults/kv-dev"
+	identityId  = rgId + "/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id-dev"
+
+	sharedWorkspaceId = "/subscriptions/SHARED/resourceGroups/rg-shared/providers/" +
+		"Microsoft.OperationalInsights/workspaces/log-shared"
+)
+
+type category struct {
+	Name       string         `json:"name"`
+	Properties map[string]any `json:"properties"`
+}
+
+func logsCategory(name string, groups ...string) category {
+	return category{Name: name, Properties: map[string]any{"categoryType": "Logs", "categoryGroups": groups}}
+}
+
+func metricsCategory() category {
+	return category{Name: "AllMetrics", Properties: map[string]any{"categoryType": "Metrics"}}
+}
+
+func newTestManager(mockContext *mocks.MockContext, resourceIds ...string) *Manager {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-dev/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		result := armresources.ResourceListResult{}
+		for _, resourceId := range resourceIds {
+			parts := strings.Split(resourceId, "/")
+			result.Value = append(result.Value, &armresources.GenericResourceExpanded{
+				ID:       convert.RefOf(resourceId),
+				Name:     convert.RefOf(parts[len(parts)-1]),
+				Type:     convert.RefOf(parts[6] + "/" + parts[7]),
+				Location: convert.RefOf("eastus2"),
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, result)
+	})
+
+	categories := map[string][]category{
+		workspaceId: {logsCategory("Audit", "audit", "allLogs"), metricsCategory()},
+		keyVaultId: {
+			logsCategory("AuditEvent", "audit", "allLogs"),
+			logsCategory("AzurePolicyEvaluationDetails", "allLogs"),
+			metricsCategory(),
+		},
+	}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/diagnosticSettingsCategories")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		resourceId := strings.TrimSuffix(
+			request.URL.Path, "/providers/Microsoft.Insights/diagnosticSettingsCategories")
+		resourceCategories, has := categories[resourceId]
+		if !has {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusNotFound, map[string]any{
+				"code": "ResourceTypeNotSupported",
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": resourceCategories})
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+		"SHARED_WORKSPACE_ID":                sharedWorkspaceId,
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	return NewManager(env, azCli, project.NewResourceManager(env, azCli))
+}
+
+func Test_ResolveAndApply(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	manager := newTestManager(mockContext, workspaceId, keyVaultId, identityId)
+
+	applied := map[string]map[string]any{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/diagnosticSettings/"+SettingName)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(contents, &body))
+		applied[strings.TrimSuffix(request.URL.Path, "/providers/Microsoft.Insights/diagnosticSettings/"+SettingName)] =
+			body["properties"].(map[string]any)
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, body)
+	})
+
+	projectConfig := &project.ProjectConfig{
+		Infra: provisioning.Options{
+			Diagnostics: &provisioning.DiagnosticsOptions{CategoryGroups: []string{"audit"}},
+		},
+	}
+
+	settings, err := manager.Resolve(*mockContext.Context, projectConfig)
+	require.NoError(t, err)
+	require.Len(t, settings, 3)
+
+	// the only workspace of the resource group is used, and sends its own diagnostics to itself
+	require.Equal(t, &Setting{
+		ResourceId:     keyVaultId,
+		ResourceName:   "kv-dev",
+		ResourceType:   "Microsoft.KeyVault/vaults",
+		WorkspaceId:    workspaceId,
+		CategoryGroups: []string{"audit"},
+		Metrics:        true,
+	}, settings[1])
+	require.Equal(t, "kv-dev (audit, metrics)", settings[1].String())
+	require.True(t, settings[0].Supported())
+	require.False(t, settings[2].Supported())
+
+	for _, setting := range settings {
+		require.NoError(t, manager.Apply(*mockContext.Context, setting))
+	}
+
+	require.Len(t, applied, 2)
+	require.Equal(t, map[string]any{
+		"workspaceId": workspaceId,
+		"logs":        []any{map[string]any{"categoryGroup": "audit", "enabled": true}},
+		"metrics":     []any{map[string]any{"category": "AllMetrics", "enabled": true}},
+	}, applied[keyVaultId])
+}
+
+func Test_ResolveWorkspace(t *testing.T) {
+	t.Run("Configured", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newTestManager(mockContext, keyVaultId)
+
+		settings, err := manager.Resolve(*mockContext.Context, &project.ProjectConfig{
+			Infra: provisioning.Options{
+				Diagnostics: &provisioning.DiagnosticsOptions{
+					Workspace: "${SHARED_WORKSPACE_ID}",
+					Metrics:   convert.RefOf(false),
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, settings, 1)
+		require.Equal(t, sharedWorkspaceId, settings[0].WorkspaceId)
+		require.Equal(t, []string{provisioning.DefaultDiagnosticsCategoryGroup}, settings[0].CategoryGroups)
+		require.False(t, settings[0].Metrics)
+	})
+
+	t.Run("NoWorkspace", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newTestManager(mockContext, keyVaultId)
+
+		_, err := manager.Resolve(*mockContext.Context, &project.ProjectConfig{
+			Infra: provisioning.Options{Diagnostics: &provisioning.DiagnosticsOptions{}},
+		})
+		require.ErrorContains(t, err, "resource group 'rg-dev' has no Log Analytics workspace")
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		manager := NewManager(environment.Ephemeral(), nil, nil)
+
+		settings, err := manager.Resolve(context.Background(), &project.ProjectConfig{})
+		require.NoError(t, err)
+		require.Empty(t, settings)
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 )
 
 const ProjectFileName = "azure.yaml"
@@ -20,6 +21,16 @@ const ConfigFileVersion = 1
 
 type AzdContext struct {
 	projectDirectory string
+	fs               vfs.Fs
+}
+
+// Fs is the file system the project and its environments are stored in.
+func (c *AzdContext) Fs() vfs.Fs {
+	if c.fs == nil {
+		return vfs.OsFs()
+	}
+
+	return c.fs
 }
 
 func (c *AzdContext) ProjectDirectory() string {
@@ -60,7 +71,7 @@ func (c *AzdContext) ListEnvironments() ([]contracts.EnvListEnvironment, error)
 		return nil, err
 	}
 
-	ents, err := os.ReadDir(c.EnvironmentDirectory())
+	ents, err := c.Fs().ReadDir(c.EnvironmentDirectory())
 	if errors.Is(err, os.ErrNotExist) {
 		return []contracts.EnvListEnvironment{}, nil
 	}
@@ -92,7 +103,7 @@ func (c *AzdContext) ListEnvironments() ([]contracts.EnvListEnvironment, error)
 // an empty string if a default environment has not been set.
 func (c *AzdContext) GetDefaultEnvironmentName() (string, error) {
 	path := filepath.Join(c.EnvironmentDirectory(), ConfigFileName)
-	file, err := os.ReadFile(path)
+	file, err := c.Fs().ReadFile(path)
 	switch {
 	case errors.Is(err, os.ErrNotExist):
 		return "", nil
@@ -115,13 +126,13 @@ func (c *AzdContext) SetDefaultEnvironmentName(name string) error {
 		DefaultEnvironment: name,
 	}
 
-	return writeConfig(path, config)
+	return writeConfig(c.Fs(), path, config)
 }
 
 var ErrEnvironmentExists = errors.New("environment already exists")
 
 func (c *AzdContext) NewEnvironment(name string) error {
-	return createEnvironment(c.EnvironmentDirectory(), name)
+	return createEnvironment(c.Fs(), c.EnvironmentDirectory(), name)
 }
 
 // Creates context with project directory set to the desired directory.
@@ -131,6 +142,14 @@ func NewAzdContextWithDirectory(projectDirectory string) *AzdContext {
 	}
 }
 
+// Creates context with project directory set to the desired directory of the file system, ex) a [vfs.MemFs] in tests.
+func NewAzdContextWithFs(fs vfs.Fs, projectDirectory string) *AzdContext {
+	return &AzdContext{
+		projectDirectory: projectDirectory,
+		fs:               fs,
+	}
+}
+
 var (
 	ErrNoProject = errors.New("no project exists; to create a new project, run `azd init`")
 )
@@ -180,12 +199,12 @@ type configFile struct {
 	DefaultEnvironment string `json:"defaultEnvironment"`
 }
 
-func createEnvironment(dir string, name string) error {
-	if err := os.MkdirAll(dir, osutil.PermissionDirectory); err != nil {
+func createEnvironment(fs vfs.Fs, dir string, name string) error {
+	if err := fs.MkdirAll(dir, osutil.PermissionDirectory); err != nil {
 		return fmt.Errorf("creating environment root: %w", err)
 	}
 
-	if err := os.Mkdir(filepath.Join(dir, name), osutil.PermissionDirectory); err != nil {
+	if err := fs.Mkdir(filepath.Join(dir, name), osutil.PermissionDirectory); err != nil {
 		if errors.Is(err, os.ErrExist) {
 			return ErrEnvironmentExists
 		}
@@ -196,17 +215,17 @@ func createEnvironment(dir string, name string) error {
 	return nil
 }
 
-func writeConfig(path string, config configFile) error {
+func writeConfig(fs vfs.Fs, path string, config configFile) error {
 	bytes, err := json.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("serializing config file: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+	if err := fs.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
 		return fmt.Errorf("creating environment root: %w", err)
 	}
 
-	if err := os.WriteFile(path, bytes, osutil.PermissionFile); err != nil {
+	if err := fs.WriteFile(path, bytes, osutil.PermissionFile); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
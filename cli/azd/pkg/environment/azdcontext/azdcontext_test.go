@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -96,20 +97,21 @@ func TestAzdContext_ListEnvironments(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			temp := t.TempDir()
-			azdCtx := NewAzdContextWithDirectory(temp)
+			temp := filepath.FromSlash("/project")
+			fs := vfs.NewMemFs()
+			azdCtx := NewAzdContextWithFs(fs, temp)
 			if tt.setupDefaultEnv != "" {
 				config := configFile{
 					Version:            ConfigFileVersion,
 					DefaultEnvironment: tt.setupDefaultEnv,
 				}
 				path := filepath.Join(temp, EnvironmentDirectoryName, ConfigFileName)
-				err := writeConfig(path, config)
+				err := writeConfig(fs, path, config)
 				require.NoError(t, err)
 			}
 
 			for _, env := range tt.setupEnv {
-				err := createEnvironment(filepath.Join(temp, EnvironmentDirectoryName), env)
+				err := createEnvironment(fs, filepath.Join(temp, EnvironmentDirectoryName), env)
 				require.NoError(t, err)
 			}
 
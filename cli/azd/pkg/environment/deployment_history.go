@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// DeploymentHistoryFileName is the name of the file, stored in the environment's [Root], recording the latest deploys
+// of the environment.
+const DeploymentHistoryFileName = "deployments.json"
+
+// maxDeploymentHistory is the number of deploys kept in the history, the oldest deploys are dropped first
+const maxDeploymentHistory = 50
+
+// DeploymentStatus is the outcome of a deploy, or of the deploy of a service.
+type DeploymentStatus string
+
+const (
+	DeploymentStatusSucceeded DeploymentStatus = "succeeded"
+	DeploymentStatusFailed    DeploymentStatus = "failed"
+	// The deploy was cancelled by the user, ex) with Ctrl+C, while in progress
+	DeploymentStatusInterrupted DeploymentStatus = "interrupted"
+)
+
+// DeploymentRecord is a deploy of the environment recorded in its deployment history.
+type DeploymentRecord struct {
+	StartedAt time.Time        `json:"startedAt"`
+	EndedAt   time.Time        `json:"endedAt"`
+	Status    DeploymentStatus `json:"status"`
+	// The services the deploy started deploying, in order
+	Services []ServiceDeploymentRecord `json:"services"`
+	Error    string                    `json:"error,omitempty"`
+}
+
+// ServiceDeploymentRecord is the deploy of a service within a [DeploymentRecord].
+type ServiceDeploymentRecord struct {
+	Name   string           `json:"name"`
+	Status DeploymentStatus `json:"status"`
+	// Whether the changes an interrupted deploy of the service left half applied were cleaned up
+	CleanedUp bool   `json:"cleanedUp,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// The url of the artifact the service was deployed from, or uploaded to once deployed
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// RecordDeployment appends the deploy to the deployment history of the environment. Environments which are not
+// persisted to disk have no history.
+func (e *Environment) RecordDeployment(record DeploymentRecord) error {
+	if e.Root == "" {
+		return nil
+	}
+
+	history, err := e.DeploymentHistory()
+	if err != nil {
+		return err
+	}
+
+	history = append(history, record)
+	if len(history) > maxDeploymentHistory {
+		history = history[len(history)-maxDeploymentHistory:]
+	}
+
+	contents, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling deployment history: %w", err)
+	}
+
+	if err := e.fsys().MkdirAll(e.Root, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("failed to create a directory: %w", err)
+	}
+
+	historyPath := filepath.Join(e.Root, DeploymentHistoryFileName)
+	if err := e.fsys().WriteFile(historyPath, contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing deployment history %s: %w", historyPath, err)
+	}
+
+	return nil
+}
+
+// DeploymentHistory returns the latest deploys of the environment, oldest first.
+func (e *Environment) DeploymentHistory() ([]DeploymentRecord, error) {
+	if e.Root == "" {
+		return []DeploymentRecord{}, nil
+	}
+
+	historyPath := filepath.Join(e.Root, DeploymentHistoryFileName)
+	contents, err := e.fsys().ReadFile(historyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return []DeploymentRecord{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading deployment history %s: %w", historyPath, err)
+	}
+
+	history := []DeploymentRecord{}
+	if err := json.Unmarshal(contents, &history); err != nil {
+		return nil, fmt.Errorf("invalid deployment history %s: %w", historyPath, err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentHistory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RecordsDeploys", func(t *testing.T) {
+		env := EmptyWithRoot(t.TempDir())
+
+		history, err := env.DeploymentHistory()
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		interrupted := DeploymentRecord{
+			StartedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			EndedAt:   time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+			Status:    DeploymentStatusInterrupted,
+			Services: []ServiceDeploymentRecord{
+				{Name: "api", Status: DeploymentStatusSucceeded},
+				{Name: "web", Status: DeploymentStatusInterrupted, CleanedUp: true, Error: "context canceled"},
+			},
+			Error: "context canceled",
+		}
+		require.NoError(t, env.RecordDeployment(interrupted))
+		require.NoError(t, env.RecordDeployment(DeploymentRecord{Status: DeploymentStatusSucceeded}))
+
+		history, err = env.DeploymentHistory()
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, interrupted, history[0])
+		require.Equal(t, DeploymentStatusSucceeded, history[1].Status)
+	})
+
+	t.Run("KeepsLatestDeploys", func(t *testing.T) {
+		env := EmptyWithRoot(t.TempDir())
+
+		for i := 0; i < maxDeploymentHistory+5; i++ {
+			require.NoError(t, env.RecordDeployment(DeploymentRecord{Error: fmt.Sprint(i)}))
+		}
+
+		history, err := env.DeploymentHistory()
+		require.NoError(t, err)
+		require.Len(t, history, maxDeploymentHistory)
+		require.Equal(t, "5", history[0].Error)
+		require.Equal(t, fmt.Sprint(maxDeploymentHistory+4), history[len(history)-1].Error)
+	})
+
+	t.Run("Ephemeral", func(t *testing.T) {
+		env := Ephemeral()
+		require.NoError(t, env.RecordDeployment(DeploymentRecord{Status: DeploymentStatusFailed}))
+
+		history, err := env.DeploymentHistory()
+		require.NoError(t, err)
+		require.Empty(t, history)
+	})
+}
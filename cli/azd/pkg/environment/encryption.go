@@ -0,0 +1,349 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/keychain"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// EncryptionConfigKey is the key of the environment config section encrypting the sensitive values of the environment.
+const EncryptionConfigKey = "encryption"
+
+// encryptedValuePrefix prefixes the encrypted values of the .env file and config of environments.
+const encryptedValuePrefix = "azdenc:v1:"
+
+// The service and account of the key encrypting the values of environments, in the keychain of the OS.
+const (
+	encryptionKeyService = "azd"
+	encryptionKeyAccount = "environment-encryption-key"
+)
+
+// sensitiveNameRegex matches the names of the values holding secrets, ex) AZURE_STORAGE_CONNECTION_STRING, OPENAI_API_KEY
+// or sqlAdminPassword.
+var sensitiveNameRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|connection_?string|(^|_)sas(_|$)|key$)`)
+
+// Encryption encrypts the sensitive values of the .env file and config of an environment at rest, with a key stored in
+// the keychain of the OS, so the files of the environment don't hold secrets in plain text. Encrypted values are
+// decrypted when the environment is loaded. It is configured in the environment config, ex)
+//
+//	{
+//	  "encryption": {
+//	    "enabled": true,
+//	    "values": ["DATABASE_URL", "MY_APP_*"]
+//	  }
+//	}
+type Encryption struct {
+	// Encrypts the sensitive values when the environment is saved.
+	Enabled bool `json:"enabled"`
+	// The names of values encrypted besides the values whose names look sensitive, ex) *_PASSWORD. Names can contain *
+	// wildcards.
+	Values []string `json:"values,omitempty"`
+}
+
+// Encryption returns the encryption configured for the environment, or a disabled value when not configured.
+func (e *Environment) Encryption() (*Encryption, error) {
+	encryption := &Encryption{}
+
+	value, has := e.Config.Get(EncryptionConfigKey)
+	if !has {
+		return encryption, nil
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling encryption config: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, encryption); err != nil {
+		return nil, fmt.Errorf("invalid '%s' config of environment '%s': %w", EncryptionConfigKey, e.GetEnvName(), err)
+	}
+
+	return encryption, nil
+}
+
+// Sensitive reports whether the value of the name is encrypted.
+func (c *Encryption) Sensitive(name string) bool {
+	if sensitiveNameRegex.MatchString(name) {
+		return true
+	}
+
+	return slices.ContainsFunc(c.Values, func(pattern string) bool {
+		matched, err := path.Match(pattern, name)
+		return err == nil && matched
+	})
+}
+
+// EncryptedValues returns the names of the values of the .env file which are encrypted when the environment is saved,
+// sorted.
+func (e *Environment) EncryptedValues() ([]string, error) {
+	encryption, err := e.Encryption()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if !encryption.Enabled {
+		return names, nil
+	}
+
+	for name := range e.dotenv {
+		if encryption.Sensitive(name) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// valueCipher encrypts and decrypts values with AES-GCM, with a key stored in the keychain. The key is created the first
+// time a value is encrypted, and read from the keychain once.
+type valueCipher struct {
+	mu       sync.Mutex
+	keychain keychain.Keychain
+	key      []byte
+}
+
+// envCipher encrypts the values of environments with the keychain of the OS.
+var envCipher = &valueCipher{}
+
+func (c *valueCipher) aead(create bool) (cipher.AEAD, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key == nil {
+		if c.keychain == nil {
+			c.keychain = keychain.New(exec.NewCommandRunner(nil))
+		}
+
+		key, err := c.keychain.Get(encryptionKeyService, encryptionKeyAccount)
+		if errors.Is(err, keychain.ErrNotFound) && create {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("creating encryption key: %w", err)
+			}
+
+			if err := c.keychain.Set(encryptionKeyService, encryptionKeyAccount, key); err != nil {
+				return nil, fmt.Errorf("storing encryption key: %w", err)
+			}
+		} else if errors.Is(err, keychain.ErrNotFound) {
+			return nil, errors.New(
+				"the encryption key isn't in the keychain, the environment was encrypted by another user or machine")
+		} else if err != nil {
+			return nil, err
+		}
+
+		c.key = key
+	}
+
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (c *valueCipher) encrypt(plaintext string) (string, error) {
+	aead, err := c.aead(true)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *valueCipher) decrypt(value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decoding encrypted value: %w", err)
+	}
+
+	aead, err := c.aead(false)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("the encrypted value can't be decrypted with the encryption key of the keychain")
+	}
+
+	return string(plaintext), nil
+}
+
+// isEncrypted reports whether the value is encrypted
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// ciphertextKey is the key of the ciphertext of a value of the .env file, or of the config at the path prefixed with
+// config:, in the ciphertexts of the environment
+func ciphertextKey(name string, plaintext string) string {
+	return name + "\x00" + plaintext
+}
+
+// decryptDotenv decrypts the encrypted values of the .env file, keeping their ciphertexts for the unchanged values to be
+// saved as is.
+func (e *Environment) decryptDotenv() error {
+	for name, value := range e.dotenv {
+		if !isEncrypted(value) {
+			continue
+		}
+
+		plaintext, err := envCipher.decrypt(value)
+		if err != nil {
+			return fmt.Errorf("decrypting '%s': %w", name, err)
+		}
+
+		e.dotenv[name] = plaintext
+		e.ciphertexts[ciphertextKey(name, plaintext)] = value
+	}
+
+	return nil
+}
+
+// encryptDotenv returns the values of the .env file with the sensitive values encrypted.
+func (e *Environment) encryptDotenv(encryption *Encryption) (map[string]string, error) {
+	values := maps.Clone(e.dotenv)
+	for name, value := range values {
+		if value == "" || !encryption.Sensitive(name) {
+			continue
+		}
+
+		encrypted, err := e.encryptValue(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting '%s': %w", name, err)
+		}
+
+		values[name] = encrypted
+	}
+
+	return values, nil
+}
+
+// decryptConfig decrypts the encrypted values of the config.
+func (e *Environment) decryptConfig() error {
+	data, err := transformConfig(e.Config.Raw(), "", func(path string, _ string, value string) (string, error) {
+		if !isEncrypted(value) {
+			return value, nil
+		}
+
+		plaintext, err := envCipher.decrypt(value)
+		if err != nil {
+			return "", fmt.Errorf("decrypting config '%s': %w", path, err)
+		}
+
+		e.ciphertexts[ciphertextKey("config:"+path, plaintext)] = value
+		return plaintext, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	e.Config = config.NewConfig(data)
+	return nil
+}
+
+// encryptConfig returns the config with the string values of the sensitive keys encrypted.
+func (e *Environment) encryptConfig(encryption *Encryption) (config.Config, error) {
+	data, err := transformConfig(e.Config.Raw(), "", func(path string, name string, value string) (string, error) {
+		if value == "" || !encryption.Sensitive(name) {
+			return value, nil
+		}
+
+		encrypted, err := e.encryptValue("config:"+path, value)
+		if err != nil {
+			return "", fmt.Errorf("encrypting config '%s': %w", path, err)
+		}
+
+		return encrypted, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return config.NewConfig(data), nil
+}
+
+// encryptValue encrypts the value, reusing the ciphertext it was loaded from when unchanged
+func (e *Environment) encryptValue(name string, value string) (string, error) {
+	if isEncrypted(value) {
+		return value, nil
+	}
+
+	if ciphertext, has := e.ciphertexts[ciphertextKey(name, value)]; has {
+		return ciphertext, nil
+	}
+
+	return envCipher.encrypt(value)
+}
+
+// transformConfig returns a copy of the config data with its string values transformed. The encryption section of the
+// config is never transformed.
+func transformConfig(
+	data map[string]any,
+	parent string,
+	transform func(path string, name string, value string) (string, error),
+) (map[string]any, error) {
+	result := make(map[string]any, len(data))
+	for name, value := range data {
+		path := name
+		if parent != "" {
+			path = parent + "." + name
+		}
+
+		switch value := value.(type) {
+		case string:
+			transformed, err := transform(path, name, value)
+			if err != nil {
+				return nil, err
+			}
+
+			result[name] = transformed
+		case map[string]any:
+			if path == EncryptionConfigKey {
+				result[name] = value
+				continue
+			}
+
+			transformed, err := transformConfig(value, path, transform)
+			if err != nil {
+				return nil, err
+			}
+
+			result[name] = transformed
+		default:
+			result[name] = value
+		}
+	}
+
+	return result, nil
+}
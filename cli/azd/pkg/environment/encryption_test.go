@@ -0,0 +1,134 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeychain struct {
+	secrets map[string][]byte
+}
+
+func (k *testKeychain) Get(service string, account string) ([]byte, error) {
+	secret, has := k.secrets[service+"/"+account]
+	if !has {
+		return nil, keychain.ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (k *testKeychain) Set(service string, account string, secret []byte) error {
+	k.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func setTestKeychain(t *testing.T) *testKeychain {
+	testKeychain := &testKeychain{secrets: map[string][]byte{}}
+
+	original := envCipher
+	envCipher = &valueCipher{keychain: testKeychain}
+	t.Cleanup(func() { envCipher = original })
+
+	return testKeychain
+}
+
+func TestEncryption_Sensitive(t *testing.T) {
+	encryption := &Encryption{Enabled: true, Values: []string{"DATABASE_URL", "MY_APP_*"}}
+
+	for _, name := range []string{
+		"AZURE_STORAGE_CONNECTION_STRING", "OPENAI_API_KEY", "sqlAdminPassword", "CLIENT_SECRET", "GITHUB_TOKEN",
+		"STORAGE_SAS", "DATABASE_URL", "MY_APP_SETTING",
+	} {
+		require.True(t, encryption.Sensitive(name), name)
+	}
+
+	for _, name := range []string{
+		"AZURE_ENV_NAME", "AZURE_KEY_VAULT_ENDPOINT", "AZURE_KEY_VAULT_NAME", "SERVICE_API_ENDPOINTS", "DATABASE_NAME",
+	} {
+		require.False(t, encryption.Sensitive(name), name)
+	}
+}
+
+func TestEncryption_SaveAndReload(t *testing.T) {
+	testKeychain := setTestKeychain(t)
+
+	root := t.TempDir()
+	env := EmptyWithRoot(root)
+	env.SetEnvName("dev")
+	env.DotenvSet("AZURE_STORAGE_CONNECTION_STRING", "AccountKey=abc")
+	env.DotenvSet("AZURE_LOCATION", "eastus2")
+	require.NoError(t, env.Config.Set("infra.parameters.sqlAdminPassword", "p@ssw0rd"))
+	require.NoError(t, env.Config.Set("infra.parameters.sku", "B1"))
+	require.NoError(t, env.Config.Set(EncryptionConfigKey, Encryption{Enabled: true}))
+	require.NoError(t, env.Save())
+
+	// The key was created in the keychain
+	require.Len(t, testKeychain.secrets, 1)
+
+	dotenv, err := os.ReadFile(filepath.Join(root, azdcontext.DotEnvFileName))
+	require.NoError(t, err)
+	require.NotContains(t, string(dotenv), "AccountKey=abc")
+	require.Contains(t, string(dotenv), `AZURE_STORAGE_CONNECTION_STRING="azdenc:v1:`)
+	require.Contains(t, string(dotenv), `AZURE_LOCATION="eastus2"`)
+
+	cfg, err := os.ReadFile(filepath.Join(root, azdcontext.ConfigFileName))
+	require.NoError(t, err)
+	require.NotContains(t, string(cfg), "p@ssw0rd")
+	require.Contains(t, string(cfg), `"sku": "B1"`)
+
+	// The values are decrypted when loaded
+	loaded, err := FromRoot(root)
+	require.NoError(t, err)
+	require.Equal(t, "AccountKey=abc", loaded.Getenv("AZURE_STORAGE_CONNECTION_STRING"))
+	password, _ := loaded.Config.Get("infra.parameters.sqlAdminPassword")
+	require.Equal(t, "p@ssw0rd", password)
+
+	encrypted, err := loaded.EncryptedValues()
+	require.NoError(t, err)
+	require.Equal(t, []string{"AZURE_STORAGE_CONNECTION_STRING"}, encrypted)
+
+	// Unchanged values are saved as they were loaded
+	loaded.DotenvSet("AZURE_LOCATION", "westus")
+	require.NoError(t, loaded.Save())
+
+	saved, err := os.ReadFile(filepath.Join(root, azdcontext.DotEnvFileName))
+	require.NoError(t, err)
+	require.Equal(t,
+		strings.ReplaceAll(string(dotenv), `AZURE_LOCATION="eastus2"`, `AZURE_LOCATION="westus"`), string(saved))
+
+	// Disabling the encryption stores the values in plain text
+	require.NoError(t, loaded.Config.Set(EncryptionConfigKey, Encryption{}))
+	require.NoError(t, loaded.Save())
+
+	dotenv, err = os.ReadFile(filepath.Join(root, azdcontext.DotEnvFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(dotenv), `AZURE_STORAGE_CONNECTION_STRING="AccountKey=abc"`)
+}
+
+func TestEncryption_KeyNotFound(t *testing.T) {
+	testKeychain := setTestKeychain(t)
+
+	root := t.TempDir()
+	env := EmptyWithRoot(root)
+	env.DotenvSet("API_KEY", "secret")
+	require.NoError(t, env.Config.Set(EncryptionConfigKey, Encryption{Enabled: true}))
+	require.NoError(t, env.Save())
+
+	// The environment was copied to another machine
+	delete(testKeychain.secrets, encryptionKeyService+"/"+encryptionKeyAccount)
+	envCipher = &valueCipher{keychain: testKeychain}
+
+	_, err := FromRoot(root)
+	require.ErrorContains(t, err, "decrypting 'API_KEY'")
+	require.ErrorContains(t, err, "the encryption key isn't in the keychain")
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/joho/godotenv"
 	"golang.org/x/exp/maps"
 )
@@ -26,6 +27,10 @@ const EnvNameEnvVarName = "AZURE_ENV_NAME"
 // LocationEnvVarName is the name of the key used to store the location property in the environment.
 const LocationEnvVarName = "AZURE_LOCATION"
 
+// LocationsEnvVarName is the name of the key used to store the locations of the regional stamps provisioned for the
+// environment.
+const LocationsEnvVarName = "AZURE_LOCATIONS"
+
 // SubscriptionIdEnvVarName is the name of they key used to store the subscription id property in the environment.
 const SubscriptionIdEnvVarName = "AZURE_SUBSCRIPTION_ID"
 
@@ -45,6 +50,10 @@ const AksClusterEnvVarName = "AZURE_AKS_CLUSTER_NAME"
 // ResourceGroupEnvVarName is the name of the azure resource group that should be used for deployments
 const ResourceGroupEnvVarName = "AZURE_RESOURCE_GROUP"
 
+// PipelinePrincipalIdEnvVarName is the name of the key used to store the object id of the service principal of the
+// pipeline configured with `azd pipeline config`.
+const PipelinePrincipalIdEnvVarName = "AZURE_PIPELINE_PRINCIPAL_ID"
+
 // The zero value of an Environment is not valid. Use [FromRoot] or [EmptyWithRoot] to create one. When writing tests,
 // [Ephemeral] and [EphemeralWithValues] are useful to create environments which are not persisted to disk.
 type Environment struct {
@@ -62,6 +71,13 @@ type Environment struct {
 	// will not be persisted when `Save` is called. This allows the zero value to be used
 	// for testing.
 	Root string
+
+	// fs is the file system Root is in, the file system of the OS when nil.
+	fs vfs.Fs
+
+	// ciphertexts are the encrypted values of the .env file and config, by name and plaintext, for the unchanged values
+	// to be saved as they were loaded.
+	ciphertexts map[string]string
 }
 
 type EnvironmentResolver func() (*Environment, error)
@@ -104,23 +120,32 @@ func CleanName(name string) string {
 // an valid empty environment file, configured to persist its contents
 // to this directory, is returned.
 func FromRoot(root string) (*Environment, error) {
-	if _, err := os.Stat(root); err != nil {
-		return EmptyWithRoot(root), err
+	return FromRootFs(vfs.OsFs(), root)
+}
+
+// FromRootFs loads an environment located in a directory of the file system, like [FromRoot].
+func FromRootFs(fs vfs.Fs, root string) (*Environment, error) {
+	empty := EmptyWithRoot(root)
+	empty.fs = fs
+
+	if _, err := fs.Stat(root); err != nil {
+		return empty, err
 	}
 
 	env := &Environment{
 		Root: root,
+		fs:   fs,
 	}
 
 	if err := env.Reload(); err != nil {
-		return EmptyWithRoot(root), err
+		return empty, err
 	}
 
 	return env, nil
 }
 
 func GetEnvironment(azdContext *azdcontext.AzdContext, name string) (*Environment, error) {
-	return FromRoot(azdContext.EnvironmentRoot(name))
+	return FromRootFs(azdContext.Fs(), azdContext.EnvironmentRoot(name))
 }
 
 // EmptyWithRoot returns an empty environment, which will be persisted
@@ -160,22 +185,23 @@ func EphemeralWithValues(name string, values map[string]string) *Environment {
 }
 
 // Getenv behaves like os.Getenv, except that any keys in the `.env` file associated with this environment are considered
-// first.
+// first. Keys prefixed with [OutputsPrefix] resolve a path of the outputs, ex) outputs.endpoints[0].url.
 func (e *Environment) Getenv(key string) string {
-	if v, has := e.dotenv[key]; has {
-		return v
-	}
-
-	return os.Getenv(key)
+	v, _ := e.LookupEnv(key)
+	return v
 }
 
 // LookupEnv behaves like os.LookupEnv, except that any keys in the `.env` file associated with this environment are
-// considered first.
+// considered first. Keys prefixed with [OutputsPrefix] resolve a path of the outputs, ex) outputs.endpoints[0].url.
 func (e *Environment) LookupEnv(key string) (string, bool) {
 	if v, has := e.dotenv[key]; has {
 		return v, true
 	}
 
+	if strings.HasPrefix(key, OutputsPrefix) {
+		return e.lookupOutput(key)
+	}
+
 	return os.LookupEnv(key)
 }
 
@@ -202,7 +228,7 @@ func (e *Environment) DotenvSet(key string, value string) {
 func (e *Environment) Reload() error {
 	// Reload env values
 	envPath := filepath.Join(e.Root, azdcontext.DotEnvFileName)
-	if envMap, err := godotenv.Read(envPath); errors.Is(err, os.ErrNotExist) {
+	if envMap, err := e.readDotenv(envPath); errors.Is(err, os.ErrNotExist) {
 		e.dotenv = make(map[string]string)
 		e.deletedKeys = make(map[string]struct{})
 	} else if err != nil {
@@ -212,9 +238,14 @@ func (e *Environment) Reload() error {
 		e.deletedKeys = make(map[string]struct{})
 	}
 
+	e.ciphertexts = make(map[string]string)
+	if err := e.decryptDotenv(); err != nil {
+		return fmt.Errorf("loading .env: %w", err)
+	}
+
 	// Reload env config
 	cfgPath := filepath.Join(e.Root, azdcontext.ConfigFileName)
-	cfgMgr := config.NewManager()
+	cfgMgr := config.NewManagerWithFs(e.fsys())
 	if cfg, err := cfgMgr.Load(cfgPath); errors.Is(err, os.ErrNotExist) {
 		e.Config = config.NewEmptyConfig()
 	} else if err != nil {
@@ -223,6 +254,10 @@ func (e *Environment) Reload() error {
 		e.Config = cfg
 	}
 
+	if err := e.decryptConfig(); err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
 	if e.GetEnvName() != "" {
 		tracing.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.GetEnvName()))
 	}
@@ -241,9 +276,21 @@ func (e *Environment) Save() error {
 		return nil
 	}
 
-	// Update configuration
-	cfgMgr := config.NewManager()
-	if err := cfgMgr.Save(e.Config, filepath.Join(e.Root, azdcontext.ConfigFileName)); err != nil {
+	encryption, err := e.Encryption()
+	if err != nil {
+		return err
+	}
+
+	// Update configuration, with its sensitive values encrypted when configured
+	cfg := e.Config
+	if encryption.Enabled {
+		if cfg, err = e.encryptConfig(encryption); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+	}
+
+	cfgMgr := config.NewManagerWithFs(e.fsys())
+	if err := cfgMgr.Save(cfg, filepath.Join(e.Root, azdcontext.ConfigFileName)); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
 
@@ -264,37 +311,55 @@ func (e *Environment) Save() error {
 		delete(e.dotenv, key)
 	}
 
-	err := os.MkdirAll(e.Root, osutil.PermissionDirectory)
+	err = e.fsys().MkdirAll(e.Root, osutil.PermissionDirectory)
 	if err != nil {
 		return fmt.Errorf("failed to create a directory: %w", err)
 	}
 
+	values := e.dotenv
+	if encryption.Enabled {
+		if values, err = e.encryptDotenv(encryption); err != nil {
+			return fmt.Errorf("saving .env: %w", err)
+		}
+	}
+
 	// Instead of calling `godotenv.Write` directly, we need to save the file ourselves, so we can fixup any numeric values
 	// that were incorrectly unquoted.
-	marshalled, err := godotenv.Marshal(e.dotenv)
+	marshalled, err := godotenv.Marshal(values)
 	if err != nil {
 		return fmt.Errorf("saving .env: %w", err)
 	}
 
-	marshalled = fixupUnquotedDotenv(e.dotenv, marshalled)
+	marshalled = fixupUnquotedDotenv(values, marshalled)
 
-	envFile, err := os.Create(filepath.Join(e.Root, azdcontext.DotEnvFileName))
-	if err != nil {
+	// Write the contents with a trailing newline, as godotenv.Write would have. The .env file is synced to disk, for
+	// the values of the environment to survive a crash.
+	envPath := filepath.Join(e.Root, azdcontext.DotEnvFileName)
+	if err := e.fsys().WriteFileDurable(envPath, []byte(marshalled+"\n"), osutil.PermissionFile); err != nil {
 		return fmt.Errorf("saving .env: %w", err)
 	}
-	defer envFile.Close()
 
-	// Write the contents (with a trailing newline), and sync the file, as godotenv.Write would have.
-	if _, err := envFile.WriteString(marshalled + "\n"); err != nil {
-		return fmt.Errorf("saving .env: %w", err)
+	tracing.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.GetEnvName()))
+	return nil
+}
+
+// fsys returns the file system the environment is stored in.
+func (e *Environment) fsys() vfs.Fs {
+	if e.fs == nil {
+		return vfs.OsFs()
 	}
 
-	if err := envFile.Sync(); err != nil {
-		return fmt.Errorf("saving .env: %w", err)
+	return e.fs
+}
+
+// readDotenv reads the key value pairs of the .env file, like godotenv.Read.
+func (e *Environment) readDotenv(path string) (map[string]string, error) {
+	contents, err := e.fsys().ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	tracing.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.GetEnvName()))
-	return nil
+	return godotenv.Unmarshal(string(contents))
 }
 
 // GetEnvName is shorthand for Getenv(EnvNameEnvVarName)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
@@ -172,6 +173,35 @@ func Test_SaveAndReload(t *testing.T) {
 	require.Equal(t, "http://api.example.com/updated", value)
 }
 
+func TestGetEnvironmentInMemory(t *testing.T) {
+	fs := vfs.NewMemFs()
+	azdCtx := azdcontext.NewAzdContextWithFs(fs, filepath.FromSlash("/project"))
+	require.NoError(t, azdCtx.NewEnvironment("dev"))
+
+	env, err := GetEnvironment(azdCtx, "dev")
+	require.NoError(t, err)
+
+	env.SetEnvName("dev")
+	env.SetLocation("eastus2")
+	require.NoError(t, env.Config.Set("auth.profile", "work"))
+	require.NoError(t, env.Save())
+
+	dotenv, err := fs.ReadFile(azdCtx.EnvironmentDotEnvPath("dev"))
+	require.NoError(t, err)
+	require.Contains(t, string(dotenv), `AZURE_LOCATION="eastus2"`)
+
+	env, err = GetEnvironment(azdCtx, "dev")
+	require.NoError(t, err)
+	require.Equal(t, "eastus2", env.GetLocation())
+
+	profile, has := env.Config.Get("auth.profile")
+	require.True(t, has)
+	require.Equal(t, "work", profile)
+
+	_, err = GetEnvironment(azdCtx, "prod")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
 func TestCleanName(t *testing.T) {
 	require.Equal(t, "already-clean-name", CleanName("already-clean-name"))
 	require.Equal(t, "was-CLEANED-with--bad--things-(123)", CleanName("was CLEANED with *bad* things (123)"))
@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// LockFileName is the name of the file, stored in the environment's [Root], which marks the environment as locked by a
+// running azd process.
+const LockFileName = "azd.lock"
+
+// LockInfo describes the azd process holding the lock of an environment.
+type LockInfo struct {
+	User       string    `json:"user"`
+	Host       string    `json:"host"`
+	Pid        int       `json:"pid"`
+	Command    string    `json:"command"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+}
+
+// EnvironmentLockedError is returned when the environment is locked by another azd process.
+type EnvironmentLockedError struct {
+	EnvName string
+	Info    LockInfo
+}
+
+func (e *EnvironmentLockedError) Error() string {
+	return fmt.Sprintf(
+		"environment '%s' is locked by %s@%s since %s (running 'azd %s', pid %d). Wait for that operation to complete, "+
+			"or run again with --break-lock if the lock is stale",
+		e.EnvName,
+		e.Info.User,
+		e.Info.Host,
+		e.Info.AcquiredAt.Local().Format(time.RFC1123),
+		e.Info.Command,
+		e.Info.Pid,
+	)
+}
+
+// Lock is an acquired environment lock. Call [Lock.Release] once the operation that required the lock completes.
+type Lock struct {
+	path string
+}
+
+// Lock takes the lock of the environment for the given command, so concurrent azd invocations, such as two CI jobs,
+// cannot provision or deploy the same environment at the same time. When the environment is already locked an
+// *EnvironmentLockedError is returned, unless breakLock is set, in which case the existing lock is replaced.
+// Environments which are not persisted to disk are never locked.
+func (e *Environment) Lock(command string, breakLock bool) (*Lock, error) {
+	if e.Root == "" {
+		return &Lock{}, nil
+	}
+
+	if err := os.MkdirAll(e.Root, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("failed to create a directory: %w", err)
+	}
+
+	lockPath := filepath.Join(e.Root, LockFileName)
+	info := newLockInfo(command)
+
+	contents, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling lock: %w", err)
+	}
+
+	if breakLock {
+		if err := os.Remove(lockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("breaking lock %s: %w", lockPath, err)
+		}
+	}
+
+	lockFile, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, osutil.PermissionFile)
+	if errors.Is(err, os.ErrExist) {
+		return nil, lockedError(e.GetEnvName(), lockPath)
+	} else if err != nil {
+		return nil, fmt.Errorf("creating lock %s: %w", lockPath, err)
+	}
+	defer lockFile.Close()
+
+	if _, err := lockFile.Write(contents); err != nil {
+		_ = os.Remove(lockPath)
+		return nil, fmt.Errorf("writing lock %s: %w", lockPath, err)
+	}
+
+	return &Lock{path: lockPath}, nil
+}
+
+// Release releases the lock. Failing to release the lock is logged but not fatal, the lock can be broken by the next
+// invocation with --break-lock.
+func (l *Lock) Release() {
+	if l.path == "" {
+		return
+	}
+
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("failed to release environment lock %s: %v", l.path, err)
+	}
+}
+
+// lockedError builds the error describing the current owner of an existing lock.
+func lockedError(envName string, lockPath string) error {
+	lockedErr := &EnvironmentLockedError{EnvName: envName}
+
+	contents, err := os.ReadFile(lockPath)
+	if err == nil {
+		err = json.Unmarshal(contents, &lockedErr.Info)
+	}
+
+	if err != nil {
+		// The lock may have been written partially or by an incompatible version, the environment is still locked.
+		log.Printf("failed reading environment lock %s: %v", lockPath, err)
+
+		if stat, statErr := os.Stat(lockPath); statErr == nil {
+			lockedErr.Info.AcquiredAt = stat.ModTime()
+		}
+	}
+
+	if lockedErr.Info.User == "" {
+		lockedErr.Info.User = "unknown"
+	}
+
+	return lockedErr
+}
+
+func newLockInfo(command string) LockInfo {
+	info := LockInfo{
+		Pid:        os.Getpid(),
+		Command:    command,
+		AcquiredAt: time.Now().UTC(),
+	}
+
+	if current, err := user.Current(); err == nil {
+		info.User = current.Username
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		info.Host = host
+	}
+
+	return info
+}
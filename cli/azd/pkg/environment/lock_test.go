@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+
+	env := EmptyWithRoot(t.TempDir())
+	env.SetEnvName("dev")
+
+	lock, err := env.Lock("provision", false)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(env.Root, LockFileName))
+
+	// A second invocation fails with the details of the current owner
+	_, err = env.Lock("deploy", false)
+	var lockedErr *EnvironmentLockedError
+	require.ErrorAs(t, err, &lockedErr)
+	require.Equal(t, "dev", lockedErr.EnvName)
+	require.Equal(t, "provision", lockedErr.Info.Command)
+	require.Equal(t, os.Getpid(), lockedErr.Info.Pid)
+	require.Contains(t, err.Error(), "--break-lock")
+
+	// Breaking the lock replaces the owner
+	brokenLock, err := env.Lock("deploy", true)
+	require.NoError(t, err)
+
+	brokenLock.Release()
+	require.NoFileExists(t, filepath.Join(env.Root, LockFileName))
+
+	// Releasing an already released lock is a no-op
+	lock.Release()
+
+	lock, err = env.Lock("deploy", false)
+	require.NoError(t, err)
+	lock.Release()
+}
+
+func TestLockEphemeral(t *testing.T) {
+	t.Parallel()
+
+	lock, err := Ephemeral().Lock("provision", false)
+	require.NoError(t, err)
+	lock.Release()
+}
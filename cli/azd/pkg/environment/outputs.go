@@ -0,0 +1,215 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// OutputsFileName is the name of the file, stored in the environment's [Root], keeping the outputs of the infrastructure
+// with their types. The `.env` file only has their values as strings, the arrays and objects marshalled as JSON.
+const OutputsFileName = "outputs.json"
+
+// OutputsPrefix is the prefix of the keys of [Environment.Getenv] and [Environment.LookupEnv] resolving a path of the
+// outputs, ex) outputs.endpoints[0].url, for azure.yaml to reference the items and properties of structured outputs.
+const OutputsPrefix = "outputs."
+
+// Output is an output of the infrastructure, with its type preserved.
+type Output struct {
+	// The type of the output, ex) string, number, bool, object or array
+	Type string `json:"type"`
+	// The value of the output. Numbers are [json.Number], objects map[string]any and arrays []any once loaded.
+	Value any `json:"value"`
+}
+
+// String returns the value as it's set in the `.env` file: strings as is, arrays and objects as JSON.
+func (o Output) String() string {
+	return formatOutputValue(o.Value)
+}
+
+// SaveOutputs adds the outputs of the infrastructure to the outputs of the environment, replacing the outputs of the same
+// name. Environments which are not persisted to disk have no outputs.
+func (e *Environment) SaveOutputs(outputs map[string]Output) error {
+	if e.Root == "" || len(outputs) == 0 {
+		return nil
+	}
+
+	saved, err := e.Outputs()
+	if err != nil {
+		return err
+	}
+
+	for name, output := range outputs {
+		saved[name] = output
+	}
+
+	contents, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling outputs: %w", err)
+	}
+
+	if err := e.fsys().MkdirAll(e.Root, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("failed to create a directory: %w", err)
+	}
+
+	outputsPath := filepath.Join(e.Root, OutputsFileName)
+	if err := e.fsys().WriteFile(outputsPath, contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing outputs %s: %w", outputsPath, err)
+	}
+
+	return nil
+}
+
+// Outputs returns the outputs of the infrastructure of the environment by name, empty until it's provisioned.
+func (e *Environment) Outputs() (map[string]Output, error) {
+	if e.Root == "" {
+		return map[string]Output{}, nil
+	}
+
+	outputsPath := filepath.Join(e.Root, OutputsFileName)
+	contents, err := e.fsys().ReadFile(outputsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Output{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading outputs %s: %w", outputsPath, err)
+	}
+
+	// Numbers are kept as they're written, ex) large integers aren't converted to floats
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+
+	outputs := map[string]Output{}
+	if err := decoder.Decode(&outputs); err != nil {
+		return nil, fmt.Errorf("invalid outputs %s: %w", outputsPath, err)
+	}
+
+	return outputs, nil
+}
+
+// OutputValue returns the value at the path of the outputs, ex) endpoints[0].url for the url of the first item of the
+// endpoints output.
+func (e *Environment) OutputValue(path string) (any, error) {
+	outputs, err := e.Outputs()
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := parseOutputPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	output, has := outputs[segments[0]]
+	if !has {
+		for name, o := range outputs {
+			if strings.EqualFold(name, segments[0]) {
+				output, has = o, true
+				break
+			}
+		}
+	}
+
+	if !has {
+		return nil, fmt.Errorf("output '%s' not found", segments[0])
+	}
+
+	value := output.Value
+	// The path of the value, ex) endpoints[0]
+	current := segments[0]
+	for _, segment := range segments[1:] {
+		switch v := value.(type) {
+		case map[string]any:
+			property, has := v[segment]
+			if !has {
+				return nil, fmt.Errorf("property '%s' of '%s' not found", segment, current)
+			}
+			value = property
+			current += "." + segment
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("index '%s' of '%s' out of range, it has %d items", segment, current, len(v))
+			}
+			value = v[index]
+			current += "[" + segment + "]"
+		default:
+			return nil, fmt.Errorf("'%s' has no property '%s'", current, segment)
+		}
+	}
+
+	return value, nil
+}
+
+// lookupOutput resolves a key of the outputs prefixed with [OutputsPrefix] as a string, see [Environment.OutputValue].
+func (e *Environment) lookupOutput(key string) (string, bool) {
+	value, err := e.OutputValue(strings.TrimPrefix(key, OutputsPrefix))
+	if err != nil {
+		log.Printf("resolving %s: %v", key, err)
+		return "", false
+	}
+
+	return formatOutputValue(value), true
+}
+
+// parseOutputPath splits the path of an output into the name of the output, and the properties and indexes of its
+// value, ex) endpoints[0].url into endpoints, 0 and url
+func parseOutputPath(path string) ([]string, error) {
+	segments := []string{}
+	for _, part := range strings.Split(path, ".") {
+		name, indexes, _ := strings.Cut(part, "[")
+		if name == "" && len(segments) == 0 {
+			return nil, fmt.Errorf("invalid output path '%s', it must start with the name of an output", path)
+		}
+
+		if name != "" {
+			segments = append(segments, name)
+		}
+
+		if indexes == "" {
+			if name == "" {
+				return nil, fmt.Errorf("invalid output path '%s'", path)
+			}
+			continue
+		}
+
+		// ex) 0][1] of matrix[0][1]
+		for _, index := range strings.Split(indexes, "[") {
+			if !strings.HasSuffix(index, "]") {
+				return nil, fmt.Errorf("invalid output path '%s', missing ']'", path)
+			}
+			segments = append(segments, strings.TrimSuffix(index, "]"))
+		}
+	}
+
+	return segments, nil
+}
+
+// formatOutputValue formats the value of an output as a string, arrays and objects as JSON
+func formatOutputValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		contents, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+
+		return string(contents)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
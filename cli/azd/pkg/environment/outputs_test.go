@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputs(t *testing.T) {
+	t.Parallel()
+
+	env := EmptyWithRoot(t.TempDir())
+
+	outputs, err := env.Outputs()
+	require.NoError(t, err)
+	require.Empty(t, outputs)
+
+	require.NoError(t, env.SaveOutputs(map[string]Output{
+		"endpoints": {Type: "array", Value: []any{
+			map[string]any{"name": "api", "url": "https://api.contoso.com"},
+			map[string]any{"name": "web", "url": "https://contoso.com"},
+		}},
+		"settings": {Type: "object", Value: map[string]any{"replicas": 3, "zones": []any{1, 2}}},
+		"capacity": {Type: "number", Value: 9007199254740993},
+		"name":     {Type: "string", Value: "contoso"},
+	}))
+	require.NoError(t, env.SaveOutputs(map[string]Output{
+		"name":    {Type: "string", Value: "fabrikam"},
+		"enabled": {Type: "bool", Value: true},
+	}))
+
+	outputs, err = env.Outputs()
+	require.NoError(t, err)
+	require.Len(t, outputs, 5)
+	require.Equal(t, Output{Type: "number", Value: json.Number("9007199254740993")}, outputs["capacity"])
+	require.Equal(t, "fabrikam", outputs["name"].String())
+	require.Equal(t, `{"replicas":3,"zones":[1,2]}`, outputs["settings"].String())
+
+	t.Run("OutputValue", func(t *testing.T) {
+		tests := map[string]struct {
+			path     string
+			expected any
+		}{
+			"Output":          {path: "name", expected: "fabrikam"},
+			"CaseInsensitive": {path: "Name", expected: "fabrikam"},
+			"Property":        {path: "endpoints[1].url", expected: "https://contoso.com"},
+			"Index":           {path: "settings.zones[1]", expected: json.Number("2")},
+			"Number":          {path: "settings.replicas", expected: json.Number("3")},
+			"Bool":            {path: "enabled", expected: true},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				value, err := env.OutputValue(test.path)
+				require.NoError(t, err)
+				require.Equal(t, test.expected, value)
+			})
+		}
+	})
+
+	t.Run("OutputValueErrors", func(t *testing.T) {
+		tests := map[string]struct {
+			path     string
+			expected string
+		}{
+			"MissingOutput":   {path: "missing", expected: "output 'missing' not found"},
+			"MissingProperty": {path: "endpoints[0].port", expected: "property 'port' of 'endpoints[0]' not found"},
+			"OutOfRange": {
+				path:     "endpoints[2].url",
+				expected: "index '2' of 'endpoints' out of range, it has 2 items",
+			},
+			"NotObject":    {path: "name.length", expected: "'name' has no property 'length'"},
+			"MissingName":  {path: "[0]", expected: "invalid output path '[0]', it must start with the name of an output"},
+			"MissingBrace": {path: "endpoints[0", expected: "invalid output path 'endpoints[0', missing ']'"},
+			"EmptyName":    {path: "settings..zones", expected: "invalid output path 'settings..zones'"},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := env.OutputValue(test.path)
+				require.EqualError(t, err, test.expected)
+			})
+		}
+	})
+
+	t.Run("Getenv", func(t *testing.T) {
+		env.DotenvSet("name", "dotenv")
+
+		require.Equal(t, "https://api.contoso.com", env.Getenv("outputs.endpoints[0].url"))
+		require.Equal(t, `{"name":"web","url":"https://contoso.com"}`, env.Getenv("outputs.endpoints[1]"))
+		require.Equal(t, "9007199254740993", env.Getenv("outputs.capacity"))
+		require.Equal(t, "fabrikam", env.Getenv("outputs.name"))
+
+		_, has := env.LookupEnv("outputs.missing")
+		require.False(t, has)
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtectionConfigKey is the key of the environment config section protecting the environment against azd down.
+const ProtectionConfigKey = "protection"
+
+// Protection guards an environment, such as a production environment, against being destroyed by azd down. It is
+// configured in the environment config, ex)
+//
+//	{
+//	  "protection": {
+//	    "protected": true,
+//	    "unlockCode": "...",
+//	    "excludedResources": ["rg-shared-data", "kv-prod"]
+//	  }
+//	}
+type Protection struct {
+	// Requires typing the name of the environment to confirm azd down.
+	Protected bool `json:"protected"`
+	// When set, azd down of a protected environment requires the same value in --unlock-code.
+	UnlockCode string `json:"unlockCode,omitempty"`
+	// Resource groups and resources, by name or resource id, that azd down never deletes.
+	ExcludedResources []string `json:"excludedResources,omitempty"`
+}
+
+// Protection returns the protection configured for the environment, or an unprotected value when not configured.
+func (e *Environment) Protection() (*Protection, error) {
+	protection := &Protection{}
+
+	value, has := e.Config.Get(ProtectionConfigKey)
+	if !has {
+		return protection, nil
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling protection config: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, protection); err != nil {
+		return nil, fmt.Errorf("invalid '%s' config of environment '%s': %w", ProtectionConfigKey, e.GetEnvName(), err)
+	}
+
+	return protection, nil
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtection(t *testing.T) {
+	t.Run("NotConfigured", func(t *testing.T) {
+		env := Ephemeral()
+
+		protection, err := env.Protection()
+		require.NoError(t, err)
+		require.False(t, protection.Protected)
+		require.Empty(t, protection.ExcludedResources)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		env := Ephemeral()
+		require.NoError(t, env.Config.Set(ProtectionConfigKey, map[string]any{
+			"protected":         true,
+			"unlockCode":        "1234",
+			"excludedResources": []any{"rg-shared-data"},
+		}))
+
+		protection, err := env.Protection()
+		require.NoError(t, err)
+		require.Equal(t, &Protection{
+			Protected:         true,
+			UnlockCode:        "1234",
+			ExcludedResources: []string{"rg-shared-data"},
+		}, protection)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		env := Ephemeral()
+		require.NoError(t, env.Config.Set(ProtectionConfigKey, map[string]any{"protected": "yes"}))
+
+		_, err := env.Protection()
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"golang.org/x/exp/slices"
+)
+
+// SharedConfigKey is the key of the environment config section referencing the shared environment of the environment.
+const SharedConfigKey = "shared"
+
+// Shared references a shared environment, ex) the platform resources of a hub environment, whose values are imported
+// into the environment before it's provisioned. It is configured in the environment config, ex)
+//
+//	{
+//	  "shared": {
+//	    "environment": "platform-prod",
+//	    "project": "../platform",
+//	    "values": ["AZURE_CONTAINER_REGISTRY_*", "LOG_ANALYTICS_WORKSPACE_ID"]
+//	  }
+//	}
+type Shared struct {
+	// The name of the shared environment.
+	Environment string `json:"environment"`
+	// The directory of the project of the shared environment, relative to the project of the environment. Defaults to
+	// the project of the environment.
+	Project string `json:"project,omitempty"`
+	// The names of the values imported from the shared environment. Names can contain * wildcards. Defaults to all the
+	// values of the shared environment.
+	Values []string `json:"values,omitempty"`
+}
+
+// sharedExcludedValues are the values of the shared environment describing the shared environment itself, which are
+// never imported.
+var sharedExcludedValues = []string{
+	EnvNameEnvVarName,
+	LocationEnvVarName,
+	LocationsEnvVarName,
+	SubscriptionIdEnvVarName,
+	TenantIdEnvVarName,
+	PrincipalIdEnvVarName,
+	ResourceGroupEnvVarName,
+	PipelinePrincipalIdEnvVarName,
+}
+
+// Shared returns the shared environment referenced by the environment, or nil when not configured.
+func (e *Environment) Shared() (*Shared, error) {
+	value, has := e.Config.Get(SharedConfigKey)
+	if !has {
+		return nil, nil
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling shared config: %w", err)
+	}
+
+	shared := &Shared{}
+	if err := json.Unmarshal(contents, shared); err != nil {
+		return nil, fmt.Errorf("invalid '%s' config of environment '%s': %w", SharedConfigKey, e.GetEnvName(), err)
+	}
+
+	if shared.Environment == "" {
+		return nil, fmt.Errorf("the '%s' config of environment '%s' has no environment", SharedConfigKey, e.GetEnvName())
+	}
+
+	return shared, nil
+}
+
+// ImportShared sets the values of the shared environment referenced by the environment, when configured, on the
+// environment and saves it. The shared environment is read, and never modified. The values that were imported are
+// returned.
+func (e *Environment) ImportShared(azdCtx *azdcontext.AzdContext) (map[string]string, error) {
+	shared, err := e.Shared()
+	if err != nil || shared == nil {
+		return nil, err
+	}
+
+	sharedCtx := azdCtx
+	if shared.Project != "" {
+		sharedCtx = azdcontext.NewAzdContextWithDirectory(
+			filepath.Join(azdCtx.ProjectDirectory(), shared.Project))
+	}
+
+	if sharedCtx.EnvironmentRoot(shared.Environment) == e.Root {
+		return nil, fmt.Errorf("environment '%s' can't be shared with itself", shared.Environment)
+	}
+
+	sharedEnv, err := GetEnvironment(sharedCtx, shared.Environment)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"shared environment '%s' not found in '%s': %w", shared.Environment, sharedCtx.ProjectDirectory(), err)
+	} else if err != nil {
+		return nil, fmt.Errorf("loading shared environment '%s': %w", shared.Environment, err)
+	}
+
+	imported := map[string]string{}
+	for name, value := range sharedEnv.Dotenv() {
+		if slices.Contains(sharedExcludedValues, name) {
+			continue
+		}
+
+		if len(shared.Values) > 0 && !slices.ContainsFunc(shared.Values, func(pattern string) bool {
+			matched, err := path.Match(pattern, name)
+			return err == nil && matched
+		}) {
+			continue
+		}
+
+		e.DotenvSet(name, value)
+		imported[name] = value
+	}
+
+	if len(imported) > 0 {
+		if err := e.Save(); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package environment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestEnvironment(t *testing.T, azdCtx *azdcontext.AzdContext, name string, values map[string]string) {
+	env := EmptyWithRoot(azdCtx.EnvironmentRoot(name))
+	env.SetEnvName(name)
+	for key, value := range values {
+		env.DotenvSet(key, value)
+	}
+	require.NoError(t, env.Save())
+}
+
+func TestImportShared(t *testing.T) {
+	platformValues := map[string]string{
+		SubscriptionIdEnvVarName:                 "PLATFORM_SUBSCRIPTION_ID",
+		LocationEnvVarName:                       "eastus2",
+		ResourceGroupEnvVarName:                  "rg-platform",
+		ContainerRegistryEndpointEnvVarName:      "crplatform.azurecr.io",
+		"AZURE_CONTAINER_REGISTRY_NAME":          "crplatform",
+		"AZURE_LOG_ANALYTICS_WORKSPACE_ID":       "/subscriptions/.../workspaces/log-platform",
+		"AZURE_VNET_APPS_SUBNET_ID":              "/subscriptions/.../subnets/apps",
+		"AZURE_PLATFORM_DIAGNOSTICS_STORAGE_KEY": "key",
+	}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		env := EphemeralWithValues("app", nil)
+
+		imported, err := env.ImportShared(azdCtx)
+		require.NoError(t, err)
+		require.Empty(t, imported)
+	})
+
+	t.Run("AllValues", func(t *testing.T) {
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		createTestEnvironment(t, azdCtx, "platform", platformValues)
+
+		env := EphemeralWithValues("app", map[string]string{LocationEnvVarName: "westus3"})
+		require.NoError(t, env.Config.Set(SharedConfigKey, Shared{Environment: "platform"}))
+
+		imported, err := env.ImportShared(azdCtx)
+		require.NoError(t, err)
+
+		// the values describing the shared environment itself aren't imported
+		require.Equal(t, map[string]string{
+			ContainerRegistryEndpointEnvVarName:      "crplatform.azurecr.io",
+			"AZURE_CONTAINER_REGISTRY_NAME":          "crplatform",
+			"AZURE_LOG_ANALYTICS_WORKSPACE_ID":       "/subscriptions/.../workspaces/log-platform",
+			"AZURE_VNET_APPS_SUBNET_ID":              "/subscriptions/.../subnets/apps",
+			"AZURE_PLATFORM_DIAGNOSTICS_STORAGE_KEY": "key",
+		}, imported)
+		require.Equal(t, "westus3", env.GetLocation())
+		require.Equal(t, "app", env.GetEnvName())
+		require.Equal(t, "crplatform", env.Getenv("AZURE_CONTAINER_REGISTRY_NAME"))
+	})
+
+	t.Run("SelectedValuesOfAnotherProject", func(t *testing.T) {
+		root := t.TempDir()
+		platformCtx := azdcontext.NewAzdContextWithDirectory(filepath.Join(root, "platform"))
+		createTestEnvironment(t, platformCtx, "platform-prod", platformValues)
+
+		azdCtx := azdcontext.NewAzdContextWithDirectory(filepath.Join(root, "app"))
+		createTestEnvironment(t, azdCtx, "app-prod", nil)
+		env, err := GetEnvironment(azdCtx, "app-prod")
+		require.NoError(t, err)
+		require.NoError(t, env.Config.Set(SharedConfigKey, map[string]any{
+			"environment": "platform-prod",
+			"project":     "../platform",
+			"values":      []any{"AZURE_CONTAINER_REGISTRY_*", "AZURE_VNET_*"},
+		}))
+
+		imported, err := env.ImportShared(azdCtx)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			ContainerRegistryEndpointEnvVarName: "crplatform.azurecr.io",
+			"AZURE_CONTAINER_REGISTRY_NAME":     "crplatform",
+			"AZURE_VNET_APPS_SUBNET_ID":         "/subscriptions/.../subnets/apps",
+		}, imported)
+
+		// the imported values are saved with the environment, and the shared environment is left as is
+		saved, err := GetEnvironment(azdCtx, "app-prod")
+		require.NoError(t, err)
+		require.Equal(t, "crplatform", saved.Getenv("AZURE_CONTAINER_REGISTRY_NAME"))
+
+		platform, err := GetEnvironment(platformCtx, "platform-prod")
+		require.NoError(t, err)
+		require.Equal(t, platformValues[SubscriptionIdEnvVarName], platform.GetSubscriptionId())
+		require.Empty(t, platform.Config.Raw())
+	})
+
+	t.Run("SharedNotFound", func(t *testing.T) {
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		env := EphemeralWithValues("app", nil)
+		require.NoError(t, env.Config.Set(SharedConfigKey, Shared{Environment: "platform"}))
+
+		_, err := env.ImportShared(azdCtx)
+		require.ErrorContains(t, err, "shared environment 'platform' not found")
+	})
+
+	t.Run("SharedWithItself", func(t *testing.T) {
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		createTestEnvironment(t, azdCtx, "app", nil)
+		env, err := GetEnvironment(azdCtx, "app")
+		require.NoError(t, err)
+		require.NoError(t, env.Config.Set(SharedConfigKey, Shared{Environment: "app"}))
+
+		_, err = env.ImportShared(azdCtx)
+		require.ErrorContains(t, err, "can't be shared with itself")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		env := EphemeralWithValues("app", nil)
+		require.NoError(t, env.Config.Set(SharedConfigKey, map[string]any{"values": []any{"*"}}))
+
+		_, err := env.Shared()
+		require.ErrorContains(t, err, "has no environment")
+	})
+}
@@ -12,6 +12,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/azure/azure-dev/cli/azd/pkg/runs"
 )
 
 // Settings to modify the way CmdTree is executed
@@ -89,6 +93,16 @@ type commandRunner struct {
 // NOTE: on Windows the command will automatically be run within a shell. This means .bat/.cmd
 // file based commands should just work.
 func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error) {
+	// Commands run in the container of the context, when set, except docker which runs the container
+	if container := ContainerFromContext(ctx); container != nil && args.Cmd != "docker" {
+		containerArgs, err := container.runArgs(args)
+		if err != nil {
+			return RunResult{}, err
+		}
+
+		args = containerArgs
+	}
+
 	// use the shell on Windows since most commands are actually just batch files wrapping
 	// real commands. And even if they're not, this will work fine without having to do any
 	// probing or checking.
@@ -123,6 +137,10 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 		if args.Stderr != nil {
 			cmd.Stderr = io.MultiWriter(args.Stderr, &stderr)
 		}
+
+		if args.Stdout != nil {
+			cmd.Stdout = args.Stdout
+		}
 	}
 
 	debugLogging := r.debugLogging
@@ -131,11 +149,13 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 	}
 
 	logMsg := logBuilder{
-		args: append([]string{args.Cmd}, args.Args...),
-		env:  args.Env,
+		args:    append([]string{args.Cmd}, args.Args...),
+		env:     args.Env,
+		started: time.Now(),
 	}
 	defer func() {
 		logMsg.Write(debugLogging, args.SensitiveData)
+		logMsg.Record(ctx, args.SensitiveData)
 	}()
 
 	if err := cmd.Start(); err != nil {
@@ -186,6 +206,13 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 }
 
 func (r *commandRunner) RunList(ctx context.Context, commands []string, args RunArgs) (RunResult, error) {
+	if ContainerFromContext(ctx) != nil {
+		args.Cmd = strings.Join(commands, " && ")
+		args.Args = nil
+		args.UseShell = true
+		return r.Run(ctx, args)
+	}
+
 	process, err := newCmdTree(ctx, "", commands, true, false)
 	if err != nil {
 		return NewRunResult(-1, "", ""), err
@@ -212,11 +239,13 @@ func (r *commandRunner) RunList(ctx context.Context, commands []string, args Run
 
 	logMsg := logBuilder{
 		// use the actual shell command invoked in the log message
-		args: process.Cmd.Args,
-		env:  args.Env,
+		args:    process.Cmd.Args,
+		env:     args.Env,
+		started: time.Now(),
 	}
 	defer func() {
 		logMsg.Write(debugLogging, args.SensitiveData)
+		logMsg.Record(ctx, args.SensitiveData)
 	}()
 
 	if err := process.Start(); err != nil {
@@ -256,14 +285,44 @@ func appendEnv(env []string) []string {
 
 // logBuilder builds messages for running of commands.
 type logBuilder struct {
-	args []string
-	env  []string
+	args    []string
+	env     []string
+	started time.Time
 
 	// Either result or err is expected to be set, but not both.
 	result *RunResult
 	err    error
 }
 
+// Record records the invocation of the tool in the run of ctx, if any.
+func (l *logBuilder) Record(ctx context.Context, sensitiveArgsData []string) {
+	args := []string{}
+	for _, arg := range RedactSensitiveArgs(l.args, sensitiveArgsData) {
+		args = append(args, RedactSensitiveData(arg))
+	}
+
+	event := &runs.Event{
+		Type:       runs.ToolEvent,
+		Name:       filepath.Base(args[0]),
+		Args:       args[1:],
+		Status:     runs.StatusSucceeded,
+		DurationMs: time.Since(l.started).Milliseconds(),
+	}
+
+	if l.result != nil {
+		exitCode := l.result.ExitCode
+		event.ExitCode = &exitCode
+		if exitCode != 0 {
+			event.Status = runs.StatusFailed
+		}
+	} else if l.err != nil {
+		event.Status = runs.StatusFailed
+		event.Error = l.err.Error()
+	}
+
+	runs.Record(ctx, event)
+}
+
 // Write writes the log message to the log file. debug enables debug logging.
 func (l *logBuilder) Write(debug bool, sensitiveArgsData []string) {
 	msg := strings.Builder{}
@@ -300,7 +359,7 @@ func (l *logBuilder) Write(debug bool, sensitiveArgsData []string) {
 		}
 	}
 
-	log.Print(msg.String())
+	log.Print(redact.String(msg.String()))
 }
 
 // newCmdTree creates a `CmdTree`, optionally using a shell appropriate for windows
@@ -0,0 +1,81 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Container runs the commands of a context in a container instead of on the host, ex) for the restore and build of a
+// service to be reproducible without installing the SDK of its language
+type Container struct {
+	// The image of the container, ex) node:20
+	Image string
+	// The directories of the host mounted in the container at the same paths, ex) the source of a service, for the paths
+	// of the commands to be the same in the container
+	Mounts []string
+}
+
+type containerContextKey struct{}
+
+// WithContainer returns a context running the commands of the [CommandRunner] in the container, except docker itself
+func WithContainer(ctx context.Context, container *Container) context.Context {
+	return context.WithValue(ctx, containerContextKey{}, container)
+}
+
+// ContainerFromContext returns the container of the context the commands run in, nil when they run on the host
+func ContainerFromContext(ctx context.Context) *Container {
+	container, _ := ctx.Value(containerContextKey{}).(*Container)
+	return container
+}
+
+// runArgs returns the args running the command in the container with docker run. The environment variables of the
+// command are passed by their names, for their values not to show up in the arguments of docker.
+func (c *Container) runArgs(args RunArgs) (RunArgs, error) {
+	// Windows paths can't be mounted at the same paths in Linux containers
+	if runtime.GOOS == "windows" {
+		return RunArgs{}, errors.New("running commands in containers isn't supported on Windows")
+	}
+
+	dockerArgs := []string{"run", "--rm"}
+	if args.Interactive || args.StdIn != nil {
+		dockerArgs = append(dockerArgs, "-i")
+	}
+
+	// The files the command writes to the mounts are owned by the user of azd, whose home isn't in the container
+	if runtime.GOOS == "linux" {
+		dockerArgs = append(dockerArgs, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), "-e", "HOME=/tmp")
+	}
+
+	for _, mount := range c.Mounts {
+		dockerArgs = append(dockerArgs, "-v", fmt.Sprintf("%s:%s", mount, mount))
+	}
+
+	if args.Cwd != "" {
+		dockerArgs = append(dockerArgs, "-w", args.Cwd)
+	}
+
+	for _, env := range args.Env {
+		name, _, _ := strings.Cut(env, "=")
+		dockerArgs = append(dockerArgs, "-e", name)
+	}
+
+	dockerArgs = append(dockerArgs, c.Image)
+	if args.UseShell {
+		dockerArgs = append(dockerArgs, "sh", "-c", strings.Join(append([]string{args.Cmd}, args.Args...), " "))
+	} else {
+		dockerArgs = append(dockerArgs, args.Cmd)
+		dockerArgs = append(dockerArgs, args.Args...)
+	}
+
+	containerArgs := args
+	containerArgs.Cmd = "docker"
+	containerArgs.Args = dockerArgs
+	containerArgs.Cwd = ""
+	containerArgs.UseShell = false
+
+	return containerArgs, nil
+}
@@ -0,0 +1,70 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerRunArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("running commands in containers isn't supported on Windows")
+	}
+
+	container := &Container{Image: "node:20", Mounts: []string{"/src/todo"}}
+
+	user := []string{}
+	if runtime.GOOS == "linux" {
+		user = []string{"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), "-e", "HOME=/tmp"}
+	}
+
+	t.Run("Command", func(t *testing.T) {
+		args := NewRunArgs("npm", "install").
+			WithCwd("/src/todo/web").
+			WithEnv([]string{"NODE_ENV=production"})
+
+		containerArgs, err := container.runArgs(args)
+		require.NoError(t, err)
+		require.Equal(t, "docker", containerArgs.Cmd)
+		require.Equal(t, "", containerArgs.Cwd)
+		require.Equal(t, []string{"NODE_ENV=production"}, containerArgs.Env)
+
+		expected := append([]string{"run", "--rm"}, user...)
+		expected = append(expected,
+			"-v", "/src/todo:/src/todo",
+			"-w", "/src/todo/web",
+			"-e", "NODE_ENV",
+			"node:20", "npm", "install")
+		require.Equal(t, expected, containerArgs.Args)
+	})
+
+	t.Run("Shell", func(t *testing.T) {
+		args := NewRunArgs("npm install && npm run build").WithShell(true)
+
+		containerArgs, err := container.runArgs(args)
+		require.NoError(t, err)
+		require.False(t, containerArgs.UseShell)
+		require.Equal(t, []string{"node:20", "sh", "-c", "npm install && npm run build"},
+			containerArgs.Args[len(containerArgs.Args)-4:])
+	})
+
+	t.Run("WithStdIn", func(t *testing.T) {
+		args := NewRunArgs("cat").WithStdIn(strings.NewReader("input"))
+
+		containerArgs, err := container.runArgs(args)
+		require.NoError(t, err)
+		require.Equal(t, []string{"run", "--rm", "-i"}, containerArgs.Args[:3])
+	})
+}
+
+func TestContainerFromContext(t *testing.T) {
+	require.Nil(t, ContainerFromContext(context.Background()))
+
+	container := &Container{Image: "golang:1.22"}
+	require.Same(t, container, ContainerFromContext(WithContainer(context.Background(), container)))
+}
@@ -18,6 +18,10 @@ type RunArgs struct {
 	// NOTE: RunResult.Stderr will still contain stderr output.
 	Stderr io.Writer
 
+	// Stdout will receive the text written to Stdout by the command as it is written, ex) when streaming logs.
+	// NOTE: RunResult.Stdout will be empty.
+	Stdout io.Writer
+
 	// Enables debug logging.
 	DebugLogging *bool
 
@@ -91,3 +95,15 @@ func (b RunArgs) WithStdIn(stdIn io.Reader) RunArgs {
 	b.StdIn = stdIn
 	return b
 }
+
+// Updates the writer receiving the output of the command as it is written
+func (b RunArgs) WithStdOut(stdOut io.Writer) RunArgs {
+	b.Stdout = stdOut
+	return b
+}
+
+// Updates the writer receiving a copy of the error output of the command as it is written
+func (b RunArgs) WithStdErr(stdErr io.Writer) RunArgs {
+	b.Stderr = stdErr
+	return b
+}
@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -21,12 +22,14 @@ func TestNewRunArgs(t *testing.T) {
 	})
 
 	t.Run("WithOverrides", func(t *testing.T) {
+		stdOut := &bytes.Buffer{}
 		runArgs := NewRunArgs("az", "login").
 			WithCwd("cwd").
 			WithEnv([]string{"foo", "bar"}).
 			WithInteractive(true).
 			WithShell(true).
 			WithDebugLogging(true).
+			WithStdOut(stdOut).
 			AppendParams("param1", "param2")
 
 		require.Equal(t, "az", runArgs.Cmd)
@@ -38,5 +41,6 @@ func TestNewRunArgs(t *testing.T) {
 		require.Equal(t, true, *runArgs.DebugLogging)
 		require.Len(t, runArgs.Env, 2)
 		require.Equal(t, runArgs.Env, []string{"foo", "bar"})
+		require.Same(t, stdOut, runArgs.Stdout)
 	})
 }
@@ -0,0 +1,361 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package forwarding relays the events of the Service Bus and Event Grid topics provisioned for an environment to a
+// locally running service, enabling local testing of event-driven applications against real topics.
+package forwarding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/google/uuid"
+)
+
+type SourceKind string
+
+const (
+	ServiceBusTopicSource SourceKind = "servicebus"
+	EventGridTopicSource  SourceKind = "eventgrid"
+)
+
+const (
+	serviceBusNamespaceResourceType  = "Microsoft.ServiceBus/namespaces"
+	eventGridTopicResourceType       = "Microsoft.EventGrid/topics"
+	eventGridSystemTopicResourceType = "Microsoft.EventGrid/systemTopics"
+
+	receiveTimeout = 30 * time.Second
+	retryDelay     = 5 * time.Second
+	cleanupTimeout = 30 * time.Second
+)
+
+// Source is a Service Bus topic or an Event Grid topic whose events are forwarded
+type Source struct {
+	Kind          SourceKind
+	ResourceGroup string
+	// The name of the Service Bus namespace or of the Event Grid topic
+	Name string
+	// The name of the topic of the Service Bus namespace
+	Topic string
+	// The resource id of the Event Grid topic
+	Id string
+}
+
+func (s Source) String() string {
+	if s.Kind == ServiceBusTopicSource {
+		return fmt.Sprintf("Service Bus topic %s/%s", s.Name, s.Topic)
+	}
+
+	return fmt.Sprintf("Event Grid topic %s", s.Name)
+}
+
+// Options configures where events are forwarded
+type Options struct {
+	// The URL of the locally running service events are posted to, ex) http://localhost:8080/events
+	TargetUrl string
+	// The public URL of a tunnel to the relay port, ex) a dev tunnel. Event Grid delivers events to webhooks, so Event
+	// Grid topics are only forwarded through a tunnel.
+	TunnelUrl string
+	// The local port of the relay receiving the Event Grid events from the tunnel
+	RelayPort int
+}
+
+// Forwarder creates temporary subscriptions to the sources and relays their events to the local service. The
+// subscriptions are deleted when forwarding stops, or by Azure once idle or expired when azd exits abruptly.
+type Forwarder struct {
+	azCli          azcli.AzCli
+	console        input.Console
+	httpClient     httputil.HttpClient
+	subscriptionId string
+	options        Options
+	// The name of the temporary subscriptions
+	subscriptionName string
+
+	consoleMutex sync.Mutex
+}
+
+func NewForwarder(
+	azCli azcli.AzCli,
+	console input.Console,
+	httpClient httputil.HttpClient,
+	subscriptionId string,
+	options Options,
+) *Forwarder {
+	return &Forwarder{
+		azCli:            azCli,
+		console:          console,
+		httpClient:       httpClient,
+		subscriptionId:   subscriptionId,
+		options:          options,
+		subscriptionName: fmt.Sprintf("azd-dev-%s", strings.Split(uuid.NewString(), "-")[0]),
+	}
+}
+
+// Sources returns the topics of the Service Bus namespaces and the Event Grid topics among the resources
+func (f *Forwarder) Sources(ctx context.Context, resources []azcli.AzCliResource) ([]Source, error) {
+	sources := []Source{}
+
+	for _, resource := range resources {
+		resourceId, err := arm.ParseResourceID(resource.Id)
+		if err != nil {
+			return nil, fmt.Errorf("parsing resource id '%s': %w", resource.Id, err)
+		}
+		resourceGroup := resourceId.ResourceGroupName
+
+		switch {
+		case strings.EqualFold(resource.Type, serviceBusNamespaceResourceType):
+			topics, err := f.azCli.ListServiceBusTopics(ctx, f.subscriptionId, resourceGroup, resource.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, topic := range topics {
+				sources = append(sources, Source{
+					Kind:          ServiceBusTopicSource,
+					ResourceGroup: resourceGroup,
+					Name:          resource.Name,
+					Topic:         topic,
+				})
+			}
+		case strings.EqualFold(resource.Type, eventGridTopicResourceType),
+			strings.EqualFold(resource.Type, eventGridSystemTopicResourceType):
+			sources = append(sources, Source{
+				Kind:          EventGridTopicSource,
+				ResourceGroup: resourceGroup,
+				Name:          resource.Name,
+				Id:            resource.Id,
+			})
+		}
+	}
+
+	return sources, nil
+}
+
+// Run forwards the events of the sources until the context is cancelled, then deletes the temporary subscriptions.
+// Event Grid topics are skipped with a warning when no tunnel is configured.
+func (f *Forwarder) Run(ctx context.Context, sources []Source) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	var relay *http.Server
+	cleanups := []func(ctx context.Context) error{}
+
+	defer func() {
+		// Stop receiving before deleting the subscriptions
+		cancel()
+		wg.Wait()
+
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+
+		for _, cleanup := range cleanups {
+			if err := cleanup(cleanupCtx); err != nil {
+				log.Printf("failed deleting temporary subscription: %v", err)
+			}
+		}
+
+		if relay != nil {
+			_ = relay.Shutdown(cleanupCtx)
+		}
+	}()
+
+	for _, source := range sources {
+		source := source
+
+		switch source.Kind {
+		case ServiceBusTopicSource:
+			if err := f.azCli.CreateServiceBusTopicSubscription(
+				ctx, f.subscriptionId, source.ResourceGroup, source.Name, source.Topic, f.subscriptionName,
+			); err != nil {
+				return err
+			}
+
+			cleanups = append(cleanups, func(ctx context.Context) error {
+				return f.azCli.DeleteServiceBusTopicSubscription(
+					ctx, f.subscriptionId, source.ResourceGroup, source.Name, source.Topic, f.subscriptionName)
+			})
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f.forwardServiceBus(ctx, source)
+			}()
+		case EventGridTopicSource:
+			if f.options.TunnelUrl == "" {
+				f.message(ctx, output.WithWarningFormat(
+					"WARNING: Skipping %s, forwarding Event Grid events requires --tunnel-url.", source))
+				continue
+			}
+
+			if relay == nil {
+				var err error
+				if relay, err = f.startRelay(); err != nil {
+					return err
+				}
+			}
+
+			endpointUrl := strings.TrimSuffix(f.options.TunnelUrl, "/") + "/"
+			if err := f.azCli.CreateEventGridSubscription(
+				ctx, f.subscriptionId, source.Id, f.subscriptionName, endpointUrl,
+			); err != nil {
+				return err
+			}
+
+			cleanups = append(cleanups, func(ctx context.Context) error {
+				return f.azCli.DeleteEventGridSubscription(ctx, f.subscriptionId, source.Id, f.subscriptionName)
+			})
+		}
+
+		f.message(ctx, fmt.Sprintf("Forwarding %s to %s", source, output.WithHighLightFormat(f.options.TargetUrl)))
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// forwardServiceBus receives the messages of the temporary subscription of the topic and posts them to the service
+func (f *Forwarder) forwardServiceBus(ctx context.Context, source Source) {
+	entityPath := fmt.Sprintf("%s/subscriptions/%s", source.Topic, f.subscriptionName)
+
+	for ctx.Err() == nil {
+		message, err := f.azCli.ReceiveServiceBusMessage(ctx, f.subscriptionId, source.Name, entityPath, receiveTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Printf("failed receiving message from %s: %v", source, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		if message == nil {
+			continue
+		}
+
+		headers := http.Header{}
+		headers.Set("Content-Type", message.ContentType)
+		if message.BrokerProperties != "" {
+			headers.Set("BrokerProperties", message.BrokerProperties)
+		}
+
+		f.forward(ctx, source, message.Body, headers)
+	}
+}
+
+// forward posts an event to the service and reports the result
+func (f *Forwarder) forward(ctx context.Context, source Source, body []byte, headers http.Header) int {
+	statusCode := 0
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.options.TargetUrl, bytes.NewReader(body))
+	if err == nil {
+		for name, values := range headers {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+
+		var response *http.Response
+		if response, err = f.httpClient.Do(req); err == nil {
+			statusCode = response.StatusCode
+			response.Body.Close()
+		}
+	}
+
+	if err != nil {
+		f.message(ctx, output.WithWarningFormat("WARNING: Failed forwarding event of %s: %v", source, err))
+		return http.StatusBadGateway
+	}
+
+	f.message(ctx, fmt.Sprintf("  %s: event forwarded (%d)", source, statusCode))
+	return statusCode
+}
+
+// startRelay listens on the relay port for the Event Grid events delivered through the tunnel
+func (f *Forwarder) startRelay() (*http.Server, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", f.options.RelayPort))
+	if err != nil {
+		return nil, fmt.Errorf("starting event relay on port %d: %w", f.options.RelayPort, err)
+	}
+
+	server := &http.Server{
+		Handler:           http.HandlerFunc(f.relayEventGrid),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("event relay stopped: %v", err)
+		}
+	}()
+
+	return server, nil
+}
+
+type eventGridEvent struct {
+	Topic string `json:"topic"`
+	Data  struct {
+		ValidationCode string `json:"validationCode"`
+	} `json:"data"`
+}
+
+// relayEventGrid completes the validation handshake of the temporary event subscriptions and forwards the other events
+// to the service
+func (f *Forwarder) relayEventGrid(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var events []eventGridEvent
+	_ = json.Unmarshal(body, &events)
+
+	source := Source{Kind: EventGridTopicSource}
+	if len(events) > 0 {
+		source.Name = events[0].Topic[strings.LastIndex(events[0].Topic, "/")+1:]
+	}
+
+	if r.Header.Get("aeg-event-type") == "SubscriptionValidation" {
+		if len(events) == 0 || events[0].Data.ValidationCode == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"validationResponse": events[0].Data.ValidationCode})
+		return
+	}
+
+	headers := http.Header{}
+	for name, values := range r.Header {
+		if strings.EqualFold(name, "Content-Type") || strings.HasPrefix(strings.ToLower(name), "aeg-") {
+			headers[name] = values
+		}
+	}
+
+	w.WriteHeader(f.forward(r.Context(), source, body, headers))
+}
+
+func (f *Forwarder) message(ctx context.Context, message string) {
+	f.consoleMutex.Lock()
+	defer f.consoleMutex.Unlock()
+
+	f.console.Message(ctx, message)
+}
@@ -0,0 +1,171 @@
+package forwarding
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const resourceGroupId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP"
+
+func newTestForwarder(mockContext *mocks.MockContext, options Options) *Forwarder {
+	return NewForwarder(
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		mockContext.Console,
+		mockContext.HttpClient,
+		"SUBSCRIPTION_ID",
+		options,
+	)
+}
+
+func TestSources(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/namespaces/sb-orders/topics")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []map[string]any{{"name": "created"}, {"name": "shipped"}},
+		})
+	})
+
+	forwarder := newTestForwarder(mockContext, Options{})
+	sources, err := forwarder.Sources(*mockContext.Context, []azcli.AzCliResource{
+		{
+			Id:   resourceGroupId + "/providers/Microsoft.ServiceBus/namespaces/sb-orders",
+			Name: "sb-orders",
+			Type: "Microsoft.ServiceBus/namespaces",
+		},
+		{
+			Id:   resourceGroupId + "/providers/Microsoft.EventGrid/systemTopics/storage",
+			Name: "storage",
+			Type: "Microsoft.EventGrid/systemTopics",
+		},
+		{
+			Id:   resourceGroupId + "/providers/Microsoft.Web/sites/app",
+			Name: "app",
+			Type: "Microsoft.Web/sites",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Source{
+		{Kind: ServiceBusTopicSource, ResourceGroup: "RESOURCE_GROUP", Name: "sb-orders", Topic: "created"},
+		{Kind: ServiceBusTopicSource, ResourceGroup: "RESOURCE_GROUP", Name: "sb-orders", Topic: "shipped"},
+		{
+			Kind:          EventGridTopicSource,
+			ResourceGroup: "RESOURCE_GROUP",
+			Name:          "storage",
+			Id:            resourceGroupId + "/providers/Microsoft.EventGrid/systemTopics/storage",
+		},
+	}, sources)
+}
+
+func TestRunServiceBus(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	ctx, cancel := context.WithCancel(*mockContext.Context)
+	defer cancel()
+
+	var created, deleted, received atomic.Int32
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.Contains(request.URL.Path, "/namespaces/sb-orders/topics/created/subscriptions/azd-dev-")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if request.Method == http.MethodPut {
+			created.Add(1)
+		} else {
+			deleted.Add(1)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Host == "sb-orders.servicebus.windows.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if received.Add(1) > 1 {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+		}
+
+		response, err := mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"orderId": 1})
+		response.Header.Set("Content-Type", "application/json")
+		return response, err
+	})
+
+	var forwarded string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.String() == "http://localhost:8080/events"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		forwarded = string(body)
+		cancel()
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	forwarder := newTestForwarder(mockContext, Options{TargetUrl: "http://localhost:8080/events"})
+	err := forwarder.Run(ctx, []Source{
+		{Kind: ServiceBusTopicSource, ResourceGroup: "RESOURCE_GROUP", Name: "sb-orders", Topic: "created"},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"orderId":1}`, forwarded)
+	require.Equal(t, int32(1), created.Load())
+	require.Equal(t, int32(1), deleted.Load())
+}
+
+func TestRunSkipsEventGridWithoutTunnel(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	ctx, cancel := context.WithCancel(*mockContext.Context)
+	cancel()
+
+	forwarder := newTestForwarder(mockContext, Options{TargetUrl: "http://localhost:8080/"})
+	err := forwarder.Run(ctx, []Source{
+		{Kind: EventGridTopicSource, ResourceGroup: "RESOURCE_GROUP", Name: "storage"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, strings.Join(mockContext.Console.Output(), "\n"), "requires --tunnel-url")
+}
+
+func TestRelayEventGrid(t *testing.T) {
+	t.Run("SubscriptionValidation", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		forwarder := newTestForwarder(mockContext, Options{TargetUrl: "http://localhost:8080/"})
+
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`[{"topic":"/providers/Microsoft.EventGrid/topics/orders","data":{"validationCode":"CODE"}}]`))
+		request.Header.Set("aeg-event-type", "SubscriptionValidation")
+		recorder := httptest.NewRecorder()
+
+		forwarder.relayEventGrid(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"validationResponse":"CODE"}`, recorder.Body.String())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		var eventType string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && request.URL.String() == "http://localhost:8080/"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			eventType = request.Header.Get("aeg-event-type")
+			return mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		})
+
+		forwarder := newTestForwarder(mockContext, Options{TargetUrl: "http://localhost:8080/"})
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`[{"topic":"/providers/Microsoft.EventGrid/topics/orders","data":{"orderId":1}}]`))
+		request.Header.Set("aeg-event-type", "Notification")
+		recorder := httptest.NewRecorder()
+
+		forwarder.relayEventGrid(recorder, request)
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+		require.Equal(t, "Notification", eventType)
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// ErrPullRequestUnavailable is returned when azd doesn't run in a GitHub Actions workflow run of a pull request with a
+// token to comment on it
+var ErrPullRequestUnavailable = errors.New("not running in GitHub Actions for a pull request with GITHUB_TOKEN set")
+
+// PullRequestCommentsClient creates and updates a comment of the pull request of the GitHub Actions workflow run azd
+// runs in. The token of the run needs the pull-requests: write permission.
+type PullRequestCommentsClient struct {
+	pipeline    runtime.Pipeline
+	apiUrl      string
+	repository  string
+	pullRequest int
+	// The id of the comment, once created or found
+	commentId int64
+}
+
+// NewPullRequestCommentsClientFromEnv creates a client for the pull request of the GitHub Actions workflow run azd runs
+// in, using the default environment variables of the run and the GITHUB_TOKEN of the step. ErrPullRequestUnavailable is
+// returned outside of GitHub Actions, for runs which aren't triggered by a pull request, or without a token.
+func NewPullRequestCommentsClientFromEnv(options *policy.ClientOptions) (*PullRequestCommentsClient, error) {
+	if strings.ToLower(os.Getenv("GITHUB_ACTIONS")) != "true" {
+		return nil, ErrPullRequestUnavailable
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if token == "" || repository == "" {
+		return nil, ErrPullRequestUnavailable
+	}
+
+	// The ref of pull request runs is refs/pull/<number>/merge
+	ref, isPullRequest := strings.CutPrefix(os.Getenv("GITHUB_REF"), "refs/pull/")
+	number, err := strconv.Atoi(strings.TrimSuffix(ref, "/merge"))
+	if !isPullRequest || err != nil {
+		return nil, ErrPullRequestUnavailable
+	}
+
+	apiUrl := os.Getenv("GITHUB_API_URL")
+	if apiUrl == "" {
+		apiUrl = "https://api.github.com"
+	}
+
+	pipeline := runtime.NewPipeline("github", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			&tokenAuthPolicy{token: token},
+		},
+	}, options)
+
+	return &PullRequestCommentsClient{
+		pipeline:    pipeline,
+		apiUrl:      strings.TrimSuffix(apiUrl, "/"),
+		repository:  repository,
+		pullRequest: number,
+	}, nil
+}
+
+type issueComment struct {
+	Id   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// UpsertComment updates the comment of the pull request containing the marker, ex) an HTML comment, with the body, or
+// creates it when the pull request has no such comment. The body should contain the marker for the comment to be
+// updated the next time.
+func (c *PullRequestCommentsClient) UpsertComment(ctx context.Context, marker string, body string) error {
+	if c.commentId == 0 {
+		commentId, err := c.findComment(ctx, marker)
+		if err != nil {
+			return err
+		}
+
+		c.commentId = commentId
+	}
+
+	method := http.MethodPatch
+	url := fmt.Sprintf("%s/repos/%s/issues/comments/%d", c.apiUrl, c.repository, c.commentId)
+	expectedStatus := http.StatusOK
+	if c.commentId == 0 {
+		method = http.MethodPost
+		url = fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiUrl, c.repository, c.pullRequest)
+		expectedStatus = http.StatusCreated
+	}
+
+	req, err := runtime.NewRequest(ctx, method, url)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(req, issueComment{Body: body}); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, expectedStatus) {
+		return fmt.Errorf("writing pull request comment: %w", runtime.NewResponseError(res))
+	}
+
+	comment, err := httputil.ReadRawResponse[issueComment](res)
+	if err != nil {
+		return fmt.Errorf("reading body: %w", err)
+	}
+
+	c.commentId = comment.Id
+	return nil
+}
+
+// findComment returns the id of the comment of the pull request containing the marker, or 0 when there is none. Only
+// the first 100 comments of the pull request are searched.
+func (c *PullRequestCommentsClient) findComment(ctx context.Context, marker string) (int64, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiUrl, c.repository, c.pullRequest))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = "per_page=100"
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return 0, fmt.Errorf("listing pull request comments: %w", runtime.NewResponseError(res))
+	}
+
+	comments, err := httputil.ReadRawResponse[[]issueComment](res)
+	if err != nil {
+		return 0, fmt.Errorf("reading body: %w", err)
+	}
+
+	for _, comment := range *comments {
+		if strings.Contains(comment.Body, marker) {
+			return comment.Id, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPullRequestCommentsClientFromEnv(t *testing.T) {
+	t.Run("NotPullRequest", func(t *testing.T) {
+		setGitHubActionsEnv(t)
+		t.Setenv("GITHUB_REF", "refs/heads/main")
+
+		_, err := NewPullRequestCommentsClientFromEnv(nil)
+		require.ErrorIs(t, err, ErrPullRequestUnavailable)
+	})
+
+	t.Run("NoToken", func(t *testing.T) {
+		setGitHubActionsEnv(t)
+		t.Setenv("GITHUB_REF", "refs/pull/12/merge")
+		t.Setenv("GITHUB_TOKEN", "")
+
+		_, err := NewPullRequestCommentsClientFromEnv(nil)
+		require.ErrorIs(t, err, ErrPullRequestUnavailable)
+	})
+}
+
+func TestUpsertComment(t *testing.T) {
+	setGitHubActionsEnv(t)
+	t.Setenv("GITHUB_REF", "refs/pull/12/merge")
+
+	mockContext := mocks.NewMockContext(context.Background())
+
+	existing := []issueComment{{Id: 1, Body: "LGTM"}}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/repos/contoso/todo/issues/12/comments"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, existing)
+	})
+
+	var created, updated issueComment
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/repos/contoso/todo/issues/12/comments"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &created))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, issueComment{Id: 2, Body: created.Body})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch && request.URL.Path == "/repos/contoso/todo/issues/comments/2"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &updated))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, issueComment{Id: 2, Body: updated.Body})
+	})
+
+	options := &policy.ClientOptions{Transport: mockContext.HttpClient}
+	client, err := NewPullRequestCommentsClientFromEnv(options)
+	require.NoError(t, err)
+
+	// The comment is created the first time, then updated
+	require.NoError(t, client.UpsertComment(*mockContext.Context, "<!-- marker -->", "<!-- marker -->\nstarted"))
+	require.Equal(t, "<!-- marker -->\nstarted", created.Body)
+
+	require.NoError(t, client.UpsertComment(*mockContext.Context, "<!-- marker -->", "<!-- marker -->\ndone"))
+	require.Equal(t, "<!-- marker -->\ndone", updated.Body)
+
+	// Another run updates the comment of the previous run
+	existing = append(existing, issueComment{Id: 2, Body: "<!-- marker -->\ndone"})
+	client, err = NewPullRequestCommentsClientFromEnv(options)
+	require.NoError(t, err)
+
+	require.NoError(t, client.UpsertComment(*mockContext.Context, "<!-- marker -->", "<!-- marker -->\nagain"))
+	require.Equal(t, "<!-- marker -->\nagain", updated.Body)
+}
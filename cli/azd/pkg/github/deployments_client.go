@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// DeploymentState is the state of a GitHub deployment status
+type DeploymentState string
+
+const (
+	DeploymentStateInProgress DeploymentState = "in_progress"
+	DeploymentStateSuccess    DeploymentState = "success"
+	DeploymentStateFailure    DeploymentState = "failure"
+)
+
+// ErrDeploymentsUnavailable is returned when azd doesn't run in a GitHub Actions workflow with a token to create
+// deployments, ex) when GITHUB_TOKEN isn't set in the environment of the step
+var ErrDeploymentsUnavailable = errors.New("not running in GitHub Actions with GITHUB_TOKEN set")
+
+// DeploymentsClient creates GitHub deployments, and their statuses, for the repository and commit of the GitHub
+// Actions workflow run azd runs in. The deployments are displayed in the Environments of the repository.
+type DeploymentsClient struct {
+	pipeline   runtime.Pipeline
+	apiUrl     string
+	repository string
+	ref        string
+	runUrl     string
+}
+
+// NewDeploymentsClientFromEnv creates a client for the GitHub Actions workflow run azd runs in, using the default
+// environment variables of the run and the GITHUB_TOKEN of the step. ErrDeploymentsUnavailable is returned outside of
+// GitHub Actions or without a token.
+func NewDeploymentsClientFromEnv(options *policy.ClientOptions) (*DeploymentsClient, error) {
+	if strings.ToLower(os.Getenv("GITHUB_ACTIONS")) != "true" {
+		return nil, ErrDeploymentsUnavailable
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	ref := os.Getenv("GITHUB_SHA")
+	if token == "" || repository == "" || ref == "" {
+		return nil, ErrDeploymentsUnavailable
+	}
+
+	apiUrl := os.Getenv("GITHUB_API_URL")
+	if apiUrl == "" {
+		apiUrl = "https://api.github.com"
+	}
+
+	runUrl := ""
+	if serverUrl, runId := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID"); serverUrl != "" && runId != "" {
+		runUrl = fmt.Sprintf("%s/%s/actions/runs/%s", serverUrl, repository, runId)
+	}
+
+	pipeline := runtime.NewPipeline("github", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			&tokenAuthPolicy{token: token},
+		},
+	}, options)
+
+	return &DeploymentsClient{
+		pipeline:   pipeline,
+		apiUrl:     strings.TrimSuffix(apiUrl, "/"),
+		repository: repository,
+		ref:        ref,
+		runUrl:     runUrl,
+	}, nil
+}
+
+type createDeploymentRequest struct {
+	Ref              string   `json:"ref"`
+	Task             string   `json:"task"`
+	Environment      string   `json:"environment"`
+	Description      string   `json:"description"`
+	AutoMerge        bool     `json:"auto_merge"`
+	RequiredContexts []string `json:"required_contexts"`
+}
+
+type createDeploymentResponse struct {
+	Id int64 `json:"id"`
+}
+
+type createDeploymentStatusRequest struct {
+	State          DeploymentState `json:"state"`
+	Environment    string          `json:"environment"`
+	EnvironmentUrl string          `json:"environment_url,omitempty"`
+	LogUrl         string          `json:"log_url,omitempty"`
+	AutoInactive   bool            `json:"auto_inactive"`
+}
+
+// CreateDeployment creates a deployment of the commit of the workflow run to the GitHub environment and returns its
+// id. The commit statuses of the repository aren't required to succeed.
+func (c *DeploymentsClient) CreateDeployment(
+	ctx context.Context,
+	environment string,
+	description string,
+) (int64, error) {
+	req, err := runtime.NewRequest(
+		ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/deployments", c.apiUrl, c.repository))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(req, createDeploymentRequest{
+		Ref:              c.ref,
+		Task:             "deploy",
+		Environment:      environment,
+		Description:      description,
+		AutoMerge:        false,
+		RequiredContexts: []string{},
+	}); err != nil {
+		return 0, err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusCreated) {
+		return 0, fmt.Errorf("creating deployment: %w", runtime.NewResponseError(res))
+	}
+
+	deployment, err := httputil.ReadRawResponse[createDeploymentResponse](res)
+	if err != nil {
+		return 0, fmt.Errorf("reading body: %w", err)
+	}
+
+	return deployment.Id, nil
+}
+
+// CreateDeploymentStatus sets the state of the deployment. The environment url, when not empty, is linked from the
+// environment of the repository. The status links to the workflow run.
+func (c *DeploymentsClient) CreateDeploymentStatus(
+	ctx context.Context,
+	deploymentId int64,
+	environment string,
+	state DeploymentState,
+	environmentUrl string,
+) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPost,
+		fmt.Sprintf("%s/repos/%s/deployments/%d/statuses", c.apiUrl, c.repository, deploymentId))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(req, createDeploymentStatusRequest{
+		State:          state,
+		Environment:    environment,
+		EnvironmentUrl: environmentUrl,
+		LogUrl:         c.runUrl,
+		// Previous successful deployments to the environment become inactive
+		AutoInactive: true,
+	}); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusCreated) {
+		return fmt.Errorf("creating deployment status: %w", runtime.NewResponseError(res))
+	}
+
+	return nil
+}
+
+type tokenAuthPolicy struct {
+	token string
+}
+
+// Do authorizes a request to the GitHub API with the token
+func (p *tokenAuthPolicy) Do(req *policy.Request) (*http.Response, error) {
+	req.Raw().Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.token))
+	req.Raw().Header.Set("Accept", "application/vnd.github+json")
+	return req.Next()
+}
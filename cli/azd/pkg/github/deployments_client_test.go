@@ -0,0 +1,104 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func setGitHubActionsEnv(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_TOKEN", "fake-token")
+	t.Setenv("GITHUB_REPOSITORY", "contoso/todo")
+	t.Setenv("GITHUB_SHA", "abc123")
+	t.Setenv("GITHUB_API_URL", "https://api.github.com")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_RUN_ID", "42")
+}
+
+func TestNewDeploymentsClientFromEnv(t *testing.T) {
+	t.Run("NotInGitHubActions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+
+		_, err := NewDeploymentsClientFromEnv(nil)
+		require.ErrorIs(t, err, ErrDeploymentsUnavailable)
+	})
+
+	t.Run("NoToken", func(t *testing.T) {
+		setGitHubActionsEnv(t)
+		t.Setenv("GITHUB_TOKEN", "")
+
+		_, err := NewDeploymentsClientFromEnv(nil)
+		require.ErrorIs(t, err, ErrDeploymentsUnavailable)
+	})
+}
+
+func TestCreateDeployment(t *testing.T) {
+	setGitHubActionsEnv(t)
+
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var body createDeploymentRequest
+	var authorization string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/repos/contoso/todo/deployments"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		authorization = request.Header.Get("Authorization")
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &body))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, map[string]any{"id": 7})
+	})
+
+	client, err := NewDeploymentsClientFromEnv(&policy.ClientOptions{
+		Transport: mockContext.HttpClient,
+	})
+	require.NoError(t, err)
+
+	id, err := client.CreateDeployment(*mockContext.Context, "dev-api", "Deploying service api")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), id)
+	require.Equal(t, "Bearer fake-token", authorization)
+	require.Equal(t, "abc123", body.Ref)
+	require.Equal(t, "dev-api", body.Environment)
+	require.Empty(t, body.RequiredContexts)
+}
+
+func TestCreateDeploymentStatus(t *testing.T) {
+	setGitHubActionsEnv(t)
+
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var body createDeploymentStatusRequest
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/repos/contoso/todo/deployments/7/statuses"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &body))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, map[string]any{"id": 1})
+	})
+
+	client, err := NewDeploymentsClientFromEnv(&policy.ClientOptions{
+		Transport: mockContext.HttpClient,
+	})
+	require.NoError(t, err)
+
+	err = client.CreateDeploymentStatus(
+		*mockContext.Context, 7, "dev-api", DeploymentStateSuccess, "https://api.contoso.com/")
+	require.NoError(t, err)
+	require.Equal(t, DeploymentStateSuccess, body.State)
+	require.Equal(t, "https://api.contoso.com/", body.EnvironmentUrl)
+	require.Equal(t, "https://github.com/contoso/todo/actions/runs/42", body.LogUrl)
+}
@@ -181,3 +181,33 @@ func (c *ApplicationItemRequestBuilder) AddPassword(ctx context.Context) (*Appli
 
 	return httputil.ReadRawResponse[ApplicationPasswordCredential](res)
 }
+
+// Replaces the certificate credentials of the application. Existing certificates to keep must be included without their
+// key, ex) as returned by Get.
+func (c *ApplicationItemRequestBuilder) SetKeyCredentials(
+	ctx context.Context,
+	keyCredentials []*ApplicationKeyCredential,
+) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/applications/%s", c.client.host, c.id))
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	err = SetHttpRequestBody(req, ApplicationUpdateKeyCredentialsRequest{
+		KeyCredentials: keyCredentials,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
@@ -241,3 +241,39 @@ func TestApplicationRemovePassword(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestApplicationSetKeyCredentials(t *testing.T) {
+	application := applications[0]
+	keyCredentials := []*graphsdk.ApplicationKeyCredential{
+		{
+			Type:  convert.RefOf("AsymmetricX509Cert"),
+			Usage: convert.RefOf("Verify"),
+			Key:   []byte("certificate"),
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockgraphsdk.RegisterApplicationSetKeyCredentialsMock(mockContext, http.StatusNoContent, *application.Id)
+
+		client, err := mockgraphsdk.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.
+			ApplicationById(*application.Id).
+			SetKeyCredentials(*mockContext.Context, keyCredentials)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockgraphsdk.RegisterApplicationSetKeyCredentialsMock(mockContext, http.StatusBadRequest, *application.Id)
+
+		client, err := mockgraphsdk.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById(*application.Id).SetKeyCredentials(*mockContext.Context, keyCredentials)
+		require.Error(t, err)
+	})
+}
@@ -9,6 +9,7 @@ type Application struct {
 	DisplayName         string                           `json:"displayName"`
 	Description         *string                          `json:"description"`
 	PasswordCredentials []*ApplicationPasswordCredential `json:"passwordCredentials"`
+	KeyCredentials      []*ApplicationKeyCredential      `json:"keyCredentials,omitempty"`
 }
 
 type ApplicationCreateRequest struct {
@@ -41,3 +42,22 @@ type ApplicationRemovePasswordRequest struct {
 type ApplicationAddPasswordResponse struct {
 	ApplicationPasswordCredential
 }
+
+// A certificate credential of a Microsoft Graph Application.
+type ApplicationKeyCredential struct {
+	KeyId               *string    `json:"keyId,omitempty"`
+	CustomKeyIdentifier *string    `json:"customKeyIdentifier,omitempty"`
+	DisplayName         *string    `json:"displayName,omitempty"`
+	StartDateTime       *time.Time `json:"startDateTime,omitempty"`
+	EndDateTime         *time.Time `json:"endDateTime,omitempty"`
+	// AsymmetricX509Cert for certificates
+	Type *string `json:"type,omitempty"`
+	// Verify for certificates
+	Usage *string `json:"usage,omitempty"`
+	// The DER encoded certificate. Only set when adding a certificate, the Graph never returns it.
+	Key []byte `json:"key,omitempty"`
+}
+
+type ApplicationUpdateKeyCredentialsRequest struct {
+	KeyCredentials []*ApplicationKeyCredential `json:"keyCredentials"`
+}
@@ -0,0 +1,221 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package ignore matches paths against patterns with the syntax of .gitignore files, ex) the .azdignore files excluding
+// files from the packages of services. See https://git-scm.com/docs/gitignore#_pattern_format
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the files excluding files from the packages of services, in the project and service directories
+const FileName = ".azdignore"
+
+// Rule is a pattern of an ignore file
+type Rule struct {
+	// The pattern, as written in its source
+	Pattern string
+	// Where the pattern comes from, ex) the path of an .azdignore file
+	Source string
+	// The line of the pattern in its source, or 0 for patterns which aren't read from a file
+	Line int
+
+	// The directory the pattern is relative to, or empty for the root of the matcher
+	base    string
+	negate  bool
+	dirOnly bool
+	regex   *regexp.Regexp
+}
+
+// String returns the pattern and where it comes from, ex) 'dist/' (.azdignore:3)
+func (r *Rule) String() string {
+	if r.Line > 0 {
+		return fmt.Sprintf("'%s' (%s:%d)", r.Pattern, r.Source, r.Line)
+	}
+
+	return fmt.Sprintf("'%s' (%s)", r.Pattern, r.Source)
+}
+
+// Matcher matches the paths under a root directory against rules. As in .gitignore files, the last rule matching a path
+// decides whether it is excluded, so later ! rules include paths excluded by earlier rules. Files under an excluded
+// directory are never matched: callers skip the directories which are excluded.
+type Matcher struct {
+	root  string
+	rules []*Rule
+}
+
+// New creates a matcher for the paths under the root directory
+func New(root string) *Matcher {
+	return &Matcher{root: root}
+}
+
+// AddPatterns adds the patterns of the source, relative to the root of the matcher
+func (m *Matcher) AddPatterns(source string, patterns ...string) error {
+	for _, pattern := range patterns {
+		if err := m.add("", source, 0, pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddFile adds the patterns of the ignore file, relative to its directory. Missing files are ignored.
+func (m *Matcher) AddFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if err := m.add(filepath.Dir(path), path, line, scanner.Text()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Match returns the rule excluding the path, or nil when the path isn't excluded. Paths which aren't under the directory
+// of the file of a rule are matched relative to the root of the matcher.
+func (m *Matcher) Match(path string, isDir bool) *Rule {
+	var matched *Rule
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		base := rule.base
+		if base == "" {
+			base = m.root
+		}
+
+		relative, ok := relativePath(base, path)
+		if !ok {
+			if relative, ok = relativePath(m.root, path); !ok {
+				continue
+			}
+		}
+
+		if rule.regex.MatchString(relative) {
+			matched = rule
+		}
+	}
+
+	if matched == nil || matched.negate {
+		return nil
+	}
+
+	return matched
+}
+
+func (m *Matcher) add(base string, source string, line int, pattern string) error {
+	text := strings.TrimRight(strings.TrimSuffix(pattern, "\r"), " \t")
+	if text == "" || strings.HasPrefix(text, "#") {
+		return nil
+	}
+
+	rule := &Rule{
+		Pattern: text,
+		Source:  source,
+		Line:    line,
+		base:    base,
+	}
+
+	if strings.HasPrefix(text, "!") {
+		rule.negate = true
+		text = text[1:]
+	} else if strings.HasPrefix(text, `\#`) || strings.HasPrefix(text, `\!`) {
+		text = text[1:]
+	}
+
+	if strings.HasSuffix(text, "/") {
+		rule.dirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+
+	// Patterns with a separator are relative to the directory of their file, the others match at any depth
+	anchored := strings.Contains(text, "/")
+	text = strings.TrimPrefix(text, "/")
+	if text == "" {
+		return nil
+	}
+
+	expression := translate(text)
+	if !anchored {
+		expression = "(.*/)?" + expression
+	}
+
+	regex, err := regexp.Compile("^" + expression + "$")
+	if err != nil {
+		return fmt.Errorf("invalid pattern '%s' in %s: %w", rule.Pattern, source, err)
+	}
+
+	rule.regex = regex
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+// translate translates the pattern to a regular expression, ex) src/**/*.map to src/(.*/)?[^/]*\.map
+func translate(pattern string) string {
+	var builder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			builder.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern) && (i == 0 || pattern[i-1] == '/'):
+			builder.WriteString(".*")
+			i++
+		case c == '*':
+			builder.WriteString("[^/]*")
+		case c == '?':
+			builder.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				builder.WriteString(`\[`)
+				continue
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return builder.String()
+}
+
+// relativePath returns the path relative to the base directory, with forward slashes, when the path is under the base
+func relativePath(base string, path string) (string, bool) {
+	relative, err := filepath.Rel(base, path)
+	if err != nil || relative == "." || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(relative), true
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Patterns(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		pattern  string
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "logs/app.log", false, true},
+		{"*.log", "app.logs", false, false},
+		{"dist/", "dist", true, true},
+		{"dist/", "dist", false, false},
+		{"dist/", "src/dist", true, true},
+		{"/dist", "src/dist", true, false},
+		{"src/*.map", "src/app.map", false, true},
+		{"src/*.map", "src/lib/app.map", false, false},
+		{"src/**/*.map", "src/lib/app.map", false, true},
+		{"src/**/*.map", "src/app.map", false, true},
+		{"**/test", "a/b/test", true, true},
+		{"logs/**", "logs/a/b.txt", false, true},
+		{"file?.txt", "file1.txt", false, true},
+		{"file[0-9].txt", "filea.txt", false, false},
+		{"file[!0-9].txt", "filea.txt", false, true},
+		{"# comment", "# comment", false, false},
+		{`\#file`, "#file", false, true},
+	}
+
+	for _, test := range tests {
+		matcher := New(root)
+		require.NoError(t, matcher.AddPatterns("test", test.pattern))
+
+		rule := matcher.Match(filepath.Join(root, filepath.FromSlash(test.path)), test.isDir)
+		require.Equal(t, test.excluded, rule != nil, "%s matching %s", test.pattern, test.path)
+	}
+}
+
+func TestMatcher_Negation(t *testing.T) {
+	root := t.TempDir()
+	matcher := New(root)
+	require.NoError(t, matcher.AddPatterns("default", ".azure/", "*.env"))
+	require.NoError(t, matcher.AddPatterns("test", "!.azure/", "!prod.env"))
+
+	require.Nil(t, matcher.Match(filepath.Join(root, ".azure"), true))
+	require.Nil(t, matcher.Match(filepath.Join(root, "prod.env"), false))
+
+	rule := matcher.Match(filepath.Join(root, "dev.env"), false)
+	require.NotNil(t, rule)
+	require.Equal(t, "'*.env' (default)", rule.String())
+}
+
+func TestMatcher_AddFile(t *testing.T) {
+	root := t.TempDir()
+	service := filepath.Join(root, "src", "api")
+	require.NoError(t, os.MkdirAll(service, 0755))
+
+	matcher := New(service)
+	require.NoError(t, matcher.AddFile(filepath.Join(root, FileName)))
+
+	contents := "# local settings\n/local.settings.json\n\ntests/\n"
+	require.NoError(t, os.WriteFile(filepath.Join(service, FileName), []byte(contents), 0600))
+	require.NoError(t, matcher.AddFile(filepath.Join(service, FileName)))
+
+	rule := matcher.Match(filepath.Join(service, "tests"), true)
+	require.NotNil(t, rule)
+	require.Equal(t, 4, rule.Line)
+	require.Equal(t, filepath.Join(service, FileName), rule.Source)
+
+	require.NotNil(t, matcher.Match(filepath.Join(service, "local.settings.json"), false))
+	require.Nil(t, matcher.Match(filepath.Join(service, "config", "local.settings.json"), false))
+
+	// Paths outside of the directory of the file are matched relative to the root
+	other := New(filepath.Join(root, "dist"))
+	require.NoError(t, other.AddFile(filepath.Join(service, FileName)))
+	require.NotNil(t, other.Match(filepath.Join(root, "dist", "local.settings.json"), false))
+}
@@ -36,6 +36,9 @@ const (
 	AzureResourceTypeAgentPool               AzureResourceType = "Microsoft.ContainerService/managedClusters/agentPools"
 	AzureResourceTypeCognitiveServiceAccount AzureResourceType = "Microsoft.CognitiveServices/accounts"
 	AzureResourceTypeSearchService           AzureResourceType = "Microsoft.Search/searchServices"
+	AzureResourceTypeMySqlServer             AzureResourceType = "Microsoft.DBforMySQL/flexibleServers"
+	AzureResourceTypeVirtualMachine          AzureResourceType = "Microsoft.Compute/virtualMachines"
+	AzureResourceTypeBastionHost             AzureResourceType = "Microsoft.Network/bastionHosts"
 )
 
 const resourceLevelSeparator = "/"
@@ -81,6 +84,12 @@ func GetResourceTypeDisplayName(resourceType AzureResourceType) string {
 		return "Azure SQL Server"
 	case AzureResourceTypePostgreSqlServer:
 		return "Azure Database for PostgreSQL flexible server"
+	case AzureResourceTypeMySqlServer:
+		return "Azure Database for MySQL flexible server"
+	case AzureResourceTypeVirtualMachine:
+		return "Virtual machine"
+	case AzureResourceTypeBastionHost:
+		return "Bastion"
 	case AzureResourceTypeCDNProfile:
 		return "Azure Front Door / CDN profile"
 	case AzureResourceTypeLoadTest:
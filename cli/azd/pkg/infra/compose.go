@@ -0,0 +1,283 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/resources"
+)
+
+// ComposableModule is a curated Bicep module which can be composed into the infrastructure of a project with azd add.
+type ComposableModule struct {
+	// The kind of resource, ex) cosmos
+	Kind        string
+	Description string
+	// The prefix of the names of the resources, ex) cosmos-
+	NamePrefix string
+	Parameters []ComposableParameter
+	Outputs    []ComposableOutput
+}
+
+// ComposableParameter is a parameter of a ComposableModule wired to a parameter of the main file, in addition to the
+// name, location and tags of the module.
+type ComposableParameter struct {
+	// The name of the parameter of the module
+	Name string
+	// The name of the parameter of the main file
+	MainName string
+	Type     string
+	// The default value of the parameter declared in the main file when the main file doesn't declare it. Parameters
+	// without a default value are only wired when declared by the main file.
+	Default string
+}
+
+// ComposableOutput is an output of a ComposableModule mapped into the environment. The name of the environment variable
+// is the suffix prefixed with the name of the composed module, ex) AZURE_COSMOS_ENDPOINT
+type ComposableOutput struct {
+	Name   string
+	Type   string
+	Suffix string
+}
+
+// publicNetworkAccessParameter wires the public network access of a module to the publicNetworkAccess parameter of the
+// main file, which azd provides for the network mode of the project, ex) Disabled for azd provision --network private.
+var publicNetworkAccessParameter = ComposableParameter{
+	Name:     "publicNetworkAccess",
+	MainName: "publicNetworkAccess",
+	Type:     "string",
+	Default:  "'Enabled'",
+}
+
+// ComposableModules are the modules available to azd add.
+var ComposableModules = []ComposableModule{
+	{
+		Kind:        "cosmos",
+		Description: "Azure Cosmos DB (serverless)",
+		NamePrefix:  "cosmos-",
+		Parameters:  []ComposableParameter{publicNetworkAccessParameter},
+		Outputs: []ComposableOutput{
+			{Name: "endpoint", Type: "string", Suffix: "ENDPOINT"},
+			{Name: "name", Type: "string", Suffix: "NAME"},
+		},
+	},
+	{
+		Kind:        "servicebus",
+		Description: "Azure Service Bus namespace",
+		NamePrefix:  "sb-",
+		Parameters:  []ComposableParameter{publicNetworkAccessParameter},
+		Outputs: []ComposableOutput{
+			{Name: "endpoint", Type: "string", Suffix: "ENDPOINT"},
+			{Name: "name", Type: "string", Suffix: "NAME"},
+		},
+	},
+	{
+		Kind:        "redis",
+		Description: "Azure Cache for Redis",
+		NamePrefix:  "redis-",
+		Parameters:  []ComposableParameter{publicNetworkAccessParameter},
+		Outputs: []ComposableOutput{
+			{Name: "hostName", Type: "string", Suffix: "HOST"},
+			{Name: "sslPort", Type: "int", Suffix: "PORT"},
+			{Name: "name", Type: "string", Suffix: "NAME"},
+		},
+	},
+	{
+		Kind:        "openai",
+		Description: "Azure OpenAI account with a chat model deployment",
+		NamePrefix:  "oai-",
+		Parameters: []ComposableParameter{
+			{
+				Name:     "deployments",
+				MainName: "openAiDeployments",
+				Type:     "array",
+				Default: "[\n" +
+					"  {\n" +
+					"    name: 'chat'\n" +
+					"    model: 'gpt-35-turbo'\n" +
+					"    version: '0613'\n" +
+					"    capacity: 10\n" +
+					"  }\n" +
+					"]",
+			},
+			{Name: "disableLocalAuth", MainName: "openAiDisableLocalAuth", Type: "bool", Default: "false"},
+			{Name: "principalId", MainName: "principalId", Type: "string"},
+			publicNetworkAccessParameter,
+		},
+		Outputs: []ComposableOutput{
+			{Name: "endpoint", Type: "string", Suffix: "ENDPOINT"},
+			{Name: "deploymentName", Type: "string", Suffix: "DEPLOYMENT_NAME"},
+			{Name: "name", Type: "string", Suffix: "NAME"},
+		},
+	},
+}
+
+// FindComposableModule returns the composable module of the kind, or nil when there is no such module.
+func FindComposableModule(kind string) *ComposableModule {
+	for i := range ComposableModules {
+		if strings.EqualFold(ComposableModules[i].Kind, kind) {
+			return &ComposableModules[i]
+		}
+	}
+
+	return nil
+}
+
+// ComposeResult describes a module composed into the infrastructure of a project.
+type ComposeResult struct {
+	// The path of the Bicep module file
+	ModulePath string
+	// The names of the outputs stored in the environment after the next provisioning
+	EnvVarNames []string
+}
+
+var (
+	composeNameRegex          = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+	subscriptionScopeRegex    = regexp.MustCompile(`(?m)^\s*targetScope\s*=\s*'subscription'`)
+	resourceGroupSymbolRegex  = regexp.MustCompile(`(?m)^\s*resource\s+rg\s+'Microsoft\.Resources/resourceGroups@`)
+	environmentNameParamRegex = regexp.MustCompile(`(?m)^\s*param\s+environmentName\s`)
+	locationParamRegex        = regexp.MustCompile(`(?m)^\s*param\s+location\s`)
+	tagsSymbolRegex           = regexp.MustCompile(`(?m)^\s*(var|param)\s+tags\s`)
+)
+
+// Compose adds the module to the main Bicep file of the infrastructure at infraPath. The module file is copied to the
+// modules directory next to the main file, unless it already exists, and a module declaration named name wires the
+// environmentName, location, tags and the parameters of the module to the parameters of the main file, declaring the
+// missing ones. The outputs of the module are added to the main file so they are stored in the environment when
+// provisioned.
+func (m *ComposableModule) Compose(infraPath string, mainModule string, name string) (*ComposeResult, error) {
+	if name == "" {
+		name = m.Kind
+	}
+
+	if !composeNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid name '%s', names must start with a letter and contain only letters and digits", name)
+	}
+
+	mainPath := filepath.Join(infraPath, fmt.Sprintf("%s.bicep", mainModule))
+	contents, err := os.ReadFile(mainPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", mainPath, err)
+	}
+
+	main := string(contents)
+	if regexp.MustCompile(fmt.Sprintf(`(?m)^\s*(module|resource|var|param|output)\s+%s\s`, name)).MatchString(main) {
+		return nil, fmt.Errorf("'%s' is already declared in %s, use --name to choose another name", name, mainPath)
+	}
+
+	if !environmentNameParamRegex.MatchString(main) {
+		return nil, fmt.Errorf("%s must declare an 'environmentName' parameter", mainPath)
+	}
+
+	scope := ""
+	if subscriptionScopeRegex.MatchString(main) {
+		if !resourceGroupSymbolRegex.MatchString(main) {
+			return nil, fmt.Errorf(
+				"%s must declare the resource group of the environment as an 'rg' resource to add modules", mainPath)
+		}
+
+		scope = "rg"
+	}
+
+	envPrefix := fmt.Sprintf("AZURE_%s_", strings.ToUpper(name))
+	envVarNames := make([]string, 0, len(m.Outputs))
+	for _, output := range m.Outputs {
+		envVarName := envPrefix + output.Suffix
+		if regexp.MustCompile(fmt.Sprintf(`(?m)^\s*output\s+%s\s`, envVarName)).MatchString(main) {
+			return nil, fmt.Errorf("output '%s' is already declared in %s", envVarName, mainPath)
+		}
+
+		envVarNames = append(envVarNames, envVarName)
+	}
+
+	modulePath, err := m.writeModuleFile(filepath.Dir(mainPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var declaration strings.Builder
+	wiredParameters := []ComposableParameter{}
+	for _, parameter := range m.Parameters {
+		declared := regexp.MustCompile(fmt.Sprintf(`(?m)^\s*param\s+%s\s`, parameter.MainName)).MatchString(main)
+		if !declared && parameter.Default == "" {
+			continue
+		}
+
+		if !declared {
+			declaration.WriteString(
+				fmt.Sprintf("\nparam %s %s = %s\n", parameter.MainName, parameter.Type, parameter.Default))
+		}
+
+		wiredParameters = append(wiredParameters, parameter)
+	}
+
+	declaration.WriteString(fmt.Sprintf("\nmodule %s './modules/%s.bicep' = {\n", name, m.Kind))
+	declaration.WriteString(fmt.Sprintf("  name: '%s'\n", name))
+	if scope != "" {
+		declaration.WriteString(fmt.Sprintf("  scope: %s\n", scope))
+	}
+	declaration.WriteString("  params: {\n")
+	declaration.WriteString(fmt.Sprintf(
+		"    name: '%s${uniqueString(subscription().id, environmentName, '%s')}'\n", m.NamePrefix, name))
+	if locationParamRegex.MatchString(main) {
+		declaration.WriteString("    location: location\n")
+	}
+	if tagsSymbolRegex.MatchString(main) {
+		declaration.WriteString("    tags: tags\n")
+	}
+	for _, parameter := range wiredParameters {
+		declaration.WriteString(fmt.Sprintf("    %s: %s\n", parameter.Name, parameter.MainName))
+	}
+	declaration.WriteString("  }\n}\n\n")
+
+	for i, output := range m.Outputs {
+		declaration.WriteString(
+			fmt.Sprintf("output %s %s = %s.outputs.%s\n", envVarNames[i], output.Type, name, output.Name))
+	}
+
+	if !strings.HasSuffix(main, "\n") {
+		main += "\n"
+	}
+
+	if err := os.WriteFile(mainPath, []byte(main+declaration.String()), osutil.PermissionFile); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", mainPath, err)
+	}
+
+	return &ComposeResult{
+		ModulePath:  modulePath,
+		EnvVarNames: envVarNames,
+	}, nil
+}
+
+// writeModuleFile copies the module file to the modules directory, keeping any existing, possibly customized, file.
+func (m *ComposableModule) writeModuleFile(infraPath string) (string, error) {
+	modulePath := filepath.Join(infraPath, "modules", fmt.Sprintf("%s.bicep", m.Kind))
+	if _, err := os.Stat(modulePath); err == nil {
+		return modulePath, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("checking %s: %w", modulePath, err)
+	}
+
+	contents, err := resources.Modules.ReadFile(path.Join("modules", fmt.Sprintf("%s.bicep", m.Kind)))
+	if err != nil {
+		return "", fmt.Errorf("reading module %s: %w", m.Kind, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(modulePath), osutil.PermissionDirectory); err != nil {
+		return "", fmt.Errorf("creating directory %s: %w", filepath.Dir(modulePath), err)
+	}
+
+	if err := os.WriteFile(modulePath, contents, osutil.PermissionFile); err != nil {
+		return "", fmt.Errorf("writing %s: %w", modulePath, err)
+	}
+
+	return modulePath, nil
+}
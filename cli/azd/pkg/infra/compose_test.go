@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeModule(t *testing.T) {
+	infraPath := t.TempDir()
+	mainPath := filepath.Join(infraPath, "main.bicep")
+	require.NoError(t, os.WriteFile(mainPath, resources.MinimalBicep, osutil.PermissionFile))
+
+	module := FindComposableModule("Redis")
+	require.NotNil(t, module)
+
+	result, err := module.Compose(infraPath, "main", "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(infraPath, "modules", "redis.bicep"), result.ModulePath)
+	require.Equal(t, []string{"AZURE_REDIS_HOST", "AZURE_REDIS_PORT", "AZURE_REDIS_NAME"}, result.EnvVarNames)
+	require.FileExists(t, result.ModulePath)
+
+	contents, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+
+	main := string(contents)
+	require.Contains(t, main, "module redis './modules/redis.bicep' = {\n"+
+		"  name: 'redis'\n"+
+		"  scope: rg\n"+
+		"  params: {\n"+
+		"    name: 'redis-${uniqueString(subscription().id, environmentName, 'redis')}'\n"+
+		"    location: location\n"+
+		"    tags: tags\n"+
+		"    publicNetworkAccess: publicNetworkAccess\n"+
+		"  }\n"+
+		"}\n")
+	// the network mode of the project is provided by azd to the main file, and flows to the module
+	require.Contains(t, main, "\nparam publicNetworkAccess string = 'Enabled'\n")
+	require.Contains(t, main, "output AZURE_REDIS_HOST string = redis.outputs.hostName\n")
+	require.Contains(t, main, "output AZURE_REDIS_PORT int = redis.outputs.sslPort\n")
+
+	// Composing a second module of the same kind requires another name
+	_, err = module.Compose(infraPath, "main", "")
+	require.Error(t, err)
+
+	result, err = module.Compose(infraPath, "main", "sessions")
+	require.NoError(t, err)
+	require.Equal(t, "AZURE_SESSIONS_HOST", result.EnvVarNames[0])
+}
+
+func TestComposeModuleInvalid(t *testing.T) {
+	infraPath := t.TempDir()
+	module := FindComposableModule("cosmos")
+	require.NotNil(t, module)
+
+	// Missing main file
+	_, err := module.Compose(infraPath, "main", "")
+	require.Error(t, err)
+
+	// Subscription scoped without a resource group
+	require.NoError(t, os.WriteFile(
+		filepath.Join(infraPath, "main.bicep"),
+		[]byte("targetScope = 'subscription'\nparam environmentName string\n"),
+		osutil.PermissionFile))
+	_, err = module.Compose(infraPath, "main", "")
+	require.Error(t, err)
+
+	_, err = module.Compose(infraPath, "main", "my-db")
+	require.Error(t, err)
+
+	require.Nil(t, FindComposableModule("unknown"))
+}
+
+func TestComposeModuleParameters(t *testing.T) {
+	infraPath := t.TempDir()
+	mainPath := filepath.Join(infraPath, "main.bicep")
+	require.NoError(t, os.WriteFile(
+		mainPath,
+		append(resources.MinimalBicep, []byte("\nparam principalId string = ''\n")...),
+		osutil.PermissionFile))
+
+	module := FindComposableModule("openai")
+	require.NotNil(t, module)
+
+	_, err := module.Compose(infraPath, "main", "")
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+
+	main := string(contents)
+	require.Contains(t, main, "\nparam openAiDeployments array = [\n")
+	require.Contains(t, main, "\nparam openAiDisableLocalAuth bool = false\n")
+	require.Contains(t, main, "    deployments: openAiDeployments\n"+
+		"    disableLocalAuth: openAiDisableLocalAuth\n"+
+		"    principalId: principalId\n")
+
+	// The parameters declared by the first module are reused
+	_, err = module.Compose(infraPath, "main", "openai2")
+	require.NoError(t, err)
+
+	contents, err = os.ReadFile(mainPath)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(contents), "param openAiDeployments "))
+}
@@ -0,0 +1,255 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+)
+
+// The maximum depth of nested deployments inspected when looking for the root cause of a failure
+const maxDeploymentFailureDepth = 10
+
+// DeploymentFailureCategory classifies well-known causes of resource deployment failures
+type DeploymentFailureCategory string
+
+const (
+	DeploymentFailureCategoryUnknown         DeploymentFailureCategory = ""
+	DeploymentFailureCategoryPolicy          DeploymentFailureCategory = "policy"
+	DeploymentFailureCategoryQuota           DeploymentFailureCategory = "quota"
+	DeploymentFailureCategorySkuNotAvailable DeploymentFailureCategory = "skuNotAvailable"
+)
+
+// DeploymentFailure describes the innermost error of a resource which failed to deploy
+type DeploymentFailure struct {
+	// The names of the deployments leading to the resource, starting with the top level deployment
+	DeploymentPath []string
+	ResourceType   string
+	ResourceName   string
+	Code           string
+	Message        string
+	Category       DeploymentFailureCategory
+}
+
+// Suggestion returns a suggestion to resolve well-known categories of failures, or an empty string.
+func (f *DeploymentFailure) Suggestion() string {
+	switch f.Category {
+	case DeploymentFailureCategoryPolicy:
+		return "A policy assigned to the subscription or resource group denied the resource. " +
+			"Update the resource to comply with the policy, or ask an administrator for an exemption."
+	case DeploymentFailureCategoryQuota:
+		return "The subscription quota for the resource is exhausted. " +
+			"Request a quota increase, free up existing capacity, or deploy to a different location."
+	case DeploymentFailureCategorySkuNotAvailable:
+		return "The requested SKU is not available in the selected location for this subscription. " +
+			"Choose a different SKU or location."
+	default:
+		return ""
+	}
+}
+
+// DeploymentFailedError is returned when resources fail to deploy, describing the root cause of each failure. The
+// original deployment error is available by unwrapping the error.
+type DeploymentFailedError struct {
+	Failures []*DeploymentFailure
+	Err      error
+}
+
+func (e *DeploymentFailedError) Error() string {
+	var sb strings.Builder
+
+	if len(e.Failures) == 1 {
+		sb.WriteString("1 resource failed to deploy:\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("%d resources failed to deploy:\n", len(e.Failures)))
+	}
+
+	for _, failure := range e.Failures {
+		sb.WriteString(fmt.Sprintf("\n- %s '%s'", failure.ResourceType, failure.ResourceName))
+		if len(failure.DeploymentPath) > 0 {
+			sb.WriteString(fmt.Sprintf(" (deployment %s)", strings.Join(failure.DeploymentPath, " > ")))
+		}
+
+		switch {
+		case failure.Code != "" && failure.Message != "":
+			sb.WriteString(fmt.Sprintf("\n  %s: %s", failure.Code, failure.Message))
+		case failure.Message != "":
+			sb.WriteString(fmt.Sprintf("\n  %s", failure.Message))
+		case failure.Code != "":
+			sb.WriteString(fmt.Sprintf("\n  %s", failure.Code))
+		}
+
+		if suggestion := failure.Suggestion(); suggestion != "" {
+			sb.WriteString(fmt.Sprintf("\n  %s", suggestion))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (e *DeploymentFailedError) Unwrap() error {
+	return e.Err
+}
+
+// FindDeploymentFailures inspects the operations of a failed deployment, drilling into failed nested deployments, and
+// returns the innermost failing resources.
+func (rm *AzureResourceManager) FindDeploymentFailures(
+	ctx context.Context,
+	deployment Deployment,
+) ([]*DeploymentFailure, error) {
+	operations, err := deployment.Operations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting deployment operations: %w", err)
+	}
+
+	return rm.findDeploymentFailuresRecursive(ctx, deployment.SubscriptionId(), []string{deployment.Name()}, operations)
+}
+
+func (rm *AzureResourceManager) findDeploymentFailuresRecursive(
+	ctx context.Context,
+	subscriptionId string,
+	deploymentPath []string,
+	operations []*armresources.DeploymentOperation,
+) ([]*DeploymentFailure, error) {
+	failures := []*DeploymentFailure{}
+
+	for _, operation := range operations {
+		if operation.Properties == nil ||
+			convert.ToValueWithDefault(operation.Properties.ProvisioningState, "") != "Failed" {
+			continue
+		}
+
+		// Failed operations without a target resource describe the deployment itself and are reported by the
+		// deployment error.
+		target := operation.Properties.TargetResource
+		if target == nil || target.ResourceType == nil || target.ResourceName == nil {
+			continue
+		}
+
+		if *target.ResourceType == string(AzureResourceTypeDeployment) && len(deploymentPath) < maxDeploymentFailureDepth {
+			nestedPath := append(append([]string{}, deploymentPath...), *target.ResourceName)
+			nestedOperations, err := rm.nestedDeploymentOperations(ctx, subscriptionId, target)
+			if err != nil {
+				return nil, err
+			}
+
+			nestedFailures, err := rm.findDeploymentFailuresRecursive(ctx, subscriptionId, nestedPath, nestedOperations)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(nestedFailures) > 0 {
+				failures = append(failures, nestedFailures...)
+				continue
+			}
+		}
+
+		failure := &DeploymentFailure{
+			DeploymentPath: deploymentPath,
+			ResourceType:   *target.ResourceType,
+			ResourceName:   *target.ResourceName,
+		}
+
+		if operation.Properties.StatusMessage != nil && operation.Properties.StatusMessage.Error != nil {
+			code, message := innermostError(operation.Properties.StatusMessage.Error)
+			failure.Code = code
+			failure.Message = message
+		}
+
+		failure.Category = deploymentFailureCategory(failure.Code, failure.Message)
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+// nestedDeploymentOperations lists the operations of a nested deployment, at resource group or subscription scope.
+func (rm *AzureResourceManager) nestedDeploymentOperations(
+	ctx context.Context,
+	subscriptionId string,
+	target *armresources.TargetResource,
+) ([]*armresources.DeploymentOperation, error) {
+	resourceGroupName := ""
+	if target.ID != nil {
+		if resourceId, err := arm.ParseResourceID(*target.ID); err == nil {
+			resourceGroupName = resourceId.ResourceGroupName
+			if resourceId.SubscriptionID != "" {
+				subscriptionId = resourceId.SubscriptionID
+			}
+		}
+	}
+
+	var operations []*armresources.DeploymentOperation
+	var err error
+	if resourceGroupName != "" {
+		operations, err = rm.azCli.ListResourceGroupDeploymentOperations(
+			ctx, subscriptionId, resourceGroupName, *target.ResourceName)
+	} else {
+		operations, err = rm.azCli.ListSubscriptionDeploymentOperations(ctx, subscriptionId, *target.ResourceName)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting operations of nested deployment '%s': %w", *target.ResourceName, err)
+	}
+
+	return operations, nil
+}
+
+// Error codes which wrap the actual cause of a failure in their details
+var wrapperErrorCodes = map[string]struct{}{
+	"DeploymentFailed":          {},
+	"ResourceDeploymentFailure": {},
+	"Conflict":                  {},
+	"BadRequest":                {},
+}
+
+// innermostError returns the code and message of the most specific error, following the error details.
+func innermostError(err *armresources.ErrorResponse) (string, string) {
+	code := convert.ToValueWithDefault(err.Code, "")
+	message := convert.ToValueWithDefault(err.Message, "")
+
+	if _, isWrapper := wrapperErrorCodes[code]; isWrapper || code == "" {
+		for _, detail := range err.Details {
+			if detail == nil {
+				continue
+			}
+
+			if detailCode, detailMessage := innermostError(detail); detailCode != "" || detailMessage != "" {
+				return detailCode, detailMessage
+			}
+		}
+	}
+
+	return code, message
+}
+
+func deploymentFailureCategory(code string, message string) DeploymentFailureCategory {
+	lowerMessage := strings.ToLower(message)
+
+	switch code {
+	case "RequestDisallowedByPolicy", "PolicyViolation":
+		return DeploymentFailureCategoryPolicy
+	case "QuotaExceeded", "InsufficientQuota", "SubscriptionIsOverQuotaForSku", "OperationNotAllowed":
+		if code != "OperationNotAllowed" || strings.Contains(lowerMessage, "quota") {
+			return DeploymentFailureCategoryQuota
+		}
+	case "SkuNotAvailable", "LocationIsOfferRestricted", "InvalidResourceLocation":
+		if code != "InvalidResourceLocation" || strings.Contains(lowerMessage, "sku") {
+			return DeploymentFailureCategorySkuNotAvailable
+		}
+	}
+
+	if strings.Contains(lowerMessage, "disallowed by policy") {
+		return DeploymentFailureCategoryPolicy
+	}
+
+	return DeploymentFailureCategoryUnknown
+}
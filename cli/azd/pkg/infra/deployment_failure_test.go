@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+var mockFailedSubDeploymentOperations string = `
+{
+	"value": [
+		{
+			"operationId": "op1",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Succeeded",
+				"targetResource": {
+					"resourceType": "Microsoft.Resources/resourceGroups",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test",
+					"resourceName": "rg-test"
+				}
+			}
+		},
+		{
+			"operationId": "op2",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": {
+						"code": "DeploymentFailed",
+						"message": "At least one resource deployment operation failed."
+					}
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Resources/deployments",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test/providers/Microsoft.Resources/deployments/resources",
+					"resourceName": "resources"
+				}
+			}
+		}
+	]
+}
+`
+
+var mockFailedGroupDeploymentOperations string = `
+{
+	"value": [
+		{
+			"operationId": "op3",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": {
+						"code": "ResourceDeploymentFailure",
+						"message": "The resource operation completed with terminal provisioning state 'Failed'.",
+						"details": [
+							{
+								"code": "RequestDisallowedByPolicy",
+								"message": "Resource 'sttest' was disallowed by policy."
+							}
+						]
+					}
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Storage/storageAccounts",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test/providers/Microsoft.Storage/storageAccounts/sttest",
+					"resourceName": "sttest"
+				}
+			}
+		},
+		{
+			"operationId": "op4",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Succeeded",
+				"targetResource": {
+					"resourceType": "Microsoft.Web/sites",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test/providers/Microsoft.Web/sites/app-test",
+					"resourceName": "app-test"
+				}
+			}
+		}
+	]
+}
+`
+
+func TestFindDeploymentFailures(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	deployment := NewSubscriptionDeployment(azCli, "eastus2", "SUBSCRIPTION_ID", "DEPLOYMENT_NAME")
+
+	mockOperations := func(path string, body string) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.Contains(request.URL.Path, path)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    &http.Request{Method: http.MethodGet},
+			}, nil
+		})
+	}
+
+	mockOperations(
+		"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/DEPLOYMENT_NAME/operations",
+		mockFailedSubDeploymentOperations,
+	)
+	mockOperations(
+		"/subscriptions/SUBSCRIPTION_ID/resourcegroups/rg-test/deployments/resources/operations",
+		mockFailedGroupDeploymentOperations,
+	)
+
+	resourceManager := NewAzureResourceManager(azCli)
+	failures, err := resourceManager.FindDeploymentFailures(*mockContext.Context, deployment)
+	require.NoError(t, err)
+	require.Equal(t, []*DeploymentFailure{
+		{
+			DeploymentPath: []string{"DEPLOYMENT_NAME", "resources"},
+			ResourceType:   "Microsoft.Storage/storageAccounts",
+			ResourceName:   "sttest",
+			Code:           "RequestDisallowedByPolicy",
+			Message:        "Resource 'sttest' was disallowed by policy.",
+			Category:       DeploymentFailureCategoryPolicy,
+		},
+	}, failures)
+
+	deploymentErr := errors.New("deployment error")
+	failedErr := &DeploymentFailedError{Failures: failures, Err: deploymentErr}
+	require.ErrorIs(t, failedErr, deploymentErr)
+	require.Contains(t, failedErr.Error(), "Microsoft.Storage/storageAccounts 'sttest' (deployment DEPLOYMENT_NAME > resources)")
+	require.Contains(t, failedErr.Error(), "RequestDisallowedByPolicy: Resource 'sttest' was disallowed by policy.")
+}
+
+func TestDeploymentFailureCategory(t *testing.T) {
+	require.Equal(t, DeploymentFailureCategoryQuota, deploymentFailureCategory("QuotaExceeded", ""))
+	require.Equal(t,
+		DeploymentFailureCategoryQuota,
+		deploymentFailureCategory("OperationNotAllowed", "Operation results in exceeding quota limits of Core."))
+	require.Equal(t, DeploymentFailureCategoryUnknown, deploymentFailureCategory("OperationNotAllowed", "Not allowed."))
+	require.Equal(t, DeploymentFailureCategorySkuNotAvailable, deploymentFailureCategory("SkuNotAvailable", ""))
+	require.Equal(t, DeploymentFailureCategoryPolicy, deploymentFailureCategory("RequestDisallowedByPolicy", ""))
+	require.Equal(t, DeploymentFailureCategoryUnknown, deploymentFailureCategory("InvalidTemplate", ""))
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
+)
+
+// DefaultArmModule is the default module of ARM templates, named like the templates of the Azure Quickstart Templates,
+// ex) azuredeploy.json and azuredeploy.parameters.json
+const DefaultArmModule = "azuredeploy"
+
+// NewArmProvider creates a new instance of an infra provider deploying ARM JSON templates. ARM templates are deployed like
+// compiled Bicep modules: the parameters missing from the parameters files are prompted from the parameters of the
+// template, and the outputs of the deployment are stored in the environment.
+func NewArmProvider(
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	console input.Console,
+	prompters prompt.Prompter,
+	curPrincipal CurrentPrincipalIdProvider,
+	alphaFeatureManager *alpha.FeatureManager,
+	clock clock.Clock,
+) Provider {
+	return &BicepProvider{
+		env:                 env,
+		console:             console,
+		azCli:               azCli,
+		prompters:           prompters,
+		curPrincipal:        curPrincipal,
+		alphaFeatureManager: alphaFeatureManager,
+		clock:               clock,
+		armTemplate:         true,
+	}
+}
+
+// readArmTemplate reads the ARM JSON template at templatePath
+func readArmTemplate(templatePath string) (azure.RawArmTemplate, azure.ArmTemplate, error) {
+	contents, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, azure.ArmTemplate{}, fmt.Errorf("reading arm template: %w", err)
+	}
+
+	rawTemplate := azure.RawArmTemplate(contents)
+
+	var template azure.ArmTemplate
+	if err := json.Unmarshal(rawTemplate, &template); err != nil {
+		return nil, azure.ArmTemplate{}, fmt.Errorf("failed unmarshalling arm template '%s' from json: %w", templatePath, err)
+	}
+
+	return rawTemplate, template, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const classicArmTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+		"siteName": {
+			"type": "String"
+		},
+		"adminPassword": {
+			"type": "SecureString",
+			"metadata": {
+				"description": "The password of the administrator"
+			}
+		}
+	},
+	"resources": [],
+	"outputs": {
+		"siteUrl": {
+			"type": "String",
+			"value": "[concat('https://', parameters('siteName'), '.azurewebsites.net')]"
+		}
+	}
+}`
+
+func createArmProvider(t *testing.T, mockContext *mocks.MockContext, files map[string]string) *BicepProvider {
+	projectPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "infra"), osutil.PermissionDirectory))
+	for name, contents := range files {
+		require.NoError(t,
+			os.WriteFile(filepath.Join(projectPath, "infra", name), []byte(contents), osutil.PermissionFile))
+	}
+
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		environment.LocationEnvVarName:       "westus2",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	accountManager := &mockaccount.MockAccountManager{
+		Subscriptions: []account.Subscription{{Id: "SUBSCRIPTION_ID", Name: "test"}},
+		Locations:     []account.Location{{Name: "westus2", DisplayName: "West US 2"}},
+	}
+
+	provider := NewArmProvider(
+		azCli,
+		env,
+		mockContext.Console,
+		prompt.NewDefaultPrompter(env, mockContext.Console, accountManager, azCli),
+		&mockCurrentPrincipal{},
+		mockContext.AlphaFeaturesManager,
+		clock.NewMock(),
+	)
+
+	require.NoError(t, provider.Initialize(*mockContext.Context, projectPath, Options{Path: "infra"}))
+	return provider.(*BicepProvider)
+}
+
+func TestArmPlan(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.Console.WhenPrompt(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Message, "for the 'adminPassword' infrastructure parameter")
+	}).Respond("P@ssw0rd")
+	mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Message, "Save the value in the environment for future use")
+	}).Respond(false)
+
+	// the template and the parameters files are named by the default module of ARM templates
+	provider := createArmProvider(t, mockContext, map[string]string{
+		"azuredeploy.json":                     classicArmTemplate,
+		"azuredeploy.parameters.json":          `{"parameters": {"siteName": {"value": "app-${AZURE_ENV_NAME}"}}}`,
+		"azuredeploy.parameters.test-env.json": `{"parameters": {"siteName": {"value": "app-test"}}}`,
+	})
+	require.Equal(t, "ARM", provider.Name())
+
+	plan, err := provider.Plan(*mockContext.Context)
+	require.NoError(t, err)
+
+	details := plan.Details.(BicepDeploymentDetails)
+	require.JSONEq(t, classicArmTemplate, string(details.Template))
+	require.Equal(t, "app-test", details.Parameters["siteName"].Value)
+	require.Equal(t, "P@ssw0rd", details.Parameters["adminPassword"].Value)
+
+	require.Equal(t, string(ParameterTypeString), plan.Deployment.Parameters["adminPassword"].Type)
+	require.Equal(t, ParameterTypeString, plan.Deployment.Outputs["siteUrl"].Type)
+}
+
+func TestArmPlanMissingTemplate(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	provider := createArmProvider(t, mockContext, map[string]string{
+		"azuredeploy.parameters.json": `{"parameters": {}}`,
+	})
+
+	_, err := provider.Plan(*mockContext.Context)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
@@ -52,6 +53,8 @@ type BicepDeploymentDetails struct {
 	// Target is the unique resource in azure that represents the deployment that will happen. A target can be scoped to
 	// either subscriptions, or resource groups.
 	Target infra.Deployment
+	// Governance is the resolved set of required tags and naming convention verified after the deployment.
+	Governance *ResolvedGovernance
 }
 
 // BicepProvider exposes infrastructure provisioning using Azure Bicep templates
@@ -66,6 +69,8 @@ type BicepProvider struct {
 	curPrincipal        CurrentPrincipalIdProvider
 	alphaFeatureManager *alpha.FeatureManager
 	clock               clock.Clock
+	// armTemplate is set for providers deploying ARM JSON templates, which are deployed as is, without the bicep CLI
+	armTemplate bool
 }
 
 var ErrResourceGroupScopeNotSupported = fmt.Errorf(
@@ -75,6 +80,10 @@ var ErrResourceGroupScopeNotSupported = fmt.Errorf(
 
 // Name gets the name of the infra provider
 func (p *BicepProvider) Name() string {
+	if p.armTemplate {
+		return "ARM"
+	}
+
 	return "Bicep"
 }
 
@@ -85,6 +94,9 @@ func (p *BicepProvider) RequiredExternalTools() []tools.ExternalTool {
 func (p *BicepProvider) Initialize(ctx context.Context, projectPath string, options Options) error {
 	if strings.TrimSpace(options.Module) == "" {
 		options.Module = DefaultModule
+		if p.armTemplate {
+			options.Module = DefaultArmModule
+		}
 	}
 
 	p.projectPath = projectPath
@@ -111,7 +123,7 @@ func (p *BicepProvider) State(ctx context.Context) (*StateResult, error) {
 	}()
 
 	modulePath := p.modulePath()
-	_, template, err := p.compileBicep(ctx, modulePath)
+	_, template, err := p.loadTemplate(ctx, modulePath)
 	if err != nil {
 		return nil, fmt.Errorf("compiling bicep template: %w", err)
 	}
@@ -165,13 +177,41 @@ func (p *BicepProvider) Plan(ctx context.Context) (*DeploymentPlan, error) {
 		return nil, fmt.Errorf("creating parameters file: %w", err)
 	}
 
+	governance, err := ResolveGovernance(p.options.Governance, p.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("resolving governance options: %w", err)
+	}
+
+	if err := p.options.OpenAI.Validate(); err != nil {
+		return nil, err
+	}
+
+	networkMode, err := ParseNetworkMode(string(p.options.Network))
+	if err != nil {
+		return nil, err
+	}
+
 	modulePath := p.modulePath()
 	// TODO: Report progress, "Compiling Bicep template"
-	rawTemplate, template, err := p.compileBicep(ctx, modulePath)
+	rawTemplate, template, err := p.loadTemplate(ctx, modulePath)
 	if err != nil {
 		return nil, fmt.Errorf("creating template: %w", err)
 	}
 
+	parameters = applyGovernanceParameters(template, parameters, governance)
+	parameters = applyOpenAIParameters(template, parameters, p.options.OpenAI)
+
+	parameters, networkDeclared := applyNetworkParameters(template, parameters, networkMode)
+	if networkMode == NetworkModePrivate && !networkDeclared {
+		p.console.MessageUxItem(ctx, &ux.WarningMessage{
+			Description: fmt.Sprintf(
+				"The template doesn't declare the '%s' or '%s' parameters, the private network mode has no effect",
+				PrivateNetworkingParameterName,
+				PublicNetworkAccessParameterName,
+			),
+		})
+	}
+
 	configuredParameters, err := p.ensureParameters(ctx, template, parameters)
 	if err != nil {
 		return nil, err
@@ -232,6 +272,7 @@ func (p *BicepProvider) Plan(ctx context.Context) (*DeploymentPlan, error) {
 			TemplateOutputs: template.Outputs,
 			Parameters:      configuredParameters,
 			Target:          target,
+			Governance:      governance,
 		},
 	}, nil
 }
@@ -251,9 +292,57 @@ func deploymentNameForEnv(envName string, clock clock.Clock) string {
 	return name[len(name)-cArmDeploymentNameLengthMax:]
 }
 
+// inFlightDeploymentConfigKey is the environment config key storing the deployment currently being provisioned, used to
+// re-attach to the deployment when the azd process that started it exits before the deployment completes.
+const inFlightDeploymentConfigKey = "provision.inFlightDeployment"
+
 // Provisioning the infrastructure within the specified template
 func (p *BicepProvider) Deploy(ctx context.Context, pd *DeploymentPlan) (*DeployResult, error) {
 	bicepDeploymentData := pd.Details.(BicepDeploymentDetails)
+	if err := p.runPreflightChecks(ctx, bicepDeploymentData); err != nil {
+		return nil, err
+	}
+
+	return p.deploy(ctx, pd, bicepDeploymentData.Target, "")
+}
+
+// Attach re-attaches to the in-flight deployment started by a previous azd process for the environment and waits for it
+// to complete, instead of starting a new deployment.
+func (p *BicepProvider) Attach(ctx context.Context, pd *DeploymentPlan) (*DeployResult, error) {
+	bicepDeploymentData := pd.Details.(BicepDeploymentDetails)
+
+	name, hasName := p.env.Config.Get(inFlightDeploymentConfigKey + ".name")
+	resumeToken, hasToken := p.env.Config.Get(inFlightDeploymentConfigKey + ".resumeToken")
+	deploymentName, nameOk := name.(string)
+	token, tokenOk := resumeToken.(string)
+	if !hasName || !hasToken || !nameOk || !tokenOk {
+		return nil, fmt.Errorf("%w for environment '%s'", ErrNoInFlightDeployment, p.env.GetEnvName())
+	}
+
+	// The plan targets a new deployment, target the in-flight deployment instead so progress is reported for it.
+	var target infra.Deployment
+	switch t := bicepDeploymentData.Target.(type) {
+	case *infra.SubscriptionDeployment:
+		target = infra.NewSubscriptionDeployment(p.azCli, t.Location(), t.SubscriptionId(), deploymentName)
+	case *infra.ResourceGroupDeployment:
+		target = infra.NewResourceGroupDeployment(p.azCli, t.SubscriptionId(), t.ResourceGroupName(), deploymentName)
+	default:
+		return nil, fmt.Errorf("unsupported deployment target: %T", t)
+	}
+
+	p.console.Message(ctx, fmt.Sprintf("Attaching to in-flight deployment %s", output.WithHighLightFormat(deploymentName)))
+
+	return p.deploy(ctx, pd, target, token)
+}
+
+// deploy starts the deployment of the plan, or resumes the in-flight deployment when a resume token is specified
+func (p *BicepProvider) deploy(
+	ctx context.Context,
+	pd *DeploymentPlan,
+	target infra.Deployment,
+	resumeToken string,
+) (*DeployResult, error) {
+	bicepDeploymentData := pd.Details.(BicepDeploymentDetails)
 
 	cancelProgress := make(chan bool)
 	defer func() { cancelProgress <- true }()
@@ -267,7 +356,7 @@ func (p *BicepProvider) Deploy(ctx context.Context, pd *DeploymentPlan) (*Deploy
 
 		// Report incremental progress
 		resourceManager := infra.NewAzureResourceManager(p.azCli)
-		progressDisplay := NewProvisioningProgressDisplay(resourceManager, p.console, bicepDeploymentData.Target)
+		progressDisplay := NewProvisioningProgressDisplay(resourceManager, p.console, target)
 		// Make initial delay shorter to be more responsive in displaying initial progress
 		initialDelay := 3 * time.Second
 		regularDelay := 10 * time.Second
@@ -293,16 +382,40 @@ func (p *BicepProvider) Deploy(ctx context.Context, pd *DeploymentPlan) (*Deploy
 	// Start the deployment
 	p.console.ShowSpinner(ctx, "Creating/Updating resources", input.Step)
 
+	deploymentTags := map[string]*string{
+		azure.TagKeyAzdEnvName: to.Ptr(p.env.GetEnvName()),
+	}
+
+	if bicepDeploymentData.Governance != nil {
+		for key, value := range bicepDeploymentData.Governance.RequiredTags {
+			deploymentTags[key] = to.Ptr(value)
+		}
+	}
+
 	deployResult, err := p.deployModule(
 		ctx,
-		bicepDeploymentData.Target,
+		target,
 		bicepDeploymentData.Template,
 		bicepDeploymentData.Parameters,
-		map[string]*string{
-			azure.TagKeyAzdEnvName: to.Ptr(p.env.GetEnvName()),
+		deploymentTags,
+		&azcli.DeployOptions{
+			ResumeToken: resumeToken,
+			OnStarted: func(resumeToken string) {
+				p.saveInFlightDeployment(target.Name(), resumeToken)
+			},
 		},
 	)
+
+	// When azd is interrupted the deployment keeps running in Azure, keep the resume token to allow re-attaching to it.
+	if !errors.Is(err, context.Canceled) {
+		p.clearInFlightDeployment()
+	}
+
 	if err != nil {
+		return nil, p.explainDeploymentError(ctx, target, err)
+	}
+
+	if err := p.verifyGovernance(ctx, bicepDeploymentData.Governance, deployResult); err != nil {
 		return nil, err
 	}
 
@@ -312,11 +425,201 @@ func (p *BicepProvider) Deploy(ctx context.Context, pd *DeploymentPlan) (*Deploy
 		azcli.CreateDeploymentOutput(deployResult.Properties.Outputs),
 	)
 
+	if p.options.OpenAI != nil && p.options.OpenAI.AuthKind() == OpenAIAuthKey {
+		if err := p.addOpenAIKeyOutput(ctx, deployResult, deployment.Outputs); err != nil {
+			return nil, err
+		}
+	}
+
 	return &DeployResult{
 		Deployment: &deployment,
 	}, nil
 }
 
+// explainDeploymentError drills into the operations of a failed deployment to report the innermost failing resources and
+// their errors instead of the top level deployment error. The original error is returned when the failures can't be
+// determined.
+func (p *BicepProvider) explainDeploymentError(ctx context.Context, target infra.Deployment, err error) error {
+	var deploymentErr *azcli.AzureDeploymentError
+	if !errors.As(err, &deploymentErr) {
+		return err
+	}
+
+	resourceManager := infra.NewAzureResourceManager(p.azCli)
+	failures, findErr := resourceManager.FindDeploymentFailures(ctx, target)
+	if findErr != nil {
+		log.Printf("failed finding the failing resources of deployment '%s': %v", target.Name(), findErr)
+		return err
+	}
+
+	if len(failures) == 0 {
+		return err
+	}
+
+	log.Printf("deployment '%s' failed: %v", target.Name(), err)
+	return &infra.DeploymentFailedError{
+		Failures: failures,
+		Err:      err,
+	}
+}
+
+// saveInFlightDeployment persists the resume token of the started deployment in the environment configuration
+func (p *BicepProvider) saveInFlightDeployment(deploymentName string, resumeToken string) {
+	err := p.env.Config.Set(inFlightDeploymentConfigKey, map[string]any{
+		"name":        deploymentName,
+		"resumeToken": resumeToken,
+	})
+	if err == nil {
+		err = p.env.Save()
+	}
+
+	if err != nil {
+		log.Printf("failed saving resume token for deployment '%s': %v", deploymentName, err)
+	}
+}
+
+// clearInFlightDeployment removes the resume token of a completed deployment from the environment configuration
+func (p *BicepProvider) clearInFlightDeployment() {
+	if _, has := p.env.Config.Get(inFlightDeploymentConfigKey); !has {
+		return
+	}
+
+	err := p.env.Config.Unset(inFlightDeploymentConfigKey)
+	if err == nil {
+		err = p.env.Save()
+	}
+
+	if err != nil {
+		log.Printf("failed clearing in-flight deployment: %v", err)
+	}
+}
+
+// applyGovernanceParameters provides the required tags and naming convention to templates declaring the
+// `requiredTags` and `namingConvention` parameters, unless the values are explicitly set in the parameters file.
+func applyGovernanceParameters(
+	template azure.ArmTemplate,
+	parameters azure.ArmParameters,
+	governance *ResolvedGovernance,
+) azure.ArmParameters {
+	if parameters == nil {
+		parameters = azure.ArmParameters{}
+	}
+
+	if _, has := template.Parameters[RequiredTagsParameterName]; has && len(governance.RequiredTags) > 0 {
+		if _, has := parameters[RequiredTagsParameterName]; !has {
+			requiredTags := map[string]any{}
+			for key, value := range governance.RequiredTags {
+				requiredTags[key] = value
+			}
+
+			parameters[RequiredTagsParameterName] = azure.ArmParameterValue{Value: requiredTags}
+		}
+	}
+
+	if _, has := template.Parameters[NamingConventionParameterName]; has && governance.NamingConvention != "" {
+		if _, has := parameters[NamingConventionParameterName]; !has {
+			parameters[NamingConventionParameterName] = azure.ArmParameterValue{Value: governance.NamingConvention}
+		}
+	}
+
+	return parameters
+}
+
+// verifyGovernance checks the resources created by the deployment carry the required tags and follow the naming
+// convention. Violations are reported as warnings unless the governance options are enforced.
+func (p *BicepProvider) verifyGovernance(
+	ctx context.Context,
+	governance *ResolvedGovernance,
+	deployment *armresources.DeploymentExtended,
+) error {
+	if governance.IsEmpty() {
+		return nil
+	}
+
+	spinnerMessage := "Verifying required tags and naming convention"
+	p.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+
+	resources, err := p.deployedResources(ctx, deployment)
+	if err != nil {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+		return fmt.Errorf("listing provisioned resources: %w", err)
+	}
+
+	violations := governance.Verify(resources)
+	if len(violations) == 0 {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepDone)
+		return nil
+	}
+
+	if governance.Enforce {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+		return &GovernanceViolationsError{Violations: violations}
+	}
+
+	p.console.StopSpinner(ctx, spinnerMessage, input.StepWarning)
+	for _, violation := range violations {
+		p.console.Message(ctx, output.WithWarningFormat("WARNING: %s", violation))
+	}
+
+	return nil
+}
+
+// deployedResources returns the resource groups and top level resources referenced by the deployment outputs
+func (p *BicepProvider) deployedResources(
+	ctx context.Context,
+	deployment *armresources.DeploymentExtended,
+) ([]GovernanceResource, error) {
+	outputResources := map[string]struct{}{}
+	for _, resourceId := range deployment.Properties.OutputResources {
+		if resourceId != nil && resourceId.ID != nil {
+			outputResources[strings.ToLower(*resourceId.ID)] = struct{}{}
+		}
+	}
+
+	isDeployed := func(resource azcli.AzCliResource) bool {
+		_, has := outputResources[strings.ToLower(resource.Id)]
+		return has
+	}
+
+	resources := []GovernanceResource{}
+	subscriptionId := p.env.GetSubscriptionId()
+
+	groups, err := p.azCli.ListResourceGroup(ctx, subscriptionId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if isDeployed(group) {
+			resources = append(resources, governanceResource(group))
+		}
+	}
+
+	for _, resourceGroup := range resourceGroupsFromDeployment(deployment) {
+		groupResources, err := p.azCli.ListResourceGroupResources(ctx, subscriptionId, resourceGroup, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range groupResources {
+			if isDeployed(resource) {
+				resources = append(resources, governanceResource(resource))
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+func governanceResource(resource azcli.AzCliResource) GovernanceResource {
+	return GovernanceResource{
+		Id:   resource.Id,
+		Name: resource.Name,
+		Type: resource.Type,
+		Tags: resource.Tags,
+	}
+}
+
 type itemToPurge struct {
 	resourceType      string
 	count             int
@@ -367,7 +670,7 @@ func (p *BicepProvider) scopeForTemplate(ctx context.Context, t azure.ArmTemplat
 func (p *BicepProvider) Destroy(ctx context.Context, options DestroyOptions) (*DestroyResult, error) {
 	modulePath := p.modulePath()
 	// TODO: Report progress, "Compiling Bicep template"
-	_, template, err := p.compileBicep(ctx, modulePath)
+	_, template, err := p.loadTemplate(ctx, modulePath)
 	if err != nil {
 		return nil, fmt.Errorf("creating template: %w", err)
 	}
@@ -391,6 +694,8 @@ func (p *BicepProvider) Destroy(ctx context.Context, options DestroyOptions) (*D
 		return nil, fmt.Errorf("getting resources to delete: %w", err)
 	}
 
+	p.excludeResourceGroups(ctx, options, groupedResources)
+
 	allResources := []azcli.AzCliResource{}
 	for _, groupResources := range groupedResources {
 		allResources = append(allResources, groupResources...)
@@ -630,6 +935,40 @@ func (p *BicepProvider) getAllResourcesToDelete(
 	return allResources, nil
 }
 
+// excludeResourceGroups removes the resource groups which are excluded from deletion, or contain resources excluded from
+// deletion, from the resource groups to delete. Deleting a resource group deletes all of its resources.
+func (p *BicepProvider) excludeResourceGroups(
+	ctx context.Context,
+	options DestroyOptions,
+	groupedResources map[string][]azcli.AzCliResource,
+) {
+	if len(options.ExcludedResources()) == 0 {
+		return
+	}
+
+	for resourceGroup, resources := range groupedResources {
+		groupId := azure.ResourceGroupRID(p.env.GetSubscriptionId(), resourceGroup)
+		if options.IsExcluded(groupId, resourceGroup) {
+			delete(groupedResources, resourceGroup)
+			p.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: Skipping resource group %s, it is excluded from deletion.", resourceGroup))
+			continue
+		}
+
+		for _, resource := range resources {
+			if options.IsExcluded(resource.Id, resource.Name) {
+				delete(groupedResources, resourceGroup)
+				p.console.Message(ctx, output.WithWarningFormat(
+					"WARNING: Skipping resource group %s, it contains %s which is excluded from deletion.",
+					resourceGroup,
+					resource.Name,
+				))
+				break
+			}
+		}
+	}
+}
+
 func generateResourceGroupsToDelete(groupedResources map[string][]azcli.AzCliResource, subId string) []string {
 	lines := []string{"Resource group(s) to be deleted:", ""}
 
@@ -1070,16 +1409,17 @@ func (p *BicepProvider) purgeAPIManagement(
 }
 
 func (p *BicepProvider) mapBicepTypeToInterfaceType(s string) ParameterType {
-	switch s {
-	case "String", "string", "secureString", "securestring":
+	// types are case insensitive, ex) SecureString in templates authored in JSON
+	switch strings.ToLower(s) {
+	case "string", "securestring":
 		return ParameterTypeString
-	case "Bool", "bool":
+	case "bool":
 		return ParameterTypeBoolean
-	case "Int", "int":
+	case "int":
 		return ParameterTypeNumber
-	case "Object", "object", "secureObject", "secureobject":
+	case "object", "secureobject":
 		return ParameterTypeObject
-	case "Array", "array":
+	case "array":
 		return ParameterTypeArray
 	default:
 		panic(fmt.Sprintf("unexpected bicep type: '%s'", s))
@@ -1118,22 +1458,57 @@ func (p *BicepProvider) createOutputParameters(
 	return outputParams
 }
 
-// loadParameters reads the parameters file template for environment/module specified by Options,
-// doing environment and command substitutions, and returns the values.
+// loadParameters reads the parameters files for environment/module specified by Options, doing environment and
+// command substitutions, and returns the values. The values of the parameters file of the environment, ex)
+// main.parameters.dev.json, are layered over the ones of the base parameters file, ex) main.parameters.json.
 func (p *BicepProvider) loadParameters(ctx context.Context) (map[string]azure.ArmParameterValue, error) {
-	parametersTemplateFilePath := p.parametersTemplateFilePath()
-	log.Printf("Reading parameters template file from: %s", parametersTemplateFilePath)
-	parametersBytes, err := os.ReadFile(parametersTemplateFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("reading parameter file template: %w", err)
-	}
-
 	principalId, err := p.curPrincipal.CurrentPrincipalId(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("fetching current principal id: %w", err)
 	}
 
-	replaced, err := envsubst.Eval(string(parametersBytes), func(name string) string {
+	parameters := map[string]azure.ArmParameterValue{}
+	for _, parametersFilePath := range p.parametersFilePaths() {
+		log.Printf("Reading parameters file from: %s", parametersFilePath)
+		layer, err := p.readParametersFile(ctx, parametersFilePath, principalId)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range layer {
+			parameters[name] = value
+		}
+	}
+
+	return parameters, nil
+}
+
+// readParametersFile reads a parameters file, compiling .bicepparam files to JSON, and substitutes the environment
+// variables and commands inside it
+func (p *BicepProvider) readParametersFile(
+	ctx context.Context,
+	parametersFilePath string,
+	principalId string,
+) (map[string]azure.ArmParameterValue, error) {
+	var parametersContents string
+	if filepath.Ext(parametersFilePath) == bicepParamsFileExtension {
+		env := append(p.env.Environ(), fmt.Sprintf("%s=%s", environment.PrincipalIdEnvVarName, principalId))
+		compiled, err := p.bicepCli.BuildParams(ctx, parametersFilePath, env)
+		if err != nil {
+			return nil, fmt.Errorf("compiling parameter file: %w", err)
+		}
+
+		parametersContents = compiled
+	} else {
+		parametersBytes, err := os.ReadFile(parametersFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading parameter file template: %w", err)
+		}
+
+		parametersContents = string(parametersBytes)
+	}
+
+	replaced, err := envsubst.Eval(parametersContents, func(name string) string {
 		if name == environment.PrincipalIdEnvVarName {
 			return principalId
 		}
@@ -1160,6 +1535,17 @@ func (p *BicepProvider) loadParameters(ctx context.Context) (map[string]azure.Ar
 	return armParameters.Parameters, nil
 }
 
+// loadTemplate returns the ARM template deployed for the module, compiling Bicep modules
+func (p *BicepProvider) loadTemplate(
+	ctx context.Context, modulePath string,
+) (azure.RawArmTemplate, azure.ArmTemplate, error) {
+	if p.armTemplate {
+		return readArmTemplate(modulePath)
+	}
+
+	return p.compileBicep(ctx, modulePath)
+}
+
 func (p *BicepProvider) compileBicep(
 	ctx context.Context, modulePath string,
 ) (azure.RawArmTemplate, azure.ArmTemplate, error) {
@@ -1213,24 +1599,84 @@ func (p *BicepProvider) deployModule(
 	armTemplate azure.RawArmTemplate,
 	armParameters azure.ArmParameters,
 	tags map[string]*string,
+	options *azcli.DeployOptions,
 ) (*armresources.DeploymentExtended, error) {
-	return target.Deploy(ctx, armTemplate, armParameters, tags)
+	return target.Deploy(ctx, armTemplate, armParameters, tags, options)
 }
 
-// Gets the path to the project parameters file path
-func (p *BicepProvider) parametersTemplateFilePath() string {
-	infraPath := p.options.Path
-	parametersFilename := fmt.Sprintf("%s.parameters.json", p.options.Module)
-	return filepath.Join(p.projectPath, infraPath, parametersFilename)
+const bicepParamsFileExtension = ".bicepparam"
+
+// Gets the paths of the parameters files of the project, in the order their values are layered: the base parameters
+// file, ex) main.parameters.json or main.bicepparam, then the parameters file of the environment, ex)
+// main.parameters.dev.json or main.dev.bicepparam. Either file may be missing, but not both.
+func (p *BicepProvider) parametersFilePaths() []string {
+	infraPath := filepath.Join(p.projectPath, p.options.Path)
+	module := p.options.Module
+	envName := p.env.GetEnvName()
+
+	layers := [][]string{
+		{
+			fmt.Sprintf("%s.parameters.json", module),
+			fmt.Sprintf("%s%s", module, bicepParamsFileExtension),
+		},
+		{
+			fmt.Sprintf("%s.parameters.%s.json", module, envName),
+			fmt.Sprintf("%s.%s%s", module, envName, bicepParamsFileExtension),
+		},
+	}
+
+	paths := []string{}
+	for _, layer := range layers {
+		for _, filename := range layer {
+			// ARM templates are deployed without the bicep CLI, which compiles .bicepparam files
+			if p.armTemplate && filepath.Ext(filename) == bicepParamsFileExtension {
+				continue
+			}
+
+			path := filepath.Join(infraPath, filename)
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		// reading the base parameters file reports it's missing
+		return []string{filepath.Join(infraPath, layers[0][0])}
+	}
+
+	return paths
 }
 
 // Gets the folder path to the specified module
 func (p *BicepProvider) modulePath() string {
 	infraPath := p.options.Path
 	moduleFilename := fmt.Sprintf("%s.bicep", p.options.Module)
+	if p.armTemplate {
+		moduleFilename = fmt.Sprintf("%s.json", p.options.Module)
+	}
+
 	return filepath.Join(p.projectPath, infraPath, moduleFilename)
 }
 
+// redactSecureValue flags the string values of a secure parameter as secure, so they are redacted from logs and
+// telemetry
+func redactSecureValue(value any) {
+	switch v := value.(type) {
+	case string:
+		redact.AddSecret(v)
+	case map[string]any:
+		for _, item := range v {
+			redactSecureValue(item)
+		}
+	case []any:
+		for _, item := range v {
+			redactSecureValue(item)
+		}
+	}
+}
+
 // Ensures the provisioning parameters are valid and prompts the user for input as needed
 func (p *BicepProvider) ensureParameters(
 	ctx context.Context,
@@ -1253,8 +1699,13 @@ func (p *BicepProvider) ensureParameters(
 
 		// If a value is explicitly configured via a parameters file, use it.
 		if v, has := parameters[key]; has {
+			value := armParameterFileValue(p.mapBicepTypeToInterfaceType(param.Type), v.Value)
+			if param.Secure() {
+				redactSecureValue(value)
+			}
+
 			configuredParameters[key] = azure.ArmParameterValue{
-				Value: armParameterFileValue(p.mapBicepTypeToInterfaceType(param.Type), v.Value),
+				Value: value,
 			}
 			continue
 		}
@@ -1276,6 +1727,12 @@ func (p *BicepProvider) ensureParameters(
 				_ = p.env.Config.Unset("infra.parameters.%s")
 			}
 
+			if param.Secure() {
+				// Prompted secure values are never saved, this value was set on purpose, ex) with
+				// azd env config set. It's kept, and redacted from the output.
+				redactSecureValue(v)
+			}
+
 			configuredParameters[key] = azure.ArmParameterValue{
 				Value: v,
 			}
@@ -1288,7 +1745,10 @@ func (p *BicepProvider) ensureParameters(
 			return nil, fmt.Errorf("prompting for value: %w", err)
 		}
 
-		if !param.Secure() {
+		// Secure values are passed to the deployment only, they are never saved in the environment.
+		if param.Secure() {
+			redactSecureValue(value)
+		} else {
 			saveParameter, err := p.console.Confirm(ctx, input.ConsoleOptions{
 				Message: "Save the value in the environment for future use",
 			})
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -29,11 +31,14 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -110,6 +115,60 @@ func TestBicepPlanPrompt(t *testing.T) {
 	require.Equal(t, "value", bicepDetails.Parameters["stringParam"].Value)
 }
 
+const governanceArmJson = `{
+	"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+	  "requiredTags": {
+		"type": "object"
+	  },
+	  "namingConvention": {
+		"type": "string"
+	  }
+	},
+	"resources": [],
+	"outputs": {}
+  }`
+
+func TestBicepPlanGovernance(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(args.Cmd, "bicep") && args.Args[0] == "--version"
+	}).Respond(exec.RunResult{
+		Stdout: "Bicep CLI version 0.12.40 (41892bd0fb)",
+		Stderr: "",
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(args.Cmd, "bicep") && args.Args[0] == "build"
+	}).Respond(exec.RunResult{
+		Stdout: governanceArmJson,
+		Stderr: "",
+	})
+
+	infraProvider := createBicepProvider(t, mockContext)
+	infraProvider.env.DotenvSet("COST_CENTER", "1234")
+	infraProvider.options.Governance = &GovernanceOptions{
+		RequiredTags: map[string]string{
+			"costCenter": "${COST_CENTER}",
+			"owner":      "team-a",
+		},
+		NamingConvention: "*-${AZURE_ENV_NAME}-*",
+	}
+
+	plan, err := infraProvider.Plan(*mockContext.Context)
+	require.NoError(t, err)
+
+	bicepDetails := plan.Details.(BicepDeploymentDetails)
+	require.Equal(t,
+		map[string]any{"costCenter": "1234", "owner": "team-a"},
+		bicepDetails.Parameters[RequiredTagsParameterName].Value,
+	)
+	require.Equal(t, "*-test-env-*", bicepDetails.Parameters[NamingConventionParameterName].Value)
+	require.Equal(t, "1234", bicepDetails.Governance.RequiredTags["costCenter"])
+}
+
 func TestBicepState(t *testing.T) {
 	expectedWebsiteUrl := "http://myapp.azurewebsites.net"
 
@@ -157,6 +216,62 @@ func TestBicepDeploy(t *testing.T) {
 	require.Equal(t, deployResult.Deployment.Outputs["WEBSITE_URL"].Value, expectedWebsiteUrl)
 }
 
+func TestBicepDeployClearsInFlightDeployment(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareBicepMocks(mockContext)
+	prepareStateMocks(mockContext)
+	prepareDeployMocks(mockContext)
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	infraProvider := createBicepProvider(t, mockContext)
+	require.NoError(t, infraProvider.env.Config.Set(inFlightDeploymentConfigKey, map[string]any{
+		"name":        "test-env",
+		"resumeToken": "token",
+	}))
+
+	deploymentPlan := DeploymentPlan{
+		Deployment: Deployment{},
+		Details: BicepDeploymentDetails{
+			Template:   azure.RawArmTemplate("{}"),
+			Parameters: testArmParameters,
+			Target: infra.NewSubscriptionDeployment(
+				azCli,
+				infraProvider.env.GetLocation(),
+				infraProvider.env.GetSubscriptionId(),
+				infraProvider.env.GetEnvName(),
+			),
+		},
+	}
+
+	_, err := infraProvider.Deploy(*mockContext.Context, &deploymentPlan)
+	require.NoError(t, err)
+
+	_, has := infraProvider.env.Config.Get(inFlightDeploymentConfigKey)
+	require.False(t, has)
+}
+
+func TestBicepAttachWithoutInFlightDeployment(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareBicepMocks(mockContext)
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	infraProvider := createBicepProvider(t, mockContext)
+
+	deploymentPlan := DeploymentPlan{
+		Details: BicepDeploymentDetails{
+			Target: infra.NewSubscriptionDeployment(
+				azCli,
+				infraProvider.env.GetLocation(),
+				infraProvider.env.GetSubscriptionId(),
+				infraProvider.env.GetEnvName(),
+			),
+		},
+	}
+
+	_, err := infraProvider.Attach(*mockContext.Context, &deploymentPlan)
+	require.ErrorIs(t, err, ErrNoInFlightDeployment)
+}
+
 func TestBicepDestroy(t *testing.T) {
 	t.Run("Interactive", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
@@ -838,3 +953,159 @@ func TestDeploymentNameForEnv(t *testing.T) {
 		assert.LessOrEqual(t, len(deploymentName), 64)
 	}
 }
+
+func TestLoadParametersLayering(t *testing.T) {
+	const baseParameters = `{
+		"parameters": {
+			"environmentName": { "value": "${AZURE_ENV_NAME}" },
+			"location": { "value": "${AZURE_LOCATION}" },
+			"skuName": { "value": "B1" }
+		}
+	}`
+
+	newProvider := func(t *testing.T, files map[string]string) (*BicepProvider, *mockBicepParamsCli) {
+		projectPath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "infra"), osutil.PermissionDirectory))
+		for name, contents := range files {
+			require.NoError(t,
+				os.WriteFile(filepath.Join(projectPath, "infra", name), []byte(contents), osutil.PermissionFile))
+		}
+
+		bicepCli := &mockBicepParamsCli{}
+		return &BicepProvider{
+			env: environment.EphemeralWithValues("dev", map[string]string{
+				environment.LocationEnvVarName: "westus2",
+			}),
+			projectPath:  projectPath,
+			options:      Options{Path: "infra", Module: "main"},
+			bicepCli:     bicepCli,
+			curPrincipal: &mockCurrentPrincipal{},
+		}, bicepCli
+	}
+
+	t.Run("BaseOnly", func(t *testing.T) {
+		provider, _ := newProvider(t, map[string]string{"main.parameters.json": baseParameters})
+
+		parameters, err := provider.loadParameters(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"environmentName": {Value: "dev"},
+			"location":        {Value: "westus2"},
+			"skuName":         {Value: "B1"},
+		}, parameters)
+	})
+
+	t.Run("EnvironmentOverridesBase", func(t *testing.T) {
+		provider, _ := newProvider(t, map[string]string{
+			"main.parameters.json": baseParameters,
+			"main.parameters.dev.json": `{
+				"parameters": {
+					"skuName": { "value": "F1" },
+					"principalId": { "value": "${AZURE_PRINCIPAL_ID}" }
+				}
+			}`,
+			// parameters files of other environments are ignored
+			"main.parameters.prod.json": `{ "parameters": { "skuName": { "value": "P1v3" } } }`,
+		})
+
+		parameters, err := provider.loadParameters(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"environmentName": {Value: "dev"},
+			"location":        {Value: "westus2"},
+			"skuName":         {Value: "F1"},
+			"principalId":     {Value: "11111111-1111-1111-1111-111111111111"},
+		}, parameters)
+	})
+
+	t.Run("BicepParams", func(t *testing.T) {
+		provider, bicepCli := newProvider(t, map[string]string{
+			"main.bicepparam":     "using 'main.bicep'",
+			"main.dev.bicepparam": "using 'main.bicep'",
+		})
+		bicepCli.compiled = map[string]string{
+			"main.bicepparam":     baseParameters,
+			"main.dev.bicepparam": `{ "parameters": { "skuName": { "value": "F1" } } }`,
+		}
+
+		parameters, err := provider.loadParameters(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"environmentName": {Value: "dev"},
+			"location":        {Value: "westus2"},
+			"skuName":         {Value: "F1"},
+		}, parameters)
+		require.Contains(t, bicepCli.env, "AZURE_LOCATION=westus2")
+		require.Contains(t, bicepCli.env, "AZURE_PRINCIPAL_ID=11111111-1111-1111-1111-111111111111")
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		provider, _ := newProvider(t, nil)
+
+		_, err := provider.loadParameters(context.Background())
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+type mockBicepParamsCli struct {
+	bicep.BicepCli
+	compiled map[string]string
+	env      []string
+}
+
+func (m *mockBicepParamsCli) BuildParams(_ context.Context, file string, env []string) (string, error) {
+	m.env = env
+	return m.compiled[filepath.Base(file)], nil
+}
+
+func TestEnsureParametersSecure(t *testing.T) {
+	template := azure.ArmTemplate{
+		Parameters: azure.ArmTemplateParameterDefinitions{
+			"adminPassword": {Type: "secureString"},
+			"apiKey":        {Type: "secureString"},
+			"connection":    {Type: "secureObject"},
+		},
+	}
+
+	console := mockinput.NewMockConsole()
+	console.WhenPrompt(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Message, "adminPassword")
+	}).RespondFn(func(options input.ConsoleOptions) (any, error) {
+		require.True(t, options.IsPassword)
+		return "Pr0mptedP@ssword", nil
+	})
+	console.WhenConfirm(func(options input.ConsoleOptions) bool {
+		return true
+	}).RespondFn(func(options input.ConsoleOptions) (any, error) {
+		return nil, fmt.Errorf("unexpected confirmation: %s", options.Message)
+	})
+
+	env := environment.EphemeralWithValues("dev", nil)
+	// set on purpose, ex) with azd env config set
+	require.NoError(t, env.Config.Set("infra.parameters.apiKey", "S@vedApiKeyValue"))
+
+	provider := &BicepProvider{
+		env:     env,
+		console: console,
+	}
+
+	parameters, err := provider.ensureParameters(context.Background(), template, azure.ArmParameters{
+		"connection": {Value: map[string]any{"password": "F1leP@sswordValue"}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "Pr0mptedP@ssword", parameters["adminPassword"].Value)
+	require.Equal(t, "S@vedApiKeyValue", parameters["apiKey"].Value)
+
+	// prompted secure values are never saved in the environment, the configured values are kept
+	_, has := env.Config.Get("infra.parameters.adminPassword")
+	require.False(t, has)
+	apiKey, has := env.Config.Get("infra.parameters.apiKey")
+	require.True(t, has)
+	require.Equal(t, "S@vedApiKeyValue", apiKey)
+
+	// and are redacted from logs and telemetry
+	require.Equal(t,
+		"<redacted> <redacted> <redacted>",
+		redact.String("Pr0mptedP@ssword S@vedApiKeyValue F1leP@sswordValue"))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+)
+
+// Drift runs an ARM what-if of the template of the project against the provisioned resources. Resources what-if
+// predicts provisioning would create or modify have drifted.
+func (p *BicepProvider) Drift(ctx context.Context) (*DriftResult, error) {
+	plan, err := p.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	details := plan.Details.(BicepDeploymentDetails)
+
+	p.console.ShowSpinner(ctx, "Comparing provisioned resources with the template", input.Step)
+	whatIfResult, err := details.Target.WhatIf(ctx, details.Template, details.Parameters)
+	p.console.StopSpinner(ctx, "Comparing provisioned resources with the template", input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	if whatIfResult.Error != nil && whatIfResult.Error.Message != nil {
+		return nil, fmt.Errorf("what-if deployment failed: %s", *whatIfResult.Error.Message)
+	}
+
+	return driftFromWhatIf(whatIfResult), nil
+}
+
+// driftFromWhatIf collects the resources what-if predicts would be created or modified, with the paths of the
+// properties which would change. Properties which wouldn't change once deployed, ex) read-only properties, are ignored.
+func driftFromWhatIf(whatIfResult *armresources.WhatIfOperationResult) *DriftResult {
+	result := &DriftResult{
+		Resources: []*DriftedResource{},
+	}
+
+	if whatIfResult.Properties == nil {
+		return result
+	}
+
+	for _, change := range whatIfResult.Properties.Changes {
+		if change == nil || change.ChangeType == nil || change.ResourceID == nil {
+			continue
+		}
+
+		switch *change.ChangeType {
+		case armresources.ChangeTypeCreate, armresources.ChangeTypeModify:
+		default:
+			continue
+		}
+
+		properties := []string{}
+		for _, delta := range change.Delta {
+			if delta.Path == nil ||
+				(delta.PropertyChangeType != nil && *delta.PropertyChangeType == armresources.PropertyChangeTypeNoEffect) {
+				continue
+			}
+			properties = append(properties, *delta.Path)
+		}
+
+		// a modification only of properties without effect isn't a drift
+		if *change.ChangeType == armresources.ChangeTypeModify && len(properties) == 0 {
+			continue
+		}
+
+		result.Resources = append(result.Resources, &DriftedResource{
+			Id:         *change.ResourceID,
+			ChangeType: string(*change.ChangeType),
+			Properties: properties,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftFromWhatIf(t *testing.T) {
+	whatIfResult := &armresources.WhatIfOperationResult{
+		Properties: &armresources.WhatIfOperationProperties{
+			Changes: []*armresources.WhatIfChange{
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Web/sites/web"),
+					ChangeType: to.Ptr(armresources.ChangeTypeModify),
+					Delta: []*armresources.WhatIfPropertyChange{
+						{
+							Path:               to.Ptr("properties.siteConfig.alwaysOn"),
+							PropertyChangeType: to.Ptr(armresources.PropertyChangeTypeModify),
+						},
+						{
+							Path:               to.Ptr("properties.outboundIpAddresses"),
+							PropertyChangeType: to.Ptr(armresources.PropertyChangeTypeNoEffect),
+						},
+					},
+				},
+				{
+					// deleted outside of azd
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st"),
+					ChangeType: to.Ptr(armresources.ChangeTypeCreate),
+				},
+				{
+					// only changes without effect
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Web/serverfarms/plan"),
+					ChangeType: to.Ptr(armresources.ChangeTypeModify),
+					Delta: []*armresources.WhatIfPropertyChange{
+						{
+							Path:               to.Ptr("properties.status"),
+							PropertyChangeType: to.Ptr(armresources.PropertyChangeTypeNoEffect),
+						},
+					},
+				},
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg"),
+					ChangeType: to.Ptr(armresources.ChangeTypeNoChange),
+				},
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"),
+					ChangeType: to.Ptr(armresources.ChangeTypeIgnore),
+				},
+			},
+		},
+	}
+
+	require.Equal(t, &provisioning.DriftResult{
+		Resources: []*provisioning.DriftedResource{
+			{
+				Id:         "/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Web/sites/web",
+				ChangeType: "Modify",
+				Properties: []string{"properties.siteConfig.alwaysOn"},
+			},
+			{
+				Id:         "/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st",
+				ChangeType: "Create",
+				Properties: []string{},
+			},
+		},
+	}, driftFromWhatIf(whatIfResult))
+}
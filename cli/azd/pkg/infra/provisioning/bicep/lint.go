@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+)
+
+// The rules azd checks in addition to the linter of bicep, named like the bicep rules they match, which aren't enabled
+// by default in bicep
+const (
+	noHardcodedLocationRule   = "no-hardcoded-location"
+	secureSecretsInParamsRule = "secure-secrets-in-params"
+	useRecentApiVersionsRule  = "use-recent-api-versions"
+)
+
+// maxApiVersionAge is the age of the api versions of resources reported by use-recent-api-versions, the default of bicep
+const maxApiVersionAge = 730 * 24 * time.Hour
+
+var (
+	lintModuleRegex   = regexp.MustCompile(`^\s*module\s+\w+\s+'([^']+)'`)
+	lintResourceRegex = regexp.MustCompile(`^\s*resource\s+\w+\s+'([^'@]+)@(\d{4}-\d{2}-\d{2})[^']*'`)
+	lintLocationRegex = regexp.MustCompile(`^\s*location:\s*'([^'$]*)'\s*$`)
+	lintParamRegex    = regexp.MustCompile(`^\s*param\s+(\w+)\s+(string|object)\b`)
+	secretNameRegex   = regexp.MustCompile(`(?i)(password|secret|token|connectionstring|key)$`)
+)
+
+// Lint reports the diagnostics of the bicep linter and the rules of azd for the module and the local modules it
+// references, with the severity configured for their rules. ARM templates aren't linted.
+func (p *BicepProvider) Lint(ctx context.Context) (*LintResult, error) {
+	if p.armTemplate || p.options.Lint.IsSkipped() {
+		return nil, nil
+	}
+
+	if err := p.options.Lint.Validate(); err != nil {
+		return nil, err
+	}
+
+	modulePath := p.modulePath()
+	diagnostics, err := p.bicepCli.Lint(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := lintFiles(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		diagnostics = append(diagnostics, analyzeBicepFile(file, string(contents), p.clock.Now())...)
+	}
+
+	return p.lintResult(diagnostics), nil
+}
+
+// lintResult groups the diagnostics by file, without the diagnostics of the rules turned off, nor the diagnostics of the
+// rules of azd the bicep linter reported too
+func (p *BicepProvider) lintResult(diagnostics []bicep.Diagnostic) *LintResult {
+	type diagnosticKey struct {
+		file string
+		line int
+		code string
+	}
+
+	seen := map[diagnosticKey]bool{}
+	filesByPath := map[string]*LintFile{}
+	for _, diagnostic := range diagnostics {
+		key := diagnosticKey{file: filepath.Clean(diagnostic.File), line: diagnostic.Line, code: diagnostic.Code}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		severity := p.options.Lint.Severity(diagnostic.Code, LintSeverity(strings.ToLower(diagnostic.Level)))
+		if severity == LintSeverityOff {
+			continue
+		}
+
+		path := diagnostic.File
+		if relative, err := filepath.Rel(p.projectPath, diagnostic.File); err == nil && !strings.HasPrefix(relative, "..") {
+			path = filepath.ToSlash(relative)
+		}
+
+		file, has := filesByPath[path]
+		if !has {
+			file = &LintFile{Path: path, Diagnostics: []*LintDiagnostic{}}
+			filesByPath[path] = file
+		}
+
+		file.Diagnostics = append(file.Diagnostics, &LintDiagnostic{
+			Line:     diagnostic.Line,
+			Column:   diagnostic.Column,
+			Rule:     diagnostic.Code,
+			Severity: severity,
+			Message:  diagnostic.Message,
+		})
+	}
+
+	result := &LintResult{Files: []*LintFile{}}
+	for _, file := range filesByPath {
+		sort.SliceStable(file.Diagnostics, func(i, j int) bool {
+			if file.Diagnostics[i].Line != file.Diagnostics[j].Line {
+				return file.Diagnostics[i].Line < file.Diagnostics[j].Line
+			}
+
+			return file.Diagnostics[i].Column < file.Diagnostics[j].Column
+		})
+		result.Files = append(result.Files, file)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	return result
+}
+
+// lintFiles returns the module and the local bicep modules it references, recursively. Modules of registries and template
+// specs aren't part of the project.
+func lintFiles(modulePath string) ([]string, error) {
+	files := []string{}
+	visited := map[string]bool{}
+
+	var visit func(path string) error
+	visit = func(path string) error {
+		if visited[path] {
+			return nil
+		}
+		visited[path] = true
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading bicep file: %w", err)
+		}
+		files = append(files, path)
+
+		for _, line := range strings.Split(string(contents), "\n") {
+			match := lintModuleRegex.FindStringSubmatch(line)
+			if match == nil || strings.Contains(match[1], ":") || filepath.Ext(match[1]) != ".bicep" {
+				continue
+			}
+
+			if err := visit(filepath.Join(filepath.Dir(path), filepath.FromSlash(match[1]))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := visit(modulePath); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// analyzeBicepFile reports the hardcoded locations of resources, the parameters named like secrets which aren't secure,
+// and the resources of api versions older than maxApiVersionAge
+func analyzeBicepFile(path string, contents string, now time.Time) []bicep.Diagnostic {
+	diagnostics := []bicep.Diagnostic{}
+	report := func(lineIndex int, line string, code string, message string) {
+		diagnostics = append(diagnostics, bicep.Diagnostic{
+			File:    path,
+			Line:    lineIndex + 1,
+			Column:  len(line) - len(strings.TrimLeft(line, " \t")) + 1,
+			Level:   "Warning",
+			Code:    code,
+			Message: message,
+		})
+	}
+
+	// The decorators of the declaration of the current line, ex) @secure()
+	decorators := []string{}
+	for i, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if match := lintLocationRegex.FindStringSubmatch(line); match != nil && match[1] != "" &&
+			!strings.EqualFold(match[1], "global") {
+			report(i, line, noHardcodedLocationRule, fmt.Sprintf(
+				"The location '%s' is hardcoded, use a parameter of the location instead, ex) the location of the "+
+					"environment.", match[1]))
+		}
+
+		if match := lintParamRegex.FindStringSubmatch(line); match != nil && secretNameRegex.MatchString(match[1]) &&
+			!containsDecorator(decorators, "@secure(") {
+			report(i, line, secureSecretsInParamsRule, fmt.Sprintf(
+				"Parameter '%s' may hold a secret, add the @secure() decorator for its value not to be logged "+
+					"nor stored in the deployment history.", match[1]))
+		}
+
+		if match := lintResourceRegex.FindStringSubmatch(line); match != nil {
+			if released, err := time.Parse("2006-01-02", match[2]); err == nil && now.Sub(released) > maxApiVersionAge {
+				report(i, line, useRecentApiVersionsRule, fmt.Sprintf(
+					"The api version %s of %s is older than %d days, use a more recent api version.",
+					match[2], match[1], int(maxApiVersionAge.Hours()/24)))
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "@"):
+			decorators = append(decorators, trimmed)
+		case trimmed != "" && !strings.HasPrefix(trimmed, "//"):
+			decorators = decorators[:0]
+		}
+	}
+
+	return diagnostics
+}
+
+func containsDecorator(decorators []string, prefix string) bool {
+	for _, decorator := range decorators {
+		if strings.HasPrefix(decorator, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
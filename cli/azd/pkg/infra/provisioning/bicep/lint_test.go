@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+var lintNow = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAnalyzeBicepFile(t *testing.T) {
+	contents := heredoc.Doc(`
+		param location string = resourceGroup().location
+		param keyVaultName string
+
+		@secure()
+		param adminPassword string
+
+		@description('The key of the API')
+		param apiKey string
+
+		resource plan 'Microsoft.Web/serverfarms@2022-03-01' = {
+		  name: 'plan'
+		  location: 'eastus'
+		}
+
+		resource dns 'Microsoft.Network/dnsZones@2018-05-01' = {
+		  name: 'contoso.com'
+		  location: 'global'
+		}
+
+		resource site 'Microsoft.Web/sites@2023-12-01' = {
+		  name: 'site'
+		  location: location
+		}
+	`)
+
+	diagnostics := analyzeBicepFile("main.bicep", contents, lintNow)
+
+	codes := []string{}
+	lines := []int{}
+	for _, diagnostic := range diagnostics {
+		codes = append(codes, diagnostic.Code)
+		lines = append(lines, diagnostic.Line)
+	}
+
+	require.Equal(t, []string{
+		secureSecretsInParamsRule,
+		useRecentApiVersionsRule,
+		noHardcodedLocationRule,
+		useRecentApiVersionsRule,
+	}, codes)
+	require.Equal(t, []int{8, 10, 12, 15}, lines)
+	require.Contains(t, diagnostics[0].Message, "Parameter 'apiKey' may hold a secret")
+	require.Equal(t, 3, diagnostics[2].Column)
+}
+
+type mockBicepLintCli struct {
+	bicep.BicepCli
+	diagnostics []bicep.Diagnostic
+}
+
+func (m *mockBicepLintCli) Lint(_ context.Context, _ string) ([]bicep.Diagnostic, error) {
+	return m.diagnostics, nil
+}
+
+func TestBicepProviderLint(t *testing.T) {
+	projectPath := t.TempDir()
+	infraPath := filepath.Join(projectPath, "infra")
+	require.NoError(t, os.MkdirAll(filepath.Join(infraPath, "app"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(infraPath, "main.bicep"), []byte(heredoc.Doc(`
+		module api './app/api.bicep' = {
+		  name: 'api'
+		}
+
+		module registry 'br/public:avm/res/container-registry/registry:0.1.0' = {
+		  name: 'registry'
+		}
+	`)), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(infraPath, "app", "api.bicep"), []byte(heredoc.Doc(`
+		param location string
+
+		resource api 'Microsoft.App/containerApps@2022-03-01' = {
+		  name: 'api'
+		  location: 'westus'
+		}
+	`)), 0600))
+
+	mockClock := clock.NewMock()
+	mockClock.Set(lintNow)
+
+	provider := &BicepProvider{
+		projectPath: projectPath,
+		options: provisioning.Options{
+			Path:   "infra",
+			Module: "main",
+			Lint: &provisioning.LintOptions{
+				Rules: map[string]provisioning.LintSeverity{
+					useRecentApiVersionsRule: provisioning.LintSeverityError,
+					"no-unused-params":       provisioning.LintSeverityOff,
+				},
+			},
+		},
+		bicepCli: &mockBicepLintCli{
+			diagnostics: []bicep.Diagnostic{
+				{
+					File:    filepath.Join(infraPath, "app", "api.bicep"),
+					Line:    1,
+					Column:  7,
+					Level:   "Warning",
+					Code:    "no-unused-params",
+					Message: `Parameter "location" is declared but never used.`,
+				},
+				{
+					// Reported by the rules of azd too
+					File:    filepath.Join(infraPath, "app", "api.bicep"),
+					Line:    5,
+					Column:  3,
+					Level:   "Warning",
+					Code:    noHardcodedLocationRule,
+					Message: "A resource location should not use a hard-coded string or variable value.",
+				},
+			},
+		},
+		clock: mockClock,
+	}
+
+	result, err := provider.Lint(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.Files, 1)
+	require.Equal(t, "infra/app/api.bicep", result.Files[0].Path)
+	require.Equal(t, []*provisioning.LintDiagnostic{
+		{
+			Line:     3,
+			Column:   1,
+			Rule:     useRecentApiVersionsRule,
+			Severity: provisioning.LintSeverityError,
+			Message: "The api version 2022-03-01 of Microsoft.App/containerApps is older than 730 days, " +
+				"use a more recent api version.",
+		},
+		{
+			Line:     5,
+			Column:   3,
+			Rule:     noHardcodedLocationRule,
+			Severity: provisioning.LintSeverityWarning,
+			Message:  "A resource location should not use a hard-coded string or variable value.",
+		},
+	}, result.Files[0].Diagnostics)
+	require.Equal(t, 1, result.Count(provisioning.LintSeverityError))
+
+	t.Run("Skip", func(t *testing.T) {
+		provider.options.Lint.Skip = true
+		defer func() { provider.options.Lint.Skip = false }()
+
+		result, err := provider.Lint(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, result)
+	})
+
+	t.Run("InvalidSeverity", func(t *testing.T) {
+		provider.options.Lint.Rules["no-unused-params"] = "fatal"
+
+		_, err := provider.Lint(context.Background())
+		require.ErrorContains(t, err, "invalid severity 'fatal' of lint rule 'no-unused-params'")
+	})
+}
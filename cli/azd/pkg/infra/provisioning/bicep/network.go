@@ -0,0 +1,36 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// applyNetworkParameters provides the network mode to templates declaring the `privateNetworking` and
+// `publicNetworkAccess` parameters, unless the values are explicitly set in the parameters file. It returns whether the
+// template declares any of the parameters.
+func applyNetworkParameters(
+	template azure.ArmTemplate,
+	parameters azure.ArmParameters,
+	mode NetworkMode,
+) (azure.ArmParameters, bool) {
+	if parameters == nil {
+		parameters = azure.ArmParameters{}
+	}
+
+	declared := false
+	for name, value := range NetworkParameters(mode) {
+		if _, has := template.Parameters[name]; !has {
+			continue
+		}
+
+		declared = true
+		if _, has := parameters[name]; !has {
+			parameters[name] = azure.ArmParameterValue{Value: value}
+		}
+	}
+
+	return parameters, declared
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyNetworkParameters(t *testing.T) {
+	template := azure.ArmTemplate{
+		Parameters: azure.ArmTemplateParameterDefinitions{
+			PrivateNetworkingParameterName:   {Type: "bool"},
+			PublicNetworkAccessParameterName: {Type: "string"},
+		},
+	}
+
+	t.Run("Private", func(t *testing.T) {
+		parameters, declared := applyNetworkParameters(template, nil, NetworkModePrivate)
+		require.True(t, declared)
+		require.Equal(t, azure.ArmParameters{
+			PrivateNetworkingParameterName:   {Value: true},
+			PublicNetworkAccessParameterName: {Value: "Disabled"},
+		}, parameters)
+	})
+
+	t.Run("NotSet", func(t *testing.T) {
+		parameters, declared := applyNetworkParameters(template, nil, "")
+		require.False(t, declared)
+		require.Empty(t, parameters)
+	})
+
+	t.Run("ParametersFileWins", func(t *testing.T) {
+		parameters, _ := applyNetworkParameters(template, azure.ArmParameters{
+			PublicNetworkAccessParameterName: {Value: "Enabled"},
+		}, NetworkModePrivate)
+		require.Equal(t, "Enabled", parameters[PublicNetworkAccessParameterName].Value)
+		require.Equal(t, true, parameters[PrivateNetworkingParameterName].Value)
+	})
+
+	t.Run("NotDeclared", func(t *testing.T) {
+		parameters, declared := applyNetworkParameters(azure.ArmTemplate{}, nil, NetworkModePrivate)
+		require.False(t, declared)
+		require.Empty(t, parameters)
+	})
+}
+
+func TestParseNetworkMode(t *testing.T) {
+	mode, err := ParseNetworkMode("private")
+	require.NoError(t, err)
+	require.Equal(t, NetworkModePrivate, mode)
+
+	_, err = ParseNetworkMode("isolated")
+	require.ErrorContains(t, err, "unsupported network mode 'isolated'")
+}
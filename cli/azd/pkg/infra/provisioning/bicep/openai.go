@@ -0,0 +1,119 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+)
+
+const (
+	// OpenAIDeploymentsParameterName is the name of the template parameter azd populates with the OpenAI model
+	// deployments declared in azure.yaml
+	OpenAIDeploymentsParameterName = "openAiDeployments"
+	// OpenAIDisableLocalAuthParameterName is the name of the template parameter azd populates with whether the keys of
+	// the OpenAI account are disabled, when services authenticate with their identity
+	OpenAIDisableLocalAuthParameterName = "openAiDisableLocalAuth"
+	// OpenAIKeyEnvVarName is the environment variable storing the key of the OpenAI account when services authenticate
+	// with keys
+	OpenAIKeyEnvVarName = "AZURE_OPENAI_KEY"
+
+	cognitiveAccountResourceType = "Microsoft.CognitiveServices/accounts"
+	openAIAccountKind            = "OpenAI"
+)
+
+// applyOpenAIParameters provides the OpenAI model deployments and authentication to templates declaring the
+// `openAiDeployments` and `openAiDisableLocalAuth` parameters, unless the values are explicitly set in the parameters
+// file.
+func applyOpenAIParameters(
+	template azure.ArmTemplate,
+	parameters azure.ArmParameters,
+	options *OpenAIOptions,
+) azure.ArmParameters {
+	if options == nil {
+		return parameters
+	}
+
+	if parameters == nil {
+		parameters = azure.ArmParameters{}
+	}
+
+	if _, has := template.Parameters[OpenAIDeploymentsParameterName]; has && len(options.Deployments) > 0 {
+		if _, has := parameters[OpenAIDeploymentsParameterName]; !has {
+			deployments := make([]any, 0, len(options.Deployments))
+			for _, deployment := range options.Deployments {
+				deployments = append(deployments, map[string]any{
+					"name":     deployment.Name,
+					"model":    deployment.Model,
+					"version":  deployment.Version,
+					"capacity": deployment.CapacityOrDefault(),
+					"sku":      deployment.SkuName(),
+				})
+			}
+
+			parameters[OpenAIDeploymentsParameterName] = azure.ArmParameterValue{Value: deployments}
+		}
+	}
+
+	if _, has := template.Parameters[OpenAIDisableLocalAuthParameterName]; has {
+		if _, has := parameters[OpenAIDisableLocalAuthParameterName]; !has {
+			parameters[OpenAIDisableLocalAuthParameterName] = azure.ArmParameterValue{
+				Value: options.AuthKind() == OpenAIAuthRbac,
+			}
+		}
+	}
+
+	return parameters
+}
+
+// addOpenAIKeyOutput adds the key of the OpenAI account provisioned by the deployment to the outputs, so it is stored in
+// the environment for services authenticating with keys.
+func (p *BicepProvider) addOpenAIKeyOutput(
+	ctx context.Context,
+	deployment *armresources.DeploymentExtended,
+	outputs map[string]OutputParameter,
+) error {
+	subscriptionId := p.env.GetSubscriptionId()
+
+	for _, resource := range deployment.Properties.OutputResources {
+		if resource == nil || resource.ID == nil {
+			continue
+		}
+
+		resourceId, err := arm.ParseResourceID(*resource.ID)
+		if err != nil || !strings.EqualFold(resourceId.ResourceType.String(), cognitiveAccountResourceType) {
+			continue
+		}
+
+		account, err := p.azCli.GetCognitiveAccount(ctx, subscriptionId, resourceId.ResourceGroupName, resourceId.Name)
+		if err != nil {
+			return fmt.Errorf("getting cognitive account '%s': %w", resourceId.Name, err)
+		}
+
+		if !strings.EqualFold(convert.ToValueWithDefault(account.Kind, ""), openAIAccountKind) {
+			continue
+		}
+
+		key, err := p.azCli.GetCognitiveAccountKey(ctx, subscriptionId, resourceId.ResourceGroupName, resourceId.Name)
+		if err != nil {
+			return fmt.Errorf("getting key of OpenAI account '%s': %w", resourceId.Name, err)
+		}
+
+		redact.AddSecret(key)
+		outputs[OpenAIKeyEnvVarName] = OutputParameter{Type: ParameterTypeString, Value: key}
+		return nil
+	}
+
+	log.Printf("no OpenAI account found in the outputs of deployment, %s not set", OpenAIKeyEnvVarName)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOpenAIParameters(t *testing.T) {
+	template := azure.ArmTemplate{
+		Parameters: azure.ArmTemplateParameterDefinitions{
+			OpenAIDeploymentsParameterName:      {Type: "array"},
+			OpenAIDisableLocalAuthParameterName: {Type: "bool"},
+		},
+	}
+
+	options := &OpenAIOptions{
+		Deployments: []OpenAIDeployment{
+			{Name: "chat", Model: "gpt-35-turbo", Version: "0613", Capacity: 30},
+		},
+	}
+
+	t.Run("Rbac", func(t *testing.T) {
+		parameters := applyOpenAIParameters(template, nil, options)
+		require.Equal(t, []any{
+			map[string]any{
+				"name":     "chat",
+				"model":    "gpt-35-turbo",
+				"version":  "0613",
+				"capacity": 30,
+				"sku":      DefaultOpenAIDeploymentSku,
+			},
+		}, parameters[OpenAIDeploymentsParameterName].Value)
+		require.Equal(t, true, parameters[OpenAIDisableLocalAuthParameterName].Value)
+	})
+
+	t.Run("Key", func(t *testing.T) {
+		keyOptions := *options
+		keyOptions.Auth = OpenAIAuthKey
+
+		parameters := applyOpenAIParameters(template, azure.ArmParameters{}, &keyOptions)
+		require.Equal(t, false, parameters[OpenAIDisableLocalAuthParameterName].Value)
+	})
+
+	t.Run("ParametersFileTakesPrecedence", func(t *testing.T) {
+		parameters := applyOpenAIParameters(template, azure.ArmParameters{
+			OpenAIDeploymentsParameterName: {Value: []any{}},
+		}, options)
+		require.Equal(t, []any{}, parameters[OpenAIDeploymentsParameterName].Value)
+	})
+
+	t.Run("NotDeclared", func(t *testing.T) {
+		parameters := applyOpenAIParameters(azure.ArmTemplate{}, azure.ArmParameters{}, options)
+		require.Empty(t, parameters)
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// SimulatePolicies runs an ARM what-if of the plan, and evaluates the Azure Policy assignments of the scope of each
+// resource provisioning would deploy against the content what-if predicts for it. Only the policies which deny
+// resources are evaluated.
+func (p *BicepProvider) SimulatePolicies(ctx context.Context, plan *DeploymentPlan) (*PolicySimulationResult, error) {
+	details := plan.Details.(BicepDeploymentDetails)
+
+	whatIfResult, err := details.Target.WhatIf(ctx, details.Template, details.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if whatIfResult.Error != nil && whatIfResult.Error.Message != nil {
+		return nil, fmt.Errorf("what-if deployment failed: %s", *whatIfResult.Error.Message)
+	}
+
+	return p.simulatePolicies(ctx, whatIfResult)
+}
+
+// simulatePolicies evaluates the policy assignments against the resources what-if predicts provisioning would create,
+// modify or deploy unchanged, which a deny policy assigned since would still deny.
+func (p *BicepProvider) simulatePolicies(
+	ctx context.Context,
+	whatIfResult *armresources.WhatIfOperationResult,
+) (*PolicySimulationResult, error) {
+	result := &PolicySimulationResult{
+		Denials: []*PolicyDenial{},
+	}
+
+	if whatIfResult.Properties == nil {
+		return result, nil
+	}
+
+	for _, change := range whatIfResult.Properties.Changes {
+		if change == nil || change.ChangeType == nil || change.ResourceID == nil {
+			continue
+		}
+
+		switch *change.ChangeType {
+		case armresources.ChangeTypeCreate,
+			armresources.ChangeTypeModify,
+			armresources.ChangeTypeDeploy,
+			armresources.ChangeTypeNoChange:
+		default:
+			continue
+		}
+
+		after, ok := change.After.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		resourceId, err := arm.ParseResourceID(*change.ResourceID)
+		if err != nil {
+			log.Printf("skipping policy simulation of resource '%s': %v", *change.ResourceID, err)
+			continue
+		}
+
+		// the content of the resource is the body of the request deploying it
+		content := map[string]any{}
+		for key, value := range after {
+			if key != "id" && key != "apiVersion" {
+				content[key] = value
+			}
+		}
+		apiVersion, _ := after["apiVersion"].(string)
+
+		evaluations, err := p.azCli.CheckPolicyRestrictions(
+			ctx, resourceId.SubscriptionID, resourceId.ResourceGroupName, content, apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("simulating policies of resource '%s': %w", *change.ResourceID, err)
+		}
+		result.Evaluated++
+
+		for _, evaluation := range evaluations {
+			if evaluation.EvaluationResult != "NonCompliant" {
+				continue
+			}
+
+			denial := &PolicyDenial{
+				ResourceId:       *change.ResourceID,
+				PolicyAssignment: evaluation.PolicyAssignmentDisplayName,
+				PolicyDefinition: evaluation.PolicyDefinitionDisplayName,
+				Reason:           evaluation.Reason,
+			}
+			if denial.PolicyAssignment == "" {
+				denial.PolicyAssignment = evaluation.PolicyAssignmentId
+			}
+			if denial.PolicyDefinition == "" {
+				denial.PolicyDefinition = evaluation.PolicyDefinitionId
+			}
+
+			result.Denials = append(result.Denials, denial)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatePolicies(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	scopes := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.PolicyInsights/checkPolicyRestrictions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		scope := strings.TrimSuffix(request.URL.Path, "/providers/Microsoft.PolicyInsights/checkPolicyRestrictions")
+		scopes = append(scopes, scope)
+
+		// the resource group of the environment doesn't exist yet
+		if scope == "/subscriptions/SUB/resourceGroups/rg-new" {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusNotFound, map[string]any{
+				"error": map[string]any{"code": "ResourceGroupNotFound"},
+			})
+		}
+
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var body struct {
+			ResourceDetails struct {
+				ResourceContent map[string]any `json:"resourceContent"`
+				ApiVersion      string         `json:"apiVersion"`
+			} `json:"resourceDetails"`
+		}
+		require.NoError(t, json.Unmarshal(contents, &body))
+		require.NotContains(t, body.ResourceDetails.ResourceContent, "id")
+
+		evaluations := []map[string]any{}
+		if body.ResourceDetails.ResourceContent["type"] == "Microsoft.Network/publicIPAddresses" {
+			require.Equal(t, "2023-04-01", body.ResourceDetails.ApiVersion)
+			evaluations = append(evaluations, map[string]any{
+				"policyInfo": map[string]any{
+					"policyAssignmentId":          "/providers/Microsoft.Authorization/policyAssignments/no-pip",
+					"policyAssignmentDisplayName": "Deny public IPs",
+					"policyDefinitionId":          "/providers/Microsoft.Authorization/policyDefinitions/no-pip",
+					"policyDefinitionEffect":      "deny",
+				},
+				"evaluationResult": "NonCompliant",
+				"evaluationDetails": map[string]any{
+					"evaluatedExpressions": []map[string]any{
+						{
+							"expression":      "type",
+							"expressionValue": "Microsoft.Network/publicIPAddresses",
+							"targetValue":     "Microsoft.Network/publicIPAddresses",
+							"operator":        "Equals",
+							"result":          "True",
+						},
+					},
+				},
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"fieldRestrictions": []any{},
+			"contentEvaluationResult": map[string]any{
+				"policyEvaluations": evaluations,
+			},
+		})
+	})
+
+	provider := &BicepProvider{azCli: mockazcli.NewAzCliFromMockContext(mockContext)}
+	whatIfResult := &armresources.WhatIfOperationResult{
+		Properties: &armresources.WhatIfOperationProperties{
+			Changes: []*armresources.WhatIfChange{
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					ChangeType: to.Ptr(armresources.ChangeTypeCreate),
+					After: map[string]any{
+						"id":         "/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+						"apiVersion": "2023-04-01",
+						"type":       "Microsoft.Network/publicIPAddresses",
+						"name":       "pip",
+						"location":   "eastus2",
+					},
+				},
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg-new/providers/Microsoft.Web/sites/web"),
+					ChangeType: to.Ptr(armresources.ChangeTypeModify),
+					After: map[string]any{
+						"apiVersion": "2022-03-01",
+						"type":       "Microsoft.Web/sites",
+						"name":       "web",
+						"location":   "eastus2",
+					},
+				},
+				{
+					ResourceID: to.Ptr("/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st"),
+					ChangeType: to.Ptr(armresources.ChangeTypeDelete),
+				},
+			},
+		},
+	}
+
+	result, err := provider.simulatePolicies(*mockContext.Context, whatIfResult)
+	require.NoError(t, err)
+
+	require.Equal(t, &provisioning.PolicySimulationResult{
+		Evaluated: 2,
+		Denials: []*provisioning.PolicyDenial{
+			{
+				ResourceId:       "/subscriptions/SUB/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+				PolicyAssignment: "Deny public IPs",
+				PolicyDefinition: "/providers/Microsoft.Authorization/policyDefinitions/no-pip",
+				Reason:           "type is Microsoft.Network/publicIPAddresses, Equals Microsoft.Network/publicIPAddresses",
+			},
+		},
+	}, result)
+
+	require.Equal(t, []string{
+		"/subscriptions/SUB/resourceGroups/rg",
+		"/subscriptions/SUB/resourceGroups/rg-new",
+		"/subscriptions/SUB",
+	}, scopes)
+}
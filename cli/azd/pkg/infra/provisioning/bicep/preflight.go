@@ -0,0 +1,619 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	managedClusterResourceType       = "Microsoft.ContainerService/managedClusters"
+	agentPoolResourceType            = "Microsoft.ContainerService/managedClusters/agentPools"
+	containerAppsEnvResourceType     = "Microsoft.App/managedEnvironments"
+	postgresFlexibleServerType       = "Microsoft.DBforPostgreSQL/flexibleServers"
+	nestedDeploymentResourceType     = "Microsoft.Resources/deployments"
+	totalRegionalVCpusUsageName      = "cores"
+	maxPreflightAlternateLocations   = 5
+	maxPreflightNestedTemplateLevels = 10
+)
+
+var parameterReferenceRegex = regexp.MustCompile(`^\[parameters\('([^']+)'\)\]$`)
+
+// preflightResource is a resource of the template checked for quotas and regional availability before provisioning
+type preflightResource struct {
+	Type     string
+	Name     string
+	Location string
+	// The number of virtual machines of each size required by the resource, ex) the AKS node pools
+	VmSizes map[string]int
+	// The SKU of the resource, ex) the PostgreSQL flexible server SKU
+	Sku string
+}
+
+// templateScope holds the parameter values of a template, or of a nested template, that can be resolved without
+// evaluating ARM template functions
+type templateScope struct {
+	parameters map[string]any
+}
+
+// resolve returns the value when it is a literal or a direct reference to a resolved parameter
+func (s templateScope) resolve(value any) (any, bool) {
+	text, isString := value.(string)
+	if !isString {
+		return value, value != nil
+	}
+
+	if !strings.HasPrefix(text, "[") || strings.HasPrefix(text, "[[") {
+		return text, true
+	}
+
+	match := parameterReferenceRegex.FindStringSubmatch(text)
+	if match == nil {
+		return nil, false
+	}
+
+	resolved, has := s.parameters[match[1]]
+	return resolved, has
+}
+
+func (s templateScope) resolveString(value any) string {
+	resolved, ok := s.resolve(value)
+	if !ok {
+		return ""
+	}
+
+	text, _ := resolved.(string)
+	return text
+}
+
+func (s templateScope) resolveInt(value any, defaultValue int) int {
+	resolved, ok := s.resolve(value)
+	if !ok {
+		return defaultValue
+	}
+
+	if number, isNumber := resolved.(float64); isNumber {
+		return int(number)
+	}
+
+	return defaultValue
+}
+
+// preflightResources finds the resources of the template, including the resources of nested deployments, which are
+// checked before provisioning. Locations that can't be resolved default to the specified location.
+func preflightResources(
+	rawTemplate azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+	defaultLocation string,
+) ([]preflightResource, error) {
+	var template map[string]any
+	if err := json.Unmarshal(rawTemplate, &template); err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	passedValues := map[string]any{}
+	for name, parameter := range parameters {
+		passedValues[name] = parameter.Value
+	}
+
+	resources := []preflightResource{}
+	collectPreflightResources(template, passedValues, defaultLocation, 0, &resources)
+
+	return resources, nil
+}
+
+func collectPreflightResources(
+	template map[string]any,
+	passedValues map[string]any,
+	defaultLocation string,
+	level int,
+	resources *[]preflightResource,
+) {
+	scope := templateScope{parameters: map[string]any{}}
+	definitions, _ := template["parameters"].(map[string]any)
+	for name, definition := range definitions {
+		if value, has := passedValues[name]; has {
+			scope.parameters[name] = value
+			continue
+		}
+
+		definitionMap, _ := definition.(map[string]any)
+		if value, ok := scope.resolve(definitionMap["defaultValue"]); ok {
+			scope.parameters[name] = value
+		}
+	}
+
+	// Templates using symbolic names declare the resources as an object instead of an array
+	var templateResources []any
+	switch value := template["resources"].(type) {
+	case []any:
+		templateResources = value
+	case map[string]any:
+		symbolicNames := maps.Keys(value)
+		sort.Strings(symbolicNames)
+		for _, symbolicName := range symbolicNames {
+			templateResources = append(templateResources, value[symbolicName])
+		}
+	}
+
+	for _, item := range templateResources {
+		resource, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		resourceType, _ := resource["type"].(string)
+		properties, _ := resource["properties"].(map[string]any)
+		location := scope.resolveString(resource["location"])
+		if location == "" {
+			location = defaultLocation
+		}
+
+		preflight := preflightResource{
+			Type:     resourceType,
+			Name:     scope.resolveString(resource["name"]),
+			Location: location,
+		}
+
+		switch {
+		case strings.EqualFold(resourceType, nestedDeploymentResourceType):
+			nestedTemplate, _ := properties["template"].(map[string]any)
+			if nestedTemplate == nil || level >= maxPreflightNestedTemplateLevels {
+				continue
+			}
+
+			nestedValues := map[string]any{}
+			nestedParameters, _ := properties["parameters"].(map[string]any)
+			for name, parameter := range nestedParameters {
+				parameterMap, _ := parameter.(map[string]any)
+				if value, ok := scope.resolve(parameterMap["value"]); ok {
+					nestedValues[name] = value
+				}
+			}
+
+			// Resources of nested deployments default to the location of the deployment
+			nestedLocation := defaultLocation
+			if location := scope.resolveString(resource["location"]); location != "" {
+				nestedLocation = location
+			}
+
+			collectPreflightResources(nestedTemplate, nestedValues, nestedLocation, level+1, resources)
+			continue
+		case strings.EqualFold(resourceType, managedClusterResourceType):
+			preflight.VmSizes = map[string]int{}
+			profiles, _ := properties["agentPoolProfiles"].([]any)
+			for _, profile := range profiles {
+				profileMap, _ := profile.(map[string]any)
+				addVmSize(scope, preflight.VmSizes, profileMap)
+			}
+		case strings.EqualFold(resourceType, agentPoolResourceType):
+			preflight.Type = managedClusterResourceType
+			preflight.VmSizes = map[string]int{}
+			addVmSize(scope, preflight.VmSizes, properties)
+		case strings.EqualFold(resourceType, containerAppsEnvResourceType):
+			// Only checked for regional availability
+		case strings.EqualFold(resourceType, postgresFlexibleServerType):
+			sku, _ := resource["sku"].(map[string]any)
+			preflight.Sku = scope.resolveString(sku["name"])
+		case strings.EqualFold(resourceType, cognitiveAccountResourceType):
+			// Only OpenAI accounts are checked, for the model deployments declared in azure.yaml
+			if !strings.EqualFold(scope.resolveString(resource["kind"]), openAIAccountKind) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		*resources = append(*resources, preflight)
+	}
+}
+
+// addVmSize adds the virtual machines of an AKS agent pool profile when its size can be resolved
+func addVmSize(scope templateScope, vmSizes map[string]int, profile map[string]any) {
+	vmSize := scope.resolveString(profile["vmSize"])
+	if vmSize == "" {
+		return
+	}
+
+	vmSizes[vmSize] += scope.resolveInt(profile["count"], 1)
+}
+
+// runPreflightChecks checks the subscription has enough quota and the locations offer the resource types and SKUs of
+// the template before starting a deployment which could otherwise fail half-way. Issues are reported as warnings unless
+// the preflight options are enforced. Failures to run a check are logged and don't prevent provisioning.
+func (p *BicepProvider) runPreflightChecks(ctx context.Context, details BicepDeploymentDetails) error {
+	if p.options.Preflight.IsSkipped() {
+		return nil
+	}
+
+	resources, err := preflightResources(details.Template, details.Parameters, p.env.GetLocation())
+	if err != nil {
+		log.Printf("skipping preflight checks: %v", err)
+		return nil
+	}
+
+	if len(resources) == 0 {
+		return nil
+	}
+
+	spinnerMessage := "Checking quotas and regional availability"
+	p.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+
+	checker := &preflightChecker{
+		azCli:          p.azCli,
+		subscriptionId: p.env.GetSubscriptionId(),
+	}
+	if p.options.OpenAI != nil {
+		checker.openAIDeployments = p.options.OpenAI.Deployments
+	}
+	issues := checker.check(ctx, resources)
+
+	if len(issues) == 0 {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepDone)
+		return nil
+	}
+
+	if p.options.Preflight.IsEnforced() {
+		p.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
+		return &PreflightError{Issues: issues}
+	}
+
+	p.console.StopSpinner(ctx, spinnerMessage, input.StepWarning)
+	for _, issue := range issues {
+		p.console.Message(ctx, output.WithWarningFormat("WARNING: %s", issue))
+	}
+
+	return nil
+}
+
+// preflightChecker runs the quota and regional availability checks, caching the results of the queries by location
+type preflightChecker struct {
+	azCli          azcli.AzCli
+	subscriptionId string
+	// The OpenAI model deployments declared in azure.yaml, checked for each OpenAI account of the template
+	openAIDeployments []OpenAIDeployment
+
+	typeLocations map[string][]string
+	computeSkus   map[string][]azcli.AzCliComputeSku
+}
+
+func (c *preflightChecker) check(ctx context.Context, resources []preflightResource) []PreflightIssue {
+	c.typeLocations = map[string][]string{}
+	c.computeSkus = map[string][]azcli.AzCliComputeSku{}
+
+	issues := []PreflightIssue{}
+	for _, resource := range resources {
+		if issue := c.checkResourceType(ctx, resource); issue != nil {
+			issues = append(issues, *issue)
+			// The SKUs and quotas of a location that doesn't offer the resource type are irrelevant
+			continue
+		}
+
+		if resource.Sku != "" && strings.EqualFold(resource.Type, postgresFlexibleServerType) {
+			if issue := c.checkPostgresSku(ctx, resource); issue != nil {
+				issues = append(issues, *issue)
+			}
+		}
+
+		issues = append(issues, c.checkVmSizes(ctx, resource)...)
+
+		if strings.EqualFold(resource.Type, cognitiveAccountResourceType) {
+			issues = append(issues, c.checkOpenAIDeployments(ctx, resource)...)
+		}
+	}
+
+	return append(issues, c.checkComputeQuotas(ctx, resources)...)
+}
+
+func (c *preflightChecker) checkResourceType(ctx context.Context, resource preflightResource) *PreflightIssue {
+	locations, has := c.typeLocations[resource.Type]
+	if !has {
+		var err error
+		locations, err = c.azCli.GetResourceTypeLocations(ctx, c.subscriptionId, resource.Type)
+		if err != nil {
+			log.Printf("preflight: failed getting locations of '%s': %v", resource.Type, err)
+		}
+
+		for i, location := range locations {
+			locations[i] = NormalizeLocation(location)
+		}
+		sort.Strings(locations)
+		c.typeLocations[resource.Type] = locations
+	}
+
+	if len(locations) == 0 || slices.Contains(locations, NormalizeLocation(resource.Location)) {
+		return nil
+	}
+
+	return &PreflightIssue{
+		ResourceType:       resource.Type,
+		ResourceName:       resource.Name,
+		Location:           resource.Location,
+		Message:            "The resource type is not available in the location.",
+		AlternateLocations: firstLocations(locations),
+	}
+}
+
+func (c *preflightChecker) checkPostgresSku(ctx context.Context, resource preflightResource) *PreflightIssue {
+	skus, err := c.azCli.ListPostgresFlexibleServerSkus(ctx, c.subscriptionId, NormalizeLocation(resource.Location))
+	if err != nil {
+		log.Printf("preflight: failed getting postgres skus in '%s': %v", resource.Location, err)
+		return nil
+	}
+
+	if len(skus) == 0 || slices.ContainsFunc(skus, func(sku string) bool {
+		return strings.EqualFold(sku, resource.Sku)
+	}) {
+		return nil
+	}
+
+	return &PreflightIssue{
+		ResourceType: resource.Type,
+		ResourceName: resource.Name,
+		Location:     resource.Location,
+		Message:      fmt.Sprintf("The SKU '%s' is not available in the location.", resource.Sku),
+	}
+}
+
+func (c *preflightChecker) checkVmSizes(ctx context.Context, resource preflightResource) []PreflightIssue {
+	issues := []PreflightIssue{}
+
+	vmSizes := make([]string, 0, len(resource.VmSizes))
+	for vmSize := range resource.VmSizes {
+		vmSizes = append(vmSizes, vmSize)
+	}
+	sort.Strings(vmSizes)
+
+	for _, vmSize := range vmSizes {
+		location := NormalizeLocation(resource.Location)
+		skus, ok := c.listComputeSkus(ctx, location)
+		if !ok {
+			return issues
+		}
+
+		if sku := findVmSku(skus, vmSize, location); sku != nil && !sku.Restricted {
+			continue
+		}
+
+		issue := PreflightIssue{
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Location:     resource.Location,
+			Message:      fmt.Sprintf("The VM size '%s' is not available to the subscription in the location.", vmSize),
+		}
+
+		if allSkus, ok := c.listComputeSkus(ctx, ""); ok {
+			locations := []string{}
+			for _, sku := range allSkus {
+				if isVmSku(sku, vmSize) && !sku.Restricted {
+					locations = append(locations, NormalizeLocation(sku.Location))
+				}
+			}
+			sort.Strings(locations)
+			issue.AlternateLocations = firstLocations(locations)
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// checkComputeQuotas checks the regional and VM family vCPU quotas of each location can fit the virtual machines of all
+// resources deployed in the location
+func (c *preflightChecker) checkComputeQuotas(ctx context.Context, resources []preflightResource) []PreflightIssue {
+	issues := []PreflightIssue{}
+
+	// location -> usage name -> required vCPUs
+	required := map[string]map[string]int64{}
+	locationNames := map[string]string{}
+	for _, resource := range resources {
+		location := NormalizeLocation(resource.Location)
+		for vmSize, count := range resource.VmSizes {
+			skus, ok := c.listComputeSkus(ctx, location)
+			if !ok {
+				continue
+			}
+
+			sku := findVmSku(skus, vmSize, location)
+			if sku == nil || sku.Restricted || sku.VCpus == 0 {
+				continue
+			}
+
+			if required[location] == nil {
+				required[location] = map[string]int64{}
+				locationNames[location] = resource.Location
+			}
+
+			vCpus := int64(sku.VCpus * count)
+			required[location][sku.Family] += vCpus
+			required[location][totalRegionalVCpusUsageName] += vCpus
+		}
+	}
+
+	locations := make([]string, 0, len(required))
+	for location := range required {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	for _, location := range locations {
+		usages, err := c.azCli.ListComputeUsages(ctx, c.subscriptionId, location)
+		if err != nil {
+			log.Printf("preflight: failed getting compute usages in '%s': %v", location, err)
+			continue
+		}
+
+		for _, usage := range usages {
+			requiredVCpus, has := required[location][usage.Name]
+			if !has || requiredVCpus <= usage.Available() {
+				continue
+			}
+
+			available := usage.Available()
+			if available < 0 {
+				available = 0
+			}
+
+			quotaName := fmt.Sprintf("'%s' vCPUs", usage.Name)
+			if usage.Name == totalRegionalVCpusUsageName {
+				quotaName = "regional vCPUs"
+			}
+
+			issues = append(issues, PreflightIssue{
+				ResourceType: managedClusterResourceType,
+				ResourceName: "node pools",
+				Location:     locationNames[location],
+				Message: fmt.Sprintf(
+					"Requires %d %s but only %d of the %d quota are available. "+
+						"Request a quota increase, reduce the node count or VM size, or choose another location.",
+					requiredVCpus, quotaName, available, usage.Limit,
+				),
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkOpenAIDeployments checks the location of the OpenAI account offers the models, versions and SKUs of the model
+// deployments and the subscription has enough capacity left for them
+func (c *preflightChecker) checkOpenAIDeployments(ctx context.Context, resource preflightResource) []PreflightIssue {
+	issues := []PreflightIssue{}
+	if len(c.openAIDeployments) == 0 {
+		return issues
+	}
+
+	location := NormalizeLocation(resource.Location)
+	models, err := c.azCli.ListCognitiveModels(ctx, c.subscriptionId, location)
+	if err != nil {
+		log.Printf("preflight: failed listing cognitive models in '%s': %v", location, err)
+	} else {
+		for _, deployment := range c.openAIDeployments {
+			if hasOpenAIModel(models, deployment) {
+				continue
+			}
+
+			model := deployment.Model
+			if deployment.Version != "" {
+				model = fmt.Sprintf("%s (%s)", deployment.Model, deployment.Version)
+			}
+
+			issues = append(issues, PreflightIssue{
+				ResourceType: resource.Type,
+				ResourceName: fmt.Sprintf("%s/%s", resource.Name, deployment.Name),
+				Location:     resource.Location,
+				Message: fmt.Sprintf(
+					"The model %s is not available with the SKU '%s' in the location.", model, deployment.SkuName()),
+			})
+		}
+	}
+
+	// usage name -> required capacity
+	required := map[string]int64{}
+	usageNames := []string{}
+	for _, deployment := range c.openAIDeployments {
+		if _, has := required[deployment.UsageName()]; !has {
+			usageNames = append(usageNames, deployment.UsageName())
+		}
+		required[deployment.UsageName()] += int64(deployment.CapacityOrDefault())
+	}
+
+	usages, err := c.azCli.ListCognitiveUsages(ctx, c.subscriptionId, location)
+	if err != nil {
+		log.Printf("preflight: failed getting cognitive usages in '%s': %v", location, err)
+		return issues
+	}
+
+	for _, usageName := range usageNames {
+		for _, usage := range usages {
+			if !strings.EqualFold(usage.Name, usageName) || required[usageName] <= usage.Available() {
+				continue
+			}
+
+			available := usage.Available()
+			if available < 0 {
+				available = 0
+			}
+
+			issues = append(issues, PreflightIssue{
+				ResourceType: resource.Type,
+				ResourceName: resource.Name,
+				Location:     resource.Location,
+				Message: fmt.Sprintf(
+					"Requires a capacity of %d of the '%s' quota but only %d of %d are available. "+
+						"Request a quota increase, reduce the capacity of the deployments, or choose another location.",
+					required[usageName], usageName, available, usage.Limit,
+				),
+			})
+		}
+	}
+
+	return issues
+}
+
+func hasOpenAIModel(models []azcli.AzCliCognitiveModel, deployment OpenAIDeployment) bool {
+	return slices.ContainsFunc(models, func(model azcli.AzCliCognitiveModel) bool {
+		return strings.EqualFold(model.Kind, openAIAccountKind) &&
+			strings.EqualFold(model.Name, deployment.Model) &&
+			(deployment.Version == "" || model.Version == deployment.Version) &&
+			slices.ContainsFunc(model.Skus, func(sku string) bool {
+				return strings.EqualFold(sku, deployment.SkuName())
+			})
+	})
+}
+
+// listComputeSkus lists the compute SKUs of the location, or all locations when empty. Returns false when the SKUs
+// can't be listed.
+func (c *preflightChecker) listComputeSkus(ctx context.Context, location string) ([]azcli.AzCliComputeSku, bool) {
+	if skus, has := c.computeSkus[location]; has {
+		return skus, skus != nil
+	}
+
+	skus, err := c.azCli.ListComputeSkus(ctx, c.subscriptionId, location)
+	if err != nil {
+		log.Printf("preflight: failed listing compute skus in '%s': %v", location, err)
+		skus = nil
+	}
+
+	c.computeSkus[location] = skus
+	return skus, skus != nil
+}
+
+func isVmSku(sku azcli.AzCliComputeSku, vmSize string) bool {
+	return sku.ResourceType == "virtualMachines" && strings.EqualFold(sku.Name, vmSize)
+}
+
+func findVmSku(skus []azcli.AzCliComputeSku, vmSize string, location string) *azcli.AzCliComputeSku {
+	for i, sku := range skus {
+		if isVmSku(sku, vmSize) && NormalizeLocation(sku.Location) == location {
+			return &skus[i]
+		}
+	}
+
+	return nil
+}
+
+func firstLocations(locations []string) []string {
+	locations = slices.Compact(locations)
+	if len(locations) > maxPreflightAlternateLocations {
+		return locations[:maxPreflightAlternateLocations]
+	}
+
+	return locations
+}
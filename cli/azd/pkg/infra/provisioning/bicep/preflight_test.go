@@ -0,0 +1,227 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const preflightTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	"parameters": {
+		"location": { "type": "string" }
+	},
+	"resources": [
+		{
+			"type": "Microsoft.Resources/deployments",
+			"name": "resources",
+			"properties": {
+				"parameters": {
+					"location": { "value": "[parameters('location')]" },
+					"nodeCount": { "value": 3 }
+				},
+				"template": {
+					"parameters": {
+						"location": { "type": "string" },
+						"nodeCount": { "type": "int" },
+						"systemVmSize": { "type": "string", "defaultValue": "Standard_D4s_v5" }
+					},
+					"resources": [
+						{
+							"type": "Microsoft.ContainerService/managedClusters",
+							"name": "aks-test",
+							"location": "[parameters('location')]",
+							"properties": {
+								"agentPoolProfiles": [
+									{ "vmSize": "[parameters('systemVmSize')]", "count": "[parameters('nodeCount')]" },
+									{ "vmSize": "[variables('userVmSize')]", "count": 2 }
+								]
+							}
+						},
+						{
+							"type": "Microsoft.DBforPostgreSQL/flexibleServers",
+							"name": "psql-test",
+							"location": "westus",
+							"sku": { "name": "Standard_B1ms" }
+						},
+						{
+							"type": "Microsoft.Storage/storageAccounts",
+							"name": "sttest",
+							"location": "[parameters('location')]"
+						}
+					]
+				}
+			}
+		}
+	]
+}`
+
+func TestPreflightResources(t *testing.T) {
+	resources, err := preflightResources(
+		azure.RawArmTemplate(preflightTemplate),
+		azure.ArmParameters{"location": {Value: "eastus2"}},
+		"centralus",
+	)
+	require.NoError(t, err)
+	require.Equal(t, []preflightResource{
+		{
+			Type:     managedClusterResourceType,
+			Name:     "aks-test",
+			Location: "eastus2",
+			VmSizes:  map[string]int{"Standard_D4s_v5": 3},
+		},
+		{
+			Type:     postgresFlexibleServerType,
+			Name:     "psql-test",
+			Location: "westus",
+			Sku:      "Standard_B1ms",
+		},
+	}, resources)
+}
+
+func TestPreflightChecker(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	mockResponse := func(pathSuffix string, body string) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, pathSuffix)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    request,
+			}, nil
+		})
+	}
+
+	mockResponse("/providers/Microsoft.ContainerService", `{
+		"namespace": "Microsoft.ContainerService",
+		"resourceTypes": [{ "resourceType": "managedClusters", "locations": ["East US 2", "West US"] }]
+	}`)
+	mockResponse("/providers/Microsoft.DBforPostgreSQL", `{
+		"namespace": "Microsoft.DBforPostgreSQL",
+		"resourceTypes": [{ "resourceType": "flexibleServers", "locations": ["East US 2", "North Europe"] }]
+	}`)
+	mockResponse("/providers/Microsoft.Compute/skus", `{
+		"value": [
+			{
+				"resourceType": "virtualMachines",
+				"name": "Standard_D4s_v5",
+				"family": "standardDSv5Family",
+				"locations": ["eastus2"],
+				"capabilities": [{ "name": "vCPUs", "value": "4" }],
+				"restrictions": []
+			}
+		]
+	}`)
+	mockResponse("/locations/eastus2/usages", `{
+		"value": [
+			{ "currentValue": 6, "limit": 10, "name": { "value": "standardDSv5Family" } },
+			{ "currentValue": 6, "limit": 100, "name": { "value": "cores" } }
+		]
+	}`)
+
+	checker := &preflightChecker{
+		azCli:          azCli,
+		subscriptionId: "SUBSCRIPTION_ID",
+	}
+
+	issues := checker.check(*mockContext.Context, []preflightResource{
+		{
+			Type:     managedClusterResourceType,
+			Name:     "aks-test",
+			Location: "eastus2",
+			VmSizes:  map[string]int{"Standard_D4s_v5": 3},
+		},
+		{
+			Type:     postgresFlexibleServerType,
+			Name:     "psql-test",
+			Location: "westus",
+			Sku:      "Standard_B1ms",
+		},
+	})
+
+	require.Len(t, issues, 2)
+	require.Equal(t, PreflightIssue{
+		ResourceType:       postgresFlexibleServerType,
+		ResourceName:       "psql-test",
+		Location:           "westus",
+		Message:            "The resource type is not available in the location.",
+		AlternateLocations: []string{"eastus2", "northeurope"},
+	}, issues[0])
+	require.Equal(t, "eastus2", issues[1].Location)
+	require.Contains(t, issues[1].Message, "Requires 12 'standardDSv5Family' vCPUs but only 4 of the 10 quota are available.")
+}
+
+func TestPreflightCheckerOpenAI(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	mockResponse := func(pathSuffix string, body string) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, pathSuffix)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    request,
+			}, nil
+		})
+	}
+
+	mockResponse("/providers/Microsoft.CognitiveServices", `{
+		"namespace": "Microsoft.CognitiveServices",
+		"resourceTypes": [{ "resourceType": "accounts", "locations": ["Sweden Central"] }]
+	}`)
+	mockResponse("/Microsoft.CognitiveServices/locations/swedencentral/models", `{
+		"value": [
+			{
+				"kind": "OpenAI",
+				"model": { "format": "OpenAI", "name": "gpt-35-turbo", "version": "0613", "skus": [{ "name": "Standard" }] }
+			}
+		]
+	}`)
+	mockResponse("/Microsoft.CognitiveServices/locations/swedencentral/usages", `{
+		"value": [
+			{ "currentValue": 200.0, "limit": 240.0, "name": { "value": "OpenAI.Standard.gpt-35-turbo" } }
+		]
+	}`)
+
+	checker := &preflightChecker{
+		azCli:          azCli,
+		subscriptionId: "SUBSCRIPTION_ID",
+		openAIDeployments: []OpenAIDeployment{
+			{Name: "chat", Model: "gpt-35-turbo", Version: "0613", Capacity: 30},
+			{Name: "chat2", Model: "gpt-35-turbo", Capacity: 20},
+			{Name: "gpt4", Model: "gpt-4", Version: "0613"},
+		},
+	}
+
+	issues := checker.check(*mockContext.Context, []preflightResource{
+		{
+			Type:     cognitiveAccountResourceType,
+			Name:     "oai-test",
+			Location: "swedencentral",
+		},
+	})
+
+	// There is no quota for gpt-4 in the location, the missing model is reported instead
+	require.Len(t, issues, 2)
+	require.Equal(t, "oai-test/gpt4", issues[0].ResourceName)
+	require.Equal(t, "The model gpt-4 (0613) is not available with the SKU 'Standard' in the location.", issues[0].Message)
+	require.Contains(t, issues[1].Message,
+		"Requires a capacity of 50 of the 'OpenAI.Standard.gpt-35-turbo' quota but only 40 of 240 are available.")
+}
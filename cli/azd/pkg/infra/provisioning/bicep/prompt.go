@@ -86,8 +86,9 @@ func (p *BicepProvider) promptForParameter(
 			value = userValue
 		case ParameterTypeString:
 			userValue, err := promptWithValidation(ctx, p.console, input.ConsoleOptions{
-				Message: msg,
-				Help:    help,
+				Message:    msg,
+				Help:       help,
+				IsPassword: param.Secure(),
 			}, convertString, validateLengthRange(key, param.MinLength, param.MaxLength))
 			if err != nil {
 				return nil, err
@@ -104,8 +105,9 @@ func (p *BicepProvider) promptForParameter(
 			value = userValue
 		case ParameterTypeObject:
 			userValue, err := promptWithValidation(ctx, p.console, input.ConsoleOptions{
-				Message: msg,
-				Help:    help,
+				Message:    msg,
+				Help:       help,
+				IsPassword: param.Secure(),
 			}, convertJson[map[string]any], validateJsonObject)
 			if err != nil {
 				return nil, err
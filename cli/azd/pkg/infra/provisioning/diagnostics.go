@@ -0,0 +1,39 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+// DefaultDiagnosticsCategoryGroup is the category group of the logs sent to the workspace when none are configured
+const DefaultDiagnosticsCategoryGroup = "allLogs"
+
+// DiagnosticsOptions configures the diagnostic settings azd creates after provisioning, sending the logs and metrics of
+// every provisioned resource to the Log Analytics workspace of the environment, ex)
+//
+//	infra:
+//	  diagnostics:
+//	    workspace: ${AZURE_LOG_ANALYTICS_WORKSPACE_ID}
+//	    categoryGroups: [audit]
+//	    metrics: false
+type DiagnosticsOptions struct {
+	// The resource id of the Log Analytics workspace. Supports environment variable substitution. Defaults to the only
+	// Log Analytics workspace of the resource group of the environment.
+	Workspace string `yaml:"workspace,omitempty"`
+	// The category groups of the logs sent to the workspace, ex) allLogs or audit. Defaults to allLogs.
+	CategoryGroups []string `yaml:"categoryGroups,omitempty"`
+	// Whether the metrics of the resources are sent to the workspace. Defaults to true.
+	Metrics *bool `yaml:"metrics,omitempty"`
+}
+
+// ResolvedCategoryGroups returns the category groups of the logs sent to the workspace
+func (o *DiagnosticsOptions) ResolvedCategoryGroups() []string {
+	if len(o.CategoryGroups) == 0 {
+		return []string{DefaultDiagnosticsCategoryGroup}
+	}
+
+	return o.CategoryGroups
+}
+
+// SendMetrics returns whether the metrics of the resources are sent to the workspace
+func (o *DiagnosticsOptions) SendMetrics() bool {
+	return o.Metrics == nil || *o.Metrics
+}
@@ -0,0 +1,29 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+)
+
+// DriftedResource is a provisioned resource whose configuration differs from the infrastructure of the project, ex)
+// after it was changed in the Azure portal
+type DriftedResource struct {
+	// Id of the resource, or its address in the infrastructure when it isn't known, ex) for resources deleted since
+	Id string `json:"id"`
+	// The change provisioning would make to the resource, ex) Modify, or Create when the resource was deleted
+	ChangeType string `json:"changeType"`
+	// Paths of the drifted properties of the resource, when reported by the provider
+	Properties []string `json:"properties,omitempty"`
+}
+
+type DriftResult struct {
+	Resources []*DriftedResource `json:"resources"`
+}
+
+// DriftDetector is implemented by providers able to compare the provisioned resources with the infrastructure of the
+// project, without changing them
+type DriftDetector interface {
+	Drift(ctx context.Context) (*DriftResult, error)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// EnvironmentHydrator derives well-known environment values from resources that were previously provisioned for an
+// environment, so an environment can be used for deployment without provisioning it again.
+type EnvironmentHydrator struct {
+	azCli                    azcli.AzCli
+	containerRegistryService azcli.ContainerRegistryService
+}
+
+// NewEnvironmentHydrator creates a new instance of the EnvironmentHydrator
+func NewEnvironmentHydrator(
+	azCli azcli.AzCli,
+	containerRegistryService azcli.ContainerRegistryService,
+) *EnvironmentHydrator {
+	return &EnvironmentHydrator{
+		azCli:                    azCli,
+		containerRegistryService: containerRegistryService,
+	}
+}
+
+// Hydrate inspects the resources referenced by the provisioning state and the resource groups tagged with the environment
+// name, setting the resource group, AKS cluster name and container registry endpoint when a single matching resource is
+// found. Values provided by the deployment outputs take precedence and are never overwritten.
+// The environment is saved when any value is set, and the values that were set are returned.
+func (h *EnvironmentHydrator) Hydrate(
+	ctx context.Context,
+	env *environment.Environment,
+	state *State,
+) (map[string]string, error) {
+	subscriptionId := env.GetSubscriptionId()
+
+	resourceIds, err := h.environmentResourceIds(ctx, subscriptionId, env.GetEnvName(), state)
+	if err != nil {
+		return nil, err
+	}
+
+	resourcesByType := map[infra.AzureResourceType][]*arm.ResourceID{}
+	for _, resourceId := range resourceIds {
+		parsed, err := arm.ParseResourceID(resourceId)
+		if err != nil {
+			log.Printf("skipping resource '%s' while hydrating environment: %v", resourceId, err)
+			continue
+		}
+
+		resourceType := infra.AzureResourceType(parsed.ResourceType.String())
+		resourcesByType[resourceType] = append(resourcesByType[resourceType], parsed)
+	}
+
+	values := map[string]string{}
+
+	if groups := resourcesByType[infra.AzureResourceTypeResourceGroup]; len(groups) == 1 {
+		values[environment.ResourceGroupEnvVarName] = groups[0].Name
+	}
+
+	if clusters := resourcesByType[infra.AzureResourceTypeManagedCluster]; len(clusters) == 1 {
+		values[environment.AksClusterEnvVarName] = clusters[0].Name
+	}
+
+	if registries := resourcesByType[infra.AzureResourceTypeContainerRegistry]; len(registries) == 1 {
+		loginServer, err := h.registryLoginServer(ctx, subscriptionId, registries[0].String())
+		if err != nil {
+			return nil, err
+		}
+
+		if loginServer != "" {
+			values[environment.ContainerRegistryEndpointEnvVarName] = loginServer
+		}
+	}
+
+	applied := map[string]string{}
+	for key, value := range values {
+		if _, has := state.Outputs[key]; has {
+			continue
+		}
+
+		env.DotenvSet(key, value)
+		applied[key] = value
+	}
+
+	if len(applied) > 0 {
+		if err := env.Save(); err != nil {
+			return nil, fmt.Errorf("writing environment: %w", err)
+		}
+	}
+
+	return applied, nil
+}
+
+// environmentResourceIds returns the unique set of resource ids from the provisioning state and the resource groups tagged
+// with the environment name, including the resources contained in those resource groups.
+func (h *EnvironmentHydrator) environmentResourceIds(
+	ctx context.Context,
+	subscriptionId string,
+	envName string,
+	state *State,
+) ([]string, error) {
+	seen := map[string]struct{}{}
+	resourceIds := []string{}
+
+	add := func(resourceId string) {
+		key := strings.ToLower(resourceId)
+		if _, has := seen[key]; has {
+			return
+		}
+
+		seen[key] = struct{}{}
+		resourceIds = append(resourceIds, resourceId)
+	}
+
+	for _, resource := range state.Resources {
+		add(resource.Id)
+	}
+
+	resourceManager := infra.NewAzureResourceManager(h.azCli)
+	groups, err := resourceManager.GetResourceGroupsForEnvironment(ctx, subscriptionId, envName)
+	var notFoundError *azureutil.ResourceNotFoundError
+	if err != nil && !errors.As(err, &notFoundError) {
+		return nil, fmt.Errorf("getting resource groups for environment: %w", err)
+	}
+
+	for _, group := range groups {
+		add(group.Id)
+
+		groupResources, err := h.azCli.ListResourceGroupResources(ctx, subscriptionId, group.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing resources for resource group '%s': %w", group.Name, err)
+		}
+
+		for _, resource := range groupResources {
+			add(resource.Id)
+		}
+	}
+
+	return resourceIds, nil
+}
+
+// registryLoginServer finds the login server of the container registry with the specified resource id
+func (h *EnvironmentHydrator) registryLoginServer(
+	ctx context.Context,
+	subscriptionId string,
+	registryId string,
+) (string, error) {
+	registries, err := h.containerRegistryService.GetContainerRegistries(ctx, subscriptionId)
+	if err != nil {
+		return "", fmt.Errorf("getting container registries: %w", err)
+	}
+
+	for _, registry := range registries {
+		if registry.ID == nil || !strings.EqualFold(*registry.ID, registryId) {
+			continue
+		}
+
+		if registry.Properties != nil && registry.Properties.LoginServer != nil {
+			return *registry.Properties.LoginServer, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+type mockContainerRegistryService struct {
+	registries []*armcontainerregistry.Registry
+}
+
+func (m *mockContainerRegistryService) Login(ctx context.Context, subscriptionId string, loginServer string) error {
+	return nil
+}
+
+func (m *mockContainerRegistryService) GetContainerRegistries(
+	ctx context.Context,
+	subscriptionId string,
+) ([]*armcontainerregistry.Registry, error) {
+	return m.registries, nil
+}
+
+func (m *mockContainerRegistryService) FindContainerRegistry(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*armcontainerregistry.Registry, error) {
+	return nil, nil
+}
+
+func (m *mockContainerRegistryService) GetTags(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+) ([]*azcli.ContainerRegistryTag, error) {
+	return nil, nil
+}
+
+func (m *mockContainerRegistryService) DeleteManifest(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	digest string,
+) error {
+	return nil
+}
+
+func (m *mockContainerRegistryService) PullCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*azcli.DockerCredentials, error) {
+	return nil, nil
+}
+
+func (m *mockContainerRegistryService) PushArtifact(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	tag string,
+	artifact azcli.ContainerRegistryArtifact,
+) (string, error) {
+	return "", nil
+}
+
+func (m *mockContainerRegistryService) AdminCredentialsUses() []azcli.AdminCredentialsUse {
+	return nil
+}
+
+func (m *mockContainerRegistryService) RotateAdminPassword(ctx context.Context, use azcli.AdminCredentialsUse) error {
+	return nil
+}
+
+const hydratorRgId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test-env"
+
+func TestEnvironmentHydrator(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	// Resource groups tagged with the environment name
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups") &&
+			request.URL.Query().Has("$filter")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Contains(t, request.URL.Query().Get("$filter"), "tagValue eq 'test-env'")
+
+		return jsonResponse(armresources.ResourceGroupListResult{
+			Value: []*armresources.ResourceGroup{
+				{
+					ID:       convert.RefOf(hydratorRgId),
+					Name:     convert.RefOf("rg-test-env"),
+					Type:     convert.RefOf("Microsoft.Resources/resourceGroups"),
+					Location: convert.RefOf("eastus2"),
+				},
+			},
+		})
+	})
+
+	// Resources within the tagged resource group
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-test-env/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return jsonResponse(armresources.ResourceListResult{
+			Value: []*armresources.GenericResourceExpanded{
+				{
+					ID:       convert.RefOf(hydratorRgId + "/providers/Microsoft.ContainerRegistry/registries/crtestenv"),
+					Name:     convert.RefOf("crtestenv"),
+					Type:     convert.RefOf("Microsoft.ContainerRegistry/registries"),
+					Location: convert.RefOf("eastus2"),
+				},
+			},
+		})
+	})
+
+	registryService := &mockContainerRegistryService{
+		registries: []*armcontainerregistry.Registry{
+			{
+				ID: convert.RefOf(hydratorRgId + "/providers/Microsoft.ContainerRegistry/registries/crtestenv"),
+				Properties: &armcontainerregistry.RegistryProperties{
+					LoginServer: convert.RefOf("crtestenv.azurecr.io"),
+				},
+			},
+		},
+	}
+
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	hydrator := NewEnvironmentHydrator(azCli, registryService)
+	values, err := hydrator.Hydrate(*mockContext.Context, env, &State{
+		Outputs: map[string]OutputParameter{
+			environment.ResourceGroupEnvVarName: {Type: ParameterTypeString, Value: "rg-from-outputs"},
+		},
+		Resources: []Resource{
+			{Id: hydratorRgId},
+			{Id: hydratorRgId + "/providers/Microsoft.ContainerService/managedClusters/aks-test-env"},
+			{Id: hydratorRgId + "/providers/Microsoft.ContainerService/managedClusters/aks-test-env/agentPools/system"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Values from outputs are not overwritten
+	require.Equal(t, map[string]string{
+		environment.AksClusterEnvVarName:                "aks-test-env",
+		environment.ContainerRegistryEndpointEnvVarName: "crtestenv.azurecr.io",
+	}, values)
+	require.Equal(t, "aks-test-env", env.Getenv(environment.AksClusterEnvVarName))
+	require.Equal(t, "crtestenv.azurecr.io", env.Getenv(environment.ContainerRegistryEndpointEnvVarName))
+}
+
+func jsonResponse(value any) (*http.Response, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
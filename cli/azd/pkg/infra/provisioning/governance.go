@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drone/envsubst"
+)
+
+const (
+	// RequiredTagsParameterName is the name of the template parameter azd populates with the required tags
+	RequiredTagsParameterName = "requiredTags"
+	// NamingConventionParameterName is the name of the template parameter azd populates with the naming convention
+	NamingConventionParameterName = "namingConvention"
+)
+
+// GovernanceOptions defines the tags and naming convention every provisioned resource is expected to follow
+type GovernanceOptions struct {
+	// Tags that must be present on every provisioned resource, ex) costCenter, owner.
+	// Values support environment variable substitution, ex) ${COST_CENTER}
+	RequiredTags map[string]string `yaml:"requiredTags,omitempty"`
+	// Pattern resource names must match, ex) *-${AZURE_ENV_NAME}-*.
+	// Supports environment variable substitution and `*` wildcards and is matched case-insensitively.
+	NamingConvention string `yaml:"namingConvention,omitempty"`
+	// Fails provisioning when provisioned resources do not follow the required tags or naming convention.
+	// When not set, violations are reported as warnings.
+	Enforce bool `yaml:"enforce,omitempty"`
+}
+
+// IsEmpty returns true when no required tags or naming convention are configured
+func (o *GovernanceOptions) IsEmpty() bool {
+	return o == nil || (len(o.RequiredTags) == 0 && o.NamingConvention == "")
+}
+
+// ResolvedGovernance is the result of resolving the governance options against an environment
+type ResolvedGovernance struct {
+	RequiredTags     map[string]string
+	NamingConvention string
+	Enforce          bool
+
+	namingRegex *regexp.Regexp
+}
+
+// ResolveGovernance substitutes the environment values referenced in the governance options and validates that
+// all required tags resolve to a value.
+func ResolveGovernance(options *GovernanceOptions, getenv func(string) string) (*ResolvedGovernance, error) {
+	resolved := &ResolvedGovernance{
+		RequiredTags: map[string]string{},
+	}
+
+	if options.IsEmpty() {
+		return resolved, nil
+	}
+
+	resolved.Enforce = options.Enforce
+
+	for key, value := range options.RequiredTags {
+		tagValue, err := envsubst.Eval(value, getenv)
+		if err != nil {
+			return nil, fmt.Errorf("substituting environment variables for required tag '%s': %w", key, err)
+		}
+
+		if strings.TrimSpace(tagValue) == "" {
+			return nil, fmt.Errorf("required tag '%s' does not have a value, set it in azure.yaml or the environment", key)
+		}
+
+		resolved.RequiredTags[key] = tagValue
+	}
+
+	if options.NamingConvention != "" {
+		convention, err := envsubst.Eval(options.NamingConvention, getenv)
+		if err != nil {
+			return nil, fmt.Errorf("substituting environment variables for naming convention: %w", err)
+		}
+
+		parts := strings.Split(convention, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+
+		namingRegex, err := regexp.Compile(fmt.Sprintf("(?i)^%s$", strings.Join(parts, ".*")))
+		if err != nil {
+			return nil, fmt.Errorf("compiling naming convention '%s': %w", convention, err)
+		}
+
+		resolved.NamingConvention = convention
+		resolved.namingRegex = namingRegex
+	}
+
+	return resolved, nil
+}
+
+// IsEmpty returns true when there are no required tags or naming convention to verify
+func (g *ResolvedGovernance) IsEmpty() bool {
+	return g == nil || (len(g.RequiredTags) == 0 && g.NamingConvention == "")
+}
+
+// GovernanceResource is a provisioned resource inspected for governance violations
+type GovernanceResource struct {
+	Id   string
+	Name string
+	Type string
+	Tags map[string]string
+}
+
+// GovernanceViolation describes a provisioned resource that does not follow the governance options
+type GovernanceViolation struct {
+	Resource GovernanceResource
+	// Required tags that are missing or have a different value
+	MissingTags []string
+	// Whether the resource name does not match the naming convention
+	InvalidName bool
+}
+
+func (v GovernanceViolation) String() string {
+	var reasons []string
+	if len(v.MissingTags) > 0 {
+		reasons = append(reasons, fmt.Sprintf("missing required tags: %s", strings.Join(v.MissingTags, ", ")))
+	}
+	if v.InvalidName {
+		reasons = append(reasons, "name does not match the naming convention")
+	}
+
+	return fmt.Sprintf("%s (%s): %s", v.Resource.Name, v.Resource.Type, strings.Join(reasons, "; "))
+}
+
+// Verify checks the resources carry all the required tags and match the naming convention
+func (g *ResolvedGovernance) Verify(resources []GovernanceResource) []GovernanceViolation {
+	violations := []GovernanceViolation{}
+
+	requiredKeys := make([]string, 0, len(g.RequiredTags))
+	for key := range g.RequiredTags {
+		requiredKeys = append(requiredKeys, key)
+	}
+	sort.Strings(requiredKeys)
+
+	for _, resource := range resources {
+		violation := GovernanceViolation{Resource: resource}
+
+		for _, key := range requiredKeys {
+			if value, has := resource.Tags[key]; !has || value != g.RequiredTags[key] {
+				violation.MissingTags = append(violation.MissingTags, key)
+			}
+		}
+
+		if g.namingRegex != nil && !g.namingRegex.MatchString(resource.Name) {
+			violation.InvalidName = true
+		}
+
+		if len(violation.MissingTags) > 0 || violation.InvalidName {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// GovernanceViolationsError is returned when enforced governance options are not followed by provisioned resources
+type GovernanceViolationsError struct {
+	Violations []GovernanceViolation
+}
+
+func (e *GovernanceViolationsError) Error() string {
+	lines := []string{
+		fmt.Sprintf("%d provisioned resource(s) do not follow the required tags or naming convention:", len(e.Violations)),
+	}
+
+	for _, violation := range e.Violations {
+		lines = append(lines, fmt.Sprintf("  - %s", violation))
+	}
+
+	return strings.Join(lines, "\n")
+}
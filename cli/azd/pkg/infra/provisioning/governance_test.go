@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveGovernance(t *testing.T) {
+	env := map[string]string{
+		"AZURE_ENV_NAME": "dev",
+		"COST_CENTER":    "1234",
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		resolved, err := ResolveGovernance(nil, func(name string) string { return env[name] })
+		require.NoError(t, err)
+		require.True(t, resolved.IsEmpty())
+	})
+
+	t.Run("Substitutes", func(t *testing.T) {
+		resolved, err := ResolveGovernance(&GovernanceOptions{
+			RequiredTags: map[string]string{
+				"costCenter": "${COST_CENTER}",
+				"owner":      "team-a",
+			},
+			NamingConvention: "*-${AZURE_ENV_NAME}-*",
+		}, func(name string) string { return env[name] })
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"costCenter": "1234", "owner": "team-a"}, resolved.RequiredTags)
+		require.Equal(t, "*-dev-*", resolved.NamingConvention)
+	})
+
+	t.Run("MissingTagValue", func(t *testing.T) {
+		_, err := ResolveGovernance(&GovernanceOptions{
+			RequiredTags: map[string]string{"owner": "${OWNER}"},
+		}, func(name string) string { return env[name] })
+		require.ErrorContains(t, err, "required tag 'owner' does not have a value")
+	})
+}
+
+func TestGovernanceVerify(t *testing.T) {
+	resolved, err := ResolveGovernance(&GovernanceOptions{
+		RequiredTags:     map[string]string{"owner": "team-a", "costCenter": "1234"},
+		NamingConvention: "*-dev-*",
+		Enforce:          true,
+	}, func(string) string { return "" })
+	require.NoError(t, err)
+
+	violations := resolved.Verify([]GovernanceResource{
+		{
+			Name: "rg-dev-app",
+			Type: "Microsoft.Resources/resourceGroups",
+			Tags: map[string]string{"owner": "team-a", "costCenter": "1234"},
+		},
+		{
+			Name: "APP-DEV-WEB",
+			Type: "Microsoft.Web/sites",
+			Tags: map[string]string{"owner": "team-a", "costCenter": "1234"},
+		},
+		{
+			Name: "stdevapp",
+			Type: "Microsoft.Storage/storageAccounts",
+			Tags: map[string]string{"owner": "team-b"},
+		},
+	})
+
+	require.Len(t, violations, 1)
+	require.Equal(t, "stdevapp", violations[0].Resource.Name)
+	require.Equal(t, []string{"costCenter", "owner"}, violations[0].MissingTags)
+	require.True(t, violations[0].InvalidName)
+
+	verifyErr := &GovernanceViolationsError{Violations: violations}
+	require.Equal(t,
+		"1 provisioned resource(s) do not follow the required tags or naming convention:\n"+
+			"  - stdevapp (Microsoft.Storage/storageAccounts): missing required tags: costCenter, owner; "+
+			"name does not match the naming convention",
+		verifyErr.Error())
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LintSeverity is the severity of a diagnostic of the linter of the infrastructure
+type LintSeverity string
+
+const (
+	// Fails provisioning
+	LintSeverityError LintSeverity = "error"
+	// Reported before provisioning, which continues
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityInfo    LintSeverity = "info"
+	// Not reported
+	LintSeverityOff LintSeverity = "off"
+)
+
+// LintOptions configures the linter run on the infrastructure before provisioning
+type LintOptions struct {
+	// Skips the linter.
+	Skip bool `yaml:"skip,omitempty"`
+	// The severity of the diagnostics of each rule, by name, ex) use-recent-api-versions: error. The rules which aren't
+	// listed keep their default severity.
+	Rules map[string]LintSeverity `yaml:"rules,omitempty"`
+}
+
+// IsSkipped returns true when the linter should not run
+func (o *LintOptions) IsSkipped() bool {
+	return o != nil && o.Skip
+}
+
+// Severity returns the configured severity of the rule, or its default severity when it isn't configured
+func (o *LintOptions) Severity(rule string, defaultSeverity LintSeverity) LintSeverity {
+	if o == nil {
+		return defaultSeverity
+	}
+
+	if severity, has := o.Rules[rule]; has {
+		return severity
+	}
+
+	return defaultSeverity
+}
+
+// Validate returns an error when a configured severity isn't known
+func (o *LintOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	for rule, severity := range o.Rules {
+		switch severity {
+		case LintSeverityError, LintSeverityWarning, LintSeverityInfo, LintSeverityOff:
+		default:
+			return fmt.Errorf(
+				"invalid severity '%s' of lint rule '%s', supported severities are error, warning, info and off",
+				severity, rule)
+		}
+	}
+
+	return nil
+}
+
+// LintDiagnostic is an issue found by the linter in a file of the infrastructure, ex) a hardcoded location
+type LintDiagnostic struct {
+	Line     int          `json:"line"`
+	Column   int          `json:"column"`
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+func (d LintDiagnostic) String() string {
+	return fmt.Sprintf("(%d,%d) %s %s: %s", d.Line, d.Column, d.Severity, d.Rule, d.Message)
+}
+
+// LintFile is a file of the infrastructure with diagnostics
+type LintFile struct {
+	// The path of the file, relative to the project
+	Path        string            `json:"path"`
+	Diagnostics []*LintDiagnostic `json:"diagnostics"`
+}
+
+type LintResult struct {
+	Files []*LintFile `json:"files"`
+}
+
+// Count returns the number of diagnostics of the severity
+func (r *LintResult) Count(severity LintSeverity) int {
+	count := 0
+	for _, file := range r.Files {
+		for _, diagnostic := range file.Diagnostics {
+			if diagnostic.Severity == severity {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// Linter is implemented by providers able to lint the infrastructure of the project before it's provisioned
+type Linter interface {
+	Lint(ctx context.Context) (*LintResult, error)
+}
+
+// LintError is returned when the linter finds diagnostics of severity error
+type LintError struct {
+	Result *LintResult
+}
+
+func (e *LintError) Error() string {
+	lines := []string{
+		fmt.Sprintf("the linter found %d error(s) in the infrastructure:", e.Result.Count(LintSeverityError)),
+	}
+	for _, file := range e.Result.Files {
+		for _, diagnostic := range file.Diagnostics {
+			if diagnostic.Severity == LintSeverityError {
+				lines = append(lines, fmt.Sprintf("  - %s%s", file.Path, diagnostic))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -5,12 +5,16 @@ package provisioning
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 )
 
 // Manages the orchestration of infrastructure provisioning
@@ -65,6 +69,162 @@ func (m *Manager) Deploy(ctx context.Context, plan *DeploymentPlan) (*DeployResu
 		return nil, fmt.Errorf("error deploying infrastructure: %w", err)
 	}
 
+	return m.completeDeploy(ctx, deployResult)
+}
+
+// Deploys a regional stamp of the Azure infrastructure in each of the locations. Each stamp is planned with the location
+// of the environment set to the location of the stamp and beforeDeploy, when set, is invoked with the plan of each stamp.
+// The outputs of every stamp are stored in the environment prefixed with the location, ex) EASTUS_WEBSITE_URL, and the
+// outputs of the first location, the primary location of the environment, are also stored without a prefix. The outputs
+// and locations are saved as each stamp is provisioned, so the stamps provisioned before a failing one remain known to the
+// next run and to azd down.
+func (m *Manager) DeployToLocations(
+	ctx context.Context,
+	locations []string,
+	beforeDeploy func(ctx context.Context, plan *DeploymentPlan) error,
+) (*DeployResult, error) {
+	if len(locations) == 0 {
+		return nil, errors.New("no locations specified")
+	}
+
+	var primary *Deployment
+	outputs := map[string]OutputParameter{}
+
+	originalLocation := m.env.GetLocation()
+	defer func() {
+		if primary == nil {
+			m.env.SetLocation(originalLocation)
+		} else {
+			m.env.SetLocation(locations[0])
+		}
+	}()
+
+	for i, location := range locations {
+		m.console.Message(ctx, fmt.Sprintf("\nProvisioning regional stamp in %s", output.WithHighLightFormat(location)))
+		m.env.SetLocation(location)
+
+		plan, err := m.Plan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("planning regional stamp in '%s': %w", location, err)
+		}
+
+		if beforeDeploy != nil {
+			if err := beforeDeploy(ctx, plan); err != nil {
+				return nil, err
+			}
+		}
+
+		deployResult, err := m.provider.Deploy(ctx, plan)
+		if err != nil {
+			return nil, fmt.Errorf("error deploying infrastructure in '%s': %w", location, err)
+		}
+
+		if primary == nil {
+			primary = deployResult.Deployment
+			for key, value := range deployResult.Deployment.Outputs {
+				outputs[key] = value
+			}
+		}
+
+		for key, value := range deployResult.Deployment.Outputs {
+			outputs[LocationOutputName(location, key)] = value
+		}
+
+		if err := m.saveStamps(locations[:i+1], outputs); err != nil {
+			return nil, fmt.Errorf("saving regional stamp in '%s': %w", location, err)
+		}
+	}
+
+	deployment := *primary
+	deployment.Outputs = outputs
+
+	return m.completeDeploy(ctx, &DeployResult{Deployment: &deployment})
+}
+
+// saveStamps saves the locations of the regional stamps provisioned so far and their outputs in the environment
+func (m *Manager) saveStamps(locations []string, outputs map[string]OutputParameter) error {
+	m.env.SetLocation(locations[0])
+	m.env.DotenvSet(environment.LocationsEnvVarName, strings.Join(locations, ","))
+
+	if len(outputs) == 0 {
+		return m.env.Save()
+	}
+
+	return UpdateEnvironment(m.env, outputs)
+}
+
+// LocationOutputName returns the name of the environment variable storing an output of the regional stamp provisioned
+// in the location, ex) EASTUS_WEBSITE_URL
+func LocationOutputName(location string, outputName string) string {
+	return fmt.Sprintf("%s_%s", strings.ToUpper(NormalizeLocation(location)), outputName)
+}
+
+// Attaches to the in-flight infrastructure deployment started by a previous azd process and waits for it to complete
+func (m *Manager) Attach(ctx context.Context, plan *DeploymentPlan) (*DeployResult, error) {
+	attacher, ok := m.provider.(DeploymentAttacher)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider does not support attaching to an in-flight deployment", m.provider.Name())
+	}
+
+	deployResult, err := attacher.Attach(ctx, plan)
+	if err != nil {
+		return nil, fmt.Errorf("error attaching to infrastructure deployment: %w", err)
+	}
+
+	return m.completeDeploy(ctx, deployResult)
+}
+
+// Drift reports the provisioned resources whose configuration differs from the infrastructure of the project
+func (m *Manager) Drift(ctx context.Context) (*DriftResult, error) {
+	detector, ok := m.provider.(DriftDetector)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider does not support detecting drift", m.provider.Name())
+	}
+
+	driftResult, err := detector.Drift(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error detecting infrastructure drift: %w", err)
+	}
+
+	return driftResult, nil
+}
+
+// SimulatePolicies reports the planned resources the Azure Policy assignments of the subscription would deny. Returns
+// nil when the provider doesn't support simulating policies.
+func (m *Manager) SimulatePolicies(ctx context.Context, plan *DeploymentPlan) (*PolicySimulationResult, error) {
+	simulator, ok := m.provider.(PolicySimulator)
+	if !ok {
+		log.Printf("skipping policy simulation, the %s provider does not support it", m.provider.Name())
+		return nil, nil
+	}
+
+	result, err := simulator.SimulatePolicies(ctx, plan)
+	if err != nil {
+		return nil, fmt.Errorf("error simulating policies: %w", err)
+	}
+
+	return result, nil
+}
+
+// Lint reports the issues of the infrastructure of the project, grouped by file. Returns nil when the provider doesn't
+// support linting, or when the linter is skipped.
+func (m *Manager) Lint(ctx context.Context) (*LintResult, error) {
+	linter, ok := m.provider.(Linter)
+	if !ok {
+		log.Printf("skipping lint, the %s provider does not support it", m.provider.Name())
+		return nil, nil
+	}
+
+	result, err := linter.Lint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error linting infrastructure: %w", err)
+	}
+
+	return result, nil
+}
+
+// completeDeploy updates the environment with the outputs of the completed deployment
+func (m *Manager) completeDeploy(ctx context.Context, deployResult *DeployResult) (*DeployResult, error) {
 	if err := UpdateEnvironment(m.env, deployResult.Deployment.Outputs); err != nil {
 		return nil, fmt.Errorf("updating environment with deployment outputs: %w", err)
 	}
@@ -5,6 +5,7 @@ package provisioning_test
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -109,6 +110,74 @@ func TestManagerDeploy(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestManagerDeployToLocations(t *testing.T) {
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	mgr := NewManager(mockContext.Container, env, mockContext.Console, mockContext.AlphaFeaturesManager)
+	err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test"})
+	require.NoError(t, err)
+
+	plannedLocations := []any{}
+	deployResult, err := mgr.DeployToLocations(
+		*mockContext.Context,
+		[]string{"westus", "westeurope"},
+		func(ctx context.Context, plan *DeploymentPlan) error {
+			plannedLocations = append(plannedLocations, plan.Deployment.Parameters["location"].Value)
+			return nil
+		},
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, deployResult)
+	require.Equal(t, []any{"westus", "westeurope"}, plannedLocations)
+	require.Equal(t, "westus", env.GetLocation())
+	require.Equal(t, "westus,westeurope", env.Getenv(environment.LocationsEnvVarName))
+}
+
+func TestManagerDeployToLocationsSavesProvisionedStamps(t *testing.T) {
+	root := t.TempDir()
+	env := environment.EmptyWithRoot(root)
+	env.SetSubscriptionId("SUBSCRIPTION_ID")
+	env.SetLocation("eastus2")
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	mgr := NewManager(mockContext.Container, env, mockContext.Console, mockContext.AlphaFeaturesManager)
+	err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test"})
+	require.NoError(t, err)
+
+	deployResult, err := mgr.DeployToLocations(
+		*mockContext.Context,
+		[]string{"westus", "westeurope"},
+		func(ctx context.Context, plan *DeploymentPlan) error {
+			if plan.Deployment.Parameters["location"].Value == "westeurope" {
+				return errors.New("policy violation")
+			}
+			return nil
+		},
+	)
+
+	require.Error(t, err)
+	require.Nil(t, deployResult)
+	require.Equal(t, "westus", env.GetLocation())
+
+	saved, err := environment.FromRoot(root)
+	require.NoError(t, err)
+	require.Equal(t, "westus", saved.GetLocation())
+	require.Equal(t, "westus", saved.Getenv(environment.LocationsEnvVarName))
+}
+
+func TestLocationOutputName(t *testing.T) {
+	require.Equal(t, "EASTUS2_WEBSITE_URL", LocationOutputName("East US 2", "WEBSITE_URL"))
+}
+
 func TestManagerDestroyWithPositiveConfirmation(t *testing.T) {
 	env := environment.EphemeralWithValues("test-env", map[string]string{
 		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"fmt"
+)
+
+// NetworkMode is how the provisioned resources are exposed to the network
+type NetworkMode string
+
+const (
+	// The resources are reachable from public networks, the default of templates
+	NetworkModePublic NetworkMode = "public"
+	// The public network access of the resources is disabled, and the resources are reached through private endpoints
+	// and the VNet integration of the services
+	NetworkModePrivate NetworkMode = "private"
+)
+
+const (
+	// PrivateNetworkingParameterName is the name of the template parameter azd populates with whether the template
+	// deploys private endpoints and integrates the services with a VNet
+	PrivateNetworkingParameterName = "privateNetworking"
+	// PublicNetworkAccessParameterName is the name of the template parameter azd populates with the public network
+	// access of the resources, Enabled or Disabled
+	PublicNetworkAccessParameterName = "publicNetworkAccess"
+)
+
+// ParseNetworkMode parses the network mode of azure.yaml or of the --network flag. An empty mode leaves the network
+// parameters of templates unset.
+func ParseNetworkMode(mode string) (NetworkMode, error) {
+	switch NetworkMode(mode) {
+	case "", NetworkModePublic, NetworkModePrivate:
+		return NetworkMode(mode), nil
+	}
+
+	return "", fmt.Errorf("unsupported network mode '%s', use '%s' or '%s'", mode, NetworkModePublic, NetworkModePrivate)
+}
+
+// NetworkParameters returns the values of the standard network parameters of templates for the network mode, or nil
+// when the mode isn't set.
+func NetworkParameters(mode NetworkMode) map[string]any {
+	switch mode {
+	case NetworkModePrivate:
+		return map[string]any{
+			PrivateNetworkingParameterName:   true,
+			PublicNetworkAccessParameterName: "Disabled",
+		}
+	case NetworkModePublic:
+		return map[string]any{
+			PrivateNetworkingParameterName:   false,
+			PublicNetworkAccessParameterName: "Enabled",
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OpenAIAuthKind is how services authenticate to the Azure OpenAI account
+type OpenAIAuthKind string
+
+const (
+	// Services authenticate with their identity, the keys of the account are disabled
+	OpenAIAuthRbac OpenAIAuthKind = "rbac"
+	// Services authenticate with the key of the account, stored in the environment as AZURE_OPENAI_KEY
+	OpenAIAuthKey OpenAIAuthKind = "key"
+)
+
+const (
+	// DefaultOpenAIDeploymentSku is the SKU of model deployments which don't specify one
+	DefaultOpenAIDeploymentSku = "Standard"
+	// DefaultOpenAIDeploymentCapacity is the capacity, in thousands of tokens per minute, of model deployments which don't
+	// specify one
+	DefaultOpenAIDeploymentCapacity = 10
+)
+
+// OpenAIOptions declares the model deployments of the Azure OpenAI account of the project, ex)
+//
+//	infra:
+//	  openai:
+//	    auth: rbac
+//	    deployments:
+//	      - name: chat
+//	        model: gpt-35-turbo
+//	        version: "0613"
+//	        capacity: 30
+type OpenAIOptions struct {
+	Deployments []OpenAIDeployment `yaml:"deployments,omitempty"`
+	// How services authenticate to the account, rbac (default) or key
+	Auth OpenAIAuthKind `yaml:"auth,omitempty"`
+}
+
+// OpenAIDeployment is a model deployment of the Azure OpenAI account
+type OpenAIDeployment struct {
+	Name    string `yaml:"name"`
+	Model   string `yaml:"model"`
+	Version string `yaml:"version,omitempty"`
+	// Thousands of tokens per minute
+	Capacity int    `yaml:"capacity,omitempty"`
+	Sku      string `yaml:"sku,omitempty"`
+}
+
+// AuthKind returns how services authenticate to the account
+func (o *OpenAIOptions) AuthKind() OpenAIAuthKind {
+	if o == nil || o.Auth == "" {
+		return OpenAIAuthRbac
+	}
+
+	return o.Auth
+}
+
+// Validate checks the deployments are named uniquely and specify a model
+func (o *OpenAIOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	if o.Auth != "" && o.Auth != OpenAIAuthRbac && o.Auth != OpenAIAuthKey {
+		return fmt.Errorf("invalid openai auth '%s', supported values are %s and %s", o.Auth, OpenAIAuthRbac, OpenAIAuthKey)
+	}
+
+	names := map[string]struct{}{}
+	for _, deployment := range o.Deployments {
+		if deployment.Name == "" {
+			return errors.New("openai deployments require a name")
+		}
+
+		if _, has := names[deployment.Name]; has {
+			return fmt.Errorf("openai deployment '%s' is declared more than once", deployment.Name)
+		}
+		names[deployment.Name] = struct{}{}
+
+		if deployment.Model == "" {
+			return fmt.Errorf("openai deployment '%s' requires a model", deployment.Name)
+		}
+
+		if deployment.Capacity < 0 {
+			return fmt.Errorf("openai deployment '%s' has a negative capacity", deployment.Name)
+		}
+	}
+
+	return nil
+}
+
+// SkuName returns the SKU of the deployment, defaulting to Standard
+func (d OpenAIDeployment) SkuName() string {
+	if d.Sku == "" {
+		return DefaultOpenAIDeploymentSku
+	}
+
+	return d.Sku
+}
+
+// CapacityOrDefault returns the capacity of the deployment in thousands of tokens per minute
+func (d OpenAIDeployment) CapacityOrDefault() int {
+	if d.Capacity == 0 {
+		return DefaultOpenAIDeploymentCapacity
+	}
+
+	return d.Capacity
+}
+
+// UsageName returns the name of the quota consumed by the deployment, ex) OpenAI.Standard.gpt-35-turbo
+func (d OpenAIDeployment) UsageName() string {
+	return fmt.Sprintf("OpenAI.%s.%s", d.SkuName(), d.Model)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIOptionsValidate(t *testing.T) {
+	var nilOptions *OpenAIOptions
+	require.NoError(t, nilOptions.Validate())
+	require.Equal(t, OpenAIAuthRbac, nilOptions.AuthKind())
+
+	valid := &OpenAIOptions{
+		Auth: OpenAIAuthKey,
+		Deployments: []OpenAIDeployment{
+			{Name: "chat", Model: "gpt-35-turbo", Version: "0613", Capacity: 30},
+			{Name: "embeddings", Model: "text-embedding-ada-002"},
+		},
+	}
+	require.NoError(t, valid.Validate())
+	require.Equal(t, OpenAIAuthKey, valid.AuthKind())
+
+	tests := map[string]*OpenAIOptions{
+		"InvalidAuth":    {Auth: "token"},
+		"MissingName":    {Deployments: []OpenAIDeployment{{Model: "gpt-35-turbo"}}},
+		"MissingModel":   {Deployments: []OpenAIDeployment{{Name: "chat"}}},
+		"DuplicateName":  {Deployments: []OpenAIDeployment{{Name: "chat", Model: "a"}, {Name: "chat", Model: "b"}}},
+		"NegativeTokens": {Deployments: []OpenAIDeployment{{Name: "chat", Model: "a", Capacity: -1}}},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, options.Validate())
+		})
+	}
+}
+
+func TestOpenAIDeploymentDefaults(t *testing.T) {
+	deployment := OpenAIDeployment{Name: "chat", Model: "gpt-35-turbo"}
+	require.Equal(t, DefaultOpenAIDeploymentSku, deployment.SkuName())
+	require.Equal(t, DefaultOpenAIDeploymentCapacity, deployment.CapacityOrDefault())
+	require.Equal(t, "OpenAI.Standard.gpt-35-turbo", deployment.UsageName())
+
+	deployment.Sku = "GlobalStandard"
+	deployment.Capacity = 50
+	require.Equal(t, 50, deployment.CapacityOrDefault())
+	require.Equal(t, "OpenAI.GlobalStandard.gpt-35-turbo", deployment.UsageName())
+}
@@ -3,7 +3,11 @@
 
 package provisioning
 
-import "github.com/azure/azure-dev/cli/azd/pkg/output"
+import (
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
 
 type ActionOptions struct {
 	// The desired console output format
@@ -34,6 +38,8 @@ type DestroyOptions struct {
 	force bool
 	// Whether or not to purge any key vaults associated with the deployment
 	purge bool
+	// Resource groups and resources, by name or id, that must not be deleted
+	excludedResources []string
 }
 
 func (o *DestroyOptions) Purge() bool {
@@ -44,6 +50,28 @@ func (o *DestroyOptions) Force() bool {
 	return o.force
 }
 
+func (o *DestroyOptions) ExcludedResources() []string {
+	return o.excludedResources
+}
+
+// IsExcluded returns true when the resource, or resource group, with the given id and name must not be deleted
+func (o *DestroyOptions) IsExcluded(id string, name string) bool {
+	for _, excluded := range o.excludedResources {
+		if strings.EqualFold(excluded, id) || strings.EqualFold(excluded, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithExcludedResources returns a copy of the options which excludes the resource groups and resources, by name or id,
+// from deletion
+func (o DestroyOptions) WithExcludedResources(excludedResources []string) DestroyOptions {
+	o.excludedResources = excludedResources
+	return o
+}
+
 func NewDestroyOptions(force bool, purge bool) DestroyOptions {
 	return DestroyOptions{
 		force: force,
@@ -0,0 +1,33 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+)
+
+// PolicyDenial is a planned resource an Azure Policy assignment would deny, ex) a public IP address denied by the
+// policies of the subscription, or a resource in a location they don't allow
+type PolicyDenial struct {
+	// Id of the planned resource
+	ResourceId string `json:"resourceId"`
+	// The display name of the policy assignment, or its id when it has none
+	PolicyAssignment string `json:"policyAssignment"`
+	// The display name of the policy definition, or its id when it has none
+	PolicyDefinition string `json:"policyDefinition"`
+	// Why the resource would be denied, when reported by the policy
+	Reason string `json:"reason,omitempty"`
+}
+
+type PolicySimulationResult struct {
+	// The number of planned resources evaluated
+	Evaluated int             `json:"evaluated"`
+	Denials   []*PolicyDenial `json:"denials"`
+}
+
+// PolicySimulator is implemented by providers able to evaluate the planned resources against the Azure Policy
+// assignments of their scope before they're provisioned
+type PolicySimulator interface {
+	SimulatePolicies(ctx context.Context, plan *DeploymentPlan) (*PolicySimulationResult, error)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreflightOptions controls the quota and regional availability checks run before provisioning
+type PreflightOptions struct {
+	// Skips the checks.
+	Skip bool `yaml:"skip,omitempty"`
+	// Fails provisioning when the checks find resources that are expected to fail.
+	// When not set, the issues are reported as warnings.
+	Enforce bool `yaml:"enforce,omitempty"`
+}
+
+// IsSkipped returns true when the preflight checks should not run
+func (o *PreflightOptions) IsSkipped() bool {
+	return o != nil && o.Skip
+}
+
+// IsEnforced returns true when preflight issues should fail provisioning
+func (o *PreflightOptions) IsEnforced() bool {
+	return o != nil && o.Enforce
+}
+
+// PreflightIssue describes a resource expected to fail provisioning because of a quota or the regional availability
+// of the resource type or SKU
+type PreflightIssue struct {
+	ResourceType string
+	ResourceName string
+	Location     string
+	Message      string
+	// Locations where the resource is expected to be available
+	AlternateLocations []string
+}
+
+func (i PreflightIssue) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s (%s) in '%s': %s", i.ResourceName, i.ResourceType, i.Location, i.Message))
+
+	if len(i.AlternateLocations) > 0 {
+		sb.WriteString(fmt.Sprintf(" Consider one of these locations: %s", strings.Join(i.AlternateLocations, ", ")))
+	}
+
+	return sb.String()
+}
+
+// PreflightError is returned when the preflight checks are enforced and find resources expected to fail provisioning
+type PreflightError struct {
+	Issues []PreflightIssue
+}
+
+func (e *PreflightError) Error() string {
+	lines := make([]string, 0, len(e.Issues)+1)
+	lines = append(lines, "resources are expected to fail provisioning because of quotas or regional availability:")
+	for _, issue := range e.Issues {
+		lines = append(lines, fmt.Sprintf("  - %s", issue))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NormalizeLocation converts a location display name, ex) East US 2, to its name, ex) eastus2
+func NormalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
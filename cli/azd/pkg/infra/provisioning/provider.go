@@ -5,6 +5,7 @@ package provisioning
 
 import (
 	"context"
+	"errors"
 )
 
 type ProviderKind string
@@ -21,6 +22,18 @@ type Options struct {
 	Provider ProviderKind `yaml:"provider"`
 	Path     string       `yaml:"path"`
 	Module   string       `yaml:"module"`
+	// Required tags and naming convention verified on provisioned resources
+	Governance *GovernanceOptions `yaml:"governance,omitempty"`
+	// Quota and regional availability checks run before provisioning
+	Preflight *PreflightOptions `yaml:"preflight,omitempty"`
+	// The linter run on the infrastructure before provisioning, and the severity of its rules
+	Lint *LintOptions `yaml:"lint,omitempty"`
+	// Model deployments of the Azure OpenAI account
+	OpenAI *OpenAIOptions `yaml:"openai,omitempty"`
+	// How the provisioned resources are exposed to the network, public or private
+	Network NetworkMode `yaml:"network,omitempty"`
+	// Diagnostic settings sending the logs and metrics of the provisioned resources to a Log Analytics workspace
+	Diagnostics *DiagnosticsOptions `yaml:"diagnostics,omitempty"`
 }
 
 type DeploymentPlan struct {
@@ -51,3 +64,12 @@ type Provider interface {
 	Deploy(ctx context.Context, plan *DeploymentPlan) (*DeployResult, error)
 	Destroy(ctx context.Context, options DestroyOptions) (*DestroyResult, error)
 }
+
+// ErrNoInFlightDeployment is returned when attaching to a deployment and no in-flight deployment is found
+var ErrNoInFlightDeployment = errors.New("no in-flight deployment found")
+
+// DeploymentAttacher is implemented by providers that are able to re-attach to an in-flight deployment started by a
+// previous azd process
+type DeploymentAttacher interface {
+	Attach(ctx context.Context, plan *DeploymentPlan) (*DeployResult, error)
+}
@@ -11,9 +11,14 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 )
 
+// UpdateEnvironment sets the outputs of the infrastructure in the `.env` file of the environment as strings, and saves
+// them with their types to the outputs of the environment, see [environment.Environment.Outputs].
 func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputParameter) error {
 	if len(outputs) > 0 {
+		typedOutputs := make(map[string]environment.Output, len(outputs))
 		for key, param := range outputs {
+			typedOutputs[key] = environment.Output{Type: string(param.Type), Value: param.Value}
+
 			// Complex types marshalled as JSON strings, simple types marshalled as simple strings
 			if param.Type == ParameterTypeArray || param.Type == ParameterTypeObject {
 				bytes, err := json.Marshal(param.Value)
@@ -29,6 +34,10 @@ func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputPa
 		if err := env.Save(); err != nil {
 			return fmt.Errorf("writing environment: %w", err)
 		}
+
+		if err := env.SaveOutputs(typedOutputs); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -83,7 +92,7 @@ func ParseProvider(kind ProviderKind) (ProviderKind, error) {
 		return Bicep, nil
 	// For the time being we need to include `Test` here for the unit tests to work as expected
 	// App builds will pass this test but fail resolving the provider since `Test` won't be registered in the container
-	case Bicep, Terraform, Test:
+	case Bicep, Arm, Terraform, Test:
 		return kind, nil
 	}
 
@@ -22,6 +22,18 @@ const succeededProvisioningState string = "Succeeded"
 const runningProvisioningState string = "Running"
 const failedProvisioningState string = "Failed"
 
+// ProgressObserver observes the progress of the deployment of the infrastructure: the number of resources deployed, out
+// of the resources of the deployment started so far
+type ProgressObserver func(ctx context.Context, deployed int, total int)
+
+type progressObserverKey struct{}
+
+// WithProgressObserver returns a context whose deployments of the infrastructure report their progress to the observer,
+// ex) to report the progress of azd up on a pull request
+func WithProgressObserver(ctx context.Context, observer ProgressObserver) context.Context {
+	return context.WithValue(ctx, progressObserverKey{}, observer)
+}
+
 // ProvisioningProgressDisplay displays interactive progress for an ongoing Azure provisioning operation.
 type ProvisioningProgressDisplay struct {
 	// Whether the deployment has started
@@ -81,11 +93,20 @@ func (display *ProvisioningProgressDisplay) ReportProgress(
 	newlyDeployedResources := []*armresources.DeploymentOperation{}
 	newlyFailedResources := []*armresources.DeploymentOperation{}
 	runningDeployments := []*armresources.DeploymentOperation{}
+	deployed, total := 0, 0
 
 	for i := range operations {
 		if operations[i].Properties.TargetResource != nil {
 			resourceId := *operations[i].Properties.TargetResource.ResourceName
 
+			if infra.IsTopLevelResourceType(
+				infra.AzureResourceType(*operations[i].Properties.TargetResource.ResourceType)) {
+				total++
+				if *operations[i].Properties.ProvisioningState == succeededProvisioningState {
+					deployed++
+				}
+			}
+
 			if !display.displayedResources[resourceId] &&
 				infra.IsTopLevelResourceType(
 					infra.AzureResourceType(*operations[i].Properties.TargetResource.ResourceType)) {
@@ -109,6 +130,10 @@ func (display *ProvisioningProgressDisplay) ReportProgress(
 		)
 	})
 
+	if observer, ok := ctx.Value(progressObserverKey{}).(ProgressObserver); ok {
+		observer(ctx, deployed, total)
+	}
+
 	displayedResources := append(newlyDeployedResources, newlyFailedResources...)
 	display.logNewlyCreatedResources(ctx, displayedResources, runningDeployments)
 	return nil
@@ -122,3 +122,28 @@ func TestReportProgress(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, mockContext.Console.Output(), outputLength)
 }
+
+func TestReportProgressObserver(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	scope := infra.NewSubscriptionDeployment(azCli, "eastus2", "SUBSCRIPTION_ID", "DEPLOYMENT_NAME")
+	mockAzDeploymentShow(t, *mockContext)
+
+	deployed, total := -1, -1
+	ctx := WithProgressObserver(*mockContext.Context, func(_ context.Context, d int, t int) {
+		deployed, total = d, t
+	})
+
+	startTime := time.Now()
+	mockResourceManager := mockResourceManager{}
+	mockResourceManager.AddInProgressOperation()
+	mockResourceManager.AddInProgressOperation()
+	mockResourceManager.AddInProgressSubResourceOperation()
+	mockResourceManager.MarkComplete(0)
+
+	progressDisplay := NewProvisioningProgressDisplay(&mockResourceManager, mockContext.Console, scope)
+	require.NoError(t, progressDisplay.ReportProgress(ctx, &startTime))
+	require.Equal(t, 1, deployed)
+	require.Equal(t, 2, total)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateEnvironment(t *testing.T) {
+	env := environment.EmptyWithRoot(t.TempDir())
+
+	err := UpdateEnvironment(env, map[string]OutputParameter{
+		"API_URL":   {Type: ParameterTypeString, Value: "https://api.contoso.com"},
+		"REPLICAS":  {Type: ParameterTypeNumber, Value: 3},
+		"ENDPOINTS": {Type: ParameterTypeArray, Value: []any{map[string]any{"url": "https://contoso.com"}}},
+	})
+	require.NoError(t, err)
+
+	// The values of the .env file are strings, for backward compatibility
+	require.Equal(t, "https://api.contoso.com", env.Getenv("API_URL"))
+	require.Equal(t, "3", env.Getenv("REPLICAS"))
+	require.Equal(t, `[{"url":"https://contoso.com"}]`, env.Getenv("ENDPOINTS"))
+
+	outputs, err := env.Outputs()
+	require.NoError(t, err)
+	require.Equal(t, environment.Output{Type: "number", Value: json.Number("3")}, outputs["REPLICAS"])
+	require.Equal(t, "array", outputs["ENDPOINTS"].Type)
+	require.Equal(t, "https://contoso.com", env.Getenv("outputs.ENDPOINTS[0].url"))
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+type terraformPlanResourceChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Actions []string       `json:"actions"`
+		Before  map[string]any `json:"before"`
+		After   map[string]any `json:"after"`
+	} `json:"change"`
+}
+
+type terraformPlanOutput struct {
+	// changes made outside of terraform, detected when refreshing the state
+	ResourceDrift []terraformPlanResourceChange `json:"resource_drift"`
+	// changes applying the plan would make, ex) to restore drifted resources
+	ResourceChanges []terraformPlanResourceChange `json:"resource_changes"`
+}
+
+// Drift plans the infrastructure of the project against the provisioned resources, like
+// `terraform plan -detailed-exitcode`. Resources changed outside of terraform, or which applying the plan would change,
+// have drifted.
+func (t *TerraformProvider) Drift(ctx context.Context) (*DriftResult, error) {
+	plan, err := t.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	details := plan.Details.(TerraformDeploymentDetails)
+
+	runResult, err := t.cli.Show(ctx, t.modulePath(), details.PlanFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("showing plan failed: %s, err:%w", runResult, err)
+	}
+
+	var planOutput terraformPlanOutput
+	if err := json.Unmarshal([]byte(runResult), &planOutput); err != nil {
+		return nil, fmt.Errorf("reading plan: %w", err)
+	}
+
+	return driftFromPlan(planOutput), nil
+}
+
+// driftFromPlan collects the drifted resources of the plan, by address. Resources which were changed outside of terraform
+// are reported with the properties which were changed.
+func driftFromPlan(planOutput terraformPlanOutput) *DriftResult {
+	result := &DriftResult{
+		Resources: []*DriftedResource{},
+	}
+	addresses := map[string]bool{}
+
+	for _, changes := range [][]terraformPlanResourceChange{planOutput.ResourceDrift, planOutput.ResourceChanges} {
+		for _, change := range changes {
+			actions := []string{}
+			for _, action := range change.Change.Actions {
+				if action != "no-op" && action != "read" {
+					actions = append(actions, action)
+				}
+			}
+			if len(actions) == 0 || addresses[change.Address] {
+				continue
+			}
+			addresses[change.Address] = true
+
+			id := change.Address
+			if resourceId, ok := change.Change.Before["id"].(string); ok && resourceId != "" {
+				id = resourceId
+			}
+
+			result.Resources = append(result.Resources, &DriftedResource{
+				Id:         id,
+				ChangeType: strings.Join(actions, ","),
+				Properties: changedProperties(change.Change.Before, change.Change.After),
+			})
+		}
+	}
+
+	return result
+}
+
+// changedProperties returns the sorted top level properties whose values differ, when the resource exists before and
+// after the change
+func changedProperties(before map[string]any, after map[string]any) []string {
+	if before == nil || after == nil {
+		return nil
+	}
+
+	properties := []string{}
+	for _, key := range maps.Keys(before) {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			properties = append(properties, key)
+		}
+	}
+	for _, key := range maps.Keys(after) {
+		if _, has := before[key]; !has {
+			properties = append(properties, key)
+		}
+	}
+	slices.Sort(properties)
+
+	return properties
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftFromPlan(t *testing.T) {
+	planJson := `{
+		"resource_drift": [
+			{
+				"address": "azurerm_linux_web_app.web",
+				"change": {
+					"actions": ["update"],
+					"before": {"id": "/subscriptions/SUB/web", "https_only": true, "name": "web"},
+					"after": {"id": "/subscriptions/SUB/web", "https_only": false, "name": "web"}
+				}
+			}
+		],
+		"resource_changes": [
+			{
+				"address": "azurerm_linux_web_app.web",
+				"change": {
+					"actions": ["update"],
+					"before": {"id": "/subscriptions/SUB/web", "https_only": false},
+					"after": {"id": "/subscriptions/SUB/web", "https_only": true}
+				}
+			},
+			{
+				"address": "azurerm_storage_account.st",
+				"change": {"actions": ["create"], "before": null, "after": {"name": "st"}}
+			},
+			{
+				"address": "azurerm_resource_group.rg",
+				"change": {"actions": ["no-op"], "before": {"id": "/subscriptions/SUB/rg"}, "after": {"id": "/subscriptions/SUB/rg"}}
+			}
+		]
+	}`
+
+	var planOutput terraformPlanOutput
+	require.NoError(t, json.Unmarshal([]byte(planJson), &planOutput))
+
+	require.Equal(t, &provisioning.DriftResult{
+		Resources: []*provisioning.DriftedResource{
+			{
+				Id:         "/subscriptions/SUB/web",
+				ChangeType: "update",
+				Properties: []string{"https_only"},
+			},
+			{
+				Id:         "azurerm_storage_account.st",
+				ChangeType: "create",
+			},
+		},
+	}, driftFromPlan(planOutput))
+}
@@ -3,6 +3,7 @@ package terraform
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -195,6 +196,11 @@ func (t *TerraformProvider) Deploy(ctx context.Context, deployment *DeploymentPl
 
 // Destroys the specified deployment through terraform destroy
 func (t *TerraformProvider) Destroy(ctx context.Context, options DestroyOptions) (*DestroyResult, error) {
+	// terraform destroy removes every resource of the state, it can't leave the excluded resources in place
+	if len(options.ExcludedResources()) > 0 {
+		return nil, errors.New("excluding resources from deletion is not supported by the terraform provider")
+	}
+
 	isRemoteBackendConfig, err := t.isRemoteBackendConfig()
 	if err != nil {
 		return nil, fmt.Errorf("reading backend config: %w", err)
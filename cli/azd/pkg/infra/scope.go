@@ -32,7 +32,14 @@ type Deployment interface {
 		template azure.RawArmTemplate,
 		parameters azure.ArmParameters,
 		tags map[string]*string,
+		options *azcli.DeployOptions,
 	) (*armresources.DeploymentExtended, error)
+	// WhatIf predicts the changes to the resources deploying a given template with a set of parameters would make.
+	WhatIf(
+		ctx context.Context,
+		template azure.RawArmTemplate,
+		parameters azure.ArmParameters,
+	) (*armresources.WhatIfOperationResult, error)
 	// Deployment fetches information about this deployment.
 	Deployment(ctx context.Context) (*armresources.DeploymentExtended, error)
 	// Operations returns all the operations for this deployment.
@@ -59,9 +66,24 @@ func (s *ResourceGroupDeployment) ResourceGroupName() string {
 }
 
 func (s *ResourceGroupDeployment) Deploy(
-	ctx context.Context, template azure.RawArmTemplate, parameters azure.ArmParameters, tags map[string]*string,
+	ctx context.Context,
+	template azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+	tags map[string]*string,
+	options *azcli.DeployOptions,
 ) (*armresources.DeploymentExtended, error) {
-	return s.azCli.DeployToResourceGroup(ctx, s.subscriptionId, s.resourceGroupName, s.name, template, parameters, tags)
+	return s.azCli.DeployToResourceGroup(
+		ctx, s.subscriptionId, s.resourceGroupName, s.name, template, parameters, tags, options)
+}
+
+// WhatIf predicts the changes to the resources of the resource group deploying the template would make.
+func (s *ResourceGroupDeployment) WhatIf(
+	ctx context.Context,
+	template azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+) (*armresources.WhatIfOperationResult, error) {
+	return s.azCli.WhatIfDeployToResourceGroup(
+		ctx, s.subscriptionId, s.resourceGroupName, s.name, template, parameters)
 }
 
 // GetDeployment fetches the result of the most recent deployment.
@@ -150,9 +172,22 @@ func (s *SubscriptionDeployment) Location() string {
 
 // Deploy a given template with a set of parameters.
 func (s *SubscriptionDeployment) Deploy(
-	ctx context.Context, template azure.RawArmTemplate, parameters azure.ArmParameters, tags map[string]*string,
+	ctx context.Context,
+	template azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+	tags map[string]*string,
+	options *azcli.DeployOptions,
 ) (*armresources.DeploymentExtended, error) {
-	return s.azCli.DeployToSubscription(ctx, s.subscriptionId, s.location, s.name, template, parameters, tags)
+	return s.azCli.DeployToSubscription(ctx, s.subscriptionId, s.location, s.name, template, parameters, tags, options)
+}
+
+// WhatIf predicts the changes to the resources of the subscription deploying the template would make.
+func (s *SubscriptionDeployment) WhatIf(
+	ctx context.Context,
+	template azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+) (*armresources.WhatIfOperationResult, error) {
+	return s.azCli.WhatIfDeployToSubscription(ctx, s.subscriptionId, s.location, s.name, template, parameters)
 }
 
 // GetDeployment fetches the result of the most recent deployment.
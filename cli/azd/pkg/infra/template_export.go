@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+)
+
+const (
+	exportedLocationParameterName    = "location"
+	exportedEnvironmentParameterName = "environmentName"
+)
+
+// NormalizeExportedTemplate prepares an ARM template exported from a resource group to be used as an azd infrastructure
+// template:
+//   - An `environmentName` parameter is added and default values of the exported name parameters containing the name of
+//     the environment are derived from it, ex) "app-dev-web" becomes
+//     "[format('app-{0}-web', parameters('environmentName'))]"
+//   - A `location` parameter is added, defaulting to the location of the resource group, and resources deployed to the
+//     location of the resource group use it.
+func NormalizeExportedTemplate(
+	rawTemplate azure.RawArmTemplate,
+	envName string,
+	location string,
+) (azure.RawArmTemplate, error) {
+	var template map[string]any
+	if err := json.Unmarshal(rawTemplate, &template); err != nil {
+		return nil, fmt.Errorf("parsing exported template: %w", err)
+	}
+
+	parameters, _ := template["parameters"].(map[string]any)
+	if parameters == nil {
+		parameters = map[string]any{}
+		template["parameters"] = parameters
+	}
+
+	usesEnvName := false
+	for _, parameter := range parameters {
+		definition, ok := parameter.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		defaultValue, ok := definition["defaultValue"].(string)
+		if !ok || envName == "" || !strings.Contains(defaultValue, envName) || strings.HasPrefix(defaultValue, "[") {
+			continue
+		}
+
+		format := strings.ReplaceAll(strings.ReplaceAll(defaultValue, "'", "''"), envName, "{0}")
+		definition["defaultValue"] = fmt.Sprintf(
+			"[format('%s', parameters('%s'))]", format, exportedEnvironmentParameterName)
+		usesEnvName = true
+	}
+
+	if usesEnvName {
+		parameters[exportedEnvironmentParameterName] = map[string]any{
+			"type":         "string",
+			"defaultValue": envName,
+			"metadata": map[string]any{
+				"description": "Name of the environment, used to derive the names of the resources",
+			},
+		}
+	}
+
+	usesLocation := false
+	resources, _ := template["resources"].([]any)
+	for _, item := range resources {
+		resource, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		resourceLocation, ok := resource["location"].(string)
+		if !ok || !strings.EqualFold(normalizeExportedLocation(resourceLocation), normalizeExportedLocation(location)) {
+			continue
+		}
+
+		resource["location"] = fmt.Sprintf("[parameters('%s')]", exportedLocationParameterName)
+		usesLocation = true
+	}
+
+	if usesLocation {
+		parameters[exportedLocationParameterName] = map[string]any{
+			"type":         "string",
+			"defaultValue": "[resourceGroup().location]",
+			"metadata": map[string]any{
+				"description": "Primary location for all resources",
+			},
+		}
+	}
+
+	normalized, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling normalized template: %w", err)
+	}
+
+	return normalized, nil
+}
+
+// normalizeExportedLocation converts a location display name, ex) East US 2, to its name, ex) eastus2
+func normalizeExportedLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeExportedTemplate(t *testing.T) {
+	rawTemplate := azure.RawArmTemplate(`{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters": {
+			"sites_app_dev_web_name": { "type": "string", "defaultValue": "app-dev-web" },
+			"vaults_kv_name": { "type": "string", "defaultValue": "kv-shared" }
+		},
+		"resources": [
+			{
+				"type": "Microsoft.Web/sites",
+				"name": "[parameters('sites_app_dev_web_name')]",
+				"location": "East US 2"
+			},
+			{
+				"type": "Microsoft.KeyVault/vaults",
+				"name": "[parameters('vaults_kv_name')]",
+				"location": "westus"
+			}
+		]
+	}`)
+
+	normalized, err := NormalizeExportedTemplate(rawTemplate, "dev", "eastus2")
+	require.NoError(t, err)
+
+	var template struct {
+		Parameters map[string]struct {
+			DefaultValue string `json:"defaultValue"`
+		} `json:"parameters"`
+		Resources []struct {
+			Location string `json:"location"`
+		} `json:"resources"`
+	}
+	require.NoError(t, json.Unmarshal(normalized, &template))
+
+	require.Equal(t, "dev", template.Parameters["environmentName"].DefaultValue)
+	require.Equal(t, "[resourceGroup().location]", template.Parameters["location"].DefaultValue)
+	require.Equal(t,
+		"[format('app-{0}-web', parameters('environmentName'))]", template.Parameters["sites_app_dev_web_name"].DefaultValue)
+	require.Equal(t, "kv-shared", template.Parameters["vaults_kv_name"].DefaultValue)
+
+	require.Equal(t, "[parameters('location')]", template.Resources[0].Location)
+	require.Equal(t, "westus", template.Resources[1].Location)
+}
+
+func TestNormalizeExportedTemplateWithoutEnvironmentName(t *testing.T) {
+	rawTemplate := azure.RawArmTemplate(`{
+		"parameters": { "storage_name": { "type": "string", "defaultValue": "stshared" } },
+		"resources": [{ "type": "Microsoft.Storage/storageAccounts", "location": "centralus" }]
+	}`)
+
+	normalized, err := NormalizeExportedTemplate(rawTemplate, "dev", "eastus2")
+	require.NoError(t, err)
+
+	var template struct {
+		Parameters map[string]any `json:"parameters"`
+	}
+	require.NoError(t, json.Unmarshal(normalized, &template))
+	require.NotContains(t, template.Parameters, "environmentName")
+	require.NotContains(t, template.Parameters, "location")
+}
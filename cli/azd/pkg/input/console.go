@@ -21,6 +21,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/azure/azure-dev/cli/azd/pkg/runs"
 	"github.com/mattn/go-isatty"
 	"github.com/nathan-fiscaletti/consolesize-go"
 	"github.com/theckman/yacspin"
@@ -136,8 +138,15 @@ func (c *AskerConsole) IsUnformatted() bool {
 
 // Prints out a message to the underlying console write
 func (c *AskerConsole) Message(ctx context.Context, message string) {
+	message = redact.String(message)
+
 	// Disable output when formatting is enabled
-	if c.formatter != nil && c.formatter.Kind() == output.JsonFormat {
+	if c.formatter != nil && c.formatter.Kind().IsStructured() {
+		if !c.jsonEvents() {
+			log.Println(message)
+			return
+		}
+
 		// we call json.Marshal directly, because the formatter marshalls using indentation, and we would prefer
 		// these objects be written on a single line.
 		jsonMessage, err := json.Marshal(output.EventForMessage(message))
@@ -152,6 +161,12 @@ func (c *AskerConsole) Message(ctx context.Context, message string) {
 	}
 }
 
+// jsonEvents returns true when the messages are written as json events, one per line. The other structured formats,
+// ex) yaml, can't be mixed with events, their messages are only logged.
+func (c *AskerConsole) jsonEvents() bool {
+	return c.formatter.Kind() == output.JsonFormat
+}
+
 func (c *AskerConsole) WarnForFeature(ctx context.Context, key alpha.FeatureId) {
 	if shouldWarn(key) {
 		c.MessageUxItem(ctx, &ux.MultilineMessage{
@@ -174,21 +189,26 @@ func shouldWarn(key alpha.FeatureId) bool {
 }
 
 func (c *AskerConsole) MessageUxItem(ctx context.Context, item ux.UxItem) {
-	if c.formatter != nil && c.formatter.Kind() == output.JsonFormat {
-		// no need to check the spinner for json format, as the spinner won't start when using json format
+	if c.formatter != nil && c.formatter.Kind().IsStructured() {
+		// no need to check the spinner for structured formats, as the spinner won't start when using them
 		// instead, there would be a message about starting spinner
+		if !c.jsonEvents() {
+			log.Println(redact.String(item.ToString(c.currentIndent)))
+			return
+		}
+
 		json, _ := json.Marshal(item)
-		fmt.Fprintln(c.writer, string(json))
+		fmt.Fprintln(c.writer, redact.String(string(json)))
 		return
 	}
 
 	if c.spinner != nil && c.spinner.Status() == yacspin.SpinnerRunning {
 		c.StopSpinner(ctx, "", Step)
 		// default non-format
-		fmt.Fprintln(c.writer, item.ToString(c.currentIndent))
+		fmt.Fprintln(c.writer, redact.String(item.ToString(c.currentIndent)))
 		_ = c.spinner.Start()
 	} else {
-		fmt.Fprintln(c.writer, item.ToString(c.currentIndent))
+		fmt.Fprintln(c.writer, redact.String(item.ToString(c.currentIndent)))
 	}
 }
 
@@ -205,8 +225,10 @@ func (c *AskerConsole) spinnerText(title, charset string) string {
 }
 
 func (c *AskerConsole) ShowSpinner(ctx context.Context, title string, format SpinnerUxType) {
-	if c.formatter != nil && c.formatter.Kind() == output.JsonFormat {
-		// Spinner is disabled when using json format.
+	title = redact.String(title)
+
+	if c.formatter != nil && c.formatter.Kind().IsStructured() {
+		// Spinner is disabled when using structured formats, ex) json.
 		return
 	}
 
@@ -317,8 +339,10 @@ func (c *AskerConsole) getIndent(format SpinnerUxType) string {
 }
 
 func (c *AskerConsole) StopSpinner(ctx context.Context, lastMessage string, format SpinnerUxType) {
-	if c.formatter != nil && c.formatter.Kind() == output.JsonFormat {
-		// Spinner is disabled when using json format.
+	recordStep(ctx, lastMessage, format)
+
+	if c.formatter != nil && c.formatter.Kind().IsStructured() {
+		// Spinner is disabled when using structured formats, ex) json.
 		return
 	}
 
@@ -338,7 +362,7 @@ func (c *AskerConsole) StopSpinner(ctx context.Context, lastMessage string, form
 		c.spinner.StopCharacter(c.getStopChar(format))
 	}
 
-	c.spinner.StopMessage(lastMessage)
+	c.spinner.StopMessage(redact.String(lastMessage))
 	_ = c.spinner.Stop()
 }
 
@@ -489,6 +513,28 @@ func NewConsole(noPrompt bool, isTerminal bool, w io.Writer, handles ConsoleHand
 	}
 }
 
+// statuses of the steps recorded in the run, by the format the spinner was stopped with
+var stepStatuses = map[SpinnerUxType]runs.Status{
+	StepDone:    runs.StatusSucceeded,
+	StepFailed:  runs.StatusFailed,
+	StepWarning: runs.StatusWarning,
+	StepSkipped: runs.StatusSkipped,
+}
+
+// recordStep records the completed step in the run of ctx, if any
+func recordStep(ctx context.Context, lastMessage string, format SpinnerUxType) {
+	status, has := stepStatuses[format]
+	if !has || lastMessage == "" {
+		return
+	}
+
+	runs.Record(ctx, &runs.Event{
+		Type:   runs.StepEvent,
+		Name:   lastMessage,
+		Status: status,
+	})
+}
+
 func GetStepResultFormat(result error) SpinnerUxType {
 	formatResult := StepDone
 	if result != nil {
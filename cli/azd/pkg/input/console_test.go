@@ -4,8 +4,13 @@
 package input
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,3 +43,39 @@ func Test_consoleTruncate(t *testing.T) {
 		require.Equal(t, expected, produced)
 	})
 }
+
+func Test_consoleRedactsSecrets(t *testing.T) {
+	redact.AddSecret("C0nsoleS3cret")
+
+	t.Run("Text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		console := NewConsole(true, false, buf, ConsoleHandles{}, &output.NoneFormatter{})
+
+		console.Message(context.Background(), "password: C0nsoleS3cret")
+		console.MessageUxItem(context.Background(), &ux.MultilineMessage{Lines: []string{"token: C0nsoleS3cret"}})
+
+		require.Equal(t, "password: <redacted>\ntoken: <redacted>\n", buf.String())
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		console := NewConsole(true, false, buf, ConsoleHandles{}, &output.JsonFormatter{})
+
+		console.Message(context.Background(), "password: C0nsoleS3cret")
+		console.MessageUxItem(context.Background(), &ux.MultilineMessage{Lines: []string{"token: C0nsoleS3cret"}})
+
+		require.NotContains(t, buf.String(), "C0nsoleS3cret")
+		require.Contains(t, buf.String(), "redacted")
+	})
+}
+
+func Test_consoleStructuredFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	console := NewConsole(true, false, buf, ConsoleHandles{}, &output.YamlFormatter{})
+
+	// Messages can't be mixed with the yaml written to the output, they're only logged
+	console.Message(context.Background(), "deploying")
+	console.MessageUxItem(context.Background(), &ux.MultilineMessage{Lines: []string{"deployed"}})
+
+	require.Empty(t, buf.String())
+}
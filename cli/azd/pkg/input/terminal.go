@@ -0,0 +1,60 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+var errNotTerminal = errors.New("not attached to a terminal")
+
+// IsTerminal returns whether both the input and the output handles are attached to a terminal, ex) when running a
+// remote shell.
+func (h ConsoleHandles) IsTerminal() bool {
+	_, inOk := terminalFd(h.Stdin)
+	_, outOk := terminalFd(h.Stdout)
+
+	return inOk && outOk
+}
+
+// MakeRaw puts the terminal of the input handle into raw mode, forwarding every key press, ex) Ctrl+C, as input.
+// The returned function restores the previous mode of the terminal.
+func (h ConsoleHandles) MakeRaw() (restore func(), err error) {
+	fd, ok := terminalFd(h.Stdin)
+	if !ok {
+		return nil, errNotTerminal
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("setting terminal raw mode: %w", err)
+	}
+
+	return func() {
+		_ = term.Restore(fd, state)
+	}, nil
+}
+
+// TerminalSize returns the width and height, in characters, of the terminal of the output handle.
+func (h ConsoleHandles) TerminalSize() (width int, height int, err error) {
+	fd, ok := terminalFd(h.Stdout)
+	if !ok {
+		return 0, 0, errNotTerminal
+	}
+
+	return term.GetSize(fd)
+}
+
+func terminalFd(handle any) (int, bool) {
+	file, ok := handle.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0, false
+	}
+
+	return int(file.Fd()), true
+}
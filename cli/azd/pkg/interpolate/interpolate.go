@@ -0,0 +1,233 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package interpolate substitutes the ${VAR} references of azure.yaml and of the k8s manifests. It extends the
+// substitutions of envsubst with:
+//   - defaults evaluated only when the variable is empty, ex) ${VAR:-${OTHER:-fallback}}
+//   - references in the names of variables, ex) ${SECRET_${AZURE_ENV_NAME}}
+//   - functions, ex) ${lower(VAR)}, ${base64(trim(VAR))} or ${lower(VAR:-Fallback)}
+//   - a strict mode, enabled with AZD_STRICT_SUBSTITUTION, failing on references which resolve to empty strings
+//
+// The other substitutions of envsubst, ex) ${VAR,,} or ${VAR/from/to}, are evaluated by envsubst.
+package interpolate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/drone/envsubst"
+)
+
+// StrictEnvVarName enables the strict mode, in which references resolving to empty strings without a default are errors
+// instead of being substituted with empty strings. It's looked up with the mapping of the values, ex) the values of the
+// environment.
+const StrictEnvVarName = "AZD_STRICT_SUBSTITUTION"
+
+// The functions of the references, ex) ${lower(VAR)}
+var functions = map[string]func(string) (string, error){
+	"lower": func(value string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+	"upper": func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"trim": func(value string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"base64": func(value string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	},
+}
+
+var functionRegex = regexp.MustCompile(`(?s)^([A-Za-z][A-Za-z0-9]*)\((.*)\)$`)
+
+// The name the variable of the substitutions evaluated by envsubst is replaced with, for the names envsubst can't parse,
+// ex) the names resolved from references or the paths of outputs
+const envsubstParam = "AZD_INTERPOLATE_PARAM"
+
+// UnresolvedReferencesError is returned in strict mode when references resolve to empty strings
+type UnresolvedReferencesError struct {
+	Names []string
+}
+
+func (e *UnresolvedReferencesError) Error() string {
+	references := make([]string, len(e.Names))
+	for i, name := range e.Names {
+		references[i] = fmt.Sprintf("${%s}", name)
+	}
+
+	return fmt.Sprintf(
+		"unresolved references %s, set them in the environment or give them a default, ex) ${%s:-default}",
+		strings.Join(references, ", "), e.Names[0])
+}
+
+// Eval substitutes the references of the template with the values of mapping, like [envsubst.Eval]. In strict mode, see
+// [StrictEnvVarName], the references resolving to empty strings return an [UnresolvedReferencesError].
+func Eval(template string, mapping func(string) string) (string, error) {
+	strict, _ := strconv.ParseBool(mapping(StrictEnvVarName))
+
+	e := &evaluator{mapping: mapping, strict: strict}
+	result, err := e.evalTemplate(template)
+	if err != nil {
+		return template, err
+	}
+
+	if len(e.unresolved) > 0 {
+		return template, &UnresolvedReferencesError{Names: e.unresolved}
+	}
+
+	return result, nil
+}
+
+type evaluator struct {
+	mapping    func(string) string
+	strict     bool
+	unresolved []string
+}
+
+// evalTemplate substitutes the references of the text, with the escapes of envsubst: $$ for $, \\ for \ and \/ for /
+func (e *evaluator) evalTemplate(template string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(template); {
+		switch {
+		case strings.HasPrefix(template[i:], "$$"):
+			sb.WriteByte('$')
+			i += 2
+		case strings.HasPrefix(template[i:], `\\`), strings.HasPrefix(template[i:], `\/`):
+			sb.WriteByte(template[i+1])
+			i += 2
+		case strings.HasPrefix(template[i:], "${"):
+			end := closingBrace(template, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("missing closing brace of '%s'", template[i:])
+			}
+
+			value, err := e.evalExpression(template[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(value)
+			i = end + 1
+		default:
+			sb.WriteByte(template[i])
+			i++
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// evalExpression evaluates the expression of a reference, ex) VAR:-fallback for ${VAR:-fallback}
+func (e *evaluator) evalExpression(expression string) (string, error) {
+	if match := functionRegex.FindStringSubmatch(expression); match != nil {
+		function, has := functions[match[1]]
+		if !has {
+			return "", fmt.Errorf("unknown function '%s' of '${%s}', supported functions are lower, upper, trim and base64",
+				match[1], expression)
+		}
+
+		value, err := e.evalExpression(match[2])
+		if err != nil {
+			return "", err
+		}
+
+		return function(value)
+	}
+
+	nameEnd := nameLength(expression)
+	if nameEnd == 0 {
+		// ex) the length of the variable, ${#VAR}
+		return e.envsubst(expression, "", "")
+	}
+
+	name, err := e.evalTemplate(expression[:nameEnd])
+	if err != nil {
+		return "", err
+	}
+
+	value := e.mapping(name)
+	operation := expression[nameEnd:]
+	switch {
+	case operation == "":
+	case strings.HasPrefix(operation, ":-"), strings.HasPrefix(operation, ":="):
+		// The default is only evaluated when it's used, its references aren't unresolved otherwise
+		if value != "" {
+			return value, nil
+		}
+
+		return e.evalTemplate(operation[2:])
+	default:
+		value, err = e.envsubst(operation, name, value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if value == "" && e.strict {
+		e.unresolved = append(e.unresolved, name)
+	}
+
+	return value, nil
+}
+
+// envsubst evaluates the operation of envsubst on the value of the variable, ex) ,, to lower the value
+func (e *evaluator) envsubst(operation string, name string, value string) (string, error) {
+	if name == "" {
+		return envsubst.Eval(fmt.Sprintf("${%s}", operation), e.mapping)
+	}
+
+	return envsubst.Eval(fmt.Sprintf("${%s%s}", envsubstParam, operation), func(n string) string {
+		if n == envsubstParam {
+			return value
+		}
+
+		return e.mapping(n)
+	})
+}
+
+// closingBrace returns the index of the brace closing the reference starting at start, -1 when it isn't closed. The
+// references nested in the reference are skipped, ex) ${VAR:-${OTHER}}.
+func closingBrace(template string, start int) int {
+	depth := 0
+	for i := start; i < len(template); i++ {
+		switch {
+		case strings.HasPrefix(template[i:], "${"):
+			depth++
+			i++
+		case template[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// nameLength returns the length of the name of the variable the expression starts with. Names are made of letters,
+// digits, underscores, the dots and indexes of the paths of outputs, ex) outputs.endpoints[0].url, and references, ex)
+// SECRET_${AZURE_ENV_NAME}.
+func nameLength(expression string) int {
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch {
+		case c == '_' || c == '.' || c == '[' || c == ']' ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+		case strings.HasPrefix(expression[i:], "${"):
+			end := closingBrace(expression, i+2)
+			if end < 0 {
+				return i
+			}
+			i = end
+		default:
+			return i
+		}
+	}
+
+	return len(expression)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package interpolate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	values := map[string]string{
+		"AZURE_ENV_NAME":       "Dev",
+		"SECRET_Dev":           "s3cr3t",
+		"PADDED":               "  Contoso  ",
+		"outputs.endpoints[0]": "https://contoso.com",
+	}
+	mapping := func(name string) string {
+		return values[name]
+	}
+
+	tests := map[string]struct {
+		template string
+		expected string
+	}{
+		"Reference":            {template: "rg-${AZURE_ENV_NAME}", expected: "rg-Dev"},
+		"Missing":              {template: "rg-${MISSING}", expected: "rg-"},
+		"NotReference":         {template: "$AZURE_ENV_NAME", expected: "$AZURE_ENV_NAME"},
+		"Default":              {template: "${MISSING:-fallback}", expected: "fallback"},
+		"DefaultNotUsed":       {template: "${AZURE_ENV_NAME:-fallback}", expected: "Dev"},
+		"AssignDefault":        {template: "${MISSING:=fallback}", expected: "fallback"},
+		"NestedDefault":        {template: "${MISSING:-${OTHER:-${AZURE_ENV_NAME}}}", expected: "Dev"},
+		"DefaultWithColon":     {template: "${MISSING:-http://localhost:8080}", expected: "http://localhost:8080"},
+		"NestedName":           {template: "${SECRET_${AZURE_ENV_NAME}}", expected: "s3cr3t"},
+		"Lower":                {template: "${lower(AZURE_ENV_NAME)}", expected: "dev"},
+		"Upper":                {template: "${upper(AZURE_ENV_NAME)}", expected: "DEV"},
+		"Trim":                 {template: "[${trim(PADDED)}]", expected: "[Contoso]"},
+		"Base64":               {template: "${base64(SECRET_Dev)}", expected: "czNjcjN0"},
+		"ComposedFunctions":    {template: "${base64(lower(trim(PADDED)))}", expected: "Y29udG9zbw=="},
+		"FunctionOfDefault":    {template: "${lower(MISSING:-FALLBACK)}", expected: "fallback"},
+		"FunctionInDefault":    {template: "${MISSING:-${lower(AZURE_ENV_NAME)}}", expected: "dev"},
+		"OutputPath":           {template: "${outputs.endpoints[0]}/api", expected: "https://contoso.com/api"},
+		"EnvsubstLower":        {template: "${AZURE_ENV_NAME,,}", expected: "dev"},
+		"EnvsubstReplace":      {template: "${PADDED// /}", expected: "Contoso"},
+		"EnvsubstOfNestedName": {template: "${SECRET_${AZURE_ENV_NAME}^^}", expected: "S3CR3T"},
+		"EnvsubstLength":       {template: "${#AZURE_ENV_NAME}", expected: "3"},
+		"EscapedDollar":        {template: "$${AZURE_ENV_NAME}", expected: "${AZURE_ENV_NAME}"},
+		"EscapedSlash":         {template: `a\/b\\c`, expected: `a/b\c`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := Eval(test.template, mapping)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	mapping := func(name string) string {
+		return ""
+	}
+
+	tests := map[string]struct {
+		template string
+		expected string
+	}{
+		"MissingClosingBrace": {template: "${VAR", expected: "missing closing brace of '${VAR'"},
+		"UnknownFunction": {
+			template: "${reverse(VAR)}",
+			expected: "unknown function 'reverse' of '${reverse(VAR)}', supported functions are lower, upper, trim and base64",
+		},
+		"EmptyReference": {template: "${}", expected: "unable to parse variable name"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Eval(test.template, mapping)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestEvalStrict(t *testing.T) {
+	values := map[string]string{
+		StrictEnvVarName: "true",
+		"AZURE_ENV_NAME": "dev",
+		"EMPTY":          "",
+	}
+	mapping := func(name string) string {
+		return values[name]
+	}
+
+	result, err := Eval("${AZURE_ENV_NAME}-${MISSING:-fallback}-${EMPTY:-}-${AZURE_ENV_NAME:-${IGNORED}}", mapping)
+	require.NoError(t, err)
+	require.Equal(t, "dev-fallback--dev", result)
+
+	_, err = Eval("${MISSING}-${lower(EMPTY)}-${OTHER:-${UNSET}}", mapping)
+	require.EqualError(t, err, "unresolved references ${MISSING}, ${EMPTY}, ${UNSET}, set them in the environment or "+
+		"give them a default, ex) ${MISSING:-default}")
+
+	var unresolvedErr *UnresolvedReferencesError
+	require.ErrorAs(t, err, &unresolvedErr)
+	require.Equal(t, []string{"MISSING", "EMPTY", "UNSET"}, unresolvedErr.Names)
+
+	// Strict mode is off by default
+	delete(values, StrictEnvVarName)
+	result, err = Eval("${MISSING}-${AZURE_ENV_NAME}", mapping)
+	require.NoError(t, err)
+	require.Equal(t, "-dev", result)
+}
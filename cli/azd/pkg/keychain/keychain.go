@@ -0,0 +1,29 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package keychain stores secrets in the keychain of the OS: the login keychain on macOS, the Secret Service through
+// libsecret on Linux, and files protected with DPAPI for the current user on Windows.
+package keychain
+
+import (
+	"errors"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+)
+
+// ErrNotFound is returned when the keychain has no secret for a service and account.
+var ErrNotFound = errors.New("secret not found in the keychain")
+
+// Keychain stores secrets, identified by a service and an account, in the keychain of the OS.
+type Keychain interface {
+	// Get returns the secret of the service and account, or ErrNotFound.
+	Get(service string, account string) ([]byte, error)
+	// Set stores the secret of the service and account, replacing the existing secret.
+	Set(service string, account string, secret []byte) error
+}
+
+// New returns the keychain of the OS. The tools of the keychain, ex) security on macOS or secret-tool on Linux, are run
+// with commandRunner.
+func New(commandRunner exec.CommandRunner) Keychain {
+	return newKeychain(commandRunner)
+}
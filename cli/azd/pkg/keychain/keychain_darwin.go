@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build darwin
+// +build darwin
+
+package keychain
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+)
+
+// The exit code of security when the keychain has no item for the service and account
+const securityItemNotFoundExitCode = 44
+
+// macosKeychain stores secrets as generic passwords of the login keychain, with the security tool
+type macosKeychain struct {
+	commandRunner exec.CommandRunner
+}
+
+func newKeychain(commandRunner exec.CommandRunner) Keychain {
+	return &macosKeychain{
+		commandRunner: commandRunner,
+	}
+}
+
+func (k *macosKeychain) Get(service string, account string) ([]byte, error) {
+	// The secret is written to a buffer, rather than captured in the result, to never be logged
+	stdout := &bytes.Buffer{}
+	result, err := k.commandRunner.Run(context.Background(),
+		exec.NewRunArgs("security", "find-generic-password", "-s", service, "-a", account, "-w").WithStdOut(stdout))
+	if result.ExitCode == securityItemNotFoundExitCode {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("reading the keychain: %w", err)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret of the keychain: %w", err)
+	}
+
+	return secret, nil
+}
+
+func (k *macosKeychain) Set(service string, account string, secret []byte) error {
+	// The commands are read from the standard input in interactive mode, for the secret not to be in the arguments of
+	// the process
+	command := fmt.Sprintf("add-generic-password -U -s %q -a %q -w %q\n",
+		service, account, base64.StdEncoding.EncodeToString(secret))
+
+	if _, err := k.commandRunner.Run(context.Background(),
+		exec.NewRunArgs("security", "-i").WithStdIn(strings.NewReader(command))); err != nil {
+		return fmt.Errorf("writing the keychain: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build unix && !darwin
+// +build unix,!darwin
+
+package keychain
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+)
+
+// secretServiceKeychain stores secrets in the Secret Service, ex) GNOME Keyring or KWallet, with secret-tool of libsecret
+type secretServiceKeychain struct {
+	commandRunner exec.CommandRunner
+}
+
+func newKeychain(commandRunner exec.CommandRunner) Keychain {
+	return &secretServiceKeychain{
+		commandRunner: commandRunner,
+	}
+}
+
+func (k *secretServiceKeychain) Get(service string, account string) ([]byte, error) {
+	// The secret is written to a buffer, rather than captured in the result, to never be logged
+	stdout := &bytes.Buffer{}
+	result, err := k.commandRunner.Run(context.Background(),
+		exec.NewRunArgs("secret-tool", "lookup", "service", service, "account", account).WithStdOut(stdout))
+	if err != nil && result.ExitCode == 1 && stdout.Len() == 0 {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, secretToolError("reading", err)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret of the keychain: %w", err)
+	}
+
+	return secret, nil
+}
+
+func (k *secretServiceKeychain) Set(service string, account string, secret []byte) error {
+	// The secret is read from the standard input, for it not to be in the arguments of the process
+	runArgs := exec.NewRunArgs(
+		"secret-tool", "store", "--label", fmt.Sprintf("%s %s", service, account), "service", service, "account", account,
+	).WithStdIn(strings.NewReader(base64.StdEncoding.EncodeToString(secret)))
+
+	if _, err := k.commandRunner.Run(context.Background(), runArgs); err != nil {
+		return secretToolError("writing", err)
+	}
+
+	return nil
+}
+
+func secretToolError(operation string, err error) error {
+	if errors.Is(err, osexec.ErrNotFound) {
+		return fmt.Errorf(
+			"%s the keychain: secret-tool is required, install libsecret-tools or the libsecret package of your "+
+				"distribution: %w", operation, err)
+	}
+
+	return fmt.Errorf("%s the keychain: %w", operation, err)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build unix && !darwin
+// +build unix,!darwin
+
+package keychain
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockexec"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SecretServiceKeychain(t *testing.T) {
+	commandRunner := mockexec.NewMockCommandRunner()
+	stored := ""
+
+	commandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.HasPrefix(command, "secret-tool store")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		contents, err := io.ReadAll(args.StdIn)
+		stored = string(contents)
+		return exec.NewRunResult(0, "", ""), err
+	})
+
+	commandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.HasPrefix(command, "secret-tool lookup")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		if stored == "" {
+			return exec.NewRunResult(1, "", ""), errors.New("exit code: 1")
+		}
+
+		_, err := args.Stdout.Write([]byte(stored + "\n"))
+		return exec.NewRunResult(0, "", ""), err
+	})
+
+	keychain := New(commandRunner)
+
+	_, err := keychain.Get("azd", "key")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, keychain.Set("azd", "key", []byte{1, 2, 3}))
+	require.NotContains(t, stored, "\x01")
+
+	secret, err := keychain.Get("azd", "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, secret)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build windows
+// +build windows
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"golang.org/x/sys/windows"
+)
+
+// dpapiKeychain stores secrets in files of the azd config directory, encrypted for the current user with
+// CryptProtectData. See https://learn.microsoft.com/windows/win32/api/dpapi/nf-dpapi-cryptprotectdata
+type dpapiKeychain struct {
+}
+
+func newKeychain(_ exec.CommandRunner) Keychain {
+	return &dpapiKeychain{}
+}
+
+func (k *dpapiKeychain) Get(service string, account string) ([]byte, error) {
+	path, err := secretPath(service, account)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(encrypted) == 0) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("reading the keychain: %w", err)
+	}
+
+	encryptedBlob := windows.DataBlob{
+		Size: uint32(len(encrypted)),
+		Data: &encrypted[0],
+	}
+	var plaintext windows.DataBlob
+
+	if err := windows.CryptUnprotectData(&encryptedBlob, nil, nil, uintptr(0), nil, 0, &plaintext); err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	secret := make([]byte, plaintext.Size)
+	copy(secret, unsafe.Slice(plaintext.Data, plaintext.Size))
+
+	if _, err := windows.LocalFree(windows.Handle(unsafe.Pointer(plaintext.Data))); err != nil {
+		return nil, fmt.Errorf("failed to free decrypted secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+func (k *dpapiKeychain) Set(service string, account string, secret []byte) error {
+	if len(secret) == 0 {
+		return errors.New("secret is empty")
+	}
+
+	path, err := secretPath(service, account)
+	if err != nil {
+		return err
+	}
+
+	plaintext := windows.DataBlob{
+		Size: uint32(len(secret)),
+		Data: &secret[0],
+	}
+	var encryptedBlob windows.DataBlob
+
+	if err := windows.CryptProtectData(&plaintext, nil, nil, uintptr(0), nil, 0, &encryptedBlob); err != nil {
+		return fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	encrypted := make([]byte, encryptedBlob.Size)
+	copy(encrypted, unsafe.Slice(encryptedBlob.Data, encryptedBlob.Size))
+
+	if _, err := windows.LocalFree(windows.Handle(unsafe.Pointer(encryptedBlob.Data))); err != nil {
+		return fmt.Errorf("failed to free encrypted secret: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("writing the keychain: %w", err)
+	}
+
+	if err := os.WriteFile(path, encrypted, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing the keychain: %w", err)
+	}
+
+	return nil
+}
+
+// secretPath returns the path of the file of the secret of the service and account, under the azd config directory
+func secretPath(service string, account string) (string, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "keychain", fmt.Sprintf("%s.%s.bin", service, account)), nil
+}
@@ -0,0 +1,362 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package loadtest runs the load test of a project on Azure Load Testing against its deployed services, and reports
+// whether the test passed its failure criteria.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// defaultPollInterval is how often the status of test runs is polled
+const defaultPollInterval = 10 * time.Second
+
+// testIdMaxLength is the maximum length of the ids of tests
+const testIdMaxLength = 50
+
+// criterionRegex matches failure criteria, ex) avg(response_time_ms) > 500
+var criterionRegex = regexp.MustCompile(`^\s*(\w+)\s*\(\s*(\w+)\s*\)\s*([<>])\s*([0-9]+(?:\.[0-9]+)?)\s*$`)
+
+// invalidTestIdRegex matches the characters not allowed in the ids of tests
+var invalidTestIdRegex = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// Criterion is the result of a failure criterion of a test run.
+type Criterion struct {
+	// The criterion as declared in azure.yaml, ex) avg(response_time_ms) > 500
+	Criterion string `json:"criterion"`
+	// The value of the metric in the test run, unset when the metric couldn't be computed
+	ActualValue *float64 `json:"actualValue,omitempty"`
+	// passed, failed or undetermined
+	Result string `json:"result"`
+}
+
+// Result is the result of a run of the load test.
+type Result struct {
+	TestId    string `json:"testId"`
+	TestRunId string `json:"testRunId"`
+	// The final status of the run, ex) DONE, FAILED or CANCELLED
+	Status string `json:"status"`
+	// PASSED, FAILED or NOT_APPLICABLE without criteria
+	TestResult string `json:"testResult"`
+	// The statistics of all the requests of the run
+	Statistics *azcli.AzCliLoadTestStatistics `json:"statistics,omitempty"`
+	Criteria   []Criterion                    `json:"criteria,omitempty"`
+	// The errors failing the run, when it didn't complete
+	Errors    []string `json:"errors,omitempty"`
+	PortalUrl string   `json:"portalUrl,omitempty"`
+}
+
+// Passed reports whether the run completed, and passed its failure criteria.
+func (r *Result) Passed() bool {
+	return r.Status == "DONE" && r.TestResult != "FAILED"
+}
+
+// Manager runs the load test of a project.
+type Manager struct {
+	env                *environment.Environment
+	azCli              azcli.AzCli
+	resourceManager    project.ResourceManager
+	loadTestingService azcli.LoadTestingService
+	// How often the status of test runs is polled
+	pollInterval time.Duration
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+	loadTestingService azcli.LoadTestingService,
+) *Manager {
+	return &Manager{
+		env:                env,
+		azCli:              azCli,
+		resourceManager:    resourceManager,
+		loadTestingService: loadTestingService,
+		pollInterval:       defaultPollInterval,
+	}
+}
+
+// Run creates, or updates, the load test of the project on the Azure Load Testing resource, runs it and waits for the
+// run to complete. endpoints are the endpoints of the deployed services, by name, set on the test plan as the
+// SERVICE_<NAME>_ENDPOINT environment variables, along with the env of the load test. The test is named after the
+// project and the environment, so that its runs can be compared over time.
+func (m *Manager) Run(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	endpoints map[string]string,
+) (*Result, error) {
+	config := projectConfig.LoadTest
+	if config == nil {
+		return nil, errors.New("no load test is configured in azure.yaml")
+	}
+
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	kind, err := testKind(config.TestPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria, err := ParseCriteria(config.FailureCriteria)
+	if err != nil {
+		return nil, err
+	}
+
+	testPlanPath := config.TestPlan
+	if !filepath.IsAbs(testPlanPath) {
+		testPlanPath = filepath.Join(projectConfig.Path, testPlanPath)
+	}
+
+	testPlan, err := os.ReadFile(testPlanPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading test plan: %w", err)
+	}
+
+	variables, err := m.variables(config, endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	loadTestId, err := m.loadTestResource(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPlaneUri, err := m.loadTestingService.GetDataPlaneUri(ctx, subscriptionId, loadTestId)
+	if err != nil {
+		return nil, err
+	}
+
+	testId := TestId(projectConfig.Name, m.env.GetEnvName())
+	engineInstances := config.EngineInstances
+	if engineInstances < 1 {
+		engineInstances = 1
+	}
+
+	if err := m.loadTestingService.CreateOrUpdateTest(ctx, subscriptionId, dataPlaneUri, testId, azcli.AzCliLoadTest{
+		DisplayName:          fmt.Sprintf("%s (%s)", projectConfig.Name, m.env.GetEnvName()),
+		Kind:                 kind,
+		EngineInstances:      engineInstances,
+		EnvironmentVariables: variables,
+		FailureCriteria:      criteria,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := m.loadTestingService.UploadTestScript(
+		ctx, subscriptionId, dataPlaneUri, testId, kind, filepath.Base(testPlanPath), testPlan); err != nil {
+		return nil, err
+	}
+
+	runTime := time.Now()
+	testRunId := fmt.Sprintf("%s-%d", testId, runTime.Unix())
+	if err := m.loadTestingService.StartTestRun(
+		ctx,
+		subscriptionId,
+		dataPlaneUri,
+		testId,
+		testRunId,
+		fmt.Sprintf("azd deploy %s", runTime.UTC().Format(time.RFC3339)),
+	); err != nil {
+		return nil, err
+	}
+
+	for {
+		testRun, err := m.loadTestingService.GetTestRun(ctx, subscriptionId, dataPlaneUri, testRunId)
+		if err != nil {
+			return nil, err
+		}
+
+		if testRun.IsTerminal() {
+			return newResult(testId, testRun, config.FailureCriteria), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.pollInterval):
+		}
+	}
+}
+
+// variables returns the environment variables of the test plan: the endpoints of the services, and the env of the
+// load test
+func (m *Manager) variables(config *project.LoadTestConfig, endpoints map[string]string) (map[string]string, error) {
+	variables := map[string]string{}
+	for name, endpoint := range endpoints {
+		key := fmt.Sprintf("SERVICE_%s_ENDPOINT", strings.ReplaceAll(strings.ToUpper(name), "-", "_"))
+		variables[key] = endpoint
+	}
+
+	for name, value := range config.Env {
+		expanded, err := value.Envsubst(m.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding env '%s' of the load test: %w", name, err)
+		}
+
+		variables[name] = expanded
+	}
+
+	return variables, nil
+}
+
+// loadTestResource returns the id of the Azure Load Testing resource of the resource group of the environment, the
+// resource named in azure.yaml or the only resource
+func (m *Manager) loadTestResource(
+	ctx context.Context,
+	subscriptionId string,
+	projectConfig *project.ProjectConfig,
+) (string, error) {
+	resourceGroupName, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return "", fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	name, err := projectConfig.LoadTest.Resource.Envsubst(m.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding resource of the load test: %w", err)
+	}
+
+	filter := fmt.Sprintf("resourceType eq '%s'", infra.AzureResourceTypeLoadTest)
+	resources, err := m.azCli.ListResourceGroupResources(
+		ctx,
+		subscriptionId,
+		resourceGroupName,
+		&azcli.ListResourceGroupResourcesOptions{
+			Filter: &filter,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("listing Azure Load Testing resources: %w", err)
+	}
+
+	for _, resource := range resources {
+		if strings.EqualFold(resource.Name, name) || (name == "" && len(resources) == 1) {
+			return resource.Id, nil
+		}
+	}
+
+	switch {
+	case name != "":
+		return "", fmt.Errorf(
+			"no Azure Load Testing resource named '%s' in resource group '%s'", name, resourceGroupName)
+	case len(resources) == 0:
+		return "", fmt.Errorf("no Azure Load Testing resource found in resource group '%s'", resourceGroupName)
+	default:
+		return "", fmt.Errorf(
+			"resource group '%s' has %d Azure Load Testing resources, set the name of the one to use with "+
+				"loadTest.resource in azure.yaml",
+			resourceGroupName, len(resources))
+	}
+}
+
+// TestId returns the id of the load test of the environment of the project, ex) todo-dev
+func TestId(projectName string, envName string) string {
+	testId := invalidTestIdRegex.ReplaceAllString(strings.ToLower(fmt.Sprintf("%s-%s", projectName, envName)), "-")
+	if len(testId) > testIdMaxLength {
+		testId = testId[:testIdMaxLength]
+	}
+
+	return strings.Trim(testId, "-_")
+}
+
+// ParseCriteria parses the failure criteria of azure.yaml, ex) avg(response_time_ms) > 500, into the criteria of the
+// test, keyed by their position.
+func ParseCriteria(criteria []string) (map[string]azcli.AzCliLoadTestCriterion, error) {
+	parsed := map[string]azcli.AzCliLoadTestCriterion{}
+	for i, criterion := range criteria {
+		matches := criterionRegex.FindStringSubmatch(criterion)
+		if matches == nil {
+			return nil, fmt.Errorf(
+				"invalid load test failure criterion '%s', expected <aggregate>(<metric>) <condition> <value>, "+
+					"ex) avg(response_time_ms) > 500",
+				criterion)
+		}
+
+		value, err := strconv.ParseFloat(matches[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of load test failure criterion '%s': %w", criterion, err)
+		}
+
+		parsed[criterionId(i)] = azcli.AzCliLoadTestCriterion{
+			ClientMetric: matches[2],
+			Aggregate:    matches[1],
+			Condition:    matches[3],
+			Value:        value,
+		}
+	}
+
+	return parsed, nil
+}
+
+// criterionId returns the id of the criterion at the position
+func criterionId(i int) string {
+	return fmt.Sprintf("criterion-%d", i+1)
+}
+
+// testKind returns the kind of the test of the test plan, from its extension
+func testKind(testPlan string) (string, error) {
+	switch strings.ToLower(filepath.Ext(testPlan)) {
+	case ".jmx":
+		return azcli.LoadTestKindJMeter, nil
+	case ".py":
+		return azcli.LoadTestKindLocust, nil
+	case "":
+		if testPlan == "" {
+			return "", errors.New("the test plan of the load test is not set")
+		}
+	}
+
+	return "", fmt.Errorf(
+		"unsupported test plan '%s', expected a JMeter test plan (.jmx) or a Locust script (.py)", testPlan)
+}
+
+func newResult(testId string, testRun *azcli.AzCliLoadTestRun, criteria []string) *Result {
+	result := &Result{
+		TestId:     testId,
+		TestRunId:  testRun.TestRunId,
+		Status:     testRun.Status,
+		TestResult: testRun.TestResult,
+		PortalUrl:  testRun.PortalUrl,
+	}
+
+	if total, has := testRun.Statistics["Total"]; has {
+		result.Statistics = &total
+	}
+
+	for i, criterion := range criteria {
+		metric, has := testRun.PassFailCriteria.PassFailMetrics[criterionId(i)]
+		if !has {
+			continue
+		}
+
+		result.Criteria = append(result.Criteria, Criterion{
+			Criterion:   strings.TrimSpace(criterion),
+			ActualValue: metric.ActualValue,
+			Result:      metric.Result,
+		})
+	}
+
+	for _, errorDetail := range testRun.ErrorDetails {
+		result.Errors = append(result.Errors, errorDetail.Message)
+	}
+
+	return result
+}
@@ -0,0 +1,224 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const loadTestId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/" +
+	"Microsoft.LoadTestService/loadTests/lt-dev"
+
+// fakeLoadTestingService records the test created, and runs it for a number of polls
+type fakeLoadTestingService struct {
+	test      azcli.AzCliLoadTest
+	fileName  string
+	testRunId string
+	polls     int
+	testRun   azcli.AzCliLoadTestRun
+}
+
+func (s *fakeLoadTestingService) GetDataPlaneUri(ctx context.Context, subscriptionId string, id string) (string, error) {
+	if id != loadTestId {
+		return "", os.ErrNotExist
+	}
+
+	return "lt-dev.eastus2.cnt-prod.loadtesting.azure.com", nil
+}
+
+func (s *fakeLoadTestingService) CreateOrUpdateTest(
+	ctx context.Context, subscriptionId string, dataPlaneUri string, testId string, test azcli.AzCliLoadTest) error {
+	s.test = test
+	return nil
+}
+
+func (s *fakeLoadTestingService) UploadTestScript(
+	ctx context.Context,
+	subscriptionId string,
+	dataPlaneUri string,
+	testId string,
+	kind string,
+	fileName string,
+	contents []byte,
+) error {
+	s.fileName = fileName
+	return nil
+}
+
+func (s *fakeLoadTestingService) StartTestRun(
+	ctx context.Context, subscriptionId string, dataPlaneUri string, testId string, testRunId string, name string) error {
+	s.testRunId = testRunId
+	return nil
+}
+
+func (s *fakeLoadTestingService) GetTestRun(
+	ctx context.Context, subscriptionId string, dataPlaneUri string, testRunId string) (*azcli.AzCliLoadTestRun, error) {
+	s.polls++
+	if s.polls < 2 {
+		return &azcli.AzCliLoadTestRun{TestRunId: testRunId, Status: "EXECUTING"}, nil
+	}
+
+	testRun := s.testRun
+	testRun.TestRunId = testRunId
+	return &testRun, nil
+}
+
+func newTestManager(t *testing.T, service azcli.LoadTestingService, loadTests ...string) (*Manager, context.Context) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-dev/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "resourceType eq 'Microsoft.LoadTestService/loadTests'", request.URL.Query().Get("$filter"))
+
+		result := armresources.ResourceListResult{Value: []*armresources.GenericResourceExpanded{}}
+		for _, name := range loadTests {
+			result.Value = append(result.Value, &armresources.GenericResourceExpanded{
+				ID:       convert.RefOf(filepath.Dir(loadTestId) + "/" + name),
+				Name:     convert.RefOf(name),
+				Type:     convert.RefOf("Microsoft.LoadTestService/loadTests"),
+				Location: convert.RefOf("eastus2"),
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, result)
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+		"API_KEY":                            "secret",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	manager := NewManager(env, azCli, project.NewResourceManager(env, azCli), service)
+	manager.pollInterval = 0
+
+	return manager, *mockContext.Context
+}
+
+func testProjectConfig(t *testing.T, config *project.LoadTestConfig) *project.ProjectConfig {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tests", "load.jmx"), []byte("<jmeterTestPlan/>"), 0600))
+
+	return &project.ProjectConfig{Name: "Todo", Path: dir, LoadTest: config}
+}
+
+func Test_Run(t *testing.T) {
+	actual := 230.5
+	service := &fakeLoadTestingService{
+		testRun: azcli.AzCliLoadTestRun{
+			Status:     "DONE",
+			TestResult: "PASSED",
+			Statistics: map[string]azcli.AzCliLoadTestStatistics{
+				"Total": {SampleCount: 1200, MeanResTime: 230.5},
+			},
+			PortalUrl: "https://portal.azure.com/#run",
+		},
+	}
+	service.testRun.PassFailCriteria.PassFailMetrics = map[string]azcli.AzCliLoadTestCriterion{
+		"criterion-1": {ActualValue: &actual, Result: "passed"},
+	}
+
+	manager, ctx := newTestManager(t, service, "lt-dev")
+	result, err := manager.Run(ctx, testProjectConfig(t, &project.LoadTestConfig{
+		TestPlan:        "tests/load.jmx",
+		Env:             map[string]project.ExpandableString{"API_KEY": project.NewExpandableString("${API_KEY}")},
+		FailureCriteria: []string{"avg(response_time_ms) > 500"},
+	}), map[string]string{"my-api": "https://api.contoso.com"})
+	require.NoError(t, err)
+	require.True(t, result.Passed())
+
+	require.Equal(t, azcli.LoadTestKindJMeter, service.test.Kind)
+	require.Equal(t, 1, service.test.EngineInstances)
+	require.Equal(t, map[string]string{
+		"SERVICE_MY_API_ENDPOINT": "https://api.contoso.com",
+		"API_KEY":                 "secret",
+	}, service.test.EnvironmentVariables)
+	require.Equal(t, azcli.AzCliLoadTestCriterion{
+		ClientMetric: "response_time_ms",
+		Aggregate:    "avg",
+		Condition:    ">",
+		Value:        500,
+	}, service.test.FailureCriteria["criterion-1"])
+	require.Equal(t, "load.jmx", service.fileName)
+
+	require.Equal(t, "todo-dev", result.TestId)
+	require.Equal(t, service.testRunId, result.TestRunId)
+	require.Equal(t, 2, service.polls)
+	require.Equal(t, 1200.0, result.Statistics.SampleCount)
+	require.Equal(t, []Criterion{
+		{Criterion: "avg(response_time_ms) > 500", ActualValue: &actual, Result: "passed"},
+	}, result.Criteria)
+}
+
+func Test_RunFailed(t *testing.T) {
+	service := &fakeLoadTestingService{
+		testRun: azcli.AzCliLoadTestRun{Status: "DONE", TestResult: "FAILED"},
+	}
+
+	manager, ctx := newTestManager(t, service, "lt-dev")
+	result, err := manager.Run(ctx, testProjectConfig(t, &project.LoadTestConfig{
+		TestPlan:        "tests/load.jmx",
+		FailureCriteria: []string{"percentage(error) > 5"},
+	}), nil)
+	require.NoError(t, err)
+	require.False(t, result.Passed())
+}
+
+func Test_RunResource(t *testing.T) {
+	t.Run("NoResource", func(t *testing.T) {
+		manager, ctx := newTestManager(t, &fakeLoadTestingService{})
+		_, err := manager.Run(ctx, testProjectConfig(t, &project.LoadTestConfig{TestPlan: "tests/load.jmx"}), nil)
+		require.ErrorContains(t, err, "no Azure Load Testing resource found")
+	})
+
+	t.Run("SeveralResources", func(t *testing.T) {
+		manager, ctx := newTestManager(t, &fakeLoadTestingService{}, "lt-dev", "lt-shared")
+		_, err := manager.Run(ctx, testProjectConfig(t, &project.LoadTestConfig{TestPlan: "tests/load.jmx"}), nil)
+		require.ErrorContains(t, err, "loadTest.resource")
+	})
+
+	t.Run("NamedResource", func(t *testing.T) {
+		service := &fakeLoadTestingService{testRun: azcli.AzCliLoadTestRun{Status: "DONE"}}
+		manager, ctx := newTestManager(t, service, "lt-shared", "lt-dev")
+		result, err := manager.Run(ctx, testProjectConfig(t, &project.LoadTestConfig{
+			TestPlan: "tests/load.jmx",
+			Resource: project.NewExpandableString("lt-${AZURE_ENV_NAME}"),
+		}), nil)
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+	})
+}
+
+func Test_ParseCriteria(t *testing.T) {
+	criteria, err := ParseCriteria([]string{"p90(response_time_ms) > 800", " percentage( error ) > 2.5 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]azcli.AzCliLoadTestCriterion{
+		"criterion-1": {ClientMetric: "response_time_ms", Aggregate: "p90", Condition: ">", Value: 800},
+		"criterion-2": {ClientMetric: "error", Aggregate: "percentage", Condition: ">", Value: 2.5},
+	}, criteria)
+
+	_, err = ParseCriteria([]string{"response_time_ms > 800"})
+	require.ErrorContains(t, err, "invalid load test failure criterion")
+}
+
+func Test_TestId(t *testing.T) {
+	require.Equal(t, "todo-dev", TestId("Todo", "dev"))
+	require.Equal(t, "my-app-feature-x", TestId("my app", "feature.x"))
+	require.Len(t, TestId(strings.Repeat("a", 60), "dev"), testIdMaxLength)
+}
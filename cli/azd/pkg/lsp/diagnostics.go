@@ -0,0 +1,206 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+const diagnosticSource = "azd"
+
+var yamlErrorLineRegex = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// scalarTypes are the schema types of the tags of YAML scalars
+var scalarTypes = map[string]string{
+	"!!str":   "string",
+	"!!bool":  "boolean",
+	"!!int":   "integer",
+	"!!float": "number",
+	"!!null":  "null",
+}
+
+// validate validates the document against the schema: its YAML syntax, the types and allowed values of its values,
+// unknown and missing properties, and the properties not allowed by the host of services
+func (a *azureYamlSchema) validate(text string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &root); err != nil {
+		message := err.Error()
+		line := 0
+		if match := yamlErrorLineRegex.FindStringSubmatch(message); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			line--
+			message = strings.TrimPrefix(message, match[0])
+		}
+
+		lines := strings.Split(text, "\n")
+		end := 0
+		if line >= 0 && line < len(lines) {
+			end = len(strings.TrimRight(lines[line], "\r"))
+		}
+
+		return append(diagnostics, Diagnostic{
+			Range: Range{
+				Start: Position{Line: line},
+				End:   Position{Line: line, Character: end},
+			},
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  strings.TrimPrefix(message, "yaml: "),
+		})
+	}
+
+	if len(root.Content) == 0 {
+		return diagnostics
+	}
+
+	a.validateNode(root.Content[0], nil, a.root, &diagnostics)
+	return diagnostics
+}
+
+// validateNode validates the value of the key, nil for the root of the document, against the schema
+func (a *azureYamlSchema) validateNode(node *yaml.Node, key *yaml.Node, s *jsonSchema, diagnostics *[]Diagnostic) {
+	s = a.resolve(s)
+	if s == nil {
+		return
+	}
+
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	report := func(at *yaml.Node, severity DiagnosticSeverity, format string, args ...any) {
+		*diagnostics = append(*diagnostics, Diagnostic{
+			Range:    nodeRange(at),
+			Severity: severity,
+			Source:   diagnosticSource,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	nodeType := ""
+	switch node.Kind {
+	case yaml.MappingNode:
+		nodeType = "object"
+	case yaml.SequenceNode:
+		nodeType = "array"
+	case yaml.ScalarNode:
+		nodeType = scalarTypes[node.ShortTag()]
+	}
+
+	if !typeMatches(s.Type, nodeType) {
+		report(node, SeverityError, "incorrect type, expected %s", strings.Join(s.Type, " or "))
+		return
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		allowed := s.enumValues()
+		if len(allowed) > 0 && nodeType != "null" && !slices.Contains(allowed, node.Value) {
+			expected := []string{}
+			for _, value := range allowed {
+				if value != "" {
+					expected = append(expected, value)
+				}
+			}
+
+			report(node, SeverityError, "'%s' isn't allowed, expected one of %s", node.Value, strings.Join(expected, ", "))
+		}
+	case yaml.MappingNode:
+		values := map[string]string{}
+		keys := []string{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keys = append(keys, node.Content[i].Value)
+			if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+				values[node.Content[i].Value] = value.Value
+			}
+		}
+
+		object := a.object(s, values)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name, value := node.Content[i], node.Content[i+1]
+			if property, has := object.properties[name.Value]; has {
+				if property.disallowed != "" {
+					report(name, SeverityError, "'%s' is %s", name.Value, property.disallowed)
+					continue
+				}
+
+				a.validateNode(value, name, property.schema, diagnostics)
+				continue
+			}
+
+			if s.AdditionalProperties == nil {
+				continue
+			}
+
+			if s.AdditionalProperties.never {
+				report(name, SeverityWarning, "unknown property '%s'", name.Value)
+				continue
+			}
+
+			a.validateNode(value, name, s.AdditionalProperties, diagnostics)
+		}
+
+		at := key
+		if at == nil {
+			at = node
+		}
+
+		for _, name := range object.required {
+			if !slices.Contains(keys, name) {
+				report(at, SeverityError, "missing required property '%s'", name)
+			}
+		}
+	case yaml.SequenceNode:
+		if s.Items == nil {
+			return
+		}
+
+		for _, item := range node.Content {
+			a.validateNode(item, item, s.Items, diagnostics)
+		}
+	}
+}
+
+// typeMatches reports whether the type of a YAML node matches the types of a schema. Values of the types of scalars
+// parse as strings, and empty values are allowed while editing.
+func typeMatches(types []string, nodeType string) bool {
+	if len(types) == 0 || nodeType == "null" || slices.Contains(types, nodeType) {
+		return true
+	}
+
+	switch nodeType {
+	case "object", "array":
+		return false
+	case "integer":
+		return slices.Contains(types, "number") || slices.Contains(types, "string")
+	default:
+		return slices.Contains(types, "string")
+	}
+}
+
+// nodeRange returns the range of the node, of the first line of mappings and lists
+func nodeRange(node *yaml.Node) Range {
+	start := Position{Line: node.Line - 1, Character: node.Column - 1}
+	if start.Line < 0 {
+		start = Position{}
+	}
+
+	length := 1
+	if node.Kind == yaml.ScalarNode {
+		length = len(strings.SplitN(node.Value, "\n", 2)[0])
+	}
+
+	return Range{
+		Start: start,
+		End:   Position{Line: start.Line, Character: start.Character + length},
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Validate(t *testing.T) {
+	schema := newTestSchema(t)
+
+	tests := []struct {
+		name string
+		text string
+		want []Diagnostic
+	}{
+		{
+			name: "Valid",
+			text: testAzureYaml,
+			want: []Diagnostic{},
+		},
+		{
+			name: "Syntax",
+			text: "name: todo\nservices:\n  api: [\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 8}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message:  "did not find expected node content",
+			}},
+		},
+		{
+			name: "UnknownProperty",
+			text: "name: todo\nservice:\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 7}},
+				Severity: SeverityWarning,
+				Source:   diagnosticSource,
+				Message:  "unknown property 'service'",
+			}},
+		},
+		{
+			name: "MissingProperty",
+			text: "infra:\n  provider: bicep\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{}, End: Position{Character: 1}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message:  "missing required property 'name'",
+			}},
+		},
+		{
+			name: "Enum",
+			text: "name: todo\nservices:\n  api:\n    project: src\n    language: js\n    host: vm\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 5, Character: 10}, End: Position{Line: 5, Character: 12}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message: "'vm' isn't allowed, expected one of appservice, containerapp, function, springapp, " +
+					"staticwebapp, aks",
+			}},
+		},
+		{
+			name: "Type",
+			text: "name: todo\nservices: api\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 1, Character: 10}, End: Position{Line: 1, Character: 13}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message:  "incorrect type, expected object",
+			}},
+		},
+		{
+			name: "HostSpecific",
+			text: "name: todo\nservices:\n  api:\n    project: src\n    language: js\n    host: appservice\n" +
+				"    docker:\n      path: Dockerfile\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 6, Character: 4}, End: Position{Line: 6, Character: 10}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message:  "'docker' is only supported when 'host' is containerapp or aks",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, schema.validate(tt.text))
+		})
+	}
+}
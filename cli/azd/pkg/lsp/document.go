@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// listItemKey is the key of the items of lists in the paths of documents
+const listItemKey = "-"
+
+var keyRegex = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s:#"'][^:#]*?)\s*:(\s|$)`)
+
+// documentLine is a line of a YAML document. The documents are analyzed by line and indentation, rather than parsed, for
+// the completion of documents being edited, which often aren't valid YAML.
+type documentLine struct {
+	// The indentation of the line, of the dash of list items
+	indent int
+	// The indentation of the key of the line, after the dash of list items
+	keyIndent int
+	listItem  bool
+	key       string
+	hasKey    bool
+	value     string
+	blank     bool
+}
+
+func parseLine(text string) documentLine {
+	trimmed := strings.TrimLeft(text, " ")
+	line := documentLine{indent: len(text) - len(trimmed)}
+	line.keyIndent = line.indent
+
+	if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+		line.listItem = true
+		content := strings.TrimLeft(strings.TrimPrefix(trimmed, "-"), " ")
+		line.keyIndent = len(text) - len(content)
+		trimmed = content
+	}
+
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		line.blank = !line.listItem
+		return line
+	}
+
+	if match := keyRegex.FindStringSubmatchIndex(trimmed); match != nil {
+		line.hasKey = true
+		line.key = strings.Trim(trimmed[match[2]:match[3]], `"'`)
+		trimmed = trimmed[match[1]:]
+	}
+
+	line.value = strings.Trim(stripComment(trimmed), ` "'`)
+	return line
+}
+
+// stripComment removes the comment at the end of a value
+func stripComment(value string) string {
+	if index := strings.Index(value, " #"); index >= 0 {
+		return value[:index]
+	}
+
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+
+	return value
+}
+
+// pathElement is a key of the path to a position of a document, with the scalar values of the keys of its mapping
+type pathElement struct {
+	key    string
+	values map[string]string
+}
+
+// document is a YAML document being edited
+type document struct {
+	text  []string
+	lines []documentLine
+}
+
+func newDocument(text string) *document {
+	doc := &document{text: strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")}
+	for _, line := range doc.text {
+		doc.lines = append(doc.lines, parseLine(line))
+	}
+
+	return doc
+}
+
+// path returns the keys of the mappings and lists containing the block indented at indent on the line, from the root
+func (d *document) path(line int, indent int) []pathElement {
+	path := []pathElement{}
+	target := indent
+
+	for i := line - 1; i >= 0 && target > 0; i-- {
+		current := d.lines[i]
+		if current.blank {
+			continue
+		}
+
+		if current.hasKey && current.keyIndent < target {
+			_, values := d.mapping(i, current.keyIndent)
+			path = append(path, pathElement{key: current.key, values: values})
+			target = current.keyIndent
+		}
+
+		if current.listItem && current.indent < target {
+			path = append(path, pathElement{key: listItemKey, values: map[string]string{}})
+			target = current.indent
+		}
+	}
+
+	// Reverse the path, collected from the innermost key
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// mapping returns the keys of the mapping indented at indent containing the line, other than the key of the line, and
+// the scalar values of its keys
+func (d *document) mapping(line int, indent int) ([]string, map[string]string) {
+	keys := []string{}
+	values := map[string]string{}
+	add := func(i int) {
+		current := d.lines[i]
+		if !current.hasKey || current.keyIndent != indent {
+			return
+		}
+
+		if i != line {
+			keys = append(keys, current.key)
+		}
+
+		if current.value != "" {
+			values[current.key] = current.value
+		}
+	}
+
+	for i := line; i >= 0 && i < len(d.lines); i-- {
+		current := d.lines[i]
+		if current.blank {
+			continue
+		}
+
+		add(i)
+		if current.keyIndent < indent || (current.listItem && current.keyIndent == indent) {
+			break
+		}
+	}
+
+	for i := line + 1; i < len(d.lines); i++ {
+		current := d.lines[i]
+		if current.blank {
+			continue
+		}
+
+		if current.indent < indent {
+			break
+		}
+
+		add(i)
+	}
+
+	return keys, values
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// pathAt returns the path to the block indented at indent on the line, including the item of the list item of the line
+func (d *document) pathAt(line int, indent int) []pathElement {
+	current := d.lines[line]
+	if current.listItem && indent >= current.keyIndent {
+		return append(d.path(line, current.indent), pathElement{key: listItemKey, values: map[string]string{}})
+	}
+
+	return d.path(line, indent)
+}
+
+// complete returns the completions at the position of the document: the keys allowed in the mapping of the position,
+// or the values allowed for the key of the position
+func (a *azureYamlSchema) complete(text string, position Position) []CompletionItem {
+	doc := newDocument(text)
+	if position.Line < 0 || position.Line >= len(doc.text) {
+		return []CompletionItem{}
+	}
+
+	// The line up to the position, for the key or value being typed, and indented to the position when past the end
+	lineText := doc.text[position.Line]
+	if position.Character < len(lineText) {
+		lineText = lineText[:position.Character]
+	} else {
+		lineText += strings.Repeat(" ", position.Character-len(lineText))
+	}
+
+	current := parseLine(lineText)
+	path := doc.pathAt(position.Line, current.keyIndent)
+	parent := a.navigate(path)
+	if parent == nil {
+		return []CompletionItem{}
+	}
+
+	keys, values := doc.mapping(position.Line, current.keyIndent)
+	if current.hasKey {
+		property := a.child(parent, current.key, values)
+		if property == nil || property.disallowed != "" {
+			return []CompletionItem{}
+		}
+
+		return a.completeValue(property.schema)
+	}
+
+	object := a.object(parent, values)
+	items := []CompletionItem{}
+	for _, name := range object.allowed() {
+		if slices.Contains(keys, name) {
+			continue
+		}
+
+		property := a.resolve(object.properties[name].schema)
+		if property == nil {
+			continue
+		}
+
+		insertText := fmt.Sprintf("%s: ", name)
+		if slices.Contains(property.Type, "object") || slices.Contains(property.Type, "array") {
+			insertText = fmt.Sprintf("%s:", name)
+		}
+
+		items = append(items, CompletionItem{
+			Label:         name,
+			Kind:          CompletionKindProperty,
+			Detail:        property.Title,
+			Documentation: newMarkdown(property.documentation()),
+			InsertText:    insertText,
+		})
+	}
+
+	return items
+}
+
+func (a *azureYamlSchema) completeValue(s *jsonSchema) []CompletionItem {
+	s = a.resolve(s)
+	items := []CompletionItem{}
+	if s == nil {
+		return items
+	}
+
+	for _, value := range s.enumValues() {
+		if value == "" {
+			continue
+		}
+
+		items = append(items, CompletionItem{Label: value, Kind: CompletionKindEnumMember})
+	}
+
+	if len(items) == 0 && slices.Contains(s.Type, "boolean") {
+		for _, value := range []string{"true", "false"} {
+			items = append(items, CompletionItem{Label: value, Kind: CompletionKindValue})
+		}
+	}
+
+	return items
+}
+
+// hover returns the documentation of the key at the position of the document, nil when the position isn't on a known key
+func (a *azureYamlSchema) hover(text string, position Position) *Hover {
+	doc := newDocument(text)
+	if position.Line < 0 || position.Line >= len(doc.text) {
+		return nil
+	}
+
+	current := doc.lines[position.Line]
+	if !current.hasKey ||
+		position.Character < current.keyIndent ||
+		position.Character > current.keyIndent+len(current.key) {
+		return nil
+	}
+
+	parent := a.navigate(doc.pathAt(position.Line, current.keyIndent))
+	if parent == nil {
+		return nil
+	}
+
+	_, values := doc.mapping(position.Line, current.keyIndent)
+	property := a.child(parent, current.key, values)
+	if property == nil || a.resolve(property.schema) == nil {
+		return nil
+	}
+
+	documentation := a.resolve(property.schema).documentation()
+	if property.disallowed != "" {
+		documentation = fmt.Sprintf("%s\n\n`%s` is %s.", documentation, current.key, property.disallowed)
+	}
+
+	if documentation == "" {
+		return nil
+	}
+
+	return &Hover{
+		Contents: *newMarkdown(documentation),
+		Range: &Range{
+			Start: Position{Line: position.Line, Character: current.keyIndent},
+			End:   Position{Line: position.Line, Character: current.keyIndent + len(current.key)},
+		},
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/schemas"
+	"github.com/stretchr/testify/require"
+)
+
+const testAzureYaml = `name: todo
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+
+  web:
+    project: src/web
+    language: js
+    host: appservice
+
+hooks:
+  preprovision:
+    shell: sh
+
+roleAssignments:
+  - principal: api
+    role: Reader
+
+`
+
+func newTestSchema(t *testing.T) *azureYamlSchema {
+	schema, err := newAzureYamlSchema(schemas.AzureYamlAlpha)
+	require.NoError(t, err)
+	return schema
+}
+
+func labels(items []CompletionItem) []string {
+	labels := []string{}
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func Test_Complete_Keys(t *testing.T) {
+	schema := newTestSchema(t)
+
+	t.Run("Root", func(t *testing.T) {
+		items := labels(schema.complete(testAzureYaml, Position{Line: 20, Character: 0}))
+		require.Contains(t, items, "infra")
+		require.Contains(t, items, "pipeline")
+		// Keys already in the document aren't completed
+		require.NotContains(t, items, "services")
+		require.NotContains(t, items, "name")
+		require.NotContains(t, items, "roleAssignments")
+	})
+
+	t.Run("HostSpecific", func(t *testing.T) {
+		api := labels(schema.complete(testAzureYaml, Position{Line: 6, Character: 4}))
+		require.Contains(t, api, "docker")
+		require.Contains(t, api, "containerApp")
+		require.NotContains(t, api, "staticWebApp")
+		require.NotContains(t, api, "host")
+
+		web := labels(schema.complete(testAzureYaml, Position{Line: 11, Character: 4}))
+		require.NotContains(t, web, "docker")
+		require.NotContains(t, web, "containerApp")
+		require.Contains(t, web, "resourceName")
+	})
+
+	t.Run("Definition", func(t *testing.T) {
+		items := labels(schema.complete(testAzureYaml, Position{Line: 15, Character: 4}))
+		require.Contains(t, items, "run")
+		require.NotContains(t, items, "shell")
+	})
+
+	t.Run("ListItem", func(t *testing.T) {
+		items := labels(schema.complete(testAzureYaml, Position{Line: 19, Character: 4}))
+		require.Contains(t, items, "scope")
+		require.NotContains(t, items, "principal")
+		require.NotContains(t, items, "role")
+
+		items = labels(schema.complete(testAzureYaml+"  - ", Position{Line: 20, Character: 4}))
+		require.Contains(t, items, "principal")
+	})
+}
+
+func Test_Complete_Values(t *testing.T) {
+	schema := newTestSchema(t)
+
+	text := strings.Replace(testAzureYaml, "host: appservice", "host: ", 1)
+	items := labels(schema.complete(text, Position{Line: 10, Character: 10}))
+	require.Equal(t, []string{"appservice", "containerapp", "function", "springapp", "staticwebapp", "aks"}, items)
+}
+
+func Test_Hover(t *testing.T) {
+	schema := newTestSchema(t)
+
+	hover := schema.hover(testAzureYaml, Position{Line: 5, Character: 6})
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "Type of Azure resource used for service implementation")
+	require.Contains(t, hover.Contents.Value, "`containerapp`")
+	require.Equal(t, Range{Start: Position{Line: 5, Character: 4}, End: Position{Line: 5, Character: 8}}, *hover.Range)
+
+	// Values aren't documented
+	require.Nil(t, schema.hover(testAzureYaml, Position{Line: 5, Character: 12}))
+}
+
+func Test_Hover_Disallowed(t *testing.T) {
+	schema := newTestSchema(t)
+
+	text := strings.Replace(testAzureYaml, "host: appservice\n", "host: appservice\n    docker:\n", 1)
+	hover := schema.hover(text, Position{Line: 11, Character: 5})
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "`docker` is only supported when 'host' is containerapp or aks.")
+}
@@ -0,0 +1,306 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// jsonSchema is the subset of JSON schema used by the azure.yaml schema
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 schemaTypes            `json:"type"`
+	Title                string                 `json:"title"`
+	Description          string                 `json:"description"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Required             []string               `json:"required"`
+	Enum                 []any                  `json:"enum"`
+	Const                json.RawMessage        `json:"const"`
+	Default              json.RawMessage        `json:"default"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	If                   *jsonSchema            `json:"if"`
+	Then                 *jsonSchema            `json:"then"`
+	Not                  *jsonSchema            `json:"not"`
+	Definitions          map[string]*jsonSchema `json:"definitions"`
+	// Set for the false schema, which no value matches, ex) properties not allowed by a condition
+	never bool
+}
+
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = jsonSchema{}
+		return nil
+	case "false":
+		*s = jsonSchema{never: true}
+		return nil
+	}
+
+	type plain jsonSchema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// schemaTypes are the types of a schema, a single type or a list of types
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*t = multiple
+	return nil
+}
+
+// enumValues returns the allowed values of the schema, from its enum or const
+func (s *jsonSchema) enumValues() []string {
+	values := []string{}
+	for _, value := range s.Enum {
+		values = append(values, fmt.Sprint(value))
+	}
+
+	if len(s.Const) > 0 {
+		var value any
+		if err := json.Unmarshal(s.Const, &value); err == nil {
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+
+	return values
+}
+
+// documentation describes the schema as markdown, ex) for hovers
+func (s *jsonSchema) documentation() string {
+	parts := []string{}
+	if s.Title != "" {
+		parts = append(parts, fmt.Sprintf("**%s**", s.Title))
+	}
+
+	if s.Description != "" && s.Description != s.Title {
+		parts = append(parts, s.Description)
+	}
+
+	if values := s.enumValues(); len(values) > 0 {
+		parts = append(parts, fmt.Sprintf("Allowed values: `%s`", strings.Join(values, "`, `")))
+	}
+
+	if len(s.Default) > 0 {
+		parts = append(parts, fmt.Sprintf("Default: `%s`", string(s.Default)))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// schemaProperty is a property of an object schema, in the context of the values of its sibling properties
+type schemaProperty struct {
+	schema *jsonSchema
+	// Why the property isn't allowed, empty when allowed
+	disallowed string
+}
+
+// azureYamlSchema navigates the azure.yaml schema
+type azureYamlSchema struct {
+	root *jsonSchema
+}
+
+func newAzureYamlSchema(contents []byte) (*azureYamlSchema, error) {
+	var root jsonSchema
+	if err := json.Unmarshal(contents, &root); err != nil {
+		return nil, fmt.Errorf("reading azure.yaml schema: %w", err)
+	}
+
+	return &azureYamlSchema{root: &root}, nil
+}
+
+// resolve follows the reference of the schema to the definitions of the root schema
+func (a *azureYamlSchema) resolve(s *jsonSchema) *jsonSchema {
+	for s != nil && s.Ref != "" {
+		name, found := strings.CutPrefix(s.Ref, "#/definitions/")
+		if !found {
+			return nil
+		}
+
+		s = a.root.Definitions[name]
+	}
+
+	return s
+}
+
+// objectSchema is the properties and required properties of an object schema, in the context of the values of the
+// properties of the object
+type objectSchema struct {
+	properties map[string]*schemaProperty
+	required   []string
+}
+
+// object returns the properties of the object schema, given the scalar values of the properties of the object for the
+// properties only allowed or required under conditions, ex) the host-specific properties of services
+func (a *azureYamlSchema) object(s *jsonSchema, values map[string]string) *objectSchema {
+	s = a.resolve(s)
+	object := &objectSchema{properties: map[string]*schemaProperty{}}
+	if s == nil {
+		return object
+	}
+
+	properties := object.properties
+	object.required = append(object.required, s.Required...)
+	for name, property := range s.Properties {
+		properties[name] = &schemaProperty{schema: property}
+	}
+
+	for _, sub := range s.AllOf {
+		sub = a.resolve(sub)
+		if sub == nil {
+			continue
+		}
+
+		applied := sub
+		if sub.If != nil {
+			if !a.matches(sub.If, values) || sub.Then == nil {
+				continue
+			}
+
+			applied = a.resolve(sub.Then)
+			if applied == nil {
+				continue
+			}
+		}
+
+		object.required = append(object.required, applied.Required...)
+		for name, property := range applied.Properties {
+			if property.never {
+				if existing, has := properties[name]; has {
+					existing.disallowed = describeCondition(sub.If)
+				}
+				continue
+			}
+
+			if _, has := properties[name]; !has {
+				properties[name] = &schemaProperty{schema: property}
+			}
+		}
+	}
+
+	return object
+}
+
+// child returns the schema of the property of the object schema, or of the items of the array schema for the "-" key,
+// nil when the property isn't known
+func (a *azureYamlSchema) child(s *jsonSchema, key string, values map[string]string) *schemaProperty {
+	s = a.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if key == listItemKey {
+		if s.Items == nil {
+			return nil
+		}
+
+		return &schemaProperty{schema: s.Items}
+	}
+
+	if property, has := a.object(s, values).properties[key]; has {
+		return property
+	}
+
+	if s.AdditionalProperties != nil && !s.AdditionalProperties.never {
+		return &schemaProperty{schema: s.AdditionalProperties}
+	}
+
+	return nil
+}
+
+// navigate returns the schema of the value at the path, nil when the path isn't known
+func (a *azureYamlSchema) navigate(path []pathElement) *jsonSchema {
+	s := a.root
+	for _, element := range path {
+		property := a.child(s, element.key, element.values)
+		if property == nil || property.disallowed != "" {
+			return nil
+		}
+
+		s = property.schema
+	}
+
+	return a.resolve(s)
+}
+
+// matches evaluates the condition of an if, for the conditions of the azure.yaml schema: properties with an enum or a
+// const, negated with not
+func (a *azureYamlSchema) matches(condition *jsonSchema, values map[string]string) bool {
+	condition = a.resolve(condition)
+	if condition == nil {
+		return true
+	}
+
+	if condition.never {
+		return false
+	}
+
+	if condition.Not != nil && a.matches(condition.Not, values) {
+		return false
+	}
+
+	for _, name := range condition.Required {
+		if _, has := values[name]; !has {
+			return false
+		}
+	}
+
+	for name, property := range condition.Properties {
+		value, has := values[name]
+		if !has {
+			continue
+		}
+
+		if allowed := property.enumValues(); len(allowed) > 0 && !slices.Contains(allowed, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describeCondition describes when the properties disallowed by the condition are allowed, ex) only when the host is
+// one of containerapp, aks
+func describeCondition(condition *jsonSchema) string {
+	if condition != nil && condition.Not != nil && len(condition.Not.Properties) == 1 {
+		for name, property := range condition.Not.Properties {
+			if values := property.enumValues(); len(values) > 0 {
+				return fmt.Sprintf("only supported when '%s' is %s", name, strings.Join(values, " or "))
+			}
+		}
+	}
+
+	return "not allowed here"
+}
+
+// allowed returns the names of the allowed properties, sorted
+func (o *objectSchema) allowed() []string {
+	names := []string{}
+	for _, name := range maps.Keys(o.properties) {
+		if o.properties[name].disallowed == "" {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,278 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package lsp implements a language server for azure.yaml, providing the completion, hover documentation and validation
+// of its keys and values, from the azure.yaml schema, to editors over the language server protocol.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// The codes of the JSON-RPC errors returned by the server
+const (
+	errorCodeParseError     = -32700
+	errorCodeInvalidParams  = -32602
+	errorCodeMethodNotFound = -32601
+	errorCodeInvalidRequest = -32600
+)
+
+// message is a JSON-RPC request, notification or response
+type message struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+type errorResponse struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Error   responseError    `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+type notification struct {
+	JsonRpc string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// Server is a language server for azure.yaml, communicating over a stream, ex) the standard input and output of
+// `azd internal lsp` started by editors. The documents are synchronized in full, and validated when opened or changed.
+type Server struct {
+	schema    *azureYamlSchema
+	documents map[string]string
+	writer    io.Writer
+	writeMu   sync.Mutex
+	shutdown  bool
+}
+
+// NewServer creates a language server for documents of the azure.yaml schema
+func NewServer(schema []byte) (*Server, error) {
+	azureYamlSchema, err := newAzureYamlSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		schema:    azureYamlSchema,
+		documents: map[string]string{},
+	}, nil
+}
+
+// Serve reads the messages of the client from reader and writes the responses to writer, until the client exits, the
+// reader is closed or the context is cancelled
+func (s *Server) Serve(ctx context.Context, reader io.Reader, writer io.Writer) error {
+	s.writer = writer
+	messages := textproto.NewReader(bufio.NewReader(reader))
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		content, err := readMessage(messages)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			if err := s.writeError(nil, errorCodeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// readMessage reads the content of a message, framed by its headers
+func readMessage(reader *textproto.Reader) ([]byte, error) {
+	headers, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header '%s'", headers.Get("Content-Length"))
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(reader.R, content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (s *Server) handle(msg message) error {
+	// Requests have an id and expect a response, notifications don't
+	reply := func(result any, err error) error {
+		if msg.Id == nil {
+			if err != nil {
+				log.Printf("lsp: failed handling %s: %v", msg.Method, err)
+			}
+			return nil
+		}
+
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			return s.writeError(msg.Id, rpcErr.Code, rpcErr.Message)
+		} else if err != nil {
+			return s.writeError(msg.Id, errorCodeInvalidParams, err.Error())
+		}
+
+		return s.write(response{JsonRpc: "2.0", Id: msg.Id, Result: result})
+	}
+
+	if s.shutdown && msg.Method != "exit" && msg.Id != nil {
+		return s.writeError(msg.Id, errorCodeInvalidRequest, "the server is shut down")
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return reply(map[string]any{
+			"capabilities": map[string]any{
+				// Documents are synchronized in full
+				"textDocumentSync": 1,
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{":", " "},
+				},
+				"hoverProvider": true,
+			},
+			"serverInfo": map[string]any{
+				"name": "azd",
+			},
+		}, nil)
+	case "shutdown":
+		s.shutdown = true
+		return reply(nil, nil)
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return reply(nil, err)
+		}
+
+		return s.update(params.TextDocument.Uri, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return reply(nil, err)
+		}
+
+		if len(params.ContentChanges) == 0 {
+			return nil
+		}
+
+		return s.update(params.TextDocument.Uri, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return reply(nil, err)
+		}
+
+		delete(s.documents, params.TextDocument.Uri)
+		return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			Uri:         params.TextDocument.Uri,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return reply(nil, err)
+		}
+
+		return reply(s.schema.complete(s.documents[params.TextDocument.Uri], params.Position), nil)
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return reply(nil, err)
+		}
+
+		return reply(s.schema.hover(s.documents[params.TextDocument.Uri], params.Position), nil)
+	default:
+		// Other notifications, ex) initialized or $/cancelRequest, are ignored
+		if msg.Id == nil {
+			return nil
+		}
+
+		return reply(nil, &responseError{
+			Code:    errorCodeMethodNotFound,
+			Message: fmt.Sprintf("method '%s' isn't supported", msg.Method),
+		})
+	}
+}
+
+// update stores the text of the document and publishes its diagnostics
+func (s *Server) update(uri string, text string) error {
+	s.documents[uri] = text
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		Uri:         uri,
+		Diagnostics: s.schema.validate(text),
+	})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return s.write(notification{JsonRpc: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) writeError(id *json.RawMessage, code int, message string) error {
+	return s.write(errorResponse{
+		JsonRpc: "2.0",
+		Id:      id,
+		Error:   responseError{Code: code, Message: message},
+	})
+}
+
+// write writes the message framed by its Content-Length header
+func (s *Server) write(msg any) error {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if _, err := fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+
+	_, err = s.writer.Write(content)
+	return err
+}
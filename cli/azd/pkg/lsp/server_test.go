@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"testing"
+
+	"github.com/azure/azure-dev/schemas"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestMessage(t *testing.T, buf *bytes.Buffer, id int, method string, params any) {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+
+	content, err := json.Marshal(msg)
+	require.NoError(t, err)
+	fmt.Fprintf(buf, "Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func Test_Server(t *testing.T) {
+	server, err := NewServer(schemas.AzureYamlAlpha)
+	require.NoError(t, err)
+
+	uri := "file:///app/azure.yaml"
+	document := map[string]any{"uri": uri}
+	position := func(line int, character int) map[string]any {
+		return map[string]any{
+			"textDocument": document,
+			"position":     map[string]any{"line": line, "character": character},
+		}
+	}
+
+	input := &bytes.Buffer{}
+	writeTestMessage(t, input, 1, "initialize", map[string]any{"capabilities": map[string]any{}})
+	writeTestMessage(t, input, 0, "initialized", map[string]any{})
+	writeTestMessage(t, input, 0, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "yaml", "version": 1, "text": "name: todo\nservice:\n"},
+	})
+	writeTestMessage(t, input, 0, "textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "name: todo\n\n"}},
+	})
+	writeTestMessage(t, input, 2, "textDocument/completion", position(1, 0))
+	writeTestMessage(t, input, 3, "textDocument/hover", position(0, 1))
+	writeTestMessage(t, input, 4, "workspace/symbol", map[string]any{})
+	writeTestMessage(t, input, 5, "shutdown", nil)
+	writeTestMessage(t, input, 0, "exit", nil)
+	// Messages after exit aren't read
+	writeTestMessage(t, input, 6, "shutdown", nil)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, server.Serve(context.Background(), input, output))
+
+	reader := textproto.NewReader(bufio.NewReader(output))
+	responses := []map[string]any{}
+	for {
+		content, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(content, &response))
+		responses = append(responses, response)
+	}
+
+	require.Len(t, responses, 7)
+
+	capabilities := responses[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	require.Equal(t, true, capabilities["hoverProvider"])
+
+	require.Equal(t, "textDocument/publishDiagnostics", responses[1]["method"])
+	diagnostics := responses[1]["params"].(map[string]any)["diagnostics"].([]any)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, "unknown property 'service'", diagnostics[0].(map[string]any)["message"])
+
+	// The change fixed the document
+	require.Empty(t, responses[2]["params"].(map[string]any)["diagnostics"])
+
+	require.EqualValues(t, 2, responses[3]["id"])
+	require.NotEmpty(t, responses[3]["result"])
+
+	require.EqualValues(t, 3, responses[4]["id"])
+	contents := responses[4]["result"].(map[string]any)["contents"].(map[string]any)
+	require.Contains(t, contents["value"], "Name of the application")
+
+	require.EqualValues(t, 4, responses[5]["id"])
+	require.EqualValues(t, errorCodeMethodNotFound, responses[5]["error"].(map[string]any)["code"])
+
+	require.EqualValues(t, 5, responses[6]["id"])
+	require.Contains(t, responses[6], "result")
+	require.Nil(t, responses[6]["result"])
+}
@@ -13,7 +13,8 @@ import (
 	"os"
 	"time"
 
-	"github.com/sethvargo/go-retry"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 	"golang.org/x/sys/windows"
 )
 
@@ -21,16 +22,16 @@ import (
 // Rename fails due to what may be transient file system errors. This can help work around issues where the file may
 // temporary be opened by a virus scanner or some other process which prevents us from renaming the file.
 func Rename(ctx context.Context, old, new string) error {
-	return retry.Do(ctx, retry.WithMaxRetries(10, retry.NewConstant(1*time.Second)), func(ctx context.Context) error {
+	return wait.Retry(ctx, clock.New(), wait.ConstantRetry(10, 1*time.Second), func(ctx context.Context) error {
 		err := os.Rename(old, new)
 		if errors.Is(err, windows.ERROR_SHARING_VIOLATION) {
 			// If some other process has a open handle to the source file, Rename can fail with ERROR_SHARING_VIOLATION.
 			log.Printf("rename of %s to %s failed due to ERROR_SHARING_VIOLATION, allowing retry", old, new)
-			return retry.RetryableError(err)
+			return wait.RetryableError(err)
 		} else if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
 			// If the target file has already exists and is in use, Rename can fail with ERROR_ACCESS_DENIED.
 			log.Printf("rename of %s to %s failed due to ERROR_ACCESS_DENIED, allowing retry", old, new)
-			return retry.RetryableError(err)
+			return wait.RetryableError(err)
 		}
 		return err
 	})
@@ -6,6 +6,9 @@ package output
 import (
 	"fmt"
 	"io"
+	"strings"
+
+	"golang.org/x/exp/slices"
 )
 
 type Format string
@@ -15,24 +18,73 @@ const (
 	JsonFormat    Format = "json"
 	TableFormat   Format = "table"
 	NoneFormat    Format = "none"
+	YamlFormat    Format = "yaml"
+	// The result is rendered with the go template of the format, ex) go-template={{.name}}
+	GoTemplateFormat Format = "go-template"
+	// The result is rendered with the go template of the file of the format, ex) go-template-file=result.tmpl
+	GoTemplateFileFormat Format = "go-template-file"
 )
 
+// structuredFormats are the formats writing the result of the commands as data for scripts, instead of messages. The
+// commands supporting json support all of them.
+var structuredFormats = []Format{JsonFormat, YamlFormat, GoTemplateFormat, GoTemplateFileFormat}
+
+// IsStructured reports whether the format writes the result of the command as data for scripts, ex) json, in which case
+// the messages of the command aren't written to stdout
+func (f Format) IsStructured() bool {
+	return slices.Contains(structuredFormats, f)
+}
+
 type Formatter interface {
 	Kind() Format
 	Format(obj interface{}, writer io.Writer, opts interface{}) error
 }
 
+// FormatterFactory creates the formatter of a format from the argument of the format, ex) the template of
+// go-template=<template>, empty when the format has none
+type FormatterFactory func(arg string) (Formatter, error)
+
+// formatterFactories are the factories of the formatters of the formats, by format
+var formatterFactories = map[Format]FormatterFactory{
+	JsonFormat:           noArgFormatter(JsonFormat, func() Formatter { return &JsonFormatter{} }),
+	EnvVarsFormat:        noArgFormatter(EnvVarsFormat, func() Formatter { return &EnvVarsFormatter{} }),
+	TableFormat:          noArgFormatter(TableFormat, func() Formatter { return &TableFormatter{} }),
+	NoneFormat:           noArgFormatter(NoneFormat, func() Formatter { return &NoneFormatter{} }),
+	YamlFormat:           noArgFormatter(YamlFormat, func() Formatter { return &YamlFormatter{} }),
+	GoTemplateFormat:     NewGoTemplateFormatter,
+	GoTemplateFileFormat: NewGoTemplateFileFormatter,
+}
+
+// RegisterFormatter registers the factory of the formatter of a format, replacing the factory of the format if any
+func RegisterFormatter(format Format, factory FormatterFactory) {
+	formatterFactories[format] = factory
+}
+
+func noArgFormatter(format Format, create func() Formatter) FormatterFactory {
+	return func(arg string) (Formatter, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("the %s format has no argument", format)
+		}
+
+		return create(), nil
+	}
+}
+
+// NewFormatter creates the formatter of the format, ex) json, or of the format with its argument, ex)
+// go-template={{.name}}
 func NewFormatter(format string) (Formatter, error) {
-	switch format {
-	case string(JsonFormat):
-		return &JsonFormatter{}, nil
-	case string(EnvVarsFormat):
-		return &EnvVarsFormatter{}, nil
-	case string(TableFormat):
-		return &TableFormatter{}, nil
-	case string(NoneFormat):
-		return &NoneFormatter{}, nil
-	default:
+	name, arg := parseFormat(format)
+	factory, has := formatterFactories[name]
+	if !has {
 		return nil, fmt.Errorf("unsupported format %v", format)
 	}
+
+	return factory(arg)
+}
+
+// parseFormat splits the format from its argument, ex) go-template={{.name}}. The name of the format isn't case
+// sensitive, its argument is.
+func parseFormat(format string) (Format, string) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(format), "=")
+	return Format(strings.ToLower(strings.TrimSpace(name))), arg
 }
@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFormatter(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    Format
+		wantErr string
+	}{
+		{format: "json", want: JsonFormat},
+		{format: " YAML ", want: YamlFormat},
+		{format: "go-template={{.Name}}", want: GoTemplateFormat},
+		{format: "json=x", wantErr: "the json format has no argument"},
+		{format: "xml", wantErr: "unsupported format xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, err := NewFormatter(tt.format)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, formatter.Kind())
+		})
+	}
+}
+
+type csvFormatter struct{}
+
+func (f *csvFormatter) Kind() Format {
+	return Format("csv")
+}
+
+func (f *csvFormatter) Format(obj interface{}, writer io.Writer, opts interface{}) error {
+	return nil
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	RegisterFormatter("csv", func(arg string) (Formatter, error) {
+		return &csvFormatter{}, nil
+	})
+	defer delete(formatterFactories, "csv")
+
+	formatter, err := NewFormatter("csv")
+	require.NoError(t, err)
+	require.Equal(t, Format("csv"), formatter.Kind())
+	require.False(t, formatter.Kind().IsStructured())
+}
+
+func TestGetCommandFormatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []Format
+		output  string
+		want    Format
+		wantErr string
+	}{
+		{
+			name:    "StructuredFormatsOfJson",
+			formats: []Format{JsonFormat, NoneFormat},
+			output:  "yaml",
+			want:    YamlFormat,
+		},
+		{
+			name:    "TemplateKeepsCase",
+			formats: []Format{JsonFormat, NoneFormat},
+			output:  "Go-Template={{.Name}}",
+			want:    GoTemplateFormat,
+		},
+		{
+			name:    "NoJson",
+			formats: []Format{TableFormat, NoneFormat},
+			output:  "yaml",
+			wantErr: "unsupported format 'yaml'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := AddOutputParam(&cobra.Command{}, tt.formats, NoneFormat)
+			require.NoError(t, cmd.Flags().Set(outputFlagName, tt.output))
+
+			formatter, err := GetCommandFormatter(cmd)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, formatter.Kind())
+			require.True(t, formatter.Kind().IsStructured())
+		})
+	}
+}
@@ -16,13 +16,27 @@ const (
 	supportedFormatterAnnotation = "github.com/azure/azure-dev/cli/azd/pkg/output/supportedOutputFormatters"
 )
 
+// AddOutputFlag adds the --output flag of the formats. The commands supporting json support the other structured formats
+// too, ex) yaml.
 func AddOutputFlag(f *pflag.FlagSet, s *string, supportedFormats []Format, defaultFormat Format) {
-	formatNames := make([]string, len(supportedFormats))
-	for i, f := range supportedFormats {
-		formatNames[i] = string(f)
+	formatNames := []string{}
+	descriptions := []string{}
+	for _, format := range supportedFormats {
+		formatNames = append(formatNames, string(format))
+		descriptions = append(descriptions, string(format))
+
+		if format == JsonFormat {
+			formatNames = append(formatNames, string(YamlFormat), string(GoTemplateFormat), string(GoTemplateFileFormat))
+			descriptions = append(
+				descriptions,
+				string(YamlFormat),
+				string(GoTemplateFormat)+"=<template>",
+				string(GoTemplateFileFormat)+"=<path>",
+			)
+		}
 	}
 
-	description := fmt.Sprintf("The output format (the supported formats are %s).", strings.Join(formatNames, ", "))
+	description := fmt.Sprintf("The output format (the supported formats are %s).", strings.Join(descriptions, ", "))
 	f.StringVarP(s, outputFlagName, "o", string(defaultFormat), description)
 	//preview:flag hide --output
 	_ = f.MarkHidden(outputFlagName)
@@ -44,16 +58,16 @@ func GetCommandFormatter(cmd *cobra.Command) (Formatter, error) {
 		return &NoneFormatter{}, nil
 	}
 
-	desiredFormatter := strings.ToLower(strings.TrimSpace(outputVal))
+	desiredFormatter, _ := parseFormat(outputVal)
 	f := cmd.Flags().Lookup(outputFlagName)
 	supportedFormatters, hasFormatters := f.Annotations[supportedFormatterAnnotation]
 	if !hasFormatters {
-		return NewFormatter(desiredFormatter)
+		return NewFormatter(outputVal)
 	}
 
 	supported := false
 	for _, formatter := range supportedFormatters {
-		if formatter == desiredFormatter {
+		if formatter == string(desiredFormatter) {
 			supported = true
 			break
 		}
@@ -62,5 +76,5 @@ func GetCommandFormatter(cmd *cobra.Command) (Formatter, error) {
 		return nil, fmt.Errorf("unsupported format '%s'", desiredFormatter)
 	}
 
-	return NewFormatter(desiredFormatter)
+	return NewFormatter(outputVal)
 }
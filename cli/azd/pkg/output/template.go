@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the functions of the templates of the go-template formats, besides the builtin functions
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		b, err := json.Marshal(value)
+		return string(b), err
+	},
+	"join": func(sep string, values []interface{}) string {
+		s := make([]string, len(values))
+		for i, value := range values {
+			s[i] = fmt.Sprint(value)
+		}
+		return strings.Join(s, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// GoTemplateFormatter renders the json representation of the object with a go template, ex) {{.name}} for the name
+// field, for scripts to extract exactly the fields they need
+type GoTemplateFormatter struct {
+	format   Format
+	template *template.Template
+}
+
+// NewGoTemplateFormatter creates the formatter of the go template, ex) of --output go-template={{.name}}
+func NewGoTemplateFormatter(text string) (Formatter, error) {
+	return newGoTemplateFormatter(GoTemplateFormat, text)
+}
+
+// NewGoTemplateFileFormatter creates the formatter of the go template of the file, ex) of
+// --output go-template-file=result.tmpl
+func NewGoTemplateFileFormatter(path string) (Formatter, error) {
+	if path == "" {
+		return nil, errors.New("the go-template-file format needs a file, ex) go-template-file=result.tmpl")
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %w", err)
+	}
+
+	return newGoTemplateFormatter(GoTemplateFileFormat, string(text))
+}
+
+func newGoTemplateFormatter(format Format, text string) (Formatter, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("the %s format needs a template, ex) go-template={{.name}}", format)
+	}
+
+	tmpl, err := template.New(string(format)).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	return &GoTemplateFormatter{
+		format:   format,
+		template: tmpl,
+	}, nil
+}
+
+func (f *GoTemplateFormatter) Kind() Format {
+	return f.format
+}
+
+func (f *GoTemplateFormatter) Format(obj interface{}, writer io.Writer, _ interface{}) error {
+	value, err := jsonValue(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := f.template.Execute(writer, value); err != nil {
+		return fmt.Errorf("rendering template: %w", err)
+	}
+
+	// Templates rarely end with a newline on the command line, the output does for scripts reading lines
+	_, err = writer.Write([]byte("\n"))
+	return err
+}
+
+var _ Formatter = (*GoTemplateFormatter)(nil)
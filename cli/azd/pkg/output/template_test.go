@@ -0,0 +1,99 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type templateInput struct {
+	Services map[string]templateService `json:"services"`
+}
+
+type templateService struct {
+	Endpoints []string `json:"endpoints"`
+}
+
+func TestGoTemplateFormatter(t *testing.T) {
+	obj := templateInput{
+		Services: map[string]templateService{
+			"api": {Endpoints: []string{"http://10.0.0.4", "http://api.contoso.com"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "Index",
+			template: "{{index .services.api.endpoints 0}}",
+			want:     "http://10.0.0.4\n",
+		},
+		{
+			name:     "Range",
+			template: "{{range $name, $svc := .services}}{{$name}}={{join \",\" $svc.endpoints}}{{end}}",
+			want:     "api=http://10.0.0.4,http://api.contoso.com\n",
+		},
+		{
+			name:     "Json",
+			template: "{{json .services.api}}",
+			want:     `{"endpoints":["http://10.0.0.4","http://api.contoso.com"]}` + "\n",
+		},
+		{
+			name:     "MissingField",
+			template: "{{.services.web.endpoints}}",
+			wantErr:  "rendering template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewGoTemplateFormatter(tt.template)
+			require.NoError(t, err)
+			require.Equal(t, GoTemplateFormat, formatter.Kind())
+
+			buffer := &bytes.Buffer{}
+			err = formatter.Format(obj, buffer, nil)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buffer.String())
+		})
+	}
+}
+
+func TestGoTemplateFormatterInvalid(t *testing.T) {
+	_, err := NewGoTemplateFormatter("")
+	require.ErrorContains(t, err, "needs a template")
+
+	_, err = NewGoTemplateFormatter("{{.name")
+	require.ErrorContains(t, err, "parsing template")
+}
+
+func TestGoTemplateFileFormatter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{upper .name}}"), 0600))
+
+	formatter, err := NewGoTemplateFileFormatter(path)
+	require.NoError(t, err)
+	require.Equal(t, GoTemplateFileFormat, formatter.Kind())
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, formatter.Format(map[string]string{"name": "api"}, buffer, nil))
+	require.Equal(t, "API\n", buffer.String())
+
+	_, err = NewGoTemplateFileFormatter(filepath.Join(t.TempDir(), "missing.tmpl"))
+	require.ErrorContains(t, err, "reading template")
+}
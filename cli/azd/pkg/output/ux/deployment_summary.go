@@ -0,0 +1,148 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ux
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// DeploymentSummary summarizes the resources provisioned and the services deployed by azd up
+type DeploymentSummary struct {
+	Environment       string
+	ProvisionDuration time.Duration
+	DeployDuration    time.Duration
+	Services          []DeployedService
+	Resources         []DeployedResource
+	ArmRequests       ArmRequestStats
+}
+
+// DeployedService is a service deployed by azd up
+type DeployedService struct {
+	Name string
+	Host string
+	// The deployed version of the service, ex) the container image or the package
+	Version   string
+	Endpoints []string
+	Duration  time.Duration
+}
+
+// ArmRequestStats counts the requests azd sent to ARM, and how they were rate limited
+type ArmRequestStats struct {
+	Requests int64
+	// The requests azd delayed to stay under the rate limits of their subscription
+	Delayed int64
+	Waited  time.Duration
+	// The requests ARM throttled with 429 responses
+	Throttled int64
+}
+
+// String returns the counters as text, ex) 312 (41 delayed by 12 seconds, 0 throttled)
+func (s ArmRequestStats) String() string {
+	if s.Delayed == 0 {
+		return fmt.Sprintf("%d (%d throttled)", s.Requests, s.Throttled)
+	}
+
+	return fmt.Sprintf("%d (%d delayed by %s, %d throttled)",
+		s.Requests, s.Delayed, DurationAsText(s.Waited), s.Throttled)
+}
+
+// DeployedResource is a resource provisioned by azd up
+type DeployedResource struct {
+	Type string
+	Name string
+}
+
+func (s *DeploymentSummary) ToString(currentIndentation string) string {
+	indentation := currentIndentation + "  "
+	builder := strings.Builder{}
+
+	builder.WriteString(fmt.Sprintf("%s%s\n", currentIndentation,
+		output.WithBold("Deployment summary (%s)", s.Environment)))
+	builder.WriteString(fmt.Sprintf("%sProvisioning: %s\n", indentation, DurationAsText(s.ProvisionDuration)))
+	builder.WriteString(fmt.Sprintf("%sDeployment: %s\n", indentation, DurationAsText(s.DeployDuration)))
+	if s.ArmRequests.Requests > 0 {
+		builder.WriteString(fmt.Sprintf("%sARM requests: %s\n", indentation, s.ArmRequests))
+	}
+
+	if len(s.Services) > 0 {
+		builder.WriteString(fmt.Sprintf("%sServices:\n", indentation))
+	}
+
+	for _, service := range s.Services {
+		builder.WriteString(fmt.Sprintf("%s  - %s (%s) in %s\n",
+			indentation, output.WithHighLightFormat(service.Name), service.Host, DurationAsText(service.Duration)))
+
+		if service.Version != "" {
+			builder.WriteString(fmt.Sprintf("%s    Version: %s\n", indentation, service.Version))
+		}
+
+		for _, endpoint := range service.Endpoints {
+			builder.WriteString(fmt.Sprintf("%s    Endpoint: %s\n", indentation, output.WithLinkFormat(endpoint)))
+		}
+	}
+
+	if len(s.Resources) > 0 {
+		builder.WriteString(fmt.Sprintf("%sProvisioned resources (%d):\n", indentation, len(s.Resources)))
+	}
+
+	for _, resource := range s.Resources {
+		builder.WriteString(fmt.Sprintf("%s  - %s: %s\n", indentation, resource.Type, resource.Name))
+	}
+
+	return builder.String()
+}
+
+func (s *DeploymentSummary) MarshalJSON() ([]byte, error) {
+	// reusing the same envelope from console messages
+	return json.Marshal(output.EventForMessage(s.ToString("")))
+}
+
+// Markdown returns the summary as Markdown, ex) for CI to comment on a pull request
+func (s *DeploymentSummary) Markdown() string {
+	builder := strings.Builder{}
+
+	builder.WriteString(fmt.Sprintf("## Deployment summary: `%s`\n\n", s.Environment))
+	builder.WriteString("| Step | Duration |\n|---|---|\n")
+	builder.WriteString(fmt.Sprintf("| Provisioning | %s |\n", DurationAsText(s.ProvisionDuration)))
+	builder.WriteString(fmt.Sprintf("| Deployment | %s |\n", DurationAsText(s.DeployDuration)))
+	if s.ArmRequests.Requests > 0 {
+		builder.WriteString(fmt.Sprintf("\nARM requests: %s\n", s.ArmRequests))
+	}
+
+	if len(s.Services) > 0 {
+		builder.WriteString("\n### Services\n\n")
+		builder.WriteString("| Service | Host | Version | Endpoints | Duration |\n|---|---|---|---|---|\n")
+	}
+
+	for _, service := range s.Services {
+		version := ""
+		if service.Version != "" {
+			version = fmt.Sprintf("`%s`", service.Version)
+		}
+
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			service.Name,
+			service.Host,
+			version,
+			strings.Join(service.Endpoints, "<br>"),
+			DurationAsText(service.Duration),
+		))
+	}
+
+	if len(s.Resources) > 0 {
+		builder.WriteString(fmt.Sprintf("\n### Provisioned resources (%d)\n\n", len(s.Resources)))
+		builder.WriteString("| Type | Name |\n|---|---|\n")
+	}
+
+	for _, resource := range s.Resources {
+		builder.WriteString(fmt.Sprintf("| %s | %s |\n", resource.Type, resource.Name))
+	}
+
+	return builder.String()
+}
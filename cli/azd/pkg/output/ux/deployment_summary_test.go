@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentSummaryMarkdown(t *testing.T) {
+	summary := &DeploymentSummary{
+		Environment:       "dev",
+		ProvisionDuration: 2*time.Minute + 5*time.Second,
+		DeployDuration:    45 * time.Second,
+		Services: []DeployedService{
+			{
+				Name:      "api",
+				Host:      "containerapp",
+				Version:   "registry.azurecr.io/app/api-dev:azd-deploy-1",
+				Endpoints: []string{"https://api.example.com/", "https://api-internal.example.com/"},
+				Duration:  30 * time.Second,
+			},
+			{
+				Name:     "web",
+				Host:     "appservice",
+				Duration: 15 * time.Second,
+			},
+		},
+		Resources: []DeployedResource{
+			{Type: "Container App", Name: "ca-api"},
+		},
+	}
+
+	expected := "## Deployment summary: `dev`\n\n" +
+		"| Step | Duration |\n|---|---|\n" +
+		"| Provisioning | 2 minutes 5 seconds |\n" +
+		"| Deployment | 45 seconds |\n" +
+		"\n### Services\n\n" +
+		"| Service | Host | Version | Endpoints | Duration |\n|---|---|---|---|---|\n" +
+		"| api | containerapp | `registry.azurecr.io/app/api-dev:azd-deploy-1` | " +
+		"https://api.example.com/<br>https://api-internal.example.com/ | 30 seconds |\n" +
+		"| web | appservice |  |  | 15 seconds |\n" +
+		"\n### Provisioned resources (1)\n\n" +
+		"| Type | Name |\n|---|---|\n" +
+		"| Container App | ca-api |\n"
+
+	require.Equal(t, expected, summary.Markdown())
+}
+
+func TestDeploymentSummaryToString(t *testing.T) {
+	summary := &DeploymentSummary{
+		Environment:    "dev",
+		DeployDuration: 45 * time.Second,
+		Services: []DeployedService{
+			{Name: "api", Host: "containerapp", Endpoints: []string{"https://api.example.com/"}},
+		},
+	}
+
+	text := summary.ToString("")
+	require.Contains(t, text, "Deployment summary (dev)")
+	require.Contains(t, text, "Deployment: 45 seconds")
+	require.Contains(t, text, "Endpoint: ")
+	require.NotContains(t, text, "Provisioned resources")
+	require.NotContains(t, text, "ARM requests")
+
+	summary.ArmRequests = ArmRequestStats{Requests: 312, Delayed: 41, Waited: 12 * time.Second, Throttled: 1}
+	require.Contains(t, summary.ToString(""), "ARM requests: 312 (41 delayed by 12 seconds, 1 throttled)")
+	require.Contains(t, summary.Markdown(), "\nARM requests: 312 (41 delayed by 12 seconds, 1 throttled)\n")
+}
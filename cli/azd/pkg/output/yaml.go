@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YamlFormatter struct {
+}
+
+func (f *YamlFormatter) Kind() Format {
+	return YamlFormat
+}
+
+// Format writes the json representation of the object as yaml, for the fields to have the names of the json format
+func (f *YamlFormatter) Format(obj interface{}, writer io.Writer, _ interface{}) error {
+	value, err := jsonValue(obj)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+var _ Formatter = (*YamlFormatter)(nil)
+
+// jsonValue converts the object to its json representation, ex) maps keyed by the json names of the fields, for the
+// formats other than json to have the same fields as json
+func jsonValue(obj interface{}) (interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
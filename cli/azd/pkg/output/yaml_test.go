@@ -0,0 +1,39 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type yamlInput struct {
+	Name      string   `json:"name"`
+	Endpoints []string `json:"endpoints"`
+	Replicas  int      `json:"replicas,omitempty"`
+}
+
+func TestYamlFormatter(t *testing.T) {
+	obj := map[string]yamlInput{
+		"api": {Name: "api", Endpoints: []string{"https://api.contoso.com"}, Replicas: 2},
+		"web": {Name: "web", Endpoints: []string{}},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := (&YamlFormatter{}).Format(obj, buffer, nil)
+	require.NoError(t, err)
+
+	expected := `api:
+  endpoints:
+    - https://api.contoso.com
+  name: api
+  replicas: 2
+web:
+  endpoints: []
+  name: web
+`
+	require.Equal(t, expected, buffer.String())
+}
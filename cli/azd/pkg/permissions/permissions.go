@@ -0,0 +1,155 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package permissions evaluates whether the signed-in principal is allowed to perform the Azure actions azd performs for
+// a project, ex) creating resource groups or pushing container images.
+package permissions
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/slices"
+)
+
+// Requirement is an Azure action azd performs for the project.
+type Requirement struct {
+	// What azd performs the action for, ex) Create resource groups
+	Description string `json:"description"`
+	// The action, ex) Microsoft.Resources/subscriptions/resourceGroups/write
+	Action string `json:"action"`
+	// The scope azd performs the action in
+	Scope string `json:"scope"`
+}
+
+// Check is whether the signed-in principal is allowed to perform the action of a requirement.
+type Check struct {
+	Requirement
+	Allowed bool `json:"allowed"`
+}
+
+// Result lists the checks of the requirements of the project.
+type Result struct {
+	Checks []*Check `json:"checks"`
+}
+
+// Missing lists the checks of the actions the signed-in principal isn't allowed to perform.
+func (r *Result) Missing() []*Check {
+	missing := []*Check{}
+	for _, check := range r.Checks {
+		if !check.Allowed {
+			missing = append(missing, check)
+		}
+	}
+
+	return missing
+}
+
+// Requirements lists the actions azd performs for the project in the subscription. The actions of services are
+// required in the resource group when it's known, ex) after provisioning, or in the subscription otherwise.
+func Requirements(projectConfig *project.ProjectConfig, subscriptionId string, resourceGroupName string) []Requirement {
+	subscriptionScope := azure.SubscriptionRID(subscriptionId)
+	servicesScope := subscriptionScope
+	if resourceGroupName != "" {
+		servicesScope = azure.ResourceGroupRID(subscriptionId, resourceGroupName)
+	}
+
+	requirements := []Requirement{
+		{
+			Description: "Create resource groups",
+			Action:      "Microsoft.Resources/subscriptions/resourceGroups/write",
+			Scope:       subscriptionScope,
+		},
+		{
+			Description: "Deploy infrastructure",
+			Action:      "Microsoft.Resources/deployments/write",
+			Scope:       subscriptionScope,
+		},
+		{
+			Description: "Create role assignments",
+			Action:      "Microsoft.Authorization/roleAssignments/write",
+			Scope:       subscriptionScope,
+		},
+	}
+
+	pushesImages := false
+	getsAksCredentials := false
+	for _, serviceConfig := range projectConfig.Services {
+		switch serviceConfig.Host {
+		case project.ContainerAppTarget:
+			pushesImages = true
+		case project.AksTarget:
+			pushesImages = true
+			getsAksCredentials = true
+		}
+	}
+
+	if pushesImages {
+		requirements = append(requirements, Requirement{
+			Description: "Push images to Azure Container Registry",
+			Action:      "Microsoft.ContainerRegistry/registries/push/write",
+			Scope:       servicesScope,
+		})
+	}
+
+	if getsAksCredentials {
+		requirements = append(requirements, Requirement{
+			Description: "Get AKS cluster credentials",
+			Action:      "Microsoft.ContainerService/managedClusters/listClusterAdminCredential/action",
+			Scope:       servicesScope,
+		})
+	}
+
+	return requirements
+}
+
+// Manager evaluates the permissions of the signed-in principal.
+type Manager struct {
+	azCli azcli.AzCli
+}
+
+func NewManager(azCli azcli.AzCli) *Manager {
+	return &Manager{
+		azCli: azCli,
+	}
+}
+
+// Check evaluates whether the signed-in principal is allowed to perform the actions azd performs for the project in the
+// subscription and resource group, which is empty when it isn't known yet.
+func (m *Manager) Check(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	subscriptionId string,
+	resourceGroupName string,
+) (*Result, error) {
+	result := &Result{
+		Checks: []*Check{},
+	}
+
+	// the permissions of each scope are listed once
+	permissionsByScope := map[string][]azcli.AzCliPermission{}
+
+	for _, requirement := range Requirements(projectConfig, subscriptionId, resourceGroupName) {
+		permissions, has := permissionsByScope[requirement.Scope]
+		if !has {
+			var err error
+			permissions, err = m.azCli.ListPermissions(ctx, subscriptionId, requirement.Scope)
+			if err != nil {
+				return nil, err
+			}
+
+			permissionsByScope[requirement.Scope] = permissions
+		}
+
+		result.Checks = append(result.Checks, &Check{
+			Requirement: requirement,
+			Allowed: slices.ContainsFunc(permissions, func(permission azcli.AzCliPermission) bool {
+				return permission.Allows(requirement.Action)
+			}),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package permissions
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Check(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	scopes := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Authorization/permissions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		scope := strings.TrimSuffix(request.URL.Path, "/providers/Microsoft.Authorization/permissions")
+		scopes = append(scopes, scope)
+
+		// Contributor in the subscription
+		permissions := []map[string]any{
+			{
+				"actions":    []string{"*"},
+				"notActions": []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+			},
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": permissions,
+		})
+	})
+
+	projectConfig := &project.ProjectConfig{
+		Name: "app",
+		Services: map[string]*project.ServiceConfig{
+			"web": {Name: "web", Host: project.AppServiceTarget},
+			"api": {Name: "api", Host: project.AksTarget},
+		},
+	}
+
+	manager := NewManager(mockazcli.NewAzCliFromMockContext(mockContext))
+	result, err := manager.Check(*mockContext.Context, projectConfig, "SUB", "rg-dev")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/subscriptions/SUB", "/subscriptions/SUB/resourceGroups/rg-dev"}, scopes)
+	require.Len(t, result.Checks, 5)
+
+	missing := result.Missing()
+	require.Len(t, missing, 1)
+	require.Equal(t, "Microsoft.Authorization/roleAssignments/write", missing[0].Action)
+	require.Equal(t, "/subscriptions/SUB", missing[0].Scope)
+}
+
+func Test_Requirements(t *testing.T) {
+	projectConfig := &project.ProjectConfig{
+		Services: map[string]*project.ServiceConfig{
+			"api": {Name: "api", Host: project.ContainerAppTarget},
+		},
+	}
+
+	requirements := Requirements(projectConfig, "SUB", "")
+	require.Len(t, requirements, 4)
+	require.Equal(t, "Microsoft.ContainerRegistry/registries/push/write", requirements[3].Action)
+	// the resource group isn't known before provisioning
+	require.Equal(t, "/subscriptions/SUB", requirements[3].Scope)
+}
@@ -775,6 +775,21 @@ func (p *AzdoCiProvider) configurePipeline(
 	}
 	details.buildDefinition = buildDefinition
 
+	// the pipeline deploys to the Azure DevOps environment of the azd environment, which records the deployment history
+	// and gates the deployments on the approvals and checks configured on it
+	azdoEnvironment, err := azdo.CreateOrUpdateEnvironment(ctx, connection, details.projectId, p.Env, p.console)
+	if err != nil {
+		return nil, err
+	}
+	p.console.MessageUxItem(ctx, &ux.MultilineMessage{
+		Lines: []string{
+			"",
+			fmt.Sprintf("Deployments to the Azure DevOps environment %s are recorded at %s.",
+				output.WithHighLightFormat(azdoEnvironment.Name), output.WithLinkFormat(azdoEnvironment.WebUrl)),
+			"Add approvals and checks to the environment to gate the deployments of the pipeline.",
+			""},
+	})
+
 	repoUrl := details.repoWebUrl
 	repoPrefix := strings.Split(repoUrl, "_git")[0]
 	pipelineUrl := fmt.Sprintf("%s_build?definitionId=%d", repoPrefix, *buildDefinition.Id)
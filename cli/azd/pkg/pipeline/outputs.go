@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// PublishOutputs publishes the values of the environment named by outputs, as the outputs of the GitHub Actions step,
+// or as the output variables of the Azure Pipelines step, azd runs in. Names can contain * wildcards, ex)
+// SERVICE_*_ENDPOINT_URL. Values flagged secure are masked in the logs of the run. Nothing is published outside of
+// GitHub Actions and Azure Pipelines.
+//
+// The logging commands of the CI systems are written to stdout.
+func PublishOutputs(env *environment.Environment, outputs []string, stdout io.Writer) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	values := map[string]string{}
+	for name, value := range env.Dotenv() {
+		if slices.ContainsFunc(outputs, func(pattern string) bool {
+			matched, err := path.Match(pattern, name)
+			return err == nil && matched
+		}) {
+			values[name] = value
+		}
+	}
+
+	names := maps.Keys(values)
+	slices.Sort(names)
+
+	switch {
+	case strings.EqualFold(os.Getenv("GITHUB_ACTIONS"), "true") && os.Getenv("GITHUB_OUTPUT") != "":
+		return publishGitHubOutputs(os.Getenv("GITHUB_OUTPUT"), names, values, stdout)
+	case strings.EqualFold(os.Getenv("TF_BUILD"), "true"):
+		publishAzdoOutputs(names, values, stdout)
+	}
+
+	return nil
+}
+
+// publishGitHubOutputs appends the values to the step outputs file of GitHub Actions. Secure values are masked first.
+func publishGitHubOutputs(outputsFile string, names []string, values map[string]string, stdout io.Writer) error {
+	file, err := os.OpenFile(outputsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, osutil.PermissionFile)
+	if err != nil {
+		return fmt.Errorf("opening step outputs file: %w", err)
+	}
+	defer file.Close()
+
+	for _, name := range names {
+		value := values[name]
+		if redact.IsSecret(value) {
+			fmt.Fprintf(stdout, "::add-mask::%s\n", value)
+		}
+
+		output := fmt.Sprintf("%s=%s\n", name, value)
+		// multi-line values are delimited by a random string, which can't be part of the value
+		if strings.ContainsAny(value, "\r\n") {
+			delimiter, err := randomDelimiter()
+			if err != nil {
+				return err
+			}
+			output = fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+		}
+
+		if _, err := file.WriteString(output); err != nil {
+			return fmt.Errorf("writing step output '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// publishAzdoOutputs sets the values as output variables of the Azure Pipelines step. Secure values are set as secret
+// variables.
+func publishAzdoOutputs(names []string, values map[string]string, stdout io.Writer) {
+	for _, name := range names {
+		value := values[name]
+		// logging commands are single lines
+		if strings.ContainsAny(value, "\r\n") {
+			log.Printf("skipping output variable '%s', multi-line values are not supported", name)
+			continue
+		}
+
+		fmt.Fprintf(stdout, "##vso[task.setvariable variable=%s;isOutput=true;issecret=%t]%s\n",
+			name, redact.IsSecret(value), value)
+	}
+}
+
+func randomDelimiter() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return "azd_" + hex.EncodeToString(bytes), nil
+}
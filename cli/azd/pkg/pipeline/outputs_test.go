@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PublishOutputs(t *testing.T) {
+	redact.AddSecret("S3cr3tK3y")
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		"WEBSITE_URL":                 "https://web.azurewebsites.net",
+		"SERVICE_API_ENDPOINT_URL":    "https://api.azurewebsites.net",
+		"AZURE_OPENAI_KEY":            "S3cr3tK3y",
+		"AZURE_OPENAI_DEPLOYMENTS":    "line1\nline2",
+		"AZURE_CONTAINER_REGISTRY":    "contoso.azurecr.io",
+		"SERVICE_API_RESOURCE_EXISTS": "true",
+	})
+	outputs := []string{"WEBSITE_URL", "SERVICE_*_ENDPOINT_URL", "AZURE_OPENAI_*"}
+
+	t.Run("GitHubActions", func(t *testing.T) {
+		outputsFile := filepath.Join(t.TempDir(), "output")
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("GITHUB_OUTPUT", outputsFile)
+		t.Setenv("TF_BUILD", "")
+
+		stdout := &bytes.Buffer{}
+		require.NoError(t, PublishOutputs(env, outputs, stdout))
+		require.Equal(t, "::add-mask::S3cr3tK3y\n", stdout.String())
+
+		content, err := os.ReadFile(outputsFile)
+		require.NoError(t, err)
+		lines := strings.Split(string(content), "\n")
+		require.Regexp(t, "^AZURE_OPENAI_DEPLOYMENTS<<azd_[0-9a-f]+$", lines[0])
+		delimiter := strings.TrimPrefix(lines[0], "AZURE_OPENAI_DEPLOYMENTS<<")
+		require.Equal(t, []string{
+			"line1",
+			"line2",
+			delimiter,
+			"AZURE_OPENAI_KEY=S3cr3tK3y",
+			"SERVICE_API_ENDPOINT_URL=https://api.azurewebsites.net",
+			"WEBSITE_URL=https://web.azurewebsites.net",
+			"",
+		}, lines[1:])
+	})
+
+	t.Run("AzurePipelines", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("TF_BUILD", "True")
+
+		stdout := &bytes.Buffer{}
+		require.NoError(t, PublishOutputs(env, outputs, stdout))
+		require.Equal(t,
+			"##vso[task.setvariable variable=AZURE_OPENAI_KEY;isOutput=true;issecret=true]S3cr3tK3y\n"+
+				"##vso[task.setvariable variable=SERVICE_API_ENDPOINT_URL;isOutput=true;issecret=false]"+
+				"https://api.azurewebsites.net\n"+
+				"##vso[task.setvariable variable=WEBSITE_URL;isOutput=true;issecret=false]https://web.azurewebsites.net\n",
+			stdout.String())
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("TF_BUILD", "")
+
+		stdout := &bytes.Buffer{}
+		require.NoError(t, PublishOutputs(env, outputs, stdout))
+		require.Empty(t, stdout.String())
+	})
+}
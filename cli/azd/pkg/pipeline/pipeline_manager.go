@@ -5,6 +5,7 @@ package pipeline
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -23,7 +24,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
-	"github.com/sethvargo/go-retry"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 	"golang.org/x/exp/slices"
 )
 
@@ -157,6 +159,11 @@ func (pm *PipelineManager) Configure(ctx context.Context) (result *PipelineConfi
 		return result, fmt.Errorf("failed to create or update service principal: %w", err)
 	}
 
+	// The service principal is the pipeline principal of the role assignments of azure.yaml
+	if err := pm.savePipelinePrincipalId(ctx, credentials); err != nil {
+		log.Printf("failed saving the object id of the service principal: %v", err)
+	}
+
 	repoSlug := gitRepoInfo.owner + "/" + gitRepoInfo.repoName
 	displayMsg = fmt.Sprintf(
 		"Configuring repository %s to use credentials for %s", repoSlug, pm.args.PipelineServicePrincipalName)
@@ -240,6 +247,42 @@ func (pm *PipelineManager) Configure(ctx context.Context) (result *PipelineConfi
 	}, nil
 }
 
+// UpdateCredentials configures the repository of an already configured pipeline to use the client credentials, ex)
+// after the secret of the service principal of the pipeline was rotated. No service principal is created and nothing is
+// pushed to the repository.
+func (pm *PipelineManager) UpdateCredentials(ctx context.Context, credentials json.RawMessage) error {
+	requiredTools, err := pm.requiredTools(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tools.EnsureInstalled(ctx, requiredTools...); err != nil {
+		return err
+	}
+
+	prj, err := project.LoadFs(ctx, pm.azdCtx.Fs(), pm.azdCtx.ProjectPath())
+	if err != nil {
+		return fmt.Errorf("finding provisioning provider: %w", err)
+	}
+
+	pm.args.PipelineAuthTypeName = string(AuthTypeClientCredentials)
+	if _, err := pm.preConfigureCheck(ctx, prj.Infra, pm.azdCtx.ProjectDirectory()); err != nil {
+		return err
+	}
+
+	gitRepoInfo, err := pm.ensureRemote(ctx, pm.azdCtx.ProjectDirectory(), pm.args.PipelineRemoteName)
+	if err != nil {
+		return fmt.Errorf("finding the repository of the pipeline: %w", err)
+	}
+
+	repoSlug := gitRepoInfo.owner + "/" + gitRepoInfo.repoName
+	displayMsg := fmt.Sprintf("Updating the credentials of repository %s", repoSlug)
+	pm.console.ShowSpinner(ctx, displayMsg, input.Step)
+
+	err = pm.ciProvider.configureConnection(ctx, gitRepoInfo, prj.Infra, credentials, AuthTypeClientCredentials)
+	pm.console.StopSpinner(ctx, displayMsg, input.GetStepResultFormat(err))
+	return err
+}
+
 // requiredTools get all the provider's required tools.
 func (pm *PipelineManager) requiredTools(ctx context.Context) ([]tools.ExternalTool, error) {
 	scmReqTools, err := pm.scmProvider.requiredTools(ctx)
@@ -406,14 +449,14 @@ func (pm *PipelineManager) pushGitRepo(ctx context.Context, gitRepoInfo *gitRepo
 	// and the credentials are rotated, the push operation will fail and the credential manager would remove the cache
 	// Then, on the next intent to push code, there should be a prompt for credentials.
 	// Due to this, we use retry here, so we can run the second intent to prompt for credentials one more time
-	return retry.Do(ctx, retry.WithMaxRetries(3, retry.NewConstant(100*time.Millisecond)), func(ctx context.Context) error {
+	return wait.Retry(ctx, clock.New(), wait.ConstantRetry(3, 100*time.Millisecond), func(ctx context.Context) error {
 		if err := pm.scmProvider.GitPush(
 			ctx,
 			pm.gitCli,
 			gitRepoInfo,
 			pm.args.PipelineRemoteName,
 			currentBranch); err != nil {
-			return retry.RetryableError(fmt.Errorf("pushing changes: %w", err))
+			return wait.RetryableError(fmt.Errorf("pushing changes: %w", err))
 		}
 		return nil
 	})
@@ -539,3 +582,19 @@ func (pm *PipelineManager) initialize(ctx context.Context, override string) erro
 
 	return nil
 }
+
+// savePipelinePrincipalId saves the object id of the service principal of the pipeline to the environment
+func (pm *PipelineManager) savePipelinePrincipalId(ctx context.Context, credentials json.RawMessage) error {
+	var azureCredentials azcli.AzureCredentials
+	if err := json.Unmarshal(credentials, &azureCredentials); err != nil {
+		return err
+	}
+
+	principalId, err := pm.azCli.GetServicePrincipalId(ctx, pm.env.GetSubscriptionId(), azureCredentials.ClientId)
+	if err != nil {
+		return err
+	}
+
+	pm.env.DotenvSet(environment.PipelinePrincipalIdEnvVarName, principalId)
+	return pm.env.Save()
+}
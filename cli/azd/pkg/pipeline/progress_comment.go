@@ -0,0 +1,227 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/github"
+)
+
+// ErrProgressCommentUnavailable is returned when azd doesn't run in a GitHub Actions or Azure Pipelines run of a pull
+// request, with a token to comment on it
+var ErrProgressCommentUnavailable = errors.New(
+	"not running for a pull request in GitHub Actions with GITHUB_TOKEN set, or in Azure Pipelines with " +
+		"SYSTEM_ACCESSTOKEN set")
+
+// ProgressState is the state of a step reported by a progress comment
+type ProgressState string
+
+const (
+	ProgressPending    ProgressState = "Pending"
+	ProgressInProgress ProgressState = "In progress"
+	ProgressSucceeded  ProgressState = "Succeeded"
+	ProgressFailed     ProgressState = "Failed"
+)
+
+// pullRequestCommenter creates or updates the comment of a pull request containing a marker
+type pullRequestCommenter interface {
+	UpsertComment(ctx context.Context, marker string, body string) error
+}
+
+// progressStep is a step of azd up reported by a progress comment, ex) the deployment of a service
+type progressStep struct {
+	name   string
+	state  ProgressState
+	detail string
+}
+
+// ProgressComment reports the live progress of azd up in a single comment of the pull request the CI run builds: the
+// percentage of the resources provisioned, then the state of the deployment of each service. The comment is updated
+// every time a step progresses, so that reviewers can follow the deployment from the pull request. Failing to update
+// the comment never fails azd up.
+//
+// The methods of a nil ProgressComment do nothing.
+type ProgressComment struct {
+	commenter pullRequestCommenter
+	envName   string
+	now       func() time.Time
+
+	// Serializes the updates of the comment, reported concurrently while provisioning
+	updating sync.Mutex
+
+	mu        sync.Mutex
+	provision progressStep
+	services  []*progressStep
+	summary   string
+}
+
+// NewProgressCommentFromEnv creates the progress comment of the environment, on the pull request of the GitHub Actions
+// or Azure Pipelines run azd runs in. ErrProgressCommentUnavailable is returned outside of the runs of pull requests.
+func NewProgressCommentFromEnv(envName string) (*ProgressComment, error) {
+	var commenter pullRequestCommenter
+	if client, err := github.NewPullRequestCommentsClientFromEnv(nil); err == nil {
+		commenter = client
+	} else if client, err := azdo.NewPullRequestCommentsClientFromEnv(nil); err == nil {
+		commenter = client
+	} else {
+		return nil, ErrProgressCommentUnavailable
+	}
+
+	return newProgressComment(commenter, envName), nil
+}
+
+func newProgressComment(commenter pullRequestCommenter, envName string) *ProgressComment {
+	return &ProgressComment{
+		commenter: commenter,
+		envName:   envName,
+		now:       time.Now,
+		provision: progressStep{name: "Provisioning", state: ProgressPending},
+	}
+}
+
+// SetServices sets the services deployed by azd up, pending until their deployment starts
+func (p *ProgressComment) SetServices(ctx context.Context, names []string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.services = make([]*progressStep, 0, len(names))
+	for _, name := range names {
+		p.services = append(p.services, &progressStep{name: name, state: ProgressPending})
+	}
+	p.mu.Unlock()
+
+	p.update(ctx)
+}
+
+// SetProvisioning sets the state of the provisioning of the infrastructure
+func (p *ProgressComment) SetProvisioning(ctx context.Context, state ProgressState) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.provision.state = state
+	if state != ProgressInProgress {
+		p.provision.detail = ""
+	}
+	p.mu.Unlock()
+
+	p.update(ctx)
+}
+
+// ObserveProvisioning reports the percentage of the resources provisioned so far. It is a provisioning.ProgressObserver.
+func (p *ProgressComment) ObserveProvisioning(ctx context.Context, deployed int, total int) {
+	if p == nil || total == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	detail := fmt.Sprintf("%d%% (%d/%d resources)", deployed*100/total, deployed, total)
+	changed := p.provision.detail != detail
+	p.provision.state = ProgressInProgress
+	p.provision.detail = detail
+	p.mu.Unlock()
+
+	if changed {
+		p.update(ctx)
+	}
+}
+
+// SetService sets the state of the deployment of the service. The detail, when not empty, is displayed along with the
+// state, ex) the endpoint of the service once deployed.
+func (p *ProgressComment) SetService(ctx context.Context, name string, state ProgressState, detail string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	var step *progressStep
+	for _, service := range p.services {
+		if service.name == name {
+			step = service
+		}
+	}
+
+	if step == nil {
+		step = &progressStep{name: name}
+		p.services = append(p.services, step)
+	}
+
+	step.state = state
+	step.detail = detail
+	p.mu.Unlock()
+
+	p.update(ctx)
+}
+
+// Complete appends the summary, ex) the deployment summary of azd up, to the comment
+func (p *ProgressComment) Complete(ctx context.Context, summary string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.summary = summary
+	p.mu.Unlock()
+
+	p.update(ctx)
+}
+
+// marker identifies the comment of the environment among the comments of the pull request
+func (p *ProgressComment) marker() string {
+	return fmt.Sprintf("<!-- azd-progress:%s -->", p.envName)
+}
+
+// Markdown returns the body of the comment
+func (p *ProgressComment) Markdown() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	builder := strings.Builder{}
+	builder.WriteString(p.marker() + "\n")
+	builder.WriteString(fmt.Sprintf("## azd up: `%s`\n\n", p.envName))
+	builder.WriteString("| Step | State |\n|---|---|\n")
+
+	steps := append([]*progressStep{&p.provision}, p.services...)
+	for i, step := range steps {
+		name := step.name
+		if i > 0 {
+			name = fmt.Sprintf("Deploy service `%s`", step.name)
+		}
+
+		state := string(step.state)
+		if step.detail != "" {
+			state = fmt.Sprintf("%s: %s", state, step.detail)
+		}
+
+		builder.WriteString(fmt.Sprintf("| %s | %s |\n", name, state))
+	}
+
+	builder.WriteString(fmt.Sprintf("\n_Updated %s_\n", p.now().UTC().Format("2006-01-02 15:04:05 UTC")))
+
+	if p.summary != "" {
+		builder.WriteString("\n" + p.summary)
+	}
+
+	return builder.String()
+}
+
+func (p *ProgressComment) update(ctx context.Context) {
+	p.updating.Lock()
+	defer p.updating.Unlock()
+
+	if err := p.commenter.UpsertComment(ctx, p.marker(), p.Markdown()); err != nil {
+		log.Printf("failed updating the progress comment of the pull request: %v", err)
+	}
+}
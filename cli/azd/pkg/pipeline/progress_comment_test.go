@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCommenter struct {
+	marker string
+	bodies []string
+	err    error
+}
+
+func (c *testCommenter) UpsertComment(ctx context.Context, marker string, body string) error {
+	c.marker = marker
+	c.bodies = append(c.bodies, body)
+	return c.err
+}
+
+func Test_ProgressComment(t *testing.T) {
+	ctx := context.Background()
+	commenter := &testCommenter{}
+	comment := newProgressComment(commenter, "dev")
+	comment.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+	comment.SetProvisioning(ctx, ProgressInProgress)
+	comment.ObserveProvisioning(ctx, 1, 4)
+	// Unchanged progress doesn't update the comment
+	comment.ObserveProvisioning(ctx, 1, 4)
+	require.Len(t, commenter.bodies, 2)
+	require.Contains(t, commenter.bodies[1], "| Provisioning | In progress: 25% (1/4 resources) |")
+
+	comment.SetProvisioning(ctx, ProgressSucceeded)
+	comment.SetServices(ctx, []string{"api", "web"})
+	comment.SetService(ctx, "api", ProgressSucceeded, "https://api.contoso.com")
+	comment.SetService(ctx, "web", ProgressFailed, "")
+
+	require.Equal(t, "<!-- azd-progress:dev -->", commenter.marker)
+	require.Equal(t,
+		"<!-- azd-progress:dev -->\n"+
+			"## azd up: `dev`\n\n"+
+			"| Step | State |\n|---|---|\n"+
+			"| Provisioning | Succeeded |\n"+
+			"| Deploy service `api` | Succeeded: https://api.contoso.com |\n"+
+			"| Deploy service `web` | Failed |\n"+
+			"\n_Updated 2024-05-01 10:00:00 UTC_\n",
+		commenter.bodies[len(commenter.bodies)-1])
+
+	comment.Complete(ctx, "## Deployment summary: `dev`\n")
+	require.Contains(t, commenter.bodies[len(commenter.bodies)-1], "UTC_\n\n## Deployment summary: `dev`\n")
+}
+
+func Test_ProgressComment_Errors(t *testing.T) {
+	// Failing to update the comment doesn't fail the deployment
+	commenter := &testCommenter{err: errors.New("forbidden")}
+	comment := newProgressComment(commenter, "dev")
+	comment.SetProvisioning(context.Background(), ProgressInProgress)
+	require.Len(t, commenter.bodies, 1)
+
+	// The methods of a nil comment do nothing
+	var disabled *ProgressComment
+	disabled.SetProvisioning(context.Background(), ProgressInProgress)
+	disabled.SetService(context.Background(), "api", ProgressSucceeded, "")
+}
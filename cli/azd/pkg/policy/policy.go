@@ -0,0 +1,243 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package policy provides an optional gate that evaluates rendered infrastructure templates and Kubernetes manifests
+// against Rego policies before they are applied.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/opa"
+	"github.com/azure/azure-dev/cli/azd/resources"
+)
+
+// The policy options supported in azure.yaml
+type Options struct {
+	// Enables policy evaluation before provisioning and deployment
+	Enabled bool `yaml:"enabled"`
+	// Additional files or directories containing Rego policies, relative to the project root
+	Paths []string `yaml:"paths,omitempty"`
+	// Excludes the policies bundled with azd from evaluation
+	DisableBuiltIn bool `yaml:"disableBuiltIn,omitempty"`
+	// Values exposed to policies as `data.settings`, ex) requiredTags, allowedRegistries, allowPublicIp
+	Settings map[string]any `yaml:"settings,omitempty"`
+	// Evaluates the Azure Policy assignments of the subscription against the planned resources before provisioning, and
+	// warns about the resources they would deny. Independent of the Rego policies enabled by Enabled.
+	SimulateAssignments bool `yaml:"simulateAssignments,omitempty"`
+}
+
+// Kind identifies the type of document being evaluated. Policies for each kind are expected to be defined
+// in the `azd.policy.<kind>` package with a `deny` rule.
+type Kind string
+
+const (
+	// A rendered ARM template, the input document is `{"template": ..., "parameters": ...}`
+	KindArm Kind = "arm"
+	// A set of Kubernetes manifests, the input document is `{"service": ..., "manifests": [...]}`
+	KindK8s Kind = "k8s"
+)
+
+// Violation is a single policy violation returned from a `deny` rule
+type Violation struct {
+	Policy   string `json:"policy"`
+	Resource string `json:"resource"`
+	Message  string `json:"msg"`
+}
+
+// ViolationsError is returned when one or more policies deny the evaluated document
+type ViolationsError struct {
+	Kind       Kind
+	Violations []Violation
+}
+
+func (e *ViolationsError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d policy violation(s) found in %s documents:", len(e.Violations), string(e.Kind)))
+
+	for _, violation := range e.Violations {
+		sb.WriteString("\n  - ")
+		if violation.Policy != "" {
+			sb.WriteString(fmt.Sprintf("[%s] ", violation.Policy))
+		}
+		if violation.Resource != "" {
+			sb.WriteString(fmt.Sprintf("%s: ", violation.Resource))
+		}
+		sb.WriteString(violation.Message)
+	}
+
+	return sb.String()
+}
+
+// Engine evaluates documents against the built-in and user-provided Rego policies
+type Engine struct {
+	opaCli opa.OpaCli
+}
+
+// NewEngine creates a new instance of the policy Engine
+func NewEngine(opaCli opa.OpaCli) *Engine {
+	return &Engine{
+		opaCli: opaCli,
+	}
+}
+
+// Check evaluates the input document and returns a *ViolationsError when any policy denies it.
+// Check is a no-op when the options are nil or policies are not enabled.
+func (e *Engine) Check(ctx context.Context, projectPath string, options *Options, kind Kind, input any) error {
+	violations, err := e.Evaluate(ctx, projectPath, options, kind, input)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return &ViolationsError{Kind: kind, Violations: violations}
+	}
+
+	return nil
+}
+
+// Evaluate runs the `deny` rules for the specified kind against the input document and returns any violations.
+func (e *Engine) Evaluate(
+	ctx context.Context,
+	projectPath string,
+	options *Options,
+	kind Kind,
+	input any,
+) ([]Violation, error) {
+	if options == nil || !options.Enabled {
+		return nil, nil
+	}
+
+	if err := tools.EnsureInstalled(ctx, e.opaCli); err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp("", "azd-policy")
+	if err != nil {
+		return nil, fmt.Errorf("creating policy working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	dataPaths := []string{}
+
+	if !options.DisableBuiltIn {
+		builtInPath := filepath.Join(workDir, "builtin")
+		if err := extractBuiltInPolicies(builtInPath); err != nil {
+			return nil, err
+		}
+
+		dataPaths = append(dataPaths, builtInPath)
+	}
+
+	for _, path := range options.Paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectPath, path)
+		}
+
+		dataPaths = append(dataPaths, path)
+	}
+
+	settings := options.Settings
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	settingsPath := filepath.Join(workDir, "settings.json")
+	if err := writeJson(settingsPath, map[string]any{"settings": settings}); err != nil {
+		return nil, fmt.Errorf("writing policy settings: %w", err)
+	}
+	dataPaths = append(dataPaths, settingsPath)
+
+	inputPath := filepath.Join(workDir, "input.json")
+	if err := writeJson(inputPath, input); err != nil {
+		return nil, fmt.Errorf("writing policy input: %w", err)
+	}
+
+	query := fmt.Sprintf("data.azd.policy.%s.deny", kind)
+	log.Printf("evaluating policy query '%s' with data paths: %v", query, dataPaths)
+
+	res, err := e.opaCli.Eval(ctx, query, dataPaths, inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating policies: %w", err)
+	}
+
+	return parseViolations(res)
+}
+
+// The subset of the `opa eval --format json` output azd is interested in
+type evalOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value []json.RawMessage `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// parseViolations converts the `opa eval` output into violations. Deny rules may produce either plain
+// string messages or objects with `policy`, `resource` and `msg` fields.
+func parseViolations(output string) ([]Violation, error) {
+	var result evalOutput
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("parsing policy evaluation result: %w", err)
+	}
+
+	violations := []Violation{}
+	for _, res := range result.Result {
+		for _, expr := range res.Expressions {
+			for _, raw := range expr.Value {
+				var message string
+				if err := json.Unmarshal(raw, &message); err == nil {
+					violations = append(violations, Violation{Message: message})
+					continue
+				}
+
+				var violation Violation
+				if err := json.Unmarshal(raw, &violation); err != nil {
+					return nil, fmt.Errorf("parsing policy violation '%s': %w", string(raw), err)
+				}
+
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+func extractBuiltInPolicies(target string) error {
+	return fs.WalkDir(resources.Policies, "policies", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		targetPath := filepath.Join(target, strings.TrimPrefix(path, "policies"))
+		if d.IsDir() {
+			return os.MkdirAll(targetPath, osutil.PermissionDirectory)
+		}
+
+		contents, err := resources.Policies.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading built-in policy '%s': %w", path, err)
+		}
+
+		return os.WriteFile(targetPath, contents, osutil.PermissionFile)
+	})
+}
+
+func writeJson(path string, value any) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bytes, osutil.PermissionFile)
+}
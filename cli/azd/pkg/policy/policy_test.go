@@ -0,0 +1,120 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockOpaCli struct {
+	evalFn func(query string, dataPaths []string, inputPath string) (string, error)
+}
+
+func (m *mockOpaCli) CheckInstalled(ctx context.Context) error { return nil }
+func (m *mockOpaCli) InstallUrl() string                       { return "" }
+func (m *mockOpaCli) Name() string                             { return "opa" }
+
+func (m *mockOpaCli) Eval(ctx context.Context, query string, dataPaths []string, inputPath string) (string, error) {
+	return m.evalFn(query, dataPaths, inputPath)
+}
+
+const violationsOutput = `{
+	"result": [{
+		"expressions": [{
+			"value": [
+				{"policy": "no-public-ip", "resource": "pip", "msg": "public IP addresses are not allowed"},
+				"plain message"
+			],
+			"text": "data.azd.policy.arm.deny"
+		}]
+	}]
+}`
+
+func Test_Engine_Disabled(t *testing.T) {
+	engine := NewEngine(&mockOpaCli{
+		evalFn: func(query string, dataPaths []string, inputPath string) (string, error) {
+			require.Fail(t, "eval should not be called when policies are disabled")
+			return "", nil
+		},
+	})
+
+	require.NoError(t, engine.Check(context.Background(), t.TempDir(), nil, KindArm, map[string]any{}))
+	require.NoError(t, engine.Check(context.Background(), t.TempDir(), &Options{}, KindArm, map[string]any{}))
+}
+
+func Test_Engine_Check(t *testing.T) {
+	projectPath := t.TempDir()
+	ran := false
+
+	engine := NewEngine(&mockOpaCli{
+		evalFn: func(query string, dataPaths []string, inputPath string) (string, error) {
+			ran = true
+			require.Equal(t, "data.azd.policy.arm.deny", query)
+			require.Len(t, dataPaths, 3)
+
+			// Built-in policies are extracted to disk
+			require.FileExists(t, filepath.Join(dataPaths[0], "arm", "no_public_ip.rego"))
+			require.FileExists(t, filepath.Join(dataPaths[0], "k8s", "allowed_registries.rego"))
+
+			// User paths are resolved relative to the project
+			require.Equal(t, filepath.Join(projectPath, "policies"), dataPaths[1])
+
+			settingsBytes, err := os.ReadFile(dataPaths[2])
+			require.NoError(t, err)
+			require.JSONEq(t, `{"settings":{"requiredTags":["owner"]}}`, string(settingsBytes))
+
+			inputBytes, err := os.ReadFile(inputPath)
+			require.NoError(t, err)
+			var input map[string]any
+			require.NoError(t, json.Unmarshal(inputBytes, &input))
+			require.Contains(t, input, "template")
+
+			return violationsOutput, nil
+		},
+	})
+
+	err := engine.Check(context.Background(), projectPath, &Options{
+		Enabled:  true,
+		Paths:    []string{"policies"},
+		Settings: map[string]any{"requiredTags": []string{"owner"}},
+	}, KindArm, map[string]any{"template": map[string]any{}})
+
+	require.True(t, ran)
+
+	var violationsErr *ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	require.Equal(t, []Violation{
+		{Policy: "no-public-ip", Resource: "pip", Message: "public IP addresses are not allowed"},
+		{Message: "plain message"},
+	}, violationsErr.Violations)
+	require.Equal(t,
+		"2 policy violation(s) found in arm documents:\n"+
+			"  - [no-public-ip] pip: public IP addresses are not allowed\n"+
+			"  - plain message",
+		err.Error())
+}
+
+func Test_Engine_NoViolations(t *testing.T) {
+	engine := NewEngine(&mockOpaCli{
+		evalFn: func(query string, dataPaths []string, inputPath string) (string, error) {
+			require.Equal(t, "data.azd.policy.k8s.deny", query)
+			// Only the settings document when built-in policies are disabled
+			require.Len(t, dataPaths, 1)
+			return `{"result":[{"expressions":[{"value":[]}]}]}`, nil
+		},
+	})
+
+	err := engine.Check(context.Background(), t.TempDir(), &Options{
+		Enabled:        true,
+		DisableBuiltIn: true,
+	}, KindK8s, map[string]any{"manifests": []any{}})
+	require.NoError(t, err)
+}
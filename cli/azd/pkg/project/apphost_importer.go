@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/apphost"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// AppHostResource is the resource of the application model of a .NET Aspire app host which a service was synthesized
+// from
+type AppHostResource struct {
+	// The name of the service of the app host in azure.yaml
+	AppHost string
+	// The name of the resource in the manifest of the app host
+	Name string
+	// The manifest of the app host
+	Manifest *apphost.Manifest
+}
+
+// Resource returns the resource of the manifest
+func (r *AppHostResource) Resource() *apphost.Resource {
+	return r.Manifest.Resources[r.Name]
+}
+
+// AppHostImporter synthesizes the services of .NET Aspire app hosts. A service of azure.yaml which is an app host, ex)
+// a dotnet service hosted on containerapp whose project sets IsAspireHost, is replaced by a service for each project and
+// container of the application model of the app host.
+type AppHostImporter struct {
+	dotnetCli dotnet.DotNetCli
+}
+
+// NewAppHostImporter creates a new instance of the AppHostImporter
+func NewAppHostImporter(dotnetCli dotnet.DotNetCli) *AppHostImporter {
+	return &AppHostImporter{
+		dotnetCli: dotnetCli,
+	}
+}
+
+// Import replaces the services of the project which are app hosts with the services of their resources
+func (i *AppHostImporter) Import(ctx context.Context, projectConfig *ProjectConfig) error {
+	for _, svc := range projectConfig.GetServicesStable() {
+		if svc.Host != ContainerAppTarget || svc.Language != ServiceLanguageDotNet {
+			continue
+		}
+
+		appHostProject, isAppHost := apphost.FindAppHostProject(svc.Path())
+		if !isAppHost {
+			continue
+		}
+
+		log.Printf("importing services of app host '%s' of service '%s'", appHostProject, svc.Name)
+		manifest, err := apphost.ManifestFromAppHost(ctx, appHostProject, i.dotnetCli)
+		if err != nil {
+			return fmt.Errorf("reading application model of app host of service '%s': %w", svc.Name, err)
+		}
+
+		services, err := appHostServices(projectConfig, svc, manifest)
+		if err != nil {
+			return err
+		}
+
+		delete(projectConfig.Services, svc.Name)
+		for _, service := range services {
+			if projectConfig.HasService(service.Name) {
+				return fmt.Errorf(
+					"resource '%s' of the app host of service '%s' has the same name as another service",
+					service.Name,
+					svc.Name,
+				)
+			}
+
+			projectConfig.Services[service.Name] = service
+		}
+	}
+
+	return nil
+}
+
+// appHostServices synthesizes the services of the deployable resources of the manifest of the app host
+func appHostServices(
+	projectConfig *ProjectConfig,
+	appHostService *ServiceConfig,
+	manifest *apphost.Manifest,
+) ([]*ServiceConfig, error) {
+	names := maps.Keys(manifest.Resources)
+	slices.Sort(names)
+
+	services := []*ServiceConfig{}
+	for _, name := range names {
+		resource := manifest.Resources[name]
+		if !resource.IsDeployable() {
+			log.Printf("skipping resource '%s' of type '%s' of app host, which isn't deployed", name, resource.Type)
+			continue
+		}
+
+		svc := &ServiceConfig{
+			Name:            name,
+			Project:         projectConfig,
+			Host:            ContainerAppTarget,
+			Language:        ServiceLanguageDocker,
+			Infra:           appHostService.Infra,
+			AppHost:         &AppHostResource{AppHost: appHostService.Name, Name: name, Manifest: manifest},
+			EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
+		}
+
+		var projectDir string
+		switch resource.Type {
+		case apphost.ResourceTypeProject:
+			svc.Language = ServiceLanguageDotNet
+			projectDir = filepath.Dir(resource.Path)
+		case apphost.ResourceTypeDockerfile:
+			projectDir = resource.Context
+			svc.Docker = DockerProjectOptions{Path: resource.Path, Context: resource.Context}
+		}
+
+		if projectDir != "" {
+			relativePath, err := filepath.Rel(projectConfig.Path, projectDir)
+			if err != nil {
+				return nil, fmt.Errorf("resolving path of resource '%s' of app host: %w", name, err)
+			}
+			svc.RelativePath = relativePath
+		}
+
+		services = append(services, svc)
+	}
+
+	return services, nil
+}
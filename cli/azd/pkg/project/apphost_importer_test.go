@@ -0,0 +1,170 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/apphost"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+// mockAppHostManifest responds to dotnet run of the app host by writing the manifest to the output path
+func mockAppHostManifest(t *testing.T, mockContext *mocks.MockContext, manifest *apphost.Manifest) {
+	contents, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "dotnet run") && strings.Contains(command, "--publisher manifest")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			outputPath := args.Args[slices.Index(args.Args, "--output-path")+1]
+			return exec.NewRunResult(0, "", ""), os.WriteFile(outputPath, contents, 0600)
+		})
+}
+
+func Test_AppHostImporter_Import(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  app:
+    project: src/AppHost
+    language: dotnet
+    host: containerapp
+  site:
+    project: src/site
+    language: js
+    host: staticwebapp
+`
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"src/AppHost/AppHost.csproj": "<Project><PropertyGroup><IsAspireHost>true</IsAspireHost></PropertyGroup></Project>",
+	})
+
+	connectionString := "{cache.bindings.tcp.host}:{cache.bindings.tcp.port}"
+	mockContext := mocks.NewMockContext(context.Background())
+	mockAppHostManifest(t, mockContext, &apphost.Manifest{
+		Resources: map[string]*apphost.Resource{
+			"apiservice": {
+				Type: apphost.ResourceTypeProject,
+				Path: filepath.Join(dir, "src", "ApiService", "ApiService.csproj"),
+			},
+			"worker": {
+				Type:    apphost.ResourceTypeDockerfile,
+				Path:    filepath.Join(dir, "worker", "Dockerfile"),
+				Context: filepath.Join(dir, "worker"),
+			},
+			"cache": {
+				Type:             apphost.ResourceTypeContainer,
+				Image:            "redis:latest",
+				ConnectionString: &connectionString,
+			},
+			"catalogdb": {Type: apphost.ResourceTypeValue},
+		},
+	})
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	projectConfig.Path = dir
+
+	importer := NewAppHostImporter(dotnet.NewDotNetCli(mockContext.CommandRunner))
+	require.NoError(t, importer.Import(*mockContext.Context, projectConfig))
+
+	services := projectConfig.GetServicesStable()
+	names := []string{}
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+	require.Equal(t, []string{"apiservice", "cache", "site", "worker"}, names)
+
+	apiService := projectConfig.Services["apiservice"]
+	require.Equal(t, ServiceLanguageDotNet, apiService.Language)
+	require.Equal(t, ContainerAppTarget, apiService.Host)
+	require.Equal(t, filepath.Join("src", "ApiService"), apiService.RelativePath)
+	require.Equal(t, "app", apiService.AppHost.AppHost)
+	require.Equal(t, apphost.ResourceTypeProject, apiService.AppHost.Resource().Type)
+	require.NotNil(t, apiService.EventDispatcher)
+	require.Same(t, projectConfig, apiService.Project)
+
+	worker := projectConfig.Services["worker"]
+	require.Equal(t, ServiceLanguageDocker, worker.Language)
+	require.Equal(t, "worker", worker.RelativePath)
+	require.Equal(t, filepath.Join(dir, "worker", "Dockerfile"), worker.Docker.Path)
+
+	cache := projectConfig.Services["cache"]
+	require.Equal(t, ServiceLanguageDocker, cache.Language)
+	require.Equal(t, "", cache.RelativePath)
+	require.Equal(t, "redis:latest", cache.AppHost.Resource().Image)
+
+	require.Nil(t, projectConfig.Services["site"].AppHost)
+}
+
+func Test_AppHostImporter_Import_Conflict(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  app:
+    project: src/AppHost
+    language: dotnet
+    host: containerapp
+  web:
+    project: src/web
+    language: js
+    host: appservice
+`
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"src/AppHost/AppHost.csproj": "<Project><PropertyGroup><IsAspireHost>true</IsAspireHost></PropertyGroup></Project>",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockAppHostManifest(t, mockContext, &apphost.Manifest{
+		Resources: map[string]*apphost.Resource{
+			"web": {Type: apphost.ResourceTypeContainer, Image: "nginx"},
+		},
+	})
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	projectConfig.Path = dir
+
+	importer := NewAppHostImporter(dotnet.NewDotNetCli(mockContext.CommandRunner))
+	err = importer.Import(*mockContext.Context, projectConfig)
+	require.ErrorContains(t, err, "resource 'web' of the app host of service 'app' has the same name as another service")
+}
+
+func Test_AppHostImporter_Import_NotAppHost(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: dotnet
+    host: containerapp
+`
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"src/api/api.csproj": "<Project Sdk=\"Microsoft.NET.Sdk.Web\" />",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	projectConfig.Path = dir
+
+	importer := NewAppHostImporter(dotnet.NewDotNetCli(mockContext.CommandRunner))
+	require.NoError(t, importer.Import(*mockContext.Context, projectConfig))
+	require.Len(t, projectConfig.Services, 1)
+	require.Nil(t, projectConfig.Services["api"].AppHost)
+}
@@ -0,0 +1,474 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// ArtifactFileName is the name of the blob describing an artifact, in the folder of the blobs of the artifact
+const ArtifactFileName = "artifact.json"
+
+const (
+	artifactPackageName   = "package"
+	artifactManifestsName = "manifests.yaml"
+)
+
+// artifactHosts are the hosts of the services which can be deployed from an artifact. Static web apps are deployed
+// from their source folders, their artifacts are only uploaded for audit.
+var artifactHosts = []ServiceTargetKind{
+	AppServiceTarget,
+	AzureFunctionTarget,
+	SpringAppTarget,
+	ContainerAppTarget,
+	AksTarget,
+}
+
+// Artifact describes the package of a service uploaded to a blob container once deployed, ex) for audit, or to deploy
+// exactly that package to other environments with `azd deploy --from-artifact`
+type Artifact struct {
+	Project string            `json:"project"`
+	Service string            `json:"service"`
+	Host    ServiceTargetKind `json:"host"`
+	// The environment the artifact was deployed to when uploaded
+	Environment string    `json:"environment"`
+	BuildId     string    `json:"buildId"`
+	Commit      string    `json:"commit,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// The blob of the package, relative to the artifact, of the services not hosted in containers
+	Package string `json:"package,omitempty"`
+	// Whether the package is a folder, zipped in its blob
+	PackageIsFolder bool `json:"packageIsFolder,omitempty"`
+	// The image of the services hosted in containers, by digest, ex) <registry>/<name>@sha256:<digest>
+	Image string `json:"image,omitempty"`
+	// The tag of the image, without the registry
+	ImageTag string `json:"imageTag,omitempty"`
+	// The blob of the k8s manifests of AKS services, rendered with the environment the artifact was deployed to
+	Manifests string `json:"manifests,omitempty"`
+}
+
+// StagedArtifact is a copy of the package of a service, kept while the service is deployed since some hosts delete
+// the package once deployed, to be uploaded once the service is deployed
+type StagedArtifact struct {
+	serviceConfig *ServiceConfig
+	// The zip of the package, empty for services hosted in containers
+	packagePath     string
+	packageExt      string
+	packageIsFolder bool
+}
+
+// Close deletes the copy of the package
+func (a *StagedArtifact) Close() error {
+	if a.packagePath == "" {
+		return nil
+	}
+
+	return os.Remove(a.packagePath)
+}
+
+// ArtifactStore uploads the packages of the deployed services to blob containers, and downloads them to deploy them
+// again, ex) to build once and promote the build through the environments
+type ArtifactStore struct {
+	env                      *environment.Environment
+	azCli                    azcli.AzCli
+	containerRegistryService azcli.ContainerRegistryService
+	docker                   docker.Docker
+	gitCli                   git.GitCli
+	fs                       vfs.Fs
+	clock                    clock.Clock
+}
+
+// NewArtifactStore creates an artifact store
+func NewArtifactStore(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	containerRegistryService azcli.ContainerRegistryService,
+	docker docker.Docker,
+	gitCli git.GitCli,
+	fs vfs.Fs,
+	clock clock.Clock,
+) *ArtifactStore {
+	return &ArtifactStore{
+		env:                      env,
+		azCli:                    azCli,
+		containerRegistryService: containerRegistryService,
+		docker:                   docker,
+		gitCli:                   gitCli,
+		fs:                       fs,
+		clock:                    clock,
+	}
+}
+
+// Stage copies the package of the service before the service is deployed, zipping the packages which are folders
+func (s *ArtifactStore) Stage(serviceConfig *ServiceConfig, packageResult *ServicePackageResult) (*StagedArtifact, error) {
+	staged := &StagedArtifact{serviceConfig: serviceConfig}
+	if serviceConfig.Host == ContainerAppTarget || serviceConfig.Host == AksTarget {
+		// The image is recorded once pushed to the registry
+		return staged, nil
+	}
+
+	packagePath := packageResult.PackagePath
+	if !filepath.IsAbs(packagePath) {
+		packagePath = filepath.Join(serviceConfig.Path(), packagePath)
+	}
+
+	info, err := os.Stat(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading package of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	if info.IsDir() {
+		zipPath, _, err := createDeployableZip(serviceConfig, packagePath)
+		if err != nil {
+			return nil, fmt.Errorf("zipping package of service '%s': %w", serviceConfig.Name, err)
+		}
+
+		staged.packagePath = zipPath
+		staged.packageExt = ".zip"
+		staged.packageIsFolder = true
+		return staged, nil
+	}
+
+	copied, err := os.CreateTemp("", "azdartifact*"+filepath.Ext(packagePath))
+	if err != nil {
+		return nil, err
+	}
+	defer copied.Close()
+
+	original, err := os.Open(packagePath)
+	if err != nil {
+		os.Remove(copied.Name())
+		return nil, err
+	}
+	defer original.Close()
+
+	if _, err := io.Copy(copied, original); err != nil {
+		os.Remove(copied.Name())
+		return nil, fmt.Errorf("copying package of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	staged.packagePath = copied.Name()
+	staged.packageExt = filepath.Ext(packagePath)
+	return staged, nil
+}
+
+// Upload uploads the artifact of the deployed service to the blob container of the url, under
+// <project>/<service>/<build id>, and returns the url of the artifact. The artifact is uploaded last, once the blobs it
+// references are uploaded.
+func (s *ArtifactStore) Upload(ctx context.Context, containerUrl string, staged *StagedArtifact) (string, error) {
+	serviceConfig := staged.serviceConfig
+
+	container, err := parseBlobUrl(containerUrl)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := s.gitCli.GetHeadCommit(ctx, serviceConfig.Path())
+	if err != nil {
+		log.Printf("the artifact of service '%s' has no commit: %v", serviceConfig.Name, err)
+		commit = ""
+	}
+
+	artifact := &Artifact{
+		Project:     serviceConfig.Project.Name,
+		Service:     serviceConfig.Name,
+		Host:        serviceConfig.Host,
+		Environment: s.env.GetEnvName(),
+		BuildId:     s.buildId(),
+		Commit:      commit,
+		CreatedAt:   s.clock.Now().UTC(),
+	}
+
+	folder := container.JoinPath(artifact.Project, artifact.Service, artifact.BuildId)
+	metadata := map[string]string{
+		"environment": artifact.Environment,
+		"service":     artifact.Service,
+		"buildid":     artifact.BuildId,
+	}
+	if commit != "" {
+		metadata["commit"] = commit
+	}
+
+	if staged.packagePath != "" {
+		artifact.Package = artifactPackageName + staged.packageExt
+		artifact.PackageIsFolder = staged.packageIsFolder
+
+		contentType := "application/octet-stream"
+		if staged.packageExt == ".zip" {
+			contentType = "application/zip"
+		}
+
+		if err := s.uploadFile(
+			ctx, folder.JoinPath(artifact.Package).String(), staged.packagePath, contentType, metadata,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	if serviceConfig.Host == ContainerAppTarget || serviceConfig.Host == AksTarget {
+		remoteTag := s.env.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME")
+		if remoteTag == "" {
+			return "", fmt.Errorf("the image of service '%s' wasn't pushed", serviceConfig.Name)
+		}
+
+		artifact.Image, err = s.docker.RepoDigest(ctx, serviceConfig.Path(), remoteTag)
+		if err != nil {
+			return "", err
+		}
+
+		_, artifact.ImageTag, _ = strings.Cut(remoteTag, "/")
+	}
+
+	if serviceConfig.Host == AksTarget {
+		manifests, err := s.renderManifests(serviceConfig)
+		if err != nil {
+			return "", err
+		}
+
+		artifact.Manifests = artifactManifestsName
+		if err := s.uploadBytes(
+			ctx, folder.JoinPath(artifact.Manifests).String(), manifests, "application/yaml", metadata,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	contents, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	artifactUrl := folder.JoinPath(ArtifactFileName).String()
+	if err := s.uploadBytes(ctx, artifactUrl, contents, "application/json", metadata); err != nil {
+		return "", err
+	}
+
+	return artifactUrl, nil
+}
+
+// Download downloads the artifact of the url, or of the folder of the url, to the directory and returns the package
+// to deploy the service from. The images of services hosted in containers are pulled.
+func (s *ArtifactStore) Download(
+	ctx context.Context,
+	artifactUrl string,
+	serviceConfig *ServiceConfig,
+	directory string,
+) (*ServicePackageResult, error) {
+	blobUrl, err := parseBlobUrl(artifactUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(blobUrl.Path, "/"+ArtifactFileName) {
+		blobUrl = blobUrl.JoinPath(ArtifactFileName)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.azCli.DownloadBlob(ctx, s.env.GetSubscriptionId(), blobUrl.String(), buf); err != nil {
+		return nil, fmt.Errorf("downloading artifact: %w", err)
+	}
+
+	var artifact Artifact
+	if err := json.Unmarshal(buf.Bytes(), &artifact); err != nil {
+		return nil, fmt.Errorf("reading artifact %s: %w", blobUrl, err)
+	}
+
+	if artifact.Service != serviceConfig.Name {
+		return nil, fmt.Errorf(
+			"the artifact is of service '%s', it cannot be deployed to service '%s'", artifact.Service, serviceConfig.Name)
+	}
+
+	if artifact.Host != serviceConfig.Host {
+		return nil, fmt.Errorf(
+			"the artifact is of a service hosted in %s, service '%s' is hosted in %s",
+			artifact.Host, serviceConfig.Name, serviceConfig.Host)
+	}
+
+	if !slices.Contains(artifactHosts, artifact.Host) {
+		return nil, fmt.Errorf("services hosted in %s cannot be deployed from artifacts", artifact.Host)
+	}
+
+	if artifact.Image != "" {
+		return s.pullImage(ctx, serviceConfig, &artifact)
+	}
+
+	if artifact.Package == "" {
+		return nil, fmt.Errorf("artifact %s has no package", blobUrl)
+	}
+
+	// The package is next to the artifact
+	packageUrl := *blobUrl
+	packageUrl.Path = strings.TrimSuffix(blobUrl.Path, ArtifactFileName) + artifact.Package
+
+	packagePath := filepath.Join(directory, artifact.Package)
+	packageFile, err := os.Create(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer packageFile.Close()
+
+	if err := s.azCli.DownloadBlob(ctx, s.env.GetSubscriptionId(), packageUrl.String(), packageFile); err != nil {
+		return nil, fmt.Errorf("downloading package of artifact: %w", err)
+	}
+
+	if err := packageFile.Close(); err != nil {
+		return nil, err
+	}
+
+	if artifact.PackageIsFolder {
+		folder := filepath.Join(directory, artifactPackageName)
+		if err := rzip.ExtractToDirectory(packagePath, folder); err != nil {
+			return nil, fmt.Errorf("extracting package of artifact: %w", err)
+		}
+
+		packagePath = folder
+	}
+
+	return &ServicePackageResult{
+		PackagePath: packagePath,
+	}, nil
+}
+
+// pullImage pulls the image of the artifact, tagged with its original tag for the image to be pushed to the registry of
+// the environment as is
+func (s *ArtifactStore) pullImage(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	artifact *Artifact,
+) (*ServicePackageResult, error) {
+	loginServer, _, _ := strings.Cut(artifact.Image, "/")
+	if err := s.containerRegistryService.Login(ctx, s.env.GetSubscriptionId(), loginServer); err != nil {
+		return nil, fmt.Errorf("logging into container registry '%s' of artifact: %w", loginServer, err)
+	}
+
+	if err := s.docker.Pull(ctx, artifact.Image); err != nil {
+		return nil, err
+	}
+
+	if err := s.docker.Tag(ctx, serviceConfig.Path(), artifact.Image, artifact.ImageTag); err != nil {
+		return nil, err
+	}
+
+	return &ServicePackageResult{
+		PackagePath: artifact.ImageTag,
+		Details: &dockerPackageResult{
+			ImageTag: artifact.ImageTag,
+		},
+	}, nil
+}
+
+// buildId identifies the build of the artifacts, the run of GitHub Actions or Azure Pipelines, or the time of the build
+func (s *ArtifactStore) buildId() string {
+	for _, name := range []string{"GITHUB_RUN_ID", "BUILD_BUILDID"} {
+		if runId := os.Getenv(name); runId != "" {
+			return runId
+		}
+	}
+
+	return s.clock.Now().UTC().Format("20060102T150405Z")
+}
+
+// renderManifests renders the k8s manifests of the AKS service with the environment, as a single yaml file
+func (s *ArtifactStore) renderManifests(serviceConfig *ServiceConfig) ([]byte, error) {
+	deploymentPath := serviceConfig.K8s.DeploymentPath
+	if deploymentPath == "" {
+		deploymentPath = defaultDeploymentPath
+	}
+
+	manifests, err := kubectl.ReadManifests(
+		s.fs, filepath.Join(serviceConfig.Path(), deploymentPath), s.env.Dotenv())
+	if err != nil {
+		return nil, fmt.Errorf("reading k8s manifests: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	for _, manifest := range manifests {
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, fmt.Errorf("rendering k8s manifests: %w", err)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("rendering k8s manifests: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *ArtifactStore) uploadFile(
+	ctx context.Context,
+	blobUrl string,
+	path string,
+	contentType string,
+	metadata map[string]string,
+) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.azCli.UploadBlob(ctx, s.env.GetSubscriptionId(), blobUrl, file, contentType, metadata)
+}
+
+func (s *ArtifactStore) uploadBytes(
+	ctx context.Context,
+	blobUrl string,
+	contents []byte,
+	contentType string,
+	metadata map[string]string,
+) error {
+	return s.azCli.UploadBlob(
+		ctx, s.env.GetSubscriptionId(), blobUrl, nopCloser{bytes.NewReader(contents)}, contentType, metadata)
+}
+
+// nopCloser is a seekable reader with a no-op Close
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// ValidateArtifactsUrl validates the url of a blob container to upload artifacts to, or of an artifact
+func ValidateArtifactsUrl(artifactsUrl string) error {
+	_, err := parseBlobUrl(artifactsUrl)
+	return err
+}
+
+// parseBlobUrl parses the url of a blob container or blob, ex) https://<account>.blob.core.windows.net/<container>
+func parseBlobUrl(blobUrl string) (*url.URL, error) {
+	parsed, err := url.Parse(blobUrl)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || strings.Trim(parsed.Path, "/") == "" {
+		return nil, fmt.Errorf(
+			"invalid blob url '%s', expected https://<account>.blob.core.windows.net/<container>[/<path>]", blobUrl)
+	}
+
+	parsed.RawQuery = ""
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	return parsed, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const testArtifactsUrl = "https://ACCOUNT.blob.core.windows.net/artifacts"
+
+// mockBlobContainer stores the blobs uploaded to the mock context, by path, and serves them back
+func mockBlobContainer(mockContext *mocks.MockContext) map[string][]byte {
+	blobs := map[string][]byte{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Host == "ACCOUNT.blob.core.windows.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		blobs[request.URL.Path] = body
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "ACCOUNT.blob.core.windows.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, has := blobs[request.URL.Path]
+		if !has {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		}
+
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	return blobs
+}
+
+func newTestArtifactStore(t *testing.T, mockContext *mocks.MockContext) *ArtifactStore {
+	t.Setenv("GITHUB_RUN_ID", "")
+	t.Setenv("BUILD_BUILDID", "")
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse")
+	}).Respond(exec.NewRunResult(0, "abc1234\n", ""))
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+
+	return NewArtifactStore(
+		environment.EphemeralWithValues("dev", map[string]string{environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID"}),
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		nil,
+		nil,
+		git.NewGitCli(mockContext.CommandRunner),
+		nil,
+		mockClock,
+	)
+}
+
+func Test_ArtifactStore_ZipPackage(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	blobs := mockBlobContainer(mockContext)
+	store := newTestArtifactStore(t, mockContext)
+
+	serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguageJavaScript)
+	serviceConfig.Project.Path = t.TempDir()
+
+	packagePath := filepath.Join(t.TempDir(), "api.zip")
+	require.NoError(t, os.WriteFile(packagePath, []byte("zip"), 0600))
+
+	staged, err := store.Stage(serviceConfig, &ServicePackageResult{PackagePath: packagePath})
+	require.NoError(t, err)
+	defer staged.Close()
+
+	// The package is deleted once deployed
+	require.NoError(t, os.Remove(packagePath))
+
+	artifactUrl, err := store.Upload(*mockContext.Context, testArtifactsUrl+"/", staged)
+	require.NoError(t, err)
+	require.Equal(t, testArtifactsUrl+"/Test-App/api/20240501T103000Z/artifact.json", artifactUrl)
+	require.Equal(t, "zip", string(blobs["/artifacts/Test-App/api/20240501T103000Z/package.zip"]))
+
+	var artifact Artifact
+	require.NoError(t, json.Unmarshal(blobs["/artifacts/Test-App/api/20240501T103000Z/artifact.json"], &artifact))
+	require.Equal(t, "api", artifact.Service)
+	require.Equal(t, AppServiceTarget, artifact.Host)
+	require.Equal(t, "dev", artifact.Environment)
+	require.Equal(t, "abc1234", artifact.Commit)
+	require.Equal(t, "package.zip", artifact.Package)
+	require.False(t, artifact.PackageIsFolder)
+
+	// The folder of the artifact can be deployed too
+	downloadDir := t.TempDir()
+	packageResult, err := store.Download(
+		*mockContext.Context, strings.TrimSuffix(artifactUrl, "/artifact.json"), serviceConfig, downloadDir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(downloadDir, "package.zip"), packageResult.PackagePath)
+
+	contents, err := os.ReadFile(packageResult.PackagePath)
+	require.NoError(t, err)
+	require.Equal(t, "zip", string(contents))
+}
+
+func Test_ArtifactStore_FolderPackage(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockBlobContainer(mockContext)
+	store := newTestArtifactStore(t, mockContext)
+
+	serviceConfig := createTestServiceConfig("src/api", SpringAppTarget, ServiceLanguageJava)
+	serviceConfig.Project.Path = t.TempDir()
+
+	packagePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packagePath, "app.jar"), []byte("jar"), 0600))
+
+	staged, err := store.Stage(serviceConfig, &ServicePackageResult{PackagePath: packagePath})
+	require.NoError(t, err)
+	defer staged.Close()
+
+	artifactUrl, err := store.Upload(*mockContext.Context, testArtifactsUrl, staged)
+	require.NoError(t, err)
+
+	downloadDir := t.TempDir()
+	packageResult, err := store.Download(*mockContext.Context, artifactUrl, serviceConfig, downloadDir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(downloadDir, "package"), packageResult.PackagePath)
+
+	contents, err := os.ReadFile(filepath.Join(packageResult.PackagePath, "app.jar"))
+	require.NoError(t, err)
+	require.Equal(t, "jar", string(contents))
+}
+
+func Test_ArtifactStore_Download_Mismatch(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	blobs := mockBlobContainer(mockContext)
+	store := newTestArtifactStore(t, mockContext)
+
+	tests := []struct {
+		name     string
+		artifact Artifact
+		wantErr  string
+	}{
+		{
+			name:     "Service",
+			artifact: Artifact{Service: "web", Host: AppServiceTarget, Package: "package.zip"},
+			wantErr:  "the artifact is of service 'web', it cannot be deployed to service 'api'",
+		},
+		{
+			name:     "Host",
+			artifact: Artifact{Service: "api", Host: AzureFunctionTarget, Package: "package.zip"},
+			wantErr:  "the artifact is of a service hosted in function, service 'api' is hosted in appservice",
+		},
+		{
+			name:     "NoPackage",
+			artifact: Artifact{Service: "api", Host: AppServiceTarget},
+			wantErr:  "has no package",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := json.Marshal(tt.artifact)
+			require.NoError(t, err)
+			blobs["/artifacts/app/api/1/artifact.json"] = contents
+
+			serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguageJavaScript)
+			_, err = store.Download(*mockContext.Context, testArtifactsUrl+"/app/api/1", serviceConfig, t.TempDir())
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_ValidateArtifactsUrl(t *testing.T) {
+	require.NoError(t, ValidateArtifactsUrl(testArtifactsUrl))
+	require.NoError(t, ValidateArtifactsUrl(testArtifactsUrl+"/app/api/1/artifact.json"))
+	require.Error(t, ValidateArtifactsUrl("http://ACCOUNT.blob.core.windows.net/artifacts"))
+	require.Error(t, ValidateArtifactsUrl("https://ACCOUNT.blob.core.windows.net"))
+	require.Error(t, ValidateArtifactsUrl("artifacts"))
+}
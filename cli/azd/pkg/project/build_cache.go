@@ -0,0 +1,320 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"golang.org/x/exp/slices"
+)
+
+// buildCacheDirectory is the directory of the build cache, relative to the project
+var buildCacheDirectory = filepath.Join(".azd", "cache")
+
+// buildCacheOperation is an operation of a service which the build cache skips when its inputs didn't change
+type buildCacheOperation string
+
+const (
+	buildCacheRestore buildCacheOperation = "restore"
+	buildCacheBuild   buildCacheOperation = "build"
+)
+
+// restoreInputs are the files of the services the dependencies are restored from, by language
+var restoreInputs = map[ServiceLanguageKind][]string{
+	ServiceLanguageJavaScript: {"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"},
+	ServiceLanguageTypeScript: {"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"},
+	ServiceLanguageDotNet:     {"*.csproj", "*.fsproj", "packages.lock.json", "Directory.Packages.props"},
+	ServiceLanguageCsharp:     {"*.csproj", "packages.lock.json", "Directory.Packages.props"},
+	ServiceLanguageFsharp:     {"*.fsproj", "packages.lock.json", "Directory.Packages.props"},
+}
+
+// restoreOutputs are the folders of the services the dependencies are restored to, by language
+var restoreOutputs = map[ServiceLanguageKind]string{
+	ServiceLanguageJavaScript: "node_modules",
+	ServiceLanguageTypeScript: "node_modules",
+	ServiceLanguageDotNet:     "obj",
+	ServiceLanguageCsharp:     "obj",
+	ServiceLanguageFsharp:     "obj",
+}
+
+// buildOutputs are the folders of the services the build writes to besides the build output path, by language
+var buildOutputs = map[ServiceLanguageKind][]string{
+	ServiceLanguageJavaScript: {},
+	ServiceLanguageTypeScript: {},
+	ServiceLanguageDotNet:     {},
+	ServiceLanguageCsharp:     {},
+	ServiceLanguageFsharp:     {},
+	ServiceLanguageJava:       {"target"},
+}
+
+// ignoredBuildInputs are the folders, relative to the services, which aren't inputs of their build, ex) build outputs.
+// Folders of the same names nested in the sources of the services, ex) src/main/java/com/contoso/build, are inputs.
+var ignoredBuildInputs = []string{
+	".azd", ".azure", "bin", "build", "dist", "obj", "out", "target",
+}
+
+// ignoredBuildInputNames are the names of the folders which aren't inputs of the build wherever they are in the services,
+// ex) restored dependencies
+var ignoredBuildInputNames = []string{
+	".git", ".venv", "__pycache__", "node_modules",
+}
+
+// buildCacheEntry is the hash of the inputs of the last successful restore or build of a service
+type buildCacheEntry struct {
+	Hash string `json:"hash"`
+	// The build output path of the service, empty for restores
+	BuildOutputPath string `json:"buildOutputPath,omitempty"`
+}
+
+// BuildCache skips the restore and build of services when their inputs didn't change since their last successful restore
+// or build, ex) when deploying again without changing a service. The inputs of a service are hashed, the lock files of
+// its dependencies for restores and its source for builds. The hash of the last restore and build of each service is
+// stored under .azd/cache of the project. The outputs stay where the frameworks write them, ex) node_modules or
+// bin/Release, and the cache is only used while they still exist.
+type BuildCache struct {
+	disabled bool
+}
+
+// NewBuildCache creates a build cache
+func NewBuildCache() *BuildCache {
+	return &BuildCache{}
+}
+
+// Disable disables the cache, ex) with --no-cache, for the services to be restored and built even when unchanged
+func (c *BuildCache) Disable() {
+	c.disabled = true
+}
+
+// lookup returns the entry of the last restore or build of the service when its inputs didn't change and its outputs
+// still exist, and the hash of the current inputs to store once the operation succeeds, empty when the operation of the
+// service isn't cached
+func (c *BuildCache) lookup(
+	serviceConfig *ServiceConfig,
+	operation buildCacheOperation,
+) (*buildCacheEntry, string) {
+	if c == nil || c.disabled || !cacheable(serviceConfig, operation) {
+		return nil, ""
+	}
+
+	var hash string
+	var err error
+	if operation == buildCacheRestore {
+		hash, err = hashRestoreInputs(serviceConfig)
+	} else {
+		hash, err = hashBuildInputs(serviceConfig)
+	}
+
+	if err != nil {
+		log.Printf("skipping %s cache of service %s: %v", operation, serviceConfig.Name, err)
+		return nil, ""
+	}
+
+	entry := readCacheEntry(serviceConfig, operation, hash)
+	if entry == nil {
+		metrics.Add(metrics.CacheMisses, 1, map[string]string{"cache": string(operation)})
+	} else {
+		metrics.Add(metrics.CacheHits, 1, map[string]string{"cache": string(operation)})
+	}
+
+	return entry, hash
+}
+
+// readCacheEntry returns the entry of the last restore or build of the service when it has the hash and its outputs
+// still exist
+func readCacheEntry(serviceConfig *ServiceConfig, operation buildCacheOperation, hash string) *buildCacheEntry {
+	contents, err := os.ReadFile(cacheEntryPath(serviceConfig, operation))
+	if err != nil {
+		return nil
+	}
+
+	var entry buildCacheEntry
+	if err := json.Unmarshal(contents, &entry); err != nil || entry.Hash != hash {
+		return nil
+	}
+
+	outputs := []string{}
+	if operation == buildCacheRestore {
+		outputs = append(outputs, filepath.Join(serviceConfig.Path(), restoreOutputs[serviceConfig.Language]))
+	} else {
+		outputs = append(outputs, entry.BuildOutputPath)
+		for _, output := range buildOutputs[serviceConfig.Language] {
+			outputs = append(outputs, filepath.Join(serviceConfig.Path(), output))
+		}
+	}
+
+	for _, output := range outputs {
+		if _, err := os.Stat(output); err != nil {
+			return nil
+		}
+	}
+
+	return &entry
+}
+
+// store stores the hash of the inputs of the successful restore or build of the service
+func (c *BuildCache) store(
+	serviceConfig *ServiceConfig,
+	operation buildCacheOperation,
+	hash string,
+	buildOutputPath string,
+) {
+	if hash == "" {
+		return
+	}
+
+	if err := writeCacheEntry(serviceConfig, operation, buildCacheEntry{
+		Hash:            hash,
+		BuildOutputPath: buildOutputPath,
+	}); err != nil {
+		log.Printf("failed storing %s cache of service %s: %v", operation, serviceConfig.Name, err)
+	}
+}
+
+// cacheable reports whether the operation of the service is cached. The builds of services hosted in containers build
+// their images, which aren't cached.
+func cacheable(serviceConfig *ServiceConfig, operation buildCacheOperation) bool {
+	if serviceConfig.Project == nil {
+		return false
+	}
+
+	if operation == buildCacheRestore {
+		_, has := restoreOutputs[serviceConfig.Language]
+		return has
+	}
+
+	_, has := buildOutputs[serviceConfig.Language]
+	return has && serviceConfig.Host != ContainerAppTarget && serviceConfig.Host != AksTarget
+}
+
+func cacheEntryPath(serviceConfig *ServiceConfig, operation buildCacheOperation) string {
+	return filepath.Join(
+		serviceConfig.Project.Path, buildCacheDirectory, serviceConfig.Name, fmt.Sprintf("%s.json", operation))
+}
+
+func writeCacheEntry(serviceConfig *ServiceConfig, operation buildCacheOperation, entry buildCacheEntry) error {
+	cacheDir := filepath.Join(serviceConfig.Project.Path, buildCacheDirectory)
+	if err := os.MkdirAll(filepath.Join(cacheDir, serviceConfig.Name), osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	// The cache is specific to the machine, it isn't committed
+	gitignore := filepath.Join(cacheDir, ".gitignore")
+	if _, err := os.Stat(gitignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(gitignore, []byte("*\n"), osutil.PermissionFile); err != nil {
+			return err
+		}
+	}
+
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(cacheEntryPath(serviceConfig, operation), contents, osutil.PermissionFile)
+}
+
+// hashRestoreInputs hashes the files of the service its dependencies are restored from
+func hashRestoreInputs(serviceConfig *ServiceConfig) (string, error) {
+	entries, err := os.ReadDir(serviceConfig.Path())
+	if err != nil {
+		return "", err
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		for _, pattern := range restoreInputs[serviceConfig.Language] {
+			if matched, _ := filepath.Match(pattern, entry.Name()); matched {
+				files = append(files, entry.Name())
+				break
+			}
+		}
+	}
+
+	return hashFiles(serviceConfig, files)
+}
+
+// hashBuildInputs hashes the source of the service, without its restored dependencies and build outputs, and without the
+// files excluded from its package by its .azdignore files
+func hashBuildInputs(serviceConfig *ServiceConfig) (string, error) {
+	root := serviceConfig.Path()
+	ignored := slices.Clone(ignoredBuildInputs)
+	if serviceConfig.OutputPath != "" {
+		ignored = append(ignored, filepath.Clean(serviceConfig.OutputPath))
+	}
+
+	matcher, err := newIgnoreMatcher(serviceConfig, root)
+	if err != nil {
+		return "", err
+	}
+
+	files := []string{}
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != root && (slices.Contains(ignored, relative) || slices.Contains(ignoredBuildInputNames, entry.Name()) ||
+				matcher.Match(path, true) != nil) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type().IsRegular() && matcher.Match(path, false) == nil {
+			files = append(files, relative)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hashFiles(serviceConfig, files)
+}
+
+// hashFiles hashes the configuration of the service and the paths and contents of its files, relative to the service
+func hashFiles(serviceConfig *ServiceConfig, files []string) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", serviceConfig.Language, serviceConfig.Host, serviceConfig.OutputPath)
+
+	slices.Sort(files)
+	for _, file := range files {
+		fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(file))
+
+		f, err := os.Open(filepath.Join(serviceConfig.Path(), file))
+		if err != nil {
+			return "", err
+		}
+
+		_, err = io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("hashing %s: %w", file, err)
+		}
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
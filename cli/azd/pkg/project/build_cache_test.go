@@ -0,0 +1,129 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createBuildCacheTestService(t *testing.T, host ServiceTargetKind) *ServiceConfig {
+	serviceConfig := createTestServiceConfig("src/api", host, ServiceLanguageTypeScript)
+	serviceConfig.Project.Path = t.TempDir()
+
+	servicePath := serviceConfig.Path()
+	require.NoError(t, os.MkdirAll(filepath.Join(servicePath, "node_modules"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(servicePath, "dist"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(servicePath, "package.json"), []byte("{}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(servicePath, "index.ts"), []byte("console.log()"), 0600))
+
+	return serviceConfig
+}
+
+func Test_BuildCache_Restore(t *testing.T) {
+	serviceConfig := createBuildCacheTestService(t, AppServiceTarget)
+	buildCache := NewBuildCache()
+
+	entry, hash := buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.Nil(t, entry)
+	require.NotEmpty(t, hash)
+
+	buildCache.store(serviceConfig, buildCacheRestore, hash, "")
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.NotNil(t, entry)
+
+	gitignore, err := os.ReadFile(filepath.Join(serviceConfig.Project.Path, buildCacheDirectory, ".gitignore"))
+	require.NoError(t, err)
+	require.Equal(t, "*\n", string(gitignore))
+
+	// The source isn't an input of the restore
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "index.ts"), []byte("//"), 0600))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.NotNil(t, entry)
+
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "package.json"), []byte(`{"a":1}`), 0600))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.Nil(t, entry)
+}
+
+func Test_BuildCache_Build(t *testing.T) {
+	serviceConfig := createBuildCacheTestService(t, AppServiceTarget)
+	buildOutputPath := filepath.Join(serviceConfig.Path(), "dist")
+	buildCache := NewBuildCache()
+
+	_, hash := buildCache.lookup(serviceConfig, buildCacheBuild)
+	buildCache.store(serviceConfig, buildCacheBuild, hash, buildOutputPath)
+
+	entry, _ := buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.NotNil(t, entry)
+	require.Equal(t, buildOutputPath, entry.BuildOutputPath)
+
+	// Restored dependencies and build outputs aren't inputs of the build
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "dist", "index.js"), []byte("//"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "node_modules", "a.js"), []byte("//"), 0600))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.NotNil(t, entry)
+
+	require.NoError(t, os.RemoveAll(buildOutputPath))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.Nil(t, entry)
+
+	require.NoError(t, os.MkdirAll(buildOutputPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "index.ts"), []byte("//"), 0600))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.Nil(t, entry)
+}
+
+func Test_BuildCache_NestedOutputNames(t *testing.T) {
+	serviceConfig := createBuildCacheTestService(t, AppServiceTarget)
+	sourcePath := filepath.Join(serviceConfig.Path(), "src", "com", "contoso", "build")
+	require.NoError(t, os.MkdirAll(sourcePath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourcePath, "Builder.java"), []byte("class Builder {}"), 0600))
+	buildCache := NewBuildCache()
+
+	_, hash := buildCache.lookup(serviceConfig, buildCacheBuild)
+	buildCache.store(serviceConfig, buildCacheBuild, hash, filepath.Join(serviceConfig.Path(), "dist"))
+
+	// Folders named like build outputs are only outputs at the root of the service
+	require.NoError(t, os.WriteFile(filepath.Join(sourcePath, "Builder.java"), []byte("class Builder { }"), 0600))
+	entry, _ := buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.Nil(t, entry)
+
+	// Restored dependencies are ignored wherever they are
+	_, hash = buildCache.lookup(serviceConfig, buildCacheBuild)
+	buildCache.store(serviceConfig, buildCacheBuild, hash, filepath.Join(serviceConfig.Path(), "dist"))
+	nestedModules := filepath.Join(serviceConfig.Path(), "packages", "lib", "node_modules")
+	require.NoError(t, os.MkdirAll(nestedModules, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(nestedModules, "a.js"), []byte("//"), 0600))
+	entry, _ = buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.NotNil(t, entry)
+}
+
+func Test_BuildCache_Disabled(t *testing.T) {
+	serviceConfig := createBuildCacheTestService(t, AppServiceTarget)
+	buildCache := NewBuildCache()
+
+	_, hash := buildCache.lookup(serviceConfig, buildCacheRestore)
+	buildCache.store(serviceConfig, buildCacheRestore, hash, "")
+
+	buildCache.Disable()
+	entry, hash := buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.Nil(t, entry)
+	require.Empty(t, hash)
+}
+
+func Test_BuildCache_ContainerBuildsNotCached(t *testing.T) {
+	serviceConfig := createBuildCacheTestService(t, ContainerAppTarget)
+	buildCache := NewBuildCache()
+
+	entry, hash := buildCache.lookup(serviceConfig, buildCacheBuild)
+	require.Nil(t, entry)
+	require.Empty(t, hash)
+
+	_, hash = buildCache.lookup(serviceConfig, buildCacheRestore)
+	require.NotEmpty(t, hash)
+}
@@ -0,0 +1,276 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"gopkg.in/yaml.v3"
+)
+
+// ResolvedValue is a value of the configuration azd resolves, with the source it's resolved from
+type ResolvedValue struct {
+	// What the value configures, ex) the project or the name of a service
+	Scope string `json:"scope"`
+	// The name of the value, ex) namespace
+	Name string `json:"name"`
+	// The resolved value, empty when it isn't resolved until azd needs it, ex) the resource found by its tags
+	Value string `json:"value"`
+	// Where the value comes from, ex) azure.yaml (services.api.k8s.namespace)
+	Source string `json:"source"`
+}
+
+// ConfigResolver resolves the configuration of the project and its services in an environment, as the commands of azd
+// resolve it from azure.yaml, the environment and the user config, annotated with the source of each value
+type ConfigResolver struct {
+	projectConfig *ProjectConfig
+	env           *environment.Environment
+	userConfig    config.Config
+	// The azure.yaml of the project, to tell the values of the project from the overrides of the environment
+	document *yaml.Node
+}
+
+// NewConfigResolver creates a resolver of the configuration of the project in the environment
+func NewConfigResolver(
+	projectConfig *ProjectConfig,
+	env *environment.Environment,
+	userConfig config.Config,
+) (*ConfigResolver, error) {
+	data, err := os.ReadFile(filepath.Join(projectConfig.Path, azdcontext.ProjectFileName))
+	if err != nil {
+		return nil, fmt.Errorf("reading project file: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing project file: %w", err)
+	}
+
+	resolver := &ConfigResolver{
+		projectConfig: projectConfig,
+		env:           env,
+		userConfig:    userConfig,
+	}
+	if len(document.Content) > 0 {
+		resolver.document = document.Content[0]
+	}
+
+	return resolver, nil
+}
+
+// ResolveProject resolves the configuration shared by the services of the project
+func (r *ConfigResolver) ResolveProject() []ResolvedValue {
+	return withScope("project", []ResolvedValue{
+		r.resolveDefaulted("subscription", environment.SubscriptionIdEnvVarName, "defaults.subscription"),
+		r.resolveDefaulted("location", environment.LocationEnvVarName, "defaults.location"),
+		r.resolveResourceGroup(),
+		r.resolveString("infra.provider", string(r.projectConfig.Infra.Provider), "infra", "provider"),
+		r.resolveString("infra.path", orDefault(r.projectConfig.Infra.Path, cInfraDirectory), "infra", "path"),
+		r.resolveString("infra.module", orDefault(r.projectConfig.Infra.Module, "main"), "infra", "module"),
+	})
+}
+
+// ResolveService resolves the configuration of the service
+func (r *ConfigResolver) ResolveService(serviceConfig *ServiceConfig) []ResolvedValue {
+	path := []string{"services", serviceConfig.Name}
+	values := []ResolvedValue{
+		r.resolveString("host", string(serviceConfig.Host), append(path, "host")...),
+		r.resolveResourceGroup(),
+	}
+
+	resourceName, ok := r.resolveExpandable("resourceName", serviceConfig.ResourceName, append(path, "resourceName")...)
+	if !ok {
+		resourceName = ResolvedValue{
+			Name: "resourceName",
+			Source: fmt.Sprintf(
+				"the resource tagged %s: %s, found when deploying", azure.TagKeyAzdServiceName, serviceConfig.Name),
+		}
+	}
+	values = append(values, resourceName)
+
+	if serviceConfig.Host != AksTarget && serviceConfig.Host != ContainerAppTarget {
+		return withScope(serviceConfig.Name, values)
+	}
+
+	// Like [ContainerHelper.RegistryName]
+	registry, ok := r.resolveExpandable("registry", serviceConfig.Docker.Registry, append(path, "docker", "registry")...)
+	if !ok {
+		registry = r.resolveEnv("registry", environment.ContainerRegistryEndpointEnvVarName)
+	}
+	values = append(values, registry)
+
+	images := r.projectConfig.Images
+	if images == nil {
+		images = &ImagesOptions{}
+	}
+	values = append(values,
+		r.resolveString("image.name", orDefault(images.Name, DefaultImageName), "images", "name"),
+		r.resolveString("image.tag", orDefault(images.Tag, DefaultImageTag), "images", "tag"),
+	)
+
+	if serviceConfig.Host == AksTarget {
+		values = append(values,
+			r.resolveEnv("cluster", environment.AksClusterEnvVarName),
+			r.resolveString(
+				"namespace", orDefault(serviceConfig.K8s.Namespace, r.projectConfig.Name), append(path, "k8s", "namespace")...),
+		)
+	}
+
+	return withScope(serviceConfig.Name, values)
+}
+
+func withScope(scope string, values []ResolvedValue) []ResolvedValue {
+	for i := range values {
+		values[i].Scope = scope
+	}
+
+	return values
+}
+
+// resolveDefaulted resolves the value of the environment, or the default of the user config when the environment
+// doesn't have it
+func (r *ConfigResolver) resolveDefaulted(name string, envVarName string, configPath string) ResolvedValue {
+	if _, has := r.env.LookupEnv(envVarName); has {
+		return r.resolveEnv(name, envVarName)
+	}
+
+	if value, has := r.userConfig.Get(configPath); has {
+		return ResolvedValue{Name: name, Value: fmt.Sprint(value), Source: fmt.Sprintf("user config (%s)", configPath)}
+	}
+
+	return r.resolveEnv(name, envVarName)
+}
+
+// resolveResourceGroup resolves the resource group like [ResourceManager.GetResourceGroupName]
+func (r *ConfigResolver) resolveResourceGroup() ResolvedValue {
+	if value, ok := r.resolveExpandable("resourceGroup", r.projectConfig.ResourceGroupName, "resourceGroup"); ok {
+		return value
+	}
+
+	if _, has := r.env.LookupEnv(environment.ResourceGroupEnvVarName); has {
+		return r.resolveEnv("resourceGroup", environment.ResourceGroupEnvVarName)
+	}
+
+	return ResolvedValue{
+		Name: "resourceGroup",
+		Source: fmt.Sprintf(
+			"the resource group tagged %s: %s, found when needed", azure.TagKeyAzdEnvName, r.env.GetEnvName()),
+	}
+}
+
+// resolveEnv resolves the value of the environment variable, from the .env file of the environment or the variables of
+// the azd process
+func (r *ConfigResolver) resolveEnv(name string, envVarName string) ResolvedValue {
+	value, has := r.env.LookupEnv(envVarName)
+	return ResolvedValue{Name: name, Value: value, Source: r.envSource(envVarName, has)}
+}
+
+func (r *ConfigResolver) envSource(envVarName string, has bool) string {
+	if !has {
+		return fmt.Sprintf("not set, %s isn't set in environment '%s'", envVarName, r.env.GetEnvName())
+	}
+
+	value, has := r.env.Dotenv()[envVarName]
+	if !has {
+		return fmt.Sprintf("environment variable %s", envVarName)
+	}
+
+	source := fmt.Sprintf(".azure/%s/.env (%s)", r.env.GetEnvName(), envVarName)
+	if templateValue, has := r.projectConfig.Environment.Values(r.env.GetEnvName())[envVarName]; has &&
+		templateValue == value {
+		source += ", set from the environment section of azure.yaml"
+	}
+
+	return source
+}
+
+// resolveString resolves a value of azure.yaml, which is its default when azure.yaml doesn't set it
+func (r *ConfigResolver) resolveString(name string, value string, path ...string) ResolvedValue {
+	source := r.yamlSource(path...)
+	if source == "" {
+		source = "default"
+	}
+
+	return ResolvedValue{Name: name, Value: value, Source: source}
+}
+
+func orDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// resolveExpandable resolves a value of azure.yaml referencing environment variables, ex) ${PLATFORM_REGISTRY}. It
+// returns false when azure.yaml doesn't have the value.
+func (r *ConfigResolver) resolveExpandable(name string, value ExpandableString, path ...string) (ResolvedValue, bool) {
+	expanded, err := value.Envsubst(r.env.Getenv)
+	if err != nil {
+		return ResolvedValue{Name: name, Source: fmt.Sprintf("%s, invalid: %v", r.yamlSource(path...), err)}, true
+	}
+
+	if strings.TrimSpace(expanded) == "" {
+		return ResolvedValue{}, false
+	}
+
+	source := r.yamlSource(path...)
+	for _, reference := range value.References() {
+		_, has := r.env.LookupEnv(reference)
+		source += fmt.Sprintf(", ${%s} from %s", reference, r.envSource(reference, has))
+	}
+
+	return ResolvedValue{Name: name, Value: expanded, Source: source}, true
+}
+
+// yamlSource returns where the value at the path of azure.yaml is set: the overrides of the environment, or the
+// project. It returns an empty string when azure.yaml doesn't set it.
+func (r *ConfigResolver) yamlSource(path ...string) string {
+	environments := yamlLookup(r.document, cEnvironmentsKey)
+	if environments != nil && environments.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(environments.Content); i += 2 {
+			name := environments.Content[i].Value
+			if strings.EqualFold(name, r.env.GetEnvName()) && yamlLookup(environments.Content[i+1], path...) != nil {
+				return fmt.Sprintf("%s (%s.%s.%s)", azdcontext.ProjectFileName, cEnvironmentsKey, name, strings.Join(path, "."))
+			}
+		}
+	}
+
+	if yamlLookup(r.document, path...) != nil {
+		return fmt.Sprintf("%s (%s)", azdcontext.ProjectFileName, strings.Join(path, "."))
+	}
+
+	return ""
+}
+
+// yamlLookup returns the node at the path of mapping keys, nil when the path doesn't exist or is null
+func yamlLookup(node *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+		node = value
+	}
+
+	if node == nil || node.Tag == "!!null" {
+		return nil
+	}
+
+	return node
+}
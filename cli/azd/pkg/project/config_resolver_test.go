@@ -0,0 +1,151 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+const resolverTestProject = `
+name: todo
+resourceGroup: rg-${AZURE_ENV_NAME}
+services:
+  api:
+    host: aks
+    language: js
+    docker:
+      registry: ${PLATFORM_REGISTRY}
+  web:
+    host: appservice
+    language: py
+    resourceName: app-web
+environment:
+  defaults:
+    AZURE_LOCATION: eastus2
+environments:
+  prod:
+    services:
+      api:
+        k8s:
+          namespace: todo-prod
+`
+
+func newTestConfigResolver(t *testing.T, env *environment.Environment, userConfig config.Config) *ConfigResolver {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "azure.yaml"), []byte(resolverTestProject), 0600))
+
+	projectConfig, err := ParseForEnvironment(context.Background(), resolverTestProject, env.GetEnvName())
+	require.NoError(t, err)
+	projectConfig.Path = path
+
+	resolver, err := NewConfigResolver(projectConfig, env, userConfig)
+	require.NoError(t, err)
+
+	return resolver
+}
+
+func valuesByName(values []ResolvedValue) map[string]ResolvedValue {
+	byName := map[string]ResolvedValue{}
+	for _, value := range values {
+		byName[value.Name] = value
+	}
+
+	return byName
+}
+
+func Test_ConfigResolver_ResolveProject(t *testing.T) {
+	env := environment.EphemeralWithValues("prod", map[string]string{
+		environment.LocationEnvVarName: "eastus2",
+	})
+	resolver := newTestConfigResolver(t, env, config.NewConfig(map[string]any{
+		"defaults": map[string]any{"subscription": "SUBSCRIPTION_ID"},
+	}))
+
+	values := valuesByName(resolver.ResolveProject())
+	require.Equal(t, ResolvedValue{
+		Scope:  "project",
+		Name:   "subscription",
+		Value:  "SUBSCRIPTION_ID",
+		Source: "user config (defaults.subscription)",
+	}, values["subscription"])
+	require.Equal(t, ResolvedValue{
+		Scope:  "project",
+		Name:   "location",
+		Value:  "eastus2",
+		Source: ".azure/prod/.env (AZURE_LOCATION), set from the environment section of azure.yaml",
+	}, values["location"])
+	require.Equal(t, ResolvedValue{
+		Scope:  "project",
+		Name:   "resourceGroup",
+		Value:  "rg-prod",
+		Source: "azure.yaml (resourceGroup), ${AZURE_ENV_NAME} from .azure/prod/.env (AZURE_ENV_NAME)",
+	}, values["resourceGroup"])
+	require.Equal(t, "default", values["infra.provider"].Source)
+	require.Equal(t, "bicep", values["infra.provider"].Value)
+}
+
+func Test_ConfigResolver_ResolveService(t *testing.T) {
+	t.Run("Aks", func(t *testing.T) {
+		t.Setenv("PLATFORM_REGISTRY", "platform.azurecr.io")
+		env := environment.EphemeralWithValues("prod", map[string]string{
+			environment.AksClusterEnvVarName: "aks-prod",
+		})
+		resolver := newTestConfigResolver(t, env, config.NewEmptyConfig())
+
+		values := valuesByName(resolver.ResolveService(resolver.projectConfig.Services["api"]))
+		require.Equal(t, ResolvedValue{
+			Scope:  "api",
+			Name:   "registry",
+			Value:  "platform.azurecr.io",
+			Source: "azure.yaml (services.api.docker.registry), ${PLATFORM_REGISTRY} from environment variable PLATFORM_REGISTRY",
+		}, values["registry"])
+		require.Equal(t, ResolvedValue{
+			Scope:  "api",
+			Name:   "cluster",
+			Value:  "aks-prod",
+			Source: ".azure/prod/.env (AZURE_AKS_CLUSTER_NAME)",
+		}, values["cluster"])
+		require.Equal(t, ResolvedValue{
+			Scope:  "api",
+			Name:   "namespace",
+			Value:  "todo-prod",
+			Source: "azure.yaml (environments.prod.services.api.k8s.namespace)",
+		}, values["namespace"])
+		require.Equal(t, ResolvedValue{Scope: "api", Name: "image.name", Value: DefaultImageName, Source: "default"},
+			values["image.name"])
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", nil)
+		resolver := newTestConfigResolver(t, env, config.NewEmptyConfig())
+
+		values := valuesByName(resolver.ResolveService(resolver.projectConfig.Services["api"]))
+		require.Equal(t, ResolvedValue{Scope: "api", Name: "namespace", Value: "todo", Source: "default"}, values["namespace"])
+		require.Equal(t, "", values["cluster"].Value)
+		require.Equal(t, "not set, AZURE_AKS_CLUSTER_NAME isn't set in environment 'dev'", values["cluster"].Source)
+		require.Equal(t, "the resource tagged azd-service-name: api, found when deploying", values["resourceName"].Source)
+
+		// Registries referencing unset variables fall back to the registry of the environment
+		require.Equal(t, "not set, AZURE_CONTAINER_REGISTRY_ENDPOINT isn't set in environment 'dev'",
+			values["registry"].Source)
+	})
+
+	t.Run("AppService", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", nil)
+		resolver := newTestConfigResolver(t, env, config.NewEmptyConfig())
+
+		values := valuesByName(resolver.ResolveService(resolver.projectConfig.Services["web"]))
+		require.Equal(t, ResolvedValue{
+			Scope:  "web",
+			Name:   "resourceName",
+			Value:  "app-web",
+			Source: "azure.yaml (services.web.resourceName)",
+		}, values["resourceName"])
+		require.NotContains(t, values, "registry")
+	})
+}
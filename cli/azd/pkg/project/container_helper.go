@@ -5,21 +5,108 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/slices"
 )
 
+const (
+	// DefaultImageName is the name of the container images of the services when not configured in azure.yaml
+	DefaultImageName = "{project}/{service}-{env}"
+	// DefaultImageTag is the tag of the container images of the services when not configured in azure.yaml
+	DefaultImageTag = "azd-deploy-{timestamp}"
+	// RotateRegistryAdminPasswordEnvVarName enables, when true, the rotation of the passwords of the admin users of the
+	// container registries azd logged into with them, after deploying in CI
+	RotateRegistryAdminPasswordEnvVarName = "AZD_ROTATE_REGISTRY_ADMIN_PASSWORD"
+)
+
+// ImagesOptions configures the naming convention of the container images of the services, in azure.yaml
+type ImagesOptions struct {
+	// Template of the name of the images. Supports the {registry}, {project}, {service} and {env} placeholders.
+	// {registry} is optional and can only start the name, the images are always pushed to the container registry of
+	// the environment.
+	Name string `yaml:"name,omitempty"`
+	// Template of the tag of the images. Supports the {timestamp}, {gitsha}, {semver}, {runid}, {service} and {env}
+	// placeholders.
+	Tag string `yaml:"tag,omitempty"`
+	// Retention of the images pushed by azd. When set, the images beyond the retention are deleted from the container
+	// registry after deploying.
+	Retention *ImagesRetention `yaml:"retention,omitempty"`
+}
+
+// ImagesRetention configures which images pushed by azd are kept in the container registry
+type ImagesRetention struct {
+	// Number of most recently pushed images kept
+	Keep int `yaml:"keep,omitempty"`
+	// When not zero, images pushed in the last days are kept
+	Days int `yaml:"days,omitempty"`
+}
+
+// StaleImage is an image pushed by azd, beyond the retention, in the container registry of the environment
+type StaleImage struct {
+	LoginServer string
+	Repository  string
+	Digest      string
+	Tags        []string
+	Pushed      time.Time
+}
+
+// registryPlaceholder starts image name templates referencing the container registry of the environment
+const registryPlaceholder = "{registry}"
+
+// invalidTagCharsRegex matches the characters not allowed in a docker tag
+var invalidTagCharsRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// imageTagProvider computes the value of a placeholder of image tag templates
+type imageTagProvider func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error)
+
+var imageTagProviders = map[string]imageTagProvider{
+	"{timestamp}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		return strconv.FormatInt(ch.clock.Now().Unix(), 10), nil
+	},
+	"{gitsha}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		return ch.gitCli.GetHeadCommit(ctx, serviceConfig.Path())
+	},
+	"{semver}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		return ch.gitCli.Describe(ctx, serviceConfig.Path())
+	},
+	"{runid}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		// GitHub Actions, then Azure Pipelines
+		for _, name := range []string{"GITHUB_RUN_ID", "BUILD_BUILDID"} {
+			if runId := os.Getenv(name); runId != "" {
+				return runId, nil
+			}
+		}
+
+		return "", errors.New("{runid} is only supported when running in GitHub Actions or Azure Pipelines")
+	},
+	"{service}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		return serviceConfig.Name, nil
+	},
+	"{env}": func(ctx context.Context, ch *ContainerHelper, serviceConfig *ServiceConfig) (string, error) {
+		return ch.env.GetEnvName(), nil
+	},
+}
+
 type ContainerHelper struct {
 	env                      *environment.Environment
 	containerRegistryService azcli.ContainerRegistryService
 	docker                   docker.Docker
+	gitCli                   git.GitCli
 	clock                    clock.Clock
+	// tag of the images set with --tag, used instead of the tag configured in azure.yaml
+	imageTag string
 }
 
 func NewContainerHelper(
@@ -27,16 +114,42 @@ func NewContainerHelper(
 	clock clock.Clock,
 	containerRegistryService azcli.ContainerRegistryService,
 	docker docker.Docker,
+	gitCli git.GitCli,
 ) *ContainerHelper {
 	return &ContainerHelper{
 		env:                      env,
 		containerRegistryService: containerRegistryService,
 		docker:                   docker,
+		gitCli:                   gitCli,
 		clock:                    clock,
 	}
 }
 
-func (ch *ContainerHelper) RegistryName(ctx context.Context) (string, error) {
+// SetImageTag sets the tag of the images packaged next, instead of the tag configured in azure.yaml
+func (ch *ContainerHelper) SetImageTag(tag string) error {
+	if tag != "" && (len(tag) > 128 || invalidTagCharsRegex.MatchString(tag) || strings.HasPrefix(tag, ".") ||
+		strings.HasPrefix(tag, "-")) {
+		return fmt.Errorf(
+			"invalid image tag '%s': tags contain up to 128 letters, digits, underscores, periods and dashes, "+
+				"and don't start with a period or a dash", tag)
+	}
+
+	ch.imageTag = tag
+	return nil
+}
+
+// RegistryName returns the login server of the container registry of the service: the registry configured for the
+// service, or else the container registry of the environment
+func (ch *ContainerHelper) RegistryName(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	registry, err := serviceConfig.Docker.Registry.Envsubst(ch.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding registry of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	if registry != "" {
+		return registry, nil
+	}
+
 	loginServer, has := ch.env.LookupEnv(environment.ContainerRegistryEndpointEnvVarName)
 	if !has {
 		return "", fmt.Errorf(
@@ -53,7 +166,7 @@ func (ch *ContainerHelper) RemoteImageTag(
 	serviceConfig *ServiceConfig,
 	localImageTag string,
 ) (string, error) {
-	loginServer, err := ch.RegistryName(ctx)
+	loginServer, err := ch.RegistryName(ctx, serviceConfig)
 	if err != nil {
 		return "", err
 	}
@@ -75,12 +188,66 @@ func (ch *ContainerHelper) LocalImageTag(ctx context.Context, serviceConfig *Ser
 		return configuredTag, nil
 	}
 
-	return fmt.Sprintf("%s/%s-%s:azd-deploy-%d",
-		strings.ToLower(serviceConfig.Project.Name),
-		strings.ToLower(serviceConfig.Name),
-		strings.ToLower(ch.env.GetEnvName()),
-		ch.clock.Now().Unix(),
-	), nil
+	name, err := ch.imageName(serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := ch.imageTagOf(ctx, serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", name, tag), nil
+}
+
+// imageName renders the image name template of the project, without the registry
+func (ch *ContainerHelper) imageName(serviceConfig *ServiceConfig) (string, error) {
+	template := DefaultImageName
+	if images := serviceConfig.Project.Images; images != nil && images.Name != "" {
+		template = images.Name
+	}
+
+	template = strings.TrimPrefix(template, registryPlaceholder+"/")
+	if strings.Contains(template, registryPlaceholder) {
+		return "", fmt.Errorf(
+			"invalid image name '%s': %s can only start the name of the image", template, registryPlaceholder)
+	}
+
+	return strings.NewReplacer(
+		"{project}", strings.ToLower(serviceConfig.Project.Name),
+		"{service}", strings.ToLower(serviceConfig.Name),
+		"{env}", strings.ToLower(ch.env.GetEnvName()),
+	).Replace(template), nil
+}
+
+// imageTagOf returns the tag set with --tag, or renders the image tag template of the project
+func (ch *ContainerHelper) imageTagOf(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	if ch.imageTag != "" {
+		return ch.imageTag, nil
+	}
+
+	template := DefaultImageTag
+	if images := serviceConfig.Project.Images; images != nil && images.Tag != "" {
+		template = images.Tag
+	}
+
+	tag := template
+	for placeholder, provider := range imageTagProviders {
+		if !strings.Contains(tag, placeholder) {
+			continue
+		}
+
+		value, err := provider(ctx, ch, serviceConfig)
+		if err != nil {
+			return "", fmt.Errorf("computing %s of image tag '%s': %w", placeholder, template, err)
+		}
+
+		tag = strings.ReplaceAll(tag, placeholder, value)
+	}
+
+	// ex) the + of semver build metadata isn't allowed in tags
+	return invalidTagCharsRegex.ReplaceAllString(tag, "-"), nil
 }
 
 func (ch *ContainerHelper) RequiredExternalTools(context.Context) []tools.ExternalTool {
@@ -96,7 +263,7 @@ func (ch *ContainerHelper) Deploy(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
 			// Get ACR Login Server
-			loginServer, err := ch.RegistryName(ctx)
+			loginServer, err := ch.RegistryName(ctx, serviceConfig)
 			if err != nil {
 				task.SetError(err)
 				return
@@ -152,8 +319,135 @@ func (ch *ContainerHelper) Deploy(
 				return
 			}
 
+			if images := serviceConfig.Project.Images; images != nil && images.Retention != nil {
+				task.SetProgress(NewServiceProgress("Deleting stale container images"))
+				// the deployment succeeded, failures to clean up the registry are only logged
+				if err := ch.pruneImages(ctx, serviceConfig, *images.Retention); err != nil {
+					log.Printf("failed deleting stale container images: %v", err)
+				}
+			}
+
 			task.SetResult(&ServiceDeployResult{
 				Package: packageOutput,
 			})
 		})
 }
+
+// StaleImages returns the images of the service pushed by azd beyond the retention, from the container registry of the
+// service, most recent first. The image deployed last is always kept. No images are returned when the service
+// wasn't deployed by azd, or was deployed to another registry.
+func (ch *ContainerHelper) StaleImages(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	retention ImagesRetention,
+) ([]*StaleImage, error) {
+	loginServer, err := ch.RegistryName(ctx, serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	deployedImage, has := strings.CutPrefix(ch.env.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME"), loginServer+"/")
+	if !has {
+		return nil, nil
+	}
+
+	repository, deployedTag, has := strings.Cut(deployedImage, ":")
+	if !has {
+		return nil, nil
+	}
+
+	tags, err := ch.containerRegistryService.GetTags(ctx, ch.env.GetSubscriptionId(), loginServer, repository)
+	if err != nil {
+		return nil, fmt.Errorf("getting tags of repository '%s': %w", repository, err)
+	}
+
+	images := staleImages(tags, deployedTag, retention, ch.clock.Now())
+	for _, image := range images {
+		image.LoginServer = loginServer
+		image.Repository = repository
+	}
+
+	return images, nil
+}
+
+// DeleteImage deletes the image, and all its tags, from the container registry
+func (ch *ContainerHelper) DeleteImage(ctx context.Context, image *StaleImage) error {
+	return ch.containerRegistryService.DeleteManifest(
+		ctx, ch.env.GetSubscriptionId(), image.LoginServer, image.Repository, image.Digest)
+}
+
+// AdminCredentialsUses returns the container registries azd used the credentials of their admin user for, when the
+// signed-in principal couldn't get a token of the registry
+func (ch *ContainerHelper) AdminCredentialsUses() []azcli.AdminCredentialsUse {
+	if ch.containerRegistryService == nil {
+		return nil
+	}
+
+	return ch.containerRegistryService.AdminCredentialsUses()
+}
+
+// RotateAdminPassword regenerates the password of the admin user of the container registry used by azd, for the
+// password not to be valid after the deploy
+func (ch *ContainerHelper) RotateAdminPassword(ctx context.Context, use azcli.AdminCredentialsUse) error {
+	return ch.containerRegistryService.RotateAdminPassword(ctx, use)
+}
+
+func (ch *ContainerHelper) pruneImages(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	retention ImagesRetention,
+) error {
+	images, err := ch.StaleImages(ctx, serviceConfig, retention)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		log.Printf("deleting stale image %s@%s", image.Repository, image.Digest)
+		if err := ch.DeleteImage(ctx, image); err != nil {
+			return fmt.Errorf("deleting image %s@%s: %w", image.Repository, image.Digest, err)
+		}
+	}
+
+	return nil
+}
+
+// staleImages groups the tags beyond the retention by image. An image tagged more than once is kept when any of its
+// tags is.
+func staleImages(
+	tags []*azcli.ContainerRegistryTag,
+	deployedTag string,
+	retention ImagesRetention,
+	now time.Time,
+) []*StaleImage {
+	tags = slices.Clone(tags)
+	slices.SortStableFunc(tags, func(a, b *azcli.ContainerRegistryTag) bool {
+		return a.LastUpdateTime.After(b.LastUpdateTime)
+	})
+
+	keptDigests := map[string]bool{}
+	keptSince := now.AddDate(0, 0, -retention.Days)
+	for i, tag := range tags {
+		if i < retention.Keep || tag.Name == deployedTag || (retention.Days > 0 && tag.LastUpdateTime.After(keptSince)) {
+			keptDigests[tag.Digest] = true
+		}
+	}
+
+	images := []*StaleImage{}
+	byDigest := map[string]*StaleImage{}
+	for _, tag := range tags {
+		if keptDigests[tag.Digest] {
+			continue
+		}
+
+		image, has := byDigest[tag.Digest]
+		if !has {
+			image = &StaleImage{Digest: tag.Digest, Pushed: tag.LastUpdateTime}
+			byDigest[tag.Digest] = image
+			images = append(images, image)
+		}
+		image.Tags = append(image.Tags, tag.Name)
+	}
+
+	return images
+}
@@ -3,9 +3,14 @@ package project
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
@@ -47,7 +52,7 @@ func Test_ContainerHelper_LocalImageTag(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := environment.EphemeralWithValues("dev", map[string]string{})
-			containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil)
+			containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil, nil)
 			serviceConfig.Docker = tt.dockerConfig
 
 			tag, err := containerHelper.LocalImageTag(*mockContext.Context, serviceConfig)
@@ -57,12 +62,84 @@ func Test_ContainerHelper_LocalImageTag(t *testing.T) {
 	}
 }
 
+func Test_ContainerHelper_LocalImageTag_Images(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "git -C")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		switch args.Args[2] {
+		case "rev-parse":
+			return exec.NewRunResult(0, "1a2b3c4\n", ""), nil
+		case "describe":
+			return exec.NewRunResult(0, "v1.2.0-3-g1a2b3c4+dirty\n", ""), nil
+		}
+		return exec.NewRunResult(1, "", "unexpected"), fmt.Errorf("unexpected command: %s", args.Args)
+	})
+	t.Setenv("GITHUB_RUN_ID", "42")
+
+	tests := []struct {
+		name     string
+		images   *ImagesOptions
+		imageTag string
+		want     string
+	}{
+		{"Name", &ImagesOptions{Name: "{project}/{service}"}, "", "my-app/web:azd-deploy-0"},
+		{"RegistryName", &ImagesOptions{Name: "{registry}/{project}/{service}"}, "", "my-app/web:azd-deploy-0"},
+		{"GitSha", &ImagesOptions{Tag: "{gitsha}"}, "", "my-app/web-dev:1a2b3c4"},
+		{"Semver", &ImagesOptions{Tag: "{semver}"}, "", "my-app/web-dev:v1.2.0-3-g1a2b3c4-dirty"},
+		{"RunId", &ImagesOptions{Tag: "{env}-{runid}"}, "", "my-app/web-dev:dev-42"},
+		{"ExplicitTag", &ImagesOptions{Tag: "{gitsha}"}, "1.0.0", "my-app/web-dev:1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environment.EphemeralWithValues("dev", map[string]string{})
+			containerHelper := NewContainerHelper(
+				env, clock.NewMock(), nil, nil, git.NewGitCli(mockContext.CommandRunner))
+			require.NoError(t, containerHelper.SetImageTag(tt.imageTag))
+			serviceConfig := &ServiceConfig{
+				Name: "web",
+				Project: &ProjectConfig{
+					Name:   "my-app",
+					Images: tt.images,
+				},
+			}
+
+			tag, err := containerHelper.LocalImageTag(*mockContext.Context, serviceConfig)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tag)
+		})
+	}
+
+	t.Run("RegistryNotFirst", func(t *testing.T) {
+		containerHelper := NewContainerHelper(environment.Ephemeral(), clock.NewMock(), nil, nil, nil)
+		serviceConfig := &ServiceConfig{
+			Name: "web",
+			Project: &ProjectConfig{
+				Name:   "my-app",
+				Images: &ImagesOptions{Name: "{project}/{registry}/{service}"},
+			},
+		}
+
+		_, err := containerHelper.LocalImageTag(*mockContext.Context, serviceConfig)
+		require.Error(t, err)
+	})
+}
+
+func Test_ContainerHelper_SetImageTag_Invalid(t *testing.T) {
+	containerHelper := NewContainerHelper(environment.Ephemeral(), clock.NewMock(), nil, nil, nil)
+
+	for _, tag := range []string{"-latest", ".latest", "1.0.0+build", "my tag", strings.Repeat("a", 129)} {
+		require.Error(t, containerHelper.SetImageTag(tag), tag)
+	}
+}
+
 func Test_ContainerHelper_RemoteImageTag(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	env := environment.EphemeralWithValues("dev", map[string]string{
 		environment.ContainerRegistryEndpointEnvVarName: "contoso.azurecr.io",
 	})
-	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil)
+	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil, nil)
 	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 	localTag, err := containerHelper.LocalImageTag(*mockContext.Context, serviceConfig)
 	require.NoError(t, err)
@@ -71,14 +148,82 @@ func Test_ContainerHelper_RemoteImageTag(t *testing.T) {
 	require.Equal(t, "contoso.azurecr.io/test-app/api-dev:azd-deploy-0", remoteTag)
 }
 
+func Test_ContainerHelper_RemoteImageTag_ServiceRegistry(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "contoso.azurecr.io",
+		"PLATFORM_REGISTRY":                             "platform.azurecr.io",
+	})
+	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil, nil)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Registry = NewExpandableString("${PLATFORM_REGISTRY}")
+
+	remoteTag, err := containerHelper.RemoteImageTag(*mockContext.Context, serviceConfig, "test-app/api-dev:azd-deploy-0")
+	require.NoError(t, err)
+	require.Equal(t, "platform.azurecr.io/test-app/api-dev:azd-deploy-0", remoteTag)
+}
+
 func Test_ContainerHelper_RemoteImageTag_NoContainer_Registry(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 
 	env := environment.Ephemeral()
 	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
-	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil)
+	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil, nil)
 
 	imageTag, err := containerHelper.RemoteImageTag(*mockContext.Context, serviceConfig, "local_tag")
 	require.Error(t, err)
 	require.Empty(t, imageTag)
 }
+
+func Test_staleImages(t *testing.T) {
+	now := time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	tags := []*azcli.ContainerRegistryTag{
+		{Name: "azd-deploy-5", Digest: "sha256:5", LastUpdateTime: daysAgo(1)},
+		{Name: "azd-deploy-1", Digest: "sha256:1", LastUpdateTime: daysAgo(40)},
+		{Name: "azd-deploy-4", Digest: "sha256:4", LastUpdateTime: daysAgo(2)},
+		{Name: "azd-deploy-3", Digest: "sha256:3", LastUpdateTime: daysAgo(20)},
+		{Name: "azd-deploy-2", Digest: "sha256:2", LastUpdateTime: daysAgo(30)},
+		// same image as azd-deploy-4, kept with it
+		{Name: "latest", Digest: "sha256:4", LastUpdateTime: daysAgo(35)},
+	}
+
+	digests := func(images []*StaleImage) []string {
+		result := []string{}
+		for _, image := range images {
+			result = append(result, image.Digest)
+		}
+		return result
+	}
+
+	t.Run("Keep", func(t *testing.T) {
+		images := staleImages(tags, "azd-deploy-5", ImagesRetention{Keep: 2}, now)
+		require.Equal(t, []string{"sha256:3", "sha256:2", "sha256:1"}, digests(images))
+		require.Equal(t, []string{"azd-deploy-3"}, images[0].Tags)
+	})
+
+	t.Run("Deployed", func(t *testing.T) {
+		images := staleImages(tags, "azd-deploy-2", ImagesRetention{Keep: 2}, now)
+		require.Equal(t, []string{"sha256:3", "sha256:1"}, digests(images))
+	})
+
+	t.Run("Days", func(t *testing.T) {
+		images := staleImages(tags, "azd-deploy-5", ImagesRetention{Keep: 1, Days: 25}, now)
+		require.Equal(t, []string{"sha256:2", "sha256:1"}, digests(images))
+	})
+}
+
+func Test_ContainerHelper_StaleImages_NotDeployed(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "contoso.azurecr.io",
+	})
+	containerHelper := NewContainerHelper(env, clock.NewMock(), nil, nil, nil)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+
+	images, err := containerHelper.StaleImages(*mockContext.Context, serviceConfig, ImagesRetention{Keep: 1})
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
@@ -0,0 +1,407 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
+	"github.com/blang/semver/v4"
+	"golang.org/x/exp/slices"
+)
+
+type PackageManagerKind string
+
+const (
+	PackageManagerNpm    PackageManagerKind = "npm"
+	PackageManagerPnpm   PackageManagerKind = "pnpm"
+	PackageManagerYarn   PackageManagerKind = "yarn"
+	PackageManagerBun    PackageManagerKind = "bun"
+	PackageManagerPip    PackageManagerKind = "pip"
+	PackageManagerPoetry PackageManagerKind = "poetry"
+	PackageManagerUv     PackageManagerKind = "uv"
+	PackageManagerDotNet PackageManagerKind = "dotnet"
+	PackageManagerMaven  PackageManagerKind = "maven"
+	PackageManagerGradle PackageManagerKind = "gradle"
+	PackageManagerGo     PackageManagerKind = "go"
+)
+
+// languagePackageManagers are the package managers of each language, in the order they're detected. The last package
+// manager of a language is its default, used when no other package manager is detected.
+var languagePackageManagers = map[ServiceLanguageKind][]PackageManagerKind{
+	"":                        {PackageManagerDotNet},
+	ServiceLanguageDotNet:     {PackageManagerDotNet},
+	ServiceLanguageCsharp:     {PackageManagerDotNet},
+	ServiceLanguageFsharp:     {PackageManagerDotNet},
+	ServiceLanguageJavaScript: {PackageManagerPnpm, PackageManagerYarn, PackageManagerBun, PackageManagerNpm},
+	ServiceLanguageTypeScript: {PackageManagerPnpm, PackageManagerYarn, PackageManagerBun, PackageManagerNpm},
+	ServiceLanguagePython:     {PackageManagerPoetry, PackageManagerUv, PackageManagerPip},
+	ServiceLanguageJava:       {PackageManagerGradle, PackageManagerMaven},
+	ServiceLanguageGo:         {PackageManagerGo},
+}
+
+// DependencyRestorer restores the dependencies of a service with a package manager. Restorers are registered by
+// PackageManagerKind, and selected for a service by DependencyRestorers.
+type DependencyRestorer interface {
+	// Detect reports whether the project of the service uses the package manager, ex) from its lock file
+	Detect(serviceConfig *ServiceConfig) bool
+
+	// Gets the external tools restoring the dependencies of the service
+	RequiredExternalTools(serviceConfig *ServiceConfig) []tools.ExternalTool
+
+	// Restores the dependencies of the service, reporting progress messages to progress
+	Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error
+}
+
+// DependencyRestoreDetails are the details of the restore result of a service
+type DependencyRestoreDetails struct {
+	PackageManager PackageManagerKind `json:"packageManager"`
+	// The path of the environment the dependencies are restored to, ex) a Python virtual environment
+	Environment string `json:"environment,omitempty"`
+}
+
+// environmentResolver is implemented by restorers restoring the dependencies of a service to an environment, ex) a
+// Python virtual environment
+type environmentResolver interface {
+	EnvironmentPath(ctx context.Context, serviceConfig *ServiceConfig) (string, error)
+}
+
+// DependencyRestorers selects the DependencyRestorer of services, by the packageManager of the service in azure.yaml or by
+// detecting the package manager of the project of the service.
+type DependencyRestorers struct {
+	serviceLocator ioc.ServiceLocator
+}
+
+// NewDependencyRestorers creates a new instance of DependencyRestorers
+func NewDependencyRestorers(serviceLocator ioc.ServiceLocator) *DependencyRestorers {
+	return &DependencyRestorers{
+		serviceLocator: serviceLocator,
+	}
+}
+
+// Select returns the restorer of the service, and its package manager. Returns a nil restorer for languages without
+// package managers.
+func (r *DependencyRestorers) Select(serviceConfig *ServiceConfig) (DependencyRestorer, PackageManagerKind, error) {
+	kinds := languagePackageManagers[serviceConfig.Language]
+	if len(kinds) == 0 {
+		if serviceConfig.PackageManager != "" {
+			return nil, "", fmt.Errorf(
+				"package manager '%s' is not supported for language '%s'", serviceConfig.PackageManager, serviceConfig.Language)
+		}
+
+		return nil, "", nil
+	}
+
+	if serviceConfig.PackageManager != "" {
+		if !slices.Contains(kinds, serviceConfig.PackageManager) {
+			return nil, "", fmt.Errorf(
+				"package manager '%s' is not supported for language '%s', supported package managers are: %s",
+				serviceConfig.PackageManager, serviceConfig.Language, joinPackageManagers(kinds))
+		}
+
+		restorer, err := r.resolve(serviceConfig.PackageManager)
+		return restorer, serviceConfig.PackageManager, err
+	}
+
+	for _, kind := range kinds[:len(kinds)-1] {
+		restorer, err := r.resolve(kind)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if restorer.Detect(serviceConfig) {
+			return restorer, kind, nil
+		}
+	}
+
+	defaultKind := kinds[len(kinds)-1]
+	restorer, err := r.resolve(defaultKind)
+	return restorer, defaultKind, err
+}
+
+// Restore restores the dependencies of the service with its restorer
+func (r *DependencyRestorers) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			restorer, kind, err := r.Select(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if restorer == nil {
+				task.SetResult(&ServiceRestoreResult{})
+				return
+			}
+
+			if err := restorer.Restore(ctx, serviceConfig, func(message string) {
+				task.SetProgress(NewServiceProgress(message))
+			}); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			details := &DependencyRestoreDetails{PackageManager: kind}
+			if resolver, ok := restorer.(environmentResolver); ok {
+				// the dependencies are restored, the environment is only reported
+				environmentPath, err := resolver.EnvironmentPath(ctx, serviceConfig)
+				if err != nil {
+					log.Printf("resolving environment of service '%s': %v", serviceConfig.Name, err)
+				}
+				details.Environment = environmentPath
+			}
+
+			task.SetResult(&ServiceRestoreResult{
+				Details: details,
+			})
+		},
+	)
+}
+
+func (r *DependencyRestorers) resolve(kind PackageManagerKind) (DependencyRestorer, error) {
+	var restorer DependencyRestorer
+	if err := r.serviceLocator.ResolveNamed(string(kind), &restorer); err != nil {
+		return nil, fmt.Errorf("resolving package manager '%s': %w", kind, err)
+	}
+
+	return restorer, nil
+}
+
+func joinPackageManagers(kinds []PackageManagerKind) string {
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = string(kind)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// fileExists reports whether any of the files exist in dir
+func fileExists(dir string, files ...string) bool {
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commandTool is a package manager invoked as a command, with a minimum version
+type commandTool struct {
+	commandRunner  exec.CommandRunner
+	name           string
+	cmd            string
+	versionArgs    []string
+	minimumVersion semver.Version
+	installUrl     string
+}
+
+func (c *commandTool) Name() string {
+	return c.name
+}
+
+func (c *commandTool) InstallUrl() string {
+	return c.installUrl
+}
+
+func (c *commandTool) CheckInstalled(ctx context.Context) error {
+	if err := tools.ToolInPath(c.cmd); err != nil {
+		return err
+	}
+
+	versionOutput, err := tools.ExecuteCommand(ctx, c.commandRunner, c.cmd, c.versionArgs...)
+	if err != nil {
+		return fmt.Errorf("checking %s version: %w", c.name, err)
+	}
+
+	version, err := tools.ExtractVersion(versionOutput)
+	if err != nil {
+		return fmt.Errorf("converting to semver version fails: %w", err)
+	}
+
+	if version.LT(c.minimumVersion) {
+		return &tools.ErrSemver{
+			ToolName: c.name,
+			VersionInfo: tools.VersionInfo{
+				MinimumVersion: c.minimumVersion,
+				UpdateCommand:  fmt.Sprintf("Visit %s to upgrade", c.installUrl),
+			},
+		}
+	}
+
+	return nil
+}
+
+// commandRestorer restores dependencies by running the restore command of a package manager, ex) poetry install
+type commandRestorer struct {
+	commandTool
+	// files of projects using the package manager, ex) lock files
+	detectFiles []string
+	// wrapper scripts of the package manager, used instead of cmd when found in the project, ex) gradlew
+	wrappers    []string
+	restoreArgs []string
+	progress    string
+}
+
+func (c *commandRestorer) Detect(serviceConfig *ServiceConfig) bool {
+	return fileExists(serviceConfig.Path(), c.detectFiles...)
+}
+
+func (c *commandRestorer) RequiredExternalTools(serviceConfig *ServiceConfig) []tools.ExternalTool {
+	if c.wrapper(serviceConfig.Path()) != "" {
+		return nil
+	}
+
+	return []tools.ExternalTool{&c.commandTool}
+}
+
+func (c *commandRestorer) Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error {
+	progress(c.progress)
+
+	if err := c.run(ctx, serviceConfig, c.restoreArgs...); err != nil {
+		return fmt.Errorf("restoring dependencies of '%s' with %s: %w", serviceConfig.Path(), c.cmd, err)
+	}
+
+	return nil
+}
+
+// run runs the package manager in the project of the service, using the wrapper script of the project when it has one
+func (c *commandRestorer) run(ctx context.Context, serviceConfig *ServiceConfig, args ...string) error {
+	cmd := c.cmd
+	if wrapper := c.wrapper(serviceConfig.Path()); wrapper != "" {
+		cmd = wrapper
+	}
+
+	runArgs := exec.NewRunArgs(cmd, args...).WithCwd(serviceConfig.Path())
+	_, err := c.commandRunner.Run(ctx, runArgs)
+	return err
+}
+
+// wrapper returns the path of the wrapper script of the package manager in the project, if any
+func (c *commandRestorer) wrapper(projectPath string) string {
+	for _, wrapper := range c.wrappers {
+		wrapperPath := filepath.Join(projectPath, wrapper)
+		if _, err := os.Stat(wrapperPath); err == nil {
+			return wrapperPath
+		}
+	}
+
+	return ""
+}
+
+// NewGradleRestorer creates a restorer of Gradle projects, using the gradle wrapper of the project when it has one
+func NewGradleRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	wrappers := []string{"gradlew"}
+	if runtime.GOOS == "windows" {
+		wrappers = []string{"gradlew.bat"}
+	}
+
+	return &commandRestorer{
+		commandTool: commandTool{
+			commandRunner:  commandRunner,
+			name:           "Gradle",
+			cmd:            "gradle",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Major: 7},
+			installUrl:     "https://gradle.org/install/",
+		},
+		detectFiles: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+		wrappers:    wrappers,
+		restoreArgs: []string{"dependencies", "--quiet"},
+		progress:    "Resolving Gradle dependencies",
+	}
+}
+
+// NewGoRestorer creates a restorer of Go modules, using go mod download
+func NewGoRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &commandRestorer{
+		commandTool: commandTool{
+			commandRunner:  commandRunner,
+			name:           "Go",
+			cmd:            "go",
+			versionArgs:    []string{"version"},
+			minimumVersion: semver.Version{Major: 1, Minor: 20},
+			installUrl:     "https://go.dev/doc/install",
+		},
+		detectFiles: []string{"go.mod"},
+		restoreArgs: []string{"mod", "download"},
+		progress:    "Downloading Go modules",
+	}
+}
+
+type dotnetRestorer struct {
+	cli dotnet.DotNetCli
+}
+
+// NewDotNetRestorer creates a restorer of .NET projects, using dotnet restore
+func NewDotNetRestorer(cli dotnet.DotNetCli) DependencyRestorer {
+	return &dotnetRestorer{cli: cli}
+}
+
+// dotnet restore restores all .NET projects
+func (r *dotnetRestorer) Detect(*ServiceConfig) bool {
+	return true
+}
+
+func (r *dotnetRestorer) RequiredExternalTools(*ServiceConfig) []tools.ExternalTool {
+	return []tools.ExternalTool{r.cli}
+}
+
+func (r *dotnetRestorer) Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error {
+	progress("Restoring .NET project dependencies")
+	projFile, err := findProjectFile(serviceConfig.Name, serviceConfig.Path())
+	if err != nil {
+		return err
+	}
+
+	return r.cli.Restore(ctx, projFile)
+}
+
+type mavenRestorer struct {
+	cli maven.MavenCli
+	// the path of the maven CLI is set per project, services are restored one at a time
+	mu sync.Mutex
+}
+
+// NewMavenRestorer creates a restorer of Maven projects, using the maven wrapper of the project when it has one
+func NewMavenRestorer(cli maven.MavenCli) DependencyRestorer {
+	return &mavenRestorer{cli: cli}
+}
+
+func (r *mavenRestorer) Detect(serviceConfig *ServiceConfig) bool {
+	return fileExists(serviceConfig.Path(), "pom.xml")
+}
+
+func (r *mavenRestorer) RequiredExternalTools(*ServiceConfig) []tools.ExternalTool {
+	return []tools.ExternalTool{r.cli}
+}
+
+func (r *mavenRestorer) Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress("Resolving maven dependencies")
+	r.cli.SetPath(serviceConfig.Path(), serviceConfig.Project.Path)
+	if err := r.cli.ResolveDependencies(ctx, serviceConfig.Path()); err != nil {
+		return fmt.Errorf("resolving maven dependencies: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/blang/semver/v4"
+)
+
+// nodeLockFiles are the lock files of the JavaScript package managers, in the order they're detected
+var nodeLockFiles = []struct {
+	file string
+	kind PackageManagerKind
+}{
+	{"pnpm-lock.yaml", PackageManagerPnpm},
+	{"yarn.lock", PackageManagerYarn},
+	{"bun.lockb", PackageManagerBun},
+	{"bun.lock", PackageManagerBun},
+	{"package-lock.json", PackageManagerNpm},
+}
+
+// packageJson is the subset of a package.json read by azd
+type packageJson struct {
+	Name    string            `json:"name"`
+	Scripts map[string]string `json:"scripts"`
+	// The package manager of the project, set by corepack, ex) pnpm@8.15.4
+	PackageManager string `json:"packageManager"`
+	// The workspaces of the project, either a list of globs or an object with a packages list
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+func readPackageJson(dir string) (*packageJson, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg packageJson
+	if err := json.Unmarshal(contents, &pkg); err != nil {
+		return nil, fmt.Errorf("parsing package.json of '%s': %w", dir, err)
+	}
+
+	return &pkg, nil
+}
+
+// nodeProject is the JavaScript project of a service, and the package manager installing its dependencies
+type nodeProject struct {
+	// The directory the package manager runs in, the root of the workspace when the service is a workspace package
+	root string
+	// The name of the package of the service in the workspace, empty when the service isn't a workspace package
+	workspacePackage string
+	// The package manager of the project, empty when it isn't detected
+	kind PackageManagerKind
+	// Whether yarn is Yarn Berry, ex) yarn 2 or later
+	yarnBerry bool
+}
+
+// detectNodeProject detects the package manager of the JavaScript project of the service, from the lock files or the
+// packageManager field of the package.json of the project. When the project doesn't have a lock file, the parent
+// directories up to the root of the azd project are searched for the root of a workspace which the service is a package
+// of, ex) a package.json with workspaces or a pnpm-workspace.yaml.
+func detectNodeProject(serviceConfig *ServiceConfig) *nodeProject {
+	servicePath := filepath.Clean(serviceConfig.Path())
+	if project := detectNodePackageManager(servicePath); project != nil {
+		return project
+	}
+
+	projectRoot := filepath.Clean(serviceConfig.Project.Path)
+	for dir := servicePath; dir != projectRoot; {
+		parent := filepath.Dir(dir)
+		if rel, err := filepath.Rel(projectRoot, parent); parent == dir || err != nil || strings.HasPrefix(rel, "..") {
+			break
+		}
+		dir = parent
+
+		if !isNodeWorkspace(dir) {
+			continue
+		}
+
+		project := detectNodePackageManager(dir)
+		if project == nil {
+			project = &nodeProject{root: dir, kind: PackageManagerNpm}
+			if fileExists(dir, "pnpm-workspace.yaml") {
+				project.kind = PackageManagerPnpm
+			}
+		}
+
+		if pkg, err := readPackageJson(servicePath); err == nil && pkg.Name != "" {
+			project.workspacePackage = pkg.Name
+		}
+
+		return project
+	}
+
+	project := &nodeProject{root: servicePath}
+	if pkg, err := readPackageJson(servicePath); err == nil {
+		project.kind, project.yarnBerry = parsePackageManagerField(pkg.PackageManager)
+	}
+
+	return project
+}
+
+// detectNodePackageManager detects the package manager of the project at dir from its lock files or the packageManager
+// field of its package.json. Returns nil when the package manager isn't detected.
+func detectNodePackageManager(dir string) *nodeProject {
+	project := &nodeProject{root: dir}
+	if pkg, err := readPackageJson(dir); err == nil {
+		project.kind, project.yarnBerry = parsePackageManagerField(pkg.PackageManager)
+	}
+
+	if project.kind == "" {
+		for _, lockFile := range nodeLockFiles {
+			if fileExists(dir, lockFile.file) {
+				project.kind = lockFile.kind
+				break
+			}
+		}
+	}
+
+	if project.kind == "" {
+		return nil
+	}
+
+	// Yarn Berry projects are configured with a .yarnrc.yml, and their yarn.lock has a __metadata entry
+	if project.kind == PackageManagerYarn && !project.yarnBerry {
+		lockFile, err := os.ReadFile(filepath.Join(dir, "yarn.lock"))
+		project.yarnBerry = fileExists(dir, ".yarnrc.yml") || (err == nil && bytes.Contains(lockFile, []byte("__metadata:")))
+	}
+
+	return project
+}
+
+// parsePackageManagerField parses the packageManager field of a package.json, ex) yarn@4.1.0
+func parsePackageManagerField(value string) (PackageManagerKind, bool) {
+	name, version, _ := strings.Cut(value, "@")
+	switch kind := PackageManagerKind(name); kind {
+	case PackageManagerNpm, PackageManagerPnpm, PackageManagerBun:
+		return kind, false
+	case PackageManagerYarn:
+		return kind, !strings.HasPrefix(version, "1.")
+	default:
+		return "", false
+	}
+}
+
+// isNodeWorkspace reports whether dir is the root of a workspace, with a pnpm-workspace.yaml or a package.json
+// declaring workspaces
+func isNodeWorkspace(dir string) bool {
+	if fileExists(dir, "pnpm-workspace.yaml") {
+		return true
+	}
+
+	pkg, err := readPackageJson(dir)
+	return err == nil && len(pkg.Workspaces) > 0 && string(pkg.Workspaces) != "null"
+}
+
+// nodeRestorer restores the dependencies of JavaScript projects with npm, pnpm, yarn or bun, and runs the scripts of
+// their package.json. When the service is a package of a workspace, the dependencies are installed at the root of the
+// workspace, and the scripts are run for the package and the workspace packages it depends on.
+type nodeRestorer struct {
+	kind          PackageManagerKind
+	commandRunner exec.CommandRunner
+	tool          tools.ExternalTool
+}
+
+// NewNpmRestorer creates a restorer of projects with a package-lock.json or without a lock file, using npm install
+func NewNpmRestorer(cli npm.NpmCli, commandRunner exec.CommandRunner) DependencyRestorer {
+	return &nodeRestorer{
+		kind:          PackageManagerNpm,
+		commandRunner: commandRunner,
+		tool:          cli,
+	}
+}
+
+// NewPnpmRestorer creates a restorer of projects with a pnpm-lock.yaml, using pnpm install
+func NewPnpmRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &nodeRestorer{
+		kind:          PackageManagerPnpm,
+		commandRunner: commandRunner,
+		tool: &commandTool{
+			commandRunner:  commandRunner,
+			name:           "pnpm",
+			cmd:            "pnpm",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Major: 7},
+			installUrl:     "https://pnpm.io/installation",
+		},
+	}
+}
+
+// NewYarnRestorer creates a restorer of projects with a yarn.lock, using yarn install. Both Yarn Classic and Yarn Berry
+// are supported.
+func NewYarnRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &nodeRestorer{
+		kind:          PackageManagerYarn,
+		commandRunner: commandRunner,
+		tool: &commandTool{
+			commandRunner:  commandRunner,
+			name:           "Yarn",
+			cmd:            "yarn",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Major: 1, Minor: 22},
+			installUrl:     "https://yarnpkg.com/getting-started/install",
+		},
+	}
+}
+
+// NewBunRestorer creates a restorer of projects with a bun.lockb or bun.lock, using bun install
+func NewBunRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &nodeRestorer{
+		kind:          PackageManagerBun,
+		commandRunner: commandRunner,
+		tool: &commandTool{
+			commandRunner:  commandRunner,
+			name:           "Bun",
+			cmd:            "bun",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Major: 1},
+			installUrl:     "https://bun.sh/docs/installation",
+		},
+	}
+}
+
+func (r *nodeRestorer) Detect(serviceConfig *ServiceConfig) bool {
+	return detectNodeProject(serviceConfig).kind == r.kind
+}
+
+func (r *nodeRestorer) RequiredExternalTools(*ServiceConfig) []tools.ExternalTool {
+	return []tools.ExternalTool{r.tool}
+}
+
+func (r *nodeRestorer) Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error {
+	project := r.project(serviceConfig)
+	if project.workspacePackage != "" {
+		progress(fmt.Sprintf("Installing %s dependencies of workspace", r.kind))
+	} else {
+		progress(fmt.Sprintf("Installing %s dependencies", r.kind))
+	}
+
+	runArgs := exec.NewRunArgs(string(r.kind), "install").WithCwd(project.root)
+	if _, err := r.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("failed to install project %s: %w", project.root, err)
+	}
+
+	return nil
+}
+
+// RunScript runs the script of the package.json of the service, when it's defined. When the service is a package of a
+// workspace, the script is also run for the workspace packages the service depends on, for package managers which
+// support it.
+func (r *nodeRestorer) RunScript(ctx context.Context, serviceConfig *ServiceConfig, script string) error {
+	// When the package.json can't be read, the script is run and the package manager reports the error
+	if pkg, err := readPackageJson(serviceConfig.Path()); err == nil {
+		if _, has := pkg.Scripts[script]; !has {
+			return nil
+		}
+	}
+
+	project := r.project(serviceConfig)
+	runArgs := exec.NewRunArgs(string(r.kind), r.scriptArgs(project, script)...).WithCwd(project.root)
+	if _, err := r.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("failed to run %s script %s, %w", r.kind, script, err)
+	}
+
+	return nil
+}
+
+func (r *nodeRestorer) scriptArgs(project *nodeProject, script string) []string {
+	pkg := project.workspacePackage
+	switch {
+	case r.kind == PackageManagerPnpm && pkg != "":
+		// the ... suffix selects the package and the workspace packages it depends on, run in dependency order
+		return []string{"--filter", pkg + "...", "run", script}
+	case r.kind == PackageManagerPnpm:
+		return []string{"run", "--if-present", script}
+	case r.kind == PackageManagerYarn && pkg != "" && project.yarnBerry:
+		return []string{"workspaces", "foreach", "--recursive", "--topological", "--from", pkg, "run", script}
+	case r.kind == PackageManagerYarn && pkg != "":
+		return []string{"workspace", pkg, "run", script}
+	case r.kind == PackageManagerBun && pkg != "":
+		return []string{"run", "--filter", pkg, script}
+	case r.kind == PackageManagerNpm && pkg != "":
+		return []string{"run", script, "--workspace", pkg, "--if-present"}
+	case r.kind == PackageManagerNpm:
+		return []string{"run", script, "--if-present"}
+	default:
+		return []string{"run", script}
+	}
+}
+
+// project returns the project of the service, with the package manager of the restorer. The package manager set in
+// azure.yaml may differ from the detected package manager.
+func (r *nodeRestorer) project(serviceConfig *ServiceConfig) *nodeProject {
+	project := detectNodeProject(serviceConfig)
+	if project.kind != r.kind {
+		project.kind = r.kind
+		project.yarnBerry = false
+	}
+
+	return project
+}
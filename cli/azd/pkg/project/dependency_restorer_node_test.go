@@ -0,0 +1,212 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFiles writes the files, by their path relative to dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for path, contents := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	}
+}
+
+func Test_detectNodeProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected nodeProject
+	}{
+		{
+			name:     "NoLockFile",
+			files:    map[string]string{"src/web/package.json": `{"name": "web"}`},
+			expected: nodeProject{root: "src/web"},
+		},
+		{
+			name:     "LockFile",
+			files:    map[string]string{"src/web/bun.lockb": ""},
+			expected: nodeProject{root: "src/web", kind: PackageManagerBun},
+		},
+		{
+			name: "PackageManagerField",
+			files: map[string]string{
+				"src/web/package.json":      `{"packageManager": "yarn@4.1.0"}`,
+				"src/web/package-lock.json": "",
+			},
+			expected: nodeProject{root: "src/web", kind: PackageManagerYarn, yarnBerry: true},
+		},
+		{
+			name: "YarnClassic",
+			files: map[string]string{
+				"src/web/yarn.lock": "# yarn lockfile v1\n",
+			},
+			expected: nodeProject{root: "src/web", kind: PackageManagerYarn},
+		},
+		{
+			name: "PnpmWorkspace",
+			files: map[string]string{
+				"pnpm-workspace.yaml":  "packages:\n  - src/*\n",
+				"pnpm-lock.yaml":       "",
+				"src/web/package.json": `{"name": "@contoso/web"}`,
+			},
+			expected: nodeProject{root: ".", workspacePackage: "@contoso/web", kind: PackageManagerPnpm},
+		},
+		{
+			name: "YarnBerryWorkspace",
+			files: map[string]string{
+				"package.json":         `{"workspaces": {"packages": ["src/*"]}}`,
+				"yarn.lock":            "__metadata:\n  version: 8\n",
+				"src/web/package.json": `{"name": "web"}`,
+			},
+			expected: nodeProject{root: ".", workspacePackage: "web", kind: PackageManagerYarn, yarnBerry: true},
+		},
+		{
+			name: "NotWorkspace",
+			files: map[string]string{
+				"package.json":         `{"name": "tools"}`,
+				"package-lock.json":    "",
+				"src/web/package.json": `{"name": "web"}`,
+			},
+			expected: nodeProject{root: "src/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, tt.files)
+
+			serviceConfig := createTestServiceConfig("src/web", AppServiceTarget, ServiceLanguageJavaScript)
+			serviceConfig.Project.Path = dir
+
+			expected := tt.expected
+			expected.root = filepath.Join(dir, expected.root)
+			require.Equal(t, &expected, detectNodeProject(serviceConfig))
+		})
+	}
+}
+
+func Test_nodeRestorer_RunScript(t *testing.T) {
+	tests := []struct {
+		name         string
+		kind         PackageManagerKind
+		files        map[string]string
+		expectedArgs []string
+		expectedCwd  string
+	}{
+		{
+			name:         "Npm",
+			kind:         PackageManagerNpm,
+			files:        map[string]string{"src/web/package.json": `{"scripts": {"build": "tsc"}}`},
+			expectedArgs: []string{"run", "build", "--if-present"},
+			expectedCwd:  "src/web",
+		},
+		{
+			name: "NpmWorkspace",
+			kind: PackageManagerNpm,
+			files: map[string]string{
+				"package.json":         `{"workspaces": ["src/*"]}`,
+				"src/web/package.json": `{"name": "web", "scripts": {"build": "tsc"}}`,
+			},
+			expectedArgs: []string{"run", "build", "--workspace", "web", "--if-present"},
+			expectedCwd:  ".",
+		},
+		{
+			name: "PnpmWorkspace",
+			kind: PackageManagerPnpm,
+			files: map[string]string{
+				"pnpm-workspace.yaml":  "packages:\n  - src/*\n",
+				"src/web/package.json": `{"name": "web", "scripts": {"build": "tsc"}}`,
+			},
+			expectedArgs: []string{"--filter", "web...", "run", "build"},
+			expectedCwd:  ".",
+		},
+		{
+			name: "YarnBerryWorkspace",
+			kind: PackageManagerYarn,
+			files: map[string]string{
+				"package.json":         `{"workspaces": ["src/*"], "packageManager": "yarn@4.1.0"}`,
+				"src/web/package.json": `{"name": "web", "scripts": {"build": "tsc"}}`,
+			},
+			expectedArgs: []string{"workspaces", "foreach", "--recursive", "--topological", "--from", "web", "run", "build"},
+			expectedCwd:  ".",
+		},
+		{
+			name: "YarnClassicWorkspace",
+			kind: PackageManagerYarn,
+			files: map[string]string{
+				"package.json":         `{"workspaces": ["src/*"]}`,
+				"yarn.lock":            "# yarn lockfile v1\n",
+				"src/web/package.json": `{"name": "web", "scripts": {"build": "tsc"}}`,
+			},
+			expectedArgs: []string{"workspace", "web", "run", "build"},
+			expectedCwd:  ".",
+		},
+		{
+			name: "BunWorkspace",
+			kind: PackageManagerBun,
+			files: map[string]string{
+				"package.json":         `{"workspaces": ["src/*"]}`,
+				"bun.lock":             "",
+				"src/web/package.json": `{"name": "web", "scripts": {"build": "tsc"}}`,
+			},
+			expectedArgs: []string{"run", "--filter", "web", "build"},
+			expectedCwd:  ".",
+		},
+		{
+			name:  "NoScript",
+			kind:  PackageManagerYarn,
+			files: map[string]string{"src/web/package.json": `{"scripts": {"start": "node index.js"}}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runArgs *exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return strings.Contains(command, "build")
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					runArgs = &args
+					return exec.NewRunResult(0, "", ""), nil
+				})
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, tt.files)
+
+			serviceConfig := createTestServiceConfig("src/web", AppServiceTarget, ServiceLanguageJavaScript)
+			serviceConfig.Project.Path = dir
+
+			restorer, err := newTestDependencyRestorers(mockContext).resolve(tt.kind)
+			require.NoError(t, err)
+
+			err = restorer.(*nodeRestorer).RunScript(*mockContext.Context, serviceConfig, "build")
+			require.NoError(t, err)
+
+			if tt.expectedArgs == nil {
+				require.Nil(t, runArgs)
+				return
+			}
+
+			require.NotNil(t, runArgs)
+			require.Equal(t, string(tt.kind), runArgs.Cmd)
+			require.Equal(t, tt.expectedArgs, runArgs.Args)
+			require.Equal(t, filepath.Join(dir, tt.expectedCwd), runArgs.Cwd)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/python"
+	"github.com/blang/semver/v4"
+)
+
+// pythonRestorer restores the dependencies of Python projects with pip, poetry or uv. Projects using poetry or uv are
+// restored from their lock file, and are built as wheels.
+type pythonRestorer struct {
+	kind          PackageManagerKind
+	cli           *python.PythonCli
+	commandRunner exec.CommandRunner
+	tool          tools.ExternalTool
+}
+
+// NewPipRestorer creates a restorer of projects with a requirements.txt, installed with pip in a virtual environment
+// of the project
+func NewPipRestorer(cli *python.PythonCli) DependencyRestorer {
+	return &pythonRestorer{
+		kind: PackageManagerPip,
+		cli:  cli,
+		tool: cli,
+	}
+}
+
+// NewPoetryRestorer creates a restorer of projects with a poetry.lock or a pyproject.toml configuring poetry, using
+// poetry install
+func NewPoetryRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &pythonRestorer{
+		kind:          PackageManagerPoetry,
+		commandRunner: commandRunner,
+		tool: &commandTool{
+			commandRunner:  commandRunner,
+			name:           "Poetry",
+			cmd:            "poetry",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Major: 1, Minor: 2},
+			installUrl:     "https://python-poetry.org/docs/#installation",
+		},
+	}
+}
+
+// NewUvRestorer creates a restorer of projects with a uv.lock or a pyproject.toml configuring uv, using uv sync
+func NewUvRestorer(commandRunner exec.CommandRunner) DependencyRestorer {
+	return &pythonRestorer{
+		kind:          PackageManagerUv,
+		commandRunner: commandRunner,
+		tool: &commandTool{
+			commandRunner:  commandRunner,
+			name:           "uv",
+			cmd:            "uv",
+			versionArgs:    []string{"--version"},
+			minimumVersion: semver.Version{Minor: 4},
+			installUrl:     "https://docs.astral.sh/uv/getting-started/installation/",
+		},
+	}
+}
+
+func (r *pythonRestorer) Detect(serviceConfig *ServiceConfig) bool {
+	switch r.kind {
+	case PackageManagerPoetry:
+		return fileExists(serviceConfig.Path(), "poetry.lock") || pyprojectHasTool(serviceConfig.Path(), "poetry")
+	case PackageManagerUv:
+		return fileExists(serviceConfig.Path(), "uv.lock") || pyprojectHasTool(serviceConfig.Path(), "uv")
+	default:
+		return fileExists(serviceConfig.Path(), "requirements.txt")
+	}
+}
+
+// pyprojectHasTool reports whether the pyproject.toml of the project has a [tool.<name>] table
+func pyprojectHasTool(projectPath string, name string) bool {
+	contents, err := os.ReadFile(filepath.Join(projectPath, "pyproject.toml"))
+	return err == nil && bytes.Contains(contents, []byte(fmt.Sprintf("[tool.%s]", name)))
+}
+
+func (r *pythonRestorer) RequiredExternalTools(*ServiceConfig) []tools.ExternalTool {
+	return []tools.ExternalTool{r.tool}
+}
+
+func (r *pythonRestorer) Restore(ctx context.Context, serviceConfig *ServiceConfig, progress func(message string)) error {
+	switch r.kind {
+	case PackageManagerPoetry:
+		progress("Installing Poetry dependencies")
+		return r.run(ctx, serviceConfig, "install", "--no-root")
+	case PackageManagerUv:
+		// --frozen installs the locked dependencies, without updating the lock file
+		progress("Syncing uv dependencies")
+		args := []string{"sync"}
+		if fileExists(serviceConfig.Path(), "uv.lock") {
+			args = append(args, "--frozen")
+		}
+		return r.run(ctx, serviceConfig, args...)
+	}
+
+	progress("Checking for Python virtual environment")
+	vEnvName := pythonVenvName(serviceConfig)
+	vEnvPath := filepath.Join(serviceConfig.Path(), vEnvName)
+
+	if _, err := os.Stat(vEnvPath); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("python virtual environment for project '%s' is not accessible: %w", serviceConfig.Path(), err)
+		}
+
+		progress("Creating Python virtual environment")
+		if err := r.cli.CreateVirtualEnv(ctx, serviceConfig.Path(), vEnvName); err != nil {
+			return fmt.Errorf(
+				"python virtual environment for project '%s' could not be created: %w", serviceConfig.Path(), err)
+		}
+	}
+
+	progress("Installing Python PIP dependencies")
+	if err := r.cli.InstallRequirements(ctx, serviceConfig.Path(), vEnvName, "requirements.txt"); err != nil {
+		return fmt.Errorf("requirements for project '%s' could not be installed: %w", serviceConfig.Path(), err)
+	}
+
+	return nil
+}
+
+// EnvironmentPath returns the path of the virtual environment the dependencies of the service are restored to
+func (r *pythonRestorer) EnvironmentPath(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	switch r.kind {
+	case PackageManagerPoetry:
+		// poetry creates the virtual environment in its cache directory, unless virtualenvs.in-project is set
+		runArgs := exec.NewRunArgs("poetry", "env", "info", "--path").WithCwd(serviceConfig.Path())
+		result, err := r.commandRunner.Run(ctx, runArgs)
+		if err != nil {
+			return "", fmt.Errorf("resolving poetry virtual environment of '%s': %w", serviceConfig.Path(), err)
+		}
+
+		return strings.TrimSpace(result.Stdout), nil
+	case PackageManagerUv:
+		if vEnvPath := os.Getenv("UV_PROJECT_ENVIRONMENT"); vEnvPath != "" {
+			if !filepath.IsAbs(vEnvPath) {
+				vEnvPath = filepath.Join(serviceConfig.Path(), vEnvPath)
+			}
+			return vEnvPath, nil
+		}
+
+		return filepath.Join(serviceConfig.Path(), ".venv"), nil
+	default:
+		return filepath.Join(serviceConfig.Path(), pythonVenvName(serviceConfig)), nil
+	}
+}
+
+// BuildWheels builds the wheels of projects using poetry or uv to the dist directory of the project, and returns the
+// paths of the wheels. Projects using pip aren't built.
+func (r *pythonRestorer) BuildWheels(ctx context.Context, serviceConfig *ServiceConfig) ([]string, error) {
+	distPath := filepath.Join(serviceConfig.Path(), "dist")
+
+	switch r.kind {
+	case PackageManagerPoetry:
+		if err := r.run(ctx, serviceConfig, "build", "--format", "wheel"); err != nil {
+			return nil, err
+		}
+	case PackageManagerUv:
+		if err := r.run(ctx, serviceConfig, "build", "--wheel", "--out-dir", distPath); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	wheels, err := filepath.Glob(filepath.Join(distPath, "*.whl"))
+	if err != nil {
+		return nil, fmt.Errorf("finding wheels of '%s': %w", serviceConfig.Path(), err)
+	}
+
+	return wheels, nil
+}
+
+// ExportRequirements writes the locked dependencies of projects using poetry or uv to a requirements.txt at dest, for
+// hosts installing the dependencies of the package with pip, ex) App Service
+func (r *pythonRestorer) ExportRequirements(ctx context.Context, serviceConfig *ServiceConfig, dest string) error {
+	switch r.kind {
+	case PackageManagerPoetry:
+		return r.run(ctx, serviceConfig, "export", "--format", "requirements.txt", "--without-hashes", "--output", dest)
+	case PackageManagerUv:
+		return r.run(ctx, serviceConfig,
+			"export", "--format", "requirements-txt", "--no-hashes", "--no-emit-project", "--output-file", dest)
+	default:
+		return nil
+	}
+}
+
+func (r *pythonRestorer) run(ctx context.Context, serviceConfig *ServiceConfig, args ...string) error {
+	runArgs := exec.NewRunArgs(string(r.kind), args...).WithCwd(serviceConfig.Path())
+	if _, err := r.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("running %s %s for '%s': %w", r.kind, args[0], serviceConfig.Path(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/python"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDependencyRestorers registers the restorers of all package managers in the container of the mock context
+func newTestDependencyRestorers(mockContext *mocks.MockContext) *DependencyRestorers {
+	commandRunner := mockContext.CommandRunner
+	restorers := map[PackageManagerKind]DependencyRestorer{
+		PackageManagerNpm:    NewNpmRestorer(npm.NewNpmCli(commandRunner), commandRunner),
+		PackageManagerPnpm:   NewPnpmRestorer(commandRunner),
+		PackageManagerYarn:   NewYarnRestorer(commandRunner),
+		PackageManagerBun:    NewBunRestorer(commandRunner),
+		PackageManagerPip:    NewPipRestorer(python.NewPythonCli(commandRunner)),
+		PackageManagerPoetry: NewPoetryRestorer(commandRunner),
+		PackageManagerUv:     NewUvRestorer(commandRunner),
+		PackageManagerDotNet: NewDotNetRestorer(dotnet.NewDotNetCli(commandRunner)),
+		PackageManagerMaven:  NewMavenRestorer(maven.NewMavenCli(commandRunner)),
+		PackageManagerGradle: NewGradleRestorer(commandRunner),
+		PackageManagerGo:     NewGoRestorer(commandRunner),
+	}
+
+	for kind, restorer := range restorers {
+		ioc.RegisterNamedInstance(mockContext.Container, string(kind), restorer)
+	}
+
+	return NewDependencyRestorers(ioc.NewServiceLocator(mockContext.Container))
+}
+
+func Test_DependencyRestorers_Select(t *testing.T) {
+	tests := []struct {
+		name           string
+		language       ServiceLanguageKind
+		files          []string
+		packageManager PackageManagerKind
+		expected       PackageManagerKind
+		expectedErr    string
+	}{
+		{name: "DefaultNpm", language: ServiceLanguageJavaScript, files: []string{"package.json"}, expected: "npm"},
+		{name: "DetectPnpm", language: ServiceLanguageTypeScript, files: []string{"pnpm-lock.yaml"}, expected: "pnpm"},
+		{name: "DetectYarn", language: ServiceLanguageJavaScript, files: []string{"yarn.lock"}, expected: "yarn"},
+		{name: "DetectBun", language: ServiceLanguageJavaScript, files: []string{"bun.lockb"}, expected: "bun"},
+		{name: "DefaultPip", language: ServiceLanguagePython, files: []string{"requirements.txt"}, expected: "pip"},
+		{name: "DetectPoetry", language: ServiceLanguagePython, files: []string{"poetry.lock"}, expected: "poetry"},
+		{name: "DetectUv", language: ServiceLanguagePython, files: []string{"uv.lock"}, expected: "uv"},
+		{name: "DefaultMaven", language: ServiceLanguageJava, files: []string{"pom.xml"}, expected: "maven"},
+		{name: "DetectGradle", language: ServiceLanguageJava, files: []string{"build.gradle.kts"}, expected: "gradle"},
+		{name: "Go", language: ServiceLanguageGo, files: []string{"go.mod"}, expected: "go"},
+		{name: "DotNet", language: ServiceLanguageCsharp, expected: "dotnet"},
+		{
+			name:           "Configured",
+			language:       ServiceLanguageJavaScript,
+			files:          []string{"yarn.lock"},
+			packageManager: PackageManagerPnpm,
+			expected:       "pnpm",
+		},
+		{
+			name:           "Unsupported",
+			language:       ServiceLanguagePython,
+			packageManager: PackageManagerNpm,
+			expectedErr:    "package manager 'npm' is not supported for language 'python'",
+		},
+		{name: "NoPackageManager", language: ServiceLanguageDocker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			restorers := newTestDependencyRestorers(mockContext)
+
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), nil, 0600))
+			}
+
+			serviceConfig := createTestServiceConfig(dir, AppServiceTarget, tt.language)
+			serviceConfig.Project.Path = ""
+			serviceConfig.PackageManager = tt.packageManager
+
+			restorer, kind, err := restorers.Select(serviceConfig)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, kind)
+			require.Equal(t, tt.expected == "", restorer == nil)
+		})
+	}
+}
+
+func Test_DependencyRestorers_Restore(t *testing.T) {
+	t.Run("Pnpm", func(t *testing.T) {
+		var runArgs exec.RunArgs
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "pnpm install")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				runArgs = args
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pnpm-lock.yaml"), nil, 0600))
+		serviceConfig := createTestServiceConfig(dir, AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Project.Path = ""
+
+		restoreTask := newTestDependencyRestorers(mockContext).Restore(*mockContext.Context, serviceConfig)
+		logProgress(restoreTask)
+
+		result, err := restoreTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, &DependencyRestoreDetails{PackageManager: PackageManagerPnpm}, result.Details)
+		require.Equal(t, "pnpm", runArgs.Cmd)
+		require.Equal(t, dir, runArgs.Cwd)
+	})
+
+	t.Run("GradleWrapper", func(t *testing.T) {
+		var runArgs exec.RunArgs
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "dependencies --quiet")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				runArgs = args
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "build.gradle"), nil, 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "gradlew"), nil, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "gradlew.bat"), nil, 0700))
+		serviceConfig := createTestServiceConfig(dir, AppServiceTarget, ServiceLanguageJava)
+		serviceConfig.Project.Path = ""
+
+		restorer, _, err := newTestDependencyRestorers(mockContext).Select(serviceConfig)
+		require.NoError(t, err)
+		// the wrapper of the project is used, gradle isn't required
+		require.Empty(t, restorer.RequiredExternalTools(serviceConfig))
+
+		require.NoError(t, restorer.Restore(*mockContext.Context, serviceConfig, func(string) {}))
+		require.Equal(t, dir, filepath.Dir(runArgs.Cmd))
+		require.True(t, strings.HasPrefix(filepath.Base(runArgs.Cmd), "gradlew"))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "go mod download")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				return exec.NewRunResult(1, "", "missing go.sum entry"), errors.New("exit code: 1")
+			})
+
+		dir := t.TempDir()
+		serviceConfig := createTestServiceConfig(dir, AppServiceTarget, ServiceLanguageGo)
+		serviceConfig.Project.Path = ""
+
+		restoreTask := newTestDependencyRestorers(mockContext).Restore(*mockContext.Context, serviceConfig)
+		logProgress(restoreTask)
+
+		_, err := restoreTask.Await()
+		require.ErrorContains(t, err, "restoring dependencies")
+	})
+}
@@ -5,36 +5,62 @@ package project
 
 import (
 	"fmt"
+	"regexp"
 
-	"github.com/drone/envsubst"
+	"github.com/azure/azure-dev/cli/azd/pkg/interpolate"
+	"golang.org/x/exp/slices"
 )
 
+// referenceRegex matches the names of the references, ex) VAR of ${VAR}, ${lower(VAR)} or $VAR, and the paths of the
+// references to the outputs of the infrastructure, ex) outputs.endpoints[0].url
+var referenceRegex = regexp.MustCompile(
+	`\$\{(?:[A-Za-z][A-Za-z0-9]*\()*(outputs\.[A-Za-z0-9_.\[\]]+)|\$\{?(?:[A-Za-z][A-Za-z0-9]*\()*([A-Za-z_][A-Za-z0-9_]*)`)
+
 func NewExpandableString(template string) ExpandableString {
 	return ExpandableString{
 		template: template,
 	}
 }
 
-// ExpandableString is a string that has ${foo} style references inside which can be evaluated.
+// ExpandableString is a string that has ${foo} style references inside which can be evaluated, with defaults,
+// functions and nested references, see [interpolate.Eval]. References to the outputs of the infrastructure, ex)
+// ${outputs.endpoints[0].url}, are evaluated with the path of the output as the name.
 type ExpandableString struct {
 	template string
 }
 
-// Envsubst evaluates the template, substituting values as [envsubst.Eval] would.
+// Envsubst evaluates the template, substituting values as [interpolate.Eval] would.
 func (e ExpandableString) Envsubst(mapping func(string) string) (string, error) {
-	return envsubst.Eval(e.template, mapping)
+	return interpolate.Eval(e.template, mapping)
 }
 
-// MustEnvsubst evaluates the template, substituting values as [envsubst.Eval] would and panics if there
+// MustEnvsubst evaluates the template, substituting values as [interpolate.Eval] would and panics if there
 // is an error (for example, the string is malformed).
 func (e ExpandableString) MustEnvsubst(mapping func(string) string) string {
-	if v, err := envsubst.Eval(e.template, mapping); err != nil {
+	if v, err := e.Envsubst(mapping); err != nil {
 		panic(fmt.Sprintf("MustEnvsubst: %v", err))
 	} else {
 		return v
 	}
 }
 
+// References returns the names of the variables referenced by the template, in the order they're first referenced.
+func (e ExpandableString) References() []string {
+	names := []string{}
+	for _, match := range referenceRegex.FindAllStringSubmatch(e.template, -1) {
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
 func (e ExpandableString) MarshalYAML() (interface{}, error) {
 	return e.template, nil
 }
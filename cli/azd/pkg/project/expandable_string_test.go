@@ -23,3 +23,28 @@ func TestExpandableStringYaml(t *testing.T) {
 
 	assert.Equal(t, "${foo}\n", string(marshalled))
 }
+
+func TestExpandableStringReferences(t *testing.T) {
+	assert.Equal(t, []string{"REGISTRY", "AZURE_ENV_NAME"},
+		NewExpandableString("${REGISTRY}/app-$AZURE_ENV_NAME:${REGISTRY}").References())
+	assert.Equal(t, []string{"AZURE_ENV_NAME", "DEFAULT_NAME"},
+		NewExpandableString("${lower(AZURE_ENV_NAME:-${DEFAULT_NAME})}").References())
+	assert.Empty(t, NewExpandableString("contoso.azurecr.io").References())
+}
+
+func TestExpandableStringOutputReferences(t *testing.T) {
+	e := NewExpandableString("${outputs.endpoints[0].url}/api?env=${AZURE_ENV_NAME}&region=${outputs.regions[1]}")
+
+	values := map[string]string{
+		"outputs.endpoints[0].url": "https://contoso.com",
+		"outputs.regions[1]":       "westus",
+		"AZURE_ENV_NAME":           "dev",
+	}
+	expanded, err := e.Envsubst(func(name string) string {
+		return values[name]
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://contoso.com/api?env=dev&region=westus", expanded)
+
+	assert.Equal(t, []string{"outputs.endpoints[0].url", "AZURE_ENV_NAME", "outputs.regions[1]"}, e.References())
+}
@@ -22,6 +22,8 @@ const (
 	ServiceLanguageTypeScript ServiceLanguageKind = "ts"
 	ServiceLanguagePython     ServiceLanguageKind = "python"
 	ServiceLanguageJava       ServiceLanguageKind = "java"
+	ServiceLanguageGo         ServiceLanguageKind = "go"
+	ServiceLanguageCustom     ServiceLanguageKind = "custom"
 	ServiceLanguageDocker     ServiceLanguageKind = "docker"
 )
 
@@ -42,7 +44,9 @@ func parseServiceLanguage(kind ServiceLanguageKind) (ServiceLanguageKind, error)
 		ServiceLanguageJavaScript,
 		ServiceLanguageTypeScript,
 		ServiceLanguagePython,
-		ServiceLanguageJava:
+		ServiceLanguageJava,
+		ServiceLanguageGo,
+		ServiceLanguageCustom:
 		// Excluding ServiceLanguageDocker since it is implicitly derived currently, and not an actual language
 		return kind, nil
 	}
@@ -0,0 +1,160 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/apphost"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+)
+
+// AppHostFramework is the name of the framework service of the services synthesized from .NET Aspire app hosts
+const AppHostFramework = "apphost"
+
+type appHostProject struct {
+	env       *environment.Environment
+	dotnetCli dotnet.DotNetCli
+	docker    docker.Docker
+	restorers *DependencyRestorers
+	// tags and packages the images built for the resources, like the images of docker projects
+	images *dockerProject
+}
+
+// NewAppHostProject creates a new instance of the framework service of the services synthesized from the resources of
+// .NET Aspire app hosts. Projects are built as container images with the container support of the .NET SDK, Dockerfiles
+// are built with docker, and the images of containers are pulled.
+func NewAppHostProject(
+	env *environment.Environment,
+	dotnetCli dotnet.DotNetCli,
+	docker docker.Docker,
+	containerHelper *ContainerHelper,
+	restorers *DependencyRestorers,
+) FrameworkService {
+	return &appHostProject{
+		env:       env,
+		dotnetCli: dotnetCli,
+		docker:    docker,
+		restorers: restorers,
+		images: &dockerProject{
+			env:             env,
+			docker:          docker,
+			containerHelper: containerHelper,
+		},
+	}
+}
+
+func (ap *appHostProject) Requirements() FrameworkRequirements {
+	return FrameworkRequirements{
+		Package: FrameworkPackageRequirements{
+			RequireRestore: false,
+			// The container image is built before it's packaged
+			RequireBuild: true,
+		},
+	}
+}
+
+// Gets the required external tools for the project
+func (ap *appHostProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{ap.dotnetCli, ap.docker}
+}
+
+// Initializes the app host project
+func (ap *appHostProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	if serviceConfig.AppHost == nil {
+		return fmt.Errorf("service '%s' isn't a resource of an app host", serviceConfig.Name)
+	}
+
+	return nil
+}
+
+// Restores the dependencies of projects with dotnet restore. Containers have no dependencies.
+func (ap *appHostProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	if serviceConfig.AppHost.Resource().Type == apphost.ResourceTypeProject {
+		return ap.restorers.Restore(ctx, serviceConfig)
+	}
+
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the container image of the resource of the service, in the local docker daemon
+func (ap *appHostProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			resource := serviceConfig.AppHost.Resource()
+			imageName := fmt.Sprintf(
+				"%s-%s",
+				strings.ToLower(serviceConfig.Project.Name),
+				strings.ToLower(serviceConfig.Name),
+			)
+
+			var imageId string
+			switch resource.Type {
+			case apphost.ResourceTypeProject:
+				task.SetProgress(NewServiceProgress("Publishing .NET container image"))
+				if err := ap.dotnetCli.PublishContainer(ctx, resource.Path, "Release", imageName, "latest"); err != nil {
+					task.SetError(fmt.Errorf("building container: %s: %w", serviceConfig.Name, err))
+					return
+				}
+				imageId = fmt.Sprintf("%s:latest", imageName)
+			case apphost.ResourceTypeDockerfile:
+				task.SetProgress(NewServiceProgress("Building Docker image"))
+				var err error
+				imageId, err = ap.docker.Build(
+					ctx, serviceConfig.Path(), resource.Path, docker.DefaultPlatform, resource.Context, imageName, nil)
+				if err != nil {
+					task.SetError(fmt.Errorf("building container: %s at %s: %w", serviceConfig.Name, resource.Context, err))
+					return
+				}
+			case apphost.ResourceTypeContainer:
+				task.SetProgress(NewServiceProgress("Pulling Docker image"))
+				if err := ap.docker.Pull(ctx, resource.Image); err != nil {
+					task.SetError(fmt.Errorf("pulling image %s of %s: %w", resource.Image, serviceConfig.Name, err))
+					return
+				}
+				imageId, imageName = resource.Image, resource.Image
+			default:
+				task.SetError(fmt.Errorf("resource '%s' of type '%s' isn't supported", serviceConfig.Name, resource.Type))
+				return
+			}
+
+			log.Printf("built image %s for %s", imageId, serviceConfig.Name)
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: imageId,
+				Details: &dockerBuildResult{
+					ImageId:   imageId,
+					ImageName: imageName,
+				},
+			})
+		},
+	)
+}
+
+// Tags the container image of the resource with the local tag of the service
+func (ap *appHostProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return ap.images.Package(ctx, serviceConfig, buildOutput)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/apphost"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AppHostProject_Build(t *testing.T) {
+	tests := []struct {
+		name            string
+		resource        *apphost.Resource
+		expectedArgs    []string
+		expectedImageId string
+	}{
+		{
+			name:     "Project",
+			resource: &apphost.Resource{Type: apphost.ResourceTypeProject, Path: "ApiService.csproj"},
+			expectedArgs: []string{
+				"publish", "ApiService.csproj",
+				"-r", "linux-x64",
+				"-p:PublishProfile=DefaultContainer",
+				"-p:ContainerRepository=test-app-api",
+				"-p:ContainerImageTag=latest",
+				"-c", "Release",
+			},
+			expectedImageId: "test-app-api:latest",
+		},
+		{
+			name:            "Container",
+			resource:        &apphost.Resource{Type: apphost.ResourceTypeContainer, Image: "redis:latest"},
+			expectedArgs:    []string{"pull", "--platform", docker.DefaultPlatform, "redis:latest"},
+			expectedImageId: "redis:latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runArgs exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return true
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					runArgs = args
+					return exec.NewRunResult(0, "", ""), nil
+				})
+
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDotNet)
+			serviceConfig.AppHost = &AppHostResource{
+				AppHost:  "app",
+				Name:     "api",
+				Manifest: &apphost.Manifest{Resources: map[string]*apphost.Resource{"api": tt.resource}},
+			}
+
+			env := environment.Ephemeral()
+			dockerCli := docker.NewDocker(mockContext.CommandRunner)
+			framework := NewAppHostProject(
+				env,
+				dotnet.NewDotNetCli(mockContext.CommandRunner),
+				dockerCli,
+				NewContainerHelper(env, clock.NewMock(), nil, dockerCli, nil),
+				newTestDependencyRestorers(mockContext),
+			)
+			require.NoError(t, framework.Initialize(*mockContext.Context, serviceConfig))
+
+			buildTask := framework.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+			logProgress(buildTask)
+
+			result, err := buildTask.Await()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedImageId, result.BuildOutputPath)
+			require.Equal(t, tt.expectedArgs, runArgs.Args)
+		})
+	}
+}
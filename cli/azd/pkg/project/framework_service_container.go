@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+)
+
+// BuildContainerOptions configures the restore and build of a service in a container of the SDK of its language instead
+// of on the host, for its builds to be reproducible without installing the SDK
+type BuildContainerOptions struct {
+	// Whether the service is restored, built and packaged in a container. Defaults to false.
+	Enabled bool `yaml:"enabled"`
+	// The image of the container, ex) node:20-bookworm. Defaults to an image of the SDK of the language of the service.
+	Image string `yaml:"image,omitempty"`
+}
+
+// The images of the SDKs of the languages services are built in by default
+var buildContainerImages = map[ServiceLanguageKind]string{
+	ServiceLanguageDotNet:     "mcr.microsoft.com/dotnet/sdk:8.0",
+	ServiceLanguageCsharp:     "mcr.microsoft.com/dotnet/sdk:8.0",
+	ServiceLanguageFsharp:     "mcr.microsoft.com/dotnet/sdk:8.0",
+	ServiceLanguageJavaScript: "node:20",
+	ServiceLanguageTypeScript: "node:20",
+	ServiceLanguagePython:     "python:3.12",
+	ServiceLanguageJava:       "maven:3-eclipse-temurin-17",
+	ServiceLanguageGo:         "golang:1.22",
+}
+
+// buildContainer returns the container the service is restored and built in, nil when it's built on the host
+func (sc *ServiceConfig) buildContainer() (*exec.Container, error) {
+	if sc.BuildContainer == nil || !sc.BuildContainer.Enabled {
+		return nil, nil
+	}
+
+	image := sc.BuildContainer.Image
+	if image == "" {
+		image = buildContainerImages[sc.Language]
+	}
+
+	if image == "" {
+		return nil, fmt.Errorf(
+			"service '%s' has no default build container for language '%s', set buildContainer.image", sc.Name, sc.Language)
+	}
+
+	// The project is mounted, for the services to reference the files of the project outside of their source, ex) the
+	// lock file of a workspace. So is the temp directory, where frameworks write their build and package outputs.
+	mounts := []string{sc.Project.Path}
+	if relative, err := filepath.Rel(sc.Project.Path, sc.Path()); err != nil || strings.HasPrefix(relative, "..") {
+		mounts = append(mounts, sc.Path())
+	}
+	mounts = append(mounts, os.TempDir())
+
+	return &exec.Container{Image: image, Mounts: mounts}, nil
+}
+
+// containerFrameworkService runs the restore, build and package of the framework service of a service in its build
+// container, with docker as the only tool required on the host
+type containerFrameworkService struct {
+	FrameworkService
+	container *exec.Container
+	docker    docker.Docker
+}
+
+func newContainerFrameworkService(
+	frameworkService FrameworkService,
+	container *exec.Container,
+	docker docker.Docker,
+) FrameworkService {
+	return &containerFrameworkService{
+		FrameworkService: frameworkService,
+		container:        container,
+		docker:           docker,
+	}
+}
+
+func (c *containerFrameworkService) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{c.docker}
+}
+
+func (c *containerFrameworkService) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return c.FrameworkService.Restore(exec.WithContainer(ctx, c.container), serviceConfig)
+}
+
+func (c *containerFrameworkService) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return c.FrameworkService.Build(exec.WithContainer(ctx, c.container), serviceConfig, restoreOutput)
+}
+
+// Package runs in the container too, as frameworks may build again when packaging, ex) the build script of npm
+func (c *containerFrameworkService) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return c.FrameworkService.Package(exec.WithContainer(ctx, c.container), serviceConfig, buildOutput)
+}
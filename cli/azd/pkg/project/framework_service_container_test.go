@@ -0,0 +1,128 @@
+package project
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceManager_BuildContainer(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	mockContext.Container.RegisterSingleton(docker.NewDocker)
+	sm := createServiceManager(mockContext, environment.Ephemeral())
+
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+	serviceConfig.BuildContainer = &BuildContainerOptions{Enabled: true, Image: "fake-sdk:1.0"}
+
+	frameworkService, err := sm.GetFrameworkService(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.IsType(t, &containerFrameworkService{}, frameworkService)
+	require.Equal(t, &exec.Container{
+		Image:  "fake-sdk:1.0",
+		Mounts: []string{".", os.TempDir()},
+	}, frameworkService.(*containerFrameworkService).container)
+
+	// Only docker is required on the host, the package manager of the service runs in the container
+	restorer, err := sm.GetDependencyRestorer(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.Nil(t, restorer)
+
+	tools, err := sm.GetRequiredTools(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	require.Equal(t, "Docker", tools[0].Name())
+}
+
+func Test_ServiceConfig_BuildContainer(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
+		container, err := serviceConfig.buildContainer()
+		require.NoError(t, err)
+		require.Nil(t, container)
+
+		serviceConfig.BuildContainer = &BuildContainerOptions{Image: "python:3.11"}
+		container, err = serviceConfig.buildContainer()
+		require.NoError(t, err)
+		require.Nil(t, container)
+	})
+
+	t.Run("DefaultImage", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
+		serviceConfig.BuildContainer = &BuildContainerOptions{Enabled: true}
+
+		container, err := serviceConfig.buildContainer()
+		require.NoError(t, err)
+		require.Equal(t, "python:3.12", container.Image)
+	})
+
+	t.Run("ServiceOutsideProject", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("../shared/api", AppServiceTarget, ServiceLanguageGo)
+		serviceConfig.BuildContainer = &BuildContainerOptions{Enabled: true}
+
+		container, err := serviceConfig.buildContainer()
+		require.NoError(t, err)
+		require.Equal(t, []string{".", serviceConfig.Path(), os.TempDir()}, container.Mounts)
+	})
+
+	t.Run("NoDefaultImage", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageCustom)
+		serviceConfig.BuildContainer = &BuildContainerOptions{Enabled: true}
+
+		_, err := serviceConfig.buildContainer()
+		require.ErrorContains(t, err, "set buildContainer.image")
+	})
+}
+
+// contextRecordingFramework records the contexts its restore and build run with
+type contextRecordingFramework struct {
+	FrameworkService
+	restoreCtx context.Context
+	buildCtx   context.Context
+}
+
+func (f *contextRecordingFramework) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	f.restoreCtx = ctx
+	return f.FrameworkService.Restore(ctx, serviceConfig)
+}
+
+func (f *contextRecordingFramework) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	f.buildCtx = ctx
+	return f.FrameworkService.Build(ctx, serviceConfig, restoreOutput)
+}
+
+func Test_ContainerFrameworkService(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+
+	recording := &contextRecordingFramework{FrameworkService: newFakeFramework(mockContext.CommandRunner)}
+	container := &exec.Container{Image: "fake-sdk:1.0"}
+	frameworkService := newContainerFrameworkService(recording, container, docker.NewDocker(mockContext.CommandRunner))
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+	restoreTask := frameworkService.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	restoreResult, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Same(t, container, exec.ContainerFromContext(recording.restoreCtx))
+
+	buildTask := frameworkService.Build(*mockContext.Context, serviceConfig, restoreResult)
+	logProgress(buildTask)
+	_, err = buildTask.Await()
+	require.NoError(t, err)
+	require.Same(t, container, exec.ContainerFromContext(recording.buildCtx))
+}
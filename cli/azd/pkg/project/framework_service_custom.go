@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/otiai10/copy"
+)
+
+// CustomOptions are the options of services built by the commands declared in azure.yaml, for languages azd has no
+// framework for, ex) Rust or C++
+type CustomOptions struct {
+	// The commands restoring the dependencies of the project
+	Restore []string `yaml:"restore,omitempty"`
+	// The commands building the project
+	Build []string `yaml:"build,omitempty"`
+	// The commands packaging the build output of the project
+	Package []string `yaml:"package,omitempty"`
+	// The path of the artifact deployed for the service, relative to the project. Either a directory, whose contents are
+	// deployed, or a file, ex) a binary.
+	Artifact string `yaml:"artifact,omitempty"`
+}
+
+type customProject struct {
+	env           *environment.Environment
+	commandRunner exec.CommandRunner
+}
+
+// NewCustomProject creates a new instance of a project built by the commands declared in azure.yaml
+func NewCustomProject(commandRunner exec.CommandRunner, env *environment.Environment) FrameworkService {
+	return &customProject{
+		env:           env,
+		commandRunner: commandRunner,
+	}
+}
+
+func (cp *customProject) Requirements() FrameworkRequirements {
+	return FrameworkRequirements{
+		Package: FrameworkPackageRequirements{
+			RequireRestore: true,
+			RequireBuild:   true,
+		},
+	}
+}
+
+// Gets the required external tools for the project. The toolchain of the project is run by the commands of the project,
+// and isn't checked by azd.
+func (cp *customProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the custom project
+func (cp *customProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	if serviceConfig.Custom.Artifact == "" {
+		return fmt.Errorf(
+			"service '%s' has language 'custom', set custom.artifact to the path of the artifact built by its commands",
+			serviceConfig.Name,
+		)
+	}
+
+	return nil
+}
+
+// Restores the dependencies of the project using the restore commands of the project
+func (cp *customProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running restore commands"))
+			if err := cp.run(ctx, serviceConfig, "restore", serviceConfig.Custom.Restore); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the project using the build commands of the project
+func (cp *customProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running build commands"))
+			if err := cp.run(ctx, serviceConfig, "build", serviceConfig.Custom.Build); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: filepath.Join(serviceConfig.Path(), serviceConfig.Custom.Artifact),
+			})
+		},
+	)
+}
+
+// Packages the project using the package commands of the project, and copies the artifact of the project to the package
+func (cp *customProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running package commands"))
+			if err := cp.run(ctx, serviceConfig, "package", serviceConfig.Custom.Package); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			artifactPath := filepath.Join(serviceConfig.Path(), serviceConfig.Custom.Artifact)
+			info, err := os.Stat(artifactPath)
+			if errors.Is(err, os.ErrNotExist) {
+				task.SetError(fmt.Errorf(
+					"artifact '%s' of service '%s' wasn't produced by its commands",
+					serviceConfig.Custom.Artifact,
+					serviceConfig.Name,
+				))
+				return
+			} else if err != nil {
+				task.SetError(fmt.Errorf("reading artifact of service '%s': %w", serviceConfig.Name, err))
+				return
+			}
+
+			packageDest, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			// the contents of directories are deployed, and files are deployed at the root of the package
+			copyDest := packageDest
+			if !info.IsDir() {
+				copyDest = filepath.Join(packageDest, info.Name())
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := copy.Copy(artifactPath, copyDest); err != nil {
+				task.SetError(fmt.Errorf("packaging for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if err := validatePackageOutput(packageDest); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: packageDest,
+			})
+		},
+	)
+}
+
+// run runs the commands of the step in the project of the service, with the values of the azd environment. The commands
+// are run in a shell, and stop at the first failing command.
+func (cp *customProject) run(ctx context.Context, serviceConfig *ServiceConfig, step string, commands []string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	runArgs := exec.NewRunArgs("", commands...).
+		WithShell(true).
+		WithCwd(serviceConfig.Path()).
+		WithEnv(cp.env.Environ())
+	if _, err := cp.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("running %s commands of service '%s' (%s): %w",
+			step, serviceConfig.Name, strings.Join(commands, " && "), err)
+	}
+
+	return nil
+}
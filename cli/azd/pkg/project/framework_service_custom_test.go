@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CustomProject(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: custom
+    host: appservice
+    custom:
+      restore:
+        - cargo fetch
+      build:
+        - cargo build --release --target x86_64-unknown-linux-musl
+        - strip target/x86_64-unknown-linux-musl/release/api
+      artifact: target/x86_64-unknown-linux-musl/release/api
+`
+	dir := t.TempDir()
+	servicePath := filepath.Join(dir, "src", "api")
+	artifactPath := filepath.Join(servicePath, "target", "x86_64-unknown-linux-musl", "release", "api")
+
+	ranCommands := [][]string{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.UseShell && args.Cmd == ""
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			require.Equal(t, servicePath, args.Cwd)
+			require.Contains(t, args.Env, "AZURE_ENV_NAME=test")
+
+			ranCommands = append(ranCommands, args.Args)
+			writeTestFiles(t, servicePath, map[string]string{
+				"target/x86_64-unknown-linux-musl/release/api": "binary",
+			})
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	projectConfig.Path = dir
+	serviceConfig := projectConfig.Services["api"]
+
+	env := environment.EphemeralWithValues("test", nil)
+	customProject := NewCustomProject(mockContext.CommandRunner, env)
+	require.NoError(t, customProject.Initialize(*mockContext.Context, serviceConfig))
+
+	restoreTask := customProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	restoreResult, err := restoreTask.Await()
+	require.NoError(t, err)
+
+	buildTask := customProject.Build(*mockContext.Context, serviceConfig, restoreResult)
+	logProgress(buildTask)
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, artifactPath, buildResult.BuildOutputPath)
+
+	packageTask := customProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	// the package commands aren't declared, and aren't run
+	require.Equal(t, [][]string{
+		{"cargo fetch"},
+		{"cargo build --release --target x86_64-unknown-linux-musl", "strip target/x86_64-unknown-linux-musl/release/api"},
+	}, ranCommands)
+
+	contents, err := os.ReadFile(filepath.Join(packageResult.PackagePath, "api"))
+	require.NoError(t, err)
+	require.Equal(t, "binary", string(contents))
+}
+
+func Test_CustomProject_Errors(t *testing.T) {
+	t.Run("NoArtifact", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageCustom)
+
+		customProject := NewCustomProject(mockContext.CommandRunner, environment.Ephemeral())
+		err := customProject.Initialize(*mockContext.Context, serviceConfig)
+		require.ErrorContains(t, err, "set custom.artifact")
+	})
+
+	t.Run("ArtifactNotProduced", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageCustom)
+		serviceConfig.Project.Path = ""
+		serviceConfig.Custom.Artifact = "dist"
+
+		customProject := NewCustomProject(mockContext.CommandRunner, environment.Ephemeral())
+		packageTask := customProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+		logProgress(packageTask)
+		_, err := packageTask.Await()
+		require.ErrorContains(t, err, "artifact 'dist' of service 'api' wasn't produced by its commands")
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
@@ -25,6 +27,9 @@ type DockerProjectOptions struct {
 	Platform  string           `json:"platform"`
 	Tag       ExpandableString `json:"tag"`
 	BuildArgs []string         `json:"buildArgs"`
+	// The login server of the container registry the image is pushed to, ex) a shared platform registry. Defaults to
+	// the container registry of the environment
+	Registry ExpandableString `json:"registry"`
 }
 
 type dockerBuildResult struct {
@@ -63,6 +68,14 @@ func (dpr *dockerPackageResult) MarshalJSON() ([]byte, error) {
 	return json.Marshal(*dpr)
 }
 
+// defaultDockerfileProvider is implemented by the framework services which can build a container image of projects
+// without a Dockerfile
+type defaultDockerfileProvider interface {
+	// DefaultDockerfile prepares the build context of the image of the project, and returns the paths of the Dockerfile
+	// and of the build context
+	DefaultDockerfile(ctx context.Context, serviceConfig *ServiceConfig) (string, string, error)
+}
+
 type dockerProject struct {
 	env             *environment.Environment
 	docker          docker.Docker
@@ -129,6 +142,20 @@ func (p *dockerProject) Build(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
 			dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
 
+			// Projects without a Dockerfile are containerized by their framework, when it supports it
+			provider, hasProvider := p.framework.(defaultDockerfileProvider)
+			if _, err := os.Stat(filepath.Join(serviceConfig.Path(), dockerOptions.Path)); err != nil &&
+				errors.Is(err, os.ErrNotExist) && serviceConfig.Docker.Path == "" && hasProvider {
+				task.SetProgress(NewServiceProgress("Preparing default Dockerfile"))
+				dockerfile, buildContext, err := provider.DefaultDockerfile(ctx, serviceConfig)
+				if err != nil {
+					task.SetError(fmt.Errorf("preparing Dockerfile of %s: %w", serviceConfig.Name, err))
+					return
+				}
+
+				dockerOptions.Path, dockerOptions.Context = dockerfile, buildContext
+			}
+
 			buildArgs := []string{}
 			for _, arg := range dockerOptions.BuildArgs {
 				buildArgs = append(buildArgs, exec.RedactSensitiveData(arg))
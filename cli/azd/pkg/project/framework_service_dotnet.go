@@ -25,16 +25,19 @@ const (
 type dotnetProject struct {
 	env       *environment.Environment
 	dotnetCli dotnet.DotNetCli
+	restorers *DependencyRestorers
 }
 
 // NewDotNetProject creates a new instance of a dotnet project
 func NewDotNetProject(
 	dotNetCli dotnet.DotNetCli,
 	env *environment.Environment,
+	restorers *DependencyRestorers,
 ) FrameworkService {
 	return &dotnetProject{
 		env:       env,
 		dotnetCli: dotNetCli,
+		restorers: restorers,
 	}
 }
 
@@ -72,27 +75,12 @@ func (dp *dotnetProject) Initialize(ctx context.Context, serviceConfig *ServiceC
 	return nil
 }
 
-// Restores the dependencies for the project
+// Restores the dependencies for the project using dotnet restore
 func (dp *dotnetProject) Restore(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
-	return async.RunTaskWithProgress(
-		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Restoring .NET project dependencies"))
-			projFile, err := findProjectFile(serviceConfig.Name, serviceConfig.Path())
-			if err != nil {
-				task.SetError(err)
-				return
-			}
-			if err := dp.dotnetCli.Restore(ctx, projFile); err != nil {
-				task.SetError(err)
-				return
-			}
-
-			task.SetResult(&ServiceRestoreResult{})
-		},
-	)
+	return dp.restorers.Restore(ctx, serviceConfig)
 }
 
 // Builds the dotnet project using the dotnet CLI
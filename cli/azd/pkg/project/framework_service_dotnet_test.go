@@ -48,7 +48,7 @@ func TestBicepOutputsWithDoubleUnderscoresAreConverted(t *testing.T) {
 	}
 
 	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
-	dp := NewDotNetProject(dotNetCli, environment.Ephemeral()).(*dotnetProject)
+	dp := NewDotNetProject(dotNetCli, environment.Ephemeral(), newTestDependencyRestorers(mockContext)).(*dotnetProject)
 
 	err := dp.setUserSecretsFromOutputs(*mockContext.Context, serviceConfig, ServiceLifecycleEventArgs{
 		Args: map[string]any{
@@ -96,7 +96,7 @@ func Test_DotNetProject_Init(t *testing.T) {
 	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api/test.csproj", AppServiceTarget, ServiceLanguageDotNet)
 
-	dotnetProject := NewDotNetProject(dotNetCli, env)
+	dotnetProject := NewDotNetProject(dotNetCli, env, newTestDependencyRestorers(mockContext))
 
 	err = dotnetProject.Initialize(*mockContext.Context, serviceConfig)
 	require.NoError(t, err)
@@ -148,7 +148,7 @@ func Test_DotNetProject_Restore(t *testing.T) {
 	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api/test.csproj", AppServiceTarget, ServiceLanguageCsharp)
 
-	dotnetProject := NewDotNetProject(dotNetCli, env)
+	dotnetProject := NewDotNetProject(dotNetCli, env, newTestDependencyRestorers(mockContext))
 	restoreTask := dotnetProject.Restore(*mockContext.Context, serviceConfig)
 	logProgress(restoreTask)
 
@@ -192,7 +192,7 @@ func Test_DotNetProject_Build(t *testing.T) {
 	err = os.MkdirAll(buildOutputDir, osutil.PermissionDirectory)
 	require.NoError(t, err)
 
-	dotnetProject := NewDotNetProject(dotNetCli, env)
+	dotnetProject := NewDotNetProject(dotNetCli, env, newTestDependencyRestorers(mockContext))
 	buildTask := dotnetProject.Build(*mockContext.Context, serviceConfig, nil)
 	logProgress(buildTask)
 
@@ -246,7 +246,7 @@ func Test_DotNetProject_Package(t *testing.T) {
 	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api/test3.csproj", AppServiceTarget, ServiceLanguageCsharp)
 
-	dotnetProject := NewDotNetProject(dotNetCli, env)
+	dotnetProject := NewDotNetProject(dotNetCli, env, newTestDependencyRestorers(mockContext))
 	packageTask := dotnetProject.Package(
 		*mockContext.Context,
 		serviceConfig,
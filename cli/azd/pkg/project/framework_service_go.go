@@ -0,0 +1,305 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/otiai10/copy"
+)
+
+// defaultGoBaseImage is the base image of the containers of Go services without a Dockerfile. Go binaries are built
+// statically, and don't need the libraries of a distribution.
+const defaultGoBaseImage = "gcr.io/distroless/static-debian12:nonroot"
+
+// GoOptions are the options of Go services
+type GoOptions struct {
+	// The main package built for the service, relative to the project of the service. Defaults to the project.
+	Package string `yaml:"package,omitempty"`
+	// The name of the binary. Defaults to the executable of the custom handler for Azure Functions, or the name of the
+	// service.
+	Binary string `yaml:"binary,omitempty"`
+	// The base image of the container of the service when the project has no Dockerfile. Defaults to a distroless image.
+	BaseImage string `yaml:"baseImage,omitempty"`
+}
+
+type goBuildResult struct {
+	Binary string `json:"binary"`
+	GOOS   string `json:"goos"`
+	GOARCH string `json:"goarch"`
+}
+
+func (gbr *goBuildResult) ToString(currentIndentation string) string {
+	return fmt.Sprintf("%s- Binary: %s (%s/%s)", currentIndentation, gbr.Binary, gbr.GOOS, gbr.GOARCH)
+}
+
+func (gbr *goBuildResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(*gbr)
+}
+
+type goProject struct {
+	env           *environment.Environment
+	commandRunner exec.CommandRunner
+	restorers     *DependencyRestorers
+}
+
+// NewGoProject creates a new instance of a Go project
+func NewGoProject(
+	commandRunner exec.CommandRunner,
+	env *environment.Environment,
+	restorers *DependencyRestorers,
+) FrameworkService {
+	return &goProject{
+		env:           env,
+		commandRunner: commandRunner,
+		restorers:     restorers,
+	}
+}
+
+func (gp *goProject) Requirements() FrameworkRequirements {
+	return FrameworkRequirements{
+		Package: FrameworkPackageRequirements{
+			RequireRestore: false,
+			// The binary is built before it's packaged
+			RequireBuild: true,
+		},
+	}
+}
+
+// Gets the required external tools for the project. The Go toolchain is required by the restorer of the project.
+func (gp *goProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the Go project
+func (gp *goProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the Go modules of the project using go mod download
+func (gp *goProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return gp.restorers.Restore(ctx, serviceConfig)
+}
+
+// Builds the main package of the project as a static binary, for the OS and architecture of the host of the service
+func (gp *goProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			binary, err := goBinaryName(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			buildOutput, err := os.MkdirTemp("", "azd-go")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating build directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			goos, goarch := goTarget(serviceConfig)
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Building Go binary for %s/%s", goos, goarch)))
+			if err := gp.build(ctx, serviceConfig, filepath.Join(buildOutput, binary), goos, goarch); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: buildOutput,
+				Details: &goBuildResult{
+					Binary: binary,
+					GOOS:   goos,
+					GOARCH: goarch,
+				},
+			})
+		},
+	)
+}
+
+// build builds the main package of the project to output. cgo is disabled for the binary to be static, ex) to run on
+// distroless images.
+func (gp *goProject) build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	output string,
+	goos string,
+	goarch string,
+) error {
+	mainPackage := serviceConfig.Go.Package
+	if mainPackage == "" {
+		mainPackage = "."
+	} else if !strings.HasPrefix(mainPackage, ".") {
+		// packages relative to the project are prefixed with ./, ex) cmd/api
+		mainPackage = "./" + filepath.ToSlash(mainPackage)
+	}
+
+	runArgs := exec.
+		NewRunArgs("go", "build", "-trimpath", "-ldflags=-s -w", "-o", output, mainPackage).
+		WithCwd(serviceConfig.Path()).
+		WithEnv([]string{"CGO_ENABLED=0", "GOOS=" + goos, "GOARCH=" + goarch})
+	if _, err := gp.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("building go project '%s': %w", serviceConfig.Path(), err)
+	}
+
+	return nil
+}
+
+// Packages the binary of the project. Services hosted on Azure Functions are packaged as custom handlers, with the
+// host.json and the function.json files of the project.
+func (gp *goProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			packageDest, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			excluded := []string{}
+			if serviceConfig.Host == AzureFunctionTarget {
+				task.SetProgress(NewServiceProgress("Copying custom handler configuration"))
+				excluded, err = buildForZip(serviceConfig, serviceConfig.Path(), packageDest, buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{excludeGoSource},
+				})
+				if err != nil {
+					task.SetError(fmt.Errorf("packaging for %s: %w", serviceConfig.Name, err))
+					return
+				}
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := copy.Copy(buildOutput.BuildOutputPath, packageDest); err != nil {
+				task.SetError(fmt.Errorf("packaging for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if err := validatePackageOutput(packageDest); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: packageDest,
+				Excluded:    excluded,
+			})
+		},
+	)
+}
+
+// DefaultDockerfile builds the binary of the project for the platform of the container, and writes a Dockerfile copying
+// the binary onto the base image of the service
+func (gp *goProject) DefaultDockerfile(ctx context.Context, serviceConfig *ServiceConfig) (string, string, error) {
+	binary, err := goBinaryName(serviceConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	buildContext, err := os.MkdirTemp("", "azd-go")
+	if err != nil {
+		return "", "", fmt.Errorf("creating build context for %s: %w", serviceConfig.Name, err)
+	}
+
+	goos, goarch := goTarget(serviceConfig)
+	if err := gp.build(ctx, serviceConfig, filepath.Join(buildContext, binary), goos, goarch); err != nil {
+		return "", "", err
+	}
+
+	baseImage := serviceConfig.Go.BaseImage
+	if baseImage == "" {
+		baseImage = defaultGoBaseImage
+	}
+
+	dockerfile := filepath.Join(buildContext, "Dockerfile")
+	contents := fmt.Sprintf("FROM %s\nCOPY %s /app/%s\nENTRYPOINT [\"/app/%s\"]\n", baseImage, binary, binary, binary)
+	if err := os.WriteFile(dockerfile, []byte(contents), osutil.PermissionFile); err != nil {
+		return "", "", fmt.Errorf("writing Dockerfile for %s: %w", serviceConfig.Name, err)
+	}
+
+	return dockerfile, buildContext, nil
+}
+
+// goTarget returns the GOOS and GOARCH of the binary of the service. Containers are built for the platform of the docker
+// image, and the other hosts run Linux on x64.
+func goTarget(serviceConfig *ServiceConfig) (string, string) {
+	if serviceConfig.Host == ContainerAppTarget || serviceConfig.Host == AksTarget {
+		platform := getDockerOptionsWithDefaults(serviceConfig.Docker).Platform
+		// ex) linux/arm64/v8
+		if parts := strings.Split(platform, "/"); len(parts) >= 2 {
+			return parts[0], parts[1]
+		}
+	}
+
+	return "linux", "amd64"
+}
+
+// goBinaryName returns the name of the binary of the service. The binary of custom handlers is named by the
+// defaultExecutablePath of the host.json of the project.
+func goBinaryName(serviceConfig *ServiceConfig) (string, error) {
+	if serviceConfig.Go.Binary != "" {
+		return serviceConfig.Go.Binary, nil
+	}
+
+	if serviceConfig.Host != AzureFunctionTarget {
+		return serviceConfig.Name, nil
+	}
+
+	var hostJson struct {
+		CustomHandler struct {
+			Description struct {
+				DefaultExecutablePath string `json:"defaultExecutablePath"`
+			} `json:"description"`
+		} `json:"customHandler"`
+	}
+
+	contents, err := os.ReadFile(filepath.Join(serviceConfig.Path(), "host.json"))
+	if err == nil {
+		err = json.Unmarshal(contents, &hostJson)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading host.json of Go function app '%s': %w", serviceConfig.Name, err)
+	}
+
+	binary := hostJson.CustomHandler.Description.DefaultExecutablePath
+	if binary == "" {
+		return "", fmt.Errorf(
+			"function apps written in Go run as custom handlers, configure "+
+				"customHandler.description.defaultExecutablePath in the host.json of service '%s'",
+			serviceConfig.Name,
+		)
+	}
+
+	return binary, nil
+}
+
+// excludeGoSource excludes the source of the Go project from the package of custom handlers
+func excludeGoSource(path string, file os.FileInfo) bool {
+	if file.IsDir() {
+		return file.Name() == "vendor"
+	}
+
+	return filepath.Ext(path) == ".go" || file.Name() == "go.mod" || file.Name() == "go.sum"
+}
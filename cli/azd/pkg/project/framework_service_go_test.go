@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+// mockGoBuild responds to go build by writing the binary to its output path
+func mockGoBuild(mockContext *mocks.MockContext, runArgs *exec.RunArgs) {
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "go build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			*runArgs = args
+			output := args.Args[slices.Index(args.Args, "-o")+1]
+			return exec.NewRunResult(0, "", ""), os.WriteFile(output, []byte("binary"), 0700)
+		})
+}
+
+func createTestGoService(dir string, host ServiceTargetKind) *ServiceConfig {
+	return &ServiceConfig{
+		Project:         &ProjectConfig{Name: "test-app", Path: dir},
+		Name:            "api",
+		RelativePath:    "src/api",
+		Host:            host,
+		Language:        ServiceLanguageGo,
+		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
+	}
+}
+
+func Test_GoProject_AppService(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src/api/go.mod": "module api", "src/api/cmd/api/main.go": ""})
+
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGoBuild(mockContext, &runArgs)
+
+	serviceConfig := createTestGoService(dir, AppServiceTarget)
+	serviceConfig.Go.Package = "cmd/api"
+
+	goProject := NewGoProject(mockContext.CommandRunner, environment.Ephemeral(), newTestDependencyRestorers(mockContext))
+	buildTask := goProject.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+	logProgress(buildTask)
+
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, &goBuildResult{Binary: "api", GOOS: "linux", GOARCH: "amd64"}, buildResult.Details)
+	require.Equal(t, "./cmd/api", runArgs.Args[len(runArgs.Args)-1])
+	require.Equal(t, filepath.Join(dir, "src", "api"), runArgs.Cwd)
+	require.Equal(t, []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64"}, runArgs.Env)
+
+	packageTask := goProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(packageResult.PackagePath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "api", entries[0].Name())
+}
+
+func Test_GoProject_FunctionCustomHandler(t *testing.T) {
+	t.Run("Package", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"src/api/go.mod":                    "module api",
+			"src/api/main.go":                   "",
+			"src/api/host.json":                 `{"customHandler": {"description": {"defaultExecutablePath": "handler"}}}`,
+			"src/api/HttpTrigger/function.json": "{}",
+		})
+
+		var runArgs exec.RunArgs
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGoBuild(mockContext, &runArgs)
+
+		serviceConfig := createTestGoService(dir, AzureFunctionTarget)
+		goProject := NewGoProject(mockContext.CommandRunner, environment.Ephemeral(), newTestDependencyRestorers(mockContext))
+
+		buildTask := goProject.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+		logProgress(buildTask)
+		buildResult, err := buildTask.Await()
+		require.NoError(t, err)
+
+		packageTask := goProject.Package(*mockContext.Context, serviceConfig, buildResult)
+		logProgress(packageTask)
+		packageResult, err := packageTask.Await()
+		require.NoError(t, err)
+
+		require.FileExists(t, filepath.Join(packageResult.PackagePath, "handler"))
+		require.FileExists(t, filepath.Join(packageResult.PackagePath, "host.json"))
+		require.FileExists(t, filepath.Join(packageResult.PackagePath, "HttpTrigger", "function.json"))
+		require.NoFileExists(t, filepath.Join(packageResult.PackagePath, "main.go"))
+		require.NoFileExists(t, filepath.Join(packageResult.PackagePath, "go.mod"))
+	})
+
+	t.Run("NoCustomHandler", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{"src/api/host.json": `{"version": "2.0"}`})
+
+		mockContext := mocks.NewMockContext(context.Background())
+		serviceConfig := createTestGoService(dir, AzureFunctionTarget)
+		goProject := NewGoProject(mockContext.CommandRunner, environment.Ephemeral(), newTestDependencyRestorers(mockContext))
+
+		buildTask := goProject.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+		logProgress(buildTask)
+		_, err := buildTask.Await()
+		require.ErrorContains(t, err, "configure customHandler.description.defaultExecutablePath")
+	})
+}
+
+func Test_GoProject_DefaultDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src/api/go.mod": "module api", "src/api/main.go": ""})
+
+	var goBuildArgs exec.RunArgs
+	var dockerfile string
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGoBuild(mockContext, &goBuildArgs)
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			contents, err := os.ReadFile(args.Args[slices.Index(args.Args, "-f")+1])
+			dockerfile = string(contents)
+			return exec.NewRunResult(0, "IMAGE_ID", ""), err
+		})
+
+	serviceConfig := createTestGoService(dir, ContainerAppTarget)
+	serviceConfig.Docker.Platform = "linux/arm64"
+
+	env := environment.Ephemeral()
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	dockerProject := NewDockerProject(env, dockerCli, NewContainerHelper(env, clock.NewMock(), nil, dockerCli, nil))
+	dockerProject.SetSource(
+		NewGoProject(mockContext.CommandRunner, env, newTestDependencyRestorers(mockContext)))
+
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+	logProgress(buildTask)
+
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, "IMAGE_ID", buildResult.BuildOutputPath)
+	require.Equal(t, []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm64"}, goBuildArgs.Env)
+	require.Equal(t,
+		"FROM gcr.io/distroless/static-debian12:nonroot\nCOPY api /app/api\nENTRYPOINT [\"/app/api\"]\n", dockerfile)
+}
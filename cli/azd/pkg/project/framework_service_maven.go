@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,23 +14,40 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
 	"github.com/otiai10/copy"
+	"golang.org/x/exp/slices"
 )
 
 // The default, conventional App Service Java package name
 const AppServiceJavaPackageName = "app"
 
+// JavaOptions are the options of Java services, built with Maven or Gradle
+type JavaOptions struct {
+	// The module of a multi-module project the service is built from, ex) api. For Maven, the directory of the module
+	// relative to the project of the service, for Gradle, the path of the subproject, ex) :api or :apps:api
+	Module string `yaml:"module,omitempty"`
+	// The file name pattern of the archive to deploy, ex) *-exec.jar, when the build produces several archives
+	Artifact string `yaml:"artifact,omitempty"`
+}
+
 type mavenProject struct {
-	env      *environment.Environment
-	mavenCli maven.MavenCli
-	javacCli javac.JavacCli
+	env       *environment.Environment
+	mavenCli  maven.MavenCli
+	javacCli  javac.JavacCli
+	restorers *DependencyRestorers
 }
 
 // NewMavenProject creates a new instance of a maven project
-func NewMavenProject(env *environment.Environment, mavenCli maven.MavenCli, javaCli javac.JavacCli) FrameworkService {
+func NewMavenProject(
+	env *environment.Environment,
+	mavenCli maven.MavenCli,
+	javaCli javac.JavacCli,
+	restorers *DependencyRestorers,
+) FrameworkService {
 	return &mavenProject{
-		env:      env,
-		mavenCli: mavenCli,
-		javacCli: javaCli,
+		env:       env,
+		mavenCli:  mavenCli,
+		javacCli:  javaCli,
+		restorers: restorers,
 	}
 }
 
@@ -43,10 +61,9 @@ func (m *mavenProject) Requirements() FrameworkRequirements {
 	}
 }
 
-// Gets the required external tools for the project
+// Gets the required external tools for the project. Maven or Gradle is required by the restorer of the project.
 func (m *mavenProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
 	return []tools.ExternalTool{
-		m.mavenCli,
 		m.javacCli,
 	}
 }
@@ -57,25 +74,15 @@ func (m *mavenProject) Initialize(ctx context.Context, serviceConfig *ServiceCon
 	return nil
 }
 
-// Restores dependencies using the Maven CLI
+// Restores dependencies with the package manager of the project, ex) maven or gradle
 func (m *mavenProject) Restore(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
-	return async.RunTaskWithProgress(
-		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Resolving maven dependencies"))
-			if err := m.mavenCli.ResolveDependencies(ctx, serviceConfig.Path()); err != nil {
-				task.SetError(fmt.Errorf("resolving maven dependencies: %w", err))
-				return
-			}
-
-			task.SetResult(&ServiceRestoreResult{})
-		},
-	)
+	return m.restorers.Restore(ctx, serviceConfig)
 }
 
-// Builds the maven project
+// Builds the project with maven or gradle, only building the module of the service in multi-module projects
 func (m *mavenProject) Build(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -83,12 +90,26 @@ func (m *mavenProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Compiling maven project"))
-			if err := m.mavenCli.Compile(ctx, serviceConfig.Path()); err != nil {
+			gradle, err := m.gradle(serviceConfig)
+			if err != nil {
 				task.SetError(err)
 				return
 			}
 
+			if gradle != nil {
+				task.SetProgress(NewServiceProgress("Compiling gradle project"))
+				if err := gradle.run(ctx, serviceConfig, gradleTask(serviceConfig, "classes")); err != nil {
+					task.SetError(fmt.Errorf("gradle build on project '%s' failed: %w", serviceConfig.Path(), err))
+					return
+				}
+			} else {
+				task.SetProgress(NewServiceProgress("Compiling maven project"))
+				if err := m.mavenCli.Compile(ctx, serviceConfig.Path(), serviceConfig.Java.Module); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
 			task.SetResult(&ServiceBuildResult{
 				Restore:         restoreOutput,
 				BuildOutputPath: serviceConfig.Path(),
@@ -110,8 +131,8 @@ func (m *mavenProject) Package(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Packaging maven project"))
-			if err := m.mavenCli.Package(ctx, serviceConfig.Path()); err != nil {
+			gradle, err := m.gradle(serviceConfig)
+			if err != nil {
 				task.SetError(err)
 				return
 			}
@@ -121,16 +142,64 @@ func (m *mavenProject) Package(
 				packageSrcPath = serviceConfig.Path()
 			}
 
+			// The build output directory of the module of the service
+			outputDir := filepath.Join(packageSrcPath, filepath.FromSlash(serviceConfig.Java.Module), "target")
+			if gradle != nil {
+				modulePath := strings.ReplaceAll(strings.Trim(serviceConfig.Java.Module, ":"), ":", "/")
+				outputDir = filepath.Join(packageSrcPath, filepath.FromSlash(modulePath), "build")
+
+				task.SetProgress(NewServiceProgress("Packaging gradle project"))
+				tasks := []string{gradleTask(serviceConfig, "build"), "-x", "test"}
+				if serviceConfig.Host == AzureFunctionTarget {
+					tasks = append(tasks, gradleTask(serviceConfig, "azureFunctionsPackage"))
+				}
+				if err := gradle.run(ctx, serviceConfig, tasks...); err != nil {
+					task.SetError(fmt.Errorf("gradle build on project '%s' failed: %w", serviceConfig.Path(), err))
+					return
+				}
+			} else {
+				task.SetProgress(NewServiceProgress("Packaging maven project"))
+				if err := m.mavenCli.Package(ctx, serviceConfig.Path(), serviceConfig.Java.Module); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			// Java function apps are deployed from the staging directory of the azure-functions plugin
+			if serviceConfig.Host == AzureFunctionTarget && serviceConfig.OutputPath == "" {
+				stagingDir, err := discoverFunctionsStagingDir(filepath.Join(outputDir, "azure-functions"))
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				if stagingDir != "" {
+					task.SetProgress(NewServiceProgress("Copying deployment package"))
+					if err := copy.Copy(stagingDir, packageDest); err != nil {
+						task.SetError(fmt.Errorf("copying to staging directory failed: %w", err))
+						return
+					}
+
+					task.SetResult(&ServicePackageResult{
+						Build:       buildOutput,
+						PackagePath: packageDest,
+					})
+					return
+				}
+			}
+
 			if serviceConfig.OutputPath != "" {
 				packageSrcPath = filepath.Join(packageSrcPath, serviceConfig.OutputPath)
+			} else if gradle != nil {
+				packageSrcPath = filepath.Join(outputDir, "libs")
 			} else {
-				packageSrcPath = filepath.Join(packageSrcPath, "target")
+				packageSrcPath = outputDir
 			}
 
 			packageSrcFileInfo, err := os.Stat(packageSrcPath)
 			if err != nil {
 				if serviceConfig.OutputPath == "" {
-					task.SetError(fmt.Errorf("reading default maven target path %s: %w", packageSrcPath, err))
+					task.SetError(fmt.Errorf("reading default build output path %s: %w", packageSrcPath, err))
 				} else {
 					task.SetError(fmt.Errorf("reading dist path %s: %w", packageSrcPath, err))
 				}
@@ -139,7 +208,7 @@ func (m *mavenProject) Package(
 
 			archive := ""
 			if packageSrcFileInfo.IsDir() {
-				archive, err = m.discoverArchive(packageSrcPath)
+				archive, err = m.discoverArchive(packageSrcPath, serviceConfig.Java.Artifact)
 				if err != nil {
 					task.SetError(err)
 					return
@@ -177,7 +246,13 @@ func isSupportedJavaArchive(archiveFile string) bool {
 	return ext == ".jar" || ext == ".war" || ext == ".ear"
 }
 
-func (m *mavenProject) discoverArchive(dir string) (string, error) {
+// nonDeployableArchiveSuffixes are the suffixes of archives built next to the archive of the application, ex) by the
+// maven-source-plugin or the plain jar of Spring Boot Gradle builds
+var nonDeployableArchiveSuffixes = []string{"-sources.jar", "-javadoc.jar", "-tests.jar", "-test-sources.jar", "-plain.jar"}
+
+// discoverArchive finds the archive to deploy in dir. When pattern is set, the archive is the one archive matching the
+// pattern, otherwise archives which aren't deployable, ex) source jars, are ignored.
+func (m *mavenProject) discoverArchive(dir string, pattern string) (string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return "", fmt.Errorf("discovering java archive files in %s: %w", dir, err)
@@ -190,13 +265,32 @@ func (m *mavenProject) discoverArchive(dir string) (string, error) {
 		}
 
 		name := entry.Name()
-		if isSupportedJavaArchive(name) {
-			archiveFiles = append(archiveFiles, name)
+		if !isSupportedJavaArchive(name) {
+			continue
+		}
+
+		if pattern != "" {
+			matched, err := filepath.Match(pattern, name)
+			if err != nil {
+				return "", fmt.Errorf("invalid java artifact pattern '%s': %w", pattern, err)
+			}
+			if !matched {
+				continue
+			}
+		} else if slices.ContainsFunc(nonDeployableArchiveSuffixes, func(suffix string) bool {
+			return strings.HasSuffix(strings.ToLower(name), suffix)
+		}) {
+			continue
 		}
+
+		archiveFiles = append(archiveFiles, name)
 	}
 
 	switch len(archiveFiles) {
 	case 0:
+		if pattern != "" {
+			return "", fmt.Errorf("no java archive files (.jar, .ear, .war) matching '%s' found in %s", pattern, dir)
+		}
 		return "", fmt.Errorf("no java archive files (.jar, .ear, .war) found in %s", dir)
 	case 1:
 		return filepath.Join(dir, archiveFiles[0]), nil
@@ -204,9 +298,66 @@ func (m *mavenProject) discoverArchive(dir string) (string, error) {
 		names := strings.Join(archiveFiles, ", ")
 		return "", fmt.Errorf(
 			//nolint:lll
-			"multiple java archive files (.jar, .ear, .war) found in %s: %s. To pick a specific archive to be used, specify the relative path to the archive file using the 'dist' property in azure.yaml",
+			"multiple java archive files (.jar, .ear, .war) found in %s: %s. To pick a specific archive to be used, specify the file name pattern of the archive using the 'java.artifact' property, or the relative path to the archive file using the 'dist' property in azure.yaml",
 			dir,
 			names,
 		)
 	}
 }
+
+// discoverFunctionsStagingDir finds the staging directory of the function app in the output directory of the
+// azure-functions plugin, ex) target/azure-functions/<function app>. Returns an empty string when the plugin isn't used.
+func discoverFunctionsStagingDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("discovering function app staging directory in %s: %w", dir, err)
+	}
+
+	stagingDirs := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			stagingDirs = append(stagingDirs, entry.Name())
+		}
+	}
+
+	switch len(stagingDirs) {
+	case 0:
+		return "", nil
+	case 1:
+		return filepath.Join(dir, stagingDirs[0]), nil
+	default:
+		return "", fmt.Errorf(
+			"multiple function app staging directories found in %s: %s. Specify the relative path to the staging "+
+				"directory using the 'dist' property in azure.yaml", dir, strings.Join(stagingDirs, ", "))
+	}
+}
+
+// gradle returns the restorer of projects built with gradle, or nil when the project is built with maven
+func (m *mavenProject) gradle(serviceConfig *ServiceConfig) (*commandRestorer, error) {
+	restorer, kind, err := m.restorers.Select(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != PackageManagerGradle {
+		return nil, nil
+	}
+
+	gradle, ok := restorer.(*commandRestorer)
+	if !ok {
+		return nil, fmt.Errorf("package manager '%s' of service '%s' can't build the project", kind, serviceConfig.Name)
+	}
+
+	return gradle, nil
+}
+
+// gradleTask returns the task of the module of the service, ex) :api:build
+func gradleTask(serviceConfig *ServiceConfig, task string) string {
+	if serviceConfig.Java.Module == "" {
+		return task
+	}
+
+	return ":" + strings.Trim(serviceConfig.Java.Module, ":") + ":" + task
+}
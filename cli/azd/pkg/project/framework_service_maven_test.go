@@ -45,7 +45,7 @@ func Test_MavenProject(t *testing.T) {
 		mavenCli := maven.NewMavenCli(mockContext.CommandRunner)
 		javaCli := javac.NewCli(mockContext.CommandRunner)
 
-		mavenProject := NewMavenProject(env, mavenCli, javaCli)
+		mavenProject := NewMavenProject(env, mavenCli, javaCli, newTestDependencyRestorers(mockContext))
 		err = mavenProject.Initialize(*mockContext.Context, serviceConfig)
 		require.NoError(t, err)
 
@@ -81,7 +81,7 @@ func Test_MavenProject(t *testing.T) {
 		mavenCli := maven.NewMavenCli(mockContext.CommandRunner)
 		javaCli := javac.NewCli(mockContext.CommandRunner)
 
-		mavenProject := NewMavenProject(env, mavenCli, javaCli)
+		mavenProject := NewMavenProject(env, mavenCli, javaCli, newTestDependencyRestorers(mockContext))
 		err = mavenProject.Initialize(*mockContext.Context, serviceConfig)
 		require.NoError(t, err)
 
@@ -123,7 +123,7 @@ func Test_MavenProject(t *testing.T) {
 		err = os.WriteFile(filepath.Join(buildOutputDir, "test.jar"), []byte("test"), osutil.PermissionFile)
 		require.NoError(t, err)
 
-		mavenProject := NewMavenProject(env, mavenCli, javaCli)
+		mavenProject := NewMavenProject(env, mavenCli, javaCli, newTestDependencyRestorers(mockContext))
 		err = mavenProject.Initialize(*mockContext.Context, serviceConfig)
 		require.NoError(t, err)
 
@@ -284,7 +284,7 @@ func Test_MavenProject_Package(t *testing.T) {
 			env := environment.Ephemeral()
 			mavenCli := maven.NewMavenCli(mockContext.CommandRunner)
 			javaCli := javac.NewCli(mockContext.CommandRunner)
-			mavenProject := NewMavenProject(env, mavenCli, javaCli)
+			mavenProject := NewMavenProject(env, mavenCli, javaCli, newTestDependencyRestorers(mockContext))
 			err = mavenProject.Initialize(*mockContext.Context, tt.args.svc)
 			require.NoError(t, err)
 
@@ -312,6 +312,134 @@ func Test_MavenProject_Package(t *testing.T) {
 	}
 }
 
+func Test_MavenProject_MultiModule(t *testing.T) {
+	tests := []struct {
+		name         string
+		host         ServiceTargetKind
+		java         JavaOptions
+		files        map[string]string
+		expectedCmd  string
+		expectedArgs []string
+		expectedFile string
+		expectedErr  string
+	}{
+		{
+			name: "MavenModule",
+			host: AppServiceTarget,
+			java: JavaOptions{Module: "api"},
+			files: map[string]string{
+				"pom.xml":                        "",
+				"api/target/api-1.0.jar":         "",
+				"api/target/api-1.0-sources.jar": "",
+				"web/target/web-1.0.jar":         "",
+			},
+			expectedCmd:  getMvnwCmd(),
+			expectedArgs: []string{"package", "-DskipTests", "--projects", "api", "--also-make"},
+			expectedFile: "app.jar",
+		},
+		{
+			name: "GradleModule",
+			host: SpringAppTarget,
+			java: JavaOptions{Module: ":apps:api"},
+			files: map[string]string{
+				"settings.gradle":                         "",
+				"apps/api/build/libs/api-1.0.jar":         "",
+				"apps/api/build/libs/api-1.0-plain.jar":   "",
+				"apps/api/build/libs/api-1.0-javadoc.jar": "",
+			},
+			expectedCmd:  "gradle",
+			expectedArgs: []string{":apps:api:build", "-x", "test"},
+			expectedFile: "app.jar",
+		},
+		{
+			name: "GradleFunctions",
+			host: AzureFunctionTarget,
+			java: JavaOptions{Module: "functions"},
+			files: map[string]string{
+				"build.gradle.kts": "",
+				"functions/build/azure-functions/contoso-func/host.json": "{}",
+				"functions/build/libs/functions-1.0.jar":                 "",
+			},
+			expectedCmd:  "gradle",
+			expectedArgs: []string{":functions:build", "-x", "test", ":functions:azureFunctionsPackage"},
+			expectedFile: "host.json",
+		},
+		{
+			name: "ArtifactPattern",
+			host: AppServiceTarget,
+			java: JavaOptions{Artifact: "*-exec.war"},
+			files: map[string]string{
+				"pom.xml":                 "",
+				"target/app-1.0.war":      "",
+				"target/app-1.0-exec.war": "",
+			},
+			expectedCmd:  getMvnwCmd(),
+			expectedArgs: []string{"package", "-DskipTests"},
+			expectedFile: "app.war",
+		},
+		{
+			name: "ArtifactPatternNoMatch",
+			host: AppServiceTarget,
+			java: JavaOptions{Artifact: "*-exec.jar"},
+			files: map[string]string{
+				"pom.xml":            "",
+				"target/app-1.0.jar": "",
+			},
+			expectedErr: "no java archive files (.jar, .ear, .war) matching '*-exec.jar'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			temp := t.TempDir()
+			writeTestFiles(t, temp, tt.files)
+			err := os.WriteFile(filepath.Join(temp, getMvnwCmd()), nil, osutil.PermissionExecutableFile)
+			require.NoError(t, err)
+
+			var runArgs exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return true
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					runArgs = args
+					return exec.NewRunResult(0, "", ""), nil
+				})
+
+			serviceConfig := &ServiceConfig{
+				Project:         &ProjectConfig{Path: temp},
+				Name:            "api",
+				Host:            tt.host,
+				Language:        ServiceLanguageJava,
+				Java:            tt.java,
+				EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
+			}
+
+			mavenCli := maven.NewMavenCli(mockContext.CommandRunner)
+			javaCli := javac.NewCli(mockContext.CommandRunner)
+			mavenProject := NewMavenProject(
+				environment.Ephemeral(), mavenCli, javaCli, newTestDependencyRestorers(mockContext))
+			require.NoError(t, mavenProject.Initialize(*mockContext.Context, serviceConfig))
+
+			packageTask := mavenProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+			logProgress(packageTask)
+
+			result, err := packageTask.Await()
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCmd, filepath.Base(runArgs.Cmd))
+			require.Equal(t, tt.expectedArgs, runArgs.Args)
+			require.Equal(t, temp, runArgs.Cwd)
+			require.FileExists(t, filepath.Join(result.PackagePath, tt.expectedFile))
+		})
+	}
+}
+
 func getMvnwCmd() string {
 	if runtime.GOOS == "windows" {
 		return "mvnw.cmd"
@@ -12,19 +12,19 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
 )
 
 type npmProject struct {
-	env *environment.Environment
-	cli npm.NpmCli
+	env       *environment.Environment
+	restorers *DependencyRestorers
 }
 
-// NewNpmProject creates a new instance of a NPM project
-func NewNpmProject(cli npm.NpmCli, env *environment.Environment) FrameworkService {
+// NewNpmProject creates a new instance of a JavaScript project, using the package manager of the project, ex) npm,
+// pnpm, yarn or bun
+func NewNpmProject(env *environment.Environment, restorers *DependencyRestorers) FrameworkService {
 	return &npmProject{
-		env: env,
-		cli: cli,
+		env:       env,
+		restorers: restorers,
 	}
 }
 
@@ -38,9 +38,9 @@ func (np *npmProject) Requirements() FrameworkRequirements {
 	}
 }
 
-// Gets the required external tools for the project
+// Gets the required external tools for the project. The package manager of the project is required by its restorer.
 func (np *npmProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
-	return []tools.ExternalTool{np.cli}
+	return []tools.ExternalTool{}
 }
 
 // Initializes the NPM project
@@ -48,25 +48,15 @@ func (np *npmProject) Initialize(ctx context.Context, serviceConfig *ServiceConf
 	return nil
 }
 
-// Restores dependencies for the NPM project using npm install command
+// Restores dependencies with the package manager of the project, ex) npm, pnpm or yarn
 func (np *npmProject) Restore(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
-	return async.RunTaskWithProgress(
-		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Installing NPM dependencies"))
-			if err := np.cli.Install(ctx, serviceConfig.Path()); err != nil {
-				task.SetError(err)
-				return
-			}
-
-			task.SetResult(&ServiceRestoreResult{})
-		},
-	)
+	return np.restorers.Restore(ctx, serviceConfig)
 }
 
-// Builds the project executing the npm `build` script defined within the project package.json
+// Builds the project executing the `build` script defined within the project package.json
 func (np *npmProject) Build(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -75,9 +65,9 @@ func (np *npmProject) Build(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
 			// Exec custom `build` script if available
-			// If `build`` script is not defined in the package.json the NPM script will NOT fail
-			task.SetProgress(NewServiceProgress("Running NPM build script"))
-			if err := np.cli.RunScript(ctx, serviceConfig.Path(), "build"); err != nil {
+			// If `build`` script is not defined in the package.json the script will NOT fail
+			task.SetProgress(NewServiceProgress("Running build script"))
+			if err := np.runScript(ctx, serviceConfig, "build"); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -109,12 +99,12 @@ func (np *npmProject) Package(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Running NPM package script"))
+			task.SetProgress(NewServiceProgress("Running package script"))
 
 			// Long term this script we call should better align with our inner-loop scenarios
 			// Keeping this defaulted to `build` will create confusion for users when we start to support
 			// both local dev / debug builds and production bundled builds
-			if err := np.cli.RunScript(ctx, serviceConfig.Path(), "build"); err != nil {
+			if err := np.runScript(ctx, serviceConfig, "build"); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -139,14 +129,8 @@ func (np *npmProject) Package(
 
 			task.SetProgress(NewServiceProgress("Copying deployment package"))
 
-			if err := buildForZip(
-				packageSource,
-				packageDest,
-				buildForZipOptions{
-					excludeConditions: []excludeDirEntryCondition{
-						excludeNodeModules,
-					},
-				}); err != nil {
+			excluded, err := buildForZip(serviceConfig, packageSource, packageDest, buildForZipOptions{})
+			if err != nil {
 				task.SetError(fmt.Errorf("packaging for %s: %w", serviceConfig.Name, err))
 				return
 			}
@@ -159,13 +143,25 @@ func (np *npmProject) Package(
 			task.SetResult(&ServicePackageResult{
 				Build:       buildOutput,
 				PackagePath: packageDest,
+				Excluded:    excluded,
 			})
 		},
 	)
 }
 
-const cNodeModulesName = "node_modules"
+// runScript runs the script of the package.json of the service with the package manager of the project
+func (np *npmProject) runScript(ctx context.Context, serviceConfig *ServiceConfig, script string) error {
+	restorer, _, err := np.restorers.Select(serviceConfig)
+	if err != nil {
+		return err
+	}
 
-func excludeNodeModules(path string, file os.FileInfo) bool {
-	return file.IsDir() && file.Name() == cNodeModulesName
+	scriptRunner, ok := restorer.(*nodeRestorer)
+	if !ok {
+		return fmt.Errorf("package manager of service '%s' can't run the scripts of package.json", serviceConfig.Name)
+	}
+
+	return scriptRunner.RunScript(ctx, serviceConfig, script)
 }
+
+const cNodeModulesName = "node_modules"
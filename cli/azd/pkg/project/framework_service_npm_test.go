@@ -10,7 +10,6 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/stretchr/testify/require"
@@ -30,10 +29,9 @@ func Test_NpmProject_Restore(t *testing.T) {
 		})
 
 	env := environment.Ephemeral()
-	npmCli := npm.NewNpmCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageTypeScript)
 
-	npmProject := NewNpmProject(npmCli, env)
+	npmProject := NewNpmProject(env, newTestDependencyRestorers(mockContext))
 	restoreTask := npmProject.Restore(*mockContext.Context, serviceConfig)
 	logProgress(restoreTask)
 
@@ -62,10 +60,9 @@ func Test_NpmProject_Build(t *testing.T) {
 		})
 
 	env := environment.Ephemeral()
-	npmCli := npm.NewNpmCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageTypeScript)
 
-	npmProject := NewNpmProject(npmCli, env)
+	npmProject := NewNpmProject(env, newTestDependencyRestorers(mockContext))
 	buildTask := npmProject.Build(*mockContext.Context, serviceConfig, nil)
 	logProgress(buildTask)
 
@@ -96,14 +93,13 @@ func Test_NpmProject_Package(t *testing.T) {
 		})
 
 	env := environment.Ephemeral()
-	npmCli := npm.NewNpmCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageTypeScript)
 	err := os.MkdirAll(serviceConfig.Path(), osutil.PermissionDirectory)
 	require.NoError(t, err)
 	err = os.WriteFile(filepath.Join(serviceConfig.Path(), "package.json"), nil, osutil.PermissionFile)
 	require.NoError(t, err)
 
-	npmProject := NewNpmProject(npmCli, env)
+	npmProject := NewNpmProject(env, newTestDependencyRestorers(mockContext))
 	packageTask := npmProject.Package(
 		*mockContext.Context,
 		serviceConfig,
@@ -5,9 +5,9 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
@@ -18,15 +18,18 @@ import (
 )
 
 type pythonProject struct {
-	env *environment.Environment
-	cli *python.PythonCli
+	env       *environment.Environment
+	cli       *python.PythonCli
+	restorers *DependencyRestorers
 }
 
 // NewPythonProject creates a new instance of the Python project
-func NewPythonProject(cli *python.PythonCli, env *environment.Environment) FrameworkService {
+func NewPythonProject(
+	cli *python.PythonCli, env *environment.Environment, restorers *DependencyRestorers) FrameworkService {
 	return &pythonProject{
-		env: env,
-		cli: cli,
+		env:       env,
+		cli:       cli,
+		restorers: restorers,
 	}
 }
 
@@ -50,53 +53,25 @@ func (pp *pythonProject) Initialize(ctx context.Context, serviceConfig *ServiceC
 	return nil
 }
 
-// Restores the project dependencies using PIP requirements.txt
+// Restores the project dependencies with the package manager of the project, ex) pip, poetry or uv
 func (pp *pythonProject) Restore(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
-	return async.RunTaskWithProgress(
-		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Checking for Python virtual environment"))
-			vEnvName := pp.getVenvName(serviceConfig)
-			vEnvPath := path.Join(serviceConfig.Path(), vEnvName)
-
-			_, err := os.Stat(vEnvPath)
-			if err != nil {
-				if os.IsNotExist(err) {
-					task.SetProgress(NewServiceProgress("Creating Python virtual environment"))
-					err = pp.cli.CreateVirtualEnv(ctx, serviceConfig.Path(), vEnvName)
-					if err != nil {
-						task.SetError(fmt.Errorf(
-							"python virtual environment for project '%s' could not be created: %w",
-							serviceConfig.Path(),
-							err,
-						))
-						return
-					}
-				} else {
-					task.SetError(
-						fmt.Errorf("python virtual environment for project '%s' is not accessible: %w", serviceConfig.Path(), err),
-					)
-					return
-				}
-			}
-
-			task.SetProgress(NewServiceProgress("Installing Python PIP dependencies"))
-			err = pp.cli.InstallRequirements(ctx, serviceConfig.Path(), vEnvName, "requirements.txt")
-			if err != nil {
-				task.SetError(
-					fmt.Errorf("requirements for project '%s' could not be installed: %w", serviceConfig.Path(), err),
-				)
-				return
-			}
+	return pp.restorers.Restore(ctx, serviceConfig)
+}
 
-			task.SetResult(&ServiceRestoreResult{})
-		},
-	)
+// pythonPackageResult are the details of the build and package of a Python service
+type pythonPackageResult struct {
+	PackageManager PackageManagerKind `json:"packageManager"`
+	// The wheels built for projects using poetry or uv
+	Wheels []string `json:"wheels,omitempty"`
+	// Whether the requirements.txt of the package was exported from the lock file of the project
+	ExportedRequirements bool `json:"exportedRequirements,omitempty"`
 }
 
-// Build for Python apps performs a no-op and returns the service path with an optional output path when specified.
+// Build for Python apps builds the wheels of projects using poetry or uv, and returns the service path with an optional
+// output path when specified. Projects using pip aren't built.
 func (pp *pythonProject) Build(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -104,6 +79,21 @@ func (pp *pythonProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			restorer, kind, err := pp.restorer(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if kind != PackageManagerPip {
+				task.SetProgress(NewServiceProgress(fmt.Sprintf("Building wheel with %s", kind)))
+			}
+			wheels, err := restorer.BuildWheels(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			buildSource := serviceConfig.Path()
 
 			if serviceConfig.OutputPath != "" {
@@ -113,6 +103,7 @@ func (pp *pythonProject) Build(
 			task.SetResult(&ServiceBuildResult{
 				Restore:         restoreOutput,
 				BuildOutputPath: buildSource,
+				Details:         &pythonPackageResult{PackageManager: kind, Wheels: wheels},
 			})
 		},
 	)
@@ -125,6 +116,12 @@ func (pp *pythonProject) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			restorer, kind, err := pp.restorer(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			packageDest, err := os.MkdirTemp("", "azd")
 			if err != nil {
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
@@ -142,19 +139,33 @@ func (pp *pythonProject) Package(
 			}
 
 			task.SetProgress(NewServiceProgress("Copying deployment package"))
-			if err := buildForZip(
-				packageSource,
-				packageDest,
-				buildForZipOptions{
-					excludeConditions: []excludeDirEntryCondition{
-						excludeVirtualEnv,
-						excludePyCache,
-					},
-				}); err != nil {
+			excluded, err := buildForZip(serviceConfig, packageSource, packageDest, buildForZipOptions{
+				excludeConditions: []excludeDirEntryCondition{
+					excludeVirtualEnv,
+				},
+			})
+			if err != nil {
 				task.SetError(fmt.Errorf("packaging for %s: %w", serviceConfig.Name, err))
 				return
 			}
 
+			details := &pythonPackageResult{PackageManager: kind}
+			if buildDetails, ok := buildOutput.Details.(*pythonPackageResult); ok {
+				details.Wheels = buildDetails.Wheels
+			}
+
+			// Hosts install the dependencies of the package from its requirements.txt, exported from the lock file of
+			// projects using poetry or uv
+			requirementsPath := filepath.Join(packageDest, "requirements.txt")
+			if _, err := os.Stat(requirementsPath); kind != PackageManagerPip && errors.Is(err, os.ErrNotExist) {
+				task.SetProgress(NewServiceProgress(fmt.Sprintf("Exporting requirements.txt with %s", kind)))
+				if err := restorer.ExportRequirements(ctx, serviceConfig, requirementsPath); err != nil {
+					task.SetError(err)
+					return
+				}
+				details.ExportedRequirements = true
+			}
+
 			if err := validatePackageOutput(packageDest); err != nil {
 				task.SetError(err)
 				return
@@ -163,11 +174,29 @@ func (pp *pythonProject) Package(
 			task.SetResult(&ServicePackageResult{
 				Build:       buildOutput,
 				PackagePath: packageDest,
+				Details:     details,
+				Excluded:    excluded,
 			})
 		},
 	)
 }
 
+// restorer returns the restorer of the package manager of the project, ex) pip, poetry or uv
+func (pp *pythonProject) restorer(serviceConfig *ServiceConfig) (*pythonRestorer, PackageManagerKind, error) {
+	restorer, kind, err := pp.restorers.Select(serviceConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pythonRestorer, ok := restorer.(*pythonRestorer)
+	if !ok {
+		return nil, "", fmt.Errorf(
+			"package manager '%s' of service '%s' is not a Python package manager", kind, serviceConfig.Name)
+	}
+
+	return pythonRestorer, kind, nil
+}
+
 const cVenvConfigFileName = "pyvenv.cfg"
 
 func isPythonVirtualEnv(path string) bool {
@@ -182,11 +211,8 @@ func excludeVirtualEnv(path string, file os.FileInfo) bool {
 	return file.IsDir() && isPythonVirtualEnv(path)
 }
 
-func excludePyCache(path string, file os.FileInfo) bool {
-	return file.IsDir() && strings.ToLower(file.Name()) == "__pycache__"
-}
-
-func (pp *pythonProject) getVenvName(serviceConfig *ServiceConfig) string {
+// pythonVenvName returns the name of the virtual environment of the project, <project directory>_env
+func pythonVenvName(serviceConfig *ServiceConfig) string {
 	trimmedPath := strings.TrimSpace(serviceConfig.Path())
 	if len(trimmedPath) > 0 && trimmedPath[len(trimmedPath)-1] == os.PathSeparator {
 		trimmedPath = trimmedPath[:len(trimmedPath)-1]
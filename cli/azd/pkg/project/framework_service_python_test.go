@@ -58,7 +58,7 @@ func Test_PythonProject_Restore(t *testing.T) {
 	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
 
-	pythonProject := NewPythonProject(pythonCli, env)
+	pythonProject := NewPythonProject(pythonCli, env, newTestDependencyRestorers(mockContext))
 	restoreTask := pythonProject.Restore(*mockContext.Context, serviceConfig)
 	logProgress(restoreTask)
 
@@ -92,7 +92,7 @@ func Test_PythonProject_Build(t *testing.T) {
 	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
 
-	pythonProject := NewPythonProject(pythonCli, env)
+	pythonProject := NewPythonProject(pythonCli, env, newTestDependencyRestorers(mockContext))
 	buildTask := pythonProject.Build(*mockContext.Context, serviceConfig, nil)
 	logProgress(buildTask)
 
@@ -114,7 +114,7 @@ func Test_PythonProject_Package(t *testing.T) {
 	err = os.WriteFile(filepath.Join(serviceConfig.Path(), "requirements.txt"), nil, osutil.PermissionFile)
 	require.NoError(t, err)
 
-	pythonProject := NewPythonProject(pythonCli, env)
+	pythonProject := NewPythonProject(pythonCli, env, newTestDependencyRestorers(mockContext))
 	packageTask := pythonProject.Package(
 		*mockContext.Context,
 		serviceConfig,
@@ -133,6 +133,109 @@ func Test_PythonProject_Package(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_PythonProject_Poetry(t *testing.T) {
+	tempDir := t.TempDir()
+	ostest.Chdir(t, tempDir)
+
+	commands := []string{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "poetry"
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			commands = append(commands, strings.Join(args.Args, " "))
+			switch args.Args[0] {
+			case "env":
+				return exec.NewRunResult(0, "/home/user/.cache/pypoetry/virtualenvs/api-py3.11\n", ""), nil
+			case "build":
+				err := os.MkdirAll(filepath.Join(args.Cwd, "dist"), osutil.PermissionDirectory)
+				require.NoError(t, err)
+				err = os.WriteFile(
+					filepath.Join(args.Cwd, "dist", "api-0.1.0-py3-none-any.whl"), nil, osutil.PermissionFile)
+				require.NoError(t, err)
+			case "export":
+				err := os.WriteFile(args.Args[len(args.Args)-1], []byte("flask==3.0.0\n"), osutil.PermissionFile)
+				require.NoError(t, err)
+			}
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.Ephemeral()
+	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
+	err := os.MkdirAll(serviceConfig.Path(), osutil.PermissionDirectory)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(serviceConfig.Path(), "poetry.lock"), nil, osutil.PermissionFile)
+	require.NoError(t, err)
+
+	pythonProject := NewPythonProject(pythonCli, env, newTestDependencyRestorers(mockContext))
+
+	restoreTask := pythonProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	restoreResult, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, &DependencyRestoreDetails{
+		PackageManager: PackageManagerPoetry,
+		Environment:    "/home/user/.cache/pypoetry/virtualenvs/api-py3.11",
+	}, restoreResult.Details)
+
+	buildTask := pythonProject.Build(*mockContext.Context, serviceConfig, restoreResult)
+	logProgress(buildTask)
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+
+	packageTask := pythonProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	require.Equal(t, &pythonPackageResult{
+		PackageManager:       PackageManagerPoetry,
+		Wheels:               []string{filepath.Join(serviceConfig.Path(), "dist", "api-0.1.0-py3-none-any.whl")},
+		ExportedRequirements: true,
+	}, packageResult.Details)
+	require.FileExists(t, filepath.Join(packageResult.PackagePath, "requirements.txt"))
+	require.Equal(t, []string{
+		"install --no-root",
+		"env info --path",
+		"build --format wheel",
+		fmt.Sprintf(
+			"export --format requirements.txt --without-hashes --output %s",
+			filepath.Join(packageResult.PackagePath, "requirements.txt")),
+	}, commands)
+}
+
+func Test_PythonRestorer_Uv(t *testing.T) {
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "uv"
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "uv.lock"), nil, osutil.PermissionFile)
+	require.NoError(t, err)
+	serviceConfig := createTestServiceConfig(dir, AppServiceTarget, ServiceLanguagePython)
+	serviceConfig.Project.Path = ""
+
+	restoreTask := newTestDependencyRestorers(mockContext).Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+
+	result, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, []string{"sync", "--frozen"}, runArgs.Args)
+	require.Equal(t, &DependencyRestoreDetails{
+		PackageManager: PackageManagerUv,
+		Environment:    filepath.Join(dir, ".venv"),
+	}, result.Details)
+}
+
 func pythonExe() string {
 	if runtime.GOOS == "windows" {
 		return "py" // https://peps.python.org/pep-0397
@@ -0,0 +1,261 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// GraphNodeKind is the kind of a node of the graph of a project
+type GraphNodeKind string
+
+const (
+	// A service of azure.yaml
+	GraphNodeService GraphNodeKind = "service"
+	// The Azure resource a service is deployed to
+	GraphNodeResource GraphNodeKind = "resource"
+	// A module of the infrastructure of the project, ex) a bicep module of main.bicep
+	GraphNodeModule GraphNodeKind = "module"
+)
+
+// GraphNode is a service, Azure resource or infrastructure module of a project
+type GraphNode struct {
+	Id    string        `json:"id"`
+	Kind  GraphNodeKind `json:"kind"`
+	Label string        `json:"label"`
+}
+
+// GraphEdge is a relation between two nodes of the graph of a project, ex) a service using another service
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+// Graph is the services of a project, the services they use, the Azure resources they're deployed to and the modules of
+// the infrastructure provisioning them, ie) what `azd up` provisions and deploys
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// The declarations of modules in bicep and terraform files, ex) module api './app/api.bicep' = {
+var (
+	bicepModuleRegex     = regexp.MustCompile(`(?m)^\s*module\s+([A-Za-z_][A-Za-z0-9_]*)\s+'([^']+)'`)
+	terraformModuleRegex = regexp.MustCompile(`(?m)^\s*module\s+"([^"]+)"`)
+)
+
+// The characters which aren't valid in the ids of nodes
+var nodeIdRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// NewGraph creates the graph of the services of the project and the modules of its infrastructure. The Azure resources
+// of the services are added with [Graph.AddTargetResource], once the environment is provisioned.
+func NewGraph(projectConfig *ProjectConfig) (*Graph, error) {
+	graph := &Graph{
+		Nodes: []*GraphNode{},
+		Edges: []*GraphEdge{},
+	}
+
+	if err := graph.addInfra(projectConfig.Path, projectConfig.Infra, "infra"); err != nil {
+		return nil, err
+	}
+
+	for _, svc := range projectConfig.GetServicesStable() {
+		graph.addNode(serviceNodeId(svc.Name), GraphNodeService, fmt.Sprintf("%s (%s)", svc.Name, svc.Host))
+	}
+
+	for _, svc := range projectConfig.GetServicesStable() {
+		for _, used := range svc.Uses {
+			graph.addEdge(serviceNodeId(svc.Name), serviceNodeId(used), "uses")
+		}
+
+		// Services with their own infrastructure are provisioned by it
+		if svc.Infra.Path == "" {
+			continue
+		}
+
+		infraId := "infra_" + svc.Name
+		if err := graph.addInfra(svc.Path(), svc.Infra, infraId); err != nil {
+			return nil, fmt.Errorf("reading infrastructure of service '%s': %w", svc.Name, err)
+		}
+
+		if graph.hasNode(nodeId(infraId)) {
+			graph.addEdge(nodeId(infraId), serviceNodeId(svc.Name), "provisions")
+		}
+	}
+
+	return graph, nil
+}
+
+// AddTargetResource adds the Azure resource the service is deployed to
+func (g *Graph) AddTargetResource(serviceName string, targetResource *environment.TargetResource) {
+	// The resources of services supporting delayed provisioning are created on deploy
+	if targetResource == nil || targetResource.ResourceName() == "" {
+		return
+	}
+
+	id := nodeId("resource_" + serviceName)
+	g.addNode(id, GraphNodeResource, fmt.Sprintf(
+		"%s (%s, %s)", targetResource.ResourceName(), targetResource.ResourceType(), targetResource.ResourceGroupName()))
+	g.addEdge(serviceNodeId(serviceName), id, "deploys to")
+}
+
+// Dot returns the graph in the DOT language of Graphviz, ex) for `dot -Tsvg`
+func (g *Graph) Dot() string {
+	shapes := map[GraphNodeKind]string{
+		GraphNodeService:  "box",
+		GraphNodeResource: "ellipse",
+		GraphNodeModule:   "component",
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("digraph azd {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(sb, "    %s [label=%s, shape=%s];\n", node.Id, dotQuote(node.Label), shapes[node.Kind])
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(sb, "    %s -> %s [label=%s];\n", edge.From, edge.To, dotQuote(edge.Label))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, ex) for markdown files rendered by GitHub
+func (g *Graph) Mermaid() string {
+	shapes := map[GraphNodeKind][2]string{
+		GraphNodeService:  {"[", "]"},
+		GraphNodeResource: {"([", "])"},
+		GraphNodeModule:   {"[[", "]]"},
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("flowchart LR\n")
+	for _, node := range g.Nodes {
+		shape := shapes[node.Kind]
+		fmt.Fprintf(sb, "    %s%s\"%s\"%s\n", node.Id, shape[0], mermaidEscape(node.Label), shape[1])
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(sb, "    %s -->|%s| %s\n", edge.From, mermaidEscape(edge.Label), edge.To)
+	}
+
+	return sb.String()
+}
+
+// addInfra adds the root module of the infrastructure of the options and the modules it declares. Nothing is added when
+// the infrastructure has no files, ex) when it's generated on provision.
+func (g *Graph) addInfra(rootPath string, options provisioning.Options, id string) error {
+	infraPath := options.Path
+	if infraPath == "" {
+		infraPath = cInfraDirectory
+	}
+	if !filepath.IsAbs(infraPath) {
+		infraPath = filepath.Join(rootPath, infraPath)
+	}
+
+	module := options.Module
+	if module == "" {
+		module = "main"
+	}
+
+	var files []string
+	var moduleRegex *regexp.Regexp
+	switch options.Provider {
+	case provisioning.Terraform:
+		matches, err := filepath.Glob(filepath.Join(infraPath, "*.tf"))
+		if err != nil {
+			return err
+		}
+		files = matches
+		moduleRegex = terraformModuleRegex
+	case provisioning.Bicep, "":
+		files = []string{filepath.Join(infraPath, module+".bicep")}
+		moduleRegex = bicepModuleRegex
+	default:
+		return nil
+	}
+
+	modules := []string{}
+	found := false
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		found = true
+		for _, match := range moduleRegex.FindAllStringSubmatch(string(contents), -1) {
+			modules = append(modules, match[1])
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	rootLabel := filepath.ToSlash(filepath.Join(options.Path, module))
+	if options.Path == "" {
+		rootLabel = filepath.ToSlash(filepath.Join(cInfraDirectory, module))
+	}
+	g.addNode(nodeId(id), GraphNodeModule, rootLabel)
+
+	sort.Strings(modules)
+	for _, name := range modules {
+		moduleId := nodeId(id + "_" + name)
+		if g.hasNode(moduleId) {
+			continue
+		}
+
+		g.addNode(moduleId, GraphNodeModule, name)
+		g.addEdge(nodeId(id), moduleId, "declares")
+	}
+
+	return nil
+}
+
+func (g *Graph) addNode(id string, kind GraphNodeKind, label string) {
+	g.Nodes = append(g.Nodes, &GraphNode{Id: id, Kind: kind, Label: label})
+}
+
+func (g *Graph) addEdge(from string, to string, label string) {
+	g.Edges = append(g.Edges, &GraphEdge{From: from, To: to, Label: label})
+}
+
+func (g *Graph) hasNode(id string) bool {
+	for _, node := range g.Nodes {
+		if node.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serviceNodeId(serviceName string) string {
+	return nodeId("service_" + serviceName)
+}
+
+// nodeId replaces the characters of the id which aren't valid in the ids of DOT and Mermaid nodes
+func nodeId(id string) string {
+	return nodeIdRegex.ReplaceAllString(id, "_")
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func mermaidEscape(value string) string {
+	return strings.ReplaceAll(value, `"`, "#quot;")
+}
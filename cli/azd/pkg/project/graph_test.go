@@ -0,0 +1,112 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGraphProject(t *testing.T) *ProjectConfig {
+	projectConfig, err := Parse(context.Background(), heredoc.Doc(`
+		name: shop
+		services:
+		  web:
+		    project: src/web
+		    language: js
+		    host: staticwebapp
+		    uses:
+		      - api
+		  api:
+		    project: src/api
+		    language: python
+		    host: containerapp
+	`))
+	require.NoError(t, err)
+
+	projectConfig.Path = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectConfig.Path, "infra"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectConfig.Path, "infra", "main.bicep"), []byte(heredoc.Doc(`
+		targetScope = 'subscription'
+
+		module web './app/web.bicep' = {
+		  name: 'web'
+		}
+
+		module api './app/api.bicep' = {
+		  name: 'api'
+		}
+	`)), 0600))
+
+	return projectConfig
+}
+
+func Test_NewGraph(t *testing.T) {
+	graph, err := NewGraph(newTestGraphProject(t))
+	require.NoError(t, err)
+
+	graph.AddTargetResource("api", environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "rg-shop", "ca-api", "Microsoft.App/containerApps"))
+	// The resources of services supporting delayed provisioning aren't created until deploy
+	graph.AddTargetResource("web", environment.NewTargetResource("SUBSCRIPTION_ID", "rg-shop", "", ""))
+
+	require.Equal(t, []*GraphNode{
+		{Id: "infra", Kind: GraphNodeModule, Label: "infra/main"},
+		{Id: "infra_api", Kind: GraphNodeModule, Label: "api"},
+		{Id: "infra_web", Kind: GraphNodeModule, Label: "web"},
+		{Id: "service_api", Kind: GraphNodeService, Label: "api (containerapp)"},
+		{Id: "service_web", Kind: GraphNodeService, Label: "web (staticwebapp)"},
+		{Id: "resource_api", Kind: GraphNodeResource, Label: "ca-api (Microsoft.App/containerApps, rg-shop)"},
+	}, graph.Nodes)
+
+	require.Equal(t, []*GraphEdge{
+		{From: "infra", To: "infra_api", Label: "declares"},
+		{From: "infra", To: "infra_web", Label: "declares"},
+		{From: "service_web", To: "service_api", Label: "uses"},
+		{From: "service_api", To: "resource_api", Label: "deploys to"},
+	}, graph.Edges)
+}
+
+func Test_NewGraph_NoInfra(t *testing.T) {
+	projectConfig := newTestGraphProject(t)
+	require.NoError(t, os.RemoveAll(filepath.Join(projectConfig.Path, "infra")))
+
+	graph, err := NewGraph(projectConfig)
+	require.NoError(t, err)
+
+	for _, node := range graph.Nodes {
+		require.Equal(t, GraphNodeService, node.Kind)
+	}
+}
+
+func Test_Graph_Formats(t *testing.T) {
+	graph := &Graph{
+		Nodes: []*GraphNode{
+			{Id: "service_web", Kind: GraphNodeService, Label: `web "frontend"`},
+			{Id: "resource_web", Kind: GraphNodeResource, Label: "app-web"},
+		},
+		Edges: []*GraphEdge{
+			{From: "service_web", To: "resource_web", Label: "deploys to"},
+		},
+	}
+
+	require.Equal(t, heredoc.Doc(`
+		flowchart LR
+		    service_web["web #quot;frontend#quot;"]
+		    resource_web(["app-web"])
+		    service_web -->|deploys to| resource_web
+	`), graph.Mermaid())
+
+	require.Equal(t, heredoc.Doc(`
+		digraph azd {
+		    rankdir=LR;
+		    service_web [label="web \"frontend\"", shape=box];
+		    resource_web [label="app-web", shape=ellipse];
+		    service_web -> resource_web [label="deploys to"];
+		}
+	`), graph.Dot())
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -15,6 +14,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/blang/semver/v4"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
@@ -25,6 +25,9 @@ const (
 	projectSchemaAnnotation = "# yaml-language-server: $schema=https://raw.githubusercontent.com/Azure/azure-dev/main/schemas/v1.0/azure.yaml.json"
 
 	cInfraDirectory = "infra"
+
+	// cEnvironmentsKey is the key of the sections of azure.yaml overriding the project for environments
+	cEnvironmentsKey = "environments"
 )
 
 func New(ctx context.Context, projectFilePath string, projectName string) (*ProjectConfig, error) {
@@ -42,13 +45,38 @@ func New(ctx context.Context, projectFilePath string, projectName string) (*Proj
 
 // Parse will parse a project from a yaml string and return the project configuration
 func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
+	return ParseForEnvironment(ctx, yamlContent, "")
+}
+
+// ParseForEnvironment parses a project like [Parse], with the section of the environment of the environments of the
+// project merged into it, ex)
+//
+//	services:
+//	  api:
+//	    host: appservice
+//	environments:
+//	  prod:
+//	    services:
+//	      api:
+//	        host: containerapp
+//
+// Mappings are merged key by key, other values replace the values of the project, and null values remove them. The
+// environments section is ignored when envName is empty.
+func ParseForEnvironment(ctx context.Context, yamlContent string, envName string) (*ProjectConfig, error) {
 	var projectConfig ProjectConfig
 
 	if strings.TrimSpace(yamlContent) == "" {
 		return nil, fmt.Errorf("unable to parse azure.yaml file. File is empty.")
 	}
 
-	if err := yaml.Unmarshal([]byte(yamlContent), &projectConfig); err != nil {
+	var document yaml.Node
+	err := yaml.Unmarshal([]byte(yamlContent), &document)
+	if err == nil && len(document.Content) > 0 {
+		if err = applyEnvironmentOverrides(document.Content[0], envName); err == nil {
+			err = document.Content[0].Decode(&projectConfig)
+		}
+	}
+	if err != nil {
 		return nil, fmt.Errorf(
 			"unable to parse azure.yaml file. Check the format of the file, "+
 				"and also verify you have the latest version of the CLI: %w",
@@ -73,7 +101,6 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 		}
 	}
 
-	var err error
 	projectConfig.Infra.Provider, err = provisioning.ParseProvider(projectConfig.Infra.Provider)
 	if err != nil {
 		return nil, fmt.Errorf("parsing project %s: %w", projectConfig.Name, err)
@@ -101,6 +128,10 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 		}
 	}
 
+	if _, err := projectConfig.GetServicesDeployOrder(); err != nil {
+		return nil, fmt.Errorf("parsing project %s: %w", projectConfig.Name, err)
+	}
+
 	if projectConfig.Infra.Path == "" {
 		projectConfig.Infra.Path = cInfraDirectory
 	}
@@ -108,18 +139,108 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 	return &projectConfig, nil
 }
 
+// applyEnvironmentOverrides removes the environments section of the project, and merges the section of the environment
+// into the project
+func applyEnvironmentOverrides(root *yaml.Node, envName string) error {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var environments *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == cEnvironmentsKey {
+			environments = root.Content[i+1]
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+
+	if environments == nil || envName == "" || environments.Tag == "!!null" {
+		return nil
+	}
+
+	if environments.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: '%s' must be a mapping of environment names", environments.Line, cEnvironmentsKey)
+	}
+
+	for i := 0; i+1 < len(environments.Content); i += 2 {
+		if !strings.EqualFold(environments.Content[i].Value, envName) {
+			continue
+		}
+
+		override := environments.Content[i+1]
+		if override.Tag == "!!null" {
+			return nil
+		}
+
+		if override.Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: the overrides of environment '%s' must be a mapping", override.Line, envName)
+		}
+
+		log.Printf("applying the overrides of environment '%s' of azure.yaml", envName)
+		mergeYamlNodes(root, override)
+		return nil
+	}
+
+	return nil
+}
+
+// mergeYamlNodes merges the override mapping into the target mapping: nested mappings are merged, other values are
+// replaced, and null values remove the keys of the target
+func mergeYamlNodes(target *yaml.Node, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+
+		index := -1
+		for j := 0; j+1 < len(target.Content); j += 2 {
+			if target.Content[j].Value == key.Value {
+				index = j
+				break
+			}
+		}
+
+		switch {
+		case index < 0 && value.Tag == "!!null":
+		case index < 0:
+			target.Content = append(target.Content, key, value)
+		case value.Tag == "!!null":
+			target.Content = append(target.Content[:index], target.Content[index+2:]...)
+		case value.Kind == yaml.MappingNode && target.Content[index+1].Kind == yaml.MappingNode:
+			mergeYamlNodes(target.Content[index+1], value)
+		default:
+			target.Content[index+1] = value
+		}
+	}
+}
+
 // Load hydrates the azure.yaml configuring into an viewable structure
 // This does not evaluate any tooling
 func Load(ctx context.Context, projectFilePath string) (*ProjectConfig, error) {
+	return LoadFs(ctx, vfs.OsFs(), projectFilePath)
+}
+
+// LoadFs hydrates the azure.yaml of the file system, like [Load]
+func LoadFs(ctx context.Context, fs vfs.Fs, projectFilePath string) (*ProjectConfig, error) {
+	return LoadFsForEnvironment(ctx, fs, projectFilePath, "")
+}
+
+// LoadFsForEnvironment hydrates the azure.yaml of the file system with the overrides of the environment merged into it,
+// like [ParseForEnvironment]
+func LoadFsForEnvironment(
+	ctx context.Context,
+	fs vfs.Fs,
+	projectFilePath string,
+	envName string,
+) (*ProjectConfig, error) {
 	log.Printf("Reading project from file '%s'\n", projectFilePath)
-	bytes, err := os.ReadFile(projectFilePath)
+	bytes, err := fs.ReadFile(projectFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("reading project file: %w", err)
 	}
 
 	yaml := string(bytes)
 
-	projectConfig, err := Parse(ctx, yaml)
+	projectConfig, err := ParseForEnvironment(ctx, yaml, envName)
 	if err != nil {
 		return nil, fmt.Errorf("parsing project file: %w", err)
 	}
@@ -163,6 +284,11 @@ func Load(ctx context.Context, projectFilePath string) (*ProjectConfig, error) {
 
 // Saves the current instance back to the azure.yaml file
 func Save(ctx context.Context, projectConfig *ProjectConfig, projectFilePath string) error {
+	return SaveFs(ctx, vfs.OsFs(), projectConfig, projectFilePath)
+}
+
+// Saves the current instance back to the azure.yaml file of the file system
+func SaveFs(ctx context.Context, fs vfs.Fs, projectConfig *ProjectConfig, projectFilePath string) error {
 	projectBytes, err := yaml.Marshal(projectConfig)
 	if err != nil {
 		return fmt.Errorf("marshalling project yaml: %w", err)
@@ -174,7 +300,7 @@ func Save(ctx context.Context, projectConfig *ProjectConfig, projectFilePath str
 		return fmt.Errorf("preparing new project file contents: %w", err)
 	}
 
-	err = os.WriteFile(projectFilePath, projectFileContents.Bytes(), osutil.PermissionFile)
+	err = fs.WriteFile(projectFilePath, projectFileContents.Bytes(), osutil.PermissionFile)
 	if err != nil {
 		return fmt.Errorf("saving project file: %w", err)
 	}
@@ -2,10 +2,13 @@ package project
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/policy"
 )
 
 // ProjectConfig is the top level object serialized into an azure.yaml file.
@@ -21,6 +24,12 @@ type ProjectConfig struct {
 	Infra             provisioning.Options       `yaml:"infra,omitempty"`
 	Pipeline          PipelineOptions            `yaml:"pipeline,omitempty"`
 	Hooks             map[string]*ext.HookConfig `yaml:"hooks,omitempty"`
+	Policy            *policy.Options            `yaml:"policy,omitempty"`
+	Images            *ImagesOptions             `yaml:"images,omitempty"`
+	RoleAssignments   []RoleAssignmentConfig     `yaml:"roleAssignments,omitempty"`
+	Environment       *EnvironmentTemplate       `yaml:"environment,omitempty"`
+	LoadTest          *LoadTestConfig            `yaml:"loadTest,omitempty"`
+	Chaos             *ChaosConfig               `yaml:"chaos,omitempty"`
 
 	*ext.EventDispatcher[ProjectLifecycleEventArgs] `yaml:",omitempty"`
 }
@@ -35,6 +44,96 @@ type RequiredVersions struct {
 // options supported in azure.yaml
 type PipelineOptions struct {
 	Provider string `yaml:"provider"`
+	// Names of the environment values published as outputs of the CI step azd runs in, ex) WEBSITE_URL. Supports *
+	// wildcards.
+	Outputs []string `yaml:"outputs,omitempty"`
+}
+
+// RoleAssignmentConfig is a role assignment declared in azure.yaml, applied after the infrastructure is provisioned.
+type RoleAssignmentConfig struct {
+	// The principal assigned the role: user, the signed in user; pipeline, the service principal of the pipeline
+	// configured with azd pipeline config; service:<name>, the managed identity of a service; or an object id.
+	Principal ExpandableString `yaml:"principal"`
+	// The name, ex) Storage Blob Data Contributor, or the id of the role definition
+	Role string `yaml:"role"`
+	// The scope of the role assignment: resourceGroup, the default; subscription; service:<name>, the resource of a
+	// service; or a resource id.
+	Scope ExpandableString `yaml:"scope,omitempty"`
+}
+
+// EnvironmentTemplate are the values of the environments of the project declared in azure.yaml, set on environments
+// when they're created. The values are committed with the project, and shouldn't be secrets.
+type EnvironmentTemplate struct {
+	// The values set on every new environment, ex) AZURE_LOCATION
+	Defaults map[string]string `yaml:"defaults,omitempty"`
+	// The values set on new environments of a given name, overriding the defaults, ex) a larger SKU for prod
+	Overrides map[string]map[string]string `yaml:"overrides,omitempty"`
+}
+
+// Values returns the values set on a new environment with the given name: the defaults, merged with the overrides of
+// the environment.
+func (et *EnvironmentTemplate) Values(envName string) map[string]string {
+	values := map[string]string{}
+	if et == nil {
+		return values
+	}
+
+	for key, value := range et.Defaults {
+		values[key] = value
+	}
+
+	for key, value := range et.Overrides[envName] {
+		values[key] = value
+	}
+
+	return values
+}
+
+// LoadTestConfig is a load test declared in azure.yaml, run on Azure Load Testing after the services are deployed.
+type LoadTestConfig struct {
+	// The path of the test plan, relative to the project: a JMeter test plan (.jmx) or a Locust script (.py)
+	TestPlan string `yaml:"testPlan"`
+	// The name of the Azure Load Testing resource, the only one in the resource group of the environment by default
+	Resource ExpandableString `yaml:"resource,omitempty"`
+	// The number of engines generating the load, 1 by default
+	EngineInstances int `yaml:"engineInstances,omitempty"`
+	// The environment variables of the test plan, ex) the endpoint of a service
+	Env map[string]ExpandableString `yaml:"env,omitempty"`
+	// The criteria failing the test, ex) avg(response_time_ms) > 500 or percentage(error) > 5
+	FailureCriteria []string `yaml:"failureCriteria,omitempty"`
+}
+
+// ChaosConfig are the Chaos Studio experiments declared in azure.yaml, run against the environment after the services
+// are deployed.
+type ChaosConfig struct {
+	// The names of the environments the experiments run in, ex) dev or test. The experiments never run in the other
+	// environments, so that they don't disrupt production.
+	Environments []string `yaml:"environments"`
+	// The experiments, run one after the other
+	Experiments []ChaosExperimentConfig `yaml:"experiments"`
+}
+
+// ChaosExperimentConfig is a Chaos Studio experiment run after the services are deployed.
+type ChaosExperimentConfig struct {
+	// The name of the experiment
+	Name ExpandableString `yaml:"name"`
+	// The resource group of the experiment, the resource group of the environment by default
+	ResourceGroup ExpandableString `yaml:"resourceGroup,omitempty"`
+}
+
+// RunsIn reports whether the experiments run in the environment with the given name.
+func (c *ChaosConfig) RunsIn(envName string) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, name := range c.Environments {
+		if strings.EqualFold(name, envName) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Project lifecycle event arguments
@@ -79,3 +178,51 @@ func (p *ProjectConfig) GetServicesStable() []*ServiceConfig {
 	}
 	return services
 }
+
+// GetServicesDeployOrder returns the services of the project in the order they're deployed, each service after the
+// services it uses and otherwise in the stable ordering of GetServicesStable. It fails when a service uses a service the
+// project doesn't have, or when services use each other.
+func (p *ProjectConfig) GetServicesDeployOrder() ([]*ServiceConfig, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	states := map[string]int{}
+	services := make([]*ServiceConfig, 0, len(p.Services))
+
+	var visit func(svc *ServiceConfig, path []string) error
+	visit = func(svc *ServiceConfig, path []string) error {
+		path = append(path, svc.Name)
+		switch states[svc.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("services use each other: %s", strings.Join(path, " -> "))
+		}
+
+		states[svc.Name] = visiting
+		for _, name := range svc.Uses {
+			used, has := p.Services[name]
+			if !has || used == nil {
+				return fmt.Errorf("service '%s' uses service '%s', which doesn't exist", svc.Name, name)
+			}
+
+			if err := visit(used, path); err != nil {
+				return err
+			}
+		}
+		states[svc.Name] = visited
+
+		services = append(services, svc)
+		return nil
+	}
+
+	for _, svc := range p.GetServicesStable() {
+		if err := visit(svc, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return services, nil
+}
@@ -39,6 +39,35 @@ func TestProjectConfigParse_Invalid(t *testing.T) {
 						host: appservice-containerapp-hybrid-edge-cloud
 			`),
 		},
+		{
+			name: "UsesUnknownService",
+			projectConfig: heredoc.Doc(`
+				name: proj-uses-unknown-service
+				services:
+					web:
+						language: js
+						host: appservice
+						uses:
+							- api
+			`),
+		},
+		{
+			name: "UsesCycle",
+			projectConfig: heredoc.Doc(`
+				name: proj-uses-cycle
+				services:
+					api:
+						language: js
+						host: appservice
+						uses:
+							- web
+					web:
+						language: js
+						host: appservice
+						uses:
+							- api
+			`),
+		},
 		{
 			name: "BadVersionConstraints",
 			projectConfig: heredoc.Doc(`
@@ -148,6 +177,30 @@ services:
 	require.Equal(t, "../", service.Docker.Context)
 }
 
+func TestProjectWithPolicyOptions(t *testing.T) {
+	const testProj = `
+name: test-proj
+policy:
+  enabled: true
+  paths:
+    - ./policies
+  settings:
+    requiredTags:
+      - owner
+      - cost-center
+`
+
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	require.NotNil(t, projectConfig.Policy)
+
+	require.True(t, projectConfig.Policy.Enabled)
+	require.False(t, projectConfig.Policy.DisableBuiltIn)
+	require.Equal(t, []string{"./policies"}, projectConfig.Policy.Paths)
+	require.Equal(t, []any{"owner", "cost-center"}, projectConfig.Policy.Settings["requiredTags"])
+}
+
 func TestProjectConfigAddHandler(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	project := getProjectConfig()
@@ -368,6 +421,62 @@ services:
 	require.Equal(t, "hello", projectConfig.ResourceGroupName.MustEnvsubst(env.Getenv))
 }
 
+func TestEnvironmentTemplate(t *testing.T) {
+	const testProj = `
+name: test-proj
+environment:
+  defaults:
+    AZURE_LOCATION: eastus2
+    APP_SERVICE_SKU: B1
+  overrides:
+    prod:
+      APP_SERVICE_SKU: P1v3
+      ALERTS_ENABLED: "true"
+`
+
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"AZURE_LOCATION":  "eastus2",
+		"APP_SERVICE_SKU": "B1",
+	}, projectConfig.Environment.Values("dev"))
+
+	require.Equal(t, map[string]string{
+		"AZURE_LOCATION":  "eastus2",
+		"APP_SERVICE_SKU": "P1v3",
+		"ALERTS_ENABLED":  "true",
+	}, projectConfig.Environment.Values("prod"))
+
+	var noTemplate *EnvironmentTemplate
+	require.Empty(t, noTemplate.Values("dev"))
+}
+
+func TestChaosConfig(t *testing.T) {
+	const testProj = `
+name: test-proj
+chaos:
+  environments: [dev, Test]
+  experiments:
+    - name: exp-${AZURE_ENV_NAME}-zone-down
+`
+
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+
+	require.True(t, projectConfig.Chaos.RunsIn("dev"))
+	require.True(t, projectConfig.Chaos.RunsIn("test"))
+	require.False(t, projectConfig.Chaos.RunsIn("prod"))
+	require.Equal(t, "exp-dev-zone-down", projectConfig.Chaos.Experiments[0].Name.MustEnvsubst(func(name string) string {
+		return map[string]string{"AZURE_ENV_NAME": "dev"}[name]
+	}))
+
+	var noChaos *ChaosConfig
+	require.False(t, noChaos.RunsIn("dev"))
+}
+
 func TestMinVersion(t *testing.T) {
 	savedVersion := internal.Version
 	t.Cleanup(func() {
@@ -437,3 +546,28 @@ metadata:
 		require.NoError(t, err)
 	})
 }
+
+func TestGetServicesDeployOrder(t *testing.T) {
+	projectConfig := &ProjectConfig{
+		Services: map[string]*ServiceConfig{
+			"api":    {Name: "api", Uses: []string{"db"}},
+			"db":     {Name: "db"},
+			"admin":  {Name: "admin", Uses: []string{"web", "api"}},
+			"web":    {Name: "web", Uses: []string{"api"}},
+			"worker": {Name: "worker"},
+		},
+	}
+
+	services, err := projectConfig.GetServicesDeployOrder()
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+	require.Equal(t, []string{"db", "api", "web", "admin", "worker"}, names)
+
+	projectConfig.Services["db"].Uses = []string{"admin"}
+	_, err = projectConfig.GetServicesDeployOrder()
+	require.EqualError(t, err, "services use each other: admin -> web -> api -> db -> admin")
+}
@@ -170,7 +170,15 @@ func (pm *projectManager) EnsureFrameworkTools(
 			return fmt.Errorf("getting service required tools: %w", err)
 		}
 
+		restorer, err := pm.serviceManager.GetDependencyRestorer(ctx, svc)
+		if err != nil {
+			return fmt.Errorf("getting dependency restorer: %w", err)
+		}
+
 		requiredTools = append(requiredTools, frameworkTools...)
+		if restorer != nil {
+			requiredTools = append(requiredTools, restorer.RequiredExternalTools(svc)...)
+		}
 	}
 
 	if err := tools.EnsureInstalled(ctx, tools.Unique(requiredTools)...); err != nil {
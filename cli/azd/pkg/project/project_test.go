@@ -252,3 +252,54 @@ func Test_Invalid_Project_File(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParseForEnvironment(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    hooks:
+      predeploy:
+        run: echo seed
+  web:
+    project: src/web
+    language: js
+    host: staticwebapp
+environments:
+  prod:
+    services:
+      api:
+        host: containerapp
+        hooks: ~
+  Test:
+    name: test-proj-test
+`
+	ctx := context.Background()
+
+	projectConfig, err := ParseForEnvironment(ctx, testProj, "prod")
+	require.NoError(t, err)
+	require.Equal(t, "test-proj", projectConfig.Name)
+	require.Equal(t, ContainerAppTarget, projectConfig.Services["api"].Host)
+	require.Equal(t, "src/api", projectConfig.Services["api"].RelativePath)
+	require.Empty(t, projectConfig.Services["api"].Hooks)
+	require.Equal(t, StaticWebAppTarget, projectConfig.Services["web"].Host)
+
+	// Environment names match regardless of their case
+	projectConfig, err = ParseForEnvironment(ctx, testProj, "test")
+	require.NoError(t, err)
+	require.Equal(t, "test-proj-test", projectConfig.Name)
+	require.Equal(t, AppServiceTarget, projectConfig.Services["api"].Host)
+
+	for _, envName := range []string{"", "dev"} {
+		projectConfig, err = ParseForEnvironment(ctx, testProj, envName)
+		require.NoError(t, err)
+		require.Equal(t, AppServiceTarget, projectConfig.Services["api"].Host)
+		require.Len(t, projectConfig.Services["api"].Hooks, 1)
+	}
+
+	_, err = ParseForEnvironment(ctx, "name: test-proj\nenvironments:\n  prod: appservice\n", "prod")
+	require.ErrorContains(t, err, "the overrides of environment 'prod' must be a mapping")
+}
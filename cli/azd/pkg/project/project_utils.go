@@ -5,35 +5,109 @@ package project
 
 import (
 	"fmt"
+	"io/fs"
+	"log"
 	"os"
+	"path/filepath"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/ignore"
 	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/otiai10/copy"
 )
 
-// CreateDeployableZip creates a zip file of a folder, recursively.
-// Returns the path to the created zip file or an error if it fails.
-func createDeployableZip(appName string, path string) (string, error) {
-	// TODO: should probably avoid picking up files that weren't meant to be deployed (ie, local .env files, etc..)
+// defaultIgnorePatterns are excluded from the packages of all services, unless included with ! patterns of .azdignore files
+var defaultIgnorePatterns = []string{".azure/"}
+
+// languageIgnorePatterns are excluded from the packages of the services of the languages, ex) restored dependencies
+var languageIgnorePatterns = map[ServiceLanguageKind][]string{
+	ServiceLanguageJavaScript: {"node_modules/"},
+	ServiceLanguageTypeScript: {"node_modules/"},
+	ServiceLanguagePython:     {"__pycache__/"},
+}
+
+// newIgnoreMatcher returns the matcher of the files excluded from the package of the service rooted at root: the default
+// patterns, then the patterns of the .azdignore files of the project and of the service. Their ! patterns include files
+// excluded by default, ex) !.azure/ ships the .azure folder.
+func newIgnoreMatcher(serviceConfig *ServiceConfig, root string) (*ignore.Matcher, error) {
+	matcher := ignore.New(root)
+	if err := matcher.AddPatterns("default", defaultIgnorePatterns...); err != nil {
+		return nil, err
+	}
+
+	if err := matcher.AddPatterns("default", languageIgnorePatterns[serviceConfig.Language]...); err != nil {
+		return nil, err
+	}
+
+	if err := matcher.AddFile(filepath.Join(serviceConfig.Project.Path, ignore.FileName)); err != nil {
+		return nil, err
+	}
+
+	if filepath.Clean(serviceConfig.Path()) != filepath.Clean(serviceConfig.Project.Path) {
+		if err := matcher.AddFile(filepath.Join(serviceConfig.Path(), ignore.FileName)); err != nil {
+			return nil, err
+		}
+	}
+
+	return matcher, nil
+}
+
+// excludedPath matches the path against the ignore patterns of the service, and returns its path relative to root, ex)
+// node_modules/, when excluded. Excluded paths are logged with the pattern excluding them.
+func excludedPath(matcher *ignore.Matcher, root string, path string, isDir bool) (string, bool) {
+	rule := matcher.Match(path, isDir)
+	if rule == nil {
+		return "", false
+	}
+
+	relative, err := filepath.Rel(root, path)
+	if err != nil {
+		relative = path
+	}
+
+	relative = filepath.ToSlash(relative)
+	if isDir {
+		relative += "/"
+	}
+
+	log.Printf("excluding '%s' from package, matching %s", relative, rule)
+	return relative, true
+}
+
+// CreateDeployableZip creates a zip file of a folder, recursively, without the files excluded by the .azdignore files of
+// the service. Returns the path to the created zip file and the excluded paths, or an error if it fails.
+func createDeployableZip(serviceConfig *ServiceConfig, path string) (string, []string, error) {
+	matcher, err := newIgnoreMatcher(serviceConfig, path)
+	if err != nil {
+		return "", nil, err
+	}
+
 	zipFile, err := os.CreateTemp("", "azddeploy*.zip")
 	if err != nil {
-		return "", fmt.Errorf("failed when creating zip package to deploy %s: %w", appName, err)
+		return "", nil, fmt.Errorf("failed when creating zip package to deploy %s: %w", serviceConfig.Name, err)
 	}
 
-	if err := rzip.CreateFromDirectory(path, zipFile); err != nil {
+	excluded := []string{}
+	err = rzip.CreateFromDirectoryWithFilter(path, zipFile, func(filePath string, entry fs.DirEntry) bool {
+		relative, ok := excludedPath(matcher, path, filePath, entry.IsDir())
+		if ok {
+			excluded = append(excluded, relative)
+		}
+		return ok
+	})
+	if err != nil {
 		// if we fail here just do our best to close things out and cleanup
 		zipFile.Close()
 		os.Remove(zipFile.Name())
-		return "", err
+		return "", nil, err
 	}
 
 	if err := zipFile.Close(); err != nil {
 		// may fail but, again, we'll do our best to cleanup here.
 		os.Remove(zipFile.Name())
-		return "", err
+		return "", nil, err
 	}
 
-	return zipFile.Name(), nil
+	return zipFile.Name(), excluded, nil
 }
 
 // excludeDirEntryCondition resolves when a file or directory should be considered or not as part of build, when build is a
@@ -47,24 +121,34 @@ type buildForZipOptions struct {
 
 // buildForZip is use by projects which build strategy is to only copy the source code into a folder which is later
 // zipped for packaging. For example Python and Node framework languages. buildForZipOptions provides the specific
-// details for each language which should not be ever copied.
-func buildForZip(src, dst string, options buildForZipOptions) error {
-
-	// these exclude conditions applies to all projects
-	options.excludeConditions = append(options.excludeConditions, globalExcludeAzdFolder)
+// details for each language which should not be ever copied, and the .azdignore files of the service exclude the files
+// matching their patterns. Returns the paths excluded by the .azdignore files and default patterns.
+func buildForZip(serviceConfig *ServiceConfig, src, dst string, options buildForZipOptions) ([]string, error) {
+	matcher, err := newIgnoreMatcher(serviceConfig, src)
+	if err != nil {
+		return nil, err
+	}
 
-	return copy.Copy(src, dst, copy.Options{
-		Skip: func(srcInfo os.FileInfo, src, dest string) (bool, error) {
+	excluded := []string{}
+	err = copy.Copy(src, dst, copy.Options{
+		Skip: func(srcInfo os.FileInfo, path, dest string) (bool, error) {
 			for _, checkExclude := range options.excludeConditions {
-				if checkExclude(src, srcInfo) {
+				if checkExclude(path, srcInfo) {
 					return true, nil
 				}
 			}
+
+			if relative, ok := excludedPath(matcher, src, path, srcInfo.IsDir()); ok {
+				excluded = append(excluded, relative)
+				return true, nil
+			}
+
 			return false, nil
 		},
 	})
-}
+	if err != nil {
+		return nil, err
+	}
 
-func globalExcludeAzdFolder(path string, file os.FileInfo) bool {
-	return file.IsDir() && file.Name() == ".azure"
+	return excluded, nil
 }
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/ignore"
+	"github.com/stretchr/testify/require"
+)
+
+func createIgnoreTestService(t *testing.T) *ServiceConfig {
+	serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguageJavaScript)
+	serviceConfig.Project.Path = t.TempDir()
+
+	servicePath := serviceConfig.Path()
+	for _, file := range []string{
+		"index.js", "node_modules/a.js", ".azure/config.json", "tests/index.test.js", "local.settings.json", "app.js.map",
+	} {
+		path := filepath.Join(servicePath, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("//"), 0600))
+	}
+
+	require.NoError(t, os.WriteFile(
+		filepath.Join(serviceConfig.Project.Path, ignore.FileName), []byte("*.map\n"), 0600))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(servicePath, ignore.FileName), []byte("tests/\nlocal.settings.json\n!node_modules/\n"), 0600))
+
+	return serviceConfig
+}
+
+func Test_BuildForZip_Ignore(t *testing.T) {
+	serviceConfig := createIgnoreTestService(t)
+	dst := t.TempDir()
+
+	excluded, err := buildForZip(serviceConfig, serviceConfig.Path(), dst, buildForZipOptions{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{".azure/", "app.js.map", "local.settings.json", "tests/"}, excluded)
+
+	require.FileExists(t, filepath.Join(dst, "index.js"))
+	require.FileExists(t, filepath.Join(dst, ignore.FileName))
+	// node_modules is excluded by default, and included by the .azdignore of the service
+	require.FileExists(t, filepath.Join(dst, "node_modules", "a.js"))
+	require.NoDirExists(t, filepath.Join(dst, "tests"))
+	require.NoFileExists(t, filepath.Join(dst, "app.js.map"))
+}
+
+func Test_CreateDeployableZip_Ignore(t *testing.T) {
+	serviceConfig := createIgnoreTestService(t)
+
+	zipPath, excluded, err := createDeployableZip(serviceConfig, serviceConfig.Path())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(zipPath) })
+	require.Len(t, excluded, 4)
+
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	files := []string{}
+	for _, file := range reader.File {
+		files = append(files, file.Name)
+	}
+	require.ElementsMatch(t, []string{ignore.FileName, "index.js", "node_modules/a.js"}, files)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// ServiceCleanupTarget is implemented by the service targets which can clean up after a deploy of the service was
+// interrupted, ex) by the user cancelling azd deploy
+type ServiceCleanupTarget interface {
+	// CleanupDeploy reverts the changes the interrupted deploy left half applied, so the service keeps running its
+	// previous deployment. It's called with a context which isn't cancelled, after the deploy returned.
+	CleanupDeploy(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+	) error
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"golang.org/x/exp/slices"
 )
 
 type ServiceConfig struct {
@@ -20,24 +21,97 @@ type ServiceConfig struct {
 	Host ServiceTargetKind `yaml:"host"`
 	// The programming language of the project
 	Language ServiceLanguageKind `yaml:"language"`
+	// The package manager restoring the dependencies of the project, ex) pnpm. Detected from the files of the project when
+	// not set.
+	PackageManager PackageManagerKind `yaml:"packageManager,omitempty"`
 	// The output path for build artifacts
 	OutputPath string `yaml:"dist"`
 	// The optional docker options
 	Docker DockerProjectOptions `yaml:"docker"`
 	// The optional K8S / AKS options
 	K8s AksOptions `yaml:"k8s"`
+	// The optional Azure Container Apps options, ex) the secrets of the container app
+	ContainerApp ContainerAppOptions `yaml:"containerApp,omitempty"`
+	// The optional Azure Static Web Apps options, ex) the location of the API of the static web app
+	StaticWebApp StaticWebAppOptions `yaml:"staticWebApp,omitempty"`
 	// The optional Azure Spring Apps options
 	Spring SpringOptions `yaml:"spring"`
+	// The optional Java options, ex) the module of a multi-module project
+	Java JavaOptions `yaml:"java,omitempty"`
+	// The optional Go options, ex) the main package of the project
+	Go GoOptions `yaml:"go,omitempty"`
+	// The commands building services with language custom, and the path of the artifact they produce
+	Custom CustomOptions `yaml:"custom,omitempty"`
+	// The optional container the service is restored, built and packaged in instead of on the host
+	BuildContainer *BuildContainerOptions `yaml:"buildContainer,omitempty"`
 	// The infrastructure provisioning configuration
 	Infra provisioning.Options `yaml:"infra"`
 	// Hook configuration for service
 	Hooks map[string]*ext.HookConfig `yaml:"hooks,omitempty"`
+	// Whether the service is packaged and deployed, false for services which only define infrastructure.
+	// Defaults to true.
+	Deploy *bool `yaml:"deploy,omitempty"`
+	// The Application Insights release annotation created when the service is deployed
+	ReleaseAnnotation *ReleaseAnnotationOptions `yaml:"releaseAnnotation,omitempty"`
+	// The services whose deploy outputs the service consumes, ex) the endpoint of a backend. They're deployed before the
+	// service, and their outputs are stored in the environment as SERVICE_<NAME>_ENDPOINT_URL and
+	// SERVICE_<NAME>_RESOURCE_ID, for the service to reference them, ex) ${SERVICE_API_ENDPOINT_URL}.
+	Uses []string `yaml:"uses,omitempty"`
+	// The app settings set on the app service or function app of the service on each deploy, ex) the endpoint of a used
+	// service. The other app settings of the app are kept.
+	AppSettings map[string]ExpandableString `yaml:"appSettings,omitempty"`
+
+	// The resource of the .NET Aspire app host the service was synthesized from, nil for the services of azure.yaml
+	AppHost *AppHostResource `yaml:"-"`
 
 	*ext.EventDispatcher[ServiceLifecycleEventArgs] `yaml:",omitempty"`
 
 	initialized bool
 }
 
+// ReleaseAnnotationOptions configures the release annotation created on the Application Insights component of the
+// environment when the service is deployed, so that deployments show up on the metric charts of the component.
+type ReleaseAnnotationOptions struct {
+	// Whether an annotation is created when the service is deployed. Defaults to true.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Whether an annotation is created when azd runs in CI. Defaults to true.
+	CI *bool `yaml:"ci,omitempty"`
+}
+
+// Annotates reports whether a release annotation is created when the service is deployed, in CI or not.
+func (o *ReleaseAnnotationOptions) Annotates(onCI bool) bool {
+	if o == nil {
+		return true
+	}
+
+	if o.Enabled != nil && !*o.Enabled {
+		return false
+	}
+
+	return !onCI || o.CI == nil || *o.CI
+}
+
+// IsDeployed reports whether azd packages and deploys the service. Services with deploy: false only define
+// infrastructure, ex) shared resources of the other services.
+func (sc *ServiceConfig) IsDeployed() bool {
+	return sc.Deploy == nil || *sc.Deploy
+}
+
+// isUsed reports whether other services of the project use the service, ie) consume the outputs of its deploy
+func (sc *ServiceConfig) isUsed() bool {
+	if sc.Project == nil {
+		return false
+	}
+
+	for _, svc := range sc.Project.Services {
+		if slices.Contains(svc.Uses, sc.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Path returns the fully qualified path to the project
 func (sc *ServiceConfig) Path() string {
 	return filepath.Join(sc.Project.Path, sc.RelativePath)
@@ -223,3 +223,35 @@ func createTestServiceConfig(path string, host ServiceTargetKind, language Servi
 		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
 	}
 }
+
+func TestServiceConfigIsDeployed(t *testing.T) {
+	deploy := false
+	require.True(t, (&ServiceConfig{}).IsDeployed())
+	require.False(t, (&ServiceConfig{Deploy: &deploy}).IsDeployed())
+
+	projectConfig, err := Parse(context.Background(), `
+name: test-proj
+services:
+  shared:
+    project: src/shared
+    language: js
+    host: containerapp
+    deploy: false
+`)
+	require.NoError(t, err)
+	require.False(t, projectConfig.Services["shared"].IsDeployed())
+}
+
+func TestReleaseAnnotationOptionsAnnotates(t *testing.T) {
+	disabled := false
+	var defaults *ReleaseAnnotationOptions
+	require.True(t, defaults.Annotates(false))
+	require.True(t, defaults.Annotates(true))
+
+	require.False(t, (&ReleaseAnnotationOptions{Enabled: &disabled}).Annotates(false))
+
+	// ci: false only suppresses the annotations of deployments run in CI
+	notOnCI := &ReleaseAnnotationOptions{CI: &disabled}
+	require.True(t, notOnCI.Annotates(false))
+	require.False(t, notOnCI.Annotates(true))
+}
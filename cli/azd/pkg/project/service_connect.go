@@ -0,0 +1,23 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// ServiceConnectTarget is implemented by the service targets which can forward a local port to the deployed service,
+// ex) to reach services which are only accessible inside a k8s cluster
+type ServiceConnectTarget interface {
+	// Connect forwards a local port to the service until the context is cancelled. ready is called with the local
+	// endpoint of the service once the port is forwarded.
+	Connect(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+		ready func(endpoint string),
+	) error
+}
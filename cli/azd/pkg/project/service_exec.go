@@ -0,0 +1,33 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+)
+
+// ExecOptions configures a command run inside a deployed service with azd exec
+type ExecOptions struct {
+	// The command and its arguments
+	Command []string
+	// Whether the command runs in a terminal, ex) an interactive shell. Service targets which can't allocate a terminal
+	// run the command without one.
+	Tty bool
+	// The console handles attached to the input and output of the command
+	Handles input.ConsoleHandles
+}
+
+// ServiceExecTarget is implemented by the service targets which can run commands inside the deployed service
+type ServiceExecTarget interface {
+	// Exec runs the command in the compute hosting the service and returns once the command exits
+	Exec(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+		options ExecOptions,
+	) error
+}
@@ -0,0 +1,33 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// LogsOptions configures the logs of a deployed service written by azd logs
+type LogsOptions struct {
+	// Only the logs newer than the duration are written, the most recent logs of the host when zero
+	Since time.Duration
+	// Whether the logs are streamed until the context is cancelled
+	Follow bool
+}
+
+// ServiceLogsTarget is implemented by the service targets which can write the logs of the deployed service
+type ServiceLogsTarget interface {
+	// Logs writes the logs of the compute hosting the service to the writer, streaming new logs until the context is
+	// cancelled when following the logs
+	Logs(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+		options LogsOptions,
+		writer io.Writer,
+	) error
+}
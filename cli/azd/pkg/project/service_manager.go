@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
@@ -12,6 +14,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 )
 
 const (
@@ -84,6 +87,11 @@ type ServiceManager interface {
 	// The framework service performs the restoration and building of the service app code
 	GetFrameworkService(ctx context.Context, serviceConfig *ServiceConfig) (FrameworkService, error)
 
+	// Gets the dependency restorer for the specified service config, selected by the package manager of the service
+	// Returns nil when the language of the service has no package manager, ex) docker, or when the service is built in
+	// a container
+	GetDependencyRestorer(ctx context.Context, serviceConfig *ServiceConfig) (DependencyRestorer, error)
+
 	// Gets the service target service for the specified service config
 	// The service target is responsible for packaging & deploying the service app code
 	// to the destination Azure resource
@@ -91,11 +99,14 @@ type ServiceManager interface {
 }
 
 type serviceManager struct {
-	env                 *environment.Environment
-	resourceManager     ResourceManager
-	serviceLocator      ioc.ServiceLocator
-	operationCache      map[string]any
+	env             *environment.Environment
+	resourceManager ResourceManager
+	serviceLocator  ioc.ServiceLocator
+	operationCache  map[string]any
+	// guards operationCache, services may be restored in parallel
+	operationCacheMu    sync.Mutex
 	alphaFeatureManager *alpha.FeatureManager
+	buildCache          *BuildCache
 }
 
 // NewServiceManager creates a new instance of the ServiceManager component
@@ -104,6 +115,7 @@ func NewServiceManager(
 	resourceManager ResourceManager,
 	serviceLocator ioc.ServiceLocator,
 	alphaFeatureManager *alpha.FeatureManager,
+	buildCache *BuildCache,
 ) ServiceManager {
 	return &serviceManager{
 		env:                 env,
@@ -111,6 +123,7 @@ func NewServiceManager(
 		serviceLocator:      serviceLocator,
 		operationCache:      map[string]any{},
 		alphaFeatureManager: alphaFeatureManager,
+		buildCache:          buildCache,
 	}
 }
 
@@ -126,8 +139,16 @@ func (sm *serviceManager) GetRequiredTools(ctx context.Context, serviceConfig *S
 		return nil, fmt.Errorf("getting service target: %w", err)
 	}
 
+	restorer, err := sm.GetDependencyRestorer(ctx, serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting dependency restorer: %w", err)
+	}
+
 	requiredTools := []tools.ExternalTool{}
 	requiredTools = append(requiredTools, frameworkService.RequiredExternalTools(ctx)...)
+	if restorer != nil {
+		requiredTools = append(requiredTools, restorer.RequiredExternalTools(serviceConfig)...)
+	}
 	requiredTools = append(requiredTools, serviceTarget.RequiredExternalTools(ctx)...)
 
 	return tools.Unique(requiredTools), nil
@@ -182,12 +203,24 @@ func (sm *serviceManager) Restore(
 			return
 		}
 
+		var cacheHash string
 		restoreResult, err := runCommand(
 			ctx,
 			task,
 			ServiceEventRestore,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+				// The dependencies are restored again only when their lock files changed since the last restore
+				var cached *buildCacheEntry
+				cached, cacheHash = sm.buildCache.lookup(serviceConfig, buildCacheRestore)
+				if cached != nil {
+					return async.RunTaskWithProgress(
+						func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+							task.SetProgress(NewServiceProgress("Dependencies unchanged, skipping restore"))
+							task.SetResult(&ServiceRestoreResult{})
+						})
+				}
+
 				return frameworkService.Restore(ctx, serviceConfig)
 			},
 		)
@@ -197,6 +230,8 @@ func (sm *serviceManager) Restore(
 			return
 		}
 
+		sm.buildCache.store(serviceConfig, buildCacheRestore, cacheHash, "")
+
 		task.SetResult(restoreResult)
 		sm.setOperationResult(ctx, serviceConfig, string(ServiceEventRestore), restoreResult)
 	})
@@ -229,12 +264,27 @@ func (sm *serviceManager) Build(
 			return
 		}
 
+		var cacheHash string
 		buildResult, err := runCommand(
 			ctx,
 			task,
 			ServiceEventBuild,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+				// The service is built again only when its source changed since the last build
+				var cached *buildCacheEntry
+				cached, cacheHash = sm.buildCache.lookup(serviceConfig, buildCacheBuild)
+				if cached != nil {
+					return async.RunTaskWithProgress(
+						func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+							task.SetProgress(NewServiceProgress("Source unchanged, skipping build"))
+							task.SetResult(&ServiceBuildResult{
+								Restore:         restoreOutput,
+								BuildOutputPath: cached.BuildOutputPath,
+							})
+						})
+				}
+
 				return frameworkService.Build(ctx, serviceConfig, restoreOutput)
 			},
 		)
@@ -244,6 +294,8 @@ func (sm *serviceManager) Build(
 			return
 		}
 
+		sm.buildCache.store(serviceConfig, buildCacheBuild, cacheHash, buildResult.BuildOutputPath)
+
 		task.SetResult(buildResult)
 		sm.setOperationResult(ctx, serviceConfig, string(ServiceEventBuild), buildResult)
 	})
@@ -421,6 +473,11 @@ func (sm *serviceManager) Deploy(
 			deployResult.Endpoints = overriddenEndpoints
 		}
 
+		if err := sm.setDeployOutputs(serviceConfig, deployResult); err != nil {
+			task.SetError(fmt.Errorf("storing the deploy outputs of service '%s': %w", serviceConfig.Name, err))
+			return
+		}
+
 		task.SetResult(deployResult)
 		sm.setOperationResult(ctx, serviceConfig, string(ServiceEventDeploy), deployResult)
 	})
@@ -458,6 +515,15 @@ func (sm *serviceManager) GetServiceTarget(ctx context.Context, serviceConfig *S
 func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig *ServiceConfig) (FrameworkService, error) {
 	var frameworkService FrameworkService
 
+	// The resources of app hosts are built as container images by the app host framework service
+	if serviceConfig.AppHost != nil {
+		if err := sm.serviceLocator.ResolveNamed(AppHostFramework, &frameworkService); err != nil {
+			panic(fmt.Errorf("failed to resolve app host framework for service '%s', %w", serviceConfig.Name, err))
+		}
+
+		return frameworkService, nil
+	}
+
 	if err := sm.serviceLocator.ResolveNamed(string(serviceConfig.Language), &frameworkService); err != nil {
 		panic(fmt.Errorf(
 			"failed to resolve language '%s' for service '%s', %w",
@@ -467,6 +533,20 @@ func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig
 		))
 	}
 
+	buildContainer, err := serviceConfig.buildContainer()
+	if err != nil {
+		return nil, err
+	}
+
+	if buildContainer != nil {
+		var dockerCli docker.Docker
+		if err := sm.serviceLocator.Resolve(&dockerCli); err != nil {
+			return nil, fmt.Errorf("resolving docker for the build container of service '%s': %w", serviceConfig.Name, err)
+		}
+
+		frameworkService = newContainerFrameworkService(frameworkService, buildContainer, dockerCli)
+	}
+
 	// For containerized applications we use a composite framework service
 	if serviceConfig.Host == ContainerAppTarget || serviceConfig.Host == AksTarget {
 		var compositeFramework CompositeFrameworkService
@@ -487,6 +567,41 @@ func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig
 	return frameworkService, nil
 }
 
+// Gets the dependency restorer for the specified service config
+func (sm *serviceManager) GetDependencyRestorer(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (DependencyRestorer, error) {
+	// The package manager of services built in a container runs in the container
+	if serviceConfig.BuildContainer != nil && serviceConfig.BuildContainer.Enabled {
+		return nil, nil
+	}
+
+	restorer, _, err := NewDependencyRestorers(sm.serviceLocator).Select(serviceConfig)
+	return restorer, err
+}
+
+// setDeployOutputs stores the outputs of the deploy of the service in the environment when other services of the project
+// use the service, for the services deployed after it to consume them
+func (sm *serviceManager) setDeployOutputs(serviceConfig *ServiceConfig, deployResult *ServiceDeployResult) error {
+	if !serviceConfig.isUsed() {
+		return nil
+	}
+
+	// The AKS service target stores the most publicly exposed endpoint itself
+	if serviceConfig.Host != AksTarget && len(deployResult.Endpoints) > 0 {
+		// Endpoints may be followed by identifying information, ex) https://<host>, (Service, Type: LoadBalancer)
+		endpointUrl, _, _ := strings.Cut(deployResult.Endpoints[0], ",")
+		sm.env.SetServiceProperty(serviceConfig.Name, "ENDPOINT_URL", endpointUrl)
+	}
+
+	if deployResult.TargetResourceId != "" {
+		sm.env.SetServiceProperty(serviceConfig.Name, "RESOURCE_ID", deployResult.TargetResourceId)
+	}
+
+	return sm.env.Save()
+}
+
 func (sm *serviceManager) getOverriddenEndpoints(ctx context.Context, serviceConfig *ServiceConfig) []string {
 	overriddenEndpoints := sm.env.GetServiceProperty(serviceConfig.Name, "ENDPOINTS")
 	if overriddenEndpoints != "" {
@@ -513,6 +628,9 @@ func (sm *serviceManager) getOperationResult(
 	serviceConfig *ServiceConfig,
 	operationName string,
 ) (any, bool) {
+	sm.operationCacheMu.Lock()
+	defer sm.operationCacheMu.Unlock()
+
 	key := fmt.Sprintf("%s:%s", serviceConfig.Name, operationName)
 	value, ok := sm.operationCache[key]
 
@@ -526,6 +644,9 @@ func (sm *serviceManager) setOperationResult(
 	operationName string,
 	result any,
 ) {
+	sm.operationCacheMu.Lock()
+	defer sm.operationCacheMu.Unlock()
+
 	key := fmt.Sprintf("%s:%s", serviceConfig.Name, operationName)
 	sm.operationCache[key] = result
 }
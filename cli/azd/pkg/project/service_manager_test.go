@@ -49,7 +49,7 @@ func createServiceManager(mockContext *mocks.MockContext, env *environment.Envir
 			},
 		}))
 
-	return NewServiceManager(env, resourceManager, serviceLocator, alphaManager)
+	return NewServiceManager(env, resourceManager, serviceLocator, alphaManager, NewBuildCache())
 }
 
 func Test_ServiceManager_GetRequiredTools(t *testing.T) {
@@ -217,6 +217,28 @@ func Test_ServiceManager_Deploy(t *testing.T) {
 	require.True(t, raisedPostDeployEvent)
 }
 
+func Test_ServiceManager_SetDeployOutputs(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.Ephemeral()
+	sm := createServiceManager(mockContext, env).(*serviceManager)
+
+	api := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+	api.Project.Services = map[string]*ServiceConfig{"api": api}
+	deployResult := &ServiceDeployResult{
+		TargetResourceId: "RESOURCE_ID",
+		Endpoints:        []string{"https://api.azurewebsites.net/"},
+	}
+
+	// No service uses api, its outputs aren't stored
+	require.NoError(t, sm.setDeployOutputs(api, deployResult))
+	require.Empty(t, env.GetServiceProperty("api", "ENDPOINT_URL"))
+
+	api.Project.Services["web"] = &ServiceConfig{Name: "web", Uses: []string{"api"}}
+	require.NoError(t, sm.setDeployOutputs(api, deployResult))
+	require.Equal(t, "https://api.azurewebsites.net/", env.GetServiceProperty("api", "ENDPOINT_URL"))
+	require.Equal(t, "RESOURCE_ID", env.GetServiceProperty("api", "RESOURCE_ID"))
+}
+
 func Test_ServiceManager_GetFrameworkService(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	setupMocksForServiceManager(mockContext)
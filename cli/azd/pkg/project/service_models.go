@@ -65,16 +65,27 @@ type ServicePackageResult struct {
 	Build       *ServiceBuildResult `json:"build"`
 	PackagePath string              `json:"packagePath"`
 	Details     interface{}         `json:"details"`
+	// The files and directories excluded from the package by the .azdignore files and default patterns, relative to
+	// the packaged directory, ex) node_modules/
+	Excluded []string `json:"excluded,omitempty"`
 }
 
 // Supports rendering messages for UX items
 func (spr *ServicePackageResult) ToString(currentIndentation string) string {
-	uxItem, ok := spr.Details.(ux.UxItem)
-	if ok {
-		return uxItem.ToString(currentIndentation)
+	var result string
+	if uxItem, ok := spr.Details.(ux.UxItem); ok {
+		result = uxItem.ToString(currentIndentation)
+	} else {
+		result = fmt.Sprintf("%s- Package Output: %s", currentIndentation, output.WithLinkFormat(spr.PackagePath))
 	}
 
-	return fmt.Sprintf("%s- Package Output: %s", currentIndentation, output.WithLinkFormat(spr.PackagePath))
+	if len(spr.Excluded) > 0 {
+		result += fmt.Sprintf(
+			"\n%s- Excluded: %d files and directories matching .azdignore and default patterns (listed with --debug)",
+			currentIndentation, len(spr.Excluded))
+	}
+
+	return result
 }
 
 func (spr *ServicePackageResult) MarshalJSON() ([]byte, error) {
@@ -89,6 +100,8 @@ type ServiceDeployResult struct {
 	Kind             ServiceTargetKind `json:"kind"`
 	Endpoints        []string          `json:"endpoints"`
 	Details          interface{}       `json:"details"`
+	// The url of the artifact of the deployed package, when uploaded
+	Artifact string `json:"artifact,omitempty"`
 }
 
 // Supports rendering messages for UX items
@@ -108,6 +121,10 @@ func (spr *ServiceDeployResult) ToString(currentIndentation string) string {
 		}
 	}
 
+	if spr.Artifact != "" {
+		builder.WriteString(fmt.Sprintf("%s- Artifact: %s\n", currentIndentation, output.WithLinkFormat(spr.Artifact)))
+	}
+
 	return builder.String()
 }
 
@@ -0,0 +1,32 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// Restarter is implemented by the service targets which can restart the deployed service with azd restart
+type Restarter interface {
+	// Restart restarts the compute hosting the service, without deploying it again
+	Restart(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+	) error
+}
+
+// Scaler is implemented by the service targets which can change the number of instances of the deployed service with
+// azd scale
+type Scaler interface {
+	// Scale sets the number of replicas, or instances, of the compute hosting the service
+	Scale(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+		replicas int,
+	) error
+}
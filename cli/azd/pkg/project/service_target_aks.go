@@ -4,18 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/policy"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubelogin"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -34,12 +47,21 @@ type AksOptions struct {
 	Deployment AksDeploymentOptions `yaml:"deployment"`
 	// The services service configuration options
 	Service AksServiceOptions `yaml:"service"`
+	// The workload identity configuration options
+	WorkloadIdentity *AksWorkloadIdentityOptions `yaml:"workloadIdentity,omitempty"`
+	// The image pull secret options, for registries the cluster can't pull from with its kubelet identity
+	ImagePullSecret *AksImagePullSecretOptions `yaml:"imagePullSecret,omitempty"`
+	// The public endpoint of the service, ex) https://${API_HOSTNAME}/api. When set, the endpoints of the service are
+	// not discovered from the resources of the cluster
+	Endpoint ExpandableString `yaml:"endpoint,omitempty"`
 }
 
 // The AKS ingress options
 type AksIngressOptions struct {
 	Name         string `yaml:"name"`
 	RelativePath string `yaml:"relativePath"`
+	// The TLS options of the ingress. When set, azd manages the certificate of the ingress in Key Vault
+	Tls *AksIngressTlsOptions `yaml:"tls,omitempty"`
 }
 
 // The AKS deployment options
@@ -52,25 +74,77 @@ type AksServiceOptions struct {
 	Name string `yaml:"name"`
 }
 
+// The AKS workload identity options. The service account of the service is federated with the user assigned managed
+// identity, and annotated with its client id, so that pods running as the service account get tokens of the identity.
+type AksWorkloadIdentityOptions struct {
+	// The resource id of the user assigned managed identity, ex) ${AZURE_API_IDENTITY_ID} from the outputs of the
+	// infrastructure
+	IdentityId ExpandableString `yaml:"identityId"`
+	// The name of the service account. Defaults to the service name
+	ServiceAccount string `yaml:"serviceAccount,omitempty"`
+}
+
+// The ingress class of the Web App Routing add-on of AKS
+const webAppRoutingIngressClass = "webapprouting.kubernetes.azure.com"
+
+// The subject of the tokens the OIDC issuer of AKS issues to pods running as a service account
+const workloadIdentitySubject = "system:serviceaccount:%s:%s"
+
+// Characters which aren't allowed in the names of federated identity credentials
+var invalidFederatedCredentialNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// The ids of the built-in roles which allow pulling images from a container registry: AcrPull, AcrPush, Contributor
+// and Owner
+var acrPullRoleIds = []string{
+	"7f951dda-4ed3-4680-a7ca-43fe172d538d",
+	"8311e382-0749-4cb8-b61a-304f252e45ec",
+	"b24988ac-6180-42a0-ab88-20f7382dd24c",
+	"8e3af657-a8ff-443c-a75c-2fe8c4bcb635",
+}
+
 type aksTarget struct {
-	env                    *environment.Environment
-	managedClustersService azcli.ManagedClustersService
-	kubectl                kubectl.KubectlCli
-	containerHelper        *ContainerHelper
+	env                      *environment.Environment
+	azCli                    azcli.AzCli
+	managedClustersService   azcli.ManagedClustersService
+	managedIdentityService   azcli.ManagedIdentityService
+	containerRegistryService azcli.ContainerRegistryService
+	kubectl                  kubectl.KubectlCli
+	kubelogin                kubelogin.KubeloginCli
+	containerHelper          *ContainerHelper
+	policyEngine             *policy.Engine
+	fs                       vfs.Fs
+	clock                    clock.Clock
+	waits                    *ServiceTargetWaits
 }
 
 // Creates a new instance of the AKS service target
 func NewAksTarget(
 	env *environment.Environment,
+	azCli azcli.AzCli,
 	managedClustersService azcli.ManagedClustersService,
+	managedIdentityService azcli.ManagedIdentityService,
+	containerRegistryService azcli.ContainerRegistryService,
 	kubectlCli kubectl.KubectlCli,
+	kubeloginCli kubelogin.KubeloginCli,
 	containerHelper *ContainerHelper,
+	policyEngine *policy.Engine,
+	fs vfs.Fs,
+	clock clock.Clock,
+	waits *ServiceTargetWaits,
 ) ServiceTarget {
 	return &aksTarget{
-		env:                    env,
-		managedClustersService: managedClustersService,
-		kubectl:                kubectlCli,
-		containerHelper:        containerHelper,
+		env:                      env,
+		azCli:                    azCli,
+		managedClustersService:   managedClustersService,
+		managedIdentityService:   managedIdentityService,
+		containerRegistryService: containerRegistryService,
+		kubectl:                  kubectlCli,
+		kubelogin:                kubeloginCli,
+		containerHelper:          containerHelper,
+		policyEngine:             policyEngine,
+		fs:                       fs,
+		clock:                    clock,
+		waits:                    waits,
 	}
 }
 
@@ -124,46 +198,28 @@ func (t *aksTarget) Deploy(
 			}
 
 			// Login to AKS cluster
-			clusterName, has := t.env.LookupEnv(environment.AksClusterEnvVarName)
-			if !has {
-				task.SetError(fmt.Errorf(
-					"could not determine AKS cluster, ensure %s is set as an output of your infrastructure",
-					environment.AksClusterEnvVarName,
-				))
-				return
-			}
-
-			log.Printf("getting AKS credentials for cluster '%s'\n", clusterName)
-			task.SetProgress(NewServiceProgress("Getting AKS credentials"))
-			clusterCreds, err := t.managedClustersService.GetAdminCredentials(
-				ctx,
-				targetResource.SubscriptionId(),
-				targetResource.ResourceGroupName(),
-				clusterName,
-			)
+			err := t.loginCluster(ctx, targetResource, func(message string) {
+				task.SetProgress(NewServiceProgress(message))
+			})
 			if err != nil {
-				task.SetError(fmt.Errorf(
-					"failed retrieving cluster admin credentials. Ensure your cluster has been configured to support admin credentials, %w",
-					err,
-				))
-				return
-			}
-
-			if len(clusterCreds.Kubeconfigs) == 0 {
-				task.SetError(fmt.Errorf(
-					"cluster credentials is empty. Ensure your cluster has been configured to support admin credentials. , %w",
-					err,
-				))
+				task.SetError(err)
 				return
 			}
 
-			// The kubeConfig that we care about will also be at position 0
-			// I don't know if there is a valid use case where this credential results would container multiple configs
-			task.SetProgress(NewServiceProgress("Configuring k8s config context"))
-			err = t.configureK8sContext(ctx, clusterName, clusterCreds.Kubeconfigs[0])
-			if err != nil {
-				task.SetError(err)
-				return
+			// Pods fail pulling the image when the kubelet identity of the cluster can't pull from the registry
+			usePullSecret := serviceConfig.K8s.ImagePullSecret != nil
+			if !usePullSecret {
+				task.SetProgress(NewServiceProgress("Validating registry access of cluster"))
+				err := t.ensureAcrPull(ctx, serviceConfig, targetResource)
+				if errors.Is(err, azcli.ErrContainerRegistryNotFound) {
+					// Registries of other tenants can't be attached to the cluster, their images are pulled with
+					// an image pull secret instead
+					log.Printf("pulling images with an image pull secret: %v", err)
+					usePullSecret = true
+				} else if err != nil {
+					task.SetError(fmt.Errorf("failed validating registry access of cluster: %w", err))
+					return
+				}
 			}
 
 			// Login, tag & push container image to ACR
@@ -220,16 +276,60 @@ func (t *aksTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Applying k8s manifests"))
-			t.kubectl.SetEnv(t.env.Dotenv())
+			var pullSecretName string
+			if usePullSecret {
+				task.SetProgress(NewServiceProgress("Creating k8s image pull secret"))
+				pullSecretName, err = t.configureImagePullSecret(ctx, serviceConfig, targetResource, namespace)
+				if err != nil {
+					task.SetError(fmt.Errorf("failed configuring image pull secret: %w", err))
+					return
+				}
+			}
+
+			if serviceConfig.K8s.WorkloadIdentity != nil {
+				task.SetProgress(NewServiceProgress("Configuring workload identity"))
+				if err := t.configureWorkloadIdentity(ctx, serviceConfig, targetResource, namespace); err != nil {
+					task.SetError(fmt.Errorf("failed configuring workload identity: %w", err))
+					return
+				}
+			}
+
+			var tlsSecretName string
+			if serviceConfig.K8s.Ingress.Tls != nil {
+				task.SetProgress(NewServiceProgress("Configuring ingress certificate"))
+				tlsSecretName, err = t.configureIngressTls(ctx, serviceConfig, targetResource, namespace)
+				if err != nil {
+					task.SetError(fmt.Errorf("failed configuring ingress certificate: %w", err))
+					return
+				}
+			}
+
 			deploymentPath := serviceConfig.K8s.DeploymentPath
 			if deploymentPath == "" {
 				deploymentPath = defaultDeploymentPath
 			}
+			manifestsPath := filepath.Join(serviceConfig.RelativePath, deploymentPath)
+
+			if serviceConfig.Project.Policy != nil && serviceConfig.Project.Policy.Enabled {
+				task.SetProgress(NewServiceProgress("Evaluating k8s manifest policies"))
+				if err := t.checkManifestPolicies(ctx, serviceConfig, manifestsPath); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			task.SetProgress(NewServiceProgress("Checking cluster compatibility"))
+			if err := t.checkClusterCompatibility(ctx, targetResource, manifestsPath); err != nil {
+				task.SetError(err)
+				return
+			}
 
+			task.SetProgress(NewServiceProgress("Applying k8s manifests"))
+			t.kubectl.SetEnv(t.env.Dotenv())
 			err = t.kubectl.Apply(
 				ctx,
-				filepath.Join(serviceConfig.RelativePath, deploymentPath),
+				t.fs,
+				manifestsPath,
 				&kubectl.KubeCliFlags{Namespace: namespace},
 			)
 			if err != nil {
@@ -237,6 +337,22 @@ func (t *aksTarget) Deploy(
 				return
 			}
 
+			if pullSecretName != "" {
+				task.SetProgress(NewServiceProgress("Adding image pull secret to deployment"))
+				if err := t.addImagePullSecret(ctx, serviceConfig, namespace, pullSecretName); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			if tlsSecretName != "" {
+				task.SetProgress(NewServiceProgress("Configuring ingress TLS"))
+				if err := t.wireIngressTls(ctx, serviceConfig, namespace, tlsSecretName); err != nil {
+					task.SetError(fmt.Errorf("failed configuring ingress TLS: %w", err))
+					return
+				}
+			}
+
 			deploymentName := serviceConfig.K8s.Deployment.Name
 			if deploymentName == "" {
 				deploymentName = serviceConfig.Name
@@ -247,12 +363,13 @@ func (t *aksTarget) Deploy(
 			task.SetProgress(NewServiceProgress("Verifying deployment"))
 			deployment, err := t.waitForDeployment(ctx, namespace, deploymentName)
 			if err != nil && !errors.Is(err, kubectl.ErrResourceNotFound) {
-				task.SetError(err)
+				task.SetProgress(NewServiceProgress("Gathering state of deployment"))
+				task.SetError(t.deploymentError(ctx, serviceConfig, namespace, deploymentName, err))
 				return
 			}
 
 			task.SetProgress(NewServiceProgress("Fetching endpoints for AKS service"))
-			endpoints, err := t.Endpoints(ctx, serviceConfig, targetResource)
+			endpoints, err := t.endpoints(ctx, serviceConfig)
 			if err != nil {
 				task.SetError(err)
 				return
@@ -290,6 +407,31 @@ func (t *aksTarget) Endpoints(
 	serviceConfig *ServiceConfig,
 	targetResource *environment.TargetResource,
 ) ([]string, error) {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return nil, fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return nil, err
+	}
+
+	return t.endpoints(ctx, serviceConfig)
+}
+
+// Gets the service endpoints from the resources of the cluster, which the current k8s context is set to
+func (t *aksTarget) endpoints(ctx context.Context, serviceConfig *ServiceConfig) ([]string, error) {
+	// The public endpoint declared in azure.yaml is used instead of discovering the endpoints
+	endpoint, err := serviceConfig.K8s.Endpoint.Envsubst(t.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("expanding endpoint: %w", err)
+	}
+
+	if endpoint != "" {
+		return []string{fmt.Sprintf("%s, (Endpoint, Type: Configured)", endpoint)}, nil
+	}
+
 	namespace := t.getK8sNamespace(serviceConfig)
 
 	serviceName := serviceConfig.K8s.Service.Name
@@ -297,7 +439,7 @@ func (t *aksTarget) Endpoints(
 		serviceName = serviceConfig.Name
 	}
 
-	ingressName := serviceConfig.K8s.Service.Name
+	ingressName := serviceConfig.K8s.Ingress.Name
 	if ingressName == "" {
 		ingressName = serviceConfig.Name
 	}
@@ -316,11 +458,188 @@ func (t *aksTarget) Endpoints(
 		return nil, fmt.Errorf("failed retrieving ingress endpoints, %w", err)
 	}
 
+	// Find endpoints for any matching Gateway API routes, which are publicly accessible through their gateway
+	routeEndpoints, err := t.getHttpRouteEndpoints(ctx, serviceConfig, namespace, ingressName)
+	if err != nil && !errors.Is(err, kubectl.ErrResourceNotFound) {
+		return nil, fmt.Errorf("failed retrieving http route endpoints, %w", err)
+	}
+
 	endpoints := append(serviceEndpoints, ingressEndpoints...)
+	endpoints = append(endpoints, routeEndpoints...)
 
 	return endpoints, nil
 }
 
+// Evaluates the rendered k8s manifests against the project policies before they are applied to the cluster
+func (t *aksTarget) checkManifestPolicies(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	manifestsPath string,
+) error {
+	manifests, err := kubectl.ReadManifests(t.fs, manifestsPath, t.env.Dotenv())
+	if err != nil {
+		return fmt.Errorf("reading k8s manifests: %w", err)
+	}
+
+	err = t.policyEngine.Check(ctx, serviceConfig.Project.Path, serviceConfig.Project.Policy, policy.KindK8s, map[string]any{
+		"service":   serviceConfig.Name,
+		"manifests": manifests,
+	})
+	if err != nil {
+		return fmt.Errorf("policy check failed for service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return nil
+}
+
+// Checks the manifests can be applied to the cluster: the API versions and kinds of the manifests are served by the
+// cluster, which requires the CRDs of custom kinds to be installed, and the ingress classes of the ingresses exist.
+// All the incompatibilities found are returned in a single error.
+func (t *aksTarget) checkClusterCompatibility(
+	ctx context.Context,
+	targetResource *environment.TargetResource,
+	manifestsPath string,
+) error {
+	manifests, err := kubectl.ReadManifests(t.fs, manifestsPath, t.env.Dotenv())
+	if err != nil {
+		return fmt.Errorf("reading k8s manifests: %w", err)
+	}
+
+	if len(manifests) == 0 {
+		return nil
+	}
+
+	apiVersions, err := t.kubectl.GetApiVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	serverVersion, err := t.kubectl.GetServerVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+	apiKinds := map[string][]string{}
+	ingressClassNames := []string{}
+
+	for _, manifest := range manifests {
+		apiVersion, _ := manifest["apiVersion"].(string)
+		kind, _ := manifest["kind"].(string)
+		if apiVersion == "" || kind == "" {
+			continue
+		}
+
+		resource := fmt.Sprintf("%s '%s'", kind, manifestName(manifest))
+
+		if !slices.Contains(apiVersions, apiVersion) {
+			// Other versions of the API group are served when the API version was deprecated, or isn't available yet
+			group, _, hasGroup := strings.Cut(apiVersion, "/")
+			servedVersions := []string{}
+			for _, served := range apiVersions {
+				if hasGroup && strings.HasPrefix(served, group+"/") {
+					servedVersions = append(servedVersions, served)
+				}
+			}
+
+			switch {
+			case len(servedVersions) > 0:
+				problems = append(problems, fmt.Sprintf(
+					"%s uses API version '%s', which Kubernetes %s of the cluster doesn't serve. Served versions: %s",
+					resource, apiVersion, serverVersion, strings.Join(servedVersions, ", ")))
+			case isBuiltInApiGroup(apiVersion):
+				problems = append(problems, fmt.Sprintf(
+					"%s uses API version '%s', which Kubernetes %s of the cluster doesn't serve",
+					resource, apiVersion, serverVersion))
+			default:
+				problems = append(problems, fmt.Sprintf(
+					"%s uses API version '%s', which isn't installed on the cluster. Install the CRDs of '%s'",
+					resource, apiVersion, group))
+			}
+
+			continue
+		}
+
+		kinds, has := apiKinds[apiVersion]
+		if !has {
+			kinds, err = t.kubectl.GetApiKinds(ctx, apiVersion)
+			if err != nil {
+				return err
+			}
+			apiKinds[apiVersion] = kinds
+		}
+
+		if !slices.Contains(kinds, kind) {
+			problems = append(problems, fmt.Sprintf(
+				"%s uses kind '%s', which isn't served by API version '%s' of the cluster. Install the CRD of the kind",
+				resource, kind, apiVersion))
+			continue
+		}
+
+		if kind == "Ingress" {
+			spec, _ := manifest["spec"].(map[string]any)
+			if ingressClassName, _ := spec["ingressClassName"].(string); ingressClassName != "" &&
+				!slices.Contains(ingressClassNames, ingressClassName) {
+				ingressClassNames = append(ingressClassNames, ingressClassName)
+			}
+		}
+	}
+
+	if len(ingressClassNames) > 0 {
+		ingressClasses, err := kubectl.GetResources[kubectl.Resource](
+			ctx, t.kubectl, kubectl.ResourceTypeIngressClass, nil)
+		if err != nil {
+			return fmt.Errorf("getting ingress classes: %w", err)
+		}
+
+		for _, ingressClassName := range ingressClassNames {
+			if slices.ContainsFunc(ingressClasses.Items, func(ingressClass kubectl.Resource) bool {
+				return ingressClass.Metadata.Name == ingressClassName
+			}) {
+				continue
+			}
+
+			if ingressClassName == webAppRoutingIngressClass {
+				problems = append(problems, fmt.Sprintf(
+					"ingress class '%s' doesn't exist, the Web App Routing add-on of AKS cluster '%s' isn't enabled. "+
+						"Enable it with `az aks approuting enable --name %s --resource-group %s`",
+					ingressClassName,
+					targetResource.ResourceName(),
+					targetResource.ResourceName(),
+					targetResource.ResourceGroupName()))
+			} else {
+				problems = append(problems, fmt.Sprintf(
+					"ingress class '%s' doesn't exist on the cluster. Install its ingress controller", ingressClassName))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"the k8s manifests are not compatible with AKS cluster '%s':\n  - %s",
+			targetResource.ResourceName(),
+			strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+// Returns the name of the resource of the manifest
+func manifestName(manifest map[string]any) string {
+	metadata, _ := manifest["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+
+	return name
+}
+
+// Returns true when the API version belongs to an API group built into Kubernetes, ex) apps/v1 or
+// networking.k8s.io/v1, rather than to a group of custom resources
+func isBuiltInApiGroup(apiVersion string) bool {
+	group, _, hasGroup := strings.Cut(apiVersion, "/")
+
+	return !hasGroup || !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
+
 func (t *aksTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -333,6 +652,320 @@ func (t *aksTarget) validateTargetResource(
 	return nil
 }
 
+// Runs the command in a container of the deployment of the service
+func (t *aksTarget) Exec(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ExecOptions,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	workload, namespace, err := t.serviceDeployment(ctx, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	return t.kubectl.ExecInContainer(
+		ctx,
+		workload,
+		options.Command,
+		options.Tty,
+		&kubectl.KubeCliFlags{Namespace: namespace},
+	)
+}
+
+// Writes the logs of the containers of the deployment of the service
+func (t *aksTarget) Logs(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	workload, namespace, err := t.serviceDeployment(ctx, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	return t.kubectl.Logs(
+		ctx,
+		workload,
+		kubectl.LogsOptions{Since: options.Since, Follow: options.Follow},
+		writer,
+		&kubectl.KubeCliFlags{Namespace: namespace},
+	)
+}
+
+// Restarts the pods of the deployment of the service with a rolling update
+func (t *aksTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	workload, namespace, err := t.serviceDeployment(ctx, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	return t.kubectl.RolloutRestart(ctx, workload, &kubectl.KubeCliFlags{Namespace: namespace})
+}
+
+// Sets the number of replicas of the deployment of the service
+func (t *aksTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	replicas int,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	workload, namespace, err := t.serviceDeployment(ctx, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	return t.kubectl.Scale(ctx, workload, replicas, &kubectl.KubeCliFlags{Namespace: namespace})
+}
+
+// Rolls the deployment of the service back to its previous revision when the interrupted deploy left its rollout
+// unfinished. The kubectl commands of the deploy are stopped when the deploy is cancelled, the manifests already applied
+// aren't reverted.
+func (t *aksTarget) CleanupDeploy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	// The deploy may have been interrupted before the credentials of the cluster were configured
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	deployment, namespace, err := t.findDeployment(ctx, serviceConfig)
+	if errors.Is(err, kubectl.ErrResourceNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if rolledOut(deployment) {
+		return nil
+	}
+
+	log.Printf("rolling back unfinished rollout of deployment '%s'", deployment.Metadata.Name)
+	return t.kubectl.RolloutUndo(
+		ctx,
+		fmt.Sprintf("deployment/%s", deployment.Metadata.Name),
+		&kubectl.KubeCliFlags{Namespace: namespace},
+	)
+}
+
+// rolledOut reports whether all the replicas of the deployment run its latest revision
+func rolledOut(deployment *kubectl.Deployment) bool {
+	return deployment.Status.UpdatedReplicas == deployment.Spec.Replicas &&
+		deployment.Status.Replicas == deployment.Spec.Replicas &&
+		deployment.Status.AvailableReplicas == deployment.Spec.Replicas
+}
+
+// Forwards a local port to the k8s service of the service, ex) to reach services of type ClusterIP, which aren't
+// accessible outside of the cluster
+func (t *aksTarget) Connect(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	ready func(endpoint string),
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginCluster(ctx, targetResource, func(message string) {
+		log.Println(message)
+	}); err != nil {
+		return err
+	}
+
+	namespace := t.getK8sNamespace(serviceConfig)
+	serviceName := serviceConfig.K8s.Service.Name
+	if serviceName == "" {
+		serviceName = serviceConfig.Name
+	}
+
+	services, err := kubectl.GetResources[kubectl.Service](
+		ctx, t.kubectl, kubectl.ResourceTypeService, &kubectl.KubeCliFlags{Namespace: namespace},
+	)
+	if err != nil {
+		return fmt.Errorf("failed getting services: %w", err)
+	}
+
+	// Services are matched the same way as when finding the endpoints of the service
+	index := slices.IndexFunc(services.Items, func(service kubectl.Service) bool {
+		return strings.Contains(service.Metadata.Name, serviceName)
+	})
+	if index == -1 {
+		return fmt.Errorf("no service matching '%s' found in namespace '%s'", serviceName, namespace)
+	}
+
+	service := services.Items[index]
+	if len(service.Spec.Ports) == 0 {
+		return fmt.Errorf("service '%s' has no ports", service.Metadata.Name)
+	}
+
+	return t.kubectl.PortForward(
+		ctx,
+		fmt.Sprintf("svc/%s", service.Metadata.Name),
+		0,
+		service.Spec.Ports[0].Port,
+		func(localPort int) {
+			ready(fmt.Sprintf("http://localhost:%d", localPort))
+		},
+		&kubectl.KubeCliFlags{Namespace: namespace},
+	)
+}
+
+// Returns the deployment of the service, ex) deployment/api, and its namespace
+func (t *aksTarget) serviceDeployment(ctx context.Context, serviceConfig *ServiceConfig) (string, string, error) {
+	deployment, namespace, err := t.findDeployment(ctx, serviceConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	return fmt.Sprintf("deployment/%s", deployment.Metadata.Name), namespace, nil
+}
+
+// findDeployment returns the k8s deployment of the service and its namespace, or an error wrapping
+// kubectl.ErrResourceNotFound when the namespace has no deployment of the service
+func (t *aksTarget) findDeployment(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (*kubectl.Deployment, string, error) {
+	namespace := t.getK8sNamespace(serviceConfig)
+	deploymentName := serviceConfig.K8s.Deployment.Name
+	if deploymentName == "" {
+		deploymentName = serviceConfig.Name
+	}
+
+	deployments, err := kubectl.GetResources[kubectl.Deployment](
+		ctx, t.kubectl, kubectl.ResourceTypeDeployment, &kubectl.KubeCliFlags{Namespace: namespace},
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed getting deployments: %w", err)
+	}
+
+	for _, deployment := range deployments.Items {
+		// Deployments are matched the same way as when deploying the service
+		if strings.Contains(deployment.Metadata.Name, deploymentName) {
+			return &deployment, namespace, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf(
+		"no deployment matching '%s' found in namespace '%s': %w", deploymentName, namespace, kubectl.ErrResourceNotFound)
+}
+
+// Gets the admin credentials of the AKS cluster of the environment and configures them as the current k8s context
+func (t *aksTarget) loginCluster(
+	ctx context.Context,
+	targetResource *environment.TargetResource,
+	progress func(message string),
+) error {
+	clusterName, has := t.env.LookupEnv(environment.AksClusterEnvVarName)
+	if !has {
+		return fmt.Errorf(
+			"could not determine AKS cluster, ensure %s is set as an output of your infrastructure",
+			environment.AksClusterEnvVarName,
+		)
+	}
+
+	log.Printf("getting AKS credentials for cluster '%s'\n", clusterName)
+	progress("Getting AKS credentials")
+	clusterCreds, err := t.managedClustersService.GetAdminCredentials(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		clusterName,
+	)
+	if err != nil {
+		// Clusters with local accounts disabled have no admin credentials, their users authenticate with Microsoft
+		// Entra ID instead
+		disabled, disabledErr := t.managedClustersService.GetLocalAccountsDisabled(
+			ctx, targetResource.SubscriptionId(), targetResource.ResourceGroupName(), clusterName)
+		if disabledErr != nil {
+			log.Printf("failed getting whether local accounts of cluster '%s' are disabled: %v", clusterName, disabledErr)
+		}
+
+		if disabled {
+			log.Printf("local accounts of cluster '%s' are disabled, getting user credentials", clusterName)
+			clusterCreds, err = t.managedClustersService.GetUserCredentials(
+				ctx, targetResource.SubscriptionId(), targetResource.ResourceGroupName(), clusterName)
+			if err != nil {
+				return fmt.Errorf("failed retrieving cluster user credentials, %w", err)
+			}
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf(
+			"failed retrieving cluster admin credentials. Ensure your cluster has been configured to support admin credentials, %w",
+			err,
+		)
+	}
+
+	if len(clusterCreds.Kubeconfigs) == 0 {
+		return fmt.Errorf(
+			"cluster credentials is empty. Ensure your cluster has been configured to support admin credentials. , %w",
+			err,
+		)
+	}
+
+	// The kubeConfig that we care about will also be at position 0
+	// I don't know if there is a valid use case where this credential results would container multiple configs
+	progress("Configuring k8s config context")
+	return t.configureK8sContext(ctx, clusterName, clusterCreds.Kubeconfigs[0])
+}
+
 func (t *aksTarget) configureK8sContext(
 	ctx context.Context,
 	clusterName string,
@@ -358,6 +991,19 @@ func (t *aksTarget) configureK8sContext(
 		)
 	}
 
+	// The users of clusters with Microsoft Entra ID authentication get their tokens with kubelogin, which prompts
+	// for a device code login by default. They are converted to get the tokens of the principal signed in to azd.
+	if len(kubeConfig.ExecPluginUsers(kubelogin.Command)) > 0 {
+		if err := tools.EnsureInstalled(ctx, t.kubelogin); err != nil {
+			return err
+		}
+
+		err := t.kubelogin.ConvertKubeConfig(ctx, kubeConfigManager.ConfigPath(clusterName), kubelogin.LoginModeAzd)
+		if err != nil {
+			return fmt.Errorf("failed converting kube config of cluster '%s' to azd login: %w", clusterName, err)
+		}
+	}
+
 	if err := kubeConfigManager.MergeConfigs(ctx, "config", "config", clusterName); err != nil {
 		return fmt.Errorf(
 			"failed merging kube configs. Verify your k8s configuration is valid. %w",
@@ -386,7 +1032,7 @@ func (t *aksTarget) waitForDeployment(
 	// The deployment can appear like it has succeeded when a previous deployment
 	// was already in place.
 	deployment, err := kubectl.WaitForResource(
-		ctx, t.kubectl, namespace, kubectl.ResourceTypeDeployment,
+		ctx, t.kubectl, t.clock, t.waits.Aks, namespace, kubectl.ResourceTypeDeployment,
 		func(deployment *kubectl.Deployment) bool {
 			return strings.Contains(deployment.Metadata.Name, deploymentNameFilter)
 		},
@@ -419,13 +1065,13 @@ func (t *aksTarget) waitForIngress(
 	ingressNameFilter string,
 ) (*kubectl.Ingress, error) {
 	return kubectl.WaitForResource(
-		ctx, t.kubectl, namespace, kubectl.ResourceTypeIngress,
+		ctx, t.kubectl, t.clock, t.waits.Aks, namespace, kubectl.ResourceTypeIngress,
 		func(ingress *kubectl.Ingress) bool {
 			return strings.Contains(ingress.Metadata.Name, ingressNameFilter)
 		},
 		func(ingress *kubectl.Ingress) bool {
 			for _, config := range ingress.Status.LoadBalancer.Ingress {
-				if config.Ip != "" {
+				if config.Address() != "" {
 					return true
 				}
 			}
@@ -443,7 +1089,7 @@ func (t *aksTarget) waitForService(
 	serviceNameFilter string,
 ) (*kubectl.Service, error) {
 	return kubectl.WaitForResource(
-		ctx, t.kubectl, namespace, kubectl.ResourceTypeService,
+		ctx, t.kubectl, t.clock, t.waits.Aks, namespace, kubectl.ResourceTypeService,
 		func(service *kubectl.Service) bool {
 			return strings.Contains(service.Metadata.Name, serviceNameFilter)
 		},
@@ -454,15 +1100,13 @@ func (t *aksTarget) waitForService(
 			}
 
 			// Load balancer can take some time to be provision by AKS
-			var ipAddress string
 			for _, config := range service.Status.LoadBalancer.Ingress {
-				if config.Ip != "" {
-					ipAddress = config.Ip
-					break
+				if config.Address() != "" {
+					return true
 				}
 			}
 
-			return ipAddress != ""
+			return false
 		},
 	)
 }
@@ -483,7 +1127,7 @@ func (t *aksTarget) getServiceEndpoints(
 	var endpoints []string
 	if service.Spec.Type == kubectl.ServiceTypeLoadBalancer {
 		for _, resource := range service.Status.LoadBalancer.Ingress {
-			endpoints = append(endpoints, fmt.Sprintf("http://%s, (Service, Type: LoadBalancer)", resource.Ip))
+			endpoints = append(endpoints, fmt.Sprintf("http://%s, (Service, Type: LoadBalancer)", resource.Address()))
 		}
 	} else if service.Spec.Type == kubectl.ServiceTypeClusterIp {
 		for index, ip := range service.Spec.ClusterIps {
@@ -507,33 +1151,387 @@ func (t *aksTarget) getIngressEndpoints(
 		return nil, err
 	}
 
-	var endpoints []string
-	var protocol string
-	if len(ingress.Spec.Tls) == 0 {
-		protocol = "http"
-	} else {
+	return ingressEndpoints(ingress, serviceConfig.K8s.Ingress.RelativePath)
+}
+
+// Returns the endpoints of the ingress: the hosts of its rules, or the addresses of its load balancer for rules
+// without hosts, joined with the paths of the rules. The relative path, when set, is used instead of the paths.
+func ingressEndpoints(ingress *kubectl.Ingress, relativePath string) ([]string, error) {
+	protocol := "http"
+	if len(ingress.Spec.Tls) > 0 {
 		protocol = "https"
 	}
 
-	for index, resource := range ingress.Status.LoadBalancer.Ingress {
-		var baseUrl string
-		if ingress.Spec.Rules[index].Host == nil {
-			baseUrl = fmt.Sprintf("%s://%s", protocol, resource.Ip)
+	hosts := []string{}
+	paths := map[string][]string{}
+	for _, rule := range ingress.Spec.Rules {
+		ruleHosts := []string{}
+		if rule.Host != nil && *rule.Host != "" {
+			ruleHosts = append(ruleHosts, *rule.Host)
 		} else {
-			baseUrl = fmt.Sprintf("%s://%s", *ingress.Spec.Rules[index].Host, resource.Ip)
+			for _, resource := range ingress.Status.LoadBalancer.Ingress {
+				ruleHosts = append(ruleHosts, resource.Address())
+			}
 		}
 
-		endpointUrl, err := url.JoinPath(baseUrl, serviceConfig.K8s.Ingress.RelativePath)
+		for _, host := range ruleHosts {
+			if !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+
+			if relativePath != "" {
+				continue
+			}
+
+			for _, ingressPath := range rule.Http.Paths {
+				if !slices.Contains(paths[host], ingressPath.Path) {
+					paths[host] = append(paths[host], ingressPath.Path)
+				}
+			}
+		}
+	}
+
+	var endpoints []string
+	for _, host := range hosts {
+		hostPaths := paths[host]
+		if len(hostPaths) == 0 {
+			hostPaths = []string{relativePath}
+		}
+
+		for _, hostPath := range hostPaths {
+			endpointUrl, err := url.JoinPath(fmt.Sprintf("%s://%s", protocol, host), hostPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed constructing service endpoints, %w", err)
+			}
+
+			endpoints = append(endpoints, fmt.Sprintf("%s, (Ingress, Type: LoadBalancer)", endpointUrl))
+		}
+	}
+
+	return endpoints, nil
+}
+
+// Retrieve any Gateway API HTTPRoute endpoints for the specified namespace and resourceFilter. Clusters without the
+// Gateway API have no routes.
+func (t *aksTarget) getHttpRouteEndpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	namespace string,
+	resourceFilter string,
+) ([]string, error) {
+	apiVersions, err := t.kubectl.GetApiVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(apiVersions, func(apiVersion string) bool {
+		return strings.HasPrefix(apiVersion, kubectl.GatewayApiGroup+"/")
+	}) {
+		return nil, nil
+	}
+
+	routes, err := kubectl.GetResources[*kubectl.HttpRoute](
+		ctx, t.kubectl, kubectl.ResourceTypeHttpRoute, &kubectl.KubeCliFlags{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+
+	index := slices.IndexFunc(routes.Items, func(route *kubectl.HttpRoute) bool {
+		return strings.Contains(route.Metadata.Name, resourceFilter)
+	})
+	if index == -1 {
+		return nil, nil
+	}
+	route := routes.Items[index]
+
+	var endpoints []string
+	for _, parentRef := range route.Spec.ParentRefs {
+		gatewayNamespace := parentRef.Namespace
+		if gatewayNamespace == "" {
+			gatewayNamespace = namespace
+		}
+
+		// Gateways can take some time to be assigned an address
+		gateway, err := kubectl.WaitForResource(
+			ctx, t.kubectl, t.clock, t.waits.Aks, gatewayNamespace, kubectl.ResourceTypeGateway,
+			func(gateway *kubectl.Gateway) bool {
+				return gateway.Metadata.Name == parentRef.Name
+			},
+			func(gateway *kubectl.Gateway) bool {
+				return len(gateway.Status.Addresses) > 0
+			},
+		)
 		if err != nil {
-			return nil, fmt.Errorf("failed constructing service endpoints, %w", err)
+			return nil, err
 		}
 
-		endpoints = append(endpoints, fmt.Sprintf("%s, (Ingress, Type: LoadBalancer)", endpointUrl))
+		gatewayEndpoints, err := httpRouteEndpoints(route, gateway, serviceConfig.K8s.Ingress.RelativePath)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, gatewayEndpoints...)
 	}
 
 	return endpoints, nil
 }
 
+// Returns the endpoints of the HTTPRoute through the gateway: the hostnames of the route, or of the listeners of the
+// gateway, or else the addresses of the gateway, joined with the path matches of the route. The relative path, when
+// set, is used instead of the path matches.
+func httpRouteEndpoints(route *kubectl.HttpRoute, gateway *kubectl.Gateway, relativePath string) ([]string, error) {
+	protocol := "http"
+	hostnames := route.Spec.Hostnames
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Protocol == "HTTPS" {
+			protocol = "https"
+		}
+
+		if len(route.Spec.Hostnames) == 0 && listener.Hostname != nil && !strings.HasPrefix(*listener.Hostname, "*") &&
+			!slices.Contains(hostnames, *listener.Hostname) {
+			hostnames = append(hostnames, *listener.Hostname)
+		}
+	}
+
+	if len(hostnames) == 0 {
+		for _, address := range gateway.Status.Addresses {
+			hostnames = append(hostnames, address.Value)
+		}
+	}
+
+	paths := []string{}
+	if relativePath != "" {
+		paths = append(paths, relativePath)
+	} else {
+		for _, rule := range route.Spec.Rules {
+			for _, match := range rule.Matches {
+				if match.Path != nil && !slices.Contains(paths, match.Path.Value) {
+					paths = append(paths, match.Path.Value)
+				}
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		paths = append(paths, "")
+	}
+
+	var endpoints []string
+	for _, hostname := range hostnames {
+		for _, routePath := range paths {
+			endpointUrl, err := url.JoinPath(fmt.Sprintf("%s://%s", protocol, hostname), routePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed constructing service endpoints, %w", err)
+			}
+
+			endpoints = append(endpoints, fmt.Sprintf("%s, (HTTPRoute, Type: Gateway)", endpointUrl))
+		}
+	}
+
+	return endpoints, nil
+}
+
+// Federates the service account of the service with its user assigned managed identity, and applies the service account
+// annotated with the client id of the identity. The client id is also set as the IDENTITY_CLIENT_ID service property,
+// available to the manifests of the service.
+func (t *aksTarget) configureWorkloadIdentity(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	namespace string,
+) error {
+	identityId, err := serviceConfig.K8s.WorkloadIdentity.IdentityId.Envsubst(t.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("expanding identity id: %w", err)
+	}
+
+	if strings.TrimSpace(identityId) == "" {
+		return fmt.Errorf("the identity id of service '%s' is empty, set 'k8s.workloadIdentity.identityId'",
+			serviceConfig.Name)
+	}
+
+	serviceAccount := serviceConfig.K8s.WorkloadIdentity.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = serviceConfig.Name
+	}
+
+	issuer, err := t.managedClustersService.GetOidcIssuerUrl(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+	if err != nil {
+		return fmt.Errorf("getting OIDC issuer of cluster: %w", err)
+	}
+
+	if issuer == "" {
+		return fmt.Errorf(
+			"the OIDC issuer of AKS cluster '%s' is not enabled, which workload identity requires",
+			targetResource.ResourceName())
+	}
+
+	credentialName := invalidFederatedCredentialNameChars.ReplaceAllString(
+		fmt.Sprintf("azd-%s-%s-%s", targetResource.ResourceName(), namespace, serviceAccount), "-")
+	if len(credentialName) > 120 {
+		credentialName = credentialName[:120]
+	}
+
+	err = t.managedIdentityService.CreateOrUpdateFederatedCredential(
+		ctx,
+		targetResource.SubscriptionId(),
+		identityId,
+		credentialName,
+		issuer,
+		fmt.Sprintf(workloadIdentitySubject, namespace, serviceAccount),
+	)
+	if err != nil {
+		return err
+	}
+
+	clientId, err := t.managedIdentityService.GetClientId(ctx, targetResource.SubscriptionId(), identityId)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.kubectl.ApplyWithInput(ctx, workloadIdentityServiceAccount(namespace, serviceAccount, clientId), nil)
+	if err != nil {
+		return fmt.Errorf("failed applying service account: %w", err)
+	}
+
+	t.env.SetServiceProperty(serviceConfig.Name, "IDENTITY_CLIENT_ID", clientId)
+	if err := t.env.Save(); err != nil {
+		return fmt.Errorf("failed updating environment with identity client id, %w", err)
+	}
+
+	return nil
+}
+
+// Ensures the kubelet identity of the cluster can pull images from the container registry of the service, by
+// assigning it the AcrPull role on the registry when it has no role allowing it yet. Clusters without a kubelet
+// managed identity are not validated.
+func (t *aksTarget) ensureAcrPull(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	loginServer, err := t.containerHelper.RegistryName(ctx, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	principalId, err := t.managedClustersService.GetKubeletIdentityObjectId(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+	if err != nil {
+		return fmt.Errorf("getting kubelet identity of cluster: %w", err)
+	}
+
+	if principalId == "" {
+		log.Printf("AKS cluster '%s' has no kubelet identity, skipping registry access validation",
+			targetResource.ResourceName())
+		return nil
+	}
+
+	registry, err := t.containerRegistryService.FindContainerRegistry(
+		ctx, targetResource.SubscriptionId(), loginServer)
+	if err != nil {
+		return err
+	}
+	registryId := *registry.ID
+
+	roleAssignments, err := t.azCli.ListRoleAssignments(ctx, targetResource.SubscriptionId(), registryId, principalId)
+	if err != nil {
+		return fmt.Errorf("listing role assignments of kubelet identity: %w", err)
+	}
+
+	if hasAcrPull(roleAssignments, registryId) {
+		return nil
+	}
+
+	acrPullRoleId := acrPullRoleIds[0]
+	roleAssignmentName := uuid.NewSHA1(
+		uuid.NameSpaceURL, []byte(strings.ToLower(registryId+"|"+principalId+"|"+acrPullRoleId))).String()
+
+	err = t.azCli.CreateRoleAssignment(
+		ctx,
+		targetResource.SubscriptionId(),
+		registryId,
+		roleAssignmentName,
+		&armauthorization.RoleAssignmentProperties{
+			PrincipalID: &principalId,
+			RoleDefinitionID: convert.RefOf(fmt.Sprintf(
+				"/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s",
+				targetResource.SubscriptionId(),
+				acrPullRoleId,
+			)),
+		},
+	)
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) && responseError.StatusCode == http.StatusForbidden {
+		return fmt.Errorf(
+			"the kubelet identity of AKS cluster '%s' can't pull images from container registry '%s', and "+
+				"you aren't permitted to assign it the AcrPull role. Ask an owner of the registry to run "+
+				"`az aks update --name %s --resource-group %s --attach-acr %s`: %w",
+			targetResource.ResourceName(),
+			loginServer,
+			targetResource.ResourceName(),
+			targetResource.ResourceGroupName(),
+			registryId,
+			err,
+		)
+	} else if err != nil {
+		return fmt.Errorf("assigning AcrPull role to kubelet identity: %w", err)
+	}
+
+	return nil
+}
+
+// Returns true when one of the role assignments allows pulling images from the registry, at the registry or above
+func hasAcrPull(roleAssignments []*armauthorization.RoleAssignment, registryId string) bool {
+	return hasRoleAssignment(roleAssignments, registryId, acrPullRoleIds)
+}
+
+// Returns true when one of the role assignments assigns one of the roles, at the resource or above
+func hasRoleAssignment(roleAssignments []*armauthorization.RoleAssignment, resourceId string, roleIds []string) bool {
+	target := strings.ToLower(resourceId)
+	for _, assignment := range roleAssignments {
+		if assignment.Properties == nil ||
+			assignment.Properties.RoleDefinitionID == nil ||
+			assignment.Properties.Scope == nil {
+			continue
+		}
+
+		if !slices.Contains(roleIds, strings.ToLower(path.Base(*assignment.Properties.RoleDefinitionID))) {
+			continue
+		}
+
+		scope := strings.ToLower(strings.TrimSuffix(*assignment.Properties.Scope, "/"))
+		if scope == "" || scope == target || strings.HasPrefix(target, scope+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the manifest of the service account annotated with the client id of its workload identity
+func workloadIdentityServiceAccount(namespace string, name string, clientId string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    azure.workload.identity/use: "true"
+  annotations:
+    azure.workload.identity/client-id: %s
+`, name, namespace, clientId)
+}
+
 func (t *aksTarget) getK8sNamespace(serviceConfig *ServiceConfig) string {
 	namespace := serviceConfig.K8s.Namespace
 	if namespace == "" {
@@ -0,0 +1,175 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+)
+
+// The number of the last log lines of the crashing containers gathered when a rollout fails
+const aksDiagnosticsLogLines = 100
+
+// The number of the most recent events of the namespace gathered when a rollout fails
+const aksDiagnosticsEvents = 50
+
+// The directory of the environment the diagnostics of the failed rollouts are saved to
+const aksDiagnosticsDirectory = "diagnostics"
+
+// AksDeploymentError is returned when the rollout of the deployment of a service fails or times out. It bundles the
+// state of the deployment, of its pods and the events of the namespace gathered once the rollout failed, for the failure
+// not to have to be reproduced to understand it.
+type AksDeploymentError struct {
+	Err error
+	// The output of kubectl describe of the deployment and its pods, the events and the logs of the crashing containers
+	Diagnostics string
+	// The file the diagnostics are saved to, empty when they aren't saved
+	DiagnosticsPath string
+}
+
+func (e *AksDeploymentError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Err.Error())
+
+	if e.Diagnostics != "" {
+		sb.WriteString("\n\nState of the deployment:\n\n")
+		sb.WriteString(e.Diagnostics)
+	}
+
+	if e.DiagnosticsPath != "" {
+		fmt.Fprintf(&sb, "\nThe state of the deployment is saved to %s", e.DiagnosticsPath)
+	}
+
+	return sb.String()
+}
+
+func (e *AksDeploymentError) Unwrap() error {
+	return e.Err
+}
+
+// deploymentError returns the error of the failed rollout of the deployment with its diagnostics, which are saved to the
+// environment
+func (t *aksTarget) deploymentError(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	namespace string,
+	deploymentName string,
+	err error,
+) error {
+	// kubectl can't run once the command is cancelled
+	if ctx.Err() != nil {
+		return err
+	}
+
+	deploymentErr := &AksDeploymentError{
+		Err:         err,
+		Diagnostics: t.deploymentDiagnostics(ctx, namespace, deploymentName),
+	}
+
+	if t.env.Root != "" {
+		path := filepath.Join(
+			t.env.Root,
+			aksDiagnosticsDirectory,
+			fmt.Sprintf("%s-%s.log", serviceConfig.Name, t.clock.Now().UTC().Format("20060102T150405Z")),
+		)
+
+		if err := t.saveDiagnostics(path, deploymentErr.Diagnostics); err != nil {
+			log.Printf("failed saving the diagnostics of deployment '%s': %v", deploymentName, err)
+		} else {
+			deploymentErr.DiagnosticsPath = path
+		}
+	}
+
+	return deploymentErr
+}
+
+func (t *aksTarget) saveDiagnostics(path string, diagnostics string) error {
+	if err := t.fs.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	return t.fs.WriteFile(path, []byte(diagnostics), osutil.PermissionFile)
+}
+
+// deploymentDiagnostics gathers kubectl describe of the deployment and its pods, the recent events of the namespace and
+// the last log lines of the crashing containers. The failures of the commands are part of the diagnostics.
+func (t *aksTarget) deploymentDiagnostics(ctx context.Context, namespace string, deploymentName string) string {
+	flags := &kubectl.KubeCliFlags{Namespace: namespace}
+
+	var sb strings.Builder
+	section := func(title string, output string, err error) {
+		fmt.Fprintf(&sb, "==> %s <==\n", title)
+		if err != nil {
+			fmt.Fprintf(&sb, "failed: %v\n\n", err)
+			return
+		}
+
+		sb.WriteString(strings.TrimRight(output, "\n"))
+		sb.WriteString("\n\n")
+	}
+
+	// kubectl describe matches the resources with names starting with the name
+	res, err := t.kubectl.Exec(ctx, flags, "describe", "deployment", deploymentName)
+	section(fmt.Sprintf("kubectl describe deployment %s", deploymentName), res.Stdout, err)
+
+	pods, err := kubectl.GetResources[kubectl.Pod](
+		ctx, t.kubectl, kubectl.ResourceTypePod, &kubectl.KubeCliFlags{Namespace: namespace})
+	if err != nil {
+		section("kubectl get pods", "", err)
+		pods = &kubectl.List[kubectl.Pod]{}
+	}
+
+	// The pods of a deployment are named after the deployment, ex) api-5d8f7c9b4-x2x7k
+	deploymentPods := []kubectl.Pod{}
+	for _, pod := range pods.Items {
+		if strings.HasPrefix(pod.Metadata.Name, deploymentName) {
+			deploymentPods = append(deploymentPods, pod)
+		}
+	}
+
+	for _, pod := range deploymentPods {
+		res, err := t.kubectl.Exec(ctx, flags, "describe", "pod", pod.Metadata.Name)
+		section(fmt.Sprintf("kubectl describe pod %s", pod.Metadata.Name), res.Stdout, err)
+	}
+
+	res, err = t.kubectl.Exec(ctx, flags, "get", "events", "--sort-by=.lastTimestamp")
+	section("kubectl get events", lastEvents(res.Stdout, aksDiagnosticsEvents), err)
+
+	for _, pod := range deploymentPods {
+		statuses := []kubectl.ContainerStatus{}
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if !status.Crashing() {
+				continue
+			}
+
+			args := []string{
+				"logs", pod.Metadata.Name, "-c", status.Name, fmt.Sprintf("--tail=%d", aksDiagnosticsLogLines),
+			}
+			// The logs of the restarted containers are the logs of the previous instance which crashed
+			if status.RestartCount > 0 {
+				args = append(args, "--previous")
+			}
+
+			res, err := t.kubectl.Exec(ctx, flags, args...)
+			section(fmt.Sprintf("kubectl %s", strings.Join(args, " ")), res.Stdout, err)
+		}
+	}
+
+	return sb.String()
+}
+
+// lastEvents returns the header and the last events of the output of kubectl get events
+func lastEvents(output string, count int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) <= count+1 {
+		return output
+	}
+
+	return strings.Join(append([]string{lines[0]}, lines[len(lines)-count:]...), "\n")
+}
@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+)
+
+// The image pull secret options of an AKS service, for registries the kubelet identity of the cluster can't pull from,
+// ex) registries of another tenant or external registries. azd creates or updates a docker-registry secret in the
+// namespace on each deploy, with fresh credentials, and adds it to the imagePullSecrets of the deployment.
+type AksImagePullSecretOptions struct {
+	// The name of the docker-registry secret. Defaults to azd-registry
+	Name string `yaml:"name,omitempty"`
+	// The user name of the registry, ex) ${REGISTRY_USERNAME}. When empty, the credentials of the admin user of the
+	// container registry are used, or else a token of the signed-in principal
+	Username ExpandableString `yaml:"username,omitempty"`
+	// The password of the registry, ex) ${REGISTRY_PASSWORD}
+	Password ExpandableString `yaml:"password,omitempty"`
+}
+
+// The name of the image pull secret, unless configured
+const defaultImagePullSecretName = "azd-registry"
+
+// Creates or updates the docker-registry secret of the container registry of the service in the namespace, with
+// the configured credentials or else credentials of the registry. The name of the secret is returned, and set as the
+// IMAGE_PULL_SECRET_NAME service property, available to the manifests of the service.
+func (t *aksTarget) configureImagePullSecret(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	namespace string,
+) (string, error) {
+	loginServer, err := t.containerHelper.RegistryName(ctx, serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	options := serviceConfig.K8s.ImagePullSecret
+	if options == nil {
+		options = &AksImagePullSecretOptions{}
+	}
+
+	name := options.Name
+	if name == "" {
+		name = defaultImagePullSecretName
+	}
+
+	username, err := options.Username.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding username: %w", err)
+	}
+
+	password, err := options.Password.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding password: %w", err)
+	}
+
+	if username == "" {
+		credentials, err := t.containerRegistryService.PullCredentials(
+			ctx, targetResource.SubscriptionId(), loginServer)
+		if err != nil {
+			return "", fmt.Errorf(
+				"%w. Set 'k8s.imagePullSecret.username' and 'k8s.imagePullSecret.password' for registries "+
+					"outside of the subscription", err)
+		}
+
+		username = credentials.Username
+		password = credentials.Password
+	}
+
+	manifest, err := imagePullSecret(namespace, name, loginServer, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := t.kubectl.ApplyWithInput(ctx, manifest, nil); err != nil {
+		return "", fmt.Errorf("failed applying image pull secret: %w", err)
+	}
+
+	t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_PULL_SECRET_NAME", name)
+	if err := t.env.Save(); err != nil {
+		return "", fmt.Errorf("failed updating environment with image pull secret name, %w", err)
+	}
+
+	return name, nil
+}
+
+// Adds the image pull secret to the pods of the deployment of the service. Services without deployment are skipped.
+func (t *aksTarget) addImagePullSecret(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	namespace string,
+	secretName string,
+) error {
+	deployment, _, err := t.findDeployment(ctx, serviceConfig)
+	if errors.Is(err, kubectl.ErrResourceNotFound) {
+		log.Printf("skipping adding image pull secret '%s': %v", secretName, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// imagePullSecrets are merged by name, so that the other secrets of the deployment are kept
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"imagePullSecrets": []any{
+						map[string]any{"name": secretName},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = t.kubectl.Exec(ctx, &kubectl.KubeCliFlags{Namespace: namespace},
+		"patch", "deployment", deployment.Metadata.Name, "--type", "strategic", "-p", string(patch))
+	if err != nil {
+		return fmt.Errorf("failed adding image pull secret to deployment '%s': %w", deployment.Metadata.Name, err)
+	}
+
+	return nil
+}
+
+// Returns the manifest of the docker-registry secret of the registry. The credentials are not passed to kubectl on the
+// command line, where other processes could read them.
+func imagePullSecret(namespace string, name string, loginServer string, username string, password string) (string, error) {
+	if strings.TrimSpace(username) == "" || password == "" {
+		return "", fmt.Errorf("the credentials of container registry '%s' are empty", loginServer)
+	}
+
+	config, err := json.Marshal(map[string]any{
+		"auths": map[string]any{
+			loginServer: map[string]any{
+				"username": username,
+				"password": password,
+				"auth":     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: %s
+  namespace: %s
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: %s
+`, name, namespace, base64.StdEncoding.EncodeToString(config)), nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Deploy_ImagePullSecret(t *testing.T) {
+	deploy := func(
+		t *testing.T,
+		mockContext *mocks.MockContext,
+		serviceConfig *ServiceConfig,
+		env *environment.Environment,
+	) ([]string, map[string]string) {
+		appliedManifests := []string{}
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "kubectl apply -f -")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			manifest, err := io.ReadAll(args.StdIn)
+			require.NoError(t, err)
+			appliedManifests = append(appliedManifests, string(manifest))
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		patches := map[string]string{}
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "kubectl patch")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			patches[args.Args[1]+"/"+args.Args[2]] = args.Args[6]
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		fs := vfs.NewMemFs()
+		serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+		require.NoError(t, setupK8sManifests(t, fs, serviceConfig))
+
+		scope := environment.NewTargetResource(
+			"SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+		deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageTag: "IMAGE_TAG",
+			},
+		}, scope)
+		logProgress(deployTask)
+		_, err := deployTask.Await()
+		require.NoError(t, err)
+
+		return appliedManifests, patches
+	}
+
+	t.Run("Configured", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		require.NoError(t, setupMocksForAksTarget(mockContext))
+
+		serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+		serviceConfig.K8s.Namespace = "api-ns"
+		serviceConfig.K8s.ImagePullSecret = &AksImagePullSecretOptions{
+			Name:     "registry",
+			Username: NewExpandableString("${REGISTRY_USERNAME}"),
+			Password: NewExpandableString("${REGISTRY_PASSWORD}"),
+		}
+		env := createEnv()
+		env.DotenvSet("REGISTRY_USERNAME", "puller")
+		env.DotenvSet("REGISTRY_PASSWORD", "secret")
+
+		appliedManifests, patches := deploy(t, mockContext, serviceConfig, env)
+
+		secret, err := imagePullSecret("api-ns", "registry", "REGISTRY.azurecr.io", "puller", "secret")
+		require.NoError(t, err)
+		require.Contains(t, appliedManifests, secret)
+		require.Equal(t, map[string]string{
+			"deployment/api-deployment": `{"spec":{"template":{"spec":{"imagePullSecrets":[{"name":"registry"}]}}}}`,
+		}, patches)
+		require.Equal(t, "registry", env.Dotenv()["SERVICE_API_IMAGE_PULL_SECRET_NAME"])
+	})
+
+	t.Run("RegistryNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		require.NoError(t, setupMocksForAksTarget(mockContext))
+		// The registry is in another tenant
+		mockazsdk.MockContainerRegistryList(mockContext, []*armcontainerregistry.Registry{})
+
+		serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+		env := createEnv()
+
+		appliedManifests, patches := deploy(t, mockContext, serviceConfig, env)
+
+		// The secret holds a token of the signed-in principal
+		secret, err := imagePullSecret(serviceConfig.Project.Name, "azd-registry", "REGISTRY.azurecr.io",
+			"00000000-0000-0000-0000-000000000000", "REFRESH_TOKEN")
+		require.NoError(t, err)
+		require.Contains(t, appliedManifests, secret)
+		require.Contains(t, patches, "deployment/api-deployment")
+	})
+}
+
+func Test_imagePullSecret(t *testing.T) {
+	secret, err := imagePullSecret("ns", "azd-registry", "contoso.io", "user", "pass")
+	require.NoError(t, err)
+	require.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: azd-registry
+  namespace: ns
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: `+
+		"eyJhdXRocyI6eyJjb250b3NvLmlvIjp7ImF1dGgiOiJkWE5sY2pwd1lYTnoiLCJwYXNzd29yZCI6InBhc3MiLCJ1c2VybmFtZSI6InVzZXIifX19\n",
+		secret)
+
+	_, err = imagePullSecret("ns", "azd-registry", "contoso.io", "", "")
+	require.ErrorContains(t, err, "the credentials of container registry 'contoso.io' are empty")
+}
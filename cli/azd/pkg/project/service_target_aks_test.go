@@ -3,15 +3,19 @@ package project
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
@@ -20,13 +24,17 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/policy"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubelogin"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/opa"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
-	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -37,24 +45,23 @@ func Test_NewAksTarget(t *testing.T) {
 	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
 	env := createEnv()
 
-	serviceTarget := createAksServiceTarget(mockContext, serviceConfig, env)
+	serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, env)
 
 	require.NotNil(t, serviceTarget)
 	require.NotNil(t, serviceConfig)
 }
 
 func Test_Required_Tools(t *testing.T) {
-	tempDir := t.TempDir()
-	ostest.Chdir(t, tempDir)
+	fs := vfs.NewMemFs()
 
 	mockContext := mocks.NewMockContext(context.Background())
 	err := setupMocksForAksTarget(mockContext)
 	require.NoError(t, err)
 
-	serviceConfig := createTestServiceConfig(tempDir, AksTarget, ServiceLanguageTypeScript)
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
 	env := createEnv()
 
-	serviceTarget := createAksServiceTarget(mockContext, serviceConfig, env)
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
 
 	requiredTools := serviceTarget.RequiredExternalTools(*mockContext.Context)
 	require.Len(t, requiredTools, 2)
@@ -63,18 +70,17 @@ func Test_Required_Tools(t *testing.T) {
 }
 
 func Test_Package_Deploy_HappyPath(t *testing.T) {
-	tempDir := t.TempDir()
-	ostest.Chdir(t, tempDir)
+	fs := vfs.NewMemFs()
 
 	mockContext := mocks.NewMockContext(context.Background())
 	err := setupMocksForAksTarget(mockContext)
 	require.NoError(t, err)
 
-	serviceConfig := createTestServiceConfig(tempDir, AksTarget, ServiceLanguageTypeScript)
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
 	env := createEnv()
 
-	serviceTarget := createAksServiceTarget(mockContext, serviceConfig, env)
-	err = setupK8sManifests(t, serviceConfig)
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	err = setupK8sManifests(t, fs, serviceConfig)
 	require.NoError(t, err)
 
 	packageTask := serviceTarget.Package(
@@ -109,21 +115,671 @@ func Test_Package_Deploy_HappyPath(t *testing.T) {
 	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", env.Dotenv()["SERVICE_API_IMAGE_NAME"])
 }
 
+func Test_Deploy_WorkloadIdentity(t *testing.T) {
+	const identityId = "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/" +
+		"Microsoft.ManagedIdentity/userAssignedIdentities/id-api"
+
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/managedClusters/CLUSTER_NAME")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{
+				OidcIssuerProfile: &armcontainerservice.ManagedClusterOIDCIssuerProfile{
+					IssuerURL: convert.RefOf("https://oidc.example.com/ISSUER/"),
+				},
+			},
+		})
+	})
+
+	var federatedCredential map[string]any
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/federatedIdentityCredentials/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.True(t, strings.HasSuffix(
+			request.URL.Path, "/id-api/federatedIdentityCredentials/azd-CLUSTER_NAME-api-ns-api-sa"))
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&federatedCredential))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, federatedCredential)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/userAssignedIdentities/id-api")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"id":         identityId,
+			"properties": map[string]any{"clientId": "CLIENT_ID"},
+		})
+	})
+
+	appliedManifests := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl apply -f -")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		manifest, err := io.ReadAll(args.StdIn)
+		require.NoError(t, err)
+		appliedManifests = append(appliedManifests, string(manifest))
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Namespace = "api-ns"
+	serviceConfig.K8s.WorkloadIdentity = &AksWorkloadIdentityOptions{
+		IdentityId:     NewExpandableString("${AZURE_API_IDENTITY_ID}"),
+		ServiceAccount: "api-sa",
+	}
+	env := createEnv()
+	env.DotenvSet("AZURE_API_IDENTITY_ID", identityId)
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	err = setupK8sManifests(t, fs, serviceConfig)
+	require.NoError(t, err)
+
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		PackagePath: "test-app/api-test:azd-deploy-0",
+		Details: &dockerPackageResult{
+			ImageHash: "IMAGE_HASH",
+			ImageTag:  "test-app/api-test:azd-deploy-0",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]any{
+		"properties": map[string]any{
+			"issuer":    "https://oidc.example.com/ISSUER/",
+			"subject":   "system:serviceaccount:api-ns:api-sa",
+			"audiences": []any{"api://AzureADTokenExchange"},
+		},
+	}, federatedCredential)
+
+	require.Contains(t, appliedManifests, workloadIdentityServiceAccount("api-ns", "api-sa", "CLIENT_ID"))
+	require.Equal(t, "CLIENT_ID", env.Dotenv()["SERVICE_API_IDENTITY_CLIENT_ID"])
+}
+
+func Test_Deploy_WorkloadIdentity_No_Oidc_Issuer(t *testing.T) {
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/managedClusters/CLUSTER_NAME")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{},
+		})
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.WorkloadIdentity = &AksWorkloadIdentityOptions{
+		IdentityId: NewExpandableString("IDENTITY_ID"),
+	}
+	env := createEnv()
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.ErrorContains(t, err, "the OIDC issuer of AKS cluster 'CLUSTER_NAME' is not enabled")
+}
+
+func Test_Deploy_AcrPull(t *testing.T) {
+	deploy := func(t *testing.T, mockContext *mocks.MockContext) error {
+		fs := vfs.NewMemFs()
+		serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+		serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, createEnv())
+		err := setupK8sManifests(t, fs, serviceConfig)
+		require.NoError(t, err)
+
+		scope := environment.NewTargetResource(
+			"SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+		deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageTag: "IMAGE_TAG",
+			},
+		}, scope)
+		logProgress(deployTask)
+		_, err = deployTask.Await()
+
+		return err
+	}
+
+	t.Run("Assigned", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		err := setupMocksForAksTarget(mockContext)
+		require.NoError(t, err)
+
+		// AcrPull is assigned on the resource group of the registry
+		setupMocksForKubeletIdentity(mockContext, []*armauthorization.RoleAssignment{
+			{
+				Properties: &armauthorization.RoleAssignmentPropertiesWithScope{
+					RoleDefinitionID: convert.RefOf(
+						"/providers/Microsoft.Authorization/roleDefinitions/7f951dda-4ed3-4680-a7ca-43fe172d538d"),
+					Scope: convert.RefOf("/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP"),
+				},
+			},
+		})
+
+		require.NoError(t, deploy(t, mockContext))
+	})
+
+	t.Run("NotAssigned", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		err := setupMocksForAksTarget(mockContext)
+		require.NoError(t, err)
+		setupMocksForKubeletIdentity(mockContext, nil)
+
+		var roleAssignment armauthorization.RoleAssignmentCreateParameters
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/roleAssignments/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.True(t, strings.HasPrefix(request.URL.Path, testRegistryId+"/providers/Microsoft.Authorization/"))
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&roleAssignment))
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, armauthorization.RoleAssignment{})
+		})
+
+		require.NoError(t, deploy(t, mockContext))
+		require.Equal(t, "KUBELET_ID", *roleAssignment.Properties.PrincipalID)
+		require.Equal(t,
+			"/subscriptions/SUB_ID/providers/Microsoft.Authorization/roleDefinitions/7f951dda-4ed3-4680-a7ca-43fe172d538d",
+			*roleAssignment.Properties.RoleDefinitionID)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		err := setupMocksForAksTarget(mockContext)
+		require.NoError(t, err)
+		setupMocksForKubeletIdentity(mockContext, nil)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/roleAssignments/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusForbidden)
+		})
+
+		err = deploy(t, mockContext)
+		require.ErrorContains(t, err, "you aren't permitted to assign it the AcrPull role")
+		require.ErrorContains(t, err, "--attach-acr "+testRegistryId)
+	})
+}
+
+func Test_Deploy_ClusterCompatibility(t *testing.T) {
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl api-versions")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "apps/v1\nnetworking.k8s.io/v1\nv1\n", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl version")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, `{"serverVersion": {"gitVersion": "v1.27.3"}}`, ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get --raw")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		kinds := map[string]string{
+			"/apis/apps/v1":              `{"resources": [{"name": "deployments", "kind": "Deployment"}]}`,
+			"/apis/networking.k8s.io/v1": `{"resources": [{"name": "ingresses", "kind": "Ingress"}]}`,
+		}
+		return exec.NewRunResult(0, kinds[args.Args[2]], ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get ingressclass")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, `{"items": [{"metadata": {"name": "nginx"}}]}`, ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	manifestsDir := filepath.Join(serviceConfig.RelativePath, defaultDeploymentPath)
+	require.NoError(t, fs.MkdirAll(manifestsDir, osutil.PermissionDirectory))
+	err = fs.WriteFile(filepath.Join(manifestsDir, "manifests.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: api-legacy
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+spec:
+  ingressClassName: webapprouting.kubernetes.azure.com
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api-nginx
+spec:
+  ingressClassName: nginx
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: api-tls
+`), osutil.PermissionFile)
+	require.NoError(t, err)
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, createEnv())
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+
+	require.EqualError(t, err, strings.Join([]string{
+		"the k8s manifests are not compatible with AKS cluster 'CLUSTER_NAME':",
+		"  - Ingress 'api-legacy' uses API version 'networking.k8s.io/v1beta1', which Kubernetes v1.27.3 of the " +
+			"cluster doesn't serve. Served versions: networking.k8s.io/v1",
+		"  - Certificate 'api-tls' uses API version 'cert-manager.io/v1', which isn't installed on the cluster. " +
+			"Install the CRDs of 'cert-manager.io'",
+		"  - ingress class 'webapprouting.kubernetes.azure.com' doesn't exist, the Web App Routing add-on of AKS " +
+			"cluster 'CLUSTER_NAME' isn't enabled. Enable it with `az aks approuting enable --name CLUSTER_NAME " +
+			"--resource-group RG_ID`",
+	}, "\n"))
+}
+
+func Test_Deploy_RolloutFailure(t *testing.T) {
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl rollout status")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(1, "", "progress deadline exceeded"), errors.New("exit code: 1")
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get pod")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, `{"items": [
+			{
+				"metadata": {"name": "api-deployment-5d8f7c9b4-x2x7k"},
+				"status": {
+					"containerStatuses": [
+						{"name": "api", "restartCount": 3, "state": {"waiting": {"reason": "CrashLoopBackOff"}}},
+						{"name": "sidecar", "ready": true, "state": {"running": {}}}
+					]
+				}
+			},
+			{"metadata": {"name": "redis-0"}}
+		]}`, ""), nil
+	})
+
+	commands := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl describe") || strings.Contains(command, "kubectl logs") ||
+			strings.Contains(command, "kubectl get events")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, strings.Join(args.Args, " "))
+		return exec.NewRunResult(0, fmt.Sprintf("output of %s", args.Args[0]), ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	err = setupK8sManifests(t, fs, serviceConfig)
+	require.NoError(t, err)
+
+	env := createEnv()
+	env.Root = filepath.Join("azure", "test")
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+
+	var deploymentErr *AksDeploymentError
+	require.ErrorAs(t, err, &deploymentErr)
+	require.ErrorContains(t, err, "deployment rollout failed")
+
+	require.Equal(t, []string{
+		"describe deployment api -n Test-App",
+		"describe pod api-deployment-5d8f7c9b4-x2x7k -n Test-App",
+		"get events --sort-by=.lastTimestamp -n Test-App",
+		"logs api-deployment-5d8f7c9b4-x2x7k -c api --tail=100 --previous -n Test-App",
+	}, commands)
+	require.Contains(t, deploymentErr.Diagnostics, "==> kubectl describe pod api-deployment-5d8f7c9b4-x2x7k <==\n"+
+		"output of describe\n")
+
+	diagnostics, err := fs.ReadFile(deploymentErr.DiagnosticsPath)
+	require.NoError(t, err)
+	require.Equal(t, deploymentErr.Diagnostics, string(diagnostics))
+	require.Equal(t, filepath.Join("azure", "test", "diagnostics"), filepath.Dir(deploymentErr.DiagnosticsPath))
+}
+
+func Test_lastEvents(t *testing.T) {
+	output := "LAST SEEN   TYPE     REASON\n1m   Normal   Pulled\n2m   Warning   BackOff\n3m   Warning   Failed\n"
+
+	require.Equal(t, output, lastEvents(output, 3))
+	require.Equal(t, "LAST SEEN   TYPE     REASON\n2m   Warning   BackOff\n3m   Warning   Failed", lastEvents(output, 2))
+}
+
+func Test_Endpoints_Configured(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Endpoint = NewExpandableString("https://${API_HOSTNAME}/api")
+	env := createEnv()
+	env.DotenvSet("API_HOSTNAME", "api.contoso.com")
+
+	serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, env)
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	endpoints, err := serviceTarget.Endpoints(*mockContext.Context, serviceConfig, scope)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://api.contoso.com/api, (Endpoint, Type: Configured)"}, endpoints)
+}
+
+func Test_Connect(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl port-forward")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{"port-forward", "svc/api-service", ":80", "-n", "api-namespace"}, args.Args)
+		_, err := args.Stdout.Write([]byte("Forwarding from 127.0.0.1:54321 -> 80\n"))
+		require.NoError(t, err)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Namespace = "api-namespace"
+	serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv())
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+
+	var endpoint string
+	err = serviceTarget.(ServiceConnectTarget).Connect(*mockContext.Context, serviceConfig, scope, func(localEndpoint string) {
+		endpoint = localEndpoint
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:54321", endpoint)
+}
+
+func Test_Restart_Scale(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	var commands [][]string
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl rollout") || strings.Contains(command, "kubectl scale")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, args.Args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Namespace = "api-namespace"
+	serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv())
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+
+	err = serviceTarget.(Restarter).Restart(*mockContext.Context, serviceConfig, scope)
+	require.NoError(t, err)
+
+	err = serviceTarget.(Scaler).Scale(*mockContext.Context, serviceConfig, scope, 3)
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"rollout", "restart", "deployment/api-deployment", "-n", "api-namespace"},
+		{"rollout", "status", "deployment/api-deployment", "-n", "api-namespace"},
+		{"scale", "deployment/api-deployment", "--replicas=3", "-n", "api-namespace"},
+	}, commands)
+}
+
+func Test_CleanupDeploy(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     kubectl.DeploymentStatus
+		rolledBack bool
+	}{
+		{
+			name:   "RolledOut",
+			status: kubectl.DeploymentStatus{AvailableReplicas: 2, ReadyReplicas: 2, Replicas: 2, UpdatedReplicas: 2},
+		},
+		{
+			name:       "UnfinishedRollout",
+			status:     kubectl.DeploymentStatus{AvailableReplicas: 2, ReadyReplicas: 2, Replicas: 3, UpdatedReplicas: 1},
+			rolledBack: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			err := setupMocksForAksTarget(mockContext)
+			require.NoError(t, err)
+
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "kubectl get deployment")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				deploymentList := createK8sResourceList(&kubectl.Deployment{
+					Resource: kubectl.Resource{
+						Metadata: kubectl.ResourceMetadata{Name: "api-deployment", Namespace: "api-namespace"},
+					},
+					Spec:   kubectl.DeploymentSpec{Replicas: 2},
+					Status: tt.status,
+				})
+				jsonBytes, err := json.Marshal(deploymentList)
+				require.NoError(t, err)
+
+				return exec.NewRunResult(0, string(jsonBytes), ""), nil
+			})
+
+			var undoArgs []string
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "kubectl rollout undo")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				undoArgs = args.Args
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+			serviceConfig.K8s.Namespace = "api-namespace"
+			serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv())
+			scope := environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+
+			err = serviceTarget.(ServiceCleanupTarget).CleanupDeploy(*mockContext.Context, serviceConfig, scope)
+			require.NoError(t, err)
+
+			if tt.rolledBack {
+				require.Equal(t, []string{"rollout", "undo", "deployment/api-deployment", "-n", "api-namespace"}, undoArgs)
+			} else {
+				require.Nil(t, undoArgs)
+			}
+		})
+	}
+}
+
+func Test_Endpoints_HttpRoute(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl api-versions")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "gateway.networking.k8s.io/v1\nv1\n", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get ing")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, `{"items": []}`, ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get httproutes.gateway.networking.k8s.io")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		jsonBytes, _ := json.Marshal(createK8sResourceList(&kubectl.HttpRoute{
+			Resource: kubectl.Resource{Metadata: kubectl.ResourceMetadata{Name: "api-route"}},
+			Spec: kubectl.HttpRouteSpec{
+				ParentRefs: []kubectl.HttpRouteParentRef{{Name: "gateway", Namespace: "infra"}},
+				Rules: []kubectl.HttpRouteRule{
+					{Matches: []kubectl.HttpRouteMatch{{Path: &kubectl.HttpPathMatch{Value: "/api"}}}},
+				},
+			},
+		}))
+
+		return exec.NewRunResult(0, string(jsonBytes), ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get gateways.gateway.networking.k8s.io")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Contains(t, args.Args, "infra")
+		jsonBytes, _ := json.Marshal(createK8sResourceList(&kubectl.Gateway{
+			Resource: kubectl.Resource{Metadata: kubectl.ResourceMetadata{Name: "gateway"}},
+			Spec: kubectl.GatewaySpec{
+				Listeners: []kubectl.GatewayListener{{Name: "http", Port: 80, Protocol: "HTTP"}},
+			},
+			Status: kubectl.GatewayStatus{
+				Addresses: []kubectl.GatewayAddress{{Type: "IPAddress", Value: "2.2.2.2"}},
+			},
+		}))
+
+		return exec.NewRunResult(0, string(jsonBytes), ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceTarget := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv())
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	endpoints, err := serviceTarget.Endpoints(*mockContext.Context, serviceConfig, scope)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"http://10.10.10.10:80, (Service, Type: ClusterIP)",
+		"http://2.2.2.2/api, (HTTPRoute, Type: Gateway)",
+	}, endpoints)
+}
+
+func Test_ingressEndpoints(t *testing.T) {
+	ingress := &kubectl.Ingress{
+		Spec: kubectl.IngressSpec{
+			Tls: []kubectl.IngressTls{{Hosts: []string{"api.contoso.com"}}},
+			Rules: []kubectl.IngressRule{
+				{
+					Host: convert.RefOf("api.contoso.com"),
+					Http: kubectl.IngressRuleHttp{
+						Paths: []kubectl.IngressPath{{Path: "/"}, {Path: "/v2"}},
+					},
+				},
+				{
+					Http: kubectl.IngressRuleHttp{
+						Paths: []kubectl.IngressPath{{Path: "/health"}},
+					},
+				},
+			},
+		},
+		Status: kubectl.IngressStatus{
+			LoadBalancer: kubectl.LoadBalancer{
+				Ingress: []kubectl.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			},
+		},
+	}
+
+	t.Run("Paths", func(t *testing.T) {
+		endpoints, err := ingressEndpoints(ingress, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"https://api.contoso.com/, (Ingress, Type: LoadBalancer)",
+			"https://api.contoso.com/v2, (Ingress, Type: LoadBalancer)",
+			"https://lb.example.com/health, (Ingress, Type: LoadBalancer)",
+		}, endpoints)
+	})
+
+	t.Run("RelativePath", func(t *testing.T) {
+		endpoints, err := ingressEndpoints(ingress, "api")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"https://api.contoso.com/api, (Ingress, Type: LoadBalancer)",
+			"https://lb.example.com/api, (Ingress, Type: LoadBalancer)",
+		}, endpoints)
+	})
+}
+
+func Test_httpRouteEndpoints(t *testing.T) {
+	gateway := &kubectl.Gateway{
+		Spec: kubectl.GatewaySpec{
+			Listeners: []kubectl.GatewayListener{
+				{Name: "https", Port: 443, Protocol: "HTTPS", Hostname: convert.RefOf("www.contoso.com")},
+			},
+		},
+		Status: kubectl.GatewayStatus{
+			Addresses: []kubectl.GatewayAddress{{Type: "IPAddress", Value: "2.2.2.2"}},
+		},
+	}
+
+	t.Run("RouteHostnames", func(t *testing.T) {
+		endpoints, err := httpRouteEndpoints(&kubectl.HttpRoute{
+			Spec: kubectl.HttpRouteSpec{Hostnames: []string{"api.contoso.com"}},
+		}, gateway, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://api.contoso.com, (HTTPRoute, Type: Gateway)"}, endpoints)
+	})
+
+	t.Run("ListenerHostname", func(t *testing.T) {
+		endpoints, err := httpRouteEndpoints(&kubectl.HttpRoute{}, gateway, "api")
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://www.contoso.com/api, (HTTPRoute, Type: Gateway)"}, endpoints)
+	})
+}
+
 func Test_Deploy_No_Cluster_Name(t *testing.T) {
-	tempDir := t.TempDir()
-	ostest.Chdir(t, tempDir)
+	fs := vfs.NewMemFs()
 
 	mockContext := mocks.NewMockContext(context.Background())
 	err := setupMocksForAksTarget(mockContext)
 	require.NoError(t, err)
 
-	serviceConfig := createTestServiceConfig(tempDir, AksTarget, ServiceLanguageTypeScript)
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
 	env := createEnv()
 
 	// Simulate AKS cluster name not found in env file
 	env.DotenvDelete(environment.AksClusterEnvVarName)
 
-	serviceTarget := createAksServiceTarget(mockContext, serviceConfig, env)
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
 	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
 	packageOutput := &ServicePackageResult{
 		Build: &ServiceBuildResult{BuildOutputPath: "IMAGE_ID"},
@@ -142,8 +798,7 @@ func Test_Deploy_No_Cluster_Name(t *testing.T) {
 }
 
 func Test_Deploy_No_Admin_Credentials(t *testing.T) {
-	tempDir := t.TempDir()
-	ostest.Chdir(t, tempDir)
+	fs := vfs.NewMemFs()
 
 	mockContext := mocks.NewMockContext(context.Background())
 	err := setupMocksForAksTarget(mockContext)
@@ -154,10 +809,10 @@ func Test_Deploy_No_Admin_Credentials(t *testing.T) {
 	err = setupListClusterAdminCredentialsMock(mockContext, http.StatusUnauthorized)
 	require.NoError(t, err)
 
-	serviceConfig := createTestServiceConfig(tempDir, AksTarget, ServiceLanguageTypeScript)
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
 	env := createEnv()
 
-	serviceTarget := createAksServiceTarget(mockContext, serviceConfig, env)
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
 	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
 	packageOutput := &ServicePackageResult{
 		Build: &ServiceBuildResult{BuildOutputPath: "IMAGE_ID"},
@@ -175,15 +830,93 @@ func Test_Deploy_No_Admin_Credentials(t *testing.T) {
 	require.Nil(t, deployResult)
 }
 
-func setupK8sManifests(t *testing.T, serviceConfig *ServiceConfig) error {
+func Test_Deploy_Local_Accounts_Disabled(t *testing.T) {
+	// kubelogin is found in the path
+	kubeloginDir := t.TempDir()
+	kubeloginName := "kubelogin"
+	if runtime.GOOS == "windows" {
+		kubeloginName += ".exe"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(kubeloginDir, kubeloginName), []byte{}, 0700))
+	t.Setenv("PATH", kubeloginDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	fs := vfs.NewMemFs()
+	mockContext := mocks.NewMockContext(context.Background())
+	require.NoError(t, setupMocksForAksTarget(mockContext))
+	require.NoError(t, setupListClusterAdminCredentialsMock(mockContext, http.StatusBadRequest))
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/managedClusters/AKS_CLUSTER")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{
+				DisableLocalAccounts: convert.RefOf(true),
+			},
+		})
+	})
+
+	kubeConfig := createTestCluster("cluster1", "user1")
+	kubeConfig.Users[0].KubeUserData.Exec = &kubectl.KubeExecConfig{
+		ApiVersion: "client.authentication.k8s.io/v1beta1",
+		Command:    "kubelogin",
+		Args:       []string{"get-token", "--login", "devicecode", "--server-id", "6dae42f8-4368-4678-94ff-3960e28e3630"},
+	}
+	kubeConfigBytes, err := yaml.Marshal(kubeConfig)
+	require.NoError(t, err)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "listClusterUserCredential")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "exec", request.URL.Query().Get("format"))
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.CredentialResults{
+			Kubeconfigs: []*armcontainerservice.CredentialResult{
+				{
+					Name:  convert.RefOf("clusterUser"),
+					Value: kubeConfigBytes,
+				},
+			},
+		})
+	})
+
+	convertArgs := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubelogin convert-kubeconfig")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		convertArgs = args.Args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	env := createEnv()
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	require.NoError(t, setupK8sManifests(t, fs, serviceConfig))
+
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.NoError(t, err)
+
+	// The kube config of the cluster gets the tokens of the principal signed in to azd
+	require.Len(t, convertArgs, 5)
+	require.Equal(t, []string{"convert-kubeconfig", "--login", "azd", "--kubeconfig"}, convertArgs[:4])
+	require.Equal(t, "AKS_CLUSTER", filepath.Base(convertArgs[4]))
+}
+
+func setupK8sManifests(t *testing.T, fs vfs.Fs, serviceConfig *ServiceConfig) error {
 	manifestsDir := filepath.Join(serviceConfig.RelativePath, defaultDeploymentPath)
-	err := os.MkdirAll(manifestsDir, osutil.PermissionDirectory)
+	err := fs.MkdirAll(manifestsDir, osutil.PermissionDirectory)
 	require.NoError(t, err)
 
 	filenames := []string{"deployment.yaml", "service.yaml", "ingress.yaml"}
 
 	for _, filename := range filenames {
-		err = os.WriteFile(filepath.Join(manifestsDir, filename), []byte(""), osutil.PermissionFile)
+		err = fs.WriteFile(filepath.Join(manifestsDir, filename), []byte(""), osutil.PermissionFile)
 		require.NoError(t, err)
 	}
 
@@ -197,12 +930,49 @@ func setupMocksForAksTarget(mockContext *mocks.MockContext) error {
 	}
 
 	setupMocksForAcr(mockContext)
+	setupMocksForKubeletIdentity(mockContext, []*armauthorization.RoleAssignment{
+		{
+			Properties: &armauthorization.RoleAssignmentPropertiesWithScope{
+				PrincipalID:      convert.RefOf("KUBELET_ID"),
+				RoleDefinitionID: convert.RefOf("/providers/Microsoft.Authorization/roleDefinitions/" + acrPullRoleIds[0]),
+				Scope:            convert.RefOf(testRegistryId),
+			},
+		},
+	})
 	setupMocksForKubectl(mockContext)
 	setupMocksForDocker(mockContext)
 
 	return nil
 }
 
+// Mocks the kubelet identity of the cluster, and its role assignments
+func setupMocksForKubeletIdentity(mockContext *mocks.MockContext, roleAssignments []*armauthorization.RoleAssignment) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		// The cluster of the target resource, and the cluster of the environment azd logs in to
+		return request.Method == http.MethodGet &&
+			(strings.HasSuffix(request.URL.Path, "/managedClusters/CLUSTER_NAME") ||
+				strings.HasSuffix(request.URL.Path, "/managedClusters/AKS_CLUSTER"))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{
+				IdentityProfile: map[string]*armcontainerservice.UserAssignedIdentity{
+					"kubeletidentity": {
+						ObjectID: convert.RefOf("KUBELET_ID"),
+					},
+				},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/roleAssignments")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armauthorization.RoleAssignmentListResult{
+			Value: roleAssignments,
+		})
+	})
+}
+
 func setupListClusterAdminCredentialsMock(mockContext *mocks.MockContext, statusCode int) error {
 	kubeConfig := createTestCluster("cluster1", "user1")
 	kubeConfigBytes, err := yaml.Marshal(kubeConfig)
@@ -233,13 +1003,13 @@ func setupListClusterAdminCredentialsMock(mockContext *mocks.MockContext, status
 	return nil
 }
 
+const testRegistryId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/" +
+	"Microsoft.ContainerRegistry/registries/REGISTRY"
+
 func setupMocksForAcr(mockContext *mocks.MockContext) {
 	mockazsdk.MockContainerRegistryList(mockContext, []*armcontainerregistry.Registry{
 		{
-			ID: convert.RefOf(
-				//nolint:lll
-				"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.ContainerRegistry/registries/REGISTRY",
-			),
+			ID:       convert.RefOf(testRegistryId),
 			Location: convert.RefOf("eastus2"),
 			Name:     convert.RefOf("REGISTRY"),
 			Properties: &armcontainerregistry.RegistryProperties{
@@ -366,6 +1136,13 @@ func setupMocksForKubectl(mockContext *mocks.MockContext) {
 		return exec.NewRunResult(0, string(jsonBytes), ""), nil
 	})
 
+	// Api versions
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl api-versions")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "apps/v1\nnetworking.k8s.io/v1\nv1\n", ""), nil
+	})
+
 	// Get Ingress
 	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 		return strings.Contains(command, "kubectl get ing")
@@ -463,6 +1240,7 @@ func createEnv() *environment.Environment {
 
 func createAksServiceTarget(
 	mockContext *mocks.MockContext,
+	fs vfs.Fs,
 	serviceConfig *ServiceConfig,
 	env *environment.Environment,
 ) ServiceTarget {
@@ -475,13 +1253,21 @@ func createAksServiceTarget(
 
 	managedClustersService := azcli.NewManagedClustersService(credentialProvider, mockContext.HttpClient)
 	containerRegistryService := azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli)
-	containerHelper := NewContainerHelper(env, clock.NewMock(), containerRegistryService, dockerCli)
+	containerHelper := NewContainerHelper(env, clock.NewMock(), containerRegistryService, dockerCli, nil)
 
 	return NewAksTarget(
 		env,
+		mockazcli.NewAzCliFromMockContext(mockContext),
 		managedClustersService,
+		azcli.NewManagedIdentityService(credentialProvider, mockContext.HttpClient),
+		containerRegistryService,
 		kubeCtl,
+		kubelogin.NewKubelogin(mockContext.CommandRunner),
 		containerHelper,
+		policy.NewEngine(opa.NewOpaCli(mockContext.CommandRunner)),
+		fs,
+		clock.NewMock(),
+		DefaultServiceTargetWaits(),
 	)
 }
 
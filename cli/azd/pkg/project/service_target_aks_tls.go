@@ -0,0 +1,441 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+// The TLS options of the ingress of an AKS service. azd issues the certificate of the host in Key Vault, or renews it
+// when it expires soon, syncs it to a TLS secret of the namespace with the secrets store CSI driver, and sets the TLS
+// section of the ingress. The Azure Key Vault secrets provider add-on must be enabled on the cluster.
+type AksIngressTlsOptions struct {
+	// The host name of the ingress, ex) ${API_HOSTNAME}
+	Host ExpandableString `yaml:"host"`
+	// The name of the Key Vault storing the certificate, ex) ${AZURE_KEY_VAULT_NAME}
+	KeyVault ExpandableString `yaml:"keyVault"`
+	// The name of the certificate in Key Vault, of the k8s TLS secret and of its SecretProviderClass.
+	// Defaults to <service name>-tls
+	SecretName string `yaml:"secretName,omitempty"`
+	// The name of the issuer of the certificate registered in Key Vault. Defaults to Self, for self-signed certificates
+	Issuer string `yaml:"issuer,omitempty"`
+	// The validity of the certificate in months. Defaults to 12
+	ValidityInMonths int `yaml:"validityInMonths,omitempty"`
+}
+
+const (
+	defaultIngressCertificateIssuer   = "Self"
+	defaultIngressCertificateValidity = 12
+	// Certificates expiring within this number of days are renewed, by azd on deploy and by Key Vault itself
+	ingressCertificateRenewalDays = 30
+	// The name of the volume mounting the TLS secret in the pods of the service
+	ingressTlsVolumeName = "azd-ingress-tls"
+)
+
+// The ids of the built-in roles which allow reading the secrets of a Key Vault: Key Vault Secrets User, Key Vault
+// Secrets Officer and Key Vault Administrator
+var keyVaultSecretsUserRoleIds = []string{
+	"4633458b-17de-408a-b874-0445c86b69e6",
+	"b86a8fe4-44ce-4948-aee5-eccb2c155cd7",
+	"00482a5a-887f-4fb3-b363-3b7fe8e74483",
+}
+
+// Characters which aren't allowed in the names of Key Vault certificates and k8s secrets
+var invalidSecretNameChars = regexp.MustCompile(`[^a-z0-9-]`)
+
+// Issues the certificate of the ingress in Key Vault, or renews it, allows the Key Vault secrets provider of the cluster
+// to read it, and applies the SecretProviderClass syncing it to a TLS secret. The name of the TLS secret is returned,
+// and set as the TLS_SECRET_NAME service property, available to the manifests of the service.
+func (t *aksTarget) configureIngressTls(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	namespace string,
+) (string, error) {
+	options := serviceConfig.K8s.Ingress.Tls
+
+	host, err := options.Host.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding host: %w", err)
+	}
+
+	vaultName, err := options.KeyVault.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding key vault: %w", err)
+	}
+
+	if strings.TrimSpace(host) == "" || strings.TrimSpace(vaultName) == "" {
+		return "", fmt.Errorf(
+			"the host or key vault of the ingress of service '%s' is empty, set 'k8s.ingress.tls.host' and "+
+				"'k8s.ingress.tls.keyVault'", serviceConfig.Name)
+	}
+
+	secretName := ingressTlsSecretName(serviceConfig)
+
+	identity, err := t.managedClustersService.GetKeyVaultSecretsProviderIdentity(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("getting key vault secrets provider of cluster: %w", err)
+	}
+
+	if identity == nil || identity.ClientID == nil || identity.ObjectID == nil {
+		return "", fmt.Errorf(
+			"the Azure Key Vault secrets provider add-on of AKS cluster '%s' is not enabled, which ingress TLS "+
+				"requires. Run `az aks enable-addons --addons azure-keyvault-secrets-provider --name %s "+
+				"--resource-group %s`",
+			targetResource.ResourceName(),
+			targetResource.ResourceName(),
+			targetResource.ResourceGroupName(),
+		)
+	}
+
+	vault, err := t.azCli.FindKeyVault(ctx, targetResource.SubscriptionId(), vaultName)
+	if err != nil {
+		return "", err
+	}
+
+	policy := ingressCertificatePolicy(options, host)
+	if err := t.ensureIngressCertificate(ctx, targetResource, vault.Name, secretName, policy); err != nil {
+		return "", err
+	}
+
+	if err := t.ensureKeyVaultSecretsUser(ctx, targetResource, vault, *identity.ObjectID); err != nil {
+		return "", err
+	}
+
+	_, err = t.kubectl.ApplyWithInput(
+		ctx,
+		ingressTlsSecretProviderClass(namespace, secretName, vault.Name, vault.Properties.TenantId, *identity.ClientID),
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed applying secret provider class: %w", err)
+	}
+
+	t.env.SetServiceProperty(serviceConfig.Name, "TLS_SECRET_NAME", secretName)
+	if err := t.env.Save(); err != nil {
+		return "", fmt.Errorf("failed updating environment with TLS secret name, %w", err)
+	}
+
+	return secretName, nil
+}
+
+// Issues the certificate in Key Vault when the vault has no such certificate, or renews it when it expires soon or
+// doesn't cover the DNS names of the policy
+func (t *aksTarget) ensureIngressCertificate(
+	ctx context.Context,
+	targetResource *environment.TargetResource,
+	vaultName string,
+	certificateName string,
+	policy azcli.AzCliKeyVaultCertificatePolicy,
+) error {
+	certificate, err := t.azCli.GetKeyVaultCertificate(ctx, targetResource.SubscriptionId(), vaultName, certificateName)
+	if err != nil && !errors.Is(err, azcli.ErrAzCliCertificateNotFound) {
+		return err
+	}
+
+	renewAfter := t.clock.Now().Add(ingressCertificateRenewalDays * 24 * time.Hour)
+	if certificate != nil &&
+		certificate.Expires.After(renewAfter) &&
+		!slices.ContainsFunc(policy.DnsNames, func(name string) bool {
+			return !slices.Contains(certificate.DnsNames, name)
+		}) {
+		return nil
+	}
+
+	if certificate != nil {
+		log.Printf("renewing certificate '%s' of key vault '%s', expiring on %s for %v",
+			certificateName, vaultName, certificate.Expires.Format(time.RFC3339), certificate.DnsNames)
+	}
+
+	_, err = t.azCli.CreateKeyVaultCertificate(
+		ctx, t.clock, t.waits.AksCertificate, targetResource.SubscriptionId(), vaultName, certificateName, policy)
+	if err != nil {
+		return fmt.Errorf("issuing certificate '%s': %w", certificateName, err)
+	}
+
+	return nil
+}
+
+// Returns the policy of the certificate of the host, from the TLS options
+func ingressCertificatePolicy(options *AksIngressTlsOptions, host string) azcli.AzCliKeyVaultCertificatePolicy {
+	policy := azcli.AzCliKeyVaultCertificatePolicy{
+		Subject:               fmt.Sprintf("CN=%s", strings.ToLower(host)),
+		DnsNames:              []string{strings.ToLower(host)},
+		Issuer:                options.Issuer,
+		ValidityInMonths:      options.ValidityInMonths,
+		RenewBeforeExpiryDays: ingressCertificateRenewalDays,
+	}
+
+	if policy.Issuer == "" {
+		policy.Issuer = defaultIngressCertificateIssuer
+	}
+
+	if policy.ValidityInMonths <= 0 {
+		policy.ValidityInMonths = defaultIngressCertificateValidity
+	}
+
+	return policy
+}
+
+// Ensures the identity of the Key Vault secrets provider of the cluster can read the secrets of the vault, by assigning
+// it the Key Vault Secrets User role on the vault when it has no role allowing it yet. Vaults using access policies are
+// not validated.
+func (t *aksTarget) ensureKeyVaultSecretsUser(
+	ctx context.Context,
+	targetResource *environment.TargetResource,
+	vault *azcli.AzCliKeyVault,
+	principalId string,
+) error {
+	if !vault.Properties.EnableRbacAuthorization {
+		log.Printf("key vault '%s' uses access policies, skipping secrets provider access validation", vault.Name)
+		return nil
+	}
+
+	roleAssignments, err := t.azCli.ListRoleAssignments(ctx, targetResource.SubscriptionId(), vault.Id, principalId)
+	if err != nil {
+		return fmt.Errorf("listing role assignments of key vault secrets provider: %w", err)
+	}
+
+	if hasRoleAssignment(roleAssignments, vault.Id, keyVaultSecretsUserRoleIds) {
+		return nil
+	}
+
+	roleId := keyVaultSecretsUserRoleIds[0]
+	roleAssignmentName := uuid.NewSHA1(
+		uuid.NameSpaceURL, []byte(strings.ToLower(vault.Id+"|"+principalId+"|"+roleId))).String()
+
+	err = t.azCli.CreateRoleAssignment(
+		ctx,
+		targetResource.SubscriptionId(),
+		vault.Id,
+		roleAssignmentName,
+		&armauthorization.RoleAssignmentProperties{
+			PrincipalID: &principalId,
+			RoleDefinitionID: convert.RefOf(fmt.Sprintf(
+				"/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s",
+				targetResource.SubscriptionId(),
+				roleId,
+			)),
+		},
+	)
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) && responseError.StatusCode == http.StatusForbidden {
+		return fmt.Errorf(
+			"the Key Vault secrets provider of AKS cluster '%s' can't read the secrets of key vault '%s', and you "+
+				"aren't permitted to assign it the Key Vault Secrets User role. Ask an owner of the key vault to "+
+				"assign the role to the principal '%s': %w",
+			targetResource.ResourceName(),
+			vault.Name,
+			principalId,
+			err,
+		)
+	} else if err != nil {
+		return fmt.Errorf("assigning Key Vault Secrets User role to key vault secrets provider: %w", err)
+	}
+
+	return nil
+}
+
+// Mounts the TLS secret in the deployment of the service, which the secrets store CSI driver requires to sync the
+// secret, and sets the TLS section of the ingress of the service. Services without deployment or ingress are skipped.
+func (t *aksTarget) wireIngressTls(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	namespace string,
+	secretName string,
+) error {
+	host, err := serviceConfig.K8s.Ingress.Tls.Host.Envsubst(t.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("expanding host: %w", err)
+	}
+
+	flags := &kubectl.KubeCliFlags{Namespace: namespace}
+
+	deployment, _, err := t.findDeployment(ctx, serviceConfig)
+	if errors.Is(err, kubectl.ErrResourceNotFound) {
+		log.Printf("skipping mounting TLS secret '%s': %v", secretName, err)
+	} else if err != nil {
+		return err
+	} else {
+		result, err := t.kubectl.Exec(ctx, flags,
+			"get", "deployment", deployment.Metadata.Name, "-o", "jsonpath={.spec.template.spec.containers[0].name}")
+		if err != nil {
+			return fmt.Errorf("failed getting containers of deployment '%s': %w", deployment.Metadata.Name, err)
+		}
+
+		patch, err := ingressTlsDeploymentPatch(strings.TrimSpace(result.Stdout), secretName)
+		if err != nil {
+			return err
+		}
+
+		_, err = t.kubectl.Exec(ctx, flags,
+			"patch", "deployment", deployment.Metadata.Name, "--type", "strategic", "-p", patch)
+		if err != nil {
+			return fmt.Errorf("failed mounting TLS secret in deployment '%s': %w", deployment.Metadata.Name, err)
+		}
+	}
+
+	ingressName := serviceConfig.K8s.Ingress.Name
+	if ingressName == "" {
+		ingressName = serviceConfig.Name
+	}
+
+	ingresses, err := kubectl.GetResources[kubectl.Ingress](ctx, t.kubectl, kubectl.ResourceTypeIngress, flags)
+	if err != nil {
+		return fmt.Errorf("failed getting ingresses: %w", err)
+	}
+
+	index := slices.IndexFunc(ingresses.Items, func(ingress kubectl.Ingress) bool {
+		return strings.Contains(ingress.Metadata.Name, ingressName)
+	})
+	if index < 0 {
+		log.Printf("skipping setting TLS of ingress: no ingress matching '%s' found in namespace '%s'",
+			ingressName, namespace)
+		return nil
+	}
+
+	patch, err := ingressTlsIngressPatch(host, secretName)
+	if err != nil {
+		return err
+	}
+
+	name := ingresses.Items[index].Metadata.Name
+	if _, err := t.kubectl.Exec(ctx, flags, "patch", "ingress", name, "--type", "merge", "-p", patch); err != nil {
+		return fmt.Errorf("failed setting TLS of ingress '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// Returns the name of the certificate and TLS secret of the ingress of the service
+func ingressTlsSecretName(serviceConfig *ServiceConfig) string {
+	if serviceConfig.K8s.Ingress.Tls.SecretName != "" {
+		return serviceConfig.K8s.Ingress.Tls.SecretName
+	}
+
+	return strings.Trim(
+		invalidSecretNameChars.ReplaceAllString(strings.ToLower(serviceConfig.Name), "-"), "-") + "-tls"
+}
+
+// Returns the manifest of the SecretProviderClass syncing the certificate of the vault to a TLS secret, read with the
+// identity of the Key Vault secrets provider of the cluster
+func ingressTlsSecretProviderClass(
+	namespace string,
+	secretName string,
+	vaultName string,
+	tenantId string,
+	clientId string,
+) string {
+	return fmt.Sprintf(`apiVersion: secrets-store.csi.x-k8s.io/v1
+kind: SecretProviderClass
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+spec:
+  provider: azure
+  secretObjects:
+    - secretName: %[1]s
+      type: kubernetes.io/tls
+      data:
+        - objectName: %[1]s
+          key: tls.key
+        - objectName: %[1]s
+          key: tls.crt
+  parameters:
+    usePodIdentity: "false"
+    useVMManagedIdentity: "true"
+    userAssignedIdentityID: %[5]s
+    keyvaultName: %[3]s
+    tenantId: %[4]s
+    objects: |
+      array:
+        - |
+          objectName: %[1]s
+          objectType: secret
+`, secretName, namespace, vaultName, tenantId, clientId)
+}
+
+// Returns the strategic merge patch mounting the TLS secret with the secrets store CSI driver in the container of the
+// deployment
+func ingressTlsDeploymentPatch(containerName string, secretName string) (string, error) {
+	patch := map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"volumes": []any{
+						map[string]any{
+							"name": ingressTlsVolumeName,
+							"csi": map[string]any{
+								"driver":           "secrets-store.csi.k8s.io",
+								"readOnly":         true,
+								"volumeAttributes": map[string]any{"secretProviderClass": secretName},
+							},
+						},
+					},
+					"containers": []any{
+						map[string]any{
+							"name": containerName,
+							"volumeMounts": []any{
+								map[string]any{
+									"name":      ingressTlsVolumeName,
+									"mountPath": "/mnt/" + ingressTlsVolumeName,
+									"readOnly":  true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	contents, err := json.Marshal(patch)
+	if err != nil {
+		return "", err
+	}
+
+	return string(contents), nil
+}
+
+// Returns the merge patch setting the TLS section of the ingress to the TLS secret of the host
+func ingressTlsIngressPatch(host string, secretName string) (string, error) {
+	contents, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"tls": []any{
+				map[string]any{
+					"hosts":      []string{strings.ToLower(host)},
+					"secretName": secretName,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(contents), nil
+}
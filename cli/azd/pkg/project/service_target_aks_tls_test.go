@@ -0,0 +1,320 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const testVaultId = "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.KeyVault/vaults/kv-test"
+
+func Test_Deploy_IngressTls(t *testing.T) {
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+	setupMocksForKeyVaultSecretsProvider(mockContext)
+
+	// The certificate doesn't exist until it is created
+	var certificatePolicy map[string]any
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "kv-test.vault.azure.net" &&
+			request.URL.Path == "/certificates/api-tls"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if certificatePolicy == nil {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"id":         "https://kv-test.vault.azure.net/certificates/api-tls/1",
+			"attributes": map[string]any{"exp": time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/certificates/api-tls/create"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+		certificatePolicy = body["policy"].(map[string]any)
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusAccepted, map[string]any{"status": "inProgress"})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/certificates/api-tls/pending"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"status": "completed"})
+	})
+
+	var roleAssignment armauthorization.RoleAssignmentCreateParameters
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/roleAssignments/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.True(t, strings.HasPrefix(request.URL.Path, testVaultId+"/providers/Microsoft.Authorization/"))
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&roleAssignment))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, armauthorization.RoleAssignment{})
+	})
+
+	appliedManifests := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl apply -f -")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		manifest, err := io.ReadAll(args.StdIn)
+		require.NoError(t, err)
+		appliedManifests = append(appliedManifests, string(manifest))
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get deployment api-deployment -o jsonpath=")
+	}).Respond(exec.NewRunResult(0, "api", ""))
+
+	patches := map[string]string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl patch")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		patches[args.Args[1]+"/"+args.Args[2]] = args.Args[6]
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Namespace = "api-ns"
+	serviceConfig.K8s.Ingress.Tls = &AksIngressTlsOptions{
+		Host:     NewExpandableString("${API_HOSTNAME}"),
+		KeyVault: NewExpandableString("${AZURE_KEY_VAULT_NAME}"),
+	}
+	env := createEnv()
+	env.DotenvSet("API_HOSTNAME", "API.contoso.com")
+	env.DotenvSet("AZURE_KEY_VAULT_NAME", "kv-test")
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, env)
+	err = setupK8sManifests(t, fs, serviceConfig)
+	require.NoError(t, err)
+
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.NoError(t, err)
+
+	require.Equal(t, "CN=api.contoso.com", certificatePolicy["x509_props"].(map[string]any)["subject"])
+	require.Equal(t, map[string]any{"name": "Self"}, certificatePolicy["issuer"])
+
+	require.Equal(t, "SECRETS_PROVIDER_ID", *roleAssignment.Properties.PrincipalID)
+	require.True(t, strings.HasSuffix(*roleAssignment.Properties.RoleDefinitionID, keyVaultSecretsUserRoleIds[0]))
+
+	require.Contains(t, appliedManifests,
+		ingressTlsSecretProviderClass("api-ns", "api-tls", "kv-test", "TENANT_ID", "SECRETS_PROVIDER_CLIENT_ID"))
+	require.Equal(t, "api-tls", env.Dotenv()["SERVICE_API_TLS_SECRET_NAME"])
+
+	deploymentPatch, err := ingressTlsDeploymentPatch("api", "api-tls")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"deployment/api-deployment": deploymentPatch,
+		"ingress/api-ingress":       `{"spec":{"tls":[{"hosts":["api.contoso.com"],"secretName":"api-tls"}]}}`,
+	}, patches)
+}
+
+func Test_Deploy_IngressTls_No_Secrets_Provider(t *testing.T) {
+	fs := vfs.NewMemFs()
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	serviceConfig.K8s.Ingress.Tls = &AksIngressTlsOptions{
+		Host:     NewExpandableString("api.contoso.com"),
+		KeyVault: NewExpandableString("kv-test"),
+	}
+
+	serviceTarget := createAksServiceTarget(mockContext, fs, serviceConfig, createEnv())
+	scope := environment.NewTargetResource("SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		Details: &dockerPackageResult{
+			ImageTag: "IMAGE_TAG",
+		},
+	}, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.ErrorContains(t, err, "the Azure Key Vault secrets provider add-on of AKS cluster 'CLUSTER_NAME' is not enabled")
+}
+
+func Test_ensureIngressCertificate(t *testing.T) {
+	policy := ingressCertificatePolicy(&AksIngressTlsOptions{Issuer: "DigiCert", ValidityInMonths: 6}, "api.contoso.com")
+	require.Equal(t, "DigiCert", policy.Issuer)
+	require.Equal(t, 6, policy.ValidityInMonths)
+
+	tests := []struct {
+		name     string
+		expires  time.Time
+		dnsNames []string
+		renewed  bool
+	}{
+		{"Valid", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), []string{"api.contoso.com"}, false},
+		{"ExpiresSoon", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), []string{"api.contoso.com"}, true},
+		{"OtherHost", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), []string{"www.contoso.com"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && request.URL.Path == "/certificates/api-tls"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+					"id":         "https://kv-test.vault.azure.net/certificates/api-tls/1",
+					"attributes": map[string]any{"exp": test.expires.Unix()},
+					"policy": map[string]any{
+						"x509_props": map[string]any{"sans": map[string]any{"dns_names": test.dnsNames}},
+					},
+				})
+			})
+
+			renewed := false
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPost && request.URL.Path == "/certificates/api-tls/create"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				renewed = true
+				return mocks.CreateHttpResponseWithBody(request, http.StatusAccepted, map[string]any{"status": "inProgress"})
+			})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && request.URL.Path == "/certificates/api-tls/pending"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"status": "completed"})
+			})
+
+			serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+			target := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv()).(*aksTarget)
+			mockClock := clock.NewMock()
+			mockClock.Set(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			target.clock = mockClock
+
+			scope := environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+			err := target.ensureIngressCertificate(
+				*mockContext.Context, scope, "kv-test", "api-tls", ingressCertificatePolicy(&AksIngressTlsOptions{},
+					"api.contoso.com"))
+			require.NoError(t, err)
+			require.Equal(t, test.renewed, renewed)
+		})
+	}
+}
+
+func Test_ensureIngressCertificate_Timeout(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/certificates/api-tls"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/certificates/api-tls/create"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusAccepted, map[string]any{"status": "inProgress"})
+	})
+
+	// The issuer never issues the certificate
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/certificates/api-tls/pending"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"status": "inProgress"})
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AksTarget, ServiceLanguageTypeScript)
+	target := createAksServiceTarget(mockContext, vfs.NewMemFs(), serviceConfig, createEnv()).(*aksTarget)
+	mockClock := clock.NewMock()
+	target.clock = mockClock
+	target.waits = DefaultServiceTargetWaits()
+	target.waits.AksCertificate = wait.Config{Timeout: time.Minute, Interval: 10 * time.Second}
+
+	scope := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "CLUSTER_NAME", string(infra.AzureResourceTypeManagedCluster))
+	result := make(chan error, 1)
+	go func() {
+		result <- target.ensureIngressCertificate(
+			*mockContext.Context, scope, "kv-test", "api-tls", ingressCertificatePolicy(&AksIngressTlsOptions{},
+				"api.contoso.com"))
+	}()
+
+	// The mock clock elapses the timeout, not the wall clock
+	for {
+		select {
+		case err := <-result:
+			require.ErrorIs(t, err, wait.ErrTimeout)
+			require.ErrorContains(t, err, "waiting for key vault certificate 'api-tls' to be issued")
+			return
+		case <-time.After(time.Millisecond):
+			mockClock.Add(10 * time.Second)
+		}
+	}
+}
+
+// Mocks a cluster with the Key Vault secrets provider add-on, and the Key Vault of the certificates
+func setupMocksForKeyVaultSecretsProvider(mockContext *mocks.MockContext) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/managedClusters/CLUSTER_NAME")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{
+				AddonProfiles: map[string]*armcontainerservice.ManagedClusterAddonProfile{
+					"azureKeyvaultSecretsProvider": {
+						Enabled: convert.RefOf(true),
+						Identity: &armcontainerservice.ManagedClusterAddonProfileIdentity{
+							ClientID: convert.RefOf("SECRETS_PROVIDER_CLIENT_ID"),
+							ObjectID: convert.RefOf("SECRETS_PROVIDER_ID"),
+						},
+					},
+				},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			request.URL.Path == "/subscriptions/SUB_ID/providers/Microsoft.KeyVault/vaults"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []any{
+				map[string]any{
+					"id":   testVaultId,
+					"name": "kv-test",
+					"properties": map[string]any{
+						"tenantId":                "TENANT_ID",
+						"enableRbacAuthorization": true,
+					},
+				},
+			},
+		})
+	})
+}
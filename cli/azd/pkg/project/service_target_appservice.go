@@ -5,7 +5,9 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/slices"
 )
 
 type appServiceTarget struct {
@@ -53,7 +56,7 @@ func (st *appServiceTarget) Package(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
 			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
-			zipFilePath, err := createDeployableZip(serviceConfig.Name, packageOutput.PackagePath)
+			zipFilePath, excluded, err := createDeployableZip(serviceConfig, packageOutput.PackagePath)
 			if err != nil {
 				task.SetError(err)
 				return
@@ -62,6 +65,7 @@ func (st *appServiceTarget) Package(
 			task.SetResult(&ServicePackageResult{
 				Build:       packageOutput.Build,
 				PackagePath: zipFilePath,
+				Excluded:    append(slices.Clone(packageOutput.Excluded), excluded...),
 			})
 		},
 	)
@@ -90,6 +94,14 @@ func (st *appServiceTarget) Deploy(
 			defer os.Remove(packageOutput.PackagePath)
 			defer zipFile.Close()
 
+			if len(serviceConfig.AppSettings) > 0 {
+				task.SetProgress(NewServiceProgress("Updating app settings"))
+				if err := updateAppSettings(ctx, st.cli, st.env, serviceConfig, targetResource); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
 			task.SetProgress(NewServiceProgress("Uploading deployment package"))
 			res, err := st.cli.DeployAppServiceZip(
 				ctx,
@@ -97,6 +109,7 @@ func (st *appServiceTarget) Deploy(
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
 				zipFile,
+				zipDeployProgress(task),
 			)
 			if err != nil {
 				task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
@@ -127,6 +140,15 @@ func (st *appServiceTarget) Deploy(
 	)
 }
 
+// zipDeployProgress reports the percent of the deployment package uploaded as the progress of the deployment
+func zipDeployProgress(
+	task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress],
+) func(percent int) {
+	return func(percent int) {
+		task.SetProgress(NewServiceProgress(fmt.Sprintf("Uploading deployment package (%d%%)", percent)))
+	}
+}
+
 // Gets the exposed endpoints for the App Service
 func (st *appServiceTarget) Endpoints(
 	ctx context.Context,
@@ -151,6 +173,108 @@ func (st *appServiceTarget) Endpoints(
 	return endpoints, nil
 }
 
+// Runs the command on the Kudu site of the app service of the service. App Service doesn't allocate terminals for
+// commands, so commands run non-interactively and their output is written once they exit.
+func (st *appServiceTarget) Exec(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ExecOptions,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	result, err := st.cli.ExecAppServiceCommand(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		strings.Join(options.Command, " "),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(options.Handles.Stdout, result.Output); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(options.Handles.Stderr, result.Error); err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 {
+		return fmt.Errorf("command exited with code %d", result.ExitCode)
+	}
+
+	return nil
+}
+
+// Streams the logs of the app service
+func (st *appServiceTarget) Logs(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if !options.Follow {
+		return errors.New("the logs of app services can only be streamed, run with --follow")
+	}
+
+	return st.cli.StreamAppServiceLogs(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		"",
+		writer,
+	)
+}
+
+// Restarts the app service
+func (st *appServiceTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return st.cli.RestartAppService(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+}
+
+// Sets the number of instances of the app service plan of the app service. The other apps of the plan are scaled too.
+func (st *appServiceTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	replicas int,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return st.cli.ScaleAppServicePlan(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		replicas,
+	)
+}
+
 func (st *appServiceTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -166,3 +290,30 @@ func (st *appServiceTarget) validateTargetResource(
 
 	return nil
 }
+
+// updateAppSettings sets the app settings of the service, with the references to the environment substituted, on the
+// app service or function app of the target resource
+func updateAppSettings(
+	ctx context.Context,
+	cli azcli.AzCli,
+	env *environment.Environment,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	settings := map[string]string{}
+	for name, value := range serviceConfig.AppSettings {
+		substituted, err := value.Envsubst(env.Getenv)
+		if err != nil {
+			return fmt.Errorf("evaluating app setting '%s': %w", name, err)
+		}
+		settings[name] = substituted
+	}
+
+	return cli.UpdateAppServiceAppSettings(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		settings,
+	)
+}
@@ -5,12 +5,18 @@ package project
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,3 +63,46 @@ func TestNewAppServiceTargetTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func Test_UpdateAppSettings(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICE_API_ENDPOINT_URL": "https://api.azurewebsites.net",
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/sites/WEB/config/appsettings/list")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.StringDictionary{})
+	})
+
+	var updated armappservice.StringDictionary
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/sites/WEB/config/appsettings")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &updated))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updated)
+	})
+
+	serviceConfig := createTestServiceConfig("./src/web", AppServiceTarget, ServiceLanguageJavaScript)
+	serviceConfig.AppSettings = map[string]ExpandableString{
+		"API_URL": NewExpandableString("${SERVICE_API_ENDPOINT_URL}/api"),
+	}
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "WEB", string(infra.AzureResourceTypeWebSite))
+
+	err := updateAppSettings(
+		*mockContext.Context,
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		env,
+		serviceConfig,
+		targetResource,
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*string{
+		"API_URL": convert.RefOf("https://api.azurewebsites.net/api"),
+	}, updated.Properties)
+}
@@ -6,7 +6,10 @@ package project
 import (
 	"context"
 	"fmt"
+	"io"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
@@ -14,8 +17,100 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"golang.org/x/exp/slices"
 )
 
+// The Azure Container Apps options
+type ContainerAppOptions struct {
+	// The secrets of the container app. On deploy, the secrets the container app doesn't have or has with another value
+	// or reference are set, the other secrets of the container app are kept.
+	Secrets []ContainerAppSecretOptions `yaml:"secrets,omitempty"`
+	// The ingress of the container app, applied on each deploy. Declaring it enables the ingress
+	Ingress *ContainerAppIngressOptions `yaml:"ingress,omitempty"`
+	// The scale settings and rules of the revisions of the container app, applied on each deploy
+	Scale *ContainerAppScaleOptions `yaml:"scale,omitempty"`
+}
+
+// The ingress options of a container app. The settings which aren't set are left as configured by the infrastructure.
+type ContainerAppIngressOptions struct {
+	// Whether the container app is reachable from outside of the container apps environment
+	External *bool `yaml:"external,omitempty"`
+	// The port of the container the ingress forwards traffic to
+	TargetPort int `yaml:"targetPort,omitempty"`
+	// The transport protocol, one of auto, http, http2 or tcp
+	Transport string `yaml:"transport,omitempty"`
+	// The CORS policy of the ingress
+	Cors *ContainerAppCorsOptions `yaml:"cors,omitempty"`
+}
+
+// The CORS policy options of the ingress of a container app
+type ContainerAppCorsOptions struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowedMethods   []string `yaml:"allowedMethods,omitempty"`
+	AllowedHeaders   []string `yaml:"allowedHeaders,omitempty"`
+	ExposeHeaders    []string `yaml:"exposeHeaders,omitempty"`
+	AllowCredentials bool     `yaml:"allowCredentials,omitempty"`
+	// The number of seconds browsers cache the result of preflight requests
+	MaxAge int `yaml:"maxAge,omitempty"`
+}
+
+// The scale options of the revisions of a container app
+type ContainerAppScaleOptions struct {
+	MinReplicas *int `yaml:"minReplicas,omitempty"`
+	MaxReplicas *int `yaml:"maxReplicas,omitempty"`
+	// The scale rules, replacing the rules of the container app when declared
+	Rules []ContainerAppScaleRuleOptions `yaml:"rules,omitempty"`
+}
+
+// The options of a scale rule of a container app, either an HTTP rule or a custom KEDA rule
+type ContainerAppScaleRuleOptions struct {
+	Name string `yaml:"name"`
+	// The HTTP scale rule
+	Http *ContainerAppHttpScaleRuleOptions `yaml:"http,omitempty"`
+	// The custom KEDA scale rule
+	Custom *ContainerAppCustomScaleRuleOptions `yaml:"custom,omitempty"`
+}
+
+// The options of an HTTP scale rule of a container app
+type ContainerAppHttpScaleRuleOptions struct {
+	// The number of concurrent requests per replica the container app scales out at
+	Concurrency int `yaml:"concurrency"`
+}
+
+// The options of a custom KEDA scale rule of a container app
+type ContainerAppCustomScaleRuleOptions struct {
+	// The type of the KEDA scaler, ex) azure-servicebus
+	Type string `yaml:"type"`
+	// The metadata of the KEDA scaler, ex) queueName
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+	// The secrets of the container app the trigger parameters of the scaler are read from
+	Auth []ContainerAppScaleRuleAuthOptions `yaml:"auth,omitempty"`
+}
+
+// The secret of the container app a trigger parameter of a KEDA scaler is read from
+type ContainerAppScaleRuleAuthOptions struct {
+	SecretRef        string `yaml:"secretRef"`
+	TriggerParameter string `yaml:"triggerParameter"`
+}
+
+// The options of a secret of a container app, with either a value or a reference to a Key Vault secret
+type ContainerAppSecretOptions struct {
+	// The name of the secret, ex) db-password
+	Name string `yaml:"name"`
+	// The value of the secret, ex) ${DB_PASSWORD} from the environment
+	Value ExpandableString `yaml:"value,omitempty"`
+	// The URL of the Key Vault secret, ex) https://<vault>.vault.azure.net/secrets/<name>
+	KeyVaultUrl ExpandableString `yaml:"keyVaultUrl,omitempty"`
+	// The identity the container app reads the Key Vault secret with, 'system' or the resource id of a user assigned
+	// identity. Defaults to system
+	Identity ExpandableString `yaml:"identity,omitempty"`
+	// The environment variable of the container referencing the secret, ex) DB_PASSWORD
+	EnvVar string `yaml:"envVar,omitempty"`
+}
+
+// Secret names are lower case alphanumeric characters, '-' and '.'
+var containerAppSecretNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
 type containerAppTarget struct {
 	env                 *environment.Environment
 	containerHelper     *ContainerHelper
@@ -92,6 +187,28 @@ func (at *containerAppTarget) Deploy(
 				return
 			}
 
+			var env map[string]string
+			if serviceConfig.AppHost != nil {
+				task.SetProgress(NewServiceProgress("Resolving environment of app host resource"))
+				env, err = at.appHostEnvironment(ctx, serviceConfig)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			secrets, err := at.secrets(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			appConfig, err := containerAppConfig(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			imageName := at.env.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME")
 			task.SetProgress(NewServiceProgress("Updating container app revision"))
 			err = at.containerAppService.AddRevision(
@@ -100,6 +217,9 @@ func (at *containerAppTarget) Deploy(
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
 				imageName,
+				env,
+				secrets,
+				appConfig,
 			)
 			if err != nil {
 				task.SetError(fmt.Errorf("updating container app service: %w", err))
@@ -150,6 +270,328 @@ func (at *containerAppTarget) Endpoints(
 	}
 }
 
+// Runs the command in the container of the container app of the service
+func (at *containerAppTarget) Exec(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ExecOptions,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if targetResource.ResourceName() == "" {
+		return fmt.Errorf("the container app of service '%s' was not found, run 'azd deploy' first", serviceConfig.Name)
+	}
+
+	execOptions := containerapps.ExecOptions{
+		Command: strings.Join(options.Command, " "),
+		Stdin:   options.Handles.Stdin,
+		Stdout:  options.Handles.Stdout,
+		Stderr:  options.Handles.Stderr,
+	}
+
+	if options.Tty {
+		restore, err := options.Handles.MakeRaw()
+		if err != nil {
+			return err
+		}
+		defer restore()
+
+		if width, height, err := options.Handles.TerminalSize(); err == nil {
+			execOptions.Width = width
+			execOptions.Height = height
+		}
+	}
+
+	return at.containerAppService.Exec(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		execOptions,
+	)
+}
+
+// Writes the console logs of the containers of the container app of the service
+func (at *containerAppTarget) Logs(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if targetResource.ResourceName() == "" {
+		return fmt.Errorf("the container app of service '%s' was not found, run 'azd deploy' first", serviceConfig.Name)
+	}
+
+	return at.containerAppService.Logs(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		containerapps.LogsOptions{Since: options.Since, Follow: options.Follow},
+		writer,
+	)
+}
+
+// Restarts the latest revision of the container app of the service
+func (at *containerAppTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if targetResource.ResourceName() == "" {
+		return fmt.Errorf("the container app of service '%s' was not found, run 'azd deploy' first", serviceConfig.Name)
+	}
+
+	return at.containerAppService.RestartRevision(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+}
+
+// Deactivates the revision the interrupted deploy added to the container app, when the traffic wasn't moved to it yet.
+// Container apps in single revision mode keep serving their previous revision until the new revision is ready.
+func (at *containerAppTarget) CleanupDeploy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if targetResource.ResourceName() == "" {
+		return nil
+	}
+
+	return at.containerAppService.DeactivateUnservedRevision(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+}
+
+// appHostEnvironment returns the environment variables of the app host resource of the service. The other resources it
+// references are reached at the names of their container apps, within the container apps environment.
+func (at *containerAppTarget) appHostEnvironment(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (map[string]string, error) {
+	resolveHost := func(resourceName string) (string, error) {
+		resourceService, has := serviceConfig.Project.Services[resourceName]
+		if !has || resourceService.AppHost == nil {
+			return "", fmt.Errorf("resource '%s' isn't deployed as a service", resourceName)
+		}
+
+		targetResource, err := at.resourceManager.GetTargetResource(ctx, at.env.GetSubscriptionId(), resourceService)
+		if err != nil {
+			return "", fmt.Errorf("finding container app of resource '%s': %w", resourceName, err)
+		}
+
+		return targetResource.ResourceName(), nil
+	}
+
+	env, err := serviceConfig.AppHost.Manifest.Environment(serviceConfig.AppHost.Name, resolveHost)
+	if err != nil {
+		return nil, fmt.Errorf("resolving environment of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return env, nil
+}
+
+// secrets resolves the secrets of the container app of the service from the environment
+func (at *containerAppTarget) secrets(serviceConfig *ServiceConfig) ([]*containerapps.Secret, error) {
+	secrets := []*containerapps.Secret{}
+	envVars := map[string]string{}
+	for _, options := range serviceConfig.ContainerApp.Secrets {
+		if !containerAppSecretNameRegex.MatchString(options.Name) {
+			return nil, fmt.Errorf(
+				"secret name '%s' of service '%s' is invalid. Secret names contain only lower case letters, digits, "+
+					"'-' and '.'", options.Name, serviceConfig.Name)
+		}
+
+		if slices.ContainsFunc(secrets, func(s *containerapps.Secret) bool { return s.Name == options.Name }) {
+			return nil, fmt.Errorf("secret '%s' of service '%s' is declared more than once", options.Name, serviceConfig.Name)
+		}
+
+		if options.EnvVar != "" {
+			if other, has := envVars[options.EnvVar]; has {
+				return nil, fmt.Errorf("secrets '%s' and '%s' of service '%s' both set environment variable '%s'",
+					other, options.Name, serviceConfig.Name, options.EnvVar)
+			}
+			envVars[options.EnvVar] = options.Name
+		}
+
+		secret := &containerapps.Secret{
+			Name:   options.Name,
+			EnvVar: options.EnvVar,
+		}
+
+		var err error
+		if secret.Value, err = options.Value.Envsubst(at.env.Getenv); err != nil {
+			return nil, fmt.Errorf("expanding value of secret '%s': %w", options.Name, err)
+		}
+
+		if secret.KeyVaultUrl, err = options.KeyVaultUrl.Envsubst(at.env.Getenv); err != nil {
+			return nil, fmt.Errorf("expanding Key Vault URL of secret '%s': %w", options.Name, err)
+		}
+
+		if secret.Identity, err = options.Identity.Envsubst(at.env.Getenv); err != nil {
+			return nil, fmt.Errorf("expanding identity of secret '%s': %w", options.Name, err)
+		}
+
+		switch {
+		case secret.Value != "" && secret.KeyVaultUrl != "":
+			return nil, fmt.Errorf("secret '%s' of service '%s' has both a value and a Key Vault URL",
+				options.Name, serviceConfig.Name)
+		case secret.Value == "" && secret.KeyVaultUrl == "":
+			return nil, fmt.Errorf(
+				"secret '%s' of service '%s' is empty. Set its value, ex) from an environment variable with "+
+					"`azd env set`, or its Key Vault URL", options.Name, serviceConfig.Name)
+		case secret.KeyVaultUrl != "" && (secret.Identity == "" || strings.EqualFold(secret.Identity, "system")):
+			secret.Identity = containerapps.SystemIdentity
+		case secret.KeyVaultUrl == "" && secret.Identity != "":
+			return nil, fmt.Errorf("secret '%s' of service '%s' has an identity but no Key Vault URL",
+				options.Name, serviceConfig.Name)
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+// containerAppConfig returns the ingress and scale configuration of the container app of the service, nil when the
+// service doesn't declare any
+func containerAppConfig(serviceConfig *ServiceConfig) (*containerapps.AppConfig, error) {
+	options := serviceConfig.ContainerApp
+	if options.Ingress == nil && options.Scale == nil {
+		return nil, nil
+	}
+
+	config := &containerapps.AppConfig{}
+	if ingress := options.Ingress; ingress != nil {
+		if ingress.TargetPort < 0 || ingress.TargetPort > 65535 {
+			return nil, fmt.Errorf("ingress target port %d of service '%s' is invalid", ingress.TargetPort, serviceConfig.Name)
+		}
+
+		if ingress.Transport != "" && !slices.Contains([]string{"auto", "http", "http2", "tcp"}, ingress.Transport) {
+			return nil, fmt.Errorf(
+				"ingress transport '%s' of service '%s' is invalid. Supported transports are auto, http, http2 and tcp",
+				ingress.Transport, serviceConfig.Name)
+		}
+
+		config.Ingress = &containerapps.Ingress{
+			External:   ingress.External,
+			TargetPort: ingress.TargetPort,
+			Transport:  ingress.Transport,
+		}
+
+		if cors := ingress.Cors; cors != nil {
+			if len(cors.AllowedOrigins) == 0 {
+				return nil, fmt.Errorf("the CORS policy of service '%s' has no allowed origins", serviceConfig.Name)
+			}
+
+			config.Ingress.Cors = &containerapps.Cors{
+				AllowedOrigins:   cors.AllowedOrigins,
+				AllowedMethods:   cors.AllowedMethods,
+				AllowedHeaders:   cors.AllowedHeaders,
+				ExposeHeaders:    cors.ExposeHeaders,
+				AllowCredentials: cors.AllowCredentials,
+				MaxAge:           cors.MaxAge,
+			}
+		}
+	}
+
+	if scale := options.Scale; scale != nil {
+		if (scale.MinReplicas != nil && *scale.MinReplicas < 0) || (scale.MaxReplicas != nil && *scale.MaxReplicas < 1) {
+			return nil, fmt.Errorf("the replicas of service '%s' are invalid", serviceConfig.Name)
+		}
+
+		if scale.MinReplicas != nil && scale.MaxReplicas != nil && *scale.MinReplicas > *scale.MaxReplicas {
+			return nil, fmt.Errorf("the minimum replicas of service '%s' are more than its maximum replicas",
+				serviceConfig.Name)
+		}
+
+		config.Scale = &containerapps.Scale{
+			MinReplicas: scale.MinReplicas,
+			MaxReplicas: scale.MaxReplicas,
+		}
+
+		for _, rule := range scale.Rules {
+			scaleRule, err := containerAppScaleRule(serviceConfig, rule)
+			if err != nil {
+				return nil, err
+			}
+
+			config.Scale.Rules = append(config.Scale.Rules, scaleRule)
+		}
+	}
+
+	return config, nil
+}
+
+func containerAppScaleRule(
+	serviceConfig *ServiceConfig,
+	rule ContainerAppScaleRuleOptions,
+) (*containerapps.ScaleRule, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("a scale rule of service '%s' has no name", serviceConfig.Name)
+	}
+
+	if (rule.Http == nil) == (rule.Custom == nil) {
+		return nil, fmt.Errorf("scale rule '%s' of service '%s' must be either an http or a custom rule",
+			rule.Name, serviceConfig.Name)
+	}
+
+	if rule.Http != nil {
+		if rule.Http.Concurrency < 1 {
+			return nil, fmt.Errorf("the concurrency of scale rule '%s' of service '%s' must be at least 1",
+				rule.Name, serviceConfig.Name)
+		}
+
+		return &containerapps.ScaleRule{
+			Name:            rule.Name,
+			HttpConcurrency: rule.Http.Concurrency,
+		}, nil
+	}
+
+	if rule.Custom.Type == "" {
+		return nil, fmt.Errorf("custom scale rule '%s' of service '%s' has no type", rule.Name, serviceConfig.Name)
+	}
+
+	auth := map[string]string{}
+	for _, a := range rule.Custom.Auth {
+		if a.SecretRef == "" || a.TriggerParameter == "" {
+			return nil, fmt.Errorf("the auth of scale rule '%s' of service '%s' needs a secretRef and a triggerParameter",
+				rule.Name, serviceConfig.Name)
+		}
+
+		auth[a.TriggerParameter] = a.SecretRef
+	}
+
+	return &containerapps.ScaleRule{
+		Name:     rule.Name,
+		Type:     rule.Custom.Type,
+		Metadata: rule.Custom.Metadata,
+		Auth:     auth,
+	}, nil
+}
+
 func (at *containerAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
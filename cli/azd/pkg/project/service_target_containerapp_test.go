@@ -131,7 +131,7 @@ func createContainerAppServiceTarget(
 
 	containerAppService := containerapps.NewContainerAppService(credentialProvider, mockContext.HttpClient, clock.NewMock())
 	containerRegistryService := azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli)
-	containerHelper := NewContainerHelper(env, clock.NewMock(), containerRegistryService, dockerCli)
+	containerHelper := NewContainerHelper(env, clock.NewMock(), containerRegistryService, dockerCli, nil)
 	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
 	resourceManager := NewResourceManager(env, azCli)
 
@@ -215,3 +215,183 @@ func setupMocksForContainerApps(mockContext *mocks.MockContext) {
 	mockazsdk.MockContainerAppUpdate(mockContext, subscriptionId, resourceGroup, appName, containerApp)
 	mockazsdk.MockContainerRegistryTokenExchange(mockContext, subscriptionId, subscriptionId, "REFRESH_TOKEN")
 }
+
+func Test_ContainerApp_Secrets(t *testing.T) {
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"DB_PASSWORD":   "password",
+		"VAULT_URI":     "https://vault.vault.azure.net",
+		"API_IDENTITY":  "IDENTITY_ID",
+		"EMPTY_SECRET":  "",
+		"UNUSED_SECRET": "unused",
+	})
+	serviceTarget := &containerAppTarget{env: env}
+
+	t.Run("Resolved", func(t *testing.T) {
+		serviceConfig := &ServiceConfig{
+			Name: "api",
+			ContainerApp: ContainerAppOptions{
+				Secrets: []ContainerAppSecretOptions{
+					{
+						Name:   "db-password",
+						Value:  NewExpandableString("${DB_PASSWORD}"),
+						EnvVar: "DB_PASSWORD",
+					},
+					{
+						Name:        "api-key",
+						KeyVaultUrl: NewExpandableString("${VAULT_URI}/secrets/api-key"),
+					},
+					{
+						Name:        "other-key",
+						KeyVaultUrl: NewExpandableString("${VAULT_URI}/secrets/other-key"),
+						Identity:    NewExpandableString("${API_IDENTITY}"),
+						EnvVar:      "OTHER_KEY",
+					},
+				},
+			},
+		}
+
+		secrets, err := serviceTarget.secrets(serviceConfig)
+		require.NoError(t, err)
+		require.Equal(t, []*containerapps.Secret{
+			{Name: "db-password", Value: "password", EnvVar: "DB_PASSWORD"},
+			{
+				Name:        "api-key",
+				KeyVaultUrl: "https://vault.vault.azure.net/secrets/api-key",
+				Identity:    containerapps.SystemIdentity,
+			},
+			{
+				Name:        "other-key",
+				KeyVaultUrl: "https://vault.vault.azure.net/secrets/other-key",
+				Identity:    "IDENTITY_ID",
+				EnvVar:      "OTHER_KEY",
+			},
+		}, secrets)
+	})
+
+	invalid := map[string][]ContainerAppSecretOptions{
+		"InvalidName": {{Name: "DB_PASSWORD", Value: NewExpandableString("value")}},
+		"Duplicate": {
+			{Name: "secret", Value: NewExpandableString("value")},
+			{Name: "secret", Value: NewExpandableString("value")},
+		},
+		"DuplicateEnvVar": {
+			{Name: "secret", Value: NewExpandableString("value"), EnvVar: "SECRET"},
+			{Name: "other", Value: NewExpandableString("value"), EnvVar: "SECRET"},
+		},
+		"Empty": {{Name: "secret", Value: NewExpandableString("${EMPTY_SECRET}")}},
+		"ValueAndReference": {
+			{
+				Name:        "secret",
+				Value:       NewExpandableString("${UNUSED_SECRET}"),
+				KeyVaultUrl: NewExpandableString("${VAULT_URI}/secrets/secret"),
+			},
+		},
+		"IdentityWithoutReference": {
+			{Name: "secret", Value: NewExpandableString("value"), Identity: NewExpandableString("${API_IDENTITY}")},
+		},
+	}
+
+	for name, secrets := range invalid {
+		t.Run(name, func(t *testing.T) {
+			serviceConfig := &ServiceConfig{
+				Name:         "api",
+				ContainerApp: ContainerAppOptions{Secrets: secrets},
+			}
+
+			_, err := serviceTarget.secrets(serviceConfig)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_containerAppConfig(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		config, err := containerAppConfig(&ServiceConfig{Name: "api"})
+		require.NoError(t, err)
+		require.Nil(t, config)
+	})
+
+	t.Run("IngressAndScale", func(t *testing.T) {
+		serviceConfig := &ServiceConfig{
+			Name: "api",
+			ContainerApp: ContainerAppOptions{
+				Ingress: &ContainerAppIngressOptions{
+					External:   convert.RefOf(true),
+					TargetPort: 8080,
+					Transport:  "http",
+					Cors:       &ContainerAppCorsOptions{AllowedOrigins: []string{"*"}},
+				},
+				Scale: &ContainerAppScaleOptions{
+					MinReplicas: convert.RefOf(1),
+					MaxReplicas: convert.RefOf(5),
+					Rules: []ContainerAppScaleRuleOptions{
+						{Name: "http", Http: &ContainerAppHttpScaleRuleOptions{Concurrency: 100}},
+						{
+							Name: "queue",
+							Custom: &ContainerAppCustomScaleRuleOptions{
+								Type:     "azure-servicebus",
+								Metadata: map[string]string{"queueName": "orders"},
+								Auth: []ContainerAppScaleRuleAuthOptions{
+									{SecretRef: "sb-connection", TriggerParameter: "connection"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		config, err := containerAppConfig(serviceConfig)
+		require.NoError(t, err)
+		require.Equal(t, &containerapps.AppConfig{
+			Ingress: &containerapps.Ingress{
+				External:   convert.RefOf(true),
+				TargetPort: 8080,
+				Transport:  "http",
+				Cors:       &containerapps.Cors{AllowedOrigins: []string{"*"}},
+			},
+			Scale: &containerapps.Scale{
+				MinReplicas: convert.RefOf(1),
+				MaxReplicas: convert.RefOf(5),
+				Rules: []*containerapps.ScaleRule{
+					{Name: "http", HttpConcurrency: 100},
+					{
+						Name:     "queue",
+						Type:     "azure-servicebus",
+						Metadata: map[string]string{"queueName": "orders"},
+						Auth:     map[string]string{"connection": "sb-connection"},
+					},
+				},
+			},
+		}, config)
+	})
+
+	invalid := map[string]ContainerAppOptions{
+		"TargetPort": {Ingress: &ContainerAppIngressOptions{TargetPort: 70000}},
+		"Transport":  {Ingress: &ContainerAppIngressOptions{Transport: "udp"}},
+		"NoOrigins":  {Ingress: &ContainerAppIngressOptions{Cors: &ContainerAppCorsOptions{}}},
+		"Replicas": {
+			Scale: &ContainerAppScaleOptions{MinReplicas: convert.RefOf(5), MaxReplicas: convert.RefOf(2)},
+		},
+		"RuleWithoutName": {
+			Scale: &ContainerAppScaleOptions{
+				Rules: []ContainerAppScaleRuleOptions{{Http: &ContainerAppHttpScaleRuleOptions{Concurrency: 1}}},
+			},
+		},
+		"RuleWithoutKind": {
+			Scale: &ContainerAppScaleOptions{Rules: []ContainerAppScaleRuleOptions{{Name: "rule"}}},
+		},
+		"CustomRuleWithoutType": {
+			Scale: &ContainerAppScaleOptions{
+				Rules: []ContainerAppScaleRuleOptions{{Name: "rule", Custom: &ContainerAppCustomScaleRuleOptions{}}},
+			},
+		},
+	}
+
+	for name, options := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := containerAppConfig(&ServiceConfig{Name: "api", ContainerApp: options})
+			require.Error(t, err)
+		})
+	}
+}
@@ -5,7 +5,9 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/slices"
 )
 
 // functionAppTarget specifies an Azure Function to deploy to.
@@ -54,7 +57,7 @@ func (f *functionAppTarget) Package(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
 			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
-			zipFilePath, err := createDeployableZip(serviceConfig.Name, packageOutput.PackagePath)
+			zipFilePath, excluded, err := createDeployableZip(serviceConfig, packageOutput.PackagePath)
 			if err != nil {
 				task.SetError(err)
 				return
@@ -63,6 +66,7 @@ func (f *functionAppTarget) Package(
 			task.SetResult(&ServicePackageResult{
 				Build:       packageOutput.Build,
 				PackagePath: zipFilePath,
+				Excluded:    append(slices.Clone(packageOutput.Excluded), excluded...),
 			})
 		},
 	)
@@ -91,6 +95,14 @@ func (f *functionAppTarget) Deploy(
 			defer os.Remove(packageOutput.PackagePath)
 			defer zipFile.Close()
 
+			if len(serviceConfig.AppSettings) > 0 {
+				task.SetProgress(NewServiceProgress("Updating app settings"))
+				if err := updateAppSettings(ctx, f.cli, f.env, serviceConfig, targetResource); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
 			task.SetProgress(NewServiceProgress("Uploading deployment package"))
 			res, err := f.cli.DeployFunctionAppUsingZipFile(
 				ctx,
@@ -98,6 +110,7 @@ func (f *functionAppTarget) Deploy(
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
 				zipFile,
+				zipDeployProgress(task),
 			)
 			if err != nil {
 				task.SetError(err)
@@ -153,6 +166,50 @@ func (f *functionAppTarget) Endpoints(
 	}
 }
 
+// Streams the invocation logs of the functions of the function app
+func (f *functionAppTarget) Logs(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options LogsOptions,
+	writer io.Writer,
+) error {
+	if err := f.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if !options.Follow {
+		return errors.New("the logs of function apps can only be streamed, run with --follow")
+	}
+
+	return f.cli.StreamAppServiceLogs(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		"application/functions/function",
+		writer,
+	)
+}
+
+// Restarts the function app
+func (f *functionAppTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := f.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return f.cli.RestartAppService(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+}
+
 func (f *functionAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
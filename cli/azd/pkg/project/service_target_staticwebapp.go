@@ -5,28 +5,46 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/swa"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 )
 
 // TODO: Enhance for multi-environment support
 // https://github.com/Azure/azure-dev/issues/1152
 const DefaultStaticWebAppEnvironmentName = "default"
 
+// StaticWebAppOptions are the locations of the static web app of a service. References to the environment are substituted,
+// ex) to deploy the output of a build per environment with build/${AZURE_ENV_NAME}.
+type StaticWebAppOptions struct {
+	// The folder of the source of the app, relative to the project, instead of the folder of the service
+	AppLocation ExpandableString `yaml:"appLocation,omitempty"`
+	// The folder of the build output deployed, relative to the app location, instead of dist
+	OutputLocation ExpandableString `yaml:"outputLocation,omitempty"`
+	// The folder of the Azure Functions API deployed with the app, relative to the app location
+	ApiLocation ExpandableString `yaml:"apiLocation,omitempty"`
+}
+
 type staticWebAppTarget struct {
-	env *environment.Environment
-	cli azcli.AzCli
-	swa swa.SwaCli
+	env   *environment.Environment
+	cli   azcli.AzCli
+	swa   swa.SwaCli
+	clock clock.Clock
+	waits *ServiceTargetWaits
 }
 
 // NewStaticWebAppTarget creates a new instance of the Static Web App target
@@ -34,11 +52,15 @@ func NewStaticWebAppTarget(
 	env *environment.Environment,
 	azCli azcli.AzCli,
 	swaCli swa.SwaCli,
+	clock clock.Clock,
+	waits *ServiceTargetWaits,
 ) ServiceTarget {
 	return &staticWebAppTarget{
-		env: env,
-		cli: azCli,
-		swa: swaCli,
+		env:   env,
+		cli:   azCli,
+		swa:   swaCli,
+		clock: clock,
+		waits: waits,
 	}
 }
 
@@ -60,7 +82,16 @@ func (at *staticWebAppTarget) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
-			packagePath := serviceConfig.OutputPath
+			packagePath, err := serviceConfig.StaticWebApp.OutputLocation.Envsubst(at.env.Getenv)
+			if err != nil {
+				task.SetError(fmt.Errorf("evaluating the output location of the static web app: %w", err))
+				return
+			}
+
+			if strings.TrimSpace(packagePath) == "" {
+				packagePath = serviceConfig.OutputPath
+			}
+
 			if strings.TrimSpace(packagePath) == "" {
 				packagePath = "build"
 			}
@@ -87,6 +118,13 @@ func (at *staticWebAppTarget) Deploy(
 				return
 			}
 
+			task.SetProgress(NewServiceProgress("Validating deployment artifacts"))
+			locations, err := at.deployLocations(serviceConfig, packageOutput)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			// Get the static webapp deployment token
 			task.SetProgress(NewServiceProgress("Retrieving deployment token"))
 			deploymentToken, err := at.cli.GetStaticWebAppApiKey(
@@ -108,10 +146,11 @@ func (at *staticWebAppTarget) Deploy(
 				targetResource.SubscriptionId(),
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
-				serviceConfig.RelativePath,
+				locations.app,
 				packageOutput.PackagePath,
 				DefaultStaticWebAppEnvironmentName,
-				*deploymentToken)
+				*deploymentToken,
+				locations.options)
 
 			log.Println(res)
 
@@ -171,6 +210,74 @@ func (at *staticWebAppTarget) Endpoints(
 	}
 }
 
+// staticWebAppLocations are the locations of the static web app deployed, relative to the project
+type staticWebAppLocations struct {
+	app     string
+	options swa.DeployOptions
+}
+
+// deployLocations resolves the locations of the app, API and configuration file of the static web app, checking they
+// exist and the configuration file is valid before anything is uploaded
+func (at *staticWebAppTarget) deployLocations(
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) (*staticWebAppLocations, error) {
+	options := serviceConfig.StaticWebApp
+	appLocation, err := options.AppLocation.Envsubst(at.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating the app location of the static web app: %w", err)
+	}
+
+	if appLocation == "" {
+		appLocation = serviceConfig.RelativePath
+	}
+
+	apiLocation, err := options.ApiLocation.Envsubst(at.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating the api location of the static web app: %w", err)
+	}
+
+	projectPath := serviceConfig.Project.Path
+	appPath := filepath.Join(projectPath, appLocation)
+	outputPath := filepath.Join(appPath, packageOutput.PackagePath)
+	if _, err := os.Stat(outputPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"the output location '%s' of the static web app doesn't exist, check that the app was built to it, "+
+				"or set 'dist' or 'staticWebApp.outputLocation' in %s",
+			outputPath, azdcontext.ProjectFileName)
+	}
+
+	locations := &staticWebAppLocations{app: appLocation}
+	if apiLocation != "" {
+		locations.options.ApiLocation = filepath.Join(appLocation, apiLocation)
+		if _, err := os.Stat(filepath.Join(projectPath, locations.options.ApiLocation)); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf(
+				"the api location '%s' of the static web app doesn't exist",
+				filepath.Join(projectPath, locations.options.ApiLocation))
+		}
+	}
+
+	// The configuration file of the build output takes precedence over the one of the source of the app
+	configPath, err := swa.FindConfigFile(outputPath, appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if configPath != "" {
+		if err := swa.ValidateConfigFile(configPath); err != nil {
+			return nil, err
+		}
+
+		configLocation, err := filepath.Rel(projectPath, filepath.Dir(configPath))
+		if err != nil {
+			return nil, err
+		}
+		locations.options.ConfigLocation = configLocation
+	}
+
+	return locations, nil
+}
+
 func (at *staticWebAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -188,10 +295,8 @@ func (at *staticWebAppTarget) validateTargetResource(
 }
 
 func (at *staticWebAppTarget) verifyDeployment(ctx context.Context, targetResource *environment.TargetResource) error {
-	retries := 0
-	const maxRetries = 10
-
-	for {
+	status := ""
+	err := wait.Until(ctx, at.clock, at.waits.StaticWebApp, func(ctx context.Context) (bool, error) {
 		envProps, err := at.cli.GetStaticWebAppEnvironmentProperties(
 			ctx,
 			targetResource.SubscriptionId(),
@@ -200,21 +305,16 @@ func (at *staticWebAppTarget) verifyDeployment(ctx context.Context, targetResour
 			DefaultStaticWebAppEnvironmentName,
 		)
 		if err != nil {
-			return fmt.Errorf("failed verifying static web app deployment: %w", err)
+			return false, fmt.Errorf("failed verifying static web app deployment: %w", err)
 		}
 
-		if envProps.Status == "Ready" {
-			break
-		}
-
-		retries++
+		status = envProps.Status
+		return status == "Ready", nil
+	})
 
-		if retries >= maxRetries {
-			return fmt.Errorf("failed verifying static web app deployment. Still in %s state", envProps.Status)
-		}
-
-		time.Sleep(5 * time.Second)
+	if errors.Is(err, wait.ErrTimeout) {
+		return fmt.Errorf("failed verifying static web app deployment. Still in %s state", status)
 	}
 
-	return nil
+	return err
 }
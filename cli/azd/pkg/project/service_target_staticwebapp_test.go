@@ -5,12 +5,22 @@ package project
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/swa"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,3 +67,135 @@ func TestNewStaticWebAppTargetTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestStaticWebAppTargetVerifyDeployment(t *testing.T) {
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeStaticWebSite))
+
+	tests := map[string]struct {
+		statuses    []armappservice.BuildStatus
+		timeout     time.Duration
+		expectError bool
+	}{
+		"Ready": {
+			statuses: []armappservice.BuildStatus{armappservice.BuildStatusReady},
+			timeout:  time.Minute,
+		},
+		"ReadyAfterUploading": {
+			statuses: []armappservice.BuildStatus{
+				armappservice.BuildStatusUploading,
+				armappservice.BuildStatusUploading,
+				armappservice.BuildStatusReady,
+			},
+			timeout: time.Minute,
+		},
+		"StillUploading": {
+			statuses:    []armappservice.BuildStatus{armappservice.BuildStatusUploading},
+			expectError: true,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			calls := 0
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet &&
+					strings.Contains(request.URL.Path, "/providers/Microsoft.Web/staticSites/res/builds/default")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				status := data.statuses[len(data.statuses)-1]
+				if calls < len(data.statuses) {
+					status = data.statuses[calls]
+				}
+				calls++
+
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK,
+					armappservice.StaticSitesClientGetStaticSiteBuildResponse{
+						StaticSiteBuildARMResource: armappservice.StaticSiteBuildARMResource{
+							Properties: &armappservice.StaticSiteBuildARMResourceProperties{
+								Hostname: convert.RefOf("res.azurestaticapps.net"),
+								Status:   convert.RefOf(status),
+							},
+						},
+					})
+			})
+
+			// no interval between checks so the test doesn't wait, a zero timeout checks once
+			serviceTarget := &staticWebAppTarget{
+				cli:   mockazcli.NewAzCliFromMockContext(mockContext),
+				clock: clock.New(),
+				waits: &ServiceTargetWaits{
+					StaticWebApp: wait.Config{Timeout: data.timeout},
+				},
+			}
+
+			err := serviceTarget.verifyDeployment(*mockContext.Context, targetResource)
+			if data.expectError {
+				require.ErrorContains(t, err, "Still in Uploading state")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, len(data.statuses), calls)
+			}
+		})
+	}
+}
+
+func TestStaticWebAppTargetDeployLocations(t *testing.T) {
+	projectPath := t.TempDir()
+	appPath := filepath.Join(projectPath, "src", "web")
+	require.NoError(t, os.MkdirAll(filepath.Join(appPath, "dist", "dev"), 0755))
+
+	serviceConfig := createTestServiceConfig(filepath.Join("src", "web"), StaticWebAppTarget, ServiceLanguageJavaScript)
+	serviceConfig.Project.Path = projectPath
+	serviceConfig.StaticWebApp = StaticWebAppOptions{
+		ApiLocation: NewExpandableString("api"),
+	}
+
+	serviceTarget := &staticWebAppTarget{
+		env: environment.EphemeralWithValues("dev", nil),
+	}
+	packageOutput := &ServicePackageResult{PackagePath: filepath.Join("dist", "dev")}
+
+	_, err := serviceTarget.deployLocations(serviceConfig, packageOutput)
+	require.ErrorContains(t, err, "the api location")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(appPath, "api"), 0755))
+	locations, err := serviceTarget.deployLocations(serviceConfig, packageOutput)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("src", "web"), locations.app)
+	require.Equal(t, swa.DeployOptions{ApiLocation: filepath.Join("src", "web", "api")}, locations.options)
+
+	configPath := filepath.Join(appPath, "dist", "dev", swa.ConfigFileName)
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"navigationFallback": {}}`), 0600))
+	_, err = serviceTarget.deployLocations(serviceConfig, packageOutput)
+	require.ErrorContains(t, err, "navigationFallback: missing 'rewrite'")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"navigationFallback": {"rewrite": "/index.html"}}`), 0600))
+	locations, err = serviceTarget.deployLocations(serviceConfig, packageOutput)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("src", "web", "dist", "dev"), locations.options.ConfigLocation)
+
+	_, err = serviceTarget.deployLocations(serviceConfig, &ServicePackageResult{PackagePath: "build"})
+	require.ErrorContains(t, err, "the output location")
+}
+
+func TestStaticWebAppTargetPackage(t *testing.T) {
+	serviceConfig := createTestServiceConfig(filepath.Join("src", "web"), StaticWebAppTarget, ServiceLanguageJavaScript)
+	serviceTarget := &staticWebAppTarget{
+		env: environment.EphemeralWithValues("dev", nil),
+	}
+
+	packageTask := serviceTarget.Package(context.Background(), serviceConfig, &ServicePackageResult{})
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, "build", packageResult.PackagePath)
+
+	serviceConfig.OutputPath = "dist"
+	serviceConfig.StaticWebApp.OutputLocation = NewExpandableString("dist/${AZURE_ENV_NAME}")
+	packageTask = serviceTarget.Package(context.Background(), serviceConfig, &ServicePackageResult{})
+	logProgress(packageTask)
+	packageResult, err = packageTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, "dist/dev", packageResult.PackagePath)
+}
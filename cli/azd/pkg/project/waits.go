@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+)
+
+// waitsConfigPath is the path of the waits in the user config, ex) deploy.waits.aks.timeout
+const waitsConfigPath = "deploy.waits"
+
+// ServiceTargetWaits are how long service targets wait for deployed resources to become ready and how often they check
+// them. Users tune them with `azd config set deploy.waits.<target>.timeout|interval <duration>`, ex)
+// `azd config set deploy.waits.aks.timeout 20m`.
+type ServiceTargetWaits struct {
+	// The rollout of deployments, services, ingresses and gateways of AKS services
+	Aks wait.Config
+	// The environment of static web apps becoming ready after a deployment
+	StaticWebApp wait.Config
+	// The Key Vault certificates of the ingresses of AKS services being issued
+	AksCertificate wait.Config
+}
+
+// DefaultServiceTargetWaits returns the waits of service targets when the user config doesn't override them
+func DefaultServiceTargetWaits() *ServiceTargetWaits {
+	return &ServiceTargetWaits{
+		Aks: kubectl.DefaultWaitConfig,
+		StaticWebApp: wait.Config{
+			Timeout:  45 * time.Second,
+			Interval: 5 * time.Second,
+		},
+		AksCertificate: azcli.DefaultKeyVaultCertificateWaitConfig,
+	}
+}
+
+// NewServiceTargetWaits returns the default waits of service targets overridden by the waits of the user config
+func NewServiceTargetWaits(userConfig config.Config) (*ServiceTargetWaits, error) {
+	waits := DefaultServiceTargetWaits()
+	targets := map[string]*wait.Config{
+		"aks":            &waits.Aks,
+		"staticwebapp":   &waits.StaticWebApp,
+		"akscertificate": &waits.AksCertificate,
+	}
+
+	for target, waitConfig := range targets {
+		if err := readWaitDuration(userConfig, target, "timeout", &waitConfig.Timeout); err != nil {
+			return nil, err
+		}
+
+		if err := readWaitDuration(userConfig, target, "interval", &waitConfig.Interval); err != nil {
+			return nil, err
+		}
+
+		if err := waitConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s.%s: %w", waitsConfigPath, target, err)
+		}
+	}
+
+	return waits, nil
+}
+
+// readWaitDuration sets the duration to the duration of the user config at deploy.waits.<target>.<name>, when it's set
+func readWaitDuration(userConfig config.Config, target string, name string, duration *time.Duration) error {
+	path := fmt.Sprintf("%s.%s.%s", waitsConfigPath, target, name)
+	value, has := userConfig.Get(path)
+	if !has {
+		return nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid %s: expected a duration, ex) 30s or 10m", path)
+	}
+
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	*duration = parsed
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceTargetWaits(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		waits, err := NewServiceTargetWaits(config.NewEmptyConfig())
+		require.NoError(t, err)
+		require.Equal(t, DefaultServiceTargetWaits(), waits)
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		userConfig := config.NewEmptyConfig()
+		require.NoError(t, userConfig.Set("deploy.waits.aks.timeout", "20m"))
+		require.NoError(t, userConfig.Set("deploy.waits.staticwebapp.interval", "1s"))
+		require.NoError(t, userConfig.Set("deploy.waits.akscertificate.timeout", "30m"))
+
+		waits, err := NewServiceTargetWaits(userConfig)
+		require.NoError(t, err)
+		require.Equal(t, 20*time.Minute, waits.Aks.Timeout)
+		require.Equal(t, DefaultServiceTargetWaits().Aks.Interval, waits.Aks.Interval)
+		require.Equal(t, DefaultServiceTargetWaits().StaticWebApp.Timeout, waits.StaticWebApp.Timeout)
+		require.Equal(t, time.Second, waits.StaticWebApp.Interval)
+		require.Equal(t, 30*time.Minute, waits.AksCertificate.Timeout)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, value := range []any{"ten minutes", "-1m", 10} {
+			userConfig := config.NewEmptyConfig()
+			require.NoError(t, userConfig.Set("deploy.waits.aks.timeout", value))
+
+			_, err := NewServiceTargetWaits(userConfig)
+			require.ErrorContains(t, err, "deploy.waits.aks")
+		}
+	})
+}
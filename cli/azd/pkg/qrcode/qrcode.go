@@ -0,0 +1,544 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package qrcode encodes short text, ex) URLs, as QR codes which can be rendered in a terminal. Only the byte mode,
+// medium error correction and versions 1 to 9 (up to 180 bytes) are supported, which is all azd needs.
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrTooLong is returned when the text doesn't fit in the largest supported QR code.
+var ErrTooLong = errors.New("text is too long to encode as a QR code")
+
+// blockLayout is the error correction block structure of a version at the medium error correction level.
+type blockLayout struct {
+	// The number of error correction codewords of each block
+	ecPerBlock int
+	// The number of blocks with shortData data codewords, followed by blocks with shortData+1 data codewords
+	shortBlocks int
+	shortData   int
+	longBlocks  int
+}
+
+func (b blockLayout) dataCodewords() int {
+	return b.shortBlocks*b.shortData + b.longBlocks*(b.shortData+1)
+}
+
+// layouts are the block structures of versions 1 to 9 at the medium error correction level, indexed by version - 1
+var layouts = []blockLayout{
+	{ecPerBlock: 10, shortBlocks: 1, shortData: 16},
+	{ecPerBlock: 16, shortBlocks: 1, shortData: 28},
+	{ecPerBlock: 26, shortBlocks: 1, shortData: 44},
+	{ecPerBlock: 18, shortBlocks: 2, shortData: 32},
+	{ecPerBlock: 24, shortBlocks: 2, shortData: 43},
+	{ecPerBlock: 16, shortBlocks: 4, shortData: 27},
+	{ecPerBlock: 18, shortBlocks: 4, shortData: 31},
+	{ecPerBlock: 22, shortBlocks: 2, shortData: 38, longBlocks: 2},
+	{ecPerBlock: 22, shortBlocks: 3, shortData: 36, longBlocks: 2},
+}
+
+// alignmentCenters are the row and column centers of the alignment patterns, indexed by version - 1
+var alignmentCenters = [][]int{
+	{},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+}
+
+// The format bits of the medium error correction level
+const mediumFormatBits = 0
+
+// QRCode is the matrix of modules of a QR code.
+type QRCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// Encode encodes the text as the smallest QR code it fits in.
+func Encode(text string) (*QRCode, error) {
+	data := []byte(text)
+
+	version := 0
+	for i, layout := range layouts {
+		// the byte mode indicator and the character count take 12 bits
+		if len(data)+2 <= layout.dataCodewords() {
+			version = i + 1
+			break
+		}
+	}
+
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	q := newQRCode(version)
+	q.drawFunctionPatterns(version)
+	q.drawCodewords(interleave(encodeData(data, layouts[version-1].dataCodewords()), layouts[version-1]))
+
+	// apply the mask with the lowest penalty
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		// masks are their own inverse
+		q.applyMask(mask)
+	}
+
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return q, nil
+}
+
+// Size is the number of modules of each side of the QR code.
+func (q *QRCode) Size() int {
+	return q.size
+}
+
+// Dark reports whether the module in the column x and the row y is dark.
+func (q *QRCode) Dark(x int, y int) bool {
+	return q.modules[y][x]
+}
+
+// String renders the QR code with Unicode half blocks, each line of text rendering two rows of modules, surrounded by
+// the quiet zone. Light modules are rendered as blocks, so the QR code reads correctly on terminals with a dark
+// background, where the blocks are the light foreground.
+func (q *QRCode) String() string {
+	const quietZone = 2
+
+	light := func(x int, y int) bool {
+		x, y = x-quietZone, y-quietZone
+		if x < 0 || y < 0 || x >= q.size || y >= q.size {
+			return true
+		}
+
+		return !q.modules[y][x]
+	}
+
+	var sb strings.Builder
+	total := q.size + 2*quietZone
+	for y := 0; y < total; y += 2 {
+		for x := 0; x < total; x++ {
+			top := light(x, y)
+			bottom := y+1 < total && light(x, y+1)
+
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteRune('\n')
+	}
+
+	return sb.String()
+}
+
+func newQRCode(version int) *QRCode {
+	size := version*4 + 17
+	q := &QRCode{
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+
+	for i := 0; i < size; i++ {
+		q.modules[i] = make([]bool, size)
+		q.function[i] = make([]bool, size)
+	}
+
+	return q
+}
+
+func (q *QRCode) setFunction(x int, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+func (q *QRCode) drawFunctionPatterns(version int) {
+	// timing patterns
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	// finder patterns, including their separators
+	for _, center := range [][2]int{{3, 3}, {q.size - 4, 3}, {3, q.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x < 0 || y < 0 || x >= q.size || y >= q.size {
+					continue
+				}
+
+				dist := max(abs(dx), abs(dy))
+				q.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	// alignment patterns, except those overlapping the finder patterns
+	centers := alignmentCenters[version-1]
+	last := len(centers) - 1
+	for i, cy := range centers {
+		for j, cx := range centers {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// reserve the format bits, which are drawn once the mask is known
+	q.drawFormatBits(0)
+
+	if version >= 7 {
+		rem := version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+
+		bits := version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 != 0
+			a, b := q.size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+}
+
+func (q *QRCode) drawFormatBits(mask int) {
+	data := mediumFormatBits<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool {
+		return (bits>>i)&1 != 0
+	}
+
+	// the copy around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	// the copy split between the other finder patterns
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// drawCodewords draws the codewords in the zigzag order, from the bottom right corner, in columns of two modules.
+func (q *QRCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		// skip the vertical timing pattern
+		if right == 6 {
+			right = 5
+		}
+
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if upward {
+					y = q.size - 1 - vert
+				}
+
+				if q.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				q.modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.function[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the QR code is to scan, following the rules of the QR code specification.
+func (q *QRCode) penalty() int {
+	penalty := 0
+	finderLike := []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+	line := make([]bool, q.size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < q.size; i++ {
+			for j := 0; j < q.size; j++ {
+				if vertical {
+					line[j] = q.modules[j][i]
+				} else {
+					line[j] = q.modules[i][j]
+				}
+			}
+
+			// runs of five or more modules of the same color
+			run := 1
+			for j := 1; j <= q.size; j++ {
+				if j < q.size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			// patterns which look like finder patterns
+			for j := 0; j+len(finderLike) <= q.size; j++ {
+				forward, backward := true, true
+				for k, dark := range finderLike {
+					forward = forward && line[j+k] == dark
+					backward = backward && line[j+len(finderLike)-1-k] == dark
+				}
+
+				if forward {
+					penalty += 40
+				}
+				if backward {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < q.size && y+1 < q.size {
+				color := q.modules[y][x]
+				if q.modules[y][x+1] == color && q.modules[y+1][x] == color && q.modules[y+1][x+1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	// the proportion of dark modules far from half
+	total := q.size * q.size
+	deviation := abs(dark*20 - total*10)
+	penalty += ((deviation+total-1)/total - 1) * 10
+
+	return penalty
+}
+
+// encodeData encodes the data in the byte mode, padded to the capacity.
+func encodeData(data []byte, capacity int) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), 8)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	// the terminator, then padding to a byte boundary
+	bits.append(0, min(4, capacity*8-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := make([]byte, capacity)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	for i, pad := len(bits)/8, byte(0xEC); i < capacity; i, pad = i+1, pad^(0xEC^0x11) {
+		codewords[i] = pad
+	}
+
+	return codewords
+}
+
+// interleave splits the data codewords into blocks, appends the error correction codewords of each block, and
+// interleaves the blocks.
+func interleave(data []byte, layout blockLayout) []byte {
+	divisor := reedSolomonDivisor(layout.ecPerBlock)
+
+	blocks := [][]byte{}
+	ecBlocks := [][]byte{}
+	offset := 0
+	for i := 0; i < layout.shortBlocks+layout.longBlocks; i++ {
+		length := layout.shortData
+		if i >= layout.shortBlocks {
+			length++
+		}
+
+		block := data[offset : offset+length]
+		offset += length
+
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+
+	result := []byte{}
+	for i := 0; i <= layout.shortData; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, ecBlock := range ecBlocks {
+			result = append(result, ecBlock[i])
+		}
+	}
+
+	return result
+}
+
+// reedSolomonDivisor returns the coefficients of the generator polynomial of the degree, from the highest power,
+// excluding the leading 1.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+func reedSolomonRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x byte, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value int, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 != 0)
+	}
+}
+
+func min(x int, y int) int {
+	if x < y {
+		return x
+	}
+
+	return y
+}
+
+func max(x int, y int) int {
+	if x > y {
+		return x
+	}
+
+	return y
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// HELLO WORLD in a version 1 QR code at the medium error correction level
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	require.Equal(t, expected, reedSolomonRemainder(data, reedSolomonDivisor(10)))
+}
+
+func TestEncodeData(t *testing.T) {
+	codewords := encodeData([]byte("az"), 6)
+
+	// byte mode, 2 characters, 'a', 'z', the terminator, then padding
+	require.Equal(t, []byte{0x40, 0x26, 0x17, 0xA0, 0xEC, 0x11}, codewords)
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+	}{
+		{text: "https://aka.ms/devicelogin", size: 25},
+		{text: "https://microsoft.com/devicelogin", size: 29},
+		{text: strings.Repeat("a", 180), size: 53},
+	}
+
+	for _, test := range tests {
+		q, err := Encode(test.text)
+		require.NoError(t, err)
+		require.Equal(t, test.size, q.Size())
+
+		// the finder patterns
+		for _, corner := range [][2]int{{0, 0}, {q.Size() - 7, 0}, {0, q.Size() - 7}} {
+			for i := 0; i < 7; i++ {
+				require.True(t, q.Dark(corner[0]+i, corner[1]))
+				require.True(t, q.Dark(corner[0], corner[1]+i))
+			}
+			require.False(t, q.Dark(corner[0]+1, corner[1]+1))
+			require.True(t, q.Dark(corner[0]+3, corner[1]+3))
+		}
+
+		// the dark module
+		require.True(t, q.Dark(8, q.Size()-8))
+
+		// both copies of the format bits match
+		for i := 0; i < 7; i++ {
+			require.Equal(t, q.Dark(q.Size()-1-i, 8), q.Dark(8, formatY(i)))
+		}
+	}
+
+	_, err := Encode(strings.Repeat("a", 181))
+	require.ErrorIs(t, err, ErrTooLong)
+}
+
+// formatY returns the row of the bit of the first copy of the format bits, in the column 8
+func formatY(i int) int {
+	if i < 6 {
+		return i
+	}
+
+	return i + 1
+}
+
+func TestString(t *testing.T) {
+	q, err := Encode("https://aka.ms/devicelogin")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(q.String(), "\n"), "\n")
+
+	// two rows of modules per line, including the quiet zone
+	require.Len(t, lines, (q.Size()+4+1)/2)
+	for _, line := range lines {
+		require.Equal(t, q.Size()+4, len([]rune(line)))
+	}
+	require.Equal(t, strings.Repeat("█", q.Size()+4), lines[0])
+}
@@ -0,0 +1,345 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package rbac applies the role assignments declared in azure.yaml, in place of role assignment scripts run as hooks.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/google/uuid"
+)
+
+const (
+	// The signed in user, or the signed in service principal when azd runs in CI
+	PrincipalUser = "user"
+	// The service principal of the pipeline configured with azd pipeline config
+	PrincipalPipeline = "pipeline"
+
+	ScopeResourceGroup = "resourceGroup"
+	ScopeSubscription  = "subscription"
+
+	// Prefix of the principals, and scopes, of services, ex) service:api
+	servicePrefix = "service:"
+)
+
+// api versions used to read the managed identities of the resources services are hosted on
+var identityApiVersions = map[string]string{
+	"Microsoft.App/containerApps":                "2023-05-01",
+	"Microsoft.Web/sites":                        "2022-03-01",
+	"Microsoft.ContainerService/managedClusters": "2023-02-01",
+}
+
+// RoleAssignment is a role assignment of azure.yaml, resolved against the environment.
+type RoleAssignment struct {
+	// The role assignment as declared in azure.yaml
+	Config           project.RoleAssignmentConfig
+	PrincipalId      string
+	RoleDefinitionId string
+	Scope            string
+	// Whether the principal is assigned the role in the scope, or in a parent scope
+	Assigned bool
+}
+
+// Name is the name of the role assignment, a GUID derived from its scope, principal and role, so that applying the
+// role assignment more than once doesn't create duplicates.
+func (r *RoleAssignment) Name() string {
+	return uuid.NewSHA1(
+		uuid.NameSpaceURL,
+		[]byte(strings.ToLower(r.Scope+"|"+r.PrincipalId+"|"+path.Base(r.RoleDefinitionId))),
+	).String()
+}
+
+// Id is the resource id of the role assignment.
+func (r *RoleAssignment) Id() string {
+	return fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignments/%s", r.Scope, r.Name())
+}
+
+// String describes the role assignment as declared in azure.yaml.
+func (r *RoleAssignment) String() string {
+	return fmt.Sprintf("%s to %s", r.Config.Role, r.Config.Principal)
+}
+
+// Manager resolves and applies the role assignments declared in azure.yaml.
+type Manager struct {
+	env                 *environment.Environment
+	azCli               azcli.AzCli
+	resourceManager     project.ResourceManager
+	principalIdProvider provisioning.CurrentPrincipalIdProvider
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+	principalIdProvider provisioning.CurrentPrincipalIdProvider,
+) *Manager {
+	return &Manager{
+		env:                 env,
+		azCli:               azCli,
+		resourceManager:     resourceManager,
+		principalIdProvider: principalIdProvider,
+	}
+}
+
+// Resolve resolves the principals, roles and scopes of the role assignments of the project, and whether they are
+// already assigned.
+func (m *Manager) Resolve(ctx context.Context, projectConfig *project.ProjectConfig) ([]*RoleAssignment, error) {
+	if len(projectConfig.RoleAssignments) == 0 {
+		return nil, nil
+	}
+
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	roleDefinitionIds := map[string]string{}
+	roleAssignments := []*RoleAssignment{}
+	for _, config := range projectConfig.RoleAssignments {
+		roleAssignment := &RoleAssignment{
+			Config: config,
+		}
+
+		principal, err := config.Principal.Envsubst(m.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding principal '%s': %w", config.Principal, err)
+		}
+
+		roleAssignment.PrincipalId, err = m.principal(
+			ctx, subscriptionId, projectConfig, principal)
+		if err != nil {
+			return nil, fmt.Errorf("resolving principal '%s': %w", principal, err)
+		}
+
+		scope, err := config.Scope.Envsubst(m.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding scope '%s': %w", config.Scope, err)
+		}
+
+		roleAssignment.Scope, err = m.scope(ctx, subscriptionId, projectConfig, scope)
+		if err != nil {
+			return nil, fmt.Errorf("resolving scope '%s': %w", scope, err)
+		}
+
+		roleDefinitionId, has := roleDefinitionIds[config.Role]
+		if !has {
+			roleDefinitionId, err = m.roleDefinitionId(ctx, subscriptionId, config.Role)
+			if err != nil {
+				return nil, err
+			}
+			roleDefinitionIds[config.Role] = roleDefinitionId
+		}
+		roleAssignment.RoleDefinitionId = roleDefinitionId
+
+		existing, err := m.azCli.ListRoleAssignments(
+			ctx, subscriptionId, roleAssignment.Scope, roleAssignment.PrincipalId)
+		if err != nil {
+			return nil, fmt.Errorf("listing role assignments of principal '%s': %w", principal, err)
+		}
+		roleAssignment.Assigned = isAssigned(roleAssignment, existing)
+
+		roleAssignments = append(roleAssignments, roleAssignment)
+	}
+
+	return roleAssignments, nil
+}
+
+// Apply assigns the role of the role assignment, when it isn't assigned already.
+func (m *Manager) Apply(ctx context.Context, roleAssignment *RoleAssignment) error {
+	if roleAssignment.Assigned {
+		return nil
+	}
+
+	err := m.azCli.CreateRoleAssignment(
+		ctx,
+		m.env.GetSubscriptionId(),
+		roleAssignment.Scope,
+		roleAssignment.Name(),
+		&armauthorization.RoleAssignmentProperties{
+			PrincipalID:      &roleAssignment.PrincipalId,
+			RoleDefinitionID: &roleAssignment.RoleDefinitionId,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("assigning %s: %w", roleAssignment, err)
+	}
+
+	roleAssignment.Assigned = true
+	return nil
+}
+
+// principal returns the object id of the principal of a role assignment
+func (m *Manager) principal(
+	ctx context.Context,
+	subscriptionId string,
+	projectConfig *project.ProjectConfig,
+	principal string,
+) (string, error) {
+	switch {
+	case principal == "":
+		return "", errors.New("principal is required")
+	case principal == PrincipalUser:
+		return m.principalIdProvider.CurrentPrincipalId(ctx)
+	case principal == PrincipalPipeline:
+		principalId := m.env.Getenv(environment.PipelinePrincipalIdEnvVarName)
+		if principalId == "" {
+			return "", fmt.Errorf(
+				"%s is not set, run `azd pipeline config` to configure the pipeline",
+				environment.PipelinePrincipalIdEnvVarName)
+		}
+		return principalId, nil
+	case strings.HasPrefix(principal, servicePrefix):
+		resourceId, err := m.serviceResourceId(ctx, subscriptionId, projectConfig, principal)
+		if err != nil {
+			return "", err
+		}
+
+		return m.managedIdentity(ctx, subscriptionId, resourceId)
+	}
+
+	if _, err := uuid.Parse(principal); err != nil {
+		return "", fmt.Errorf(
+			"principal must be %s, %s, %s<name> or an object id", PrincipalUser, PrincipalPipeline, servicePrefix)
+	}
+
+	return principal, nil
+}
+
+// scope returns the resource id of the scope of a role assignment
+func (m *Manager) scope(
+	ctx context.Context,
+	subscriptionId string,
+	projectConfig *project.ProjectConfig,
+	scope string,
+) (string, error) {
+	switch {
+	case scope == "" || scope == ScopeResourceGroup:
+		resourceGroupName, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+		if err != nil {
+			return "", fmt.Errorf("getting resource group name: %w", err)
+		}
+		return azure.ResourceGroupRID(subscriptionId, resourceGroupName), nil
+	case scope == ScopeSubscription:
+		return azure.SubscriptionRID(subscriptionId), nil
+	case strings.HasPrefix(scope, servicePrefix):
+		return m.serviceResourceId(ctx, subscriptionId, projectConfig, scope)
+	case strings.HasPrefix(scope, "/"):
+		return strings.TrimSuffix(scope, "/"), nil
+	}
+
+	return "", fmt.Errorf(
+		"scope must be %s, %s, %s<name> or a resource id", ScopeResourceGroup, ScopeSubscription, servicePrefix)
+}
+
+// serviceResourceId returns the id of the resource the service of service:<name> is hosted on
+func (m *Manager) serviceResourceId(
+	ctx context.Context,
+	subscriptionId string,
+	projectConfig *project.ProjectConfig,
+	service string,
+) (string, error) {
+	serviceName := strings.TrimPrefix(service, servicePrefix)
+	serviceConfig, has := projectConfig.Services[serviceName]
+	if !has {
+		return "", fmt.Errorf("service name '%s' doesn't exist", serviceName)
+	}
+
+	targetResource, err := m.resourceManager.GetTargetResource(ctx, subscriptionId, serviceConfig)
+	if err != nil {
+		return "", fmt.Errorf("getting target resource of service '%s': %w", serviceName, err)
+	}
+
+	return fmt.Sprintf(
+		"%s/providers/%s/%s",
+		azure.ResourceGroupRID(targetResource.SubscriptionId(), targetResource.ResourceGroupName()),
+		targetResource.ResourceType(),
+		targetResource.ResourceName(),
+	), nil
+}
+
+// managedIdentity returns the principal id of the managed identity of the resource, the system assigned identity or
+// the only user assigned identity
+func (m *Manager) managedIdentity(ctx context.Context, subscriptionId string, resourceId string) (string, error) {
+	resourceType := strings.Join(strings.Split(strings.SplitN(resourceId, "/providers/", 2)[1], "/")[:2], "/")
+	apiVersion, has := identityApiVersions[resourceType]
+	if !has {
+		return "", fmt.Errorf("managed identities of %s resources are not supported", resourceType)
+	}
+
+	resource, err := m.azCli.GetResource(ctx, subscriptionId, resourceId, apiVersion)
+	if err != nil {
+		return "", err
+	}
+
+	if resource.Identity != nil && resource.Identity.PrincipalID != nil {
+		return *resource.Identity.PrincipalID, nil
+	}
+
+	if resource.Identity != nil && len(resource.Identity.UserAssignedIdentities) == 1 {
+		for _, identity := range resource.Identity.UserAssignedIdentities {
+			if identity != nil && identity.PrincipalID != nil {
+				return *identity.PrincipalID, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf(
+		"resource %s has no system assigned managed identity, or more than one user assigned managed identity",
+		resource.Name)
+}
+
+// roleDefinitionId returns the id of the role definition of the role, a role name or id
+func (m *Manager) roleDefinitionId(ctx context.Context, subscriptionId string, role string) (string, error) {
+	if strings.HasPrefix(role, "/") {
+		return role, nil
+	}
+
+	if _, err := uuid.Parse(role); err == nil {
+		return fmt.Sprintf(
+			"%s/providers/Microsoft.Authorization/roleDefinitions/%s", azure.SubscriptionRID(subscriptionId), role), nil
+	}
+
+	roleDefinition, err := m.azCli.GetRoleDefinition(ctx, subscriptionId, azure.SubscriptionRID(subscriptionId), role)
+	if err != nil {
+		return "", err
+	}
+
+	return *roleDefinition.ID, nil
+}
+
+// isAssigned returns whether one of the existing role assignments assigns the role to the principal in the scope of the
+// role assignment, or in a parent scope
+func isAssigned(roleAssignment *RoleAssignment, existing []*armauthorization.RoleAssignment) bool {
+	for _, assignment := range existing {
+		if assignment.Properties == nil ||
+			assignment.Properties.RoleDefinitionID == nil ||
+			assignment.Properties.Scope == nil ||
+			assignment.Properties.PrincipalID == nil {
+			continue
+		}
+
+		if !strings.EqualFold(*assignment.Properties.PrincipalID, roleAssignment.PrincipalId) ||
+			!strings.EqualFold(path.Base(*assignment.Properties.RoleDefinitionID), path.Base(roleAssignment.RoleDefinitionId)) {
+			continue
+		}
+
+		scope := strings.ToLower(strings.TrimSuffix(*assignment.Properties.Scope, "/"))
+		target := strings.ToLower(roleAssignment.Scope)
+		if scope == "" || scope == target || strings.HasPrefix(target, scope+"/") {
+			return true
+		}
+	}
+
+	return false
+}
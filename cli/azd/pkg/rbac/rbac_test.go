@@ -0,0 +1,263 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rgScope           = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev"
+	userPrincipalId   = "11111111-1111-1111-1111-111111111111"
+	pipelinePrincipal = "22222222-2222-2222-2222-222222222222"
+	blobContributorId = "/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Authorization/roleDefinitions/" +
+		"ba92f5b4-2d11-453d-a403-e96b0029c9fe"
+	keyVaultReaderGuid = "21090545-7ca7-4776-b22c-e363652d74d2"
+)
+
+type principalIdProviderFunc func(ctx context.Context) (string, error)
+
+func (f principalIdProviderFunc) CurrentPrincipalId(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func jsonResponse(request *http.Request, statusCode int, value any) (*http.Response, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Request:    request,
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+	}, nil
+}
+
+func Test_ResolveAndApply(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/roleDefinitions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "roleName eq 'Storage Blob Data Contributor'", request.URL.Query().Get("$filter"))
+
+		return jsonResponse(request, http.StatusOK, armauthorization.RoleDefinitionListResult{
+			Value: []*armauthorization.RoleDefinition{
+				{ID: convert.RefOf(blobContributorId)},
+			},
+		})
+	})
+
+	// the user is assigned the role in the subscription, which includes the resource group
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/roleAssignments")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		result := armauthorization.RoleAssignmentListResult{}
+		if strings.Contains(request.URL.Query().Get("$filter"), userPrincipalId) {
+			result.Value = []*armauthorization.RoleAssignment{
+				{
+					Properties: &armauthorization.RoleAssignmentPropertiesWithScope{
+						PrincipalID:      convert.RefOf(userPrincipalId),
+						RoleDefinitionID: convert.RefOf(blobContributorId),
+						Scope:            convert.RefOf("/subscriptions/SUBSCRIPTION_ID"),
+					},
+				},
+			}
+		}
+
+		return jsonResponse(request, http.StatusOK, result)
+	})
+
+	created := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/roleAssignments/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		var body armauthorization.RoleAssignmentCreateParameters
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+		created = append(created, request.URL.Path+" "+*body.Properties.PrincipalID)
+
+		return jsonResponse(request, http.StatusCreated, armauthorization.RoleAssignment{})
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName:      "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:       "rg-dev",
+		environment.PipelinePrincipalIdEnvVarName: pipelinePrincipal,
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	manager := NewManager(
+		env,
+		azCli,
+		project.NewResourceManager(env, azCli),
+		principalIdProviderFunc(func(ctx context.Context) (string, error) {
+			return userPrincipalId, nil
+		}),
+	)
+
+	projectConfig := &project.ProjectConfig{
+		RoleAssignments: []project.RoleAssignmentConfig{
+			{Principal: project.NewExpandableString("user"), Role: "Storage Blob Data Contributor"},
+			{Principal: project.NewExpandableString("pipeline"), Role: "Storage Blob Data Contributor"},
+			{
+				Principal: project.NewExpandableString("user"),
+				Role:      keyVaultReaderGuid,
+				Scope:     project.NewExpandableString("subscription"),
+			},
+		},
+	}
+
+	roleAssignments, err := manager.Resolve(*mockContext.Context, projectConfig)
+	require.NoError(t, err)
+	require.Len(t, roleAssignments, 3)
+
+	require.True(t, roleAssignments[0].Assigned)
+	require.Equal(t, rgScope, roleAssignments[0].Scope)
+
+	require.False(t, roleAssignments[1].Assigned)
+	require.Equal(t, pipelinePrincipal, roleAssignments[1].PrincipalId)
+	require.Equal(t, blobContributorId, roleAssignments[1].RoleDefinitionId)
+
+	require.False(t, roleAssignments[2].Assigned)
+	require.Equal(t, "/subscriptions/SUBSCRIPTION_ID", roleAssignments[2].Scope)
+	require.Equal(t,
+		"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Authorization/roleDefinitions/"+keyVaultReaderGuid,
+		roleAssignments[2].RoleDefinitionId)
+
+	for _, roleAssignment := range roleAssignments {
+		require.NoError(t, manager.Apply(*mockContext.Context, roleAssignment))
+		require.True(t, roleAssignment.Assigned)
+	}
+
+	require.Equal(t, []string{
+		roleAssignments[1].Id() + " " + pipelinePrincipal,
+		roleAssignments[2].Id() + " " + userPrincipalId,
+	}, created)
+}
+
+func Test_ResolveErrors(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.ResourceGroupEnvVarName:  "rg-dev",
+	})
+	manager := NewManager(env, nil, nil, nil)
+
+	tests := map[string]struct {
+		config project.RoleAssignmentConfig
+		err    string
+	}{
+		"PipelineNotConfigured": {
+			config: project.RoleAssignmentConfig{Principal: project.NewExpandableString("pipeline"), Role: "Reader"},
+			err:    "AZURE_PIPELINE_PRINCIPAL_ID is not set",
+		},
+		"InvalidPrincipal": {
+			config: project.RoleAssignmentConfig{Principal: project.NewExpandableString("someone"), Role: "Reader"},
+			err:    "principal must be user, pipeline, service:<name> or an object id",
+		},
+		"UnknownService": {
+			config: project.RoleAssignmentConfig{Principal: project.NewExpandableString("service:api"), Role: "Reader"},
+			err:    "service name 'api' doesn't exist",
+		},
+		"InvalidScope": {
+			config: project.RoleAssignmentConfig{
+				Principal: project.NewExpandableString(userPrincipalId),
+				Role:      "Reader",
+				Scope:     project.NewExpandableString("tenant"),
+			},
+			err: "scope must be resourceGroup, subscription, service:<name> or a resource id",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := manager.Resolve(context.Background(), &project.ProjectConfig{
+				RoleAssignments: []project.RoleAssignmentConfig{test.config},
+			})
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func Test_managedIdentity(t *testing.T) {
+	const appId = rgScope + "/providers/Microsoft.App/containerApps/ca-api"
+
+	run := func(t *testing.T, identity *armresources.Identity) (string, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/containerApps/ca-api")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Equal(t, "2023-05-01", request.URL.Query().Get("api-version"))
+
+			return jsonResponse(request, http.StatusOK, armresources.GenericResource{
+				ID:       convert.RefOf(appId),
+				Name:     convert.RefOf("ca-api"),
+				Type:     convert.RefOf("Microsoft.App/containerApps"),
+				Location: convert.RefOf("eastus2"),
+				Identity: identity,
+			})
+		})
+
+		manager := NewManager(nil, mockazcli.NewAzCliFromMockContext(mockContext), nil, nil)
+		return manager.managedIdentity(*mockContext.Context, "SUBSCRIPTION_ID", appId)
+	}
+
+	t.Run("SystemAssigned", func(t *testing.T) {
+		principalId, err := run(t, &armresources.Identity{PrincipalID: convert.RefOf(userPrincipalId)})
+		require.NoError(t, err)
+		require.Equal(t, userPrincipalId, principalId)
+	})
+
+	t.Run("UserAssigned", func(t *testing.T) {
+		principalId, err := run(t, &armresources.Identity{
+			UserAssignedIdentities: map[string]*armresources.IdentityUserAssignedIdentitiesValue{
+				rgScope + "/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id-api": {
+					PrincipalID: convert.RefOf(pipelinePrincipal),
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, pipelinePrincipal, principalId)
+	})
+
+	t.Run("NoIdentity", func(t *testing.T) {
+		_, err := run(t, nil)
+		require.ErrorContains(t, err, "resource ca-api has no system assigned managed identity")
+	})
+}
+
+func Test_Name(t *testing.T) {
+	roleAssignment := &RoleAssignment{
+		PrincipalId:      userPrincipalId,
+		RoleDefinitionId: blobContributorId,
+		Scope:            rgScope,
+	}
+
+	// the name doesn't depend on the case of the scope, or the scope of the role definition id
+	require.Equal(t, roleAssignment.Name(), (&RoleAssignment{
+		PrincipalId:      userPrincipalId,
+		RoleDefinitionId: "ba92f5b4-2d11-453d-a403-e96b0029c9fe",
+		Scope:            strings.ToUpper(rgScope),
+	}).Name())
+
+	require.NotEqual(t, roleAssignment.Name(), (&RoleAssignment{
+		PrincipalId:      pipelinePrincipal,
+		RoleDefinitionId: blobContributorId,
+		Scope:            rgScope,
+	}).Name())
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package redact is the registry of the values flagged secure while azd runs, ex) the values of @secure() Bicep
+// parameters, registry passwords or kube config tokens. The values are redacted from the text written to the console,
+// logs and telemetry.
+package redact
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Redacted replaces the secure values in redacted text
+const Redacted = "<redacted>"
+
+// Values shorter than minSecretLength aren't redacted, since they would redact unrelated parts of the text.
+const minSecretLength = 4
+
+var (
+	mu      sync.RWMutex
+	secrets = map[string]struct{}{}
+)
+
+// AddSecret flags the value as secure. It's redacted from the text passed to String, or written to a Writer, from then
+// on.
+func AddSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	secrets[value] = struct{}{}
+}
+
+// IsSecret reports whether the value is flagged secure.
+func IsSecret(value string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, has := secrets[value]
+	return has
+}
+
+// String returns s, with the values flagged secure replaced by Redacted.
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if len(secrets) == 0 {
+		return s
+	}
+
+	// longer values first, so that a value containing another one is redacted as a whole
+	values := maps.Keys(secrets)
+	slices.SortFunc(values, func(a, b string) bool {
+		return len(a) > len(b)
+	})
+
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, Redacted)
+	}
+
+	return s
+}
+
+// NewWriter returns a writer that redacts the values flagged secure from the text written to w. Each write is redacted
+// on its own, so the writer is meant for writers receiving whole messages, ex) the output of the log package.
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+type writer struct {
+	w io.Writer
+}
+
+func (r *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, String(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func resetSecrets(t *testing.T) {
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		secrets = map[string]struct{}{}
+	})
+}
+
+func Test_String(t *testing.T) {
+	t.Run("NoSecrets", func(t *testing.T) {
+		resetSecrets(t)
+		require.Equal(t, "password: P@ssw0rd!", String("password: P@ssw0rd!"))
+	})
+
+	t.Run("Secrets", func(t *testing.T) {
+		resetSecrets(t)
+		AddSecret("P@ssw0rd!")
+		AddSecret("P@ssw0rd!-admin")
+
+		require.Equal(t,
+			"password: <redacted>, admin password: <redacted>",
+			String("password: P@ssw0rd!, admin password: P@ssw0rd!-admin"))
+	})
+
+	t.Run("ShortValuesIgnored", func(t *testing.T) {
+		resetSecrets(t)
+		AddSecret("")
+		AddSecret("abc")
+
+		require.Equal(t, "abc", String("abc"))
+	})
+}
+
+func Test_NewWriter(t *testing.T) {
+	resetSecrets(t)
+	AddSecret("S3cr3tV@lue")
+
+	buf := &bytes.Buffer{}
+	logger := log.New(NewWriter(buf), "", 0)
+	logger.Print(fmt.Sprintf("Run exec: 'tool --password S3cr3tV@lue', output: %s", "S3cr3tV@lue"))
+
+	require.Equal(t, "Run exec: 'tool --password <redacted>', output: <redacted>\n", buf.String())
+	require.NotContains(t, buf.String(), "S3cr3tV@lue")
+}
+
+func Test_IsSecret(t *testing.T) {
+	resetSecrets(t)
+	AddSecret("S3cr3tV@lue")
+
+	require.True(t, IsSecret("S3cr3tV@lue"))
+	require.False(t, IsSecret("S3cr3t"))
+}
@@ -0,0 +1,324 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package remote runs azd in a Container Apps job of the subscription instead of on the local machine, ex) when the
+// local network can't reach the private resources of an environment, or uploads from it are slow.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/slices"
+)
+
+// JobIdEnvVarName is the name of the key used to store the resource id of the Container Apps job azd runs in remotely.
+// The image of the job must have azd installed, and the job the credentials of the pipeline identity in its
+// AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_CLIENT_SECRET environment variables. It's usually an output of the
+// infrastructure of the project.
+const JobIdEnvVarName = "AZURE_REMOTE_JOB_ID"
+
+// StorageUrlEnvVarName is the name of the key used to store the url of the blob container the project is uploaded to for
+// the job, and the job uploads its logs to.
+const StorageUrlEnvVarName = "AZURE_REMOTE_STORAGE_URL"
+
+// The environment variables of the executions of the job, read by the worker
+const (
+	packageUrlEnvVarName = "AZD_REMOTE_PACKAGE_URL"
+	logUrlEnvVarName     = "AZD_REMOTE_LOG_URL"
+)
+
+const (
+	packageBlobName = "project.zip"
+	logBlobName     = "azd.log"
+)
+
+// how often the status and logs of the run are polled
+const logPollInterval = 5 * time.Second
+
+// The command of the executions of the job, running the worker
+var workerCommand = []string{"azd", "internal", "remote-run"}
+
+// The directories which aren't uploaded with the project, restored or generated by the run
+var excludedDirectories = []string{".git", "node_modules", ".venv"}
+
+// Runner runs `azd up` for an environment in the Container Apps job of the environment, and streams its logs back.
+// The job runs the [Worker] of the run.
+type Runner struct {
+	azCli               azcli.AzCli
+	containerAppService containerapps.ContainerAppService
+	clock               clock.Clock
+}
+
+// NewRunner creates a runner
+func NewRunner(
+	azCli azcli.AzCli,
+	containerAppService containerapps.ContainerAppService,
+	clock clock.Clock,
+) *Runner {
+	return &Runner{
+		azCli:               azCli,
+		containerAppService: containerAppService,
+		clock:               clock,
+	}
+}
+
+// Execution is a run of `azd up` in the job of an environment
+type Execution struct {
+	// The name of the execution of the job
+	Name    string
+	JobName string
+	// The url of the blob the logs of the run are uploaded to
+	LogUrl string
+
+	jobId          *arm.ResourceID
+	subscriptionId string
+}
+
+// Start uploads the project to the blob container of the environment and starts a run of `azd up` for the environment in
+// its job. The steps are reported to progress.
+func (r *Runner) Start(
+	ctx context.Context,
+	env *environment.Environment,
+	projectPath string,
+	progress func(string),
+) (*Execution, error) {
+	jobId, err := parseJobId(env.Getenv(JobIdEnvVarName))
+	if err != nil {
+		return nil, err
+	}
+
+	storageUrl, err := parseStorageUrl(env.Getenv(StorageUrlEnvVarName))
+	if err != nil {
+		return nil, err
+	}
+
+	progress("Packaging project")
+	packagePath, err := packageProject(projectPath, env.GetEnvName())
+	if err != nil {
+		return nil, fmt.Errorf("packaging project: %w", err)
+	}
+	defer os.Remove(packagePath)
+
+	// Each run has its own folder, for the logs of the runs to be kept
+	folder := storageUrl.JoinPath(env.GetEnvName(), r.clock.Now().UTC().Format("20060102T150405Z"))
+	packageUrl := folder.JoinPath(packageBlobName).String()
+	logUrl := folder.JoinPath(logBlobName).String()
+
+	progress("Uploading project")
+	if err := r.uploadPackage(ctx, env, packageUrl, packagePath); err != nil {
+		return nil, err
+	}
+
+	progress("Starting job")
+	executionName, err := r.containerAppService.StartJob(
+		ctx,
+		jobId.SubscriptionID,
+		jobId.ResourceGroupName,
+		jobId.Name,
+		workerCommand,
+		map[string]string{
+			packageUrlEnvVarName:                 packageUrl,
+			logUrlEnvVarName:                     logUrl,
+			environment.EnvNameEnvVarName:        env.GetEnvName(),
+			environment.SubscriptionIdEnvVarName: env.GetSubscriptionId(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("started execution '%s' of job '%s', logging to %s", executionName, jobId.Name, logUrl)
+
+	return &Execution{
+		Name:           executionName,
+		JobName:        jobId.Name,
+		LogUrl:         logUrl,
+		jobId:          jobId,
+		subscriptionId: env.GetSubscriptionId(),
+	}, nil
+}
+
+// Follow writes the logs of the run to the writer until it completes, and returns an error when it didn't succeed. The
+// run isn't stopped when the context is cancelled.
+func (r *Runner) Follow(ctx context.Context, execution *Execution, writer io.Writer) error {
+	jobId := execution.jobId
+	written := 0
+	for {
+		status, err := r.containerAppService.GetJobExecutionStatus(
+			ctx, jobId.SubscriptionID, jobId.ResourceGroupName, jobId.Name, execution.Name)
+		if err != nil {
+			return err
+		}
+
+		// The log is read after the status, for the last read of a completed run to have all its lines
+		written, err = r.writeLog(ctx, execution.subscriptionId, execution.LogUrl, written, writer)
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case armappcontainers.JobExecutionRunningStateSucceeded:
+			return nil
+		case armappcontainers.JobExecutionRunningStateFailed,
+			armappcontainers.JobExecutionRunningStateStopped,
+			armappcontainers.JobExecutionRunningStateDegraded:
+			return fmt.Errorf("execution '%s' of job '%s' completed with status %s", execution.Name, jobId.Name, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"execution '%s' of job '%s' keeps running, its logs are uploaded to %s: %w",
+				execution.Name, jobId.Name, execution.LogUrl, ctx.Err())
+		case <-r.clock.After(logPollInterval):
+		}
+	}
+}
+
+func (r *Runner) uploadPackage(ctx context.Context, env *environment.Environment, packageUrl, packagePath string) error {
+	file, err := os.Open(packagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	metadata := map[string]string{"environment": env.GetEnvName()}
+	if err := r.azCli.UploadBlob(
+		ctx, env.GetSubscriptionId(), packageUrl, file, "application/zip", metadata,
+	); err != nil {
+		return fmt.Errorf("uploading project: %w", err)
+	}
+
+	return nil
+}
+
+// writeLog writes the lines of the log of the run after the first written bytes, and returns the number of bytes of the
+// log written. The log doesn't exist until the run starts.
+func (r *Runner) writeLog(
+	ctx context.Context,
+	subscriptionId string,
+	logUrl string,
+	written int,
+	writer io.Writer,
+) (int, error) {
+	buf := &bytes.Buffer{}
+	if err := r.azCli.DownloadBlob(ctx, subscriptionId, logUrl, buf); err != nil {
+		var responseErr *azcore.ResponseError
+		if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+			return written, nil
+		}
+
+		return written, fmt.Errorf("downloading logs of the run: %w", err)
+	}
+
+	if buf.Len() <= written {
+		return written, nil
+	}
+
+	if _, err := writer.Write(buf.Bytes()[written:]); err != nil {
+		return written, err
+	}
+
+	return buf.Len(), nil
+}
+
+// parseJobId parses the resource id of the Container Apps job of the environment
+func parseJobId(jobId string) (*arm.ResourceID, error) {
+	if jobId == "" {
+		return nil, fmt.Errorf(
+			"the environment has no Container Apps job to run azd in, set %s to the resource id of the job",
+			JobIdEnvVarName)
+	}
+
+	resourceId, err := arm.ParseResourceID(jobId)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", JobIdEnvVarName, err)
+	}
+
+	if !strings.EqualFold(resourceId.ResourceType.String(), "Microsoft.App/jobs") {
+		return nil, fmt.Errorf("%s must be the resource id of a Container Apps job, not of a %s",
+			JobIdEnvVarName, resourceId.ResourceType.String())
+	}
+
+	return resourceId, nil
+}
+
+// parseStorageUrl parses the url of the blob container of the environment, ex)
+// https://<account>.blob.core.windows.net/<container>
+func parseStorageUrl(storageUrl string) (*url.URL, error) {
+	if storageUrl == "" {
+		return nil, fmt.Errorf(
+			"the environment has no blob container to upload the project to, set %s to the url of the container",
+			StorageUrlEnvVarName)
+	}
+
+	parsed, err := url.Parse(storageUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", StorageUrlEnvVarName, err)
+	}
+
+	if parsed.Scheme != "https" || parsed.Host == "" || strings.Trim(parsed.Path, "/") == "" {
+		return nil, fmt.Errorf(
+			"%s must be the url of a blob container, ex) https://<account>.blob.core.windows.net/<container>",
+			StorageUrlEnvVarName)
+	}
+
+	return parsed, nil
+}
+
+// packageProject zips the project for the run, with the directory of the environment the run is for and without the
+// directories of the other environments, nor the directories restored or generated by the run. Returns the path of the
+// zip file.
+func packageProject(projectPath string, envName string) (string, error) {
+	zipFile, err := os.CreateTemp("", "azdremote*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	err = rzip.CreateFromDirectoryWithFilter(projectPath, zipFile, func(path string, entry fs.DirEntry) bool {
+		relative, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return false
+		}
+
+		segments := strings.Split(filepath.ToSlash(relative), "/")
+		if segments[0] == azdcontext.EnvironmentDirectoryName {
+			return len(segments) > 1 && segments[1] != envName
+		}
+
+		return entry.IsDir() && slices.Contains(excludedDirectories, entry.Name())
+	})
+	if err != nil {
+		zipFile.Close()
+		os.Remove(zipFile.Name())
+		return "", err
+	}
+
+	if err := zipFile.Close(); err != nil {
+		os.Remove(zipFile.Name())
+		return "", err
+	}
+
+	return zipFile.Name(), nil
+}
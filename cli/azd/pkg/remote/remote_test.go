@@ -0,0 +1,261 @@
+package remote
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testJobId      = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.App/jobs/azd-runner"
+	testStorageUrl = "https://ACCOUNT.blob.core.windows.net/remote"
+)
+
+func Test_packageProject(t *testing.T) {
+	projectPath := t.TempDir()
+	for _, file := range []string{
+		"azure.yaml",
+		"src/api/main.py",
+		"src/web/node_modules/react/index.js",
+		".git/HEAD",
+		".azure/config.json",
+		".azure/dev/.env",
+		".azure/prod/.env",
+	} {
+		path := filepath.Join(projectPath, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(file), 0600))
+	}
+
+	packagePath, err := packageProject(projectPath, "dev")
+	require.NoError(t, err)
+	defer os.Remove(packagePath)
+
+	reader, err := zip.OpenReader(packagePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	files := []string{}
+	for _, file := range reader.File {
+		files = append(files, file.Name)
+	}
+
+	require.ElementsMatch(t, []string{".azure/dev/.env", "azure.yaml", "src/api/main.py"}, files)
+}
+
+func Test_Runner(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "azure.yaml"), []byte("name: todo"), 0600))
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		JobIdEnvVarName:                      testJobId,
+		StorageUrlEnvVarName:                 testStorageUrl,
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		blobs := mockBlobContainer(mockContext)
+		var executionEnv map[string]string
+		mockJob(mockContext, &executionEnv, armappcontainers.JobExecutionRunningStateSucceeded)
+
+		runner := newTestRunner(mockContext)
+		steps := []string{}
+		execution, err := runner.Start(*mockContext.Context, env, projectPath, func(step string) {
+			steps = append(steps, step)
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Packaging project", "Uploading project", "Starting job"}, steps)
+		require.Equal(t, "azd-runner-1a2b3c", execution.Name)
+		require.Equal(t, "azd-runner", execution.JobName)
+		require.Equal(t, testStorageUrl+"/dev/20240501T103000Z/azd.log", execution.LogUrl)
+
+		require.Contains(t, blobs, "/remote/dev/20240501T103000Z/project.zip")
+		require.Equal(t, map[string]string{
+			packageUrlEnvVarName:                 testStorageUrl + "/dev/20240501T103000Z/project.zip",
+			logUrlEnvVarName:                     execution.LogUrl,
+			environment.EnvNameEnvVarName:        "dev",
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		}, executionEnv)
+
+		blobs["/remote/dev/20240501T103000Z/azd.log"] = []byte("Provisioning Azure resources\nDeploying services\n")
+
+		logs := &bytes.Buffer{}
+		require.NoError(t, runner.Follow(*mockContext.Context, execution, logs))
+		require.Equal(t, "Provisioning Azure resources\nDeploying services\n", logs.String())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		blobs := mockBlobContainer(mockContext)
+		var executionEnv map[string]string
+		mockJob(mockContext, &executionEnv, armappcontainers.JobExecutionRunningStateFailed)
+
+		runner := newTestRunner(mockContext)
+		execution, err := runner.Start(*mockContext.Context, env, projectPath, func(string) {})
+		require.NoError(t, err)
+
+		blobs["/remote/dev/20240501T103000Z/azd.log"] = []byte("ERROR: deployment failed\n")
+
+		logs := &bytes.Buffer{}
+		err = runner.Follow(*mockContext.Context, execution, logs)
+		require.EqualError(t, err, "execution 'azd-runner-1a2b3c' of job 'azd-runner' completed with status Failed")
+		require.Equal(t, "ERROR: deployment failed\n", logs.String())
+	})
+
+	t.Run("NoJob", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		noJob := environment.EphemeralWithValues("dev", map[string]string{StorageUrlEnvVarName: testStorageUrl})
+
+		_, err := newTestRunner(mockContext).Start(*mockContext.Context, noJob, projectPath, func(string) {})
+		require.ErrorContains(t, err, "set AZURE_REMOTE_JOB_ID to the resource id of the job")
+	})
+}
+
+func Test_writeLog(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	blobs := mockBlobContainer(mockContext)
+	runner := newTestRunner(mockContext)
+	logUrl := testStorageUrl + "/dev/20240501T103000Z/azd.log"
+
+	// The log doesn't exist until the run starts
+	logs := &bytes.Buffer{}
+	written, err := runner.writeLog(*mockContext.Context, "SUBSCRIPTION_ID", logUrl, 0, logs)
+	require.NoError(t, err)
+	require.Equal(t, 0, written)
+
+	blobs["/remote/dev/20240501T103000Z/azd.log"] = []byte("line 1\n")
+	written, err = runner.writeLog(*mockContext.Context, "SUBSCRIPTION_ID", logUrl, written, logs)
+	require.NoError(t, err)
+	require.Equal(t, 7, written)
+
+	// Only the lines after the written ones are written
+	blobs["/remote/dev/20240501T103000Z/azd.log"] = []byte("line 1\nline 2\n")
+	written, err = runner.writeLog(*mockContext.Context, "SUBSCRIPTION_ID", logUrl, written, logs)
+	require.NoError(t, err)
+	require.Equal(t, 14, written)
+	require.Equal(t, "line 1\nline 2\n", logs.String())
+}
+
+func Test_parseJobId(t *testing.T) {
+	jobId, err := parseJobId(testJobId)
+	require.NoError(t, err)
+	require.Equal(t, "azd-runner", jobId.Name)
+
+	_, err = parseJobId(
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.App/containerApps/api")
+	require.ErrorContains(t, err, "must be the resource id of a Container Apps job")
+}
+
+func newTestRunner(mockContext *mocks.MockContext) *Runner {
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+
+	return NewRunner(
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		containerapps.NewContainerAppService(mockContext.SubscriptionCredentialProvider, mockContext.HttpClient, mockClock),
+		mockClock,
+	)
+}
+
+// mockBlobContainer stores the blobs uploaded to the mock context, by path, and serves them back
+func mockBlobContainer(mockContext *mocks.MockContext) map[string][]byte {
+	blobs := map[string][]byte{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Host == "ACCOUNT.blob.core.windows.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		blobs[request.URL.Path] = body
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "ACCOUNT.blob.core.windows.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, has := blobs[request.URL.Path]
+		if !has {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		}
+
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	return blobs
+}
+
+// mockJob mocks the job of testJobId, recording the environment variables of the execution it starts, whose status is
+// the given one
+func mockJob(
+	mockContext *mocks.MockContext,
+	executionEnv *map[string]string,
+	status armappcontainers.JobExecutionRunningState,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/jobs/azd-runner")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappcontainers.Job{
+			Properties: &armappcontainers.JobProperties{
+				Template: &armappcontainers.JobTemplate{
+					Containers: []*armappcontainers.Container{
+						{Name: convert.RefOf("azd"), Image: convert.RefOf("azd:latest")},
+					},
+				},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/jobs/azd-runner/start")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		var template armappcontainers.JobExecutionTemplate
+		if err := json.NewDecoder(request.Body).Decode(&template); err != nil {
+			return nil, err
+		}
+
+		*executionEnv = map[string]string{}
+		for _, envVar := range template.Containers[0].Env {
+			(*executionEnv)[*envVar.Name] = *envVar.Value
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappcontainers.JobExecutionBase{
+			Name: convert.RefOf("azd-runner-1a2b3c"),
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/jobs/azd-runner/executions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappcontainers.ContainerAppJobExecutions{
+			Value: []*armappcontainers.JobExecution{
+				{Name: convert.RefOf("azd-runner-1a2b3c"), Status: convert.RefOf(status)},
+			},
+		})
+	})
+}
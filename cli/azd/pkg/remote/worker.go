@@ -0,0 +1,218 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
+)
+
+// how often the log of the run is uploaded while it runs
+const logUploadInterval = 5 * time.Second
+
+// Worker runs `azd up` in an execution of the job started by the [Runner], from the project the runner uploaded, and
+// uploads the log of the run for the runner to stream it back
+type Worker struct {
+	authManager   *auth.Manager
+	azCli         azcli.AzCli
+	commandRunner exec.CommandRunner
+	clock         clock.Clock
+}
+
+// NewWorker creates a worker
+func NewWorker(
+	authManager *auth.Manager,
+	azCli azcli.AzCli,
+	commandRunner exec.CommandRunner,
+	clock clock.Clock,
+) *Worker {
+	return &Worker{
+		authManager:   authManager,
+		azCli:         azCli,
+		commandRunner: commandRunner,
+		clock:         clock,
+	}
+}
+
+// Run logs in with the credentials of the pipeline identity of the job, and runs `azd up` for the environment of the
+// execution in the project it downloads. The output of the run is written to the writer and uploaded to the log of the
+// run.
+func (w *Worker) Run(ctx context.Context, writer io.Writer) error {
+	packageUrl := os.Getenv(packageUrlEnvVarName)
+	logUrl := os.Getenv(logUrlEnvVarName)
+	envName := os.Getenv(environment.EnvNameEnvVarName)
+	subscriptionId := os.Getenv(environment.SubscriptionIdEnvVarName)
+	if packageUrl == "" || logUrl == "" || envName == "" || subscriptionId == "" {
+		return errors.New("the execution has no project to run, remote runs are started by `azd up --remote`")
+	}
+
+	clientId := os.Getenv("AZURE_CLIENT_ID")
+	tenantId := os.Getenv("AZURE_TENANT_ID")
+	clientSecret := os.Getenv("AZURE_CLIENT_SECRET")
+	if clientId == "" || tenantId == "" || clientSecret == "" {
+		return errors.New(
+			"the job has no credentials of the pipeline identity, set AZURE_CLIENT_ID, AZURE_TENANT_ID and " +
+				"AZURE_CLIENT_SECRET on its container")
+	}
+
+	if _, err := w.authManager.LoginWithServicePrincipalSecret(ctx, tenantId, clientId, clientSecret); err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+
+	projectPath, err := w.downloadProject(ctx, subscriptionId, packageUrl)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(projectPath)
+
+	azdPath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	runLog := &logBuffer{}
+	output := io.MultiWriter(writer, runLog)
+
+	uploadCtx, stopUploads := context.WithCancel(ctx)
+	uploadsDone := make(chan struct{})
+	go func() {
+		defer close(uploadsDone)
+		w.uploadLog(uploadCtx, subscriptionId, logUrl, runLog)
+	}()
+
+	_, runErr := w.commandRunner.Run(ctx, exec.NewRunArgs(azdPath, "up", "--environment", envName, "--no-prompt").
+		WithCwd(projectPath).
+		WithStdOut(output).
+		WithStdErr(output))
+
+	stopUploads()
+	<-uploadsDone
+
+	// The last upload has all the lines of the run, the runner reads it once the execution completes
+	if _, err := runLog.upload(ctx, w.azCli, subscriptionId, logUrl); err != nil {
+		return err
+	}
+
+	return runErr
+}
+
+// downloadProject downloads and extracts the project uploaded by the runner, and returns its path
+func (w *Worker) downloadProject(ctx context.Context, subscriptionId string, packageUrl string) (string, error) {
+	directory, err := os.MkdirTemp("", "azdremote")
+	if err != nil {
+		return "", err
+	}
+
+	packagePath := filepath.Join(directory, packageBlobName)
+	packageFile, err := os.Create(packagePath)
+	if err != nil {
+		os.RemoveAll(directory)
+		return "", err
+	}
+	defer packageFile.Close()
+
+	if err := w.azCli.DownloadBlob(ctx, subscriptionId, packageUrl, packageFile); err != nil {
+		os.RemoveAll(directory)
+		return "", fmt.Errorf("downloading project: %w", err)
+	}
+
+	if err := packageFile.Close(); err != nil {
+		os.RemoveAll(directory)
+		return "", err
+	}
+
+	projectPath := filepath.Join(directory, "project")
+	if err := rzip.ExtractToDirectory(packagePath, projectPath); err != nil {
+		os.RemoveAll(directory)
+		return "", fmt.Errorf("extracting project: %w", err)
+	}
+
+	return projectPath, nil
+}
+
+// uploadLog uploads the log of the run when it changed, until the context is cancelled
+func (w *Worker) uploadLog(ctx context.Context, subscriptionId string, logUrl string, runLog *logBuffer) {
+	ticker := w.clock.Ticker(logUploadInterval)
+	defer ticker.Stop()
+
+	uploaded := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if runLog.len() == uploaded {
+				continue
+			}
+
+			length, err := runLog.upload(ctx, w.azCli, subscriptionId, logUrl)
+			if err != nil {
+				// The next upload has the lines of the failed one
+				log.Printf("failed uploading log of the run: %v", err)
+				continue
+			}
+
+			uploaded = length
+		}
+	}
+}
+
+// logBuffer is the output of the run, written by the command while it's uploaded
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Len()
+}
+
+// upload replaces the log blob with the output written so far, and returns its length
+func (b *logBuffer) upload(ctx context.Context, azCli azcli.AzCli, subscriptionId string, logUrl string) (int, error) {
+	b.mu.Lock()
+	contents := bytes.Clone(b.buf.Bytes())
+	b.mu.Unlock()
+
+	if err := azCli.UploadBlob(
+		ctx, subscriptionId, logUrl, nopCloser{bytes.NewReader(contents)}, "text/plain", nil,
+	); err != nil {
+		return 0, fmt.Errorf("uploading log of the run: %w", err)
+	}
+
+	return len(contents), nil
+}
+
+// nopCloser is a seekable reader with a no-op Close
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Worker_Run(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	worker := NewWorker(nil, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.CommandRunner, clock.NewMock())
+
+	t.Setenv(packageUrlEnvVarName, "")
+	t.Setenv(logUrlEnvVarName, "")
+	err := worker.Run(*mockContext.Context, &bytes.Buffer{})
+	require.ErrorContains(t, err, "remote runs are started by `azd up --remote`")
+
+	t.Setenv(packageUrlEnvVarName, testStorageUrl+"/dev/20240501T103000Z/project.zip")
+	t.Setenv(logUrlEnvVarName, testStorageUrl+"/dev/20240501T103000Z/azd.log")
+	t.Setenv(environment.EnvNameEnvVarName, "dev")
+	t.Setenv(environment.SubscriptionIdEnvVarName, "SUBSCRIPTION_ID")
+	t.Setenv("AZURE_CLIENT_SECRET", "")
+	err = worker.Run(*mockContext.Context, &bytes.Buffer{})
+	require.ErrorContains(t, err, "the job has no credentials of the pipeline identity")
+}
+
+func Test_logBuffer_upload(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	blobs := mockBlobContainer(mockContext)
+
+	runLog := &logBuffer{}
+	_, err := runLog.Write([]byte("Provisioning Azure resources\n"))
+	require.NoError(t, err)
+
+	length, err := runLog.upload(
+		*mockContext.Context,
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		"SUBSCRIPTION_ID",
+		testStorageUrl+"/dev/20240501T103000Z/azd.log")
+	require.NoError(t, err)
+	require.Equal(t, runLog.len(), length)
+	require.Equal(t, "Provisioning Azure resources\n", string(blobs["/remote/dev/20240501T103000Z/azd.log"]))
+}
@@ -0,0 +1,270 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package runs records the events of each azd run, ex) the command, its steps, the tools it invokes and its result, as
+// newline delimited JSON to .azure/<env>/runs/<id>.ndjson. The files are read by `azd runs list` and `azd runs show`,
+// and by external dashboards, or to debug flaky CI runs. The schema of the events is documented in docs/runs.md.
+package runs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+)
+
+// DirectoryName is the name of the directory of the environment the runs are recorded in
+const DirectoryName = "runs"
+
+// FileExtension is the extension of the files the runs are recorded in
+const FileExtension = ".ndjson"
+
+// only the most recent runs are kept in the directory of the environment
+const maxRuns = 100
+
+type EventType string
+
+const (
+	// CommandEvent is recorded when a command starts, including the child commands of composite commands like azd up
+	CommandEvent EventType = "command"
+	// StepEvent is recorded when a step of a command, displayed as a progress spinner, completes
+	StepEvent EventType = "step"
+	// ToolEvent is recorded when an external tool invoked by azd, ex) docker or kubectl, exits
+	ToolEvent EventType = "tool"
+	// ResultEvent is recorded when a command completes
+	ResultEvent EventType = "result"
+)
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusWarning   Status = "warning"
+	StatusSkipped   Status = "skipped"
+)
+
+// Event is a line of the file of a run. The values flagged secure are redacted from the event when it's recorded.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+	// Name of the command, ex) azd deploy, of the step, or of the tool, ex) docker
+	Name string `json:"name"`
+	// Arguments of the tool
+	Args []string `json:"args,omitempty"`
+	// Names of the flags set on the command
+	Flags []string `json:"flags,omitempty"`
+	// Version of azd, on command events
+	Version string `json:"version,omitempty"`
+	// Name of the environment, on command events
+	Environment string `json:"environment,omitempty"`
+	// Status of steps, tools and results
+	Status Status `json:"status,omitempty"`
+	// Duration of tools and commands, in milliseconds
+	DurationMs int64 `json:"durationMs,omitempty"`
+	// Exit code of tools
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Error of failed tools and results
+	Error string `json:"error,omitempty"`
+	// Id of the telemetry trace of the command, on result events
+	TraceId string `json:"traceId,omitempty"`
+}
+
+// StatusOf returns StatusFailed when err is set, StatusSucceeded otherwise
+func StatusOf(err error) Status {
+	if err != nil {
+		return StatusFailed
+	}
+
+	return StatusSucceeded
+}
+
+// names of steps are displayed with colors
+var ansiEscapes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Recorder records the events of a run. Until the environment of the run is known, ex) while azd up creates it, the
+// events are kept in memory and written once the Recorder is opened.
+type Recorder struct {
+	id       string
+	mu       sync.Mutex
+	buffered []*Event
+	file     *os.File
+}
+
+// NewRecorder creates a Recorder for a new run. Ids of runs are sortable by the time the runs started.
+func NewRecorder() *Recorder {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+
+	return &Recorder{
+		id: fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix)),
+	}
+}
+
+// Id returns the id of the run
+func (r *Recorder) Id() string {
+	return r.id
+}
+
+// IsOpen reports whether the events are written to the file of the run
+func (r *Recorder) IsOpen() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file != nil
+}
+
+// Open creates the file of the run in the directory of the environment, at envRoot, and writes the events recorded so
+// far. The oldest runs of the environment are removed.
+func (r *Recorder) Open(envRoot string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		return nil
+	}
+
+	dir := filepath.Join(envRoot, DirectoryName)
+	if err := os.MkdirAll(dir, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating runs directory: %w", err)
+	}
+
+	if err := prune(dir, maxRuns-1); err != nil {
+		log.Printf("failed removing old runs: %v", err)
+	}
+
+	file, err := os.OpenFile(
+		filepath.Join(dir, r.id+FileExtension), os.O_APPEND|os.O_CREATE|os.O_WRONLY, osutil.PermissionFile)
+	if err != nil {
+		return fmt.Errorf("creating run file: %w", err)
+	}
+	r.file = file
+
+	for _, event := range r.buffered {
+		r.write(event)
+	}
+	r.buffered = nil
+
+	return nil
+}
+
+// Record records the event, with the current time when the event has none
+func (r *Recorder) Record(event *Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	event.Name = redact.String(ansiEscapes.ReplaceAllString(event.Name, ""))
+	event.Error = redact.String(event.Error)
+	for i, arg := range event.Args {
+		event.Args[i] = redact.String(arg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		r.buffered = append(r.buffered, event)
+		return
+	}
+
+	r.write(event)
+}
+
+// Close closes the file of the run. Events recorded while the Recorder was never opened are discarded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buffered = nil
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *Recorder) write(event *Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("failed marshalling run event: %v", err)
+		return
+	}
+
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		log.Printf("failed writing run event: %v", err)
+	}
+}
+
+// removes the oldest run files of dir, keeping the keep most recent ones
+func prune(dir string, keep int) error {
+	ids, err := ids(dir)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(ids)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, ids[i]+FileExtension)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns the ids of the runs recorded in dir, oldest first
+func ids(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), FileExtension) {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), FileExtension))
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+type contextKey string
+
+const recorderContextKey contextKey = "runs-recorder"
+
+// WithRecorder returns a context the events of the run are recorded from, by Record
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderContextKey, recorder)
+}
+
+// RecorderFromContext returns the Recorder of the run, or nil when the run isn't recorded
+func RecorderFromContext(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderContextKey).(*Recorder)
+	return recorder
+}
+
+// Record records the event in the run of the context, if any
+func Record(ctx context.Context, event *Event) {
+	if recorder := RecorderFromContext(ctx); recorder != nil {
+		recorder.Record(event)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	envRoot := t.TempDir()
+	recorder := NewRecorder()
+	ctx := WithRecorder(context.Background(), recorder)
+
+	// events are kept until the recorder is opened
+	Record(ctx, &Event{Type: CommandEvent, Name: "azd up"})
+	require.False(t, recorder.IsOpen())
+	require.NoDirExists(t, filepath.Join(envRoot, DirectoryName))
+
+	require.NoError(t, recorder.Open(envRoot))
+	require.True(t, recorder.IsOpen())
+
+	redact.AddSecret("SECRET_VALUE")
+	exitCode := 1
+	Record(ctx, &Event{
+		Type:     ToolEvent,
+		Name:     "docker",
+		Args:     []string{"login", "--password", "SECRET_VALUE"},
+		Status:   StatusFailed,
+		ExitCode: &exitCode,
+	})
+	Record(ctx, &Event{Type: StepEvent, Name: "Packaging service \x1b[36mapi\x1b[0m", Status: StatusSucceeded})
+	Record(ctx, &Event{Type: ResultEvent, Name: "azd up", Status: StatusOf(errors.New("failed")), Error: "failed"})
+	require.NoError(t, recorder.Close())
+
+	events, err := Read(envRoot, recorder.Id())
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, CommandEvent, events[0].Type)
+	require.False(t, events[0].Time.IsZero())
+	require.Equal(t, []string{"login", "--password", redact.Redacted}, events[1].Args)
+	require.Equal(t, 1, *events[1].ExitCode)
+	require.Equal(t, "Packaging service api", events[2].Name)
+	require.Equal(t, StatusFailed, events[3].Status)
+
+	// events of runs which aren't recorded are ignored
+	Record(context.Background(), &Event{Type: CommandEvent, Name: "azd up"})
+}
+
+func Test_List(t *testing.T) {
+	envRoot := t.TempDir()
+	dir := filepath.Join(envRoot, DirectoryName)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	started := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	write := func(id string, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, id+FileExtension), []byte(content), 0600))
+	}
+
+	write("20240115T093000Z-000001", `{"time":"2024-01-15T09:30:00Z","type":"command","name":"azd up"}
+{"time":"2024-01-15T09:30:01Z","type":"command","name":"azd provision"}
+{"time":"2024-01-15T09:30:30Z","type":"result","name":"azd provision","status":"succeeded","durationMs":29000}
+{"time":"2024-01-15T09:31:00Z","type":"result","name":"azd up","status":"failed","durationMs":60000,"error":"boom"}
+`)
+	// interrupted while writing the last event
+	write("20240115T100000Z-000002", `{"time":"2024-01-15T10:00:00Z","type":"command","name":"azd deploy"}
+{"time":"2024-01-15T10:00:`)
+
+	list, err := List(envRoot)
+	require.NoError(t, err)
+	require.Equal(t, []*Run{
+		{
+			Id:      "20240115T100000Z-000002",
+			Command: "azd deploy",
+			Started: started.Add(30 * time.Minute),
+		},
+		{
+			Id:         "20240115T093000Z-000001",
+			Command:    "azd up",
+			Started:    started,
+			Status:     StatusFailed,
+			DurationMs: 60000,
+			Error:      "boom",
+		},
+	}, list)
+
+	_, err = Read(envRoot, "missing")
+	require.ErrorIs(t, err, ErrRunNotFound)
+
+	_, err = Read(envRoot, "../runs/20240115T093000Z-000001")
+	require.ErrorIs(t, err, ErrRunNotFound)
+
+	list, err = List(t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func Test_prune(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"3", "1", "2"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, id+FileExtension), nil, 0600))
+	}
+
+	require.NoError(t, prune(dir, 2))
+
+	remaining, err := ids(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2", "3"}, remaining)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrRunNotFound is returned when the environment has no run with the given id
+var ErrRunNotFound = errors.New("run not found")
+
+// Run summarizes a recorded run
+type Run struct {
+	Id      string    `json:"id"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+	// Status of the run. Empty when the run didn't complete, ex) azd was interrupted, or is still running.
+	Status     Status `json:"status,omitempty"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// List returns the runs recorded in the directory of the environment at envRoot, most recent first
+func List(envRoot string) ([]*Run, error) {
+	dir := filepath.Join(envRoot, DirectoryName)
+	ids, err := ids(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing runs: %w", err)
+	}
+
+	runs := []*Run{}
+	for i := len(ids) - 1; i >= 0; i-- {
+		events, err := Read(envRoot, ids[i])
+		if err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, summarize(ids[i], events))
+	}
+
+	return runs, nil
+}
+
+// Read returns the events of the run with the given id, recorded in the directory of the environment at envRoot
+func Read(envRoot string, id string) ([]*Event, error) {
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("run '%s': %w", id, ErrRunNotFound)
+	}
+
+	file, err := os.Open(filepath.Join(envRoot, DirectoryName, id+FileExtension))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run '%s': %w", id, ErrRunNotFound)
+		}
+		return nil, fmt.Errorf("reading run '%s': %w", id, err)
+	}
+	defer file.Close()
+
+	events := []*Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event Event
+		// the last line is incomplete when azd was killed while writing it
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading run '%s': %w", id, err)
+	}
+
+	return events, nil
+}
+
+// summarize returns the run of the events. The command of the run is the first command recorded, and its result the
+// last result of that command, since child commands, ex) azd provision run by azd up, record results too.
+func summarize(id string, events []*Event) *Run {
+	run := &Run{Id: id}
+	for _, event := range events {
+		switch {
+		case event.Type == CommandEvent && run.Command == "":
+			run.Command = event.Name
+			run.Started = event.Time
+		case event.Type == ResultEvent && event.Name == run.Command:
+			run.Status = event.Status
+			run.DurationMs = event.DurationMs
+			run.Error = event.Error
+		}
+	}
+
+	return run
+}
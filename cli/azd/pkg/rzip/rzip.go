@@ -5,6 +5,7 @@ package rzip
 
 import (
 	"archive/zip"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -13,12 +14,25 @@ import (
 )
 
 func CreateFromDirectory(source string, buf *os.File) error {
+	return CreateFromDirectoryWithFilter(source, buf, nil)
+}
+
+// CreateFromDirectoryWithFilter creates the zip of the directory without the files and directories the filter excludes.
+// The files under excluded directories aren't visited.
+func CreateFromDirectoryWithFilter(source string, buf *os.File, exclude func(path string, entry fs.DirEntry) bool) error {
 	w := zip.NewWriter(buf)
 	err := filepath.WalkDir(source, func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if path != source && exclude != nil && exclude(path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if info.IsDir() {
 			return nil
 		}
@@ -56,3 +70,53 @@ func CreateFromDirectory(source string, buf *os.File) error {
 
 	return w.Close()
 }
+
+// ExtractToDirectory extracts the zip file to the target directory, rejecting the files outside of it
+func ExtractToDirectory(source string, target string) error {
+	r, err := zip.OpenReader(source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		path := filepath.Join(target, filepath.FromSlash(file.Name))
+		if !strings.HasPrefix(path, filepath.Clean(target)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid file path in zip: %s", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := extractFile(file, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(file *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
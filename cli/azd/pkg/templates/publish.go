@@ -0,0 +1,327 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/ignore"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+)
+
+// The file of template repositories describing the template in galleries
+const MetadataFileName = "metadata.json"
+
+const (
+	// The OCI artifact type of templates pushed to container registries
+	ArtifactType = "application/vnd.azure.azd.template.v1"
+	// The media type of the content of template artifacts, a gzipped tarball of the template repository
+	ArtifactMediaType = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// The paths never packaged with templates, in addition to the paths of the .gitignore and .azdignore files of the template
+var packageIgnorePatterns = []string{".git/", ".azure/"}
+
+// TemplateMetadata is the metadata.json of a template repository
+type TemplateMetadata struct {
+	// The friendly short name of the template
+	Name string `json:"name"`
+	// The description of the template
+	Description string `json:"description"`
+	// The repository of the template, ex) {owner}/{repo} for GitHub repositories
+	RepositoryPath string `json:"repositoryPath"`
+	// The version of the template, ex) 1.2.0. Tags the template when pushed to container registries.
+	Version string `json:"version,omitempty"`
+	// The tags of the template, ex) the languages and Azure services it uses
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Template returns the template as listed in galleries
+func (m *TemplateMetadata) Template() Template {
+	return Template{
+		Name:           m.Name,
+		Description:    m.Description,
+		RepositoryPath: m.RepositoryPath,
+	}
+}
+
+// ValidateTemplate validates the template repository at path: its azure.yaml loads, its infra folder has the main module
+// of the infrastructure, and its metadata.json describes the template. All the problems found are returned together.
+func ValidateTemplate(ctx context.Context, path string) (*TemplateMetadata, error) {
+	problems := []string{}
+
+	projectConfig, err := project.Load(ctx, filepath.Join(path, azdcontext.ProjectFileName))
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else if problem := validateInfra(path, projectConfig); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	metadata, err := readMetadata(path)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		if metadata.Name == "" {
+			problems = append(problems, fmt.Sprintf("%s has no 'name'", MetadataFileName))
+		}
+
+		if metadata.Description == "" {
+			problems = append(problems, fmt.Sprintf("%s has no 'description'", MetadataFileName))
+		}
+
+		if metadata.RepositoryPath == "" {
+			problems = append(problems, fmt.Sprintf("%s has no 'repositoryPath'", MetadataFileName))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("template '%s' is not valid:\n - %s", path, strings.Join(problems, "\n - "))
+	}
+
+	return metadata, nil
+}
+
+// validateInfra returns the problem of the infra folder of the project, or an empty string when it has the main module
+func validateInfra(path string, projectConfig *project.ProjectConfig) string {
+	infraPath := projectConfig.Infra.Path
+	if !filepath.IsAbs(infraPath) {
+		infraPath = filepath.Join(path, infraPath)
+	}
+
+	module := projectConfig.Infra.Module
+	if module == "" {
+		module = "main"
+	}
+
+	entries, err := os.ReadDir(infraPath)
+	if err != nil {
+		return fmt.Sprintf("reading infra folder '%s': %v", projectConfig.Infra.Path, err)
+	}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && strings.TrimSuffix(entry.Name(), ext) == module &&
+			(ext == ".bicep" || ext == ".json" || ext == ".tf") {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("infra folder '%s' has no module '%s'", projectConfig.Infra.Path, module)
+}
+
+func readMetadata(path string) (*TemplateMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(path, MetadataFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s is missing", MetadataFileName)
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", MetadataFileName, err)
+	}
+
+	var metadata TemplateMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", MetadataFileName, err)
+	}
+
+	return &metadata, nil
+}
+
+// PackageTemplate packages the template repository at path as a gzipped tarball, without the .git and .azure folders and
+// the files excluded by the .gitignore and .azdignore files at its root. Packages of the same files are identical.
+func PackageTemplate(path string) ([]byte, error) {
+	matcher := ignore.New(path)
+	if err := matcher.AddPatterns("default", packageIgnorePatterns...); err != nil {
+		return nil, err
+	}
+
+	for _, ignoreFile := range []string{".gitignore", ignore.FileName} {
+		if err := matcher.AddFile(filepath.Join(path, ignoreFile)); err != nil {
+			return nil, err
+		}
+	}
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filePath == path {
+			return nil
+		}
+
+		if matcher.Match(filePath, entry.IsDir()) != nil {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Symbolic links and other special files are not packaged
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(path, filePath)
+		if err != nil {
+			return err
+		}
+
+		header.Name = filepath.ToSlash(relative)
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+		header.ModTime = time.Unix(0, 0)
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("packaging template: %w", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("packaging template: %w", err)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("packaging template: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// PublishToGallery adds the template to the gallery file, a JSON list of templates like the curated list of azd, or
+// updates the template of the gallery with the same repository. The file is created when missing.
+func PublishToGallery(galleryPath string, template Template) error {
+	gallery := []Template{}
+
+	data, err := os.ReadFile(galleryPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading gallery: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &gallery); err != nil {
+			return fmt.Errorf("parsing gallery '%s': %w", galleryPath, err)
+		}
+	}
+
+	updated := false
+	for i, existing := range gallery {
+		if strings.EqualFold(existing.RepositoryPath, template.RepositoryPath) {
+			gallery[i] = template
+			updated = true
+		}
+	}
+
+	if !updated {
+		gallery = append(gallery, template)
+	}
+
+	data, err = json.MarshalIndent(gallery, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(galleryPath), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating gallery folder: %w", err)
+	}
+
+	if err := os.WriteFile(galleryPath, append(data, '\n'), osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing gallery: %w", err)
+	}
+
+	return nil
+}
+
+// ExtractTemplate extracts the template packaged by PackageTemplate to the directory
+func ExtractTemplate(content []byte, directory string) error {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("extracting template: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("extracting template: %w", err)
+		}
+
+		// Entries are never extracted outside of the directory
+		target := filepath.Join(directory, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(directory)+string(os.PathSeparator)) {
+			return fmt.Errorf("extracting template: invalid path '%s'", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, osutil.PermissionDirectory); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tarReader, target, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractFile(reader io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
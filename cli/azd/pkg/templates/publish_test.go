@@ -0,0 +1,120 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplateFiles(t *testing.T, path string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(path, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+	}
+}
+
+func Test_ValidateTemplate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		path := t.TempDir()
+		writeTemplateFiles(t, path, map[string]string{
+			"azure.yaml":     "name: todo\n",
+			"infra/main.tf":  "",
+			"metadata.json":  `{"name": "Todo", "description": "A todo app", "repositoryPath": "contoso/todo"}`,
+			"src/api/app.py": "",
+		})
+
+		metadata, err := ValidateTemplate(context.Background(), path)
+		require.NoError(t, err)
+		require.Equal(t, Template{
+			Name:           "Todo",
+			Description:    "A todo app",
+			RepositoryPath: "contoso/todo",
+		}, metadata.Template())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		path := t.TempDir()
+		writeTemplateFiles(t, path, map[string]string{
+			"azure.yaml":       "name: todo\ninfra:\n  module: app\n",
+			"infra/main.bicep": "",
+			"metadata.json":    `{"name": "Todo"}`,
+		})
+
+		_, err := ValidateTemplate(context.Background(), path)
+		require.ErrorContains(t, err, "infra folder 'infra' has no module 'app'")
+		require.ErrorContains(t, err, "metadata.json has no 'description'")
+		require.ErrorContains(t, err, "metadata.json has no 'repositoryPath'")
+	})
+
+	t.Run("MissingFiles", func(t *testing.T) {
+		_, err := ValidateTemplate(context.Background(), t.TempDir())
+		require.ErrorContains(t, err, "metadata.json is missing")
+	})
+}
+
+func Test_PackageTemplate(t *testing.T) {
+	path := t.TempDir()
+	writeTemplateFiles(t, path, map[string]string{
+		"azure.yaml":             "name: todo\n",
+		"infra/main.bicep":       "targetScope = 'subscription'\n",
+		"src/api/app.py":         "print('todo')\n",
+		"src/api/.env":           "SECRET=1\n",
+		"src/web/node_modules/x": "",
+		".gitignore":             ".env\nnode_modules/\n",
+		".git/HEAD":              "ref: refs/heads/main\n",
+		".azure/dev/.env":        "AZURE_ENV_NAME=dev\n",
+	})
+
+	content, err := PackageTemplate(path)
+	require.NoError(t, err)
+
+	// Packages of the same files are identical
+	again, err := PackageTemplate(path)
+	require.NoError(t, err)
+	require.Equal(t, content, again)
+
+	directory := t.TempDir()
+	require.NoError(t, ExtractTemplate(content, directory))
+
+	data, err := os.ReadFile(filepath.Join(directory, "src", "api", "app.py"))
+	require.NoError(t, err)
+	require.Equal(t, "print('todo')\n", string(data))
+	require.FileExists(t, filepath.Join(directory, "infra", "main.bicep"))
+	require.FileExists(t, filepath.Join(directory, ".gitignore"))
+
+	for _, ignored := range []string{".git", ".azure", "src/api/.env", "src/web/node_modules"} {
+		require.NoFileExists(t, filepath.Join(directory, filepath.FromSlash(ignored)))
+		require.NoDirExists(t, filepath.Join(directory, filepath.FromSlash(ignored)))
+	}
+}
+
+func Test_PublishToGallery(t *testing.T) {
+	galleryPath := filepath.Join(t.TempDir(), "gallery", "templates.json")
+
+	require.NoError(t, PublishToGallery(galleryPath, Template{
+		Name: "Todo", Description: "A todo app", RepositoryPath: "contoso/todo",
+	}))
+	require.NoError(t, PublishToGallery(galleryPath, Template{
+		Name: "Chat", Description: "A chat app", RepositoryPath: "contoso/chat",
+	}))
+
+	// Templates of the same repository are updated
+	require.NoError(t, PublishToGallery(galleryPath, Template{
+		Name: "Todo", Description: "A todo app, in Python", RepositoryPath: "Contoso/Todo",
+	}))
+
+	data, err := os.ReadFile(galleryPath)
+	require.NoError(t, err)
+
+	var gallery []Template
+	require.NoError(t, json.Unmarshal(data, &gallery))
+	require.Equal(t, []Template{
+		{Name: "Todo", Description: "A todo app, in Python", RepositoryPath: "Contoso/Todo"},
+		{Name: "Chat", Description: "A chat app", RepositoryPath: "contoso/chat"},
+	}, gallery)
+}
@@ -0,0 +1,176 @@
+package templates
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
+)
+
+// VerificationStep is a step of the verification of a template, ex) provisioning it or probing an endpoint
+type VerificationStep struct {
+	// The name of the step, ex) provision
+	Name string
+	// How long the step took
+	Duration time.Duration
+	// The reason the step failed, nil when it passed or was skipped
+	Err error
+	// The reason the step was skipped, empty when it ran
+	SkippedReason string
+}
+
+// VerificationReport is the result of the verification of a template
+type VerificationReport struct {
+	// The name of the template
+	Template string
+	// The steps of the verification, in the order they ran
+	Steps []*VerificationStep
+}
+
+// Failed returns true when any step of the verification failed
+func (r *VerificationReport) Failed() bool {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report in the JUnit XML format understood by CI systems, as a test suite of the template with
+// a test case per step
+func (r *VerificationReport) WriteJUnit(writer io.Writer) error {
+	suite := junitTestSuite{
+		Name:  r.Template,
+		Cases: []junitTestCase{},
+	}
+
+	var total time.Duration
+	for _, step := range r.Steps {
+		testCase := junitTestCase{
+			Name:      step.Name,
+			ClassName: r.Template,
+			Time:      junitTime(step.Duration),
+		}
+
+		if step.Err != nil {
+			testCase.Failure = &junitMessage{Message: step.Err.Error(), Text: step.Err.Error()}
+			suite.Failures++
+		} else if step.SkippedReason != "" {
+			testCase.Skipped = &junitMessage{Message: step.SkippedReason}
+			suite.Skipped++
+		}
+
+		total += step.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Tests = len(suite.Cases)
+	suite.Time = junitTime(total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func junitTime(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// ProbeEndpoint sends GET requests to the endpoint every interval of the wait config, until it responds without a
+// server error, the timeout of the wait config elapses on the clock or ctx is done. Endpoints are usually not ready right
+// after deployment, ex) while the first container image is pulled. The timeout is checked between the requests, a request
+// which doesn't respond only stops once ctx is done, callers set the deadline of ctx to the timeout.
+func ProbeEndpoint(
+	ctx context.Context,
+	clk clock.Clock,
+	waitConfig wait.Config,
+	client httputil.HttpClient,
+	endpoint string,
+) error {
+	var probeErr error
+	err := wait.Until(ctx, clk, waitConfig, func(ctx context.Context) (bool, error) {
+		probeErr = probeEndpoint(ctx, client, endpoint)
+		return probeErr == nil, nil
+	})
+	if err != nil {
+		// The endpoint was probed before the wait stopped, its error is the reason it's not ready
+		return fmt.Errorf("endpoint '%s' is not ready: %w", endpoint, probeErr)
+	}
+
+	return nil
+}
+
+func probeEndpoint(ctx context.Context, client httputil.HttpClient, endpoint string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerificationReport_WriteJUnit(t *testing.T) {
+	report := &VerificationReport{
+		Template: "todo",
+		Steps: []*VerificationStep{
+			{Name: "provision", Duration: 90 * time.Second},
+			{Name: "probe web https://todo.contoso.com/", Duration: 1500 * time.Millisecond, Err: errors.New("not ready")},
+			{Name: "probe api http://10.0.0.1", SkippedReason: "only reachable inside the cluster"},
+		},
+	}
+	require.True(t, report.Failed())
+
+	var buffer bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buffer))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1" time="91.500">
+  <testsuite name="todo" tests="3" failures="1" skipped="1" time="91.500">
+    <testcase name="provision" classname="todo" time="90.000"></testcase>
+    <testcase name="probe web https://todo.contoso.com/" classname="todo" time="1.500">
+      <failure message="not ready">not ready</failure>
+    </testcase>
+    <testcase name="probe api http://10.0.0.1" classname="todo" time="0.000">
+      <skipped message="only reachable inside the cluster"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buffer.String())
+}
+
+func Test_ProbeEndpoint(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		requests := 0
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.URL.Host == "todo.contoso.com"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			requests++
+			if requests < 3 {
+				return mocks.CreateEmptyHttpResponse(request, http.StatusBadGateway)
+			}
+
+			// Client errors mean the endpoint is up, ex) endpoints requiring authentication
+			return mocks.CreateEmptyHttpResponse(request, http.StatusUnauthorized)
+		})
+
+		probeWait := wait.Config{Timeout: time.Minute, Interval: time.Millisecond}
+		err := ProbeEndpoint(
+			*mockContext.Context, clock.New(), probeWait, mockContext.HttpClient, "https://todo.contoso.com/")
+		require.NoError(t, err)
+		require.Equal(t, 3, requests)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.URL.Host == "todo.contoso.com"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+		})
+
+		mockClock := clock.NewMock()
+		probeWait := wait.Config{Timeout: 10 * time.Minute, Interval: 10 * time.Second}
+		result := make(chan error, 1)
+		go func() {
+			result <- ProbeEndpoint(
+				*mockContext.Context, mockClock, probeWait, mockContext.HttpClient, "https://todo.contoso.com/")
+		}()
+
+		// The mock clock elapses the timeout, not the wall clock
+		for {
+			select {
+			case err := <-result:
+				require.ErrorContains(t, err,
+					"endpoint 'https://todo.contoso.com/' is not ready: responded with status 503")
+				return
+			case <-time.After(time.Millisecond):
+				mockClock.Add(probeWait.Interval)
+			}
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.URL.Host == "todo.contoso.com"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+		})
+
+		// The timeout of the verification cancels the probe before its own timeout
+		ctx, cancel := context.WithTimeout(*mockContext.Context, 20*time.Millisecond)
+		defer cancel()
+
+		probeWait := wait.Config{Timeout: time.Hour, Interval: time.Millisecond}
+		err := ProbeEndpoint(ctx, clock.New(), probeWait, mockContext.HttpClient, "https://todo.contoso.com/")
+		require.ErrorContains(t, err, "endpoint 'https://todo.contoso.com/' is not ready")
+	})
+}
@@ -13,8 +13,9 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 	"github.com/google/uuid"
-	"github.com/sethvargo/go-retry"
 )
 
 // Required model structure for Azure Credentials tools
@@ -69,17 +70,7 @@ func (cli *azCli) CreateOrUpdateServicePrincipal(
 		ResourceManagerEndpointUrl: "https://management.azure.com/",
 	}
 
-	credentialsJson, err := json.Marshal(azureCreds)
-	if err != nil {
-		return nil, fmt.Errorf("failed marshalling Azure credentials to JSON: %w", err)
-	}
-
-	var rawMessage json.RawMessage
-	if err := json.Unmarshal(credentialsJson, &rawMessage); err != nil {
-		return nil, fmt.Errorf("failed unmarshalling JSON to raw message: %w", err)
-	}
-
-	return rawMessage, nil
+	return marshalAzureCredentials(azureCreds)
 }
 
 // Gets or creates an application with the specified name
@@ -216,7 +207,7 @@ func (cli *azCli) ensureRoleAssignment(
 ) error {
 	// Find the specified role in the subscription scope
 	scope := azure.SubscriptionRID(subscriptionId)
-	roleDefinition, err := cli.getRoleDefinition(ctx, subscriptionId, scope, roleName)
+	roleDefinition, err := cli.GetRoleDefinition(ctx, subscriptionId, scope, roleName)
 	if err != nil {
 		return err
 	}
@@ -230,8 +221,11 @@ func (cli *azCli) ensureRoleAssignment(
 	return nil
 }
 
+// principalPropagationPolicy retries operations failing until new principals are available in Azure AD
+var principalPropagationPolicy = wait.ExponentialRetry(2*time.Second, 15*time.Second, time.Minute)
+
 // Applies the role assignment to the specified service principal
-// This operation will retry up to 10 times to ensure the new service principal is available in Azure AD
+// This operation retries for up to a minute to ensure the new service principal is available in Azure AD
 func (cli *azCli) applyRoleAssignmentWithRetry(
 	ctx context.Context,
 	subscriptionId string,
@@ -248,7 +242,7 @@ func (cli *azCli) applyRoleAssignmentWithRetry(
 
 	// There is a lag in the application/service principal becoming available in Azure AD
 	// This can cause the role assignment operation to fail
-	return retry.Do(ctx, retry.WithMaxRetries(10, retry.NewConstant(time.Second*5)), func(ctx context.Context) error {
+	return wait.Retry(ctx, clock.New(), principalPropagationPolicy, func(ctx context.Context) error {
 		_, err = roleAssignmentsClient.Create(ctx, scope, roleAssignmentId, armauthorization.RoleAssignmentCreateParameters{
 			Properties: &armauthorization.RoleAssignmentProperties{
 				PrincipalID:      servicePrincipal.Id,
@@ -263,7 +257,7 @@ func (cli *azCli) applyRoleAssignmentWithRetry(
 				return nil
 			}
 
-			return retry.RetryableError(
+			return wait.RetryableError(
 				fmt.Errorf(
 					"failed assigning role assignment '%s' to service principal '%s' : %w",
 					*roleDefinition.Name,
@@ -278,7 +272,7 @@ func (cli *azCli) applyRoleAssignmentWithRetry(
 }
 
 // Find the Azure role definition for the specified scope and role name
-func (cli *azCli) getRoleDefinition(
+func (cli *azCli) GetRoleDefinition(
 	ctx context.Context,
 	subscriptionId string,
 	scope string,
@@ -311,6 +305,94 @@ func (cli *azCli) getRoleDefinition(
 	return roleDefinitions[0], nil
 }
 
+// Gets the object id of the service principal of the specified application
+func (cli *azCli) GetServicePrincipalId(ctx context.Context, subscriptionId string, appId string) (string, error) {
+	graphClient, err := cli.createGraphClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	matchingItems, err := graphClient.
+		ServicePrincipals().
+		Filter(fmt.Sprintf("appId eq '%s'", appId)).
+		Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed retrieving service principal list: %w", err)
+	}
+
+	if len(matchingItems.Value) == 0 || matchingItems.Value[0].Id == nil {
+		return "", fmt.Errorf("service principal of application '%s' was not found", appId)
+	}
+
+	return *matchingItems.Value[0].Id, nil
+}
+
+// Lists the role assignments of the principal at, above and below the specified scope
+func (cli *azCli) ListRoleAssignments(
+	ctx context.Context,
+	subscriptionId string,
+	scope string,
+	principalId string,
+) ([]*armauthorization.RoleAssignment, error) {
+	roleAssignmentsClient, err := cli.createRoleAssignmentsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := roleAssignmentsClient.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: convert.RefOf(fmt.Sprintf("principalId eq '%s'", principalId)),
+	})
+
+	roleAssignments := []*armauthorization.RoleAssignment{}
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting next page of role assignments: %w", err)
+		}
+
+		roleAssignments = append(roleAssignments, page.RoleAssignmentListResult.Value...)
+	}
+
+	return roleAssignments, nil
+}
+
+// Creates the role assignment in the specified scope. Role assignments which already exist are not an error.
+func (cli *azCli) CreateRoleAssignment(
+	ctx context.Context,
+	subscriptionId string,
+	scope string,
+	roleAssignmentName string,
+	properties *armauthorization.RoleAssignmentProperties,
+) error {
+	roleAssignmentsClient, err := cli.createRoleAssignmentsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	// There is a lag in new managed identities becoming available in Azure AD, which fails the role assignment
+	policy := principalPropagationPolicy
+	policy.Retryable = func(err error) bool {
+		var responseError *azcore.ResponseError
+		return errors.As(err, &responseError) && responseError.ErrorCode == "PrincipalNotFound"
+	}
+
+	return wait.Retry(ctx, clock.New(), policy, func(ctx context.Context) error {
+		_, err := roleAssignmentsClient.Create(
+			ctx, scope, roleAssignmentName, armauthorization.RoleAssignmentCreateParameters{
+				Properties: properties,
+			}, nil)
+
+		// If the response is a 409 conflict then the role has already been assigned.
+		var responseError *azcore.ResponseError
+		if errors.As(err, &responseError) && responseError.StatusCode == http.StatusConflict {
+			return nil
+		}
+
+		return err
+	})
+}
+
 // Creates a graph users client using credentials from the Go context.
 func (cli *azCli) createGraphClient(
 	ctx context.Context,
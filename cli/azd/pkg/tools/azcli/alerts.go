@@ -0,0 +1,173 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	actionGroupsApiVersion = "2023-01-01"
+	metricAlertsApiVersion = "2018-03-01"
+)
+
+// AzCliActionGroup is an action group notifying email addresses and webhooks of the alerts it's the action of
+type AzCliActionGroup struct {
+	// The short name of the group, shown in emails, at most 12 characters
+	ShortName string
+	Emails    []string
+	Webhooks  []string
+}
+
+// AzCliMetricAlert is an alert rule firing when a metric of a resource crosses a static threshold
+type AzCliMetricAlert struct {
+	Description string
+	// 0 (critical) to 4 (verbose)
+	Severity   int
+	ResourceId string
+	// The namespace of the metric, usually the type of the resource
+	MetricNamespace string
+	MetricName      string
+	// Average, Minimum, Maximum, Total or Count
+	Aggregation string
+	// GreaterThan, GreaterThanOrEqual, LessThan or LessThanOrEqual
+	Operator  string
+	Threshold float64
+	// Values of the dimensions of the metric the alert is limited to, ex) statusCodeCategory: 5xx
+	Dimensions map[string]string
+	// The action group notified when the alert fires, none when empty
+	ActionGroupId string
+}
+
+// CreateOrUpdateActionGroup creates, or updates, the action group named name in the resource group
+func (cli *azCli) CreateOrUpdateActionGroup(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	name string,
+	actionGroup AzCliActionGroup,
+) error {
+	emailReceivers := []map[string]any{}
+	for i, email := range actionGroup.Emails {
+		emailReceivers = append(emailReceivers, map[string]any{
+			"name":                 fmt.Sprintf("email-%d", i+1),
+			"emailAddress":         email,
+			"useCommonAlertSchema": true,
+		})
+	}
+
+	webhookReceivers := []map[string]any{}
+	for i, webhook := range actionGroup.Webhooks {
+		webhookReceivers = append(webhookReceivers, map[string]any{
+			"name":                 fmt.Sprintf("webhook-%d", i+1),
+			"serviceUri":           webhook,
+			"useCommonAlertSchema": true,
+		})
+	}
+
+	body := map[string]any{
+		"location": "Global",
+		"properties": map[string]any{
+			"groupShortName":   actionGroup.ShortName,
+			"enabled":          true,
+			"emailReceivers":   emailReceivers,
+			"webhookReceivers": webhookReceivers,
+		},
+	}
+
+	if err := armSend(
+		ctx,
+		cli,
+		subscriptionId,
+		http.MethodPut,
+		fmt.Sprintf(
+			"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/actionGroups/%s",
+			subscriptionId, resourceGroupName, name,
+		),
+		actionGroupsApiVersion,
+		body,
+	); err != nil {
+		return fmt.Errorf("creating action group '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// CreateOrUpdateMetricAlert creates, or updates, the metric alert rule named name in the resource group
+func (cli *azCli) CreateOrUpdateMetricAlert(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	name string,
+	alert AzCliMetricAlert,
+) error {
+	dimensionNames := make([]string, 0, len(alert.Dimensions))
+	for dimensionName := range alert.Dimensions {
+		dimensionNames = append(dimensionNames, dimensionName)
+	}
+	sort.Strings(dimensionNames)
+
+	dimensions := []map[string]any{}
+	for _, dimensionName := range dimensionNames {
+		dimensions = append(dimensions, map[string]any{
+			"name":     dimensionName,
+			"operator": "Include",
+			"values":   []string{alert.Dimensions[dimensionName]},
+		})
+	}
+
+	actions := []map[string]any{}
+	if alert.ActionGroupId != "" {
+		actions = append(actions, map[string]any{"actionGroupId": alert.ActionGroupId})
+	}
+
+	body := map[string]any{
+		"location": "global",
+		"properties": map[string]any{
+			"description":         alert.Description,
+			"severity":            alert.Severity,
+			"enabled":             true,
+			"scopes":              []string{alert.ResourceId},
+			"evaluationFrequency": "PT1M",
+			"windowSize":          "PT5M",
+			"criteria": map[string]any{
+				"odata.type": "Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria",
+				"allOf": []map[string]any{
+					{
+						"criterionType":   "StaticThresholdCriterion",
+						"name":            "threshold",
+						"metricNamespace": alert.MetricNamespace,
+						"metricName":      alert.MetricName,
+						"timeAggregation": alert.Aggregation,
+						"operator":        alert.Operator,
+						"threshold":       alert.Threshold,
+						"dimensions":      dimensions,
+					},
+				},
+			},
+			"autoMitigate": true,
+			"actions":      actions,
+		},
+	}
+
+	if err := armSend(
+		ctx,
+		cli,
+		subscriptionId,
+		http.MethodPut,
+		fmt.Sprintf(
+			"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/metricAlerts/%s",
+			subscriptionId, resourceGroupName, name,
+		),
+		metricAlertsApiVersion,
+		body,
+	); err != nil {
+		return fmt.Errorf("creating metric alert '%s': %w", name, err)
+	}
+
+	return nil
+}
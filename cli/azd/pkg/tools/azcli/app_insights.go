@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const appInsightsAnnotationsApiVersion = "2015-05-01"
+
+// AzCliAppInsightsAnnotation is an annotation of an Application Insights component, shown on its metric charts
+type AzCliAppInsightsAnnotation struct {
+	Name string
+	// The category of the annotation, ex) Deployment for release annotations
+	Category  string
+	EventTime time.Time
+	// Properties of the annotation, ex) the ReleaseName of a release annotation
+	Properties map[string]string
+}
+
+// CreateAppInsightsAnnotation creates an annotation of the Application Insights component
+func (cli *azCli) CreateAppInsightsAnnotation(
+	ctx context.Context,
+	subscriptionId string,
+	componentId string,
+	annotation AzCliAppInsightsAnnotation,
+) error {
+	// the properties of annotations are a JSON string
+	properties, err := json.Marshal(annotation.Properties)
+	if err != nil {
+		return fmt.Errorf("marshalling annotation properties: %w", err)
+	}
+
+	body := []map[string]any{
+		{
+			"Id":             uuid.NewString(),
+			"AnnotationName": annotation.Name,
+			"Category":       annotation.Category,
+			"EventTime":      annotation.EventTime.UTC().Format(time.RFC3339),
+			"Properties":     string(properties),
+		},
+	}
+
+	if err := armSend(
+		ctx,
+		cli,
+		subscriptionId,
+		http.MethodPut,
+		fmt.Sprintf("%s/Annotations", componentId),
+		appInsightsAnnotationsApiVersion,
+		body,
+	); err != nil {
+		return fmt.Errorf("creating annotation '%s': %w", annotation.Name, err)
+	}
+
+	return nil
+}
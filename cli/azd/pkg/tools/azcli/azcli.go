@@ -10,6 +10,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
@@ -17,6 +18,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 )
 
 var (
@@ -26,6 +29,9 @@ var (
 	ErrDeploymentNotFound       = errors.New("deployment not found")
 	ErrNoConfigurationValue     = errors.New("no value configured")
 	ErrAzCliSecretNotFound      = errors.New("secret not found")
+	ErrAzCliCertificateNotFound = errors.New("certificate not found")
+	ErrAzCliKeyVaultNotFound    = errors.New("key vault not found")
+	ErrResourceTypeNotFound     = errors.New("resource type not found")
 )
 
 type AzCli interface {
@@ -71,12 +77,40 @@ type AzCli interface {
 		resourceGroupName string,
 		accountName string,
 	) (armcognitiveservices.Account, error)
+	GetCognitiveAccountKey(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		accountName string,
+	) (string, error)
 	GetKeyVaultSecret(
 		ctx context.Context,
 		subscriptionId string,
 		vaultName string,
 		secretName string,
 	) (*AzCliKeyVaultSecret, error)
+	// FindKeyVault finds the Key Vault by name among the vaults of the subscription. ErrAzCliKeyVaultNotFound is returned
+	// when the subscription has no such vault.
+	FindKeyVault(ctx context.Context, subscriptionId string, vaultName string) (*AzCliKeyVault, error)
+	// GetKeyVaultCertificate gets the current version of the certificate. ErrAzCliCertificateNotFound is returned when
+	// the vault has no such certificate.
+	GetKeyVaultCertificate(
+		ctx context.Context,
+		subscriptionId string,
+		vaultName string,
+		certificateName string,
+	) (*AzCliKeyVaultCertificate, error)
+	// CreateKeyVaultCertificate creates the certificate, or a new version of it when it exists, and waits for its issuer
+	// to issue it.
+	CreateKeyVaultCertificate(
+		ctx context.Context,
+		clk clock.Clock,
+		waitConfig wait.Config,
+		subscriptionId string,
+		vaultName string,
+		certificateName string,
+		policy AzCliKeyVaultCertificatePolicy,
+	) (*AzCliKeyVaultCertificate, error)
 	GetAppConfig(
 		ctx context.Context, subscriptionId string, resourceGroupName string, configName string) (*AzCliAppConfig, error)
 	PurgeApim(ctx context.Context, subscriptionId string, apimName string, location string) error
@@ -91,14 +125,16 @@ type AzCli interface {
 		subscriptionId string,
 		resourceGroup string,
 		appName string,
-		deployZipFile io.Reader,
+		deployZipFile io.ReadSeeker,
+		progress func(percent int),
 	) (*string, error)
 	DeployFunctionAppUsingZipFile(
 		ctx context.Context,
 		subscriptionID string,
 		resourceGroup string,
 		funcName string,
-		deployZipFile io.Reader,
+		deployZipFile io.ReadSeeker,
+		progress func(percent int),
 	) (*string, error)
 	GetFunctionAppProperties(
 		ctx context.Context,
@@ -114,6 +150,7 @@ type AzCli interface {
 		armTemplate azure.RawArmTemplate,
 		parameters azure.ArmParameters,
 		tags map[string]*string,
+		options *DeployOptions,
 	) (*armresources.DeploymentExtended, error)
 	DeployToResourceGroup(
 		ctx context.Context,
@@ -123,7 +160,24 @@ type AzCli interface {
 		armTemplate azure.RawArmTemplate,
 		parameters azure.ArmParameters,
 		tags map[string]*string,
+		options *DeployOptions,
 	) (*armresources.DeploymentExtended, error)
+	WhatIfDeployToSubscription(
+		ctx context.Context,
+		subscriptionId string,
+		location string,
+		deploymentName string,
+		armTemplate azure.RawArmTemplate,
+		parameters azure.ArmParameters,
+	) (*armresources.WhatIfOperationResult, error)
+	WhatIfDeployToResourceGroup(
+		ctx context.Context,
+		subscriptionId,
+		resourceGroup,
+		deploymentName string,
+		armTemplate azure.RawArmTemplate,
+		parameters azure.ArmParameters,
+	) (*armresources.WhatIfOperationResult, error)
 	DeleteSubscriptionDeployment(ctx context.Context, subscriptionId string, deploymentName string) error
 	DeleteResourceGroup(ctx context.Context, subscriptionId string, resourceGroupName string) error
 	CreateOrUpdateResourceGroup(
@@ -164,6 +218,119 @@ type AzCli interface {
 		resourceGroupName string,
 		deploymentName string,
 	) ([]*armresources.DeploymentOperation, error)
+	ExportResourceGroupTemplate(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+	) (azure.RawArmTemplate, error)
+	ListComputeSkus(ctx context.Context, subscriptionId string, location string) ([]AzCliComputeSku, error)
+	ListComputeUsages(ctx context.Context, subscriptionId string, location string) ([]AzCliUsage, error)
+	ListPostgresFlexibleServerSkus(ctx context.Context, subscriptionId string, location string) ([]string, error)
+	GetResourceTypeLocations(ctx context.Context, subscriptionId string, resourceType string) ([]string, error)
+	// GetResourceTypeApiVersions returns the api-versions of a resource type available in the cloud of the subscription
+	GetResourceTypeApiVersions(ctx context.Context, subscriptionId string, resourceType string) ([]string, error)
+	ListCognitiveModels(ctx context.Context, subscriptionId string, location string) ([]AzCliCognitiveModel, error)
+	ListCognitiveUsages(ctx context.Context, subscriptionId string, location string) ([]AzCliUsage, error)
+	ListDiagnosticSettingsCategories(
+		ctx context.Context,
+		subscriptionId string,
+		resourceId string,
+	) ([]AzCliDiagnosticSettingsCategory, error)
+	CreateOrUpdateDiagnosticSetting(
+		ctx context.Context,
+		subscriptionId string,
+		resourceId string,
+		name string,
+		setting AzCliDiagnosticSetting,
+	) error
+	CreateAppInsightsAnnotation(
+		ctx context.Context,
+		subscriptionId string,
+		componentId string,
+		annotation AzCliAppInsightsAnnotation,
+	) error
+	CreateOrUpdateActionGroup(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		name string,
+		actionGroup AzCliActionGroup,
+	) error
+	CreateOrUpdateMetricAlert(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		name string,
+		alert AzCliMetricAlert,
+	) error
+	StartChaosExperiment(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		name string,
+	) error
+	ListChaosExperimentExecutions(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		name string,
+	) ([]AzCliChaosExperimentExecution, error)
+	CheckPolicyRestrictions(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceContent map[string]any,
+		apiVersion string,
+	) ([]AzCliPolicyEvaluation, error)
+	ListServiceBusTopics(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		namespaceName string,
+	) ([]string, error)
+	CreateServiceBusTopicSubscription(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		namespaceName string,
+		topicName string,
+		name string,
+	) error
+	DeleteServiceBusTopicSubscription(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		namespaceName string,
+		topicName string,
+		name string,
+	) error
+	ReceiveServiceBusMessage(
+		ctx context.Context,
+		subscriptionId string,
+		namespaceName string,
+		entityPath string,
+		timeout time.Duration,
+	) (*AzCliServiceBusMessage, error)
+	CreateEventGridSubscription(
+		ctx context.Context,
+		subscriptionId string,
+		topicId string,
+		name string,
+		endpointUrl string,
+	) error
+	DeleteEventGridSubscription(ctx context.Context, subscriptionId string, topicId string, name string) error
+	// UploadBlob uploads the content to the block blob of the url, ex)
+	// https://<account>.blob.core.windows.net/<container>/<name>, replacing the blob when it exists
+	UploadBlob(
+		ctx context.Context,
+		subscriptionId string,
+		blobUrl string,
+		content io.ReadSeekCloser,
+		contentType string,
+		metadata map[string]string,
+	) error
+	// DownloadBlob writes the content of the blob of the url to the writer
+	DownloadBlob(ctx context.Context, subscriptionId string, blobUrl string, writer io.Writer) error
 	// CreateOrUpdateServicePrincipal creates a service principal using a given name and returns a JSON object which
 	// may be used by tools which understand the `AZURE_CREDENTIALS` format (i.e. the `sdk-auth` format). The service
 	// principal is assigned a given role. If an existing principal exists with the given name,
@@ -174,12 +341,102 @@ type AzCli interface {
 		applicationName string,
 		rolesToAssign []string,
 	) (json.RawMessage, error)
+	// GetServicePrincipalId returns the object id of the service principal of the application with the given app id.
+	GetServicePrincipalId(ctx context.Context, subscriptionId string, appId string) (string, error)
+	// GetServicePrincipal gets the service principal with the object id or display name, along with the credentials of
+	// its application.
+	GetServicePrincipal(ctx context.Context, subscriptionId string, principal string) (*AzCliServicePrincipal, error)
+	// ResetServicePrincipalSecret replaces the secrets of the service principal with a new secret and returns a JSON
+	// object in the `AZURE_CREDENTIALS` format.
+	ResetServicePrincipalSecret(
+		ctx context.Context,
+		subscriptionId string,
+		servicePrincipal *AzCliServicePrincipal,
+	) (json.RawMessage, error)
+	// SetServicePrincipalCertificate replaces the certificates of the service principal with the PEM or DER encoded
+	// certificate.
+	SetServicePrincipalCertificate(
+		ctx context.Context,
+		subscriptionId string,
+		servicePrincipal *AzCliServicePrincipal,
+		certificate []byte,
+	) error
+	// EnsureServicePrincipalRoles assigns the roles to the service principal in the subscription.
+	EnsureServicePrincipalRoles(
+		ctx context.Context,
+		subscriptionId string,
+		servicePrincipal *AzCliServicePrincipal,
+		roleNames []string,
+	) error
+	// GetRoleDefinition finds the role definition with the given name, ex) Storage Blob Data Reader, in the scope.
+	GetRoleDefinition(
+		ctx context.Context,
+		subscriptionId string,
+		scope string,
+		roleName string,
+	) (*armauthorization.RoleDefinition, error)
+	// ListRoleAssignments lists the role assignments of the principal at, above and below the scope.
+	ListRoleAssignments(
+		ctx context.Context,
+		subscriptionId string,
+		scope string,
+		principalId string,
+	) ([]*armauthorization.RoleAssignment, error)
+	// ListPermissions lists the permissions of the signed-in principal at the scope, including the permissions
+	// inherited from the scopes above it.
+	ListPermissions(ctx context.Context, subscriptionId string, scope string) ([]AzCliPermission, error)
+	// CreateRoleAssignment creates the role assignment named roleAssignmentName, a GUID, in the scope. Role assignments
+	// which already exist are not an error.
+	CreateRoleAssignment(
+		ctx context.Context,
+		subscriptionId string,
+		scope string,
+		roleAssignmentName string,
+		properties *armauthorization.RoleAssignmentProperties,
+	) error
 	GetAppServiceProperties(
 		ctx context.Context,
 		subscriptionId string,
 		resourceGroupName string,
 		applicationName string,
 	) (*AzCliAppServiceProperties, error)
+	ExecAppServiceCommand(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		command string,
+	) (*AzCliAppServiceCommandResult, error)
+	StreamAppServiceLogs(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		logPath string,
+		writer io.Writer,
+	) error
+	RestartAppService(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+	) error
+	// ScaleAppServicePlan sets the number of instances of the app service plan hosting the app service
+	ScaleAppServicePlan(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		instances int,
+	) error
+	// UpdateAppServiceAppSettings adds or updates the app settings of the app service, keeping its other app settings
+	UpdateAppServiceAppSettings(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		settings map[string]string,
+	) error
 	GetStaticWebAppProperties(
 		ctx context.Context,
 		subscriptionID string,
@@ -248,15 +505,18 @@ type AzCliDeploymentOutput struct {
 }
 
 type AzCliResource struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Location string `json:"location"`
+	Id       string            `json:"id"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Location string            `json:"location"`
+	Tags     map[string]string `json:"tags,omitempty"`
 }
 
 type AzCliResourceExtended struct {
 	AzCliResource
 	Kind string `json:"kind"`
+	// The managed identities of the resource, if any
+	Identity *armresources.Identity `json:"identity,omitempty"`
 }
 
 type AzCliDeploymentResourceReference struct {
@@ -369,7 +629,11 @@ func (cli *azCli) clientOptionsBuilder(ctx context.Context) *azsdk.ClientOptions
 	return azsdk.NewClientOptionsBuilder().
 		WithTransport(cli.httpClient).
 		WithPerCallPolicy(azsdk.NewUserAgentPolicy(cli.UserAgent())).
-		WithPerCallPolicy(azsdk.NewMsCorrelationPolicy(ctx))
+		WithPerCallPolicy(azsdk.NewMsCorrelationPolicy(ctx)).
+		WithPerCallPolicy(azsdk.NewApiCallMetricsPolicy()).
+		WithPerCallPolicy(azsdk.NewApiVersionPolicy()).
+		WithPerRetryPolicy(azsdk.NewApiRetryMetricsPolicy()).
+		WithPerRetryPolicy(azsdk.NewArmRateLimitPolicy(azsdk.ArmRateLimiter()))
 }
 
 func clientOptionsBuilder(
@@ -379,5 +643,9 @@ func clientOptionsBuilder(
 	return azsdk.NewClientOptionsBuilder().
 		WithTransport(httpClient).
 		WithPerCallPolicy(azsdk.NewUserAgentPolicy(userAgent)).
-		WithPerCallPolicy(azsdk.NewMsCorrelationPolicy(ctx))
+		WithPerCallPolicy(azsdk.NewMsCorrelationPolicy(ctx)).
+		WithPerCallPolicy(azsdk.NewApiCallMetricsPolicy()).
+		WithPerCallPolicy(azsdk.NewApiVersionPolicy()).
+		WithPerRetryPolicy(azsdk.NewApiRetryMetricsPolicy()).
+		WithPerRetryPolicy(azsdk.NewArmRateLimitPolicy(azsdk.ArmRateLimiter()))
 }
@@ -90,7 +90,7 @@ func Test_DeployFunctionAppUsingZipFile(t *testing.T) {
 		registerDeployMocks(mockContext, &ran)
 		registerPollingMocks(mockContext, &ran)
 
-		zipFile := bytes.NewBuffer([]byte{})
+		zipFile := bytes.NewReader([]byte{})
 
 		res, err := azCli.DeployFunctionAppUsingZipFile(
 			*mockContext.Context,
@@ -98,6 +98,7 @@ func Test_DeployFunctionAppUsingZipFile(t *testing.T) {
 			"RESOURCE_GROUP_ID",
 			"FUNC_APP_NAME",
 			zipFile,
+			nil,
 		)
 
 		require.NoError(t, err)
@@ -112,7 +113,7 @@ func Test_DeployFunctionAppUsingZipFile(t *testing.T) {
 
 		registerConflictMocks(mockContext, &ran)
 
-		zipFile := bytes.NewBuffer([]byte{})
+		zipFile := bytes.NewReader([]byte{})
 
 		res, err := azCli.DeployFunctionAppUsingZipFile(
 			*mockContext.Context,
@@ -120,6 +121,7 @@ func Test_DeployFunctionAppUsingZipFile(t *testing.T) {
 			"RESOURCE_GROUP_ID",
 			"FUNC_APP_NAME",
 			zipFile,
+			nil,
 		)
 
 		require.Nil(t, res)
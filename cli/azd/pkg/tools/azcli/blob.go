@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	blobApiVersion = "2021-12-02"
+	storageScope   = "https://storage.azure.com/.default"
+)
+
+// UploadBlob uploads the content to the block blob of the url, replacing the blob when it exists. The metadata is set
+// on the blob, ex) to find the blobs of an environment.
+func (cli *azCli) UploadBlob(
+	ctx context.Context,
+	subscriptionId string,
+	blobUrl string,
+	content io.ReadSeekCloser,
+	contentType string,
+	metadata map[string]string,
+) error {
+	pipeline, err := cli.blobPipeline(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodPut, blobUrl)
+	if err != nil {
+		return err
+	}
+
+	req.Raw().Header.Set("x-ms-version", blobApiVersion)
+	req.Raw().Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Raw().Header.Set("x-ms-blob-content-type", contentType)
+	for name, value := range metadata {
+		req.Raw().Header.Set("x-ms-meta-"+name, value)
+	}
+
+	if err := req.SetBody(content, contentType); err != nil {
+		return err
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("uploading blob %s: %w", blobUrl, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusCreated) {
+		return fmt.Errorf("uploading blob %s: %w", blobUrl, runtime.NewResponseError(response))
+	}
+
+	return nil
+}
+
+// DownloadBlob writes the content of the blob of the url to the writer
+func (cli *azCli) DownloadBlob(ctx context.Context, subscriptionId string, blobUrl string, writer io.Writer) error {
+	pipeline, err := cli.blobPipeline(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodGet, blobUrl)
+	if err != nil {
+		return err
+	}
+
+	req.Raw().Header.Set("x-ms-version", blobApiVersion)
+	runtime.SkipBodyDownload(req)
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading blob %s: %w", blobUrl, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return fmt.Errorf("downloading blob %s: %w", blobUrl, runtime.NewResponseError(response))
+	}
+
+	if _, err := io.Copy(writer, response.Body); err != nil {
+		return fmt.Errorf("downloading blob %s: %w", blobUrl, err)
+	}
+
+	return nil
+}
+
+// blobPipeline creates the pipeline of the requests to the blob service, authenticated with the credential of the
+// subscription of the storage account
+func (cli *azCli) blobPipeline(ctx context.Context, subscriptionId string) (runtime.Pipeline, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return runtime.Pipeline{}, err
+	}
+
+	return runtime.NewPipeline("azcli", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{storageScope}, nil)},
+	}, cli.clientOptionsBuilder(ctx).BuildCoreClientOptions()), nil
+}
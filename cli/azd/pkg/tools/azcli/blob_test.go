@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
+
+func Test_UploadBlob(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			request.URL.Host == "ACCOUNT.blob.core.windows.net" &&
+			request.URL.Path == "/artifacts/app/api/artifact.json"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "BlockBlob", request.Header.Get("x-ms-blob-type"))
+		require.Equal(t, "application/json", request.Header.Get("x-ms-blob-content-type"))
+		require.Equal(t, "dev", request.Header.Get("x-ms-meta-environment"))
+
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.Equal(t, "{}", string(body))
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	err := azCli.UploadBlob(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"https://ACCOUNT.blob.core.windows.net/artifacts/app/api/artifact.json",
+		readSeekNopCloser{bytes.NewReader([]byte("{}"))},
+		"application/json",
+		map[string]string{"environment": "dev"},
+	)
+	require.NoError(t, err)
+}
+
+func Test_DownloadBlob(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && request.URL.Path == "/artifacts/app/api/artifact.json"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"service": "api"})
+		})
+
+		buf := &bytes.Buffer{}
+		err := azCli.DownloadBlob(
+			*mockContext.Context,
+			"SUBSCRIPTION_ID",
+			"https://ACCOUNT.blob.core.windows.net/artifacts/app/api/artifact.json",
+			buf,
+		)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"service":"api"}`, buf.String())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		})
+
+		err := azCli.DownloadBlob(
+			*mockContext.Context,
+			"SUBSCRIPTION_ID",
+			"https://ACCOUNT.blob.core.windows.net/artifacts/app/api/artifact.json",
+			&bytes.Buffer{},
+		)
+		require.ErrorContains(t, err, "downloading blob")
+	})
+}
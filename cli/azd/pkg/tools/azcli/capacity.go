@@ -0,0 +1,352 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	computeSkusApiVersion          = "2021-07-01"
+	computeUsagesApiVersion        = "2023-03-01"
+	postgresCapabilitiesApiVersion = "2022-12-01"
+)
+
+// AzCliComputeSku is a compute SKU, ex) a virtual machine size, offered in a location
+type AzCliComputeSku struct {
+	Name         string
+	ResourceType string
+	Family       string
+	Location     string
+	VCpus        int
+	// Whether the SKU is not available to the subscription in the location
+	Restricted bool
+}
+
+// AzCliUsage is the current usage and limit of a quota in a location
+type AzCliUsage struct {
+	Name         string
+	CurrentValue int64
+	Limit        int64
+}
+
+// Available returns the remaining capacity of the quota
+func (u AzCliUsage) Available() int64 {
+	return u.Limit - u.CurrentValue
+}
+
+type armListResponse[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+type armComputeSku struct {
+	Name         string   `json:"name"`
+	ResourceType string   `json:"resourceType"`
+	Family       string   `json:"family"`
+	Locations    []string `json:"locations"`
+	Capabilities []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"capabilities"`
+	Restrictions []struct {
+		Type   string   `json:"type"`
+		Values []string `json:"values"`
+	} `json:"restrictions"`
+}
+
+type armUsage struct {
+	CurrentValue int64 `json:"currentValue"`
+	Limit        int64 `json:"limit"`
+	Name         struct {
+		Value string `json:"value"`
+	} `json:"name"`
+}
+
+type armPostgresCapability struct {
+	SupportedFlexibleServerEditions []struct {
+		SupportedServerVersions []struct {
+			SupportedVcores []struct {
+				Name string `json:"name"`
+			} `json:"supportedVcores"`
+		} `json:"supportedServerVersions"`
+	} `json:"supportedFlexibleServerEditions"`
+}
+
+// ListComputeSkus lists the compute SKUs offered in the location, or in all locations when location is empty
+func (cli *azCli) ListComputeSkus(
+	ctx context.Context,
+	subscriptionId string,
+	location string,
+) ([]AzCliComputeSku, error) {
+	query := url.Values{}
+	query.Set("api-version", computeSkusApiVersion)
+	if location != "" {
+		query.Set("$filter", fmt.Sprintf("location eq '%s'", location))
+	}
+
+	armSkus, err := armList[armComputeSku](
+		ctx, cli, subscriptionId, fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/skus", subscriptionId), query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing compute skus: %w", err)
+	}
+
+	skus := []AzCliComputeSku{}
+	for _, armSku := range armSkus {
+		vCpus := 0
+		for _, capability := range armSku.Capabilities {
+			if capability.Name == "vCPUs" {
+				vCpus, _ = strconv.Atoi(capability.Value)
+			}
+		}
+
+		for _, skuLocation := range armSku.Locations {
+			restricted := false
+			for _, restriction := range armSku.Restrictions {
+				if restriction.Type != "Location" {
+					continue
+				}
+
+				for _, value := range restriction.Values {
+					if strings.EqualFold(value, skuLocation) {
+						restricted = true
+					}
+				}
+			}
+
+			skus = append(skus, AzCliComputeSku{
+				Name:         armSku.Name,
+				ResourceType: armSku.ResourceType,
+				Family:       armSku.Family,
+				Location:     skuLocation,
+				VCpus:        vCpus,
+				Restricted:   restricted,
+			})
+		}
+	}
+
+	return skus, nil
+}
+
+// ListComputeUsages lists the compute quotas of the subscription in the location, ex) the vCPUs of a VM family
+func (cli *azCli) ListComputeUsages(ctx context.Context, subscriptionId string, location string) ([]AzCliUsage, error) {
+	query := url.Values{}
+	query.Set("api-version", computeUsagesApiVersion)
+
+	armUsages, err := armList[armUsage](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/locations/%s/usages", subscriptionId, location),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing compute usages: %w", err)
+	}
+
+	usages := make([]AzCliUsage, 0, len(armUsages))
+	for _, usage := range armUsages {
+		usages = append(usages, AzCliUsage{
+			Name:         usage.Name.Value,
+			CurrentValue: usage.CurrentValue,
+			Limit:        usage.Limit,
+		})
+	}
+
+	return usages, nil
+}
+
+// ListPostgresFlexibleServerSkus lists the names of the PostgreSQL flexible server SKUs offered in the location
+func (cli *azCli) ListPostgresFlexibleServerSkus(
+	ctx context.Context,
+	subscriptionId string,
+	location string,
+) ([]string, error) {
+	query := url.Values{}
+	query.Set("api-version", postgresCapabilitiesApiVersion)
+
+	capabilities, err := armList[armPostgresCapability](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf(
+			"/subscriptions/%s/providers/Microsoft.DBforPostgreSQL/locations/%s/capabilities", subscriptionId, location,
+		),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing postgres capabilities: %w", err)
+	}
+
+	skus := map[string]struct{}{}
+	for _, capability := range capabilities {
+		for _, edition := range capability.SupportedFlexibleServerEditions {
+			for _, version := range edition.SupportedServerVersions {
+				for _, vCore := range version.SupportedVcores {
+					skus[vCore.Name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(skus))
+	for sku := range skus {
+		result = append(result, sku)
+	}
+
+	return result, nil
+}
+
+// GetResourceTypeLocations returns the display names of the locations a resource type, ex)
+// Microsoft.App/managedEnvironments, is available in
+func (cli *azCli) GetResourceTypeLocations(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	providerResourceType, err := cli.getProviderResourceType(ctx, subscriptionId, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]string, 0, len(providerResourceType.Locations))
+	for _, location := range providerResourceType.Locations {
+		if location != nil {
+			locations = append(locations, *location)
+		}
+	}
+
+	return locations, nil
+}
+
+// GetResourceTypeApiVersions returns the api-versions of a resource type, ex) Microsoft.App/managedEnvironments,
+// available in the cloud of the subscription
+func (cli *azCli) GetResourceTypeApiVersions(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	providerResourceType, err := cli.getProviderResourceType(ctx, subscriptionId, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersions := make([]string, 0, len(providerResourceType.APIVersions))
+	for _, apiVersion := range providerResourceType.APIVersions {
+		if apiVersion != nil {
+			apiVersions = append(apiVersions, *apiVersion)
+		}
+	}
+
+	return apiVersions, nil
+}
+
+// getProviderResourceType returns the resource type, ex) Microsoft.App/managedEnvironments, of its resource provider
+func (cli *azCli) getProviderResourceType(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) (*armresources.ProviderResourceType, error) {
+	namespace, typeName, has := strings.Cut(resourceType, "/")
+	if !has {
+		return nil, fmt.Errorf("invalid resource type '%s'", resourceType)
+	}
+
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := cli.clientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := armresources.NewProvidersClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating providers client: %w", err)
+	}
+
+	provider, err := client.Get(ctx, namespace, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource provider '%s': %w", namespace, err)
+	}
+
+	for _, providerResourceType := range provider.ResourceTypes {
+		if providerResourceType != nil &&
+			strings.EqualFold(convert.ToValueWithDefault(providerResourceType.ResourceType, ""), typeName) {
+			return providerResourceType, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrResourceTypeNotFound, resourceType)
+}
+
+// armList sends GET requests to an ARM list endpoint without a dedicated SDK client and returns the values of all pages
+func armList[T any](
+	ctx context.Context,
+	cli *azCli,
+	subscriptionId string,
+	path string,
+	query url.Values,
+) ([]T, error) {
+	pipeline, err := cli.armPipeline(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []T{}
+	nextLink := fmt.Sprintf("https://%s%s?%s", azure.ManagementHostName, path, query.Encode())
+	for nextLink != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, nextLink)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := pipeline.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !runtime.HasStatusCode(response, http.StatusOK) {
+			return nil, runtime.NewResponseError(response)
+		}
+
+		page, err := httputil.ReadRawResponse[armListResponse[T]](response)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, page.Value...)
+		nextLink = page.NextLink
+	}
+
+	return values, nil
+}
+
+// armPipeline creates an HTTP pipeline authenticated for ARM requests without a dedicated SDK client
+func (cli *azCli) armPipeline(ctx context.Context, subscriptionId string) (runtime.Pipeline, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return runtime.Pipeline{}, err
+	}
+
+	options := cli.clientOptionsBuilder(ctx).BuildArmClientOptions()
+	pipeline, err := armruntime.NewPipeline("azcli", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return runtime.Pipeline{}, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	return pipeline, nil
+}
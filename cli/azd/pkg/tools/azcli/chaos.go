@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const chaosExperimentsApiVersion = "2024-01-01"
+
+// AzCliChaosExperimentExecution is a run of a Chaos Studio experiment
+type AzCliChaosExperimentExecution struct {
+	Id   string
+	Name string
+	// ex) Running, Success, Failed or Cancelled
+	Status    string
+	StartedAt time.Time
+	// Zero while the execution is running
+	StoppedAt time.Time
+}
+
+// IsTerminal reports whether the execution is over, successfully or not
+func (e *AzCliChaosExperimentExecution) IsTerminal() bool {
+	switch e.Status {
+	case "Success", "Failed", "Cancelled":
+		return true
+	}
+
+	return false
+}
+
+type armChaosExperimentExecution struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Status    string     `json:"status"`
+		StartedAt time.Time  `json:"startedAt"`
+		StoppedAt *time.Time `json:"stoppedAt"`
+	} `json:"properties"`
+}
+
+// StartChaosExperiment starts a new execution of the Chaos Studio experiment named name in the resource group
+func (cli *azCli) StartChaosExperiment(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	name string,
+) error {
+	if err := armSend(
+		ctx,
+		cli,
+		subscriptionId,
+		http.MethodPost,
+		fmt.Sprintf("%s/start", chaosExperimentPath(subscriptionId, resourceGroupName, name)),
+		chaosExperimentsApiVersion,
+		nil,
+	); err != nil {
+		return fmt.Errorf("starting chaos experiment '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// ListChaosExperimentExecutions lists the executions of the Chaos Studio experiment named name in the resource group
+func (cli *azCli) ListChaosExperimentExecutions(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	name string,
+) ([]AzCliChaosExperimentExecution, error) {
+	query := url.Values{}
+	query.Set("api-version", chaosExperimentsApiVersion)
+
+	values, err := armList[armChaosExperimentExecution](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("%s/executions", chaosExperimentPath(subscriptionId, resourceGroupName, name)),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing executions of chaos experiment '%s': %w", name, err)
+	}
+
+	executions := make([]AzCliChaosExperimentExecution, 0, len(values))
+	for _, value := range values {
+		execution := AzCliChaosExperimentExecution{
+			Id:        value.Id,
+			Name:      value.Name,
+			Status:    value.Properties.Status,
+			StartedAt: value.Properties.StartedAt,
+		}
+		if value.Properties.StoppedAt != nil {
+			execution.StoppedAt = *value.Properties.StoppedAt
+		}
+
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
+
+func chaosExperimentPath(subscriptionId string, resourceGroupName string, name string) string {
+	return fmt.Sprintf(
+		"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Chaos/experiments/%s",
+		subscriptionId, resourceGroupName, name,
+	)
+}
@@ -3,10 +3,46 @@ package azcli
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
+const cognitiveServicesApiVersion = "2023-05-01"
+
+// AzCliCognitiveModel is a model which can be deployed to a cognitive services account in a location
+type AzCliCognitiveModel struct {
+	// The kind of account the model can be deployed to, ex) OpenAI
+	Kind    string
+	Format  string
+	Name    string
+	Version string
+	// The names of the deployment SKUs of the model, ex) Standard
+	Skus []string
+}
+
+type armCognitiveModel struct {
+	Kind  string `json:"kind"`
+	Model struct {
+		Format  string `json:"format"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Skus    []struct {
+			Name string `json:"name"`
+		} `json:"skus"`
+	} `json:"model"`
+}
+
+// Cognitive services usages are reported as decimal numbers
+type armCognitiveUsage struct {
+	CurrentValue float64 `json:"currentValue"`
+	Limit        float64 `json:"limit"`
+	Name         struct {
+		Value string `json:"value"`
+	} `json:"name"`
+}
+
 // GetCognitiveAccount finds the cognitive account within a subscription
 func (cli *azCli) GetCognitiveAccount(
 	ctx context.Context,
@@ -26,6 +62,94 @@ func (cli *azCli) GetCognitiveAccount(
 	return response.Account, nil
 }
 
+// GetCognitiveAccountKey returns the primary key of the cognitive account
+func (cli *azCli) GetCognitiveAccountKey(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	accountName string,
+) (string, error) {
+	client, err := cli.createCognitiveAccountClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := client.ListKeys(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		return "", fmt.Errorf("listing keys of cognitive account: %w", err)
+	}
+
+	return convert.ToValueWithDefault(response.Key1, ""), nil
+}
+
+// ListCognitiveModels lists the models which can be deployed to cognitive services accounts in the location
+func (cli *azCli) ListCognitiveModels(
+	ctx context.Context,
+	subscriptionId string,
+	location string,
+) ([]AzCliCognitiveModel, error) {
+	query := url.Values{}
+	query.Set("api-version", cognitiveServicesApiVersion)
+
+	armModels, err := armList[armCognitiveModel](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("/subscriptions/%s/providers/Microsoft.CognitiveServices/locations/%s/models", subscriptionId, location),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing cognitive models: %w", err)
+	}
+
+	models := make([]AzCliCognitiveModel, 0, len(armModels))
+	for _, armModel := range armModels {
+		skus := make([]string, 0, len(armModel.Model.Skus))
+		for _, sku := range armModel.Model.Skus {
+			skus = append(skus, sku.Name)
+		}
+
+		models = append(models, AzCliCognitiveModel{
+			Kind:    armModel.Kind,
+			Format:  armModel.Model.Format,
+			Name:    armModel.Model.Name,
+			Version: armModel.Model.Version,
+			Skus:    skus,
+		})
+	}
+
+	return models, nil
+}
+
+// ListCognitiveUsages lists the cognitive services quotas of the subscription in the location, ex) the capacity of the
+// Standard deployments of a model
+func (cli *azCli) ListCognitiveUsages(ctx context.Context, subscriptionId string, location string) ([]AzCliUsage, error) {
+	query := url.Values{}
+	query.Set("api-version", cognitiveServicesApiVersion)
+
+	armUsages, err := armList[armCognitiveUsage](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("/subscriptions/%s/providers/Microsoft.CognitiveServices/locations/%s/usages", subscriptionId, location),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing cognitive usages: %w", err)
+	}
+
+	usages := make([]AzCliUsage, 0, len(armUsages))
+	for _, usage := range armUsages {
+		usages = append(usages, AzCliUsage{
+			Name:         usage.Name.Value,
+			CurrentValue: int64(usage.CurrentValue),
+			Limit:        int64(usage.Limit),
+		})
+	}
+
+	return usages, nil
+}
+
 // PurgeCognitiveAccount starts purge operation and wait until it is completed.
 func (cli *azCli) PurgeCognitiveAccount(
 	ctx context.Context, subscriptionId, location, resourceGroup, accountName string) error {
@@ -62,3 +62,54 @@ func Test_PurgeCognitiveAccount(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func Test_ListCognitiveModelsAndUsages(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/Microsoft.CognitiveServices/locations/eastus/models")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []map[string]any{
+				{
+					"kind": "OpenAI",
+					"model": map[string]any{
+						"format":  "OpenAI",
+						"name":    "gpt-35-turbo",
+						"version": "0613",
+						"skus":    []map[string]any{{"name": "Standard"}},
+					},
+				},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/Microsoft.CognitiveServices/locations/eastus/usages")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []map[string]any{
+				{
+					"name":         map[string]any{"value": "OpenAI.Standard.gpt-35-turbo"},
+					"currentValue": 60.0,
+					"limit":        240.0,
+				},
+			},
+		})
+	})
+
+	models, err := azCli.ListCognitiveModels(*mockContext.Context, "SUBSCRIPTION_ID", "eastus")
+	require.NoError(t, err)
+	require.Equal(t, []AzCliCognitiveModel{
+		{Kind: "OpenAI", Format: "OpenAI", Name: "gpt-35-turbo", Version: "0613", Skus: []string{"Standard"}},
+	}, models)
+
+	usages, err := azCli.ListCognitiveUsages(*mockContext.Context, "SUBSCRIPTION_ID", "eastus")
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	require.Equal(t, "OpenAI.Standard.gpt-35-turbo", usages[0].Name)
+	require.Equal(t, int64(180), usages[0].Available())
+}
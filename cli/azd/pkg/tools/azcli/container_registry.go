@@ -2,12 +2,16 @@ package azcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
@@ -17,26 +21,91 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"golang.org/x/exp/slices"
 )
 
-type dockerCredentials struct {
+// DockerCredentials are the credentials of a container registry, as used by docker
+type DockerCredentials struct {
 	Username    string
 	Password    string
 	LoginServer string
 }
 
+// AdminCredentialsUse is a container registry the credentials of its admin user were used for by the process. The
+// credentials themselves are only kept in memory for the time of the call using them.
+type AdminCredentialsUse struct {
+	SubscriptionId string
+	LoginServer    string
+	// Whether the credentials were given out to pull images, ex) in the image pull secrets of AKS services, which keep
+	// using them after the deploy
+	Pull bool
+	// The name of the password used, password or password2
+	passwordName armcontainerregistry.PasswordName
+}
+
+// ErrContainerRegistryNotFound is returned when the subscription has no container registry with the login server, ex)
+// for registries of another tenant or external registries
+var ErrContainerRegistryNotFound = errors.New("container registry not found")
+
 type acrToken struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type acrAccessToken struct {
+	AccessToken string `json:"access_token"`
+}
+
+// ContainerRegistryTag is a tag of a repository of a container registry
+type ContainerRegistryTag struct {
+	Name           string    `json:"name"`
+	Digest         string    `json:"digest"`
+	CreatedTime    time.Time `json:"createdTime"`
+	LastUpdateTime time.Time `json:"lastUpdateTime"`
+}
+
+type acrTagList struct {
+	Tags []*ContainerRegistryTag `json:"tags"`
+}
+
+// matches the url of the next page in the Link header of ACR list responses, ex) </acr/v1/...&last=tag>; rel="next"
+var acrNextLinkRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
 // ContainerRegistryService provides access to query and login to Azure Container Registries (ACR)
 type ContainerRegistryService interface {
 	// Logs into the specified container registry
 	Login(ctx context.Context, subscriptionId string, loginServer string) error
 	// Gets a list of container registries for the specified subscription
 	GetContainerRegistries(ctx context.Context, subscriptionId string) ([]*armcontainerregistry.Registry, error)
+	// Finds the container registry with the login server in the specified subscription
+	FindContainerRegistry(ctx context.Context, subscriptionId string, loginServer string) (
+		*armcontainerregistry.Registry, error)
+	// Gets the tags of a repository of the container registry, most recently updated first. No tags are returned when
+	// the repository doesn't exist.
+	GetTags(ctx context.Context, subscriptionId string, loginServer string, repository string) (
+		[]*ContainerRegistryTag, error)
+	// Deletes the manifest of an image, and all the tags referencing it, from a repository of the container registry
+	DeleteManifest(ctx context.Context, subscriptionId string, loginServer string, repository string, digest string) error
+	// Gets credentials to pull images from the container registry: the credentials of its admin user when enabled, or
+	// else a refresh token of the signed-in principal, which expires after a few hours
+	PullCredentials(ctx context.Context, subscriptionId string, loginServer string) (*DockerCredentials, error)
+	// Pushes an OCI artifact to a repository of the container registry with the tag, and returns the digest of its
+	// manifest
+	PushArtifact(
+		ctx context.Context,
+		subscriptionId string,
+		loginServer string,
+		repository string,
+		tag string,
+		artifact ContainerRegistryArtifact,
+	) (string, error)
+	// Gets the container registries the credentials of their admin user were used for by the process, instead of a
+	// token of the signed-in principal
+	AdminCredentialsUses() []AdminCredentialsUse
+	// Regenerates the password of the admin user of the container registry used by the process, for the password to no
+	// longer be valid
+	RotateAdminPassword(ctx context.Context, use AdminCredentialsUse) error
 }
 
 type containerRegistryService struct {
@@ -44,6 +113,10 @@ type containerRegistryService struct {
 	docker             docker.Docker
 	httpClient         httputil.HttpClient
 	userAgent          string
+
+	adminUsesMu sync.Mutex
+	// The uses of the credentials of admin users, by login server
+	adminUses map[string]*AdminCredentialsUse
 }
 
 // Creates a new instance of the ContainerRegistryService
@@ -57,6 +130,7 @@ func NewContainerRegistryService(
 		docker:             docker,
 		httpClient:         httpClient,
 		userAgent:          azdinternal.UserAgent(),
+		adminUses:          map[string]*AdminCredentialsUse{},
 	}
 }
 
@@ -85,6 +159,35 @@ func (crs *containerRegistryService) GetContainerRegistries(
 	return results, nil
 }
 
+// Finds the container registry with the login server in the specified subscription
+func (crs *containerRegistryService) FindContainerRegistry(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*armcontainerregistry.Registry, error) {
+	registries, err := crs.GetContainerRegistries(ctx, subscriptionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing container registries: %w", err)
+	}
+
+	matchIndex := slices.IndexFunc(registries, func(registry *armcontainerregistry.Registry) bool {
+		return registry.Properties != nil &&
+			registry.Properties.LoginServer != nil &&
+			strings.EqualFold(*registry.Properties.LoginServer, loginServer)
+	})
+
+	if matchIndex == -1 {
+		return nil, fmt.Errorf(
+			"cannot find registry with login server '%s' and subscriptionId '%s': %w",
+			loginServer,
+			subscriptionId,
+			ErrContainerRegistryNotFound,
+		)
+	}
+
+	return registries[matchIndex], nil
+}
+
 func (crs *containerRegistryService) Login(ctx context.Context, subscriptionId string, loginServer string) error {
 	// First attempt to get ACR credentials from the logged in user
 	dockerCreds, tokenErr := crs.getTokenCredentials(ctx, subscriptionId, loginServer)
@@ -92,11 +195,12 @@ func (crs *containerRegistryService) Login(ctx context.Context, subscriptionId s
 		log.Printf("failed getting ACR token credentials: %s\n", tokenErr.Error())
 
 		// If that fails, attempt to get ACR credentials from the admin user
-		adminCreds, adminErr := crs.getAdminUserCredentials(ctx, subscriptionId, loginServer)
+		adminCreds, adminErr := crs.getAdminUserCredentials(ctx, subscriptionId, loginServer, false)
 		if adminErr != nil {
 			return fmt.Errorf("failed logging into container registry, token: %w, admin: %w", tokenErr, adminErr)
 		}
 
+		log.Printf("logging into container registry '%s' with the credentials of its admin user", loginServer)
 		dockerCreds = adminCreds
 	}
 
@@ -111,29 +215,54 @@ func (crs *containerRegistryService) Login(ctx context.Context, subscriptionId s
 	return nil
 }
 
+// Gets credentials to pull images from the container registry: the credentials of its admin user when enabled, or
+// else a refresh token of the signed-in principal, which expires after a few hours
+func (crs *containerRegistryService) PullCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*DockerCredentials, error) {
+	adminCreds, adminErr := crs.getAdminUserCredentials(ctx, subscriptionId, loginServer, true)
+	if adminErr == nil {
+		return adminCreds, nil
+	}
+
+	log.Printf("failed getting ACR admin user credentials: %s\n", adminErr.Error())
+
+	tokenCreds, tokenErr := crs.getTokenCredentials(ctx, subscriptionId, loginServer)
+	if tokenErr != nil {
+		return nil, fmt.Errorf(
+			"failed getting container registry credentials, admin: %w, token: %w", adminErr, tokenErr)
+	}
+
+	return tokenCreds, nil
+}
+
 func (crs *containerRegistryService) getTokenCredentials(
 	ctx context.Context,
 	subscriptionId string,
 	loginServer string,
-) (*dockerCredentials, error) {
+) (*DockerCredentials, error) {
 	acrToken, err := crs.getAcrToken(ctx, subscriptionId, loginServer)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting ACR token: %w", err)
 	}
 
-	return &dockerCredentials{
+	return &DockerCredentials{
 		Username:    "00000000-0000-0000-0000-000000000000",
 		Password:    acrToken.RefreshToken,
 		LoginServer: loginServer,
 	}, nil
 }
 
-// Logs into the specified container registry
+// Gets the credentials of the admin user of the container registry. The passwords are redacted from the output and the
+// logs, and the use of the credentials is recorded, see AdminCredentialsUses.
 func (crs *containerRegistryService) getAdminUserCredentials(
 	ctx context.Context,
 	subscriptionId string,
 	loginServer string,
-) (*dockerCredentials, error) {
+	pull bool,
+) (*DockerCredentials, error) {
 	client, err := crs.createRegistriesClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
@@ -154,13 +283,101 @@ func (crs *containerRegistryService) getAdminUserCredentials(
 		return nil, fmt.Errorf("getting container registry credentials: %w", err)
 	}
 
-	return &dockerCredentials{
+	if credResponse.Username == nil || len(credResponse.Passwords) == 0 || credResponse.Passwords[0].Value == nil {
+		return nil, fmt.Errorf("container registry '%s' has no admin user credentials", loginServer)
+	}
+
+	// Both passwords are redacted, either may be used
+	for _, password := range credResponse.Passwords {
+		if password.Value != nil {
+			redact.AddSecret(*password.Value)
+		}
+	}
+
+	passwordName := armcontainerregistry.PasswordNamePassword
+	if credResponse.Passwords[0].Name != nil {
+		passwordName = *credResponse.Passwords[0].Name
+	}
+
+	crs.adminUsesMu.Lock()
+	defer crs.adminUsesMu.Unlock()
+
+	use, has := crs.adminUses[loginServer]
+	if !has {
+		use = &AdminCredentialsUse{
+			SubscriptionId: subscriptionId,
+			LoginServer:    loginServer,
+		}
+		crs.adminUses[loginServer] = use
+	}
+	use.Pull = use.Pull || pull
+	use.passwordName = passwordName
+
+	return &DockerCredentials{
 		Username:    *credResponse.Username,
 		Password:    *credResponse.Passwords[0].Value,
 		LoginServer: loginServer,
 	}, nil
 }
 
+// Gets the container registries the credentials of their admin user were used for by the process, ordered by login
+// server
+func (crs *containerRegistryService) AdminCredentialsUses() []AdminCredentialsUse {
+	crs.adminUsesMu.Lock()
+	defer crs.adminUsesMu.Unlock()
+
+	uses := make([]AdminCredentialsUse, 0, len(crs.adminUses))
+	for _, use := range crs.adminUses {
+		uses = append(uses, *use)
+	}
+
+	slices.SortFunc(uses, func(a, b AdminCredentialsUse) bool {
+		return a.LoginServer < b.LoginServer
+	})
+
+	return uses
+}
+
+// Regenerates the password of the admin user of the container registry used by the process. The new password isn't
+// read, it's retrieved again by the next use of the admin credentials.
+func (crs *containerRegistryService) RotateAdminPassword(ctx context.Context, use AdminCredentialsUse) error {
+	client, err := crs.createRegistriesClient(ctx, use.SubscriptionId)
+	if err != nil {
+		return err
+	}
+
+	registryName, _, _ := strings.Cut(use.LoginServer, ".")
+	_, resourceGroup, err := crs.findContainerRegistryByName(ctx, use.SubscriptionId, registryName)
+	if err != nil {
+		return err
+	}
+
+	passwordName := use.passwordName
+	if passwordName == "" {
+		passwordName = armcontainerregistry.PasswordNamePassword
+	}
+
+	response, err := client.RegenerateCredential(
+		ctx,
+		resourceGroup,
+		registryName,
+		armcontainerregistry.RegenerateCredentialParameters{Name: &passwordName},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("regenerating password '%s' of container registry '%s': %w", passwordName, use.LoginServer, err)
+	}
+
+	// The response has the new passwords
+	for _, password := range response.Passwords {
+		if password.Value != nil {
+			redact.AddSecret(*password.Value)
+		}
+	}
+
+	return nil
+}
+
 func (crs *containerRegistryService) findContainerRegistryByName(
 	ctx context.Context,
 	subscriptionId string,
@@ -260,6 +477,142 @@ func (crs *containerRegistryService) getAcrToken(
 	return acrTokenBody, nil
 }
 
+// Gets the tags of a repository of the container registry, most recently updated first
+func (crs *containerRegistryService) GetTags(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+) ([]*ContainerRegistryTag, error) {
+	accessToken, err := crs.getAcrAccessToken(
+		ctx, subscriptionId, loginServer, fmt.Sprintf("repository:%s:metadata_read", repository))
+	if err != nil {
+		return nil, fmt.Errorf("failed getting ACR access token: %w", err)
+	}
+
+	tags := []*ContainerRegistryTag{}
+	nextUrl := fmt.Sprintf("https://%s/acr/v1/%s/_tags?orderby=timedesc&n=100", loginServer, repository)
+
+	for nextUrl != "" {
+		req, err := azruntime.NewRequest(ctx, http.MethodGet, nextUrl)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Raw().Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+		response, err := crs.acrPipeline(ctx).Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		// The repository was never pushed to
+		if azruntime.HasStatusCode(response, http.StatusNotFound) {
+			response.Body.Close()
+			return tags, nil
+		}
+
+		if !azruntime.HasStatusCode(response, http.StatusOK) {
+			return nil, azruntime.NewResponseError(response)
+		}
+
+		page, err := httputil.ReadRawResponse[acrTagList](response)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+
+		nextUrl = ""
+		if match := acrNextLinkRegex.FindStringSubmatch(response.Header.Get("Link")); match != nil {
+			nextUrl = fmt.Sprintf("https://%s%s", loginServer, match[1])
+		}
+	}
+
+	return tags, nil
+}
+
+// Deletes the manifest of an image from a repository of the container registry
+func (crs *containerRegistryService) DeleteManifest(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	digest string,
+) error {
+	accessToken, err := crs.getAcrAccessToken(
+		ctx, subscriptionId, loginServer, fmt.Sprintf("repository:%s:delete", repository))
+	if err != nil {
+		return fmt.Errorf("failed getting ACR access token: %w", err)
+	}
+
+	manifestUrl := fmt.Sprintf("https://%s/v2/%s/manifests/%s", loginServer, repository, digest)
+	req, err := azruntime.NewRequest(ctx, http.MethodDelete, manifestUrl)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Raw().Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+	response, err := crs.acrPipeline(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if !azruntime.HasStatusCode(response, http.StatusAccepted, http.StatusOK, http.StatusNotFound) {
+		return azruntime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// Exchanges an ACR refresh token for an access token to the data plane of the registry, with the given scope,
+// ex) repository:my-app/web:delete
+func (crs *containerRegistryService) getAcrAccessToken(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	scope string,
+) (string, error) {
+	refreshToken, err := crs.getAcrToken(ctx, subscriptionId, loginServer)
+	if err != nil {
+		return "", fmt.Errorf("failed getting ACR token: %w", err)
+	}
+
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("service", loginServer)
+	formData.Set("scope", scope)
+	formData.Set("refresh_token", refreshToken.RefreshToken)
+
+	tokenUrl := fmt.Sprintf("https://%s/oauth2/token", loginServer)
+	req, err := azruntime.NewRequest(ctx, http.MethodPost, tokenUrl)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+
+	setHttpRequestBody(req, formData)
+
+	response, err := crs.acrPipeline(ctx).Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !azruntime.HasStatusCode(response, http.StatusOK) {
+		return "", azruntime.NewResponseError(response)
+	}
+
+	accessToken, err := httputil.ReadRawResponse[acrAccessToken](response)
+	if err != nil {
+		return "", err
+	}
+
+	return accessToken.AccessToken, nil
+}
+
+func (crs *containerRegistryService) acrPipeline(ctx context.Context) azruntime.Pipeline {
+	options := clientOptionsBuilder(ctx, crs.httpClient, crs.userAgent).BuildCoreClientOptions()
+	return azruntime.NewPipeline("azd-acr", internal.Version, azruntime.PipelineOptions{}, options)
+}
+
 func setHttpRequestBody(req *policy.Request, formData url.Values) {
 	raw := req.Raw()
 	raw.Header.Set("Content-Type", "application/x-www-form-urlencoded")
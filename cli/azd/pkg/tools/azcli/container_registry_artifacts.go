@@ -0,0 +1,195 @@
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/azure/azure-dev/cli/azd/internal/metrics"
+)
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ociEmptyConfigMediaType = "application/vnd.oci.empty.v1+json"
+)
+
+// The empty config of OCI artifacts which aren't images
+var ociEmptyConfig = []byte("{}")
+
+// ContainerRegistryArtifact is an OCI artifact with a single layer, ex) an azd template packaged as a tarball
+type ContainerRegistryArtifact struct {
+	// The type of the artifact, ex) application/vnd.azure.azd.template.v1
+	ArtifactType string
+	// The media type of the content, ex) application/vnd.oci.image.layer.v1.tar+gzip
+	MediaType string
+	// The content of the artifact
+	Content []byte
+	// The annotations of the manifest of the artifact, ex) org.opencontainers.image.title
+	Annotations map[string]string
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int               `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Pushes an OCI artifact to a repository of the container registry with the tag, and returns the digest of its manifest
+func (crs *containerRegistryService) PushArtifact(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	tag string,
+	artifact ContainerRegistryArtifact,
+) (string, error) {
+	accessToken, err := crs.getAcrAccessToken(
+		ctx, subscriptionId, loginServer, fmt.Sprintf("repository:%s:pull,push", repository))
+	if err != nil {
+		return "", fmt.Errorf("failed getting ACR access token: %w", err)
+	}
+
+	config, err := crs.pushBlob(ctx, accessToken, loginServer, repository, ociEmptyConfigMediaType, ociEmptyConfig)
+	if err != nil {
+		return "", fmt.Errorf("pushing artifact config: %w", err)
+	}
+
+	layer, err := crs.pushBlob(ctx, accessToken, loginServer, repository, artifact.MediaType, artifact.Content)
+	if err != nil {
+		return "", fmt.Errorf("pushing artifact content: %w", err)
+	}
+
+	manifest, err := json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		ArtifactType:  artifact.ArtifactType,
+		Config:        *config,
+		Layers:        []ociDescriptor{*layer},
+		Annotations:   artifact.Annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	manifestUrl := fmt.Sprintf("https://%s/v2/%s/manifests/%s", loginServer, repository, tag)
+	response, err := crs.sendAcrRequest(
+		ctx, accessToken, http.MethodPut, manifestUrl, ociManifestMediaType, manifest)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if !azruntime.HasStatusCode(response, http.StatusCreated) {
+		return "", fmt.Errorf("pushing artifact manifest: %w", azruntime.NewResponseError(response))
+	}
+
+	return ociDigest(manifest), nil
+}
+
+// Uploads the blob to the repository in a single request, unless the repository already has it
+func (crs *containerRegistryService) pushBlob(
+	ctx context.Context,
+	accessToken string,
+	loginServer string,
+	repository string,
+	mediaType string,
+	content []byte,
+) (*ociDescriptor, error) {
+	descriptor := &ociDescriptor{
+		MediaType: mediaType,
+		Digest:    ociDigest(content),
+		Size:      len(content),
+	}
+
+	blobUrl := fmt.Sprintf("https://%s/v2/%s/blobs/%s", loginServer, repository, descriptor.Digest)
+	response, err := crs.sendAcrRequest(ctx, accessToken, http.MethodHead, blobUrl, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	if azruntime.HasStatusCode(response, http.StatusOK) {
+		return descriptor, nil
+	}
+
+	uploadsUrl := fmt.Sprintf("https://%s/v2/%s/blobs/uploads/", loginServer, repository)
+	response, err = crs.sendAcrRequest(ctx, accessToken, http.MethodPost, uploadsUrl, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if !azruntime.HasStatusCode(response, http.StatusAccepted) {
+		return nil, azruntime.NewResponseError(response)
+	}
+
+	// The location of the upload is usually relative to the registry
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing upload location: %w", err)
+	}
+
+	uploadUrl := (&url.URL{Scheme: "https", Host: loginServer}).ResolveReference(location)
+	query := uploadUrl.Query()
+	query.Set("digest", descriptor.Digest)
+	uploadUrl.RawQuery = query.Encode()
+
+	uploadResponse, err := crs.sendAcrRequest(
+		ctx, accessToken, http.MethodPut, uploadUrl.String(), "application/octet-stream", content)
+	if err != nil {
+		return nil, err
+	}
+	defer uploadResponse.Body.Close()
+
+	if !azruntime.HasStatusCode(uploadResponse, http.StatusCreated) {
+		return nil, azruntime.NewResponseError(uploadResponse)
+	}
+
+	metrics.Add(metrics.BytesPushed, int64(len(content)), map[string]string{"upload": "artifact"})
+
+	return descriptor, nil
+}
+
+func (crs *containerRegistryService) sendAcrRequest(
+	ctx context.Context,
+	accessToken string,
+	method string,
+	requestUrl string,
+	contentType string,
+	body []byte,
+) (*http.Response, error) {
+	req, err := azruntime.NewRequest(ctx, method, requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Raw().Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	if body != nil {
+		// The body is rewound when the request is retried
+		if err := req.SetBody(streaming.NopCloser(bytes.NewReader(body)), contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	return crs.acrPipeline(ctx).Do(req)
+}
+
+func ociDigest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
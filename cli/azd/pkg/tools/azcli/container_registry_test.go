@@ -0,0 +1,257 @@
+package azcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func newContainerRegistryServiceFromMockContext(mockContext *mocks.MockContext) ContainerRegistryService {
+	return NewContainerRegistryService(
+		mockaccount.SubscriptionCredentialProviderFunc(func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		}),
+		mockContext.HttpClient,
+		nil,
+	)
+}
+
+func mockAcrAccessToken(mockContext *mocks.MockContext) *[]string {
+	scopes := []string{}
+	mockazsdk.MockContainerRegistryTokenExchange(mockContext, "SUBSCRIPTION_ID", "contoso.azurecr.io", "REFRESH_TOKEN")
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/oauth2/token"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if err := request.ParseForm(); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, request.PostForm.Get("scope"))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]string{
+			"access_token": fmt.Sprintf("ACCESS_TOKEN_%d", len(scopes)),
+		})
+	})
+
+	return &scopes
+}
+
+func Test_ContainerRegistryService_GetTags(t *testing.T) {
+	t.Run("Pages", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		scopes := mockAcrAccessToken(mockContext)
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && request.URL.Path == "/acr/v1/my-app/web-dev/_tags"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Equal(t, "Bearer ACCESS_TOKEN_1", request.Header.Get("Authorization"))
+
+			if request.URL.Query().Get("last") == "" {
+				response, err := mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+					"tags": []map[string]any{{"name": "azd-deploy-2", "digest": "sha256:2"}},
+				})
+				response.Header.Set("Link", `</acr/v1/my-app/web-dev/_tags?last=azd-deploy-2&n=100>; rel="next"`)
+				return response, err
+			}
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+				"tags": []map[string]any{{"name": "azd-deploy-1", "digest": "sha256:1"}},
+			})
+		})
+
+		tags, err := newContainerRegistryServiceFromMockContext(mockContext).
+			GetTags(*mockContext.Context, "SUBSCRIPTION_ID", "contoso.azurecr.io", "my-app/web-dev")
+		require.NoError(t, err)
+		require.Len(t, tags, 2)
+		require.Equal(t, "azd-deploy-2", tags[0].Name)
+		require.Equal(t, "sha256:1", tags[1].Digest)
+		require.Equal(t, []string{"repository:my-app/web-dev:metadata_read"}, *scopes)
+	})
+
+	t.Run("RepositoryNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockAcrAccessToken(mockContext)
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasPrefix(request.URL.Path, "/acr/v1/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		})
+
+		tags, err := newContainerRegistryServiceFromMockContext(mockContext).
+			GetTags(*mockContext.Context, "SUBSCRIPTION_ID", "contoso.azurecr.io", "my-app/web-dev")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+	})
+}
+
+func Test_ContainerRegistryService_DeleteManifest(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	scopes := mockAcrAccessToken(mockContext)
+	deleted := false
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodDelete &&
+			request.URL.Path == "/v2/my-app/web-dev/manifests/sha256:1"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		deleted = true
+		return mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+	})
+
+	err := newContainerRegistryServiceFromMockContext(mockContext).
+		DeleteManifest(*mockContext.Context, "SUBSCRIPTION_ID", "contoso.azurecr.io", "my-app/web-dev", "sha256:1")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.Equal(t, []string{"repository:my-app/web-dev:delete"}, *scopes)
+}
+
+func Test_ContainerRegistryService_PushArtifact(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	scopes := mockAcrAccessToken(mockContext)
+	uploaded := map[string]string{}
+	var manifest ociManifest
+
+	// The registry already has the empty config
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodHead && strings.HasPrefix(request.URL.Path, "/v2/templates/todo/blobs/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(request.URL.Path, ociDigest(ociEmptyConfig)) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		}
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/v2/templates/todo/blobs/uploads/"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response, err := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		response.Header.Set("Location", "/v2/templates/todo/blobs/uploads/UPLOAD_ID?_state=STATE")
+		return response, err
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == "/v2/templates/todo/blobs/uploads/UPLOAD_ID"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "STATE", request.URL.Query().Get("_state"))
+
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		uploaded[request.URL.Query().Get("digest")] = string(body)
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == "/v2/templates/todo/manifests/1.0.0"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, ociManifestMediaType, request.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&manifest))
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	digest, err := newContainerRegistryServiceFromMockContext(mockContext).PushArtifact(
+		*mockContext.Context, "SUBSCRIPTION_ID", "contoso.azurecr.io", "templates/todo", "1.0.0",
+		ContainerRegistryArtifact{
+			ArtifactType: "application/vnd.azure.azd.template.v1",
+			MediaType:    "application/vnd.oci.image.layer.v1.tar+gzip",
+			Content:      []byte("TEMPLATE"),
+			Annotations:  map[string]string{"org.opencontainers.image.title": "Todo"},
+		})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(digest, "sha256:"))
+	require.Equal(t, []string{"repository:templates/todo:pull,push"}, *scopes)
+
+	// Only the content is uploaded
+	require.Equal(t, map[string]string{ociDigest([]byte("TEMPLATE")): "TEMPLATE"}, uploaded)
+	require.Equal(t, "application/vnd.azure.azd.template.v1", manifest.ArtifactType)
+	require.Equal(t, ociEmptyConfigMediaType, manifest.Config.MediaType)
+	require.Equal(t, ociDescriptor{
+		MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+		Digest:    ociDigest([]byte("TEMPLATE")),
+		Size:      8,
+	}, manifest.Layers[0])
+	require.Equal(t, "Todo", manifest.Annotations["org.opencontainers.image.title"])
+}
+
+func Test_ContainerRegistryService_AdminCredentials(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockazsdk.MockContainerRegistryList(mockContext, []*armcontainerregistry.Registry{
+		{
+			ID: convert.RefOf(
+				"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/" +
+					"Microsoft.ContainerRegistry/registries/contoso"),
+			Name: convert.RefOf("contoso"),
+			Properties: &armcontainerregistry.RegistryProperties{
+				LoginServer: convert.RefOf("contoso.azurecr.io"),
+			},
+		},
+	})
+	mockazsdk.MockContainerRegistryCredentials(mockContext, &armcontainerregistry.RegistryListCredentialsResult{
+		Username: convert.RefOf("contoso"),
+		Passwords: []*armcontainerregistry.RegistryPassword{
+			{
+				Name:  convert.RefOf(armcontainerregistry.PasswordNamePassword2),
+				Value: convert.RefOf("ADMIN_PASSWORD_2"),
+			},
+			{
+				Name:  convert.RefOf(armcontainerregistry.PasswordNamePassword),
+				Value: convert.RefOf("ADMIN_PASSWORD_1"),
+			},
+		},
+	})
+
+	regenerated := ""
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/regenerateCredential")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Contains(t, request.URL.Path, "/resourceGroups/RESOURCE_GROUP/")
+
+		var parameters armcontainerregistry.RegenerateCredentialParameters
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&parameters))
+		regenerated = string(*parameters.Name)
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerregistry.RegistryListCredentialsResult{
+			Username: convert.RefOf("contoso"),
+			Passwords: []*armcontainerregistry.RegistryPassword{
+				{
+					Name:  convert.RefOf(armcontainerregistry.PasswordNamePassword2),
+					Value: convert.RefOf("NEW_ADMIN_PASSWORD_2"),
+				},
+			},
+		})
+	})
+
+	service := newContainerRegistryServiceFromMockContext(mockContext)
+	require.Empty(t, service.AdminCredentialsUses())
+
+	credentials, err := service.PullCredentials(*mockContext.Context, "SUBSCRIPTION_ID", "contoso.azurecr.io")
+	require.NoError(t, err)
+	require.Equal(t, "contoso", credentials.Username)
+	require.Equal(t, "ADMIN_PASSWORD_2", credentials.Password)
+
+	// Both passwords are redacted
+	require.True(t, redact.IsSecret("ADMIN_PASSWORD_1"))
+	require.True(t, redact.IsSecret("ADMIN_PASSWORD_2"))
+
+	uses := service.AdminCredentialsUses()
+	require.Len(t, uses, 1)
+	require.Equal(t, "SUBSCRIPTION_ID", uses[0].SubscriptionId)
+	require.Equal(t, "contoso.azurecr.io", uses[0].LoginServer)
+	require.True(t, uses[0].Pull)
+
+	// The password used is regenerated
+	require.NoError(t, service.RotateAdminPassword(*mockContext.Context, uses[0]))
+	require.Equal(t, "password2", regenerated)
+	require.True(t, redact.IsSecret("NEW_ADMIN_PASSWORD_2"))
+}
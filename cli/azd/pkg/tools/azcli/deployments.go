@@ -8,8 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
@@ -127,6 +129,30 @@ func (cli *azCli) createDeploymentsClient(
 	return client, nil
 }
 
+// DeployOptions are the optional settings used when starting an ARM deployment
+type DeployOptions struct {
+	// The resume token of an in-flight deployment started by a previous azd process.
+	// When set, the existing deployment is polled until completion instead of starting a new deployment.
+	ResumeToken string
+	// Invoked with the resume token of the deployment after it has been started or resumed
+	OnStarted func(resumeToken string)
+}
+
+// notifyDeploymentStarted invokes the OnStarted callback with the resume token of the deployment operation
+func notifyDeploymentStarted[T any](options *DeployOptions, poller *runtime.Poller[T]) {
+	if options == nil || options.OnStarted == nil {
+		return
+	}
+
+	resumeToken, err := poller.ResumeToken()
+	if err != nil {
+		log.Printf("failed getting resume token for deployment: %v", err)
+		return
+	}
+
+	options.OnStarted(resumeToken)
+}
+
 func (cli *azCli) DeployToSubscription(
 	ctx context.Context,
 	subscriptionId string,
@@ -135,12 +161,18 @@ func (cli *azCli) DeployToSubscription(
 	armTemplate azure.RawArmTemplate,
 	parameters azure.ArmParameters,
 	tags map[string]*string,
+	options *DeployOptions,
 ) (*armresources.DeploymentExtended, error) {
 	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, fmt.Errorf("creating deployments client: %w", err)
 	}
 
+	beginOptions := &armresources.DeploymentsClientBeginCreateOrUpdateAtSubscriptionScopeOptions{}
+	if options != nil {
+		beginOptions.ResumeToken = options.ResumeToken
+	}
+
 	createFromTemplateOperation, err := deploymentClient.BeginCreateOrUpdateAtSubscriptionScope(
 		ctx, deploymentName,
 		armresources.Deployment{
@@ -151,11 +183,13 @@ func (cli *azCli) DeployToSubscription(
 			},
 			Location: to.Ptr(location),
 			Tags:     tags,
-		}, nil)
+		}, beginOptions)
 	if err != nil {
 		return nil, fmt.Errorf("starting deployment to subscription: %w", err)
 	}
 
+	notifyDeploymentStarted(options, createFromTemplateOperation)
+
 	// wait for deployment creation
 	deployResult, err := createFromTemplateOperation.PollUntilDone(ctx, nil)
 	if err != nil {
@@ -175,12 +209,18 @@ func (cli *azCli) DeployToResourceGroup(
 	armTemplate azure.RawArmTemplate,
 	parameters azure.ArmParameters,
 	tags map[string]*string,
+	options *DeployOptions,
 ) (*armresources.DeploymentExtended, error) {
 	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, fmt.Errorf("creating deployments client: %w", err)
 	}
 
+	beginOptions := &armresources.DeploymentsClientBeginCreateOrUpdateOptions{}
+	if options != nil {
+		beginOptions.ResumeToken = options.ResumeToken
+	}
+
 	createFromTemplateOperation, err := deploymentClient.BeginCreateOrUpdate(
 		ctx, resourceGroup, deploymentName,
 		armresources.Deployment{
@@ -190,11 +230,13 @@ func (cli *azCli) DeployToResourceGroup(
 				Mode:       to.Ptr(armresources.DeploymentModeIncremental),
 			},
 			Tags: tags,
-		}, nil)
+		}, beginOptions)
 	if err != nil {
 		return nil, fmt.Errorf("starting deployment to resource group: %w", err)
 	}
 
+	notifyDeploymentStarted(options, createFromTemplateOperation)
+
 	// wait for deployment creation
 	deployResult, err := createFromTemplateOperation.PollUntilDone(ctx, nil)
 	if err != nil {
@@ -208,6 +250,78 @@ func (cli *azCli) DeployToResourceGroup(
 	return &deployResult.DeploymentExtended, nil
 }
 
+// WhatIfDeployToSubscription predicts the changes to the resources of the subscription deploying the template would make
+func (cli *azCli) WhatIfDeployToSubscription(
+	ctx context.Context,
+	subscriptionId string,
+	location string,
+	deploymentName string,
+	armTemplate azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+) (*armresources.WhatIfOperationResult, error) {
+	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, fmt.Errorf("creating deployments client: %w", err)
+	}
+
+	whatIfOperation, err := deploymentClient.BeginWhatIfAtSubscriptionScope(
+		ctx, deploymentName,
+		armresources.DeploymentWhatIf{
+			Properties: &armresources.DeploymentWhatIfProperties{
+				Template:   armTemplate,
+				Parameters: parameters,
+				Mode:       to.Ptr(armresources.DeploymentModeIncremental),
+			},
+			Location: to.Ptr(location),
+		}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting what-if deployment to subscription: %w", err)
+	}
+
+	whatIfResult, err := whatIfOperation.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("what-if deployment to subscription:\n\nDeployment Error Details:\n%w",
+			createDeploymentError(err))
+	}
+
+	return &whatIfResult.WhatIfOperationResult, nil
+}
+
+// WhatIfDeployToResourceGroup predicts the changes to the resources of the resource group deploying the template would
+// make
+func (cli *azCli) WhatIfDeployToResourceGroup(
+	ctx context.Context,
+	subscriptionId, resourceGroup, deploymentName string,
+	armTemplate azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+) (*armresources.WhatIfOperationResult, error) {
+	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, fmt.Errorf("creating deployments client: %w", err)
+	}
+
+	whatIfOperation, err := deploymentClient.BeginWhatIf(
+		ctx, resourceGroup, deploymentName,
+		armresources.DeploymentWhatIf{
+			Properties: &armresources.DeploymentWhatIfProperties{
+				Template:   armTemplate,
+				Parameters: parameters,
+				Mode:       to.Ptr(armresources.DeploymentModeIncremental),
+			},
+		}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting what-if deployment to resource group: %w", err)
+	}
+
+	whatIfResult, err := whatIfOperation.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("what-if deployment to resource group:\n\nDeployment Error Details:\n%w",
+			createDeploymentError(err))
+	}
+
+	return &whatIfResult.WhatIfOperationResult, nil
+}
+
 func (cli *azCli) DeleteSubscriptionDeployment(ctx context.Context, subscriptionId string, deploymentName string) error {
 	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
 	if err != nil {
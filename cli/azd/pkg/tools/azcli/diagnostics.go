@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+const diagnosticSettingsApiVersion = "2021-05-01-preview"
+
+// AzCliDiagnosticSettingsCategory is a category of logs, or metrics, a resource can send with diagnostic settings
+type AzCliDiagnosticSettingsCategory struct {
+	Name string
+	// Logs or Metrics
+	CategoryType string
+	// The groups the category belongs to, ex) allLogs and audit
+	CategoryGroups []string
+}
+
+// AzCliDiagnosticSetting is a diagnostic setting sending the logs and metrics of a resource to a Log Analytics workspace
+type AzCliDiagnosticSetting struct {
+	WorkspaceId string
+	// The category groups of the logs sent to the workspace, ex) allLogs
+	CategoryGroups []string
+	// Whether all the metrics of the resource are sent to the workspace
+	Metrics bool
+}
+
+type armDiagnosticSettingsCategory struct {
+	Name       string `json:"name"`
+	Properties struct {
+		CategoryType   string   `json:"categoryType"`
+		CategoryGroups []string `json:"categoryGroups"`
+	} `json:"properties"`
+}
+
+// ListDiagnosticSettingsCategories lists the categories of logs and metrics of the resource. Resources whose type doesn't
+// support diagnostic settings have no categories.
+func (cli *azCli) ListDiagnosticSettingsCategories(
+	ctx context.Context,
+	subscriptionId string,
+	resourceId string,
+) ([]AzCliDiagnosticSettingsCategory, error) {
+	query := url.Values{}
+	query.Set("api-version", diagnosticSettingsApiVersion)
+
+	armCategories, err := armList[armDiagnosticSettingsCategory](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("%s/providers/Microsoft.Insights/diagnosticSettingsCategories", resourceId),
+		query,
+	)
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) &&
+		(responseErr.StatusCode == http.StatusBadRequest || responseErr.StatusCode == http.StatusNotFound) {
+		return []AzCliDiagnosticSettingsCategory{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("listing diagnostic settings categories: %w", err)
+	}
+
+	categories := make([]AzCliDiagnosticSettingsCategory, 0, len(armCategories))
+	for _, category := range armCategories {
+		categories = append(categories, AzCliDiagnosticSettingsCategory{
+			Name:           category.Name,
+			CategoryType:   category.Properties.CategoryType,
+			CategoryGroups: category.Properties.CategoryGroups,
+		})
+	}
+
+	return categories, nil
+}
+
+// CreateOrUpdateDiagnosticSetting creates, or updates, the diagnostic setting of the resource named name
+func (cli *azCli) CreateOrUpdateDiagnosticSetting(
+	ctx context.Context,
+	subscriptionId string,
+	resourceId string,
+	name string,
+	setting AzCliDiagnosticSetting,
+) error {
+	logs := make([]map[string]any, 0, len(setting.CategoryGroups))
+	for _, categoryGroup := range setting.CategoryGroups {
+		logs = append(logs, map[string]any{
+			"categoryGroup": categoryGroup,
+			"enabled":       true,
+		})
+	}
+
+	metrics := []map[string]any{}
+	if setting.Metrics {
+		metrics = append(metrics, map[string]any{
+			"category": "AllMetrics",
+			"enabled":  true,
+		})
+	}
+
+	body := map[string]any{
+		"properties": map[string]any{
+			"workspaceId": setting.WorkspaceId,
+			"logs":        logs,
+			"metrics":     metrics,
+		},
+	}
+
+	if err := armSend(
+		ctx,
+		cli,
+		subscriptionId,
+		http.MethodPut,
+		fmt.Sprintf("%s/providers/Microsoft.Insights/diagnosticSettings/%s", resourceId, name),
+		diagnosticSettingsApiVersion,
+		body,
+	); err != nil {
+		return fmt.Errorf("creating diagnostic setting '%s': %w", name, err)
+	}
+
+	return nil
+}
@@ -38,14 +38,15 @@ func (cli *azCli) DeployFunctionAppUsingZipFile(
 	subscriptionId string,
 	resourceGroup string,
 	appName string,
-	deployZipFile io.Reader,
+	deployZipFile io.ReadSeeker,
+	progress func(percent int),
 ) (*string, error) {
 	client, err := cli.createZipDeployClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := client.Deploy(ctx, appName, deployZipFile)
+	response, err := client.Deploy(ctx, appName, deployZipFile, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
@@ -18,8 +17,10 @@ type AzCliKeyVault struct {
 	Name       string `json:"name"`
 	Location   string `json:"location"`
 	Properties struct {
-		EnableSoftDelete      bool `json:"enableSoftDelete"`
-		EnablePurgeProtection bool `json:"enablePurgeProtection"`
+		TenantId                string `json:"tenantId"`
+		EnableSoftDelete        bool   `json:"enableSoftDelete"`
+		EnablePurgeProtection   bool   `json:"enablePurgeProtection"`
+		EnableRbacAuthorization bool   `json:"enableRbacAuthorization"`
 	} `json:"properties"`
 }
 
@@ -50,11 +51,15 @@ func (cli *azCli) GetKeyVault(
 		Name:     *vault.Name,
 		Location: *vault.Location,
 		Properties: struct {
-			EnableSoftDelete      bool "json:\"enableSoftDelete\""
-			EnablePurgeProtection bool "json:\"enablePurgeProtection\""
+			TenantId                string "json:\"tenantId\""
+			EnableSoftDelete        bool   "json:\"enableSoftDelete\""
+			EnablePurgeProtection   bool   "json:\"enablePurgeProtection\""
+			EnableRbacAuthorization bool   "json:\"enableRbacAuthorization\""
 		}{
-			EnableSoftDelete:      convert.ToValueWithDefault(vault.Properties.EnableSoftDelete, false),
-			EnablePurgeProtection: convert.ToValueWithDefault(vault.Properties.EnablePurgeProtection, false),
+			TenantId:                convert.ToValueWithDefault(vault.Properties.TenantID, ""),
+			EnableSoftDelete:        convert.ToValueWithDefault(vault.Properties.EnableSoftDelete, false),
+			EnablePurgeProtection:   convert.ToValueWithDefault(vault.Properties.EnablePurgeProtection, false),
+			EnableRbacAuthorization: convert.ToValueWithDefault(vault.Properties.EnableRbacAuthorization, false),
 		},
 	}, nil
 }
@@ -65,12 +70,7 @@ func (cli *azCli) GetKeyVaultSecret(
 	vaultName string,
 	secretName string,
 ) (*AzCliKeyVaultSecret, error) {
-	vaultUrl := vaultName
-	if !strings.Contains(strings.ToLower(vaultName), "https://") {
-		vaultUrl = fmt.Sprintf("https://%s.vault.azure.net", vaultName)
-	}
-
-	client, err := cli.createSecretsDataClient(ctx, subscriptionId, vaultUrl)
+	client, err := cli.createSecretsDataClient(ctx, subscriptionId, keyVaultUrl(vaultName))
 	if err != nil {
 		return nil, nil
 	}
@@ -0,0 +1,292 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
+)
+
+const (
+	keyVaultApiVersion     = "2023-07-01"
+	keyVaultDataApiVersion = "7.4"
+	keyVaultScope          = "https://vault.azure.net/.default"
+)
+
+// DefaultKeyVaultCertificateWaitConfig is how long CreateKeyVaultCertificate waits for the certificate to be issued and how
+// often it checks its pending operation by default
+var DefaultKeyVaultCertificateWaitConfig = wait.Config{
+	Timeout:  10 * time.Minute,
+	Interval: 2 * time.Second,
+}
+
+// AzCliKeyVaultCertificate is the current version of a Key Vault certificate
+type AzCliKeyVaultCertificate struct {
+	// The id of the version of the certificate, ex) https://<vault>.vault.azure.net/certificates/<name>/<version>
+	Id string
+	// The id of the secret holding the certificate and its private key
+	SecretId string
+	// The DNS names of the certificate
+	DnsNames []string
+	// The expiration time of the certificate
+	Expires time.Time
+}
+
+// AzCliKeyVaultCertificatePolicy is the policy of a Key Vault certificate: how it is issued and renewed
+type AzCliKeyVaultCertificatePolicy struct {
+	// The subject of the certificate, ex) CN=api.contoso.com
+	Subject string
+	// The DNS names of the certificate
+	DnsNames []string
+	// The name of the issuer of the certificate registered in the vault, or Self for self-signed certificates
+	Issuer string
+	// The validity of the certificate in months
+	ValidityInMonths int
+	// The number of days before the expiration of the certificate when Key Vault renews it
+	RenewBeforeExpiryDays int
+}
+
+type keyVaultCertificateBundle struct {
+	Id         string `json:"id"`
+	Sid        string `json:"sid"`
+	Attributes struct {
+		Expires int64 `json:"exp"`
+	} `json:"attributes"`
+	Policy struct {
+		X509Props struct {
+			Sans struct {
+				DnsNames []string `json:"dns_names"`
+			} `json:"sans"`
+		} `json:"x509_props"`
+	} `json:"policy"`
+}
+
+type keyVaultCertificateOperation struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// FindKeyVault finds the Key Vault by name among the vaults of the subscription. ErrAzCliKeyVaultNotFound is returned
+// when the subscription has no such vault.
+func (cli *azCli) FindKeyVault(ctx context.Context, subscriptionId string, vaultName string) (*AzCliKeyVault, error) {
+	query := url.Values{}
+	query.Set("api-version", keyVaultApiVersion)
+
+	vaults, err := armList[AzCliKeyVault](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf("/subscriptions/%s/providers/Microsoft.KeyVault/vaults", subscriptionId),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing key vaults: %w", err)
+	}
+
+	for _, vault := range vaults {
+		if strings.EqualFold(vault.Name, vaultName) {
+			return &vault, nil
+		}
+	}
+
+	return nil, fmt.Errorf("'%s': %w", vaultName, ErrAzCliKeyVaultNotFound)
+}
+
+// GetKeyVaultCertificate gets the current version of the certificate. ErrAzCliCertificateNotFound is returned when the
+// vault has no such certificate.
+func (cli *azCli) GetKeyVaultCertificate(
+	ctx context.Context,
+	subscriptionId string,
+	vaultName string,
+	certificateName string,
+) (*AzCliKeyVaultCertificate, error) {
+	pipeline, err := cli.keyVaultPipeline(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := keyVaultSend(
+		ctx, pipeline, http.MethodGet, fmt.Sprintf("%s/certificates/%s", keyVaultUrl(vaultName), certificateName), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrAzCliCertificateNotFound
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, fmt.Errorf("getting key vault certificate: %w", runtime.NewResponseError(response))
+	}
+
+	bundle, err := httputil.ReadRawResponse[keyVaultCertificateBundle](response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzCliKeyVaultCertificate{
+		Id:       bundle.Id,
+		SecretId: bundle.Sid,
+		DnsNames: bundle.Policy.X509Props.Sans.DnsNames,
+		Expires:  time.Unix(bundle.Attributes.Expires, 0).UTC(),
+	}, nil
+}
+
+// CreateKeyVaultCertificate creates the certificate, or a new version of it when it exists, and waits for its issuer to
+// issue it. The private key of the certificate is exportable, and its secret is PEM encoded, as expected by ingress
+// controllers. It checks the pending operation of the certificate on the clock, until the timeout of the wait config.
+func (cli *azCli) CreateKeyVaultCertificate(
+	ctx context.Context,
+	clk clock.Clock,
+	waitConfig wait.Config,
+	subscriptionId string,
+	vaultName string,
+	certificateName string,
+	certificatePolicy AzCliKeyVaultCertificatePolicy,
+) (*AzCliKeyVaultCertificate, error) {
+	pipeline, err := cli.keyVaultPipeline(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	certificateUrl := fmt.Sprintf("%s/certificates/%s", keyVaultUrl(vaultName), certificateName)
+	body := map[string]any{
+		"policy": map[string]any{
+			"key_props": map[string]any{
+				"exportable": true,
+				"kty":        "RSA",
+				"key_size":   2048,
+				"reuse_key":  false,
+			},
+			"secret_props": map[string]any{
+				"contentType": "application/x-pem-file",
+			},
+			"x509_props": map[string]any{
+				"subject":         certificatePolicy.Subject,
+				"sans":            map[string]any{"dns_names": certificatePolicy.DnsNames},
+				"validity_months": certificatePolicy.ValidityInMonths,
+			},
+			"issuer": map[string]any{
+				"name": certificatePolicy.Issuer,
+			},
+			"lifetime_actions": []any{
+				map[string]any{
+					"trigger": map[string]any{"days_before_expiry": certificatePolicy.RenewBeforeExpiryDays},
+					"action":  map[string]any{"action_type": "AutoRenew"},
+				},
+			},
+		},
+	}
+
+	response, err := keyVaultSend(ctx, pipeline, http.MethodPost, certificateUrl+"/create", body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusAccepted) {
+		return nil, fmt.Errorf("creating key vault certificate: %w", runtime.NewResponseError(response))
+	}
+
+	err = wait.Until(ctx, clk, waitConfig, func(ctx context.Context) (bool, error) {
+		response, err := keyVaultSend(ctx, pipeline, http.MethodGet, certificateUrl+"/pending", nil)
+		if err != nil {
+			return false, err
+		}
+
+		if !runtime.HasStatusCode(response, http.StatusOK) {
+			err := runtime.NewResponseError(response)
+			response.Body.Close()
+			return false, fmt.Errorf("getting key vault certificate operation: %w", err)
+		}
+
+		operation, err := httputil.ReadRawResponse[keyVaultCertificateOperation](response)
+		response.Body.Close()
+		if err != nil {
+			return false, err
+		}
+
+		if strings.EqualFold(operation.Status, "completed") {
+			return true, nil
+		}
+
+		if !strings.EqualFold(operation.Status, "inProgress") {
+			message := operation.Status
+			if operation.Error != nil {
+				message = fmt.Sprintf("%s: %s", operation.Error.Code, operation.Error.Message)
+			}
+
+			return false, fmt.Errorf("issuing key vault certificate '%s': %s", certificateName, message)
+		}
+
+		return false, nil
+	})
+	if errors.Is(err, wait.ErrTimeout) {
+		return nil, fmt.Errorf("waiting for key vault certificate '%s' to be issued: %w", certificateName, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return cli.GetKeyVaultCertificate(ctx, subscriptionId, vaultName, certificateName)
+}
+
+// keyVaultPipeline creates an HTTP pipeline authenticated for requests to the data plane of Key Vault
+func (cli *azCli) keyVaultPipeline(ctx context.Context, subscriptionId string) (runtime.Pipeline, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return runtime.Pipeline{}, err
+	}
+
+	return runtime.NewPipeline("azcli", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{keyVaultScope}, nil)},
+	}, cli.clientOptionsBuilder(ctx).BuildCoreClientOptions()), nil
+}
+
+// keyVaultSend sends a request with an optional JSON body to the data plane of Key Vault. The response is returned
+// whatever its status code.
+func keyVaultSend(
+	ctx context.Context,
+	pipeline runtime.Pipeline,
+	method string,
+	requestUrl string,
+	body any,
+) (*http.Response, error) {
+	req, err := runtime.NewRequest(ctx, method, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Raw().URL.RawQuery = "api-version=" + keyVaultDataApiVersion
+	if body != nil {
+		if err := runtime.MarshalAsJSON(req, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return pipeline.Do(req)
+}
+
+// keyVaultUrl returns the url of the vault, which is either its name or already its url
+func keyVaultUrl(vaultName string) string {
+	if strings.Contains(strings.ToLower(vaultName), "https://") {
+		return strings.TrimSuffix(vaultName, "/")
+	}
+
+	return fmt.Sprintf("https://%s.vault.azure.net", vaultName)
+}
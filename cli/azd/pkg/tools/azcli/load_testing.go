@@ -0,0 +1,380 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	// The api version of Azure Load Testing resources
+	loadTestsApiVersion = "2022-12-01"
+	// The api version of the data plane of Azure Load Testing, the first supporting Locust tests
+	loadTestingDataPlaneApiVersion = "2024-12-01-preview"
+	loadTestingScope               = "https://cnt-prod.loadtesting.azure.com/.default"
+	// How often the validation of uploaded test files is polled
+	loadTestFileValidationInterval = 5 * time.Second
+)
+
+// Kinds of Azure Load Testing tests
+const (
+	LoadTestKindJMeter = "JMX"
+	LoadTestKindLocust = "Locust"
+)
+
+// AzCliLoadTest is a test of an Azure Load Testing resource
+type AzCliLoadTest struct {
+	DisplayName string
+	// JMX or Locust
+	Kind            string
+	EngineInstances int
+	// Environment variables of the test script, ex) the endpoints of the services
+	EnvironmentVariables map[string]string
+	// The criteria failing a test run, by id
+	FailureCriteria map[string]AzCliLoadTestCriterion
+}
+
+// AzCliLoadTestCriterion is a criterion failing a test run when a client metric crosses a threshold, ex)
+// avg(response_time_ms) > 500
+type AzCliLoadTestCriterion struct {
+	ClientMetric string  `json:"clientMetric"`
+	Aggregate    string  `json:"aggregate"`
+	Condition    string  `json:"condition"`
+	Value        float64 `json:"value"`
+	// The value of the metric in the test run, set on the criteria of test runs
+	ActualValue *float64 `json:"actualValue,omitempty"`
+	// passed, failed or undetermined, set on the criteria of test runs
+	Result string `json:"result,omitempty"`
+}
+
+// AzCliLoadTestRun is a run of a test of an Azure Load Testing resource
+type AzCliLoadTestRun struct {
+	TestRunId string `json:"testRunId"`
+	// ex) EXECUTING, DONE, FAILED or CANCELLED
+	Status string `json:"status"`
+	// PASSED, FAILED or NOT_APPLICABLE, set once the run is done
+	TestResult string `json:"testResult"`
+	// The statistics of the requests of the run, by request name, Total for all the requests
+	Statistics       map[string]AzCliLoadTestStatistics `json:"testRunStatistics"`
+	PassFailCriteria struct {
+		PassFailMetrics map[string]AzCliLoadTestCriterion `json:"passFailMetrics"`
+	} `json:"passFailCriteria"`
+	PortalUrl    string `json:"portalUrl"`
+	ErrorDetails []struct {
+		Message string `json:"message"`
+	} `json:"errorDetails"`
+}
+
+// AzCliLoadTestStatistics are statistics of the requests of a test run
+type AzCliLoadTestStatistics struct {
+	SampleCount  float64 `json:"sampleCount"`
+	ErrorPct     float64 `json:"errorPct"`
+	MeanResTime  float64 `json:"meanResTime"`
+	Pct90ResTime float64 `json:"pct90ResTime"`
+	Throughput   float64 `json:"throughput"`
+}
+
+// IsTerminal reports whether the run is over, successfully or not
+func (r *AzCliLoadTestRun) IsTerminal() bool {
+	switch r.Status {
+	case "DONE", "FAILED", "CANCELLED", "VALIDATION_FAILURE":
+		return true
+	}
+
+	return false
+}
+
+// LoadTestingService runs the tests of Azure Load Testing resources
+type LoadTestingService interface {
+	// Gets the host name of the data plane of the Azure Load Testing resource
+	GetDataPlaneUri(ctx context.Context, subscriptionId string, loadTestId string) (string, error)
+	// Creates, or updates, the test
+	CreateOrUpdateTest(
+		ctx context.Context,
+		subscriptionId string,
+		dataPlaneUri string,
+		testId string,
+		test AzCliLoadTest,
+	) error
+	// Uploads the script of the test, ex) a JMeter test plan, and waits for its validation
+	UploadTestScript(
+		ctx context.Context,
+		subscriptionId string,
+		dataPlaneUri string,
+		testId string,
+		kind string,
+		fileName string,
+		contents []byte,
+	) error
+	// Starts a run of the test
+	StartTestRun(
+		ctx context.Context,
+		subscriptionId string,
+		dataPlaneUri string,
+		testId string,
+		testRunId string,
+		displayName string,
+	) error
+	// Gets the status, and results, of the test run
+	GetTestRun(
+		ctx context.Context,
+		subscriptionId string,
+		dataPlaneUri string,
+		testRunId string,
+	) (*AzCliLoadTestRun, error)
+}
+
+type loadTestingService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+	// How often the validation of uploaded test files is polled
+	validationInterval time.Duration
+}
+
+// Creates a new instance of the LoadTestingService
+func NewLoadTestingService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) LoadTestingService {
+	return &loadTestingService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.UserAgent(),
+		validationInterval: loadTestFileValidationInterval,
+	}
+}
+
+// Gets the host name of the data plane of the Azure Load Testing resource
+func (s *loadTestingService) GetDataPlaneUri(
+	ctx context.Context,
+	subscriptionId string,
+	loadTestId string,
+) (string, error) {
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	options := clientOptionsBuilder(ctx, s.httpClient, s.userAgent).BuildArmClientOptions()
+	client, err := armresources.NewClient(subscriptionId, credential, options)
+	if err != nil {
+		return "", fmt.Errorf("creating resources client, %w", err)
+	}
+
+	res, err := client.GetByID(ctx, loadTestId, loadTestsApiVersion, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting load test resource: %w", err)
+	}
+
+	properties, _ := res.Properties.(map[string]any)
+	dataPlaneUri, _ := properties["dataPlaneURI"].(string)
+	if dataPlaneUri == "" {
+		return "", fmt.Errorf("resource %s is not an Azure Load Testing resource", loadTestId)
+	}
+
+	return dataPlaneUri, nil
+}
+
+// Creates, or updates, the test
+func (s *loadTestingService) CreateOrUpdateTest(
+	ctx context.Context,
+	subscriptionId string,
+	dataPlaneUri string,
+	testId string,
+	test AzCliLoadTest,
+) error {
+	body := map[string]any{
+		"displayName": test.DisplayName,
+		"kind":        test.Kind,
+		"loadTestConfiguration": map[string]any{
+			"engineInstances": test.EngineInstances,
+		},
+		"environmentVariables": test.EnvironmentVariables,
+		"passFailCriteria": map[string]any{
+			"passFailMetrics": test.FailureCriteria,
+		},
+	}
+
+	contents, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.send(
+		ctx, subscriptionId, http.MethodPatch, dataPlaneUri, fmt.Sprintf("/tests/%s", testId), nil,
+		contents, "application/merge-patch+json",
+	)
+	if err != nil {
+		return fmt.Errorf("creating load test '%s': %w", testId, err)
+	}
+
+	return nil
+}
+
+// Uploads the script of the test, and waits for its validation
+func (s *loadTestingService) UploadTestScript(
+	ctx context.Context,
+	subscriptionId string,
+	dataPlaneUri string,
+	testId string,
+	kind string,
+	fileName string,
+	contents []byte,
+) error {
+	fileType := "JMX_FILE"
+	if kind == LoadTestKindLocust {
+		fileType = "TEST_SCRIPT"
+	}
+
+	query := url.Values{}
+	query.Set("fileType", fileType)
+	path := fmt.Sprintf("/tests/%s/files/%s", testId, fileName)
+
+	if _, err := s.send(
+		ctx, subscriptionId, http.MethodPut, dataPlaneUri, path, query, contents, "application/octet-stream",
+	); err != nil {
+		return fmt.Errorf("uploading test script '%s': %w", fileName, err)
+	}
+
+	for {
+		response, err := s.send(ctx, subscriptionId, http.MethodGet, dataPlaneUri, path, nil, nil, "")
+		if err != nil {
+			return fmt.Errorf("getting test script '%s': %w", fileName, err)
+		}
+
+		var file struct {
+			ValidationStatus  string `json:"validationStatus"`
+			ValidationFailure string `json:"validationFailureDetails"`
+		}
+		if err := json.Unmarshal(response, &file); err != nil {
+			return fmt.Errorf("reading test script '%s': %w", fileName, err)
+		}
+
+		switch file.ValidationStatus {
+		case "VALIDATION_SUCCESS", "NOT_VALIDATED", "VALIDATION_NOT_REQUIRED":
+			return nil
+		case "VALIDATION_FAILURE":
+			return fmt.Errorf("test script '%s' is invalid: %s", fileName, file.ValidationFailure)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.validationInterval):
+		}
+	}
+}
+
+// Starts a run of the test
+func (s *loadTestingService) StartTestRun(
+	ctx context.Context,
+	subscriptionId string,
+	dataPlaneUri string,
+	testId string,
+	testRunId string,
+	displayName string,
+) error {
+	contents, err := json.Marshal(map[string]any{
+		"testId":      testId,
+		"displayName": displayName,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.send(
+		ctx, subscriptionId, http.MethodPatch, dataPlaneUri, fmt.Sprintf("/test-runs/%s", testRunId), nil,
+		contents, "application/merge-patch+json",
+	)
+	if err != nil {
+		return fmt.Errorf("starting run of load test '%s': %w", testId, err)
+	}
+
+	return nil
+}
+
+// Gets the status, and results, of the test run
+func (s *loadTestingService) GetTestRun(
+	ctx context.Context,
+	subscriptionId string,
+	dataPlaneUri string,
+	testRunId string,
+) (*AzCliLoadTestRun, error) {
+	response, err := s.send(
+		ctx, subscriptionId, http.MethodGet, dataPlaneUri, fmt.Sprintf("/test-runs/%s", testRunId), nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("getting load test run '%s': %w", testRunId, err)
+	}
+
+	testRun := &AzCliLoadTestRun{}
+	if err := json.Unmarshal(response, testRun); err != nil {
+		return nil, fmt.Errorf("reading load test run '%s': %w", testRunId, err)
+	}
+
+	return testRun, nil
+}
+
+// send sends a request to the data plane of the Azure Load Testing resource, and returns the body of the response
+func (s *loadTestingService) send(
+	ctx context.Context,
+	subscriptionId string,
+	method string,
+	dataPlaneUri string,
+	path string,
+	query url.Values,
+	body []byte,
+	contentType string,
+) ([]byte, error) {
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := runtime.NewPipeline("azcli", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{loadTestingScope}, nil)},
+	}, clientOptionsBuilder(ctx, s.httpClient, s.userAgent).BuildCoreClientOptions())
+
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", loadTestingDataPlaneApiVersion)
+
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("https://%s%s?%s", dataPlaneUri, path, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		if err := req.SetBody(streaming.NopCloser(bytes.NewReader(body)), contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusCreated) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return runtime.Payload(response)
+}
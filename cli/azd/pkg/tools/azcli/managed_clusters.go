@@ -7,6 +7,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 )
 
@@ -19,8 +20,50 @@ type ManagedClustersService interface {
 		resourceGroupName string,
 		resourceName string,
 	) (*armcontainerservice.CredentialResults, error)
+	// Gets the user credentials for the specified resource. The users of clusters with Microsoft Entra ID
+	// authentication get their tokens with the kubelogin exec plugin.
+	GetUserCredentials(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceName string,
+	) (*armcontainerservice.CredentialResults, error)
+	// Gets whether the local accounts of the cluster are disabled, in which case it has no admin credentials and
+	// users authenticate with Microsoft Entra ID
+	GetLocalAccountsDisabled(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceName string,
+	) (bool, error)
+	// Gets the url of the OIDC issuer of the cluster, empty when the issuer is not enabled
+	GetOidcIssuerUrl(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceName string,
+	) (string, error)
+	// Gets the object id of the kubelet identity of the cluster, which pulls the images of its pods. Empty when the
+	// cluster has no kubelet managed identity, ex) clusters using a service principal
+	GetKubeletIdentityObjectId(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceName string,
+	) (string, error)
+	// Gets the identity of the Azure Key Vault secrets provider add-on of the cluster, which the secrets store CSI driver
+	// reads Key Vault with. Nil when the add-on is not enabled.
+	GetKeyVaultSecretsProviderIdentity(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		resourceName string,
+	) (*armcontainerservice.ManagedClusterAddonProfileIdentity, error)
 }
 
+// The name of the Azure Key Vault secrets provider add-on in the add-on profiles of clusters
+const keyVaultSecretsProviderAddon = "azureKeyvaultSecretsProvider"
+
 type managedClustersService struct {
 	credentialProvider account.SubscriptionCredentialProvider
 	httpClient         httputil.HttpClient
@@ -59,6 +102,142 @@ func (cs *managedClustersService) GetAdminCredentials(
 	return &credResult.CredentialResults, nil
 }
 
+// Gets the user credentials for the specified resource, in the exec format of kubelogin for clusters with Microsoft
+// Entra ID authentication
+func (cs *managedClustersService) GetUserCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceName string,
+) (*armcontainerservice.CredentialResults, error) {
+	client, err := cs.createManagedClusterClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	credResult, err := client.ListClusterUserCredentials(
+		ctx,
+		resourceGroupName,
+		resourceName,
+		&armcontainerservice.ManagedClustersClientListClusterUserCredentialsOptions{
+			Format: convert.RefOf(armcontainerservice.FormatExec),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credResult.CredentialResults, nil
+}
+
+// Gets whether the local accounts of the cluster are disabled
+func (cs *managedClustersService) GetLocalAccountsDisabled(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceName string,
+) (bool, error) {
+	client, err := cs.createManagedClusterClient(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	cluster, err := client.Get(ctx, resourceGroupName, resourceName, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if cluster.Properties == nil {
+		return false, nil
+	}
+
+	return convert.ToValueWithDefault(cluster.Properties.DisableLocalAccounts, false), nil
+}
+
+// Gets the url of the OIDC issuer of the cluster, empty when the issuer is not enabled
+func (cs *managedClustersService) GetOidcIssuerUrl(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceName string,
+) (string, error) {
+	client, err := cs.createManagedClusterClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := client.Get(ctx, resourceGroupName, resourceName, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if cluster.Properties == nil || cluster.Properties.OidcIssuerProfile == nil {
+		return "", nil
+	}
+
+	return convert.ToValueWithDefault(cluster.Properties.OidcIssuerProfile.IssuerURL, ""), nil
+}
+
+// Gets the object id of the kubelet identity of the cluster, which pulls the images of its pods. Empty when the
+// cluster has no kubelet managed identity, ex) clusters using a service principal
+func (cs *managedClustersService) GetKubeletIdentityObjectId(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceName string,
+) (string, error) {
+	client, err := cs.createManagedClusterClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := client.Get(ctx, resourceGroupName, resourceName, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if cluster.Properties == nil || cluster.Properties.IdentityProfile == nil {
+		return "", nil
+	}
+
+	kubeletIdentity, has := cluster.Properties.IdentityProfile["kubeletidentity"]
+	if !has || kubeletIdentity == nil {
+		return "", nil
+	}
+
+	return convert.ToValueWithDefault(kubeletIdentity.ObjectID, ""), nil
+}
+
+// Gets the identity of the Azure Key Vault secrets provider add-on of the cluster, which the secrets store CSI driver
+// reads Key Vault with. Nil when the add-on is not enabled.
+func (cs *managedClustersService) GetKeyVaultSecretsProviderIdentity(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceName string,
+) (*armcontainerservice.ManagedClusterAddonProfileIdentity, error) {
+	client, err := cs.createManagedClusterClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := client.Get(ctx, resourceGroupName, resourceName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.Properties == nil || cluster.Properties.AddonProfiles == nil {
+		return nil, nil
+	}
+
+	addon, has := cluster.Properties.AddonProfiles[keyVaultSecretsProviderAddon]
+	if !has || addon == nil || !convert.ToValueWithDefault(addon.Enabled, false) || addon.Identity == nil {
+		return nil, nil
+	}
+
+	return addon.Identity, nil
+}
+
 func (cs *managedClustersService) createManagedClusterClient(
 	ctx context.Context,
 	subscriptionId string,
@@ -0,0 +1,133 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The api version of user assigned managed identities, and their federated identity credentials
+const userAssignedIdentitiesApiVersion = "2023-01-31"
+
+// The audience of the tokens exchanged for tokens of managed identities
+const federatedCredentialAudience = "api://AzureADTokenExchange"
+
+// ManagedIdentityService provides actions on top of user assigned managed identities
+type ManagedIdentityService interface {
+	// Gets the client id of the user assigned managed identity
+	GetClientId(ctx context.Context, subscriptionId string, identityId string) (string, error)
+	// Creates, or updates, the federated identity credential of the user assigned managed identity, which trusts the
+	// tokens of the subject issued by the issuer
+	CreateOrUpdateFederatedCredential(
+		ctx context.Context,
+		subscriptionId string,
+		identityId string,
+		name string,
+		issuer string,
+		subject string,
+	) error
+}
+
+type managedIdentityService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+}
+
+// Creates a new instance of the ManagedIdentityService
+func NewManagedIdentityService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) ManagedIdentityService {
+	return &managedIdentityService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.UserAgent(),
+	}
+}
+
+// Gets the client id of the user assigned managed identity
+func (s *managedIdentityService) GetClientId(
+	ctx context.Context,
+	subscriptionId string,
+	identityId string,
+) (string, error) {
+	client, err := s.createResourcesClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.GetByID(ctx, identityId, userAssignedIdentitiesApiVersion, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting managed identity: %w", err)
+	}
+
+	properties, _ := res.Properties.(map[string]any)
+	clientId, _ := properties["clientId"].(string)
+	if clientId == "" {
+		return "", fmt.Errorf("resource %s is not a user assigned managed identity", identityId)
+	}
+
+	return clientId, nil
+}
+
+// Creates, or updates, the federated identity credential of the user assigned managed identity
+func (s *managedIdentityService) CreateOrUpdateFederatedCredential(
+	ctx context.Context,
+	subscriptionId string,
+	identityId string,
+	name string,
+	issuer string,
+	subject string,
+) error {
+	client, err := s.createResourcesClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	poller, err := client.BeginCreateOrUpdateByID(
+		ctx,
+		fmt.Sprintf("%s/federatedIdentityCredentials/%s", identityId, name),
+		userAssignedIdentitiesApiVersion,
+		armresources.GenericResource{
+			Properties: map[string]any{
+				"issuer":    issuer,
+				"subject":   subject,
+				"audiences": []string{federatedCredentialAudience},
+			},
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("creating federated identity credential: %w", err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("creating federated identity credential: %w", err)
+	}
+
+	return nil
+}
+
+func (s *managedIdentityService) createResourcesClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armresources.Client, error) {
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(ctx, s.httpClient, s.userAgent).BuildArmClientOptions()
+
+	client, err := armresources.NewClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating resources client, %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,299 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+)
+
+const (
+	serviceBusApiVersion = "2021-11-01"
+	eventGridApiVersion  = "2022-06-15"
+	serviceBusScope      = "https://servicebus.azure.net/.default"
+	// Temporary subscriptions are deleted by Azure when they are idle, ex) when azd exits without deleting them
+	temporarySubscriptionIdleTimeout = "PT15M"
+	temporarySubscriptionLifetime    = 24 * time.Hour
+)
+
+// AzCliServiceBusMessage is a message received from a Service Bus entity
+type AzCliServiceBusMessage struct {
+	Body        []byte
+	ContentType string
+	// The broker properties of the message, ex) its MessageId, as JSON
+	BrokerProperties string
+}
+
+type armServiceBusTopic struct {
+	Name string `json:"name"`
+}
+
+// ListServiceBusTopics lists the names of the topics of the Service Bus namespace
+func (cli *azCli) ListServiceBusTopics(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	namespaceName string,
+) ([]string, error) {
+	query := url.Values{}
+	query.Set("api-version", serviceBusApiVersion)
+
+	topics, err := armList[armServiceBusTopic](
+		ctx,
+		cli,
+		subscriptionId,
+		fmt.Sprintf(
+			"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/topics",
+			subscriptionId, resourceGroupName, namespaceName,
+		),
+		query,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing service bus topics: %w", err)
+	}
+
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+
+	return names, nil
+}
+
+// CreateServiceBusTopicSubscription creates a temporary subscription to the topic, deleted by Azure once idle
+func (cli *azCli) CreateServiceBusTopicSubscription(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	namespaceName string,
+	topicName string,
+	name string,
+) error {
+	body := map[string]any{
+		"properties": map[string]any{
+			"autoDeleteOnIdle": temporarySubscriptionIdleTimeout,
+		},
+	}
+
+	if err := armSend(
+		ctx, cli, subscriptionId, http.MethodPut,
+		serviceBusSubscriptionPath(subscriptionId, resourceGroupName, namespaceName, topicName, name),
+		serviceBusApiVersion,
+		body,
+	); err != nil {
+		return fmt.Errorf("creating subscription '%s' of topic '%s': %w", name, topicName, err)
+	}
+
+	return nil
+}
+
+// DeleteServiceBusTopicSubscription deletes a subscription of the topic
+func (cli *azCli) DeleteServiceBusTopicSubscription(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	namespaceName string,
+	topicName string,
+	name string,
+) error {
+	if err := armSend(
+		ctx, cli, subscriptionId, http.MethodDelete,
+		serviceBusSubscriptionPath(subscriptionId, resourceGroupName, namespaceName, topicName, name),
+		serviceBusApiVersion,
+		nil,
+	); err != nil {
+		return fmt.Errorf("deleting subscription '%s' of topic '%s': %w", name, topicName, err)
+	}
+
+	return nil
+}
+
+// ReceiveServiceBusMessage receives and deletes the next message of the entity, ex) a topic subscription
+// <topic>/subscriptions/<name>, waiting up to the timeout for a message. Returns nil when no message is received.
+func (cli *azCli) ReceiveServiceBusMessage(
+	ctx context.Context,
+	subscriptionId string,
+	namespaceName string,
+	entityPath string,
+	timeout time.Duration,
+) (*AzCliServiceBusMessage, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := runtime.NewPipeline("azcli", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{serviceBusScope}, nil)},
+	}, cli.clientOptionsBuilder(ctx).BuildCoreClientOptions())
+
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodDelete,
+		fmt.Sprintf(
+			"https://%s.servicebus.windows.net/%s/messages/head?timeout=%d",
+			namespaceName, entityPath, int(timeout.Seconds()),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading message: %w", err)
+	}
+
+	return &AzCliServiceBusMessage{
+		Body:             body,
+		ContentType:      response.Header.Get("Content-Type"),
+		BrokerProperties: response.Header.Get("BrokerProperties"),
+	}, nil
+}
+
+// CreateEventGridSubscription creates a temporary event subscription to the Event Grid topic or system topic, delivering
+// events to the webhook endpoint. The subscription expires after a day.
+func (cli *azCli) CreateEventGridSubscription(
+	ctx context.Context,
+	subscriptionId string,
+	topicId string,
+	name string,
+	endpointUrl string,
+) error {
+	body := map[string]any{
+		"properties": map[string]any{
+			"destination": map[string]any{
+				"endpointType": "WebHook",
+				"properties": map[string]any{
+					"endpointUrl": endpointUrl,
+				},
+			},
+			"expirationTimeUtc": time.Now().UTC().Add(temporarySubscriptionLifetime).Format(time.RFC3339),
+		},
+	}
+
+	if err := armSend(
+		ctx, cli, subscriptionId, http.MethodPut, eventGridSubscriptionPath(topicId, name), eventGridApiVersion, body,
+	); err != nil {
+		return fmt.Errorf("creating event subscription '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// DeleteEventGridSubscription deletes an event subscription of the Event Grid topic or system topic
+func (cli *azCli) DeleteEventGridSubscription(
+	ctx context.Context,
+	subscriptionId string,
+	topicId string,
+	name string,
+) error {
+	if err := armSend(
+		ctx, cli, subscriptionId, http.MethodDelete, eventGridSubscriptionPath(topicId, name), eventGridApiVersion, nil,
+	); err != nil {
+		return fmt.Errorf("deleting event subscription '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func serviceBusSubscriptionPath(
+	subscriptionId string,
+	resourceGroupName string,
+	namespaceName string,
+	topicName string,
+	name string,
+) string {
+	return fmt.Sprintf(
+		"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/topics/%s/subscriptions/%s",
+		subscriptionId, resourceGroupName, namespaceName, topicName, name,
+	)
+}
+
+// eventGridSubscriptionPath returns the path of an event subscription, a child resource of system topics and an
+// extension resource of custom topics
+func eventGridSubscriptionPath(topicId string, name string) string {
+	if strings.Contains(strings.ToLower(topicId), "/providers/microsoft.eventgrid/systemtopics/") {
+		return fmt.Sprintf("%s/eventSubscriptions/%s", topicId, name)
+	}
+
+	return fmt.Sprintf("%s/providers/Microsoft.EventGrid/eventSubscriptions/%s", topicId, name)
+}
+
+// armSend sends a request with an optional JSON body to an ARM endpoint without a dedicated SDK client. Long running
+// operations are started but not awaited.
+func armSend(
+	ctx context.Context,
+	cli *azCli,
+	subscriptionId string,
+	method string,
+	path string,
+	apiVersion string,
+	body any,
+) error {
+	pipeline, err := cli.armPipeline(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+
+	req, err := runtime.NewRequest(
+		ctx, method, fmt.Sprintf("https://%s%s?%s", azure.ManagementHostName, path, query.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		contents, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		if err := req.SetBody(streaming.NopCloser(bytes.NewReader(contents)), "application/json"); err != nil {
+			return err
+		}
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(
+		response, http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent,
+	) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package azcli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateServiceBusTopicSubscription(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	var body map[string]any
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.HasSuffix(request.URL.Path, "/namespaces/NAMESPACE/topics/orders/subscriptions/azd-dev-1234")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &body))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, map[string]any{})
+	})
+
+	err := azCli.CreateServiceBusTopicSubscription(
+		*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "NAMESPACE", "orders", "azd-dev-1234")
+	require.NoError(t, err)
+	require.Equal(t, temporarySubscriptionIdleTimeout, body["properties"].(map[string]any)["autoDeleteOnIdle"])
+}
+
+func Test_ReceiveServiceBusMessage(t *testing.T) {
+	t.Run("Message", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete &&
+				request.URL.Host == "NAMESPACE.servicebus.windows.net" &&
+				request.URL.Path == "/orders/subscriptions/azd-dev-1234/messages/head"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Equal(t, "30", request.URL.Query().Get("timeout"))
+
+			response, err := mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"id": 1})
+			response.Header.Set("Content-Type", "application/json")
+			response.Header.Set("BrokerProperties", `{"MessageId":"1"}`)
+			return response, err
+		})
+
+		message, err := azCli.ReceiveServiceBusMessage(
+			*mockContext.Context, "SUBSCRIPTION_ID", "NAMESPACE", "orders/subscriptions/azd-dev-1234", 30*time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, message)
+		require.JSONEq(t, `{"id":1}`, string(message.Body))
+		require.Equal(t, "application/json", message.ContentType)
+		require.Equal(t, `{"MessageId":"1"}`, message.BrokerProperties)
+	})
+
+	t.Run("NoMessage", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete && strings.HasSuffix(request.URL.Path, "/messages/head")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+		})
+
+		message, err := azCli.ReceiveServiceBusMessage(
+			*mockContext.Context, "SUBSCRIPTION_ID", "NAMESPACE", "orders/subscriptions/azd-dev-1234", 30*time.Second)
+		require.NoError(t, err)
+		require.Nil(t, message)
+	})
+}
+
+func Test_EventGridSubscriptionPath(t *testing.T) {
+	customTopic := "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.EventGrid/topics/orders"
+	require.Equal(
+		t,
+		customTopic+"/providers/Microsoft.EventGrid/eventSubscriptions/azd-dev-1234",
+		eventGridSubscriptionPath(customTopic, "azd-dev-1234"),
+	)
+
+	systemTopic := "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.EventGrid/systemTopics/storage"
+	require.Equal(
+		t,
+		systemTopic+"/eventSubscriptions/azd-dev-1234",
+		eventGridSubscriptionPath(systemTopic, "azd-dev-1234"),
+	)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const permissionsApiVersion = "2022-04-01"
+
+// AzCliPermission is a set of actions the signed-in principal is allowed to perform at a scope, granted by the role
+// definitions of its role assignments
+type AzCliPermission struct {
+	Actions        []string `json:"actions"`
+	NotActions     []string `json:"notActions"`
+	DataActions    []string `json:"dataActions"`
+	NotDataActions []string `json:"notDataActions"`
+}
+
+// Allows returns whether the permission allows the action, ex) Microsoft.Resources/deployments/write. The actions of
+// the permission may contain wildcards, ex) Microsoft.Resources/*.
+func (p AzCliPermission) Allows(action string) bool {
+	return matchesAnyAction(p.Actions, action) && !matchesAnyAction(p.NotActions, action)
+}
+
+func matchesAnyAction(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expression, action); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListPermissions lists the permissions of the signed-in principal at the scope, ex) a subscription or resource group,
+// including the permissions inherited from the scopes above it.
+func (cli *azCli) ListPermissions(ctx context.Context, subscriptionId string, scope string) ([]AzCliPermission, error) {
+	query := url.Values{}
+	query.Set("api-version", permissionsApiVersion)
+
+	permissions, err := armList[AzCliPermission](
+		ctx, cli, subscriptionId, fmt.Sprintf("%s/providers/Microsoft.Authorization/permissions", scope), query)
+	if err != nil {
+		return nil, fmt.Errorf("listing permissions of scope '%s': %w", scope, err)
+	}
+
+	return permissions, nil
+}
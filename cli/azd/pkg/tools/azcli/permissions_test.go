@@ -0,0 +1,31 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PermissionAllows(t *testing.T) {
+	contributor := AzCliPermission{
+		Actions: []string{"*"},
+		NotActions: []string{
+			"Microsoft.Authorization/*/Delete",
+			"Microsoft.Authorization/*/Write",
+		},
+	}
+
+	require.True(t, contributor.Allows("Microsoft.Resources/deployments/write"))
+	require.False(t, contributor.Allows("Microsoft.Authorization/roleAssignments/write"))
+
+	acrPush := AzCliPermission{
+		Actions: []string{"Microsoft.ContainerRegistry/registries/pull/read", "Microsoft.ContainerRegistry/registries/push/*"},
+	}
+
+	require.True(t, acrPush.Allows("Microsoft.ContainerRegistry/registries/push/write"))
+	require.False(t, acrPush.Allows("Microsoft.ContainerRegistry/registries/delete"))
+	require.False(t, AzCliPermission{}.Allows("Microsoft.Resources/deployments/write"))
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const policyRestrictionsApiVersion = "2022-03-01"
+
+// AzCliPolicyEvaluation is the evaluation of a policy assignment against the content of a resource
+type AzCliPolicyEvaluation struct {
+	PolicyAssignmentId          string
+	PolicyAssignmentDisplayName string
+	PolicyDefinitionId          string
+	PolicyDefinitionDisplayName string
+	// The effect of the policy, ex) deny
+	Effect string
+	// Compliant or NonCompliant
+	EvaluationResult string
+	// Why the resource isn't compliant, ex) the non-compliance message of the assignment or the failed expression
+	Reason string
+}
+
+type armPolicyRestrictions struct {
+	ContentEvaluationResult struct {
+		PolicyEvaluations []struct {
+			PolicyInfo struct {
+				PolicyDefinitionId          string `json:"policyDefinitionId"`
+				PolicyDefinitionDisplayName string `json:"policyDefinitionDisplayName"`
+				PolicyDefinitionEffect      string `json:"policyDefinitionEffect"`
+				PolicyAssignmentId          string `json:"policyAssignmentId"`
+				PolicyAssignmentDisplayName string `json:"policyAssignmentDisplayName"`
+			} `json:"policyInfo"`
+			EvaluationResult  string `json:"evaluationResult"`
+			EvaluationDetails struct {
+				Reason               string `json:"reason"`
+				EvaluatedExpressions []struct {
+					Expression      string `json:"expression"`
+					ExpressionValue any    `json:"expressionValue"`
+					TargetValue     any    `json:"targetValue"`
+					Operator        string `json:"operator"`
+					Result          string `json:"result"`
+				} `json:"evaluatedExpressions"`
+			} `json:"evaluationDetails"`
+		} `json:"policyEvaluations"`
+	} `json:"contentEvaluationResult"`
+}
+
+// CheckPolicyRestrictions evaluates the policy assignments of the resource group, or of the subscription when
+// resourceGroupName is empty or the resource group doesn't exist yet, against the content of a resource, ex) the
+// resource predicted by a what-if deployment. Only the policies which would deny the resource are evaluated.
+func (cli *azCli) CheckPolicyRestrictions(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	resourceContent map[string]any,
+	apiVersion string,
+) ([]AzCliPolicyEvaluation, error) {
+	scope := azure.SubscriptionRID(subscriptionId)
+	if resourceGroupName != "" {
+		scope = azure.ResourceGroupRID(subscriptionId, resourceGroupName)
+	}
+
+	body := map[string]any{
+		"resourceDetails": map[string]any{
+			"resourceContent": resourceContent,
+			"apiVersion":      apiVersion,
+		},
+	}
+
+	restrictions, err := cli.checkPolicyRestrictions(ctx, subscriptionId, scope, body)
+
+	var responseErr *azcore.ResponseError
+	if resourceGroupName != "" && errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		restrictions, err = cli.checkPolicyRestrictions(ctx, subscriptionId, azure.SubscriptionRID(subscriptionId), body)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("checking policy restrictions: %w", err)
+	}
+
+	evaluations := []AzCliPolicyEvaluation{}
+	for _, evaluation := range restrictions.ContentEvaluationResult.PolicyEvaluations {
+		reason := evaluation.EvaluationDetails.Reason
+		if reason == "" {
+			for _, expression := range evaluation.EvaluationDetails.EvaluatedExpressions {
+				if expression.Result == "True" {
+					reason = fmt.Sprintf(
+						"%s is %v, %s %v",
+						expression.Expression, expression.ExpressionValue, expression.Operator, expression.TargetValue)
+					break
+				}
+			}
+		}
+
+		evaluations = append(evaluations, AzCliPolicyEvaluation{
+			PolicyAssignmentId:          evaluation.PolicyInfo.PolicyAssignmentId,
+			PolicyAssignmentDisplayName: evaluation.PolicyInfo.PolicyAssignmentDisplayName,
+			PolicyDefinitionId:          evaluation.PolicyInfo.PolicyDefinitionId,
+			PolicyDefinitionDisplayName: evaluation.PolicyInfo.PolicyDefinitionDisplayName,
+			Effect:                      evaluation.PolicyInfo.PolicyDefinitionEffect,
+			EvaluationResult:            evaluation.EvaluationResult,
+			Reason:                      reason,
+		})
+	}
+
+	return evaluations, nil
+}
+
+func (cli *azCli) checkPolicyRestrictions(
+	ctx context.Context,
+	subscriptionId string,
+	scope string,
+	body map[string]any,
+) (*armPolicyRestrictions, error) {
+	pipeline, err := cli.armPipeline(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("api-version", policyRestrictionsApiVersion)
+
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf(
+			"https://%s%s/providers/Microsoft.PolicyInsights/checkPolicyRestrictions?%s",
+			azure.ManagementHostName, scope, query.Encode(),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(contents)), "application/json"); err != nil {
+		return nil, err
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[armPolicyRestrictions](response)
+}
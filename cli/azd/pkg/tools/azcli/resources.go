@@ -2,9 +2,14 @@ package azcli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
 func (cli *azCli) GetResource(
@@ -26,7 +31,8 @@ func (cli *azCli) GetResource(
 			Type:     *res.Type,
 			Location: *res.Location,
 		},
-		Kind: *res.Kind,
+		Kind:     convert.ToValueWithDefault(res.Kind, ""),
+		Identity: res.Identity,
 	}, nil
 }
 
@@ -62,6 +68,7 @@ func (cli *azCli) ListResourceGroupResources(
 				Name:     *resource.Name,
 				Type:     *resource.Type,
 				Location: *resource.Location,
+				Tags:     tagsFromAzure(resource.Tags),
 			})
 		}
 	}
@@ -110,6 +117,7 @@ func (cli *azCli) ListResourceGroup(
 				Name:     *group.Name,
 				Type:     *group.Type,
 				Location: *group.Location,
+				Tags:     tagsFromAzure(group.Tags),
 			})
 		}
 	}
@@ -156,6 +164,43 @@ func (cli *azCli) DeleteResourceGroup(ctx context.Context, subscriptionId string
 	return nil
 }
 
+// ExportResourceGroupTemplate exports the resources of the resource group to an ARM template. Resource names are exported
+// as template parameters with the current names as default values.
+func (cli *azCli) ExportResourceGroupTemplate(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+) (azure.RawArmTemplate, error) {
+	client, err := cli.createResourceGroupClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	poller, err := client.BeginExportTemplate(ctx, resourceGroupName, armresources.ExportTemplateRequest{
+		Resources: []*string{to.Ptr("*")},
+		Options:   to.Ptr("IncludeParameterDefaultValue"),
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning resource group export: %w", err)
+	}
+
+	result, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("exporting resource group: %w", err)
+	}
+
+	if result.Error != nil && result.Error.Message != nil {
+		log.Printf("resource group '%s' was partially exported: %s", resourceGroupName, *result.Error.Message)
+	}
+
+	template, err := json.Marshal(result.Template)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling exported template: %w", err)
+	}
+
+	return template, nil
+}
+
 func (cli *azCli) createResourcesClient(ctx context.Context, subscriptionId string) (*armresources.Client, error) {
 	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
 	if err != nil {
@@ -188,3 +233,17 @@ func (cli *azCli) createResourceGroupClient(
 
 	return client, nil
 }
+
+// tagsFromAzure converts the tags returned by the Azure SDK into a plain map
+func tagsFromAzure(tags map[string]*string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(tags))
+	for key, value := range tags {
+		result[key] = convert.ToValueWithDefault(value, "")
+	}
+
+	return result
+}
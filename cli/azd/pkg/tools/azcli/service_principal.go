@@ -0,0 +1,263 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/google/uuid"
+)
+
+// AzCliServicePrincipal is a service principal and the credentials of its application
+type AzCliServicePrincipal struct {
+	// The object id of the service principal
+	ObjectId string `json:"objectId"`
+	AppId    string `json:"appId"`
+	// The object id of the application of the service principal
+	ApplicationObjectId string                            `json:"applicationObjectId"`
+	DisplayName         string                            `json:"displayName"`
+	TenantId            string                            `json:"tenantId"`
+	Secrets             []AzCliServicePrincipalCredential `json:"secrets"`
+	Certificates        []AzCliServicePrincipalCredential `json:"certificates"`
+	// The subjects of the federated identity credentials, ex) repo:owner/repo:ref:refs/heads/main
+	FederatedSubjects []string `json:"federatedSubjects"`
+}
+
+// AzCliServicePrincipalCredential is a secret or certificate of the application of a service principal
+type AzCliServicePrincipalCredential struct {
+	KeyId       string `json:"keyId"`
+	DisplayName string `json:"displayName,omitempty"`
+	// The first characters of a secret
+	Hint        string     `json:"hint,omitempty"`
+	EndDateTime *time.Time `json:"endDateTime,omitempty"`
+}
+
+// GetServicePrincipal gets the service principal with the object id or display name, along with the credentials of its
+// application.
+func (cli *azCli) GetServicePrincipal(
+	ctx context.Context,
+	subscriptionId string,
+	principal string,
+) (*AzCliServicePrincipal, error) {
+	graphClient, err := cli.createGraphClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var servicePrincipal *graphsdk.ServicePrincipal
+	if _, err := uuid.Parse(principal); err == nil {
+		servicePrincipal, err = graphClient.ServicePrincipalById(principal).Get(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving service principal '%s': %w", principal, err)
+		}
+	} else {
+		matchingItems, err := graphClient.
+			ServicePrincipals().
+			Filter(fmt.Sprintf("displayName eq '%s'", principal)).
+			Get(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving service principal list: %w", err)
+		}
+
+		if len(matchingItems.Value) == 0 {
+			return nil, fmt.Errorf("service principal '%s' was not found", principal)
+		}
+
+		if len(matchingItems.Value) > 1 {
+			return nil, fmt.Errorf("more than 1 service principal exists with same name '%s'", principal)
+		}
+
+		servicePrincipal = &matchingItems.Value[0]
+	}
+
+	applications, err := graphClient.
+		Applications().
+		Filter(fmt.Sprintf("appId eq '%s'", servicePrincipal.AppId)).
+		Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving application list: %w", err)
+	}
+
+	if len(applications.Value) == 0 || applications.Value[0].Id == nil {
+		return nil, fmt.Errorf(
+			"application of service principal '%s' was not found in the directory", servicePrincipal.DisplayName)
+	}
+	application := applications.Value[0]
+
+	federatedCredentials, err := graphClient.
+		ApplicationById(*application.Id).
+		FederatedIdentityCredentials().
+		Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving federated identity credentials: %w", err)
+	}
+
+	result := &AzCliServicePrincipal{
+		ObjectId:            convert.ToValueWithDefault(servicePrincipal.Id, ""),
+		AppId:               servicePrincipal.AppId,
+		ApplicationObjectId: *application.Id,
+		DisplayName:         servicePrincipal.DisplayName,
+		TenantId:            convert.ToValueWithDefault(servicePrincipal.AppOwnerOrganizationId, ""),
+		Secrets:             []AzCliServicePrincipalCredential{},
+		Certificates:        []AzCliServicePrincipalCredential{},
+		FederatedSubjects:   []string{},
+	}
+
+	for _, secret := range application.PasswordCredentials {
+		result.Secrets = append(result.Secrets, AzCliServicePrincipalCredential{
+			KeyId:       convert.ToValueWithDefault(secret.KeyId, ""),
+			DisplayName: convert.ToValueWithDefault(secret.DisplayName, ""),
+			Hint:        convert.ToValueWithDefault(secret.Hint, ""),
+			EndDateTime: secret.EndDateTime,
+		})
+	}
+
+	for _, certificate := range application.KeyCredentials {
+		result.Certificates = append(result.Certificates, AzCliServicePrincipalCredential{
+			KeyId:       convert.ToValueWithDefault(certificate.KeyId, ""),
+			DisplayName: convert.ToValueWithDefault(certificate.DisplayName, ""),
+			EndDateTime: certificate.EndDateTime,
+		})
+	}
+
+	for _, federatedCredential := range federatedCredentials.Value {
+		result.FederatedSubjects = append(result.FederatedSubjects, federatedCredential.Subject)
+	}
+
+	return result, nil
+}
+
+// ResetServicePrincipalSecret replaces the secrets of the application of the service principal with a new secret and
+// returns the new credentials in the `AZURE_CREDENTIALS` format.
+func (cli *azCli) ResetServicePrincipalSecret(
+	ctx context.Context,
+	subscriptionId string,
+	servicePrincipal *AzCliServicePrincipal,
+) (json.RawMessage, error) {
+	graphClient, err := cli.createGraphClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := graphClient.ApplicationById(servicePrincipal.ApplicationObjectId).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving application '%s': %w", servicePrincipal.DisplayName, err)
+	}
+
+	credential, err := resetCredentials(ctx, graphClient, application)
+	if err != nil {
+		return nil, fmt.Errorf("failed resetting application credentials: %w", err)
+	}
+
+	return marshalAzureCredentials(AzureCredentials{
+		ClientId:                   servicePrincipal.AppId,
+		ClientSecret:               *credential.SecretText,
+		SubscriptionId:             subscriptionId,
+		TenantId:                   servicePrincipal.TenantId,
+		ResourceManagerEndpointUrl: "https://management.azure.com/",
+	})
+}
+
+// SetServicePrincipalCertificate replaces the certificates of the application of the service principal with the PEM or
+// DER encoded certificate. Only the public certificate is uploaded, a private key in the same PEM file is ignored.
+func (cli *azCli) SetServicePrincipalCertificate(
+	ctx context.Context,
+	subscriptionId string,
+	servicePrincipal *AzCliServicePrincipal,
+	certificate []byte,
+) error {
+	parsed, err := parseCertificate(certificate)
+	if err != nil {
+		return err
+	}
+
+	graphClient, err := cli.createGraphClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	err = graphClient.
+		ApplicationById(servicePrincipal.ApplicationObjectId).
+		SetKeyCredentials(ctx, []*graphsdk.ApplicationKeyCredential{
+			{
+				DisplayName:   convert.RefOf("Azure Developer CLI"),
+				StartDateTime: &parsed.NotBefore,
+				EndDateTime:   &parsed.NotAfter,
+				Type:          convert.RefOf("AsymmetricX509Cert"),
+				Usage:         convert.RefOf("Verify"),
+				Key:           parsed.Raw,
+			},
+		})
+	if err != nil {
+		return fmt.Errorf("failed setting certificate of application '%s': %w", servicePrincipal.DisplayName, err)
+	}
+
+	return nil
+}
+
+// EnsureServicePrincipalRoles assigns the roles to the service principal in the subscription. Roles which are already
+// assigned are not an error.
+func (cli *azCli) EnsureServicePrincipalRoles(
+	ctx context.Context,
+	subscriptionId string,
+	servicePrincipal *AzCliServicePrincipal,
+	roleNames []string,
+) error {
+	err := cli.ensureRoleAssignments(ctx, subscriptionId, roleNames, &graphsdk.ServicePrincipal{
+		Id:          &servicePrincipal.ObjectId,
+		AppId:       servicePrincipal.AppId,
+		DisplayName: servicePrincipal.DisplayName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed applying role assignment: %w", err)
+	}
+
+	return nil
+}
+
+// parseCertificate parses the first certificate of a PEM file, or a DER encoded certificate
+func parseCertificate(contents []byte) (*x509.Certificate, error) {
+	rest := contents
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+
+	certificate, err := x509.ParseCertificate(contents)
+	if err != nil {
+		return nil, errors.New("the certificate must be a PEM or DER encoded X.509 certificate")
+	}
+
+	return certificate, nil
+}
+
+// marshalAzureCredentials returns the credentials in the `AZURE_CREDENTIALS` format
+func marshalAzureCredentials(azureCreds AzureCredentials) (json.RawMessage, error) {
+	credentialsJson, err := json.Marshal(azureCreds)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshalling Azure credentials to JSON: %w", err)
+	}
+
+	var rawMessage json.RawMessage
+	if err := json.Unmarshal(credentialsJson, &rawMessage); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling JSON to raw message: %w", err)
+	}
+
+	return rawMessage, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockgraphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetServicePrincipal(t *testing.T) {
+	endDateTime := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	application := graphsdk.Application{
+		Id:          convert.RefOf("APPLICATION_ID"),
+		AppId:       &expectedServicePrincipalCredential.ClientId,
+		DisplayName: "MY_APP",
+		PasswordCredentials: []*graphsdk.ApplicationPasswordCredential{
+			{
+				KeyId:       convert.RefOf("KEY_ID"),
+				DisplayName: convert.RefOf("Azure Developer CLI"),
+				Hint:        convert.RefOf("abc"),
+				EndDateTime: &endDateTime,
+			},
+		},
+	}
+	servicePrincipal := graphsdk.ServicePrincipal{
+		Id:                     convert.RefOf("SPN_ID"),
+		AppId:                  expectedServicePrincipalCredential.ClientId,
+		DisplayName:            "MY_APP",
+		AppOwnerOrganizationId: &expectedServicePrincipalCredential.TenantId,
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockgraphsdk.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+	mockgraphsdk.RegisterServicePrincipalListMock(
+		mockContext, http.StatusOK, []graphsdk.ServicePrincipal{servicePrincipal})
+	mockgraphsdk.RegisterFederatedCredentialsListMock(
+		mockContext, *application.Id, http.StatusOK, []graphsdk.FederatedIdentityCredential{
+			{Name: "main", Subject: "repo:owner/repo:ref:refs/heads/main"},
+		})
+
+	azCli := newAzCliFromMockContext(mockContext)
+	actual, err := azCli.GetServicePrincipal(
+		*mockContext.Context, expectedServicePrincipalCredential.SubscriptionId, "MY_APP")
+	require.NoError(t, err)
+
+	require.Equal(t, &AzCliServicePrincipal{
+		ObjectId:            "SPN_ID",
+		AppId:               expectedServicePrincipalCredential.ClientId,
+		ApplicationObjectId: "APPLICATION_ID",
+		DisplayName:         "MY_APP",
+		TenantId:            expectedServicePrincipalCredential.TenantId,
+		Secrets: []AzCliServicePrincipalCredential{
+			{KeyId: "KEY_ID", DisplayName: "Azure Developer CLI", Hint: "abc", EndDateTime: &endDateTime},
+		},
+		Certificates:      []AzCliServicePrincipalCredential{},
+		FederatedSubjects: []string{"repo:owner/repo:ref:refs/heads/main"},
+	}, actual)
+}
+
+func Test_ResetServicePrincipalSecret(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("APPLICATION_ID"),
+		AppId:       &expectedServicePrincipalCredential.ClientId,
+		DisplayName: "MY_APP",
+		PasswordCredentials: []*graphsdk.ApplicationPasswordCredential{
+			{KeyId: convert.RefOf("OLD_KEY_ID")},
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockgraphsdk.RegisterApplicationGetItemMock(mockContext, http.StatusOK, *application.Id, &application)
+	mockgraphsdk.RegisterApplicationRemovePasswordMock(mockContext, http.StatusNoContent, *application.Id)
+	mockgraphsdk.RegisterApplicationAddPasswordMock(
+		mockContext, http.StatusOK, *application.Id, &graphsdk.ApplicationPasswordCredential{
+			KeyId:      convert.RefOf("KEY_ID"),
+			SecretText: &expectedServicePrincipalCredential.ClientSecret,
+		})
+
+	azCli := newAzCliFromMockContext(mockContext)
+	rawMessage, err := azCli.ResetServicePrincipalSecret(
+		*mockContext.Context, expectedServicePrincipalCredential.SubscriptionId, &AzCliServicePrincipal{
+			ObjectId:            "SPN_ID",
+			AppId:               expectedServicePrincipalCredential.ClientId,
+			ApplicationObjectId: *application.Id,
+			DisplayName:         "MY_APP",
+			TenantId:            expectedServicePrincipalCredential.TenantId,
+		})
+	require.NoError(t, err)
+	assertAzureCredentials(t, rawMessage)
+}
+
+func Test_ParseCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "azd"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	// the private key is ignored when it comes before the certificate
+	pemContents := append(
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+
+	t.Run("PEM", func(t *testing.T) {
+		certificate, err := parseCertificate(pemContents)
+		require.NoError(t, err)
+		require.Equal(t, der, certificate.Raw)
+	})
+
+	t.Run("DER", func(t *testing.T) {
+		certificate, err := parseCertificate(der)
+		require.NoError(t, err)
+		require.Equal(t, "azd", certificate.Subject.CommonName)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := parseCertificate([]byte("not a certificate"))
+		require.Error(t, err)
+	})
+}
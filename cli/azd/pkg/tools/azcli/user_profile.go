@@ -48,17 +48,27 @@ func (u *UserProfileService) createGraphClient(ctx context.Context, tenantId str
 }
 
 func (user *UserProfileService) GetSignedInUserId(ctx context.Context, tenantId string) (string, error) {
-	client, err := user.createGraphClient(ctx, tenantId)
+	userProfile, err := user.GetSignedInUser(ctx, tenantId)
 	if err != nil {
 		return "", err
 	}
 
+	return userProfile.Id, nil
+}
+
+// GetSignedInUser gets the profile of the signed-in user. Fails when a service principal is signed in.
+func (user *UserProfileService) GetSignedInUser(ctx context.Context, tenantId string) (*graphsdk.UserProfile, error) {
+	client, err := user.createGraphClient(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
 	userProfile, err := client.Me().Get(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed retrieving current user profile: %w", err)
+		return nil, fmt.Errorf("failed retrieving current user profile: %w", err)
 	}
 
-	return userProfile.Id, nil
+	return userProfile, nil
 }
 
 func (u *UserProfileService) GetAccessToken(ctx context.Context, tenantId string) (*AzCliAccessToken, error) {
@@ -2,9 +2,13 @@ package azcli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
 	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
@@ -35,19 +39,227 @@ func (cli *azCli) GetAppServiceProperties(
 	}, nil
 }
 
+// AzCliAppServiceCommandResult is the result of a command run on the Kudu site of an app service
+type AzCliAppServiceCommandResult struct {
+	Output   string `json:"Output"`
+	Error    string `json:"Error"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// ExecAppServiceCommand runs a command on the Kudu site of the app service and waits for its completion. More info
+// can be found at https://github.com/projectkudu/kudu/wiki/REST-API
+func (cli *azCli) ExecAppServiceCommand(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	command string,
+) (*AzCliAppServiceCommandResult, error) {
+	pipeline, err := cli.armPipeline(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := runtime.NewRequest(
+		ctx, http.MethodPost, fmt.Sprintf("https://%s.scm.azurewebsites.net/api/command", appName))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runtime.MarshalAsJSON(req, map[string]string{"command": command}); err != nil {
+		return nil, err
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("running command on app service '%s': %w", appName, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, fmt.Errorf("running command on app service '%s': %w", appName, runtime.NewResponseError(response))
+	}
+
+	var result AzCliAppServiceCommandResult
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding command result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// StreamAppServiceLogs writes the live log stream of the Kudu site of the app service until the context is cancelled.
+// The log path selects the logs under the LogFiles directory of the app, ex) application/functions/function for the
+// invocation logs of a function app, all logs are streamed when empty.
+func (cli *azCli) StreamAppServiceLogs(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	logPath string,
+	writer io.Writer,
+) error {
+	pipeline, err := cli.armPipeline(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/logstream", appName)
+	if logPath != "" {
+		endpoint = fmt.Sprintf("%s/%s", endpoint, logPath)
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return err
+	}
+
+	// The log stream doesn't end, its body is read as it is received
+	runtime.SkipBodyDownload(req)
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return fmt.Errorf("streaming logs of app service '%s': %w", appName, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return fmt.Errorf("streaming logs of app service '%s': %w", appName, runtime.NewResponseError(response))
+	}
+
+	if _, err := io.Copy(writer, response.Body); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("streaming logs of app service '%s': %w", appName, err)
+	}
+
+	return nil
+}
+
+// RestartAppService restarts the app service
+func (cli *azCli) RestartAppService(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Restart(ctx, resourceGroup, appName, nil); err != nil {
+		return fmt.Errorf("restarting app service '%s': %w", appName, err)
+	}
+
+	return nil
+}
+
+// ScaleAppServicePlan sets the number of instances of the app service plan hosting the app service. The plan can be
+// in another resource group than the app service.
+func (cli *azCli) ScaleAppServicePlan(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	instances int,
+) error {
+	webAppsClient, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	webApp, err := webAppsClient.Get(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving webapp properties: %w", err)
+	}
+
+	if webApp.Properties == nil || webApp.Properties.ServerFarmID == nil {
+		return fmt.Errorf("app service '%s' has no app service plan", appName)
+	}
+
+	planId, err := arm.ParseResourceID(*webApp.Properties.ServerFarmID)
+	if err != nil {
+		return fmt.Errorf("parsing app service plan id: %w", err)
+	}
+
+	plansClient, err := cli.createPlansClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	plan, err := plansClient.Get(ctx, planId.ResourceGroupName, planId.Name, nil)
+	if err != nil {
+		return fmt.Errorf("getting app service plan '%s': %w", planId.Name, err)
+	}
+
+	if plan.SKU == nil {
+		plan.SKU = &armappservice.SKUDescription{}
+	}
+	plan.SKU.Capacity = convert.RefOf(int32(instances))
+
+	poller, err := plansClient.BeginCreateOrUpdate(ctx, planId.ResourceGroupName, planId.Name, plan.Plan, nil)
+	if err != nil {
+		return fmt.Errorf("scaling app service plan '%s': %w", planId.Name, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("scaling app service plan '%s': %w", planId.Name, err)
+	}
+
+	return nil
+}
+
+// UpdateAppServiceAppSettings adds or updates the app settings of the app service, ex) of a web or function app. The other
+// app settings of the app service are kept.
+func (cli *azCli) UpdateAppServiceAppSettings(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	settings map[string]string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.ListApplicationSettings(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("listing app settings of app service '%s': %w", appName, err)
+	}
+
+	appSettings := armappservice.StringDictionary{Properties: current.Properties}
+	if appSettings.Properties == nil {
+		appSettings.Properties = map[string]*string{}
+	}
+	for name, value := range settings {
+		appSettings.Properties[name] = convert.RefOf(value)
+	}
+
+	if _, err := client.UpdateApplicationSettings(ctx, resourceGroup, appName, appSettings, nil); err != nil {
+		return fmt.Errorf("updating app settings of app service '%s': %w", appName, err)
+	}
+
+	return nil
+}
+
 func (cli *azCli) DeployAppServiceZip(
 	ctx context.Context,
 	subscriptionId string,
 	resourceGroup string,
 	appName string,
-	deployZipFile io.Reader,
+	deployZipFile io.ReadSeeker,
+	progress func(percent int),
 ) (*string, error) {
 	client, err := cli.createZipDeployClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := client.Deploy(ctx, appName, deployZipFile)
+	response, err := client.Deploy(ctx, appName, deployZipFile, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +282,21 @@ func (cli *azCli) createWebAppsClient(ctx context.Context, subscriptionId string
 	return client, nil
 }
 
+func (cli *azCli) createPlansClient(ctx context.Context, subscriptionId string) (*armappservice.PlansClient, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := cli.clientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := armappservice.NewPlansClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating Plans client: %w", err)
+	}
+
+	return client, nil
+}
+
 func (cli *azCli) createZipDeployClient(ctx context.Context, subscriptionId string) (*azsdk.ZipDeployClient, error) {
 	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
 	if err != nil {
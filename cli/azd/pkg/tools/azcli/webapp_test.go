@@ -0,0 +1,188 @@
+package azcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExecAppServiceCommand(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		var body map[string]string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost &&
+				request.URL.Host == "APP_NAME.scm.azurewebsites.net" &&
+				request.URL.Path == "/api/command"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			contents, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(contents, &body))
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, AzCliAppServiceCommandResult{
+				Output:   "hello\n",
+				ExitCode: 0,
+			})
+		})
+
+		result, err := azCli.ExecAppServiceCommand(
+			*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME", "echo hello")
+		require.NoError(t, err)
+		require.Equal(t, "echo hello", body["command"])
+		require.Equal(t, "hello\n", result.Output)
+		require.Equal(t, 0, result.ExitCode)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && request.URL.Host == "APP_NAME.scm.azurewebsites.net"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusUnauthorized)
+		})
+
+		_, err := azCli.ExecAppServiceCommand(
+			*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME", "echo hello")
+		require.ErrorContains(t, err, "running command on app service 'APP_NAME'")
+	})
+}
+
+func Test_StreamAppServiceLogs(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			request.URL.Host == "APP_NAME.scm.azurewebsites.net" &&
+			request.URL.Path == "/api/logstream/application/functions/function"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("Executing 'Functions.hello'\n")),
+		}, nil
+	})
+
+	output := &bytes.Buffer{}
+	err := azCli.StreamAppServiceLogs(
+		*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME", "application/functions/function", output)
+	require.NoError(t, err)
+	require.Equal(t, "Executing 'Functions.hello'\n", output.String())
+}
+
+func Test_RestartAppService(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	restarted := false
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Web/sites/APP_NAME/restart")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		restarted = true
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	err := azCli.RestartAppService(*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME")
+	require.NoError(t, err)
+	require.True(t, restarted)
+}
+
+func Test_ScaleAppServicePlan(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	planId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/PLAN_RESOURCE_GROUP/providers/Microsoft.Web/serverfarms/PLAN"
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/sites/APP_NAME")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.Site{
+			Properties: &armappservice.SiteProperties{
+				ServerFarmID: convert.RefOf(planId),
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == planId
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.Plan{
+			Location: convert.RefOf("eastus2"),
+			SKU: &armappservice.SKUDescription{
+				Name:     convert.RefOf("P1v3"),
+				Capacity: convert.RefOf(int32(1)),
+			},
+		})
+	})
+
+	var updated armappservice.Plan
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == planId
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &updated))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updated)
+	})
+
+	err := azCli.ScaleAppServicePlan(*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME", 3)
+	require.NoError(t, err)
+	require.Equal(t, "P1v3", *updated.SKU.Name)
+	require.Equal(t, int32(3), *updated.SKU.Capacity)
+}
+
+func Test_UpdateAppServiceAppSettings(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/sites/APP_NAME/config/appsettings/list")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.StringDictionary{
+			Properties: map[string]*string{
+				"API_URL":   convert.RefOf("https://old.azurewebsites.net"),
+				"LOG_LEVEL": convert.RefOf("info"),
+			},
+		})
+	})
+
+	var updated armappservice.StringDictionary
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/sites/APP_NAME/config/appsettings")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		contents, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &updated))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updated)
+	})
+
+	err := azCli.UpdateAppServiceAppSettings(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"APP_NAME",
+		map[string]string{"API_URL": "https://api.azurewebsites.net"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*string{
+		"API_URL":   convert.RefOf("https://api.azurewebsites.net"),
+		"LOG_LEVEL": convert.RefOf("info"),
+	}, updated.Properties)
+}
@@ -5,6 +5,7 @@ package bicep
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,9 +13,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/internal/offline"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
@@ -31,8 +36,31 @@ var BicepVersion semver.Version = semver.MustParse("0.18.4")
 
 type BicepCli interface {
 	Build(ctx context.Context, file string) (string, error)
+	// Decompile converts the ARM template file to Bicep and returns the Bicep source
+	Decompile(ctx context.Context, file string) (string, error)
+	// BuildParams compiles the .bicepparam file to a parameters JSON file and returns its contents. The environment
+	// variables are available to the file through readEnvironmentVariable.
+	BuildParams(ctx context.Context, file string, env []string) (string, error)
+	// Lint returns the diagnostics of the linter and the compiler for the file and the modules it references
+	Lint(ctx context.Context, file string) ([]Diagnostic, error)
 }
 
+// Diagnostic is a diagnostic reported by bicep for a file, ex) a warning of a linter rule
+type Diagnostic struct {
+	File   string
+	Line   int
+	Column int
+	// The level of the diagnostic, Error, Warning or Info
+	Level string
+	// The code of the diagnostic, the name of the linter rule for linter diagnostics, ex) no-unused-params
+	Code    string
+	Message string
+}
+
+// The diagnostics written by bicep to stderr, ex)
+// /infra/main.bicep(3,7) : Warning no-unused-params: Parameter "x" is declared but never used. [https://aka.ms/...]
+var diagnosticRegex = regexp.MustCompile(`^(.+)\((\d+),(\d+)\) : (Error|Warning|Info) ([^:\s]+): (.*?)(?: \[[^\]]+\])?$`)
+
 // NewBicepCli creates a new BicepCli. Azd manages its own copy of the bicep CLI, stored in `$AZD_CONFIG_DIR/bin`. If
 // bicep is not present at this location, or if it is present but is older than the minimum supported version, it is
 // downloaded.
@@ -41,7 +69,7 @@ func NewBicepCli(
 	console input.Console,
 	commandRunner exec.CommandRunner,
 ) (BicepCli, error) {
-	return newBicepCliWithTransporter(ctx, console, commandRunner, http.DefaultClient)
+	return newBicepCliWithTransporter(ctx, console, commandRunner, offline.NewTransporter(http.DefaultClient))
 }
 
 // newBicepCliWithTransporter is like NewBicepCli but allows providing a custom transport to use when downloading the
@@ -220,6 +248,12 @@ func downloadBicep(ctx context.Context, transporter policy.Transporter, bicepVer
 		return err
 	}
 
+	// Bicep does not publish a checksum manifest, the release can only be verified against a manifest provided through
+	// tools.ChecksumManifestEnvVarName.
+	if err := tools.NewDownloadVerifier(transporter).Verify(spanCtx, f.Name(), releaseName, ""); err != nil {
+		return err
+	}
+
 	if err := osutil.Rename(ctx, f.Name(), name); err != nil {
 		return err
 	}
@@ -256,6 +290,86 @@ func (cli *bicepCli) Build(ctx context.Context, file string) (string, error) {
 	return buildRes.Stdout, nil
 }
 
+func (cli *bicepCli) Decompile(ctx context.Context, file string) (string, error) {
+	args := []string{"decompile", file, "--stdout"}
+	decompileRes, err := cli.runCommand(ctx, args...)
+
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed running bicep decompile: %w",
+			err,
+		)
+	}
+
+	return decompileRes.Stdout, nil
+}
+
+func (cli *bicepCli) BuildParams(ctx context.Context, file string, env []string) (string, error) {
+	// the output holds the values of the secure parameters, it isn't written to the debug log
+	runArgs := exec.NewRunArgs(cli.path, "build-params", file, "--stdout").
+		WithEnv(env).
+		WithDebugLogging(false)
+	buildRes, err := cli.runner.Run(ctx, runArgs)
+
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed running bicep build-params: %w",
+			err,
+		)
+	}
+
+	// newer versions of bicep wrap the parameters file, along with the template it uses, in the output
+	var wrapped struct {
+		ParametersJson string `json:"parametersJson"`
+	}
+	if err := json.Unmarshal([]byte(buildRes.Stdout), &wrapped); err == nil && wrapped.ParametersJson != "" {
+		return wrapped.ParametersJson, nil
+	}
+
+	return buildRes.Stdout, nil
+}
+
+func (cli *bicepCli) Lint(ctx context.Context, file string) ([]Diagnostic, error) {
+	// bicep lint requires newer versions of bicep than the minimum version, build reports the same diagnostics
+	lintRes, err := cli.runCommand(ctx, "build", file, "--stdout")
+	diagnostics := parseDiagnostics(lintRes.Stderr)
+
+	// bicep exits unsuccessfully when the file has errors, which are diagnostics of the file
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || len(diagnostics) == 0) {
+		return nil, fmt.Errorf(
+			"failed running bicep build: %w",
+			err,
+		)
+	}
+
+	return diagnostics, nil
+}
+
+// parseDiagnostics parses the diagnostics of the output of bicep, ignoring the other lines
+func parseDiagnostics(output string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, line := range strings.Split(output, "\n") {
+		match := diagnosticRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+
+		lineNumber, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    match[1],
+			Line:    lineNumber,
+			Column:  column,
+			Level:   match[4],
+			Code:    match[5],
+			Message: match[6],
+		})
+	}
+
+	return diagnostics
+}
+
 func (cli *bicepCli) runCommand(ctx context.Context, args ...string) (exec.RunResult, error) {
 	runArgs := exec.NewRunArgs(cli.path, args...)
 	return cli.runner.Run(ctx, runArgs)
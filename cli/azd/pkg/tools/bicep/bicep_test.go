@@ -137,3 +137,31 @@ func TestNewBicepCliWillUpgrade(t *testing.T) {
 
 	require.Equal(t, []byte(NEW_FILE_CONTENTS), contents)
 }
+
+func TestParseDiagnostics(t *testing.T) {
+	output := strings.Join([]string{
+		"/src/infra/main.bicep(3,7) : Warning no-unused-params: Parameter \"x\" is declared but never used. " +
+			"[https://aka.ms/bicep/linter/no-unused-params]",
+		"C:\\src\\infra\\app\\api.bicep(12,3) : Error BCP037: The property \"sku\" is not allowed on objects of type \"x\".\r",
+		"Build succeeded.",
+	}, "\n")
+
+	require.Equal(t, []Diagnostic{
+		{
+			File:    "/src/infra/main.bicep",
+			Line:    3,
+			Column:  7,
+			Level:   "Warning",
+			Code:    "no-unused-params",
+			Message: "Parameter \"x\" is declared but never used.",
+		},
+		{
+			File:    "C:\\src\\infra\\app\\api.bicep",
+			Line:    12,
+			Column:  3,
+			Level:   "Error",
+			Code:    "BCP037",
+			Message: "The property \"sku\" is not allowed on objects of type \"x\".",
+		},
+	}, parseDiagnostics(output))
+}
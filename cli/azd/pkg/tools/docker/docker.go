@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"regexp"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
 )
@@ -29,6 +31,8 @@ type Docker interface {
 	) (string, error)
 	Tag(ctx context.Context, cwd string, imageName string, tag string) error
 	Push(ctx context.Context, cwd string, tag string) error
+	Pull(ctx context.Context, imageName string) error
+	RepoDigest(ctx context.Context, cwd string, tag string) (string, error)
 }
 
 func NewDocker(commandRunner exec.CommandRunner) Docker {
@@ -42,6 +46,8 @@ type docker struct {
 }
 
 func (d *docker) Login(ctx context.Context, loginServer string, username string, password string) error {
+	redact.AddSecret(password)
+
 	runArgs := exec.NewRunArgs(
 		"docker", "login",
 		"--username", username,
@@ -116,6 +122,43 @@ func (d *docker) Push(ctx context.Context, cwd string, tag string) error {
 	return nil
 }
 
+// Pulls the image from its registry, for the platform of the images built by azd
+func (d *docker) Pull(ctx context.Context, imageName string) error {
+	_, err := d.executeCommand(ctx, "", "pull", "--platform", DefaultPlatform, imageName)
+	if err != nil {
+		return fmt.Errorf("pulling image: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the reference by digest, ex) <registry>/<name>@sha256:<digest>, of the image pushed with the tag
+func (d *docker) RepoDigest(ctx context.Context, cwd string, tag string) (string, error) {
+	res, err := d.executeCommand(ctx, cwd, "image", "inspect", "--format", "{{json .RepoDigests}}", tag)
+	if err != nil {
+		return "", fmt.Errorf("inspecting image: %w", err)
+	}
+
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(res.Stdout)), &repoDigests); err != nil {
+		return "", fmt.Errorf("inspecting image: %w", err)
+	}
+
+	// The tag may have a port, ex) localhost:5000/api:1.0, only a colon after the last slash starts the tag
+	repository := tag
+	if i := strings.LastIndex(tag, ":"); i > strings.LastIndex(tag, "/") {
+		repository = tag[:i]
+	}
+
+	for _, repoDigest := range repoDigests {
+		if strings.HasPrefix(repoDigest, repository+"@") {
+			return repoDigest, nil
+		}
+	}
+
+	return "", fmt.Errorf("image %s wasn't pushed to %s", tag, repository)
+}
+
 func (d *docker) versionInfo() tools.VersionInfo {
 	return tools.VersionInfo{
 		MinimumVersion: semver.Version{
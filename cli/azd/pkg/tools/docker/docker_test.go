@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -382,6 +383,28 @@ func Test_DockerPush(t *testing.T) {
 	})
 }
 
+func Test_DockerRepoDigest(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{
+			"image", "inspect", "--format", "{{json .RepoDigests}}", args.Args[len(args.Args)-1],
+		}, args.Args)
+
+		return exec.NewRunResult(0, `["other.azurecr.io/app/api@sha256:1","registry.azurecr.io/app/api@sha256:2"]`, ""), nil
+	})
+
+	repoDigest, err := docker.RepoDigest(context.Background(), ".", "registry.azurecr.io/app/api:azd-deploy-1")
+	require.NoError(t, err)
+	require.Equal(t, "registry.azurecr.io/app/api@sha256:2", repoDigest)
+
+	_, err = docker.RepoDigest(context.Background(), ".", "localhost:5000/app/api:1.0")
+	require.ErrorContains(t, err, "wasn't pushed to localhost:5000/app/api")
+}
+
 func Test_DockerLogin(t *testing.T) {
 	t.Run("NoError", func(t *testing.T) {
 		ran := false
@@ -413,6 +436,8 @@ func Test_DockerLogin(t *testing.T) {
 
 		require.Equal(t, true, ran)
 		require.Nil(t, err)
+		// the password is masked in the console and debug output from then on
+		require.Equal(t, "password: <redacted>", redact.String("password: PASSWORD"))
 	})
 
 	t.Run("WithError", func(t *testing.T) {
@@ -20,6 +20,8 @@ type DotNetCli interface {
 	Restore(ctx context.Context, project string) error
 	Build(ctx context.Context, project string, configuration string, output string) error
 	Publish(ctx context.Context, project string, configuration string, output string) error
+	PublishContainer(ctx context.Context, project string, configuration string, imageName string, tag string) error
+	PublishAppHostManifest(ctx context.Context, hostProject string, manifestPath string) error
 	InitializeSecret(ctx context.Context, project string) error
 	SetSecrets(ctx context.Context, secrets map[string]string, project string) error
 }
@@ -110,6 +112,48 @@ func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration
 	return nil
 }
 
+// PublishContainer publishes the project as a container image of the local docker daemon, using the container support of
+// the .NET SDK. The project doesn't need a Dockerfile.
+func (cli *dotNetCli) PublishContainer(
+	ctx context.Context,
+	project string,
+	configuration string,
+	imageName string,
+	tag string,
+) error {
+	runArgs := exec.NewRunArgs("dotnet", "publish", project,
+		"-r", "linux-x64",
+		"-p:PublishProfile=DefaultContainer",
+		fmt.Sprintf("-p:ContainerRepository=%s", imageName),
+		fmt.Sprintf("-p:ContainerImageTag=%s", tag),
+	)
+	if configuration != "" {
+		runArgs = runArgs.AppendParams("-c", configuration)
+	}
+
+	_, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("dotnet publish of container image of project '%s' failed: %w", project, err)
+	}
+	return nil
+}
+
+// PublishAppHostManifest runs the .NET Aspire app host project with the manifest publisher, which writes the manifest of
+// the application model of the app host to manifestPath
+func (cli *dotNetCli) PublishAppHostManifest(ctx context.Context, hostProject string, manifestPath string) error {
+	runArgs := exec.NewRunArgs("dotnet", "run",
+		"--project", hostProject,
+		"--publisher", "manifest",
+		"--output-path", manifestPath,
+	)
+
+	_, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("generating manifest of app host '%s' failed: %w", hostProject, err)
+	}
+	return nil
+}
+
 func (cli *dotNetCli) InitializeSecret(ctx context.Context, project string) error {
 	runArgs := exec.NewRunArgs("dotnet", "user-secrets", "init", "--project", project)
 	_, err := cli.commandRunner.Run(ctx, runArgs)
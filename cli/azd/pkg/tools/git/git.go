@@ -26,6 +26,11 @@ type GitCli interface {
 	AddRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	UpdateRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	GetCurrentBranch(ctx context.Context, repositoryPath string) (string, error)
+	// abbreviated hash of the commit checked out in the repository
+	GetHeadCommit(ctx context.Context, repositoryPath string) (string, error)
+	// most recent tag reachable from the commit checked out, suffixed with the number of commits on top of it and the
+	// abbreviated hash of the commit. The abbreviated hash is returned when there are no tags.
+	Describe(ctx context.Context, repositoryPath string) (string, error)
 	AddFile(ctx context.Context, repositoryPath string, filespec string) error
 	Commit(ctx context.Context, repositoryPath string, message string) error
 	PushUpstream(ctx context.Context, repositoryPath string, origin string, branch string) error
@@ -137,6 +142,30 @@ func (cli *gitCli) GetCurrentBranch(ctx context.Context, repositoryPath string)
 	return strings.TrimSpace(res.Stdout), nil
 }
 
+func (cli *gitCli) GetHeadCommit(ctx context.Context, repositoryPath string) (string, error) {
+	runArgs := newRunArgs("-C", repositoryPath, "rev-parse", "--short", "HEAD")
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return "", ErrNotRepository
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get head commit: %w", err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+func (cli *gitCli) Describe(ctx context.Context, repositoryPath string) (string, error) {
+	runArgs := newRunArgs("-C", repositoryPath, "describe", "--tags", "--always")
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return "", ErrNotRepository
+	} else if err != nil {
+		return "", fmt.Errorf("failed to describe head commit: %w", err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
 func (cli *gitCli) InitRepo(ctx context.Context, repositoryPath string) error {
 	runArgs := newRunArgs("-C", repositoryPath, "init")
 	_, err := cli.commandRunner.Run(ctx, runArgs)
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/internal/offline"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
@@ -47,7 +48,8 @@ type GitHubCli interface {
 }
 
 func NewGitHubCli(ctx context.Context, console input.Console, commandRunner exec.CommandRunner) (GitHubCli, error) {
-	return newGitHubCliImplementation(ctx, console, commandRunner, http.DefaultClient, downloadGh, extractGhCli)
+	return newGitHubCliImplementation(
+		ctx, console, commandRunner, offline.NewTransporter(http.DefaultClient), downloadGh, extractGhCli)
 }
 
 // GitHubCliVersion is the minimum version of GitHub cli that we require (and the one we fetch when we fetch bicep on
@@ -514,30 +516,37 @@ type getGitHubCliImplementation func(
 // extractGitHubCliFromFileImplementation defines how the cli is extracted
 type extractGitHubCliFromFileImplementation func(src, dst string) (string, error)
 
-// downloadGh downloads a given version of GitHub cli from the release site.
-func downloadGh(
-	ctx context.Context,
-	transporter policy.Transporter,
-	ghVersion semver.Version,
-	extractImplementation extractGitHubCliFromFileImplementation,
-	path string) error {
-
+// ghReleaseName returns the name of the GitHub cli release archive for the current platform.
+func ghReleaseName(ghVersion semver.Version) (string, error) {
 	binaryName := func(platform string) string {
 		return fmt.Sprintf("gh_%s_%s", ghVersion, platform)
 	}
 
 	systemArch := runtime.GOARCH
 	// arm and x86 not supported (similar to bicep)
-	var releaseName string
 	switch runtime.GOOS {
 	case "windows":
-		releaseName = binaryName(fmt.Sprintf("windows_%s.zip", systemArch))
+		return binaryName(fmt.Sprintf("windows_%s.zip", systemArch)), nil
 	case "darwin":
-		releaseName = binaryName(fmt.Sprintf("macOS_%s.zip", systemArch))
+		return binaryName(fmt.Sprintf("macOS_%s.zip", systemArch)), nil
 	case "linux":
-		releaseName = binaryName(fmt.Sprintf("linux_%s.tar.gz", systemArch))
+		return binaryName(fmt.Sprintf("linux_%s.tar.gz", systemArch)), nil
 	default:
-		return fmt.Errorf("unsupported platform")
+		return "", fmt.Errorf("unsupported platform")
+	}
+}
+
+// downloadGh downloads a given version of GitHub cli from the release site.
+func downloadGh(
+	ctx context.Context,
+	transporter policy.Transporter,
+	ghVersion semver.Version,
+	extractImplementation extractGitHubCliFromFileImplementation,
+	path string) error {
+
+	releaseName, err := ghReleaseName(ghVersion)
+	if err != nil {
+		return err
 	}
 
 	// example: https://github.com/cli/cli/releases/download/v2.28.0/gh_2.28.0_linux_arm64.rpm
@@ -589,6 +598,13 @@ func downloadGh(
 		_ = os.Remove(compressedFileName)
 	}()
 
+	checksumsUrl := fmt.Sprintf(
+		"https://github.com/cli/cli/releases/download/v%s/gh_%s_checksums.txt", ghVersion, ghVersion)
+	if err := tools.NewDownloadVerifier(transporter).Verify(
+		spanCtx, compressedFileName, releaseName, checksumsUrl); err != nil {
+		return err
+	}
+
 	// unzip downloaded file
 	log.Printf("extracting file %s", compressedFileName)
 	_, err = extractImplementation(compressedFileName, tmpPath)
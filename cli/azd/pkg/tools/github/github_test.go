@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
 	"github.com/blang/semver/v4"
@@ -91,6 +93,7 @@ func TestNewGitHubCli(t *testing.T) {
 		StatusCode: http.StatusOK,
 		Body:       io.NopCloser(bytes.NewBufferString("this is github cli")),
 	})
+	mockGhChecksums(t, mockContext, "this is github cli")
 
 	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 		return strings.Contains(args.Cmd, "gh") && len(args.Args) == 1 && args.Args[0] == "--version"
@@ -150,6 +153,7 @@ func TestNewGitHubCliUpdate(t *testing.T) {
 		StatusCode: http.StatusOK,
 		Body:       io.NopCloser(bytes.NewBufferString("this is github cli")),
 	})
+	mockGhChecksums(t, mockContext, "this is github cli")
 
 	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 		return strings.Contains(args.Cmd, "gh") && len(args.Args) == 1 && args.Args[0] == "--version"
@@ -193,6 +197,56 @@ func TestNewGitHubCliUpdate(t *testing.T) {
 	require.Equal(t, []byte("this is github cli"), contents)
 }
 
+func TestNewGitHubCliChecksumMismatch(t *testing.T) {
+	configRoot := t.TempDir()
+	t.Setenv("AZD_CONFIG_DIR", configRoot)
+
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "github.com"
+	}).Respond(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString("this is not github cli")),
+	})
+	mockGhChecksums(t, mockContext, "this is github cli")
+
+	extracted := false
+	mockExtract := func(src, dst string) (string, error) {
+		extracted = true
+		return src, nil
+	}
+
+	_, err := newGitHubCliImplementation(
+		*mockContext.Context,
+		mockContext.Console,
+		mockContext.CommandRunner,
+		mockContext.HttpClient,
+		downloadGh,
+		mockExtract,
+	)
+	require.ErrorIs(t, err, tools.ErrUnverifiedDownload)
+	require.False(t, extracted)
+}
+
+// mockGhChecksums responds to requests for the checksums of the GitHub cli release with the checksum of releaseContent
+func mockGhChecksums(t *testing.T, mockContext *mocks.MockContext, releaseContent string) {
+	releaseName, err := ghReleaseName(GitHubCliVersion)
+	require.NoError(t, err)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "github.com" &&
+			strings.HasSuffix(request.URL.Path, "_checksums.txt")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		checksums := fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte(releaseContent)), releaseName)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(checksums)),
+		}, nil
+	})
+}
+
 func createSampleZip(path, content, file string) (string, error) {
 	filePath := filepath.Join(path, "zippedFile.zip")
 	zipFile, err := os.Create(filePath)
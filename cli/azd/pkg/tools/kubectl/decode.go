@@ -0,0 +1,262 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Object is a resource as returned by kubectl, with all its fields. Resources of custom resource definitions, and
+// fields azd doesn't model, are read from objects.
+type Object map[string]any
+
+// ApiVersion returns the apiVersion of the resource, ex) networking.k8s.io/v1
+func (o Object) ApiVersion() string {
+	value, _ := o.NestedString("apiVersion")
+	return value
+}
+
+// Kind returns the kind of the resource, ex) Ingress
+func (o Object) Kind() string {
+	value, _ := o.NestedString("kind")
+	return value
+}
+
+// NestedField returns the field at the path of keys, ex) "spec", "replicas", and whether the resource has it
+func (o Object) NestedField(path ...string) (any, bool) {
+	var value any = map[string]any(o)
+	for _, key := range path {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = fields[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// NestedString returns the string field at the path of keys, and whether the resource has it
+func (o Object) NestedString(path ...string) (string, bool) {
+	value, ok := o.NestedField(path...)
+	if !ok {
+		return "", false
+	}
+
+	text, ok := value.(string)
+	return text, ok
+}
+
+// Resources implementing objectSetter keep the object they are decoded from, ex) through the embedded Resource
+type objectSetter interface {
+	setObject(object Object)
+}
+
+func (r *Resource) setObject(object Object) {
+	r.Object = object
+}
+
+// DecodeResource decodes the output of `kubectl get <type> <name>` into the resource model. The output of the previous
+// apiVersions of the resource is converted to the shape of its current apiVersion, fields unknown to the model are
+// ignored, and the full resource is kept in the Object of the model.
+func DecodeResource[T any](data []byte, format OutputType) (T, error) {
+	var resource T
+
+	object, err := decodeObject(data, format)
+	if err != nil {
+		return resource, err
+	}
+
+	if err := decodeModel(object, &resource); err != nil {
+		return resource, err
+	}
+
+	return resource, nil
+}
+
+// DecodeResources decodes the output of `kubectl get <type>` into a list of the resource model. Each item is decoded
+// like DecodeResource does.
+func DecodeResources[T any](data []byte, format OutputType) (*List[T], error) {
+	object, err := decodeObject(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &List[T]{
+		Items: []T{},
+	}
+	list.ApiVersion = object.ApiVersion()
+	list.Kind = object.Kind()
+	list.Object = object
+
+	items, _ := object.NestedField("items")
+	values, ok := items.([]any)
+	if items != nil && !ok {
+		return nil, fmt.Errorf("failed decoding resources, 'items' is not a list")
+	}
+
+	for i, value := range values {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed decoding resources, item %d is not an object", i)
+		}
+
+		var item T
+		if err := decodeModel(Object(fields), &item); err != nil {
+			return nil, fmt.Errorf("failed decoding resources, item %d: %w", i, err)
+		}
+
+		list.Items = append(list.Items, item)
+	}
+
+	return list, nil
+}
+
+func decodeObject(data []byte, format OutputType) (Object, error) {
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, fmt.Errorf("failed decoding resources, kubectl returned no output")
+	}
+
+	var object Object
+
+	switch format {
+	case OutputTypeJson:
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, fmt.Errorf("failed unmarshalling resources JSON, %w", err)
+		}
+	case OutputTypeYaml:
+		var fields map[string]any
+		if err := yaml.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed unmarshalling resources YAML, %w", err)
+		}
+
+		// Objects hold the same types whatever the output format, ex) numbers are float64
+		jsonData, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed unmarshalling resources YAML, %w", err)
+		}
+
+		if err := json.Unmarshal(jsonData, &object); err != nil {
+			return nil, fmt.Errorf("failed unmarshalling resources YAML, %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed unmarshalling resources. Output format '%s' is not supported", format)
+	}
+
+	return object, nil
+}
+
+// decodeModel decodes the object into the model, through a copy converted to the current apiVersion of its kind
+func decodeModel[T any](object Object, model *T) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	var current map[string]any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+
+	if convert, has := conversions[strings.ToLower(object.Kind())]; has {
+		convert(object.ApiVersion(), current)
+	}
+
+	if data, err = json.Marshal(current); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, model); err != nil {
+		return fmt.Errorf("failed unmarshalling %s '%s', %w", object.Kind(), objectName(object), err)
+	}
+
+	// Models are either structs embedding a Resource, ex) Deployment, or pointers to them, ex) *Deployment
+	if setter, ok := any(model).(objectSetter); ok {
+		setter.setObject(object)
+	} else if setter, ok := any(*model).(objectSetter); ok {
+		setter.setObject(object)
+	}
+
+	return nil
+}
+
+func objectName(object Object) string {
+	name, _ := object.NestedString("metadata", "name")
+	return name
+}
+
+// conversions convert, by kind, the fields of the previous apiVersions of resources to their current apiVersion
+var conversions = map[string]func(apiVersion string, fields map[string]any){
+	"ingress": convertIngress,
+}
+
+// convertIngress converts extensions/v1beta1 and networking.k8s.io/v1beta1 ingresses, served by clusters before 1.22,
+// to networking.k8s.io/v1: spec.backend is spec.defaultBackend, and backends reference services by name and port.
+func convertIngress(apiVersion string, fields map[string]any) {
+	if !strings.HasSuffix(apiVersion, "/v1beta1") {
+		return
+	}
+
+	spec, ok := fields["spec"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	if backend, has := spec["backend"].(map[string]any); has {
+		spec["defaultBackend"] = convertIngressBackend(backend)
+		delete(spec, "backend")
+	}
+
+	rules, _ := spec["rules"].([]any)
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		http, ok := rule["http"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		paths, _ := http["paths"].([]any)
+		for _, path := range paths {
+			path, ok := path.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			if backend, has := path["backend"].(map[string]any); has {
+				path["backend"] = convertIngressBackend(backend)
+			}
+		}
+	}
+}
+
+func convertIngressBackend(backend map[string]any) map[string]any {
+	serviceName, has := backend["serviceName"]
+	if !has {
+		return backend
+	}
+
+	port := map[string]any{}
+	switch servicePort := backend["servicePort"].(type) {
+	case float64:
+		port["number"] = servicePort
+	case string:
+		port["name"] = servicePort
+	}
+
+	return map[string]any{
+		"service": map[string]any{
+			"name": serviceName,
+			"port": port,
+		},
+	}
+}
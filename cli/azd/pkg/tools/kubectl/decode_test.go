@@ -0,0 +1,131 @@
+package kubectl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DecodeResources_Deployment(t *testing.T) {
+	tests := map[string]struct {
+		file              string
+		replicas          int
+		availableReplicas int
+	}{
+		"1.24": {file: "deployments-1.24.json", replicas: 2, availableReplicas: 2},
+		"1.30": {file: "deployments-1.30.json", replicas: 3, availableReplicas: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile("../../../test/testdata/k8s/" + test.file)
+			require.NoError(t, err)
+
+			deployments, err := DecodeResources[*Deployment](data, OutputTypeJson)
+			require.NoError(t, err)
+			require.Len(t, deployments.Items, 1)
+
+			deployment := deployments.Items[0]
+			require.Equal(t, "todo-api", deployment.Metadata.Name)
+			require.Equal(t, "todo", deployment.Metadata.Namespace)
+			require.Equal(t, test.replicas, deployment.Spec.Replicas)
+			require.Equal(t, test.availableReplicas, deployment.Status.AvailableReplicas)
+
+			// Fields the model doesn't have are kept
+			strategy, has := deployment.Object.NestedString("spec", "strategy", "type")
+			require.True(t, has)
+			require.Equal(t, "RollingUpdate", strategy)
+			require.Equal(t, "apps/v1", deployment.Object.ApiVersion())
+		})
+	}
+}
+
+func Test_DecodeResources_Ingress(t *testing.T) {
+	t.Run("v1", func(t *testing.T) {
+		data, err := os.ReadFile("../../../test/testdata/k8s/ingress.json")
+		require.NoError(t, err)
+
+		ingresses, err := DecodeResources[Ingress](data, OutputTypeJson)
+		require.NoError(t, err)
+
+		backend := ingresses.Items[0].Spec.Rules[0].Http.Paths[0].Backend
+		require.Equal(t, "ratings-web", backend.Service.Name)
+		require.Equal(t, 80, backend.Service.Port.Number)
+	})
+
+	t.Run("v1beta1", func(t *testing.T) {
+		data, err := os.ReadFile("../../../test/testdata/k8s/ingress-v1beta1.json")
+		require.NoError(t, err)
+
+		ingresses, err := DecodeResources[Ingress](data, OutputTypeJson)
+		require.NoError(t, err)
+
+		ingress := ingresses.Items[0]
+		require.Equal(t, "extensions/v1beta1", ingress.ApiVersion)
+		require.Equal(t, "todo.contoso.com", *ingress.Spec.Rules[0].Host)
+		require.Equal(t, "20.62.1.10", ingress.Status.LoadBalancer.Ingress[0].Address())
+
+		backend := ingress.Spec.Rules[0].Http.Paths[0].Backend
+		require.Equal(t, &IngressServiceBackend{Name: "todo-api", Port: IngressServicePort{Number: 80}}, backend.Service)
+		require.Equal(t, &IngressBackend{
+			Service: &IngressServiceBackend{Name: "todo-web", Port: IngressServicePort{Name: "http"}},
+		}, ingress.Spec.DefaultBackend)
+
+		// The object is kept as returned by kubectl
+		serviceName, has := ingress.Object.NestedString("spec", "backend", "serviceName")
+		require.True(t, has)
+		require.Equal(t, "todo-web", serviceName)
+	})
+}
+
+func Test_DecodeResources_CustomResource(t *testing.T) {
+	data, err := os.ReadFile("../../../test/testdata/k8s/httproutes.yaml")
+	require.NoError(t, err)
+
+	routes, err := DecodeResources[*HttpRoute](data, OutputTypeYaml)
+	require.NoError(t, err)
+	require.Equal(t, []string{"todo.contoso.com"}, routes.Items[0].Spec.Hostnames)
+	require.Equal(t, "/api", routes.Items[0].Spec.Rules[0].Matches[0].Path.Value)
+
+	// Resources of any kind can be read as objects
+	objects, err := DecodeResources[Object](data, OutputTypeYaml)
+	require.NoError(t, err)
+	require.Equal(t, "HTTPRoute", objects.Items[0].Kind())
+
+	controller, has := objects.Items[0].NestedField("status", "parents")
+	require.True(t, has)
+	require.Len(t, controller, 1)
+}
+
+func Test_DecodeResource(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		service, err := DecodeResource[Service]([]byte(`{
+			"apiVersion": "v1",
+			"kind": "Service",
+			"metadata": {"name": "todo-api", "namespace": "todo"},
+			"spec": {"type": "ClusterIP", "ports": [{"port": 80, "targetPort": 3100}], "ipFamilyPolicy": "SingleStack"}
+		}`), OutputTypeJson)
+		require.NoError(t, err)
+		require.Equal(t, ServiceTypeClusterIp, service.Spec.Type)
+		require.Equal(t, "todo-api", service.Metadata.Name)
+
+		policy, _ := service.Object.NestedString("spec", "ipFamilyPolicy")
+		require.Equal(t, "SingleStack", policy)
+	})
+
+	t.Run("NoOutput", func(t *testing.T) {
+		_, err := DecodeResource[Service]([]byte("  \n"), OutputTypeJson)
+		require.ErrorContains(t, err, "kubectl returned no output")
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		_, err := DecodeResource[Service]([]byte("{}"), OutputType("wide"))
+		require.ErrorContains(t, err, "Output format 'wide' is not supported")
+	})
+
+	t.Run("InvalidItem", func(t *testing.T) {
+		_, err := DecodeResources[Service]([]byte(`{"kind": "List", "items": ["todo-api"]}`), OutputTypeJson)
+		require.ErrorContains(t, err, "item 0 is not an object")
+	})
+}
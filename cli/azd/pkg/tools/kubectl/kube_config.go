@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"gopkg.in/yaml.v3"
 )
 
@@ -37,9 +38,32 @@ func ParseKubeConfig(ctx context.Context, raw []byte) (*KubeConfig, error) {
 		return nil, fmt.Errorf("failed unmarshalling Kube Config YAML: %w", err)
 	}
 
+	// the credentials of the users are redacted from the console and logs
+	for _, user := range existing.Users {
+		for _, value := range []string{user.KubeUserData.ClientKeyData, user.KubeUserData.Token, user.KubeUserData.Password} {
+			if value != "" {
+				redact.AddSecret(value)
+			}
+		}
+	}
+
 	return &existing, nil
 }
 
+// ExecPluginUsers returns the users of the config getting their credentials with the exec plugin command,
+// ex) kubelogin
+func (c *KubeConfig) ExecPluginUsers(command string) []*KubeUser {
+	users := []*KubeUser{}
+	for _, user := range c.Users {
+		exec := user.KubeUserData.Exec
+		if exec != nil && strings.TrimSuffix(filepath.Base(exec.Command), ".exe") == command {
+			users = append(users, user)
+		}
+	}
+
+	return users
+}
+
 // Saves the KubeConfig to the kube configuration folder with the specified name
 func (kcm *KubeConfigManager) SaveKubeConfig(ctx context.Context, configName string, config *KubeConfig) error {
 	kubeConfigRaw, err := yaml.Marshal(config)
@@ -61,6 +85,11 @@ func (kcm *KubeConfigManager) SaveKubeConfig(ctx context.Context, configName str
 	return nil
 }
 
+// Returns the path of the KubeConfig with the specified name
+func (kcm *KubeConfigManager) ConfigPath(configName string) string {
+	return filepath.Join(kcm.configPath, configName)
+}
+
 // Deletes the KubeConfig with the specified name
 func (kcm *KubeConfigManager) DeleteKubeConfig(ctx context.Context, configName string) error {
 	kubeConfigPath := filepath.Join(kcm.configPath, configName)
@@ -6,8 +6,10 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/redact"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func Test_MergeKubeConfig(t *testing.T) {
@@ -41,6 +43,95 @@ func Test_MergeKubeConfig(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_ParseKubeConfig(t *testing.T) {
+	raw := `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster1
+  cluster:
+    server: https://cluster1.eastus2.azmk8s.io:443
+users:
+- name: clusterAdmin_cluster1
+  user:
+    client-certificate-data: CLIENT_CERTIFICATE_DATA
+    client-key-data: CLIENT_KEY_DATA
+    token: KUBE_USER_TOKEN
+`
+
+	kubeConfig, err := ParseKubeConfig(context.Background(), []byte(raw))
+	require.NoError(t, err)
+	require.Len(t, kubeConfig.Users, 1)
+	require.Equal(t, "https://cluster1.eastus2.azmk8s.io:443", kubeConfig.Clusters[0].Cluster.Server)
+
+	// the credentials of the users are masked in the console and debug output
+	require.Equal(t,
+		"CLIENT_CERTIFICATE_DATA <redacted> <redacted>",
+		redact.String("CLIENT_CERTIFICATE_DATA CLIENT_KEY_DATA KUBE_USER_TOKEN"))
+}
+
+func Test_KubeConfig_RoundTrip(t *testing.T) {
+	raw := `apiVersion: v1
+clusters:
+    - name: cluster1
+      cluster:
+        certificate-authority-data: CERTIFICATE_AUTHORITY_DATA
+        server: https://cluster1.eastus2.azmk8s.io:443
+        proxy-url: http://proxy.contoso.com:3128
+contexts:
+    - name: cluster1
+      context:
+        cluster: cluster1
+        user: clusterUser_cluster1
+        namespace: todo
+users:
+    - name: clusterUser_cluster1
+      user:
+        exec:
+            apiVersion: client.authentication.k8s.io/v1beta1
+            command: kubelogin
+            args:
+                - get-token
+                - --login
+                - devicecode
+            env:
+                - name: AAD_LOGIN_METHOD
+                  value: devicecode
+            installHint: install kubelogin
+            provideClusterInfo: true
+            interactiveMode: IfAvailable
+    - name: clusterAdmin_cluster1
+      user:
+        client-certificate-data: CLIENT_CERTIFICATE_DATA
+        client-key-data: CLIENT_KEY_DATA
+        token: KUBE_ADMIN_TOKEN
+        auth-provider:
+            name: oidc
+kind: Config
+current-context: cluster1
+preferences: {}
+extensions:
+    - name: contoso
+`
+
+	kubeConfig, err := ParseKubeConfig(context.Background(), []byte(raw))
+	require.NoError(t, err)
+
+	// The users getting their tokens with kubelogin are found
+	users := kubeConfig.ExecPluginUsers("kubelogin")
+	require.Len(t, users, 1)
+	require.Equal(t, "clusterUser_cluster1", users[0].Name)
+	require.Equal(t, "KUBE_ADMIN_TOKEN", kubeConfig.Users[1].KubeUserData.Token)
+
+	// Nothing is lost when the config is saved
+	saved, err := yaml.Marshal(kubeConfig)
+	require.NoError(t, err)
+
+	var expected, actual map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(raw), &expected))
+	require.NoError(t, yaml.Unmarshal(saved, &actual))
+	require.Equal(t, expected, actual)
+}
+
 func createTestCluster(clusterName, username string) *KubeConfig {
 	return &KubeConfig{
 		ApiVersion:     "v1",
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/interpolate"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
-	"github.com/drone/envsubst"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 )
 
 // Executes commands against the Kubernetes CLI
@@ -21,8 +26,8 @@ type KubectlCli interface {
 	Cwd(cwd string)
 	// Sets the env vars available to the CLI
 	SetEnv(env map[string]string)
-	// Applies one or more files from the specified path
-	Apply(ctx context.Context, path string, flags *KubeCliFlags) error
+	// Applies one or more files from the specified path of the file system
+	Apply(ctx context.Context, fs vfs.Fs, path string, flags *KubeCliFlags) error
 	// Applies manifests from the specified input
 	ApplyWithInput(ctx context.Context, input string, flags *KubeCliFlags) (*exec.RunResult, error)
 	// Views the current k8s configuration including available clusters, contexts & users
@@ -42,6 +47,40 @@ type KubectlCli interface {
 	Exec(ctx context.Context, flags *KubeCliFlags, args ...string) (exec.RunResult, error)
 	// Gets the deployment rollout status
 	RolloutStatus(ctx context.Context, deploymentName string, flags *KubeCliFlags) (*exec.RunResult, error)
+	// Restarts the pods of the workload, ex) deployment/api, with a rolling update and waits for the rollout
+	RolloutRestart(ctx context.Context, workload string, flags *KubeCliFlags) error
+	// Rolls the workload, ex) deployment/api, back to its previous revision
+	RolloutUndo(ctx context.Context, workload string, flags *KubeCliFlags) error
+	// Sets the number of replicas of the workload, ex) deployment/api
+	Scale(ctx context.Context, workload string, replicas int, flags *KubeCliFlags) error
+	// Runs a command in a container of the workload, ex) deployment/api, attached to the console
+	ExecInContainer(ctx context.Context, workload string, command []string, tty bool, flags *KubeCliFlags) error
+	// Writes the logs of the containers of the workload, ex) deployment/api, to the writer
+	Logs(ctx context.Context, workload string, options LogsOptions, writer io.Writer, flags *KubeCliFlags) error
+	// Forwards a local port to the port of the resource, ex) svc/api, until the context is cancelled. A free local port
+	// is chosen when localPort is zero. ready is called with the local port once it is forwarded.
+	PortForward(
+		ctx context.Context,
+		resource string,
+		localPort int,
+		remotePort int,
+		ready func(localPort int),
+		flags *KubeCliFlags,
+	) error
+	// Gets the version of the Kubernetes API server of the cluster, ex) v1.27.3
+	GetServerVersion(ctx context.Context) (string, error)
+	// Gets the API versions served by the cluster, ex) networking.k8s.io/v1
+	GetApiVersions(ctx context.Context) ([]string, error)
+	// Gets the kinds of the resources served by the cluster in the API version, ex) Ingress for networking.k8s.io/v1
+	GetApiKinds(ctx context.Context, apiVersion string) ([]string, error)
+}
+
+// Options of the logs written by kubectl logs
+type LogsOptions struct {
+	// Only the logs newer than the duration are written, all logs when zero
+	Since time.Duration
+	// Whether the logs are streamed until the command is cancelled
+	Follow bool
 }
 
 type OutputType string
@@ -122,6 +161,71 @@ func (cli *kubectlCli) getClientVersion(ctx context.Context) (string, error) {
 	return versionObj.ClientVersion.GitVersion, nil
 }
 
+// Gets the version of the Kubernetes API server of the cluster, ex) v1.27.3
+func (cli *kubectlCli) GetServerVersion(ctx context.Context) (string, error) {
+	versionRes, err := cli.Exec(ctx, &KubeCliFlags{Output: OutputTypeJson}, "version")
+	if err != nil {
+		return "", fmt.Errorf("fetching kubernetes server version: %w", err)
+	}
+
+	var versionObj struct {
+		ServerVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+
+	if err := json.Unmarshal([]byte(versionRes.Stdout), &versionObj); err != nil {
+		return "", fmt.Errorf("parsing kubectl version output: %w", err)
+	}
+
+	return versionObj.ServerVersion.GitVersion, nil
+}
+
+// Gets the API versions served by the cluster, ex) networking.k8s.io/v1
+func (cli *kubectlCli) GetApiVersions(ctx context.Context) ([]string, error) {
+	res, err := cli.Exec(ctx, nil, "api-versions")
+	if err != nil {
+		return nil, fmt.Errorf("fetching kubernetes api versions: %w", err)
+	}
+
+	return strings.Fields(res.Stdout), nil
+}
+
+// Gets the kinds of the resources served by the cluster in the API version, ex) Ingress for networking.k8s.io/v1
+func (cli *kubectlCli) GetApiKinds(ctx context.Context, apiVersion string) ([]string, error) {
+	// The core API group is served at /api, the others at /apis
+	apiPath := "/apis/" + apiVersion
+	if !strings.Contains(apiVersion, "/") {
+		apiPath = "/api/" + apiVersion
+	}
+
+	res, err := cli.Exec(ctx, nil, "get", "--raw", apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("fetching kubernetes api resources of '%s': %w", apiVersion, err)
+	}
+
+	var resourceList struct {
+		Resources []struct {
+			Name string `json:"name"`
+			Kind string `json:"kind"`
+		} `json:"resources"`
+	}
+
+	if err := json.Unmarshal([]byte(res.Stdout), &resourceList); err != nil {
+		return nil, fmt.Errorf("parsing kubernetes api resources of '%s': %w", apiVersion, err)
+	}
+
+	kinds := []string{}
+	for _, resource := range resourceList.Resources {
+		// Sub resources, ex) deployments/scale, have the kind of their own schema
+		if !strings.Contains(resource.Name, "/") {
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+
+	return kinds, nil
+}
+
 // Returns the installation URL to install the K8s CLI
 func (cli *kubectlCli) InstallUrl() string {
 	return "https://aka.ms/azure-dev/kubectl-install"
@@ -199,8 +303,8 @@ func (cli *kubectlCli) ApplyWithInput(ctx context.Context, input string, flags *
 }
 
 // Applies manifests from the specified input
-func (cli *kubectlCli) Apply(ctx context.Context, path string, flags *KubeCliFlags) error {
-	if err := cli.applyTemplates(ctx, path, flags); err != nil {
+func (cli *kubectlCli) Apply(ctx context.Context, fs vfs.Fs, path string, flags *KubeCliFlags) error {
+	if err := cli.applyTemplates(ctx, fs, path, flags); err != nil {
 		return fmt.Errorf("failed process templates, %w", err)
 	}
 
@@ -253,6 +357,160 @@ func (cli *kubectlCli) RolloutStatus(
 	return &res, nil
 }
 
+// Restarts the pods of the workload, ex) deployment/api, with a rolling update and waits for the rollout
+func (cli *kubectlCli) RolloutRestart(ctx context.Context, workload string, flags *KubeCliFlags) error {
+	if _, err := cli.Exec(ctx, flags, "rollout", "restart", workload); err != nil {
+		return fmt.Errorf("restarting %s, %w", workload, err)
+	}
+
+	if _, err := cli.Exec(ctx, flags, "rollout", "status", workload); err != nil {
+		return fmt.Errorf("deployment rollout failed, %w", err)
+	}
+
+	return nil
+}
+
+// Rolls the workload, ex) deployment/api, back to its previous revision
+func (cli *kubectlCli) RolloutUndo(ctx context.Context, workload string, flags *KubeCliFlags) error {
+	if _, err := cli.Exec(ctx, flags, "rollout", "undo", workload); err != nil {
+		return fmt.Errorf("rolling back %s, %w", workload, err)
+	}
+
+	return nil
+}
+
+// Sets the number of replicas of the workload, ex) deployment/api
+func (cli *kubectlCli) Scale(ctx context.Context, workload string, replicas int, flags *KubeCliFlags) error {
+	if _, err := cli.Exec(ctx, flags, "scale", workload, fmt.Sprintf("--replicas=%d", replicas)); err != nil {
+		return fmt.Errorf("scaling %s, %w", workload, err)
+	}
+
+	return nil
+}
+
+// Runs a command in a container of the workload, ex) deployment/api, attached to the console.
+// A TTY is allocated for the command when tty is set.
+func (cli *kubectlCli) ExecInContainer(
+	ctx context.Context,
+	workload string,
+	command []string,
+	tty bool,
+	flags *KubeCliFlags,
+) error {
+	args := []string{"exec", "-i"}
+	if tty {
+		args = append(args, "-t")
+	}
+	args = append(args, workload)
+
+	// The flags must precede the command of the container
+	if flags != nil && flags.Namespace != "" {
+		args = append(args, "-n", flags.Namespace)
+	}
+	args = append(args, "--")
+	args = append(args, command...)
+
+	runArgs := exec.
+		NewRunArgs("kubectl", args...).
+		WithEnv(environ(cli.env)).
+		WithInteractive(true)
+
+	if _, err := cli.executeCommandWithArgs(ctx, runArgs, nil); err != nil {
+		return fmt.Errorf("kubectl exec: %w", err)
+	}
+
+	return nil
+}
+
+// Writes the logs of all the containers of the workload, prefixed with the pod and container, to the writer.
+// When following the logs, returns once the context is cancelled.
+func (cli *kubectlCli) Logs(
+	ctx context.Context,
+	workload string,
+	options LogsOptions,
+	writer io.Writer,
+	flags *KubeCliFlags,
+) error {
+	runArgs := exec.
+		NewRunArgs("kubectl", "logs", workload, "--all-containers", "--prefix").
+		WithEnv(environ(cli.env)).
+		WithStdOut(writer)
+
+	if options.Since > 0 {
+		runArgs = runArgs.AppendParams(fmt.Sprintf("--since=%s", options.Since))
+	}
+
+	if options.Follow {
+		runArgs = runArgs.AppendParams("-f")
+	}
+
+	if _, err := cli.executeCommandWithArgs(ctx, runArgs, flags); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return fmt.Errorf("kubectl logs: %w", err)
+	}
+
+	return nil
+}
+
+// Forwards a local port to the port of the resource, ex) svc/api, until the context is cancelled. A free local port
+// is chosen when localPort is zero. ready is called with the local port once it is forwarded.
+func (cli *kubectlCli) PortForward(
+	ctx context.Context,
+	resource string,
+	localPort int,
+	remotePort int,
+	ready func(localPort int),
+	flags *KubeCliFlags,
+) error {
+	ports := fmt.Sprintf(":%d", remotePort)
+	if localPort > 0 {
+		ports = fmt.Sprintf("%d:%d", localPort, remotePort)
+	}
+
+	runArgs := exec.
+		NewRunArgs("kubectl", "port-forward", resource, ports).
+		WithEnv(environ(cli.env)).
+		WithStdOut(&portForwardWriter{ready: ready})
+
+	if _, err := cli.executeCommandWithArgs(ctx, runArgs, flags); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return fmt.Errorf("kubectl port-forward: %w", err)
+	}
+
+	return nil
+}
+
+// matches the line kubectl port-forward writes once the port is forwarded, ex) Forwarding from 127.0.0.1:8080 -> 80
+var portForwardingRegex = regexp.MustCompile(`Forwarding from 127\.0\.0\.1:(\d+) ->`)
+
+// portForwardWriter calls ready with the local port the first time kubectl port-forward writes it is forwarding
+type portForwardWriter struct {
+	ready    func(localPort int)
+	output   strings.Builder
+	notified bool
+}
+
+func (w *portForwardWriter) Write(p []byte) (int, error) {
+	if w.notified {
+		return len(p), nil
+	}
+
+	w.output.Write(p)
+	if match := portForwardingRegex.FindStringSubmatch(w.output.String()); match != nil {
+		w.notified = true
+		localPort, _ := strconv.Atoi(match[1])
+		w.ready(localPort)
+	}
+
+	return len(p), nil
+}
+
 // Executes a k8s CLI command from the specified arguments and flags
 func (cli *kubectlCli) Exec(ctx context.Context, flags *KubeCliFlags, args ...string) (exec.RunResult, error) {
 	runArgs := exec.
@@ -262,20 +520,13 @@ func (cli *kubectlCli) Exec(ctx context.Context, flags *KubeCliFlags, args ...st
 	return cli.executeCommandWithArgs(ctx, runArgs, flags)
 }
 
-func (cli *kubectlCli) applyTemplate(ctx context.Context, filePath string, flags *KubeCliFlags) error {
-	fileBytes, err := os.ReadFile(filePath)
+func (cli *kubectlCli) applyTemplate(ctx context.Context, fs vfs.Fs, filePath string, flags *KubeCliFlags) error {
+	fileBytes, err := fs.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed reading manifest file '%s', %w", filePath, err)
 	}
 
-	yaml := string(fileBytes)
-	replaced, err := envsubst.Eval(yaml, func(name string) string {
-		if val, has := cli.env[name]; has {
-			return val
-		}
-		return os.Getenv(name)
-	})
-
+	replaced, err := substituteEnv(string(fileBytes), cli.env)
 	if err != nil {
 		return fmt.Errorf("failed replacing env vars, %w", err)
 	}
@@ -288,8 +539,8 @@ func (cli *kubectlCli) applyTemplate(ctx context.Context, filePath string, flags
 	return nil
 }
 
-func (cli *kubectlCli) applyTemplates(ctx context.Context, directoryPath string, flags *KubeCliFlags) error {
-	entries, err := os.ReadDir(directoryPath)
+func (cli *kubectlCli) applyTemplates(ctx context.Context, fs vfs.Fs, directoryPath string, flags *KubeCliFlags) error {
+	entries, err := fs.ReadDir(directoryPath)
 	if err != nil {
 		return fmt.Errorf("failed reading files in path, '%s', %w", directoryPath, err)
 	}
@@ -298,7 +549,7 @@ func (cli *kubectlCli) applyTemplates(ctx context.Context, directoryPath string,
 		entryPath := filepath.Join(directoryPath, entry.Name())
 
 		if entry.IsDir() {
-			if err := cli.applyTemplates(ctx, entryPath, flags); err != nil {
+			if err := cli.applyTemplates(ctx, fs, entryPath, flags); err != nil {
 				return fmt.Errorf("failed applying templates at '%s', %w", entryPath, err)
 			}
 
@@ -310,7 +561,7 @@ func (cli *kubectlCli) applyTemplates(ctx context.Context, directoryPath string,
 			continue
 		}
 
-		if err := cli.applyTemplate(ctx, entryPath, flags); err != nil {
+		if err := cli.applyTemplate(ctx, fs, entryPath, flags); err != nil {
 			return fmt.Errorf("failed applying template '%s', %w", entryPath, err)
 		}
 	}
@@ -342,6 +593,17 @@ func (cli *kubectlCli) executeCommandWithArgs(
 	return cli.commandRunner.Run(ctx, args)
 }
 
+// substituteEnv replaces env var references in the manifest contents, preferring the specified values
+// over the process environment, see [interpolate.Eval]
+func substituteEnv(content string, env map[string]string) (string, error) {
+	return interpolate.Eval(content, func(name string) string {
+		if val, has := env[name]; has {
+			return val
+		}
+		return os.Getenv(name)
+	})
+}
+
 func environ(values map[string]string) []string {
 	env := []string{}
 	for key, value := range values {
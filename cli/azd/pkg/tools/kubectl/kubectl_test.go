@@ -3,20 +3,21 @@ package kubectl
 import (
 	"bytes"
 	"context"
-	"os"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_ApplyFiles(t *testing.T) {
-	tempDir := t.TempDir()
-	ostest.Chdir(t, tempDir)
+	fs := vfs.NewMemFs()
 
 	ran := false
 	var runArgs exec.RunArgs
@@ -33,10 +34,10 @@ func Test_ApplyFiles(t *testing.T) {
 
 	cli := NewKubectl(mockContext.CommandRunner)
 
-	err := os.WriteFile("test.yaml", []byte("yaml"), osutil.PermissionFile)
+	err := fs.WriteFile("test.yaml", []byte("yaml"), osutil.PermissionFile)
 	require.NoError(t, err)
 
-	err = cli.Apply(*mockContext.Context, tempDir, &KubeCliFlags{
+	err = cli.Apply(*mockContext.Context, fs, ".", &KubeCliFlags{
 		Namespace: "test-namespace",
 	})
 	require.NoError(t, err)
@@ -155,6 +156,49 @@ func Test_Command_Args(t *testing.T) {
 				return err
 			},
 		},
+		"exec-in-container": {
+			mockCommandPredicate: "kubectl exec -i -t",
+			expectedCmd:          "kubectl",
+			expectedArgs: []string{
+				"exec", "-i", "-t", "deployment/deployment-name", "-n", "test-namespace", "--", "/bin/sh",
+			},
+			testFn: func() error {
+				return cli.ExecInContainer(
+					*mockContext.Context,
+					"deployment/deployment-name",
+					[]string{"/bin/sh"},
+					true,
+					&KubeCliFlags{
+						Namespace: "test-namespace",
+					},
+				)
+			},
+		},
+		"logs": {
+			mockCommandPredicate: "kubectl logs",
+			expectedCmd:          "kubectl",
+			expectedArgs: []string{
+				"logs",
+				"deployment/deployment-name",
+				"--all-containers",
+				"--prefix",
+				"--since=15m0s",
+				"-f",
+				"-n",
+				"test-namespace",
+			},
+			testFn: func() error {
+				return cli.Logs(
+					*mockContext.Context,
+					"deployment/deployment-name",
+					LogsOptions{Since: 15 * time.Minute, Follow: true},
+					io.Discard,
+					&KubeCliFlags{
+						Namespace: "test-namespace",
+					},
+				)
+			},
+		},
 	}
 
 	for testName, config := range tests {
@@ -214,3 +258,71 @@ func TestGetClientVersion(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "v1.25.4", ver)
 }
+
+func TestGetServerVersion(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl version")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{"version", "-o", "json"}, args.Args)
+		return exec.NewRunResult(0, `{
+			"clientVersion": {"gitVersion": "v1.25.4"},
+			"serverVersion": {"gitVersion": "v1.27.3"}
+		}`, ""), nil
+	})
+
+	cli := NewKubectl(mockContext.CommandRunner)
+
+	ver, err := cli.GetServerVersion(*mockContext.Context)
+	require.NoError(t, err)
+	require.Equal(t, "v1.27.3", ver)
+}
+
+func TestGetApiKinds(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl get --raw")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		if args.Args[2] == "/api/v1" {
+			return exec.NewRunResult(0, `{"resources": [{"name": "services", "kind": "Service"}]}`, ""), nil
+		}
+
+		require.Equal(t, "/apis/apps/v1", args.Args[2])
+		return exec.NewRunResult(0, `{"resources": [
+			{"name": "deployments", "kind": "Deployment"},
+			{"name": "deployments/scale", "kind": "Scale"}
+		]}`, ""), nil
+	})
+
+	cli := NewKubectl(mockContext.CommandRunner)
+
+	kinds, err := cli.GetApiKinds(*mockContext.Context, "v1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Service"}, kinds)
+
+	kinds, err = cli.GetApiKinds(*mockContext.Context, "apps/v1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Deployment"}, kinds)
+}
+
+func TestPortForward(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kubectl port-forward")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{"port-forward", "svc/api", ":80", "-n", "test-namespace"}, args.Args)
+		_, err := args.Stdout.Write([]byte("Forwarding from 127.0.0.1:54321 -> 80\nForwarding from [::1]:54321 -> 80\n"))
+		require.NoError(t, err)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	cli := NewKubectl(mockContext.CommandRunner)
+
+	readyPorts := []int{}
+	err := cli.PortForward(*mockContext.Context, "svc/api", 0, 80, func(localPort int) {
+		readyPorts = append(readyPorts, localPort)
+	}, &KubeCliFlags{Namespace: "test-namespace"})
+	require.NoError(t, err)
+	require.Equal(t, []int{54321}, readyPorts)
+}
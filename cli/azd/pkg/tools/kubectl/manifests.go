@@ -0,0 +1,72 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadManifests reads all the k8s manifests in the specified directory (and sub directories) of the file system,
+// replacing env var references the same way `Apply` does, and returns the parsed documents.
+// Files containing multiple YAML documents return a manifest per document.
+func ReadManifests(fs vfs.Fs, directoryPath string, env map[string]string) ([]map[string]any, error) {
+	entries, err := fs.ReadDir(directoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading files in path, '%s', %w", directoryPath, err)
+	}
+
+	manifests := []map[string]any{}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(directoryPath, entry.Name())
+
+		if entry.IsDir() {
+			children, err := ReadManifests(fs, entryPath, env)
+			if err != nil {
+				return nil, err
+			}
+
+			manifests = append(manifests, children...)
+			continue
+		}
+
+		ext := filepath.Ext(entry.Name())
+		if !(ext == ".yaml" || ext == ".yml") {
+			continue
+		}
+
+		fileBytes, err := fs.ReadFile(entryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading manifest file '%s', %w", entryPath, err)
+		}
+
+		replaced, err := substituteEnv(string(fileBytes), env)
+		if err != nil {
+			return nil, fmt.Errorf("failed replacing env vars in '%s', %w", entryPath, err)
+		}
+
+		decoder := yaml.NewDecoder(strings.NewReader(replaced))
+		for {
+			var manifest map[string]any
+			err := decoder.Decode(&manifest)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing manifest file '%s', %w", entryPath, err)
+			}
+
+			// Empty documents (ex: trailing separators) decode to a nil map
+			if manifest != nil {
+				manifests = append(manifests, manifest)
+			}
+		}
+	}
+
+	return manifests, nil
+}
@@ -0,0 +1,55 @@
+package kubectl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReadManifests(t *testing.T) {
+	fs := vfs.NewMemFs()
+	root := "manifests"
+	nested := filepath.Join(root, "nested")
+	require.NoError(t, fs.MkdirAll(nested, osutil.PermissionDirectory))
+
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          image: ${IMAGE_NAME}
+`
+	multiDoc := `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+---
+`
+
+	require.NoError(t, fs.WriteFile(filepath.Join(root, "deployment.yaml"), []byte(deployment), osutil.PermissionFile))
+	require.NoError(t, fs.WriteFile(filepath.Join(nested, "other.yml"), []byte(multiDoc), osutil.PermissionFile))
+	require.NoError(t, fs.WriteFile(filepath.Join(root, "README.md"), []byte("ignored"), osutil.PermissionFile))
+
+	manifests, err := ReadManifests(fs, root, map[string]string{"IMAGE_NAME": "myacr.azurecr.io/api:latest"})
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+
+	require.Equal(t, "Deployment", manifests[0]["kind"])
+	containers := manifests[0]["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)["containers"]
+	require.Equal(t, "myacr.azurecr.io/api:latest", containers.([]any)[0].(map[string]any)["image"])
+
+	require.Equal(t, "Service", manifests[1]["kind"])
+	require.Equal(t, "Ingress", manifests[2]["kind"])
+}
@@ -8,15 +8,22 @@ import (
 type ResourceType string
 
 const (
-	ResourceTypeDeployment ResourceType = "deployment"
-	ResourceTypeIngress    ResourceType = "ing"
-	ResourceTypeService    ResourceType = "svc"
+	ResourceTypeDeployment   ResourceType = "deployment"
+	ResourceTypeIngress      ResourceType = "ing"
+	ResourceTypeService      ResourceType = "svc"
+	ResourceTypeIngressClass ResourceType = "ingressclass"
+	ResourceTypeHttpRoute    ResourceType = "httproutes.gateway.networking.k8s.io"
+	ResourceTypeGateway      ResourceType = "gateways.gateway.networking.k8s.io"
+	ResourceTypePod          ResourceType = "pod"
 )
 
 type Resource struct {
 	ApiVersion string           `json:"apiVersion" yaml:"apiVersion"`
 	Kind       string           `json:"kind"       yaml:"kind"`
 	Metadata   ResourceMetadata `json:"metadata"   yaml:"metadata"`
+	// The resource as returned by kubectl, including the fields the model doesn't have. Set when the resource is
+	// decoded with DecodeResource or DecodeResources.
+	Object Object `json:"-" yaml:"-"`
 }
 
 type List[T any] struct {
@@ -49,12 +56,57 @@ type DeploymentStatus struct {
 	UpdatedReplicas   int `json:"updatedReplicas"   yaml:"updatedReplicas"`
 }
 
+type Pod ResourceWithSpec[any, PodStatus]
+
+type PodStatus struct {
+	Phase                 string            `json:"phase"                 yaml:"phase"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses" yaml:"initContainerStatuses"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses"     yaml:"containerStatuses"`
+}
+
+type ContainerStatus struct {
+	Name         string         `json:"name"         yaml:"name"`
+	Ready        bool           `json:"ready"        yaml:"ready"`
+	RestartCount int            `json:"restartCount" yaml:"restartCount"`
+	State        ContainerState `json:"state"        yaml:"state"`
+}
+
+// The state of a container, only one of which is set
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"    yaml:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"    yaml:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty" yaml:"terminated,omitempty"`
+}
+
+type ContainerStateWaiting struct {
+	// ex) CrashLoopBackOff or ImagePullBackOff
+	Reason  string `json:"reason"  yaml:"reason"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type ContainerStateRunning struct {
+	StartedAt string `json:"startedAt" yaml:"startedAt"`
+}
+
+type ContainerStateTerminated struct {
+	ExitCode int    `json:"exitCode" yaml:"exitCode"`
+	Reason   string `json:"reason"   yaml:"reason"`
+}
+
+// Crashing reports whether the container exited with an error or was restarted
+func (s ContainerStatus) Crashing() bool {
+	return s.RestartCount > 0 ||
+		(s.State.Waiting != nil && s.State.Waiting.Reason == "CrashLoopBackOff") ||
+		(s.State.Terminated != nil && s.State.Terminated.ExitCode != 0)
+}
+
 type Ingress ResourceWithSpec[IngressSpec, IngressStatus]
 
 type IngressSpec struct {
-	IngressClassName string        `json:"ingressClassName" yaml:"ingressClassName"`
-	Tls              []IngressTls  `json:"tls"              yaml:"tls"`
-	Rules            []IngressRule `json:"rules"            yaml:"rules"`
+	IngressClassName string          `json:"ingressClassName" yaml:"ingressClassName"`
+	DefaultBackend   *IngressBackend `json:"defaultBackend"   yaml:"defaultBackend"`
+	Tls              []IngressTls    `json:"tls"              yaml:"tls"`
+	Rules            []IngressRule   `json:"rules"            yaml:"rules"`
 }
 
 type IngressTls struct {
@@ -72,8 +124,24 @@ type IngressRuleHttp struct {
 }
 
 type IngressPath struct {
-	Path     string `json:"path"     yaml:"path"`
-	PathType string `json:"pathType" yaml:"pathType"`
+	Path     string         `json:"path"     yaml:"path"`
+	PathType string         `json:"pathType" yaml:"pathType"`
+	Backend  IngressBackend `json:"backend"  yaml:"backend"`
+}
+
+type IngressBackend struct {
+	Service *IngressServiceBackend `json:"service" yaml:"service"`
+}
+
+type IngressServiceBackend struct {
+	Name string             `json:"name" yaml:"name"`
+	Port IngressServicePort `json:"port" yaml:"port"`
+}
+
+// The port of the service, either its number or its name
+type IngressServicePort struct {
+	Number int    `json:"number" yaml:"number"`
+	Name   string `json:"name"   yaml:"name"`
 }
 
 type IngressStatus struct {
@@ -86,6 +154,68 @@ type LoadBalancer struct {
 
 type LoadBalancerIngress struct {
 	Ip string `json:"ip" yaml:"ip"`
+	// Load balancers of some environments, ex) AWS, are reachable through a DNS name instead of an IP address
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// Address returns the IP address of the load balancer, or its hostname
+func (i LoadBalancerIngress) Address() string {
+	if i.Ip != "" {
+		return i.Ip
+	}
+
+	return i.Hostname
+}
+
+// The API group of the Gateway API, which routes traffic from gateways to services with routes, ex) HTTPRoute
+const GatewayApiGroup = "gateway.networking.k8s.io"
+
+type HttpRoute ResourceWithSpec[HttpRouteSpec, any]
+
+type HttpRouteSpec struct {
+	ParentRefs []HttpRouteParentRef `json:"parentRefs" yaml:"parentRefs"`
+	Hostnames  []string             `json:"hostnames"  yaml:"hostnames"`
+	Rules      []HttpRouteRule      `json:"rules"      yaml:"rules"`
+}
+
+type HttpRouteParentRef struct {
+	Name      string `json:"name"      yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+type HttpRouteRule struct {
+	Matches []HttpRouteMatch `json:"matches" yaml:"matches"`
+}
+
+type HttpRouteMatch struct {
+	Path *HttpPathMatch `json:"path" yaml:"path"`
+}
+
+type HttpPathMatch struct {
+	Type  string `json:"type"  yaml:"type"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type Gateway ResourceWithSpec[GatewaySpec, GatewayStatus]
+
+type GatewaySpec struct {
+	Listeners []GatewayListener `json:"listeners" yaml:"listeners"`
+}
+
+type GatewayListener struct {
+	Name     string  `json:"name"     yaml:"name"`
+	Hostname *string `json:"hostname" yaml:"hostname"`
+	Port     int     `json:"port"     yaml:"port"`
+	Protocol string  `json:"protocol" yaml:"protocol"`
+}
+
+type GatewayStatus struct {
+	Addresses []GatewayAddress `json:"addresses" yaml:"addresses"`
+}
+
+type GatewayAddress struct {
+	Type  string `json:"type"  yaml:"type"`
+	Value string `json:"value" yaml:"value"`
 }
 
 type Service ResourceWithSpec[ServiceSpec, ServiceStatus]
@@ -148,6 +278,8 @@ type KubeConfig struct {
 	Kind           string          `yaml:"kind"`
 	CurrentContext string          `yaml:"current-context"`
 	Preferences    KubePreferences `yaml:"preferences"`
+	// The fields the model doesn't have, ex) extensions, kept when the config is saved
+	Extra map[string]any `yaml:",inline"`
 }
 
 type KubeCluster struct {
@@ -156,8 +288,10 @@ type KubeCluster struct {
 }
 
 type KubeClusterData struct {
-	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
 	Server                   string `yaml:"server"`
+	// The fields the model doesn't have, ex) proxy-url, kept when the config is saved
+	Extra map[string]any `yaml:",inline"`
 }
 
 type KubeContext struct {
@@ -166,8 +300,11 @@ type KubeContext struct {
 }
 
 type KubeContextData struct {
-	Cluster string `yaml:"cluster"`
-	User    string `yaml:"user"`
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace,omitempty"`
+	// The fields the model doesn't have, kept when the config is saved
+	Extra map[string]any `yaml:",inline"`
 }
 
 type KubeUser struct {
@@ -175,5 +312,38 @@ type KubeUser struct {
 	KubeUserData KubeUserData `yaml:"user"`
 }
 
-type KubeUserData map[string]any
+// The credentials of a kube config user: a client certificate, a token, a user name and password, or an exec plugin
+// getting credentials, ex) kubelogin for clusters with Microsoft Entra ID authentication
+type KubeUserData struct {
+	ClientCertificateData string          `yaml:"client-certificate-data,omitempty"`
+	ClientKeyData         string          `yaml:"client-key-data,omitempty"`
+	ClientCertificate     string          `yaml:"client-certificate,omitempty"`
+	ClientKey             string          `yaml:"client-key,omitempty"`
+	Token                 string          `yaml:"token,omitempty"`
+	TokenFile             string          `yaml:"tokenFile,omitempty"`
+	Username              string          `yaml:"username,omitempty"`
+	Password              string          `yaml:"password,omitempty"`
+	Exec                  *KubeExecConfig `yaml:"exec,omitempty"`
+	// The fields the model doesn't have, ex) auth-provider, kept when the config is saved
+	Extra map[string]any `yaml:",inline"`
+}
+
+// The exec plugin of a kube config user, the command kubectl runs to get the credentials of the user
+type KubeExecConfig struct {
+	ApiVersion         string        `yaml:"apiVersion"`
+	Command            string        `yaml:"command"`
+	Args               []string      `yaml:"args,omitempty"`
+	Env                []KubeExecEnv `yaml:"env,omitempty"`
+	InstallHint        string        `yaml:"installHint,omitempty"`
+	ProvideClusterInfo bool          `yaml:"provideClusterInfo,omitempty"`
+	InteractiveMode    string        `yaml:"interactiveMode,omitempty"`
+	// The fields the model doesn't have, kept when the config is saved
+	Extra map[string]any `yaml:",inline"`
+}
+
+type KubeExecEnv struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
 type KubePreferences map[string]any
@@ -72,3 +72,41 @@ func Test_Ingress_UnMarshalling(t *testing.T) {
 		require.Equal(t, "myapp.centralus.cloudapp.azure.com", ingressResources.Items[0].Spec.Tls[0].Hosts[0])
 	})
 }
+
+func Test_ContainerStatus_Crashing(t *testing.T) {
+	tests := map[string]struct {
+		status   ContainerStatus
+		expected bool
+	}{
+		"Running": {
+			status:   ContainerStatus{Ready: true, State: ContainerState{Running: &ContainerStateRunning{}}},
+			expected: false,
+		},
+		"ImagePullBackOff": {
+			status:   ContainerStatus{State: ContainerState{Waiting: &ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			expected: false,
+		},
+		"CrashLoopBackOff": {
+			status:   ContainerStatus{State: ContainerState{Waiting: &ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			expected: true,
+		},
+		"Restarted": {
+			status:   ContainerStatus{Ready: true, RestartCount: 1, State: ContainerState{Running: &ContainerStateRunning{}}},
+			expected: true,
+		},
+		"Failed": {
+			status:   ContainerStatus{State: ContainerState{Terminated: &ContainerStateTerminated{ExitCode: 1}}},
+			expected: true,
+		},
+		"Completed": {
+			status:   ContainerStatus{State: ContainerState{Terminated: &ContainerStateTerminated{ExitCode: 0}}},
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.status.Crashing())
+		})
+	}
+}
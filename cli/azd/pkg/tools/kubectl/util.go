@@ -2,13 +2,12 @@ package kubectl
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
-	"github.com/sethvargo/go-retry"
-	"gopkg.in/yaml.v3"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
+	"github.com/benbjohnson/clock"
 )
 
 var (
@@ -31,29 +30,13 @@ func GetResource[T any](
 		flags.Output = OutputTypeJson
 	}
 
-	var resource T
-
 	res, err := cli.Exec(ctx, flags, "get", string(resourceType), resourceName)
 	if err != nil {
+		var resource T
 		return resource, fmt.Errorf("failed getting resources, %w", err)
 	}
 
-	switch flags.Output {
-	case OutputTypeJson:
-		err = json.Unmarshal([]byte(res.Stdout), &resource)
-		if err != nil {
-			return resource, fmt.Errorf("failed unmarshalling resources JSON, %w", err)
-		}
-	case OutputTypeYaml:
-		err = yaml.Unmarshal([]byte(res.Stdout), &resource)
-		if err != nil {
-			return resource, fmt.Errorf("failed unmarshalling resources YAML, %w", err)
-		}
-	default:
-		return resource, fmt.Errorf("failed unmarshalling resources. Output format '%s' is not supported", flags.Output)
-	}
-
-	return resource, nil
+	return DecodeResource[T]([]byte(res.Stdout), flags.Output)
 }
 
 func GetResources[T any](
@@ -75,31 +58,22 @@ func GetResources[T any](
 		return nil, fmt.Errorf("failed getting resources, %w", err)
 	}
 
-	var list List[T]
-
-	switch flags.Output {
-	case OutputTypeJson:
-		err = json.Unmarshal([]byte(res.Stdout), &list)
-		if err != nil {
-			return nil, fmt.Errorf("failed unmarshalling resources JSON, %w", err)
-		}
-	case OutputTypeYaml:
-		err = yaml.Unmarshal([]byte(res.Stdout), &list)
-		if err != nil {
-			return nil, fmt.Errorf("failed unmarshalling resources YAML, %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("failed unmarshalling resources. Output format '%s' is not supported", flags.Output)
-	}
-
-	return &list, nil
+	return DecodeResources[T]([]byte(res.Stdout), flags.Output)
 }
 
 type ResourceFilterFn[T comparable] func(resource T) bool
 
+// DefaultWaitConfig is how long WaitForResource waits for a resource to become ready and how often it gets it by default
+var DefaultWaitConfig = wait.Config{
+	Timeout:  10 * time.Minute,
+	Interval: 10 * time.Second,
+}
+
 func WaitForResource[T comparable](
 	ctx context.Context,
 	cli KubectlCli,
+	clk clock.Clock,
+	waitConfig wait.Config,
 	namespace string,
 	resourceType ResourceType,
 	resourceFilter ResourceFilterFn[T],
@@ -107,36 +81,34 @@ func WaitForResource[T comparable](
 ) (T, error) {
 	var resource T
 	var zero T
-	err := retry.Do(
-		ctx,
-		retry.WithMaxDuration(time.Minute*10, retry.NewConstant(time.Second*10)),
-		func(ctx context.Context) error {
-			result, err := GetResources[T](ctx, cli, resourceType, &KubeCliFlags{
-				Namespace: namespace,
-			})
-
-			if err != nil {
-				return fmt.Errorf("failed waiting for resource, %w", err)
-			}
+	err := wait.Until(ctx, clk, waitConfig, func(ctx context.Context) (bool, error) {
+		result, err := GetResources[T](ctx, cli, resourceType, &KubeCliFlags{
+			Namespace: namespace,
+		})
 
-			for _, r := range result.Items {
-				if resourceFilter(r) {
-					resource = r
-					break
-				}
-			}
+		if err != nil {
+			return false, fmt.Errorf("failed waiting for resource, %w", err)
+		}
 
-			if resource == zero {
-				return fmt.Errorf("cannot find resource for '%s', %w", resourceType, ErrResourceNotFound)
+		resource = zero
+		for _, r := range result.Items {
+			if resourceFilter(r) {
+				resource = r
+				break
 			}
+		}
 
-			if !readyStatusFilter(resource) {
-				return retry.RetryableError(fmt.Errorf("resource '%s' is not ready, %w", resourceType, ErrResourceNotReady))
-			}
+		if resource == zero {
+			return false, fmt.Errorf("cannot find resource for '%s', %w", resourceType, ErrResourceNotFound)
+		}
+
+		return readyStatusFilter(resource), nil
+	})
 
-			return nil
-		},
-	)
+	if errors.Is(err, wait.ErrTimeout) {
+		return zero, fmt.Errorf("failed waiting for resource, resource '%s' is not ready after %s, %w",
+			resourceType, waitConfig.Timeout, ErrResourceNotReady)
+	}
 
 	if err != nil {
 		return zero, fmt.Errorf("failed waiting for resource, %w", err)
@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package kubelogin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// LoginMode is how kubelogin gets the Microsoft Entra ID tokens of the users of a kube config
+type LoginMode string
+
+const (
+	// Gets the tokens of the principal signed in to azd
+	LoginModeAzd LoginMode = "azd"
+	// Gets the tokens of the principal signed in to the Azure CLI
+	LoginModeAzureCli LoginMode = "azurecli"
+)
+
+// The exec plugin command of the users of clusters with Microsoft Entra ID authentication
+const Command = "kubelogin"
+
+// KubeloginCli is the kubelogin client-go credential plugin, which gets the Microsoft Entra ID tokens kubectl
+// authenticates to AKS clusters with
+type KubeloginCli interface {
+	tools.ExternalTool
+
+	// Converts the exec plugin users of the kube config file to the login mode, so that kubectl gets their tokens
+	// without prompting, ex) with the device code flow
+	ConvertKubeConfig(ctx context.Context, kubeConfigPath string, loginMode LoginMode) error
+}
+
+type kubeloginCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the kubelogin CLI
+func NewKubelogin(commandRunner exec.CommandRunner) KubeloginCli {
+	return &kubeloginCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *kubeloginCli) ConvertKubeConfig(ctx context.Context, kubeConfigPath string, loginMode LoginMode) error {
+	runArgs := exec.NewRunArgs(
+		Command, "convert-kubeconfig", "--login", string(loginMode), "--kubeconfig", kubeConfigPath)
+
+	if _, err := cli.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("kubelogin convert-kubeconfig: %w", err)
+	}
+
+	return nil
+}
+
+func (cli *kubeloginCli) CheckInstalled(_ context.Context) error {
+	return tools.ToolInPath(Command)
+}
+
+func (cli *kubeloginCli) Name() string {
+	return "kubelogin"
+}
+
+func (cli *kubeloginCli) InstallUrl() string {
+	return "https://azure.github.io/kubelogin/install.html"
+}
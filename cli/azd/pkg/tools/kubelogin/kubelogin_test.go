@@ -0,0 +1,47 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package kubelogin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConvertKubeConfig(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var runArgs exec.RunArgs
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "kubelogin convert-kubeconfig")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		cli := NewKubelogin(mockContext.CommandRunner)
+		err := cli.ConvertKubeConfig(*mockContext.Context, "/home/user/.kube/cluster", LoginModeAzd)
+		require.NoError(t, err)
+		require.Equal(t, "kubelogin", runArgs.Cmd)
+		require.Equal(t,
+			[]string{"convert-kubeconfig", "--login", "azd", "--kubeconfig", "/home/user/.kube/cluster"}, runArgs.Args)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "kubelogin convert-kubeconfig")
+		}).SetError(errors.New("unknown login mode"))
+
+		cli := NewKubelogin(mockContext.CommandRunner)
+		err := cli.ConvertKubeConfig(*mockContext.Context, "/home/user/.kube/cluster", LoginModeAzd)
+		require.ErrorContains(t, err, "kubelogin convert-kubeconfig: unknown login mode")
+	})
+}
@@ -20,8 +20,12 @@ type MavenCli interface {
 	tools.ExternalTool
 	SetPath(projectPath string, rootProjectPath string)
 	ResolveDependencies(ctx context.Context, projectPath string) error
-	Compile(ctx context.Context, projectPath string) error
-	Package(ctx context.Context, projectPath string) error
+	// Compile compiles the project. When module is set, only the module of a multi-module project and the modules it
+	// depends on are compiled.
+	Compile(ctx context.Context, projectPath string, module string) error
+	// Package packages the project, without running its tests. When module is set, only the module of a multi-module
+	// project and the modules it depends on are packaged.
+	Package(ctx context.Context, projectPath string, module string) error
 }
 
 type mavenCli struct {
@@ -173,13 +177,13 @@ func (cli *mavenCli) extractVersion(ctx context.Context) (string, error) {
 	return parts[1], nil
 }
 
-func (cli *mavenCli) Compile(ctx context.Context, projectPath string) error {
+func (cli *mavenCli) Compile(ctx context.Context, projectPath string, module string) error {
 	mvnCmd, err := cli.mvnCmd()
 	if err != nil {
 		return err
 	}
 
-	runArgs := exec.NewRunArgs(mvnCmd, "compile").WithCwd(projectPath)
+	runArgs := exec.NewRunArgs(mvnCmd, append([]string{"compile"}, moduleArgs(module)...)...).WithCwd(projectPath)
 	_, err = cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn compile on project '%s' failed: %w", projectPath, err)
@@ -188,14 +192,15 @@ func (cli *mavenCli) Compile(ctx context.Context, projectPath string) error {
 	return nil
 }
 
-func (cli *mavenCli) Package(ctx context.Context, projectPath string) error {
+func (cli *mavenCli) Package(ctx context.Context, projectPath string, module string) error {
 	mvnCmd, err := cli.mvnCmd()
 	if err != nil {
 		return err
 	}
 
 	// Maven's package phase includes tests by default. Skip it explicitly.
-	runArgs := exec.NewRunArgs(mvnCmd, "package", "-DskipTests").WithCwd(projectPath)
+	args := append([]string{"package", "-DskipTests"}, moduleArgs(module)...)
+	runArgs := exec.NewRunArgs(mvnCmd, args...).WithCwd(projectPath)
 	_, err = cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn package on project '%s' failed: %w", projectPath, err)
@@ -218,6 +223,15 @@ func (cli *mavenCli) ResolveDependencies(ctx context.Context, projectPath string
 	return nil
 }
 
+// moduleArgs selects the module of a multi-module project, and the modules it depends on
+func moduleArgs(module string) []string {
+	if module == "" {
+		return nil
+	}
+
+	return []string{"--projects", module, "--also-make"}
+}
+
 func NewMavenCli(commandRunner exec.CommandRunner) MavenCli {
 	return &mavenCli{
 		commandRunner: commandRunner,
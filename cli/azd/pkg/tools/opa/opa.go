@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package opa
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/blang/semver/v4"
+)
+
+// OpaCli executes commands against the Open Policy Agent CLI
+type OpaCli interface {
+	tools.ExternalTool
+	// Evaluates the specified query against the data paths (policies and data documents) and the input file.
+	// Returns the raw JSON output of `opa eval --format json`.
+	Eval(ctx context.Context, query string, dataPaths []string, inputPath string) (string, error)
+}
+
+type opaCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// NewOpaCli creates a new instance of the Open Policy Agent CLI
+func NewOpaCli(commandRunner exec.CommandRunner) OpaCli {
+	return &opaCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *opaCli) versionInfo() tools.VersionInfo {
+	return tools.VersionInfo{
+		// 0.59.0 is the first version supporting `import rego.v1`, which the built-in policies use.
+		MinimumVersion: semver.Version{
+			Major: 0,
+			Minor: 59,
+			Patch: 0},
+		UpdateCommand: "Visit https://www.openpolicyagent.org/docs/latest/#running-opa to upgrade",
+	}
+}
+
+// Checks whether or not the OPA CLI is installed and available within the PATH
+func (cli *opaCli) CheckInstalled(ctx context.Context) error {
+	if err := tools.ToolInPath("opa"); err != nil {
+		return err
+	}
+
+	opaRes, err := tools.ExecuteCommand(ctx, cli.commandRunner, "opa", "version")
+	if err != nil {
+		return fmt.Errorf("checking %s version: %w", cli.Name(), err)
+	}
+
+	log.Printf("opa version: %s", opaRes)
+
+	opaSemver, err := tools.ExtractVersion(opaRes)
+	if err != nil {
+		return fmt.Errorf("converting to semver version fails: %w", err)
+	}
+
+	updateDetail := cli.versionInfo()
+	if opaSemver.LT(updateDetail.MinimumVersion) {
+		return &tools.ErrSemver{ToolName: cli.Name(), VersionInfo: updateDetail}
+	}
+
+	return nil
+}
+
+// Returns the installation URL to install the OPA CLI
+func (cli *opaCli) InstallUrl() string {
+	return "https://www.openpolicyagent.org/docs/latest/#running-opa"
+}
+
+// Gets the name of the Tool
+func (cli *opaCli) Name() string {
+	return "Open Policy Agent CLI"
+}
+
+func (cli *opaCli) Eval(ctx context.Context, query string, dataPaths []string, inputPath string) (string, error) {
+	args := []string{"eval", "--format", "json"}
+	for _, dataPath := range dataPaths {
+		args = append(args, "--data", dataPath)
+	}
+	args = append(args, "--input", inputPath, query)
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("opa", args...))
+	if err != nil {
+		return "", fmt.Errorf("opa eval: %w", err)
+	}
+
+	return res.Stdout, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package opa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockexec"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OpaEval(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ran := false
+		execMock := mockexec.NewMockCommandRunner().
+			When(func(args exec.RunArgs, command string) bool { return args.Cmd == "opa" }).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				ran = true
+				require.Equal(t, []string{
+					"eval", "--format", "json",
+					"--data", "policies",
+					"--data", "data.json",
+					"--input", "input.json",
+					"data.azd.policy.arm.deny",
+				}, args.Args)
+
+				return exec.NewRunResult(0, `{"result":[]}`, ""), nil
+			})
+
+		cli := NewOpaCli(execMock)
+		res, err := cli.Eval(
+			context.Background(), "data.azd.policy.arm.deny", []string{"policies", "data.json"}, "input.json")
+		require.NoError(t, err)
+		require.True(t, ran)
+		require.Equal(t, `{"result":[]}`, res)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		execMock := mockexec.NewMockCommandRunner().
+			When(func(args exec.RunArgs, command string) bool { return args.Cmd == "opa" }).
+			SetError(errors.New("rego_parse_error"))
+
+		cli := NewOpaCli(execMock)
+		_, err := cli.Eval(context.Background(), "data.azd.policy.arm.deny", nil, "input.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rego_parse_error")
+	})
+}
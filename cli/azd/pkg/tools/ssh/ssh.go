@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// ForwardOptions are the options of a local port forward through a ssh host.
+type ForwardOptions struct {
+	// The local port forwarded to the remote host
+	LocalPort int
+	// The host, and port, connections are forwarded to, as resolved by the ssh host
+	RemoteHost string
+	RemotePort int
+	// The ssh host, its ssh port, 22 when 0, and the user to sign in as
+	Host string
+	Port int
+	User string
+}
+
+type SshCli interface {
+	tools.ExternalTool
+
+	// Forward forwards the local port to the remote host, through the ssh host, until the context is cancelled or the
+	// connection to the ssh host ends. ssh runs interactively, to prompt for passwords or passphrases.
+	Forward(ctx context.Context, options ForwardOptions) error
+}
+
+func NewSshCli(commandRunner exec.CommandRunner) SshCli {
+	return &sshCli{
+		commandRunner: commandRunner,
+	}
+}
+
+type sshCli struct {
+	commandRunner exec.CommandRunner
+}
+
+func (cli *sshCli) Forward(ctx context.Context, options ForwardOptions) error {
+	runArgs := exec.NewRunArgs("ssh", forwardArgs(options)...).WithInteractive(true)
+
+	if _, err := cli.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("forwarding port %d to %s:%d through %s: %w",
+			options.LocalPort, options.RemoteHost, options.RemotePort, options.Host, err)
+	}
+
+	return nil
+}
+
+func forwardArgs(options ForwardOptions) []string {
+	args := []string{
+		"-N",
+		"-L", fmt.Sprintf("%d:%s:%d", options.LocalPort, options.RemoteHost, options.RemotePort),
+		// fail instead of running without the forward, when the local port is already in use
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "StrictHostKeyChecking=accept-new",
+	}
+
+	if options.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(options.Port))
+	}
+
+	destination := options.Host
+	if options.User != "" {
+		destination = options.User + "@" + options.Host
+	}
+
+	return append(args, destination)
+}
+
+func (cli *sshCli) CheckInstalled(_ context.Context) error {
+	return tools.ToolInPath("ssh")
+}
+
+func (cli *sshCli) Name() string {
+	return "OpenSSH"
+}
+
+func (cli *sshCli) InstallUrl() string {
+	return "https://learn.microsoft.com/windows-server/administration/openssh/openssh_install_firstuse"
+}
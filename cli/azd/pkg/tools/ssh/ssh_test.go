@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ssh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Forward(t *testing.T) {
+	t.Run("JumpBox", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		var runArgs exec.RunArgs
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "ssh"
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		cli := NewSshCli(mockContext.CommandRunner)
+		err := cli.Forward(*mockContext.Context, ForwardOptions{
+			LocalPort:  15432,
+			RemoteHost: "psql-dev.postgres.database.azure.com",
+			RemotePort: 5432,
+			Host:       "jump.eastus2.cloudapp.azure.com",
+			User:       "azureuser",
+		})
+		require.NoError(t, err)
+
+		require.True(t, runArgs.Interactive)
+		require.Equal(t, []string{
+			"-N",
+			"-L", "15432:psql-dev.postgres.database.azure.com:5432",
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "StrictHostKeyChecking=accept-new",
+			"azureuser@jump.eastus2.cloudapp.azure.com",
+		}, runArgs.Args)
+	})
+
+	t.Run("Port", func(t *testing.T) {
+		require.Equal(t, []string{
+			"-N",
+			"-L", "3306:mysql-dev.mysql.database.azure.com:3306",
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "StrictHostKeyChecking=accept-new",
+			"-p", "50022",
+			"localhost",
+		}, forwardArgs(ForwardOptions{
+			LocalPort:  3306,
+			RemoteHost: "mysql-dev.mysql.database.azure.com",
+			RemotePort: 3306,
+			Host:       "localhost",
+			Port:       50022,
+		}))
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package swa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ConfigFileName is the name of the file configuring the routing, authentication and platform of a static web app
+const ConfigFileName = "staticwebapp.config.json"
+
+// configProperties are the top level properties of the configuration file
+var configProperties = []string{
+	"$schema",
+	"auth",
+	"forwardingGateway",
+	"globalHeaders",
+	"mimeTypes",
+	"navigationFallback",
+	"networking",
+	"platform",
+	"responseOverrides",
+	"routes",
+	"trailingSlash",
+}
+
+// overriddenStatusCodes are the status codes the responses of which can be overridden
+var overriddenStatusCodes = []string{"400", "401", "403", "404"}
+
+// config is the subset of the configuration file which is validated before the static web app is deployed
+type config struct {
+	Routes []struct {
+		Route        *string  `json:"route"`
+		AllowedRoles []string `json:"allowedRoles"`
+		Redirect     string   `json:"redirect"`
+		Rewrite      string   `json:"rewrite"`
+		StatusCode   int      `json:"statusCode"`
+	} `json:"routes"`
+	NavigationFallback *struct {
+		Rewrite string `json:"rewrite"`
+	} `json:"navigationFallback"`
+	ResponseOverrides map[string]json.RawMessage `json:"responseOverrides"`
+	TrailingSlash     string                     `json:"trailingSlash"`
+	Platform          *struct {
+		ApiRuntime string `json:"apiRuntime"`
+	} `json:"platform"`
+}
+
+// FindConfigFile returns the path of the configuration file of the first folder which has one, or an empty path when
+// none of them has one
+func FindConfigFile(folders ...string) (string, error) {
+	for _, folder := range folders {
+		path := filepath.Join(folder, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	return "", nil
+}
+
+// ValidateConfigFile validates the configuration file, which the static web app otherwise rejects once the deployment is
+// uploaded or silently ignores
+func ValidateConfigFile(path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", ConfigFileName, err)
+	}
+
+	if err := validateConfig(contents); err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func validateConfig(contents []byte) error {
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &properties); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(contents, syntaxErr.Offset)
+			return fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+
+		return err
+	}
+
+	names := maps.Keys(properties)
+	slices.Sort(names)
+	for _, name := range names {
+		if !slices.Contains(configProperties, name) {
+			return fmt.Errorf("unknown property '%s', expected one of %s", name, strings.Join(configProperties[1:], ", "))
+		}
+	}
+
+	var cfg config
+	if err := json.Unmarshal(contents, &cfg); err != nil {
+		return err
+	}
+
+	for i, route := range cfg.Routes {
+		if route.Route == nil || *route.Route == "" {
+			return fmt.Errorf("routes[%d]: missing 'route'", i)
+		}
+
+		if route.Redirect != "" && route.Rewrite != "" {
+			return fmt.Errorf("routes[%d] '%s': 'redirect' and 'rewrite' cannot be set together", i, *route.Route)
+		}
+
+		if route.Redirect != "" && route.StatusCode != 0 && route.StatusCode != 301 && route.StatusCode != 302 {
+			return fmt.Errorf("routes[%d] '%s': the 'statusCode' of a redirect must be 301 or 302", i, *route.Route)
+		}
+	}
+
+	if cfg.NavigationFallback != nil && cfg.NavigationFallback.Rewrite == "" {
+		return errors.New("navigationFallback: missing 'rewrite'")
+	}
+
+	for statusCode := range cfg.ResponseOverrides {
+		if !slices.Contains(overriddenStatusCodes, statusCode) {
+			return fmt.Errorf(
+				"responseOverrides: the response of status code '%s' cannot be overridden, expected one of %s",
+				statusCode, strings.Join(overriddenStatusCodes, ", "))
+		}
+	}
+
+	if cfg.TrailingSlash != "" && !slices.Contains([]string{"always", "never", "auto"}, cfg.TrailingSlash) {
+		return fmt.Errorf("trailingSlash: expected always, never or auto, got '%s'", cfg.TrailingSlash)
+	}
+
+	if cfg.Platform != nil && cfg.Platform.ApiRuntime != "" && !strings.Contains(cfg.Platform.ApiRuntime, ":") {
+		return fmt.Errorf(
+			"platform: expected an 'apiRuntime' of the form <language>:<version>, ex) node:18, got '%s'",
+			cfg.Platform.ApiRuntime)
+	}
+
+	return nil
+}
+
+// position returns the line and column, starting at 1, of the character of the contents before the offset, ie) of the
+// invalid character of the syntax errors of the json decoder
+func position(contents []byte, offset int64) (int, int) {
+	if offset > int64(len(contents)) {
+		offset = int64(len(contents))
+	}
+
+	before := contents[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+
+	return line, column
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package swa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "Valid",
+			config: `{
+				"routes": [{"route": "/admin/*", "allowedRoles": ["admin"]}, {"route": "/old", "redirect": "/new"}],
+				"navigationFallback": {"rewrite": "/index.html", "exclude": ["/images/*"]},
+				"responseOverrides": {"404": {"rewrite": "/404.html"}},
+				"trailingSlash": "auto",
+				"platform": {"apiRuntime": "node:18"}
+			}`,
+		},
+		{
+			name:    "Syntax",
+			config:  "{\n  \"routes\": [\n    {\"route\": \"/\"},\n  ]\n}",
+			wantErr: "line 4, column 3: invalid character ']' looking for beginning of value",
+		},
+		{
+			name:    "UnknownProperty",
+			config:  `{"route": []}`,
+			wantErr: "unknown property 'route'",
+		},
+		{
+			name:    "MissingRoute",
+			config:  `{"routes": [{"rewrite": "/index.html"}]}`,
+			wantErr: "routes[0]: missing 'route'",
+		},
+		{
+			name:    "RedirectAndRewrite",
+			config:  `{"routes": [{"route": "/", "redirect": "/a", "rewrite": "/b"}]}`,
+			wantErr: "routes[0] '/': 'redirect' and 'rewrite' cannot be set together",
+		},
+		{
+			name:    "RedirectStatusCode",
+			config:  `{"routes": [{"route": "/", "redirect": "/a", "statusCode": 200}]}`,
+			wantErr: "routes[0] '/': the 'statusCode' of a redirect must be 301 or 302",
+		},
+		{
+			name:    "NavigationFallback",
+			config:  `{"navigationFallback": {"exclude": ["/images/*"]}}`,
+			wantErr: "navigationFallback: missing 'rewrite'",
+		},
+		{
+			name:    "ResponseOverrides",
+			config:  `{"responseOverrides": {"500": {"rewrite": "/500.html"}}}`,
+			wantErr: "the response of status code '500' cannot be overridden",
+		},
+		{
+			name:    "TrailingSlash",
+			config:  `{"trailingSlash": "sometimes"}`,
+			wantErr: "trailingSlash: expected always, never or auto, got 'sometimes'",
+		},
+		{
+			name:    "ApiRuntime",
+			config:  `{"platform": {"apiRuntime": "node"}}`,
+			wantErr: "expected an 'apiRuntime' of the form <language>:<version>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig([]byte(tt.config))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_FindConfigFile(t *testing.T) {
+	appPath := t.TempDir()
+	outputPath := filepath.Join(appPath, "build")
+	require.NoError(t, os.MkdirAll(outputPath, 0755))
+
+	path, err := FindConfigFile(outputPath, appPath)
+	require.NoError(t, err)
+	require.Empty(t, path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(appPath, ConfigFileName), []byte("{}"), 0600))
+	path, err = FindConfigFile(outputPath, appPath)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(appPath, ConfigFileName), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputPath, ConfigFileName), []byte("{}"), 0600))
+	path, err = FindConfigFile(outputPath, appPath)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(outputPath, ConfigFileName), path)
+}
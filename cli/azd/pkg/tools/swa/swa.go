@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
@@ -36,9 +37,18 @@ type SwaCli interface {
 		outputRelativeFolderPath string,
 		environment string,
 		deploymentToken string,
+		options DeployOptions,
 	) (string, error)
 }
 
+// DeployOptions are the optional locations of the deploy of a static web app
+type DeployOptions struct {
+	// The folder of the Azure Functions API deployed with the app, relative to the working directory
+	ApiLocation string
+	// The folder of the staticwebapp.config.json file, relative to the working directory
+	ConfigLocation string
+}
+
 type swaCli struct {
 	// commandRunner allows us to stub out the CommandRunner, for testing.
 	commandRunner exec.CommandRunner
@@ -68,6 +78,7 @@ func (cli *swaCli) Deploy(
 	outputRelativeFolderPath string,
 	environment string,
 	deploymentToken string,
+	options DeployOptions,
 ) (string, error) {
 	log.Printf(
 		"SWA Deploy: TenantId: %s, SubscriptionId: %s, ResourceGroup: %s, ResourceName: %s, Environment: %s",
@@ -78,25 +89,62 @@ func (cli *swaCli) Deploy(
 		environment,
 	)
 
-	res, err := cli.executeCommand(ctx,
-		cwd, "deploy",
+	args := []string{
+		"deploy",
 		"--tenant-id", tenantId,
 		"--subscription-id", subscriptionId,
 		"--resource-group", resourceGroup,
 		"--app-name", appName,
 		"--app-location", appFolderPath,
 		"--output-location", outputRelativeFolderPath,
+	}
+
+	if options.ApiLocation != "" {
+		args = append(args, "--api-location", options.ApiLocation)
+	}
+
+	if options.ConfigLocation != "" {
+		args = append(args, "--swa-config-location", options.ConfigLocation)
+	}
+
+	args = append(args,
 		"--env", environment,
 		"--no-use-keychain",
 		"--deployment-token", deploymentToken)
 
+	res, err := cli.executeCommand(ctx, cwd, args...)
 	if err != nil {
+		if reason := deployFailureReason(res.Stdout + "\n" + res.Stderr); reason != "" {
+			return "", fmt.Errorf("swa deploy: %s: %w", reason, err)
+		}
+
 		return "", fmt.Errorf("swa deploy: %w", err)
 	}
 
 	return res.Stdout + res.Stderr, nil
 }
 
+// deployFailureReason returns the reasons the SWA CLI gives for failing a deploy, ex) the app artifacts folder has no
+// default file, otherwise the errors it printed, or an empty reason when the output has none
+func deployFailureReason(output string) string {
+	reasons := []string{}
+	messages := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if _, reason, has := strings.Cut(line, "Failure Reason:"); has {
+			reasons = append(reasons, strings.TrimSpace(reason))
+		} else if message, has := strings.CutPrefix(line, "✖"); has && strings.TrimSpace(message) != "" {
+			messages = append(messages, strings.TrimSpace(message))
+		}
+	}
+
+	if len(reasons) == 0 {
+		reasons = messages
+	}
+
+	return strings.Join(reasons, "; ")
+}
+
 func (cli *swaCli) CheckInstalled(_ context.Context) error {
 
 	return tools.ToolInPath("npx")
@@ -131,6 +131,7 @@ func Test_SwaDeploy(t *testing.T) {
 			"build",
 			"default",
 			"deploymentToken",
+			DeployOptions{},
 		)
 		require.NoError(t, err)
 		require.True(t, ran)
@@ -178,6 +179,7 @@ func Test_SwaDeploy(t *testing.T) {
 			"build",
 			"default",
 			"deploymentToken",
+			DeployOptions{},
 		)
 		require.True(t, ran)
 		require.EqualError(
@@ -187,3 +189,57 @@ func Test_SwaDeploy(t *testing.T) {
 		)
 	})
 }
+
+func Test_SwaDeploy_Options(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	swacli := NewSwaCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "npx")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{
+			"-y", "@azure/static-web-apps-cli@1.0.6",
+			"deploy",
+			"--tenant-id", "tenantID",
+			"--subscription-id", "subscriptionID",
+			"--resource-group", "resourceGroupID",
+			"--app-name", "appName",
+			"--app-location", "service/path",
+			"--output-location", "build",
+			"--api-location", "service/path/api",
+			"--swa-config-location", "service/path/build",
+			"--env", "default",
+			"--no-use-keychain",
+			"--deployment-token", "deploymentToken",
+		}, args.Args)
+
+		return exec.RunResult{
+			Stdout: "Deploying project to Azure Static Web Apps...\n" +
+				"✖ Deployment Failed :(\n" +
+				"✖ Deployment Failure Reason: Failed to find a default file in the app artifacts folder (build).",
+			ExitCode: 1,
+		}, errors.New("exit code: 1")
+	})
+
+	_, err := swacli.Deploy(
+		context.Background(),
+		"./projectPath",
+		"tenantID",
+		"subscriptionID",
+		"resourceGroupID",
+		"appName",
+		"service/path",
+		"build",
+		"default",
+		"deploymentToken",
+		DeployOptions{
+			ApiLocation:    "service/path/api",
+			ConfigLocation: "service/path/build",
+		},
+	)
+	require.EqualError(
+		t,
+		err,
+		"swa deploy: Failed to find a default file in the app artifacts folder (build).: exit code: 1",
+	)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// RequireVerifiedDownloadsEnvVarName is the name of the environment variable which, when set to true, makes azd refuse
+// any downloaded tool that cannot be verified against a checksum manifest, including tools which do not publish one.
+const RequireVerifiedDownloadsEnvVarName = "AZD_REQUIRE_VERIFIED_DOWNLOADS"
+
+// ChecksumManifestEnvVarName is the name of the environment variable holding the URL or the path of a checksum manifest
+// to use instead of the manifests published by the tools, for example one hosted by an enterprise.
+const ChecksumManifestEnvVarName = "AZD_DOWNLOAD_CHECKSUM_MANIFEST"
+
+// ErrUnverifiedDownload is returned when downloaded content could not be verified.
+var ErrUnverifiedDownload = errors.New("downloaded content could not be verified")
+
+// DownloadVerifier verifies downloaded files against checksum manifests in the format produced by `sha256sum`, where each
+// line contains the hex encoded SHA-256 hash of a file followed by its name.
+type DownloadVerifier struct {
+	transporter      policy.Transporter
+	require          bool
+	manifestOverride string
+	// Why verification is required, part of the errors of unverified downloads
+	requireReason string
+}
+
+// NewDownloadVerifier creates a DownloadVerifier configured from the RequireVerifiedDownloadsEnvVarName and
+// ChecksumManifestEnvVarName environment variables, using transporter to fetch manifests.
+func NewDownloadVerifier(transporter policy.Transporter) *DownloadVerifier {
+	require, err := strconv.ParseBool(os.Getenv(RequireVerifiedDownloadsEnvVarName))
+	if err != nil && os.Getenv(RequireVerifiedDownloadsEnvVarName) != "" {
+		// Fail closed, an invalid value is most likely an attempt to turn verification on.
+		log.Printf("invalid value for %s, requiring verification: %v", RequireVerifiedDownloadsEnvVarName, err)
+		require = true
+	}
+
+	return &DownloadVerifier{
+		transporter:      transporter,
+		require:          require,
+		manifestOverride: os.Getenv(ChecksumManifestEnvVarName),
+		requireReason:    fmt.Sprintf("%s is set", RequireVerifiedDownloadsEnvVarName),
+	}
+}
+
+// NewRequiredDownloadVerifier creates a DownloadVerifier which always requires verification against the published
+// manifests, ignoring RequireVerifiedDownloadsEnvVarName and ChecksumManifestEnvVarName, ex) for the binaries replacing
+// azd itself.
+func NewRequiredDownloadVerifier(transporter policy.Transporter, requireReason string) *DownloadVerifier {
+	return &DownloadVerifier{
+		transporter:   transporter,
+		require:       true,
+		requireReason: requireReason,
+	}
+}
+
+// Verify checks the file at path, published as fileName, against the checksum manifest available at manifestUrl. An
+// empty manifestUrl indicates the tool does not publish a manifest. A checksum mismatch is always an error. When no
+// checksum is available for the file, an error is returned only when verification is required.
+func (v *DownloadVerifier) Verify(ctx context.Context, path string, fileName string, manifestUrl string) error {
+	manifestLocation := manifestUrl
+	if v.manifestOverride != "" {
+		manifestLocation = v.manifestOverride
+	}
+
+	unverified := func(reason string) error {
+		if v.require {
+			return fmt.Errorf("%w: %s: %s", ErrUnverifiedDownload, reason, v.requireReason)
+		}
+
+		log.Printf("skipping verification of %s: %s", fileName, reason)
+		return nil
+	}
+
+	if manifestLocation == "" {
+		return unverified(fmt.Sprintf("no checksum manifest is published for %s", fileName))
+	}
+
+	manifest, err := v.loadManifest(ctx, manifestLocation)
+	if err != nil {
+		// A published manifest which can't be read is treated as a failure, the content may have been tampered with.
+		return fmt.Errorf("%w: reading checksum manifest %s: %v", ErrUnverifiedDownload, manifestLocation, err)
+	}
+
+	expected, has := manifest[fileName]
+	if !has {
+		return unverified(fmt.Sprintf("%s is not listed in checksum manifest %s", fileName, manifestLocation))
+	}
+
+	actual, err := fileChecksum(path)
+	if err != nil {
+		return fmt.Errorf("computing checksum of %s: %w", fileName, err)
+	}
+
+	if !strings.EqualFold(expected, actual) {
+		return fmt.Errorf(
+			"%w: checksum of %s is %s, expected %s from %s", ErrUnverifiedDownload, fileName, actual, expected, manifestLocation)
+	}
+
+	log.Printf("verified checksum of %s", fileName)
+	return nil
+}
+
+// loadManifest reads the manifest from an http(s) URL or a local file.
+func (v *DownloadVerifier) loadManifest(ctx context.Context, location string) (map[string]string, error) {
+	var contents []byte
+
+	if strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := v.transporter.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("http error %d", resp.StatusCode)
+		}
+
+		if contents, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if contents, err = os.ReadFile(location); err != nil {
+			return nil, err
+		}
+	}
+
+	return ParseChecksumManifest(contents)
+}
+
+// ParseChecksumManifest parses a manifest in the format produced by `sha256sum`, returning the checksums by file name.
+func ParseChecksumManifest(contents []byte) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksum manifest line '%s'", line)
+		}
+
+		checksum, err := hex.DecodeString(fields[0])
+		if err != nil || len(checksum) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 checksum '%s'", fields[0])
+		}
+
+		// sha256sum marks files hashed in binary mode with a leading '*'
+		checksums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return checksums, nil
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
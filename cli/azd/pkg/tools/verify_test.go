@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksumManifest(t *testing.T) {
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte("content")))
+
+	manifest := fmt.Sprintf("# comment\n%s  tool.tar.gz\n\n%s *tool.zip\n", checksum, checksum)
+
+	checksums, err := ParseChecksumManifest([]byte(manifest))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"tool.tar.gz": checksum,
+		"tool.zip":    checksum,
+	}, checksums)
+
+	_, err = ParseChecksumManifest([]byte("not-a-checksum tool.zip"))
+	require.Error(t, err)
+}
+
+func TestDownloadVerifier(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tool.zip")
+	require.NoError(t, os.WriteFile(path, []byte("content"), osutil.PermissionFile))
+
+	manifestPath := filepath.Join(dir, "checksums.txt")
+	manifest := fmt.Sprintf("%x  tool.zip\n%x  other.zip\n", sha256.Sum256([]byte("content")), sha256.Sum256([]byte("other")))
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), osutil.PermissionFile))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	ctx := *mockContext.Context
+
+	t.Run("Verified", func(t *testing.T) {
+		t.Setenv(ChecksumManifestEnvVarName, manifestPath)
+		t.Setenv(RequireVerifiedDownloadsEnvVarName, "true")
+
+		require.NoError(t, NewDownloadVerifier(mockContext.HttpClient).Verify(ctx, path, "tool.zip", ""))
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		t.Setenv(ChecksumManifestEnvVarName, manifestPath)
+
+		err := NewDownloadVerifier(mockContext.HttpClient).Verify(ctx, path, "other.zip", "")
+		require.ErrorIs(t, err, ErrUnverifiedDownload)
+	})
+
+	t.Run("NoManifest", func(t *testing.T) {
+		require.NoError(t, NewDownloadVerifier(mockContext.HttpClient).Verify(ctx, path, "tool.zip", ""))
+
+		t.Setenv(RequireVerifiedDownloadsEnvVarName, "true")
+		err := NewDownloadVerifier(mockContext.HttpClient).Verify(ctx, path, "tool.zip", "")
+		require.ErrorIs(t, err, ErrUnverifiedDownload)
+	})
+
+	t.Run("NotListed", func(t *testing.T) {
+		t.Setenv(ChecksumManifestEnvVarName, manifestPath)
+		t.Setenv(RequireVerifiedDownloadsEnvVarName, "true")
+
+		err := NewDownloadVerifier(mockContext.HttpClient).Verify(ctx, path, "missing.zip", "")
+		require.ErrorIs(t, err, ErrUnverifiedDownload)
+	})
+}
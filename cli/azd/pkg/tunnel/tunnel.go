@@ -0,0 +1,372 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	azdexec "github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// JumpBoxHostEnvVarName is the name of the key used to store the public host name, or IP address, of the jump box to ssh
+// to. It's usually an output of the infrastructure of the project.
+const JumpBoxHostEnvVarName = "AZURE_JUMPBOX_HOST"
+
+// JumpBoxUserEnvVarName is the name of the key used to store the user to sign in to the jump box as.
+const JumpBoxUserEnvVarName = "AZURE_JUMPBOX_USER"
+
+// JumpBoxNameEnvVarName is the name of the key used to store the name of the jump box virtual machine, when the resource
+// group of the environment has more than one.
+const JumpBoxNameEnvVarName = "AZURE_JUMPBOX_NAME"
+
+const defaultJumpBoxUser = "azureuser"
+
+// how long to wait for the Bastion tunnel to accept connections
+const bastionTunnelTimeout = 30 * time.Second
+
+// Target is a kind of data service tunnels are opened to.
+type Target struct {
+	ResourceType infra.AzureResourceType
+	// the host name of a server is its name followed by the suffix
+	HostSuffix string
+	Port       int
+	// the format of the connection string to the local port, where %d is the port
+	ConnectionString string
+}
+
+// Targets are the data services tunnels can be opened to, by name.
+var Targets = map[string]Target{
+	"postgres": {
+		ResourceType:     infra.AzureResourceTypePostgreSqlServer,
+		HostSuffix:       ".postgres.database.azure.com",
+		Port:             5432,
+		ConnectionString: "postgresql://localhost:%d/postgres?sslmode=require",
+	},
+	"mysql": {
+		ResourceType:     infra.AzureResourceTypeMySqlServer,
+		HostSuffix:       ".mysql.database.azure.com",
+		Port:             3306,
+		ConnectionString: "mysql://localhost:%d/?ssl-mode=REQUIRED",
+	},
+	"sql": {
+		ResourceType:     infra.AzureResourceTypeSqlServer,
+		HostSuffix:       ".database.windows.net",
+		Port:             1433,
+		ConnectionString: "Server=tcp:localhost,%d;Encrypt=True;TrustServerCertificate=True",
+	},
+	"redis": {
+		ResourceType:     infra.AzureResourceTypeCacheForRedis,
+		HostSuffix:       ".redis.cache.windows.net",
+		Port:             6380,
+		ConnectionString: "rediss://localhost:%d",
+	},
+}
+
+// TargetNames returns the sorted names of the targets.
+func TargetNames() []string {
+	names := maps.Keys(Targets)
+	slices.Sort(names)
+	return names
+}
+
+// Via is how tunnels reach the network of the data service.
+type Via string
+
+const (
+	// ssh to the public host of a jump box
+	ViaSsh Via = "ssh"
+	// ssh to a jump box over a native client tunnel of Azure Bastion, which requires the Azure CLI
+	ViaBastion Via = "bastion"
+)
+
+// Tunnel is a route from a local port to a data service of the environment, through a jump box in its network.
+type Tunnel struct {
+	Target     Target
+	ServerName string
+	RemoteHost string
+	Via        Via
+	// The user to sign in to the jump box as
+	JumpBoxUser string
+	// The public host of the jump box, when via ssh
+	JumpBoxHost string
+	// The Bastion, and the id of the jump box virtual machine, when via Bastion
+	ResourceGroup string
+	BastionName   string
+	JumpBoxId     string
+}
+
+// ConnectionString returns the connection string to the data service, through the local port of the tunnel.
+func (t *Tunnel) ConnectionString(localPort int) string {
+	return fmt.Sprintf(t.Target.ConnectionString, localPort)
+}
+
+type Manager struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager project.ResourceManager
+	sshCli          ssh.SshCli
+	commandRunner   azdexec.CommandRunner
+}
+
+func NewManager(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager project.ResourceManager,
+	sshCli ssh.SshCli,
+	commandRunner azdexec.CommandRunner,
+) *Manager {
+	return &Manager{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		sshCli:          sshCli,
+		commandRunner:   commandRunner,
+	}
+}
+
+// Resolve finds the server of the target, and the jump box to reach it through, in the resource group of the
+// environment. serverName is required when the resource group has more than one server of the target. When via is
+// empty, tunnels are via ssh when the host of the jump box is set in the environment, otherwise via Bastion.
+func (m *Manager) Resolve(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	targetName string,
+	serverName string,
+	via Via,
+) (*Tunnel, error) {
+	target, has := Targets[targetName]
+	if !has {
+		return nil, fmt.Errorf(
+			"target '%s' is not supported, supported targets are %s", targetName, strings.Join(TargetNames(), ", "))
+	}
+
+	subscriptionId := m.env.GetSubscriptionId()
+	if subscriptionId == "" {
+		return nil, errors.New("infrastructure has not been provisioned. Run `azd provision`")
+	}
+
+	resourceGroup, err := m.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	server, err := m.findResource(ctx, subscriptionId, resourceGroup, target.ResourceType, serverName, "--name")
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel := &Tunnel{
+		Target:        target,
+		ServerName:    server.Name,
+		RemoteHost:    server.Name + target.HostSuffix,
+		Via:           via,
+		JumpBoxUser:   m.env.Getenv(JumpBoxUserEnvVarName),
+		JumpBoxHost:   m.env.Getenv(JumpBoxHostEnvVarName),
+		ResourceGroup: resourceGroup,
+	}
+
+	if tunnel.JumpBoxUser == "" {
+		tunnel.JumpBoxUser = defaultJumpBoxUser
+	}
+
+	if tunnel.Via == "" {
+		tunnel.Via = ViaBastion
+		if tunnel.JumpBoxHost != "" {
+			tunnel.Via = ViaSsh
+		}
+	}
+
+	switch tunnel.Via {
+	case ViaSsh:
+		if tunnel.JumpBoxHost == "" {
+			return nil, fmt.Errorf(
+				"the host of the jump box is not set. Set %s in the environment, ex) as an output of the infrastructure",
+				JumpBoxHostEnvVarName)
+		}
+	case ViaBastion:
+		bastion, err := m.findResource(ctx, subscriptionId, resourceGroup, infra.AzureResourceTypeBastionHost, "", "")
+		if err != nil {
+			return nil, err
+		}
+
+		jumpBox, err := m.findResource(
+			ctx,
+			subscriptionId,
+			resourceGroup,
+			infra.AzureResourceTypeVirtualMachine,
+			m.env.Getenv(JumpBoxNameEnvVarName),
+			JumpBoxNameEnvVarName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tunnel.BastionName = bastion.Name
+		tunnel.JumpBoxId = jumpBox.Id
+	default:
+		return nil, fmt.Errorf("'%s' is not supported, tunnels are via %s or %s", tunnel.Via, ViaSsh, ViaBastion)
+	}
+
+	return tunnel, nil
+}
+
+// findResource finds the resource of the type in the resource group, by name when set. selector is how users set the
+// name, when the resource group has more than one resource of the type.
+func (m *Manager) findResource(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	resourceType infra.AzureResourceType,
+	name string,
+	selector string,
+) (*azcli.AzCliResource, error) {
+	filter := fmt.Sprintf("resourceType eq '%s'", resourceType)
+	resources, err := m.azCli.ListResourceGroupResources(
+		ctx,
+		subscriptionId,
+		resourceGroup,
+		&azcli.ListResourceGroupResourcesOptions{
+			Filter: &filter,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing resources of type %s: %w", resourceType, err)
+	}
+
+	displayName := infra.GetResourceTypeDisplayName(resourceType)
+	if name != "" {
+		for _, resource := range resources {
+			if strings.EqualFold(resource.Name, name) {
+				return &resource, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%s '%s' was not found in resource group %s", displayName, name, resourceGroup)
+	}
+
+	switch {
+	case len(resources) == 0:
+		return nil, fmt.Errorf("no %s was found in resource group %s", displayName, resourceGroup)
+	case len(resources) > 1 && selector != "":
+		names := []string{}
+		for _, resource := range resources {
+			names = append(names, resource.Name)
+		}
+
+		return nil, fmt.Errorf(
+			"resource group %s has more than one %s (%s), select one with %s",
+			resourceGroup, displayName, strings.Join(names, ", "), selector)
+	case len(resources) > 1:
+		log.Printf("resource group %s has more than one %s, using %s", resourceGroup, displayName, resources[0].Name)
+	}
+
+	return &resources[0], nil
+}
+
+// Open forwards the local port to the data service, until the context is cancelled or the connection to the jump box
+// ends.
+func (m *Manager) Open(ctx context.Context, tunnel *Tunnel, localPort int) error {
+	if err := tools.EnsureInstalled(ctx, m.sshCli); err != nil {
+		return err
+	}
+
+	forwardOptions := ssh.ForwardOptions{
+		LocalPort:  localPort,
+		RemoteHost: tunnel.RemoteHost,
+		RemotePort: tunnel.Target.Port,
+		Host:       tunnel.JumpBoxHost,
+		User:       tunnel.JumpBoxUser,
+	}
+
+	if tunnel.Via == ViaSsh {
+		return m.sshCli.Forward(ctx, forwardOptions)
+	}
+
+	if _, err := exec.LookPath("az"); err != nil {
+		return fmt.Errorf(
+			"tunnels via Bastion require the Azure CLI, install it from https://aka.ms/azure-cli: %w", err)
+	}
+
+	bastionPort, err := freePort()
+	if err != nil {
+		return fmt.Errorf("finding a free port for the Bastion tunnel: %w", err)
+	}
+
+	bastionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	bastionErr := make(chan error, 1)
+	go func() {
+		runArgs := azdexec.NewRunArgs(
+			"az", "network", "bastion", "tunnel",
+			"--name", tunnel.BastionName,
+			"--resource-group", tunnel.ResourceGroup,
+			"--target-resource-id", tunnel.JumpBoxId,
+			"--resource-port", "22",
+			"--port", strconv.Itoa(bastionPort),
+		)
+		_, err := m.commandRunner.Run(bastionCtx, runArgs)
+		bastionErr <- err
+	}()
+
+	if err := waitForPort(bastionCtx, bastionPort, bastionErr); err != nil {
+		return fmt.Errorf("opening Bastion tunnel to %s: %w", tunnel.JumpBoxId, err)
+	}
+
+	forwardOptions.Host = "localhost"
+	forwardOptions.Port = bastionPort
+
+	return m.sshCli.Forward(ctx, forwardOptions)
+}
+
+// waitForPort waits for the local port to accept connections, the process listening on it to fail or the timeout.
+func waitForPort(ctx context.Context, port int, processErr <-chan error) error {
+	address := net.JoinHostPort("localhost", strconv.Itoa(port))
+	timeout := time.After(bastionTunnelTimeout)
+
+	for {
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case err := <-processErr:
+			if err == nil {
+				err = errors.New("the tunnel closed")
+			}
+			return err
+		case <-timeout:
+			return fmt.Errorf("port %d did not accept connections in %s", port, bastionTunnelTimeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
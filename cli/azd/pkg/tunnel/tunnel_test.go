@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+const tunnelRgId = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev"
+
+func Test_Resolve(t *testing.T) {
+	resources := map[string][]string{
+		"Microsoft.DBforPostgreSQL/flexibleServers": {"psql-dev"},
+		"Microsoft.DBforMySQL/flexibleServers":      {"mysql-a", "mysql-b"},
+		"Microsoft.Network/bastionHosts":            {"bas-dev"},
+		"Microsoft.Compute/virtualMachines":         {"vm-jump"},
+	}
+
+	newManager := func(t *testing.T, values map[string]string) *Manager {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/rg-dev/resources")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			resourceType := strings.TrimSuffix(
+				strings.TrimPrefix(request.URL.Query().Get("$filter"), "resourceType eq '"), "'")
+
+			result := armresources.ResourceListResult{}
+			for _, name := range resources[resourceType] {
+				result.Value = append(result.Value, &armresources.GenericResourceExpanded{
+					ID:       convert.RefOf(fmt.Sprintf("%s/providers/%s/%s", tunnelRgId, resourceType, name)),
+					Name:     convert.RefOf(name),
+					Type:     convert.RefOf(resourceType),
+					Location: convert.RefOf("eastus2"),
+				})
+			}
+
+			body, err := json.Marshal(result)
+			if err != nil {
+				return nil, err
+			}
+
+			return &http.Response{
+				Request:    request,
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBuffer(body)),
+			}, nil
+		})
+
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.ResourceGroupEnvVarName:  "rg-dev",
+		})
+		for key, value := range values {
+			env.DotenvSet(key, value)
+		}
+
+		azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+		return NewManager(
+			env,
+			azCli,
+			project.NewResourceManager(env, azCli),
+			ssh.NewSshCli(mockContext.CommandRunner),
+			mockContext.CommandRunner,
+		)
+	}
+
+	t.Run("Bastion", func(t *testing.T) {
+		manager := newManager(t, nil)
+		tunnel, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "postgres", "", "")
+		require.NoError(t, err)
+
+		require.Equal(t, ViaBastion, tunnel.Via)
+		require.Equal(t, "psql-dev", tunnel.ServerName)
+		require.Equal(t, "psql-dev.postgres.database.azure.com", tunnel.RemoteHost)
+		require.Equal(t, "bas-dev", tunnel.BastionName)
+		require.Equal(t, tunnelRgId+"/providers/Microsoft.Compute/virtualMachines/vm-jump", tunnel.JumpBoxId)
+		require.Equal(t, "azureuser", tunnel.JumpBoxUser)
+		require.Equal(t, "postgresql://localhost:15432/postgres?sslmode=require", tunnel.ConnectionString(15432))
+	})
+
+	t.Run("Ssh", func(t *testing.T) {
+		manager := newManager(t, map[string]string{
+			JumpBoxHostEnvVarName: "jump.eastus2.cloudapp.azure.com",
+			JumpBoxUserEnvVarName: "admin",
+		})
+		tunnel, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "postgres", "", "")
+		require.NoError(t, err)
+
+		require.Equal(t, ViaSsh, tunnel.Via)
+		require.Equal(t, "jump.eastus2.cloudapp.azure.com", tunnel.JumpBoxHost)
+		require.Equal(t, "admin", tunnel.JumpBoxUser)
+		require.Empty(t, tunnel.BastionName)
+	})
+
+	t.Run("SshWithoutHost", func(t *testing.T) {
+		manager := newManager(t, nil)
+		_, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "postgres", "", ViaSsh)
+		require.ErrorContains(t, err, JumpBoxHostEnvVarName)
+	})
+
+	t.Run("MoreThanOneServer", func(t *testing.T) {
+		manager := newManager(t, nil)
+		_, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "mysql", "", "")
+		require.ErrorContains(t, err, "more than one")
+		require.ErrorContains(t, err, "--name")
+
+		tunnel, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "mysql", "mysql-b", "")
+		require.NoError(t, err)
+		require.Equal(t, "mysql-b.mysql.database.azure.com", tunnel.RemoteHost)
+	})
+
+	t.Run("NoServer", func(t *testing.T) {
+		manager := newManager(t, nil)
+		_, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "redis", "", "")
+		require.ErrorContains(t, err, "no Cache for Redis was found in resource group rg-dev")
+	})
+
+	t.Run("UnsupportedTarget", func(t *testing.T) {
+		manager := newManager(t, nil)
+		_, err := manager.Resolve(context.Background(), &project.ProjectConfig{}, "mongo", "", "")
+		require.ErrorContains(t, err, "supported targets are mysql, postgres, redis, sql")
+	})
+}
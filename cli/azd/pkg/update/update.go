@@ -0,0 +1,405 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package update provides functionality to check for new versions of azd and to upgrade azd in place.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/offline"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/installer"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/blang/semver/v4"
+)
+
+// Channel is a release channel of azd
+type Channel string
+
+const (
+	// ChannelStable contains the generally available releases of azd
+	ChannelStable Channel = "stable"
+	// ChannelBeta contains the pre-releases of azd
+	ChannelBeta Channel = "beta"
+)
+
+// ChannelConfigKey is the user configuration key holding the channel used for update checks and upgrades
+const ChannelConfigKey = "updates.channel"
+
+// CheckConfigKey is the user configuration key which, when set to false, disables the daily new version notice
+const CheckConfigKey = "updates.check"
+
+// The location the standalone releases of azd are published to, as used by the install scripts
+const releaseBaseUrl = "https://azdrelease.azureedge.net/azd/standalone/release"
+
+// ParseChannel parses the name of a release channel
+func ParseChannel(value string) (Channel, error) {
+	switch Channel(strings.ToLower(value)) {
+	case ChannelStable:
+		return ChannelStable, nil
+	case ChannelBeta:
+		return ChannelBeta, nil
+	default:
+		return "", fmt.Errorf("invalid channel '%s', supported channels are '%s' and '%s'", value, ChannelStable, ChannelBeta)
+	}
+}
+
+// ChannelFromConfig returns the release channel configured in the user configuration, defaulting to the stable channel
+func ChannelFromConfig(userConfig config.Config) (Channel, error) {
+	value, has := userConfig.Get(ChannelConfigKey)
+	if !has {
+		return ChannelStable, nil
+	}
+
+	channel, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid value for '%s', expected a string", ChannelConfigKey)
+	}
+
+	return ParseChannel(channel)
+}
+
+// CheckEnabled returns false when the new version notice has been disabled in the user configuration
+func CheckEnabled(userConfig config.Config) bool {
+	value, has := userConfig.Get(CheckConfigKey)
+	if !has {
+		return true
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return !strings.EqualFold(v, "false") && !strings.EqualFold(v, "off")
+	default:
+		return true
+	}
+}
+
+// VersionUrl returns the URL which returns the latest version of azd available in the channel
+func VersionUrl(channel Channel) string {
+	if channel == ChannelBeta {
+		return "https://aka.ms/azure-dev/versions/cli/beta"
+	}
+
+	return "https://aka.ms/azure-dev/versions/cli/latest"
+}
+
+// SupportsSelfUpdate returns true when azd was installed by one of the install scripts and can replace its own binary.
+// Installations managed by a package manager are upgraded with that package manager instead.
+func SupportsSelfUpdate() bool {
+	switch installer.InstalledBy() {
+	case installer.InstallTypeSh, installer.InstallTypePs:
+		return true
+	default:
+		return false
+	}
+}
+
+// UpgradeInstructions describes how to upgrade azd, based on how it was installed
+func UpgradeInstructions() string {
+	if SupportsSelfUpdate() {
+		return "run:\nazd upgrade"
+	}
+
+	installedBy := installer.InstalledBy()
+	switch runtime.GOOS {
+	case "windows":
+		switch installedBy {
+		case installer.InstallTypeWinget:
+			return "run:\nwinget upgrade Microsoft.Azd"
+		case installer.InstallTypeChoco:
+			return "run:\nchoco upgrade azd"
+		default:
+			// Also covers "msi" case where the user installed directly via MSI
+			return "visit https://aka.ms/azd/upgrade/windows"
+		}
+	case "linux":
+		// Also covers "deb" and "rpm" cases which are currently documented. When package manager distribution support is
+		// added, this will need to be updated.
+		return "visit https://aka.ms/azd/upgrade/linux"
+	case "darwin":
+		if installedBy == installer.InstallTypeBrew {
+			return "run:\nbrew update && brew upgrade azd"
+		}
+
+		return "visit https://aka.ms/azd/upgrade/mac"
+	default:
+		// Platform is not recognized, use the generic install link
+		return "visit https://aka.ms/azd/upgrade"
+	}
+}
+
+// Manager checks for and installs new versions of azd
+type Manager struct {
+	httpClient httputil.HttpClient
+}
+
+// NewManager creates a new Manager. Requests are served from the offline mirror when offline mode is enabled.
+func NewManager(httpClient httputil.HttpClient) *Manager {
+	return &Manager{
+		httpClient: offline.NewTransporter(httpClient),
+	}
+}
+
+// LatestVersion returns the latest version of azd available in the channel
+func (m *Manager) LatestVersion(ctx context.Context, channel Channel) (semver.Version, error) {
+	body, err := m.get(ctx, VersionUrl(channel))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("fetching latest version: %w", err)
+	}
+
+	versionText := strings.TrimSpace(string(body))
+	version, err := semver.Parse(versionText)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("parsing latest version '%s': %w", versionText, err)
+	}
+
+	return version, nil
+}
+
+// ReleaseNotes returns the release notes of the given version of azd, formatted as markdown
+func (m *Manager) ReleaseNotes(ctx context.Context, version semver.Version) (string, error) {
+	releaseUrl := fmt.Sprintf("https://api.github.com/repos/Azure/azure-dev/releases/tags/azure-dev-cli_%s", version)
+
+	body, err := m.get(ctx, releaseUrl)
+	if err != nil {
+		return "", fmt.Errorf("fetching release notes: %w", err)
+	}
+
+	var release struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("parsing release notes: %w", err)
+	}
+
+	return strings.TrimSpace(release.Body), nil
+}
+
+// Update downloads the given version of azd, verifies it against the checksums published with the release and replaces
+// the executable at exePath with it.
+func (m *Manager) Update(ctx context.Context, version semver.Version, exePath string) error {
+	archiveName, binaryName, err := releaseArtifact()
+	if err != nil {
+		return err
+	}
+
+	releaseUrl := fmt.Sprintf("%s/%s", releaseBaseUrl, version)
+	exeDir := filepath.Dir(exePath)
+
+	archive, err := os.CreateTemp(exeDir, fmt.Sprintf("%s.tmp*", archiveName))
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+
+	log.Printf("downloading azd release %s/%s -> %s", releaseUrl, archiveName, archive.Name())
+	if err := m.download(ctx, fmt.Sprintf("%s/%s", releaseUrl, archiveName), archive); err != nil {
+		return fmt.Errorf("downloading azd %s: %w", version, err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	// The upgraded binary is always verified against the published checksums, regardless of
+	// tools.RequireVerifiedDownloadsEnvVarName and tools.ChecksumManifestEnvVarName
+	verifier := tools.NewRequiredDownloadVerifier(m.httpClient, "updates of azd are always verified")
+	if err := verifier.Verify(ctx, archive.Name(), archiveName, fmt.Sprintf("%s/checksums.txt", releaseUrl)); err != nil {
+		return err
+	}
+
+	newExePath := exePath + ".new"
+	if err := extractFile(archive.Name(), archiveName, binaryName, newExePath); err != nil {
+		return fmt.Errorf("extracting %s: %w", binaryName, err)
+	}
+	defer func() {
+		_ = os.Remove(newExePath)
+	}()
+
+	return replaceExecutable(ctx, exePath, newExePath)
+}
+
+func (m *Manager) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.UserAgent())
+
+	res, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error %d", res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (m *Manager) download(ctx context.Context, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.UserAgent())
+
+	res, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("http error %d", res.StatusCode)
+	}
+
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// releaseArtifact returns the name of the release archive for the current platform and the name of the azd binary it
+// contains, following the naming used by the install scripts.
+func releaseArtifact() (archiveName string, binaryName string, err error) {
+	switch runtime.GOARCH {
+	case "amd64", "arm64":
+	default:
+		return "", "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	}
+
+	platform := fmt.Sprintf("azd-%s-%s", runtime.GOOS, runtime.GOARCH)
+
+	switch runtime.GOOS {
+	case "windows":
+		return platform + ".zip", platform + ".exe", nil
+	case "darwin":
+		return platform + ".zip", platform, nil
+	case "linux":
+		return platform + ".tar.gz", platform, nil
+	default:
+		return "", "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+// extractFile extracts the file named fileName from the zip or tar.gz archive at archivePath, writing it to dst.
+func extractFile(archivePath string, archiveName string, fileName string, dst string) error {
+	var src io.Reader
+
+	if strings.HasSuffix(archiveName, ".zip") {
+		zipReader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer zipReader.Close()
+
+		for _, file := range zipReader.File {
+			if filepath.Base(file.Name) != fileName || file.FileInfo().IsDir() {
+				continue
+			}
+
+			fileReader, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer fileReader.Close()
+
+			src = fileReader
+			break
+		}
+	} else {
+		archive, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+
+		gzipReader, err := gzip.NewReader(archive)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == fileName {
+				src = tarReader
+				break
+			}
+		}
+	}
+
+	if src == nil {
+		return fmt.Errorf("%s not found in %s", fileName, archiveName)
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, osutil.PermissionExecutableFile)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	/* #nosec G110 - decompression bomb false positive, the archive has been verified */
+	if _, err := io.Copy(dstFile, src); err != nil {
+		return err
+	}
+
+	return dstFile.Close()
+}
+
+// replaceExecutable replaces the executable at exePath with the one at newExePath. The running executable can't be
+// overwritten on Windows, but it can be renamed, so the current executable is moved aside first.
+func replaceExecutable(ctx context.Context, exePath string, newExePath string) error {
+	oldExePath := exePath + ".old"
+	_ = os.Remove(oldExePath)
+
+	if err := osutil.Rename(ctx, exePath, oldExePath); err != nil {
+		return fmt.Errorf("moving current executable: %w", err)
+	}
+
+	if err := osutil.Rename(ctx, newExePath, exePath); err != nil {
+		// Restore the current executable so azd remains usable
+		if restoreErr := osutil.Rename(ctx, oldExePath, exePath); restoreErr != nil {
+			log.Printf("failed to restore %s: %v", exePath, restoreErr)
+		}
+
+		return fmt.Errorf("replacing executable: %w", err)
+	}
+
+	if err := os.Remove(oldExePath); err != nil {
+		// Expected on Windows while the previous version is still running, it is removed by the next upgrade.
+		log.Printf("failed to remove previous executable %s: %v", oldExePath, err)
+	}
+
+	return nil
+}
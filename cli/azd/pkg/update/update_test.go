@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelFromConfig(t *testing.T) {
+	channel, err := ChannelFromConfig(config.NewEmptyConfig())
+	require.NoError(t, err)
+	require.Equal(t, ChannelStable, channel)
+
+	channel, err = ChannelFromConfig(config.NewConfig(map[string]any{"updates": map[string]any{"channel": "Beta"}}))
+	require.NoError(t, err)
+	require.Equal(t, ChannelBeta, channel)
+
+	_, err = ChannelFromConfig(config.NewConfig(map[string]any{"updates": map[string]any{"channel": "nightly"}}))
+	require.Error(t, err)
+}
+
+func TestCheckEnabled(t *testing.T) {
+	require.True(t, CheckEnabled(config.NewEmptyConfig()))
+	require.False(t, CheckEnabled(config.NewConfig(map[string]any{"updates": map[string]any{"check": false}})))
+	require.False(t, CheckEnabled(config.NewConfig(map[string]any{"updates": map[string]any{"check": "false"}})))
+	require.True(t, CheckEnabled(config.NewConfig(map[string]any{"updates": map[string]any{"check": "true"}})))
+}
+
+func TestLatestVersion(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.String() == VersionUrl(ChannelBeta)
+	}).Respond(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString("1.2.0-beta.1\n")),
+	})
+
+	version, err := NewManager(mockContext.HttpClient).LatestVersion(*mockContext.Context, ChannelBeta)
+	require.NoError(t, err)
+	require.Equal(t, semver.MustParse("1.2.0-beta.1"), version)
+}
+
+func TestUpdate(t *testing.T) {
+	archiveName, binaryName, err := releaseArtifact()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	version := semver.MustParse("1.2.0")
+	archive := createReleaseArchive(t, archiveName, binaryName, "new azd")
+
+	verified := fmt.Sprintf("%x  %s\n", sha256.Sum256(archive), archiveName)
+	tests := []struct {
+		name     string
+		manifest string
+		// The manifest of tools.ChecksumManifestEnvVarName, ignored by updates
+		manifestOverride string
+		expected         string
+	}{
+		{name: "Verified", manifest: verified, expected: "new azd"},
+		{
+			name:     "ChecksumMismatch",
+			manifest: fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("tampered")), archiveName),
+			expected: "old azd",
+		},
+		{
+			name:     "MissingEntry",
+			manifest: fmt.Sprintf("%x  %s\n", sha256.Sum256(archive), "other-"+archiveName),
+			expected: "old azd",
+		},
+		{
+			name:             "ManifestOverrideIgnored",
+			manifest:         fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("tampered")), archiveName),
+			manifestOverride: verified,
+			expected:         "old azd",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Verification isn't required by the environment, updates are verified regardless
+			t.Setenv(tools.RequireVerifiedDownloadsEnvVarName, "false")
+			t.Setenv(tools.ChecksumManifestEnvVarName, "")
+			if test.manifestOverride != "" {
+				manifestPath := filepath.Join(t.TempDir(), "checksums.txt")
+				require.NoError(t, os.WriteFile(manifestPath, []byte(test.manifestOverride), 0600))
+				t.Setenv(tools.ChecksumManifestEnvVarName, manifestPath)
+			}
+
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return strings.HasSuffix(request.URL.Path, fmt.Sprintf("/%s/%s", version, archiveName))
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(archive))}, nil
+			})
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return strings.HasSuffix(request.URL.Path, fmt.Sprintf("/%s/checksums.txt", version))
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(test.manifest))}, nil
+			})
+
+			exePath := filepath.Join(t.TempDir(), "azd")
+			require.NoError(t, os.WriteFile(exePath, []byte("old azd"), 0700))
+
+			err := NewManager(mockContext.HttpClient).Update(*mockContext.Context, version, exePath)
+			if test.expected == "old azd" {
+				require.ErrorIs(t, err, tools.ErrUnverifiedDownload)
+			} else {
+				require.NoError(t, err)
+			}
+
+			contents, err := os.ReadFile(exePath)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(contents))
+
+			entries, err := os.ReadDir(filepath.Dir(exePath))
+			require.NoError(t, err)
+			require.Len(t, entries, 1, "temporary files should be removed")
+		})
+	}
+}
+
+// createReleaseArchive creates a release archive containing a single binary with the given contents
+func createReleaseArchive(t *testing.T, archiveName string, binaryName string, contents string) []byte {
+	var buf bytes.Buffer
+
+	if strings.HasSuffix(archiveName, ".zip") {
+		zipWriter := zip.NewWriter(&buf)
+		w, err := zipWriter.Create(binaryName)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(contents))
+		require.NoError(t, err)
+		require.NoError(t, zipWriter.Close())
+
+		return buf.Bytes()
+	}
+
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Name:     binaryName,
+		Mode:     0755,
+		Size:     int64(len(contents)),
+		Typeflag: tar.TypeReg,
+	}))
+	_, err := tarWriter.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
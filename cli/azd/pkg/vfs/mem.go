@@ -0,0 +1,279 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errIsDir       = errors.New("is a directory")
+	errNotDir      = errors.New("not a directory")
+	errDirNotEmpty = errors.New("directory not empty")
+)
+
+// MemFs is a file system backed by memory. Relative and absolute paths are separate trees, both of which have an
+// existing root directory.
+type MemFs struct {
+	entries map[string]*memEntry
+	lock    sync.RWMutex
+}
+
+type memEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (e *memEntry) isDir() bool {
+	return e.mode.IsDir()
+}
+
+// NewMemFs returns an empty file system backed by memory.
+func NewMemFs() *MemFs {
+	return &MemFs{
+		entries: map[string]*memEntry{},
+	}
+}
+
+func (m *MemFs) ReadFile(name string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	entry, err := m.entry("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.isDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+
+	return append([]byte{}, entry.data...), nil
+}
+
+func (m *MemFs) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path := filepath.Clean(name)
+	if err := m.ensureParent("open", path); err != nil {
+		return err
+	}
+
+	if entry, has := m.entries[path]; has && entry.isDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	}
+
+	m.entries[path] = &memEntry{
+		data:    append([]byte{}, data...),
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+// WriteFileDurable is WriteFile, the memory holds the data once it returns
+func (m *MemFs) WriteFileDurable(name string, data []byte, perm fs.FileMode) error {
+	return m.WriteFile(name, data, perm)
+}
+
+func (m *MemFs) Stat(name string) (fs.FileInfo, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	entry, err := m.entry("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memFileInfo{name: filepath.Base(filepath.Clean(name)), entry: entry}, nil
+}
+
+func (m *MemFs) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	entry, err := m.entry("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.isDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: errNotDir}
+	}
+
+	path := filepath.Clean(name)
+	entries := []fs.DirEntry{}
+	for childPath, child := range m.entries {
+		if childPath != path && filepath.Dir(childPath) == path {
+			entries = append(entries, fs.FileInfoToDirEntry(&memFileInfo{name: filepath.Base(childPath), entry: child}))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (m *MemFs) Mkdir(name string, perm fs.FileMode) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path := filepath.Clean(name)
+	if _, err := m.entry("mkdir", path); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+
+	if err := m.ensureParent("mkdir", path); err != nil {
+		return err
+	}
+
+	m.mkdir(path, perm)
+	return nil
+}
+
+func (m *MemFs) MkdirAll(path string, perm fs.FileMode) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.mkdirAll(filepath.Clean(path), perm)
+}
+
+func (m *MemFs) Remove(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path := filepath.Clean(name)
+	entry, has := m.entries[path]
+	if !has {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if entry.isDir() {
+		for childPath := range m.entries {
+			if filepath.Dir(childPath) == path && childPath != path {
+				return &fs.PathError{Op: "remove", Path: name, Err: errDirNotEmpty}
+			}
+		}
+	}
+
+	delete(m.entries, path)
+	return nil
+}
+
+func (m *MemFs) RemoveAll(path string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path = filepath.Clean(path)
+	prefix := path + string(filepath.Separator)
+	if isRoot(path) {
+		prefix = path
+	}
+
+	for entryPath := range m.entries {
+		if entryPath == path || strings.HasPrefix(entryPath, prefix) {
+			delete(m.entries, entryPath)
+		}
+	}
+
+	return nil
+}
+
+// entry returns the entry of the path, including the implicit root directories.
+func (m *MemFs) entry(op string, name string) (*memEntry, error) {
+	path := filepath.Clean(name)
+	if isRoot(path) {
+		return &memEntry{mode: fs.ModeDir | 0755}, nil
+	}
+
+	entry, has := m.entries[path]
+	if !has {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return entry, nil
+}
+
+// ensureParent returns an error unless the parent directory of the path exists.
+func (m *MemFs) ensureParent(op string, path string) error {
+	parent, err := m.entry(op, filepath.Dir(path))
+	if err != nil {
+		return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+
+	if !parent.isDir() {
+		return &fs.PathError{Op: op, Path: path, Err: errNotDir}
+	}
+
+	return nil
+}
+
+func (m *MemFs) mkdirAll(path string, perm fs.FileMode) error {
+	if entry, err := m.entry("mkdir", path); err == nil {
+		if !entry.isDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: errNotDir}
+		}
+
+		return nil
+	}
+
+	if err := m.mkdirAll(filepath.Dir(path), perm); err != nil {
+		return err
+	}
+
+	m.mkdir(path, perm)
+	return nil
+}
+
+func (m *MemFs) mkdir(path string, perm fs.FileMode) {
+	m.entries[path] = &memEntry{
+		mode:    fs.ModeDir | perm.Perm(),
+		modTime: time.Now(),
+	}
+}
+
+// isRoot reports whether the cleaned path is the root of a tree, ex) "/", "C:\" or "."
+func isRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+type memFileInfo struct {
+	name  string
+	entry *memEntry
+}
+
+func (i *memFileInfo) Name() string {
+	return i.name
+}
+
+func (i *memFileInfo) Size() int64 {
+	return int64(len(i.entry.data))
+}
+
+func (i *memFileInfo) Mode() fs.FileMode {
+	return i.entry.mode
+}
+
+func (i *memFileInfo) ModTime() time.Time {
+	return i.entry.modTime
+}
+
+func (i *memFileInfo) IsDir() bool {
+	return i.entry.isDir()
+}
+
+func (i *memFileInfo) Sys() any {
+	return nil
+}
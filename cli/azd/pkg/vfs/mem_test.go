@@ -0,0 +1,56 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package vfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemFs(t *testing.T) {
+	memFs := NewMemFs()
+	dir := filepath.FromSlash("/project/.azure")
+	file := filepath.Join(dir, "config.json")
+
+	_, err := memFs.ReadFile(file)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// the parent directory must exist
+	require.ErrorIs(t, memFs.WriteFile(file, []byte("{}"), 0600), fs.ErrNotExist)
+
+	require.NoError(t, memFs.MkdirAll(dir, 0755))
+	require.NoError(t, memFs.WriteFile(file, []byte("{}"), 0600))
+
+	contents, err := memFs.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(contents))
+
+	info, err := memFs.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, "config.json", info.Name())
+	require.Equal(t, int64(2), info.Size())
+	require.False(t, info.IsDir())
+
+	require.ErrorIs(t, memFs.Mkdir(dir, 0755), fs.ErrExist)
+	require.NoError(t, memFs.Mkdir(filepath.Join(dir, "dev"), 0755))
+
+	entries, err := memFs.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "config.json", entries[0].Name())
+	require.Equal(t, "dev", entries[1].Name())
+	require.True(t, entries[1].IsDir())
+
+	// directories with entries can't be removed
+	require.Error(t, memFs.Remove(dir))
+	require.NoError(t, memFs.Remove(file))
+	require.ErrorIs(t, memFs.Remove(file), fs.ErrNotExist)
+
+	require.NoError(t, memFs.RemoveAll(filepath.FromSlash("/project")))
+	_, err = memFs.Stat(dir)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
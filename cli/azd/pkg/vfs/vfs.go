@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package vfs abstracts the file system azd reads and writes projects and environments through, so they can be backed
+// by the OS file system, or by memory in tests and for projects mounted from remote sources.
+package vfs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// Fs is a file system. The methods behave like their counterparts in the os package, including returning errors which
+// match [fs.ErrNotExist] and [fs.ErrExist].
+type Fs interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// WriteFileDurable is like WriteFile, except the data is on disk once it returns, and the file is replaced at once:
+	// readers see either its previous contents or the data, ex) after a crash while it's written.
+	WriteFileDurable(name string, data []byte, perm fs.FileMode) error
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir returns the entries of the directory sorted by file name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Mkdir(name string, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+}
+
+type osFs struct{}
+
+// OsFs returns the file system of the OS.
+func OsFs() Fs {
+	return osFs{}
+}
+
+func (osFs) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFs) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// WriteFileDurable writes the data to a temporary file next to the file, syncs it and renames it over the file
+func (osFs) WriteFileDurable(name string, data []byte, perm fs.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// The temporary file is removed unless it's renamed
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(temp.Name(), perm); err != nil {
+		return err
+	}
+
+	if err := osutil.Rename(context.Background(), temp.Name(), name); err != nil {
+		return err
+	}
+
+	renamed = true
+	return nil
+}
+
+func (osFs) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFs) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFs) Mkdir(name string, perm fs.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+func (osFs) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFs) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFs) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
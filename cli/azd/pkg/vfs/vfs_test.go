@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package vfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOsFsWriteFileDurable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	fsys := OsFs()
+
+	require.NoError(t, fsys.WriteFileDurable(path, []byte("A=1\n"), 0600))
+	require.NoError(t, fsys.WriteFileDurable(path, []byte("A=2\n"), 0600))
+
+	contents, err := fsys.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "A=2\n", string(contents))
+
+	// The temporary files are renamed over the file
+	entries, err := fsys.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Writing to a missing directory fails without leaving a file behind
+	err = fsys.WriteFileDurable(filepath.Join(dir, "missing", ".env"), []byte("A=1\n"), 0600)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// RetryPolicy configures how an operation failing with transient errors is retried, waiting between the attempts with an
+// exponential backoff with jitter, until an attempt succeeds or the attempts or the time budget are exhausted.
+type RetryPolicy struct {
+	// The maximum number of attempts, including the first one. Zero doesn't limit the attempts, in which case MaxElapsed
+	// should be set.
+	MaxAttempts int
+	// The time budget after which no attempt is started anymore. Zero doesn't limit the time.
+	MaxElapsed time.Duration
+	// The delay before the second attempt.
+	Delay time.Duration
+	// The factor the delay grows by after each attempt. Values up to 1 keep the delay constant.
+	Multiplier float64
+	// The maximum delay between two attempts. Zero doesn't limit the delay.
+	MaxDelay time.Duration
+	// The fraction of the delay it's randomized by, ex) with 0.2 a delay of 10s is between 8s and 12s. Zero disables
+	// the jitter.
+	Jitter float64
+	// Classifies whether an attempt failed with a transient error. When nil, errors marked with [RetryableError] are
+	// retried.
+	Retryable func(err error) bool
+}
+
+// ConstantRetry returns a policy retrying the operation up to retries times after the first attempt, waiting the same
+// delay between the attempts.
+func ConstantRetry(retries int, delay time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: retries + 1,
+		Delay:       delay,
+	}
+}
+
+// ExponentialRetry returns a policy retrying until the budget is exhausted, doubling the delay after each attempt, up to
+// maxDelay, with a jitter of 20%.
+func ExponentialRetry(delay time.Duration, maxDelay time.Duration, budget time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxElapsed: budget,
+		Delay:      delay,
+		Multiplier: 2,
+		MaxDelay:   maxDelay,
+		Jitter:     0.2,
+	}
+}
+
+// retryableError marks an error as transient
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// RetryableError marks the error as transient, for the default classifier of the policy to retry the operation.
+func RetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryableError{err: err}
+}
+
+// IsRetryable reports whether the error is marked with [RetryableError].
+func IsRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// Retry runs the operation until it succeeds, it fails with an error the policy doesn't classify as retryable, or the
+// attempts or time budget of the policy are exhausted on the clock, in which case the error of the last attempt is
+// returned.
+func Retry(ctx context.Context, clk clock.Clock, policy RetryPolicy, operation func(ctx context.Context) error) error {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	start := clk.Now()
+	for attempt := 1; ; attempt++ {
+		err := operation(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable(err) {
+			return unwrapRetryable(err)
+		}
+
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return unwrapRetryable(err)
+		}
+
+		delay := policy.delay(attempt, rand.Float64())
+		if policy.MaxElapsed > 0 && clk.Since(start)+delay > policy.MaxElapsed {
+			return unwrapRetryable(err)
+		}
+
+		log.Printf("attempt %d failed, retrying in %s: %v", attempt, delay, err)
+
+		if err := sleep(ctx, clk, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// delay returns the delay after the attempt, where random is a random number in [0, 1) the jitter is applied with
+func (p RetryPolicy) delay(attempt int, random float64) time.Duration {
+	delay := float64(p.Delay)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		delay *= p.Multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			break
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*random - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// unwrapRetryable removes the transient mark of the error, returning the error of the operation
+func unwrapRetryable(err error) error {
+	if retryable, ok := err.(*retryableError); ok {
+		return retryable.err
+	}
+
+	return err
+}
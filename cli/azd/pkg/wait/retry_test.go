@@ -0,0 +1,139 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry(t *testing.T) {
+	t.Run("SucceedsAfterRetries", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), clock.New(), ConstantRetry(5, 0), func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return RetryableError(errTransient)
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), clock.New(), ConstantRetry(3, 0), func(ctx context.Context) error {
+			attempts++
+			return RetryableError(errTransient)
+		})
+
+		require.Equal(t, errTransient, err)
+		require.Equal(t, 4, attempts)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), clock.New(), ConstantRetry(4, 0), func(ctx context.Context) error {
+			attempts++
+			return errTransient
+		})
+
+		require.Equal(t, errTransient, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("Classifier", func(t *testing.T) {
+		attempts := 0
+		policy := ConstantRetry(3, 0)
+		policy.Retryable = func(err error) bool {
+			return errors.Is(err, errTransient)
+		}
+
+		err := Retry(context.Background(), clock.New(), policy, func(ctx context.Context) error {
+			attempts++
+			return errTransient
+		})
+
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, 4, attempts)
+	})
+
+	t.Run("BudgetExhausted", func(t *testing.T) {
+		clk := clock.NewMock()
+		policy := RetryPolicy{
+			MaxElapsed: 10 * time.Second,
+		}
+
+		// each attempt takes 4 seconds, so the budget is exhausted after the third attempt
+		attempts := 0
+		err := Retry(context.Background(), clk, policy, func(ctx context.Context) error {
+			attempts++
+			clk.Add(4 * time.Second)
+			return RetryableError(errTransient)
+		})
+
+		require.Equal(t, errTransient, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("DelayExceedsBudget", func(t *testing.T) {
+		attempts := 0
+		policy := ExponentialRetry(time.Minute, time.Hour, 30*time.Second)
+		err := Retry(context.Background(), clock.New(), policy, func(ctx context.Context) error {
+			attempts++
+			return RetryableError(errTransient)
+		})
+
+		require.Equal(t, errTransient, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, clock.New(), ConstantRetry(4, time.Minute), func(ctx context.Context) error {
+			cancel()
+			return RetryableError(errTransient)
+		})
+
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Run("Constant", func(t *testing.T) {
+		policy := ConstantRetry(10, 5*time.Second)
+		for attempt := 1; attempt < 5; attempt++ {
+			require.Equal(t, 5*time.Second, policy.delay(attempt, 0.5))
+		}
+	})
+
+	t.Run("Exponential", func(t *testing.T) {
+		policy := RetryPolicy{Delay: time.Second, Multiplier: 2, MaxDelay: 10 * time.Second}
+		expected := []time.Duration{
+			time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+		}
+		for i, delay := range expected {
+			require.Equal(t, delay, policy.delay(i+1, 0.5))
+		}
+
+		// a large number of attempts doesn't overflow the delay
+		require.Equal(t, 10*time.Second, policy.delay(1000, 0.5))
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		policy := ExponentialRetry(10*time.Second, time.Minute, time.Hour)
+		require.Equal(t, 8*time.Second, policy.delay(1, 0))
+		require.Equal(t, 10*time.Second, policy.delay(1, 0.5))
+		require.Equal(t, 11*time.Second, policy.delay(1, 0.75))
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package wait polls for a condition, ex) a deployed resource becoming ready, and retries operations failing with
+// transient errors, on an injected clock so the waits are configurable and tests don't have to wait in real time.
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// ErrTimeout is returned when the condition isn't met before the timeout elapses.
+var ErrTimeout = errors.New("timed out")
+
+// Config is how long to wait for a condition and how often to check it.
+type Config struct {
+	// The duration after which waiting stops, zero to check the condition once.
+	Timeout time.Duration
+	// The duration between two checks of the condition.
+	Interval time.Duration
+}
+
+// Validate returns an error when the durations of the config are negative.
+func (c Config) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout '%s' is negative", c.Timeout)
+	}
+
+	if c.Interval < 0 {
+		return fmt.Errorf("interval '%s' is negative", c.Interval)
+	}
+
+	return nil
+}
+
+// ConditionFn checks the condition, returning true when it's met. An error stops waiting.
+type ConditionFn func(ctx context.Context) (bool, error)
+
+// Until checks the condition every interval of the config until it's met, it returns an error or the timeout of the config
+// elapses, in which case [ErrTimeout] is returned.
+func Until(ctx context.Context, clk clock.Clock, config Config, condition ConditionFn) error {
+	deadline := clk.Now().Add(config.Timeout)
+
+	for {
+		done, err := condition(ctx)
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		if !clk.Now().Before(deadline) {
+			return fmt.Errorf("%w after %s", ErrTimeout, config.Timeout)
+		}
+
+		if err := sleep(ctx, clk, config.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits the duration on the clock, or returns the error of the context when it's done before.
+func sleep(ctx context.Context, clk clock.Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clk.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+// untilWithMock runs Until on a mock clock, advancing the clock by the interval until Until returns
+func untilWithMock(config Config, condition ConditionFn) (*clock.Mock, error) {
+	clk := clock.NewMock()
+	result := make(chan error, 1)
+	go func() {
+		result <- Until(context.Background(), clk, config, condition)
+	}()
+
+	for {
+		select {
+		case err := <-result:
+			return clk, err
+		case <-time.After(time.Millisecond):
+			clk.Add(config.Interval)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	t.Run("MetImmediately", func(t *testing.T) {
+		checks := 0
+		err := Until(context.Background(), clock.NewMock(), Config{Timeout: time.Minute, Interval: time.Second},
+			func(ctx context.Context) (bool, error) {
+				checks++
+				return true, nil
+			})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, checks)
+	})
+
+	t.Run("MetAfterChecks", func(t *testing.T) {
+		checks := 0
+		err := Until(context.Background(), clock.New(), Config{Timeout: time.Minute, Interval: time.Millisecond},
+			func(ctx context.Context) (bool, error) {
+				checks++
+				return checks == 3, nil
+			})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, checks)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		checks := 0
+		start := time.Now()
+		clk, err := untilWithMock(Config{Timeout: 10 * time.Minute, Interval: 10 * time.Second},
+			func(ctx context.Context) (bool, error) {
+				checks++
+				return false, nil
+			})
+
+		require.ErrorIs(t, err, ErrTimeout)
+		require.GreaterOrEqual(t, checks, 2)
+		// the mock clock elapsed the timeout, not the wall clock
+		require.GreaterOrEqual(t, clk.Now().Sub(time.Unix(0, 0)), 10*time.Minute)
+		require.Less(t, time.Since(start), time.Minute)
+	})
+
+	t.Run("ZeroTimeoutChecksOnce", func(t *testing.T) {
+		checks := 0
+		err := Until(context.Background(), clock.NewMock(), Config{},
+			func(ctx context.Context) (bool, error) {
+				checks++
+				return false, nil
+			})
+
+		require.ErrorIs(t, err, ErrTimeout)
+		require.Equal(t, 1, checks)
+	})
+
+	t.Run("ConditionError", func(t *testing.T) {
+		conditionErr := errors.New("not found")
+		err := Until(context.Background(), clock.NewMock(), Config{Timeout: time.Minute, Interval: time.Second},
+			func(ctx context.Context) (bool, error) {
+				return false, conditionErr
+			})
+
+		require.ErrorIs(t, err, conditionErr)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Until(ctx, clock.NewMock(), Config{Timeout: time.Minute, Interval: time.Second},
+			func(ctx context.Context) (bool, error) {
+				cancel()
+				return false, nil
+			})
+
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{Timeout: time.Minute}.Validate())
+	require.Error(t, Config{Timeout: -time.Minute}.Validate())
+	require.Error(t, Config{Interval: -time.Second}.Validate())
+}
@@ -0,0 +1,201 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package workspace registers the directories of azd projects by name in the user config, for commands to run against a
+// project without changing to its directory, ex) `azd deploy -w shop-api`.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+)
+
+// The paths of the user config the workspaces are stored at, the directories of the projects by workspace name, and the
+// name of the current workspace
+const (
+	projectsConfigPath = "workspaces.projects"
+	currentConfigPath  = "workspaces.current"
+)
+
+// The names of workspaces are keys of the user config, which can't have dots
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// Workspace is a registered azd project
+type Workspace struct {
+	Name string `json:"name"`
+	// The directory of the azure.yaml of the project
+	Path string `json:"path"`
+	// The default environment of the project, empty when it has none
+	DefaultEnvironment string `json:"defaultEnvironment,omitempty"`
+	// Whether commands run outside of a project run against the project of the workspace
+	Current bool `json:"current"`
+}
+
+// Manager registers workspaces, and switches between them
+type Manager struct {
+	userConfigManager config.UserConfigManager
+}
+
+// NewManager creates a workspace manager
+func NewManager(userConfigManager config.UserConfigManager) *Manager {
+	return &Manager{
+		userConfigManager: userConfigManager,
+	}
+}
+
+// List returns the registered workspaces, sorted by name
+func (m *Manager) List() ([]*Workspace, error) {
+	userConfig, err := m.userConfigManager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := userConfig.Get(currentConfigPath)
+	projects, _ := userConfig.Get(projectsConfigPath)
+	projectsMap, _ := projects.(map[string]any)
+
+	workspaces := []*Workspace{}
+	for name, path := range projectsMap {
+		workspace := &Workspace{
+			Name:    name,
+			Path:    fmt.Sprint(path),
+			Current: current == name,
+		}
+
+		// The default environment is read from the project, which changes it with `azd env select`
+		workspace.DefaultEnvironment, _ = azdcontext.NewAzdContextWithDirectory(workspace.Path).GetDefaultEnvironmentName()
+		workspaces = append(workspaces, workspace)
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Name < workspaces[j].Name
+	})
+
+	return workspaces, nil
+}
+
+// Get returns the registered workspace of the name
+func (m *Manager) Get(name string) (*Workspace, error) {
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.Name == name {
+			return workspace, nil
+		}
+	}
+
+	return nil, fmt.Errorf("workspace '%s' isn't registered, register it with `azd workspace add`", name)
+}
+
+// Current returns the current workspace, nil when there is none
+func (m *Manager) Current() (*Workspace, error) {
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.Current {
+			return workspace, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Add registers the project of the directory as the workspace of the name, replacing the directory of the workspace when
+// it's registered. Returns the registered workspace.
+func (m *Manager) Add(name string, path string) (*Workspace, error) {
+	if !nameRegex.MatchString(name) {
+		return nil, fmt.Errorf(
+			"invalid workspace name '%s', names start with a letter or a digit followed by letters, digits, "+
+				"'-' or '_'", name)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(path, azdcontext.ProjectFileName)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("'%s' isn't an azd project, it has no %s", path, azdcontext.ProjectFileName)
+		}
+
+		return nil, err
+	}
+
+	userConfig, err := m.userConfigManager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := userConfig.Set(projectsConfigPath+"."+name, path); err != nil {
+		return nil, err
+	}
+
+	if err := m.userConfigManager.Save(userConfig); err != nil {
+		return nil, err
+	}
+
+	return m.Get(name)
+}
+
+// Remove unregisters the workspace of the name, and clears the current workspace when it's the one
+func (m *Manager) Remove(name string) error {
+	if _, err := m.Get(name); err != nil {
+		return err
+	}
+
+	userConfig, err := m.userConfigManager.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := userConfig.Unset(projectsConfigPath + "." + name); err != nil {
+		return err
+	}
+
+	if current, _ := userConfig.Get(currentConfigPath); current == name {
+		if err := userConfig.Unset(currentConfigPath); err != nil {
+			return err
+		}
+	}
+
+	return m.userConfigManager.Save(userConfig)
+}
+
+// Switch makes the workspace of the name the current workspace, or clears the current workspace when the name is empty
+func (m *Manager) Switch(name string) error {
+	if name != "" {
+		if _, err := m.Get(name); err != nil {
+			return err
+		}
+	}
+
+	userConfig, err := m.userConfigManager.Load()
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		err = userConfig.Unset(currentConfigPath)
+	} else {
+		err = userConfig.Set(currentConfigPath, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.userConfigManager.Save(userConfig)
+}
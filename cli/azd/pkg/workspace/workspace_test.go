@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProject creates an azd project whose default environment is the given one, when not empty
+func newTestProject(t *testing.T, defaultEnvironment string) string {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, azdcontext.ProjectFileName), []byte("name: test"), 0600))
+
+	if defaultEnvironment != "" {
+		require.NoError(t, azdcontext.NewAzdContextWithDirectory(path).SetDefaultEnvironmentName(defaultEnvironment))
+	}
+
+	return path
+}
+
+func Test_Manager(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	manager := NewManager(config.NewUserConfigManager())
+
+	shopApi := newTestProject(t, "dev")
+	billing := newTestProject(t, "")
+
+	workspaces, err := manager.List()
+	require.NoError(t, err)
+	require.Empty(t, workspaces)
+
+	ws, err := manager.Add("shop-api", shopApi)
+	require.NoError(t, err)
+	require.Equal(t, &Workspace{Name: "shop-api", Path: shopApi, DefaultEnvironment: "dev"}, ws)
+
+	_, err = manager.Add("billing", billing)
+	require.NoError(t, err)
+
+	workspaces, err = manager.List()
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+	require.Equal(t, "billing", workspaces[0].Name)
+	require.Equal(t, "shop-api", workspaces[1].Name)
+
+	current, err := manager.Current()
+	require.NoError(t, err)
+	require.Nil(t, current)
+
+	require.NoError(t, manager.Switch("shop-api"))
+	current, err = manager.Current()
+	require.NoError(t, err)
+	require.Equal(t, "shop-api", current.Name)
+
+	require.ErrorContains(t, manager.Switch("orders"), "workspace 'orders' isn't registered")
+
+	// Removing the current workspace clears it
+	require.NoError(t, manager.Remove("shop-api"))
+	current, err = manager.Current()
+	require.NoError(t, err)
+	require.Nil(t, current)
+
+	_, err = manager.Get("shop-api")
+	require.Error(t, err)
+
+	require.NoError(t, manager.Switch("billing"))
+	require.NoError(t, manager.Switch(""))
+	current, err = manager.Current()
+	require.NoError(t, err)
+	require.Nil(t, current)
+}
+
+func Test_Manager_Add_Invalid(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	manager := NewManager(config.NewUserConfigManager())
+
+	_, err := manager.Add("shop.api", newTestProject(t, ""))
+	require.ErrorContains(t, err, "invalid workspace name 'shop.api'")
+
+	_, err = manager.Add("shop-api", t.TempDir())
+	require.ErrorContains(t, err, "isn't an azd project, it has no azure.yaml")
+}
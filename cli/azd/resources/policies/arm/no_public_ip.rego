@@ -0,0 +1,18 @@
+package azd.policy.arm
+
+import rego.v1
+
+# Denies public IP addresses anywhere in the rendered template, including nested module deployments.
+# Set `allowPublicIp: true` in the policy settings to opt out.
+deny contains violation if {
+	not data.settings.allowPublicIp
+	walk(input.template, [_, resource])
+	is_object(resource)
+	is_string(resource.type)
+	lower(resource.type) == "microsoft.network/publicipaddresses"
+	violation := {
+		"policy": "no-public-ip",
+		"resource": object.get(resource, "name", ""),
+		"msg": "public IP addresses are not allowed",
+	}
+}
@@ -0,0 +1,18 @@
+package azd.policy.arm
+
+import rego.v1
+
+# Denies resources that declare tags but are missing one of the tags listed in the `requiredTags` policy setting.
+deny contains violation if {
+	some tag in data.settings.requiredTags
+	walk(input.template, [_, resource])
+	is_object(resource)
+	is_string(resource.type)
+	is_object(resource.tags)
+	not resource.tags[tag]
+	violation := {
+		"policy": "required-tags",
+		"resource": object.get(resource, "name", ""),
+		"msg": sprintf("resource of type '%s' is missing required tag '%s'", [resource.type, tag]),
+	}
+}
@@ -0,0 +1,24 @@
+package azd.policy.k8s
+
+import rego.v1
+
+# Denies container images that are not pulled from one of the registries listed in the `allowedRegistries`
+# policy setting. The policy is a no-op when no registries are configured.
+deny contains violation if {
+	count(data.settings.allowedRegistries) > 0
+	some manifest in input.manifests
+	walk(manifest, [_, container])
+	is_object(container)
+	is_string(container.image)
+	not allowed_image(container.image)
+	violation := {
+		"policy": "allowed-registries",
+		"resource": object.get(manifest.metadata, "name", ""),
+		"msg": sprintf("image '%s' is not from an allowed registry", [container.image]),
+	}
+}
+
+allowed_image(image) if {
+	some registry in data.settings.allowedRegistries
+	startswith(image, concat("", [registry, "/"]))
+}
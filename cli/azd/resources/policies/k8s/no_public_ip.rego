@@ -0,0 +1,20 @@
+package azd.policy.k8s
+
+import rego.v1
+
+internal_annotation := "service.beta.kubernetes.io/azure-load-balancer-internal"
+
+# Denies LoadBalancer services that would be assigned a public IP address.
+# Set `allowPublicIp: true` in the policy settings to opt out.
+deny contains violation if {
+	not data.settings.allowPublicIp
+	some manifest in input.manifests
+	manifest.kind == "Service"
+	manifest.spec.type == "LoadBalancer"
+	object.get(manifest.metadata, ["annotations", internal_annotation], "false") != "true"
+	violation := {
+		"policy": "no-public-ip",
+		"resource": object.get(manifest.metadata, "name", ""),
+		"msg": "LoadBalancer services must be internal, public IP addresses are not allowed",
+	}
+}
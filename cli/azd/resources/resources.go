@@ -1,7 +1,7 @@
 package resources
 
 import (
-	_ "embed"
+	"embed"
 )
 
 //go:embed templates.json
@@ -15,3 +15,12 @@ var MinimalBicep []byte
 
 //go:embed minimal/main.parameters.json
 var MinimalBicepParameters []byte
+
+//go:embed policies
+var Policies embed.FS
+
+//go:embed modules
+var Modules embed.FS
+
+//go:embed import
+var ImportTemplates embed.FS
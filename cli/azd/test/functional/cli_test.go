@@ -34,11 +34,12 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
 	"github.com/azure/azure-dev/cli/azd/test/azdcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
 	"github.com/azure/azure-dev/cli/azd/test/recording"
+	"github.com/benbjohnson/clock"
 	"github.com/joho/godotenv"
-	"github.com/sethvargo/go-retry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
@@ -657,9 +658,10 @@ func logHandles(t *testing.T, path string) {
 func removeAllWithDiagnostics(t *testing.T, path string) error {
 	retryCount := 0
 	loggedOnce := false
-	return retry.Do(
+	return wait.Retry(
 		context.Background(),
-		retry.WithMaxRetries(10, retry.NewConstant(1*time.Second)),
+		clock.New(),
+		wait.ConstantRetry(10, 1*time.Second),
 		func(_ context.Context) error {
 			removeErr := os.RemoveAll(path)
 			if removeErr == nil {
@@ -674,7 +676,7 @@ func removeAllWithDiagnostics(t *testing.T, path string) error {
 			}
 
 			retryCount++
-			return retry.RetryableError(removeErr)
+			return wait.RetryableError(removeErr)
 		},
 	)
 }
@@ -20,9 +20,10 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/wait"
 	"github.com/azure/azure-dev/cli/azd/test/azdcli"
+	"github.com/benbjohnson/clock"
 	"github.com/joho/godotenv"
-	"github.com/sethvargo/go-retry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -192,15 +193,15 @@ func Test_CLI_Up_Down_FuncApp(t *testing.T) {
 	// We've seen some cases in CI where issuing a get right after a deploy ends up with us getting a 404, so retry the
 	// request a
 	// handful of times if it fails with a 404.
-	err = retry.Do(ctx, retry.WithMaxRetries(10, retry.NewConstant(5*time.Second)), func(ctx context.Context) error {
+	err = wait.Retry(ctx, clock.New(), wait.ConstantRetry(10, 5*time.Second), func(ctx context.Context) error {
 		/* #nosec G107 - Potential HTTP request made with variable url false positive */
 		res, err := http.Get(url)
 		if err != nil {
-			return retry.RetryableError(err)
+			return wait.RetryableError(err)
 		}
 		defer res.Body.Close()
 		if res.StatusCode != http.StatusOK {
-			return retry.RetryableError(
+			return wait.RetryableError(
 				fmt.Errorf("expected %d but got %d for request to %s", http.StatusOK, res.StatusCode, url),
 			)
 		}
@@ -333,13 +334,13 @@ func Test_CLI_Up_ResourceGroupScope(t *testing.T) {
 // Validates that the service is up-and-running, by issuing a GET request
 // and expecting a 2XX status code, with a matching response body.
 func probeServiceHealth(t *testing.T, ctx context.Context, url string, expectedBody string) error {
-	return retry.Do(ctx, retry.WithMaxRetries(10, retry.NewConstant(5*time.Second)), func(ctx context.Context) error {
+	return wait.Retry(ctx, clock.New(), wait.ConstantRetry(10, 5*time.Second), func(ctx context.Context) error {
 		t.Logf("Attempting to Get URL: %s", url)
 
 		/* #nosec G107 - Potential HTTP request made with variable url false positive */
 		res, err := http.Get(url)
 		if err != nil {
-			return retry.RetryableError(err)
+			return wait.RetryableError(err)
 		}
 
 		var buf bytes.Buffer
@@ -349,7 +350,7 @@ func probeServiceHealth(t *testing.T, ctx context.Context, url string, expectedB
 		bodyString := buf.String()
 
 		if bodyString != expectedBody {
-			return retry.RetryableError(
+			return wait.RetryableError(
 				fmt.Errorf("expected %s but got %s for request to %s", expectedBody, bodyString, url),
 			)
 		} else {
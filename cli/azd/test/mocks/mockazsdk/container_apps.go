@@ -105,6 +105,64 @@ func MockContainerAppRevisionGet(
 	return mockRequest
 }
 
+func MockContainerAppRevisionRestart(
+	mockContext *mocks.MockContext,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	revisionName string,
+) *http.Request {
+	mockRequest := &http.Request{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(
+			request.URL.Path,
+			fmt.Sprintf(
+				"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s/revisions/%s/restart",
+				subscriptionId,
+				resourceGroup,
+				appName,
+				revisionName,
+			),
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		*mockRequest = *request
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	return mockRequest
+}
+
+func MockContainerAppRevisionDeactivate(
+	mockContext *mocks.MockContext,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	revisionName string,
+) *http.Request {
+	mockRequest := &http.Request{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(
+			request.URL.Path,
+			fmt.Sprintf(
+				"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s/revisions/%s/deactivate",
+				subscriptionId,
+				resourceGroup,
+				appName,
+				revisionName,
+			),
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		*mockRequest = *request
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	return mockRequest
+}
+
 func MockContainerAppSecretsList(
 	mockContext *mocks.MockContext,
 	subscriptionId string,
@@ -136,3 +194,73 @@ func MockContainerAppSecretsList(
 
 	return mockRequest
 }
+
+func MockContainerAppReplicasList(
+	mockContext *mocks.MockContext,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	revisionName string,
+	replicas *armappcontainers.ReplicaCollection,
+) *http.Request {
+	mockRequest := &http.Request{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.Contains(
+			request.URL.Path,
+			fmt.Sprintf(
+				"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s/revisions/%s/replicas",
+				subscriptionId,
+				resourceGroup,
+				appName,
+				revisionName,
+			),
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		*mockRequest = *request
+
+		response := armappcontainers.ContainerAppsRevisionReplicasClientListReplicasResponse{
+			ReplicaCollection: *replicas,
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, response)
+	})
+
+	return mockRequest
+}
+
+func MockContainerAppAuthToken(
+	mockContext *mocks.MockContext,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	token string,
+) *http.Request {
+	mockRequest := &http.Request{}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(
+			request.URL.Path,
+			fmt.Sprintf(
+				"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s/getAuthtoken",
+				subscriptionId,
+				resourceGroup,
+				appName,
+			),
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		*mockRequest = *request
+
+		response := armappcontainers.ContainerAppsClientGetAuthTokenResponse{
+			ContainerAppAuthToken: armappcontainers.ContainerAppAuthToken{
+				Properties: &armappcontainers.ContainerAppAuthTokenProperties{
+					Token: &token,
+				},
+			},
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, response)
+	})
+
+	return mockRequest
+}
@@ -114,6 +114,19 @@ func RegisterApplicationRemovePasswordMock(
 	})
 }
 
+func RegisterApplicationSetKeyCredentialsMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch &&
+			strings.HasSuffix(request.URL.Path, fmt.Sprintf("/applications/%s", appId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, statusCode)
+	})
+}
+
 func RegisterServicePrincipalListMock(
 	mockContext *mocks.MockContext,
 	statusCode int,